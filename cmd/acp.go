@@ -37,6 +37,7 @@ protocol stream.`,
 		cwd, _ := cmd.Flags().GetString("cwd")
 		debug, _ := cmd.Flags().GetBool("debug")
 		autoApprove, _ := cmd.Flags().GetBool("auto-approve")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
 
 		if cwd != "" {
 			if err := os.Chdir(cwd); err != nil {
@@ -51,10 +52,13 @@ protocol stream.`,
 			cwd = c
 		}
 
-		_, err := config.Load(cwd, debug)
+		cfg, err := config.Load(cwd, debug)
 		if err != nil {
 			return err
 		}
+		if readOnly {
+			cfg.ReadOnly = true
+		}
 
 		// ACP is headless: log to stderr so the user sees output and
 		// stdout stays clean for the JSON-RPC protocol stream.
@@ -122,6 +126,7 @@ func init() {
 	acpCmd.Flags().StringP("cwd", "c", "", "Working directory for the project")
 	acpCmd.Flags().BoolP("debug", "d", false, "Enable debug logging")
 	acpCmd.Flags().Bool("auto-approve", false, "Auto-approve all permission requests (dangerous — no human in the loop)")
+	acpCmd.Flags().Bool("read-only", false, "Disable every tool that changes files and only allow read-only bash commands")
 
 	rootCmd.AddCommand(acpCmd)
 }
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query, verify and export the compliance audit trail",
+	Long: `Work with the audit trail recorded when "audit.enabled" is set.
+
+Every entry of the trail — tool calls, permission decisions and provider
+request digests — carries the hash of the entry before it, and is signed
+when "audit.signingKey" is configured. verify recomputes the chain and
+reports the head hash; keep that hash somewhere the trail's writers cannot
+change to later prove the trail was not truncated.
+
+Tool calls are also copied to the tool_executions table of the project
+database, which list filters by session, tool and time range.`,
+	Example: `
+  # Every bash call of the last day
+  opencode audit list --tool bash --since 24h
+
+  # Check the project's trail, including signatures
+  opencode audit verify --public-key audit.pub
+
+  # Hand a verified copy to an auditor
+  opencode audit export -o audit-2026-10.jsonl`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audited tool calls",
+	Long: `List the audited tool calls recorded in the project database, oldest
+first. --since and --until take an RFC 3339 time, a date (2006-01-02) or a
+duration counted back from now (90m, 24h, 7d).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var f audit.Filter
+		f.SessionID, _ = cmd.Flags().GetString("session")
+		f.Tool, _ = cmd.Flags().GetString("tool")
+		f.Limit, _ = cmd.Flags().GetInt("limit")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		now := time.Now()
+		for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+			value, _ := cmd.Flags().GetString(name)
+			if value == "" {
+				continue
+			}
+			t, err := parseTimeFlag(value, now)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", name, err)
+			}
+			*dst = t
+		}
+
+		conn, err := openSessionDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		executions, err := audit.List(context.Background(), db.NewQuerier(conn), f)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(executions)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tSESSION\tAGENT\tTOOL\tSTATUS\tEXIT\tDURATION\tDECISION\tINPUT")
+		for _, e := range executions {
+			exit := "-"
+			if e.ExitCode != nil {
+				exit = fmt.Sprint(*e.ExitCode)
+			}
+			decision := "-"
+			if e.Decision != "" {
+				decision = e.Decision + " (" + e.Via + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%dms\t%s\t%s\n",
+				e.Time.Local().Format(time.DateTime), e.SessionID, e.AgentID, e.Tool,
+				e.Status, exit, e.DurationMs, decision, abbreviateInput(e.Input))
+		}
+		return w.Flush()
+	},
+}
+
+// parseTimeFlag reads a --since/--until value: an RFC 3339 time, a local
+// date, or a duration before now. Shared by the audit and session commands.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if d, err := config.ParseDurationExtended(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time, date or duration", value)
+}
+
+// abbreviateInput fits a tool input on one table row.
+func abbreviateInput(input string) string {
+	input = strings.Join(strings.Fields(input), " ")
+	if r := []rune(input); len(r) > 80 {
+		input = string(r[:79]) + "…"
+	}
+	return input
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify [trail.jsonl]",
+	Short: "Check that the audit trail has not been tampered with",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, pub, err := readAuditTrail(cmd, args)
+		if err != nil {
+			return err
+		}
+		res, err := audit.Verify(bytes.NewReader(data), pub)
+		if err != nil {
+			return fmt.Errorf("audit trail verification failed: %w", err)
+		}
+		fmt.Printf("OK: %d entries, %d signed\nhead: %s\n", res.Entries, res.Signed, res.Head)
+		return nil
+	},
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export [trail.jsonl]",
+	Short: "Verify the audit trail and copy it",
+	Long: `Verify the audit trail and write a copy of it. Nothing is written when
+verification fails. The head hash printed on stderr identifies the copy.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		data, pub, err := readAuditTrail(cmd, args)
+		if err != nil {
+			return err
+		}
+		res, err := audit.Verify(bytes.NewReader(data), pub)
+		if err != nil {
+			return fmt.Errorf("audit trail verification failed: %w", err)
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write trail: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d entries, head %s\n", res.Entries, res.Head)
+		return nil
+	},
+}
+
+// readAuditTrail reads the trail named on the command line, or the one the
+// project config points at, along with the key to check signatures with.
+func readAuditTrail(cmd *cobra.Command, args []string) ([]byte, ed25519.PublicKey, error) {
+	cwd, _ := cmd.Flags().GetString("cwd")
+	keyPath, _ := cmd.Flags().GetString("public-key")
+
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+		cfg, err := config.Load(cwd, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cfg.Audit == nil {
+			return nil, nil, fmt.Errorf("no audit trail configured; pass the trail file as an argument")
+		}
+		path = audit.Path(cfg)
+		if keyPath == "" {
+			keyPath = cfg.Audit.SigningKey
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pub ed25519.PublicKey
+	if keyPath != "" {
+		if pub, err = audit.LoadPublicKey(keyPath); err != nil {
+			return nil, nil, err
+		}
+	}
+	return data, pub, nil
+}
+
+func init() {
+	auditCmd.PersistentFlags().StringP("cwd", "c", "", "Working directory for the project")
+	auditCmd.PersistentFlags().String("public-key", "", "PEM Ed25519 public key that must have signed every entry (default: the configured signing key)")
+	auditCmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug logging")
+	auditListCmd.Flags().String("session", "", "Only calls made in this session")
+	auditListCmd.Flags().String("tool", "", "Only calls of this tool")
+	auditListCmd.Flags().String("since", "", "Only calls at or after this time")
+	auditListCmd.Flags().String("until", "", "Only calls at or before this time")
+	auditListCmd.Flags().Int("limit", 0, "Maximum number of calls to show (default: all)")
+	auditListCmd.Flags().Bool("json", false, "Print calls as JSON")
+	auditExportCmd.Flags().StringP("output", "o", "", "File to write the copy to (default: stdout)")
+
+	auditCmd.AddCommand(auditListCmd, auditVerifyCmd, auditExportCmd)
+	rootCmd.AddCommand(auditCmd)
+}
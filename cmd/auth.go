@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/copilot"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Sign in to providers that use account login instead of API keys",
+}
+
+var authCopilotCmd = &cobra.Command{
+	Use:   "copilot",
+	Short: "Sign in to GitHub Copilot with a device code",
+	Long: `Sign in to GitHub Copilot through GitHub's device flow.
+
+The command prints a code to enter at github.com/login/device. Once the
+account is authorised, the GitHub token is stored in
+$XDG_DATA_HOME/opencode/copilot.json (~/.local/share/opencode/copilot.json)
+together with the chat models the subscription offers. They are available
+as "copilot.<model>" on the next start, e.g. copilot.gpt-4.1. The list is
+refreshed in the background whenever a Copilot model is used.`,
+	Example: `
+  # Sign in
+  opencode auth copilot
+
+  # Forget the stored token
+  opencode auth copilot --logout`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if logout, _ := cmd.Flags().GetBool("logout"); logout {
+			if err := copilot.RemoveAuth(); err != nil {
+				return fmt.Errorf("failed to remove Copilot sign-in: %w", err)
+			}
+			fmt.Println("Signed out of GitHub Copilot.")
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		code, err := copilot.RequestDeviceCode(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start GitHub sign-in: %w", err)
+		}
+		fmt.Printf("Open %s and enter the code %s\nWaiting for authorisation...\n", code.VerificationURI, code.UserCode)
+		oauthToken, err := copilot.PollAccessToken(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		listCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		list, err := copilot.FetchModels(listCtx, copilot.NewTokenSource(oauthToken))
+		if err != nil {
+			return err
+		}
+		if err := copilot.SaveAuth(copilot.Auth{OAuthToken: oauthToken, Models: list, UpdatedAt: time.Now().Unix()}); err != nil {
+			return fmt.Errorf("failed to store Copilot sign-in: %w", err)
+		}
+		fmt.Printf("Signed in to GitHub Copilot; %d models available:\n", len(list))
+		for _, m := range list {
+			fmt.Printf("  copilot.%s\t%s\n", m.ID, m.Name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	authCopilotCmd.Flags().Bool("logout", false, "Remove the stored GitHub token")
+
+	authCmd.AddCommand(authCopilotCmd)
+	rootCmd.AddCommand(authCmd)
+}
@@ -456,7 +456,7 @@ func main() {
 	// task(s) that completed during the wait. Interactive ctx and
 	// no-pending-task ctx must execute the sleep verbatim.
 	{
-		fullBash := tools.NewBashTool(perm, agentReg)
+		fullBash := tools.NewBashTool(perm, agentReg, tools.OutputLimits{})
 		ireg := task.GlobalRegistry()
 		niCtx := context.WithValue(ctx, tools.NonInteractiveContextKey, true)
 
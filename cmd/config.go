@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/doctor"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check the configuration",
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find configuration problems and how to fix them",
+	Long: `Load the configuration the way opencode does and check it:
+
+  - every warning the loader logs, such as an invalid model replaced by the
+    default, is reported
+  - each provider key is tried with a model listing request (OpenAI,
+    Anthropic and Gemini)
+  - the model and fallback models of every agent must exist, belong to a
+    configured provider and be available to the account
+  - each enabled MCP server is started and asked for its tools
+  - each configured language server binary must be installed
+
+Problems are printed with a suggested fix. The command exits with status 1
+when any problem is an error, so it can gate CI or a dotfiles setup.`,
+	Example: `
+  # Check everything
+  opencode config doctor
+
+  # Skip the provider calls and MCP server starts
+  opencode config doctor --offline`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		offline, _ := cmd.Flags().GetBool("offline")
+		profile, _ := cmd.Flags().GetString("profile")
+
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+		if cmd.Flags().Changed("profile") {
+			config.SetProfile(profile)
+		}
+
+		logged := len(logging.List())
+		cfg, err := config.Load(cwd, false)
+		if err != nil {
+			cmd.SilenceUsage = true
+			printProblems([]doctor.Problem{{
+				Severity: doctor.SeverityError,
+				Area:     "config",
+				Problem:  err.Error(),
+				Fix:      "Fix the config file; nothing else can be checked until it loads",
+			}})
+			return fmt.Errorf("the configuration does not load")
+		}
+		reg := agentregistry.GetRegistry()
+		loadLogs := logging.List()[logged:]
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		report := doctor.Run(ctx, cfg, reg, doctor.Options{Offline: offline, LoadLogs: loadLogs})
+
+		fmt.Printf("Checked %d providers, %d agents, %d MCP servers and %d language servers.\n",
+			report.Checked["provider"], report.Checked["agent"], report.Checked["mcp"], report.Checked["lsp"])
+		if len(report.Problems) == 0 {
+			fmt.Println("No problems found.")
+			return nil
+		}
+		fmt.Println()
+		printProblems(report.Problems)
+		if n := report.Errors(); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d problem(s) need fixing", n)
+		}
+		return nil
+	},
+}
+
+// printProblems prints one row per problem, then its fix indented below.
+func printProblems(problems []doctor.Problem) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tAREA\tSUBJECT\tPROBLEM")
+	for _, p := range problems {
+		subject := p.Subject
+		if subject == "" {
+			subject = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Severity, p.Area, subject, p.Problem)
+		if p.Fix != "" {
+			fmt.Fprintf(w, "\t\t\t  fix: %s\n", p.Fix)
+		}
+	}
+	w.Flush()
+}
+
+func init() {
+	configDoctorCmd.Flags().StringP("cwd", "c", "", "Working directory for the project")
+	configDoctorCmd.Flags().Bool("offline", false, "Skip the checks that call providers or start MCP servers")
+	configDoctorCmd.Flags().String("profile", "", "Configuration profile to check")
+	configCmd.AddCommand(configDoctorCmd)
+	rootCmd.AddCommand(configCmd)
+}
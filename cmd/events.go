@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// runEvent is one line of the jsonl output format. Fields not used by an
+// event type are left out.
+type runEvent struct {
+	Type      string `json:"type"`
+	Time      int64  `json:"time"`
+	SessionID string `json:"session_id,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+
+	// start and message
+	Agent     string `json:"agent,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Reasoning string `json:"reasoning,omitempty"`
+
+	// tool_call and tool_result
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	Tool       string          `json:"tool,omitempty"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Output     string          `json:"output,omitempty"`
+	IsError    bool            `json:"is_error,omitempty"`
+
+	// usage: running totals of the session
+	InputTokens  int64   `json:"input_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+
+	// agent and result
+	Event        string          `json:"event,omitempty"`
+	Progress     string          `json:"progress,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	StructOutput json.RawMessage `json:"struct_output,omitempty"`
+}
+
+// Event types of the jsonl output format.
+const (
+	runEventStart      = "start"
+	runEventMessage    = "message"
+	runEventToolCall   = "tool_call"
+	runEventToolResult = "tool_result"
+	runEventUsage      = "usage"
+	runEventAgent      = "agent"
+	runEventResult     = "result"
+)
+
+// jsonlEmitter writes the events of one non-interactive run as JSON lines.
+// Message updates arrive as full snapshots many times over while a response
+// streams, so each tool call, tool result and finished message is written
+// once, the first time it is complete.
+type jsonlEmitter struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	sessions session.Service
+	root     string
+
+	// inTree caches which sessions belong to the run's session tree.
+	inTree  map[string]bool
+	emitted map[string]bool
+	totals  map[string][3]float64
+}
+
+func newJSONLEmitter(w io.Writer, sessions session.Service, sess session.Session) *jsonlEmitter {
+	root := sess.RootSessionID
+	if root == "" {
+		root = sess.ID
+	}
+	return &jsonlEmitter{
+		enc:      json.NewEncoder(w),
+		sessions: sessions,
+		root:     root,
+		inTree:   map[string]bool{sess.ID: true, root: true},
+		emitted:  map[string]bool{},
+		totals:   map[string][3]float64{},
+	}
+}
+
+func (e *jsonlEmitter) emit(ev runEvent) {
+	ev.Time = time.Now().UnixMilli()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.enc.Encode(ev); err != nil {
+		logging.Warn("Failed to write event", "type", ev.Type, "error", err)
+	}
+}
+
+// once reports whether key is seen for the first time.
+func (e *jsonlEmitter) once(key string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.emitted[key] {
+		return false
+	}
+	e.emitted[key] = true
+	return true
+}
+
+func (e *jsonlEmitter) belongs(ctx context.Context, sessionID string) bool {
+	e.mu.Lock()
+	in, ok := e.inTree[sessionID]
+	e.mu.Unlock()
+	if ok {
+		return in
+	}
+	s, err := e.sessions.Get(ctx, sessionID)
+	in = err == nil && s.RootSessionID == e.root
+	e.mu.Lock()
+	e.inTree[sessionID] = in
+	e.mu.Unlock()
+	return in
+}
+
+func (e *jsonlEmitter) message(ctx context.Context, msg message.Message) {
+	if !e.belongs(ctx, msg.SessionID) {
+		return
+	}
+	for _, call := range msg.ToolCalls() {
+		if !call.Finished || !e.once("call:"+call.ID) {
+			continue
+		}
+		input := json.RawMessage(call.Input)
+		if !json.Valid(input) {
+			input, _ = json.Marshal(call.Input)
+		}
+		e.emit(runEvent{
+			Type:       runEventToolCall,
+			SessionID:  msg.SessionID,
+			MessageID:  msg.ID,
+			ToolCallID: call.ID,
+			Tool:       call.Name,
+			Input:      input,
+		})
+	}
+	for _, result := range msg.ToolResults() {
+		if !e.once("result:" + result.ToolCallID) {
+			continue
+		}
+		output := result.Content
+		if result.IsImageToolResponse() {
+			output = "[image]"
+		}
+		e.emit(runEvent{
+			Type:       runEventToolResult,
+			SessionID:  msg.SessionID,
+			MessageID:  msg.ID,
+			ToolCallID: result.ToolCallID,
+			Tool:       result.Name,
+			Output:     output,
+			IsError:    result.IsError,
+		})
+	}
+	if msg.Role != message.Assistant || !msg.IsFinished() {
+		return
+	}
+	text, reasoning := msg.Content().Text, msg.ReasoningContent().Thinking
+	if (text == "" && reasoning == "") || !e.once("message:"+msg.ID) {
+		return
+	}
+	e.emit(runEvent{
+		Type:      runEventMessage,
+		SessionID: msg.SessionID,
+		MessageID: msg.ID,
+		Model:     string(msg.Model),
+		Text:      text,
+		Reasoning: reasoning,
+	})
+}
+
+// usage writes the session's totals when they changed since the last line.
+func (e *jsonlEmitter) usage(ctx context.Context, s session.Session) {
+	if !e.belongs(ctx, s.ID) {
+		return
+	}
+	totals := [3]float64{float64(s.PromptTokens), float64(s.CompletionTokens), s.Cost}
+	e.mu.Lock()
+	changed := e.totals[s.ID] != totals
+	e.totals[s.ID] = totals
+	e.mu.Unlock()
+	if !changed {
+		return
+	}
+	e.emit(runEvent{
+		Type:         runEventUsage,
+		SessionID:    s.ID,
+		InputTokens:  s.PromptTokens,
+		OutputTokens: s.CompletionTokens,
+		Cost:         s.Cost,
+	})
+}
+
+func (e *jsonlEmitter) agentEvent(ev agent.AgentEvent) {
+	out := runEvent{
+		Type:      runEventAgent,
+		SessionID: ev.SessionID,
+		MessageID: ev.Message.ID,
+		Event:     string(ev.Type),
+		Progress:  ev.Progress,
+	}
+	if ev.Error != nil {
+		out.Error = ev.Error.Error()
+	}
+	e.emit(out)
+}
+
+// follow forwards the run's events until ctx is done. The returned function
+// stops following and waits for the forwarder to exit.
+func (e *jsonlEmitter) follow(ctx context.Context, a *app.App, runAgent agent.Service) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	msgCh := a.Messages.Subscribe(ctx)
+	sesCh := a.Sessions.Subscribe(ctx)
+	agentCh := runAgent.Subscribe(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-msgCh:
+				if !ok {
+					return
+				}
+				e.message(ctx, ev.Payload)
+			case ev, ok := <-sesCh:
+				if !ok {
+					return
+				}
+				e.usage(ctx, ev.Payload)
+			case ev, ok := <-agentCh:
+				if !ok {
+					return
+				}
+				e.agentEvent(ev.Payload)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// skipHistory marks the messages a resumed session already has as written,
+// so only what the run adds shows up.
+func (e *jsonlEmitter) skipHistory(ctx context.Context, a *app.App, sessionID string) {
+	msgs, err := a.Messages.List(ctx, sessionID)
+	if err != nil {
+		logging.Warn("Failed to list messages for event output", "session_id", sessionID, "error", err)
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, msg := range msgs {
+		e.emitted["message:"+msg.ID] = true
+		for _, call := range msg.ToolCalls() {
+			e.emitted["call:"+call.ID] = true
+		}
+		for _, result := range msg.ToolResults() {
+			e.emitted["result:"+result.ToolCallID] = true
+		}
+	}
+}
+
+// catchUp writes what the subscription missed: updates may be dropped when
+// a subscriber falls behind, so the session's stored messages and totals
+// are replayed once the run is over.
+func (e *jsonlEmitter) catchUp(ctx context.Context, a *app.App, sessionID string) {
+	msgs, err := a.Messages.List(ctx, sessionID)
+	if err != nil {
+		logging.Warn("Failed to list messages for event output", "session_id", sessionID, "error", err)
+		return
+	}
+	for _, msg := range msgs {
+		e.message(ctx, msg)
+	}
+	if s, err := a.Sessions.Get(ctx, sessionID); err == nil {
+		e.usage(ctx, s)
+	}
+}
+
+// finishJSONLRun stops following the run, writes what the subscription
+// missed and the closing result line. A cancelled run ends without an
+// error, as in the other formats.
+func finishJSONLRun(ctx context.Context, a *app.App, e *jsonlEmitter, stop func(), sessionID string, result agent.AgentEvent) error {
+	stop()
+	// A --timeout cancels ctx; the store is still readable.
+	ctx = context.WithoutCancel(ctx)
+	e.catchUp(ctx, a, sessionID)
+
+	out := runEvent{
+		Type:      runEventResult,
+		SessionID: sessionID,
+		MessageID: result.Message.ID,
+		Text:      result.Message.Content().String(),
+	}
+	if result.StructOutput != nil && json.Valid([]byte(result.StructOutput.Content)) {
+		out.StructOutput = json.RawMessage(result.StructOutput.Content)
+	}
+	if usage, err := a.Sessions.TreeUsage(ctx, sessionID); err == nil {
+		out.Cost = usage.Cost
+	}
+	var err error
+	if result.Error != nil {
+		out.Error = result.Error.Error()
+		if errors.Is(result.Error, context.Canceled) || errors.Is(result.Error, agent.ErrRequestCancelled) {
+			logging.Warn("Agent processing cancelled", "session_id", sessionID)
+		} else {
+			err = fmt.Errorf("agent processing failed for session %s: %w", sessionID, result.Error)
+		}
+	}
+	e.emit(out)
+	if err == nil {
+		logging.Info("Non-interactive run completed", "session_id", sessionID)
+	}
+	return err
+}
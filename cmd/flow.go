@@ -14,6 +14,7 @@ import (
 
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/flow"
 	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/logging"
@@ -74,16 +75,37 @@ func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFor
 
 	a.Permissions.AutoApproveSession(sess.ID)
 
+	runAgent := a.ActiveAgent()
+	var events *jsonlEmitter
+	var stopEvents func()
+	if outputFormat == format.JSONL {
+		events = newJSONLEmitter(os.Stdout, a.Sessions, sess)
+		events.skipHistory(ctx, a, sess.ID)
+		events.emit(runEvent{
+			Type:      runEventStart,
+			SessionID: sess.ID,
+			Agent:     string(runAgent.AgentID()),
+			Model:     string(runAgent.Model().ID),
+		})
+		stopEvents = events.follow(ctx, a, runAgent)
+	}
+
 	// Headless prompt invocation is non-interactive: hold the turn open
 	// until background tasks (bash run_in_background, task async, monitor)
 	// complete so the CLI's final output reflects the post-completion
 	// state. See openspec/specs/background-tasks.
-	done, err := a.ActiveAgent().RunWith(ctx, sess.ID, prompt, 0, agent.RunOptions{NonInteractive: true})
+	done, err := runAgent.RunWith(ctx, sess.ID, prompt, 0, agent.RunOptions{NonInteractive: true, Batch: true})
 	if err != nil {
+		if stopEvents != nil {
+			stopEvents()
+		}
 		return fmt.Errorf("failed to start agent processing stream for session %s: %w", sess.ID, err)
 	}
 
 	result := <-done
+	if events != nil {
+		return finishJSONLRun(ctx, a, events, stopEvents, sess.ID, result)
+	}
 	if result.Error != nil {
 		if errors.Is(result.Error, context.Canceled) || errors.Is(result.Error, agent.ErrRequestCancelled) {
 			logging.Warn("Agent processing cancelled", "session_id", sess.ID)
@@ -186,6 +208,12 @@ func runFlowNonInteractive(ctx context.Context, a *app.App, flowID, prompt, sess
 		if rootSessionID == "" {
 			rootSessionID = state.RootSessionID
 		}
+		if state.Status == flow.FlowStatusWaitingApproval {
+			// The run blocks here until a decision lands in flow_states;
+			// the gate polls, so approving from another process works.
+			fmt.Fprintf(os.Stderr, "Step %q is waiting for approval. Approve or reject it with POST /flow/approve {\"sessionID\": %q, \"approve\": true}\n",
+				state.StepID, state.SessionID)
+		}
 		var output any
 		if state.IsStructOutput && state.Output != "" {
 			var parsed map[string]any
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/flow"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+var flowsCmd = &cobra.Command{
+	Use:   "flows",
+	Short: "Run scheduled flows and inspect their history",
+	Long: `Flows whose spec sets a cron schedule are started by "opencode flows daemon".
+
+  flow:
+    schedule: "0 9 * * 1"        # minute hour day-of-month month day-of-week
+    scheduleArgs:
+      prompt: Summarise last week's merged PRs
+    steps: ...
+
+A flow never overlaps itself: a firing that comes due while the previous run
+is still in progress is recorded as skipped. Every firing is stored in the
+project database and listed by "opencode flows history".`,
+	Example: `
+  # Run scheduled flows until interrupted
+  opencode flows daemon
+
+  # Show the last runs of one flow
+  opencode flows history weekly-report`,
+}
+
+var flowsDaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Start flows on their cron schedules until interrupted",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		if cwd != "" {
+			if err := os.Chdir(cwd); err != nil {
+				return fmt.Errorf("failed to change directory: %w", err)
+			}
+		} else {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+
+		cfg, err := config.Load(cwd, debug)
+		if err != nil {
+			return err
+		}
+
+		level := slog.LevelInfo
+		if debug {
+			level = slog.LevelDebug
+		}
+		logging.SetupStderrLogging(level)
+
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		application, err := app.New(ctx, conn, nil, "")
+		if err != nil {
+			logging.Error("Failed to create app", "error", err)
+			return err
+		}
+		defer application.Shutdown()
+
+		scheduler := flow.NewScheduler(application.Flows, db.NewQuerier(conn), db.GetProjectID(cfg.WorkingDir))
+		if err := scheduler.Start(ctx); err != nil {
+			return err
+		}
+		defer scheduler.Stop()
+
+		scheduled := scheduler.Scheduled()
+		if len(scheduled) == 0 {
+			logging.Warn("No enabled flow declares a schedule yet; new or edited flow files are picked up while running")
+		}
+		for id, next := range scheduled {
+			logging.Info("Flow scheduled", "flow", id, "next", next.Format(time.DateTime))
+		}
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case sig := <-sigCh:
+			logging.Info("Flows daemon: received signal, shutting down", "signal", sig.String())
+		case <-ctx.Done():
+		}
+		return nil
+	},
+}
+
+var flowsHistoryCmd = &cobra.Command{
+	Use:   "history [flow-id]",
+	Short: "List scheduled flow runs, newest first",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		debug, _ := cmd.Flags().GetBool("debug")
+		limit, _ := cmd.Flags().GetInt("limit")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+		cfg, err := config.Load(cwd, debug)
+		if err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		flowID := ""
+		if len(args) == 1 {
+			flowID = args[0]
+		}
+		runs, err := flow.ScheduleHistory(context.Background(), db.NewQuerier(conn), db.GetProjectID(cfg.WorkingDir), flowID, limit)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(runs)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FLOW\tSCHEDULED\tSTATUS\tDURATION\tSESSION\tERROR")
+		for _, run := range runs {
+			duration := "-"
+			if run.FinishedAt > 0 {
+				duration = (time.Duration(run.FinishedAt-run.StartedAt) * time.Second).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				run.FlowID, time.Unix(run.ScheduledAt, 0).Format(time.DateTime), run.Status,
+				duration, run.SessionID, run.Error)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	flowsCmd.PersistentFlags().StringP("cwd", "c", "", "Working directory for the project")
+	flowsCmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug logging")
+
+	flowsHistoryCmd.Flags().Int("limit", flow.DefaultHistoryLimit, "Maximum number of runs to show")
+	flowsHistoryCmd.Flags().Bool("json", false, "Print runs as JSON")
+
+	flowsCmd.AddCommand(flowsDaemonCmd, flowsHistoryCmd)
+	rootCmd.AddCommand(flowsCmd)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	cterm "github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate the project's agent instructions file",
+	Long: `Have the explorer agent survey the repository and write the instructions
+file every agent reads at the start of a session: build and test commands,
+an architecture overview and the project's conventions.
+
+An existing AGENTS.md, opencode.md or CLAUDE.md is updated in place;
+otherwise AGENTS.md is created. A --file no contextPaths entry covers yet is
+added to contextPaths in the project's .opencode.json. The explorer only
+reads the repository, so nothing but those files is written. The result is
+then opened in $VISUAL or $EDITOR for review.
+
+The TUI's /init command runs the same survey.`,
+	Example: `
+  # Survey the current project and review the result
+  opencode init
+
+  # Write opencode.md without opening an editor
+  opencode init --file opencode.md --no-edit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		debug, _ := cmd.Flags().GetBool("debug")
+		file, _ := cmd.Flags().GetString("file")
+		noEdit, _ := cmd.Flags().GetBool("no-edit")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		timeoutStr, _ := cmd.Flags().GetString("timeout")
+
+		if cwd != "" {
+			if err := os.Chdir(cwd); err != nil {
+				return fmt.Errorf("failed to change directory: %w", err)
+			}
+		}
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+
+		if _, err := config.Load(cwd, debug); err != nil {
+			return err
+		}
+		level := slog.LevelInfo
+		if debug {
+			level = slog.LevelDebug
+		}
+		logging.SetupStderrLogging(level)
+
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if timeoutStr != "" {
+			timeout, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return fmt.Errorf("invalid --timeout value %q: %w (use formats like 10s, 30m, 1h)", timeoutStr, err)
+			}
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		application, err := app.New(ctx, conn, nil, "")
+		if err != nil {
+			logging.Error("Failed to create app", "error", err)
+			return err
+		}
+		defer application.Shutdown()
+
+		var spinner *format.Spinner
+		if !quiet {
+			spinner = format.NewSpinner("Surveying the repository...")
+			spinner.Start()
+		}
+		res, err := application.InitProject(ctx, file)
+		if spinner != nil {
+			spinner.Stop()
+		}
+		application.ForceShutdown()
+		if err != nil {
+			return err
+		}
+
+		if res.Created {
+			fmt.Printf("Created %s\n", res.File)
+		} else {
+			fmt.Printf("Updated %s\n", res.File)
+		}
+		if res.Registered {
+			fmt.Printf("Added %s to contextPaths in .opencode.json\n", res.File)
+		}
+
+		if noEdit || !cterm.IsTerminal(os.Stdin.Fd()) {
+			return nil
+		}
+		return openInEditor(res.Path)
+	},
+}
+
+// openInEditor opens path in $VISUAL or $EDITOR, which may carry arguments
+// (e.g. "code --wait"), and waits for it to exit.
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...) //nolint:gosec
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+func init() {
+	initCmd.Flags().StringP("cwd", "c", "", "Working directory for the project")
+	initCmd.Flags().BoolP("debug", "d", false, "Enable debug logging")
+	initCmd.Flags().String("file", "", "Instructions file to write, relative to the working directory (default: the existing one, else AGENTS.md)")
+	initCmd.Flags().Bool("no-edit", false, "Don't open the result in an editor")
+	initCmd.Flags().BoolP("quiet", "q", false, "Hide spinner")
+	initCmd.Flags().StringP("timeout", "t", "", "Give up after this long (e.g. 10m)")
+
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/memory"
+)
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "List, add and forget project memories",
+	Long: `Manage the facts agents remember about this project across sessions.
+
+Agents write memories with the memory_write tool and read them with
+memory_read. High-priority memories are put into the system prompt of every
+agent working in the project.`,
+	Example: `
+  # Show everything remembered about this project
+  opencode memory list
+
+  # Tell every future session how to run the tests
+  opencode memory add --key test-command --high "Run tests with make test, not go test"
+
+  # Forget a memory by key or ID
+  opencode memory forget test-command`,
+}
+
+var memoryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's memories, most recently updated first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query, _ := cmd.Flags().GetString("query")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		memories, closeDB, err := openMemories(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		list, err := memories.List(context.Background(), memory.Query{Contains: query})
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(list)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tKEY\tPRIORITY\tUPDATED\tCONTENT")
+		for _, m := range list {
+			key := m.Key
+			if key == "" {
+				key = "-"
+			}
+			content := strings.Join(strings.Fields(m.Content), " ")
+			if len(content) > 80 {
+				content = content[:77] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				m.ID, key, m.Priority, time.Unix(m.UpdatedAt, 0).Format(time.DateTime), content)
+		}
+		return w.Flush()
+	},
+}
+
+var memoryAddCmd = &cobra.Command{
+	Use:   "add <content>",
+	Short: "Remember a fact about the project",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, _ := cmd.Flags().GetString("key")
+		high, _ := cmd.Flags().GetBool("high")
+		priority := memory.PriorityNormal
+		if high {
+			priority = memory.PriorityHigh
+		}
+
+		memories, closeDB, err := openMemories(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		m, err := memories.Write(context.Background(), key, strings.Join(args, " "), priority)
+		if err != nil {
+			return err
+		}
+		fmt.Println(m.ID)
+		return nil
+	},
+}
+
+var memoryForgetCmd = &cobra.Command{
+	Use:   "forget <key-or-id>",
+	Short: "Delete a memory",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		memories, closeDB, err := openMemories(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		m, err := memories.Delete(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Forgot memory %s\n", m.ID)
+		return nil
+	},
+}
+
+// openMemories loads the project config and connects to its database
+// without bringing up agents.
+func openMemories(cmd *cobra.Command) (memory.Service, func(), error) {
+	cwd, _ := cmd.Flags().GetString("cwd")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		cwd = c
+	}
+	cfg, err := config.Load(cwd, debug)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return memory.NewService(db.NewQuerier(conn), db.GetProjectID(cfg.WorkingDir)), func() { _ = conn.Close() }, nil
+}
+
+func init() {
+	memoryCmd.PersistentFlags().StringP("cwd", "c", "", "Working directory for the project")
+	memoryCmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug logging")
+
+	memoryListCmd.Flags().StringP("query", "q", "", "Only memories whose key or content contains this text")
+	memoryListCmd.Flags().Bool("json", false, "Print memories as JSON")
+
+	memoryAddCmd.Flags().StringP("key", "k", "", "Stable key; adding an existing key replaces that memory")
+	memoryAddCmd.Flags().Bool("high", false, "Put the memory into every agent's system prompt")
+
+	memoryCmd.AddCommand(memoryListCmd, memoryAddCmd, memoryForgetCmd)
+	rootCmd.AddCommand(memoryCmd)
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Manage the model catalog",
+}
+
+var modelsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Refresh the model catalog from the configured providers",
+	Long: `Query the model-listing endpoint of every configured provider and cache
+the models opencode does not know yet in
+$XDG_CACHE_HOME/opencode/models.json (~/.cache/opencode/models.json).
+
+Cached models are registered as "<provider>.<api model>", e.g.
+openai.gpt-5.2, and appear in the model picker from the next start. Context
+windows and output limits come from the provider when it reports them;
+pricing and capabilities are taken from the closest built-in model of the
+same provider.
+
+OpenAI, Anthropic and Gemini can be synced. Local, Ollama and Copilot
+models are discovered on every start and need no sync.`,
+	Example: `
+  # Sync every configured provider
+  opencode models sync
+
+  # Sync only Anthropic
+  opencode models sync --provider anthropic`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		debug, _ := cmd.Flags().GetBool("debug")
+		only, _ := cmd.Flags().GetStringSlice("provider")
+
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+		cfg, err := config.Load(cwd, debug)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range only {
+			if !models.CanSync(models.ModelProvider(p)) {
+				return fmt.Errorf("provider %q does not support model sync", p)
+			}
+		}
+
+		creds := cfg.SyncCredentials()
+		if len(only) > 0 {
+			maps.DeleteFunc(creds, func(p models.ModelProvider, _ models.SyncCredentials) bool {
+				return !slices.Contains(only, string(p))
+			})
+		}
+		if len(creds) == 0 {
+			return fmt.Errorf("no configured provider supports model sync")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		results, err := models.SyncCatalog(ctx, creds)
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Printf("%s: %v\n", r.Provider, r.Err)
+				continue
+			}
+			fmt.Printf("%s: %d models listed, %d not built in\n", r.Provider, r.Listed, len(r.Added))
+			for _, m := range r.Added {
+				fmt.Printf("  %s\t%s\n", m.ID, m.Name)
+			}
+		}
+		return err
+	},
+}
+
+var modelsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check which configured models the account can use",
+	Long: `Query the model-listing endpoint of every configured provider and report
+whether it is reachable, how long it took to answer and which of its known
+models the account can use. Some organisations disable specific models;
+those are greyed out in the model picker with the reason shown here.
+
+The result is cached in $XDG_CACHE_HOME/opencode/availability.json. The
+TUI and server also check in the background every modelCheck.interval
+(12h by default).
+
+OpenAI, Anthropic and Gemini can be checked; models of other providers are
+listed as not checked.`,
+	Example: `
+  # Check every configured provider
+  opencode models check
+
+  # Show the last result without querying the providers
+  opencode models check --cached`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		debug, _ := cmd.Flags().GetBool("debug")
+		cached, _ := cmd.Flags().GetBool("cached")
+
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+		cfg, err := config.Load(cwd, debug)
+		if err != nil {
+			return err
+		}
+
+		report := models.CurrentAvailability()
+		if !cached {
+			creds := cfg.SyncCredentials()
+			if len(creds) == 0 {
+				return fmt.Errorf("no configured provider supports model checks")
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if report, err = models.CheckAvailability(ctx, creds); err != nil {
+				return err
+			}
+		}
+		printAvailability(cfg, report)
+		return nil
+	},
+}
+
+// printAvailability prints one row per enabled provider, then the
+// unavailable models with their reasons.
+func printAvailability(cfg *config.Config, report models.AvailabilityReport) {
+	var providers []models.ModelProvider
+	for p, pc := range cfg.Providers {
+		if !pc.Disabled {
+			providers = append(providers, p)
+		}
+	}
+	slices.Sort(providers)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tSTATUS\tLATENCY\tCHECKED\tMODELS")
+	var unavailable []string
+	for _, p := range providers {
+		h, checked := report.Providers[p]
+		if !checked || !models.CanCheckAvailability(p) {
+			fmt.Fprintf(w, "%s\t-\t-\t-\tnot checked\n", p)
+			continue
+		}
+		ok, bad := 0, 0
+		for id, m := range models.SupportedModels {
+			if m.Provider != p {
+				continue
+			}
+			a, known := report.Models[id]
+			switch {
+			case !known:
+			case a.Available:
+				ok++
+			default:
+				bad++
+				unavailable = append(unavailable, fmt.Sprintf("%s\t%s", id, a.Reason))
+			}
+		}
+		status, summary := "ok", fmt.Sprintf("%d available, %d unavailable", ok, bad)
+		if h.Error != "" {
+			status, summary = "error", h.Error
+		}
+		checkedAt := time.Unix(h.CheckedAt, 0).Format("2006-01-02 15:04")
+		fmt.Fprintf(w, "%s\t%s\t%dms\t%s\t%s\n", p, status, h.LatencyMS, checkedAt, summary)
+	}
+	w.Flush()
+
+	if len(unavailable) > 0 {
+		slices.Sort(unavailable)
+		fmt.Println("\nUnavailable models:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, line := range unavailable {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		w.Flush()
+	}
+}
+
+func init() {
+	modelsCmd.PersistentFlags().StringP("cwd", "c", "", "Working directory for the project")
+	modelsCmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug logging")
+	modelsSyncCmd.Flags().StringSlice("provider", nil, "Only sync these providers")
+
+	modelsCheckCmd.Flags().Bool("cached", false, "Show the last result without querying the providers")
+
+	modelsCmd.AddCommand(modelsSyncCmd, modelsCheckCmd)
+	rootCmd.AddCommand(modelsCmd)
+}
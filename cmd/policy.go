@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Sign and inspect the organisation policy",
+	Long: `Work with the organisation policy: a signed JSON document, fetched from a
+central URL at startup, whose deny rules, banned providers, budget ceilings
+and locked settings user and project configs cannot override.
+
+Machines find the policy through a policy source file installed by their
+admins (see docs/policy.md).`,
+	Example: `
+  # Sign a policy before publishing it next to policy.json.sig
+  opencode policy sign --key policy-key.pem policy.json > policy.json.sig
+
+  # Show the policy in force on this machine
+  opencode policy show`,
+}
+
+var policySignCmd = &cobra.Command{
+	Use:   "sign <policy.json>",
+	Short: "Print the signature of a policy document",
+	Long: `Check a policy document and print its base64 Ed25519 signature, to be
+published at the policy URL with ".sig" appended.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keyPath, _ := cmd.Flags().GetString("key")
+		body, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := config.ParsePolicy(body); err != nil {
+			return err
+		}
+		key, err := audit.LoadPrivateKey(keyPath)
+		if err != nil {
+			return err
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(ed25519.Sign(key, body)))
+		return nil
+	},
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the policy in force",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, path, err := config.ReadPolicySource()
+		if err != nil {
+			return err
+		}
+		if src == nil {
+			fmt.Printf("No policy source installed at %s and %s is not set.\n", config.SystemPolicySourcePath(), config.PolicySourceEnv)
+			return nil
+		}
+		cwd, _ := cmd.Flags().GetString("cwd")
+		if cwd == "" {
+			if cwd, err = os.Getwd(); err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+		}
+		cfg, err := config.Load(cwd, false)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Source: %s\nURL:    %s\n", path, src.URL)
+		if cfg.Policy == nil {
+			fmt.Println("The policy could not be fetched and no verified copy is cached.")
+			return nil
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cfg.Policy)
+	},
+}
+
+func init() {
+	policySignCmd.Flags().String("key", "", "PEM PKCS#8 Ed25519 private key to sign with")
+	_ = policySignCmd.MarkFlagRequired("key")
+	policyShowCmd.Flags().StringP("cwd", "c", "", "Working directory for the project")
+	policyCmd.AddCommand(policySignCmd, policyShowCmd)
+	rootCmd.AddCommand(policyCmd)
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/runqueue"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Submit, list and cancel queued headless runs",
+	Long: `Manage the persistent run queue executed by "opencode serve".
+
+Queued runs are stored in the project database, so they survive server
+restarts and can be submitted from any machine that shares the database.
+The server starts them highest priority first, oldest first, within the
+limits of the runQueue config block.`,
+	Example: `
+  # Queue a prompt for the coder agent
+  opencode queue submit -p "Fix the flaky test in ./internal/api"
+
+  # Queue a flow ahead of everything with a lower priority
+  opencode queue submit --flow triage --arg issue=42 --priority 10
+
+  # Show queued, running and recent runs, then cancel one
+  opencode queue list
+  opencode queue cancel 3f2a...`,
+}
+
+var queueSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Queue a prompt or flow run",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompt, _ := cmd.Flags().GetString("prompt")
+		flowID, _ := cmd.Flags().GetString("flow")
+		agentName, _ := cmd.Flags().GetString("agent")
+		argPairs, _ := cmd.Flags().GetStringArray("arg")
+		fresh, _ := cmd.Flags().GetBool("fresh")
+		priority, _ := cmd.Flags().GetInt64("priority")
+		provider, _ := cmd.Flags().GetString("provider")
+		project, _ := cmd.Flags().GetString("project")
+
+		params := runqueue.SubmitParams{
+			Kind:     runqueue.KindPrompt,
+			Target:   agentName,
+			Payload:  runqueue.Payload{Prompt: prompt},
+			Priority: priority,
+			Provider: provider,
+			Project:  project,
+		}
+		if flowID != "" {
+			if agentName != "" {
+				return fmt.Errorf("--agent cannot be combined with --flow")
+			}
+			flowArgs := map[string]any{}
+			if prompt != "" {
+				flowArgs["prompt"] = prompt
+			}
+			for _, pair := range argPairs {
+				k, v, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("invalid --arg format %q, expected key=value", pair)
+				}
+				flowArgs[k] = v
+			}
+			params.Kind = runqueue.KindFlow
+			params.Target = flowID
+			params.Payload = runqueue.Payload{Args: flowArgs, Fresh: fresh}
+		} else if len(argPairs) > 0 || fresh {
+			return fmt.Errorf("--arg and --fresh require --flow")
+		}
+
+		queue, closeDB, err := openRunQueue(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		run, err := queue.Submit(context.Background(), params)
+		if err != nil {
+			return err
+		}
+		fmt.Println(run.ID)
+		return nil
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued, running and recent runs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		queue, closeDB, err := openRunQueue(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		runs, err := queue.List(context.Background(), limit)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(runs)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTATUS\tPRIORITY\tKIND\tTARGET\tSUBMITTED\tSESSION")
+		for _, run := range runs {
+			target := run.Target
+			if target == "" {
+				target = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+				run.ID, run.Status, run.Priority, run.Kind, target,
+				time.Unix(run.CreatedAt, 0).Format(time.DateTime), run.SessionID)
+		}
+		return w.Flush()
+	},
+}
+
+var queueCancelCmd = &cobra.Command{
+	Use:   "cancel <run-id>",
+	Short: "Cancel a queued or running run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queue, closeDB, err := openRunQueue(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		if _, err := queue.Cancel(context.Background(), args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Cancelled run %s\n", args[0])
+		return nil
+	},
+}
+
+// openRunQueue loads the project config and connects to its database
+// without bringing up agents. Runs submitted here are executed by the
+// `opencode serve` process sharing that database.
+func openRunQueue(cmd *cobra.Command) (runqueue.Service, func(), error) {
+	cwd, _ := cmd.Flags().GetString("cwd")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		cwd = c
+	}
+	cfg, err := config.Load(cwd, debug)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return runqueue.NewService(db.NewQuerier(conn), db.GetProjectID(cfg.WorkingDir)), func() { _ = conn.Close() }, nil
+}
+
+func init() {
+	queueCmd.PersistentFlags().StringP("cwd", "c", "", "Working directory for the project")
+	queueCmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug logging")
+
+	queueSubmitCmd.Flags().StringP("prompt", "p", "", "Prompt to run (passed as the prompt arg of a flow)")
+	queueSubmitCmd.Flags().StringP("flow", "F", "", "Flow ID to run instead of a plain prompt")
+	queueSubmitCmd.Flags().String("agent", "", "Primary agent for prompt runs (default: the active agent)")
+	queueSubmitCmd.Flags().StringArrayP("arg", "A", nil, "Flow argument as key=value (repeatable)")
+	queueSubmitCmd.Flags().Bool("fresh", false, "Discard previous flow state before running")
+	queueSubmitCmd.Flags().Int64("priority", 0, "Higher priorities start first")
+	queueSubmitCmd.Flags().String("provider", "", "Provider limit bucket (default: the agent's provider)")
+	queueSubmitCmd.Flags().String("project", "", "Project limit bucket (default: this project)")
+
+	queueListCmd.Flags().Int("limit", runqueue.DefaultListLimit, "Maximum number of runs to show")
+	queueListCmd.Flags().Bool("json", false, "Print runs as JSON")
+
+	queueCmd.AddCommand(queueSubmitCmd, queueListCmd, queueCancelCmd)
+	rootCmd.AddCommand(queueCmd)
+}
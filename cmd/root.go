@@ -14,6 +14,8 @@ import (
 	"github.com/opencode-ai/opencode/internal/flow"
 	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/langfuse"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/tui"
@@ -91,6 +93,8 @@ to assist developers in writing, debugging, and understanding code directly from
 		projectID, _ := cmd.Flags().GetString("project-id")
 		maxTurns, _ := cmd.Flags().GetInt("max-turns")
 		autoApprove, _ := cmd.Flags().GetBool("auto-approve")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		profile, _ := cmd.Flags().GetString("profile")
 
 		if deleteSession && sessionID == "" && flowID == "" {
 			return fmt.Errorf("--delete requires --session/-s or --flow/-F to be specified")
@@ -122,6 +126,9 @@ to assist developers in writing, debugging, and understanding code directly from
 			spinner.Start()
 		}
 
+		if cmd.Flags().Changed("profile") {
+			config.SetProfile(profile)
+		}
 		cfg, err := config.Load(cwd, debug)
 		if err != nil {
 			if spinner != nil {
@@ -150,6 +157,9 @@ to assist developers in writing, debugging, and understanding code directly from
 		if maxTurns > 0 {
 			config.Get().MaxTurns = maxTurns
 		}
+		if readOnly {
+			cfg.ReadOnly = true
+		}
 
 		// Connect DB, this will also run migrations
 		conn, err := db.Connect()
@@ -260,6 +270,9 @@ to assist developers in writing, debugging, and understanding code directly from
 		}
 
 		// Interactive mode
+		app.StartModelChecks(ctx)
+		app.StartCodeIndex(ctx)
+
 		// Set up the TUI
 		program := tea.NewProgram(
 			tui.New(app),
@@ -454,10 +467,19 @@ func setupSubscriptions(app *app.App, parentCtx context.Context) (chan tea.Msg,
 	setupSubscriber(ctx, &wg, "messages", app.Messages.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "mcp", app.MCPRegistry.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "lsp", app.LspService.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "bash-output", tools.SubscribeBashOutput, ch)
+	setupSubscriber(ctx, &wg, "usage", agent.SubscribeUsage, ch)
+	setupSubscriber(ctx, &wg, "subagents", agent.SubscribeSubagents, ch)
 	setupBlockingSubscriber(ctx, &wg, "permissions", app.Permissions.Subscribe, permCh)
 	if app.Questions != nil {
 		setupBlockingSubscriber(ctx, &wg, "questions", app.Questions.Subscribe, permCh)
 	}
+	if app.Flows != nil {
+		setupSubscriber(ctx, &wg, "flows", app.Flows.Subscribe, ch)
+	}
+	if app.Todos != nil {
+		setupSubscriber(ctx, &wg, "todos", app.Todos.Subscribe, ch)
+	}
 	if app.Crons != nil {
 		setupSubscriber(ctx, &wg, "cron-jobs", app.Crons.Subscribe, ch)
 		setupSubscriber(ctx, &wg, "cron-missed", app.Crons.SubscribeMissed, ch)
@@ -510,7 +532,7 @@ func init() {
 
 	// Add format flag with validation logic
 	rootCmd.Flags().StringP("output-format", "f", format.Text.String(),
-		"Output format for non-interactive mode (text, json, json_schema='{...}' or json_schema=/path/to/schema.json)")
+		"Output format for non-interactive mode (text, json, jsonl, json_schema='{...}' or json_schema=/path/to/schema.json)")
 
 	// Add quiet flag to hide spinner in non-interactive mode
 	rootCmd.Flags().BoolP("quiet", "q", false, "Hide spinner in non-interactive mode")
@@ -532,6 +554,12 @@ func init() {
 	// Add auto-approve flag
 	rootCmd.Flags().Bool("auto-approve", false, "Start with auto-approve enabled (skip permission dialogs for ask rules)")
 
+	// Add read-only flag
+	rootCmd.Flags().Bool("read-only", false, "Disable every tool that changes files and only allow read-only bash commands")
+
+	// Add profile flag
+	rootCmd.Flags().String("profile", "", "Configuration profile to use (overrides the \"profile\" config key; empty for none)")
+
 	// Register flag completion functions
 	rootCmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return format.SupportedFormats, cobra.ShellCompDirectiveNoFileComp
@@ -2,31 +2,56 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
 	"sort"
+	"strings"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/hooks"
 	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"gopkg.in/yaml.v3"
 )
 
-// JSONSchemaType represents a JSON Schema type
-type JSONSchemaType struct {
-	Type                 string           `json:"type,omitempty"`
-	Description          string           `json:"description,omitempty"`
-	Properties           map[string]any   `json:"properties,omitempty"`
-	Required             []string         `json:"required,omitempty"`
-	AdditionalProperties any              `json:"additionalProperties,omitempty"`
-	Enum                 []any            `json:"enum,omitempty"`
-	Items                map[string]any   `json:"items,omitempty"`
-	OneOf                []map[string]any `json:"oneOf,omitempty"`
-	AnyOf                []map[string]any `json:"anyOf,omitempty"`
-	Default              any              `json:"default,omitempty"`
-}
+// yamlHeader tells YAML editors how to use the schema. yaml-language-server
+// (VS Code's YAML extension, Neovim, Helix, ...) reads the modeline from the
+// config file itself.
+const yamlHeader = `# OpenCode configuration schema for .opencode.yaml and .opencode.yml.
+# Reference it from the first line of the config file:
+#
+#   # yaml-language-server: $schema=./opencode-schema.yaml
+#
+`
+
+// modulePath prefixes the package paths whose sources are read for field
+// doc comments.
+const modulePath = "github.com/opencode-ai/opencode"
 
 func main() {
+	asYAML := flag.Bool("yaml", false, "emit the schema as YAML, with a header for YAML editors")
+	flag.Parse()
+
 	schema := generateSchema()
 
+	if *asYAML {
+		fmt.Print(yamlHeader)
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(schema); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding schema: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Pretty print the schema
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
@@ -36,803 +61,659 @@ func main() {
 	}
 }
 
+// generateSchema derives the schema from config.Config: every field with a
+// JSON name becomes a property of the matching type, and annotations add
+// descriptions, defaults and constraints on top.
 func generateSchema() map[string]any {
-	schema := map[string]any{
-		"$schema":     "http://json-schema.org/draft-07/schema#",
-		"title":       "OpenCode Configuration",
-		"description": "Configuration schema for the OpenCode application",
-		"type":        "object",
-		"properties":  map[string]any{},
-	}
-
-	// Add Data configuration
-	schema["properties"].(map[string]any)["data"] = map[string]any{
-		"type":        "object",
-		"description": "Storage configuration",
-		"properties": map[string]any{
-			"directory": map[string]any{
-				"type":        "string",
-				"description": "Directory where application data is stored",
-				"default":     ".opencode",
-			},
-		},
-		"required": []string{"directory"},
-	}
-
-	// Add working directory
-	schema["properties"].(map[string]any)["wd"] = map[string]any{
-		"type":        "string",
-		"description": "Working directory for the application",
-	}
-
-	// Add debug flags
-	schema["properties"].(map[string]any)["debug"] = map[string]any{
-		"type":        "boolean",
-		"description": "Enable debug mode",
-		"default":     false,
-	}
-
-	schema["properties"].(map[string]any)["debugLSP"] = map[string]any{
-		"type":        "boolean",
-		"description": "Enable LSP debug mode",
-		"default":     false,
-	}
-
-	schema["properties"].(map[string]any)["contextPaths"] = map[string]any{
-		"type":        "array",
-		"description": "Context paths for the application",
-		"items": map[string]any{
-			"type": "string",
-		},
-		"default": []string{
-			".github/copilot-instructions.md",
-			".cursorrules",
-			".cursor/rules/",
-			"CLAUDE.md",
-			"CLAUDE.local.md",
-			"opencode.md",
-			"opencode.local.md",
-			"OpenCode.md",
-			"OpenCode.local.md",
-			"OPENCODE.md",
-			"OPENCODE.local.md",
-			"AGENTS.md",
-			"AGENTS.local.md",
-		},
-	}
+	return newGenerator().generate()
+}
 
-	schema["properties"].(map[string]any)["agentPaths"] = map[string]any{
-		"type":        "array",
-		"description": "Custom directories to scan for markdown agent definitions (*.md) at startup. Supports ~ for the home directory and relative paths (resolved against the working directory). Custom-path agents have the lowest precedence among discovery sources.",
-		"items": map[string]any{
-			"type": "string",
-		},
-	}
+func (g *generator) generate() map[string]any {
+	schema := g.schemaOf(reflect.TypeOf(config.Config{}), "")
+	// Config files may carry keys of their own, "$schema" first of all.
+	delete(schema, "additionalProperties")
 
-	schema["properties"].(map[string]any)["flowPaths"] = map[string]any{
-		"type":        "array",
-		"description": "Custom directories to scan for flow YAML definitions (*.yaml / *.yml) at startup. Supports ~ for the home directory and relative paths (resolved against the working directory). Flows discovered here get a namespaced ID <parent-dir-basename>/<file-basename> and can never shadow a built-in (slash-free) flow ID.",
-		"items": map[string]any{
-			"type": "string",
-		},
-	}
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "OpenCode Configuration"
+	schema["definitions"] = g.definitions
+	return schema
+}
 
-	schema["properties"].(map[string]any)["tui"] = map[string]any{
-		"type":        "object",
-		"description": "Terminal User Interface configuration",
-		"properties": map[string]any{
-			"theme": map[string]any{
-				"type":        "string",
-				"description": "TUI theme name",
-				"default":     "opencode",
-				"enum": []string{
-					"opencode",
-					"catppuccin",
-					"dracula",
-					"flexoki",
-					"gruvbox",
-					"monokai",
-					"onedark",
-					"tokyonight",
-					"tron",
-				},
-			},
-			"vimMode": map[string]any{
-				"type":        "boolean",
-				"description": "Enable vim-style keybindings for the chat text input",
-				"default":     false,
-			},
-		},
-	}
+// definition emits a type once under #/definitions and refers to it from
+// everywhere else it appears. Path is where the annotations of the type
+// live.
+type definition struct {
+	name string
+	path string
+}
 
-	// Add MCP servers
-	schema["properties"].(map[string]any)["mcpServers"] = map[string]any{
-		"type":        "object",
-		"description": "Model Control Protocol server configurations",
-		"additionalProperties": map[string]any{
-			"type":        "object",
-			"description": "MCP server configuration",
-			"properties": map[string]any{
-				"command": map[string]any{
-					"type":        "string",
-					"description": "Command to execute for the MCP server",
-				},
-				"env": map[string]any{
-					"type":        "array",
-					"description": "Environment variables for the MCP server",
-					"items": map[string]any{
-						"type": "string",
-					},
-				},
-				"args": map[string]any{
-					"type":        "array",
-					"description": "Command arguments for the MCP server",
-					"items": map[string]any{
-						"type": "string",
-					},
-				},
-				"type": map[string]any{
-					"type":        "string",
-					"description": "Type of MCP server",
-					"enum":        []string{"stdio", "sse"},
-					"default":     "stdio",
-				},
-				"url": map[string]any{
-					"type":        "string",
-					"description": "URL for SSE type MCP servers",
-				},
-				"headers": map[string]any{
-					"type":        "object",
-					"description": "HTTP headers for SSE type MCP servers",
-					"additionalProperties": map[string]any{
-						"type": "string",
-					},
-				},
-				"disabled": map[string]any{
-					"type":        "boolean",
-					"description": "Whether the MCP server is disabled",
-					"default":     false,
-				},
-				"callToolTimeoutSeconds": map[string]any{
-					"type":        "integer",
-					"description": "Per-tool-call timeout override in seconds. Zero or omitted falls back to the built-in default (5 minutes).",
-					"minimum":     0,
-				},
-			},
-			"required": []string{"command"},
-		},
-	}
+// definitions are the types used in more than one place: profiles repeat
+// the providers, agents and permissions of the config.
+var definitions = map[reflect.Type]definition{
+	reflect.TypeOf(config.Agent{}):            {name: "agent", path: "agents.*"},
+	reflect.TypeOf(config.Provider{}):         {name: "provider", path: "providers.*"},
+	reflect.TypeOf(config.PermissionConfig{}): {name: "permission", path: "permission"},
+	reflect.TypeOf(hooks.MatcherGroup{}):      {name: "hookMatcherGroup", path: "hooks.*[]"},
+}
 
-	// Add providers
-	providerSchema := map[string]any{
-		"type":        "object",
-		"description": "LLM provider configurations",
-		"additionalProperties": map[string]any{
-			"type":        "object",
-			"description": "Provider configuration",
-			"properties": map[string]any{
-				"apiKey": map[string]any{
-					"type":        "string",
-					"description": "API key for the provider",
-				},
-				"disabled": map[string]any{
-					"type":        "boolean",
-					"description": "Whether the provider is disabled",
-					"default":     false,
-				},
-				"baseURL": map[string]any{
-					"type":        "string",
-					"description": "Base URL for the provider instead of default one",
-				},
-				"headers": map[string]any{
-					"type":        "object",
-					"description": "Extra headers to attach to request",
-					"additionalProperties": map[string]any{
-						"type": "string",
-					},
-				},
-				"metadata": map[string]any{
-					"type":        "object",
-					"description": "Metadata key-value pairs attached to every LLM API request body. Keys are built-in identifiers (sessionId, userId, tags) that OpenCode resolves at runtime. Values are the field names used in the metadata object sent to the API.",
-					"properties": map[string]any{
-						"sessionId": map[string]any{
-							"type":        "string",
-							"description": "Field name for the session ID in the metadata object. The value is resolved from the current session context.",
-						},
-						"userId": map[string]any{
-							"type":        "string",
-							"description": "Field name for the user ID in the metadata object. The value is read from OPENCODE_USER_ID env var, telemetry.userId config, or auto-generated as UUID at startup.",
-						},
-						"tags": map[string]any{
-							"type":        "string",
-							"description": "Field name for the tags array in the metadata object. Tags are resolved from telemetry.tags config and can be extended dynamically at runtime.",
-						},
-					},
-					"additionalProperties": false,
-				},
-			},
-		},
-	}
+// knownKeys are the map keys opencode gives a meaning to, listed so editors
+// can complete them. Other keys stay valid.
+var knownKeys = map[string][]string{
+	"agents": {
+		config.AgentCoder,
+		config.AgentExplorer,
+		config.AgentDescriptor,
+		config.AgentSummarizer,
+		config.AgentWorkhorse,
+		config.AgentHivemind,
+		config.AgentTranslator,
+	},
+	"hooks": {hooks.EventPreToolUse, hooks.EventPostToolUse, hooks.EventSessionStart, hooks.EventSessionEnd},
+}
 
-	// Add known providers
-	knownProviders := []string{
-		string(models.ProviderAnthropic),
-		string(models.ProviderOpenAI),
-		string(models.ProviderGemini),
-		string(models.ProviderBedrock),
-		string(models.ProviderVertexAI),
-		string(models.ProviderYandexCloud),
-		string(models.ProviderKimi),
-	}
+// typeSchemas replace the derived schema of types whose JSON form differs
+// from their Go shape, or that only take a known set of values. A map keyed
+// by a type with an enum gets one property per value.
+var typeSchemas = map[reflect.Type]func() map[string]any{
+	reflect.TypeOf(config.ContextPath{}): contextPathSchema,
+	reflect.TypeOf(models.ModelID("")): func() map[string]any {
+		return map[string]any{"type": "string", "enum": modelEnum()}
+	},
+	reflect.TypeOf(config.MCPType("")): func() map[string]any {
+		return stringEnum(config.MCPStdio, config.MCPSse, config.MCPHttp, config.MCPStreamableHttp, config.MCPWebSocket)
+	},
+	reflect.TypeOf(config.AgentMode("")): func() map[string]any {
+		return stringEnum(config.AgentModeAgent, config.AgentModeSubagent)
+	},
+	reflect.TypeOf(config.ProviderType("")): func() map[string]any {
+		return stringEnum(config.ProviderSQLite, config.ProviderMySQL)
+	},
+	reflect.TypeOf(config.RouteClass("")): func() map[string]any {
+		return stringEnum(config.RouteClasses...)
+	},
+}
 
-	providerSchema["additionalProperties"].(map[string]any)["properties"].(map[string]any)["provider"] = map[string]any{
-		"type":        "string",
-		"description": "Provider type",
-		"enum":        knownProviders,
+func stringEnum[T ~string](values ...T) map[string]any {
+	enum := make([]string, len(values))
+	for i, v := range values {
+		enum[i] = string(v)
 	}
+	return map[string]any{"type": "string", "enum": enum}
+}
 
-	schema["properties"].(map[string]any)["providers"] = providerSchema
-
-	// Add agents
-	agentSchema := map[string]any{
-		"type":        "object",
-		"description": "Agent configurations",
-		"additionalProperties": map[string]any{
-			"type":        "object",
-			"description": "Agent configuration",
-			"properties": map[string]any{
-				"model": map[string]any{
-					"type":        "string",
-					"description": "Model ID for the agent",
-				},
-				"maxTokens": map[string]any{
-					"type":        "integer",
-					"description": "Maximum tokens for the agent",
-					"minimum":     1,
-				},
-				"reasoningEffort": map[string]any{
-					"type":        "string",
-					"description": "Reasoning effort for models that support it (OpenAI, Anthropic). 'max' is only available for models with maximum thinking support.",
-					"enum":        []string{"low", "medium", "high", "max"},
-				},
-				"mode": map[string]any{
-					"type":        "string",
-					"description": "Agent mode: 'agent' for primary agents, 'subagent' for agents invoked by task tool",
-					"enum":        []string{"agent", "subagent"},
-				},
-				"name": map[string]any{
-					"type":        "string",
-					"description": "Display name for the agent",
-				},
-				"description": map[string]any{
-					"type":        "string",
-					"description": "Description of the agent's purpose",
-				},
-				"prompt": map[string]any{
-					"type":        "string",
-					"description": "Custom system prompt for the agent",
-				},
-				"color": map[string]any{
-					"type":        "string",
-					"description": "Badge color for subagent display (e.g., 'blue', 'orange', 'primary', 'warning')",
-				},
-				"hidden": map[string]any{
-					"type":        "boolean",
-					"description": "Whether the agent is hidden from TUI agent switching",
-					"default":     false,
-				},
-				"disabled": map[string]any{
-					"type":        "boolean",
-					"description": "Whether the agent is disabled and excluded from the registry entirely",
-					"default":     false,
-				},
-				"permission": map[string]any{
-					"type":        "object",
-					"description": "Agent-specific permission overrides. Keys are tool names (e.g., 'bash', 'edit', 'skill'), values are either a simple action string or an object with glob-pattern keys",
-					"additionalProperties": map[string]any{
-						"anyOf": []map[string]any{
-							{
-								"type":        "string",
-								"description": "Simple permission action",
-								"enum":        []string{"allow", "deny", "ask"},
-							},
-							{
-								"type":        "object",
-								"description": "Granular permission patterns (glob-pattern keys to action values)",
-								"additionalProperties": map[string]any{
-									"type": "string",
-									"enum": []string{"allow", "deny", "ask"},
-								},
-							},
-						},
-					},
-				},
-				"maxTurns": map[string]any{
-					"type":        "integer",
-					"description": "Maximum number of tool-use turns per request for this agent. Default is 100.",
-					"minimum":     1,
-				},
-				"tools": map[string]any{
-					"type":        "object",
-					"description": "Tool enable/disable configuration",
-					"additionalProperties": map[string]any{
-						"type":        "boolean",
-						"description": "Whether the tool is enabled for this agent",
-					},
-				},
-				"parallelToolUse": map[string]any{
-					"type":        "boolean",
-					"description": "Whether to enable parallel tool execution for this agent. When true (default), independent tool calls run concurrently. Set to false to force sequential execution.",
-					"default":     true,
-				},
-				"skills": map[string]any{
-					"type":        "array",
-					"description": "List of skill names to preload into the agent's system prompt at startup. Skills are injected as <skill_content> blocks. Only skills not explicitly denied by permissions are injected. Variable substitution and shell markup are not expanded for preloaded skills.",
-					"items": map[string]any{
-						"type": "string",
-					},
-				},
-				"taskBudget": map[string]any{
-					"type":        "integer",
-					"description": "Advisory token budget for the full agentic loop (minimum 20000). Only supported by models with SupportsTaskBudget. The budget is carried across compaction via the remaining field.",
-					"minimum":     20000,
-				},
-			},
-			"required": []string{"model"},
-		},
+// modelEnum lists the built-in models. Local, Ollama, Copilot and synced
+// models depend on the machine generating the schema and are left out.
+func modelEnum() []string {
+	synced := map[models.ModelID]bool{}
+	if catalog, err := models.LoadCatalog(); err == nil {
+		for _, m := range catalog.Models {
+			synced[m.ID] = true
+		}
 	}
-
-	// Add model enum
-	modelEnum := []string{}
+	enum := []string{}
 	for modelID, info := range models.SupportedModels {
-		if info.Provider != models.ProviderLocal {
-			modelEnum = append(modelEnum, string(modelID))
+		if synced[modelID] {
+			continue
+		}
+		if info.Provider != models.ProviderLocal && info.Provider != models.ProviderOllama && info.Provider != models.ProviderCopilot {
+			enum = append(enum, string(modelID))
 		}
 	}
-	sort.Slice(modelEnum, func(i, j int) bool {
-		mi := models.SupportedModels[models.ModelID(modelEnum[i])]
-		mj := models.SupportedModels[models.ModelID(modelEnum[j])]
+	sort.Slice(enum, func(i, j int) bool {
+		mi := models.SupportedModels[models.ModelID(enum[i])]
+		mj := models.SupportedModels[models.ModelID(enum[j])]
 		if mi.Provider != mj.Provider {
 			return mi.Provider < mj.Provider
 		}
 		return mi.Name < mj.Name
 	})
-	agentSchema["additionalProperties"].(map[string]any)["properties"].(map[string]any)["model"].(map[string]any)["enum"] = modelEnum
+	return enum
+}
 
-	// Add specific agent properties
-	agentProperties := map[string]any{}
-	knownAgents := []string{
-		string(config.AgentCoder),
-		string(config.AgentExplorer),
-		string(config.AgentDescriptor),
-		string(config.AgentSummarizer),
-		string(config.AgentWorkhorse),
-		string(config.AgentHivemind),
+type generator struct {
+	definitions map[string]any
+	// used records the annotation paths that matched a field.
+	used map[string]bool
+	// docs holds the field doc comments by "pkgpath.Type.Field", read from
+	// the module sources one package at a time.
+	docs   map[string]string
+	parsed map[string]bool
+	root   string
+}
+
+func newGenerator() *generator {
+	root, _ := os.Getwd()
+	// Read the sources next to this file, wherever the generator runs from.
+	if _, file, _, ok := runtime.Caller(0); ok {
+		root = filepath.Join(filepath.Dir(file), "..", "..")
+	}
+	return &generator{
+		definitions: map[string]any{},
+		used:        map[string]bool{},
+		docs:        map[string]string{},
+		parsed:      map[string]bool{},
+		root:        root,
 	}
+}
 
-	for _, agentName := range knownAgents {
-		agentProperties[agentName] = map[string]any{
-			"$ref": "#/definitions/agent",
+// schemaOf returns the schema of t at path, a dotted JSON path where "*"
+// stands for any map key and "[]" for an array item.
+func (g *generator) schemaOf(t reflect.Type, path string) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if def, ok := definitions[t]; ok {
+		if _, done := g.definitions[def.name]; !done {
+			g.definitions[def.name] = map[string]any{} // placeholder against recursion
+			g.definitions[def.name] = g.derive(t, def.path)
 		}
+		return map[string]any{"$ref": "#/definitions/" + def.name}
 	}
+	return g.derive(t, path)
+}
 
-	// Create a combined schema that allows both specific agents and additional ones
-	combinedAgentSchema := map[string]any{
-		"type":                 "object",
-		"description":          "Agent configurations",
-		"properties":           agentProperties,
-		"additionalProperties": agentSchema["additionalProperties"],
+func (g *generator) derive(t reflect.Type, path string) map[string]any {
+	var s map[string]any
+	if hook, ok := typeSchemas[t]; ok {
+		s = hook()
+	} else {
+		switch t.Kind() {
+		case reflect.Bool:
+			s = map[string]any{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = map[string]any{"type": "integer"}
+		case reflect.Float32, reflect.Float64:
+			s = map[string]any{"type": "number"}
+		case reflect.String:
+			s = map[string]any{"type": "string"}
+		case reflect.Slice, reflect.Array:
+			s = map[string]any{"type": "array", "items": g.schemaOf(t.Elem(), path+"[]")}
+		case reflect.Map:
+			s = g.mapSchema(t, path)
+		case reflect.Struct:
+			s = g.structSchema(t, path)
+		default:
+			// An interface holds any JSON value.
+			s = map[string]any{}
+		}
 	}
+	g.annotate(s, path)
+	return s
+}
 
-	schema["properties"].(map[string]any)["agents"] = combinedAgentSchema
-	schema["definitions"] = map[string]any{
-		"agent": agentSchema["additionalProperties"],
+func (g *generator) mapSchema(t reflect.Type, path string) map[string]any {
+	if hook, ok := typeSchemas[t.Key()]; ok {
+		if keys, ok := hook()["enum"].([]string); ok {
+			props := map[string]any{}
+			for _, key := range keys {
+				props[key] = g.schemaOf(t.Elem(), joinPath(path, key))
+			}
+			return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+		}
 	}
-
-	// Add LSP configuration
-	schema["properties"].(map[string]any)["lsp"] = map[string]any{
-		"type":        "object",
-		"description": "Language Server Protocol configurations. Built-in servers are auto-detected; use this to override, disable, or add custom servers.",
-		"additionalProperties": map[string]any{
-			"type":        "object",
-			"description": "LSP configuration for a language server",
-			"properties": map[string]any{
-				"disabled": map[string]any{
-					"type":        "boolean",
-					"description": "Whether the LSP server is disabled",
-					"default":     false,
-				},
-				"command": map[string]any{
-					"type":        "string",
-					"description": "Command to execute for the LSP server",
-				},
-				"args": map[string]any{
-					"type":        "array",
-					"description": "Command arguments for the LSP server",
-					"items": map[string]any{
-						"type": "string",
-					},
-				},
-				"extensions": map[string]any{
-					"type":        "array",
-					"description": "File extensions this LSP server should handle (e.g., [\".go\", \".mod\"])",
-					"items": map[string]any{
-						"type": "string",
-					},
-				},
-				"env": map[string]any{
-					"type":        "object",
-					"description": "Environment variables to set when starting the LSP server",
-					"additionalProperties": map[string]any{
-						"type": "string",
-					},
-				},
-				"initialization": map[string]any{
-					"type":        "object",
-					"description": "Initialization options sent to the LSP server during the initialize request. Options vary by server.",
-				},
-			},
-		},
+	s := map[string]any{"type": "object", "additionalProperties": g.schemaOf(t.Elem(), joinPath(path, "*"))}
+	if keys, ok := knownKeys[path]; ok {
+		props := map[string]any{}
+		for _, key := range keys {
+			props[key] = g.schemaOf(t.Elem(), joinPath(path, key))
+		}
+		s["properties"] = props
 	}
+	return s
+}
 
-	// Add disableLSPDownload flag
-	schema["properties"].(map[string]any)["disableLSPDownload"] = map[string]any{
-		"type":        "boolean",
-		"description": "Disable automatic downloading and installation of LSP servers. Can also be set via OPENCODE_DISABLE_LSP_DOWNLOAD environment variable.",
-		"default":     false,
-	}
+func (g *generator) structSchema(t reflect.Type, path string) map[string]any {
+	props := map[string]any{}
+	g.addFields(props, t, path)
+	return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+}
 
-	// Add shell configuration
-	schema["properties"].(map[string]any)["shell"] = map[string]any{
-		"type":        "object",
-		"description": "Shell configuration for the bash tool",
-		"properties": map[string]any{
-			"path": map[string]any{
-				"type":        "string",
-				"description": "Path to the shell executable",
-			},
-			"args": map[string]any{
-				"type":        "array",
-				"description": "Arguments to pass to the shell",
-				"items": map[string]any{
-					"type": "string",
-				},
-				"default": []string{"-l"},
-			},
-		},
+func (g *generator) addFields(props map[string]any, t reflect.Type, path string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			g.addFields(props, field.Type, path)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s := g.schemaOf(field.Type, joinPath(path, name))
+		if _, ok := s["description"]; !ok {
+			if doc := g.fieldDoc(t, field, name); doc != "" {
+				s["description"] = doc
+			}
+		}
+		props[name] = s
 	}
+}
 
-	// Add autoCompact flag
-	schema["properties"].(map[string]any)["autoCompact"] = map[string]any{
-		"type":        "boolean",
-		"description": "Enable automatic compaction of session history",
-		"default":     true,
+// annotate merges the annotation of path into s.
+func (g *generator) annotate(s map[string]any, path string) {
+	a, ok := annotations[path]
+	if !ok {
+		return
 	}
-
-	// Add session provider configuration
-	schema["properties"].(map[string]any)["sessionProvider"] = map[string]any{
-		"type":        "object",
-		"description": "Session storage provider configuration",
-		"properties": map[string]any{
-			"type": map[string]any{
-				"type":        "string",
-				"description": "Type of session storage provider",
-				"enum":        []string{"sqlite", "mysql"},
-				"default":     "sqlite",
-			},
-			"mysql": map[string]any{
-				"type":        "object",
-				"description": "MySQL-specific configuration",
-				"properties": map[string]any{
-					"dsn": map[string]any{
-						"type":        "string",
-						"description": "MySQL Data Source Name (DSN) connection string",
-					},
-					"host": map[string]any{
-						"type":        "string",
-						"description": "MySQL server host",
-					},
-					"port": map[string]any{
-						"type":        "integer",
-						"description": "MySQL server port",
-						"default":     3306,
-					},
-					"database": map[string]any{
-						"type":        "string",
-						"description": "MySQL database name",
-					},
-					"username": map[string]any{
-						"type":        "string",
-						"description": "MySQL username",
-					},
-					"password": map[string]any{
-						"type":        "string",
-						"description": "MySQL password",
-					},
-					"maxConnections": map[string]any{
-						"type":        "integer",
-						"description": "Maximum number of open connections",
-						"default":     10,
-					},
-					"maxIdleConnections": map[string]any{
-						"type":        "integer",
-						"description": "Maximum number of idle connections",
-						"default":     5,
-					},
-					"connectionTimeout": map[string]any{
-						"type":        "integer",
-						"description": "Connection timeout in seconds",
-						"default":     30,
-					},
-				},
-			},
-		},
+	g.used[path] = true
+	for k, v := range a {
+		s[k] = v
 	}
+}
 
-	// Add session cleanup configuration
-	schema["properties"].(map[string]any)["sessionCleanup"] = map[string]any{
-		"type":        "object",
-		"description": "Session cleanup configuration for removing old sessions",
-		"properties": map[string]any{
-			"maxAge": map[string]any{
-				"type":        "string",
-				"description": "Maximum age of sessions before they are eligible for cleanup. Supports Go duration strings (e.g. \"720h\") plus \"d\" for days and \"y\" for years. Examples: \"720h\", \"30d\", \"1y\". Defaults to \"30d\" (720h).",
-				"default":     "30d",
-			},
-		},
-		"additionalProperties": false,
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
 	}
+	return path + "." + name
+}
 
-	// Add skills configuration
-	schema["properties"].(map[string]any)["skills"] = map[string]any{
-		"type":        "object",
-		"description": "Skills configuration",
-		"properties": map[string]any{
-			"paths": map[string]any{
-				"type":        "array",
-				"description": "Custom paths to search for skills (supports ~ for home directory and relative paths)",
-				"items": map[string]any{
-					"type": "string",
-				},
-			},
-		},
+// fieldDoc returns the doc comment of a struct field, or its trailing line
+// comment, with the Go field name replaced by the JSON one.
+func (g *generator) fieldDoc(t reflect.Type, field reflect.StructField, name string) string {
+	pkg := t.PkgPath()
+	if !g.parsed[pkg] {
+		g.parsed[pkg] = true
+		g.parseDocs(pkg)
 	}
-
-	// Add web search configuration
-	schema["properties"].(map[string]any)["webSearch"] = map[string]any{
-		"type":        "object",
-		"description": "Web search provider configuration",
-		"properties": map[string]any{
-			"providers": map[string]any{
-				"type":        "object",
-				"description": "Search provider configurations keyed by provider name",
-				"additionalProperties": map[string]any{
-					"type":        "object",
-					"description": "Search provider configuration",
-					"properties": map[string]any{
-						"baseUrl": map[string]any{
-							"type":        "string",
-							"description": "Full URL to POST search queries to",
-						},
-						"apiKey": map[string]any{
-							"type":        "string",
-							"description": "API key for the provider. Supports 'env:VAR_NAME' syntax. Falls back to LOCAL_ENDPOINT_API_KEY env var.",
-						},
-						"description": map[string]any{
-							"type":        "string",
-							"description": "Human-readable description shown to the LLM to help select the right provider",
-						},
-					},
-					"required": []string{"baseUrl"},
-				},
-			},
-		},
+	doc := g.docs[pkg+"."+t.Name()+"."+field.Name]
+	if rest, ok := strings.CutPrefix(doc, field.Name+" "); ok {
+		doc = name + " " + rest
 	}
+	return doc
+}
 
-	// Add maxTurns at the top level (CLI override)
-	schema["properties"].(map[string]any)["maxTurns"] = map[string]any{
-		"type":        "integer",
-		"description": "Global maximum number of agent tool-use turns per request. When set, overrides per-agent maxTurns. Also settable via --max-turns CLI flag.",
-		"minimum":     1,
+func (g *generator) parseDocs(pkg string) {
+	rel, ok := strings.CutPrefix(pkg, modulePath+"/")
+	if !ok {
+		return
 	}
+	files, _ := filepath.Glob(filepath.Join(g.root, filepath.FromSlash(rel), "*.go"))
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range st.Fields.List {
+				text := field.Doc.Text()
+				if text == "" {
+					text = field.Comment.Text()
+				}
+				text = strings.Join(strings.Fields(text), " ")
+				for _, ident := range field.Names {
+					g.docs[pkg+"."+spec.Name.Name+"."+ident.Name] = text
+				}
+			}
+			return true
+		})
+	}
+}
 
-	// Add telemetry configuration
-	schema["properties"].(map[string]any)["telemetry"] = map[string]any{
-		"type":        "object",
-		"description": "Telemetry configuration for identifying requests. Values are used by provider metadata resolution.",
-		"properties": map[string]any{
-			"userId": map[string]any{
-				"type":        "string",
-				"description": "User identifier attached to LLM requests. Takes precedence over auto-generated UUID but is overridden by OPENCODE_USER_ID env var.",
-			},
-			"tags": map[string]any{
-				"type":        "array",
-				"description": "Static tags attached to every LLM request when the provider metadata has a tags field configured. Useful for environment labels (e.g., 'prod', 'dev', 'team-a').",
-				"items": map[string]any{
-					"type": "string",
-				},
-			},
-			"defaultTags": map[string]any{
-				"type":        "array",
-				"description": "List of predefined tag keys that OpenCode will automatically add to metadata. Supported keys: 'agent'. When set, only listed tag keys are included in dynamic metadata tags.",
-				"items": map[string]any{
-					"type": "string",
-					"enum": []string{"agent"},
-				},
-			},
-			"langfuse": map[string]any{
-				"type":        "object",
-				"description": "Langfuse observability integration. When enabled, traces and generations are sent directly to Langfuse for LLM observability.",
+// annotations add what the Go types can't say: descriptions, defaults,
+// bounds and the values a string takes. Keys are schema paths as passed to
+// schemaOf. A field without a description here is described by its doc
+// comment.
+var annotations = map[string]map[string]any{
+	"":                                              {"description": "Configuration schema for the OpenCode application"},
+	"agentPaths":                                    {"description": "Custom directories to scan for markdown agent definitions (*.md) at startup. Supports ~ for the home directory and relative paths (resolved against the working directory). Custom-path agents have the lowest precedence among discovery sources."},
+	"agents":                                        {"description": "Agent configurations"},
+	"agents.*":                                      {"description": "Agent configuration", "required": []string{"model"}},
+	"agents.*.budget":                               {"description": "Hard spending limit for the session this agent runs in. The run stops once it is reached."},
+	"agents.*.budget.maxCostUSD":                    {"description": "Maximum cost in USD", "minimum": 0},
+	"agents.*.budget.maxTokens":                     {"description": "Maximum prompt plus completion tokens", "minimum": 0},
+	"agents.*.color":                                {"description": "Badge color for subagent display (e.g., 'blue', 'orange', 'primary', 'warning')"},
+	"agents.*.context":                              {"description": "Inline context added to this agent's system prompt after its context files"},
+	"agents.*.contextPaths":                         {"description": "Context files and directories (ending in /) for this agent's system prompt, replacing the global contextPaths"},
+	"agents.*.description":                          {"description": "Description of the agent's purpose"},
+	"agents.*.disabled":                             {"description": "Whether the agent is disabled and excluded from the registry entirely", "default": false},
+	"agents.*.dryRun":                               {"description": "Make the agent's write tools (edit, write, multiedit, patch, delete and mutating bash commands) report what they would change instead of applying it", "default": false},
+	"agents.*.fallbackModels":                       {"description": "Models tried in order when the agent's model cannot be used, or its provider fails with a quota or overload error"},
+	"agents.*.hidden":                               {"description": "Whether the agent is hidden from TUI agent switching", "default": false},
+	"agents.*.maxParallelTasks":                     {"description": "How many task tool calls of one turn run their subagents at the same time. Further calls wait for a free slot.", "default": 4, "minimum": 1},
+	"agents.*.maxTokens":                            {"description": "Maximum tokens for the agent", "minimum": 1},
+	"agents.*.maxTurns":                             {"description": "Maximum number of tool-use turns per request for this agent. Default is 100.", "minimum": 1},
+	"agents.*.mode":                                 {"description": "Agent mode: 'agent' for primary agents, 'subagent' for agents invoked by task tool"},
+	"agents.*.model":                                {"description": "Model ID for the agent"},
+	"agents.*.name":                                 {"description": "Display name for the agent"},
+	"agents.*.native":                               {"description": "Whether the agent is one of opencode's built-in agents"},
+	"agents.*.output":                               {"description": "Structured output: the agent's final answer must conform to schema. See docs/structured-output.md."},
+	"agents.*.output.schema":                        {"description": "JSON schema of the final answer"},
+	"agents.*.outputLimits":                         {"description": "Per-call caps on tool output for this agent. Unset fields keep the built-in limits; explorer defaults to half of them."},
+	"agents.*.outputLimits.maxLineLength":           {"description": "Characters of a line the read tool keeps before cutting it (default 2000)", "minimum": 1},
+	"agents.*.outputLimits.maxListFiles":            {"description": "Most entries one ls call returns (default 1000)", "minimum": 1},
+	"agents.*.outputLimits.maxOutputBytes":          {"description": "Bytes of bash, run_task and job_output output returned before the rest is saved to a temp file (default 51200)", "minimum": 1},
+	"agents.*.outputLimits.maxOutputLines":          {"description": "Lines of bash and run_task output returned before the rest is saved to a temp file (default 2000)", "minimum": 1},
+	"agents.*.outputLimits.maxReadBytes":            {"description": "Largest file the read tool opens, in bytes (default 256000)", "minimum": 1},
+	"agents.*.outputLimits.readLines":               {"description": "Most lines one read call returns (default 2000)", "minimum": 1},
+	"agents.*.parallelToolUse":                      {"description": "Whether to enable parallel tool execution for this agent. When true (default), independent tool calls run concurrently. Set to false to force sequential execution.", "default": true},
+	"agents.*.permission":                           {"description": "Agent-specific permission overrides. Keys are tool names (e.g., 'bash', 'edit', 'skill'), values are either a simple action string or an object with glob-pattern keys"},
+	"agents.*.permission.*":                         {"anyOf": permissionRuleSchemas("Simple permission action")},
+	"agents.*.prompt":                               {"description": "Custom system prompt for the agent"},
+	"agents.*.reasoningEffort":                      {"description": "Reasoning effort for models that support it (OpenAI, Anthropic). 'max' is only available for models with maximum thinking support. 'auto' picks low, medium or high for each turn from the user's prompt.", "enum": []string{"low", "medium", "high", "max", "auto"}},
+	"agents.*.responseCache":                        {"description": "Reuse the response of an earlier synchronous task tool call to this subagent when the prompt and the workspace state (git HEAD plus uncommitted changes) are identical. Meant for read-only subagents such as explorer.", "required": []string{"ttl"}},
+	"agents.*.responseCache.ttl":                    {"description": "How long a cached response stays valid, as a Go duration or a number of days or years (e.g. \"30m\", \"1d\")"},
+	"agents.*.skills":                               {"description": "List of skill names to preload into the agent's system prompt at startup. Skills are injected as <skill_content> blocks. Only skills not explicitly denied by permissions are injected. Variable substitution and shell markup are not expanded for preloaded skills."},
+	"agents.*.taskBudget":                           {"description": "Advisory token budget for the full agentic loop (minimum 20000). Only supported by models with SupportsTaskBudget. The budget is carried across compaction via the remaining field.", "minimum": 20000},
+	"agents.*.thinkingBudgetTokens":                 {"description": "Tokens the model may spend reasoning before it answers (minimum 1024, kept below maxTokens). Sets Anthropic's extended thinking budget_tokens and Gemini's thinkingBudget; for OpenAI and adaptive thinking models it picks the reasoning effort when reasoningEffort is unset (up to 4096 low, up to 16384 medium, above that high).", "minimum": 1024},
+	"agents.*.tools":                                {"description": "Tool enable/disable configuration"},
+	"agents.*.tools.*":                              {"description": "Whether the tool is enabled for this agent"},
+	"audit":                                         {"description": "Append-only, hash-chained audit trail of tool calls, permission decisions and provider requests. Check it with `opencode audit verify`."},
+	"audit.enabled":                                 {"description": "Record the audit trail", "default": false},
+	"audit.path":                                    {"description": "JSONL file of the trail; relative paths resolve against the data directory", "default": "audit.jsonl"},
+	"audit.signingKey":                              {"description": "PEM file with an Ed25519 private key (PKCS#8) used to sign every entry"},
+	"autoCompact":                                   {"description": "Enable automatic compaction of session history", "default": true},
+	"autoSnapshot":                                  {"description": "Record the git work tree as a hidden snapshot before the first file change of each agent run, so /restore can reset it", "default": false},
+	"budget":                                        {"description": "Hard spending limits. A run that reaches one stops with a budget_exceeded event."},
+	"budget.global":                                 {"description": "Limit for all sessions of the project combined"},
+	"budget.global.maxCostUSD":                      {"description": "Maximum cost in USD", "minimum": 0},
+	"budget.global.maxTokens":                       {"description": "Maximum prompt plus completion tokens", "minimum": 0},
+	"budget.session":                                {"description": "Limit for each session tree (root session plus its subagent and flow step sessions)"},
+	"budget.session.maxCostUSD":                     {"description": "Maximum cost in USD", "minimum": 0},
+	"budget.session.maxTokens":                      {"description": "Maximum prompt plus completion tokens", "minimum": 0},
+	"codeSearch":                                    {"description": "Enable the codesearch tool: semantic search over the workspace backed by an embeddings index stored in the database", "required": []string{"provider"}},
+	"codeSearch.baseURL":                            {"description": "Override the provider's embeddings endpoint; /embeddings is appended for OpenAI-compatible providers"},
+	"codeSearch.exclude":                            {"description": "Doublestar patterns, relative to the working directory, of files that are not indexed"},
+	"codeSearch.model":                              {"description": "Embedding model; defaults to text-embedding-3-small (openai), text-embedding-004 (gemini) or nomic-embed-text (ollama), required for local"},
+	"codeSearch.provider":                           {"description": "Provider serving the embeddings", "enum": []string{"openai", "gemini", "ollama", "local"}},
+	"contentFilter":                                 {"description": "What to do when the provider's content filter blocks a response. The response is marked as blocked and recorded in the audit trail either way"},
+	"contentFilter.maxRetries":                      {"description": "Retries one turn may make (0 = 1)", "minimum": 0},
+	"contentFilter.retry":                           {"description": "none ends the turn on the blocked response; rephrase asks the model again with a note that its answer was blocked; fallback repeats the request on the agent's next fallback model", "default": "none", "enum": []string{"none", "rephrase", "fallback"}},
+	"contextPaths":                                  {"description": "Context files and directories (ending in /) added to the system prompt. Each file is capped at 8000 estimated tokens unless the entry sets maxTokens.", "default": []string{".github/copilot-instructions.md", ".cursorrules", ".cursor/rules/", "CLAUDE.md", "CLAUDE.local.md", "opencode.md", "opencode.local.md", "OpenCode.md", "OpenCode.local.md", "OPENCODE.md", "OPENCODE.local.md", "AGENTS.md", "AGENTS.local.md"}},
+	"data":                                          {"description": "Storage configuration", "required": []string{"directory"}},
+	"data.directory":                                {"description": "Directory where application data is stored", "default": ".opencode"},
+	"debug":                                         {"description": "Enable debug mode", "default": false},
+	"debugLSP":                                      {"description": "Enable LSP debug mode", "default": false},
+	"diffBudget":                                    {"description": "Cap how much the write tools may change in one turn; going over asks for confirmation even in auto-approve sessions, or is refused"},
+	"diffBudget.action":                             {"description": "What happens when a change would go over the budget; runs without a user to ask always refuse", "default": "ask", "enum": []string{"ask", "deny"}},
+	"diffBudget.maxFiles":                           {"description": "Distinct files one turn may change, including its subagents' changes (0 = no limit)", "minimum": 0},
+	"diffBudget.maxLines":                           {"description": "Lines added plus removed one turn may change, including its subagents' changes (0 = no limit)", "minimum": 0},
+	"disableAutoTitle":                              {"description": "Do not generate session titles automatically, on the first message or after compaction. /retitle still generates one on request.", "default": false},
+	"disableLSPDownload":                            {"description": "Disable automatic downloading and installation of LSP servers. Can also be set via OPENCODE_DISABLE_LSP_DOWNLOAD environment variable.", "default": false},
+	"dryRun":                                        {"description": "Make the write tools of every agent report the diff or command they would apply instead of applying it", "default": false},
+	"flowPaths":                                     {"description": "Custom directories to scan for flow YAML definitions (*.yaml / *.yml) at startup. Supports ~ for the home directory and relative paths (resolved against the working directory). Flows discovered here get a namespaced ID <parent-dir-basename>/<file-basename> and can never shadow a built-in (slash-free) flow ID."},
+	"hooks":                                         {"description": "Claude-Code-compatible hooks. Keys are event names (`PreToolUse`, `PostToolUse`, `SessionStart`, `SessionEnd`); values are matcher groups whose entries fire as POSIX subprocesses receiving event JSON on stdin and returning decisions on stdout, or as webhooks receiving the same JSON in a POST body. The block is loaded once at process startup; restart required to pick up edits. Shape matches Claude Code's `settings.json` `hooks` block byte-for-byte for the events implemented here. See docs/hooks.md and openspec/specs/hook-runtime/spec.md."},
+	"hooks.*":                                       {"description": "Other Claude Code event names (UserPromptSubmit, Stop, etc.) load cleanly but do not yet fire in opencode."},
+	"hooks.*[]":                                     {"description": "A matcher group runs its inner `hooks` list sequentially when its matcher matches the triggering tool name (or, for SessionStart / SessionEnd, the source / reason).", "required": []string{"hooks"}},
+	"hooks.*[].hooks":                               {"description": "Sequentially-run hook entries."},
+	"hooks.*[].hooks[]":                             {"description": "A single executable hook within a matcher group."},
+	"hooks.*[].hooks[].args":                        {"description": "Optional argv tail. Presence switches the spawn from shell form to exec form — author-controlled inputs are passed through verbatim with no shell expansion."},
+	"hooks.*[].hooks[].command":                     {"description": "Executable to spawn (`command` hooks). When `args` is omitted, the value is passed to `sh -c \"…\"` (shell form). When `args` is present, the value is exec'd directly with `args` as argv[1:] (no shell tokenization)."},
+	"hooks.*[].hooks[].headers":                     {"description": "Extra request headers for an `http` hook. Values expand `$VAR` / `${VAR}` from the environment."},
+	"hooks.*[].hooks[].shell":                       {"description": "Override the shell binary used for shell-form invocations. Defaults to `bash` if available on PATH, else `sh`."},
+	"hooks.*[].hooks[].timeout":                     {"description": "Per-hook timeout in seconds. Default 600. The runner SIGTERMs the process group on overrun, then SIGKILLs after a 2-second grace; an `http` request is cancelled.", "minimum": 1},
+	"hooks.*[].hooks[].type":                        {"description": "Hook implementation type. `command` spawns a subprocess; `http` POSTs the event JSON to `url`. Settings entries with any other type are loaded and silently skipped with a WARN log so a settings.json that targets Claude Code's other hook types still loads cleanly.", "default": "command", "enum": []string{"command", "http"}},
+	"hooks.*[].hooks[].url":                         {"description": "Endpoint an `http` hook POSTs the event JSON to. A 2xx response body is read like a command hook's stdout; any other status is a non-blocking error."},
+	"hooks.*[].matcher":                             {"description": "Tool-name predicate. Empty / `*` matches every tool. A value composed only of `[A-Za-z0-9_, |]` is an exact name or `|`/`,`-separated list, compared case-insensitively. Anything else is a Go RE2 regex (case-sensitive unless `(?i)` is used). opencode tool names are lowercase (`bash`, `edit`, `write`, …); PascalCase matchers from Claude Code configs (`Bash`, `Edit|Write`) also match."},
+	"hooks.PostToolUse":                             {"description": "Fires after a tool's Run returns successfully. Hooks can replace `tool_output` (RTK-style log compaction) or append additional context to the next agent turn. Does NOT fire on tool error."},
+	"hooks.PreToolUse":                              {"description": "Fires before tool dispatch. Hooks can mutate `tool_input`, deny the call (`permissionDecision: \"deny\"` or exit 2), or override the standard permission gate (`permissionDecision: \"allow\"`)."},
+	"hooks.SessionEnd":                              {"description": "Fires when a session started by this process is deleted (`reason: \"delete\"`) or the process exits (`reason: \"exit\"`). Matchers compare against `reason`. Notification only."},
+	"hooks.SessionStart":                            {"description": "Fires when a top-level session is created. Matchers compare against `source` (`new`). Notification only: output is logged and cannot veto the session."},
+	"lsp":                                           {"description": "Language Server Protocol configurations. Built-in servers are auto-detected; use this to override, disable, or add custom servers."},
+	"lsp.*":                                         {"description": "LSP configuration for a language server"},
+	"lsp.*.args":                                    {"description": "Command arguments for the LSP server"},
+	"lsp.*.command":                                 {"description": "Command to execute for the LSP server"},
+	"lsp.*.disabled":                                {"description": "Whether the LSP server is disabled", "default": false},
+	"lsp.*.env":                                     {"description": "Environment variables to set when starting the LSP server"},
+	"lsp.*.extensions":                              {"description": "File extensions this LSP server should handle (e.g., [\".go\", \".mod\"])"},
+	"lsp.*.initialization":                          {"type": "object", "description": "Initialization options sent to the LSP server during the initialize request. Options vary by server."},
+	"maxTurns":                                      {"description": "Global maximum number of agent tool-use turns per request. When set, overrides per-agent maxTurns. Also settable via --max-turns CLI flag.", "minimum": 1},
+	"mcpServers":                                    {"description": "Model Control Protocol server configurations"},
+	"mcpServers.*":                                  {"description": "MCP server configuration", "required": []string{"command"}},
+	"mcpServers.*.args":                             {"description": "Command arguments for the MCP server"},
+	"mcpServers.*.callToolTimeoutSeconds":           {"description": "Per-tool-call timeout override in seconds. Zero or omitted falls back to the built-in default (5 minutes).", "minimum": 0},
+	"mcpServers.*.command":                          {"description": "Command to execute for the MCP server"},
+	"mcpServers.*.disabled":                         {"description": "Whether the MCP server is disabled", "default": false},
+	"mcpServers.*.env":                              {"description": "Environment variables for the MCP server"},
+	"mcpServers.*.headers":                          {"description": "HTTP headers for sse, http, streamable-http and websocket type MCP servers (sent with the WebSocket handshake)"},
+	"mcpServers.*.type":                             {"description": "Type of MCP server", "default": "stdio"},
+	"mcpServers.*.url":                              {"description": "URL for sse, http, streamable-http and websocket type MCP servers"},
+	"modelCheck":                                    {"description": "Background checks of which models the configured API keys can use; unavailable models are greyed out in the model picker"},
+	"modelCheck.disabled":                           {"description": "Disable the background checks; `opencode models check` still works", "default": false},
+	"modelCheck.interval":                           {"description": "How old the last check may be before it is repeated, e.g. 6h or 1d", "default": "12h"},
+	"moderation":                                    {"description": "Screen assistant responses before their tool calls run, with local regexp rules and an optional moderation endpoint"},
+	"moderation.endpoint":                           {"description": "URL that receives a JSON POST of each response with tool calls and answers {\"flagged\": bool, \"reason\": string}"},
+	"moderation.failClosed":                         {"description": "Block the tool calls when the endpoint cannot be reached", "default": false},
+	"moderation.headers":                            {"description": "HTTP headers sent to the endpoint"},
+	"moderation.noOverride":                         {"description": "Block flagged tool calls without offering the user an override", "default": false},
+	"moderation.rules":                              {"description": "Regexp rules checked against the response text and tool call inputs"},
+	"moderation.rules[]":                            {"required": []string{"pattern"}},
+	"moderation.rules[].name":                       {"description": "Rule name, recorded in the audit trail"},
+	"moderation.rules[].pattern":                    {"description": "RE2 regular expression"},
+	"moderation.rules[].reason":                     {"description": "Explanation shown to the model and the user when the rule fires"},
+	"moderation.rules[].tools":                      {"description": "Only check these tools' inputs (wildcards allowed); the response text is then skipped"},
+	"moderation.timeoutMs":                          {"description": "Endpoint request timeout in milliseconds", "default": 10000},
+	"permission":                                    {"description": "Global permission configuration"},
+	"permission.allowSecrets":                       {"description": "Paths or patterns exempt from the secret file guard (.env, private keys, cloud credentials). Use [\"*\"] to turn the guard off."},
+	"permission.review":                             {"description": "Route permission requests that would otherwise ask (or be auto-approved) through a reviewer agent that approves, denies or escalates them", "required": []string{"agent"}},
+	"permission.review.agent":                       {"description": "ID of the reviewer agent; its prompt defines the policy"},
+	"permission.review.tools":                       {"description": "Tool names (wildcards allowed) to review. Empty reviews every tool."},
+	"permission.rules":                              {"description": "Permission rules. Keys are tool names (e.g., 'bash', 'edit', 'skill'). Values are either a simple action string or an object with glob-pattern keys."},
+	"permission.rules.*":                            {"anyOf": permissionRuleSchemas("Simple permission action for all uses of this tool")},
+	"permission.skill":                              {"description": "Skill permission patterns (supports wildcards like 'internal-*')"},
+	"permission.skill.*":                            {"description": "Permission action", "enum": []string{"allow", "deny", "ask"}},
+	"profile":                                       {"description": "Profile applied by default, typically set in a project's .opencode.json. Overridden by --profile and OPENCODE_PROFILE."},
+	"profiles":                                      {"description": "Named configuration presets (e.g. work, personal, cheap) selected with \"profile\", --profile or /profile. The selected one is merged over the rest of the configuration."},
+	"profiles.*":                                    {"description": "Profile configuration"},
+	"profiles.*.agents":                             {"description": "Agent settings merged over agents while the profile is in force"},
+	"profiles.*.providers":                          {"description": "Provider settings merged over providers while the profile is in force"},
+	"providers":                                     {"description": "LLM provider configurations"},
+	"providers.*":                                   {"description": "Provider configuration"},
+	"providers.*.apiKey":                            {"description": "API key for the provider. ${env:NAME}, ${file:path} and ${keychain:service/account} placeholders are resolved at load time."},
+	"providers.*.baseURL":                           {"description": "Base URL for the provider instead of default one"},
+	"providers.*.batch":                             {"description": "Anthropic and OpenAI only: send the requests of offline runs (flow steps, opencode -p, queued runs) through the batch API at about half the price. Answers may take hours"},
+	"providers.*.batch.enabled":                     {"description": "Use the batch API for offline runs"},
+	"providers.*.batch.pollInterval":                {"description": "How often a submitted batch is checked (default 30s)"},
+	"providers.*.batch.timeout":                     {"description": "Fail and cancel a batch that hasn't ended by then (default 24h)"},
+	"providers.*.batch.window":                      {"description": "How long requests are collected before they are submitted as one batch (default 2s)"},
+	"providers.*.disabled":                          {"description": "Whether the provider is disabled", "default": false},
+	"providers.*.headers":                           {"description": "Extra headers to attach to request"},
+	"providers.*.keepAlive":                         {"description": "Ollama only: how long the model stays loaded after a request, as a duration (e.g. '30m') or seconds ('-1' keeps it loaded)"},
+	"providers.*.metadata":                          {"description": "Metadata key-value pairs attached to every LLM API request body. Keys are built-in identifiers (sessionId, userId, tags) that OpenCode resolves at runtime. Values are the field names used in the metadata object sent to the API."},
+	"providers.*.metadata.sessionId":                {"description": "Field name for the session ID in the metadata object. The value is resolved from the current session context."},
+	"providers.*.metadata.tags":                     {"description": "Field name for the tags array in the metadata object. Tags are resolved from telemetry.tags config and can be extended dynamically at runtime."},
+	"providers.*.metadata.userId":                   {"description": "Field name for the user ID in the metadata object. The value is read from OPENCODE_USER_ID env var, telemetry.userId config, or auto-generated as UUID at startup."},
+	"providers.*.numCtx":                            {"description": "Ollama only: context window (num_ctx) requested for every call. Defaults to the model's context length, capped at 32768", "minimum": 1},
+	"providers.*.retry":                             {"description": "Retry policy for failed requests to this provider. Unset fields keep the client's defaults"},
+	"providers.*.retry.baseDelayMs":                 {"description": "First backoff delay in milliseconds, doubled on every further retry", "minimum": 0},
+	"providers.*.retry.maxDelayMs":                  {"description": "Cap on a single backoff delay in milliseconds, Retry-After included", "minimum": 0},
+	"providers.*.retry.maxRetries":                  {"description": "Retries after the first attempt (default 8); -1 disables retrying", "minimum": -1},
+	"providers.*.retry.respectRetryAfter":           {"description": "Wait as long as the provider's Retry-After header asks", "default": true},
+	"providers.*.retry.retryOnStatus":               {"description": "HTTP status codes to retry, replacing the client's defaults"},
+	"readOnly":                                      {"description": "Remove the file-changing tools from every agent and only let bash run read-only commands, regardless of permissions", "default": false},
+	"router":                                        {"description": "Chat bridge connecting `opencode serve` to Telegram, Slack and Mattermost. See docs/bridge.md."},
+	"router.agentPeerAllowlist":                     {"description": "Peers the router_send tool may message. Not enforced yet."},
+	"router.agentPeerAllowlist[].channel":           {"description": "Channel of the peer: telegram, slack or mattermost", "enum": []string{"telegram", "slack", "mattermost"}},
+	"router.agentPeerAllowlist[].identity":          {"description": "ID of the bot, app or instance identity the peer talks to"},
+	"router.agentPeerAllowlist[].mention":           {"description": "Mention prepended to messages sent to the peer"},
+	"router.agentPeerAllowlist[].peerId":            {"description": "Platform ID of the peer: a chat, user or channel ID"},
+	"router.channels":                               {"description": "Per-platform channels and their identities"},
+	"router.channels.mattermost":                    {"description": "Mattermost channel"},
+	"router.channels.mattermost.enabled":            {"description": "Whether the Mattermost channel runs"},
+	"router.channels.mattermost.instances":          {"description": "Mattermost server connections"},
+	"router.channels.mattermost.instances[].access": {"description": "'private' accepts only paired or allowlisted peers; 'public' (default) accepts anyone", "enum": []string{"private", "public"}},
+	"router.channels.mattermost.instances[].accessToken":   {"description": "Bot or personal access token"},
+	"router.channels.mattermost.instances[].enabled":       {"description": "Whether the connection runs"},
+	"router.channels.mattermost.instances[].groupsEnabled": {"description": "Answer in channels as well as direct messages"},
+	"router.channels.mattermost.instances[].id":            {"description": "Identity ID, unique within the channel"},
+	"router.channels.mattermost.instances[].inbound":       {"description": "'disabled' stops the connection listening for messages while it still sends; use when an orchestrator forwards inbound messages", "enum": []string{"enabled", "disabled"}},
+	"router.channels.mattermost.instances[].peerAllowlist": {"description": "Peers accepted in private mode: user IDs or channel IDs"},
+	"router.channels.mattermost.instances[].serverUrl":     {"description": "URL of the Mattermost server"},
+	"router.channels.slack":                                {"description": "Slack channel"},
+	"router.channels.slack.apps":                           {"description": "Slack app identities, connected over Socket Mode"},
+	"router.channels.slack.apps[].access":                  {"description": "'private' accepts only paired or allowlisted peers; 'public' (default) accepts anyone", "enum": []string{"private", "public"}},
+	"router.channels.slack.apps[].appToken":                {"description": "App-level token for Socket Mode (xapp-...)"},
+	"router.channels.slack.apps[].botToken":                {"description": "Bot token (xoxb-...)"},
+	"router.channels.slack.apps[].enabled":                 {"description": "Whether the app runs"},
+	"router.channels.slack.apps[].groupsEnabled":           {"description": "Answer in channels as well as direct messages"},
+	"router.channels.slack.apps[].id":                      {"description": "Identity ID, unique within the channel"},
+	"router.channels.slack.apps[].inbound":                 {"description": "'disabled' stops the app listening for messages while it still sends; use when an orchestrator forwards inbound messages", "enum": []string{"enabled", "disabled"}},
+	"router.channels.slack.apps[].peerAllowlist":           {"description": "Peers accepted in private mode: user IDs (U…), DM channel IDs (D…) or channel IDs (C…)"},
+	"router.channels.slack.enabled":                        {"description": "Whether the Slack channel runs"},
+	"router.channels.telegram":                             {"description": "Telegram channel"},
+	"router.channels.telegram.bots":                        {"description": "Telegram bot identities"},
+	"router.channels.telegram.bots[].access":               {"description": "'private' accepts only paired or allowlisted peers; 'public' (default) accepts anyone", "enum": []string{"private", "public"}},
+	"router.channels.telegram.bots[].enabled":              {"description": "Whether the bot runs"},
+	"router.channels.telegram.bots[].groupsEnabled":        {"description": "Answer in group chats as well as direct messages"},
+	"router.channels.telegram.bots[].id":                   {"description": "Identity ID, unique within the channel"},
+	"router.channels.telegram.bots[].inbound":              {"description": "'disabled' stops the bot listening for messages while it still sends; use when an orchestrator forwards inbound messages", "enum": []string{"enabled", "disabled"}},
+	"router.channels.telegram.bots[].pairingCodeHash":      {"description": "SHA-256 hex of the pairing code private peers send with /pair"},
+	"router.channels.telegram.bots[].token":                {"description": "Bot token from @BotFather"},
+	"router.channels.telegram.enabled":                     {"description": "Whether the Telegram channel runs"},
+	"router.permissionMode":                                {"description": "How permission requests are answered while the agent is driven from chat", "enum": []string{"allow", "deny"}},
+	"router.questionMode":                                  {"description": "How questions from the agent reach the chat: 'interactive' renders buttons, 'auto-reject' answers with the default, 'disabled' drops them", "enum": []string{"interactive", "auto-reject", "disabled"}},
+	"router.toolUpdatesEnabled":                            {"description": "Post tool progress ([tool] pending, running, completed) to the chat, not just typing indicators and the final messages"},
+	"routing":                                              {"description": "Route each user request to the model configured for its class (quick question, code edit, large refactor, summarization) instead of the agent's own model"},
+	"routing.agents":                                       {"description": "Agents whose requests are routed", "default": []string{"coder"}},
+	"routing.rules":                                        {"description": "Model per request class; classes without a rule keep the agent's model"},
+	"routing.rules.code_edit":                              {"description": "Model for code edit requests"},
+	"routing.rules.large_refactor":                         {"description": "Model for large refactor requests"},
+	"routing.rules.quick_question":                         {"description": "Model for quick question requests"},
+	"routing.rules.summarization":                          {"description": "Model for summarization requests"},
+	"runQueue":                                             {"description": "Concurrency limits of the persistent run queue drained by `opencode serve`"},
+	"runQueue.concurrency":                                 {"description": "Maximum number of queued runs executing at once", "default": 1, "minimum": 1},
+	"runQueue.projects":                                    {"description": "Maximum running runs per project ID"},
+	"runQueue.projects.*":                                  {"minimum": 1},
+	"runQueue.providers":                                   {"description": "Maximum running runs per LLM provider, e.g. {\"anthropic\": 2}"},
+	"runQueue.providers.*":                                 {"minimum": 1},
+	"sandbox":                                              {"description": "Confine the tools that change files, and the bash working directory, to a set of directories. Symlinks are resolved before paths are compared."},
+	"sandbox.allowedPaths":                                 {"description": "Directories tools may change files in. \"~\" expands to the home directory; relative paths resolve against the working directory. Empty disables the sandbox."},
+	"sandbox.exceptions":                                   {"description": "Permission-style globs (e.g. \"/tmp/*\") for paths outside allowedPaths that tools may still change"},
+	"sessionCleanup":                                       {"description": "Session cleanup configuration for removing old sessions"},
+	"sessionCleanup.maxAge":                                {"description": "Maximum age of sessions before they are eligible for cleanup. Supports Go duration strings (e.g. \"720h\") plus \"d\" for days and \"y\" for years. Examples: \"720h\", \"30d\", \"1y\". Defaults to \"30d\" (720h).", "default": "30d"},
+	"sessionProvider":                                      {"description": "Session storage provider configuration"},
+	"sessionProvider.mysql":                                {"description": "MySQL-specific configuration"},
+	"sessionProvider.mysql.connectionTimeout":              {"description": "Connection timeout in seconds", "default": 30},
+	"sessionProvider.mysql.database":                       {"description": "MySQL database name"},
+	"sessionProvider.mysql.dsn":                            {"description": "MySQL Data Source Name (DSN) connection string"},
+	"sessionProvider.mysql.host":                           {"description": "MySQL server host"},
+	"sessionProvider.mysql.maxConnections":                 {"description": "Maximum number of open connections", "default": 10},
+	"sessionProvider.mysql.maxIdleConnections":             {"description": "Maximum number of idle connections", "default": 5},
+	"sessionProvider.mysql.password":                       {"description": "MySQL password"},
+	"sessionProvider.mysql.port":                           {"description": "MySQL server port", "default": 3306},
+	"sessionProvider.mysql.username":                       {"description": "MySQL username"},
+	"sessionProvider.type":                                 {"description": "Type of session storage provider", "default": "sqlite"},
+	"shell":                                                {"description": "Shell configuration for the bash tool"},
+	"shell.args":                                           {"description": "Arguments to pass to the shell. Defaults to `-l` for POSIX shells, `-NoLogo -NoProfile -NonInteractive -ExecutionPolicy Bypass -Command -` for PowerShell and `/Q /D /K` for cmd.", "default": []string{"-l"}},
+	"shell.backend":                                        {"description": "Where commands run: the host shell or a Docker/Podman container configured under `container`", "default": "host", "enum": []string{"host", "container"}},
+	"shell.container":                                      {"description": "Container for the `container` backend. The working directory is mounted at its host path.", "required": []string{"image"}},
+	"shell.container.args":                                 {"description": "Extra arguments for `run`, e.g. [\"--memory\", \"2g\"]"},
+	"shell.container.env":                                  {"description": "Variables to set as NAME=value, or NAME to pass the host's value through"},
+	"shell.container.image":                                {"description": "Image the shell runs in"},
+	"shell.container.mounts":                               {"description": "Extra volumes as \"host:container[:ro]\"; relative host paths resolve against the working directory"},
+	"shell.container.network":                              {"description": "Value for --network: \"none\" cuts the container off, \"bridge\" or a network name gives it access", "default": "none"},
+	"shell.container.readOnly":                             {"description": "Mount the working directory read-only", "default": false},
+	"shell.container.runtime":                              {"description": "Container CLI", "default": "docker", "enum": []string{"docker", "podman"}},
+	"shell.path":                                           {"description": "Path to the shell executable. pwsh, powershell and cmd are run with PowerShell or batch syntax; anything else is treated as a POSIX shell."},
+	"skills":                                               {"description": "Skills configuration"},
+	"skills.paths":                                         {"description": "Custom paths to search for skills (supports ~ for home directory and relative paths)"},
+	"snippets":                                             {"description": "Named prompt snippets. Typing !name in the prompt editor expands to the snippet's text (ctrl+g expands in place, sending expands the rest); /snippets picks one from a list. $FILE is replaced with the last file picked with @ and $SELECTION with the rest of the prompt. Markdown files in .opencode/snippets and ~/.config/opencode/snippets add more."},
+	"telemetry":                                            {"description": "Telemetry configuration for identifying requests. Values are used by provider metadata resolution."},
+	"telemetry.defaultTags":                                {"description": "List of predefined tag keys that OpenCode will automatically add to metadata. Supported keys: 'agent'. When set, only listed tag keys are included in dynamic metadata tags."},
+	"telemetry.defaultTags[]":                              {"enum": []string{"agent"}},
+	"telemetry.flowArgs":                                   {"description": "Top-level flow argument names to extract into Langfuse trace metadata. Supports wildcards (e.g., 'ticket_id', 'project*', '*'). Matched args appear as dedicated metadata fields on flow step traces."},
+	"telemetry.langfuse":                                   {"description": "Langfuse observability integration. When enabled, traces and generations are sent directly to Langfuse for LLM observability."},
+	"telemetry.langfuse.baseURL":                           {"description": "Langfuse host URL. Supports 'env:VAR_NAME' syntax. Falls back to LANGFUSE_BASE_URL env var, then https://cloud.langfuse.com."},
+	"telemetry.langfuse.enabled":                           {"description": "Enable Langfuse tracing", "default": false},
+	"telemetry.langfuse.publicKey":                         {"description": "Langfuse public key. Supports 'env:VAR_NAME' syntax. Falls back to LANGFUSE_PUBLIC_KEY env var."},
+	"telemetry.langfuse.secretKey":                         {"description": "Langfuse secret key. Supports 'env:VAR_NAME' syntax. Falls back to LANGFUSE_SECRET_KEY env var."},
+	"telemetry.metadataNamespace":                          {"description": "Prefix for custom (non-Langfuse-standard) metadata keys on traces and generations. When set, keys like flow_id and agent_id become namespace.flow_id, namespace.agent_id — grouping them visually in the Langfuse UI while keeping each value independently filterable. Empty (default) preserves flat keys."},
+	"telemetry.tags":                                       {"description": "Static tags attached to every LLM request when the provider metadata has a tags field configured. Useful for environment labels (e.g., 'prod', 'dev', 'team-a')."},
+	"telemetry.tools":                                      {"description": "Controls what tool call data is logged to the telemetry backend. When enabled is false, no tool input/output is logged."},
+	"telemetry.tools.enabled":                              {"description": "Enable tool input/output logging. When false, no tool data is logged regardless of logInput/logOutput patterns.", "default": false},
+	"telemetry.tools.logInput":                             {"description": "Tool name patterns controlling which tools have their input logged. Supports wildcards (e.g., 'datadog*', 'read', '*'). If empty, no tool inputs are logged."},
+	"telemetry.tools.logOutput":                            {"description": "Tool name patterns controlling which tools have their output logged. Supports wildcards (e.g., 'bash', 'grep', '*'). If empty, no tool outputs are logged."},
+	"telemetry.userId":                                     {"description": "User identifier attached to LLM requests. Takes precedence over auto-generated UUID but is overridden by OPENCODE_USER_ID env var."},
+	"translation":                                          {"description": "Translate final assistant responses into another language (code blocks are kept untouched)"},
+	"translation.enabled":                                  {"description": "Translate responses in new sessions by default. Can be toggled per session with /translate.", "default": false},
+	"translation.language":                                 {"description": "Target language, e.g. \"German\" or \"pt-BR\". Empty disables translation."},
+	"tui":                                                  {"description": "Terminal User Interface configuration"},
+	"tui.theme":                                            {"description": "TUI theme name", "default": "opencode", "enum": theme.AvailableThemes()},
+	"tui.usagePanel":                                       {"description": "Show the token usage panel in the sidebar", "default": false},
+	"tui.vimMode":                                          {"description": "Enable vim-style keybindings for the chat text input", "default": false},
+	"wd":                                                   {"description": "Working directory for the application"},
+	"webSearch":                                            {"description": "Web search provider configuration"},
+	"webSearch.providers":                                  {"description": "Search provider configurations keyed by provider name"},
+	"webSearch.providers.*":                                {"description": "Search provider configuration"},
+	"webSearch.providers.*.apiKey":                         {"description": "API key for the provider. Supports 'env:VAR_NAME' syntax. Falls back to LOCAL_ENDPOINT_API_KEY env var."},
+	"webSearch.providers.*.baseUrl":                        {"description": "Search endpoint. Required for generic (the URL to POST to) and searxng (the instance URL); tavily and brave default to their public APIs"},
+	"webSearch.providers.*.description":                    {"description": "Human-readable description shown to the LLM to help select the right provider"},
+	"webSearch.providers.*.type":                           {"description": "Wire format of the provider: generic (POST {query, max_results}, the default), tavily, brave or searxng (self-hosted, JSON format enabled)", "default": "generic", "enum": []string{"generic", "tavily", "brave", "searxng"}},
+	"webhooks":                                             {"description": "GitHub / GitLab webhook receiver for `opencode serve`: labelled issues and command comments start flow runs"},
+	"webhooks.repos":                                       {"description": "Repositories accepted by /webhook/github and /webhook/gitlab"},
+	"webhooks.repos[]":                                     {"required": []string{"name", "provider", "secret"}},
+	"webhooks.repos[].allowedAuthors":                      {"description": "Usernames allowed to trigger runs. Empty: GitHub comments need an OWNER, MEMBER or COLLABORATOR association and GitLab comments are ignored"},
+	"webhooks.repos[].args":                                {"description": "Extra flow args merged under the event-derived args"},
+	"webhooks.repos[].command":                             {"description": "Comment prefix that triggers commentFlow", "default": "/opencode"},
+	"webhooks.repos[].commentFlow":                         {"description": "Flow run for command comments on issues and pull/merge requests (defaults to issueFlow)"},
+	"webhooks.repos[].issueFlow":                           {"description": "Flow run when the label is added to an issue"},
+	"webhooks.repos[].label":                               {"description": "Issue label that triggers issueFlow", "default": "opencode"},
+	"webhooks.repos[].name":                                {"description": "GitHub full_name or GitLab path_with_namespace"},
+	"webhooks.repos[].provider":                            {"description": "Git hosting service", "enum": []string{"github", "gitlab"}},
+	"webhooks.repos[].secret":                              {"description": "GitHub webhook secret or GitLab secret token; ${VAR} is expanded from the environment"},
+}
+
+// contextPathSchema describes a contextPaths entry: a bare path or an
+// object with options.
+func contextPathSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []map[string]any{
+			{"type": "string"},
+			{
+				"type": "object",
 				"properties": map[string]any{
-					"enabled": map[string]any{
-						"type":        "boolean",
-						"description": "Enable Langfuse tracing",
-						"default":     false,
-					},
-					"publicKey": map[string]any{
-						"type":        "string",
-						"description": "Langfuse public key. Supports 'env:VAR_NAME' syntax. Falls back to LANGFUSE_PUBLIC_KEY env var.",
-					},
-					"secretKey": map[string]any{
+					"path": map[string]any{
 						"type":        "string",
-						"description": "Langfuse secret key. Supports 'env:VAR_NAME' syntax. Falls back to LANGFUSE_SECRET_KEY env var.",
+						"description": "File path, or directory path ending in /",
 					},
-					"baseURL": map[string]any{
-						"type":        "string",
-						"description": "Langfuse host URL. Supports 'env:VAR_NAME' syntax. Falls back to LANGFUSE_BASE_URL env var, then https://cloud.langfuse.com.",
-					},
-				},
-				"additionalProperties": false,
-			},
-			"tools": map[string]any{
-				"type":        "object",
-				"description": "Controls what tool call data is logged to the telemetry backend. When enabled is false, no tool input/output is logged.",
-				"properties": map[string]any{
-					"enabled": map[string]any{
-						"type":        "boolean",
-						"description": "Enable tool input/output logging. When false, no tool data is logged regardless of logInput/logOutput patterns.",
-						"default":     false,
-					},
-					"logInput": map[string]any{
-						"type":        "array",
-						"description": "Tool name patterns controlling which tools have their input logged. Supports wildcards (e.g., 'datadog*', 'read', '*'). If empty, no tool inputs are logged.",
-						"items": map[string]any{
-							"type": "string",
-						},
-					},
-					"logOutput": map[string]any{
-						"type":        "array",
-						"description": "Tool name patterns controlling which tools have their output logged. Supports wildcards (e.g., 'bash', 'grep', '*'). If empty, no tool outputs are logged.",
-						"items": map[string]any{
-							"type": "string",
-						},
+					"maxTokens": map[string]any{
+						"type":        "integer",
+						"description": "Token limit for each matched file; larger files keep their headings and first paragraphs. 0 uses the default, -1 disables the limit.",
+						"minimum":     -1,
 					},
 				},
-				"additionalProperties": false,
-			},
-			"flowArgs": map[string]any{
-				"type":        "array",
-				"description": "Top-level flow argument names to extract into Langfuse trace metadata. Supports wildcards (e.g., 'ticket_id', 'project*', '*'). Matched args appear as dedicated metadata fields on flow step traces.",
-				"items": map[string]any{
-					"type": "string",
-				},
-			},
-			"metadataNamespace": map[string]any{
-				"type":        "string",
-				"description": "Prefix for custom (non-Langfuse-standard) metadata keys on traces and generations. When set, keys like flow_id and agent_id become namespace.flow_id, namespace.agent_id — grouping them visually in the Langfuse UI while keeping each value independently filterable. Empty (default) preserves flat keys.",
-			},
-		},
-		"additionalProperties": false,
-	}
-
-	// Add hooks configuration (Claude-Code-compatible PreToolUse / PostToolUse
-	// subprocess hooks). When updating this schema, also update
-	// `internal/hooks/config.go` (MatcherGroup, HookEntry) and the docs at
-	// docs/hooks.md so the on-disk JSON shape stays in sync with the loader.
-	hookEntrySchema := map[string]any{
-		"type":        "object",
-		"description": "A single executable hook within a matcher group.",
-		"properties": map[string]any{
-			"type": map[string]any{
-				"type":        "string",
-				"description": "Hook implementation type. v1 supports `command` only; settings entries with any other type are loaded and silently skipped with a WARN log so a settings.json that targets Claude Code's other hook types still loads cleanly.",
-				"enum":        []string{"command"},
-				"default":     "command",
-			},
-			"command": map[string]any{
-				"type":        "string",
-				"description": "Executable to spawn. When `args` is omitted, the value is passed to `sh -c \"…\"` (shell form). When `args` is present, the value is exec'd directly with `args` as argv[1:] (no shell tokenization).",
-			},
-			"args": map[string]any{
-				"type":        "array",
-				"description": "Optional argv tail. Presence switches the spawn from shell form to exec form — author-controlled inputs are passed through verbatim with no shell expansion.",
-				"items":       map[string]any{"type": "string"},
-			},
-			"timeout": map[string]any{
-				"type":        "integer",
-				"description": "Per-hook timeout in seconds. Default 600. The runner SIGTERMs the process group on overrun, then SIGKILLs after a 2-second grace.",
-				"minimum":     1,
-			},
-			"shell": map[string]any{
-				"type":        "string",
-				"description": "Override the shell binary used for shell-form invocations. Defaults to `bash` if available on PATH, else `sh`.",
+				"required": []string{"path"},
 			},
 		},
-		"required":             []string{"command"},
-		"additionalProperties": false,
-	}
-	matcherGroupSchema := map[string]any{
-		"type":        "object",
-		"description": "A matcher group runs its inner `hooks` list sequentially when its matcher matches the triggering tool name.",
-		"properties": map[string]any{
-			"matcher": map[string]any{
-				"type":        "string",
-				"description": "Tool-name predicate. Empty / `*` matches every tool. A value composed only of `[A-Za-z0-9_, |]` is an exact name or `|`/`,`-separated list, compared case-insensitively. Anything else is a Go RE2 regex (case-sensitive unless `(?i)` is used). opencode tool names are lowercase (`bash`, `edit`, `write`, …); PascalCase matchers from Claude Code configs (`Bash`, `Edit|Write`) also match.",
-			},
-			"hooks": map[string]any{
-				"type":        "array",
-				"description": "Sequentially-run hook entries.",
-				"items":       hookEntrySchema,
-			},
-		},
-		"required":             []string{"hooks"},
-		"additionalProperties": false,
-	}
-	schema["properties"].(map[string]any)["hooks"] = map[string]any{
-		"type":        "object",
-		"description": "Claude-Code-compatible PreToolUse / PostToolUse subprocess hooks. Keys are event names (`PreToolUse`, `PostToolUse`); values are matcher groups whose entries fire as POSIX subprocesses receiving event JSON on stdin and returning decisions on stdout. The block is loaded once at process startup; restart required to pick up edits. Shape matches Claude Code's `settings.json` `hooks` block byte-for-byte for the events implemented here. See docs/hooks.md and openspec/specs/hook-runtime/spec.md.",
-		"properties": map[string]any{
-			"PreToolUse": map[string]any{
-				"type":        "array",
-				"description": "Fires before tool dispatch. Hooks can mutate `tool_input`, deny the call (`permissionDecision: \"deny\"` or exit 2), or override the standard permission gate (`permissionDecision: \"allow\"`).",
-				"items":       matcherGroupSchema,
-			},
-			"PostToolUse": map[string]any{
-				"type":        "array",
-				"description": "Fires after a tool's Run returns successfully. Hooks can replace `tool_output` (RTK-style log compaction) or append additional context to the next agent turn. Does NOT fire on tool error.",
-				"items":       matcherGroupSchema,
-			},
-		},
-		"additionalProperties": map[string]any{
-			"type":        "array",
-			"description": "Other Claude Code event names (SessionStart, UserPromptSubmit, Stop, etc.) load cleanly but do not yet fire in opencode v1.",
-			"items":       matcherGroupSchema,
-		},
 	}
+}
 
-	// Add permission configuration
-	schema["properties"].(map[string]any)["permission"] = map[string]any{
-		"type":        "object",
-		"description": "Global permission configuration. Keys are tool names (e.g., 'bash', 'edit', 'skill'). Values are either a simple action string or an object with glob-pattern keys.",
-		"properties": map[string]any{
-			"skill": map[string]any{
-				"type":        "object",
-				"description": "Skill permission patterns (supports wildcards like 'internal-*')",
-				"additionalProperties": map[string]any{
-					"type":        "string",
-					"description": "Permission action",
-					"enum":        []string{"allow", "deny", "ask"},
-				},
-			},
+// permissionRuleSchemas are the two forms of a permission rule: one action
+// for every use of the tool, or actions by glob pattern.
+func permissionRuleSchemas(simple string) []map[string]any {
+	action := []string{"allow", "deny", "ask"}
+	return []map[string]any{
+		{
+			"type":        "string",
+			"description": simple,
+			"enum":        action,
 		},
-		"additionalProperties": map[string]any{
-			"anyOf": []map[string]any{
-				{
-					"type":        "string",
-					"description": "Simple permission action for all uses of this tool",
-					"enum":        []string{"allow", "deny", "ask"},
-				},
-				{
-					"type":        "object",
-					"description": "Granular permission patterns (glob-pattern keys to action values)",
-					"additionalProperties": map[string]any{
-						"type": "string",
-						"enum": []string{"allow", "deny", "ask"},
-					},
-				},
+		{
+			"type":        "object",
+			"description": "Granular permission patterns (glob-pattern keys to action values)",
+			"additionalProperties": map[string]any{
+				"type": "string",
+				"enum": action,
 			},
 		},
 	}
-
-	return schema
 }
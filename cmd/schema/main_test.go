@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// TestEveryFieldDescribed fails when a config field has neither an
+// annotation nor a doc comment to describe it.
+func TestEveryFieldDescribed(t *testing.T) {
+	g := newGenerator()
+	schema := g.generate()
+
+	var missing []string
+	var walk func(node map[string]any, path string)
+	walk = func(node map[string]any, path string) {
+		if ref, ok := node["$ref"].(string); ok {
+			node = g.definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		}
+		props, _ := node["properties"].(map[string]any)
+		for name, p := range props {
+			p := p.(map[string]any)
+			if _, ok := p["description"]; !ok && p["$ref"] == nil {
+				missing = append(missing, joinPath(path, name))
+			}
+			walk(p, joinPath(path, name))
+		}
+		if ap, ok := node["additionalProperties"].(map[string]any); ok {
+			walk(ap, joinPath(path, "*"))
+		}
+		if items, ok := node["items"].(map[string]any); ok {
+			walk(items, path+"[]")
+		}
+	}
+	walk(schema, "")
+	slices.Sort(missing)
+	assert.Empty(t, missing, "describe these fields with a doc comment or an entry in annotations")
+}
+
+// TestAnnotationsMatchFields catches annotations left behind by a renamed
+// or removed field.
+func TestAnnotationsMatchFields(t *testing.T) {
+	g := newGenerator()
+	g.generate()
+
+	var stale []string
+	for path := range annotations {
+		if !g.used[path] {
+			stale = append(stale, path)
+		}
+	}
+	slices.Sort(stale)
+	assert.Empty(t, stale)
+}
+
+func TestSchemaFollowsConfig(t *testing.T) {
+	schema := generateSchema()
+	props := schema["properties"].(map[string]any)
+	definitions := schema["definitions"].(map[string]any)
+
+	permission := definitions["permission"].(map[string]any)["properties"].(map[string]any)
+	require.Contains(t, permission, "rules")
+	rules := permission["rules"].(map[string]any)["additionalProperties"].(map[string]any)
+	assert.Len(t, rules["anyOf"], 2)
+
+	assert.Contains(t, props, "router", "fields of types from other packages are walked")
+	assert.NotContains(t, props, "Policy", `json:"-" fields are left out`)
+
+	agents := props["agents"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#/definitions/agent"}, agents["additionalProperties"])
+	assert.Contains(t, agents["properties"], "coder")
+
+	agent := definitions["agent"].(map[string]any)["properties"].(map[string]any)
+	model := agent["model"].(map[string]any)
+	assert.Contains(t, model["enum"], "gpt-5")
+	assert.Equal(t, "Model ID for the agent", model["description"])
+
+	rulesByClass := props["routing"].(map[string]any)["properties"].(map[string]any)["rules"].(map[string]any)
+	assert.Equal(t, false, rulesByClass["additionalProperties"])
+	assert.Contains(t, rulesByClass["properties"], "code_edit")
+}
+
+func TestFieldDocFallback(t *testing.T) {
+	g := newGenerator()
+	field, _ := reflect.TypeOf(config.MCPServer{}).FieldByName("CallToolTimeoutSeconds")
+
+	doc := g.fieldDoc(reflect.TypeOf(config.MCPServer{}), field, "callToolTimeoutSeconds")
+	assert.True(t, strings.HasPrefix(doc, "callToolTimeoutSeconds overrides the default per-tool-call timeout"), doc)
+	assert.NotContains(t, doc, "\n")
+}
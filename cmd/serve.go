@@ -67,6 +67,7 @@ Authentication can be enabled by setting the OPENCODE_SERVER_PASSWORD environmen
 		debug, _ := cmd.Flags().GetBool("debug")
 		cwd, _ := cmd.Flags().GetString("cwd")
 		autoApprove, _ := cmd.Flags().GetBool("auto-approve")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
 		agentID, _ := cmd.Flags().GetString("agent")
 
 		if cwd != "" {
@@ -86,6 +87,9 @@ Authentication can be enabled by setting the OPENCODE_SERVER_PASSWORD environmen
 		if err != nil {
 			return err
 		}
+		if readOnly {
+			cfg.ReadOnly = true
+		}
 
 		// Headless mode: log to stderr so the user sees output.
 		level := slog.LevelInfo
@@ -119,6 +123,8 @@ Authentication can be enabled by setting the OPENCODE_SERVER_PASSWORD environmen
 			return err
 		}
 		defer application.Shutdown()
+		application.StartModelChecks(ctx)
+		application.StartCodeIndex(ctx)
 
 		// Pin the active agent if one was named on the command line. Without this,
 		// `serve` always boots with whatever agent app.New picks first; OpenWork
@@ -139,6 +145,11 @@ Authentication can be enabled by setting the OPENCODE_SERVER_PASSWORD environmen
 			enableAutoApprove(ctx, application)
 		}
 
+		// Drain the persistent run queue (POST /queue, `opencode queue
+		// submit`). Only serve dispatches, so CLI and TUI processes can
+		// enqueue against the same database without racing for runs.
+		application.StartRunQueue(ctx)
+
 		// Conditionally start the chat-bridge orchestrator. The bridge
 		// stays disabled unless an operator opted in by adding a
 		// `router` section to .opencode.json with at least one enabled
@@ -248,6 +259,7 @@ Authentication can be enabled by setting the OPENCODE_SERVER_PASSWORD environmen
 			Hostname:   hostname,
 			CORSOrigin: corsOrigin,
 		}
+		serverOpts.NoUI, _ = cmd.Flags().GetBool("no-ui")
 		if bridgeSvc != nil {
 			serverOpts.Bridge = bridgeSvc
 		}
@@ -556,6 +568,8 @@ func init() {
 	serveCmd.Flags().String("cors-origin", "", "Alias for --cors (deprecated)")
 	_ = serveCmd.Flags().MarkHidden("cors-origin")
 	serveCmd.Flags().Bool("auto-approve", false, "Auto-approve all permission requests (dangerous — no human in the loop)")
+	serveCmd.Flags().Bool("no-ui", false, "Do not serve the read-only web UI at /ui/")
+	serveCmd.Flags().Bool("read-only", false, "Disable every tool that changes files and only allow read-only bash commands")
 	serveCmd.Flags().BoolP("debug", "d", false, "Debug")
 	serveCmd.Flags().StringP("cwd", "c", "", "Current working directory")
 	serveCmd.Flags().StringP("agent", "a", "", "Agent ID to use (e.g. coder, hivemind, or a custom one from .opencode/agents/)")
@@ -0,0 +1,433 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/dataset"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/feedback"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/importer"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:     "session",
+	Aliases: []string{"sessions"},
+	Short:   "Export and import sessions",
+	Long: `Move sessions, or the changes they made, between machines or database
+backends.
+
+An archive holds a whole session tree — the root session, its flow steps,
+task and title sub-sessions, every message (including tool calls and tool
+results) and the file history recorded for them. Archives are plain JSON
+and do not depend on the backend they were exported from, so a session can
+be exported from SQLite and imported into MySQL or vice versa.`,
+	Example: `
+  # Export the files a session changed as a patch
+  opencode session diff 3f2a... > session.patch
+
+  # Export a session tree to a file
+  opencode session export 3f2a... -o session.json
+
+  # Build a fine-tuning dataset from last month's successful sessions
+  opencode session dataset --since 720h --label success -o train.jsonl
+
+  # Import it into the database configured for another project
+  opencode session import session.json -c ../other-project`,
+}
+
+var sessionExportCmd = &cobra.Command{
+	Use:   "export <session-id>",
+	Short: "Export a session tree as a JSON archive",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		sessions, closeDB, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		archive, err := sessions.Export(context.Background(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to export session %s: %w", args[0], err)
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(archive); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		if w != os.Stdout {
+			fmt.Fprintf(os.Stderr, "Exported %d sessions, %d messages, %d file versions to %s\n",
+				len(archive.Sessions), len(archive.Messages), len(archive.Files), output)
+		}
+		return nil
+	},
+}
+
+var sessionDiffCmd = &cobra.Command{
+	Use:   "diff <session-id>",
+	Short: "Export the file changes of a session tree as a patch",
+	Long: `Print the changes a session tree — the session, its subagents and flow
+steps — made to files, computed from the recorded file history rather than
+the working tree. The patch can be applied to another checkout, e.g. to
+bring changes made in a sandbox back to your machine.
+
+Formats:
+  patch   a single unified diff of the net changes (git apply, patch -p1)
+  series  one patch per prompt in git format-patch mbox format (git am)
+
+Paths inside the project directory are written relative to it.`,
+	Example: `
+  # Apply a session's changes to another checkout
+  opencode session diff 3f2a... > session.patch
+  git -C ../other-checkout apply session.patch
+
+  # Replay them as one commit per prompt
+  opencode sessions diff 3f2a... --format series | git am`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if format != "patch" && format != "series" {
+			return fmt.Errorf("unsupported format %q (want patch or series)", format)
+		}
+
+		conn, err := openSessionDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		q := db.NewQuerier(conn)
+		sessions := session.NewService(q, "")
+		files := history.NewService(q, conn)
+
+		ctx := context.Background()
+		sess, err := sessions.Get(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load session %s: %w", args[0], err)
+		}
+		rootID := sess.ID
+		if sess.RootSessionID != "" {
+			rootID = sess.RootSessionID
+		}
+		root, err := sessions.Get(ctx, rootID)
+		if err != nil {
+			return fmt.Errorf("failed to load root session %s: %w", rootID, err)
+		}
+		versions, err := files.ListBySessionTree(ctx, rootID)
+		if err != nil {
+			return fmt.Errorf("failed to load file history: %w", err)
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		workDir := config.WorkingDirectory()
+		if format == "patch" {
+			return history.WritePatch(w, history.Changes(versions), workDir)
+		}
+
+		msgs, err := message.NewService(q, conn).List(ctx, rootID)
+		if err != nil {
+			return fmt.Errorf("failed to load messages: %w", err)
+		}
+		var turns []history.Turn
+		for _, m := range msgs {
+			if m.Role == message.User && !m.Synthetic {
+				turns = append(turns, history.Turn{Subject: m.Content().Text, Start: m.CreatedAt})
+			}
+		}
+		// Flow sessions have no prompts of their own; their steps make up
+		// a single patch.
+		if len(turns) == 0 {
+			turns = []history.Turn{{Subject: root.Title, Start: root.CreatedAt}}
+		}
+		return history.WritePatchSeries(w, versions, turns, workDir)
+	},
+}
+
+var sessionDatasetCmd = &cobra.Command{
+	Use:   "dataset [session-id...]",
+	Short: "Export sessions as an OpenAI chat-format JSONL dataset",
+	Long: `Write sessions as a JSONL dataset in the OpenAI chat fine-tuning format,
+one example per session, for building evals or fine-tunes from real usage.
+
+Tool calls keep their structure: assistant messages carry tool_calls and
+each result follows as a tool message. Reasoning, attachments and subagent
+sessions are left out. Secrets are replaced with [REDACTED]: configured
+provider API keys, values of environment variables named like secrets
+(*KEY*, *TOKEN*, *SECRET*, *PASSWORD*) and common credential formats.
+
+Without session IDs every session of the project is considered. Filters:
+  --agent   sessions in which the agent made a request
+  --since   sessions created at or after a time, date or duration ago
+  --until   sessions created at or before a time, date or duration ago
+  --label   success (the last reply finished its turn and did not follow
+            a failed tool call) or failure; a /good or /bad rating of the
+            last reply takes precedence`,
+	Example: `
+  # Successful coder sessions of the last week, for an eval set
+  opencode session dataset --agent coder --since 168h --label success --metadata -o evals.jsonl
+
+  # Two sessions with a system prompt prepended
+  opencode session dataset 3f2a... 9c1d... --system "You are a coding agent." > train.jsonl`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		system, _ := cmd.Flags().GetString("system")
+		withMetadata, _ := cmd.Flags().GetBool("metadata")
+		labelFlag, _ := cmd.Flags().GetString("label")
+
+		var f dataset.Filter
+		f.Agent, _ = cmd.Flags().GetString("agent")
+		label, err := dataset.ParseLabel(labelFlag)
+		if err != nil {
+			return err
+		}
+		f.Label = label
+		now := time.Now()
+		for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+			value, _ := cmd.Flags().GetString(name)
+			if value == "" {
+				continue
+			}
+			t, err := parseTimeFlag(value, now)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", name, err)
+			}
+			*dst = t
+		}
+
+		conn, err := openSessionDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		q := db.NewQuerier(conn)
+		exporter := &dataset.Exporter{
+			Sessions: session.NewService(q, ""),
+			Messages: message.NewService(q, conn),
+			Feedback: feedback.NewService(q),
+			Redactor: dataset.NewRedactor(dataset.EnvironmentSecrets(config.Get())...),
+		}
+
+		ctx := context.Background()
+		ids := args
+		if len(ids) == 0 {
+			all, err := exporter.Sessions.List(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list sessions: %w", err)
+			}
+			// List is newest first; datasets read better in order.
+			for i := len(all) - 1; i >= 0; i-- {
+				ids = append(ids, all[i].ID)
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" && output != "-" {
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer file.Close()
+			w = file
+		}
+		stats, err := exporter.Export(ctx, w, ids, f, dataset.Options{System: system, Metadata: withMetadata})
+		if err != nil {
+			return fmt.Errorf("failed to export dataset: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d examples, skipped %d sessions without a prompt and a reply\n", stats.Examples, stats.Skipped)
+		return nil
+	},
+}
+
+var sessionImportCmd = &cobra.Command{
+	Use:   "import <archive.json>",
+	Short: "Import a session tree from a JSON archive",
+	Long: `Import a session tree from a JSON archive into the configured database.
+
+Session, message and file IDs are kept as-is; the import fails without
+changing anything if any of the sessions already exist.
+
+With --from, import transcripts written by another coding agent instead:
+
+  claude  Claude Code (~/.claude/projects/<project>/*.jsonl)
+  codex   Codex CLI (~/.codex/sessions/**/rollout-*.jsonl)
+
+Without file arguments every transcript the agent stored for the project
+directory is imported; transcripts imported before are skipped. Prompts,
+replies, reasoning, tool calls and tool results are converted on a
+best-effort basis.`,
+	Example: `
+  # Import all Claude Code sessions of the current project
+  opencode session import --from claude
+
+  # Import a single Codex CLI rollout
+  opencode session import --from codex ~/.codex/sessions/2026/10/01/rollout-....jsonl`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if from, _ := cmd.Flags().GetString("from"); from != "" {
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if from, _ := cmd.Flags().GetString("from"); from != "" {
+			return importTranscripts(cmd, from, args)
+		}
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open archive: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+		var archive session.Archive
+		if err := json.NewDecoder(r).Decode(&archive); err != nil {
+			return fmt.Errorf("failed to parse archive: %w", err)
+		}
+
+		sessions, closeDB, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		root, err := sessions.Import(context.Background(), archive)
+		if err != nil {
+			return fmt.Errorf("failed to import archive: %w", err)
+		}
+		fmt.Printf("Imported session %s (%q): %d sessions, %d messages, %d file versions\n",
+			root.ID, root.Title, len(archive.Sessions), len(archive.Messages), len(archive.Files))
+		return nil
+	},
+}
+
+// importTranscripts converts and imports another agent's transcripts:
+// the given files, or every transcript it stored for the project.
+func importTranscripts(cmd *cobra.Command, from string, paths []string) error {
+	source, err := importer.ParseSource(from)
+	if err != nil {
+		return err
+	}
+	sessions, closeDB, err := openSessionStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if len(paths) == 0 {
+		if paths, err = importer.Discover(source, config.WorkingDirectory()); err != nil {
+			return fmt.Errorf("failed to find %s transcripts: %w", source, err)
+		}
+		if len(paths) == 0 {
+			fmt.Printf("No %s transcripts found for %s\n", source, config.WorkingDirectory())
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	imported, skipped := 0, 0
+	for _, path := range paths {
+		archive, err := importer.Convert(source, path)
+		if errors.Is(err, importer.ErrEmptyTranscript) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		root, err := sessions.Import(ctx, archive)
+		if errors.Is(err, session.ErrSessionExists) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", path, err)
+		}
+		imported++
+		fmt.Printf("Imported session %s (%q): %d messages\n", root.ID, root.Title, len(archive.Messages))
+	}
+	fmt.Printf("Imported %d %s sessions, skipped %d empty or already imported\n", imported, source, skipped)
+	return nil
+}
+
+// openSessionStore loads the project config and connects to its session
+// database without bringing up agents, LSP clients or MCP servers.
+func openSessionStore(cmd *cobra.Command) (session.Service, func(), error) {
+	conn, err := openSessionDB(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session.NewService(db.NewQuerier(conn), ""), func() { _ = conn.Close() }, nil
+}
+
+// openSessionDB loads the project config and connects to its database.
+func openSessionDB(cmd *cobra.Command) (*sql.DB, error) {
+	cwd, _ := cmd.Flags().GetString("cwd")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		cwd = c
+	}
+	if _, err := config.Load(cwd, debug); err != nil {
+		return nil, err
+	}
+	return db.Connect()
+}
+
+func init() {
+	sessionCmd.PersistentFlags().StringP("cwd", "c", "", "Working directory for the project")
+	sessionCmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug logging")
+	sessionExportCmd.Flags().StringP("output", "o", "", "File to write the archive to (default: stdout)")
+	sessionImportCmd.Flags().String("from", "", "Import transcripts of another agent: claude or codex")
+	sessionDatasetCmd.Flags().StringP("output", "o", "", "File to write the dataset to (default: stdout)")
+	sessionDatasetCmd.Flags().String("agent", "", "Only sessions in which this agent made a request")
+	sessionDatasetCmd.Flags().String("since", "", "Only sessions created at or after this time")
+	sessionDatasetCmd.Flags().String("until", "", "Only sessions created at or before this time")
+	sessionDatasetCmd.Flags().String("label", "any", "Only sessions labelled success or failure")
+	sessionDatasetCmd.Flags().String("system", "", "System message to prepend to every example")
+	sessionDatasetCmd.Flags().Bool("metadata", false, "Add session metadata to every example (for evals)")
+	sessionDiffCmd.Flags().StringP("format", "f", "patch", "Output format: patch or series")
+	sessionDiffCmd.Flags().StringP("output", "o", "", "File to write the patch to (default: stdout)")
+
+	sessionCmd.AddCommand(sessionExportCmd, sessionImportCmd, sessionDiffCmd, sessionDatasetCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report token usage and cost from the project database",
+	Long: `Sum the usage of every model request made in the project: agent turns,
+subagent tasks, summaries and translations. Each request is recorded with
+its model, tokens, cost and duration, and the rows are kept when sessions
+are deleted.
+
+--by picks what to group by: day, model and agent, or a comma-separated
+combination of them. --since and --until take an RFC 3339 time, a date
+(2006-01-02) or a duration counted back from now (90m, 24h, 7d).`,
+	Example: `
+  # Daily spend of the last week
+  opencode usage --since 7d
+
+  # Which models the last month went to, per agent
+  opencode usage --since 30d --by model,agent`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, _ := cmd.Flags().GetString("by")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		var byDay, byModel, byAgent bool
+		for _, key := range strings.Split(by, ",") {
+			switch strings.TrimSpace(key) {
+			case "day":
+				byDay = true
+			case "model":
+				byModel = true
+			case "agent":
+				byAgent = true
+			case "":
+			default:
+				return fmt.Errorf("invalid --by %q: use day, model or agent", key)
+			}
+		}
+
+		var since, until time.Time
+		now := time.Now()
+		for name, dst := range map[string]*time.Time{"since": &since, "until": &until} {
+			value, _ := cmd.Flags().GetString(name)
+			if value == "" {
+				continue
+			}
+			t, err := parseTimeFlag(value, now)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", name, err)
+			}
+			*dst = t
+		}
+
+		sessions, closeDB, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+		requests, err := sessions.ListMessageUsage(context.Background(), since, until)
+		if err != nil {
+			return err
+		}
+		groups := session.GroupMessageUsage(requests, byDay, byModel, byAgent)
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(groups)
+		}
+
+		var header []string
+		if byDay {
+			header = append(header, "DAY")
+		}
+		if byModel {
+			header = append(header, "MODEL")
+		}
+		if byAgent {
+			header = append(header, "AGENT")
+		}
+		header = append(header, "REQUESTS", "INPUT", "OUTPUT", "CACHE READ", "CACHE WRITE", "TIME", "COST")
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		var total session.UsageGroup
+		for _, g := range groups {
+			var row []string
+			if byDay {
+				row = append(row, g.Day)
+			}
+			if byModel {
+				row = append(row, g.Model)
+			}
+			if byAgent {
+				row = append(row, g.Agent)
+			}
+			fmt.Fprintln(w, strings.Join(append(row, usageColumns(g)...), "\t"))
+
+			total.Requests += g.Requests
+			total.InputTokens += g.InputTokens
+			total.OutputTokens += g.OutputTokens
+			total.CacheReadTokens += g.CacheReadTokens
+			total.CacheCreationTokens += g.CacheCreationTokens
+			total.DurationMs += g.DurationMs
+			total.Cost += g.Cost
+		}
+		if len(groups) > 1 {
+			row := make([]string, len(header)-7)
+			if len(row) > 0 {
+				row[0] = "TOTAL"
+			}
+			fmt.Fprintln(w, strings.Join(append(row, usageColumns(total)...), "\t"))
+		}
+		return w.Flush()
+	},
+}
+
+// usageColumns formats the sums of one report row.
+func usageColumns(g session.UsageGroup) []string {
+	return []string{
+		fmt.Sprint(g.Requests),
+		fmt.Sprint(g.InputTokens),
+		fmt.Sprint(g.OutputTokens),
+		fmt.Sprint(g.CacheReadTokens),
+		fmt.Sprint(g.CacheCreationTokens),
+		(time.Duration(g.DurationMs) * time.Millisecond).Round(time.Second).String(),
+		fmt.Sprintf("$%.4f", g.Cost),
+	}
+}
+
+func init() {
+	usageCmd.Flags().StringP("cwd", "c", "", "Working directory for the project")
+	usageCmd.Flags().BoolP("debug", "d", false, "Enable debug logging")
+	usageCmd.Flags().String("since", "", "Only requests made at or after this time")
+	usageCmd.Flags().String("until", "", "Only requests made at or before this time")
+	usageCmd.Flags().String("by", "day", "Group by day, model and/or agent, comma-separated")
+	usageCmd.Flags().Bool("json", false, "Print the groups as JSON")
+
+	rootCmd.AddCommand(usageCmd)
+}
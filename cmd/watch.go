@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/flow"
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/watch"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Re-run a prompt, custom command or flow when files change",
+	Long: `Watch the project for file changes and re-trigger an agent run or a flow
+after a debounced quiet period.
+
+--command is resolved in this order:
+  1. A flow ID (see .opencode/flows) — the flow is re-run from scratch.
+  2. Anything else is sent to the active agent as a prompt. Slash commands
+     (e.g. "/user:fix-tests") are expanded the same way as with -p.
+
+Each agent run uses a fresh session. Runs never overlap: changes made while
+a run is in progress (including the agent's own edits) are coalesced into a
+single follow-up run.`,
+	Example: `
+  # Keep the tests green while editing Go sources
+  opencode watch --paths 'internal/**/*.go' --command "Run go test ./... and fix any failures"
+
+  # Regenerate docs through a custom command
+  opencode watch --paths 'src/**' --command /project:update-docs
+
+  # Re-run a flow with arguments
+  opencode watch --paths 'api/**/*.proto' --command gen-clients --arg target=go`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		debug, _ := cmd.Flags().GetBool("debug")
+		paths, _ := cmd.Flags().GetStringArray("paths")
+		command, _ := cmd.Flags().GetString("command")
+		debounce, _ := cmd.Flags().GetDuration("debounce")
+		agentID, _ := cmd.Flags().GetString("agent")
+		flowArgs, _ := cmd.Flags().GetStringArray("arg")
+		argsFile, _ := cmd.Flags().GetString("args-file")
+		initial, _ := cmd.Flags().GetBool("initial")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("--command is required")
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("--paths is required")
+		}
+
+		if cwd != "" {
+			if err := os.Chdir(cwd); err != nil {
+				return fmt.Errorf("failed to change directory: %w", err)
+			}
+		}
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+			cwd = c
+		}
+
+		if _, err := config.Load(cwd, debug); err != nil {
+			return err
+		}
+
+		level := slog.LevelInfo
+		if debug {
+			level = slog.LevelDebug
+		}
+		logging.SetupStderrLogging(level)
+
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		application, err := app.New(ctx, conn, nil, "")
+		if err != nil {
+			logging.Error("Failed to create app", "error", err)
+			return err
+		}
+		defer application.Shutdown()
+
+		if agentID != "" {
+			if err := application.SetActiveAgent(config.AgentName(agentID)); err != nil {
+				return fmt.Errorf("invalid agent: %w", err)
+			}
+		}
+
+		_, flowErr := flow.Get(command)
+		isFlow := flowErr == nil
+		if !isFlow && (len(flowArgs) > 0 || argsFile != "") {
+			return fmt.Errorf("--arg and --args-file are only valid when --command names a flow")
+		}
+
+		trigger := func(runCtx context.Context, changed []string) {
+			if len(changed) > 0 {
+				logging.Info("Watch: change detected, re-running", "command", command, "files", len(changed))
+			}
+			var runErr error
+			if isFlow {
+				runErr = runFlowNonInteractive(runCtx, application, command, "", "", true, flowArgs, argsFile, quiet)
+			} else {
+				runErr = runNonInteractive(runCtx, application, watchPrompt(command, changed), format.Text, quiet)
+			}
+			if runErr != nil && runCtx.Err() == nil {
+				logging.Error("Watch: run failed", "command", command, "error", runErr)
+			}
+		}
+
+		w, err := watch.New(cwd, paths, debounce, trigger)
+		if err != nil {
+			return err
+		}
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			sig := <-sigCh
+			logging.Info("Watch: received signal, shutting down", "signal", sig.String())
+			cancel()
+		}()
+
+		if initial {
+			trigger(ctx, nil)
+		}
+
+		logging.Info("Watching for changes", "paths", paths, "command", command, "flow", isFlow)
+		return w.Run(ctx)
+	},
+}
+
+// watchPrompt resolves slash commands in the configured prompt and, when
+// the run was caused by file changes, tells the agent which files changed.
+func watchPrompt(command string, changed []string) string {
+	prompt := command
+	if resolved, err := resolveSlashPrompt(command, ""); err == nil {
+		prompt = resolved
+	} else {
+		logging.Warn("Watch: failed to resolve slash command, sending as-is", "command", command, "error", err)
+	}
+	if len(changed) == 0 {
+		return prompt
+	}
+	return prompt + "\n\nFiles changed since the last run:\n- " + strings.Join(changed, "\n- ")
+}
+
+func init() {
+	watchCmd.Flags().StringP("cwd", "c", "", "Working directory for the project")
+	watchCmd.Flags().BoolP("debug", "d", false, "Enable debug logging")
+	watchCmd.Flags().StringArray("paths", nil, "Glob pattern (doublestar) of files to watch, relative to the working directory (repeatable)")
+	watchCmd.Flags().String("command", "", "Prompt, slash command or flow ID to run on change")
+	watchCmd.Flags().Duration("debounce", watch.DefaultDebounce, "Quiet period after the last change before re-running")
+	watchCmd.Flags().StringP("agent", "a", "", "Agent to run prompts with")
+	watchCmd.Flags().StringArray("arg", nil, "Flow argument as key=value (repeatable, flows only)")
+	watchCmd.Flags().String("args-file", "", "JSON file with flow arguments (flows only)")
+	watchCmd.Flags().Bool("initial", false, "Run once immediately on start, before any change")
+	watchCmd.Flags().BoolP("quiet", "q", false, "Hide spinner")
+
+	rootCmd.AddCommand(watchCmd)
+}
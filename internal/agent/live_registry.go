@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"sync/atomic"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// liveRegistry is the Registry GetRegistry hands out. It delegates to the
+// registry built from the current configuration, so the app, the agent
+// factory and the tools that hold it see RefreshRegistry's rebuilds.
+type liveRegistry struct {
+	cur atomic.Pointer[registry]
+}
+
+func (l *liveRegistry) Get(id string) (AgentInfo, bool) { return l.cur.Load().Get(id) }
+
+func (l *liveRegistry) List() []AgentInfo { return l.cur.Load().List() }
+
+func (l *liveRegistry) ListByMode(mode config.AgentMode) []AgentInfo {
+	return l.cur.Load().ListByMode(mode)
+}
+
+func (l *liveRegistry) EvaluatePermission(agentID, toolName, input string) permission.Action {
+	return l.cur.Load().EvaluatePermission(agentID, toolName, input)
+}
+
+func (l *liveRegistry) EvaluateReadPermission(agentID, toolName, input string) permission.Action {
+	return l.cur.Load().EvaluateReadPermission(agentID, toolName, input)
+}
+
+func (l *liveRegistry) ReadDenyPatterns(agentID, toolName string) []string {
+	return l.cur.Load().ReadDenyPatterns(agentID, toolName)
+}
+
+func (l *liveRegistry) IsToolEnabled(agentID, toolName string) bool {
+	return l.cur.Load().IsToolEnabled(agentID, toolName)
+}
+
+func (l *liveRegistry) IsToolExplicitlyEnabled(agentID, toolName string) bool {
+	return l.cur.Load().IsToolExplicitlyEnabled(agentID, toolName)
+}
+
+func (l *liveRegistry) HasTools(agentID string) bool { return l.cur.Load().HasTools(agentID) }
+
+func (l *liveRegistry) GlobalPermissions() map[string]any {
+	return l.cur.Load().GlobalPermissions()
+}
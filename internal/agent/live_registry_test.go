@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+func TestRefreshRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config.Reset()
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	t.Cleanup(config.Reset)
+	InvalidateRegistry()
+	t.Cleanup(InvalidateRegistry)
+
+	reg := GetRegistry()
+	if _, ok := reg.Get(config.AgentWorkhorse); !ok {
+		t.Fatal("workhorse should be registered")
+	}
+
+	cfg := config.Get()
+	agentCfg := cfg.Agents[config.AgentWorkhorse]
+	agentCfg.Disabled = true
+	cfg.Agents[config.AgentWorkhorse] = agentCfg
+	RefreshRegistry()
+
+	if _, ok := reg.Get(config.AgentWorkhorse); ok {
+		t.Error("a registry obtained before the refresh should follow the rebuilt one")
+	}
+}
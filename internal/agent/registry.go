@@ -5,6 +5,7 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -31,17 +32,29 @@ type AgentInfo struct {
 	Disabled        bool             `yaml:"disabled,omitempty"`
 	Color           string           `yaml:"color,omitempty"`
 	Model           string           `yaml:"model,omitempty"`
+	FallbackModels  []string         `yaml:"fallbackModels,omitempty"`
 	MaxTokens       int64            `yaml:"maxTokens,omitempty"`
 	MaxTurns        int              `yaml:"maxTurns,omitempty"`
 	ReasoningEffort string           `yaml:"reasoningEffort,omitempty"`
 	TaskBudget      int64            `yaml:"taskBudget,omitempty"`
-	Prompt          string           `yaml:"-"`
-	Skills          []string         `yaml:"skills,omitempty"`
-	Permission      map[string]any   `yaml:"permission,omitempty"`
-	Tools           map[string]bool  `yaml:"tools,omitempty"`
-	Output          *Output          `yaml:"output,omitempty"`
-	Location        string           `yaml:"-"`
-	ParallelToolUse *bool            `yaml:"parallelToolUse,omitempty"`
+	// ThinkingBudgetTokens caps the tokens spent reasoning per response;
+	// see config.Agent.
+	ThinkingBudgetTokens int64           `yaml:"thinkingBudgetTokens,omitempty"`
+	Prompt               string          `yaml:"-"`
+	Skills               []string        `yaml:"skills,omitempty"`
+	Permission           map[string]any  `yaml:"permission,omitempty"`
+	Tools                map[string]bool `yaml:"tools,omitempty"`
+	Output               *Output         `yaml:"output,omitempty"`
+	Location             string          `yaml:"-"`
+	ParallelToolUse      *bool           `yaml:"parallelToolUse,omitempty"`
+	// MaxParallelTasks caps the task tool calls of one turn that run at
+	// the same time; see ParallelTaskLimit.
+	MaxParallelTasks int  `yaml:"maxParallelTasks,omitempty"`
+	DryRun           bool `yaml:"dryRun,omitempty"`
+	// ContextPaths, when set, replaces the global contextPaths for this
+	// agent; Context snippets are appended after its context files.
+	ContextPaths []config.ContextPath `yaml:"contextPaths,omitempty"`
+	Context      []string             `yaml:"context,omitempty"`
 	// Interactive is set in-memory by AgentFactory.NewAgent when the
 	// agent is being constructed for a flow step with `interactive: true`.
 	// NOT persisted via YAML — agent-level interactiveness is derived
@@ -91,26 +104,39 @@ type Registry interface {
 type registry struct {
 	agents      map[string]AgentInfo
 	globalPerms map[string]any
+	// secretAllow opts secret files back in; see permission.IsSecretPath.
+	secretAllow []string
+	// policyRules are the organisation policy's deny rules, checked before
+	// any agent or config rule.
+	policyRules map[string]any
 }
 
 var (
-	registryInstance Registry
+	registryInstance *liveRegistry
 	registryOnce     sync.Once
 )
 
 func GetRegistry() Registry {
 	registryOnce.Do(func() {
-		registryInstance = newRegistry()
+		registryInstance = &liveRegistry{}
+		registryInstance.cur.Store(newRegistry())
 	})
 	return registryInstance
 }
 
+// RefreshRegistry rebuilds the registry from the current configuration,
+// e.g. after config.SwitchProfile. Registries already handed out by
+// GetRegistry follow the rebuilt one.
+func RefreshRegistry() {
+	GetRegistry().(*liveRegistry).cur.Store(newRegistry())
+}
+
 func InvalidateRegistry() {
 	registryOnce = sync.Once{}
 	registryInstance = nil
 }
 
-func newRegistry() Registry {
+func newRegistry() *registry {
 	cfg := config.Get()
 	agents := make(map[string]AgentInfo)
 
@@ -147,9 +173,19 @@ func newRegistry() Registry {
 		}
 		logging.Info("Agent discovered", args...)
 	}
+	var secretAllow []string
+	if cfg.Permission != nil {
+		secretAllow = cfg.Permission.AllowSecrets
+	}
+	var policyRules map[string]any
+	if cfg.Policy != nil {
+		policyRules = cfg.Policy.Permission
+	}
 	return &registry{
 		agents:      agents,
 		globalPerms: globalPerms,
+		secretAllow: secretAllow,
+		policyRules: policyRules,
 	}
 }
 
@@ -186,6 +222,9 @@ func (r *registry) ListByMode(mode config.AgentMode) []AgentInfo {
 }
 
 func (r *registry) EvaluatePermission(agentID, toolName, input string) permission.Action {
+	if r.touchesSecret(toolName, input) || permission.PolicyDenies(toolName, input, false, r.policyRules) {
+		return permission.ActionDeny
+	}
 	a, ok := r.agents[agentID]
 	if !ok {
 		return permission.EvaluateToolPermission(toolName, input, nil, r.globalPerms)
@@ -199,6 +238,9 @@ func (r *registry) EvaluatePermission(agentID, toolName, input string) permissio
 }
 
 func (r *registry) EvaluateReadPermission(agentID, toolName, input string) permission.Action {
+	if r.touchesSecret(toolName, input) || permission.PolicyDenies(toolName, input, true, r.policyRules) {
+		return permission.ActionDeny
+	}
 	a, ok := r.agents[agentID]
 	if !ok {
 		return permission.EvaluateReadToolPermission(toolName, input, nil, r.globalPerms)
@@ -212,11 +254,30 @@ func (r *registry) EvaluateReadPermission(agentID, toolName, input string) permi
 }
 
 func (r *registry) ReadDenyPatterns(agentID, toolName string) []string {
-	a, ok := r.agents[agentID]
-	if !ok {
-		return permission.ReadDenyPatterns(toolName, nil, r.globalPerms)
+	var patterns []string
+	if a, ok := r.agents[agentID]; ok {
+		patterns = permission.ReadDenyPatterns(toolName, a.Permission, r.globalPerms)
+	} else {
+		patterns = permission.ReadDenyPatterns(toolName, nil, r.globalPerms)
 	}
-	return permission.ReadDenyPatterns(toolName, a.Permission, r.globalPerms)
+	policy := permission.ReadDenyPatterns(toolName, nil, r.policyRules)
+	for _, p := range append(policy, permission.SecretDenyPatterns(r.secretAllow)...) {
+		if !slices.Contains(patterns, p) {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// touchesSecret applies the secret file guard, which runs ahead of the
+// configured rules: a broad "read": "allow" must not expose .env files.
+// Projects opt files back in with permission.allowSecrets.
+func (r *registry) touchesSecret(toolName, input string) bool {
+	if toolName == "bash" {
+		return permission.CommandTouchesSecret(input, r.secretAllow)
+	}
+	return slices.Contains(permission.SecretGuardedTools, toolName) &&
+		permission.IsSecretPath(input, r.secretAllow)
 }
 
 func (r *registry) IsToolEnabled(agentID, toolName string) bool {
@@ -273,6 +334,19 @@ func (info *AgentInfo) AllowsParallelToolUse() bool {
 	return *info.ParallelToolUse
 }
 
+// DefaultMaxParallelTasks is the number of task tool calls of one turn
+// that run at the same time when the agent does not set maxParallelTasks.
+const DefaultMaxParallelTasks = 4
+
+// ParallelTaskLimit returns how many task tool calls of one turn may run
+// their subagents at the same time.
+func (info *AgentInfo) ParallelTaskLimit() int {
+	if info.MaxParallelTasks <= 0 {
+		return DefaultMaxParallelTasks
+	}
+	return info.MaxParallelTasks
+}
+
 func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 	builtins := []AgentInfo{
 		{
@@ -289,13 +363,15 @@ func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 			Mode:        config.AgentModeAgent,
 			Native:      true,
 			Tools: map[string]bool{
-				"bash":      false,
-				"edit":      false,
-				"multiedit": false,
-				"write":     false,
-				"delete":    false,
-				"patch":     false,
-				"lsp":       false,
+				"bash":          false,
+				"run_task":      false,
+				"edit":          false,
+				"multiedit":     false,
+				"notebook_edit": false,
+				"write":         false,
+				"delete":        false,
+				"patch":         false,
+				"lsp":           false,
 				// Cron tools are default-deny across the fleet (see
 				// IsToolExplicitlyEnabled). Hivemind opts in here so the
 				// coordinator can schedule recurring tasks out of the box.
@@ -311,13 +387,16 @@ func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 			Mode:        config.AgentModeSubagent,
 			Native:      true,
 			Tools: map[string]bool{
-				"bash":      false,
-				"edit":      false,
-				"multiedit": false,
-				"write":     false,
-				"delete":    false,
-				"patch":     false,
-				"task":      false,
+				"bash":          false,
+				"run_task":      false,
+				"edit":          false,
+				"multiedit":     false,
+				"notebook_edit": false,
+				"write":         false,
+				"delete":        false,
+				"patch":         false,
+				"task":          false,
+				"todowrite":     false,
 			},
 		},
 		{
@@ -353,14 +432,27 @@ func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 				"*": false,
 			},
 		},
+		{
+			ID:          config.AgentTranslator,
+			Name:        "Translator Agent",
+			Description: "Translates final responses into the configured output language.",
+			Mode:        config.AgentModeSubagent,
+			Native:      true,
+			Hidden:      true,
+			Tools: map[string]bool{
+				"*": false,
+			},
+		},
 	}
 
 	for _, b := range builtins {
 		if agentCfg, ok := cfg.Agents[b.ID]; ok {
 			b.Model = string(agentCfg.Model)
+			b.FallbackModels = modelIDStrings(agentCfg.FallbackModels)
 			b.MaxTokens = agentCfg.MaxTokens
 			b.ReasoningEffort = agentCfg.ReasoningEffort
 			b.TaskBudget = agentCfg.TaskBudget
+			b.ThinkingBudgetTokens = agentCfg.ThinkingBudgetTokens
 		}
 		agents[b.ID] = b
 	}
@@ -417,6 +509,9 @@ func applyConfigOverrides(agents map[string]AgentInfo, cfg *config.Config) {
 		if agentCfg.Model != "" {
 			existing.Model = string(agentCfg.Model)
 		}
+		if len(agentCfg.FallbackModels) > 0 {
+			existing.FallbackModels = modelIDStrings(agentCfg.FallbackModels)
+		}
 		if agentCfg.MaxTokens > 0 {
 			existing.MaxTokens = agentCfg.MaxTokens
 		}
@@ -429,6 +524,9 @@ func applyConfigOverrides(agents map[string]AgentInfo, cfg *config.Config) {
 		if agentCfg.TaskBudget > 0 {
 			existing.TaskBudget = agentCfg.TaskBudget
 		}
+		if agentCfg.ThinkingBudgetTokens > 0 {
+			existing.ThinkingBudgetTokens = agentCfg.ThinkingBudgetTokens
+		}
 		if agentCfg.Name != "" {
 			existing.Name = agentCfg.Name
 		}
@@ -450,6 +548,9 @@ func applyConfigOverrides(agents map[string]AgentInfo, cfg *config.Config) {
 		if agentCfg.Disabled {
 			existing.Disabled = true
 		}
+		if agentCfg.DryRun {
+			existing.DryRun = true
+		}
 		if agentCfg.Permission != nil {
 			existing.Permission = mergePermissions(existing.Permission, agentCfg.Permission)
 		}
@@ -468,14 +569,34 @@ func applyConfigOverrides(agents map[string]AgentInfo, cfg *config.Config) {
 		if agentCfg.ParallelToolUse != nil {
 			existing.ParallelToolUse = agentCfg.ParallelToolUse
 		}
+		if agentCfg.MaxParallelTasks > 0 {
+			existing.MaxParallelTasks = agentCfg.MaxParallelTasks
+		}
 		if agentCfg.Skills != nil {
 			existing.Skills = deduplicateSkills(agentCfg.Skills, name)
 		}
+		if agentCfg.ContextPaths != nil {
+			existing.ContextPaths = agentCfg.ContextPaths
+		}
+		if agentCfg.Context != nil {
+			existing.Context = agentCfg.Context
+		}
 
 		agents[name] = existing
 	}
 }
 
+func modelIDStrings[T ~string](ids []T) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = string(id)
+	}
+	return out
+}
+
 func mergePermissions(base, overlay map[string]any) map[string]any {
 	if base == nil {
 		return overlay
@@ -505,12 +626,18 @@ func mergeMarkdownIntoExisting(existing, md *AgentInfo) {
 	if md.Model != "" {
 		existing.Model = md.Model
 	}
+	if len(md.FallbackModels) > 0 {
+		existing.FallbackModels = md.FallbackModels
+	}
 	if md.MaxTurns > 0 {
 		existing.MaxTurns = md.MaxTurns
 	}
 	if md.TaskBudget > 0 {
 		existing.TaskBudget = md.TaskBudget
 	}
+	if md.ThinkingBudgetTokens > 0 {
+		existing.ThinkingBudgetTokens = md.ThinkingBudgetTokens
+	}
 	if md.Prompt != "" {
 		existing.Prompt = md.Prompt
 	}
@@ -535,12 +662,24 @@ func mergeMarkdownIntoExisting(existing, md *AgentInfo) {
 	if md.Disabled {
 		existing.Disabled = true
 	}
+	if md.DryRun {
+		existing.DryRun = true
+	}
 	if md.ParallelToolUse != nil {
 		existing.ParallelToolUse = md.ParallelToolUse
 	}
+	if md.MaxParallelTasks > 0 {
+		existing.MaxParallelTasks = md.MaxParallelTasks
+	}
 	if md.Skills != nil {
 		existing.Skills = deduplicateSkills(md.Skills, existing.ID)
 	}
+	if md.ContextPaths != nil {
+		existing.ContextPaths = md.ContextPaths
+	}
+	if md.Context != nil {
+		existing.Context = md.Context
+	}
 	existing.Location = md.Location
 }
 
@@ -3,6 +3,7 @@ package agent
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/opencode-ai/opencode/internal/config"
@@ -529,6 +530,38 @@ You are a domain expert.
 	}
 }
 
+func TestParseAgentMarkdownWithContext(t *testing.T) {
+	dir := t.TempDir()
+	md := `---
+description: Reviewer
+mode: subagent
+contextPaths:
+  - REVIEW.md
+  - path: docs/review/
+    maxTokens: 2000
+context:
+  - Flag every TODO.
+---
+
+You review code.
+`
+	path := filepath.Join(dir, "reviewer.md")
+	os.WriteFile(path, []byte(md), 0o644)
+
+	agent, err := parseAgentMarkdown(path)
+	if err != nil {
+		t.Fatalf("parseAgentMarkdown() error = %v", err)
+	}
+
+	want := []config.ContextPath{{Path: "REVIEW.md"}, {Path: "docs/review/", MaxTokens: 2000}}
+	if !slices.Equal(agent.ContextPaths, want) {
+		t.Errorf("ContextPaths = %+v, want %+v", agent.ContextPaths, want)
+	}
+	if len(agent.Context) != 1 || agent.Context[0] != "Flag every TODO." {
+		t.Errorf("Context = %q", agent.Context)
+	}
+}
+
 func TestMergeMarkdownSkillsReplace(t *testing.T) {
 	existing := AgentInfo{
 		ID:     "myagent",
@@ -741,3 +774,73 @@ func containsAt(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRegistrySecretGuard(t *testing.T) {
+	r := &registry{
+		agents: map[string]AgentInfo{
+			"coder": {
+				ID:         "coder",
+				Mode:       config.AgentModeAgent,
+				Permission: map[string]any{"read": "allow", "edit": "allow", "bash": "allow"},
+			},
+		},
+	}
+
+	if got := r.EvaluateReadPermission("coder", "read", "/repo/.env"); got != permission.ActionDeny {
+		t.Errorf("reading .env should be denied despite read=allow, got %v", got)
+	}
+	if got := r.EvaluatePermission("coder", "edit", "/repo/certs/server.pem"); got != permission.ActionDeny {
+		t.Errorf("editing a .pem should be denied, got %v", got)
+	}
+	if got := r.EvaluatePermission("coder", "bash", "cat .env | grep KEY"); got != permission.ActionDeny {
+		t.Errorf("bash reading .env should be denied, got %v", got)
+	}
+	if got := r.EvaluateReadPermission("coder", "read", "/repo/.env.example"); got != permission.ActionAllow {
+		t.Errorf(".env.example should stay readable, got %v", got)
+	}
+	if got := r.ReadDenyPatterns("coder", "grep"); !slices.Contains(got, ".env") {
+		t.Errorf("grep deny patterns should exclude .env, got %v", got)
+	}
+
+	r.secretAllow = []string{".env"}
+	if got := r.EvaluateReadPermission("coder", "read", "/repo/.env"); got != permission.ActionAllow {
+		t.Errorf("opted-in .env should fall through to the read rule, got %v", got)
+	}
+	if got := r.ReadDenyPatterns("coder", "grep"); slices.Contains(got, ".env") {
+		t.Errorf("opted-in .env should not be excluded from grep, got %v", got)
+	}
+}
+
+func TestRegistryPolicyRules(t *testing.T) {
+	r := &registry{
+		agents: map[string]AgentInfo{
+			"coder": {
+				ID:         "coder",
+				Mode:       config.AgentModeAgent,
+				Permission: map[string]any{"read": "allow", "bash": "allow"},
+			},
+		},
+		globalPerms: map[string]any{"webfetch": "allow"},
+		policyRules: map[string]any{
+			"bash":     map[string]any{"curl *": "deny"},
+			"read":     map[string]any{"/secrets/*": "deny"},
+			"webfetch": "deny",
+		},
+	}
+
+	if got := r.EvaluatePermission("coder", "bash", "curl example.com"); got != permission.ActionDeny {
+		t.Errorf("policy should deny curl despite bash=allow, got %v", got)
+	}
+	if got := r.EvaluatePermission("coder", "bash", "git status"); got != permission.ActionAllow {
+		t.Errorf("commands the policy does not mention should follow the agent rule, got %v", got)
+	}
+	if got := r.EvaluatePermission("unknown", "webfetch", "https://example.com"); got != permission.ActionDeny {
+		t.Errorf("policy should deny webfetch despite the global allow, got %v", got)
+	}
+	if got := r.EvaluateReadPermission("coder", "grep", "/secrets/token"); got != permission.ActionDeny {
+		t.Errorf("policy read rules should cover grep, got %v", got)
+	}
+	if got := r.ReadDenyPatterns("coder", "grep"); !slices.Contains(got, "/secrets/*") {
+		t.Errorf("grep deny patterns should include the policy's, got %v", got)
+	}
+}
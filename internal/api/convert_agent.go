@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+)
+
+// ConvertAgentEvent converts an agent.AgentEvent published by agentID to
+// the API representation. The session ID falls back to the message's
+// session for response events that don't carry one explicitly.
+func ConvertAgentEvent(agentID config.AgentName, ev agent.AgentEvent) APIAgentEvent {
+	out := APIAgentEvent{
+		SessionID:  ev.SessionID,
+		Agent:      agentID,
+		Type:       string(ev.Type),
+		MessageID:  ev.Message.ID,
+		Progress:   ev.Progress,
+		Done:       ev.Done,
+		FlowStepID: ev.FlowStepID,
+	}
+	if out.SessionID == "" {
+		out.SessionID = ev.Message.SessionID
+	}
+	if ev.Error != nil {
+		out.Error = ev.Error.Error()
+	}
+	if ev.StructOutput != nil {
+		out.StructOutput = ev.StructOutput.Content
+	}
+	return out
+}
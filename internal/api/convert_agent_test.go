@@ -0,0 +1,36 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func TestConvertAgentEvent_ResponseFallsBackToMessageSession(t *testing.T) {
+	ev := agent.AgentEvent{
+		Type:         agent.AgentEventTypeResponse,
+		Message:      message.Message{ID: "msg-1", SessionID: "ses-1"},
+		StructOutput: &message.ToolResult{Content: `{"ok":true}`},
+		Done:         true,
+	}
+	got := ConvertAgentEvent("coder", ev)
+	if got.SessionID != "ses-1" || got.MessageID != "msg-1" || got.Agent != "coder" {
+		t.Fatalf("unexpected identity fields: %+v", got)
+	}
+	if got.Type != "response" || !got.Done || got.StructOutput != `{"ok":true}` {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+func TestConvertAgentEvent_Error(t *testing.T) {
+	got := ConvertAgentEvent("coder", agent.AgentEvent{
+		Type:      agent.AgentEventTypeError,
+		SessionID: "ses-2",
+		Error:     errors.New("boom"),
+	})
+	if got.SessionID != "ses-2" || got.Error != "boom" || got.Type != "error" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
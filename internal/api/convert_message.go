@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 
 	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/message"
@@ -32,6 +33,7 @@ func ConvertMessageInfo(msg message.Message) APIMessageInfo {
 			Created: msg.CreatedAt,
 			Updated: msg.UpdatedAt,
 		},
+		Pinned: msg.IsPinned(),
 	}
 }
 
@@ -78,6 +80,13 @@ func convertParts(messageID, sessionID string, parts []message.ContentPart, resu
 		}
 	}
 
+	var citations []APICitation
+	for _, part := range parts {
+		if c, ok := part.(message.Citations); ok {
+			citations = convertCitations(c.Items)
+		}
+	}
+
 	apiParts := make([]APIPart, 0, len(parts))
 	partIndex := 0
 
@@ -88,9 +97,10 @@ func convertParts(messageID, sessionID string, parts []message.ContentPart, resu
 				continue
 			}
 			apiParts = append(apiParts, APIPart{
-				ID:   fmt.Sprintf("part-%d", partIndex),
-				Type: "text",
-				Text: p.Text,
+				ID:        fmt.Sprintf("part-%d", partIndex),
+				Type:      "text",
+				Text:      p.Text,
+				Citations: citations,
 			})
 			partIndex++
 
@@ -125,6 +135,14 @@ func convertParts(messageID, sessionID string, parts []message.ContentPart, resu
 			// Finish parts are internal-only metadata; not exposed via the API.
 			continue
 
+		case message.Citations:
+			// Attached to the text part above rather than emitted on their own.
+			continue
+
+		case message.Pin:
+			// Reported as APIMessage.Pinned.
+			continue
+
 		case message.BinaryContent:
 			apiParts = append(apiParts, APIPart{
 				ID:   fmt.Sprintf("part-%d", partIndex),
@@ -139,6 +157,12 @@ func convertParts(messageID, sessionID string, parts []message.ContentPart, resu
 				Text: p.URL,
 			})
 			partIndex++
+
+		case message.ImageContent:
+			apiPart := convertImage(p)
+			apiPart.ID = fmt.Sprintf("part-%d", partIndex)
+			apiParts = append(apiParts, apiPart)
+			partIndex++
 		}
 	}
 
@@ -151,6 +175,22 @@ func convertParts(messageID, sessionID string, parts []message.ContentPart, resu
 	return apiParts
 }
 
+func convertCitations(items []message.Citation) []APICitation {
+	out := make([]APICitation, 0, len(items))
+	for _, c := range items {
+		out = append(out, APICitation{
+			Start:     c.Start,
+			End:       c.End,
+			CallID:    c.ToolCallID,
+			Tool:      c.ToolName,
+			Path:      c.Path,
+			StartLine: c.StartLine,
+			EndLine:   c.EndLine,
+		})
+	}
+	return out
+}
+
 // convertToolCall creates an APIPart for a ToolCall, merging in the ToolResult
 // from resultMap if one exists for this call ID.
 func convertToolCall(tc message.ToolCall, resultMap map[string]message.ToolResult, index int) APIPart {
@@ -303,10 +343,25 @@ func ConvertPart(messageID, sessionID string, part message.ContentPart) APIPart
 			CallID:    p.ToolCallID,
 			State:     state,
 		}
+	case message.ImageContent:
+		apiPart := convertImage(p)
+		apiPart.ID = "image-" + filepath.Base(p.Path)
+		apiPart.MessageID = messageID
+		apiPart.SessionID = sessionID
+		return apiPart
 	}
 	return APIPart{MessageID: messageID, SessionID: sessionID}
 }
 
+// convertImage creates the file part of an image the model produced.
+func convertImage(img message.ImageContent) APIPart {
+	return APIPart{
+		Type: "file",
+		URL:  "file://" + filepath.ToSlash(img.Path),
+		Mime: img.MIMEType,
+	}
+}
+
 // buildToolResultMap builds a lookup from tool call ID to ToolResult across
 // all messages. If multiple results exist for the same call ID, the last one wins.
 func buildToolResultMap(msgs []message.Message) map[string]message.ToolResult {
@@ -16,6 +16,8 @@ var providerDisplayNames = map[models.ModelProvider]string{
 	models.ProviderBedrock:     "AWS Bedrock",
 	models.ProviderYandexCloud: "Yandex Cloud",
 	models.ProviderLocal:       "Local",
+	models.ProviderOllama:      "Ollama",
+	models.ProviderCopilot:     "GitHub Copilot",
 }
 
 // providerEnvKeys maps providers to the environment variable names used
@@ -28,6 +30,7 @@ var providerEnvKeys = map[models.ModelProvider][]string{
 	models.ProviderBedrock:     {"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION"},
 	models.ProviderYandexCloud: {"YANDEXCLOUD_API_KEY", "YANDEXCLOUD_FOLDER_ID"},
 	models.ProviderLocal:       {"LOCAL_ENDPOINT"},
+	models.ProviderOllama:      {"OLLAMA_HOST"},
 }
 
 // ConvertProviders groups all supported models by their provider and returns
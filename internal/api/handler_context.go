@@ -0,0 +1,59 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+)
+
+// APIContextExcludeRequest is the request body for excluding context items
+// from a session's next turn.
+type APIContextExcludeRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// handleSessionContext lists what the session's next provider request will
+// contain, with estimated token costs.
+func (s *Server) handleSessionContext(w http.ResponseWriter, r *http.Request) {
+	activeAgent := s.app.ActiveAgent()
+	if activeAgent == nil {
+		writeError(w, http.StatusInternalServerError, "no active agent available")
+		return
+	}
+	report, err := activeAgent.InspectContext(r.Context(), r.PathValue("sessionID"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to inspect context")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleSessionContextExclude sets the items the session's next turn leaves
+// out, replacing earlier exclusions, and returns the updated listing.
+func (s *Server) handleSessionContextExclude(w http.ResponseWriter, r *http.Request) {
+	var req APIContextExcludeRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	activeAgent := s.app.ActiveAgent()
+	if activeAgent == nil {
+		writeError(w, http.StatusInternalServerError, "no active agent available")
+		return
+	}
+	if err := activeAgent.ExcludeFromNextTurn(r.PathValue("sessionID"), req.IDs); err != nil {
+		if errors.Is(err, agent.ErrContextItemRequired) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to exclude context items")
+		return
+	}
+	s.handleSessionContext(w, r)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/logging"
@@ -72,8 +73,8 @@ func (s *Server) handleGlobalEvent(w http.ResponseWriter, r *http.Request) {
 }
 
 // streamEvents is the shared implementation for SSE event streaming endpoints.
-// It subscribes to the message, session, and permission brokers and fans in
-// events from all of them into a single SSE stream.
+// It subscribes to the message, session, permission and agent brokers and
+// fans in events from all of them into a single SSE stream.
 func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -104,7 +105,41 @@ func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
 		flowCh = s.flowRunner.subscribeFlowEvents(ctx)
 	}
 
-	streamLoop(ctx, w, flusher, msgCh, partCh, sesCh, permCh, questionCh, flowCh)
+	agentCh := s.subscribeAgentEvents(ctx)
+
+	streamLoop(ctx, w, flusher, msgCh, partCh, sesCh, permCh, questionCh, flowCh, agentCh)
+}
+
+// subscribeAgentEvents fans in the event brokers of every primary agent so
+// clients can observe run completion, errors and summarization progress
+// without polling /session/status. The returned channel closes once ctx is
+// done and every agent subscription has drained. It is nil when there is
+// no agent to subscribe to, so streamLoop never reads it as closed.
+func (s *Server) subscribeAgentEvents(ctx context.Context) <-chan APIAgentEvent {
+	if len(s.app.PrimaryAgents) == 0 {
+		return nil
+	}
+	out := make(chan APIAgentEvent, 64)
+	var wg sync.WaitGroup
+	for id, a := range s.app.PrimaryAgents {
+		ch := a.Subscribe(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ev := range ch {
+				select {
+				case out <- ConvertAgentEvent(id, ev.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
 }
 
 // streamLoop runs the fan-in select loop that reads from the broker channels
@@ -119,6 +154,7 @@ func streamLoop(
 	permCh <-chan pubsub.Event[permission.PermissionRequest],
 	questionCh <-chan pubsub.Event[question.Request],
 	flowCh <-chan pubsub.Event[FlowEvent],
+	agentCh <-chan APIAgentEvent,
 ) {
 	heartbeat := time.NewTicker(30 * time.Second)
 	defer heartbeat.Stop()
@@ -185,6 +221,14 @@ func streamLoop(
 				return
 			}
 
+		case event, ok := <-agentCh:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, flusher, "agent."+event.Type, event); err != nil {
+				return
+			}
+
 		case <-heartbeat.C:
 			if _, err := fmt.Fprintf(w, ": heartbeat\n\n"); err != nil {
 				return
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/app"
+)
+
+func TestSubscribeAgentEventsWithoutAgents(t *testing.T) {
+	s := &Server{app: &app.App{}}
+	if ch := s.subscribeAgentEvents(t.Context()); ch != nil {
+		t.Fatal("expected a nil channel so the event stream doesn't end at once")
+	}
+}
@@ -27,6 +27,9 @@ type flowRunStatus string
 const (
 	flowRunRunning         flowRunStatus = "running"
 	flowRunWaitingForInput flowRunStatus = "waiting_for_input"
+	// flowRunWaitingApproval marks a run paused at a `gate: manual` step
+	// until POST /flow/approve records a decision.
+	flowRunWaitingApproval flowRunStatus = "waiting_approval"
 	flowRunCompleted       flowRunStatus = "completed"
 	flowRunFailed          flowRunStatus = "failed"
 	// flowRunPostponed marks a run that terminated because the last
@@ -48,6 +51,7 @@ const (
 	evFlowStepFailed      flowEventType = "flow.step.failed"
 	evFlowStepPostponed   flowEventType = "flow.step.postponed"
 	evFlowWaitingForInput flowEventType = "flow.waiting_for_input"
+	evFlowWaitingApproval flowEventType = "flow.waiting_approval"
 	evFlowCompleted       flowEventType = "flow.completed"
 	evFlowFailed          flowEventType = "flow.failed"
 	evFlowPostponed       flowEventType = "flow.postponed"
@@ -188,6 +192,12 @@ type flowRunState struct {
 	lastStepPostponed bool
 }
 
+// inFlight reports whether the run can still make progress. A run paused
+// at an approval gate still holds the single-run slot and can be aborted.
+func (rs *flowRunState) inFlight() bool {
+	return rs.Status == flowRunRunning || rs.Status == flowRunWaitingApproval
+}
+
 // flowRunnerSingleton is the process-wide tracker installed on
 // api.Server at construction. Tests can swap it out via a Server hook.
 //
@@ -288,6 +298,55 @@ func (s *Server) handleFlowAbort(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"aborted": true})
 }
 
+// handleFlowApprove records an approve/reject decision for a step held
+// at a `gate: manual` gate. sessionID identifies the step; it defaults
+// to the current run's waiting step when omitted.
+func (s *Server) handleFlowApprove(w http.ResponseWriter, r *http.Request) {
+	if s.flowRunner == nil {
+		writeError(w, http.StatusServiceUnavailable, "flow runner not configured")
+		return
+	}
+	var body struct {
+		SessionID string `json:"sessionID"`
+		Approve   *bool  `json:"approve"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.Approve == nil {
+		writeError(w, http.StatusBadRequest, "approve is required")
+		return
+	}
+	if body.SessionID == "" {
+		if snap := s.flowRunner.Snapshot(); snap != nil && snap.Status == flowRunWaitingApproval && snap.CurrentStep != nil {
+			body.SessionID = snap.CurrentStep.SessionID
+		}
+	}
+	if body.SessionID == "" {
+		writeError(w, http.StatusConflict, "no flow step is waiting for approval")
+		return
+	}
+	svc := s.flowRunner.app.FlowsService()
+	if svc == nil {
+		writeError(w, http.StatusServiceUnavailable, "flow service not configured")
+		return
+	}
+	st, err := svc.ResolveGate(r.Context(), body.SessionID, *body.Approve)
+	switch {
+	case errors.Is(err, flow.ErrGateNotPending):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{
+			"sessionID": st.SessionID,
+			"stepID":    st.StepID,
+			"approved":  *body.Approve,
+		})
+	}
+}
+
 // errFlowAlreadyRunning is the sentinel returned by Start when another
 // run is in flight.
 var errFlowAlreadyRunning = errors.New("flow: another run is already in flight")
@@ -314,7 +373,7 @@ func (fr *flowRunner) Start(parent context.Context, flowID string, args map[stri
 		}
 	}
 	fr.mu.Lock()
-	if fr.currentRun != nil && fr.currentRun.Status == flowRunRunning {
+	if fr.currentRun != nil && fr.currentRun.inFlight() {
 		fr.mu.Unlock()
 		return StartResult{}, errFlowAlreadyRunning
 	}
@@ -423,7 +482,7 @@ func (fr *flowRunner) observeStep(state *flowRunState, st *flow.FlowState) {
 		// either it's a fresh step or a resume of the postponed one.
 		// Either way the run can no longer terminate as postponed.
 		state.lastStepPostponed = false
-		if state.Status == flowRunWaitingForInput {
+		if state.Status == flowRunWaitingForInput || state.Status == flowRunWaitingApproval {
 			state.Status = flowRunRunning
 		}
 		fr.publishEvent(state, FlowEvent{
@@ -457,6 +516,21 @@ func (fr *flowRunner) observeStep(state *flowRunState, st *flow.FlowState) {
 			Cost:           cost,
 			ContextSize:    contextSize,
 		})
+	case flow.FlowStatusWaitingApproval:
+		// A `gate: manual` step is held until POST /flow/approve. The
+		// step is current but hasn't started its agent yet.
+		state.currentStep = &rec
+		state.Status = flowRunWaitingApproval
+		fr.publishEvent(state, FlowEvent{
+			Type:        evFlowWaitingApproval,
+			RunID:       state.RunID,
+			FlowID:      state.FlowID,
+			StepID:      rec.ID,
+			SessionID:   rec.SessionID,
+			Iteration:   st.Iteration,
+			Cost:        cost,
+			ContextSize: contextSize,
+		})
 	case flow.FlowStatusCompleted:
 		rec.CompletedAt = now
 		state.completedSteps = append(state.completedSteps, rec)
@@ -632,7 +706,7 @@ func (fr *flowRunner) finish(state *flowRunState, status flowRunStatus, errMsg s
 func (fr *flowRunner) Abort() bool {
 	fr.mu.Lock()
 	defer fr.mu.Unlock()
-	if fr.currentRun == nil || fr.currentRun.Status != flowRunRunning {
+	if fr.currentRun == nil || !fr.currentRun.inFlight() {
 		return false
 	}
 	if fr.currentRun.cancel != nil {
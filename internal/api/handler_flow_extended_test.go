@@ -92,6 +92,33 @@ func (s *stubSessions) ListOldSessions(context.Context, string) ([]session.Sessi
 func (s *stubSessions) CleanupOldSessions(context.Context, string) (int, error) {
 	return 0, nil
 }
+func (s *stubSessions) TreeUsage(context.Context, string) (session.Usage, error) {
+	return session.Usage{}, nil
+}
+func (s *stubSessions) ProjectUsage(context.Context) (session.Usage, error) {
+	return session.Usage{}, nil
+}
+func (s *stubSessions) RecordUsage(context.Context, string, ...session.UsageEntry) error {
+	return nil
+}
+func (s *stubSessions) UsageBreakdown(context.Context, string) ([]session.UsageEntry, error) {
+	return nil, nil
+}
+func (s *stubSessions) RecordMessageUsage(context.Context, session.MessageUsage) error {
+	return nil
+}
+func (s *stubSessions) ListMessageUsage(context.Context, time.Time, time.Time) ([]session.MessageUsage, error) {
+	return nil, nil
+}
+func (s *stubSessions) Export(context.Context, string) (session.Archive, error) {
+	return session.Archive{}, nil
+}
+func (s *stubSessions) Import(context.Context, session.Archive) (session.Session, error) {
+	return session.Session{}, nil
+}
+func (s *stubSessions) Fork(context.Context, string, string) (session.Session, error) {
+	return session.Session{}, nil
+}
 func (s *stubSessions) Subscribe(ctx context.Context) <-chan pubsub.Event[session.Session] {
 	return s.Broker.Subscribe(ctx)
 }
@@ -26,6 +26,15 @@ type stubFlowService struct {
 	runErr    error
 	mu        sync.Mutex
 	runs      int
+	lastArgs  map[string]any
+
+	gateErr       error
+	gateDecisions []gateDecision
+}
+
+type gateDecision struct {
+	sessionID string
+	approved  bool
 }
 
 func newStubFlowService(steps []flow.FlowState) *stubFlowService {
@@ -35,12 +44,13 @@ func newStubFlowService(steps []flow.FlowState) *stubFlowService {
 	}
 }
 
-func (s *stubFlowService) Run(ctx context.Context, _ string, flowID string, _ map[string]any, _ bool) (<-chan agentpkg.AgentEvent, <-chan *flow.FlowState, error) {
+func (s *stubFlowService) Run(ctx context.Context, _ string, flowID string, args map[string]any, _ bool) (<-chan agentpkg.AgentEvent, <-chan *flow.FlowState, error) {
 	if s.runErr != nil {
 		return nil, nil, s.runErr
 	}
 	s.mu.Lock()
 	s.runs++
+	s.lastArgs = args
 	s.mu.Unlock()
 
 	ae := make(chan agentpkg.AgentEvent, 1)
@@ -62,6 +72,16 @@ func (s *stubFlowService) Run(ctx context.Context, _ string, flowID string, _ ma
 	return ae, fs, nil
 }
 
+func (s *stubFlowService) ResolveGate(_ context.Context, sessionID string, approved bool) (*flow.FlowState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gateDecisions = append(s.gateDecisions, gateDecision{sessionID: sessionID, approved: approved})
+	if s.gateErr != nil {
+		return nil, s.gateErr
+	}
+	return &flow.FlowState{SessionID: sessionID, Status: flow.FlowStatusWaitingApproval}, nil
+}
+
 // SetInteractiveHook satisfies the InteractiveHookSetter contract for
 // cmd/serve.go's wiring; tests don't actually exercise this path.
 func (s *stubFlowService) SetInteractiveHook(h flow.InteractiveHook) {}
@@ -79,6 +99,7 @@ func newFlowTestServer(t *testing.T, svc flow.Service) *httptest.Server {
 	mux.HandleFunc("POST /flow", s.handleFlowStart)
 	mux.HandleFunc("GET /flow/status", s.handleFlowStatus)
 	mux.HandleFunc("DELETE /flow", s.handleFlowAbort)
+	mux.HandleFunc("POST /flow/approve", s.handleFlowApprove)
 	server := httptest.NewServer(mux)
 	t.Cleanup(server.Close)
 	return server
@@ -247,3 +268,78 @@ func TestFlowStatusIdleBeforeFirstRun(t *testing.T) {
 		t.Errorf("idle status = %v", body["status"])
 	}
 }
+
+func TestFlowApproveResolvesWaitingStep(t *testing.T) {
+	t.Parallel()
+	svc := newStubFlowService([]flow.FlowState{
+		{StepID: "deploy", SessionID: "sess-deploy", Status: flow.FlowStatusWaitingApproval},
+		{StepID: "deploy", SessionID: "sess-deploy", Status: flow.FlowStatusRunning},
+	})
+	svc.stepDelay = 300 * time.Millisecond // hold the gate open while we poll
+	server := newFlowTestServer(t, svc)
+
+	resp, err := server.Client().Post(server.URL+"/flow", "application/json", strings.NewReader(`{"flowID":"gated"}`))
+	if err != nil {
+		t.Fatalf("POST /flow: %v", err)
+	}
+	resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := server.Client().Get(server.URL + "/flow/status")
+		if err != nil {
+			t.Fatalf("GET status: %v", err)
+		}
+		var snap flowRunSnapshot
+		_ = json.NewDecoder(resp.Body).Decode(&snap)
+		resp.Body.Close()
+		if snap.Status == flowRunWaitingApproval {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run never reached waiting_approval, last status %q", snap.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// sessionID omitted: defaults to the current run's waiting step.
+	resp, err = server.Client().Post(server.URL+"/flow/approve", "application/json", strings.NewReader(`{"approve":true}`))
+	if err != nil {
+		t.Fatalf("POST approve: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("approve status = %d, want 200", resp.StatusCode)
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if len(svc.gateDecisions) != 1 || svc.gateDecisions[0] != (gateDecision{sessionID: "sess-deploy", approved: true}) {
+		t.Errorf("gate decisions = %+v", svc.gateDecisions)
+	}
+}
+
+func TestFlowApproveErrors(t *testing.T) {
+	t.Parallel()
+	svc := newStubFlowService(nil)
+	svc.gateErr = flow.ErrGateNotPending
+	server := newFlowTestServer(t, svc)
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`{"sessionID":"s"}`, http.StatusBadRequest},               // approve missing
+		{`{"approve":false}`, http.StatusConflict},                 // nothing waiting
+		{`{"sessionID":"s","approve":false}`, http.StatusConflict}, // not pending
+	}
+	for _, tc := range cases {
+		resp, err := server.Client().Post(server.URL+"/flow/approve", "application/json", strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatalf("POST %s: %v", tc.body, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("POST %s = %d, want %d", tc.body, resp.StatusCode, tc.want)
+		}
+	}
+}
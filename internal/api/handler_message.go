@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/opencode-ai/opencode/internal/llm/agent"
@@ -13,11 +14,27 @@ import (
 	"github.com/opencode-ai/opencode/internal/message"
 )
 
-// handleMessageList returns all messages for a session.
+// handleMessageList returns the messages of a session: all of them, or with
+// ?limit= the page preceding ?before= (the latest page without it).
 func (s *Server) handleMessageList(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.PathValue("sessionID")
-	messages, err := s.app.Messages.List(r.Context(), sessionID)
+	var messages []message.Message
+	var err error
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, convErr := strconv.ParseInt(raw, 10, 64)
+		if convErr != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		messages, err = s.app.Messages.ListBefore(r.Context(), sessionID, r.URL.Query().Get("before"), limit)
+	} else {
+		messages, err = s.app.Messages.List(r.Context(), sessionID)
+	}
 	if err != nil {
+		if errors.Is(err, message.ErrMessageNotFound) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "failed to list messages")
 		return
 	}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/opencode-ai/opencode/internal/flow"
+	"github.com/opencode-ai/opencode/internal/runqueue"
+)
+
+// queueSubmitRequest is the POST /queue body. A run is a flow run when
+// flowID is set and a prompt run otherwise.
+type queueSubmitRequest struct {
+	FlowID   string         `json:"flowID,omitempty"`
+	Agent    string         `json:"agent,omitempty"`
+	Prompt   string         `json:"prompt,omitempty"`
+	Args     map[string]any `json:"args,omitempty"`
+	Fresh    bool           `json:"fresh,omitempty"`
+	Priority int64          `json:"priority,omitempty"`
+	Provider string         `json:"provider,omitempty"`
+	Project  string         `json:"project,omitempty"`
+}
+
+func (s *Server) runQueue(w http.ResponseWriter) runqueue.Service {
+	if s.app == nil || s.app.RunQueue == nil {
+		writeError(w, http.StatusServiceUnavailable, "run queue not configured")
+		return nil
+	}
+	return s.app.RunQueue
+}
+
+// handleQueueSubmit queues a prompt or flow run and returns it with 202;
+// the dispatcher starts it once priority and concurrency limits allow.
+func (s *Server) handleQueueSubmit(w http.ResponseWriter, r *http.Request) {
+	queue := s.runQueue(w)
+	if queue == nil {
+		return
+	}
+	var body queueSubmitRequest
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := runqueue.SubmitParams{
+		Kind:     runqueue.KindPrompt,
+		Target:   body.Agent,
+		Payload:  runqueue.Payload{Prompt: body.Prompt},
+		Priority: body.Priority,
+		Provider: body.Provider,
+		Project:  body.Project,
+	}
+	if body.FlowID != "" {
+		if _, err := flow.Get(body.FlowID); err != nil {
+			if errors.Is(err, flow.ErrFlowNotFound) {
+				writeError(w, http.StatusNotFound, "flow not found")
+				return
+			}
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		args := body.Args
+		if args == nil {
+			args = map[string]any{}
+		}
+		if body.Prompt != "" {
+			args["prompt"] = body.Prompt
+		}
+		params.Kind = runqueue.KindFlow
+		params.Target = body.FlowID
+		params.Payload = runqueue.Payload{Args: args, Fresh: body.Fresh}
+	}
+
+	run, err := queue.Submit(r.Context(), params)
+	if err != nil {
+		writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// handleQueueList returns the most recent runs of every status, newest
+// first. ?limit= caps the result (default runqueue.DefaultListLimit).
+func (s *Server) handleQueueList(w http.ResponseWriter, r *http.Request) {
+	queue := s.runQueue(w)
+	if queue == nil {
+		return
+	}
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	runs, err := queue.List(r.Context(), limit)
+	if err != nil {
+		writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *Server) handleQueueGet(w http.ResponseWriter, r *http.Request) {
+	queue := s.runQueue(w)
+	if queue == nil {
+		return
+	}
+	run, err := queue.Get(r.Context(), r.PathValue("runID"))
+	if err != nil {
+		writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// handleQueueCancel cancels a queued or running run. Cancelling a run
+// that already finished is a 409.
+func (s *Server) handleQueueCancel(w http.ResponseWriter, r *http.Request) {
+	queue := s.runQueue(w)
+	if queue == nil {
+		return
+	}
+	run, err := queue.Cancel(r.Context(), r.PathValue("runID"))
+	if err != nil {
+		writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+func writeQueueError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, runqueue.ErrRunNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, runqueue.ErrRunFinished):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, runqueue.ErrInvalidRun):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
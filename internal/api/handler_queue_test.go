@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/runqueue"
+)
+
+func newQueueTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := &Server{app: &app.App{RunQueue: runqueue.NewService(db.NewTestQuerier(t), "proj")}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /queue", s.handleQueueList)
+	mux.HandleFunc("POST /queue", s.handleQueueSubmit)
+	mux.HandleFunc("GET /queue/{runID}", s.handleQueueGet)
+	mux.HandleFunc("DELETE /queue/{runID}", s.handleQueueCancel)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func queueRequest(t *testing.T, method, url string, body any) (*http.Response, runqueue.Run) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, _ := http.NewRequest(method, url, &buf)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	var run runqueue.Run
+	if resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+			t.Fatalf("decode run: %v", err)
+		}
+	}
+	return resp, run
+}
+
+func TestQueueSubmitListAndCancel(t *testing.T) {
+	srv := newQueueTestServer(t)
+
+	resp, low := queueRequest(t, http.MethodPost, srv.URL+"/queue", map[string]any{"prompt": "later"})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("submit status = %d, want 202", resp.StatusCode)
+	}
+	_, high := queueRequest(t, http.MethodPost, srv.URL+"/queue", map[string]any{
+		"prompt": "first", "agent": "coder", "priority": 5, "provider": "openai",
+	})
+	if high.Kind != runqueue.KindPrompt || high.Target != "coder" || high.Priority != 5 ||
+		high.Provider != "openai" || high.Project != "proj" || high.Status != runqueue.StatusQueued {
+		t.Fatalf("submitted run = %+v", high)
+	}
+
+	listResp, err := http.Get(srv.URL + "/queue?limit=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listResp.Body.Close()
+	var runs []runqueue.Run
+	if err := json.NewDecoder(listResp.Body).Decode(&runs); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("listed %d runs, want 2", len(runs))
+	}
+
+	resp, cancelled := queueRequest(t, http.MethodDelete, srv.URL+"/queue/"+low.ID, nil)
+	if resp.StatusCode != http.StatusOK || cancelled.Status != runqueue.StatusCancelled {
+		t.Fatalf("cancel: status=%d run=%+v", resp.StatusCode, cancelled)
+	}
+	if resp, _ := queueRequest(t, http.MethodDelete, srv.URL+"/queue/"+low.ID, nil); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("second cancel status = %d, want 409", resp.StatusCode)
+	}
+	if resp, got := queueRequest(t, http.MethodGet, srv.URL+"/queue/"+high.ID, nil); resp.StatusCode != http.StatusOK || got.ID != high.ID {
+		t.Fatalf("get: status=%d run=%+v", resp.StatusCode, got)
+	}
+}
+
+func TestQueueErrors(t *testing.T) {
+	srv := newQueueTestServer(t)
+
+	if resp, _ := queueRequest(t, http.MethodPost, srv.URL+"/queue", map[string]any{}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("empty prompt status = %d, want 400", resp.StatusCode)
+	}
+	if resp, _ := queueRequest(t, http.MethodGet, srv.URL+"/queue/missing", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("get missing status = %d, want 404", resp.StatusCode)
+	}
+	if resp, _ := queueRequest(t, http.MethodDelete, srv.URL+"/queue/missing", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cancel missing status = %d, want 404", resp.StatusCode)
+	}
+	resp, err := http.Get(srv.URL + "/queue?limit=zero")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad limit status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/blackboard"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/session"
 )
 
@@ -138,6 +142,76 @@ func (s *Server) handleSessionUpdate(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, ConvertSessionWithDir(sess, resolveDirectory(r)))
 }
 
+// handleSessionFork copies the session's history up to and including the
+// given message into a new session.
+func (s *Server) handleSessionFork(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+
+	var req APISessionForkRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.MessageID == "" {
+		writeError(w, http.StatusBadRequest, "messageID is required")
+		return
+	}
+
+	fork, err := s.app.Sessions.Fork(r.Context(), sessionID, req.MessageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "session not found")
+		case errors.Is(err, session.ErrMessageNotFound):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to fork session")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ConvertSessionWithDir(fork, resolveDirectory(r)))
+}
+
+// handleSessionMerge folds a side session's summary and file history into
+// the session.
+func (s *Server) handleSessionMerge(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+
+	var req APISessionMergeRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.SourceID == "" {
+		writeError(w, http.StatusBadRequest, "sourceID is required")
+		return
+	}
+
+	res, err := s.app.MergeSession(r.Context(), sessionID, req.SourceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "session not found")
+		case errors.Is(err, app.ErrMergeSameSession), errors.Is(err, app.ErrMergeSubsession), errors.Is(err, app.ErrMergeEmpty):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	files := res.Files
+	if files == nil {
+		files = []string{}
+	}
+	writeJSON(w, http.StatusOK, APISessionMergeResponse{
+		MessageID:  res.MessageID,
+		Files:      files,
+		Summarized: res.Summarized,
+	})
+}
+
 // handleSessionStatus returns the busy/idle status of all sessions.
 func (s *Server) handleSessionStatus(w http.ResponseWriter, r *http.Request) {
 	sessions, err := s.app.Sessions.List(r.Context())
@@ -170,6 +244,44 @@ func (s *Server) handleSessionTodo(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, []struct{}{})
 }
 
+// handleSessionBlackboard returns the blackboard shared by the session's
+// tree, oldest first. ?topic=, ?query=, ?agent= and ?after= filter it the
+// same way the blackboard_read tool does; ?limit= keeps the newest N.
+func (s *Server) handleSessionBlackboard(w http.ResponseWriter, r *http.Request) {
+	if s.app.Blackboard == nil {
+		writeJSON(w, http.StatusOK, []struct{}{})
+		return
+	}
+	params := r.URL.Query()
+	q := blackboard.Query{
+		Topic:    params.Get("topic"),
+		Contains: params.Get("query"),
+		Agent:    params.Get("agent"),
+		After:    params.Get("after"),
+	}
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		q.Limit = n
+	}
+	entries, err := s.app.Blackboard.Query(r.Context(), r.PathValue("sessionID"), q)
+	if errors.Is(err, blackboard.ErrSessionNotFound) {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []blackboard.Entry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
 // handleSessionChildren returns descendant sessions of the given session.
 // Sessions.ListChildren returns the whole subtree (matches by root_session_id,
 // which equals the session's own ID for top-level sessions), so we filter out
@@ -191,6 +303,17 @@ func (s *Server) handleSessionChildren(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, ConvertSessionsWithDir(children, resolveDirectory(r)))
 }
 
+// handleSessionCancelBranch cancels only the task-tool subagent or flow
+// step running in sessionID. The parent run continues and receives an
+// error result for that branch.
+func (s *Server) handleSessionCancelBranch(w http.ResponseWriter, r *http.Request) {
+	if !agent.CancelBranch(r.PathValue("sessionID")) {
+		writeError(w, http.StatusConflict, "no subagent or flow step is running in this session")
+		return
+	}
+	writeJSON(w, http.StatusOK, true)
+}
+
 // handleSessionAbort cancels the active agent run for a session.
 func (s *Server) handleSessionAbort(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.PathValue("sessionID")
@@ -0,0 +1,430 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// APIShare is a read-only live link to a session. Anyone holding the token
+// can watch the session and its subagent sessions; nothing can be changed
+// through it.
+type APIShare struct {
+	Token     string `json:"token"`
+	SessionID string `json:"sessionID"`
+	URL       string `json:"url"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// share is one active link. ctx is cancelled on revoke so open viewers are
+// disconnected.
+type share struct {
+	token     string
+	sessionID string
+	createdAt time.Time
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// shareRegistry holds the active links. They live as long as the server;
+// a restart revokes them all.
+type shareRegistry struct {
+	mu        sync.RWMutex
+	byToken   map[string]*share
+	bySession map[string]*share
+}
+
+func newShareRegistry() *shareRegistry {
+	return &shareRegistry{
+		byToken:   map[string]*share{},
+		bySession: map[string]*share{},
+	}
+}
+
+// create returns the session's link, creating it on first use.
+func (r *shareRegistry) create(sessionID string) (*share, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sh, ok := r.bySession[sessionID]; ok {
+		return sh, nil
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate share token: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sh := &share{
+		token:     hex.EncodeToString(buf),
+		sessionID: sessionID,
+		createdAt: time.Now(),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	r.byToken[sh.token] = sh
+	r.bySession[sessionID] = sh
+	return sh, nil
+}
+
+func (r *shareRegistry) get(sessionID string) (*share, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sh, ok := r.bySession[sessionID]
+	return sh, ok
+}
+
+func (r *shareRegistry) lookup(token string) (*share, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sh, ok := r.byToken[token]
+	return sh, ok
+}
+
+// revoke removes the session's link and disconnects its viewers. It
+// reports false when the session was not shared.
+func (r *shareRegistry) revoke(sessionID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sh, ok := r.bySession[sessionID]
+	if !ok {
+		return false
+	}
+	delete(r.bySession, sessionID)
+	delete(r.byToken, sh.token)
+	sh.cancel()
+	return true
+}
+
+func shareResponse(r *http.Request, sh *share) APIShare {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return APIShare{
+		Token:     sh.token,
+		SessionID: sh.sessionID,
+		URL:       fmt.Sprintf("%s://%s/share/%s", scheme, r.Host, sh.token),
+		CreatedAt: sh.createdAt.UnixMilli(),
+	}
+}
+
+// handleShareCreate creates (or returns the existing) read-only link for a
+// session.
+func (s *Server) handleShareCreate(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+	if _, err := s.app.Sessions.Get(r.Context(), sessionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get session")
+		return
+	}
+	sh, err := s.shares.create(sessionID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.Info("Session shared", "session_id", sessionID)
+	writeJSON(w, http.StatusOK, shareResponse(r, sh))
+}
+
+// handleShareGet returns the session's active link.
+func (s *Server) handleShareGet(w http.ResponseWriter, r *http.Request) {
+	sh, ok := s.shares.get(r.PathValue("sessionID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "session is not shared")
+		return
+	}
+	writeJSON(w, http.StatusOK, shareResponse(r, sh))
+}
+
+// handleShareRevoke revokes the session's link and disconnects its viewers.
+func (s *Server) handleShareRevoke(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+	if !s.shares.revoke(sessionID) {
+		writeError(w, http.StatusNotFound, "session is not shared")
+		return
+	}
+	logging.Info("Session share revoked", "session_id", sessionID)
+	writeJSON(w, http.StatusOK, true)
+}
+
+// sharedSession resolves the {token} path value, answering 404 for unknown
+// or revoked tokens.
+func (s *Server) sharedSession(w http.ResponseWriter, r *http.Request) (*share, session.Session, bool) {
+	sh, ok := s.shares.lookup(r.PathValue("token"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "share not found")
+		return nil, session.Session{}, false
+	}
+	sess, err := s.app.Sessions.Get(r.Context(), sh.sessionID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "share not found")
+		return nil, session.Session{}, false
+	}
+	return sh, sess, true
+}
+
+// handleShareSession returns the shared session.
+func (s *Server) handleShareSession(w http.ResponseWriter, r *http.Request) {
+	_, sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, ConvertSession(sess))
+}
+
+// handleShareMessages returns the shared session's messages.
+func (s *Server) handleShareMessages(w http.ResponseWriter, r *http.Request) {
+	_, sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+	msgs, err := s.app.Messages.List(r.Context(), sess.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list messages")
+		return
+	}
+	writeJSON(w, http.StatusOK, ConvertMessages(msgs))
+}
+
+// handleShareEvents streams the message and session events of the shared
+// session tree. Permission and question events are left out: viewers
+// cannot answer them and they may carry details of the owner's machine.
+func (s *Server) handleShareEvents(w http.ResponseWriter, r *http.Request) {
+	sh, _, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	setSSEHeaders(w)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stop := context.AfterFunc(sh.ctx, cancel)
+	defer stop()
+
+	msgCh := s.app.Messages.Subscribe(ctx)
+	partCh := s.app.Messages.SubscribeParts(ctx)
+	sesCh := s.app.Sessions.Subscribe(ctx)
+	tree := newShareTree(s.app.Sessions, sh.sessionID)
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-msgCh:
+			if !ok {
+				return
+			}
+			if tree.contains(ctx, event.Payload.SessionID) {
+				err = writeSSEEvent(w, flusher, sseEventType("message", event.Type), ConvertMessageToResponse(event.Payload))
+			}
+		case event, ok := <-partCh:
+			if !ok {
+				return
+			}
+			if tree.contains(ctx, event.Payload.SessionID) {
+				apiPart := ConvertPart(event.Payload.MessageID, event.Payload.SessionID, event.Payload.Part)
+				err = writeSSEEvent(w, flusher, "message.part.updated", map[string]any{"part": apiPart})
+			}
+		case event, ok := <-sesCh:
+			if !ok {
+				return
+			}
+			if tree.contains(ctx, event.Payload.ID) {
+				err = writeSSEEvent(w, flusher, sseEventType("session", event.Type), ConvertSession(event.Payload))
+			}
+		case <-heartbeat.C:
+			if _, err = fmt.Fprintf(w, ": heartbeat\n\n"); err == nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// shareTree answers whether a session belongs to the tree under root,
+// caching each answer for the lifetime of one stream. Only answers backed
+// by successful lookups are cached, so a failed one is retried on the
+// session's next event.
+type shareTree struct {
+	sessions session.Service
+	root     string
+	known    map[string]bool
+}
+
+func newShareTree(sessions session.Service, root string) *shareTree {
+	return &shareTree{sessions: sessions, root: root, known: map[string]bool{root: true}}
+}
+
+func (t *shareTree) contains(ctx context.Context, sessionID string) bool {
+	if in, ok := t.known[sessionID]; ok {
+		return in
+	}
+	// Walk up the parents; the path is recorded so siblings resolve from
+	// the cache.
+	var path []string
+	in := false
+	for id := sessionID; id != ""; {
+		if v, ok := t.known[id]; ok {
+			in = v
+			break
+		}
+		path = append(path, id)
+		sess, err := t.sessions.Get(ctx, id)
+		if err != nil {
+			return false
+		}
+		id = sess.ParentSessionID
+	}
+	for _, id := range path {
+		t.known[id] = in
+	}
+	return in
+}
+
+// shareView is the data behind the shared session page.
+type shareView struct {
+	Title    string
+	Token    string
+	Messages []shareViewMessage
+}
+
+type shareViewMessage struct {
+	Role      string
+	Model     string
+	Reasoning string
+	Text      string
+	Tools     []string
+}
+
+func newShareView(token string, sess session.Session, msgs []message.Message) shareView {
+	view := shareView{Title: sess.Title, Token: token}
+	for _, m := range msgs {
+		if m.Role == message.Tool {
+			continue
+		}
+		vm := shareViewMessage{
+			Role:      string(m.Role),
+			Model:     string(m.Model),
+			Reasoning: m.ReasoningContent().Thinking,
+			Text:      m.Content().Text,
+		}
+		for _, call := range m.ToolCalls() {
+			vm.Tools = append(vm.Tools, call.Name)
+		}
+		if vm.Text == "" && vm.Reasoning == "" && len(vm.Tools) == 0 {
+			continue
+		}
+		view.Messages = append(view.Messages, vm)
+	}
+	return view
+}
+
+// handleSharePage renders the shared session as a page that refreshes its
+// message list on every event of the stream. ?fragment=1 renders only the
+// message list; the page uses it to refresh.
+func (s *Server) handleSharePage(w http.ResponseWriter, r *http.Request) {
+	sh, sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+	msgs, err := s.app.Messages.List(r.Context(), sess.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list messages")
+		return
+	}
+	name := "page"
+	if r.URL.Query().Get("fragment") != "" {
+		name = "messages"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := shareTemplate.ExecuteTemplate(w, name, newShareView(sh.token, sess, msgs)); err != nil {
+		logging.Error("failed to render shared session", "session_id", sess.ID, "error", err)
+	}
+}
+
+var shareTemplate = template.Must(template.New("share").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`{{define "messages"}}{{range .Messages}}<div class="msg {{.Role}}">
+<div class="role">{{.Role}}{{if .Model}} · {{.Model}}{{end}}</div>
+{{if .Reasoning}}<details><summary>Thinking</summary><pre>{{.Reasoning}}</pre></details>{{end}}
+{{if .Text}}<pre>{{.Text}}</pre>{{end}}
+{{if .Tools}}<div class="tools">Tools: {{join .Tools ", "}}</div>{{end}}
+</div>
+{{else}}<p class="empty">No messages yet.</p>
+{{end}}{{end}}{{define "page"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}} · OpenCode</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.3rem; }
+.status { color: #888; font-size: .85rem; }
+.msg { border-left: 3px solid #ccc; padding: .25rem .75rem; margin: 1rem 0; }
+.msg.user { border-color: #4a7bd0; }
+.msg.assistant { border-color: #3a9a5b; }
+.role { font-size: .8rem; color: #666; text-transform: uppercase; }
+pre { white-space: pre-wrap; word-wrap: break-word; font-family: ui-monospace, monospace; font-size: .9rem; }
+.tools { font-size: .85rem; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="status" id="status">Read-only view · connecting…</div>
+<div id="messages">{{template "messages" .}}</div>
+<script>
+(function () {
+  var base = "/share/{{.Token}}";
+  var status = document.getElementById("status");
+  var list = document.getElementById("messages");
+  var pending = null;
+  function refresh() {
+    if (pending) return;
+    pending = setTimeout(function () {
+      fetch(base + "?fragment=1").then(function (r) {
+        pending = null;
+        if (!r.ok) { status.textContent = "Read-only view · share revoked"; return; }
+        return r.text().then(function (html) {
+          var atBottom = window.innerHeight + window.scrollY >= document.body.scrollHeight - 40;
+          list.innerHTML = html;
+          if (atBottom) window.scrollTo(0, document.body.scrollHeight);
+        });
+      }).catch(function () { pending = null; });
+    }, 300);
+  }
+  var events = new EventSource(base + "/event");
+  events.onopen = function () { status.textContent = "Read-only view · live"; };
+  events.onmessage = refresh;
+  events.onerror = function () { status.textContent = "Read-only view · reconnecting…"; };
+})();
+</script>
+</body>
+</html>
+{{end}}`))
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+func newShareTestServer(t *testing.T, sessions session.Service) *httptest.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	config.Reset()
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Logf("config.Load warning: %v", err)
+	}
+	t.Cleanup(config.Reset)
+
+	s := &Server{app: &app.App{Sessions: sessions}, shares: newShareRegistry()}
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
+	ts := httptest.NewServer(chain(mux, authMiddleware("secret"), jsonContentTypeMiddleware))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func shareRequest(t *testing.T, method, url string, auth bool) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth {
+		req.SetBasicAuth("opencode", "secret")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestShareLinkLifecycle(t *testing.T) {
+	stub := &stubSessions{byID: map[string]session.Session{"sess-1": {ID: "sess-1", Title: "Fix the parser"}}}
+	ts := newShareTestServer(t, stub)
+
+	if resp := shareRequest(t, http.MethodPost, ts.URL+"/session/sess-1/share", false); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("creating a share without credentials: status %d, want 401", resp.StatusCode)
+	}
+	resp := shareRequest(t, http.MethodPost, ts.URL+"/session/sess-1/share", true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("create share: status %d", resp.StatusCode)
+	}
+	var sh APIShare
+	if err := json.NewDecoder(resp.Body).Decode(&sh); err != nil {
+		t.Fatal(err)
+	}
+	if sh.Token == "" || sh.URL != ts.URL+"/share/"+sh.Token {
+		t.Fatalf("unexpected share %+v", sh)
+	}
+
+	// Sharing again returns the same link.
+	var again APIShare
+	if err := json.NewDecoder(shareRequest(t, http.MethodPost, ts.URL+"/session/sess-1/share", true).Body).Decode(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again.Token != sh.Token {
+		t.Errorf("second share got token %q, want %q", again.Token, sh.Token)
+	}
+
+	// The token alone grants read access.
+	resp = shareRequest(t, http.MethodGet, ts.URL+"/share/"+sh.Token+"/session", false)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("read shared session: status %d", resp.StatusCode)
+	}
+	var got APISession
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Fix the parser" {
+		t.Errorf("shared session title %q", got.Title)
+	}
+	if resp := shareRequest(t, http.MethodGet, ts.URL+"/share/not-a-token/session", false); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown token: status %d, want 404", resp.StatusCode)
+	}
+
+	if resp := shareRequest(t, http.MethodDelete, ts.URL+"/session/sess-1/share", true); resp.StatusCode != http.StatusOK {
+		t.Fatalf("revoke share: status %d", resp.StatusCode)
+	}
+	if resp := shareRequest(t, http.MethodGet, ts.URL+"/share/"+sh.Token+"/session", false); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("revoked token: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestShareTreeContains(t *testing.T) {
+	stub := &stubSessions{byID: map[string]session.Session{
+		"root":  {ID: "root"},
+		"task":  {ID: "task", ParentSessionID: "root"},
+		"inner": {ID: "inner", ParentSessionID: "task"},
+		"other": {ID: "other"},
+	}}
+	tree := newShareTree(stub, "task")
+	ctx := context.Background()
+	for id, want := range map[string]bool{"task": true, "inner": true, "root": false, "other": false, "missing": false} {
+		if got := tree.contains(ctx, id); got != want {
+			t.Errorf("contains(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestShareTreeRetriesFailedLookup(t *testing.T) {
+	stub := &stubSessions{byID: map[string]session.Session{
+		"task":  {ID: "task"},
+		"inner": {ID: "inner", ParentSessionID: "task"},
+	}, err: errors.New("database is locked")}
+	tree := newShareTree(stub, "task")
+	ctx := context.Background()
+
+	if tree.contains(ctx, "inner") {
+		t.Fatal("contains(inner) = true while lookups fail")
+	}
+	stub.err = nil
+	if !tree.contains(ctx, "inner") {
+		t.Fatal("a failed lookup was cached; contains(inner) stayed false")
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFS holds the read-only web UI served under /ui/. It is plain HTML,
+// CSS and JavaScript on top of the JSON API and the /event stream, so the
+// binary needs no build step and no extra assets.
+//
+//go:embed web
+var webFS embed.FS
+
+// uiHandler serves the embedded web UI. The JSON content type set by the
+// middleware is dropped so the file server picks one from the extension.
+func uiHandler() http.Handler {
+	sub, err := fs.Sub(webFS, "web")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/ui/", http.FileServerFS(sub))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Del("Content-Type")
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// handleUIRedirect sends browsers opening the server's root to the UI.
+func (s *Server) handleUIRedirect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Del("Content-Type")
+	http.Redirect(w, r, "/ui/", http.StatusFound)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebUI(t *testing.T) {
+	t.Setenv("OPENCODE_SERVER_PASSWORD", "")
+	s := NewServer(nil, ServerOptions{})
+	ts := httptest.NewServer(s.httpSrv.Handler)
+	t.Cleanup(ts.Close)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err := client.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/ui/" {
+		t.Fatalf("GET /: status %d, location %q; want a redirect to /ui/", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	for path, want := range map[string]string{
+		"/ui/":          "text/html",
+		"/ui/app.js":    "text/javascript",
+		"/ui/style.css": "text/css",
+	} {
+		resp, err := client.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(body) == 0 {
+			t.Fatalf("GET %s: status %d, %d bytes", path, resp.StatusCode, len(body))
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, want) {
+			t.Errorf("GET %s: content type %q, want %s", path, ct, want)
+		}
+	}
+
+	noUI := NewServer(nil, ServerOptions{NoUI: true})
+	ts2 := httptest.NewServer(noUI.httpSrv.Handler)
+	t.Cleanup(ts2.Close)
+	resp, err = client.Get(ts2.URL + "/ui/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /ui/ with NoUI: status %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/opencode-ai/opencode/internal/feedback"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// APISessionUsage is the spend of a session tree broken down by agent and
+// tool. Tool entries estimate the input cost of re-sending each tool's
+// results and are already part of the agent totals. Feedback tallies the
+// ratings given to the session's replies by agent and model.
+type APISessionUsage struct {
+	Cost     float64              `json:"cost"`
+	Tokens   int64                `json:"tokens"`
+	Agents   []session.UsageEntry `json:"agents"`
+	Tools    []session.UsageEntry `json:"tools"`
+	Feedback []feedback.Tally     `json:"feedback"`
+}
+
+// handleSessionUsage returns the cost breakdown of the tree containing the
+// session.
+func (s *Server) handleSessionUsage(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+	total, err := s.app.Sessions.TreeUsage(r.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get session usage")
+		return
+	}
+	entries, err := s.app.Sessions.UsageBreakdown(r.Context(), sessionID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get session usage")
+		return
+	}
+	resp := APISessionUsage{
+		Cost:     total.Cost,
+		Tokens:   total.Tokens,
+		Agents:   []session.UsageEntry{},
+		Tools:    []session.UsageEntry{},
+		Feedback: []feedback.Tally{},
+	}
+	for _, e := range entries {
+		if e.Category == session.UsageTool {
+			resp.Tools = append(resp.Tools, e)
+		} else {
+			resp.Agents = append(resp.Agents, e)
+		}
+	}
+	if s.app.Feedback != nil {
+		ratings, err := s.app.Feedback.List(r.Context(), sessionID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get session feedback")
+			return
+		}
+		resp.Feedback = append(resp.Feedback, feedback.Summarize(ratings)...)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
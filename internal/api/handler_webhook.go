@@ -0,0 +1,495 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+const (
+	webhookProviderGitHub = "github"
+	webhookProviderGitLab = "gitlab"
+
+	defaultWebhookLabel   = "opencode"
+	defaultWebhookCommand = "/opencode"
+
+	// webhookMaxBody bounds a delivery. GitHub caps payloads at 25 MB;
+	// issue and comment events are a few KB.
+	webhookMaxBody = 5 << 20
+	// webhookQueueSize bounds runs accepted while another flow is in
+	// flight. Deliveries beyond it get 503 and are redelivered by the
+	// hosting service's retry.
+	webhookQueueSize = 64
+)
+
+// errWebhookAuthor rejects events from users the repo doesn't trust to
+// start unattended runs.
+var errWebhookAuthor = errors.New("author is not allowed to trigger runs")
+
+// githubTrustedAssociations are the author_association values that can
+// trigger comment runs when a repo has no allowedAuthors list.
+var githubTrustedAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// webhookRetryInterval is how often the queue worker retries a start
+// while the single flow slot is busy.
+var webhookRetryInterval = 2 * time.Second
+
+// webhookJob is one flow run derived from a delivery.
+type webhookJob struct {
+	delivery string
+	flowID   string
+	args     map[string]any
+}
+
+// webhookReceiver turns verified GitHub / GitLab deliveries into flow
+// runs. The flow runner executes one run at a time, so accepted runs
+// wait in a FIFO until the slot frees up.
+type webhookReceiver struct {
+	repos  []config.WebhookRepo
+	runner *flowRunner
+	jobs   chan webhookJob
+}
+
+// newWebhookReceiver returns nil when no repository is configured, so
+// the routes answer 404 like any other disabled subsystem.
+func newWebhookReceiver(cfg *config.WebhooksConfig, runner *flowRunner) *webhookReceiver {
+	if cfg == nil || len(cfg.Repos) == 0 || runner == nil {
+		return nil
+	}
+	repos := make([]config.WebhookRepo, 0, len(cfg.Repos))
+	for _, r := range cfg.Repos {
+		// Expand before the check: an unset ${VAR} would otherwise leave
+		// an empty key every sender can sign with.
+		r.Secret = os.ExpandEnv(r.Secret)
+		if r.Name == "" || r.Secret == "" {
+			logging.Warn("Skipping webhook repo without name or secret", "repo", r.Name)
+			continue
+		}
+		if r.Provider != webhookProviderGitHub && r.Provider != webhookProviderGitLab {
+			logging.Warn("Skipping webhook repo with unknown provider", "repo", r.Name, "provider", r.Provider)
+			continue
+		}
+		if r.Label == "" {
+			r.Label = defaultWebhookLabel
+		}
+		if r.Command == "" {
+			r.Command = defaultWebhookCommand
+		}
+		if r.CommentFlow == "" {
+			r.CommentFlow = r.IssueFlow
+		}
+		repos = append(repos, r)
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+	wr := &webhookReceiver{
+		repos:  repos,
+		runner: runner,
+		jobs:   make(chan webhookJob, webhookQueueSize),
+	}
+	go wr.work(context.Background())
+	return wr
+}
+
+func (wr *webhookReceiver) repo(provider, name string) (config.WebhookRepo, bool) {
+	for _, r := range wr.repos {
+		if r.Provider == provider && strings.EqualFold(r.Name, name) {
+			return r, true
+		}
+	}
+	return config.WebhookRepo{}, false
+}
+
+// enqueue accepts a run without blocking the delivery.
+func (wr *webhookReceiver) enqueue(job webhookJob) bool {
+	select {
+	case wr.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// work starts queued runs in order, waiting while another flow holds
+// the runner's single slot.
+func (wr *webhookReceiver) work(ctx context.Context) {
+	for {
+		var job webhookJob
+		select {
+		case <-ctx.Done():
+			return
+		case job = <-wr.jobs:
+		}
+		for {
+			res, err := wr.runner.Start(ctx, job.flowID, job.args, false)
+			if errors.Is(err, errFlowAlreadyRunning) {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(webhookRetryInterval):
+				}
+				continue
+			}
+			if err != nil {
+				logging.Error("Webhook flow run failed to start", "delivery", job.delivery, "flow", job.flowID, "error", err)
+			} else {
+				logging.Info("Webhook flow run started", "delivery", job.delivery, "flow", job.flowID, "run", res.RunID)
+			}
+			break
+		}
+	}
+}
+
+// webhookRepoRef is the repository part both providers' payloads share
+// once decoded; used to find the config entry before verifying.
+type webhookRepoRef struct {
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// handleWebhookGitHub receives GitHub deliveries. Handled events:
+// `issues` labeled with the repo's label, and `issue_comment` created
+// with a body starting with the repo's command.
+func (s *Server) handleWebhookGitHub(w http.ResponseWriter, r *http.Request) {
+	repo, body, ok := s.readWebhook(w, r, webhookProviderGitHub)
+	if !ok {
+		return
+	}
+	if !verifyGitHubSignature(repo.Secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+	event := r.Header.Get("X-GitHub-Event")
+	if event == "ping" {
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+		return
+	}
+	job, err := githubWebhookJob(repo, event, body)
+	s.acceptWebhook(w, r.Header.Get("X-GitHub-Delivery"), job, err)
+}
+
+// handleWebhookGitLab receives GitLab deliveries. Handled events:
+// `Issue Hook` where the repo's label was just added, and `Note Hook`
+// comments on issues or merge requests starting with the repo's command.
+func (s *Server) handleWebhookGitLab(w http.ResponseWriter, r *http.Request) {
+	repo, body, ok := s.readWebhook(w, r, webhookProviderGitLab)
+	if !ok {
+		return
+	}
+	token := r.Header.Get("X-Gitlab-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(repo.Secret)) != 1 {
+		writeError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	job, err := gitlabWebhookJob(repo, r.Header.Get("X-Gitlab-Event"), body)
+	s.acceptWebhook(w, r.Header.Get("X-Gitlab-Event-UUID"), job, err)
+}
+
+// readWebhook reads the delivery and resolves its repository config.
+// It writes the error response itself and returns ok=false on failure.
+func (s *Server) readWebhook(w http.ResponseWriter, r *http.Request, provider string) (config.WebhookRepo, []byte, bool) {
+	if s.webhooks == nil {
+		writeError(w, http.StatusNotFound, "webhooks not configured")
+		return config.WebhookRepo{}, nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return config.WebhookRepo{}, nil, false
+	}
+	var ref webhookRepoRef
+	if err := json.Unmarshal(body, &ref); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return config.WebhookRepo{}, nil, false
+	}
+	name := ref.Repository.FullName
+	if provider == webhookProviderGitLab {
+		name = ref.Project.PathWithNamespace
+	}
+	repo, ok := s.webhooks.repo(provider, name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("repository %q not configured", name))
+		return config.WebhookRepo{}, nil, false
+	}
+	return repo, body, true
+}
+
+// acceptWebhook answers a verified delivery: 202 when a run was queued,
+// 200 when the event isn't one the repo acts on.
+func (s *Server) acceptWebhook(w http.ResponseWriter, delivery string, job *webhookJob, err error) {
+	if errors.Is(err, errWebhookAuthor) {
+		logging.Warn("Rejected webhook delivery", "delivery", delivery, "error", err)
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"ignored": true})
+		return
+	}
+	job.delivery = delivery
+	if !s.webhooks.enqueue(*job) {
+		writeError(w, http.StatusServiceUnavailable, "webhook queue is full")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"queued": true, "flowID": job.flowID})
+}
+
+func verifyGitHubSignature(secret string, body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+type githubIssue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request"`
+}
+
+type githubPayload struct {
+	Action string `json:"action"`
+	Label  struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Issue   githubIssue `json:"issue"`
+	Comment struct {
+		Body              string `json:"body"`
+		HTMLURL           string `json:"html_url"`
+		AuthorAssociation string `json:"author_association"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// githubWebhookJob maps a GitHub event to a run, or nil when the repo
+// doesn't act on it.
+func githubWebhookJob(repo config.WebhookRepo, event string, body []byte) (*webhookJob, error) {
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", event, err)
+	}
+	base := map[string]any{
+		"provider": webhookProviderGitHub,
+		"repo":     p.Repository.FullName,
+		"number":   p.Issue.Number,
+		"title":    p.Issue.Title,
+		"body":     p.Issue.Body,
+		"url":      p.Issue.HTMLURL,
+		"author":   p.Sender.Login,
+	}
+	switch event {
+	case "issues":
+		if p.Action != "labeled" || p.Label.Name != repo.Label || repo.IssueFlow == "" {
+			return nil, nil
+		}
+		if !webhookAuthorAllowed(repo, p.Sender.Login, true) {
+			return nil, fmt.Errorf("%w: %s", errWebhookAuthor, p.Sender.Login)
+		}
+		base["event"] = "issue"
+		base["prompt"] = issuePrompt(p.Issue.Number, p.Issue.Title, p.Issue.Body)
+		return newWebhookJob(repo, repo.IssueFlow, base), nil
+	case "issue_comment":
+		if p.Action != "created" || repo.CommentFlow == "" {
+			return nil, nil
+		}
+		command, ok := parseWebhookCommand(repo.Command, p.Comment.Body)
+		if !ok {
+			return nil, nil
+		}
+		trusted := slices.Contains(githubTrustedAssociations, p.Comment.AuthorAssociation)
+		if !webhookAuthorAllowed(repo, p.Sender.Login, trusted) {
+			return nil, fmt.Errorf("%w: %s (%s)", errWebhookAuthor, p.Sender.Login, p.Comment.AuthorAssociation)
+		}
+		base["event"] = "comment"
+		base["command"] = command
+		base["comment"] = p.Comment.Body
+		base["comment_url"] = p.Comment.HTMLURL
+		base["is_pull_request"] = p.Issue.PullRequest != nil
+		base["prompt"] = commentPrompt(command, p.Issue.Number, p.Issue.Title, p.Issue.Body)
+		return newWebhookJob(repo, repo.CommentFlow, base), nil
+	}
+	return nil, nil
+}
+
+type gitlabPayload struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		URL          string `json:"url"`
+		Action       string `json:"action"`
+		Note         string `json:"note"`
+		NoteableType string `json:"noteable_type"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Labels *struct {
+			Previous []gitlabLabel `json:"previous"`
+			Current  []gitlabLabel `json:"current"`
+		} `json:"labels"`
+	} `json:"changes"`
+	Issue        *gitlabNoteable `json:"issue"`
+	MergeRequest *gitlabNoteable `json:"merge_request"`
+	Project      struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+}
+
+type gitlabLabel struct {
+	Title string `json:"title"`
+}
+
+type gitlabNoteable struct {
+	IID         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// gitlabWebhookJob maps a GitLab event to a run, or nil when the repo
+// doesn't act on it.
+func gitlabWebhookJob(repo config.WebhookRepo, event string, body []byte) (*webhookJob, error) {
+	var p gitlabPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", event, err)
+	}
+	attrs := p.ObjectAttributes
+	base := map[string]any{
+		"provider": webhookProviderGitLab,
+		"repo":     p.Project.PathWithNamespace,
+		"author":   p.User.Username,
+	}
+	switch event {
+	case "Issue Hook":
+		// Only the transition that adds the label triggers, so later
+		// edits of a labelled issue don't start another run.
+		labels := p.Changes.Labels
+		if labels == nil || repo.IssueFlow == "" ||
+			hasGitLabLabel(labels.Previous, repo.Label) || !hasGitLabLabel(labels.Current, repo.Label) {
+			return nil, nil
+		}
+		if !webhookAuthorAllowed(repo, p.User.Username, true) {
+			return nil, fmt.Errorf("%w: %s", errWebhookAuthor, p.User.Username)
+		}
+		base["event"] = "issue"
+		base["number"] = attrs.IID
+		base["title"] = attrs.Title
+		base["body"] = attrs.Description
+		base["url"] = attrs.URL
+		base["prompt"] = issuePrompt(attrs.IID, attrs.Title, attrs.Description)
+		return newWebhookJob(repo, repo.IssueFlow, base), nil
+	case "Note Hook":
+		target := p.Issue
+		if attrs.NoteableType == "MergeRequest" {
+			target = p.MergeRequest
+		}
+		if target == nil || repo.CommentFlow == "" {
+			return nil, nil
+		}
+		command, ok := parseWebhookCommand(repo.Command, attrs.Note)
+		if !ok {
+			return nil, nil
+		}
+		if !webhookAuthorAllowed(repo, p.User.Username, false) {
+			return nil, fmt.Errorf("%w: %s", errWebhookAuthor, p.User.Username)
+		}
+		base["event"] = "comment"
+		base["number"] = target.IID
+		base["title"] = target.Title
+		base["body"] = target.Description
+		base["url"] = target.URL
+		base["command"] = command
+		base["comment"] = attrs.Note
+		base["comment_url"] = attrs.URL
+		base["is_pull_request"] = attrs.NoteableType == "MergeRequest"
+		base["prompt"] = commentPrompt(command, target.IID, target.Title, target.Description)
+		return newWebhookJob(repo, repo.CommentFlow, base), nil
+	}
+	return nil, nil
+}
+
+// webhookAuthorAllowed reports whether author may trigger a run. A
+// configured allowedAuthors list decides on its own; without one the
+// provider's own signal (trusted) decides.
+func webhookAuthorAllowed(repo config.WebhookRepo, author string, trusted bool) bool {
+	if len(repo.AllowedAuthors) == 0 {
+		return trusted
+	}
+	return author != "" && slices.ContainsFunc(repo.AllowedAuthors, func(a string) bool {
+		return strings.EqualFold(a, author)
+	})
+}
+
+func hasGitLabLabel(labels []gitlabLabel, name string) bool {
+	return slices.ContainsFunc(labels, func(l gitlabLabel) bool { return l.Title == name })
+}
+
+// newWebhookJob layers the event args over the repo's configured args.
+func newWebhookJob(repo config.WebhookRepo, flowID string, eventArgs map[string]any) *webhookJob {
+	args := make(map[string]any, len(repo.Args)+len(eventArgs))
+	maps.Copy(args, repo.Args)
+	maps.Copy(args, eventArgs)
+	return &webhookJob{flowID: flowID, args: args}
+}
+
+// parseWebhookCommand returns the text after prefix when the comment's
+// first line starts with it ("/opencode fix tests" → "fix tests").
+func parseWebhookCommand(prefix, comment string) (string, bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(comment), "\n")
+	rest, ok := strings.CutPrefix(line, prefix)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+func issuePrompt(number int, title, body string) string {
+	return fmt.Sprintf("Issue #%d: %s\n\n%s", number, title, body)
+}
+
+func commentPrompt(command string, number int, title, body string) string {
+	return fmt.Sprintf("%s\n\nOn #%d: %s\n\n%s", command, number, title, body)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/flow"
+)
+
+func newWebhookTestServer(t *testing.T, svc *stubFlowService) *httptest.Server {
+	t.Helper()
+	t.Setenv("TEST_WEBHOOK_SECRET", "s3cret")
+	fr := newFlowRunner(svc)
+	fr.validateFlowID = nil
+	wr := newWebhookReceiver(&config.WebhooksConfig{Repos: []config.WebhookRepo{
+		{Name: "acme/app", Provider: "github", Secret: "${TEST_WEBHOOK_SECRET}", IssueFlow: "fix-issue", CommentFlow: "pr-command", Args: map[string]any{"base": "main"}},
+		{Name: "group/sub/app", Provider: "gitlab", Secret: "tok", IssueFlow: "fix-issue", AllowedAuthors: []string{"Maintainer"}},
+	}}, fr)
+	if wr == nil {
+		t.Fatal("webhook receiver not created")
+	}
+	s := &Server{flowRunner: fr, webhooks: wr}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook/github", s.handleWebhookGitHub)
+	mux.HandleFunc("POST /webhook/gitlab", s.handleWebhookGitLab)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func signGitHub(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(t *testing.T, server *httptest.Server, path, body string, headers map[string]string) int {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", path, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func waitForRunArgs(t *testing.T, svc *stubFlowService) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		svc.mu.Lock()
+		args := svc.lastArgs
+		svc.mu.Unlock()
+		if args != nil {
+			return args
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("queued webhook run never started")
+	return nil
+}
+
+func TestWebhookGitHubLabeledIssueStartsFlow(t *testing.T) {
+	svc := newStubFlowService([]flow.FlowState{{StepID: "s1", Status: flow.FlowStatusCompleted}})
+	server := newWebhookTestServer(t, svc)
+
+	body := `{"action":"labeled","label":{"name":"opencode"},"issue":{"number":7,"title":"Crash on start","body":"stack trace"},"repository":{"full_name":"acme/app"},"sender":{"login":"octo"}}`
+	code := postWebhook(t, server, "/webhook/github", body, map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-Hub-Signature-256": signGitHub("s3cret", body),
+	})
+	if code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	args := waitForRunArgs(t, svc)
+	if args["number"] != 7 || args["event"] != "issue" || args["base"] != "main" {
+		t.Errorf("args = %+v", args)
+	}
+	if !strings.Contains(args["prompt"].(string), "Crash on start") {
+		t.Errorf("prompt = %q", args["prompt"])
+	}
+}
+
+func TestWebhookGitHubRejectsBadSignature(t *testing.T) {
+	svc := newStubFlowService(nil)
+	server := newWebhookTestServer(t, svc)
+
+	body := `{"action":"labeled","label":{"name":"opencode"},"repository":{"full_name":"acme/app"}}`
+	code := postWebhook(t, server, "/webhook/github", body, map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-Hub-Signature-256": signGitHub("wrong", body),
+	})
+	if code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", code)
+	}
+	unknown := `{"repository":{"full_name":"other/repo"}}`
+	code = postWebhook(t, server, "/webhook/github", unknown, map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-Hub-Signature-256": signGitHub("s3cret", unknown),
+	})
+	if code != http.StatusNotFound {
+		t.Errorf("unknown repo status = %d, want 404", code)
+	}
+}
+
+func TestWebhookSkipsRepoWithUnsetSecret(t *testing.T) {
+	t.Setenv("UNSET_WEBHOOK_SECRET", "")
+	fr := newFlowRunner(newStubFlowService(nil))
+	wr := newWebhookReceiver(&config.WebhooksConfig{Repos: []config.WebhookRepo{
+		{Name: "acme/app", Provider: "github", Secret: "${UNSET_WEBHOOK_SECRET}", IssueFlow: "fix-issue"},
+	}}, fr)
+	if wr != nil {
+		t.Fatalf("repo with a secret that expands to empty was accepted: %+v", wr.repos)
+	}
+}
+
+func TestWebhookGitHubCommentCommand(t *testing.T) {
+	svc := newStubFlowService([]flow.FlowState{{StepID: "s1", Status: flow.FlowStatusCompleted}})
+	server := newWebhookTestServer(t, svc)
+
+	ignored := `{"action":"created","comment":{"body":"/opencodex nope"},"issue":{"number":3},"repository":{"full_name":"acme/app"}}`
+	code := postWebhook(t, server, "/webhook/github", ignored, map[string]string{
+		"X-GitHub-Event":      "issue_comment",
+		"X-Hub-Signature-256": signGitHub("s3cret", ignored),
+	})
+	if code != http.StatusOK {
+		t.Errorf("non-command comment status = %d, want 200", code)
+	}
+
+	outsider := `{"action":"created","comment":{"body":"/opencode leak secrets","author_association":"NONE"},"issue":{"number":3},"repository":{"full_name":"acme/app"},"sender":{"login":"drive-by"}}`
+	code = postWebhook(t, server, "/webhook/github", outsider, map[string]string{
+		"X-GitHub-Event":      "issue_comment",
+		"X-Hub-Signature-256": signGitHub("s3cret", outsider),
+	})
+	if code != http.StatusForbidden {
+		t.Errorf("outsider comment status = %d, want 403", code)
+	}
+
+	body := `{"action":"created","comment":{"body":"/opencode fix tests\nplease","author_association":"MEMBER"},"issue":{"number":3,"title":"Add cache","pull_request":{"url":"x"}},"repository":{"full_name":"acme/app"},"sender":{"login":"octo"}}`
+	code = postWebhook(t, server, "/webhook/github", body, map[string]string{
+		"X-GitHub-Event":      "issue_comment",
+		"X-Hub-Signature-256": signGitHub("s3cret", body),
+	})
+	if code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	args := waitForRunArgs(t, svc)
+	if args["command"] != "fix tests" || args["is_pull_request"] != true {
+		t.Errorf("args = %+v", args)
+	}
+}
+
+func TestWebhookGitLabLabelTransition(t *testing.T) {
+	svc := newStubFlowService([]flow.FlowState{{StepID: "s1", Status: flow.FlowStatusCompleted}})
+	server := newWebhookTestServer(t, svc)
+	headers := map[string]string{"X-Gitlab-Event": "Issue Hook", "X-Gitlab-Token": "tok"}
+
+	// Label already present before this update: no new run.
+	edit := `{"object_attributes":{"iid":5,"title":"t"},"changes":{"labels":{"previous":[{"title":"opencode"}],"current":[{"title":"opencode"},{"title":"bug"}]}},"project":{"path_with_namespace":"group/sub/app"}}`
+	if code := postWebhook(t, server, "/webhook/gitlab", edit, headers); code != http.StatusOK {
+		t.Errorf("edit status = %d, want 200", code)
+	}
+
+	added := `{"object_attributes":{"iid":5,"title":"t"},"changes":{"labels":{"previous":[],"current":[{"title":"opencode"}]}},"project":{"path_with_namespace":"group/sub/app"},"user":{"username":"maintainer"}}`
+	if code := postWebhook(t, server, "/webhook/gitlab", added, map[string]string{"X-Gitlab-Event": "Issue Hook", "X-Gitlab-Token": "bad"}); code != http.StatusUnauthorized {
+		t.Errorf("bad token status = %d, want 401", code)
+	}
+	if code := postWebhook(t, server, "/webhook/gitlab", added, headers); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	if args := waitForRunArgs(t, svc); args["number"] != 5 || args["provider"] != "gitlab" {
+		t.Errorf("args = %+v", args)
+	}
+}
+
+func TestWebhookGitLabCommentAuthor(t *testing.T) {
+	svc := newStubFlowService([]flow.FlowState{{StepID: "s1", Status: flow.FlowStatusCompleted}})
+	server := newWebhookTestServer(t, svc)
+	headers := map[string]string{"X-Gitlab-Event": "Note Hook", "X-Gitlab-Token": "tok"}
+
+	outsider := `{"object_attributes":{"note":"/opencode run","noteable_type":"Issue"},"issue":{"iid":5,"title":"t"},"project":{"path_with_namespace":"group/sub/app"},"user":{"username":"drive-by"}}`
+	if code := postWebhook(t, server, "/webhook/gitlab", outsider, headers); code != http.StatusForbidden {
+		t.Errorf("outsider status = %d, want 403", code)
+	}
+	allowed := `{"object_attributes":{"note":"/opencode run","noteable_type":"Issue"},"issue":{"iid":5,"title":"t"},"project":{"path_with_namespace":"group/sub/app"},"user":{"username":"maintainer"}}`
+	if code := postWebhook(t, server, "/webhook/gitlab", allowed, headers); code != http.StatusAccepted {
+		t.Fatalf("allowed status = %d, want 202", code)
+	}
+	if args := waitForRunArgs(t, svc); args["author"] != "maintainer" || args["command"] != "run" {
+		t.Errorf("args = %+v", args)
+	}
+}
+
+func TestWebhookAuthorAllowed(t *testing.T) {
+	open := config.WebhookRepo{}
+	listed := config.WebhookRepo{AllowedAuthors: []string{"Octo"}}
+	if !webhookAuthorAllowed(open, "anyone", true) || webhookAuthorAllowed(open, "anyone", false) {
+		t.Error("without a list the provider signal should decide")
+	}
+	if !webhookAuthorAllowed(listed, "octo", false) {
+		t.Error("listed author should be allowed regardless of association")
+	}
+	if webhookAuthorAllowed(listed, "other", true) || webhookAuthorAllowed(listed, "", true) {
+		t.Error("unlisted author should be rejected when a list is configured")
+	}
+}
+
+func TestParseWebhookCommand(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    string
+		ok      bool
+	}{
+		{"/opencode fix tests", "fix tests", true},
+		{"  /opencode\tupdate docs\nmore", "update docs", true},
+		{"/opencode", "", true},
+		{"/opencodefix", "", false},
+		{"please /opencode fix", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseWebhookCommand("/opencode", tt.comment)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseWebhookCommand(%q) = %q, %v; want %q, %v", tt.comment, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/logging"
@@ -34,7 +35,11 @@ func corsMiddleware(allowOrigin string) func(http.Handler) http.Handler {
 func authMiddleware(password string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if password == "" {
+			// Webhook deliveries can't send Basic auth; their handlers
+			// verify the per-repo signature instead. Share links carry
+			// their own token and only ever serve GETs.
+			if password == "" || strings.HasPrefix(r.URL.Path, "/webhook/") ||
+				(r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/share/")) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/version"
 )
@@ -19,6 +20,8 @@ type ServerOptions struct {
 	Port       int
 	Hostname   string
 	CORSOrigin string
+	// NoUI leaves out the embedded web UI at /ui/.
+	NoUI bool
 	// Bridge, when non-nil, registers its HTTP routes under /router/*
 	// on the same mux. The orchestrator owns the handlers — the API
 	// server only forwards mux registration. Routes outside /router/*
@@ -44,6 +47,9 @@ type Server struct {
 	corsOrigin     string
 	healthReporter HealthReporter
 	flowRunner     *flowRunner
+	webhooks       *webhookReceiver
+	shares         *shareRegistry
+	ui             bool
 }
 
 // NewServer creates a new API server.
@@ -53,6 +59,8 @@ func NewServer(application *app.App, opts ServerOptions) *Server {
 		port:     opts.Port,
 		hostname: opts.Hostname,
 		password: os.Getenv("OPENCODE_SERVER_PASSWORD"),
+		shares:   newShareRegistry(),
+		ui:       !opts.NoUI,
 	}
 
 	// Flow runner: a single-flow-at-a-time tracker driven by /flow/*
@@ -61,9 +69,16 @@ func NewServer(application *app.App, opts ServerOptions) *Server {
 	if application != nil && application.Flows != nil {
 		s.flowRunner = newFlowRunnerWithSessions(application.Flows, application.Sessions)
 	}
+	if cfg := config.Get(); cfg != nil {
+		s.webhooks = newWebhookReceiver(cfg.Webhooks, s.flowRunner)
+	}
 
 	mux := http.NewServeMux()
 	s.registerRoutes(mux)
+	if !opts.NoUI {
+		mux.Handle("GET /ui/", uiHandler())
+		mux.HandleFunc("GET /{$}", s.handleUIRedirect)
+	}
 	if opts.Bridge != nil {
 		opts.Bridge.RegisterRoutes(mux)
 		// If the bridge also satisfies HealthReporter (the bridge service
@@ -112,7 +127,10 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /session/{sessionID}", s.handleSessionDelete)
 	mux.HandleFunc("PATCH /session/{sessionID}", s.handleSessionUpdate)
 	mux.HandleFunc("GET /session/{sessionID}/children", s.handleSessionChildren)
+	mux.HandleFunc("POST /session/{sessionID}/fork", s.handleSessionFork)
+	mux.HandleFunc("POST /session/{sessionID}/merge", s.handleSessionMerge)
 	mux.HandleFunc("POST /session/{sessionID}/abort", s.handleSessionAbort)
+	mux.HandleFunc("POST /session/{sessionID}/cancel", s.handleSessionCancelBranch)
 	mux.HandleFunc("POST /session/{sessionID}/permissions/{permissionID}", s.handlePermissionRespond)
 
 	// Messages & prompts
@@ -121,10 +139,26 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /session/{sessionID}/message", s.handleSessionPrompt)
 	mux.HandleFunc("POST /session/{sessionID}/prompt_async", s.handleSessionPromptAsync)
 	mux.HandleFunc("POST /session/{sessionID}/summarize", s.handleSessionSummarize)
+	mux.HandleFunc("GET /session/{sessionID}/context", s.handleSessionContext)
+	mux.HandleFunc("PUT /session/{sessionID}/context/exclude", s.handleSessionContextExclude)
+	mux.HandleFunc("GET /session/{sessionID}/usage", s.handleSessionUsage)
+
+	// Read-only live links. The /share/{token} routes are authenticated
+	// by the token instead of the server password (see authMiddleware).
+	mux.HandleFunc("POST /session/{sessionID}/share", s.handleShareCreate)
+	mux.HandleFunc("GET /session/{sessionID}/share", s.handleShareGet)
+	mux.HandleFunc("DELETE /session/{sessionID}/share", s.handleShareRevoke)
+	mux.HandleFunc("GET /share/{token}", s.handleSharePage)
+	mux.HandleFunc("GET /share/{token}/session", s.handleShareSession)
+	mux.HandleFunc("GET /share/{token}/message", s.handleShareMessages)
+	mux.HandleFunc("GET /share/{token}/event", s.handleShareEvents)
 
 	// Todos
 	mux.HandleFunc("GET /session/{sessionID}/todo", s.handleSessionTodo)
 
+	// Agent blackboard
+	mux.HandleFunc("GET /session/{sessionID}/blackboard", s.handleSessionBlackboard)
+
 	// Config
 	mux.HandleFunc("GET /config", s.handleConfigGet)
 	mux.HandleFunc("GET /config/providers", s.handleConfigProviders)
@@ -166,7 +200,20 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /flow", s.handleFlowList)
 	mux.HandleFunc("POST /flow", s.handleFlowStart)
 	mux.HandleFunc("GET /flow/status", s.handleFlowStatus)
+	mux.HandleFunc("POST /flow/approve", s.handleFlowApprove)
 	mux.HandleFunc("DELETE /flow", s.handleFlowAbort)
+
+	// Persistent run queue, drained by the dispatcher `opencode serve`
+	// starts.
+	mux.HandleFunc("GET /queue", s.handleQueueList)
+	mux.HandleFunc("POST /queue", s.handleQueueSubmit)
+	mux.HandleFunc("GET /queue/{runID}", s.handleQueueGet)
+	mux.HandleFunc("DELETE /queue/{runID}", s.handleQueueCancel)
+
+	// Git hosting webhooks. Authenticated by per-repo signature instead
+	// of the server password (see authMiddleware).
+	mux.HandleFunc("POST /webhook/github", s.handleWebhookGitHub)
+	mux.HandleFunc("POST /webhook/gitlab", s.handleWebhookGitLab)
 }
 
 // Start starts the HTTP server. It blocks until the server is shut down.
@@ -195,6 +242,9 @@ func (s *Server) Start(ctx context.Context) error {
 	fmt.Fprintf(os.Stderr, "  Version:    %s\n", version.Version)
 	fmt.Fprintf(os.Stderr, "  Auth:       %s\n", auth)
 	fmt.Fprintf(os.Stderr, "  CORS:       %s\n", s.corsOrigin)
+	if s.ui {
+		fmt.Fprintf(os.Stderr, "  Web UI:     %s/ui/\n", url)
+	}
 
 	// Router/bridge status. Three cases:
 	//   1. No bridge wired (cfg.Router == nil) → don't print anything.
@@ -40,6 +40,8 @@ type APIMessageInfo struct {
 	Tokens     APIMessageTokens `json:"tokens"`
 	Cost       float64          `json:"cost"`
 	Time       APIMessageTime   `json:"time"`
+	// Pinned messages are kept verbatim through compaction.
+	Pinned bool `json:"pinned,omitempty"`
 }
 
 // APIMessageTokens holds token usage breakdown for a message.
@@ -79,11 +81,29 @@ type APIPart struct {
 
 	// For text and reasoning parts
 	Text string `json:"text,omitempty"`
+	// Citations link byte ranges of Text to the tool calls backing them.
+	Citations []APICitation `json:"citations,omitempty"`
 
 	// For tool parts
 	Tool   string        `json:"tool,omitempty"`
 	CallID string        `json:"callID,omitempty"`
 	State  *APIToolState `json:"state,omitempty"`
+
+	// For file parts holding an image the model produced: a file:// URL
+	// on the server's machine and the image's MIME type.
+	URL  string `json:"url,omitempty"`
+	Mime string `json:"mime,omitempty"`
+}
+
+// APICitation links a byte range of a text part to a tool result.
+type APICitation struct {
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	CallID    string `json:"callID"`
+	Tool      string `json:"tool"`
+	Path      string `json:"path,omitempty"`
+	StartLine int    `json:"startLine,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
 }
 
 // APIToolState represents the execution state of a tool call.
@@ -144,6 +164,20 @@ type APIPermissionRequest struct {
 	Path        string `json:"path"`
 }
 
+// APIAgentEvent is the SDK-facing form of an agent.AgentEvent, streamed
+// as agent.response / agent.error / agent.summarize SSE frames.
+type APIAgentEvent struct {
+	SessionID    string `json:"sessionID"`
+	Agent        string `json:"agent"`
+	Type         string `json:"type"`
+	MessageID    string `json:"messageID,omitempty"`
+	Error        string `json:"error,omitempty"`
+	Progress     string `json:"progress,omitempty"`
+	Done         bool   `json:"done"`
+	StructOutput string `json:"structOutput,omitempty"`
+	FlowStepID   string `json:"flowStepID,omitempty"`
+}
+
 // APIPermissionReply is the request body for replying to a permission request.
 // Supports two shapes:
 //   - Legacy OpenWork: {"allow": true|false}
@@ -256,6 +290,24 @@ type APISessionUpdateRequest struct {
 	Permission []APIPermissionRule `json:"permission,omitempty"`
 }
 
+// APISessionForkRequest is the request body for forking a session.
+type APISessionForkRequest struct {
+	MessageID string `json:"messageID"`
+}
+
+// APISessionMergeRequest is the request body for merging a side session
+// into a session.
+type APISessionMergeRequest struct {
+	SourceID string `json:"sourceID"`
+}
+
+// APISessionMergeResponse reports what a merge added to the session.
+type APISessionMergeResponse struct {
+	MessageID  string   `json:"messageID"`
+	Files      []string `json:"files"`
+	Summarized bool     `json:"summarized"`
+}
+
 // APIPermissionRule mirrors the dax SDK PermissionRule shape so SDK clients
 // can pass through their wildcard-allow rules. Only a single shape is honored
 // today (see shouldAutoApprove); other rules are silently ignored.
@@ -0,0 +1,307 @@
+// Read-only web UI for `opencode serve`: the session list, the transcript
+// of the selected session, and live updates from the /event stream. It
+// only ever issues GET requests.
+(function () {
+  "use strict";
+
+  var state = {
+    sessions: [],
+    status: {},
+    selected: null,
+    messages: [], // APIMessageResponse, oldest first
+    open: {}, // part IDs of expanded <details>
+  };
+
+  var els = {
+    sessions: document.getElementById("sessions"),
+    transcript: document.getElementById("transcript"),
+    filter: document.getElementById("filter"),
+    live: document.getElementById("live"),
+    flow: document.getElementById("flow"),
+  };
+
+  function get(path) {
+    return fetch(path, { headers: { Accept: "application/json" } }).then(function (r) {
+      if (!r.ok) throw new Error(path + ": " + r.status);
+      return r.json();
+    });
+  }
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) node.className = className;
+    if (text !== undefined && text !== null) node.textContent = text;
+    return node;
+  }
+
+  // debounce collapses bursts of events into one refresh.
+  function debounce(fn, ms) {
+    var timer = null;
+    return function () {
+      if (timer) return;
+      timer = setTimeout(function () { timer = null; fn(); }, ms);
+    };
+  }
+
+  function formatTime(ms) {
+    return ms ? new Date(ms).toLocaleString() : "";
+  }
+
+  function formatCost(usd) {
+    return usd ? "$" + usd.toFixed(usd < 1 ? 4 : 2) : "";
+  }
+
+  // Sessions
+
+  function loadSessions() {
+    return Promise.all([get("/session"), get("/session/status").catch(function () { return {}; })])
+      .then(function (res) {
+        state.sessions = res[0] || [];
+        state.status = res[1] || {};
+        renderSessions();
+      })
+      .catch(function (err) { console.error(err); });
+  }
+
+  function renderSessions() {
+    var query = els.filter.value.trim().toLowerCase();
+    var byParent = {};
+    state.sessions.forEach(function (s) {
+      var key = s.parentID || "";
+      (byParent[key] = byParent[key] || []).push(s);
+    });
+    Object.keys(byParent).forEach(function (k) {
+      byParent[k].sort(function (a, b) { return b.time.updated - a.time.updated; });
+    });
+
+    els.sessions.textContent = "";
+    (byParent[""] || []).forEach(function (root) {
+      var children = byParent[root.id] || [];
+      var matches = !query || (root.title || "").toLowerCase().indexOf(query) >= 0 ||
+        children.some(function (c) { return (c.title || "").toLowerCase().indexOf(query) >= 0; });
+      if (!matches) return;
+      els.sessions.appendChild(sessionItem(root, false));
+      // Subagent and flow step sessions are listed under their parent
+      // while it is selected or one of them is.
+      var open = state.selected === root.id || children.some(function (c) { return c.id === state.selected; });
+      if (open) children.forEach(function (c) { els.sessions.appendChild(sessionItem(c, true)); });
+    });
+    if (!els.sessions.firstChild) els.sessions.appendChild(el("li", "empty", "No sessions."));
+  }
+
+  function sessionItem(s, child) {
+    var li = el("li", child ? "child" : "");
+    var a = el("a");
+    a.href = "#/session/" + encodeURIComponent(s.id);
+    if (s.id === state.selected) a.className = "selected";
+    var title = el("span", "title", s.title || s.id);
+    if (state.status[s.id] && state.status[s.id].type === "busy") title.className += " busy";
+    a.appendChild(title);
+    var meta = [formatTime(s.time.updated), formatCost(s.cost)].filter(Boolean).join(" · ");
+    a.appendChild(el("span", "meta", meta));
+    li.appendChild(a);
+    return li;
+  }
+
+  // Transcript
+
+  function selectSession(id) {
+    state.selected = id;
+    state.messages = [];
+    renderSessions();
+    if (!id) {
+      els.transcript.textContent = "";
+      els.transcript.appendChild(el("p", "empty", "Select a session to see its transcript."));
+      return;
+    }
+    get("/session/" + encodeURIComponent(id) + "/message")
+      .then(function (msgs) {
+        if (state.selected !== id) return;
+        state.messages = msgs || [];
+        renderTranscript(true);
+      })
+      .catch(function () {
+        els.transcript.textContent = "";
+        els.transcript.appendChild(el("p", "empty", "Session not found."));
+      });
+  }
+
+  function currentSession() {
+    for (var i = 0; i < state.sessions.length; i++) {
+      if (state.sessions[i].id === state.selected) return state.sessions[i];
+    }
+    return null;
+  }
+
+  function renderTranscript(scrollToEnd) {
+    var box = els.transcript;
+    var atBottom = scrollToEnd || box.scrollTop + box.clientHeight >= box.scrollHeight - 40;
+    // Keep expanded tool calls and thinking blocks open across re-renders.
+    state.open = {};
+    box.querySelectorAll("details[open]").forEach(function (d) { state.open[d.dataset.id] = true; });
+    box.textContent = "";
+
+    var s = currentSession();
+    box.appendChild(el("h2", "", s ? s.title || s.id : state.selected));
+    if (s) {
+      var summary = [
+        "Updated " + formatTime(s.time.updated),
+        s.token.input + s.token.output + " tokens",
+        formatCost(s.cost),
+        state.status[s.id] && state.status[s.id].type === "busy" ? "running" : "idle",
+      ].filter(Boolean).join(" · ");
+      box.appendChild(el("div", "summary", summary));
+      var children = state.sessions.filter(function (c) { return c.parentID === s.id; });
+      if (children.length) {
+        var links = el("div", "summary children", "Subsessions: ");
+        children.forEach(function (c) {
+          var a = el("a", "", c.title || c.id);
+          a.href = "#/session/" + encodeURIComponent(c.id);
+          links.appendChild(a);
+        });
+        box.appendChild(links);
+      }
+    }
+
+    var shown = 0;
+    state.messages.forEach(function (m) {
+      var node = renderMessage(m);
+      if (node) { box.appendChild(node); shown++; }
+    });
+    if (!shown) box.appendChild(el("p", "empty", "No messages yet."));
+    if (atBottom) box.scrollTop = box.scrollHeight;
+  }
+
+  function renderMessage(m) {
+    var parts = (m.parts || []).filter(function (p) {
+      return p.type === "tool" || (p.text && p.text.trim());
+    });
+    if (!parts.length) return null;
+    var info = m.info;
+    var div = el("div", "msg " + info.role);
+    var role = info.role + (info.modelID ? " · " + info.modelID : "");
+    var cost = formatCost(info.cost);
+    div.appendChild(el("div", "role", role + (cost ? " · " + cost : "")));
+    parts.forEach(function (p) { div.appendChild(renderPart(p)); });
+    return div;
+  }
+
+  function renderPart(p) {
+    if (p.type === "reasoning") {
+      var d = el("details");
+      d.dataset.id = p.id;
+      d.open = !!state.open[p.id];
+      d.appendChild(el("summary", "", "Thinking"));
+      d.appendChild(el("pre", "", p.text));
+      return d;
+    }
+    if (p.type === "tool") {
+      var st = p.state || {};
+      var tool = el("details", "tool" + (st.status === "error" ? " error" : ""));
+      tool.dataset.id = p.id;
+      tool.open = !!state.open[p.id];
+      var sum = el("summary", "", p.tool + (st.title ? ": " + st.title : ""));
+      sum.appendChild(el("span", "status", st.status || ""));
+      tool.appendChild(sum);
+      if (st.input) tool.appendChild(el("pre", "", JSON.stringify(st.input, null, 2)));
+      if (st.output) tool.appendChild(el("pre", "", st.output));
+      if (st.error) tool.appendChild(el("pre", "", st.error));
+      return tool;
+    }
+    return el("pre", "", p.text);
+  }
+
+  // Live updates
+
+  function upsertMessage(resp) {
+    if (resp.info.sessionID !== state.selected) return false;
+    for (var i = 0; i < state.messages.length; i++) {
+      if (state.messages[i].info.id === resp.info.id) {
+        state.messages[i] = resp;
+        return true;
+      }
+    }
+    state.messages.push(resp);
+    return true;
+  }
+
+  function upsertPart(part) {
+    if (part.sessionID !== state.selected) return false;
+    for (var i = 0; i < state.messages.length; i++) {
+      var m = state.messages[i];
+      if (m.info.id !== part.messageID) continue;
+      m.parts = m.parts || [];
+      for (var j = 0; j < m.parts.length; j++) {
+        if (m.parts[j].id === part.id) {
+          m.parts[j] = part;
+          return true;
+        }
+      }
+      m.parts.push(part);
+      return true;
+    }
+    return false;
+  }
+
+  function loadFlow() {
+    get("/flow/status")
+      .then(function (snap) {
+        if (!snap || snap.status === "idle") {
+          els.flow.hidden = true;
+          return;
+        }
+        var step = snap.currentStep ? " · " + snap.currentStep.id : "";
+        els.flow.textContent = "Flow " + snap.flowID + ": " + snap.status + step;
+        els.flow.hidden = false;
+      })
+      .catch(function () { els.flow.hidden = true; });
+  }
+
+  var refreshSessions = debounce(function () {
+    loadSessions().then(function () { if (state.selected) renderTranscript(false); });
+  }, 500);
+  var refreshTranscript = debounce(function () { renderTranscript(false); }, 100);
+  var refreshFlow = debounce(loadFlow, 500);
+
+  function connect() {
+    var events = new EventSource("/event");
+    events.onopen = function () {
+      els.live.textContent = "live";
+      els.live.className = "live on";
+    };
+    events.onerror = function () {
+      els.live.textContent = "reconnecting…";
+      els.live.className = "live";
+    };
+    events.onmessage = function (e) {
+      var ev;
+      try { ev = JSON.parse(e.data); } catch (err) { return; }
+      var type = ev.type || "";
+      var props = ev.properties || {};
+      if (type === "message.created" || type === "message.updated") {
+        if (props.info && upsertMessage(props)) refreshTranscript();
+      } else if (type === "message.deleted") {
+        var before = state.messages.length;
+        state.messages = state.messages.filter(function (m) { return !props.info || m.info.id !== props.info.id; });
+        if (state.messages.length !== before) refreshTranscript();
+      } else if (type === "message.part.updated") {
+        if (props.part && upsertPart(props.part)) refreshTranscript();
+      } else if (type.indexOf("session.") === 0 || type.indexOf("agent.") === 0) {
+        refreshSessions();
+      } else if (type.indexOf("flow.") === 0) {
+        refreshFlow();
+      }
+    };
+  }
+
+  function route() {
+    var match = location.hash.match(/^#\/session\/(.+)$/);
+    selectSession(match ? decodeURIComponent(match[1]) : null);
+  }
+
+  els.filter.addEventListener("input", renderSessions);
+  window.addEventListener("hashchange", route);
+  loadSessions().then(route);
+  loadFlow();
+  connect();
+})();
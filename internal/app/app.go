@@ -8,12 +8,17 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/blackboard"
+	"github.com/opencode-ai/opencode/internal/codesearch"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/cron"
 	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/feedback"
 	"github.com/opencode-ai/opencode/internal/flow"
 	"github.com/opencode-ai/opencode/internal/history"
 	"github.com/opencode-ai/opencode/internal/hooks"
@@ -21,13 +26,17 @@ import (
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/question"
 	"github.com/opencode-ai/opencode/internal/recap"
+	"github.com/opencode-ai/opencode/internal/runqueue"
+	"github.com/opencode-ai/opencode/internal/runtimestate"
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/task"
 	"github.com/opencode-ai/opencode/internal/todo"
+	"github.com/opencode-ai/opencode/internal/translation"
 	"github.com/opencode-ai/opencode/internal/tui/theme"
 )
 
@@ -36,14 +45,22 @@ type App struct {
 	Messages      message.Service
 	History       history.Service
 	Recaps        recap.Service
+	Feedback      feedback.Service
 	Permissions   permission.Service
 	Registry      agentregistry.Registry
 	MCPRegistry   agent.MCPRegistry
 	Flows         flow.Service
 	Crons         cron.Service
 	CronScheduler *cron.Scheduler
+	RunQueue      runqueue.Service
+	RunDispatcher *runqueue.Dispatcher // nil unless StartRunQueue was called
 	Todos         *todo.Store
-	Questions     question.Service // nil in non-interactive mode
+	RuntimeState  runtimestate.Service
+	Memories      memory.Service
+	Blackboard    blackboard.Service
+	CodeIndex     *codesearch.Index // nil unless codeSearch is configured
+	Questions     question.Service  // nil in non-interactive mode
+	Translations  translation.Service
 	AgentFactory  agent.AgentFactory
 	LspService    lsp.LspService
 
@@ -65,12 +82,32 @@ type App struct {
 	// updates again after the first Update call.
 	activeSessionID atomic.Value // stores string
 
+	// restoredSessions records sessions whose runtime snapshot has already
+	// been looked up, so switching back and forth restores it only once.
+	restoredSessions sync.Map
+	// saveRuntimeOnce guards the snapshot taken on exit. Shutdown can run
+	// more than once, and after ForceShutdown the shell and monitors are
+	// already killed, so it consumes the once without saving.
+	saveRuntimeOnce sync.Once
+
+	// hookRegistry and hookSessions back the SessionStart / SessionEnd
+	// hooks; hookSessions holds the sessions started in this process that
+	// haven't ended yet. See hooks.go.
+	hookRegistry *hooks.Registry
+	hookSessions sync.Map
+
 	cliOutputSchema map[string]any
 }
 
 // SetActiveSessionID is called by the TUI whenever the selected session changes.
 func (app *App) SetActiveSessionID(id string) {
 	app.activeSessionID.Store(id)
+	if id == "" || app.RuntimeState == nil {
+		return
+	}
+	if _, seen := app.restoredSessions.LoadOrStore(id, struct{}{}); !seen {
+		go app.restoreRuntimeState(id)
+	}
 }
 
 // ActiveSessionID returns the session currently visible in the TUI.
@@ -134,6 +171,7 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 	messages := message.NewService(q, conn)
 	files := history.NewService(q, conn)
 	recaps := recap.NewService(q)
+	feedbacks := feedback.NewService(q)
 	reg := agentregistry.GetRegistry()
 	perm := permission.NewPermissionService()
 	lspSvc := NewLspService()
@@ -141,6 +179,18 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 	factory := agent.NewAgentFactory(sessions, messages, perm, files, lspSvc, reg, mcpRegistry)
 	todoStore := todo.NewStore()
 	factory.SetTodoStore(todoStore)
+	board := blackboard.NewService(q)
+	factory.SetBlackboard(board)
+	codeIndex := newCodeIndex(q, projectID)
+	if codeIndex != nil {
+		factory.SetCodeIndex(codeIndex)
+		go codeIndex.Watch(ctx, files)
+	}
+	memories := memory.NewService(q, queueProjectID(projectID))
+	memory.SetDefault(memories)
+	factory.SetMemory(memories)
+	translations := translation.NewService()
+	factory.SetTranslationService(translations)
 	flows := flow.NewService(sessions, messages, q, perm, factory)
 
 	// Hook registry: reads the `hooks` block from .opencode.json on
@@ -188,11 +238,31 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 		factory.SetQuestionService(questionSvc)
 	}
 
+	// A configured safety reviewer that fails to start must not silently
+	// degrade into plain auto-approve for unattended runs.
+	if appCfg != nil && appCfg.Permission != nil && appCfg.Permission.Review != nil {
+		reviewer, err := agent.NewPermissionReviewer(appCfg.Permission.Review)
+		if err != nil {
+			return nil, err
+		}
+		perm.SetReviewer(reviewer)
+	}
+
+	// A compliance trail that cannot be written must stop the run rather
+	// than let it proceed unaudited.
+	if err := audit.Init(appCfg); err != nil {
+		return nil, fmt.Errorf("failed to open audit trail: %w", err)
+	}
+	if audit.Enabled() {
+		audit.UseStore(q)
+	}
+
 	app := &App{
 		Sessions:      sessions,
 		Messages:      messages,
 		History:       files,
 		Recaps:        recaps,
+		Feedback:      feedbacks,
 		Permissions:   perm,
 		Registry:      reg,
 		LspService:    lspSvc,
@@ -201,9 +271,16 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 		AgentFactory:  factory,
 		Flows:         flows,
 		Crons:         cronSvc,
+		RunQueue:      runqueue.NewService(q, queueProjectID(projectID)),
 		Todos:         todoStore,
+		RuntimeState:  runtimestate.NewService(q),
+		Blackboard:    board,
+		CodeIndex:     codeIndex,
+		Memories:      memories,
 		Questions:     questionSvc,
+		Translations:  translations,
 	}
+	app.watchSessionHooks(ctx, factory.HookRegistry())
 
 	// Install the global background-task registry. EnqueueTaskCompletion
 	// (used by bash run_in_background, task async, monitor, and the cron
@@ -250,7 +327,7 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 
 	// Create and start the cron scheduler after primary agents are ready
 	if cronSvc != nil {
-		taskTool := agent.NewAgentTool(sessions, perm, reg, factory)
+		taskTool := agent.NewAgentTool(sessions, messages, perm, reg, factory)
 		taskRunner := cron.NewTaskToolRunner(taskTool)
 		busyChecker := cron.NewAppBusyChecker(
 			func(sessionID string) bool {
@@ -306,6 +383,18 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 	return app, nil
 }
 
+// queueProjectID resolves the project queued runs default to, mirroring
+// how the session service scopes sessions.
+func queueProjectID(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if cfg := config.Get(); cfg != nil {
+		return db.GetProjectID(cfg.WorkingDir)
+	}
+	return ""
+}
+
 // initTheme sets the application theme based on the configuration
 func (app *App) initTheme() {
 	cfg := config.Get()
@@ -323,9 +412,14 @@ func (app *App) initTheme() {
 
 // Shutdown performs a clean shutdown of the application
 func (app *App) Shutdown() {
+	app.saveRuntimeOnce.Do(app.saveRuntimeState)
+	app.endSessionHooks()
 	if app.CronScheduler != nil {
 		app.CronScheduler.Stop()
 	}
+	if app.RunDispatcher != nil {
+		app.RunDispatcher.Stop()
+	}
 	// Reset the task registry singleton so a fresh test run / embedded
 	// re-init (rare) sees a clean slate. Production processes shut down the
 	// whole binary so this is mostly defensive.
@@ -335,20 +429,27 @@ func (app *App) Shutdown() {
 	}
 	tools.CleanupTempDir()
 	app.LspService.Shutdown(context.Background())
+	audit.Shutdown()
 }
 
 // ForceShutdown performs an aggressive shutdown for non-interactive mode
 func (app *App) ForceShutdown() {
 	logging.Info("Starting force shutdown")
+	app.saveRuntimeOnce.Do(func() {})
+	app.endSessionHooks()
 	if app.CronScheduler != nil {
 		app.CronScheduler.Stop()
 	}
+	if app.RunDispatcher != nil {
+		app.RunDispatcher.Stop()
+	}
 	if app.Messages != nil {
 		app.Messages.Shutdown()
 	}
 	tools.CleanupTempDir()
 	app.LspService.ForceShutdown()
 	app.forceKillAllChildProcesses()
+	audit.Shutdown()
 	logging.Info("Force shutdown completed")
 }
 
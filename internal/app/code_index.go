@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/codesearch"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// newCodeIndex returns the codesearch index when codeSearch is configured.
+// It returns nil when it is not, or when the embedding provider can't be
+// set up, which leaves the codesearch tool out.
+func newCodeIndex(q db.Querier, projectID string) *codesearch.Index {
+	cfg := config.Get()
+	if cfg == nil || cfg.CodeSearch == nil {
+		return nil
+	}
+	embedder, err := codesearch.NewEmbedder(cfg.CodeSearch, cfg.Providers)
+	if err != nil {
+		logging.WarnPersist(fmt.Sprintf("Code search disabled: %s", err))
+		return nil
+	}
+	return codesearch.New(q, queueProjectID(projectID), cfg.WorkingDir, embedder, cfg.CodeSearch.Exclude)
+}
+
+// StartCodeIndex brings the codesearch index up to date in the background,
+// so the first search doesn't wait for it. Without this the index is built
+// on the first search.
+func (app *App) StartCodeIndex(ctx context.Context) {
+	if app.CodeIndex == nil {
+		return
+	}
+	go func() {
+		defer logging.RecoverPanic("code-index", nil)
+		if err := app.CodeIndex.EnsureIndexed(ctx); err != nil && ctx.Err() == nil {
+			logging.Warn("Failed to build the code index", "error", err)
+		}
+	}()
+}
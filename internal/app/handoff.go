@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// FollowHandoff makes to, the primary agent a handoff tool call named, the
+// active agent and runs it on sessionID. The run starts without a new
+// prompt: the agent continues from the session's history, which ends with
+// the handoff result addressed to it.
+func (app *App) FollowHandoff(ctx context.Context, sessionID string, to config.AgentName) error {
+	if err := app.SetActiveAgent(to); err != nil {
+		return err
+	}
+	events, err := app.ActiveAgent().Run(ctx, sessionID, "", 0)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer logging.RecoverPanic("app.FollowHandoff", nil)
+		// Run sends one terminal event and closes; front-ends follow the
+		// run through the message and agent brokers.
+		for range events {
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/hooks"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// sessionEndTimeout bounds the SessionEnd hooks fired while the app
+// shuts down, so a slow webhook can't hold the process open.
+const sessionEndTimeout = 5 * time.Second
+
+// watchSessionHooks fires SessionStart for every top-level session this
+// process creates and SessionEnd when one is deleted. Sessions still open
+// at shutdown get SessionEnd from endSessionHooks. Subagent sessions are
+// skipped; their lifetime is one tool call, which PreToolUse/PostToolUse
+// already cover.
+func (app *App) watchSessionHooks(ctx context.Context, reg *hooks.Registry) {
+	if reg == nil {
+		return
+	}
+	app.hookRegistry = reg
+	events := app.Sessions.Subscribe(ctx)
+	go func() {
+		for ev := range events {
+			sess := ev.Payload
+			if sess.ParentSessionID != "" {
+				continue
+			}
+			switch ev.Type {
+			case pubsub.CreatedEvent:
+				if !reg.HasEvent(hooks.EventSessionStart) && !reg.HasEvent(hooks.EventSessionEnd) {
+					continue
+				}
+				app.hookSessions.Store(sess.ID, struct{}{})
+				go reg.RunSessionStart(ctx, sess.ID, reg.ProjectRoot(), "new")
+			case pubsub.DeletedEvent:
+				if _, started := app.hookSessions.LoadAndDelete(sess.ID); started {
+					go reg.RunSessionEnd(ctx, sess.ID, reg.ProjectRoot(), "delete")
+				}
+			}
+		}
+	}()
+}
+
+// endSessionHooks fires SessionEnd with reason "exit" for every session
+// started by this process that wasn't deleted.
+func (app *App) endSessionHooks() {
+	reg := app.hookRegistry
+	if reg == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionEndTimeout)
+	defer cancel()
+	app.hookSessions.Range(func(key, _ any) bool {
+		app.hookSessions.Delete(key)
+		reg.RunSessionEnd(ctx, key.(string), reg.ProjectRoot(), "exit")
+		return true
+	})
+}
@@ -25,10 +25,17 @@ type serverNameContextKey string
 
 const ServerNameContextKey serverNameContextKey = "server_name"
 
+// clientWaitTimeout bounds how long WaitClientsForFile blocks on a server
+// that is still starting. Slow servers get past it on a later call.
+const clientWaitTimeout = 20 * time.Second
+
 type lspService struct {
-	clients   map[string]*lsp.Client
-	clientsCh chan *lsp.Client
-	mu        sync.RWMutex
+	clients map[string]*lsp.Client
+	// starting holds the extensions of servers being started or
+	// restarted; changed is closed and replaced whenever one finishes.
+	starting map[string][]string
+	changed  chan struct{}
+	mu       sync.RWMutex
 
 	watcherCancelFuncs []context.CancelFunc
 	cancelMu           sync.Mutex
@@ -39,16 +46,23 @@ type lspService struct {
 
 func NewLspService() lsp.LspService {
 	return &lspService{
-		clients:   make(map[string]*lsp.Client),
-		clientsCh: make(chan *lsp.Client, 50),
-		Broker:    pubsub.NewBroker[lsp.LSPServerEvent](),
+		clients:  make(map[string]*lsp.Client),
+		starting: make(map[string][]string),
+		changed:  make(chan struct{}),
+		Broker:   pubsub.NewBroker[lsp.LSPServerEvent](),
 	}
 }
 
 func (s *lspService) Init(ctx context.Context) {
 	cfg := config.Get()
+	servers := install.ResolveServers(cfg)
+	// Mark every server as starting before any goroutine runs, so a tool
+	// called right after Init waits instead of finding no client.
+	for name, server := range servers {
+		s.markStarting(name, server.Extensions)
+	}
 	wg := sync.WaitGroup{}
-	for name, server := range install.ResolveServers(cfg) {
+	for name, server := range servers {
 		wg.Add(1)
 		go func() {
 			lspName := "LSP-" + name
@@ -56,13 +70,13 @@ func (s *lspService) Init(ctx context.Context) {
 				logging.ErrorPersist(fmt.Sprintf("Panic while starting %s", lspName))
 			})
 			defer wg.Done()
+			defer s.finishStarting(name)
 			s.startLSPServer(ctx, name, server)
 		}()
 	}
 	go func() {
 		wg.Wait()
 		logging.Info("LSP clients initialization completed")
-		close(s.clientsCh)
 	}()
 	logging.Info("LSP clients initialization started in background")
 }
@@ -127,14 +141,37 @@ func (s *lspService) Clients() map[string]*lsp.Client {
 	return snapshot
 }
 
-func (s *lspService) ClientsCh() <-chan *lsp.Client {
-	return s.clientsCh
-}
-
 func (s *lspService) ClientsForFile(filePath string) []*lsp.Client {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.clientsForExt(strings.ToLower(filepath.Ext(filePath)))
+}
+
+func (s *lspService) WaitClientsForFile(ctx context.Context, filePath string) ([]*lsp.Client, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
+	timeout := time.NewTimer(clientWaitTimeout)
+	defer timeout.Stop()
+	for {
+		s.mu.RLock()
+		clients := s.clientsForExt(ext)
+		starting := s.startingForExt(ext)
+		changed := s.changed
+		s.mu.RUnlock()
+		if len(clients) > 0 || !starting {
+			return clients, nil
+		}
+		select {
+		case <-changed:
+		case <-timeout.C:
+			return nil, lsp.ErrServerStarting
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// clientsForExt must be called with s.mu held.
+func (s *lspService) clientsForExt(ext string) []*lsp.Client {
 	var matched []*lsp.Client
 	for _, client := range s.clients {
 		if slices.Contains(client.GetExtensions(), ext) {
@@ -144,6 +181,32 @@ func (s *lspService) ClientsForFile(filePath string) []*lsp.Client {
 	return matched
 }
 
+// startingForExt must be called with s.mu held.
+func (s *lspService) startingForExt(ext string) bool {
+	for _, exts := range s.starting {
+		if slices.Contains(exts, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *lspService) markStarting(name string, extensions []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.starting[name] = extensions
+}
+
+// finishStarting wakes WaitClientsForFile callers once a start attempt is
+// over, whether or not it produced a client.
+func (s *lspService) finishStarting(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.starting, name)
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
 func (s *lspService) NotifyOpenFile(ctx context.Context, filePath string) {
 	for _, client := range s.Clients() {
 		_ = client.OpenFile(ctx, filePath)
@@ -312,7 +375,6 @@ func (s *lspService) createAndStartLSPClient(ctx context.Context, name string, s
 
 	s.mu.Lock()
 	s.clients[name] = lspClient
-	s.clientsCh <- lspClient
 	s.mu.Unlock()
 
 	go s.runWorkspaceWatcher(watchCtx, name, workspaceWatcher)
@@ -337,6 +399,9 @@ func (s *lspService) restartLSPClient(ctx context.Context, name string) {
 		return
 	}
 
+	s.markStarting(name, server.Extensions)
+	defer s.finishStarting(name)
+
 	s.mu.Lock()
 	oldClient, exists := s.clients[name]
 	if exists {
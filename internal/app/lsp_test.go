@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/lsp"
+)
+
+func TestWaitClientsForFile(t *testing.T) {
+	s := NewLspService().(*lspService)
+	ctx := context.Background()
+
+	// Nothing configured for the extension: no wait.
+	if clients, err := s.WaitClientsForFile(ctx, "main.go"); err != nil || len(clients) != 0 {
+		t.Fatalf("idle service = %v, %v", clients, err)
+	}
+
+	s.markStarting("gopls", []string{".go"})
+	s.markStarting("pyright", []string{".py"})
+	type result struct {
+		clients []*lsp.Client
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		clients, err := s.WaitClientsForFile(ctx, "/repo/MAIN.GO")
+		done <- result{clients, err}
+	}()
+
+	// Another server finishing does not release the waiter.
+	s.finishStarting("pyright")
+	select {
+	case r := <-done:
+		t.Fatalf("returned before gopls was up: %v, %v", r.clients, r.err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	client := &lsp.Client{}
+	client.SetExtensions([]string{".go"})
+	s.mu.Lock()
+	s.clients["gopls"] = client
+	s.mu.Unlock()
+	s.finishStarting("gopls")
+
+	select {
+	case r := <-done:
+		if r.err != nil || len(r.clients) != 1 || r.clients[0] != client {
+			t.Fatalf("after gopls started = %v, %v", r.clients, r.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released when gopls started")
+	}
+}
+
+func TestWaitClientsForFileCancelled(t *testing.T) {
+	s := NewLspService().(*lspService)
+	s.markStarting("gopls", []string{".go"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.WaitClientsForFile(ctx, "main.go"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context's error", err)
+	}
+
+	// A failed start releases waiters with no clients and no error.
+	s.finishStarting("gopls")
+	if clients, err := s.WaitClientsForFile(context.Background(), "main.go"); err != nil || len(clients) != 0 {
+		t.Fatalf("after a failed start = %v, %v", clients, err)
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+var (
+	// ErrMergeSameSession is returned by MergeSession when both sessions
+	// are the same.
+	ErrMergeSameSession = errors.New("a session can't be merged into itself")
+	// ErrMergeSubsession is returned by MergeSession for subagent and flow
+	// step sessions, which belong to the tree of their root session.
+	ErrMergeSubsession = errors.New("only top-level sessions can be merged")
+	// ErrMergeEmpty is returned by MergeSession when the side session has
+	// no messages to summarize.
+	ErrMergeEmpty = errors.New("the session to merge has no messages")
+)
+
+// MergeResult describes a side session folded into another by MergeSession.
+type MergeResult struct {
+	// MessageID is the message that carries the side session into the
+	// target's history.
+	MessageID string
+	// Files are the paths whose history was linked into the target.
+	Files []string
+	// Summarized is set when the side session was compacted for the merge.
+	Summarized bool
+}
+
+// MergeSession folds the side session sourceID back into targetID: the
+// compacted summary of the side session is appended to the target's
+// history, and the files it changed are linked into the target's file
+// history so they show up among the target's changes. The side session is
+// compacted first when it has no summary or went on after its last one. It
+// is left in place otherwise; delete it once it is no longer needed.
+func (app *App) MergeSession(ctx context.Context, targetID, sourceID string) (MergeResult, error) {
+	if targetID == sourceID {
+		return MergeResult{}, ErrMergeSameSession
+	}
+	for _, id := range []string{targetID, sourceID} {
+		if app.ActiveAgent().IsSessionBusy(id) {
+			return MergeResult{}, fmt.Errorf("session %s is busy", id)
+		}
+	}
+	target, err := app.Sessions.Get(ctx, targetID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	source, err := app.Sessions.Get(ctx, sourceID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if target.ParentSessionID != "" || source.ParentSessionID != "" {
+		return MergeResult{}, ErrMergeSubsession
+	}
+
+	var res MergeResult
+	msgs, err := app.Messages.List(ctx, sourceID)
+	if err != nil {
+		return res, fmt.Errorf("failed to list messages of session %s: %w", sourceID, err)
+	}
+	if len(msgs) == 0 {
+		return res, ErrMergeEmpty
+	}
+	if source.SummaryMessageID == "" || msgs[len(msgs)-1].ID != source.SummaryMessageID {
+		if err := app.ActiveAgent().SummarizeSync(ctx, sourceID); err != nil {
+			return res, fmt.Errorf("failed to summarize session %s: %w", sourceID, err)
+		}
+		res.Summarized = true
+		if source, err = app.Sessions.Get(ctx, sourceID); err != nil {
+			return res, err
+		}
+	}
+	summary, err := app.Messages.Get(ctx, source.SummaryMessageID)
+	if err != nil {
+		return res, fmt.Errorf("failed to load the summary of session %s: %w", sourceID, err)
+	}
+
+	if res.Files, err = app.linkFileHistory(ctx, targetID, sourceID); err != nil {
+		return res, err
+	}
+
+	msg, err := app.Messages.Create(ctx, targetID, message.CreateMessageParams{
+		Role:      message.User,
+		Parts:     []message.ContentPart{message.TextContent{Text: mergedSessionText(source.Title, summary.Content().String(), res.Files)}},
+		Synthetic: true,
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to add the merged session to session %s: %w", targetID, err)
+	}
+	res.MessageID = msg.ID
+	return res, nil
+}
+
+// linkFileHistory copies the file history of the side session's tree into
+// the target: the version each file had before the side session changed it,
+// unless the target already tracks the file, and the version it left.
+func (app *App) linkFileHistory(ctx context.Context, targetID, sourceID string) ([]string, error) {
+	// Forked and imported sessions have no tree of their own.
+	files, err := app.History.ListBySessionTree(ctx, sourceID)
+	if err == nil && len(files) == 0 {
+		files, err = app.History.ListBySession(ctx, sourceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of session %s: %w", sourceID, err)
+	}
+	byPath := make(map[string][]history.File)
+	for _, f := range files {
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		versions := byPath[path]
+		history.SortVersions(versions)
+		first, last := versions[0], versions[len(versions)-1]
+
+		current, err := app.History.GetByPathAndSession(ctx, path, targetID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if current, err = app.History.Create(ctx, targetID, path, first.Content); err != nil {
+				return nil, fmt.Errorf("failed to link %s: %w", path, err)
+			}
+		case err != nil:
+			return nil, fmt.Errorf("failed to look up %s in session %s: %w", path, targetID, err)
+		}
+		if current.Content != last.Content {
+			if _, err := app.History.CreateVersion(ctx, targetID, path, last.Content); err != nil {
+				return nil, fmt.Errorf("failed to link %s: %w", path, err)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// mergedSessionText is the message a merged side session is carried into
+// the target's history with.
+func mergedSessionText(title, summary string, files []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<merged_session title=\"%s\">\n", html.EscapeString(title))
+	b.WriteString("The user merged a side session into this one. This is its summary:\n\n")
+	b.WriteString(strings.TrimSpace(summary))
+	b.WriteString("\n")
+	if len(files) > 0 {
+		b.WriteString("\nFiles it changed:\n")
+		for _, f := range files {
+			fmt.Fprintf(&b, "- %s\n", f)
+		}
+	}
+	b.WriteString("</merged_session>")
+	return b.String()
+}
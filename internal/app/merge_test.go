@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// summarizingAgent compacts a session by appending a canned summary.
+type summarizingAgent struct {
+	agent.Service
+	app   *App
+	calls int
+}
+
+func (s *summarizingAgent) IsSessionBusy(string) bool { return false }
+
+func (s *summarizingAgent) SummarizeSync(ctx context.Context, sessionID string) error {
+	s.calls++
+	msg, err := s.app.Messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "Tried a cache; the hit rate was too low."}},
+	})
+	if err != nil {
+		return err
+	}
+	sess, err := s.app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	sess.SummaryMessageID = msg.ID
+	_, err = s.app.Sessions.Save(ctx, sess)
+	return err
+}
+
+func newMergeTestApp(t *testing.T) (*App, *summarizingAgent) {
+	t.Helper()
+	conn := db.OpenTestDB(t)
+	q := db.NewSQLiteQuerier(conn)
+	a := &App{
+		Sessions: session.NewService(q, "proj"),
+		Messages: message.NewService(q, conn),
+		History:  history.NewService(q, conn),
+	}
+	stub := &summarizingAgent{app: a}
+	a.activeAgent = stub
+	return a, stub
+}
+
+func TestMergeSession(t *testing.T) {
+	ctx := context.Background()
+	a, stub := newMergeTestApp(t)
+
+	main, err := a.Sessions.Create(ctx, "Main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spike, err := a.Sessions.Create(ctx, "Cache spike")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Messages.Create(ctx, spike.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "try a cache"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The spike changed cache.go, which main never touched, and main.go,
+	// which main already tracks.
+	for _, f := range []struct{ session, path, content string }{
+		{main.ID, "/w/main.go", "main v0"},
+		{spike.ID, "/w/cache.go", ""},
+		{spike.ID, "/w/cache.go", "cache v1"},
+		{spike.ID, "/w/main.go", "main v0"},
+		{spike.ID, "/w/main.go", "main with cache"},
+	} {
+		if _, err := a.History.CreateVersion(ctx, f.session, f.path, f.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := a.MergeSession(ctx, main.ID, spike.ID)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !res.Summarized || stub.calls != 1 {
+		t.Errorf("summarized = %v after %d calls, want a compaction", res.Summarized, stub.calls)
+	}
+	if strings.Join(res.Files, ",") != "/w/cache.go,/w/main.go" {
+		t.Errorf("files = %v", res.Files)
+	}
+
+	msgs, err := a.Messages.List(ctx, main.ID)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("main has %d messages, err %v", len(msgs), err)
+	}
+	text := msgs[0].Content().String()
+	if msgs[0].ID != res.MessageID || !msgs[0].Synthetic || !strings.Contains(text, `<merged_session title="Cache spike">`) ||
+		!strings.Contains(text, "hit rate was too low") || !strings.Contains(text, "- /w/cache.go") {
+		t.Errorf("merged message = %+v: %s", msgs[0], text)
+	}
+
+	files, err := a.History.ListBySession(ctx, main.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := map[string][]string{}
+	for _, f := range files {
+		byPath[f.Path] = append(byPath[f.Path], f.Version+"="+f.Content)
+	}
+	if got := strings.Join(byPath["/w/cache.go"], ","); !strings.HasPrefix(got, "initial=,") || !strings.HasSuffix(got, "=cache v1") {
+		t.Errorf("cache.go history = %s, want the spike's original then its result", got)
+	}
+	if got := byPath["/w/main.go"]; len(got) != 2 || got[0] != "initial=main v0" || !strings.HasSuffix(got[1], "=main with cache") {
+		t.Errorf("main.go history = %v, want main's own original then the spike's result", got)
+	}
+
+	// A summary that is still current is reused.
+	if _, err := a.MergeSession(ctx, main.ID, spike.ID); err != nil {
+		t.Fatalf("second merge: %v", err)
+	}
+	if stub.calls != 1 {
+		t.Errorf("compacted again although the summary was current")
+	}
+}
+
+func TestMergeSessionRefuses(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newMergeTestApp(t)
+	main, _ := a.Sessions.Create(ctx, "Main")
+	empty, _ := a.Sessions.Create(ctx, "Empty")
+	child, err := a.Sessions.CreateTaskSession(ctx, "call-1", main.ID, "Task")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		source string
+		want   error
+	}{
+		{main.ID, ErrMergeSameSession},
+		{child.ID, ErrMergeSubsession},
+		{empty.ID, ErrMergeEmpty},
+	} {
+		if _, err := a.MergeSession(ctx, main.ID, tt.source); !errors.Is(err, tt.want) {
+			t.Errorf("merge %s: err = %v, want %v", tt.source, err, tt.want)
+		}
+	}
+}
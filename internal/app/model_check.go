@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// modelCheckTimeout bounds one round of availability checks.
+const modelCheckTimeout = time.Minute
+
+// StartModelChecks checks that the configured providers' models are
+// available to the account whenever the cached result is older than
+// modelCheck.interval, until ctx is done. The model picker greys out the
+// models a check found unavailable.
+func (app *App) StartModelChecks(ctx context.Context) {
+	cfg := config.Get()
+	if cfg == nil {
+		return
+	}
+	interval := cfg.ModelCheckInterval()
+	if interval == 0 {
+		return
+	}
+	go func() {
+		defer logging.RecoverPanic("model-checks", nil)
+		warnUnavailableModels(cfg, models.CurrentAvailability())
+
+		for {
+			last := time.Unix(models.CurrentAvailability().CheckedAt, 0)
+			wait := time.Until(last.Add(interval))
+			if wait <= 0 {
+				checkModels(ctx, cfg)
+				wait = interval
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+func checkModels(ctx context.Context, cfg *config.Config) {
+	creds := cfg.SyncCredentials()
+	if len(creds) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, modelCheckTimeout)
+	defer cancel()
+	report, err := models.CheckAvailability(ctx, creds)
+	if err != nil {
+		logging.Warn("Model availability check failed", "error", err)
+	}
+	for provider, h := range report.Providers {
+		if _, checked := creds[provider]; checked && h.Error != "" {
+			logging.Warn("Provider health check failed", "provider", provider, "error", h.Error)
+		}
+	}
+	warnUnavailableModels(cfg, report)
+}
+
+// warnUnavailableModels tells the user up front when an agent is set to
+// a model the account cannot use, rather than on its first request.
+func warnUnavailableModels(cfg *config.Config, report models.AvailabilityReport) {
+	var names []string
+	for name, agent := range cfg.Agents {
+		if a, ok := report.Models[agent.Model]; ok && !a.Available {
+			names = append(names, fmt.Sprintf("%s (%s: %s)", name, agent.Model, a.Reason))
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	slices.Sort(names)
+	for _, n := range names {
+		logging.WarnPersist("Agent model is unavailable: " + n)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/prompt"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/onboard"
+)
+
+// InitResult describes the instructions file written by InitProject.
+type InitResult struct {
+	// File is the file as given or chosen, Path its absolute location.
+	File string
+	Path string
+	// Created is false when an existing file was updated.
+	Created bool
+	// Registered is set when File was added to the project's contextPaths
+	// because no entry covered it yet.
+	Registered bool
+	SessionID  string
+}
+
+// initRunning guards against two surveys writing the same file.
+var initRunning atomic.Bool
+
+// InitProject has the explorer agent survey the repository in a session of
+// its own and writes the instructions file it produces: file, or when empty
+// the existing AGENTS.md, opencode.md or CLAUDE.md, else AGENTS.md. The
+// file is then registered as a context path and the project marked as
+// initialized, so running agents pick it up on their next request.
+func (a *App) InitProject(ctx context.Context, file string) (InitResult, error) {
+	if !initRunning.CompareAndSwap(false, true) {
+		return InitResult{}, errors.New("the project is already being initialized")
+	}
+	defer initRunning.Store(false)
+
+	cwd := config.WorkingDirectory()
+	res := InitResult{File: onboard.TargetFile(cwd, file)}
+	res.Path = res.File
+	if !filepath.IsAbs(res.Path) {
+		res.Path = filepath.Join(cwd, res.File)
+	}
+	existing, err := os.ReadFile(res.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return res, fmt.Errorf("failed to read %s: %w", res.File, err)
+	}
+	res.Created = existing == nil
+
+	doc, sessionID, err := a.runOnboarding(ctx, res.File, string(existing))
+	res.SessionID = sessionID
+	if err != nil {
+		return res, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(res.Path), 0o755); err != nil {
+		return res, fmt.Errorf("failed to create directory for %s: %w", res.File, err)
+	}
+	if err := os.WriteFile(res.Path, []byte(doc), 0o644); err != nil {
+		return res, fmt.Errorf("failed to write %s: %w", res.File, err)
+	}
+	// The TUI offers to run /init on a project's first start; this has
+	// done it.
+	if err := config.MarkProjectInitialized(); err != nil {
+		logging.Warn("Failed to mark project initialized", "error", err)
+	}
+	// contextPaths are relative to the working directory; a file outside
+	// it can't be one.
+	if rel, err := filepath.Rel(cwd, res.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		if res.Registered, err = config.AddProjectContextPath(rel); err != nil {
+			logging.Warn("Failed to add the instructions file to contextPaths", "file", rel, "error", err)
+		}
+	} else {
+		logging.Warn("Instructions file is outside the working directory and won't be read by agents", "file", res.Path)
+	}
+	prompt.InvalidateContext()
+	return res, nil
+}
+
+// runOnboarding runs the explorer agent over the repository and returns the
+// instructions file it wrote along with the session it ran in.
+func (a *App) runOnboarding(ctx context.Context, file, existing string) (string, string, error) {
+	explorer, err := a.AgentFactory.NewAgent(ctx, string(config.AgentExplorer), nil, "", false, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create the explorer agent: %w", err)
+	}
+	sess, err := a.Sessions.Create(ctx, "Init: "+file)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create session: %w", err)
+	}
+	a.Permissions.AutoApproveSession(sess.ID)
+
+	done, err := explorer.RunWith(ctx, sess.ID, onboard.Prompt(file, existing), 0, agent.RunOptions{NonInteractive: true})
+	if err != nil {
+		return "", sess.ID, fmt.Errorf("failed to start the explorer agent for session %s: %w", sess.ID, err)
+	}
+	result := <-done
+	if result.Error != nil {
+		return "", sess.ID, fmt.Errorf("explorer agent failed for session %s: %w", sess.ID, result.Error)
+	}
+	logging.Info("Onboarding survey completed", "session_id", sess.ID, "file", file)
+	doc, err := onboard.ExtractDocument(result.Message.Content().String())
+	return doc, sess.ID, err
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// ErrNothingToPin is returned by TogglePin when the session has no prompt.
+var ErrNothingToPin = errors.New("no prompt to pin yet")
+
+// TogglePin pins the last prompt of the session, or unpins it when it is
+// already pinned. Compaction keeps pinned prompts verbatim after the
+// summary, so requirements stated once survive long tool loops. It returns
+// the updated prompt.
+func (app *App) TogglePin(ctx context.Context, sessionID string) (message.Message, error) {
+	sess, err := app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return message.Message{}, err
+	}
+	msgs, err := app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return message.Message{}, err
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		// A summary written by compaction is a user message too.
+		if msg.Role != message.User || msg.Synthetic || msg.ID == sess.SummaryMessageID || msg.Content().Text == "" {
+			continue
+		}
+		msg.SetPinned(!msg.IsPinned())
+		if err := app.Messages.Update(ctx, msg); err != nil {
+			return message.Message{}, err
+		}
+		return msg, nil
+	}
+	return message.Message{}, ErrNothingToPin
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func TestTogglePin(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newMergeTestApp(t)
+	sess, err := a.Sessions.Create(ctx, "pins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.TogglePin(ctx, sess.ID); !errors.Is(err, ErrNothingToPin) {
+		t.Fatalf("TogglePin on an empty session = %v, want ErrNothingToPin", err)
+	}
+
+	prompt, err := a.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "Never touch the migrations."}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.Assistant,
+		Parts: []message.ContentPart{message.TextContent{Text: "Understood."}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pinned, err := a.TogglePin(ctx, sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pinned.ID != prompt.ID || !pinned.IsPinned() {
+		t.Fatalf("TogglePin pinned %s (pinned=%v), want prompt %s pinned", pinned.ID, pinned.IsPinned(), prompt.ID)
+	}
+	stored, err := a.Messages.Get(ctx, prompt.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.IsPinned() || stored.Content().Text != "Never touch the migrations." {
+		t.Fatalf("stored prompt = %+v, want it pinned with its text", stored.Parts)
+	}
+
+	unpinned, err := a.TogglePin(ctx, sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpinned.IsPinned() {
+		t.Fatal("second TogglePin should unpin the prompt")
+	}
+}
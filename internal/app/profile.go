@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+)
+
+// SwitchProfile reloads the configuration with the named profile, "" for
+// none, and rebuilds the agent registry and the primary agents' providers
+// from it. It is refused while an agent is working. When an agent can't be
+// rebuilt, the previous profile is restored.
+func (app *App) SwitchProfile(name string) error {
+	for _, a := range app.PrimaryAgents {
+		if a.IsBusy() {
+			return agent.ErrAgentBusy
+		}
+	}
+	prev := config.Get().Profile
+	if err := config.SwitchProfile(name); err != nil {
+		return err
+	}
+	if err := app.reloadAgents(); err != nil {
+		if restoreErr := config.SwitchProfile(prev); restoreErr == nil {
+			_ = app.reloadAgents()
+		}
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	return nil
+}
+
+func (app *App) reloadAgents() error {
+	agentregistry.RefreshRegistry()
+	for id, a := range app.PrimaryAgents {
+		if _, err := a.Reload(); err != nil {
+			return fmt.Errorf("agent %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// ErrNotUserMessage is returned by Rewind for messages the user did not write.
+var ErrNotUserMessage = errors.New("only user prompts can be edited or regenerated")
+
+// Rewind supersedes the user prompt messageID and everything after it, so the
+// session continues as if that prompt had never been sent. It returns the
+// prompt's text and attachments for the caller to send again, edited or as
+// is. Files changed by the superseded turns are left alone; their
+// checkpoints stay available to /undo.
+func (app *App) Rewind(ctx context.Context, sessionID, messageID string) (string, []message.Attachment, error) {
+	if app.ActiveAgent().IsSessionBusy(sessionID) {
+		return "", nil, fmt.Errorf("session %s is busy", sessionID)
+	}
+	msg, err := app.Messages.Get(ctx, messageID)
+	if err != nil {
+		return "", nil, err
+	}
+	if msg.SessionID != sessionID {
+		return "", nil, message.ErrMessageNotFound
+	}
+	if msg.Role != message.User || msg.Synthetic {
+		return "", nil, ErrNotUserMessage
+	}
+
+	superseded, err := app.Messages.SupersedeFrom(ctx, sessionID, messageID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// A summary written after the prompt now covers superseded history.
+	sess, err := app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, m := range superseded {
+		if m.ID == sess.SummaryMessageID {
+			sess.SummaryMessageID = ""
+			if _, err := app.Sessions.Save(ctx, sess); err != nil {
+				return "", nil, fmt.Errorf("failed to clear the session summary: %w", err)
+			}
+			break
+		}
+	}
+
+	var attachments []message.Attachment
+	for _, bc := range msg.BinaryContent() {
+		attachments = append(attachments, message.Attachment{
+			FilePath: bc.Path,
+			FileName: filepath.Base(bc.Path),
+			MimeType: bc.MIMEType,
+			Content:  bc.Data,
+		})
+	}
+	return msg.Content().String(), attachments, nil
+}
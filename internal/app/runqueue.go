@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/flow"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/runqueue"
+)
+
+// StartRunQueue starts dispatching queued runs. Only `opencode serve`
+// calls it, so a single process per database executes the queue.
+func (app *App) StartRunQueue(ctx context.Context) {
+	if app.RunQueue == nil || app.RunDispatcher != nil {
+		return
+	}
+	app.RunDispatcher = runqueue.NewDispatcher(app.RunQueue, &queueExecutor{app: app}, runQueueLimits)
+	app.RunDispatcher.Start(ctx)
+}
+
+func runQueueLimits() runqueue.Limits {
+	cfg := config.Get()
+	if cfg == nil || cfg.RunQueue == nil {
+		return runqueue.Limits{}
+	}
+	return runqueue.Limits{
+		Concurrency: cfg.RunQueue.Concurrency,
+		Providers:   cfg.RunQueue.Providers,
+		Projects:    cfg.RunQueue.Projects,
+	}
+}
+
+// queueExecutor runs queued prompts and flows the same way the headless
+// CLI does: fresh auto-approved sessions and non-interactive turns.
+type queueExecutor struct {
+	app *App
+}
+
+func (e *queueExecutor) agentFor(name string) (agent.Service, error) {
+	if name == "" {
+		if a := e.app.ActiveAgent(); a != nil {
+			return a, nil
+		}
+		return nil, errors.New("no active agent available")
+	}
+	a, ok := e.app.PrimaryAgents[config.AgentName(name)]
+	if !ok {
+		return nil, fmt.Errorf("agent %q not found among primary agents", name)
+	}
+	return a, nil
+}
+
+func (e *queueExecutor) Provider(run runqueue.Run) string {
+	switch run.Kind {
+	case runqueue.KindPrompt:
+		if a, err := e.agentFor(run.Target); err == nil {
+			return string(a.Model().Provider)
+		}
+	case runqueue.KindFlow:
+		// A flow may mix agents; its first step decides the bucket.
+		f, err := flow.Get(run.Target)
+		if err != nil || len(f.Spec.Steps) == 0 {
+			return ""
+		}
+		cfg := config.Get()
+		if cfg == nil {
+			return ""
+		}
+		name := f.Spec.Steps[0].Agent
+		if name == "" {
+			name = string(config.AgentCoder)
+		}
+		if agentCfg, ok := cfg.Agents[config.AgentName(name)]; ok {
+			return string(models.SupportedModels[agentCfg.Model].Provider)
+		}
+	}
+	return ""
+}
+
+func (e *queueExecutor) Execute(ctx context.Context, run runqueue.Run) (string, error) {
+	switch run.Kind {
+	case runqueue.KindPrompt:
+		return e.executePrompt(ctx, run)
+	case runqueue.KindFlow:
+		return e.executeFlow(ctx, run)
+	}
+	return "", fmt.Errorf("unknown run kind %q", run.Kind)
+}
+
+func (e *queueExecutor) executePrompt(ctx context.Context, run runqueue.Run) (string, error) {
+	a, err := e.agentFor(run.Target)
+	if err != nil {
+		return "", err
+	}
+	const maxPromptLengthForTitle = 100
+	title := run.Payload.Prompt
+	if len(title) > maxPromptLengthForTitle {
+		title = title[:maxPromptLengthForTitle] + "..."
+	}
+	sess, err := e.app.Sessions.Create(ctx, "Queued: "+title)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	e.app.Permissions.AutoApproveSession(sess.ID)
+
+	done, err := a.RunWith(ctx, sess.ID, run.Payload.Prompt, 0, agent.RunOptions{NonInteractive: true, Batch: true})
+	if err != nil {
+		return sess.ID, err
+	}
+	result := <-done
+	return sess.ID, result.Error
+}
+
+func (e *queueExecutor) executeFlow(ctx context.Context, run runqueue.Run) (string, error) {
+	f, err := flow.Get(run.Target)
+	if err != nil {
+		return "", err
+	}
+	// Without a spec prefix the flow would key its sessions on the current
+	// second, so two queued runs of one flow could resume each other.
+	prefix := ""
+	if f.Spec.Session.Prefix == "" {
+		prefix = run.ID
+	}
+	args := run.Payload.Args
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	agentEvents, states, err := e.app.Flows.Run(ctx, prefix, run.Target, args, run.Payload.Fresh)
+	if err != nil {
+		return "", err
+	}
+	return flow.WaitForRun(ctx, agentEvents, states)
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/runtimestate"
+)
+
+// runtimeStateTimeout bounds capturing and restoring runtime state, which
+// talks to the shell and the database while the app starts or stops.
+const runtimeStateTimeout = 10 * time.Second
+
+// saveRuntimeState snapshots the in-memory working context of the active
+// session and of every session with todos or running monitors, so that a
+// restarted opencode can pick up where this one stopped.
+func (app *App) saveRuntimeState() {
+	if app.RuntimeState == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeStateTimeout)
+	defer cancel()
+
+	states := make(map[string]*runtimestate.State)
+	stateFor := func(sessionID string) *runtimestate.State {
+		st, ok := states[sessionID]
+		if !ok {
+			st = &runtimestate.State{}
+			states[sessionID] = st
+		}
+		return st
+	}
+	if id := app.ActiveSessionID(); id != "" {
+		stateFor(id)
+	}
+	if app.Todos != nil {
+		for _, id := range app.Todos.Sessions() {
+			stateFor(id).Todos = app.Todos.Get(id)
+		}
+	}
+	for _, m := range tools.RunningMonitors() {
+		st := stateFor(m.SessionID)
+		st.Monitors = append(st.Monitors, runtimestate.Monitor{
+			AgentID: m.AgentID,
+			CallID:  m.CallID,
+			Params:  m.Params,
+		})
+	}
+	if len(states) == 0 {
+		return
+	}
+
+	// The shell is shared by every session of the project, so each
+	// snapshot carries it and whichever session is reopened first gets it.
+	shellState := captureShellState(ctx)
+	for id, st := range states {
+		st.Shell = shellState
+		if st.IsEmpty() {
+			continue
+		}
+		if err := app.RuntimeState.Save(ctx, id, *st); err != nil {
+			logging.Warn("Failed to save runtime state", "session", id, "error", err)
+		}
+	}
+}
+
+func captureShellState(ctx context.Context) *runtimestate.Shell {
+	workingDir := config.WorkingDirectory()
+	sh := shell.LookupPersistentShell(workingDir)
+	if sh == nil {
+		return nil
+	}
+	env, err := sh.CaptureEnv(ctx)
+	if err != nil {
+		logging.Warn("Failed to capture shell environment", "error", err)
+	}
+	cwd := sh.Cwd()
+	if cwd == workingDir {
+		cwd = ""
+	}
+	if cwd == "" && len(env) == 0 {
+		return nil
+	}
+	return &runtimestate.Shell{Cwd: cwd, Env: env}
+}
+
+// restoreRuntimeState applies the snapshot saved for sessionID by a previous
+// process, then drops it so the state is only restored once.
+func (app *App) restoreRuntimeState(sessionID string) {
+	defer logging.RecoverPanic("app.restoreRuntimeState", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeStateTimeout)
+	defer cancel()
+
+	st, err := app.RuntimeState.Load(ctx, sessionID)
+	if err != nil {
+		logging.Warn("Failed to load runtime state", "session", sessionID, "error", err)
+		return
+	}
+	if st == nil {
+		return
+	}
+
+	if st.Shell != nil {
+		if sh := shell.GetPersistentShell(config.WorkingDirectory()); sh != nil {
+			if err := sh.Restore(ctx, st.Shell.Cwd, st.Shell.Env); err != nil {
+				logging.Warn("Failed to restore shell state", "session", sessionID, "error", err)
+			}
+		}
+	}
+	if app.Todos != nil && len(st.Todos) > 0 && len(app.Todos.Get(sessionID)) == 0 {
+		app.Todos.Set(sessionID, st.Todos)
+	}
+	for _, m := range st.Monitors {
+		taskID, err := tools.RestartMonitor(app.Registry, tools.RunningMonitor{
+			SessionID: sessionID,
+			AgentID:   m.AgentID,
+			CallID:    m.CallID,
+			Params:    m.Params,
+		})
+		if err != nil {
+			logging.Warn("Failed to restart monitor", "session", sessionID, "cmd", m.Params.Cmd, "error", err)
+			continue
+		}
+		logging.Info("Restarted monitor", "session", sessionID, "task_id", taskID, "cmd", m.Params.Cmd)
+	}
+
+	if err := app.RuntimeState.Delete(ctx, sessionID); err != nil {
+		logging.Warn("Failed to delete runtime state", "session", sessionID, "error", err)
+	}
+}
@@ -0,0 +1,296 @@
+// Package audit keeps the compliance audit trail: an append-only JSONL file
+// in which every entry carries the SHA-256 hash of its predecessor, so
+// editing, dropping or reordering entries breaks the chain. Entries can
+// additionally be signed with an Ed25519 key, which keeps whoever can write
+// the file but not read the key from forging a consistent new chain.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+type Kind string
+
+const (
+	// KindToolCall is a finished tool call, successful or not.
+	KindToolCall Kind = "tool_call"
+	// KindPermission is the outcome of a permission request.
+	KindPermission Kind = "permission"
+	// KindProviderRequest is a request sent to a model provider. Only a
+	// digest of the conversation and tool set is kept.
+	KindProviderRequest Kind = "provider_request"
+	// KindModeration is a response flagged by moderation, with the tool
+	// calls it held back.
+	KindModeration Kind = "moderation"
+	// KindContentFilter is a response the provider's content filter
+	// blocked, with the retry it led to.
+	KindContentFilter Kind = "content_filter"
+)
+
+const defaultFileName = "audit.jsonl"
+
+// Entry is one line of the trail. Hash covers the JSON encoding of the
+// entry with Hash and Signature cleared, PrevHash included.
+type Entry struct {
+	Seq       int64  `json:"seq"`
+	Time      string `json:"time"`
+	Kind      Kind   `json:"kind"`
+	SessionID string `json:"session_id,omitempty"`
+	AgentID   string `json:"agent_id,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	CallID    string `json:"call_id,omitempty"`
+	Action    string `json:"action,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Input     string `json:"input,omitempty"`
+	// Status is ok, error or denied for tool calls.
+	Status string `json:"status,omitempty"`
+	// ExitCode is set for tool calls that ran a process.
+	ExitCode   *int  `json:"exit_code,omitempty"`
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Decision is allow or deny for permission requests, moderation and
+	// the tool calls that asked for permission; Via names what decided it
+	// (user, session_grant, project_grant, auto_approve, reviewer, hook or
+	// cancelled; rule for calls denied by a rule without asking, rule or
+	// endpoint for moderation).
+	Decision string `json:"decision,omitempty"`
+	Via      string `json:"via,omitempty"`
+	// Reason is the reviewer's justification when Via is reviewer.
+	Reason      string `json:"reason,omitempty"`
+	Model       string `json:"model,omitempty"`
+	RequestHash string `json:"request_hash,omitempty"`
+
+	PrevHash  string `json:"prev_hash"`
+	Hash      string `json:"hash,omitempty"`
+	Signature string `json:"sig,omitempty"`
+}
+
+func (e Entry) digest() string {
+	e.Hash, e.Signature = "", ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends entries to a trail file. It is safe for concurrent use within
+// one process; two processes must not share a trail.
+type Log struct {
+	mu   sync.Mutex
+	f    *os.File
+	key  ed25519.PrivateKey
+	seq  int64
+	prev string
+}
+
+// Open opens the trail at path for appending, creating it if needed, and
+// continues the chain from its last entry. key may be nil.
+func Open(path string, key ed25519.PrivateKey) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{f: f, key: key}
+	last, err := lastEntry(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit trail %s: %w", path, err)
+	}
+	if last != nil {
+		l.seq, l.prev = last.Seq, last.Hash
+	}
+	return l, nil
+}
+
+func lastEntry(f *os.File) (*Entry, error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[len(data)-1] != '\n' {
+		return nil, errors.New("ends in a partially written entry; run `opencode audit verify`")
+	}
+	data = data[:len(data)-1]
+	line := data[bytes.LastIndexByte(data, '\n')+1:]
+	var e Entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, fmt.Errorf("last entry is not valid JSON: %w", err)
+	}
+	return &e, nil
+}
+
+// Append chains e to the trail, filling in Seq, Time, PrevHash, Hash and
+// Signature, and syncs it to disk before returning.
+func (l *Log) Append(e Entry) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	if e.Time == "" {
+		e.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	e.PrevHash = l.prev
+	e.Hash = e.digest()
+	e.Signature = ""
+	if l.key != nil {
+		e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(l.key, []byte(e.Hash)))
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return Entry{}, err
+	}
+	if err := l.f.Sync(); err != nil {
+		return Entry{}, err
+	}
+	l.seq, l.prev = e.Seq, e.Hash
+	return e, nil
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// VerifyResult summarises a trail that passed Verify.
+type VerifyResult struct {
+	Entries int
+	Signed  int
+	// Head is the hash of the last entry. Recording it elsewhere lets a
+	// later verification prove the trail was not truncated since.
+	Head string
+}
+
+// Verify checks that every entry of the trail read from r hashes to its
+// Hash, links to its predecessor and continues the sequence. With a public
+// key, every entry must also carry a valid signature.
+func Verify(r io.Reader, pub ed25519.PublicKey) (VerifyResult, error) {
+	var res VerifyResult
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	prev := ""
+	for line := 1; sc.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return res, fmt.Errorf("line %d: invalid entry: %w", line, err)
+		}
+		if e.Seq != int64(res.Entries)+1 {
+			return res, fmt.Errorf("line %d: sequence %d, want %d", line, e.Seq, res.Entries+1)
+		}
+		if e.PrevHash != prev {
+			return res, fmt.Errorf("line %d (seq %d): prev_hash does not match the preceding entry", line, e.Seq)
+		}
+		if e.digest() != e.Hash {
+			return res, fmt.Errorf("line %d (seq %d): content does not match its hash", line, e.Seq)
+		}
+		if e.Signature != "" {
+			res.Signed++
+		}
+		if pub != nil {
+			sig, err := base64.StdEncoding.DecodeString(e.Signature)
+			if err != nil || !ed25519.Verify(pub, []byte(e.Hash), sig) {
+				return res, fmt.Errorf("line %d (seq %d): missing or invalid signature", line, e.Seq)
+			}
+		}
+		prev = e.Hash
+		res.Entries++
+	}
+	if err := sc.Err(); err != nil {
+		return res, err
+	}
+	res.Head = prev
+	return res, nil
+}
+
+// Path returns where the trail configured in cfg lives.
+func Path(cfg *config.Config) string {
+	path := cfg.Audit.Path
+	if path == "" {
+		path = defaultFileName
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.Data.Directory, path)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.WorkingDir, path)
+	}
+	return path
+}
+
+var global atomic.Pointer[Log]
+
+// Init opens the trail configured under "audit" and makes Record write to
+// it. It does nothing when auditing is disabled.
+func Init(cfg *config.Config) error {
+	if cfg == nil || cfg.Audit == nil || !cfg.Audit.Enabled {
+		return nil
+	}
+	var key ed25519.PrivateKey
+	if cfg.Audit.SigningKey != "" {
+		var err error
+		if key, err = LoadPrivateKey(cfg.Audit.SigningKey); err != nil {
+			return fmt.Errorf("audit signing key: %w", err)
+		}
+	}
+	l, err := Open(Path(cfg), key)
+	if err != nil {
+		return err
+	}
+	if old := global.Swap(l); old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// Shutdown closes the trail opened by Init and drops the store set by
+// UseStore.
+func Shutdown() {
+	store.Store(nil)
+	if l := global.Swap(nil); l != nil {
+		l.Close()
+	}
+}
+
+// Enabled reports whether Record writes anywhere; callers use it to skip
+// building expensive entries.
+func Enabled() bool { return global.Load() != nil }
+
+// Record appends e to the trail opened by Init, if any. Failures are
+// logged rather than returned so that auditing never changes the outcome
+// of the audited action.
+func Record(e Entry) {
+	l := global.Load()
+	if l == nil {
+		return
+	}
+	appended, err := l.Append(e)
+	if err != nil {
+		logging.Error("Failed to write audit entry", "kind", e.Kind, "error", err)
+		return
+	}
+	storeEntry(appended)
+}
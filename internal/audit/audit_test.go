@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTrail(t *testing.T, path string, key ed25519.PrivateKey, tools ...string) {
+	t.Helper()
+	l, err := Open(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for _, tool := range tools {
+		if _, err := l.Append(Entry{Kind: KindToolCall, SessionID: "s1", Tool: tool, Input: `{"command":"ls"}`, Status: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVerifyChainAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeTrail(t, path, nil, "bash", "view")
+	writeTrail(t, path, nil, "edit")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Verify(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if res.Entries != 3 || res.Signed != 0 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !strings.Contains(lines[2], res.Head) {
+		t.Errorf("head %s is not the last entry's hash", res.Head)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeTrail(t, path, nil, "bash", "view", "edit")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+
+	edited := strings.Replace(string(data), `"tool":"view"`, `"tool":"ls"`, 1)
+	if _, err := Verify(strings.NewReader(edited), nil); err == nil || !strings.Contains(err.Error(), "does not match its hash") {
+		t.Errorf("edited entry: got %v", err)
+	}
+
+	dropped := lines[0] + lines[2]
+	if _, err := Verify(strings.NewReader(dropped), nil); err == nil || !strings.Contains(err.Error(), "sequence") {
+		t.Errorf("dropped entry: got %v", err)
+	}
+}
+
+func TestVerifySignatures(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "audit.key")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := LoadPrivateKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedPub, err := LoadPublicKey(keyPath)
+	if err != nil || !loadedPub.Equal(pub) {
+		t.Fatalf("public key from private key file: %v", err)
+	}
+
+	signed := filepath.Join(dir, "signed.jsonl")
+	writeTrail(t, signed, key, "bash", "edit")
+	data, _ := os.ReadFile(signed)
+	res, err := Verify(bytes.NewReader(data), pub)
+	if err != nil || res.Signed != 2 {
+		t.Fatalf("signed trail: %+v, %v", res, err)
+	}
+
+	// A chain rebuilt without the key hashes correctly but is unsigned.
+	unsigned := filepath.Join(dir, "unsigned.jsonl")
+	writeTrail(t, unsigned, nil, "bash", "edit")
+	data, _ = os.ReadFile(unsigned)
+	if _, err := Verify(bytes.NewReader(data), pub); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("unsigned trail accepted: %v", err)
+	}
+}
+
+func TestOpenRejectsPartialEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	writeTrail(t, path, nil, "bash")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":2,"ki`)
+	f.Close()
+	if _, err := Open(path, nil); err == nil {
+		t.Error("expected error for partially written trail")
+	}
+}
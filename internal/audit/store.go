@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// The trail file is the tamper-evident record; the tool_executions table
+// is a queryable copy of its tool-call entries for `opencode audit list`.
+// Each row keeps the seq and hash of the entry it was copied from, so a
+// row can be checked against the verified trail. Rows are only ever
+// inserted and are kept when their session is deleted.
+
+var store atomic.Pointer[db.Querier]
+
+// UseStore makes Record copy tool-call entries into the tool_executions
+// table through q. nil stops the copying.
+func UseStore(q db.Querier) {
+	if q == nil {
+		store.Store(nil)
+		return
+	}
+	store.Store(&q)
+}
+
+// Filter selects tool executions for List. Zero fields match everything.
+type Filter struct {
+	SessionID string
+	Tool      string
+	Since     time.Time
+	Until     time.Time
+	// Limit caps the number of rows; 0 means no cap.
+	Limit int
+}
+
+// Execution is one audited tool call as stored in the table.
+type Execution struct {
+	Seq        int64     `json:"seq"`
+	Time       time.Time `json:"time"`
+	SessionID  string    `json:"session_id"`
+	AgentID    string    `json:"agent_id"`
+	Tool       string    `json:"tool"`
+	CallID     string    `json:"call_id"`
+	Input      string    `json:"input"`
+	Status     string    `json:"status"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Decision   string    `json:"decision,omitempty"`
+	Via        string    `json:"via,omitempty"`
+	// Hash is the hash of the trail entry the row was copied from.
+	Hash string `json:"hash"`
+}
+
+// List returns the tool executions recorded in q that match f, oldest
+// first.
+func List(ctx context.Context, q db.Querier, f Filter) ([]Execution, error) {
+	params := db.ListToolExecutionsParams{
+		SessionID: f.SessionID,
+		Tool:      f.Tool,
+		Until:     1<<63 - 1,
+		Limit:     1<<63 - 1,
+	}
+	if !f.Since.IsZero() {
+		params.Since = f.Since.UnixMilli()
+	}
+	if !f.Until.IsZero() {
+		params.Until = f.Until.UnixMilli()
+	}
+	if f.Limit > 0 {
+		params.Limit = int64(f.Limit)
+	}
+	rows, err := q.ListToolExecutions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	executions := make([]Execution, len(rows))
+	for i, r := range rows {
+		executions[i] = Execution{
+			Seq:        r.Seq,
+			Time:       time.UnixMilli(r.CreatedAt),
+			SessionID:  r.SessionID,
+			AgentID:    r.AgentID,
+			Tool:       r.Tool,
+			CallID:     r.CallID,
+			Input:      r.Input,
+			Status:     r.Status,
+			DurationMs: r.DurationMs,
+			Decision:   r.Decision,
+			Via:        r.Via,
+			Hash:       r.Hash,
+		}
+		if r.ExitCode.Valid {
+			code := int(r.ExitCode.Int64)
+			executions[i].ExitCode = &code
+		}
+	}
+	return executions, nil
+}
+
+// storeEntry copies an appended tool-call entry into the table. Like
+// Record, it logs failures instead of returning them.
+func storeEntry(e Entry) {
+	p := store.Load()
+	if p == nil || e.Kind != KindToolCall {
+		return
+	}
+	created := time.Now()
+	if t, err := time.Parse(time.RFC3339Nano, e.Time); err == nil {
+		created = t
+	}
+	var exitCode sql.NullInt64
+	if e.ExitCode != nil {
+		exitCode = sql.NullInt64{Int64: int64(*e.ExitCode), Valid: true}
+	}
+	err := (*p).CreateToolExecution(context.Background(), db.CreateToolExecutionParams{
+		Seq:        e.Seq,
+		SessionID:  e.SessionID,
+		AgentID:    e.AgentID,
+		Tool:       e.Tool,
+		CallID:     e.CallID,
+		Input:      e.Input,
+		Status:     e.Status,
+		ExitCode:   exitCode,
+		DurationMs: e.DurationMs,
+		Decision:   e.Decision,
+		Via:        e.Via,
+		Hash:       e.Hash,
+		CreatedAt:  created.UnixMilli(),
+	})
+	if err != nil {
+		logging.Error("Failed to store audited tool call", "tool", e.Tool, "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func newTestStore(t *testing.T) db.Querier {
+	t.Helper()
+	return db.NewTestQuerier(t)
+}
+
+func TestRecordCopiesToolCallsToStore(t *testing.T) {
+	q := newTestStore(t)
+	l, err := Open(filepath.Join(t.TempDir(), "audit.jsonl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	global.Store(l)
+	UseStore(q)
+	t.Cleanup(Shutdown)
+
+	exit := 2
+	Record(Entry{Kind: KindToolCall, SessionID: "s1", AgentID: "coder", Tool: "bash", Input: `{"command":"make"}`, Status: "error", ExitCode: &exit, Decision: "allow", Via: "user"})
+	Record(Entry{Kind: KindPermission, SessionID: "s1", Tool: "bash", Decision: "allow", Via: "user"})
+	Record(Entry{Kind: KindToolCall, SessionID: "s1", Tool: "view", Status: "ok"})
+	Record(Entry{Kind: KindToolCall, SessionID: "s2", Tool: "bash", Status: "denied", Decision: "deny", Via: "rule"})
+
+	ctx := context.Background()
+	all, err := List(ctx, q, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d executions, want the 3 tool calls", len(all))
+	}
+	first := all[0]
+	if first.Seq != 1 || first.Hash == "" || first.ExitCode == nil || *first.ExitCode != 2 || first.Via != "user" {
+		t.Errorf("first execution = %+v", first)
+	}
+	if all[1].ExitCode != nil {
+		t.Errorf("exit code of a call without one = %d", *all[1].ExitCode)
+	}
+
+	bash, err := List(ctx, q, Filter{Tool: "bash"})
+	if err != nil || len(bash) != 2 {
+		t.Errorf("tool filter = %d executions, %v", len(bash), err)
+	}
+	s1, err := List(ctx, q, Filter{SessionID: "s1", Tool: "bash"})
+	if err != nil || len(s1) != 1 || s1[0].SessionID != "s1" {
+		t.Errorf("session and tool filter = %+v, %v", s1, err)
+	}
+	limited, err := List(ctx, q, Filter{Limit: 1})
+	if err != nil || len(limited) != 1 || limited[0].Tool != "bash" {
+		t.Errorf("limit = %+v, %v", limited, err)
+	}
+	future, err := List(ctx, q, Filter{Since: time.Now().Add(time.Hour)})
+	if err != nil || len(future) != 0 {
+		t.Errorf("since filter = %d executions, %v", len(future), err)
+	}
+	past, err := List(ctx, q, Filter{Until: time.Now().Add(-time.Hour)})
+	if err != nil || len(past) != 0 {
+		t.Errorf("until filter = %d executions, %v", len(past), err)
+	}
+}
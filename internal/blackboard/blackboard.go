@@ -0,0 +1,201 @@
+// Package blackboard is a shared message board for the agents working on
+// one root session. Subagents post findings while they run and the
+// hivemind (or a sibling subagent) reads or waits on them, so concurrent
+// tasks can coordinate instead of running in isolation.
+package blackboard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrEmptyEntry      = errors.New("blackboard entry content is empty")
+)
+
+// Entry is one message on a root session's blackboard.
+type Entry struct {
+	ID            string `json:"id"`
+	RootSessionID string `json:"rootSessionID"`
+	// SessionID is the (sub)session that posted the entry.
+	SessionID string `json:"sessionID"`
+	Agent     string `json:"agent,omitempty"`
+	Topic     string `json:"topic,omitempty"`
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// Query filters a blackboard. The zero value returns every entry.
+type Query struct {
+	// Topic matches entries with exactly this topic, ignoring case.
+	Topic string
+	// Contains matches entries whose topic or content contains the text,
+	// ignoring case.
+	Contains string
+	Agent    string
+	// After skips entries up to and including this entry ID, so a reader
+	// can poll for what is new since its last read.
+	After string
+	// Limit keeps only the most recent matches when positive.
+	Limit int
+	// Wait blocks up to this long for a matching entry when none exists
+	// yet. The first match posted in that window is returned on its own.
+	Wait time.Duration
+}
+
+func (q Query) matches(e Entry) bool {
+	if q.Topic != "" && !strings.EqualFold(e.Topic, q.Topic) {
+		return false
+	}
+	if q.Agent != "" && e.Agent != q.Agent {
+		return false
+	}
+	if q.After != "" && e.ID <= q.After {
+		return false
+	}
+	if q.Contains != "" {
+		needle := strings.ToLower(q.Contains)
+		if !strings.Contains(strings.ToLower(e.Topic), needle) &&
+			!strings.Contains(strings.ToLower(e.Content), needle) {
+			return false
+		}
+	}
+	return true
+}
+
+type Service interface {
+	pubsub.Suscriber[Entry]
+	// Post adds an entry to the blackboard of sessionID's root session.
+	Post(ctx context.Context, sessionID, agent, topic, content string) (Entry, error)
+	// Query reads the blackboard of sessionID's root session, oldest
+	// first. Any session in the tree sees the same board.
+	Query(ctx context.Context, sessionID string, q Query) ([]Entry, error)
+}
+
+type service struct {
+	*pubsub.Broker[Entry]
+	q db.Querier
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Entry](),
+		q:      q,
+	}
+}
+
+func (s *service) Post(ctx context.Context, sessionID, agent, topic, content string) (Entry, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return Entry{}, ErrEmptyEntry
+	}
+	rootID, err := s.rootSessionID(ctx, sessionID)
+	if err != nil {
+		return Entry{}, err
+	}
+	// Version 7 IDs sort by creation time, which is what Query.After
+	// compares against.
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to generate entry ID: %w", err)
+	}
+	dbEntry, err := s.q.CreateBlackboardEntry(ctx, db.CreateBlackboardEntryParams{
+		ID:            id.String(),
+		RootSessionID: rootID,
+		SessionID:     sessionID,
+		Agent:         agent,
+		Topic:         strings.TrimSpace(topic),
+		Content:       content,
+	})
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to post blackboard entry: %w", err)
+	}
+	entry := fromDBItem(dbEntry)
+	s.Publish(pubsub.CreatedEvent, entry)
+	return entry, nil
+}
+
+func (s *service) Query(ctx context.Context, sessionID string, q Query) ([]Entry, error) {
+	rootID, err := s.rootSessionID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before listing so an entry posted in between is not lost.
+	var events <-chan pubsub.Event[Entry]
+	if q.Wait > 0 {
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events = s.Subscribe(subCtx)
+	}
+
+	dbEntries, err := s.q.ListBlackboardEntries(ctx, rootID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blackboard entries: %w", err)
+	}
+	var entries []Entry
+	for _, item := range dbEntries {
+		if e := fromDBItem(item); q.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[len(entries)-q.Limit:]
+	}
+	if len(entries) > 0 || q.Wait <= 0 {
+		return entries, nil
+	}
+
+	timer := time.NewTimer(q.Wait)
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil, nil
+			}
+			if event.Type == pubsub.CreatedEvent && event.Payload.RootSessionID == rootID && q.matches(event.Payload) {
+				return []Entry{event.Payload}, nil
+			}
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *service) rootSessionID(ctx context.Context, sessionID string) (string, error) {
+	sess, err := s.q.GetSessionByID(ctx, sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	if sess.RootSessionID.Valid && sess.RootSessionID.String != "" {
+		return sess.RootSessionID.String, nil
+	}
+	return sess.ID, nil
+}
+
+func fromDBItem(item db.BlackboardEntry) Entry {
+	return Entry{
+		ID:            item.ID,
+		RootSessionID: item.RootSessionID,
+		SessionID:     item.SessionID,
+		Agent:         item.Agent,
+		Topic:         item.Topic,
+		Content:       item.Content,
+		CreatedAt:     item.CreatedAt,
+	}
+}
@@ -0,0 +1,202 @@
+package blackboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+func newTestServices(t *testing.T) (Service, session.Service) {
+	t.Helper()
+	q := db.NewTestQuerier(t)
+	return NewService(q), session.NewService(q, "proj")
+}
+
+func newTree(t *testing.T, sessions session.Service) (root, childA, childB session.Session) {
+	t.Helper()
+	ctx := context.Background()
+	root, err := sessions.Create(ctx, "root")
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	if childA, err = sessions.CreateTaskSession(ctx, "call-a", root.ID, "a"); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if childB, err = sessions.CreateTaskSession(ctx, "call-b", childA.ID, "b"); err != nil {
+		t.Fatalf("create grandchild: %v", err)
+	}
+	return root, childA, childB
+}
+
+func TestPostIsSharedAcrossTheSessionTree(t *testing.T) {
+	board, sessions := newTestServices(t)
+	ctx := context.Background()
+	root, childA, childB := newTree(t, sessions)
+	other, err := sessions.Create(ctx, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := board.Post(ctx, childA.ID, "explorer", "api", "  handlers live in internal/api  ")
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if first.RootSessionID != root.ID || first.SessionID != childA.ID || first.Content != "handlers live in internal/api" {
+		t.Fatalf("posted entry = %+v", first)
+	}
+	if _, err := board.Post(ctx, childB.ID, "workhorse", "tests", "TestServer fails on Windows"); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if _, err := board.Post(ctx, other.ID, "explorer", "api", "unrelated"); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+
+	for _, id := range []string{root.ID, childA.ID, childB.ID} {
+		entries, err := board.Query(ctx, id, Query{})
+		if err != nil {
+			t.Fatalf("query from %s: %v", id, err)
+		}
+		if len(entries) != 2 || entries[0].ID != first.ID {
+			t.Fatalf("query from %s = %+v, want both tree entries oldest first", id, entries)
+		}
+	}
+
+	if _, err := board.Post(ctx, root.ID, "hivemind", "", " "); !errors.Is(err, ErrEmptyEntry) {
+		t.Fatalf("empty post: err = %v, want ErrEmptyEntry", err)
+	}
+	if _, err := board.Query(ctx, "missing", Query{}); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("query missing session: err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	board, sessions := newTestServices(t)
+	ctx := context.Background()
+	root, childA, _ := newTree(t, sessions)
+
+	post := func(agent, topic, content string) Entry {
+		t.Helper()
+		e, err := board.Post(ctx, childA.ID, agent, topic, content)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		return e
+	}
+	a := post("explorer", "API", "router is in server.go")
+	b := post("workhorse", "tests", "flaky: TestRouter")
+	c := post("explorer", "tests", "fixtures under testdata/")
+
+	cases := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"topic ignores case", Query{Topic: "api"}, []string{a.ID}},
+		{"contains matches topic and content", Query{Contains: "ROUTER"}, []string{a.ID, b.ID}},
+		{"agent", Query{Agent: "explorer"}, []string{a.ID, c.ID}},
+		{"after", Query{After: a.ID}, []string{b.ID, c.ID}},
+		{"limit keeps the newest", Query{Topic: "tests", Limit: 1}, []string{c.ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := board.Query(ctx, root.ID, tc.query)
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if len(entries) != len(tc.want) {
+				t.Fatalf("got %d entries, want %d: %+v", len(entries), len(tc.want), entries)
+			}
+			for i, e := range entries {
+				if e.ID != tc.want[i] {
+					t.Fatalf("entries[%d] = %s (%s), want %s", i, e.ID, e.Content, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryWaitsForMatchingEntry(t *testing.T) {
+	board, sessions := newTestServices(t)
+	ctx := context.Background()
+	root, childA, _ := newTree(t, sessions)
+
+	type result struct {
+		entries []Entry
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		entries, err := board.Query(ctx, root.ID, Query{Topic: "done", Wait: 5 * time.Second})
+		done <- result{entries, err}
+	}()
+
+	// Keep posting until the waiter is subscribed: the first post may land
+	// before it is, in which case the listing picks it up instead.
+	deadline := time.Now().Add(5 * time.Second)
+	if _, err := board.Post(ctx, childA.ID, "workhorse", "progress", "halfway"); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := board.Post(ctx, childA.ID, "workhorse", "done", "all green"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case r := <-done:
+			if r.err != nil {
+				t.Fatalf("query: %v", r.err)
+			}
+			if len(r.entries) == 0 || r.entries[0].Content != "all green" {
+				t.Fatalf("waited entries = %+v", r.entries)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("query did not return after a matching post")
+		}
+	}
+}
+
+func TestQueryWaitTimesOut(t *testing.T) {
+	board, sessions := newTestServices(t)
+	root, _, _ := newTree(t, sessions)
+
+	start := time.Now()
+	entries, err := board.Query(context.Background(), root.ID, Query{Wait: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("entries = %+v, want none", entries)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatal("query returned before the wait elapsed")
+	}
+}
+
+func TestEntriesAreDeletedWithTheRootSession(t *testing.T) {
+	board, sessions := newTestServices(t)
+	ctx := context.Background()
+	root, childA, _ := newTree(t, sessions)
+	if _, err := board.Post(ctx, childA.ID, "explorer", "", "note"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.DeleteTree(ctx, root.ID); err != nil {
+		t.Fatalf("delete tree: %v", err)
+	}
+	recreated, err := sessions.CreateWithID(ctx, root.ID, "again")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := board.Query(ctx, recreated.ID, Query{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("entries survived the session delete: %+v", entries)
+	}
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/bridge"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/cron"
@@ -48,6 +50,7 @@ func (s *Service) ChatCommands() map[string]CommandHandler {
 		"session":  s.cmdSession,
 		"rename":   s.cmdRename,
 		"compact":  s.cmdCompact,
+		"pin":      s.cmdPin,
 		"crons":    s.cmdCrons,
 		"reset":    s.cmdReset,
 		"abort":    s.cmdAbort,
@@ -385,6 +388,29 @@ func (s *Service) cmdRename(ctx context.Context, in bridge.Inbound) *bridge.Comm
 	return replyText(fmt.Sprintf("Renamed session %s to: %s", shortSessionID(binding.SessionID), title))
 }
 
+// cmdPin toggles the pin on the last prompt of the bound session, the
+// equivalent of the TUI /pin command.
+func (s *Service) cmdPin(ctx context.Context, in bridge.Inbound) *bridge.CommandReply {
+	binding, err := s.resolveBinding(ctx, in.Peer)
+	if err != nil {
+		return replyText("Failed to resolve binding: " + err.Error())
+	}
+	if binding.SessionID == "" {
+		return replyText("No session bound here yet — send a message first, then `/pin`.")
+	}
+	msg, err := s.app.TogglePin(ctx, binding.SessionID)
+	if errors.Is(err, app.ErrNothingToPin) {
+		return replyText("Nothing to pin yet — send a message first.")
+	}
+	if err != nil {
+		return replyText("Failed to pin: " + err.Error())
+	}
+	if msg.IsPinned() {
+		return replyText("Pinned your last message; compaction keeps it verbatim.")
+	}
+	return replyText("Unpinned your last message.")
+}
+
 // compactSummaryMinChars is the floor for how much of the retained summary is
 // echoed back to chat. The actual limit scales with the model's context window
 // (see compactSummaryLimit) so large-context models — whose summaries run
@@ -633,6 +659,7 @@ func (s *Service) helpEntriesForChannel(channel string) []helpEntry {
 		{Cmd: "/session [id-prefix]", Desc: "show details or switch by ID prefix"},
 		{Cmd: "/rename <new title>", Desc: "rename the current session"},
 		{Cmd: "/compact", Desc: "summarize the current session to shrink its context"},
+		{Cmd: "/pin", Desc: "keep your last message verbatim through /compact (again to unpin)"},
 		{Cmd: "/crons", Desc: "list active scheduled cron jobs (★ = current session)"},
 		{Cmd: "/reset", Desc: "forget this binding; next message starts fresh"},
 		{Cmd: "/abort", Desc: "cancel an in-flight run on the current session"},
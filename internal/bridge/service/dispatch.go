@@ -354,6 +354,12 @@ func (d *sessionDispatch) handleTerminalEvent(ctx context.Context, ev agent.Agen
 			"session", d.sessionID, "err", ev.Error)
 		// Fall through to fan-out so any partial text the agent
 		// emitted before erroring still reaches the chat surface.
+	case agent.AgentEventTypeBudgetExceeded:
+		logging.Warn("bridge: agent run stopped by budget",
+			"session", d.sessionID, "err", ev.Error)
+		// Fan out the last response, then tell the peers why the agent
+		// went quiet.
+		defer d.notifyBudgetExceeded(ctx, ev.Error)
 	case agent.AgentEventTypeSummarize:
 		// Summarization is internal — no chat-surface delivery.
 		return
@@ -385,6 +391,20 @@ func (d *sessionDispatch) handleTerminalEvent(ctx context.Context, ev agent.Agen
 	}
 }
 
+// notifyBudgetExceeded tells the bound peers the run was stopped by a
+// cost or token limit, since no further replies will arrive until the
+// budget is raised.
+func (d *sessionDispatch) notifyBudgetExceeded(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	out := bridge.Outbound{Text: "✗ Agent stopped: " + err.Error()}
+	if _, sendErr := d.svc.SendBySessionID(ctx, d.sessionID, out); sendErr != nil {
+		logging.Warn("bridge: budget notice fan-out failed",
+			"session", d.sessionID, "err", sendErr)
+	}
+}
+
 // agentMessageText concatenates every TextContent part in the agent's
 // terminal message. ReasoningContent (the model's internal chain of
 // thought) and ToolCall/ToolResult parts are skipped — they're not
@@ -439,26 +459,27 @@ func (d *sessionDispatch) handlePartEvent(ev pubsub.Event[message.PartEvent]) {
 	tu := d.svc.cfg.ToolUpdatesEnabled
 	switch part := ev.Payload.Part.(type) {
 	case message.ToolCall:
-		// Streaming providers (Anthropic) publish each ToolCall up to
-		// THREE times:
+		// Streaming providers (Anthropic) publish each ToolCall many
+		// times:
 		//   1. EventToolUseStart — Finished=false, Input empty
-		//   2. EventToolUseStop  — Finished=true,  Input STILL empty
-		//      (the delta-accumulation path is commented out in
-		//      agent.go, so Input isn't merged at this point)
-		//   3. EventComplete     — Finished=true,  Input MERGED with
+		//   2. EventToolUseDelta — Finished=false, Input partial
+		//      (throttled, any number of times)
+		//   3. EventToolUseStop  — Finished=true,  Input complete
+		//   4. EventComplete     — Finished=true,  Input MERGED with
 		//      the assembled args (via mergeToolCalls)
-		// Non-streaming providers (OpenAI / Gemini) only fire #3.
+		// Non-streaming providers (OpenAI / Gemini) only fire #4.
 		//
 		// We want exactly one line per tool call, with the full args.
-		// Filter on `Finished && Input != ""`:
-		//   - #1 fails Finished                  → skip
-		//   - #2 has empty Input                 → skip
-		//   - #3 (the only useful one)           → emit
+		// Filter on `Finished && Input != ""` and emit the first such
+		// event only: #3 for streaming providers, #4 otherwise.
 		// A genuinely-no-args tool (e.g. get_all_projects → "{}")
 		// still passes because its Input is the literal "{}", not "".
 		if !tu || !part.Finished || part.Input == "" {
 			return
 		}
+		if d.toolCallStarted(part.ID) {
+			return
+		}
 		callID := callIDSuffix(part.ID)
 		label := part.Name + callID
 		paramsText := formatToolParams(part.Name, part.Input)
@@ -765,6 +786,12 @@ func (d *sessionDispatch) recordToolCallStart(toolCallID string) {
 	d.toolCallStart.Store(toolCallID, time.Now().UnixMilli())
 }
 
+// toolCallStarted reports whether the ToolCall was already emitted.
+func (d *sessionDispatch) toolCallStarted(toolCallID string) bool {
+	_, ok := d.toolCallStart.Load(toolCallID)
+	return ok
+}
+
 // consumeToolCallDuration returns the elapsed milliseconds since the
 // matching ToolCall start was recorded, then deletes the entry. Returns
 // 0 if no start was recorded (rare — usually means the call was
@@ -0,0 +1,364 @@
+// Package citation links spans of an assistant response to the tool results
+// that back them: file references such as main.go:12-20 and quoted code are
+// matched against the file contents and command output the agent actually
+// saw earlier in the session.
+package citation
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// minSnippetLen is the shortest inline code or code block line matched
+// against tool output. Shorter snippets (`err`, `nil`) appear everywhere and
+// would produce meaningless citations.
+const minSnippetLen = 8
+
+// Line is one line of tool output. Num is the 1-based line number in Path,
+// or 0 when the output is not a file.
+type Line struct {
+	Num  int
+	Text string
+}
+
+// Source is the evidence produced by a single tool call.
+type Source struct {
+	ToolCallID string
+	ToolName   string
+	Path       string
+	Lines      []Line
+}
+
+var (
+	readLineRe = regexp.MustCompile(`^\s*(\d+)\|(.*)$`)
+	grepLineRe = regexp.MustCompile(`^  Line (\d+): (.*)$`)
+	fileRefRe  = regexp.MustCompile(`([\w.\-/]*\w\.\w+):(\d+)(?:-(\d+))?`)
+	fenceRe    = regexp.MustCompile("(?s)```[^\n]*\n(.*?)```")
+	inlineRe   = regexp.MustCompile("`([^`\n]+)`")
+)
+
+// Collect extracts the citable evidence from the tool results in msgs, in
+// session order. Failed tool calls are skipped. Relative paths are resolved
+// against workingDir.
+func Collect(msgs []message.Message, workingDir string) []Source {
+	calls := make(map[string]message.ToolCall)
+	for _, msg := range msgs {
+		for _, tc := range msg.ToolCalls() {
+			calls[tc.ID] = tc
+		}
+	}
+
+	sources := make([]Source, 0)
+	for _, msg := range msgs {
+		for _, tr := range msg.ToolResults() {
+			if tr.IsError || tr.IsImageToolResponse() {
+				continue
+			}
+			input := calls[tr.ToolCallID].Input
+			switch tr.Name {
+			case tools.ReadToolName:
+				if src, ok := readSource(tr, input, workingDir); ok {
+					sources = append(sources, src)
+				}
+			case tools.GrepToolName:
+				sources = append(sources, grepSources(tr, input, workingDir)...)
+			case tools.CodeSearchToolName:
+				sources = append(sources, codeSearchSources(tr, workingDir)...)
+			case tools.BashToolName:
+				sources = append(sources, outputSource(tr))
+			}
+		}
+	}
+	return sources
+}
+
+func readSource(tr message.ToolResult, input, workingDir string) (Source, bool) {
+	var params tools.ViewParams
+	_ = json.Unmarshal([]byte(input), &params)
+	var meta tools.ViewResponseMetadata
+	_ = json.Unmarshal([]byte(tr.Metadata), &meta)
+
+	path := meta.FilePath
+	if path == "" {
+		path = params.FilePath
+	}
+	if path == "" {
+		return Source{}, false
+	}
+	src := Source{ToolCallID: tr.ToolCallID, ToolName: tr.Name, Path: absPath(path, workingDir)}
+	if meta.Content != "" {
+		for i, text := range strings.Split(meta.Content, "\n") {
+			src.Lines = append(src.Lines, Line{Num: params.Offset + 1 + i, Text: strings.TrimSuffix(text, "\r")})
+		}
+		return src, true
+	}
+	// Older results carry no metadata; fall back to the numbered output.
+	for _, text := range strings.Split(tr.Content, "\n") {
+		if m := readLineRe.FindStringSubmatch(text); m != nil {
+			num, _ := strconv.Atoi(m[1])
+			src.Lines = append(src.Lines, Line{Num: num, Text: m[2]})
+		}
+	}
+	return src, len(src.Lines) > 0
+}
+
+// grepSources splits grep output into one source per matched file.
+func grepSources(tr message.ToolResult, input, workingDir string) []Source {
+	var params tools.GrepParams
+	_ = json.Unmarshal([]byte(input), &params)
+	root := workingDir
+	if params.Path != "" {
+		root = absPath(params.Path, workingDir)
+	}
+
+	sources := make([]Source, 0)
+	var meta tools.GrepResponseMetadata
+	_ = json.Unmarshal([]byte(tr.Metadata), &meta)
+	if len(meta.Matches) > 0 {
+		byPath := make(map[string]int)
+		for _, m := range meta.Matches {
+			path := absPath(m.File, root)
+			i, ok := byPath[path]
+			if !ok {
+				i = len(sources)
+				byPath[path] = i
+				sources = append(sources, Source{ToolCallID: tr.ToolCallID, ToolName: tr.Name, Path: path})
+			}
+			sources[i].Lines = append(sources[i].Lines, Line{Num: m.Line, Text: m.Preview})
+		}
+		return sources
+	}
+	// Without structured matches, parse the built-in engine's older
+	// "path:" / "  Line N: text" output.
+	var cur *Source
+	for _, text := range strings.Split(tr.Content, "\n") {
+		if m := grepLineRe.FindStringSubmatch(text); m != nil && cur != nil {
+			num, _ := strconv.Atoi(m[1])
+			cur.Lines = append(cur.Lines, Line{Num: num, Text: m[2]})
+			continue
+		}
+		if strings.HasSuffix(text, ":") && !strings.HasPrefix(text, " ") {
+			sources = append(sources, Source{
+				ToolCallID: tr.ToolCallID,
+				ToolName:   tr.Name,
+				Path:       absPath(strings.TrimSuffix(text, ":"), root),
+			})
+			cur = &sources[len(sources)-1]
+		}
+	}
+	return sources
+}
+
+// codeSearchSources turns each codesearch snippet into a source.
+func codeSearchSources(tr message.ToolResult, workingDir string) []Source {
+	var meta tools.CodeSearchResponseMetadata
+	_ = json.Unmarshal([]byte(tr.Metadata), &meta)
+	sources := make([]Source, 0, len(meta.Results))
+	for _, r := range meta.Results {
+		src := Source{ToolCallID: tr.ToolCallID, ToolName: tr.Name, Path: absPath(r.Path, workingDir)}
+		for i, text := range strings.Split(r.Content, "\n") {
+			src.Lines = append(src.Lines, Line{Num: r.StartLine + i, Text: text})
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+func outputSource(tr message.ToolResult) Source {
+	src := Source{ToolCallID: tr.ToolCallID, ToolName: tr.Name}
+	for _, text := range strings.Split(tr.Content, "\n") {
+		src.Lines = append(src.Lines, Line{Text: text})
+	}
+	return src
+}
+
+// Extract finds the spans of text backed by sources and returns their
+// citations ordered by position. When several tool calls saw the same
+// evidence, the most recent one is cited.
+func Extract(text string, sources []Source, workingDir string) []message.Citation {
+	if len(sources) == 0 || text == "" {
+		return nil
+	}
+	var cites []message.Citation
+	taken := func(start, end int) bool {
+		for _, c := range cites {
+			if start < c.End && c.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, m := range fileRefRe.FindAllStringSubmatchIndex(text, -1) {
+		path := text[m[2]:m[3]]
+		start, _ := strconv.Atoi(text[m[4]:m[5]])
+		end := start
+		if m[6] >= 0 {
+			end, _ = strconv.Atoi(text[m[6]:m[7]])
+		}
+		src := findByPath(sources, path, start, workingDir)
+		if src == nil {
+			continue
+		}
+		cites = append(cites, message.Citation{
+			Start: m[0], End: m[1],
+			ToolCallID: src.ToolCallID, ToolName: src.ToolName,
+			Path: src.Path, StartLine: start, EndLine: max(start, end),
+		})
+	}
+
+	for _, m := range fenceRe.FindAllStringSubmatchIndex(text, -1) {
+		if taken(m[0], m[1]) {
+			continue
+		}
+		if c, ok := matchBlock(text[m[2]:m[3]], sources); ok {
+			c.Start, c.End = m[0], m[1]
+			cites = append(cites, c)
+		}
+	}
+
+	for _, m := range inlineRe.FindAllStringSubmatchIndex(text, -1) {
+		if taken(m[0], m[1]) || insideFence(text, m[0]) {
+			continue
+		}
+		snippet := strings.TrimSpace(text[m[2]:m[3]])
+		var c message.Citation
+		ok := false
+		if src := findByPath(sources, snippet, 0, workingDir); src != nil {
+			c, ok = citeSource(*src, 0, len(src.Lines)-1), true
+		} else if len(snippet) >= minSnippetLen {
+			c, ok = matchLine(snippet, sources)
+		}
+		if ok {
+			c.Start, c.End = m[0], m[1]
+			cites = append(cites, c)
+		}
+	}
+
+	sort.Slice(cites, func(i, j int) bool { return cites[i].Start < cites[j].Start })
+	return cites
+}
+
+// findByPath returns the latest file source for path, preferring one whose
+// lines include line when it is non-zero.
+func findByPath(sources []Source, path string, line int, workingDir string) *Source {
+	var fallback *Source
+	for i := len(sources) - 1; i >= 0; i-- {
+		src := &sources[i]
+		if src.Path == "" || !samePath(src.Path, path, workingDir) {
+			continue
+		}
+		if line == 0 || hasLine(*src, line) {
+			return src
+		}
+		if fallback == nil {
+			fallback = src
+		}
+	}
+	return fallback
+}
+
+func samePath(full, ref, workingDir string) bool {
+	ref = filepath.Clean(ref)
+	if filepath.IsAbs(ref) {
+		return full == ref
+	}
+	if full == filepath.Join(workingDir, ref) {
+		return true
+	}
+	// Bare file names and partial paths still count when unambiguous
+	// enough to share the trailing path components.
+	return strings.HasSuffix(full, string(filepath.Separator)+ref)
+}
+
+func hasLine(src Source, num int) bool {
+	for _, l := range src.Lines {
+		if l.Num == num {
+			return true
+		}
+	}
+	return false
+}
+
+func matchLine(snippet string, sources []Source) (message.Citation, bool) {
+	for i := len(sources) - 1; i >= 0; i-- {
+		for j, l := range sources[i].Lines {
+			if strings.Contains(l.Text, snippet) {
+				return citeSource(sources[i], j, j), true
+			}
+		}
+	}
+	return message.Citation{}, false
+}
+
+// matchBlock looks for the non-blank lines of a code block as a run of
+// consecutive lines in one source. Whitespace at either end of a line is
+// ignored so re-indented quotes still match.
+func matchBlock(block string, sources []Source) (message.Citation, bool) {
+	var want []string
+	for _, l := range strings.Split(block, "\n") {
+		if t := strings.TrimSpace(l); t != "" {
+			want = append(want, t)
+		}
+	}
+	if len(want) == 0 || (len(want) == 1 && len(want[0]) < minSnippetLen) {
+		return message.Citation{}, false
+	}
+	for i := len(sources) - 1; i >= 0; i-- {
+		lines := sources[i].Lines
+		for j := range lines {
+			if strings.TrimSpace(lines[j].Text) != want[0] {
+				continue
+			}
+			k, w := j, 0
+			for k < len(lines) && w < len(want) {
+				t := strings.TrimSpace(lines[k].Text)
+				if t == "" {
+					k++
+					continue
+				}
+				if t != want[w] {
+					break
+				}
+				k++
+				w++
+			}
+			if w == len(want) {
+				return citeSource(sources[i], j, k-1), true
+			}
+		}
+	}
+	return message.Citation{}, false
+}
+
+func citeSource(src Source, from, to int) message.Citation {
+	c := message.Citation{ToolCallID: src.ToolCallID, ToolName: src.ToolName, Path: src.Path}
+	if len(src.Lines) > 0 && src.Lines[from].Num > 0 {
+		c.StartLine = src.Lines[from].Num
+		c.EndLine = src.Lines[to].Num
+	}
+	return c
+}
+
+func insideFence(text string, pos int) bool {
+	for _, m := range fenceRe.FindAllStringIndex(text, -1) {
+		if pos >= m[0] && pos < m[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func absPath(path, base string) string {
+	if filepath.IsAbs(path) || base == "" {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
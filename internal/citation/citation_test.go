@@ -0,0 +1,141 @@
+package citation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/codesearch"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func toolTurn(id, name string, input any, result message.ToolResult) []message.Message {
+	in, _ := json.Marshal(input)
+	result.ToolCallID = id
+	result.Name = name
+	return []message.Message{
+		{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: id, Name: name, Input: string(in)}}},
+		{Role: message.Tool, Parts: []message.ContentPart{result}},
+	}
+}
+
+func testSources(t *testing.T) []Source {
+	t.Helper()
+	meta, _ := json.Marshal(tools.ViewResponseMetadata{
+		FilePath: "/repo/internal/app/app.go",
+		Content:  "func New() *App {\n\treturn &App{}\n}",
+	})
+	var msgs []message.Message
+	msgs = append(msgs, toolTurn("read-1", tools.ReadToolName,
+		tools.ViewParams{FilePath: "internal/app/app.go", Offset: 9},
+		message.ToolResult{Content: "<file>...</file>", Metadata: string(meta)})...)
+	msgs = append(msgs, toolTurn("grep-1", tools.GrepToolName,
+		tools.GrepParams{Pattern: "Timeout"},
+		message.ToolResult{Content: "Found 1 files\nconfig/config.go:\n  Line 42: \tTimeout time.Duration\n"})...)
+	msgs = append(msgs, toolTurn("bash-1", tools.BashToolName,
+		map[string]string{"command": "go test ./..."},
+		message.ToolResult{Content: "ok  \tgithub.com/x/app\t0.02s\nFAIL\tgithub.com/x/cmd [build failed]"})...)
+	msgs = append(msgs, toolTurn("bash-2", tools.BashToolName,
+		map[string]string{"command": "false"},
+		message.ToolResult{Content: "exit status 1", IsError: true})...)
+
+	sources := Collect(msgs, "/repo")
+	if len(sources) != 3 {
+		t.Fatalf("Collect returned %d sources, want 3 (errors skipped)", len(sources))
+	}
+	return sources
+}
+
+func TestExtract(t *testing.T) {
+	sources := testSources(t)
+	text := strings.Join([]string{
+		"The constructor lives in app.go:10-12 and looks like this:",
+		"```go",
+		"func New() *App {",
+		"    return &App{}",
+		"}",
+		"```",
+		"The field `Timeout time.Duration` is declared in config/config.go:42.",
+		"The build fails: `[build failed]`, while `main.go` was never read.",
+	}, "\n")
+
+	cites := Extract(text, sources, "/repo")
+	want := []struct {
+		span       string
+		toolCallID string
+		path       string
+		start, end int
+	}{
+		{"app.go:10-12", "read-1", "/repo/internal/app/app.go", 10, 12},
+		{"```go\nfunc New() *App {\n    return &App{}\n}\n```", "read-1", "/repo/internal/app/app.go", 10, 12},
+		{"`Timeout time.Duration`", "grep-1", "/repo/config/config.go", 42, 42},
+		{"config/config.go:42", "grep-1", "/repo/config/config.go", 42, 42},
+		{"`[build failed]`", "bash-1", "", 0, 0},
+	}
+	if len(cites) != len(want) {
+		t.Fatalf("got %d citations, want %d: %+v", len(cites), len(want), cites)
+	}
+	for i, w := range want {
+		c := cites[i]
+		if got := text[c.Start:c.End]; got != w.span {
+			t.Errorf("citation %d span = %q, want %q", i, got, w.span)
+		}
+		if c.ToolCallID != w.toolCallID || c.Path != w.path || c.StartLine != w.start || c.EndLine != w.end {
+			t.Errorf("citation %d = %+v, want %s %s:%d-%d", i, c, w.toolCallID, w.path, w.start, w.end)
+		}
+	}
+}
+
+func TestExtractIgnoresUnbackedReferences(t *testing.T) {
+	sources := testSources(t)
+	text := "See other.go:3, the snippet `fmt.Println(x)` and `err`."
+	if cites := Extract(text, sources, "/repo"); len(cites) != 0 {
+		t.Fatalf("expected no citations, got %+v", cites)
+	}
+}
+
+func TestCollectGrepMatches(t *testing.T) {
+	meta, _ := json.Marshal(tools.GrepResponseMetadata{
+		Mode: "content",
+		Matches: []tools.GrepMatch{
+			{File: "config/config.go", Line: 41, Preview: "type Config struct {", Context: true},
+			{File: "config/config.go", Line: 42, Column: 2, Preview: "\tTimeout time.Duration"},
+			{File: "app.go", Line: 7, Column: 5, Preview: "cfg.Timeout = 0"},
+		},
+	})
+	msgs := toolTurn("grep-1", tools.GrepToolName,
+		tools.GrepParams{Pattern: "Timeout", Path: "internal", OutputMode: "content"},
+		message.ToolResult{Content: "internal/config/config.go:42:\tTimeout time.Duration\n", Metadata: string(meta)})
+
+	sources := Collect(msgs, "/repo")
+	if len(sources) != 2 {
+		t.Fatalf("Collect returned %d sources, want one per file: %+v", len(sources), sources)
+	}
+	if sources[0].Path != "/repo/internal/config/config.go" || len(sources[0].Lines) != 2 || sources[0].Lines[1].Num != 42 {
+		t.Errorf("unexpected first source %+v", sources[0])
+	}
+	if sources[1].Path != "/repo/internal/app.go" || sources[1].Lines[0].Text != "cfg.Timeout = 0" {
+		t.Errorf("unexpected second source %+v", sources[1])
+	}
+}
+
+func TestCollectCodeSearchResults(t *testing.T) {
+	meta, _ := json.Marshal(tools.CodeSearchResponseMetadata{
+		Results: []codesearch.Result{
+			{Path: "internal/config/load.go", StartLine: 40, EndLine: 41, Content: "func Load() error {\n\treturn nil"},
+		},
+	})
+	msgs := toolTurn("cs-1", tools.CodeSearchToolName,
+		tools.CodeSearchParams{Query: "where is the config loaded"},
+		message.ToolResult{Content: "internal/config/load.go:40-41 (score 0.91)\n", Metadata: string(meta)})
+
+	sources := Collect(msgs, "/repo")
+	if len(sources) != 1 {
+		t.Fatalf("Collect returned %d sources: %+v", len(sources), sources)
+	}
+	src := sources[0]
+	if src.Path != "/repo/internal/config/load.go" || len(src.Lines) != 2 || src.Lines[1].Num != 41 || src.Lines[1].Text != "\treturn nil" {
+		t.Errorf("unexpected source %+v", src)
+	}
+}
@@ -0,0 +1,80 @@
+package codesearch
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// chunkLines is the size of the window a file is split into;
+	// consecutive windows share chunkOverlap lines so a definition cut at
+	// a boundary still appears whole in one of them.
+	chunkLines   = 60
+	chunkOverlap = 10
+	// maxChunkBytes ends a window early on long lines, keeping each chunk
+	// well inside the embedding models' input limits.
+	maxChunkBytes = 4000
+	// maxFileBytes skips generated and data files that would flood the
+	// index.
+	maxFileBytes = 512 * 1024
+)
+
+// Chunk is a window of lines of one file. Lines are 1-based and
+// inclusive.
+type Chunk struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Content   string
+}
+
+// embeddingText is what gets embedded: the path carries as much meaning
+// as the code for queries like "where is the config loaded".
+func (c Chunk) embeddingText() string {
+	return c.Path + "\n" + c.Content
+}
+
+// indexable reports whether content looks like text worth indexing.
+func indexable(content []byte) bool {
+	if len(content) == 0 || len(content) > maxFileBytes {
+		return false
+	}
+	head := content[:min(len(content), 8000)]
+	return !bytes.Contains(head, []byte{0}) && utf8.Valid(head)
+}
+
+// SplitFile cuts content into overlapping windows of lines. Windows
+// holding only whitespace are dropped.
+func SplitFile(path, content string) []Chunk {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var chunks []Chunk
+	for start := 0; start < len(lines); {
+		end, size := start, 0
+		for end < len(lines) && end-start < chunkLines {
+			if end > start && size+len(lines[end]) > maxChunkBytes {
+				break
+			}
+			size += len(lines[end]) + 1
+			end++
+		}
+		text := strings.Join(lines[start:end], "\n")
+		if len(text) > maxChunkBytes {
+			// A single huge line; cut it on a rune boundary.
+			n := maxChunkBytes
+			for n > 0 && !utf8.RuneStart(text[n]) {
+				n--
+			}
+			text = text[:n]
+		}
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, Chunk{Path: path, StartLine: start + 1, EndLine: end, Content: text})
+		}
+		if end == len(lines) {
+			break
+		}
+		// Step back by the overlap, but always move forward.
+		start = max(end-chunkOverlap, start+1)
+	}
+	return chunks
+}
@@ -0,0 +1,206 @@
+package codesearch
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+// Default embedding models per provider. local has none: the model
+// depends on what the server has loaded.
+var defaultModels = map[models.ModelProvider]string{
+	models.ProviderOpenAI: "text-embedding-3-small",
+	models.ProviderGemini: "text-embedding-004",
+	models.ProviderOllama: "nomic-embed-text",
+}
+
+const (
+	openAIBaseURL = "https://api.openai.com/v1"
+	geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+var embedHTTPClient = &http.Client{Timeout: 2 * time.Minute}
+
+// Embedder turns texts into vectors, all from one model.
+type Embedder interface {
+	// Model identifies the vectors; chunks embedded by another model are
+	// re-indexed.
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder returns the embedder cs selects, authenticated with the
+// matching entry of providers.
+func NewEmbedder(cs *config.CodeSearchConfig, providers map[models.ModelProvider]config.Provider) (Embedder, error) {
+	model := cmp.Or(cs.Model, defaultModels[cs.Provider])
+	if model == "" {
+		return nil, fmt.Errorf("no embedding model configured for %s", cs.Provider)
+	}
+	p := providers[cs.Provider]
+	switch cs.Provider {
+	case models.ProviderOpenAI:
+		if p.APIKey == "" {
+			return nil, fmt.Errorf("codesearch needs an API key for the openai provider")
+		}
+		return &openAIEmbedder{
+			url:     strings.TrimRight(cmp.Or(cs.BaseURL, p.BaseURL, openAIBaseURL), "/") + "/embeddings",
+			apiKey:  p.APIKey,
+			headers: p.Headers,
+			model:   model,
+		}, nil
+	case models.ProviderOllama:
+		return &openAIEmbedder{
+			url:     strings.TrimRight(cmp.Or(cs.BaseURL, models.OllamaHost()+"/v1"), "/") + "/embeddings",
+			apiKey:  cmp.Or(p.APIKey, os.Getenv("OLLAMA_API_KEY")),
+			headers: p.Headers,
+			model:   model,
+		}, nil
+	case models.ProviderLocal:
+		base := cs.BaseURL
+		if base == "" {
+			endpoint := os.Getenv("LOCAL_ENDPOINT")
+			if endpoint == "" {
+				return nil, fmt.Errorf("codesearch needs codeSearch.baseURL or LOCAL_ENDPOINT for the local provider")
+			}
+			base = strings.TrimRight(endpoint, "/") + "/v1"
+		}
+		return &openAIEmbedder{
+			url:     strings.TrimRight(base, "/") + "/embeddings",
+			apiKey:  cmp.Or(p.APIKey, os.Getenv("LOCAL_ENDPOINT_API_KEY")),
+			headers: p.Headers,
+			model:   model,
+		}, nil
+	case models.ProviderGemini:
+		if p.APIKey == "" {
+			return nil, fmt.Errorf("codesearch needs an API key for the gemini provider")
+		}
+		return &geminiEmbedder{
+			baseURL: strings.TrimRight(cmp.Or(cs.BaseURL, geminiBaseURL), "/"),
+			apiKey:  p.APIKey,
+			model:   model,
+		}, nil
+	default:
+		return nil, fmt.Errorf("provider %s does not serve embeddings", cs.Provider)
+	}
+}
+
+// openAIEmbedder calls an OpenAI-compatible /embeddings endpoint, which
+// Ollama, LM Studio and most local servers implement too.
+type openAIEmbedder struct {
+	url     string
+	apiKey  string
+	headers map[string]string
+	model   string
+}
+
+func (e *openAIEmbedder) Model() string { return e.model }
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var res struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	headers := map[string]string{}
+	for k, v := range e.headers {
+		headers[k] = v
+	}
+	if e.apiKey != "" {
+		headers["Authorization"] = "Bearer " + e.apiKey
+	}
+	body := map[string]any{"model": e.model, "input": texts}
+	if err := postJSON(ctx, e.url, headers, body, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings endpoint returned %d vectors for %d inputs", len(res.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range res.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings endpoint returned index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// geminiEmbedder calls the Gemini batchEmbedContents endpoint.
+type geminiEmbedder struct {
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func (e *geminiEmbedder) Model() string { return e.model }
+
+func (e *geminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	type part struct {
+		Text string `json:"text"`
+	}
+	type request struct {
+		Model   string `json:"model"`
+		Content struct {
+			Parts []part `json:"parts"`
+		} `json:"content"`
+	}
+	requests := make([]request, len(texts))
+	for i, t := range texts {
+		requests[i].Model = "models/" + e.model
+		requests[i].Content.Parts = []part{{Text: t}}
+	}
+	var res struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	url := fmt.Sprintf("%s/models/%s:batchEmbedContents", e.baseURL, e.model)
+	headers := map[string]string{"x-goog-api-key": e.apiKey}
+	if err := postJSON(ctx, url, headers, map[string]any{"requests": requests}, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embeddings endpoint returned %d vectors for %d inputs", len(res.Embeddings), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for i, emb := range res.Embeddings {
+		vectors[i] = emb.Values
+	}
+	return vectors, nil
+}
+
+func postJSON(ctx context.Context, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := embedHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("embedding request failed: %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,388 @@
+// Package codesearch answers natural-language queries about the workspace.
+// Files are split into overlapping line windows, embedded by the
+// configured provider and stored in the database; a query is embedded the
+// same way and the closest chunks are returned.
+package codesearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/fileutil"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// embedBatchSize is how many chunks go into one embeddings request.
+const embedBatchSize = 64
+
+// Result is one chunk matching a query.
+type Result struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"startLine"`
+	EndLine   int     `json:"endLine"`
+	Score     float64 `json:"score"`
+	Content   string  `json:"content"`
+}
+
+// Stats summarises an Update.
+type Stats struct {
+	Files   int // files in the index afterwards
+	Indexed int // files embedded in this update
+	Removed int // files dropped because they are gone or excluded
+	Chunks  int // chunks embedded in this update
+}
+
+// Index is the embedded chunks of one project. Paths are stored relative
+// to root with forward slashes.
+type Index struct {
+	q         db.Querier
+	projectID string
+	root      string
+	embedder  Embedder
+	exclude   []string
+
+	// mu serialises updates; indexed records that a full Update has
+	// succeeded in this process.
+	mu      sync.Mutex
+	indexed bool
+
+	// cache holds the project's chunks with decoded vectors between
+	// searches and is dropped whenever the index changes.
+	cacheMu sync.Mutex
+	cache   []cachedChunk
+}
+
+type cachedChunk struct {
+	chunk  Chunk
+	vector []float32
+}
+
+// New returns the index of projectID's files under root.
+func New(q db.Querier, projectID, root string, embedder Embedder, exclude []string) *Index {
+	return &Index{q: q, projectID: projectID, root: root, embedder: embedder, exclude: exclude}
+}
+
+// Update brings the index in line with the files under root: new and
+// changed files are embedded, deleted and excluded ones dropped. A change
+// of embedding model re-indexes everything.
+func (ix *Index) Update(ctx context.Context) (Stats, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.update(ctx)
+}
+
+// EnsureIndexed runs Update unless one has already succeeded in this
+// process; file changes after that arrive through Watch.
+func (ix *Index) EnsureIndexed(ctx context.Context) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.indexed {
+		return nil
+	}
+	_, err := ix.update(ctx)
+	return err
+}
+
+func (ix *Index) update(ctx context.Context) (Stats, error) {
+	var stats Stats
+	stored, err := ix.q.ListCodeChunkFiles(ctx, ix.projectID)
+	if err != nil {
+		return stats, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	hashes := make(map[string]string, len(stored))
+	for _, f := range stored {
+		if f.Model != ix.embedder.Model() {
+			logging.Info("Embedding model changed, rebuilding code index", "from", f.Model, "to", ix.embedder.Model())
+			if err := ix.q.DeleteCodeChunksForProject(ctx, ix.projectID); err != nil {
+				return stats, fmt.Errorf("failed to clear code index: %w", err)
+			}
+			hashes = map[string]string{}
+			break
+		}
+		hashes[f.Path] = f.FileHash
+	}
+	defer ix.dropCache()
+
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(ix.root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(ix.root, path)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		if fileutil.SkipHidden(rel) || ix.excluded(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || !indexable(content) {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+		hash := hashContent(content)
+		if hashes[rel] == hash {
+			return nil
+		}
+		n, err := ix.indexFile(ctx, rel, string(content), hash)
+		if err != nil {
+			return err
+		}
+		stats.Indexed++
+		stats.Chunks += n
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	for path := range hashes {
+		if seen[path] {
+			continue
+		}
+		if err := ix.q.DeleteCodeChunksForPath(ctx, db.DeleteCodeChunksForPathParams{ProjectID: ix.projectID, Path: path}); err != nil {
+			return stats, fmt.Errorf("failed to drop %s from the code index: %w", path, err)
+		}
+		stats.Removed++
+	}
+	stats.Files = len(seen)
+	ix.indexed = true
+	logging.Info("Code index updated", "files", stats.Files, "indexed", stats.Indexed, "removed", stats.Removed, "chunks", stats.Chunks)
+	return stats, nil
+}
+
+// UpdateFile re-indexes one file, given as an absolute path or relative
+// to root. A file that no longer exists, is excluded or is not text is
+// dropped from the index.
+func (ix *Index) UpdateFile(ctx context.Context, path string) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ix.root, path)
+	}
+	rel, err := filepath.Rel(ix.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	defer ix.dropCache()
+
+	rel = filepath.ToSlash(rel)
+	content, err := os.ReadFile(path)
+	if err != nil || !indexable(content) || fileutil.SkipHidden(filepath.FromSlash(rel)) || ix.excluded(rel) {
+		if err := ix.q.DeleteCodeChunksForPath(ctx, db.DeleteCodeChunksForPathParams{ProjectID: ix.projectID, Path: rel}); err != nil {
+			return fmt.Errorf("failed to drop %s from the code index: %w", rel, err)
+		}
+		return nil
+	}
+	_, err = ix.indexFile(ctx, rel, string(content), hashContent(content))
+	return err
+}
+
+// indexFile replaces the stored chunks of rel, returning how many were
+// written.
+func (ix *Index) indexFile(ctx context.Context, rel, content, hash string) (int, error) {
+	chunks := SplitFile(rel, content)
+	vectors := make([][]float32, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		batch := chunks[start:min(start+embedBatchSize, len(chunks))]
+		texts := make([]string, len(batch))
+		for i, c := range batch {
+			texts[i] = c.embeddingText()
+		}
+		embedded, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed %s: %w", rel, err)
+		}
+		vectors = append(vectors, embedded...)
+	}
+
+	if err := ix.q.DeleteCodeChunksForPath(ctx, db.DeleteCodeChunksForPathParams{ProjectID: ix.projectID, Path: rel}); err != nil {
+		return 0, fmt.Errorf("failed to drop old chunks of %s: %w", rel, err)
+	}
+	now := time.Now().Unix()
+	for i, c := range chunks {
+		err := ix.q.CreateCodeChunk(ctx, db.CreateCodeChunkParams{
+			ProjectID: ix.projectID,
+			Path:      rel,
+			StartLine: int64(c.StartLine),
+			EndLine:   int64(c.EndLine),
+			Content:   c.Content,
+			FileHash:  hash,
+			Model:     ix.embedder.Model(),
+			Embedding: encodeVector(normalize(vectors[i])),
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to store chunk of %s: %w", rel, err)
+		}
+	}
+	return len(chunks), nil
+}
+
+// Search returns the limit chunks closest to query, best first. pathPrefix,
+// when set, keeps only files under that directory or with that prefix.
+func (ix *Index) Search(ctx context.Context, query, pathPrefix string, limit int) ([]Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is empty")
+	}
+	if err := ix.EnsureIndexed(ctx); err != nil {
+		return nil, err
+	}
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embeddings endpoint returned %d vectors for the query", len(vectors))
+	}
+	q := normalize(vectors[0])
+
+	chunks, err := ix.chunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pathPrefix = strings.TrimPrefix(filepath.ToSlash(pathPrefix), "./")
+	var results []Result
+	for _, c := range chunks {
+		if len(c.vector) != len(q) || !strings.HasPrefix(c.chunk.Path, pathPrefix) {
+			continue
+		}
+		results = append(results, Result{
+			Path:      c.chunk.Path,
+			StartLine: c.chunk.StartLine,
+			EndLine:   c.chunk.EndLine,
+			Score:     dot(q, c.vector),
+			Content:   c.chunk.Content,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return dedupeOverlaps(results, limit), nil
+}
+
+// dedupeOverlaps keeps the best of any overlapping windows of the same
+// file, so the neighbouring windows of one hit don't crowd out other
+// files.
+func dedupeOverlaps(sorted []Result, limit int) []Result {
+	var kept []Result
+	for _, r := range sorted {
+		if limit > 0 && len(kept) >= limit {
+			break
+		}
+		if slices.ContainsFunc(kept, func(k Result) bool {
+			return k.Path == r.Path && k.StartLine <= r.EndLine && r.StartLine <= k.EndLine
+		}) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+func (ix *Index) chunks(ctx context.Context) ([]cachedChunk, error) {
+	ix.cacheMu.Lock()
+	defer ix.cacheMu.Unlock()
+	if ix.cache != nil {
+		return ix.cache, nil
+	}
+	rows, err := ix.q.ListCodeChunks(ctx, db.ListCodeChunksParams{ProjectID: ix.projectID, Model: ix.embedder.Model()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load code index: %w", err)
+	}
+	cache := make([]cachedChunk, 0, len(rows))
+	for _, r := range rows {
+		cache = append(cache, cachedChunk{
+			chunk:  Chunk{Path: r.Path, StartLine: int(r.StartLine), EndLine: int(r.EndLine), Content: r.Content},
+			vector: decodeVector(r.Embedding),
+		})
+	}
+	ix.cache = cache
+	return cache, nil
+}
+
+func (ix *Index) dropCache() {
+	ix.cacheMu.Lock()
+	ix.cache = nil
+	ix.cacheMu.Unlock()
+}
+
+func (ix *Index) excluded(rel string) bool {
+	for _, pattern := range ix.exclude {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// dot is the cosine similarity of two normalised vectors.
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
@@ -0,0 +1,256 @@
+package codesearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+// wordEmbedder embeds a text as the counts of a few words, which is
+// enough to rank the test files deterministically.
+type wordEmbedder struct {
+	model string
+	calls int
+	texts int
+}
+
+var testVocabulary = []string{"config", "parse", "retry", "http", "database"}
+
+func (e *wordEmbedder) Model() string { return e.model }
+
+func (e *wordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	e.texts += len(texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, len(testVocabulary))
+		lower := strings.ToLower(text)
+		for j, w := range testVocabulary {
+			v[j] = float32(strings.Count(lower, w))
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+func newTestIndex(t *testing.T, embedder Embedder, exclude ...string) (*Index, string) {
+	t.Helper()
+	root := t.TempDir()
+	return New(db.NewTestQuerier(t), "project", root, embedder, exclude), root
+}
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitFile(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 130; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	chunks := SplitFile("a.go", strings.Join(lines, "\n")+"\n")
+	want := [][2]int{{1, 60}, {51, 110}, {101, 130}}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(want), chunks)
+	}
+	for i, w := range want {
+		if chunks[i].StartLine != w[0] || chunks[i].EndLine != w[1] {
+			t.Errorf("chunk %d covers %d-%d, want %d-%d", i, chunks[i].StartLine, chunks[i].EndLine, w[0], w[1])
+		}
+	}
+	if !strings.HasPrefix(chunks[1].Content, "line 51\n") {
+		t.Errorf("chunk 1 starts with %q", chunks[1].Content[:10])
+	}
+
+	if got := SplitFile("blank.txt", "\n\n  \n"); len(got) != 0 {
+		t.Errorf("blank file produced chunks: %+v", got)
+	}
+
+	long := strings.Repeat("x", 3000)
+	got := SplitFile("long.txt", long+"\n"+long+"\n"+long)
+	if len(got) < 2 {
+		t.Fatalf("long lines were not split: %+v", got)
+	}
+	for _, c := range got {
+		if len(c.Content) > maxChunkBytes {
+			t.Errorf("chunk %d-%d is %d bytes", c.StartLine, c.EndLine, len(c.Content))
+		}
+	}
+}
+
+func TestIndexUpdateAndSearch(t *testing.T) {
+	ctx := context.Background()
+	embedder := &wordEmbedder{model: "words-v1"}
+	ix, root := newTestIndex(t, embedder, "gen/**")
+
+	writeFile(t, root, "internal/config/load.go", "// Load and parse the config file.\nfunc Load() {}\n")
+	writeFile(t, root, "internal/client/retry.go", "// Retry failed http requests.\nfunc Do() {}\n")
+	writeFile(t, root, "gen/schema.go", "// parse config parse config\n")
+	writeFile(t, root, "node_modules/x/index.js", "parse config\n")
+	writeFile(t, root, "logo.png", "\x89PNG\x00\x00config")
+
+	stats, err := ix.Update(ctx)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if stats.Files != 2 || stats.Indexed != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	results, err := ix.Search(ctx, "where is the config parsed", "", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) == 0 || results[0].Path != "internal/config/load.go" {
+		t.Fatalf("config file should rank first: %+v", results)
+	}
+	if results[0].StartLine != 1 || !strings.Contains(results[0].Content, "func Load") {
+		t.Errorf("unexpected top result %+v", results[0])
+	}
+
+	results, err = ix.Search(ctx, "retry http", "internal/config/", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	for _, r := range results {
+		if !strings.HasPrefix(r.Path, "internal/config/") {
+			t.Errorf("path filter let %s through", r.Path)
+		}
+	}
+
+	// Nothing changed: nothing is embedded again.
+	before := embedder.texts
+	if stats, err = ix.Update(ctx); err != nil || stats.Indexed != 0 {
+		t.Fatalf("no-op Update = %+v, %v", stats, err)
+	}
+	if embedder.texts != before {
+		t.Errorf("no-op Update embedded %d texts", embedder.texts-before)
+	}
+
+	// A changed and a deleted file.
+	writeFile(t, root, "internal/client/retry.go", "// Open the database.\n")
+	if err := os.Remove(filepath.Join(root, "internal/config/load.go")); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err = ix.Update(ctx); err != nil || stats.Indexed != 1 || stats.Removed != 1 || stats.Files != 1 {
+		t.Fatalf("Update after changes = %+v, %v", stats, err)
+	}
+	results, _ = ix.Search(ctx, "database", "", 5)
+	if len(results) != 1 || results[0].Path != "internal/client/retry.go" {
+		t.Fatalf("unexpected results after re-index: %+v", results)
+	}
+}
+
+func TestIndexUpdateFile(t *testing.T) {
+	ctx := context.Background()
+	ix, root := newTestIndex(t, &wordEmbedder{model: "words-v1"})
+	writeFile(t, root, "a.go", "// http client\n")
+	if err := ix.EnsureIndexed(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, root, "b.go", "// database access\n")
+	if err := ix.UpdateFile(ctx, filepath.Join(root, "b.go")); err != nil {
+		t.Fatalf("UpdateFile: %v", err)
+	}
+	results, err := ix.Search(ctx, "database", "", 1)
+	if err != nil || len(results) != 1 || results[0].Path != "b.go" {
+		t.Fatalf("new file not searchable: %+v, %v", results, err)
+	}
+
+	if err := os.Remove(filepath.Join(root, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.UpdateFile(ctx, "b.go"); err != nil {
+		t.Fatalf("UpdateFile after delete: %v", err)
+	}
+	results, _ = ix.Search(ctx, "database", "", 5)
+	for _, r := range results {
+		if r.Path == "b.go" {
+			t.Fatalf("deleted file still indexed: %+v", results)
+		}
+	}
+
+	// Files outside the root are ignored.
+	if err := ix.UpdateFile(ctx, filepath.Join(t.TempDir(), "c.go")); err != nil {
+		t.Errorf("UpdateFile outside root: %v", err)
+	}
+}
+
+func TestIndexModelChangeRebuilds(t *testing.T) {
+	ctx := context.Background()
+	ix, root := newTestIndex(t, &wordEmbedder{model: "words-v1"})
+	writeFile(t, root, "a.go", "// http client\n")
+	if _, err := ix.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	next := &wordEmbedder{model: "words-v2"}
+	ix.embedder = next
+	stats, err := ix.Update(ctx)
+	if err != nil || stats.Indexed != 1 {
+		t.Fatalf("Update with a new model = %+v, %v", stats, err)
+	}
+	if next.texts == 0 {
+		t.Error("new model embedded nothing")
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var got struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		// Out of order, as the API allows.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer srv.Close()
+
+	embedder, err := NewEmbedder(
+		&config.CodeSearchConfig{Provider: models.ProviderOpenAI, BaseURL: srv.URL + "/v1"},
+		map[models.ModelProvider]config.Provider{models.ProviderOpenAI: {APIKey: "key"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedder.Model() != "text-embedding-3-small" {
+		t.Errorf("default model = %q", embedder.Model())
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if got.Model != "text-embedding-3-small" || len(got.Input) != 2 {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors not ordered by index: %v", vectors)
+	}
+
+	if _, err := NewEmbedder(&config.CodeSearchConfig{Provider: models.ProviderOpenAI}, nil); err == nil {
+		t.Error("expected an error without an API key")
+	}
+}
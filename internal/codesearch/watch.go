@@ -0,0 +1,48 @@
+package codesearch
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// watchDebounce batches the burst of history events one multi-file edit
+// produces into a single re-index.
+const watchDebounce = 2 * time.Second
+
+// Watch re-indexes every file the history service records a change to,
+// until ctx is done. Changes made outside opencode are picked up by the
+// next Update.
+func (ix *Index) Watch(ctx context.Context, files pubsub.Suscriber[history.File]) {
+	defer logging.RecoverPanic("code-index-watch", nil)
+	events := files.Subscribe(ctx)
+	pending := make(map[string]struct{})
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Payload.Path == "" {
+				continue
+			}
+			pending[event.Payload.Path] = struct{}{}
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			for path := range pending {
+				if err := ix.UpdateFile(ctx, path); err != nil {
+					logging.Warn("Failed to re-index file", "path", path, "error", err)
+				}
+			}
+			clear(pending)
+		}
+	}
+}
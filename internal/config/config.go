@@ -2,21 +2,26 @@
 package config
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/opencode-ai/opencode/internal/bridge"
 	"github.com/opencode-ai/opencode/internal/hooks"
 	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // MCPType defines the type of MCP (Model Control Protocol) server.
@@ -27,6 +32,12 @@ const (
 	MCPStdio MCPType = "stdio"
 	MCPSse   MCPType = "sse"
 	MCPHttp  MCPType = "http"
+	// MCPStreamableHttp uses the streamable HTTP transport like MCPHttp, but
+	// keeps one session open for the lifetime of the process, resuming it
+	// after dropped connections.
+	MCPStreamableHttp MCPType = "streamable-http"
+	// MCPWebSocket keeps a persistent WebSocket connection to the server.
+	MCPWebSocket MCPType = "websocket"
 )
 
 // MCPServer defines the configuration for a Model Control Protocol server.
@@ -72,8 +83,29 @@ const (
 	AgentDescriptor AgentName = "descriptor"
 	AgentWorkhorse  AgentName = "workhorse"
 	AgentHivemind   AgentName = "hivemind"
+	AgentTranslator AgentName = "translator"
 )
 
+// ReasoningEffortAuto lets the agent pick the reasoning effort for each turn
+// from the user's prompt instead of using a fixed level.
+const ReasoningEffortAuto = "auto"
+
+// MinThinkingBudgetTokens is the smallest thinking budget Anthropic accepts.
+const MinThinkingBudgetTokens = 1024
+
+// ReasoningEffortForBudget maps a thinking budget to the reasoning effort
+// of providers that take a level instead of a token count.
+func ReasoningEffortForBudget(budget int64) string {
+	switch {
+	case budget <= 4096:
+		return "low"
+	case budget <= 16384:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
 // AgentOutput defines structured output configuration for an agent.
 type AgentOutput struct {
 	Schema map[string]any `json:"schema,omitempty"`
@@ -84,7 +116,7 @@ type Agent struct {
 	Model           models.ModelID  `json:"model"`
 	MaxTokens       int64           `json:"maxTokens"`
 	MaxTurns        int             `json:"maxTurns,omitempty"`
-	ReasoningEffort string          `json:"reasoningEffort"`      // For openai models low,medium,high
+	ReasoningEffort string          `json:"reasoningEffort"`      // low, medium, high, or auto to pick per turn
 	Permission      map[string]any  `json:"permission,omitempty"` // tool name -> "allow" | {"pattern": "action"}
 	Tools           map[string]bool `json:"tools,omitempty"`      // e.g., {"skill": false}
 	Mode            AgentMode       `json:"mode,omitempty"`       // "agent" or "subagent"
@@ -95,10 +127,53 @@ type Agent struct {
 	Color           string          `json:"color,omitempty"`
 	Hidden          bool            `json:"hidden,omitempty"`
 	Disabled        bool            `json:"disabled,omitempty"`
+	DryRun          bool            `json:"dryRun,omitempty"`
 	ParallelToolUse *bool           `json:"parallelToolUse,omitempty"`
-	Output          *AgentOutput    `json:"output,omitempty"`
-	Skills          []string        `json:"skills,omitempty"`
-	TaskBudget      int64           `json:"taskBudget,omitempty"`
+	// MaxParallelTasks caps how many task tool calls of one turn run
+	// their subagents at the same time.
+	MaxParallelTasks int          `json:"maxParallelTasks,omitempty"`
+	Output           *AgentOutput `json:"output,omitempty"`
+	Skills           []string     `json:"skills,omitempty"`
+	TaskBudget       int64        `json:"taskBudget,omitempty"`
+	// ThinkingBudgetTokens is how many tokens the model may spend
+	// reasoning before it answers: Anthropic's extended thinking budget,
+	// Gemini's thinking budget, and the reasoning effort it maps to for
+	// OpenAI and adaptive thinking models when reasoningEffort is unset.
+	ThinkingBudgetTokens int64 `json:"thinkingBudgetTokens,omitempty"`
+	// Budget stops the agent once the session it runs in has spent more
+	// than the limits allow.
+	Budget *BudgetLimits `json:"budget,omitempty"`
+	// ResponseCache reuses the answer of an earlier task tool call to
+	// this subagent with the same prompt on the same workspace state.
+	ResponseCache *ResponseCacheConfig `json:"responseCache,omitempty"`
+	// FallbackModels are tried in order when Model's provider cannot be
+	// created, or fails with a quota or overload error mid-session.
+	FallbackModels []models.ModelID `json:"fallbackModels,omitempty"`
+	// ContextPaths replaces the global contextPaths for this agent.
+	ContextPaths []ContextPath `json:"contextPaths,omitempty"`
+	// Context is inline text added to the agent's project context after
+	// its context files.
+	Context []string `json:"context,omitempty"`
+	// OutputLimits caps how much one call of bash, read, ls and the other
+	// output-heavy tools returns to this agent.
+	OutputLimits *OutputLimits `json:"outputLimits,omitempty"`
+}
+
+// OutputLimits caps tool output per call. Zero fields keep the built-in
+// limits.
+type OutputLimits struct {
+	MaxOutputBytes int `json:"maxOutputBytes,omitempty"`
+	MaxOutputLines int `json:"maxOutputLines,omitempty"`
+	MaxReadBytes   int `json:"maxReadBytes,omitempty"`
+	ReadLines      int `json:"readLines,omitempty"`
+	MaxLineLength  int `json:"maxLineLength,omitempty"`
+	MaxListFiles   int `json:"maxListFiles,omitempty"`
+}
+
+// ResponseCacheConfig enables the subagent response cache.
+type ResponseCacheConfig struct {
+	// TTL is how long a cached response stays valid, e.g. "30m" or "1d".
+	TTL string `json:"ttl"`
 }
 
 // LangfuseConfig defines configuration for Langfuse observability integration.
@@ -147,6 +222,43 @@ type Provider struct {
 	BaseURL  string            `json:"baseURL"`
 	Headers  map[string]string `json:"headers,omitempty"`
 	Metadata *ProviderMetadata `json:"metadata,omitempty"`
+	Retry    *RetryConfig      `json:"retry,omitempty"`
+	// KeepAlive and NumCtx only apply to the ollama provider.
+	KeepAlive string `json:"keepAlive,omitempty"`
+	NumCtx    int64  `json:"numCtx,omitempty"`
+	// Batch sends the requests of offline runs (flow steps, headless
+	// prompts, queued runs) through the provider's batch API. Only
+	// anthropic and openai support it.
+	Batch *BatchConfig `json:"batch,omitempty"`
+}
+
+// BatchConfig tunes batch API mode. Durations accept a Go duration or a
+// number of days, e.g. "30s" or "1d".
+type BatchConfig struct {
+	Enabled bool `json:"enabled"`
+	// Window is how long requests are collected before they are
+	// submitted together (default 2s).
+	Window string `json:"window,omitempty"`
+	// PollInterval is how often a submitted batch is checked (default 30s).
+	PollInterval string `json:"pollInterval,omitempty"`
+	// Timeout fails the requests of a batch that hasn't ended by then
+	// (default 24h).
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// RetryConfig tunes how a provider's client retries failed requests.
+// Unset fields keep the client's defaults.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	// (default 8); -1 disables retrying.
+	MaxRetries  int `json:"maxRetries,omitempty"`
+	BaseDelayMs int `json:"baseDelayMs,omitempty"`
+	MaxDelayMs  int `json:"maxDelayMs,omitempty"`
+	// RetryOnStatus replaces the HTTP status codes that are retried.
+	RetryOnStatus []int `json:"retryOnStatus,omitempty"`
+	// RespectRetryAfter waits as long as the provider's Retry-After
+	// header asks (default true).
+	RespectRetryAfter *bool `json:"respectRetryAfter,omitempty"`
 }
 
 // Data defines storage configuration.
@@ -166,14 +278,48 @@ type LSPConfig struct {
 
 // TUIConfig defines the configuration for the Terminal User Interface.
 type TUIConfig struct {
-	Theme   string `json:"theme,omitempty"`
-	VimMode bool   `json:"vimMode,omitempty"`
+	Theme      string `json:"theme,omitempty"`
+	VimMode    bool   `json:"vimMode,omitempty"`
+	UsagePanel bool   `json:"usagePanel,omitempty"`
 }
 
 // ShellConfig defines the configuration for the shell used by the bash tool.
 type ShellConfig struct {
 	Path string   `json:"path,omitempty"`
 	Args []string `json:"args,omitempty"`
+	// Backend is where commands run: ShellBackendHost (default) or
+	// ShellBackendContainer.
+	Backend   string           `json:"backend,omitempty"`
+	Container *ContainerConfig `json:"container,omitempty"`
+}
+
+// Shell backends.
+const (
+	ShellBackendHost      = "host"
+	ShellBackendContainer = "container"
+)
+
+// ContainerConfig runs the bash tool's commands in a Docker or Podman
+// container instead of the host shell. The working directory is mounted
+// at the same path, so paths in commands and tool results agree.
+type ContainerConfig struct {
+	// Runtime is the container CLI: "docker" (default) or "podman".
+	Runtime string `json:"runtime,omitempty"`
+	// Image the shell runs in. Required.
+	Image string `json:"image"`
+	// Network is passed to --network: "none" (default) cuts the container
+	// off, "bridge" or a network name gives it access.
+	Network string `json:"network,omitempty"`
+	// Mounts are extra volumes in "host:container[:ro]" form. Relative
+	// host paths resolve against the working directory.
+	Mounts []string `json:"mounts,omitempty"`
+	// Env lists variables to set in the container, as NAME=value or as
+	// NAME to pass the host's value through.
+	Env []string `json:"env,omitempty"`
+	// ReadOnly mounts the working directory read-only.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Args are extra arguments for `<runtime> run`, e.g. resource limits.
+	Args []string `json:"args,omitempty"`
 }
 
 // ProviderType defines the type of session storage provider.
@@ -215,6 +361,253 @@ type SessionCleanupConfig struct {
 	MaxAge string `json:"maxAge,omitempty"`
 }
 
+// DefaultModelCheckInterval is how often configured models are checked
+// for availability unless modelCheck.interval says otherwise.
+const DefaultModelCheckInterval = 12 * time.Hour
+
+// ModelCheckConfig controls the background check that the configured
+// providers' models are available to the account.
+type ModelCheckConfig struct {
+	// Disabled turns the background check off. `opencode models check`
+	// still works.
+	Disabled bool `json:"disabled,omitempty"`
+	// Interval between checks. Supports Go duration strings plus "d" for
+	// days, e.g. "6h" or "1d". Defaults to "12h".
+	Interval string `json:"interval,omitempty"`
+}
+
+// CodeSearchConfig enables the codesearch tool. The workspace is split into
+// chunks that are embedded by Provider and stored in the database, and
+// re-indexed as files change. See docs/codesearch.md.
+type CodeSearchConfig struct {
+	// Provider serves the embeddings: openai, gemini, ollama or local.
+	Provider models.ModelProvider `json:"provider"`
+	// Model is the embedding model. Every provider except local has a
+	// default.
+	Model string `json:"model,omitempty"`
+	// BaseURL overrides the provider's embeddings endpoint.
+	BaseURL string `json:"baseURL,omitempty"`
+	// Exclude lists doublestar patterns, relative to the working
+	// directory, of files that are not indexed.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Actions of DiffBudgetConfig.
+const (
+	DiffBudgetAsk  = "ask"
+	DiffBudgetDeny = "deny"
+)
+
+// DiffBudgetConfig caps how much the write tools (edit, multiedit, write,
+// patch, delete, notebook_edit) may change in a single turn, including
+// changes made by the turn's subagents. A change that would go over
+// either limit is held for confirmation even in auto-approve sessions, or
+// refused.
+type DiffBudgetConfig struct {
+	// MaxFiles is the number of distinct files one turn may change. 0
+	// means no limit.
+	MaxFiles int `json:"maxFiles,omitempty"`
+	// MaxLines is the number of lines (added plus removed) one turn may
+	// change. 0 means no limit.
+	MaxLines int `json:"maxLines,omitempty"`
+	// Action is "ask" (default) to ask the user before going over the
+	// budget, or "deny" to refuse. Runs without a user to ask always
+	// refuse.
+	Action string `json:"action,omitempty"`
+}
+
+// Retry strategies of ContentFilterConfig.
+const (
+	ContentFilterRetryNone     = "none"
+	ContentFilterRetryRephrase = "rephrase"
+	ContentFilterRetryFallback = "fallback"
+)
+
+// ContentFilterConfig sets what the agent does when the provider's content
+// filter blocks a response. The blocked response is always marked as such
+// and recorded in the audit trail.
+type ContentFilterConfig struct {
+	// Retry is "none" (default) to end the turn on the blocked response,
+	// "rephrase" to ask the model again with a note that its answer was
+	// blocked, or "fallback" to repeat the request on the agent's next
+	// fallback model.
+	Retry string `json:"retry,omitempty"`
+	// MaxRetries caps the retries in one turn. 0 means 1.
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+// TranslationConfig enables post-processing of final assistant responses
+// through the translator agent. Code blocks and inline code are never sent
+// for translation.
+type TranslationConfig struct {
+	// Language is the target language, e.g. "German" or "pt-BR". Empty
+	// disables the feature entirely.
+	Language string `json:"language,omitempty"`
+	// Enabled is the initial state for new sessions; it can be toggled
+	// per session at runtime.
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// RouteClass is a kind of user request the router tells apart.
+type RouteClass string
+
+const (
+	RouteQuickQuestion RouteClass = "quick_question"
+	RouteCodeEdit      RouteClass = "code_edit"
+	RouteLargeRefactor RouteClass = "large_refactor"
+	RouteSummarization RouteClass = "summarization"
+)
+
+// RouteClasses lists every RouteClass.
+var RouteClasses = []RouteClass{RouteQuickQuestion, RouteCodeEdit, RouteLargeRefactor, RouteSummarization}
+
+// RoutingConfig sends each user request of the routed agents to the model
+// configured for its class instead of the agent's own model.
+type RoutingConfig struct {
+	// Agents lists the routed agents. Defaults to the coder agent.
+	Agents []AgentName `json:"agents,omitempty"`
+	// Rules maps a request class to its model. Classes without a rule keep
+	// the agent's model.
+	Rules map[RouteClass]models.ModelID `json:"rules,omitempty"`
+}
+
+// RoutesAgent reports whether requests of agent are routed.
+func (r *RoutingConfig) RoutesAgent(agent AgentName) bool {
+	if r == nil || len(r.Rules) == 0 {
+		return false
+	}
+	if len(r.Agents) == 0 {
+		return agent == AgentCoder
+	}
+	return slices.Contains(r.Agents, agent)
+}
+
+// BudgetLimits caps what a scope may spend. Zero fields are unlimited.
+type BudgetLimits struct {
+	MaxCostUSD float64 `json:"maxCostUSD,omitempty"`
+	// MaxTokens counts prompt and completion tokens together.
+	MaxTokens int64 `json:"maxTokens,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (b *BudgetLimits) IsZero() bool {
+	return b == nil || (b.MaxCostUSD <= 0 && b.MaxTokens <= 0)
+}
+
+// BudgetConfig holds the hard spending limits enforced by the agent loop.
+// Per-agent limits live on Agent.Budget.
+type BudgetConfig struct {
+	// Session applies to each session tree: the root session plus its
+	// task, title and flow step sub-sessions.
+	Session *BudgetLimits `json:"session,omitempty"`
+	// Global applies to all sessions of the project combined.
+	Global *BudgetLimits `json:"global,omitempty"`
+}
+
+// AuditConfig enables the hash-chained compliance audit trail of tool
+// calls, permission decisions and provider requests. See docs/audit.md.
+type AuditConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Path of the JSONL trail. Relative paths resolve against the data
+	// directory; the default is audit.jsonl.
+	Path string `json:"path,omitempty"`
+	// SigningKey is a PEM file holding an Ed25519 private key (PKCS#8)
+	// that signs every entry.
+	SigningKey string `json:"signingKey,omitempty"`
+}
+
+// SandboxConfig confines the tools that change files (edit, multiedit,
+// write, patch, delete, notebook_edit) and the bash working directory to
+// AllowedPaths, whatever the user answers to a permission prompt.
+type SandboxConfig struct {
+	// AllowedPaths are the roots tools may change files in. "~" expands to
+	// the home directory; relative paths resolve against the working
+	// directory. Empty disables the sandbox.
+	AllowedPaths []string `json:"allowedPaths,omitempty"`
+	// Exceptions are permission-style globs (e.g. "/tmp/*") for paths
+	// outside AllowedPaths that tools may still change.
+	Exceptions []string `json:"exceptions,omitempty"`
+}
+
+// ModerationConfig screens assistant responses before their tool calls
+// run. Rules are checked locally; Endpoint, when set, is asked as well.
+// See docs/moderation.md.
+type ModerationConfig struct {
+	Rules []ModerationRule `json:"rules,omitempty"`
+	// Endpoint receives a JSON POST of the response and answers
+	// {"flagged": bool, "reason": string}.
+	Endpoint  string            `json:"endpoint,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	TimeoutMs int               `json:"timeoutMs,omitempty"`
+	// FailClosed blocks the tool calls when the endpoint cannot be
+	// reached; by default they run and a warning is logged.
+	FailClosed bool `json:"failClosed,omitempty"`
+	// NoOverride blocks flagged calls outright instead of asking the user
+	// whether to run them anyway.
+	NoOverride bool `json:"noOverride,omitempty"`
+}
+
+// ModerationRule flags a response when Pattern (an RE2 regexp) matches its
+// text or the input of one of its tool calls.
+type ModerationRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// Tools limits the rule to these tools' inputs (wildcards allowed);
+	// the response text is then not checked.
+	Tools  []string `json:"tools,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// WebhooksConfig configures the /webhook/{github,gitlab} receiver of
+// `opencode serve`.
+type WebhooksConfig struct {
+	Repos []WebhookRepo `json:"repos,omitempty"`
+}
+
+// WebhookRepo binds one repository to the flows its events trigger.
+type WebhookRepo struct {
+	// Name is the GitHub full_name or GitLab path_with_namespace
+	// ("owner/repo", "group/subgroup/project").
+	Name string `json:"name"`
+	// Provider is "github" or "gitlab".
+	Provider string `json:"provider"`
+	// Secret verifies deliveries: the HMAC key of GitHub's
+	// X-Hub-Signature-256, or the GitLab X-Gitlab-Token value.
+	// ${VAR} references are expanded from the environment.
+	Secret string `json:"secret"`
+	// Label is the issue label that triggers IssueFlow. Defaults to
+	// "opencode".
+	Label string `json:"label,omitempty"`
+	// Command is the comment prefix that triggers CommentFlow. Defaults
+	// to "/opencode".
+	Command string `json:"command,omitempty"`
+	// IssueFlow runs when Label is added to an issue.
+	IssueFlow string `json:"issueFlow,omitempty"`
+	// CommentFlow runs for Command comments on issues and pull/merge
+	// requests. Empty falls back to IssueFlow.
+	CommentFlow string `json:"commentFlow,omitempty"`
+	// Args are merged under the event-derived args of every run.
+	Args map[string]any `json:"args,omitempty"`
+	// AllowedAuthors lists the usernames allowed to trigger runs. When
+	// empty, GitHub comments need an OWNER, MEMBER or COLLABORATOR
+	// author association and GitLab comments are ignored, since GitLab
+	// payloads don't carry the commenter's access level. Label triggers
+	// are already limited by the hosting service to users who can triage.
+	AllowedAuthors []string `json:"allowedAuthors,omitempty"`
+}
+
+// RunQueueConfig bounds how many queued headless runs `opencode serve`
+// executes at once. Zero or missing limits fall back to the defaults.
+type RunQueueConfig struct {
+	// Concurrency caps running runs across every provider and project.
+	// Defaults to 1.
+	Concurrency int `json:"concurrency,omitempty"`
+	// Providers caps running runs per LLM provider ("anthropic": 2).
+	Providers map[string]int `json:"providers,omitempty"`
+	// Projects caps running runs per project ID.
+	Projects map[string]int `json:"projects,omitempty"`
+}
+
 // SessionCleanupMaxAge returns the configured max age duration, or the default.
 func (c *Config) SessionCleanupMaxAge() time.Duration {
 	if c.SessionCleanup == nil || c.SessionCleanup.MaxAge == "" {
@@ -228,6 +621,36 @@ func (c *Config) SessionCleanupMaxAge() time.Duration {
 	return d
 }
 
+// ModelCheckInterval returns how often models are checked for
+// availability in the background, or 0 when the check is disabled.
+func (c *Config) ModelCheckInterval() time.Duration {
+	if c.ModelCheck != nil && c.ModelCheck.Disabled {
+		return 0
+	}
+	if c.ModelCheck == nil || c.ModelCheck.Interval == "" {
+		return DefaultModelCheckInterval
+	}
+	d, err := ParseDurationExtended(c.ModelCheck.Interval)
+	if err != nil {
+		logging.Warn("invalid modelCheck.interval, using default", "value", c.ModelCheck.Interval, "error", err, "default", DefaultModelCheckInterval)
+		return DefaultModelCheckInterval
+	}
+	return d
+}
+
+// SyncCredentials returns the credentials of the enabled providers whose
+// model-listing endpoint can be queried.
+func (c *Config) SyncCredentials() map[models.ModelProvider]models.SyncCredentials {
+	creds := map[models.ModelProvider]models.SyncCredentials{}
+	for provider, p := range c.Providers {
+		if p.Disabled || p.APIKey == "" || !models.CanSync(provider) {
+			continue
+		}
+		creds[provider] = models.SyncCredentials{APIKey: p.APIKey, BaseURL: p.BaseURL, Headers: p.Headers}
+	}
+	return creds
+}
+
 // ParseDurationExtended extends time.ParseDuration with support for "d" (days) and "y" (years).
 // Negative or zero durations are rejected.
 func ParseDurationExtended(s string) (time.Duration, error) {
@@ -269,7 +692,8 @@ type SkillsConfig struct {
 
 // WebSearchProvider defines configuration for a single web search provider.
 type WebSearchProvider struct {
-	BaseURL     string `json:"baseUrl"`               // Full URL to POST search queries to (required)
+	Type        string `json:"type,omitempty"`        // "generic" (default), "tavily", "brave" or "searxng"
+	BaseURL     string `json:"baseUrl,omitempty"`     // Search endpoint; required for generic and searxng, defaults for tavily and brave
 	APIKey      string `json:"apiKey,omitempty"`      // Per-provider API key or "env:VAR_NAME"; falls back to LOCAL_ENDPOINT_API_KEY
 	Description string `json:"description,omitempty"` // Human-readable description shown to LLM; defaults generated in code
 }
@@ -283,21 +707,40 @@ type WebSearchConfig struct {
 // Each tool key maps to either a simple string ("allow"/"deny"/"ask")
 // or an object with glob pattern keys (e.g., {"*": "ask", "git *": "allow"}).
 type PermissionConfig struct {
-	Skill map[string]string `json:"skill,omitempty"` // Deprecated: use Rules instead
-	Rules map[string]any    `json:"rules,omitempty"` // tool name -> "allow" | {"pattern": "action"}
+	Skill  map[string]string       `json:"skill,omitempty"` // Deprecated: use Rules instead
+	Rules  map[string]any          `json:"rules,omitempty"` // tool name -> "allow" | {"pattern": "action"}
+	Review *PermissionReviewConfig `json:"review,omitempty"`
+	// AllowSecrets opts files matching the built-in secret patterns (.env,
+	// private keys, cloud credentials) back in for tools. Entries are paths
+	// or patterns; "*" turns the guard off.
+	AllowSecrets []string `json:"allowSecrets,omitempty"`
+}
+
+// PermissionReviewConfig routes "ask"-resolved permission requests to a
+// reviewer agent that approves, denies or escalates them to the user.
+type PermissionReviewConfig struct {
+	// Agent is the ID of the reviewer agent; its prompt carries the policy.
+	Agent AgentName `json:"agent"`
+	// Tools limits review to these tool names (wildcards allowed). Empty
+	// means every tool that would otherwise ask.
+	Tools []string `json:"tools,omitempty"`
 }
 
 // Config is the main configuration structure for the application.
 type Config struct {
-	Data         Data                              `json:"data"`
-	WorkingDir   string                            `json:"wd,omitempty"`
-	MCPServers   map[string]MCPServer              `json:"mcpServers,omitempty"`
-	Providers    map[models.ModelProvider]Provider `json:"providers,omitempty"`
-	LSP          map[string]LSPConfig              `json:"lsp,omitempty"`
-	Agents       map[AgentName]Agent               `json:"agents,omitempty"`
-	Debug        bool                              `json:"debug,omitempty"`
-	DebugLSP     bool                              `json:"debugLSP,omitempty"`
-	ContextPaths []string                          `json:"contextPaths,omitempty"`
+	Data       Data                              `json:"data"`
+	WorkingDir string                            `json:"wd,omitempty"`
+	MCPServers map[string]MCPServer              `json:"mcpServers,omitempty"`
+	Providers  map[models.ModelProvider]Provider `json:"providers,omitempty"`
+	LSP        map[string]LSPConfig              `json:"lsp,omitempty"`
+	Agents     map[AgentName]Agent               `json:"agents,omitempty"`
+	// Profiles are named presets of providers, agents and permissions;
+	// Profile names the one in force. See profile.go.
+	Profiles     map[string]Profile `json:"profiles,omitempty"`
+	Profile      string             `json:"profile,omitempty"`
+	Debug        bool               `json:"debug,omitempty"`
+	DebugLSP     bool               `json:"debugLSP,omitempty"`
+	ContextPaths []ContextPath      `json:"contextPaths,omitempty"`
 	// AgentPaths lists custom directories to scan for markdown agent
 	// definitions (*.md) at startup, mirroring Skills.Paths. Supports "~"
 	// for the home directory and relative paths (resolved against the
@@ -316,7 +759,11 @@ type Config struct {
 	TUI                TUIConfig             `json:"tui"`
 	Shell              ShellConfig           `json:"shell,omitempty"`
 	AutoCompact        bool                  `json:"autoCompact,omitempty"`
+	AutoSnapshot       bool                  `json:"autoSnapshot,omitempty"`
+	DryRun             bool                  `json:"dryRun,omitempty"`
+	ReadOnly           bool                  `json:"readOnly,omitempty"`
 	DisableLSPDownload bool                  `json:"disableLSPDownload,omitempty"`
+	DisableAutoTitle   bool                  `json:"disableAutoTitle,omitempty"`
 	SessionProvider    SessionProviderConfig `json:"sessionProvider,omitempty"`
 	Skills             *SkillsConfig         `json:"skills,omitempty"`
 	Permission         *PermissionConfig     `json:"permission,omitempty"`
@@ -325,6 +772,26 @@ type Config struct {
 	Telemetry          *TelemetryConfig      `json:"telemetry,omitempty"`
 	SessionCleanup     *SessionCleanupConfig `json:"sessionCleanup,omitempty"`
 	Router             *bridge.Config        `json:"router,omitempty"`
+	Translation        *TranslationConfig    `json:"translation,omitempty"`
+	Budget             *BudgetConfig         `json:"budget,omitempty"`
+	Audit              *AuditConfig          `json:"audit,omitempty"`
+	Moderation         *ModerationConfig     `json:"moderation,omitempty"`
+	Sandbox            *SandboxConfig        `json:"sandbox,omitempty"`
+	Routing            *RoutingConfig        `json:"routing,omitempty"`
+	ModelCheck         *ModelCheckConfig     `json:"modelCheck,omitempty"`
+	CodeSearch         *CodeSearchConfig     `json:"codeSearch,omitempty"`
+	DiffBudget         *DiffBudgetConfig     `json:"diffBudget,omitempty"`
+	ContentFilter      *ContentFilterConfig  `json:"contentFilter,omitempty"`
+	// Webhooks maps GitHub / GitLab events to flow runs in server mode.
+	// See docs/webhooks.md.
+	Webhooks *WebhooksConfig `json:"webhooks,omitempty"`
+	// RunQueue limits the concurrency of the persistent run queue.
+	// See docs/run-queue.md.
+	RunQueue *RunQueueConfig `json:"runQueue,omitempty"`
+	// Snippets maps snippet names to the prompt text the editor expands
+	// `!name` to. Markdown files in .opencode/snippets add more. See
+	// internal/snippet.
+	Snippets map[string]string `json:"snippets,omitempty"`
 	// Hooks is the Claude-Code-compatible PreToolUse / PostToolUse
 	// subprocess hook map. Keys are event names (`PreToolUse`,
 	// `PostToolUse`); values are matcher groups whose entries fire as
@@ -334,6 +801,9 @@ type Config struct {
 	// can copy-paste between hosts. See docs/hooks.md and
 	// openspec/specs/hook-runtime/spec.md.
 	Hooks map[string][]hooks.MatcherGroup `json:"hooks,omitempty"`
+	// Policy is the organisation policy in force, nil when none is
+	// installed. It never comes from a config file. See docs/policy.md.
+	Policy *Policy `json:"-"`
 }
 
 // Application constants
@@ -345,6 +815,69 @@ const (
 	MaxTokensFallbackDefault = 4096
 )
 
+// DefaultContextFileMaxTokens caps each context file unless its
+// contextPaths entry sets maxTokens.
+const DefaultContextFileMaxTokens = 8000
+
+// ContextPath is one contextPaths entry: a file, or a directory when Path
+// ends in "/". In config it is either a bare path string or an object
+// carrying options.
+type ContextPath struct {
+	Path string `json:"path" yaml:"path"`
+	// MaxTokens limits every file matched by the entry, estimated at four
+	// bytes per token. Larger files are truncated, keeping headings and
+	// first paragraphs. 0 means DefaultContextFileMaxTokens, -1 no limit.
+	MaxTokens int `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`
+}
+
+// TokenLimit resolves MaxTokens, returning 0 when the entry is unlimited.
+func (c ContextPath) TokenLimit() int {
+	switch {
+	case c.MaxTokens < 0:
+		return 0
+	case c.MaxTokens == 0:
+		return DefaultContextFileMaxTokens
+	default:
+		return c.MaxTokens
+	}
+}
+
+func (c *ContextPath) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*c = ContextPath{Path: path}
+		return nil
+	}
+	type plain ContextPath
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// UnmarshalYAML accepts the same two forms in agent markdown frontmatter.
+func (c *ContextPath) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = ContextPath{Path: node.Value}
+		return nil
+	}
+	type plain ContextPath
+	return node.Decode((*plain)(c))
+}
+
+// contextPathHook lets viper decode bare strings in contextPaths.
+func contextPathHook(from, to reflect.Type, data any) (any, error) {
+	if to == reflect.TypeOf(ContextPath{}) && from.Kind() == reflect.String {
+		return ContextPath{Path: data.(string)}, nil
+	}
+	return data, nil
+}
+
+// decodeHooks extends viper's default decode hooks, which passing any hook
+// replaces, with contextPathHook.
+var decodeHooks = viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+	contextPathHook,
+))
+
 var defaultContextPaths = []string{
 	".github/copilot-instructions.md",
 	".cursorrules",
@@ -366,13 +899,21 @@ type Configurator interface {
 	WorkingDirectory() string
 }
 
-// Global configuration instance
+// cfg is the configuration load is building, or last built. Only the
+// loading code reads it; everyone else goes through Get, which returns the
+// published configuration.
 var cfg *Config
 
+// published is the configuration Get returns. SwitchProfile replaces it
+// with a freshly loaded one instead of rewriting the value readers hold.
+var published atomic.Pointer[Config]
+
 // Reset clears the global configuration, allowing Load to be called again.
 // This is intended for use in tests only.
 func Reset() {
 	cfg = nil
+	published.Store(nil)
+	profileOverride = nil
 }
 
 // Load initializes the configuration from environment variables and config files.
@@ -382,7 +923,14 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	if cfg != nil {
 		return cfg, nil
 	}
+	c, err := load(workingDir, debug, loadPolicy)
+	published.Store(c)
+	return c, err
+}
 
+// load builds cfg from the environment and config files, taking the
+// organisation policy from policy.
+func load(workingDir string, debug bool, policy func() (*Policy, error)) (*Config, error) {
 	cfg = &Config{
 		WorkingDir: workingDir,
 		MCPServers: make(map[string]MCPServer),
@@ -393,25 +941,48 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	configureViper()
 	setDefaults(debug)
 
-	// Read global config
-	if err := readConfig(viper.ReadInConfig()); err != nil {
+	// The organisation policy is fetched before any config file is read:
+	// its defaults sit beneath them and its locked values over them.
+	orgPolicy, err := policy()
+	if err != nil {
 		return cfg, err
 	}
+	applyPolicyDefaults(orgPolicy)
+
+	// Read global config, the first .opencode.{json,yaml,yml,toml} found
+	// in $HOME, $XDG_CONFIG_HOME/opencode and ~/.config/opencode.
+	if path := findConfigFile(globalConfigDirs()...); path != "" {
+		viper.SetConfigFile(path)
+		viper.SetConfigType(configFormat(path))
+		if err := readConfig(viper.ReadInConfig()); err != nil {
+			return cfg, err
+		}
+	}
 
 	// Load and merge local config
 	mergeLocalConfig(workingDir)
+	profile, err := applyProfile()
+	if err != nil {
+		return cfg, err
+	}
 
 	setProviderDefaults()
+	applyPolicyLocks(orgPolicy)
 
 	// Apply configuration to the struct
-	if err := viper.Unmarshal(cfg); err != nil {
+	if err := viper.Unmarshal(cfg, decodeHooks); err != nil {
 		return cfg, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := resolveSecrets(cfg); err != nil {
+		return cfg, fmt.Errorf("failed to resolve config secrets: %w", err)
+	}
 
 	// Viper uses "." as a key delimiter, which mangles map keys containing
 	// dots (e.g., "~/.openai/*" becomes nested {"~/": {"openai/*": ...}}).
 	// Re-flatten any nested maps in permission configs back to dot-joined keys.
 	fixPermissionKeys(cfg)
+	enforcePolicy(cfg, orgPolicy)
+	cfg.Profile = profile
 
 	applyDefaultValues()
 	defaultLevel := slog.LevelInfo
@@ -529,11 +1100,6 @@ func flattenPatternMap(patterns map[string]any) map[string]any {
 
 // configureViper sets up viper's configuration paths and environment variables.
 func configureViper() {
-	viper.SetConfigName(fmt.Sprintf(".%s", appName))
-	viper.SetConfigType("json")
-	viper.AddConfigPath("$HOME")
-	viper.AddConfigPath(fmt.Sprintf("$XDG_CONFIG_HOME/%s", appName))
-	viper.AddConfigPath(fmt.Sprintf("$HOME/.config/%s", appName))
 	viper.SetEnvPrefix(strings.ToUpper(appName))
 	viper.AutomaticEnv()
 }
@@ -776,10 +1342,13 @@ func readConfig(err error) error {
 
 // mergeLocalConfig loads and merges configuration from the local directory.
 func mergeLocalConfig(workingDir string) {
+	path := findConfigFile(workingDir)
+	if path == "" {
+		return
+	}
 	local := viper.New()
-	local.SetConfigName(fmt.Sprintf(".%s", appName))
-	local.SetConfigType("json")
-	local.AddConfigPath(workingDir)
+	local.SetConfigFile(path)
+	local.SetConfigType(configFormat(path))
 
 	// Merge local config if it exists
 	if err := local.ReadInConfig(); err == nil {
@@ -796,10 +1365,27 @@ func applyDefaultValues() {
 			cfg.MCPServers[k] = v
 		}
 	}
+	// A configured num_ctx is the window Ollama actually serves, so token
+	// budgets and compaction must use it instead of the discovered one.
+	if p, ok := cfg.Providers[models.ProviderOllama]; ok && p.NumCtx > 0 {
+		for id, m := range models.SupportedModels {
+			if m.Provider == models.ProviderOllama {
+				m.ContextWindow = p.NumCtx
+				models.SupportedModels[id] = m
+			}
+		}
+	}
 }
 
 // It validates model IDs and providers, ensuring they are supported.
 func validateAgent(cfg *Config, name AgentName, agent Agent) error {
+	if l := agent.OutputLimits; l != nil {
+		if l.MaxOutputBytes < 0 || l.MaxOutputLines < 0 || l.MaxReadBytes < 0 ||
+			l.ReadLines < 0 || l.MaxLineLength < 0 || l.MaxListFiles < 0 {
+			return fmt.Errorf("agent %s: outputLimits must not be negative", name)
+		}
+	}
+
 	// Check if model exists
 	model, modelExists := models.SupportedModels[agent.Model]
 	if !modelExists {
@@ -857,6 +1443,12 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 		}
 	}
 
+	// The fallbacks above pick models from environment keys, so the ban is
+	// checked on the model the agent ended up with.
+	if model, ok := models.SupportedModels[cfg.Agents[name].Model]; ok && cfg.Policy.BansProvider(model.Provider) {
+		return fmt.Errorf("agent %s: provider %s is banned by the organisation policy", name, model.Provider)
+	}
+
 	// Validate maxTurns
 	if agent.MaxTurns < 0 {
 		logging.Warn("invalid maxTurns, resetting to default",
@@ -867,12 +1459,15 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 		cfg.Agents[name] = updatedAgent
 	}
 
-	// Validate max tokens
+	// Validate max tokens. Zero means unset and quietly takes the default;
+	// a warning there would bury real problems, e.g. in `config doctor`.
 	if agent.MaxTokens <= 0 {
-		logging.Warn("invalid max tokens, setting to default",
-			"agent", name,
-			"model", agent.Model,
-			"max_tokens", agent.MaxTokens)
+		if agent.MaxTokens < 0 {
+			logging.Warn("invalid max tokens, setting to default",
+				"agent", name,
+				"model", agent.Model,
+				"max_tokens", agent.MaxTokens)
+		}
 
 		// Update the agent with default max tokens
 		updatedAgent := cfg.Agents[name]
@@ -897,7 +1492,7 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 	}
 
 	// Validate reasoning effort for models that support reasoning
-	if model.CanReason && provider == models.ProviderOpenAI || provider == models.ProviderLocal {
+	if model.CanReason && (provider == models.ProviderOpenAI || provider == models.ProviderCopilot) || provider == models.ProviderLocal {
 		if agent.ReasoningEffort == "" {
 			// Set default reasoning effort for models that support it
 			logging.Info("setting default reasoning effort for model that supports reasoning",
@@ -907,11 +1502,14 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 			// Update the agent with default reasoning effort
 			updatedAgent := cfg.Agents[name]
 			updatedAgent.ReasoningEffort = "medium"
+			if agent.ThinkingBudgetTokens > 0 {
+				updatedAgent.ReasoningEffort = ReasoningEffortForBudget(agent.ThinkingBudgetTokens)
+			}
 			cfg.Agents[name] = updatedAgent
 		} else {
 			// Check if reasoning effort is valid (low, medium, high)
 			effort := strings.ToLower(agent.ReasoningEffort)
-			if effort != "low" && effort != "medium" && effort != "high" {
+			if effort != "low" && effort != "medium" && effort != "high" && effort != ReasoningEffortAuto {
 				logging.Warn("invalid reasoning effort, setting to medium",
 					"agent", name,
 					"model", agent.Model,
@@ -938,10 +1536,23 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 				updatedAgent := cfg.Agents[name]
 				updatedAgent.ReasoningEffort = "max"
 				cfg.Agents[name] = updatedAgent
+			} else if agent.ThinkingBudgetTokens > 0 {
+				// Adaptive thinking takes an effort, not a token count.
+				updatedAgent := cfg.Agents[name]
+				updatedAgent.ReasoningEffort = ReasoningEffortForBudget(agent.ThinkingBudgetTokens)
+				cfg.Agents[name] = updatedAgent
 			}
 		} else {
 			effort := strings.ToLower(agent.ReasoningEffort)
-			if effort == "xhigh" && !model.SupportsXHighThinking {
+			if effort == ReasoningEffortAuto && model.Provider == models.ProviderKimi {
+				logging.Warn("kimi models only support 'max' reasoning effort, ignoring 'auto'",
+					"agent", name,
+					"model", agent.Model)
+
+				updatedAgent := cfg.Agents[name]
+				updatedAgent.ReasoningEffort = "max"
+				cfg.Agents[name] = updatedAgent
+			} else if effort == "xhigh" && !model.SupportsXHighThinking {
 				logging.Warn("model doesn't support 'xhigh' reasoning effort, falling back to 'high'",
 					"agent", name,
 					"model", agent.Model)
@@ -957,7 +1568,7 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 				updatedAgent := cfg.Agents[name]
 				updatedAgent.ReasoningEffort = "high"
 				cfg.Agents[name] = updatedAgent
-			} else if effort != "low" && effort != "medium" && effort != "high" && effort != "xhigh" && effort != "max" {
+			} else if effort != "low" && effort != "medium" && effort != "high" && effort != "xhigh" && effort != "max" && effort != ReasoningEffortAuto {
 				logging.Warn("invalid reasoning effort for adaptive thinking model, setting to high",
 					"agent", name,
 					"model", agent.Model,
@@ -1004,6 +1615,39 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 		}
 	}
 
+	// Validate thinking budget
+	if agent.ThinkingBudgetTokens > 0 {
+		budget := agent.ThinkingBudgetTokens
+		maxTokens := cfg.Agents[name].MaxTokens
+		if !model.CanReason {
+			logging.Warn("model doesn't support reasoning but thinking budget is set, ignoring",
+				"agent", name,
+				"model", agent.Model,
+				"thinking_budget_tokens", budget)
+			budget = 0
+		} else if budget < MinThinkingBudgetTokens {
+			logging.Warn("thinking budget below minimum (1024), adjusting",
+				"agent", name,
+				"model", agent.Model,
+				"thinking_budget_tokens", budget)
+			budget = MinThinkingBudgetTokens
+		}
+		if budget > 0 && maxTokens > 0 && budget >= maxTokens {
+			// The budget is part of the response, which maxTokens caps.
+			logging.Warn("thinking budget must be below max tokens, adjusting",
+				"agent", name,
+				"model", agent.Model,
+				"thinking_budget_tokens", budget,
+				"max_tokens", maxTokens)
+			budget = max(MinThinkingBudgetTokens, maxTokens*4/5)
+		}
+		if budget != agent.ThinkingBudgetTokens {
+			updatedAgent := cfg.Agents[name]
+			updatedAgent.ThinkingBudgetTokens = budget
+			cfg.Agents[name] = updatedAgent
+		}
+	}
+
 	return nil
 }
 
@@ -1035,12 +1679,35 @@ func Validate() error {
 		if err := validateProviderMetadata(provider, providerCfg.Metadata); err != nil {
 			return err
 		}
+		if err := validateBatchConfig(provider, providerCfg.Batch); err != nil {
+			return err
+		}
 	}
 
 	if err := validateTelemetryConfig(cfg.Telemetry); err != nil {
 		return err
 	}
 
+	if err := validateShellConfig(cfg.Shell); err != nil {
+		return err
+	}
+
+	if err := validateCodeSearchConfig(cfg.CodeSearch); err != nil {
+		return err
+	}
+
+	if err := validateDiffBudgetConfig(cfg.DiffBudget); err != nil {
+		return err
+	}
+
+	if err := validateContentFilterConfig(cfg.ContentFilter); err != nil {
+		return err
+	}
+
+	if cfg.Permission != nil && cfg.Permission.Review != nil && cfg.Permission.Review.Agent == "" {
+		return fmt.Errorf("permission.review.agent is required when permission.review is set")
+	}
+
 	// Validate LSP configurations
 	for language, lspConfig := range cfg.LSP {
 		if lspConfig.Command == "" && !lspConfig.Disabled && len(lspConfig.Extensions) == 0 {
@@ -1090,6 +1757,79 @@ func validateSessionProvider() error {
 	return nil
 }
 
+// validateCodeSearchConfig checks that the embedding provider is one the
+// codesearch index can call.
+func validateCodeSearchConfig(cs *CodeSearchConfig) error {
+	if cs == nil {
+		return nil
+	}
+	switch cs.Provider {
+	case models.ProviderOpenAI, models.ProviderGemini, models.ProviderOllama:
+	case models.ProviderLocal:
+		if cs.Model == "" {
+			return fmt.Errorf("codeSearch.model is required for the local provider")
+		}
+	case "":
+		return fmt.Errorf("codeSearch.provider is required")
+	default:
+		return fmt.Errorf("codeSearch.provider %q does not serve embeddings; use openai, gemini, ollama or local", cs.Provider)
+	}
+	return nil
+}
+
+// validateDiffBudgetConfig checks the diff budget's limits and action.
+func validateDiffBudgetConfig(b *DiffBudgetConfig) error {
+	if b == nil {
+		return nil
+	}
+	if b.MaxFiles < 0 || b.MaxLines < 0 {
+		return fmt.Errorf("diffBudget.maxFiles and diffBudget.maxLines must not be negative")
+	}
+	switch b.Action {
+	case "", DiffBudgetAsk, DiffBudgetDeny:
+	default:
+		return fmt.Errorf("diffBudget.action %q is invalid; use %q or %q", b.Action, DiffBudgetAsk, DiffBudgetDeny)
+	}
+	return nil
+}
+
+// validateContentFilterConfig checks the content filter retry strategy.
+func validateContentFilterConfig(c *ContentFilterConfig) error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("contentFilter.maxRetries must not be negative")
+	}
+	switch c.Retry {
+	case "", ContentFilterRetryNone, ContentFilterRetryRephrase, ContentFilterRetryFallback:
+	default:
+		return fmt.Errorf("contentFilter.retry %q is invalid; use %q, %q or %q", c.Retry,
+			ContentFilterRetryNone, ContentFilterRetryRephrase, ContentFilterRetryFallback)
+	}
+	return nil
+}
+
+// validateShellConfig validates the shell backend.
+func validateShellConfig(shell ShellConfig) error {
+	switch shell.Backend {
+	case "", ShellBackendHost:
+		return nil
+	case ShellBackendContainer:
+	default:
+		return fmt.Errorf("shell: invalid backend %q (must be 'host' or 'container')", shell.Backend)
+	}
+	if shell.Container == nil || shell.Container.Image == "" {
+		return fmt.Errorf("shell.container.image is required when shell.backend is container")
+	}
+	switch shell.Container.Runtime {
+	case "", "docker", "podman":
+	default:
+		return fmt.Errorf("shell.container: invalid runtime %q (must be 'docker' or 'podman')", shell.Container.Runtime)
+	}
+	return nil
+}
+
 // validateProviderMetadata validates provider metadata configuration.
 func validateProviderMetadata(provider models.ModelProvider, meta *ProviderMetadata) error {
 	if meta == nil {
@@ -1107,6 +1847,26 @@ func validateProviderMetadata(provider models.ModelProvider, meta *ProviderMetad
 	return nil
 }
 
+// validateBatchConfig checks that batch mode is set on a provider with a
+// batch API and that its durations parse.
+func validateBatchConfig(provider models.ModelProvider, batch *BatchConfig) error {
+	if batch == nil || !batch.Enabled {
+		return nil
+	}
+	if provider != models.ProviderAnthropic && provider != models.ProviderOpenAI {
+		return fmt.Errorf("provider %s: batch mode is only supported by anthropic and openai", provider)
+	}
+	for name, value := range map[string]string{"window": batch.Window, "pollInterval": batch.PollInterval, "timeout": batch.Timeout} {
+		if value == "" {
+			continue
+		}
+		if d, err := ParseDurationExtended(value); err != nil || d <= 0 {
+			return fmt.Errorf("provider %s: invalid batch.%s %q", provider, name, value)
+		}
+	}
+	return nil
+}
+
 // validateTelemetryConfig validates telemetry configuration.
 func validateTelemetryConfig(telemetry *TelemetryConfig) error {
 	if telemetry == nil {
@@ -1283,7 +2043,7 @@ func setDefaultModelForAgent(agent AgentName) bool {
 	return false
 }
 
-// UpdateCfgFile atomically rewrites .opencode.json by applying the provided
+// UpdateCfgFile atomically rewrites the config file by applying the provided
 // closure to the on-disk copy of the configuration.
 //
 // Atomicity guarantee: the function writes to <configFile>.tmp in the same
@@ -1299,13 +2059,14 @@ func setDefaultModelForAgent(agent AgentName) bool {
 // never silently widen an operator-tightened mode.
 //
 // Live-state contract: UpdateCfgFile does NOT refresh the in-memory cfg
-// singleton returned by config.Get(). Every caller MUST mutate config.cfg in
+// singleton returned by config.Get(). Every caller MUST mutate that config in
 // process alongside this call if subsequent reads must reflect the change
 // without a process restart. The existing callers (UpdateTheme,
 // UpdateVimMode, UpdateAgentModel) and the bridge HTTP handlers under
 // internal/bridge/* follow this contract — mutating cfg.X first, then
 // invoking UpdateCfgFile to persist the same change.
 func UpdateCfgFile(updateCfg func(config *Config)) error {
+	cfg := Get()
 	if cfg == nil {
 		return fmt.Errorf("config not loaded")
 	}
@@ -1333,25 +2094,21 @@ func UpdateCfgFile(updateCfg func(config *Config)) error {
 	}
 
 	var userCfg *Config
-	if err := json.Unmarshal(configData, &userCfg); err != nil {
+	if err := unmarshalConfigFile(configFile, configData, &userCfg); err != nil {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	updateCfg(userCfg)
 
-	// SetEscapeHTML(false) preserves characters like <, >, & in MCP server
-	// args (e.g. "--with 'fakeredis<2.26'") that JSON-default escaping would
-	// mangle.
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	enc.SetEscapeHTML(false)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(userCfg); err != nil {
+	// The file keeps its format: YAML and TOML configs are rewritten as
+	// YAML and TOML.
+	payload, err := marshalConfigFile(configFile, userCfg)
+	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
 	mode := resolveCfgFileMode(userCfg, configFile, createdFresh)
-	return atomicWriteFile(configFile, buf.Bytes(), mode)
+	return atomicWriteFile(configFile, payload, mode)
 }
 
 // resolveCfgFileMode picks the file mode for an UpdateCfgFile write per the
@@ -1445,11 +2202,12 @@ func atomicWriteFile(target string, payload []byte, mode os.FileMode) error {
 // Get returns the current configuration.
 // It's safe to call this function multiple times.
 func Get() *Config {
-	return cfg
+	return published.Load()
 }
 
 // WorkingDirectory returns the current working directory from the configuration.
 func WorkingDirectory() string {
+	cfg := Get()
 	if cfg == nil {
 		panic("config not loaded")
 	}
@@ -1461,6 +2219,7 @@ func (c *Config) WorkingDirectory() string {
 }
 
 func UpdateAgentModel(agentName AgentName, modelID models.ModelID) error {
+	cfg := Get()
 	if cfg == nil {
 		panic("config not loaded")
 	}
@@ -1498,6 +2257,7 @@ func UpdateAgentModel(agentName AgentName, modelID models.ModelID) error {
 
 // UpdateTheme updates the theme in the configuration and writes it to the config file.
 func UpdateTheme(themeName string) error {
+	cfg := Get()
 	if cfg == nil {
 		return fmt.Errorf("config not loaded")
 	}
@@ -1513,6 +2273,7 @@ func UpdateTheme(themeName string) error {
 
 // UpdateVimMode updates the vim mode setting and writes it to the config file.
 func UpdateVimMode(enabled bool) error {
+	cfg := Get()
 	if cfg == nil {
 		return fmt.Errorf("config not loaded")
 	}
@@ -1523,3 +2284,151 @@ func UpdateVimMode(enabled bool) error {
 		config.TUI.VimMode = enabled
 	})
 }
+
+func UpdateUsagePanel(enabled bool) error {
+	cfg := Get()
+	if cfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+
+	cfg.TUI.UsagePanel = enabled
+
+	return UpdateCfgFile(func(config *Config) {
+		config.TUI.UsagePanel = enabled
+	})
+}
+
+// AddProjectPermissionRule records action for pattern under toolName in
+// permission.rules of the project's config file, creating .opencode.json if
+// needed, and applies the same rule to the loaded config. An existing
+// string value for the tool ("ask") becomes the "*" entry of a pattern map
+// so it keeps applying to everything else.
+func AddProjectPermissionRule(toolName, pattern, action string) error {
+	cfg := Get()
+	if cfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if cfg.Permission == nil {
+		cfg.Permission = &PermissionConfig{}
+	}
+	if cfg.Permission.Rules == nil {
+		cfg.Permission.Rules = make(map[string]any)
+	}
+	cfg.Permission.Rules[toolName] = withPermissionPattern(cfg.Permission.Rules[toolName], pattern, action)
+
+	return updateProjectFile(func(doc map[string]any) {
+		perm, _ := doc["permission"].(map[string]any)
+		if perm == nil {
+			perm = map[string]any{}
+		}
+		rules, _ := perm["rules"].(map[string]any)
+		if rules == nil {
+			rules = map[string]any{}
+		}
+		rules[toolName] = withPermissionPattern(rules[toolName], pattern, action)
+		perm["rules"] = rules
+		doc["permission"] = perm
+	})
+}
+
+// AddProjectContextPath adds path, relative to the working directory, to
+// contextPaths of the project's .opencode.json and of the loaded config,
+// unless an entry already covers it. It reports whether it was added.
+//
+// A project file without contextPaths gets the whole loaded list, defaults
+// included, since its list replaces rather than extends the global one.
+func AddProjectContextPath(path string) (bool, error) {
+	cfg := Get()
+	if cfg == nil {
+		return false, fmt.Errorf("config not loaded")
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	if contextPathsCover(cfg.ContextPaths, path) {
+		return false, nil
+	}
+	current := cfg.ContextPaths
+	cfg.ContextPaths = append(slices.Clone(current), ContextPath{Path: path})
+
+	err := updateProjectFile(func(doc map[string]any) {
+		paths, ok := doc["contextPaths"].([]any)
+		if !ok {
+			for _, p := range current {
+				if p.MaxTokens != 0 {
+					paths = append(paths, map[string]any{"path": p.Path, "maxTokens": p.MaxTokens})
+				} else {
+					paths = append(paths, p.Path)
+				}
+			}
+		}
+		doc["contextPaths"] = append(paths, path)
+	})
+	return err == nil, err
+}
+
+// contextPathsCover reports whether one of paths reads file: the same file,
+// or a directory entry ("dir/") containing it.
+func contextPathsCover(paths []ContextPath, file string) bool {
+	for _, p := range paths {
+		entry := filepath.ToSlash(p.Path)
+		if strings.HasSuffix(entry, "/") {
+			if strings.HasPrefix(file, strings.TrimPrefix(entry, "./")) {
+				return true
+			}
+			continue
+		}
+		if filepath.ToSlash(filepath.Clean(entry)) == file {
+			return true
+		}
+	}
+	return false
+}
+
+// updateProjectFile applies edit to the project's config file, creating
+// .opencode.json if there is none. The file is edited as a plain document
+// rather than through Config, so keys this version doesn't know about
+// survive the rewrite, and it keeps its format.
+func updateProjectFile(edit func(doc map[string]any)) error {
+	cfg := Get()
+	path := findConfigFile(cfg.WorkingDir)
+	if path == "" {
+		path = filepath.Join(cfg.WorkingDir, fmt.Sprintf(".%s.json", appName))
+	}
+	doc := map[string]any{}
+	mode := os.FileMode(0o644)
+	if data, err := os.ReadFile(path); err == nil {
+		if doc, err = decodeConfigDoc(path, data); err != nil {
+			return err
+		}
+		if st, err := os.Stat(path); err == nil {
+			mode = st.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	edit(doc)
+
+	payload, err := encodeConfigDoc(path, doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return atomicWriteFile(path, payload, mode)
+}
+
+// withPermissionPattern returns a tool's permission value with pattern
+// set to action. The value is a copy; the input is not modified.
+func withPermissionPattern(current any, pattern, action string) any {
+	patterns := map[string]any{}
+	switch v := current.(type) {
+	case string:
+		patterns["*"] = v
+	case map[string]any:
+		maps.Copy(patterns, v)
+	case map[string]string:
+		for k, s := range v {
+			patterns[k] = s
+		}
+	}
+	patterns[pattern] = action
+	return patterns
+}
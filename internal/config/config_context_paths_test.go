@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const contextPathsBody = `{"contextPaths":["CLAUDE.md",{"path":"docs/","maxTokens":2000},{"path":"AGENTS.md","maxTokens":-1}]}`
+
+func checkContextPaths(t *testing.T, got []ContextPath) {
+	t.Helper()
+	want := []ContextPath{
+		{Path: "CLAUDE.md"},
+		{Path: "docs/", MaxTokens: 2000},
+		{Path: "AGENTS.md", MaxTokens: -1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ContextPaths = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ContextPaths[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	limits := []int{DefaultContextFileMaxTokens, 2000, 0}
+	for i, l := range limits {
+		if got[i].TokenLimit() != l {
+			t.Errorf("ContextPaths[%d].TokenLimit() = %d, want %d", i, got[i].TokenLimit(), l)
+		}
+	}
+}
+
+// Entries may be bare strings or objects with options, in both the JSON
+// and the viper loader paths.
+func TestConfig_ContextPathsMixedEntries(t *testing.T) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(contextPathsBody), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	checkContextPaths(t, cfg.ContextPaths)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".opencode.json"), []byte(contextPathsBody), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	v.SetConfigName(".opencode")
+	v.SetConfigType("json")
+	v.AddConfigPath(dir)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var viperCfg Config
+	if err := v.Unmarshal(&viperCfg, decodeHooks); err != nil {
+		t.Fatalf("viper unmarshal: %v", err)
+	}
+	checkContextPaths(t, viperCfg.ContextPaths)
+}
+
+// Agents may set their own contextPaths and inline context; agent names are
+// map keys, so check the viper loader as well.
+func TestConfig_AgentContextPathsViperRoundTrip(t *testing.T) {
+	body := `{"agents":{"Reviewer":{"contextPaths":["CLAUDE.md",{"path":"docs/","maxTokens":2000},{"path":"AGENTS.md","maxTokens":-1}],"context":["Flag every TODO."]}}}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".opencode.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	v.SetConfigName(".opencode")
+	v.SetConfigType("json")
+	v.AddConfigPath(dir)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg, decodeHooks); err != nil {
+		t.Fatalf("viper unmarshal: %v", err)
+	}
+	agent, ok := cfg.Agents["reviewer"]
+	if !ok {
+		t.Fatalf("agents = %v, want the lowercased reviewer key", cfg.Agents)
+	}
+	checkContextPaths(t, agent.ContextPaths)
+	if len(agent.Context) != 1 || agent.Context[0] != "Flag every TODO." {
+		t.Errorf("Context = %q", agent.Context)
+	}
+}
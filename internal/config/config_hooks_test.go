@@ -136,6 +136,42 @@ func TestConfig_HooksViperRoundTripLowercasesEventKeys(t *testing.T) {
 	}
 }
 
+// TestConfig_HTTPHookViperRoundTrip verifies an `http` entry keeps its
+// url and headers through viper. Header names come back lowercased like
+// every other map key, which is harmless because HTTP header names are
+// case-insensitive.
+func TestConfig_HTTPHookViperRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	body := `{"hooks":{"SessionStart":[{"hooks":[{"type":"http","url":"https://example.com/hook","headers":{"Authorization":"Bearer $TOKEN"},"timeout":5}]}]}}`
+	if err := os.WriteFile(filepath.Join(dir, ".opencode.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.SetConfigName(".opencode")
+	v.SetConfigType("json")
+	v.AddConfigPath(dir)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	g := cfg.Hooks["sessionstart"]
+	if len(g) != 1 || len(g[0].Hooks) != 1 {
+		t.Fatalf("SessionStart group not preserved: %+v", cfg.Hooks)
+	}
+	e := g[0].Hooks[0]
+	if e.Type != hooks.TypeHTTP || e.URL != "https://example.com/hook" || e.Timeout != 5 {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Headers["authorization"] != "Bearer $TOKEN" {
+		t.Errorf("headers = %v, want authorization preserved", e.Headers)
+	}
+}
+
 func mapKeys[V any](m map[string]V) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
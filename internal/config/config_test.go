@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/opencode-ai/opencode/internal/llm/models"
 )
@@ -241,6 +242,114 @@ func TestValidateProviderMetadata(t *testing.T) {
 	}
 }
 
+func TestValidateShellConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		shell       ShellConfig
+		expectError bool
+	}{
+		{name: "default backend", shell: ShellConfig{}},
+		{name: "host backend", shell: ShellConfig{Backend: ShellBackendHost}},
+		{name: "unknown backend", shell: ShellConfig{Backend: "vm"}, expectError: true},
+		{name: "container without image", shell: ShellConfig{Backend: ShellBackendContainer, Container: &ContainerConfig{}}, expectError: true},
+		{name: "container without settings", shell: ShellConfig{Backend: ShellBackendContainer}, expectError: true},
+		{name: "container", shell: ShellConfig{Backend: ShellBackendContainer, Container: &ContainerConfig{Image: "golang:1.24"}}},
+		{name: "podman", shell: ShellConfig{Backend: ShellBackendContainer, Container: &ContainerConfig{Runtime: "podman", Image: "golang:1.24"}}},
+		{name: "unknown runtime", shell: ShellConfig{Backend: ShellBackendContainer, Container: &ContainerConfig{Runtime: "lxc", Image: "golang:1.24"}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateShellConfig(tt.shell)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCodeSearchConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cs          *CodeSearchConfig
+		expectError bool
+	}{
+		{name: "unset", cs: nil},
+		{name: "openai", cs: &CodeSearchConfig{Provider: models.ProviderOpenAI}},
+		{name: "ollama", cs: &CodeSearchConfig{Provider: models.ProviderOllama}},
+		{name: "local with model", cs: &CodeSearchConfig{Provider: models.ProviderLocal, Model: "bge-m3"}},
+		{name: "local without model", cs: &CodeSearchConfig{Provider: models.ProviderLocal}, expectError: true},
+		{name: "no provider", cs: &CodeSearchConfig{}, expectError: true},
+		{name: "anthropic", cs: &CodeSearchConfig{Provider: models.ProviderAnthropic}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCodeSearchConfig(tt.cs)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDiffBudgetConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		b           *DiffBudgetConfig
+		expectError bool
+	}{
+		{name: "unset", b: nil},
+		{name: "limits only", b: &DiffBudgetConfig{MaxFiles: 20, MaxLines: 2000}},
+		{name: "deny", b: &DiffBudgetConfig{MaxLines: 500, Action: DiffBudgetDeny}},
+		{name: "negative limit", b: &DiffBudgetConfig{MaxFiles: -1}, expectError: true},
+		{name: "unknown action", b: &DiffBudgetConfig{MaxFiles: 5, Action: "warn"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDiffBudgetConfig(tt.b)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateContentFilterConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		c           *ContentFilterConfig
+		expectError bool
+	}{
+		{name: "unset", c: nil},
+		{name: "fallback", c: &ContentFilterConfig{Retry: ContentFilterRetryFallback, MaxRetries: 2}},
+		{name: "negative retries", c: &ContentFilterConfig{Retry: ContentFilterRetryRephrase, MaxRetries: -1}, expectError: true},
+		{name: "unknown strategy", c: &ContentFilterConfig{Retry: "sanitize"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateContentFilterConfig(tt.c)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestValidateTelemetryConfig(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -409,3 +518,26 @@ func TestFixPermissionKeys_GlobalRules(t *testing.T) {
 		t.Errorf("expected '~/.ssh/*' key after fix, got: %v", readMap)
 	}
 }
+
+func TestModelCheckInterval(t *testing.T) {
+	tests := []struct {
+		name  string
+		check *ModelCheckConfig
+		want  time.Duration
+	}{
+		{"unset", nil, DefaultModelCheckInterval},
+		{"empty interval", &ModelCheckConfig{}, DefaultModelCheckInterval},
+		{"custom", &ModelCheckConfig{Interval: "6h"}, 6 * time.Hour},
+		{"days", &ModelCheckConfig{Interval: "1d"}, 24 * time.Hour},
+		{"invalid", &ModelCheckConfig{Interval: "soon"}, DefaultModelCheckInterval},
+		{"disabled", &ModelCheckConfig{Disabled: true, Interval: "6h"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ModelCheck: tt.check}
+			if got := cfg.ModelCheckInterval(); got != tt.want {
+				t.Errorf("ModelCheckInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
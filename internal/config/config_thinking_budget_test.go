@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+func TestValidateAgentThinkingBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		agent      Agent
+		wantBudget int64
+		wantEffort string
+	}{
+		{
+			name:       "raised to the minimum",
+			agent:      Agent{Model: models.Claude45Opus, MaxTokens: 8000, ThinkingBudgetTokens: 500},
+			wantBudget: MinThinkingBudgetTokens,
+		},
+		{
+			name:       "kept below max tokens",
+			agent:      Agent{Model: models.Claude45Opus, MaxTokens: 8000, ThinkingBudgetTokens: 10000},
+			wantBudget: 6400,
+		},
+		{
+			name:       "maps to an openai effort",
+			agent:      Agent{Model: models.O4Mini, MaxTokens: 8000, ThinkingBudgetTokens: 2000},
+			wantBudget: 2000,
+			wantEffort: "low",
+		},
+		{
+			name:       "explicit effort wins",
+			agent:      Agent{Model: models.O4Mini, MaxTokens: 40000, ThinkingBudgetTokens: 32000, ReasoningEffort: "medium"},
+			wantBudget: 32000,
+			wantEffort: "medium",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearProviderEnv(t)
+			c := &Config{
+				Agents: map[AgentName]Agent{AgentCoder: tt.agent},
+				Providers: map[models.ModelProvider]Provider{
+					models.ProviderAnthropic: {APIKey: "test-key"},
+					models.ProviderOpenAI:    {APIKey: "test-key"},
+				},
+			}
+			if err := validateAgent(c, AgentCoder, c.Agents[AgentCoder]); err != nil {
+				t.Fatalf("validateAgent: %v", err)
+			}
+			got := c.Agents[AgentCoder]
+			if got.ThinkingBudgetTokens != tt.wantBudget {
+				t.Fatalf("thinking budget = %d, want %d", got.ThinkingBudgetTokens, tt.wantBudget)
+			}
+			if got.ReasoningEffort != tt.wantEffort {
+				t.Fatalf("reasoning effort = %q, want %q", got.ReasoningEffort, tt.wantEffort)
+			}
+		})
+	}
+}
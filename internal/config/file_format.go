@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configExts are the config file formats, in the order they are looked up
+// within a directory: when a directory has several, JSON wins, then YAML,
+// then TOML.
+var configExts = []string{"json", "yaml", "yml", "toml"}
+
+// findConfigFile returns the first .opencode.<ext> that exists, trying the
+// directories in order and configExts within each. It returns "" when there
+// is none.
+func findConfigFile(dirs ...string) string {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, ext := range configExts {
+			path := filepath.Join(dir, fmt.Sprintf(".%s.%s", appName, ext))
+			if st, err := os.Stat(path); err == nil && !st.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// globalConfigDirs are the directories searched for the global config file,
+// in order.
+func globalConfigDirs() []string {
+	home := os.Getenv("HOME")
+	dirs := []string{home}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, appName))
+	}
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, ".config", appName))
+	}
+	return dirs
+}
+
+// configFormat returns the format of a config file from its extension.
+func configFormat(path string) string {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "yml" {
+		return "yaml"
+	}
+	if ext == "yaml" || ext == "toml" {
+		return ext
+	}
+	return "json"
+}
+
+// decodeConfigDoc parses a config file of any supported format into a
+// plain document.
+func decodeConfigDoc(path string, data []byte) (map[string]any, error) {
+	doc := map[string]any{}
+	var err error
+	switch configFormat(path) {
+	case "yaml":
+		err = yaml.Unmarshal(data, &doc)
+	case "toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	return doc, nil
+}
+
+// encodeConfigDoc renders a document in the format of the file at path.
+func encodeConfigDoc(path string, doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	switch configFormat(path) {
+	case "yaml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+			return nil, err
+		}
+	default:
+		// SetEscapeHTML(false) preserves characters like <, >, & in MCP
+		// server args that JSON-default escaping would mangle.
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalConfigFile decodes a config file of any supported format into
+// v, through the JSON field names the Config types declare.
+func unmarshalConfigFile(path string, data []byte, v any) error {
+	if configFormat(path) != "json" {
+		doc, err := decodeConfigDoc(path, data)
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// marshalConfigFile renders v in the format of the file at path.
+func marshalConfigFile(path string, v any) ([]byte, error) {
+	if configFormat(path) == "json" {
+		return encodeConfigDoc(path, v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]any{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return encodeConfigDoc(path, doc)
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	assert.Equal(t, "", findConfigFile(first, second))
+
+	write := func(dir, name string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0o644))
+		return path
+	}
+	toml := write(second, ".opencode.toml")
+	assert.Equal(t, toml, findConfigFile(first, second))
+	yaml := write(second, ".opencode.yaml")
+	assert.Equal(t, yaml, findConfigFile(first, second), "YAML before TOML")
+	json := write(second, ".opencode.json")
+	assert.Equal(t, json, findConfigFile(first, second), "JSON before YAML")
+	yml := write(first, ".opencode.yml")
+	assert.Equal(t, yml, findConfigFile(first, second), "directories come first")
+}
+
+func TestLoadYAMLAndTOML(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".opencode.toml"), []byte(`
+[providers.openai]
+apiKey = "global-key"
+
+[agents.coder]
+model = "gpt-5"
+maxTokens = 4000
+`), 0o644))
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".opencode.yaml"), []byte(`
+agents:
+  coder:
+    model: o3
+autoCompact: false
+`), 0o644))
+	prevFile := viper.ConfigFileUsed()
+	Reset()
+	t.Cleanup(func() {
+		Reset()
+		viper.SetConfigFile(prevFile)
+	})
+
+	c, err := Load(workDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, "global-key", c.Providers[models.ProviderOpenAI].APIKey)
+	assert.Equal(t, models.ModelID("o3"), c.Agents[AgentCoder].Model, "the project file wins")
+	assert.Equal(t, int64(4000), c.Agents[AgentCoder].MaxTokens)
+	assert.False(t, c.AutoCompact)
+}
+
+func TestUpdateCfgFileKeepsFormat(t *testing.T) {
+	for name, content := range map[string]string{
+		".opencode.yaml": "tui:\n  theme: old\n",
+		".opencode.toml": "[tui]\ntheme = \"old\"\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+			prevFile := viper.ConfigFileUsed()
+			useConfig(t, &Config{})
+			viper.SetConfigFile(path)
+			t.Cleanup(func() { viper.SetConfigFile(prevFile) })
+
+			require.NoError(t, UpdateCfgFile(func(c *Config) { c.TUI.Theme = "new" }))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			var got Config
+			require.NoError(t, unmarshalConfigFile(path, data, &got))
+			assert.Equal(t, "new", got.TUI.Theme)
+		})
+	}
+}
+
+func TestUpdateProjectFileKeepsFormat(t *testing.T) {
+	workDir := t.TempDir()
+	path := filepath.Join(workDir, ".opencode.yml")
+	require.NoError(t, os.WriteFile(path, []byte("custom: kept\n"), 0o644))
+	useConfig(t, &Config{WorkingDir: workDir})
+
+	require.NoError(t, updateProjectFile(func(doc map[string]any) { doc["autoCompact"] = true }))
+
+	_, err := os.Stat(filepath.Join(workDir, ".opencode.json"))
+	assert.True(t, os.IsNotExist(err), "no JSON file is created next to the YAML one")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	doc, err := decodeConfigDoc(path, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"custom": "kept", "autoCompact": true}, doc)
+}
@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// PolicySource is the machine-wide file that points opencode at an
+// organisation policy. Admins install it at SystemPolicySourcePath (e.g.
+// via MDM); user and project configs cannot name a different policy.
+type PolicySource struct {
+	// URL of the policy JSON: https://, http:// or file://. Its detached
+	// signature is read from URL + ".sig".
+	URL string `json:"url"`
+	// PublicKey verifies the policy's Ed25519 signature: a PEM encoded
+	// public key, inline or as a file path.
+	PublicKey string `json:"publicKey"`
+	// FailClosed refuses to start when no valid policy can be fetched or
+	// found in the cache. By default opencode starts without one and warns.
+	FailClosed bool `json:"failClosed,omitempty"`
+}
+
+// Policy is the locked organisation layer. It is fetched before the user
+// and project configs are read, and what it sets cannot be overridden by
+// them. See docs/policy.md.
+type Policy struct {
+	// Defaults are config values applied beneath the user and project
+	// configs, for settings an organisation distributes but does not lock.
+	Defaults map[string]any `json:"defaults,omitempty"`
+	// Locked are config values applied over every other layer.
+	Locked map[string]any `json:"locked,omitempty"`
+	// Permission holds deny rules in the permission.rules shape. They are
+	// checked before agent, project and user rules, which cannot lift them.
+	Permission map[string]any `json:"permission,omitempty"`
+	// BannedProviders may not serve any agent.
+	BannedProviders []models.ModelProvider `json:"bannedProviders,omitempty"`
+	// Budget caps the configured budget: the lower of each pair of limits
+	// applies.
+	Budget *BudgetConfig `json:"budget,omitempty"`
+}
+
+const (
+	// PolicySourceEnv names a policy source file when none is installed
+	// at SystemPolicySourcePath, for containers and CI images.
+	PolicySourceEnv = "OPENCODE_POLICY_SOURCE"
+
+	policyFetchTimeout = 10 * time.Second
+	policyMaxSize      = 1 << 20
+)
+
+// SystemPolicySourcePath returns where admins install the policy source.
+func SystemPolicySourcePath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), appName, "policy.json")
+	}
+	return filepath.Join("/etc", appName, "policy.json")
+}
+
+// ReadPolicySource reads the installed policy source. The system file
+// wins over PolicySourceEnv, so a user cannot point opencode elsewhere on
+// a managed machine. It returns nil when neither exists.
+func ReadPolicySource() (*PolicySource, string, error) {
+	path := SystemPolicySourcePath()
+	if _, err := os.Stat(path); err != nil {
+		path = os.Getenv(PolicySourceEnv)
+		if path == "" {
+			return nil, "", nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, path, fmt.Errorf("policy source: %w", err)
+	}
+	var src PolicySource
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, path, fmt.Errorf("policy source %s: %w", path, err)
+	}
+	if src.URL == "" || src.PublicKey == "" {
+		return nil, path, fmt.Errorf("policy source %s: url and publicKey are required", path)
+	}
+	return &src, path, nil
+}
+
+// loadPolicy fetches and verifies the policy named by the installed
+// source. A verified copy is cached so a machine that is offline keeps the
+// last policy it saw.
+func loadPolicy() (*Policy, error) {
+	src, _, err := ReadPolicySource()
+	if err != nil || src == nil {
+		return nil, err
+	}
+	pub, err := parsePolicyKey(src.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	body, sig, fetchErr := fetchSignedPolicy(src.URL, pub)
+	if fetchErr == nil {
+		writePolicyCache(src.URL, body, sig)
+	} else {
+		cached, cacheErr := readPolicyCache(src.URL, pub)
+		if cacheErr != nil {
+			if src.FailClosed {
+				return nil, fmt.Errorf("organisation policy unavailable: %w", fetchErr)
+			}
+			logging.Warn("Organisation policy unavailable, starting without it", "url", src.URL, "error", fetchErr)
+			return nil, nil
+		}
+		logging.Warn("Organisation policy unavailable, using the cached copy", "url", src.URL, "error", fetchErr)
+		body = cached
+	}
+	return ParsePolicy(body)
+}
+
+// ParsePolicy decodes a policy document, rejecting unknown keys and
+// permission rules other than "deny".
+func ParsePolicy(data []byte) (*Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+	for tool, rule := range p.Permission {
+		switch r := rule.(type) {
+		case string:
+			if r != "deny" {
+				return nil, fmt.Errorf("policy: permission.%s must be \"deny\" or a map of patterns to \"deny\"", tool)
+			}
+		case map[string]any:
+			for pattern, action := range r {
+				if action != "deny" {
+					return nil, fmt.Errorf("policy: permission.%s[%q] must be \"deny\"", tool, pattern)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("policy: permission.%s must be \"deny\" or a map of patterns to \"deny\"", tool)
+		}
+	}
+	return &p, nil
+}
+
+// VerifyPolicy checks sig, the base64 encoded detached Ed25519 signature
+// of body.
+func VerifyPolicy(body, sig []byte, pub ed25519.PublicKey) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("policy signature: %w", err)
+	}
+	if !ed25519.Verify(pub, body, raw) {
+		return errors.New("policy signature does not match")
+	}
+	return nil
+}
+
+func fetchSignedPolicy(rawURL string, pub ed25519.PublicKey) (body, sig []byte, err error) {
+	if body, err = fetchPolicyFile(rawURL); err != nil {
+		return nil, nil, err
+	}
+	if sig, err = fetchPolicyFile(rawURL + ".sig"); err != nil {
+		return nil, nil, err
+	}
+	if err := VerifyPolicy(body, sig, pub); err != nil {
+		return nil, nil, err
+	}
+	return body, sig, nil
+}
+
+func fetchPolicyFile(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("policy url: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		return os.ReadFile(u.Path)
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("policy url %s: unsupported scheme %q", rawURL, u.Scheme)
+	}
+	client := &http.Client{Timeout: policyFetchTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, policyMaxSize))
+}
+
+// parsePolicyKey accepts a PEM public key inline or as a file path.
+func parsePolicyKey(key string) (ed25519.PublicKey, error) {
+	data := []byte(key)
+	if !strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(key); err != nil {
+			return nil, fmt.Errorf("policy public key: %w", err)
+		}
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("policy public key: no PEM data found")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("policy public key: %w", err)
+	}
+	pub, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("policy public key: not an Ed25519 key")
+	}
+	return pub, nil
+}
+
+// policyCachePath is where the last verified policy is kept. The cache is
+// keyed by URL and re-verified on read, so editing it gains nothing.
+func policyCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName, "policy.json"), nil
+}
+
+type policyCache struct {
+	URL       string `json:"url"`
+	Body      []byte `json:"body"`
+	Signature []byte `json:"signature"`
+}
+
+func writePolicyCache(rawURL string, body, sig []byte) {
+	path, err := policyCachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(policyCache{URL: rawURL, Body: body, Signature: sig})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		logging.Debug("Failed to cache organisation policy", "error", err)
+	}
+}
+
+func readPolicyCache(rawURL string, pub ed25519.PublicKey) ([]byte, error) {
+	path, err := policyCachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c policyCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.URL != rawURL {
+		return nil, errors.New("cached policy is for another url")
+	}
+	if err := VerifyPolicy(c.Body, c.Signature, pub); err != nil {
+		return nil, err
+	}
+	return c.Body, nil
+}
+
+// applyPolicyDefaults sets the policy's defaults beneath every config
+// file.
+func applyPolicyDefaults(p *Policy) {
+	if p == nil {
+		return
+	}
+	for key, value := range p.Defaults {
+		viper.SetDefault(key, value)
+	}
+}
+
+// applyPolicyLocks sets the policy's locked values over every config
+// file, leaf by leaf so sibling settings from other layers survive.
+func applyPolicyLocks(p *Policy) {
+	if p == nil {
+		return
+	}
+	var lock func(prefix string, m map[string]any)
+	lock = func(prefix string, m map[string]any) {
+		for key, value := range m {
+			if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+				lock(prefix+key+".", nested)
+				continue
+			}
+			viper.Set(prefix+key, value)
+		}
+	}
+	lock("", p.Locked)
+}
+
+// enforcePolicy applies what cannot be expressed as config values: banned
+// providers are disabled and budgets are capped. The deny rules are
+// applied by the agent registry and the provider ban again by
+// validateAgent, for models chosen later.
+func enforcePolicy(c *Config, p *Policy) {
+	c.Policy = p
+	if p == nil {
+		return
+	}
+	for _, provider := range p.BannedProviders {
+		providerCfg := c.Providers[provider]
+		providerCfg.Disabled = true
+		c.Providers[provider] = providerCfg
+	}
+	if p.Budget != nil {
+		if c.Budget == nil {
+			c.Budget = &BudgetConfig{}
+		}
+		c.Budget.Session = capBudgetLimits(c.Budget.Session, p.Budget.Session)
+		c.Budget.Global = capBudgetLimits(c.Budget.Global, p.Budget.Global)
+	}
+}
+
+// capBudgetLimits returns the stricter of each limit; zero is unlimited.
+func capBudgetLimits(configured, ceiling *BudgetLimits) *BudgetLimits {
+	if ceiling.IsZero() {
+		return configured
+	}
+	capped := BudgetLimits{}
+	if configured != nil {
+		capped = *configured
+	}
+	if ceiling.MaxCostUSD > 0 && (capped.MaxCostUSD <= 0 || capped.MaxCostUSD > ceiling.MaxCostUSD) {
+		capped.MaxCostUSD = ceiling.MaxCostUSD
+	}
+	if ceiling.MaxTokens > 0 && (capped.MaxTokens <= 0 || capped.MaxTokens > ceiling.MaxTokens) {
+		capped.MaxTokens = ceiling.MaxTokens
+	}
+	return &capped
+}
+
+// BansProvider reports whether the policy forbids provider.
+func (p *Policy) BansProvider(provider models.ModelProvider) bool {
+	return p != nil && slices.Contains(p.BannedProviders, provider)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+// servePolicy serves body at /policy.json and its signature by priv at
+// /policy.json.sig, and installs a policy source pointing there.
+func servePolicy(t *testing.T, body string, sign ed25519.PrivateKey, pub ed25519.PublicKey, failClosed bool) *httptest.Server {
+	t.Helper()
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(sign, []byte(body)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/policy.json", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(body)) })
+	mux.HandleFunc("/policy.json.sig", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(sig)) })
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	src, err := json.Marshal(PolicySource{
+		URL:        srv.URL + "/policy.json",
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		FailClosed: failClosed,
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "source.json")
+	require.NoError(t, os.WriteFile(path, src, 0o644))
+	t.Setenv(PolicySourceEnv, path)
+	return srv
+}
+
+func TestLoadPolicy(t *testing.T) {
+	if _, err := os.Stat(SystemPolicySourcePath()); err == nil {
+		t.Skip("a system policy source is installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, other, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	body := `{"bannedProviders": ["openai"], "permission": {"bash": {"curl *": "deny"}}}`
+
+	t.Run("no source", func(t *testing.T) {
+		t.Setenv(PolicySourceEnv, "")
+		p, err := loadPolicy()
+		require.NoError(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		srv := servePolicy(t, body, priv, pub, true)
+		p, err := loadPolicy()
+		require.NoError(t, err)
+		require.NotNil(t, p)
+		assert.True(t, p.BansProvider(models.ProviderOpenAI))
+		assert.Contains(t, p.Permission, "bash")
+
+		srv.Close()
+		p, err = loadPolicy()
+		require.NoError(t, err, "the cached copy is used while unreachable")
+		require.NotNil(t, p)
+		assert.True(t, p.BansProvider(models.ProviderOpenAI))
+	})
+
+	t.Run("wrong signature", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		servePolicy(t, body, other, pub, true)
+		_, err := loadPolicy()
+		assert.ErrorContains(t, err, "signature")
+
+		servePolicy(t, body, other, pub, false)
+		p, err := loadPolicy()
+		require.NoError(t, err, "fail-open starts without a policy")
+		assert.Nil(t, p)
+	})
+}
+
+func TestParsePolicy(t *testing.T) {
+	_, err := ParsePolicy([]byte(`{"permission": {"bash": {"git *": "allow"}}}`))
+	assert.Error(t, err, "policies may only deny")
+	_, err = ParsePolicy([]byte(`{"permission": {"bash": "ask"}}`))
+	assert.Error(t, err)
+	_, err = ParsePolicy([]byte(`{"lock": {}}`))
+	assert.Error(t, err, "unknown keys are rejected")
+
+	p, err := ParsePolicy([]byte(`{"permission": {"webfetch": "deny", "bash": {"rm -rf *": "deny"}}}`))
+	require.NoError(t, err)
+	assert.Len(t, p.Permission, 2)
+}
+
+func TestEnforcePolicy(t *testing.T) {
+	c := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			models.ProviderOpenAI: {APIKey: "key"},
+		},
+		Budget: &BudgetConfig{
+			Session: &BudgetLimits{MaxCostUSD: 50, MaxTokens: 1000},
+		},
+	}
+	enforcePolicy(c, &Policy{
+		BannedProviders: []models.ModelProvider{models.ProviderOpenAI, models.ProviderGemini},
+		Budget: &BudgetConfig{
+			Session: &BudgetLimits{MaxCostUSD: 10},
+			Global:  &BudgetLimits{MaxTokens: 5000},
+		},
+	})
+
+	assert.True(t, c.Providers[models.ProviderOpenAI].Disabled)
+	assert.Equal(t, "key", c.Providers[models.ProviderOpenAI].APIKey)
+	assert.True(t, c.Providers[models.ProviderGemini].Disabled)
+	assert.Equal(t, &BudgetLimits{MaxCostUSD: 10, MaxTokens: 1000}, c.Budget.Session)
+	assert.Equal(t, &BudgetLimits{MaxTokens: 5000}, c.Budget.Global)
+	assert.NotNil(t, c.Policy)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/spf13/viper"
+)
+
+// Profile is a named preset of providers, agent settings and permissions.
+// The selected profile is merged over the rest of the configuration the
+// way the project config is merged over the global one: maps are merged
+// key by key and the profile's values win.
+type Profile struct {
+	Providers  map[models.ModelProvider]Provider `json:"providers,omitempty"`
+	Agents     map[AgentName]Agent               `json:"agents,omitempty"`
+	Permission *PermissionConfig                 `json:"permission,omitempty"`
+}
+
+// profileOverride is the profile chosen with --profile or switched to at
+// runtime. It wins over the "profile" key of the config files; nil leaves
+// the choice to them.
+var profileOverride *string
+
+// SetProfile selects the profile Load applies, "" for none, overriding the
+// "profile" key of the config files. Call it before Load.
+func SetProfile(name string) {
+	profileOverride = &name
+}
+
+// applyProfile merges the selected profile into the settings read so far
+// and returns its name. An unknown profile is an error rather than a
+// silent fallback to the base settings: they may use another account's
+// keys or looser permissions.
+func applyProfile() (string, error) {
+	name := viper.GetString("profile")
+	if profileOverride != nil {
+		name = *profileOverride
+	}
+	if name == "" {
+		return "", nil
+	}
+	preset, ok := viper.Get("profiles." + name).(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("unknown profile %q", name)
+	}
+	return name, viper.MergeConfigMap(preset)
+}
+
+// ProfileNames returns the names of the configured profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// SwitchProfile reloads the configuration with the named profile applied,
+// "" for none, and publishes it: Get returns the new *Config from then on,
+// while holders of the previous one keep a consistent, if stale, value.
+// Read-only mode, the turn limit given on the command line and the
+// organisation policy loaded at startup are kept. On failure the previous
+// configuration stays in force. Agents and the agent registry have to be
+// refreshed by the caller.
+func SwitchProfile(name string) error {
+	switchMu.Lock()
+	defer switchMu.Unlock()
+
+	prev := Get()
+	if prev == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if name != "" {
+		if _, ok := prev.Profiles[name]; !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	prevOverride := profileOverride
+	fresh, err := reload(prev, &name)
+	if err != nil {
+		if _, restoreErr := reload(prev, prevOverride); restoreErr != nil {
+			err = fmt.Errorf("%w (restoring the previous profile: %v)", err, restoreErr)
+		}
+		cfg = prev
+		return err
+	}
+	fresh.ReadOnly = fresh.ReadOnly || prev.ReadOnly
+	fresh.MaxTurns = prev.MaxTurns
+	published.Store(fresh)
+	return nil
+}
+
+// switchMu serializes profile switches, which rebuild the package's
+// loading state.
+var switchMu sync.Mutex
+
+// reload loads the configuration again with override as the profile,
+// without publishing it. Only the values read from files are dropped:
+// defaults registered outside Load, such as the Copilot and Ollama models,
+// must survive. The organisation policy is reused rather than fetched again.
+func reload(prev *Config, override *string) (*Config, error) {
+	cfg = nil
+	profileOverride = override
+	// Load sets the type back to the global config file's format.
+	viper.SetConfigType("json")
+	if err := viper.ReadConfig(strings.NewReader("{}")); err != nil {
+		return nil, err
+	}
+	return load(prev.WorkingDir, prev.Debug, func() (*Policy, error) { return prev.Policy, nil })
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+const profileTestConfig = `{
+  "providers": {"openai": {"apiKey": "base-key"}},
+  "agents": {"coder": {"model": "gpt-5"}},
+  "profile": "cheap",
+  "profiles": {
+    "cheap": {
+      "providers": {"openai": {"apiKey": "cheap-key"}},
+      "agents": {"coder": {"model": "o4-mini"}},
+      "permission": {"rules": {"bash": "deny"}}
+    },
+    "work": {
+      "agents": {"coder": {"model": "o3"}}
+    }
+  }
+}`
+
+func loadProfileTestConfig(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("OPENCODE_PROFILE", "")
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".opencode.json"), []byte(profileTestConfig), 0o644))
+	Reset()
+	t.Cleanup(Reset)
+	return workDir
+}
+
+func TestLoadAppliesProfile(t *testing.T) {
+	workDir := loadProfileTestConfig(t)
+
+	c, err := Load(workDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, "cheap", c.Profile, "the project's default profile")
+	assert.Equal(t, models.ModelID("o4-mini"), c.Agents[AgentCoder].Model)
+	assert.Equal(t, "cheap-key", c.Providers[models.ProviderOpenAI].APIKey)
+	assert.Equal(t, "deny", c.Permission.Rules["bash"])
+	assert.Equal(t, []string{"cheap", "work"}, c.ProfileNames())
+
+	Reset()
+	SetProfile("work")
+	c, err = Load(workDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, "work", c.Profile, "--profile wins over the config key")
+	assert.Equal(t, models.ModelID("o3"), c.Agents[AgentCoder].Model)
+	assert.Equal(t, "base-key", c.Providers[models.ProviderOpenAI].APIKey)
+
+	Reset()
+	SetProfile("missing")
+	_, err = Load(workDir, false)
+	assert.ErrorContains(t, err, `unknown profile "missing"`)
+}
+
+func TestSwitchProfile(t *testing.T) {
+	workDir := loadProfileTestConfig(t)
+	c, err := Load(workDir, false)
+	require.NoError(t, err)
+	c.ReadOnly = true
+	policy := &Policy{}
+	c.Policy = policy
+
+	require.NoError(t, SwitchProfile(""))
+	fresh := Get()
+	assert.NotSame(t, c, fresh, "the new config is published, not copied over the old one")
+	assert.Equal(t, "cheap", c.Profile, "holders of the old config keep a consistent value")
+	assert.Equal(t, "", fresh.Profile)
+	assert.Equal(t, models.ModelID("gpt-5"), fresh.Agents[AgentCoder].Model)
+	assert.True(t, fresh.ReadOnly, "command-line read-only mode is kept")
+	assert.Same(t, policy, fresh.Policy, "the loaded organisation policy is reused")
+
+	require.NoError(t, SwitchProfile("work"))
+	assert.Equal(t, "work", Get().Profile)
+	assert.Equal(t, models.ModelID("o3"), Get().Agents[AgentCoder].Model)
+
+	assert.Error(t, SwitchProfile("missing"))
+	assert.Equal(t, "work", Get().Profile, "a failed switch keeps the profile in force")
+}
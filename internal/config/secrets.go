@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// secretRef matches the placeholders resolved at load time:
+//
+//	${env:NAME}               the environment variable NAME
+//	${file:/path/to/key}      the file's content, without the trailing newline
+//	${keychain:service}       a secret from the OS keychain
+//	${keychain:service/user}  the same, for one account of the service
+//
+// Plain ${NAME} references are left alone; webhook secrets and hook headers
+// expand those themselves when they are used.
+var secretRef = regexp.MustCompile(`\$\{(env|file|keychain):([^}]+)\}`)
+
+// keychainLookup reads a secret from the OS keychain; replaced in tests.
+var keychainLookup = lookupKeychain
+
+// keychainCache keeps keychain secrets for the life of the process, so a
+// profile switch, which reloads the configuration, doesn't ask for them
+// again.
+var keychainCache sync.Map
+
+// resolveSecrets replaces the placeholders in every string setting of c,
+// apiKey, headers and MCP env included. The profiles are skipped: the one
+// in use has been merged into the settings already, and the others may
+// refer to secrets that are not available on this machine.
+func resolveSecrets(c *Config) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Name == "Profiles" {
+			continue
+		}
+		if err := resolveValue(v.Field(i), jsonName(field), c.WorkingDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveValue(v reflect.Value, path, workingDir string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := expandSecrets(v.String(), workingDir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if v.CanSet() && s != v.String() {
+			v.SetString(s)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return resolveValue(v.Elem(), path, workingDir)
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return nil
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := resolveValue(elem, path, workingDir); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := resolveValue(v.Field(i), path+"."+jsonName(field), workingDir); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := resolveValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), workingDir); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values aren't addressable: resolve a copy and store it back.
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := resolveValue(elem, fmt.Sprintf("%s.%v", path, iter.Key()), workingDir); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// expandSecrets replaces the placeholders in s. A placeholder that can't be
+// resolved is an error: an empty API key fails later, and less clearly.
+func expandSecrets(s, workingDir string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var firstErr error
+	out := secretRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := secretRef.FindStringSubmatch(ref)
+		value, err := resolveSecret(m[1], m[2], workingDir)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return value
+	})
+	return out, firstErr
+}
+
+func resolveSecret(scheme, ref, workingDir string) (string, error) {
+	switch scheme {
+	case "env":
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return value, nil
+	case "file":
+		path := ref
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			path = filepath.Join(home, rest)
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		if value, ok := keychainCache.Load(ref); ok {
+			return value.(string), nil
+		}
+		service, account, _ := strings.Cut(ref, "/")
+		value, err := keychainLookup(service, account)
+		if err != nil {
+			return "", fmt.Errorf("keychain %s: %w", ref, err)
+		}
+		keychainCache.Store(ref, value)
+		return value, nil
+	}
+}
+
+// lookupKeychain reads a generic password with the platform's keychain
+// tool: security(1) on macOS and secret-tool(1) from libsecret on Linux.
+// An empty account matches any account of the service.
+func lookupKeychain(service, account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-s", service, "-w"}
+		if account != "" {
+			args = append(args, "-a", account)
+		}
+		cmd = exec.Command("security", args...)
+	case "linux", "freebsd", "openbsd":
+		args := []string{"lookup", "service", service}
+		if account != "" {
+			args = append(args, "account", account)
+		}
+		cmd = exec.Command("secret-tool", args...)
+	default:
+		return "", fmt.Errorf("keychain lookups are not supported on %s", runtime.GOOS)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	value := strings.TrimRight(string(out), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("no secret found")
+	}
+	return value, nil
+}
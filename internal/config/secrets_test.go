@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+func stubKeychain(t *testing.T, secrets map[string]string) {
+	t.Helper()
+	prev := keychainLookup
+	keychainLookup = func(service, account string) (string, error) {
+		if v, ok := secrets[service+"/"+account]; ok {
+			return v, nil
+		}
+		return "", errors.New("no secret found")
+	}
+	t.Cleanup(func() {
+		keychainLookup = prev
+		keychainCache.Clear()
+	})
+}
+
+func TestExpandSecrets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key"), []byte("file-secret\n"), 0o600))
+	t.Setenv("OPENCODE_TEST_TOKEN", "env-secret")
+	stubKeychain(t, map[string]string{"opencode/": "kc-secret", "opencode/work": "kc-work"})
+
+	for in, want := range map[string]string{
+		"${env:OPENCODE_TEST_TOKEN}":        "env-secret",
+		"Bearer ${env:OPENCODE_TEST_TOKEN}": "Bearer env-secret",
+		"${file:key}":                       "file-secret",
+		"${file:" + dir + "/key}":           "file-secret",
+		"${keychain:opencode}":              "kc-secret",
+		"${keychain:opencode/work}":         "kc-work",
+		"${HOME} and $PATH":                 "${HOME} and $PATH",
+		"plain":                             "plain",
+	} {
+		got, err := expandSecrets(in, dir)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"${env:OPENCODE_TEST_UNSET}", "${file:missing}", "${keychain:other}"} {
+		_, err := expandSecrets(in, dir)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("OPENCODE_TEST_TOKEN", "env-secret")
+	c := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			models.ProviderOpenAI: {APIKey: "${env:OPENCODE_TEST_TOKEN}", Headers: map[string]string{"X-Key": "${env:OPENCODE_TEST_TOKEN}"}},
+		},
+		MCPServers: map[string]MCPServer{
+			"github": {Env: []string{"GITHUB_TOKEN=${env:OPENCODE_TEST_TOKEN}"}},
+		},
+		Profiles: map[string]Profile{
+			"elsewhere": {Providers: map[models.ModelProvider]Provider{models.ProviderOpenAI: {APIKey: "${env:OPENCODE_TEST_UNSET}"}}},
+		},
+	}
+
+	require.NoError(t, resolveSecrets(c), "unused profiles are not resolved")
+	assert.Equal(t, "env-secret", c.Providers[models.ProviderOpenAI].APIKey)
+	assert.Equal(t, "env-secret", c.Providers[models.ProviderOpenAI].Headers["X-Key"])
+	assert.Equal(t, []string{"GITHUB_TOKEN=env-secret"}, c.MCPServers["github"].Env)
+
+	c.Providers[models.ProviderOpenAI] = Provider{APIKey: "${env:OPENCODE_TEST_UNSET}"}
+	err := resolveSecrets(c)
+	assert.ErrorContains(t, err, "providers.openai.apiKey: environment variable OPENCODE_TEST_UNSET is not set")
+}
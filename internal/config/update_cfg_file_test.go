@@ -5,12 +5,25 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/opencode-ai/opencode/internal/bridge"
 	"github.com/spf13/viper"
 )
 
+// useConfig publishes c as the loaded config until the test ends.
+func useConfig(t *testing.T, c *Config) {
+	t.Helper()
+	prevCfg, prevPublished := cfg, published.Load()
+	cfg = c
+	published.Store(c)
+	t.Cleanup(func() {
+		cfg = prevCfg
+		published.Store(prevPublished)
+	})
+}
+
 // withTestConfigFile points viper at a fresh .opencode.json under t.TempDir
 // and primes the package-global cfg singleton. The caller's closure can then
 // drive UpdateCfgFile against a clean filesystem environment.
@@ -25,13 +38,11 @@ func withTestConfigFile(t *testing.T, initialContent string) (configPath string,
 		}
 	}
 
-	prevCfg := cfg
 	prevConfigFile := viper.ConfigFileUsed()
-	cfg = &Config{}
+	useConfig(t, &Config{})
 	viper.SetConfigFile(configPath)
 
 	restore = func() {
-		cfg = prevCfg
 		// viper.Reset() is too heavy; just restore the config file pointer
 		// to whatever was there before. If prev was empty, SetConfigFile("")
 		// is the documented way to clear it.
@@ -167,3 +178,119 @@ func TestUpdateCfgFilePreservesLaxModeWhenNoTokens(t *testing.T) {
 		t.Errorf("mode = %v, want 0o644 (no tokens, no operator hardening — preserve)", got)
 	}
 }
+
+func TestAddProjectPermissionRule(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".opencode.json")
+	seed := `{"agents":{"coder":{"model":"x"}},"futureKey":true,"permission":{"rules":{"bash":"ask"}}}`
+	if err := os.WriteFile(configPath, []byte(seed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, &Config{WorkingDir: dir, Permission: &PermissionConfig{Rules: map[string]any{"bash": "ask"}}})
+
+	if err := AddProjectPermissionRule("bash", "go test ./...", "allow"); err != nil {
+		t.Fatalf("first rule: %v", err)
+	}
+	if err := AddProjectPermissionRule("edit", "/repo/main.go", "allow"); err != nil {
+		t.Fatalf("second rule: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("result is not valid JSON: %v", err)
+	}
+	if doc["futureKey"] != true || doc["agents"] == nil {
+		t.Errorf("unrelated keys lost: %s", data)
+	}
+	rules := doc["permission"].(map[string]any)["rules"].(map[string]any)
+	bash := rules["bash"].(map[string]any)
+	if bash["*"] != "ask" || bash["go test ./..."] != "allow" {
+		t.Errorf("bash rules = %v", bash)
+	}
+	if edit := rules["edit"].(map[string]any); edit["/repo/main.go"] != "allow" {
+		t.Errorf("edit rules = %v", edit)
+	}
+
+	live := cfg.Permission.Rules["bash"].(map[string]any)
+	if live["*"] != "ask" || live["go test ./..."] != "allow" {
+		t.Errorf("in-memory bash rules = %v", live)
+	}
+}
+
+func TestAddProjectPermissionRuleCreatesFile(t *testing.T) {
+	dir := t.TempDir()
+	useConfig(t, &Config{WorkingDir: dir})
+
+	if err := AddProjectPermissionRule("webfetch", "example.com", "allow"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".opencode.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Permission PermissionConfig `json:"permission"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Permission.Rules["webfetch"].(map[string]any)["example.com"]; got != "allow" {
+		t.Errorf("webfetch rule = %v in %s", got, data)
+	}
+}
+
+func TestAddProjectContextPath(t *testing.T) {
+	dir := t.TempDir()
+	useConfig(t, &Config{WorkingDir: dir, ContextPaths: []ContextPath{
+		{Path: "AGENTS.md"},
+		{Path: ".cursor/rules/"},
+		{Path: "big.md", MaxTokens: -1},
+	}})
+
+	for _, covered := range []string{"AGENTS.md", "./AGENTS.md", ".cursor/rules/go.md"} {
+		added, err := AddProjectContextPath(covered)
+		if err != nil || added {
+			t.Fatalf("AddProjectContextPath(%q) = %v, %v; want not added", covered, added, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".opencode.json")); !os.IsNotExist(err) {
+		t.Fatalf("project file written for covered paths: %v", err)
+	}
+
+	added, err := AddProjectContextPath("docs/AGENTS.md")
+	if err != nil || !added {
+		t.Fatalf("AddProjectContextPath = %v, %v", added, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".opencode.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	// The list replaces the global one, so the loaded entries come along.
+	want := `["AGENTS.md",".cursor/rules/",{"maxTokens":-1,"path":"big.md"},"docs/AGENTS.md"]`
+	if got, _ := json.Marshal(doc["contextPaths"]); string(got) != want {
+		t.Errorf("contextPaths = %s, want %s", got, want)
+	}
+	if n := len(cfg.ContextPaths); n != 4 || cfg.ContextPaths[3].Path != "docs/AGENTS.md" {
+		t.Errorf("in-memory contextPaths = %+v", cfg.ContextPaths)
+	}
+
+	// A project list of its own is extended as is.
+	if err := os.WriteFile(filepath.Join(dir, ".opencode.json"), []byte(`{"contextPaths":["README.md"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddProjectContextPath("NOTES.md"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, ".opencode.json"))
+	if !strings.Contains(string(data), `"README.md",`+"\n"+`    "NOTES.md"`) {
+		t.Errorf("project file = %s", data)
+	}
+}
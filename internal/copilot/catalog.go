@@ -0,0 +1,135 @@
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// ModelInfo is a chat model from the Copilot catalog.
+type ModelInfo struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Vendor          string `json:"vendor,omitempty"`
+	ContextWindow   int64  `json:"context_window,omitempty"`
+	MaxOutputTokens int64  `json:"max_output_tokens,omitempty"`
+	SupportsVision  bool   `json:"supports_vision,omitempty"`
+	// SupportsReasoningEffort is set for models that take the OpenAI
+	// reasoning_effort parameter.
+	SupportsReasoningEffort bool `json:"supports_reasoning_effort,omitempty"`
+}
+
+type catalogResponse struct {
+	Data []struct {
+		ID                 string `json:"id"`
+		Name               string `json:"name"`
+		Vendor             string `json:"vendor"`
+		ModelPickerEnabled bool   `json:"model_picker_enabled"`
+		Capabilities       struct {
+			Type   string `json:"type"`
+			Limits struct {
+				MaxContextWindowTokens int64 `json:"max_context_window_tokens"`
+				MaxOutputTokens        int64 `json:"max_output_tokens"`
+				MaxPromptTokens        int64 `json:"max_prompt_tokens"`
+			} `json:"limits"`
+			Supports struct {
+				ToolCalls       bool `json:"tool_calls"`
+				Vision          bool `json:"vision"`
+				ReasoningEffort any  `json:"reasoning_effort"`
+			} `json:"supports"`
+		} `json:"capabilities"`
+		Policy *struct {
+			State string `json:"state"`
+		} `json:"policy"`
+	} `json:"data"`
+}
+
+// FetchModels lists the chat models the signed-in account can pick. Models
+// without tool calling are left out since agents cannot work without it.
+func FetchModels(ctx context.Context, tokens *TokenSource) ([]ModelInfo, error) {
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(token.Endpoint, "/")+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	req.Header.Set("Accept", "application/json")
+	for k, v := range Headers() {
+		req.Header.Set(k, v)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Copilot models: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list Copilot models: %s", res.Status)
+	}
+	var body catalogResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode Copilot models: %w", err)
+	}
+
+	var out []ModelInfo
+	for _, m := range body.Data {
+		c := m.Capabilities
+		if c.Type != "chat" || !c.Supports.ToolCalls || !m.ModelPickerEnabled {
+			continue
+		}
+		if m.Policy != nil && m.Policy.State == "disabled" {
+			continue
+		}
+		window := c.Limits.MaxContextWindowTokens
+		if window == 0 {
+			window = c.Limits.MaxPromptTokens + c.Limits.MaxOutputTokens
+		}
+		out = append(out, ModelInfo{
+			ID:                      m.ID,
+			Name:                    m.Name,
+			Vendor:                  m.Vendor,
+			ContextWindow:           window,
+			MaxOutputTokens:         c.Limits.MaxOutputTokens,
+			SupportsVision:          c.Supports.Vision,
+			SupportsReasoningEffort: c.Supports.ReasoningEffort != nil,
+		})
+	}
+	return out, nil
+}
+
+var refreshOnce sync.Once
+
+// RefreshStoredModels updates the catalog stored next to the token, once per
+// process, so models added to the subscription show up on the next start.
+// Failures are only logged.
+func RefreshStoredModels(tokens *TokenSource) {
+	refreshOnce.Do(func() {
+		go func() {
+			defer logging.RecoverPanic("copilot-catalog", nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			list, err := FetchModels(ctx, tokens)
+			if err != nil {
+				logging.Debug("Failed to refresh Copilot models", "error", err)
+				return
+			}
+			auth, err := LoadAuth()
+			if err != nil {
+				return
+			}
+			auth.Models = list
+			auth.UpdatedAt = time.Now().Unix()
+			if err := SaveAuth(auth); err != nil {
+				logging.Debug("Failed to store Copilot models", "error", err)
+			}
+		}()
+	})
+}
@@ -0,0 +1,270 @@
+// Package copilot signs in to GitHub Copilot with the OAuth device flow and
+// turns the stored GitHub token into the short-lived tokens the Copilot chat
+// API accepts.
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ClientID is the OAuth app GitHub's own editor integrations sign in
+	// with; Copilot only issues chat tokens to recognised integrations.
+	ClientID = "Iv1.b507a08c87ecfe98"
+
+	DefaultAPIEndpoint = "https://api.githubcopilot.com"
+
+	editorVersion       = "vscode/1.99.0"
+	editorPluginVersion = "copilot-chat/0.26.0"
+	integrationID       = "vscode-chat"
+)
+
+// Endpoints are variables so tests can point them at a local server.
+var (
+	deviceCodeURL  = "https://github.com/login/device/code"
+	accessTokenURL = "https://github.com/login/oauth/access_token"
+	copilotAuthURL = "https://api.github.com/copilot_internal/v2/token"
+
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// ErrNotSignedIn is returned when no GitHub token is stored.
+var ErrNotSignedIn = errors.New("not signed in to GitHub Copilot; run `opencode auth copilot`")
+
+// Headers are sent with every Copilot API request.
+func Headers() map[string]string {
+	return map[string]string{
+		"Editor-Version":         editorVersion,
+		"Editor-Plugin-Version":  editorPluginVersion,
+		"Copilot-Integration-Id": integrationID,
+	}
+}
+
+// Auth is what `opencode auth copilot` stores: the long-lived GitHub OAuth
+// token and the model catalog last fetched with it.
+type Auth struct {
+	OAuthToken string      `json:"oauth_token"`
+	Models     []ModelInfo `json:"models,omitempty"`
+	UpdatedAt  int64       `json:"updated_at,omitempty"`
+}
+
+// AuthPath is the file the GitHub token is stored in,
+// $XDG_DATA_HOME/opencode/copilot.json or ~/.local/share/opencode/copilot.json.
+func AuthPath() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "opencode", "copilot.json")
+}
+
+// LoadAuth reads the stored sign-in. It returns ErrNotSignedIn when there is
+// none.
+func LoadAuth() (Auth, error) {
+	var auth Auth
+	data, err := os.ReadFile(AuthPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return auth, ErrNotSignedIn
+	}
+	if err != nil {
+		return auth, err
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return auth, fmt.Errorf("invalid %s: %w", AuthPath(), err)
+	}
+	if auth.OAuthToken == "" {
+		return auth, ErrNotSignedIn
+	}
+	return auth, nil
+}
+
+// SaveAuth writes auth readable by the current user only.
+func SaveAuth(auth Auth) error {
+	path := AuthPath()
+	if path == "" {
+		return errors.New("cannot resolve home directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(auth, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// RemoveAuth signs out by deleting the stored token.
+func RemoveAuth() error {
+	err := os.Remove(AuthPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// DeviceCode is the code the user enters at VerificationURI.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// RequestDeviceCode starts the device flow.
+func RequestDeviceCode(ctx context.Context) (DeviceCode, error) {
+	var code DeviceCode
+	err := postForm(ctx, deviceCodeURL, url.Values{"client_id": {ClientID}, "scope": {"read:user"}}, &code)
+	if err == nil && code.DeviceCode == "" {
+		err = errors.New("GitHub returned no device code")
+	}
+	return code, err
+}
+
+// PollAccessToken waits until the user has entered code and returns the
+// GitHub OAuth token, or fails once the code expires or is denied.
+func PollAccessToken(ctx context.Context, code DeviceCode) (string, error) {
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+		var res struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+			Interval    int    `json:"interval"`
+		}
+		err := postForm(ctx, accessTokenURL, url.Values{
+			"client_id":   {ClientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &res)
+		if err != nil {
+			return "", err
+		}
+		switch res.Error {
+		case "":
+			if res.AccessToken != "" {
+				return res.AccessToken, nil
+			}
+		case "authorization_pending":
+		case "slow_down":
+			interval = time.Duration(max(res.Interval, int(interval/time.Second)+5)) * time.Second
+		default:
+			if res.Description != "" {
+				return "", fmt.Errorf("GitHub sign-in failed: %s", res.Description)
+			}
+			return "", fmt.Errorf("GitHub sign-in failed: %s", res.Error)
+		}
+		if code.ExpiresIn > 0 && time.Now().After(deadline) {
+			return "", errors.New("GitHub sign-in code expired")
+		}
+	}
+}
+
+func postForm(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// Token is a Copilot API token and the endpoint it is valid for.
+type Token struct {
+	Value     string
+	Endpoint  string
+	ExpiresAt time.Time
+}
+
+// TokenSource exchanges a GitHub OAuth token for Copilot API tokens and
+// renews them shortly before they expire.
+type TokenSource struct {
+	oauthToken string
+
+	mu    sync.Mutex
+	token Token
+}
+
+func NewTokenSource(oauthToken string) *TokenSource {
+	return &TokenSource{oauthToken: oauthToken}
+}
+
+// Token returns a valid Copilot API token.
+func (s *TokenSource) Token(ctx context.Context) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Value != "" && time.Until(s.token.ExpiresAt) > time.Minute {
+		return s.token, nil
+	}
+	if s.oauthToken == "" {
+		return Token{}, ErrNotSignedIn
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, copilotAuthURL, nil)
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Authorization", "token "+s.oauthToken)
+	req.Header.Set("Accept", "application/json")
+	for k, v := range Headers() {
+		req.Header.Set(k, v)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to get Copilot token: %w", err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return Token{}, errors.New("GitHub token was revoked; run `opencode auth copilot` again")
+	case http.StatusForbidden, http.StatusNotFound:
+		return Token{}, errors.New("this GitHub account has no Copilot subscription")
+	default:
+		return Token{}, fmt.Errorf("failed to get Copilot token: %s", res.Status)
+	}
+	var body struct {
+		Token     string `json:"token"`
+		ExpiresAt int64  `json:"expires_at"`
+		Endpoints struct {
+			API string `json:"api"`
+		} `json:"endpoints"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return Token{}, fmt.Errorf("failed to decode Copilot token: %w", err)
+	}
+	s.token = Token{Value: body.Token, Endpoint: body.Endpoints.API, ExpiresAt: time.Unix(body.ExpiresAt, 0)}
+	if s.token.Endpoint == "" {
+		s.token.Endpoint = DefaultAPIEndpoint
+	}
+	return s.token, nil
+}
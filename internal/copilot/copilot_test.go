@@ -0,0 +1,126 @@
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub serves the device flow, token exchange and catalog endpoints.
+func fakeGitHub(t *testing.T, pendingPolls int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var exchanges atomic.Int32
+	var polls atomic.Int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	mux.HandleFunc("POST /login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, ClientID, r.Form.Get("client_id"))
+		json.NewEncoder(w).Encode(map[string]any{
+			"device_code": "dev", "user_code": "ABCD-1234",
+			"verification_uri": "https://github.com/login/device", "expires_in": 60, "interval": 0,
+		})
+	})
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if int(polls.Add(1)) <= pendingPolls {
+			json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_token"})
+	})
+	mux.HandleFunc("GET /copilot_internal/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token gho_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		exchanges.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{
+			"token": "tid=copilot", "expires_at": time.Now().Add(30 * time.Minute).Unix(),
+			"endpoints": map[string]string{"api": srv.URL},
+		})
+	})
+	mux.HandleFunc("GET /models", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tid=copilot", r.Header.Get("Authorization"))
+		assert.Equal(t, integrationID, r.Header.Get("Copilot-Integration-Id"))
+		w.Write([]byte(`{"data":[
+			{"id":"gpt-4.1","name":"GPT-4.1","model_picker_enabled":true,
+			 "capabilities":{"type":"chat","limits":{"max_context_window_tokens":128000,"max_output_tokens":16384},"supports":{"tool_calls":true,"vision":true}}},
+			{"id":"o3","name":"o3","model_picker_enabled":true,
+			 "capabilities":{"type":"chat","limits":{"max_prompt_tokens":100000,"max_output_tokens":50000},"supports":{"tool_calls":true,"reasoning_effort":["low","high"]}}},
+			{"id":"text-embedding-3-small","model_picker_enabled":false,"capabilities":{"type":"embeddings"}},
+			{"id":"no-tools","model_picker_enabled":true,"capabilities":{"type":"chat","supports":{"tool_calls":false}}},
+			{"id":"blocked","model_picker_enabled":true,"policy":{"state":"disabled"},"capabilities":{"type":"chat","supports":{"tool_calls":true}}}
+		]}`))
+	})
+	t.Cleanup(srv.Close)
+
+	prev := []string{deviceCodeURL, accessTokenURL, copilotAuthURL}
+	deviceCodeURL = srv.URL + "/login/device/code"
+	accessTokenURL = srv.URL + "/login/oauth/access_token"
+	copilotAuthURL = srv.URL + "/copilot_internal/v2/token"
+	t.Cleanup(func() { deviceCodeURL, accessTokenURL, copilotAuthURL = prev[0], prev[1], prev[2] })
+	return srv, &exchanges
+}
+
+func TestDeviceFlow(t *testing.T) {
+	fakeGitHub(t, 1)
+	ctx := context.Background()
+	code, err := RequestDeviceCode(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-1234", code.UserCode)
+
+	token, err := PollAccessToken(ctx, code)
+	require.NoError(t, err)
+	assert.Equal(t, "gho_token", token)
+}
+
+func TestTokenSourceCachesToken(t *testing.T) {
+	srv, exchanges := fakeGitHub(t, 0)
+	tokens := NewTokenSource("gho_token")
+	for range 2 {
+		token, err := tokens.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "tid=copilot", token.Value)
+		assert.Equal(t, srv.URL, token.Endpoint)
+	}
+	assert.EqualValues(t, 1, exchanges.Load())
+
+	_, err := NewTokenSource("revoked").Token(context.Background())
+	assert.ErrorContains(t, err, "revoked")
+	_, err = NewTokenSource("").Token(context.Background())
+	assert.ErrorIs(t, err, ErrNotSignedIn)
+}
+
+func TestFetchModels(t *testing.T) {
+	fakeGitHub(t, 0)
+	list, err := FetchModels(context.Background(), NewTokenSource("gho_token"))
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, ModelInfo{ID: "gpt-4.1", Name: "GPT-4.1", ContextWindow: 128000, MaxOutputTokens: 16384, SupportsVision: true}, list[0])
+	assert.Equal(t, int64(150000), list[1].ContextWindow)
+	assert.True(t, list[1].SupportsReasoningEffort)
+}
+
+func TestAuthStore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	_, err := LoadAuth()
+	assert.ErrorIs(t, err, ErrNotSignedIn)
+
+	require.NoError(t, SaveAuth(Auth{OAuthToken: "gho_token", Models: []ModelInfo{{ID: "gpt-4.1"}}}))
+	auth, err := LoadAuth()
+	require.NoError(t, err)
+	assert.Equal(t, "gho_token", auth.OAuthToken)
+	assert.Len(t, auth.Models, 1)
+
+	require.NoError(t, RemoveAuth())
+	require.NoError(t, RemoveAuth())
+	_, err = LoadAuth()
+	assert.ErrorIs(t, err, ErrNotSignedIn)
+}
@@ -0,0 +1,342 @@
+// Package dataset turns stored sessions into JSONL datasets in the OpenAI
+// chat fine-tuning format, for building evals or fine-tunes from real
+// usage. Each session becomes one example: its user prompts, assistant
+// replies and tool calls with their results, in order. Tool-call structure
+// is kept as OpenAI tool_calls and tool messages; reasoning, attachments
+// and subagent sessions are left out. Every string is passed through a
+// Redactor before it is written. A thumbs up or down the user gave the
+// final reply overrides the heuristic success label.
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/feedback"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// Label classifies how a session ended.
+type Label string
+
+const (
+	// LabelSuccess sessions end with an assistant reply that finished
+	// its turn and does not directly follow a failed tool call.
+	LabelSuccess Label = "success"
+	// LabelFailure sessions end in an error, a cancellation, a permission
+	// denial, a truncated reply or a failed tool call.
+	LabelFailure Label = "failure"
+)
+
+// Filter selects the sessions to export. Zero fields match everything.
+type Filter struct {
+	// Agent keeps sessions in which the named agent made a request.
+	Agent string
+	Since time.Time
+	Until time.Time
+	Label Label
+}
+
+// Options shape the examples.
+type Options struct {
+	// System, when set, is prepended to every example as a system message.
+	System string
+	// Metadata adds a metadata object (session, title, agents, label,
+	// creation time, reply ratings) to every example. Useful for evals; fine-tuning
+	// endpoints may reject the extra key.
+	Metadata bool
+}
+
+// Example is one line of the dataset.
+type Example struct {
+	Messages []ChatMessage `json:"messages"`
+	Metadata *Metadata     `json:"metadata,omitempty"`
+}
+
+// ChatMessage is a message in the OpenAI chat format.
+type ChatMessage struct {
+	Role       string     `json:"role"`
+	Content    *string    `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type Metadata struct {
+	SessionID string   `json:"session_id"`
+	Title     string   `json:"title"`
+	Agents    []string `json:"agents,omitempty"`
+	Label     Label    `json:"label"`
+	CreatedAt string   `json:"created_at"`
+	Feedback  []Rating `json:"feedback,omitempty"`
+}
+
+// Rating is the user's feedback on one assistant reply.
+type Rating struct {
+	MessageID string          `json:"message_id"`
+	Rating    feedback.Rating `json:"rating"`
+	Comment   string          `json:"comment,omitempty"`
+	AgentID   string          `json:"agent_id,omitempty"`
+	Model     string          `json:"model,omitempty"`
+}
+
+// Exporter reads sessions, their messages and, when Feedback is set, the
+// ratings of their replies from the stores.
+type Exporter struct {
+	Sessions session.Service
+	Messages message.Service
+	Feedback feedback.Service
+	Redactor *Redactor
+}
+
+// Stats counts what Export wrote.
+type Stats struct {
+	Examples int
+	Skipped  int
+}
+
+// Export writes one JSONL line per session in ids that matches f. Sessions
+// with nothing to learn from (no user prompt or no assistant reply) are
+// skipped and counted.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, ids []string, f Filter, opts Options) (Stats, error) {
+	var stats Stats
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, id := range ids {
+		sess, err := e.Sessions.Get(ctx, id)
+		if err != nil {
+			return stats, fmt.Errorf("session %s: %w", id, err)
+		}
+		created := time.Unix(sess.CreatedAt, 0)
+		if (!f.Since.IsZero() && created.Before(f.Since)) || (!f.Until.IsZero() && created.After(f.Until)) {
+			continue
+		}
+		agents, err := e.agents(ctx, id)
+		if err != nil {
+			return stats, err
+		}
+		if f.Agent != "" && !slices.Contains(agents, f.Agent) {
+			continue
+		}
+		msgs, err := e.Messages.List(ctx, id)
+		if err != nil {
+			return stats, fmt.Errorf("messages of session %s: %w", id, err)
+		}
+		ratings, err := e.ratings(ctx, id)
+		if err != nil {
+			return stats, err
+		}
+		label := Classify(msgs)
+		if rated, ok := ratedLabel(msgs, ratings); ok {
+			label = rated
+		}
+		if f.Label != "" && label != f.Label {
+			continue
+		}
+		example, ok := e.convert(msgs, opts)
+		if !ok {
+			stats.Skipped++
+			continue
+		}
+		if opts.Metadata {
+			example.Metadata = &Metadata{
+				SessionID: sess.ID,
+				Title:     e.Redactor.Redact(sess.Title),
+				Agents:    agents,
+				Label:     label,
+				CreatedAt: created.UTC().Format(time.RFC3339),
+			}
+			for _, r := range ratings {
+				example.Metadata.Feedback = append(example.Metadata.Feedback, Rating{
+					MessageID: r.MessageID,
+					Rating:    r.Rating,
+					Comment:   e.Redactor.Redact(r.Comment),
+					AgentID:   r.AgentID,
+					Model:     r.Model,
+				})
+			}
+		}
+		if err := enc.Encode(example); err != nil {
+			return stats, err
+		}
+		stats.Examples++
+	}
+	return stats, nil
+}
+
+// agents lists the agents that made requests in the session's tree.
+func (e *Exporter) agents(ctx context.Context, id string) ([]string, error) {
+	entries, err := e.Sessions.UsageBreakdown(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("usage of session %s: %w", id, err)
+	}
+	var agents []string
+	for _, u := range entries {
+		if u.Category == session.UsageAgent && !slices.Contains(agents, u.Name) {
+			agents = append(agents, u.Name)
+		}
+	}
+	slices.Sort(agents)
+	return agents, nil
+}
+
+// ratings lists the feedback given in the session, if a store is set.
+func (e *Exporter) ratings(ctx context.Context, id string) ([]feedback.Feedback, error) {
+	if e.Feedback == nil {
+		return nil, nil
+	}
+	ratings, err := e.Feedback.List(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("feedback of session %s: %w", id, err)
+	}
+	return ratings, nil
+}
+
+// ratedLabel returns the label the user gave the conversation's last
+// assistant reply, if they rated it.
+func ratedLabel(msgs []message.Message, ratings []feedback.Feedback) (Label, bool) {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != message.Assistant {
+			continue
+		}
+		for _, r := range ratings {
+			if r.MessageID != msgs[i].ID {
+				continue
+			}
+			if r.Rating == feedback.Up {
+				return LabelSuccess, true
+			}
+			return LabelFailure, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// Classify labels a conversation by how it ended.
+func Classify(msgs []message.Message) Label {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		m := msgs[i]
+		switch m.Role {
+		case message.User, message.Tool:
+			// The last prompt or tool result got no reply.
+			return LabelFailure
+		case message.Assistant:
+			if m.FinishReason() != message.FinishReasonEndTurn {
+				return LabelFailure
+			}
+			// A final reply straight after a failed call usually reports
+			// the failure rather than a result.
+			if i > 0 && msgs[i-1].Role == message.Tool {
+				for _, r := range msgs[i-1].ToolResults() {
+					if r.IsError {
+						return LabelFailure
+					}
+				}
+			}
+			return LabelSuccess
+		}
+	}
+	return LabelFailure
+}
+
+// convert builds the example. Tool calls without a result, and results
+// without a call, are dropped: the format pairs every call with a result.
+func (e *Exporter) convert(msgs []message.Message, opts Options) (Example, bool) {
+	answered := map[string]bool{}
+	for _, m := range msgs {
+		for _, r := range m.ToolResults() {
+			answered[r.ToolCallID] = true
+		}
+	}
+
+	var ex Example
+	if opts.System != "" {
+		ex.Messages = append(ex.Messages, ChatMessage{Role: "system", Content: ptr(opts.System)})
+	}
+	emitted := map[string]bool{}
+	var hasUser, hasAssistant bool
+	for _, m := range msgs {
+		switch m.Role {
+		case message.User:
+			text := strings.TrimSpace(m.Content().Text)
+			if text == "" {
+				continue
+			}
+			ex.Messages = append(ex.Messages, ChatMessage{Role: "user", Content: ptr(e.Redactor.Redact(text))})
+			hasUser = true
+		case message.Assistant:
+			cm := ChatMessage{Role: "assistant"}
+			if text := m.Content().Text; strings.TrimSpace(text) != "" {
+				cm.Content = ptr(e.Redactor.Redact(text))
+			}
+			for _, call := range m.ToolCalls() {
+				if !answered[call.ID] {
+					continue
+				}
+				emitted[call.ID] = true
+				args := call.Input
+				if args == "" {
+					args = "{}"
+				}
+				cm.ToolCalls = append(cm.ToolCalls, ToolCall{
+					ID:       call.ID,
+					Type:     "function",
+					Function: FunctionCall{Name: call.Name, Arguments: e.Redactor.Redact(args)},
+				})
+			}
+			if cm.Content == nil && len(cm.ToolCalls) == 0 {
+				continue
+			}
+			ex.Messages = append(ex.Messages, cm)
+			hasAssistant = true
+		case message.Tool:
+			for _, r := range m.ToolResults() {
+				if !emitted[r.ToolCallID] {
+					continue
+				}
+				content := r.Content
+				if r.IsImageToolResponse() {
+					content = "[image]"
+				}
+				ex.Messages = append(ex.Messages, ChatMessage{
+					Role:       "tool",
+					Content:    ptr(e.Redactor.Redact(content)),
+					ToolCallID: r.ToolCallID,
+				})
+			}
+		}
+	}
+	return ex, hasUser && hasAssistant
+}
+
+func ptr(s string) *string { return &s }
+
+// ParseLabel validates a --label value; "" and "any" match every session.
+func ParseLabel(s string) (Label, error) {
+	switch strings.ToLower(s) {
+	case "", "any":
+		return "", nil
+	case string(LabelSuccess):
+		return LabelSuccess, nil
+	case string(LabelFailure):
+		return LabelFailure, nil
+	}
+	return "", fmt.Errorf("unknown label %q (want success, failure or any)", s)
+}
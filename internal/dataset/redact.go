@@ -0,0 +1,108 @@
+package dataset
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// Redacted replaces every secret found in exported text.
+const Redacted = "[REDACTED]"
+
+// minSecretLen keeps short environment values ("1", "true", a port) from
+// being treated as secrets and blanking out unrelated text.
+const minSecretLen = 8
+
+// secretPatterns match credential shapes that are recognisable without
+// knowing the value: provider API keys, cloud and VCS tokens, private key
+// blocks and bearer headers. Patterns never match quotes or backslashes,
+// so redacting inside a JSON string keeps it valid.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{30,}`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{40,}`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),
+	regexp.MustCompile(`(?i)\bBearer\s+[A-Za-z0-9._~+/-]{16,}=*`),
+}
+
+// assignmentPattern matches NAME=value and "name": "value" pairs whose
+// name says the value is a secret; only the value is replaced.
+var assignmentPattern = regexp.MustCompile(`(?i)\b([A-Z0-9_]*(?:api[_-]?key|secret|token|password|passwd)[A-Z0-9_]*)(\\?"?\s*[:=]\s*\\?"?)([^\s"'\\,}]{8,})`)
+
+// secretEnvNames are the substrings that mark an environment variable as
+// holding a secret.
+var secretEnvNames = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL"}
+
+// Redactor removes secrets from text: known values (configured API keys,
+// secret-looking environment variables) and common credential shapes.
+type Redactor struct {
+	values []string
+}
+
+// NewRedactor returns a redactor for the given known secret values in
+// addition to the built-in patterns. Values shorter than eight bytes are
+// ignored.
+func NewRedactor(values ...string) *Redactor {
+	r := &Redactor{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLen || seen[v] {
+			continue
+		}
+		seen[v] = true
+		r.values = append(r.values, v)
+	}
+	// Longest first, so a secret that contains another is replaced whole.
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+	return r
+}
+
+// EnvironmentSecrets returns the values of the environment variables and
+// the provider API keys in cfg that look like secrets.
+func EnvironmentSecrets(cfg *config.Config) []string {
+	var values []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(name)
+		for _, marker := range secretEnvNames {
+			if strings.Contains(upper, marker) {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+	if cfg != nil {
+		for _, p := range cfg.Providers {
+			values = append(values, p.APIKey)
+		}
+	}
+	return values
+}
+
+// Redact returns s with every secret replaced by Redacted.
+func (r *Redactor) Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Redacted)
+	}
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, Redacted)
+	}
+	return assignmentPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := assignmentPattern.FindStringSubmatch(m)
+		if sub[3] == Redacted {
+			return m
+		}
+		return sub[1] + sub[2] + Redacted
+	})
+}
@@ -27,9 +27,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.addBridgeAllowlistEntryStmt, err = db.PrepareContext(ctx, addBridgeAllowlistEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query AddBridgeAllowlistEntry: %w", err)
 	}
+	if q.addSessionUsageStmt, err = db.PrepareContext(ctx, addSessionUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query AddSessionUsage: %w", err)
+	}
 	if q.claimCronJobForFiringStmt, err = db.PrepareContext(ctx, claimCronJobForFiring); err != nil {
 		return nil, fmt.Errorf("error preparing query ClaimCronJobForFiring: %w", err)
 	}
+	if q.claimQueuedRunStmt, err = db.PrepareContext(ctx, claimQueuedRun); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimQueuedRun: %w", err)
+	}
 	if q.clearStaleFiringStmt, err = db.PrepareContext(ctx, clearStaleFiring); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearStaleFiring: %w", err)
 	}
@@ -39,21 +45,48 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countBridgeSessionsByIdentityStmt, err = db.PrepareContext(ctx, countBridgeSessionsByIdentity); err != nil {
 		return nil, fmt.Errorf("error preparing query CountBridgeSessionsByIdentity: %w", err)
 	}
+	if q.countRunningFlowScheduleRunsStmt, err = db.PrepareContext(ctx, countRunningFlowScheduleRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query CountRunningFlowScheduleRuns: %w", err)
+	}
+	if q.createBlackboardEntryStmt, err = db.PrepareContext(ctx, createBlackboardEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBlackboardEntry: %w", err)
+	}
+	if q.createCheckpointStmt, err = db.PrepareContext(ctx, createCheckpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCheckpoint: %w", err)
+	}
+	if q.createCodeChunkStmt, err = db.PrepareContext(ctx, createCodeChunk); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCodeChunk: %w", err)
+	}
 	if q.createCronJobStmt, err = db.PrepareContext(ctx, createCronJob); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateCronJob: %w", err)
 	}
 	if q.createFileStmt, err = db.PrepareContext(ctx, createFile); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFile: %w", err)
 	}
+	if q.createFlowScheduleRunStmt, err = db.PrepareContext(ctx, createFlowScheduleRun); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFlowScheduleRun: %w", err)
+	}
 	if q.createFlowStateStmt, err = db.PrepareContext(ctx, createFlowState); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFlowState: %w", err)
 	}
+	if q.createMemoryStmt, err = db.PrepareContext(ctx, createMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMemory: %w", err)
+	}
 	if q.createMessageStmt, err = db.PrepareContext(ctx, createMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMessage: %w", err)
 	}
+	if q.createMessageUsageStmt, err = db.PrepareContext(ctx, createMessageUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMessageUsage: %w", err)
+	}
+	if q.createQueuedRunStmt, err = db.PrepareContext(ctx, createQueuedRun); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateQueuedRun: %w", err)
+	}
 	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
 	}
+	if q.createToolExecutionStmt, err = db.PrepareContext(ctx, createToolExecution); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateToolExecution: %w", err)
+	}
 	if q.deleteBridgeSessionByPeerStmt, err = db.PrepareContext(ctx, deleteBridgeSessionByPeer); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteBridgeSessionByPeer: %w", err)
 	}
@@ -63,6 +96,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteBridgeSessionsBySessionStmt, err = db.PrepareContext(ctx, deleteBridgeSessionsBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteBridgeSessionsBySession: %w", err)
 	}
+	if q.deleteCheckpointStmt, err = db.PrepareContext(ctx, deleteCheckpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCheckpoint: %w", err)
+	}
+	if q.deleteCodeChunksForPathStmt, err = db.PrepareContext(ctx, deleteCodeChunksForPath); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCodeChunksForPath: %w", err)
+	}
+	if q.deleteCodeChunksForProjectStmt, err = db.PrepareContext(ctx, deleteCodeChunksForProject); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCodeChunksForProject: %w", err)
+	}
 	if q.deleteCronJobStmt, err = db.PrepareContext(ctx, deleteCronJob); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteCronJob: %w", err)
 	}
@@ -72,12 +114,21 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteFlowStatesByRootSessionStmt, err = db.PrepareContext(ctx, deleteFlowStatesByRootSession); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteFlowStatesByRootSession: %w", err)
 	}
+	if q.deleteMemoryStmt, err = db.PrepareContext(ctx, deleteMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMemory: %w", err)
+	}
 	if q.deleteMessageStmt, err = db.PrepareContext(ctx, deleteMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMessage: %w", err)
 	}
+	if q.deleteMessageFeedbackStmt, err = db.PrepareContext(ctx, deleteMessageFeedback); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMessageFeedback: %w", err)
+	}
 	if q.deleteRecapBySessionIDStmt, err = db.PrepareContext(ctx, deleteRecapBySessionID); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteRecapBySessionID: %w", err)
 	}
+	if q.deleteRuntimeSnapshotStmt, err = db.PrepareContext(ctx, deleteRuntimeSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteRuntimeSnapshot: %w", err)
+	}
 	if q.deleteSessionStmt, err = db.PrepareContext(ctx, deleteSession); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSession: %w", err)
 	}
@@ -90,6 +141,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteSessionTreeStmt, err = db.PrepareContext(ctx, deleteSessionTree); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSessionTree: %w", err)
 	}
+	if q.failRunningFlowScheduleRunsStmt, err = db.PrepareContext(ctx, failRunningFlowScheduleRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query FailRunningFlowScheduleRuns: %w", err)
+	}
+	if q.finishFlowScheduleRunStmt, err = db.PrepareContext(ctx, finishFlowScheduleRun); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishFlowScheduleRun: %w", err)
+	}
+	if q.finishQueuedRunStmt, err = db.PrepareContext(ctx, finishQueuedRun); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishQueuedRun: %w", err)
+	}
+	if q.getBlackboardEntryStmt, err = db.PrepareContext(ctx, getBlackboardEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlackboardEntry: %w", err)
+	}
 	if q.getBridgeSessionStmt, err = db.PrepareContext(ctx, getBridgeSession); err != nil {
 		return nil, fmt.Errorf("error preparing query GetBridgeSession: %w", err)
 	}
@@ -108,12 +171,30 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getMaxSeqBySessionStmt, err = db.PrepareContext(ctx, getMaxSeqBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMaxSeqBySession: %w", err)
 	}
+	if q.getMemoryByNameStmt, err = db.PrepareContext(ctx, getMemoryByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMemoryByName: %w", err)
+	}
+	if q.getMemoryStmt, err = db.PrepareContext(ctx, getMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMemory: %w", err)
+	}
 	if q.getMessageStmt, err = db.PrepareContext(ctx, getMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMessage: %w", err)
 	}
+	if q.getMessageFeedbackStmt, err = db.PrepareContext(ctx, getMessageFeedback); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMessageFeedback: %w", err)
+	}
+	if q.getQueuedRunStmt, err = db.PrepareContext(ctx, getQueuedRun); err != nil {
+		return nil, fmt.Errorf("error preparing query GetQueuedRun: %w", err)
+	}
 	if q.getRecapBySessionIDStmt, err = db.PrepareContext(ctx, getRecapBySessionID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetRecapBySessionID: %w", err)
 	}
+	if q.getProjectUsageStmt, err = db.PrepareContext(ctx, getProjectUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProjectUsage: %w", err)
+	}
+	if q.getRuntimeSnapshotStmt, err = db.PrepareContext(ctx, getRuntimeSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRuntimeSnapshot: %w", err)
+	}
 	if q.getSessionByIDStmt, err = db.PrepareContext(ctx, getSessionByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSessionByID: %w", err)
 	}
@@ -123,6 +204,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listActiveCronJobsStmt, err = db.PrepareContext(ctx, listActiveCronJobs); err != nil {
 		return nil, fmt.Errorf("error preparing query ListActiveCronJobs: %w", err)
 	}
+	if q.listBlackboardEntriesStmt, err = db.PrepareContext(ctx, listBlackboardEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBlackboardEntries: %w", err)
+	}
 	if q.listBridgeAllowlistStmt, err = db.PrepareContext(ctx, listBridgeAllowlist); err != nil {
 		return nil, fmt.Errorf("error preparing query ListBridgeAllowlist: %w", err)
 	}
@@ -132,9 +216,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listBridgeSessionsBySessionStmt, err = db.PrepareContext(ctx, listBridgeSessionsBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query ListBridgeSessionsBySession: %w", err)
 	}
+	if q.listCheckpointsSinceMessageStmt, err = db.PrepareContext(ctx, listCheckpointsSinceMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCheckpointsSinceMessage: %w", err)
+	}
 	if q.listChildSessionsStmt, err = db.PrepareContext(ctx, listChildSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListChildSessions: %w", err)
 	}
+	if q.listCodeChunkFilesStmt, err = db.PrepareContext(ctx, listCodeChunkFiles); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCodeChunkFiles: %w", err)
+	}
+	if q.listCodeChunksStmt, err = db.PrepareContext(ctx, listCodeChunks); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCodeChunks: %w", err)
+	}
 	if q.listCronJobsBySessionStmt, err = db.PrepareContext(ctx, listCronJobsBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query ListCronJobsBySession: %w", err)
 	}
@@ -150,6 +243,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listFilesBySessionTreeStmt, err = db.PrepareContext(ctx, listFilesBySessionTree); err != nil {
 		return nil, fmt.Errorf("error preparing query ListFilesBySessionTree: %w", err)
 	}
+	if q.listFlowScheduleRunsStmt, err = db.PrepareContext(ctx, listFlowScheduleRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFlowScheduleRuns: %w", err)
+	}
 	if q.listFlowStatesByFlowIDStmt, err = db.PrepareContext(ctx, listFlowStatesByFlowID); err != nil {
 		return nil, fmt.Errorf("error preparing query ListFlowStatesByFlowID: %w", err)
 	}
@@ -165,15 +261,42 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listLatestSessionTreeFilesStmt, err = db.PrepareContext(ctx, listLatestSessionTreeFiles); err != nil {
 		return nil, fmt.Errorf("error preparing query ListLatestSessionTreeFiles: %w", err)
 	}
+	if q.listMemoriesStmt, err = db.PrepareContext(ctx, listMemories); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMemories: %w", err)
+	}
+	if q.listMessageFeedbackStmt, err = db.PrepareContext(ctx, listMessageFeedback); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMessageFeedback: %w", err)
+	}
+	if q.listMessageUsageStmt, err = db.PrepareContext(ctx, listMessageUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMessageUsage: %w", err)
+	}
+	if q.listMessagesBeforeStmt, err = db.PrepareContext(ctx, listMessagesBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMessagesBefore: %w", err)
+	}
 	if q.listMessagesBySessionStmt, err = db.PrepareContext(ctx, listMessagesBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query ListMessagesBySession: %w", err)
 	}
+	if q.listMessagesFromStmt, err = db.PrepareContext(ctx, listMessagesFrom); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMessagesFrom: %w", err)
+	}
 	if q.listMissedOneShotsStmt, err = db.PrepareContext(ctx, listMissedOneShots); err != nil {
 		return nil, fmt.Errorf("error preparing query ListMissedOneShots: %w", err)
 	}
+	if q.listPendingQueuedRunsStmt, err = db.PrepareContext(ctx, listPendingQueuedRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPendingQueuedRuns: %w", err)
+	}
+	if q.listQueuedRunsStmt, err = db.PrepareContext(ctx, listQueuedRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListQueuedRuns: %w", err)
+	}
+	if q.listSessionUsageStmt, err = db.PrepareContext(ctx, listSessionUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSessionUsage: %w", err)
+	}
 	if q.listSessionsStmt, err = db.PrepareContext(ctx, listSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSessions: %w", err)
 	}
+	if q.listToolExecutionsStmt, err = db.PrepareContext(ctx, listToolExecutions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListToolExecutions: %w", err)
+	}
 	if q.markBridgeSessionMentionConsumedStmt, err = db.PrepareContext(ctx, markBridgeSessionMentionConsumed); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkBridgeSessionMentionConsumed: %w", err)
 	}
@@ -183,12 +306,21 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.renameSessionStmt, err = db.PrepareContext(ctx, renameSession); err != nil {
 		return nil, fmt.Errorf("error preparing query RenameSession: %w", err)
 	}
+	if q.requeueRunningRunsStmt, err = db.PrepareContext(ctx, requeueRunningRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query RequeueRunningRuns: %w", err)
+	}
 	if q.setCronJobFiringStmt, err = db.PrepareContext(ctx, setCronJobFiring); err != nil {
 		return nil, fmt.Errorf("error preparing query SetCronJobFiring: %w", err)
 	}
+	if q.setFlowStateApprovalStmt, err = db.PrepareContext(ctx, setFlowStateApproval); err != nil {
+		return nil, fmt.Errorf("error preparing query SetFlowStateApproval: %w", err)
+	}
 	if q.setGeneratedTitleStmt, err = db.PrepareContext(ctx, setGeneratedTitle); err != nil {
 		return nil, fmt.Errorf("error preparing query SetGeneratedTitle: %w", err)
 	}
+	if q.supersedeMessageStmt, err = db.PrepareContext(ctx, supersedeMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query SupersedeMessage: %w", err)
+	}
 	if q.updateBridgeSessionPeerIDStmt, err = db.PrepareContext(ctx, updateBridgeSessionPeerID); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateBridgeSessionPeerID: %w", err)
 	}
@@ -213,6 +345,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateFlowStateStmt, err = db.PrepareContext(ctx, updateFlowState); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateFlowState: %w", err)
 	}
+	if q.updateMemoryStmt, err = db.PrepareContext(ctx, updateMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMemory: %w", err)
+	}
 	if q.updateMessageStmt, err = db.PrepareContext(ctx, updateMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateMessage: %w", err)
 	}
@@ -222,9 +357,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.upsertBridgeSessionStmt, err = db.PrepareContext(ctx, upsertBridgeSession); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertBridgeSession: %w", err)
 	}
+	if q.upsertMessageFeedbackStmt, err = db.PrepareContext(ctx, upsertMessageFeedback); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertMessageFeedback: %w", err)
+	}
 	if q.upsertRecapStmt, err = db.PrepareContext(ctx, upsertRecap); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertRecap: %w", err)
 	}
+	if q.upsertRuntimeSnapshotStmt, err = db.PrepareContext(ctx, upsertRuntimeSnapshot); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertRuntimeSnapshot: %w", err)
+	}
 	return &q, nil
 }
 
@@ -235,11 +376,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing addBridgeAllowlistEntryStmt: %w", cerr)
 		}
 	}
+	if q.addSessionUsageStmt != nil {
+		if cerr := q.addSessionUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addSessionUsageStmt: %w", cerr)
+		}
+	}
 	if q.claimCronJobForFiringStmt != nil {
 		if cerr := q.claimCronJobForFiringStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing claimCronJobForFiringStmt: %w", cerr)
 		}
 	}
+	if q.claimQueuedRunStmt != nil {
+		if cerr := q.claimQueuedRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimQueuedRunStmt: %w", cerr)
+		}
+	}
 	if q.clearStaleFiringStmt != nil {
 		if cerr := q.clearStaleFiringStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearStaleFiringStmt: %w", cerr)
@@ -255,6 +406,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countBridgeSessionsByIdentityStmt: %w", cerr)
 		}
 	}
+	if q.countRunningFlowScheduleRunsStmt != nil {
+		if cerr := q.countRunningFlowScheduleRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countRunningFlowScheduleRunsStmt: %w", cerr)
+		}
+	}
+	if q.createBlackboardEntryStmt != nil {
+		if cerr := q.createBlackboardEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBlackboardEntryStmt: %w", cerr)
+		}
+	}
+	if q.createCheckpointStmt != nil {
+		if cerr := q.createCheckpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCheckpointStmt: %w", cerr)
+		}
+	}
+	if q.createCodeChunkStmt != nil {
+		if cerr := q.createCodeChunkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCodeChunkStmt: %w", cerr)
+		}
+	}
 	if q.createCronJobStmt != nil {
 		if cerr := q.createCronJobStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createCronJobStmt: %w", cerr)
@@ -265,21 +436,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createFileStmt: %w", cerr)
 		}
 	}
+	if q.createFlowScheduleRunStmt != nil {
+		if cerr := q.createFlowScheduleRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFlowScheduleRunStmt: %w", cerr)
+		}
+	}
 	if q.createFlowStateStmt != nil {
 		if cerr := q.createFlowStateStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createFlowStateStmt: %w", cerr)
 		}
 	}
+	if q.createMemoryStmt != nil {
+		if cerr := q.createMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMemoryStmt: %w", cerr)
+		}
+	}
 	if q.createMessageStmt != nil {
 		if cerr := q.createMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createMessageStmt: %w", cerr)
 		}
 	}
+	if q.createMessageUsageStmt != nil {
+		if cerr := q.createMessageUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMessageUsageStmt: %w", cerr)
+		}
+	}
+	if q.createQueuedRunStmt != nil {
+		if cerr := q.createQueuedRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createQueuedRunStmt: %w", cerr)
+		}
+	}
 	if q.createSessionStmt != nil {
 		if cerr := q.createSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
 		}
 	}
+	if q.createToolExecutionStmt != nil {
+		if cerr := q.createToolExecutionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createToolExecutionStmt: %w", cerr)
+		}
+	}
 	if q.deleteBridgeSessionByPeerStmt != nil {
 		if cerr := q.deleteBridgeSessionByPeerStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteBridgeSessionByPeerStmt: %w", cerr)
@@ -295,6 +491,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteBridgeSessionsBySessionStmt: %w", cerr)
 		}
 	}
+	if q.deleteCheckpointStmt != nil {
+		if cerr := q.deleteCheckpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCheckpointStmt: %w", cerr)
+		}
+	}
+	if q.deleteCodeChunksForPathStmt != nil {
+		if cerr := q.deleteCodeChunksForPathStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCodeChunksForPathStmt: %w", cerr)
+		}
+	}
+	if q.deleteCodeChunksForProjectStmt != nil {
+		if cerr := q.deleteCodeChunksForProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCodeChunksForProjectStmt: %w", cerr)
+		}
+	}
 	if q.deleteCronJobStmt != nil {
 		if cerr := q.deleteCronJobStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteCronJobStmt: %w", cerr)
@@ -310,16 +521,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteFlowStatesByRootSessionStmt: %w", cerr)
 		}
 	}
+	if q.deleteMemoryStmt != nil {
+		if cerr := q.deleteMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMemoryStmt: %w", cerr)
+		}
+	}
 	if q.deleteMessageStmt != nil {
 		if cerr := q.deleteMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteMessageStmt: %w", cerr)
 		}
 	}
+	if q.deleteMessageFeedbackStmt != nil {
+		if cerr := q.deleteMessageFeedbackStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMessageFeedbackStmt: %w", cerr)
+		}
+	}
 	if q.deleteRecapBySessionIDStmt != nil {
 		if cerr := q.deleteRecapBySessionIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteRecapBySessionIDStmt: %w", cerr)
 		}
 	}
+	if q.deleteRuntimeSnapshotStmt != nil {
+		if cerr := q.deleteRuntimeSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteRuntimeSnapshotStmt: %w", cerr)
+		}
+	}
 	if q.deleteSessionStmt != nil {
 		if cerr := q.deleteSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteSessionStmt: %w", cerr)
@@ -340,6 +566,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteSessionTreeStmt: %w", cerr)
 		}
 	}
+	if q.failRunningFlowScheduleRunsStmt != nil {
+		if cerr := q.failRunningFlowScheduleRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failRunningFlowScheduleRunsStmt: %w", cerr)
+		}
+	}
+	if q.finishFlowScheduleRunStmt != nil {
+		if cerr := q.finishFlowScheduleRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishFlowScheduleRunStmt: %w", cerr)
+		}
+	}
+	if q.finishQueuedRunStmt != nil {
+		if cerr := q.finishQueuedRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishQueuedRunStmt: %w", cerr)
+		}
+	}
+	if q.getBlackboardEntryStmt != nil {
+		if cerr := q.getBlackboardEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlackboardEntryStmt: %w", cerr)
+		}
+	}
 	if q.getBridgeSessionStmt != nil {
 		if cerr := q.getBridgeSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getBridgeSessionStmt: %w", cerr)
@@ -370,16 +616,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getMaxSeqBySessionStmt: %w", cerr)
 		}
 	}
+	if q.getMemoryByNameStmt != nil {
+		if cerr := q.getMemoryByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMemoryByNameStmt: %w", cerr)
+		}
+	}
+	if q.getMemoryStmt != nil {
+		if cerr := q.getMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMemoryStmt: %w", cerr)
+		}
+	}
 	if q.getMessageStmt != nil {
 		if cerr := q.getMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMessageStmt: %w", cerr)
 		}
 	}
+	if q.getMessageFeedbackStmt != nil {
+		if cerr := q.getMessageFeedbackStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMessageFeedbackStmt: %w", cerr)
+		}
+	}
+	if q.getQueuedRunStmt != nil {
+		if cerr := q.getQueuedRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getQueuedRunStmt: %w", cerr)
+		}
+	}
 	if q.getRecapBySessionIDStmt != nil {
 		if cerr := q.getRecapBySessionIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getRecapBySessionIDStmt: %w", cerr)
 		}
 	}
+	if q.getProjectUsageStmt != nil {
+		if cerr := q.getProjectUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProjectUsageStmt: %w", cerr)
+		}
+	}
+	if q.getRuntimeSnapshotStmt != nil {
+		if cerr := q.getRuntimeSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRuntimeSnapshotStmt: %w", cerr)
+		}
+	}
 	if q.getSessionByIDStmt != nil {
 		if cerr := q.getSessionByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getSessionByIDStmt: %w", cerr)
@@ -395,6 +671,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listActiveCronJobsStmt: %w", cerr)
 		}
 	}
+	if q.listBlackboardEntriesStmt != nil {
+		if cerr := q.listBlackboardEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBlackboardEntriesStmt: %w", cerr)
+		}
+	}
 	if q.listBridgeAllowlistStmt != nil {
 		if cerr := q.listBridgeAllowlistStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listBridgeAllowlistStmt: %w", cerr)
@@ -410,11 +691,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listBridgeSessionsBySessionStmt: %w", cerr)
 		}
 	}
+	if q.listCheckpointsSinceMessageStmt != nil {
+		if cerr := q.listCheckpointsSinceMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCheckpointsSinceMessageStmt: %w", cerr)
+		}
+	}
 	if q.listChildSessionsStmt != nil {
 		if cerr := q.listChildSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listChildSessionsStmt: %w", cerr)
 		}
 	}
+	if q.listCodeChunkFilesStmt != nil {
+		if cerr := q.listCodeChunkFilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCodeChunkFilesStmt: %w", cerr)
+		}
+	}
+	if q.listCodeChunksStmt != nil {
+		if cerr := q.listCodeChunksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCodeChunksStmt: %w", cerr)
+		}
+	}
 	if q.listCronJobsBySessionStmt != nil {
 		if cerr := q.listCronJobsBySessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listCronJobsBySessionStmt: %w", cerr)
@@ -440,6 +736,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listFilesBySessionTreeStmt: %w", cerr)
 		}
 	}
+	if q.listFlowScheduleRunsStmt != nil {
+		if cerr := q.listFlowScheduleRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFlowScheduleRunsStmt: %w", cerr)
+		}
+	}
 	if q.listFlowStatesByFlowIDStmt != nil {
 		if cerr := q.listFlowStatesByFlowIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listFlowStatesByFlowIDStmt: %w", cerr)
@@ -465,21 +766,66 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listLatestSessionTreeFilesStmt: %w", cerr)
 		}
 	}
+	if q.listMemoriesStmt != nil {
+		if cerr := q.listMemoriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMemoriesStmt: %w", cerr)
+		}
+	}
+	if q.listMessageFeedbackStmt != nil {
+		if cerr := q.listMessageFeedbackStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMessageFeedbackStmt: %w", cerr)
+		}
+	}
+	if q.listMessageUsageStmt != nil {
+		if cerr := q.listMessageUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMessageUsageStmt: %w", cerr)
+		}
+	}
+	if q.listMessagesBeforeStmt != nil {
+		if cerr := q.listMessagesBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMessagesBeforeStmt: %w", cerr)
+		}
+	}
 	if q.listMessagesBySessionStmt != nil {
 		if cerr := q.listMessagesBySessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listMessagesBySessionStmt: %w", cerr)
 		}
 	}
+	if q.listMessagesFromStmt != nil {
+		if cerr := q.listMessagesFromStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMessagesFromStmt: %w", cerr)
+		}
+	}
 	if q.listMissedOneShotsStmt != nil {
 		if cerr := q.listMissedOneShotsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listMissedOneShotsStmt: %w", cerr)
 		}
 	}
+	if q.listPendingQueuedRunsStmt != nil {
+		if cerr := q.listPendingQueuedRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPendingQueuedRunsStmt: %w", cerr)
+		}
+	}
+	if q.listQueuedRunsStmt != nil {
+		if cerr := q.listQueuedRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listQueuedRunsStmt: %w", cerr)
+		}
+	}
+	if q.listSessionUsageStmt != nil {
+		if cerr := q.listSessionUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSessionUsageStmt: %w", cerr)
+		}
+	}
 	if q.listSessionsStmt != nil {
 		if cerr := q.listSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listSessionsStmt: %w", cerr)
 		}
 	}
+	if q.listToolExecutionsStmt != nil {
+		if cerr := q.listToolExecutionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listToolExecutionsStmt: %w", cerr)
+		}
+	}
 	if q.markBridgeSessionMentionConsumedStmt != nil {
 		if cerr := q.markBridgeSessionMentionConsumedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markBridgeSessionMentionConsumedStmt: %w", cerr)
@@ -495,16 +841,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing renameSessionStmt: %w", cerr)
 		}
 	}
+	if q.requeueRunningRunsStmt != nil {
+		if cerr := q.requeueRunningRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requeueRunningRunsStmt: %w", cerr)
+		}
+	}
 	if q.setCronJobFiringStmt != nil {
 		if cerr := q.setCronJobFiringStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing setCronJobFiringStmt: %w", cerr)
 		}
 	}
+	if q.setFlowStateApprovalStmt != nil {
+		if cerr := q.setFlowStateApprovalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setFlowStateApprovalStmt: %w", cerr)
+		}
+	}
 	if q.setGeneratedTitleStmt != nil {
 		if cerr := q.setGeneratedTitleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing setGeneratedTitleStmt: %w", cerr)
 		}
 	}
+	if q.supersedeMessageStmt != nil {
+		if cerr := q.supersedeMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing supersedeMessageStmt: %w", cerr)
+		}
+	}
 	if q.updateBridgeSessionPeerIDStmt != nil {
 		if cerr := q.updateBridgeSessionPeerIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateBridgeSessionPeerIDStmt: %w", cerr)
@@ -545,6 +906,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateFlowStateStmt: %w", cerr)
 		}
 	}
+	if q.updateMemoryStmt != nil {
+		if cerr := q.updateMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMemoryStmt: %w", cerr)
+		}
+	}
 	if q.updateMessageStmt != nil {
 		if cerr := q.updateMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateMessageStmt: %w", cerr)
@@ -560,11 +926,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing upsertBridgeSessionStmt: %w", cerr)
 		}
 	}
+	if q.upsertMessageFeedbackStmt != nil {
+		if cerr := q.upsertMessageFeedbackStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertMessageFeedbackStmt: %w", cerr)
+		}
+	}
 	if q.upsertRecapStmt != nil {
 		if cerr := q.upsertRecapStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertRecapStmt: %w", cerr)
 		}
 	}
+	if q.upsertRuntimeSnapshotStmt != nil {
+		if cerr := q.upsertRuntimeSnapshotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertRuntimeSnapshotStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -605,60 +981,104 @@ type Queries struct {
 	db                                   DBTX
 	tx                                   *sql.Tx
 	addBridgeAllowlistEntryStmt          *sql.Stmt
+	addSessionUsageStmt                  *sql.Stmt
 	claimCronJobForFiringStmt            *sql.Stmt
+	claimQueuedRunStmt                   *sql.Stmt
 	clearStaleFiringStmt                 *sql.Stmt
 	countActiveCronJobsBySessionStmt     *sql.Stmt
 	countBridgeSessionsByIdentityStmt    *sql.Stmt
+	countRunningFlowScheduleRunsStmt     *sql.Stmt
+	createBlackboardEntryStmt            *sql.Stmt
+	createCheckpointStmt                 *sql.Stmt
+	createCodeChunkStmt                  *sql.Stmt
 	createCronJobStmt                    *sql.Stmt
 	createFileStmt                       *sql.Stmt
+	createFlowScheduleRunStmt            *sql.Stmt
 	createFlowStateStmt                  *sql.Stmt
+	createMemoryStmt                     *sql.Stmt
 	createMessageStmt                    *sql.Stmt
+	createMessageUsageStmt               *sql.Stmt
+	createQueuedRunStmt                  *sql.Stmt
 	createSessionStmt                    *sql.Stmt
+	createToolExecutionStmt              *sql.Stmt
 	deleteBridgeSessionByPeerStmt        *sql.Stmt
 	deleteBridgeSessionsByIdentityStmt   *sql.Stmt
 	deleteBridgeSessionsBySessionStmt    *sql.Stmt
+	deleteCheckpointStmt                 *sql.Stmt
+	deleteCodeChunksForPathStmt          *sql.Stmt
+	deleteCodeChunksForProjectStmt       *sql.Stmt
 	deleteCronJobStmt                    *sql.Stmt
 	deleteFileStmt                       *sql.Stmt
 	deleteFlowStatesByRootSessionStmt    *sql.Stmt
+	deleteMemoryStmt                     *sql.Stmt
 	deleteMessageStmt                    *sql.Stmt
+	deleteMessageFeedbackStmt            *sql.Stmt
 	deleteRecapBySessionIDStmt           *sql.Stmt
+	deleteRuntimeSnapshotStmt            *sql.Stmt
 	deleteSessionStmt                    *sql.Stmt
 	deleteSessionFilesStmt               *sql.Stmt
 	deleteSessionMessagesStmt            *sql.Stmt
 	deleteSessionTreeStmt                *sql.Stmt
+	failRunningFlowScheduleRunsStmt      *sql.Stmt
+	finishFlowScheduleRunStmt            *sql.Stmt
+	finishQueuedRunStmt                  *sql.Stmt
+	getBlackboardEntryStmt               *sql.Stmt
 	getBridgeSessionStmt                 *sql.Stmt
 	getCronJobStmt                       *sql.Stmt
 	getFileStmt                          *sql.Stmt
 	getFileByPathAndSessionStmt          *sql.Stmt
 	getFlowStateStmt                     *sql.Stmt
 	getMaxSeqBySessionStmt               *sql.Stmt
+	getMemoryByNameStmt                  *sql.Stmt
+	getMemoryStmt                        *sql.Stmt
 	getMessageStmt                       *sql.Stmt
+	getMessageFeedbackStmt               *sql.Stmt
+	getQueuedRunStmt                     *sql.Stmt
 	getRecapBySessionIDStmt              *sql.Stmt
+	getProjectUsageStmt                  *sql.Stmt
+	getRuntimeSnapshotStmt               *sql.Stmt
 	getSessionByIDStmt                   *sql.Stmt
 	isBridgeAllowlistedStmt              *sql.Stmt
 	listActiveCronJobsStmt               *sql.Stmt
+	listBlackboardEntriesStmt            *sql.Stmt
 	listBridgeAllowlistStmt              *sql.Stmt
 	listBridgeSessionsByIdentityStmt     *sql.Stmt
 	listBridgeSessionsBySessionStmt      *sql.Stmt
+	listCheckpointsSinceMessageStmt      *sql.Stmt
 	listChildSessionsStmt                *sql.Stmt
+	listCodeChunkFilesStmt               *sql.Stmt
+	listCodeChunksStmt                   *sql.Stmt
 	listCronJobsBySessionStmt            *sql.Stmt
 	listDueCronJobsStmt                  *sql.Stmt
 	listFilesByPathStmt                  *sql.Stmt
 	listFilesBySessionStmt               *sql.Stmt
 	listFilesBySessionTreeStmt           *sql.Stmt
+	listFlowScheduleRunsStmt             *sql.Stmt
 	listFlowStatesByFlowIDStmt           *sql.Stmt
 	listFlowStatesByRootSessionStmt      *sql.Stmt
 	listLatestMessagesBySessionStmt      *sql.Stmt
 	listLatestSessionFilesStmt           *sql.Stmt
 	listLatestSessionTreeFilesStmt       *sql.Stmt
+	listMemoriesStmt                     *sql.Stmt
+	listMessageFeedbackStmt              *sql.Stmt
+	listMessageUsageStmt                 *sql.Stmt
+	listMessagesBeforeStmt               *sql.Stmt
 	listMessagesBySessionStmt            *sql.Stmt
+	listMessagesFromStmt                 *sql.Stmt
 	listMissedOneShotsStmt               *sql.Stmt
+	listPendingQueuedRunsStmt            *sql.Stmt
+	listQueuedRunsStmt                   *sql.Stmt
+	listSessionUsageStmt                 *sql.Stmt
 	listSessionsStmt                     *sql.Stmt
+	listToolExecutionsStmt               *sql.Stmt
 	markBridgeSessionMentionConsumedStmt *sql.Stmt
 	removeBridgeAllowlistEntryStmt       *sql.Stmt
 	renameSessionStmt                    *sql.Stmt
+	requeueRunningRunsStmt               *sql.Stmt
 	setCronJobFiringStmt                 *sql.Stmt
+	setFlowStateApprovalStmt             *sql.Stmt
 	setGeneratedTitleStmt                *sql.Stmt
+	supersedeMessageStmt                 *sql.Stmt
 	updateBridgeSessionPeerIDStmt        *sql.Stmt
 	updateBridgeSessionSessionIDStmt     *sql.Stmt
 	updateCronJobAfterRunStmt            *sql.Stmt
@@ -667,10 +1087,13 @@ type Queries struct {
 	updateCronJobStatusStmt              *sql.Stmt
 	updateFileStmt                       *sql.Stmt
 	updateFlowStateStmt                  *sql.Stmt
+	updateMemoryStmt                     *sql.Stmt
 	updateMessageStmt                    *sql.Stmt
 	updateSessionStmt                    *sql.Stmt
 	upsertBridgeSessionStmt              *sql.Stmt
+	upsertMessageFeedbackStmt            *sql.Stmt
 	upsertRecapStmt                      *sql.Stmt
+	upsertRuntimeSnapshotStmt            *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
@@ -678,60 +1101,104 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		db:                                   tx,
 		tx:                                   tx,
 		addBridgeAllowlistEntryStmt:          q.addBridgeAllowlistEntryStmt,
+		addSessionUsageStmt:                  q.addSessionUsageStmt,
 		claimCronJobForFiringStmt:            q.claimCronJobForFiringStmt,
+		claimQueuedRunStmt:                   q.claimQueuedRunStmt,
 		clearStaleFiringStmt:                 q.clearStaleFiringStmt,
 		countActiveCronJobsBySessionStmt:     q.countActiveCronJobsBySessionStmt,
 		countBridgeSessionsByIdentityStmt:    q.countBridgeSessionsByIdentityStmt,
+		countRunningFlowScheduleRunsStmt:     q.countRunningFlowScheduleRunsStmt,
+		createBlackboardEntryStmt:            q.createBlackboardEntryStmt,
+		createCheckpointStmt:                 q.createCheckpointStmt,
+		createCodeChunkStmt:                  q.createCodeChunkStmt,
 		createCronJobStmt:                    q.createCronJobStmt,
 		createFileStmt:                       q.createFileStmt,
+		createFlowScheduleRunStmt:            q.createFlowScheduleRunStmt,
 		createFlowStateStmt:                  q.createFlowStateStmt,
+		createMemoryStmt:                     q.createMemoryStmt,
 		createMessageStmt:                    q.createMessageStmt,
+		createMessageUsageStmt:               q.createMessageUsageStmt,
+		createQueuedRunStmt:                  q.createQueuedRunStmt,
 		createSessionStmt:                    q.createSessionStmt,
+		createToolExecutionStmt:              q.createToolExecutionStmt,
 		deleteBridgeSessionByPeerStmt:        q.deleteBridgeSessionByPeerStmt,
 		deleteBridgeSessionsByIdentityStmt:   q.deleteBridgeSessionsByIdentityStmt,
 		deleteBridgeSessionsBySessionStmt:    q.deleteBridgeSessionsBySessionStmt,
+		deleteCheckpointStmt:                 q.deleteCheckpointStmt,
+		deleteCodeChunksForPathStmt:          q.deleteCodeChunksForPathStmt,
+		deleteCodeChunksForProjectStmt:       q.deleteCodeChunksForProjectStmt,
 		deleteCronJobStmt:                    q.deleteCronJobStmt,
 		deleteFileStmt:                       q.deleteFileStmt,
 		deleteFlowStatesByRootSessionStmt:    q.deleteFlowStatesByRootSessionStmt,
+		deleteMemoryStmt:                     q.deleteMemoryStmt,
 		deleteMessageStmt:                    q.deleteMessageStmt,
+		deleteMessageFeedbackStmt:            q.deleteMessageFeedbackStmt,
 		deleteRecapBySessionIDStmt:           q.deleteRecapBySessionIDStmt,
+		deleteRuntimeSnapshotStmt:            q.deleteRuntimeSnapshotStmt,
 		deleteSessionStmt:                    q.deleteSessionStmt,
 		deleteSessionFilesStmt:               q.deleteSessionFilesStmt,
 		deleteSessionMessagesStmt:            q.deleteSessionMessagesStmt,
 		deleteSessionTreeStmt:                q.deleteSessionTreeStmt,
+		failRunningFlowScheduleRunsStmt:      q.failRunningFlowScheduleRunsStmt,
+		finishFlowScheduleRunStmt:            q.finishFlowScheduleRunStmt,
+		finishQueuedRunStmt:                  q.finishQueuedRunStmt,
+		getBlackboardEntryStmt:               q.getBlackboardEntryStmt,
 		getBridgeSessionStmt:                 q.getBridgeSessionStmt,
 		getCronJobStmt:                       q.getCronJobStmt,
 		getFileStmt:                          q.getFileStmt,
 		getFileByPathAndSessionStmt:          q.getFileByPathAndSessionStmt,
 		getFlowStateStmt:                     q.getFlowStateStmt,
 		getMaxSeqBySessionStmt:               q.getMaxSeqBySessionStmt,
+		getMemoryByNameStmt:                  q.getMemoryByNameStmt,
+		getMemoryStmt:                        q.getMemoryStmt,
 		getMessageStmt:                       q.getMessageStmt,
+		getMessageFeedbackStmt:               q.getMessageFeedbackStmt,
+		getQueuedRunStmt:                     q.getQueuedRunStmt,
 		getRecapBySessionIDStmt:              q.getRecapBySessionIDStmt,
+		getProjectUsageStmt:                  q.getProjectUsageStmt,
+		getRuntimeSnapshotStmt:               q.getRuntimeSnapshotStmt,
 		getSessionByIDStmt:                   q.getSessionByIDStmt,
 		isBridgeAllowlistedStmt:              q.isBridgeAllowlistedStmt,
 		listActiveCronJobsStmt:               q.listActiveCronJobsStmt,
+		listBlackboardEntriesStmt:            q.listBlackboardEntriesStmt,
 		listBridgeAllowlistStmt:              q.listBridgeAllowlistStmt,
 		listBridgeSessionsByIdentityStmt:     q.listBridgeSessionsByIdentityStmt,
 		listBridgeSessionsBySessionStmt:      q.listBridgeSessionsBySessionStmt,
+		listCheckpointsSinceMessageStmt:      q.listCheckpointsSinceMessageStmt,
 		listChildSessionsStmt:                q.listChildSessionsStmt,
+		listCodeChunkFilesStmt:               q.listCodeChunkFilesStmt,
+		listCodeChunksStmt:                   q.listCodeChunksStmt,
 		listCronJobsBySessionStmt:            q.listCronJobsBySessionStmt,
 		listDueCronJobsStmt:                  q.listDueCronJobsStmt,
 		listFilesByPathStmt:                  q.listFilesByPathStmt,
 		listFilesBySessionStmt:               q.listFilesBySessionStmt,
 		listFilesBySessionTreeStmt:           q.listFilesBySessionTreeStmt,
+		listFlowScheduleRunsStmt:             q.listFlowScheduleRunsStmt,
 		listFlowStatesByFlowIDStmt:           q.listFlowStatesByFlowIDStmt,
 		listFlowStatesByRootSessionStmt:      q.listFlowStatesByRootSessionStmt,
 		listLatestMessagesBySessionStmt:      q.listLatestMessagesBySessionStmt,
 		listLatestSessionFilesStmt:           q.listLatestSessionFilesStmt,
 		listLatestSessionTreeFilesStmt:       q.listLatestSessionTreeFilesStmt,
+		listMemoriesStmt:                     q.listMemoriesStmt,
+		listMessageFeedbackStmt:              q.listMessageFeedbackStmt,
+		listMessageUsageStmt:                 q.listMessageUsageStmt,
+		listMessagesBeforeStmt:               q.listMessagesBeforeStmt,
 		listMessagesBySessionStmt:            q.listMessagesBySessionStmt,
+		listMessagesFromStmt:                 q.listMessagesFromStmt,
 		listMissedOneShotsStmt:               q.listMissedOneShotsStmt,
+		listPendingQueuedRunsStmt:            q.listPendingQueuedRunsStmt,
+		listQueuedRunsStmt:                   q.listQueuedRunsStmt,
+		listSessionUsageStmt:                 q.listSessionUsageStmt,
 		listSessionsStmt:                     q.listSessionsStmt,
+		listToolExecutionsStmt:               q.listToolExecutionsStmt,
 		markBridgeSessionMentionConsumedStmt: q.markBridgeSessionMentionConsumedStmt,
 		removeBridgeAllowlistEntryStmt:       q.removeBridgeAllowlistEntryStmt,
 		renameSessionStmt:                    q.renameSessionStmt,
+		requeueRunningRunsStmt:               q.requeueRunningRunsStmt,
 		setCronJobFiringStmt:                 q.setCronJobFiringStmt,
+		setFlowStateApprovalStmt:             q.setFlowStateApprovalStmt,
 		setGeneratedTitleStmt:                q.setGeneratedTitleStmt,
+		supersedeMessageStmt:                 q.supersedeMessageStmt,
 		updateBridgeSessionPeerIDStmt:        q.updateBridgeSessionPeerIDStmt,
 		updateBridgeSessionSessionIDStmt:     q.updateBridgeSessionSessionIDStmt,
 		updateCronJobAfterRunStmt:            q.updateCronJobAfterRunStmt,
@@ -740,9 +1207,12 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		updateCronJobStatusStmt:              q.updateCronJobStatusStmt,
 		updateFileStmt:                       q.updateFileStmt,
 		updateFlowStateStmt:                  q.updateFlowStateStmt,
+		updateMemoryStmt:                     q.updateMemoryStmt,
 		updateMessageStmt:                    q.updateMessageStmt,
 		updateSessionStmt:                    q.updateSessionStmt,
 		upsertBridgeSessionStmt:              q.upsertBridgeSessionStmt,
+		upsertMessageFeedbackStmt:            q.upsertMessageFeedbackStmt,
 		upsertRecapStmt:                      q.upsertRecapStmt,
+		upsertRuntimeSnapshotStmt:            q.upsertRuntimeSnapshotStmt,
 	}
 }
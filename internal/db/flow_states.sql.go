@@ -35,7 +35,7 @@ INSERT INTO flow_states (
     ?,
     strftime('%s', 'now'),
     strftime('%s', 'now')
-) RETURNING session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration
+) RETURNING session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration, approval
 `
 
 type CreateFlowStateParams struct {
@@ -75,6 +75,7 @@ func (q *Queries) CreateFlowState(ctx context.Context, arg CreateFlowStateParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Iteration,
+		&i.Approval,
 	)
 	return i, err
 }
@@ -89,7 +90,7 @@ func (q *Queries) DeleteFlowStatesByRootSession(ctx context.Context, rootSession
 }
 
 const getFlowState = `-- name: GetFlowState :one
-SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration FROM flow_states WHERE session_id = ? LIMIT 1
+SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration, approval FROM flow_states WHERE session_id = ? LIMIT 1
 `
 
 func (q *Queries) GetFlowState(ctx context.Context, sessionID string) (FlowState, error) {
@@ -107,12 +108,13 @@ func (q *Queries) GetFlowState(ctx context.Context, sessionID string) (FlowState
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Iteration,
+		&i.Approval,
 	)
 	return i, err
 }
 
 const listFlowStatesByFlowID = `-- name: ListFlowStatesByFlowID :many
-SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration FROM flow_states WHERE flow_id = ? ORDER BY created_at ASC
+SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration, approval FROM flow_states WHERE flow_id = ? ORDER BY created_at ASC
 `
 
 func (q *Queries) ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]FlowState, error) {
@@ -136,6 +138,7 @@ func (q *Queries) ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Iteration,
+			&i.Approval,
 		); err != nil {
 			return nil, err
 		}
@@ -151,7 +154,7 @@ func (q *Queries) ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]
 }
 
 const listFlowStatesByRootSession = `-- name: ListFlowStatesByRootSession :many
-SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration FROM flow_states WHERE root_session_id = ? ORDER BY created_at ASC
+SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration, approval FROM flow_states WHERE root_session_id = ? ORDER BY created_at ASC
 `
 
 func (q *Queries) ListFlowStatesByRootSession(ctx context.Context, rootSessionID string) ([]FlowState, error) {
@@ -175,6 +178,7 @@ func (q *Queries) ListFlowStatesByRootSession(ctx context.Context, rootSessionID
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Iteration,
+			&i.Approval,
 		); err != nil {
 			return nil, err
 		}
@@ -189,6 +193,39 @@ func (q *Queries) ListFlowStatesByRootSession(ctx context.Context, rootSessionID
 	return items, nil
 }
 
+const setFlowStateApproval = `-- name: SetFlowStateApproval :one
+UPDATE flow_states
+SET approval = ?,
+    updated_at = strftime('%s', 'now')
+WHERE session_id = ?
+RETURNING session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration, approval
+`
+
+type SetFlowStateApprovalParams struct {
+	Approval  sql.NullString `json:"approval"`
+	SessionID string         `json:"session_id"`
+}
+
+func (q *Queries) SetFlowStateApproval(ctx context.Context, arg SetFlowStateApprovalParams) (FlowState, error) {
+	row := q.queryRow(ctx, q.setFlowStateApprovalStmt, setFlowStateApproval, arg.Approval, arg.SessionID)
+	var i FlowState
+	err := row.Scan(
+		&i.SessionID,
+		&i.RootSessionID,
+		&i.FlowID,
+		&i.StepID,
+		&i.Status,
+		&i.Args,
+		&i.Output,
+		&i.IsStructOutput,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Iteration,
+		&i.Approval,
+	)
+	return i, err
+}
+
 const updateFlowState = `-- name: UpdateFlowState :one
 UPDATE flow_states
 SET status = ?,
@@ -198,7 +235,7 @@ SET status = ?,
     iteration = ?,
     updated_at = strftime('%s', 'now')
 WHERE session_id = ?
-RETURNING session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration
+RETURNING session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, created_at, updated_at, iteration, approval
 `
 
 type UpdateFlowStateParams struct {
@@ -232,6 +269,7 @@ func (q *Queries) UpdateFlowState(ctx context.Context, arg UpdateFlowStateParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Iteration,
+		&i.Approval,
 	)
 	return i, err
 }
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS checkpoints (
+    id VARCHAR(255) PRIMARY KEY,
+    session_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    path VARCHAR(1024) NOT NULL,
+    content LONGTEXT NOT NULL,
+    existed TINYINT(1) NOT NULL DEFAULT 1,
+    created_at BIGINT NOT NULL,
+    UNIQUE KEY idx_checkpoints_message_path (session_id, message_id, path(255)),
+    KEY idx_checkpoints_session_id (session_id),
+    CONSTRAINT fk_checkpoints_session_id FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS checkpoints;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flow_states
+  ADD COLUMN approval VARCHAR(16) NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flow_states
+  DROP COLUMN approval;
+
+-- +goose StatementEnd
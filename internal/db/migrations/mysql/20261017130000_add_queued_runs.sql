@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS queued_runs (
+    id VARCHAR(255) PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    payload LONGTEXT NOT NULL,
+    priority BIGINT NOT NULL DEFAULT 0,
+    provider VARCHAR(64) NOT NULL DEFAULT '',
+    project VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'queued',
+    session_id VARCHAR(255),
+    error LONGTEXT,
+    created_at BIGINT NOT NULL,
+    started_at BIGINT,
+    finished_at BIGINT,
+    KEY idx_queued_runs_dispatch (status, priority, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS queued_runs;
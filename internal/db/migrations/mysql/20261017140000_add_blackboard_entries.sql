@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS blackboard_entries (
+    id VARCHAR(255) PRIMARY KEY,
+    root_session_id VARCHAR(255) NOT NULL,
+    session_id VARCHAR(255) NOT NULL,
+    agent VARCHAR(255) NOT NULL DEFAULT '',
+    topic VARCHAR(255) NOT NULL DEFAULT '',
+    content LONGTEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    KEY idx_blackboard_entries_root (root_session_id, created_at),
+    CONSTRAINT fk_blackboard_entries_root FOREIGN KEY (root_session_id) REFERENCES sessions(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS blackboard_entries;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS flow_schedule_runs (
+    id VARCHAR(255) PRIMARY KEY,
+    project_id VARCHAR(255) NOT NULL DEFAULT '',
+    flow_id VARCHAR(255) NOT NULL,
+    scheduled_at BIGINT NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    session_id VARCHAR(255),
+    error LONGTEXT,
+    started_at BIGINT NOT NULL,
+    finished_at BIGINT,
+    UNIQUE KEY idx_flow_schedule_runs_slot (project_id, flow_id, scheduled_at),
+    KEY idx_flow_schedule_runs_status (project_id, flow_id, status)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS flow_schedule_runs;
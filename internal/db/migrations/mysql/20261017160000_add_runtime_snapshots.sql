@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS runtime_snapshots (
+    session_id VARCHAR(255) PRIMARY KEY,
+    version VARCHAR(64) NOT NULL DEFAULT '',
+    state LONGTEXT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    CONSTRAINT fk_runtime_snapshots_session FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS runtime_snapshots;
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN superseded_at BIGINT NULL;
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_session_message_count_on_delete;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER update_session_message_count_on_delete AFTER DELETE ON messages FOR EACH ROW
+UPDATE sessions
+SET
+  message_count = message_count - 1
+WHERE
+  id = OLD.session_id
+  AND OLD.superseded_at IS NULL;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER update_session_message_count_on_supersede AFTER UPDATE ON messages FOR EACH ROW
+UPDATE sessions
+SET
+  message_count = message_count - 1
+WHERE
+  id = NEW.session_id
+  AND OLD.superseded_at IS NULL
+  AND NEW.superseded_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_session_message_count_on_supersede;
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_session_message_count_on_delete;
+-- +goose StatementEnd
+-- +goose StatementBegin
+DELETE FROM messages WHERE superseded_at IS NOT NULL;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER update_session_message_count_on_delete AFTER DELETE ON messages FOR EACH ROW
+UPDATE sessions
+SET
+  message_count = message_count - 1
+WHERE
+  id = OLD.session_id;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE messages DROP COLUMN superseded_at;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Audit rows outlive the sessions they refer to, so there is no foreign
+-- key to sessions. created_at is in Unix milliseconds so calls within the
+-- same second keep their order.
+CREATE TABLE IF NOT EXISTS tool_executions (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    seq BIGINT NOT NULL DEFAULT 0,
+    session_id VARCHAR(255) NOT NULL DEFAULT '',
+    agent_id VARCHAR(255) NOT NULL DEFAULT '',
+    tool VARCHAR(191) NOT NULL,
+    call_id VARCHAR(255) NOT NULL DEFAULT '',
+    input LONGTEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    exit_code BIGINT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    decision VARCHAR(16) NOT NULL DEFAULT '',
+    via VARCHAR(32) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL,
+    INDEX idx_tool_executions_created_at (created_at),
+    INDEX idx_tool_executions_session (session_id, created_at),
+    INDEX idx_tool_executions_tool (tool, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS tool_executions;
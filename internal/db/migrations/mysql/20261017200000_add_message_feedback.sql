@@ -0,0 +1,18 @@
+-- +goose Up
+-- One rating per message, given by the user on an assistant reply.
+CREATE TABLE IF NOT EXISTS message_feedback (
+    message_id VARCHAR(255) PRIMARY KEY,
+    session_id VARCHAR(255) NOT NULL,
+    rating VARCHAR(16) NOT NULL,
+    comment TEXT NOT NULL,
+    agent_id VARCHAR(255) NOT NULL DEFAULT '',
+    model VARCHAR(255) NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    INDEX idx_message_feedback_session (session_id),
+    CONSTRAINT fk_message_feedback_message FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    CONSTRAINT fk_message_feedback_session FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS message_feedback;
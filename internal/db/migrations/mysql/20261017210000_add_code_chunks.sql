@@ -0,0 +1,20 @@
+-- +goose Up
+-- Embedded workspace chunks behind the codesearch tool. embedding holds the
+-- vector as little-endian float32s; file_hash is the SHA-256 of the whole
+-- file so unchanged files are skipped on re-index.
+CREATE TABLE IF NOT EXISTS code_chunks (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    project_id VARCHAR(255) NOT NULL,
+    path VARCHAR(767) NOT NULL,
+    start_line BIGINT NOT NULL,
+    end_line BIGINT NOT NULL,
+    content LONGTEXT NOT NULL,
+    file_hash VARCHAR(64) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    embedding LONGBLOB NOT NULL,
+    updated_at BIGINT NOT NULL,
+    INDEX idx_code_chunks_project_path (project_id, path(191))
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS code_chunks;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Project memories outlive sessions, so they are keyed by project only.
+-- name is empty for free-text notes; a named memory is replaced when it is
+-- written again.
+CREATE TABLE IF NOT EXISTS memories (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    project_id VARCHAR(255) NOT NULL,
+    name VARCHAR(191) NOT NULL DEFAULT '',
+    content LONGTEXT NOT NULL,
+    priority VARCHAR(16) NOT NULL DEFAULT 'normal',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    INDEX idx_memories_project_name (project_id, name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS memories;
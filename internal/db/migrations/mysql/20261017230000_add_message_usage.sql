@@ -0,0 +1,24 @@
+-- +goose Up
+-- One row per model request. Like tool_executions, rows outlive the
+-- sessions they refer to so spend reports stay complete after cleanup.
+-- created_at is in Unix milliseconds.
+CREATE TABLE IF NOT EXISTS message_usage (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    session_id VARCHAR(255) NOT NULL DEFAULT '',
+    project_id VARCHAR(255) NOT NULL DEFAULT '',
+    agent_id VARCHAR(255) NOT NULL DEFAULT '',
+    model VARCHAR(191) NOT NULL DEFAULT '',
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_creation_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_read_tokens BIGINT NOT NULL DEFAULT 0,
+    cost DOUBLE NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at BIGINT NOT NULL,
+    INDEX idx_message_usage_project (project_id, created_at),
+    INDEX idx_message_usage_session (session_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS message_usage;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE flow_states ADD COLUMN approval TEXT;
+
+-- +goose Down
+ALTER TABLE flow_states DROP COLUMN approval;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS blackboard_entries (
+    id TEXT PRIMARY KEY,
+    root_session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    agent TEXT NOT NULL DEFAULT '',
+    topic TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackboard_entries_root ON blackboard_entries (root_session_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_blackboard_entries_root;
+DROP TABLE IF EXISTS blackboard_entries;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS flow_schedule_runs (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL DEFAULT '',
+    flow_id TEXT NOT NULL,
+    scheduled_at INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    session_id TEXT,
+    error TEXT,
+    started_at INTEGER NOT NULL,
+    finished_at INTEGER,
+    UNIQUE (project_id, flow_id, scheduled_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flow_schedule_runs_status ON flow_schedule_runs (project_id, flow_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_flow_schedule_runs_status;
+DROP TABLE IF EXISTS flow_schedule_runs;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN superseded_at INTEGER;
+
+-- Superseded messages stay in the table (checkpoints still refer to them)
+-- but no longer count towards the session.
+DROP TRIGGER IF EXISTS update_session_message_count_on_delete;
+
+CREATE TRIGGER IF NOT EXISTS update_session_message_count_on_delete
+AFTER DELETE ON messages
+WHEN old.superseded_at IS NULL
+BEGIN
+UPDATE sessions SET
+    message_count = message_count - 1
+WHERE id = old.session_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_session_message_count_on_supersede
+AFTER UPDATE OF superseded_at ON messages
+WHEN old.superseded_at IS NULL AND new.superseded_at IS NOT NULL
+BEGIN
+UPDATE sessions SET
+    message_count = message_count - 1
+WHERE id = new.session_id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_session_message_count_on_supersede;
+DROP TRIGGER IF EXISTS update_session_message_count_on_delete;
+
+DELETE FROM messages WHERE superseded_at IS NOT NULL;
+
+CREATE TRIGGER IF NOT EXISTS update_session_message_count_on_delete
+AFTER DELETE ON messages
+BEGIN
+UPDATE sessions SET
+    message_count = message_count - 1
+WHERE id = old.session_id;
+END;
+
+ALTER TABLE messages DROP COLUMN superseded_at;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Audit rows outlive the sessions they refer to, so there is no foreign
+-- key to sessions. created_at is in Unix milliseconds so calls within the
+-- same second keep their order.
+CREATE TABLE IF NOT EXISTS tool_executions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    seq INTEGER NOT NULL DEFAULT 0,
+    session_id TEXT NOT NULL DEFAULT '',
+    agent_id TEXT NOT NULL DEFAULT '',
+    tool TEXT NOT NULL,
+    call_id TEXT NOT NULL DEFAULT '',
+    input TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    exit_code INTEGER,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    decision TEXT NOT NULL DEFAULT '',
+    via TEXT NOT NULL DEFAULT '',
+    hash TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_executions_created_at ON tool_executions (created_at);
+CREATE INDEX IF NOT EXISTS idx_tool_executions_session ON tool_executions (session_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tool_executions_tool ON tool_executions (tool, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tool_executions_tool;
+DROP INDEX IF EXISTS idx_tool_executions_session;
+DROP INDEX IF EXISTS idx_tool_executions_created_at;
+DROP TABLE IF EXISTS tool_executions;
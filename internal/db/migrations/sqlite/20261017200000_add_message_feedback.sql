@@ -0,0 +1,18 @@
+-- +goose Up
+-- One rating per message, given by the user on an assistant reply.
+CREATE TABLE IF NOT EXISTS message_feedback (
+    message_id TEXT PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    rating TEXT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    agent_id TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_feedback_session ON message_feedback (session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_message_feedback_session;
+DROP TABLE IF EXISTS message_feedback;
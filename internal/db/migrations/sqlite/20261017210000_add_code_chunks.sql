@@ -0,0 +1,22 @@
+-- +goose Up
+-- Embedded workspace chunks behind the codesearch tool. embedding holds the
+-- vector as little-endian float32s; file_hash is the SHA-256 of the whole
+-- file so unchanged files are skipped on re-index.
+CREATE TABLE IF NOT EXISTS code_chunks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    path TEXT NOT NULL,
+    start_line INTEGER NOT NULL,
+    end_line INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    file_hash TEXT NOT NULL,
+    model TEXT NOT NULL,
+    embedding BLOB NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_code_chunks_project_path ON code_chunks (project_id, path);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_code_chunks_project_path;
+DROP TABLE IF EXISTS code_chunks;
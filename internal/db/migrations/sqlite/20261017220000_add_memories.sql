@@ -0,0 +1,19 @@
+-- +goose Up
+-- Project memories outlive sessions, so they are keyed by project only.
+-- name is empty for free-text notes; a named memory is replaced when it is
+-- written again.
+CREATE TABLE IF NOT EXISTS memories (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    priority TEXT NOT NULL DEFAULT 'normal',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_memories_project_name ON memories (project_id, name);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_memories_project_name;
+DROP TABLE IF EXISTS memories;
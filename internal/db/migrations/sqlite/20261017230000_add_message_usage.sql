@@ -0,0 +1,27 @@
+-- +goose Up
+-- One row per model request. Like tool_executions, rows outlive the
+-- sessions they refer to so spend reports stay complete after cleanup.
+-- created_at is in Unix milliseconds.
+CREATE TABLE IF NOT EXISTS message_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id TEXT NOT NULL DEFAULT '',
+    session_id TEXT NOT NULL DEFAULT '',
+    project_id TEXT NOT NULL DEFAULT '',
+    agent_id TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_creation_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_tokens INTEGER NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_usage_project ON message_usage (project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_message_usage_session ON message_usage (session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_message_usage_session;
+DROP INDEX IF EXISTS idx_message_usage_project;
+DROP TABLE IF EXISTS message_usage;
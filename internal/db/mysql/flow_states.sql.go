@@ -74,7 +74,7 @@ func (q *Queries) DeleteFlowStatesByRootSession(ctx context.Context, rootSession
 }
 
 const getFlowState = `-- name: GetFlowState :one
-SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, iteration, created_at, updated_at FROM flow_states WHERE session_id = ? LIMIT 1
+SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, iteration, created_at, updated_at, approval FROM flow_states WHERE session_id = ? LIMIT 1
 `
 
 func (q *Queries) GetFlowState(ctx context.Context, sessionID string) (FlowState, error) {
@@ -92,12 +92,13 @@ func (q *Queries) GetFlowState(ctx context.Context, sessionID string) (FlowState
 		&i.Iteration,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Approval,
 	)
 	return i, err
 }
 
 const listFlowStatesByFlowID = `-- name: ListFlowStatesByFlowID :many
-SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, iteration, created_at, updated_at FROM flow_states WHERE flow_id = ? ORDER BY created_at ASC
+SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, iteration, created_at, updated_at, approval FROM flow_states WHERE flow_id = ? ORDER BY created_at ASC
 `
 
 func (q *Queries) ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]FlowState, error) {
@@ -121,6 +122,7 @@ func (q *Queries) ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]
 			&i.Iteration,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Approval,
 		); err != nil {
 			return nil, err
 		}
@@ -136,7 +138,7 @@ func (q *Queries) ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]
 }
 
 const listFlowStatesByRootSession = `-- name: ListFlowStatesByRootSession :many
-SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, iteration, created_at, updated_at FROM flow_states WHERE root_session_id = ? ORDER BY created_at ASC
+SELECT session_id, root_session_id, flow_id, step_id, status, args, output, is_struct_output, iteration, created_at, updated_at, approval FROM flow_states WHERE root_session_id = ? ORDER BY created_at ASC
 `
 
 func (q *Queries) ListFlowStatesByRootSession(ctx context.Context, rootSessionID string) ([]FlowState, error) {
@@ -160,6 +162,7 @@ func (q *Queries) ListFlowStatesByRootSession(ctx context.Context, rootSessionID
 			&i.Iteration,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Approval,
 		); err != nil {
 			return nil, err
 		}
@@ -174,6 +177,21 @@ func (q *Queries) ListFlowStatesByRootSession(ctx context.Context, rootSessionID
 	return items, nil
 }
 
+const setFlowStateApproval = `-- name: SetFlowStateApproval :execresult
+UPDATE flow_states
+SET approval = ?
+WHERE session_id = ?
+`
+
+type SetFlowStateApprovalParams struct {
+	Approval  sql.NullString `json:"approval"`
+	SessionID string         `json:"session_id"`
+}
+
+func (q *Queries) SetFlowStateApproval(ctx context.Context, arg SetFlowStateApprovalParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, setFlowStateApproval, arg.Approval, arg.SessionID)
+}
+
 const updateFlowState = `-- name: UpdateFlowState :execresult
 UPDATE flow_states
 SET status = ?,
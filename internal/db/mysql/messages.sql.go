@@ -82,7 +82,7 @@ func (q *Queries) GetMaxSeqBySession(ctx context.Context, sessionID string) (int
 }
 
 const getMessage = `-- name: GetMessage :one
-SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic
+SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at
 FROM messages
 WHERE id = ? LIMIT 1
 `
@@ -101,15 +101,16 @@ func (q *Queries) GetMessage(ctx context.Context, id string) (Message, error) {
 		&i.UpdatedAt,
 		&i.FinishedAt,
 		&i.Synthetic,
+		&i.SupersededAt,
 	)
 	return i, err
 }
 
 const listLatestMessagesBySession = `-- name: ListLatestMessagesBySession :many
-SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic FROM (
-    SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic
+SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at FROM (
+    SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at
     FROM messages
-    WHERE session_id = ?
+    WHERE session_id = ? AND superseded_at IS NULL
     ORDER BY seq DESC, created_at DESC
     LIMIT ?
 ) sub
@@ -141,6 +142,63 @@ func (q *Queries) ListLatestMessagesBySession(ctx context.Context, arg ListLates
 			&i.UpdatedAt,
 			&i.FinishedAt,
 			&i.Synthetic,
+			&i.SupersededAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesBefore = `-- name: ListMessagesBefore :many
+SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at FROM (
+    SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at
+    FROM messages
+    WHERE session_id = ?
+      AND superseded_at IS NULL
+      AND (COALESCE(seq, 0) < ?
+        OR (COALESCE(seq, 0) = ? AND created_at < ?))
+    ORDER BY seq DESC, created_at DESC
+    LIMIT ?
+) sub
+ORDER BY seq ASC, created_at ASC
+`
+
+type ListMessagesBeforeParams struct {
+	SessionID       string `json:"session_id"`
+	BeforeSeq       int64  `json:"before_seq"`
+	BeforeCreatedAt int64  `json:"before_created_at"`
+	Limit           int32  `json:"limit"`
+}
+
+func (q *Queries) ListMessagesBefore(ctx context.Context, arg ListMessagesBeforeParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, listMessagesBefore, arg.SessionID, arg.BeforeSeq, arg.BeforeSeq, arg.BeforeCreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Role,
+			&i.Parts,
+			&i.Model,
+			&i.Seq,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FinishedAt,
+			&i.Synthetic,
+			&i.SupersededAt,
 		); err != nil {
 			return nil, err
 		}
@@ -156,9 +214,9 @@ func (q *Queries) ListLatestMessagesBySession(ctx context.Context, arg ListLates
 }
 
 const listMessagesBySession = `-- name: ListMessagesBySession :many
-SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic
+SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at
 FROM messages
-WHERE session_id = ?
+WHERE session_id = ? AND superseded_at IS NULL
 ORDER BY seq ASC, created_at ASC
 `
 
@@ -182,6 +240,7 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID string) (
 			&i.UpdatedAt,
 			&i.FinishedAt,
 			&i.Synthetic,
+			&i.SupersededAt,
 		); err != nil {
 			return nil, err
 		}
@@ -196,6 +255,68 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID string) (
 	return items, nil
 }
 
+const listMessagesFrom = `-- name: ListMessagesFrom :many
+SELECT id, session_id, role, parts, model, seq, created_at, updated_at, finished_at, synthetic, superseded_at
+FROM messages
+WHERE session_id = ?
+  AND superseded_at IS NULL
+  AND (COALESCE(seq, 0) > ?
+    OR (COALESCE(seq, 0) = ? AND created_at >= ?))
+ORDER BY seq ASC, created_at ASC
+`
+
+type ListMessagesFromParams struct {
+	SessionID     string `json:"session_id"`
+	FromSeq       int64  `json:"from_seq"`
+	FromCreatedAt int64  `json:"from_created_at"`
+}
+
+func (q *Queries) ListMessagesFrom(ctx context.Context, arg ListMessagesFromParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, listMessagesFrom, arg.SessionID, arg.FromSeq, arg.FromSeq, arg.FromCreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Message{}
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Role,
+			&i.Parts,
+			&i.Model,
+			&i.Seq,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FinishedAt,
+			&i.Synthetic,
+			&i.SupersededAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const supersedeMessage = `-- name: SupersedeMessage :exec
+UPDATE messages
+SET superseded_at = UNIX_TIMESTAMP()
+WHERE id = ? AND superseded_at IS NULL
+`
+
+func (q *Queries) SupersedeMessage(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, supersedeMessage, id)
+	return err
+}
+
 const updateMessage = `-- name: UpdateMessage :exec
 UPDATE messages
 SET
@@ -8,6 +8,16 @@ import (
 	"database/sql"
 )
 
+type BlackboardEntry struct {
+	ID            string `json:"id"`
+	RootSessionID string `json:"root_session_id"`
+	SessionID     string `json:"session_id"`
+	Agent         string `json:"agent"`
+	Topic         string `json:"topic"`
+	Content       string `json:"content"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
 type BridgeAllowlist struct {
 	ProjectID  string `json:"project_id"`
 	Channel    string `json:"channel"`
@@ -28,6 +38,29 @@ type BridgeSession struct {
 	UpdatedAt         int64          `json:"updated_at"`
 }
 
+type Checkpoint struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	MessageID string `json:"message_id"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Existed   bool   `json:"existed"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type CodeChunk struct {
+	ID        int64  `json:"id"`
+	ProjectID string `json:"project_id"`
+	Path      string `json:"path"`
+	StartLine int64  `json:"start_line"`
+	EndLine   int64  `json:"end_line"`
+	Content   string `json:"content"`
+	FileHash  string `json:"file_hash"`
+	Model     string `json:"model"`
+	Embedding []byte `json:"embedding"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
 type CronJob struct {
 	ID           string         `json:"id"`
 	SessionID    string         `json:"session_id"`
@@ -71,19 +104,93 @@ type FlowState struct {
 	Iteration      int32          `json:"iteration"`
 	CreatedAt      int64          `json:"created_at"`
 	UpdatedAt      int64          `json:"updated_at"`
+	Approval       sql.NullString `json:"approval"`
+}
+
+type FlowScheduleRun struct {
+	ID          string         `json:"id"`
+	ProjectID   string         `json:"project_id"`
+	FlowID      string         `json:"flow_id"`
+	ScheduledAt int64          `json:"scheduled_at"`
+	Status      string         `json:"status"`
+	SessionID   sql.NullString `json:"session_id"`
+	Error       sql.NullString `json:"error"`
+	StartedAt   int64          `json:"started_at"`
+	FinishedAt  sql.NullInt64  `json:"finished_at"`
+}
+
+type Memory struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	Priority  string `json:"priority"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
 }
 
 type Message struct {
+	ID           string         `json:"id"`
+	SessionID    string         `json:"session_id"`
+	Role         string         `json:"role"`
+	Parts        string         `json:"parts"`
+	Model        sql.NullString `json:"model"`
+	Seq          sql.NullInt64  `json:"seq"`
+	CreatedAt    int64          `json:"created_at"`
+	UpdatedAt    int64          `json:"updated_at"`
+	FinishedAt   sql.NullInt64  `json:"finished_at"`
+	Synthetic    bool           `json:"synthetic"`
+	SupersededAt sql.NullInt64  `json:"superseded_at"`
+}
+
+type MessageFeedback struct {
+	MessageID string `json:"message_id"`
+	SessionID string `json:"session_id"`
+	Rating    string `json:"rating"`
+	Comment   string `json:"comment"`
+	AgentID   string `json:"agent_id"`
+	Model     string `json:"model"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type MessageUsage struct {
+	ID                  int64   `json:"id"`
+	MessageID           string  `json:"message_id"`
+	SessionID           string  `json:"session_id"`
+	ProjectID           string  `json:"project_id"`
+	AgentID             string  `json:"agent_id"`
+	Model               string  `json:"model"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Cost                float64 `json:"cost"`
+	DurationMs          int64   `json:"duration_ms"`
+	CreatedAt           int64   `json:"created_at"`
+}
+
+type QueuedRun struct {
 	ID         string         `json:"id"`
-	SessionID  string         `json:"session_id"`
-	Role       string         `json:"role"`
-	Parts      string         `json:"parts"`
-	Model      sql.NullString `json:"model"`
-	Seq        sql.NullInt64  `json:"seq"`
+	Kind       string         `json:"kind"`
+	Target     string         `json:"target"`
+	Payload    string         `json:"payload"`
+	Priority   int64          `json:"priority"`
+	Provider   string         `json:"provider"`
+	Project    string         `json:"project"`
+	Status     string         `json:"status"`
+	SessionID  sql.NullString `json:"session_id"`
+	Error      sql.NullString `json:"error"`
 	CreatedAt  int64          `json:"created_at"`
-	UpdatedAt  int64          `json:"updated_at"`
+	StartedAt  sql.NullInt64  `json:"started_at"`
 	FinishedAt sql.NullInt64  `json:"finished_at"`
-	Synthetic  bool           `json:"synthetic"`
+}
+
+type RuntimeSnapshot struct {
+	SessionID string `json:"session_id"`
+	Version   string `json:"version"`
+	State     string `json:"state"`
+	UpdatedAt int64  `json:"updated_at"`
 }
 
 type Session struct {
@@ -111,3 +218,34 @@ type SessionRecap struct {
 	MessageCount int64  `json:"message_count"`
 	CreatedAt    int64  `json:"created_at"`
 }
+
+type SessionUsage struct {
+	SessionID           string  `json:"session_id"`
+	Category            string  `json:"category"`
+	Name                string  `json:"name"`
+	Model               string  `json:"model"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Cost                float64 `json:"cost"`
+	UpdatedAt           int64   `json:"updated_at"`
+}
+
+type ToolExecution struct {
+	ID         int64         `json:"id"`
+	Seq        int64         `json:"seq"`
+	SessionID  string        `json:"session_id"`
+	AgentID    string        `json:"agent_id"`
+	Tool       string        `json:"tool"`
+	CallID     string        `json:"call_id"`
+	Input      string        `json:"input"`
+	Status     string        `json:"status"`
+	ExitCode   sql.NullInt64 `json:"exit_code"`
+	DurationMs int64         `json:"duration_ms"`
+	Decision   string        `json:"decision"`
+	Via        string        `json:"via"`
+	Hash       string        `json:"hash"`
+	CreatedAt  int64         `json:"created_at"`
+}
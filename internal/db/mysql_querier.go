@@ -267,16 +267,17 @@ func (q *MySQLQuerier) ListMessagesBySession(ctx context.Context, sessionID stri
 
 func mysqlMessageToMessage(m mysqldb.Message) Message {
 	return Message{
-		ID:         m.ID,
-		SessionID:  m.SessionID,
-		Role:       m.Role,
-		Parts:      m.Parts,
-		Model:      m.Model,
-		Seq:        m.Seq,
-		CreatedAt:  m.CreatedAt,
-		UpdatedAt:  m.UpdatedAt,
-		FinishedAt: m.FinishedAt,
-		Synthetic:  m.Synthetic,
+		ID:           m.ID,
+		SessionID:    m.SessionID,
+		Role:         m.Role,
+		Parts:        m.Parts,
+		Model:        m.Model,
+		Seq:          m.Seq,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+		FinishedAt:   m.FinishedAt,
+		Synthetic:    m.Synthetic,
+		SupersededAt: m.SupersededAt,
 	}
 }
 
@@ -294,6 +295,11 @@ func (q *MySQLQuerier) DeleteMessage(ctx context.Context, id string) error {
 	return q.queries.DeleteMessage(ctx, id)
 }
 
+// SupersedeMessage hides a message from session listings without deleting it
+func (q *MySQLQuerier) SupersedeMessage(ctx context.Context, id string) error {
+	return q.queries.SupersedeMessage(ctx, id)
+}
+
 // DeleteSessionMessages deletes all messages for a session
 func (q *MySQLQuerier) DeleteSessionMessages(ctx context.Context, sessionID string) error {
 	return q.queries.DeleteSessionMessages(ctx, sessionID)
@@ -320,6 +326,41 @@ func (q *MySQLQuerier) ListLatestMessagesBySession(ctx context.Context, arg List
 	return messages, nil
 }
 
+// ListMessagesBefore lists up to N messages preceding a cursor in a session
+func (q *MySQLQuerier) ListMessagesBefore(ctx context.Context, arg ListMessagesBeforeParams) ([]Message, error) {
+	mysqlMessages, err := q.queries.ListMessagesBefore(ctx, mysqldb.ListMessagesBeforeParams{
+		SessionID:       arg.SessionID,
+		BeforeSeq:       arg.BeforeSeq,
+		BeforeCreatedAt: arg.BeforeCreatedAt,
+		Limit:           int32(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, len(mysqlMessages))
+	for i, m := range mysqlMessages {
+		messages[i] = mysqlMessageToMessage(m)
+	}
+	return messages, nil
+}
+
+// ListMessagesFrom lists the messages of a session from a cursor onwards
+func (q *MySQLQuerier) ListMessagesFrom(ctx context.Context, arg ListMessagesFromParams) ([]Message, error) {
+	mysqlMessages, err := q.queries.ListMessagesFrom(ctx, mysqldb.ListMessagesFromParams{
+		SessionID:     arg.SessionID,
+		FromSeq:       arg.FromSeq,
+		FromCreatedAt: arg.FromCreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, len(mysqlMessages))
+	for i, m := range mysqlMessages {
+		messages[i] = mysqlMessageToMessage(m)
+	}
+	return messages, nil
+}
+
 // CreateFile creates a file and returns it
 func (q *MySQLQuerier) CreateFile(ctx context.Context, arg CreateFileParams) (File, error) {
 	_, err := q.queries.CreateFile(ctx, mysqldb.CreateFileParams{
@@ -559,6 +600,7 @@ func (q *MySQLQuerier) GetFlowState(ctx context.Context, sessionID string) (Flow
 		Iteration:      int64(fs.Iteration),
 		CreatedAt:      fs.CreatedAt,
 		UpdatedAt:      fs.UpdatedAt,
+		Approval:       fs.Approval,
 	}, nil
 }
 
@@ -583,6 +625,7 @@ func (q *MySQLQuerier) ListFlowStatesByRootSession(ctx context.Context, rootSess
 			Iteration:      int64(fs.Iteration),
 			CreatedAt:      fs.CreatedAt,
 			UpdatedAt:      fs.UpdatedAt,
+			Approval:       fs.Approval,
 		}
 	}
 	return states, nil
@@ -609,6 +652,7 @@ func (q *MySQLQuerier) ListFlowStatesByFlowID(ctx context.Context, flowID string
 			Iteration:      int64(fs.Iteration),
 			CreatedAt:      fs.CreatedAt,
 			UpdatedAt:      fs.UpdatedAt,
+			Approval:       fs.Approval,
 		}
 	}
 	return states, nil
@@ -630,6 +674,18 @@ func (q *MySQLQuerier) UpdateFlowState(ctx context.Context, arg UpdateFlowStateP
 	return q.GetFlowState(ctx, arg.SessionID)
 }
 
+// SetFlowStateApproval records a gate decision and returns the flow state
+func (q *MySQLQuerier) SetFlowStateApproval(ctx context.Context, arg SetFlowStateApprovalParams) (FlowState, error) {
+	_, err := q.queries.SetFlowStateApproval(ctx, mysqldb.SetFlowStateApprovalParams{
+		Approval:  arg.Approval,
+		SessionID: arg.SessionID,
+	})
+	if err != nil {
+		return FlowState{}, err
+	}
+	return q.GetFlowState(ctx, arg.SessionID)
+}
+
 // DeleteFlowStatesByRootSession deletes all flow states for a root session
 func (q *MySQLQuerier) DeleteFlowStatesByRootSession(ctx context.Context, rootSessionID string) error {
 	return q.queries.DeleteFlowStatesByRootSession(ctx, rootSessionID)
@@ -843,3 +899,401 @@ func (q *MySQLQuerier) UpsertRecap(ctx context.Context, arg UpsertRecapParams) (
 func (q *MySQLQuerier) DeleteRecapBySessionID(ctx context.Context, sessionID string) error {
 	return q.queries.DeleteRecapBySessionID(ctx, sessionID)
 }
+
+// AddSessionUsage adds a request's usage to a session's per-agent or per-tool totals
+func (q *MySQLQuerier) AddSessionUsage(ctx context.Context, arg AddSessionUsageParams) error {
+	return q.queries.AddSessionUsage(ctx, mysqldb.AddSessionUsageParams(arg))
+}
+
+// ListSessionUsage lists the usage totals recorded on a session
+func (q *MySQLQuerier) ListSessionUsage(ctx context.Context, sessionID string) ([]SessionUsage, error) {
+	rows, err := q.queries.ListSessionUsage(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]SessionUsage, len(rows))
+	for i, r := range rows {
+		usage[i] = SessionUsage(r)
+	}
+	return usage, nil
+}
+
+// GetRuntimeSnapshot gets the runtime snapshot of a session
+func (q *MySQLQuerier) GetRuntimeSnapshot(ctx context.Context, sessionID string) (RuntimeSnapshot, error) {
+	r, err := q.queries.GetRuntimeSnapshot(ctx, sessionID)
+	if err != nil {
+		return RuntimeSnapshot{}, err
+	}
+	return RuntimeSnapshot(r), nil
+}
+
+// UpsertRuntimeSnapshot creates or replaces the runtime snapshot of a session
+func (q *MySQLQuerier) UpsertRuntimeSnapshot(ctx context.Context, arg UpsertRuntimeSnapshotParams) error {
+	return q.queries.UpsertRuntimeSnapshot(ctx, mysqldb.UpsertRuntimeSnapshotParams(arg))
+}
+
+// DeleteRuntimeSnapshot deletes the runtime snapshot of a session
+func (q *MySQLQuerier) DeleteRuntimeSnapshot(ctx context.Context, sessionID string) error {
+	return q.queries.DeleteRuntimeSnapshot(ctx, sessionID)
+}
+
+// CreateCheckpoint records a file pre-image, ignoring duplicates for the same message and path
+func (q *MySQLQuerier) CreateCheckpoint(ctx context.Context, arg CreateCheckpointParams) error {
+	return q.queries.CreateCheckpoint(ctx, mysqldb.CreateCheckpointParams{
+		ID:        arg.ID,
+		SessionID: arg.SessionID,
+		MessageID: arg.MessageID,
+		Path:      arg.Path,
+		Content:   arg.Content,
+		Existed:   arg.Existed,
+	})
+}
+
+// ListCheckpointsSinceMessage lists checkpoints recorded at or after a message
+func (q *MySQLQuerier) ListCheckpointsSinceMessage(ctx context.Context, arg ListCheckpointsSinceMessageParams) ([]Checkpoint, error) {
+	rows, err := q.queries.ListCheckpointsSinceMessage(ctx, mysqldb.ListCheckpointsSinceMessageParams{
+		SessionID: arg.SessionID,
+		MessageID: arg.MessageID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]Checkpoint, len(rows))
+	for i, c := range rows {
+		checkpoints[i] = Checkpoint{
+			ID:        c.ID,
+			SessionID: c.SessionID,
+			MessageID: c.MessageID,
+			Path:      c.Path,
+			Content:   c.Content,
+			Existed:   c.Existed,
+			CreatedAt: c.CreatedAt,
+		}
+	}
+	return checkpoints, nil
+}
+
+// DeleteCheckpoint deletes a checkpoint by ID
+func (q *MySQLQuerier) DeleteCheckpoint(ctx context.Context, id string) error {
+	return q.queries.DeleteCheckpoint(ctx, id)
+}
+
+// GetProjectUsage sums cost and tokens over every session of a project
+func (q *MySQLQuerier) GetProjectUsage(ctx context.Context, projectID sql.NullString) (GetProjectUsageRow, error) {
+	row, err := q.queries.GetProjectUsage(ctx, projectID)
+	if err != nil {
+		return GetProjectUsageRow{}, err
+	}
+	return GetProjectUsageRow{Cost: row.Cost, Tokens: row.Tokens}, nil
+}
+
+// CreateQueuedRun creates a queued run and returns it
+func (q *MySQLQuerier) CreateQueuedRun(ctx context.Context, arg CreateQueuedRunParams) (QueuedRun, error) {
+	_, err := q.queries.CreateQueuedRun(ctx, mysqldb.CreateQueuedRunParams{
+		ID:       arg.ID,
+		Kind:     arg.Kind,
+		Target:   arg.Target,
+		Payload:  arg.Payload,
+		Priority: arg.Priority,
+		Provider: arg.Provider,
+		Project:  arg.Project,
+	})
+	if err != nil {
+		return QueuedRun{}, err
+	}
+	return q.GetQueuedRun(ctx, arg.ID)
+}
+
+// GetQueuedRun gets a queued run by ID
+func (q *MySQLQuerier) GetQueuedRun(ctx context.Context, id string) (QueuedRun, error) {
+	r, err := q.queries.GetQueuedRun(ctx, id)
+	if err != nil {
+		return QueuedRun{}, err
+	}
+	return mysqlQueuedRunToQueuedRun(r), nil
+}
+
+// ListQueuedRuns lists the most recently submitted runs
+func (q *MySQLQuerier) ListQueuedRuns(ctx context.Context, limit int64) ([]QueuedRun, error) {
+	rows, err := q.queries.ListQueuedRuns(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]QueuedRun, len(rows))
+	for i, r := range rows {
+		runs[i] = mysqlQueuedRunToQueuedRun(r)
+	}
+	return runs, nil
+}
+
+// ListPendingQueuedRuns lists queued and running runs in dispatch order
+func (q *MySQLQuerier) ListPendingQueuedRuns(ctx context.Context) ([]QueuedRun, error) {
+	rows, err := q.queries.ListPendingQueuedRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]QueuedRun, len(rows))
+	for i, r := range rows {
+		runs[i] = mysqlQueuedRunToQueuedRun(r)
+	}
+	return runs, nil
+}
+
+// ClaimQueuedRun marks a queued run as running if nobody claimed it first
+func (q *MySQLQuerier) ClaimQueuedRun(ctx context.Context, id string) (int64, error) {
+	return q.queries.ClaimQueuedRun(ctx, id)
+}
+
+// FinishQueuedRun records the terminal status of a queued or running run
+func (q *MySQLQuerier) FinishQueuedRun(ctx context.Context, arg FinishQueuedRunParams) (int64, error) {
+	return q.queries.FinishQueuedRun(ctx, mysqldb.FinishQueuedRunParams{
+		Status:    arg.Status,
+		SessionID: arg.SessionID,
+		Error:     arg.Error,
+		ID:        arg.ID,
+	})
+}
+
+// RequeueRunningRuns puts runs interrupted by a restart back in the queue
+func (q *MySQLQuerier) RequeueRunningRuns(ctx context.Context) error {
+	return q.queries.RequeueRunningRuns(ctx)
+}
+
+func mysqlQueuedRunToQueuedRun(r mysqldb.QueuedRun) QueuedRun {
+	return QueuedRun{
+		ID:         r.ID,
+		Kind:       r.Kind,
+		Target:     r.Target,
+		Payload:    r.Payload,
+		Priority:   r.Priority,
+		Provider:   r.Provider,
+		Project:    r.Project,
+		Status:     r.Status,
+		SessionID:  r.SessionID,
+		Error:      r.Error,
+		CreatedAt:  r.CreatedAt,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+}
+
+// CreateBlackboardEntry posts an entry to a root session's blackboard and returns it
+func (q *MySQLQuerier) CreateBlackboardEntry(ctx context.Context, arg CreateBlackboardEntryParams) (BlackboardEntry, error) {
+	_, err := q.queries.CreateBlackboardEntry(ctx, mysqldb.CreateBlackboardEntryParams{
+		ID:            arg.ID,
+		RootSessionID: arg.RootSessionID,
+		SessionID:     arg.SessionID,
+		Agent:         arg.Agent,
+		Topic:         arg.Topic,
+		Content:       arg.Content,
+	})
+	if err != nil {
+		return BlackboardEntry{}, err
+	}
+	return q.GetBlackboardEntry(ctx, arg.ID)
+}
+
+// GetBlackboardEntry gets a blackboard entry by ID
+func (q *MySQLQuerier) GetBlackboardEntry(ctx context.Context, id string) (BlackboardEntry, error) {
+	e, err := q.queries.GetBlackboardEntry(ctx, id)
+	if err != nil {
+		return BlackboardEntry{}, err
+	}
+	return BlackboardEntry(e), nil
+}
+
+// ListBlackboardEntries lists a root session's blackboard, oldest first
+func (q *MySQLQuerier) ListBlackboardEntries(ctx context.Context, rootSessionID string) ([]BlackboardEntry, error) {
+	rows, err := q.queries.ListBlackboardEntries(ctx, rootSessionID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]BlackboardEntry, len(rows))
+	for i, e := range rows {
+		entries[i] = BlackboardEntry(e)
+	}
+	return entries, nil
+}
+
+// CreateFlowScheduleRun records a scheduled flow run unless its slot was already taken
+func (q *MySQLQuerier) CreateFlowScheduleRun(ctx context.Context, arg CreateFlowScheduleRunParams) (int64, error) {
+	return q.queries.CreateFlowScheduleRun(ctx, mysqldb.CreateFlowScheduleRunParams(arg))
+}
+
+// CountRunningFlowScheduleRuns counts a flow's scheduled runs still in progress
+func (q *MySQLQuerier) CountRunningFlowScheduleRuns(ctx context.Context, arg CountRunningFlowScheduleRunsParams) (int64, error) {
+	return q.queries.CountRunningFlowScheduleRuns(ctx, mysqldb.CountRunningFlowScheduleRunsParams(arg))
+}
+
+// FinishFlowScheduleRun records the outcome of a running scheduled flow run
+func (q *MySQLQuerier) FinishFlowScheduleRun(ctx context.Context, arg FinishFlowScheduleRunParams) error {
+	return q.queries.FinishFlowScheduleRun(ctx, mysqldb.FinishFlowScheduleRunParams(arg))
+}
+
+// FailRunningFlowScheduleRuns marks a project's interrupted scheduled runs as failed
+func (q *MySQLQuerier) FailRunningFlowScheduleRuns(ctx context.Context, arg FailRunningFlowScheduleRunsParams) error {
+	return q.queries.FailRunningFlowScheduleRuns(ctx, mysqldb.FailRunningFlowScheduleRunsParams(arg))
+}
+
+// ListFlowScheduleRuns lists scheduled flow runs, newest slot first
+func (q *MySQLQuerier) ListFlowScheduleRuns(ctx context.Context, arg ListFlowScheduleRunsParams) ([]FlowScheduleRun, error) {
+	rows, err := q.queries.ListFlowScheduleRuns(ctx, mysqldb.ListFlowScheduleRunsParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]FlowScheduleRun, len(rows))
+	for i, r := range rows {
+		runs[i] = FlowScheduleRun(r)
+	}
+	return runs, nil
+}
+
+// CreateToolExecution appends a tool call to the audit table
+func (q *MySQLQuerier) CreateToolExecution(ctx context.Context, arg CreateToolExecutionParams) error {
+	return q.queries.CreateToolExecution(ctx, mysqldb.CreateToolExecutionParams(arg))
+}
+
+// ListToolExecutions lists audited tool calls matching a filter, oldest first
+func (q *MySQLQuerier) ListToolExecutions(ctx context.Context, arg ListToolExecutionsParams) ([]ToolExecution, error) {
+	rows, err := q.queries.ListToolExecutions(ctx, mysqldb.ListToolExecutionsParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	executions := make([]ToolExecution, len(rows))
+	for i, r := range rows {
+		executions[i] = ToolExecution(r)
+	}
+	return executions, nil
+}
+
+// CreateMessageUsage records the usage of one model request
+func (q *MySQLQuerier) CreateMessageUsage(ctx context.Context, arg CreateMessageUsageParams) error {
+	return q.queries.CreateMessageUsage(ctx, mysqldb.CreateMessageUsageParams(arg))
+}
+
+// ListMessageUsage lists a project's request usage in a time range, oldest first
+func (q *MySQLQuerier) ListMessageUsage(ctx context.Context, arg ListMessageUsageParams) ([]MessageUsage, error) {
+	rows, err := q.queries.ListMessageUsage(ctx, mysqldb.ListMessageUsageParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]MessageUsage, len(rows))
+	for i, r := range rows {
+		usage[i] = MessageUsage(r)
+	}
+	return usage, nil
+}
+
+// UpsertMessageFeedback records or replaces the user's rating of a message
+func (q *MySQLQuerier) UpsertMessageFeedback(ctx context.Context, arg UpsertMessageFeedbackParams) error {
+	return q.queries.UpsertMessageFeedback(ctx, mysqldb.UpsertMessageFeedbackParams(arg))
+}
+
+// GetMessageFeedback gets the user's rating of a message
+func (q *MySQLQuerier) GetMessageFeedback(ctx context.Context, messageID string) (MessageFeedback, error) {
+	r, err := q.queries.GetMessageFeedback(ctx, messageID)
+	if err != nil {
+		return MessageFeedback{}, err
+	}
+	return MessageFeedback(r), nil
+}
+
+// ListMessageFeedback lists the ratings given in a session, oldest first
+func (q *MySQLQuerier) ListMessageFeedback(ctx context.Context, sessionID string) ([]MessageFeedback, error) {
+	rows, err := q.queries.ListMessageFeedback(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	feedback := make([]MessageFeedback, len(rows))
+	for i, r := range rows {
+		feedback[i] = MessageFeedback(r)
+	}
+	return feedback, nil
+}
+
+// DeleteMessageFeedback removes the user's rating of a message
+func (q *MySQLQuerier) DeleteMessageFeedback(ctx context.Context, messageID string) error {
+	return q.queries.DeleteMessageFeedback(ctx, messageID)
+}
+
+// CreateCodeChunk stores one embedded chunk of a workspace file
+func (q *MySQLQuerier) CreateCodeChunk(ctx context.Context, arg CreateCodeChunkParams) error {
+	return q.queries.CreateCodeChunk(ctx, mysqldb.CreateCodeChunkParams(arg))
+}
+
+// DeleteCodeChunksForPath drops the chunks of one file
+func (q *MySQLQuerier) DeleteCodeChunksForPath(ctx context.Context, arg DeleteCodeChunksForPathParams) error {
+	return q.queries.DeleteCodeChunksForPath(ctx, mysqldb.DeleteCodeChunksForPathParams(arg))
+}
+
+// DeleteCodeChunksForProject drops a project's whole index
+func (q *MySQLQuerier) DeleteCodeChunksForProject(ctx context.Context, projectID string) error {
+	return q.queries.DeleteCodeChunksForProject(ctx, projectID)
+}
+
+// ListCodeChunkFiles lists the indexed files of a project with their hashes
+func (q *MySQLQuerier) ListCodeChunkFiles(ctx context.Context, projectID string) ([]ListCodeChunkFilesRow, error) {
+	rows, err := q.queries.ListCodeChunkFiles(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]ListCodeChunkFilesRow, len(rows))
+	for i, r := range rows {
+		files[i] = ListCodeChunkFilesRow(r)
+	}
+	return files, nil
+}
+
+// ListCodeChunks lists a project's chunks embedded with a model
+func (q *MySQLQuerier) ListCodeChunks(ctx context.Context, arg ListCodeChunksParams) ([]CodeChunk, error) {
+	rows, err := q.queries.ListCodeChunks(ctx, mysqldb.ListCodeChunksParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]CodeChunk, len(rows))
+	for i, r := range rows {
+		chunks[i] = CodeChunk(r)
+	}
+	return chunks, nil
+}
+
+// CreateMemory stores a project memory
+func (q *MySQLQuerier) CreateMemory(ctx context.Context, arg CreateMemoryParams) error {
+	return q.queries.CreateMemory(ctx, mysqldb.CreateMemoryParams(arg))
+}
+
+// UpdateMemory replaces the content and priority of a memory
+func (q *MySQLQuerier) UpdateMemory(ctx context.Context, arg UpdateMemoryParams) error {
+	return q.queries.UpdateMemory(ctx, mysqldb.UpdateMemoryParams(arg))
+}
+
+// GetMemory gets a project memory by ID
+func (q *MySQLQuerier) GetMemory(ctx context.Context, arg GetMemoryParams) (Memory, error) {
+	m, err := q.queries.GetMemory(ctx, mysqldb.GetMemoryParams(arg))
+	return Memory(m), err
+}
+
+// GetMemoryByName gets a project memory by name
+func (q *MySQLQuerier) GetMemoryByName(ctx context.Context, arg GetMemoryByNameParams) (Memory, error) {
+	m, err := q.queries.GetMemoryByName(ctx, mysqldb.GetMemoryByNameParams(arg))
+	return Memory(m), err
+}
+
+// ListMemories lists a project's memories, most recently updated first
+func (q *MySQLQuerier) ListMemories(ctx context.Context, projectID string) ([]Memory, error) {
+	rows, err := q.queries.ListMemories(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]Memory, len(rows))
+	for i, r := range rows {
+		memories[i] = Memory(r)
+	}
+	return memories, nil
+}
+
+// DeleteMemory deletes a project memory
+func (q *MySQLQuerier) DeleteMemory(ctx context.Context, arg DeleteMemoryParams) error {
+	return q.queries.DeleteMemory(ctx, mysqldb.DeleteMemoryParams(arg))
+}
@@ -11,63 +11,107 @@ import (
 
 type Querier interface {
 	AddBridgeAllowlistEntry(ctx context.Context, arg AddBridgeAllowlistEntryParams) error
+	AddSessionUsage(ctx context.Context, arg AddSessionUsageParams) error
 	// Atomically marks a cron job as firing only if it is still due. Returns the
 	// number of rows affected; 0 means another worker already claimed it or the
 	// row's next_run_at moved into the future.
 	ClaimCronJobForFiring(ctx context.Context, arg ClaimCronJobForFiringParams) (int64, error)
+	ClaimQueuedRun(ctx context.Context, id string) (int64, error)
 	ClearStaleFiring(ctx context.Context) error
 	CountActiveCronJobsBySession(ctx context.Context, sessionID string) (int64, error)
 	CountBridgeSessionsByIdentity(ctx context.Context, arg CountBridgeSessionsByIdentityParams) (int64, error)
+	CountRunningFlowScheduleRuns(ctx context.Context, arg CountRunningFlowScheduleRunsParams) (int64, error)
+	CreateBlackboardEntry(ctx context.Context, arg CreateBlackboardEntryParams) (BlackboardEntry, error)
+	CreateCheckpoint(ctx context.Context, arg CreateCheckpointParams) error
+	CreateCodeChunk(ctx context.Context, arg CreateCodeChunkParams) error
 	CreateCronJob(ctx context.Context, arg CreateCronJobParams) (CronJob, error)
 	CreateFile(ctx context.Context, arg CreateFileParams) (File, error)
+	CreateFlowScheduleRun(ctx context.Context, arg CreateFlowScheduleRunParams) (int64, error)
 	CreateFlowState(ctx context.Context, arg CreateFlowStateParams) (FlowState, error)
+	CreateMemory(ctx context.Context, arg CreateMemoryParams) error
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreateMessageUsage(ctx context.Context, arg CreateMessageUsageParams) error
+	CreateQueuedRun(ctx context.Context, arg CreateQueuedRunParams) (QueuedRun, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateToolExecution(ctx context.Context, arg CreateToolExecutionParams) error
 	DeleteBridgeSessionByPeer(ctx context.Context, arg DeleteBridgeSessionByPeerParams) error
 	DeleteBridgeSessionsByIdentity(ctx context.Context, arg DeleteBridgeSessionsByIdentityParams) error
 	DeleteBridgeSessionsBySession(ctx context.Context, arg DeleteBridgeSessionsBySessionParams) error
+	DeleteCheckpoint(ctx context.Context, id string) error
+	DeleteCodeChunksForPath(ctx context.Context, arg DeleteCodeChunksForPathParams) error
+	DeleteCodeChunksForProject(ctx context.Context, projectID string) error
 	DeleteCronJob(ctx context.Context, id string) error
 	DeleteFile(ctx context.Context, id string) error
 	DeleteFlowStatesByRootSession(ctx context.Context, rootSessionID string) error
+	DeleteMemory(ctx context.Context, arg DeleteMemoryParams) error
 	DeleteMessage(ctx context.Context, id string) error
+	DeleteMessageFeedback(ctx context.Context, messageID string) error
 	DeleteRecapBySessionID(ctx context.Context, sessionID string) error
+	DeleteRuntimeSnapshot(ctx context.Context, sessionID string) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
 	DeleteSessionTree(ctx context.Context, arg DeleteSessionTreeParams) error
+	FailRunningFlowScheduleRuns(ctx context.Context, arg FailRunningFlowScheduleRunsParams) error
+	FinishFlowScheduleRun(ctx context.Context, arg FinishFlowScheduleRunParams) error
+	FinishQueuedRun(ctx context.Context, arg FinishQueuedRunParams) (int64, error)
+	GetBlackboardEntry(ctx context.Context, id string) (BlackboardEntry, error)
 	GetBridgeSession(ctx context.Context, arg GetBridgeSessionParams) (BridgeSession, error)
 	GetCronJob(ctx context.Context, id string) (CronJob, error)
 	GetFile(ctx context.Context, id string) (File, error)
 	GetFileByPathAndSession(ctx context.Context, arg GetFileByPathAndSessionParams) (File, error)
 	GetFlowState(ctx context.Context, sessionID string) (FlowState, error)
 	GetMaxSeqBySession(ctx context.Context, sessionID string) (int64, error)
+	GetMemory(ctx context.Context, arg GetMemoryParams) (Memory, error)
+	GetMemoryByName(ctx context.Context, arg GetMemoryByNameParams) (Memory, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
+	GetMessageFeedback(ctx context.Context, messageID string) (MessageFeedback, error)
+	GetProjectUsage(ctx context.Context, projectID sql.NullString) (GetProjectUsageRow, error)
+	GetQueuedRun(ctx context.Context, id string) (QueuedRun, error)
 	GetRecapBySessionID(ctx context.Context, sessionID string) (SessionRecap, error)
+	GetRuntimeSnapshot(ctx context.Context, sessionID string) (RuntimeSnapshot, error)
 	GetSessionByID(ctx context.Context, id string) (Session, error)
 	IsBridgeAllowlisted(ctx context.Context, arg IsBridgeAllowlistedParams) (int64, error)
 	ListActiveCronJobs(ctx context.Context) ([]CronJob, error)
+	ListBlackboardEntries(ctx context.Context, rootSessionID string) ([]BlackboardEntry, error)
 	ListBridgeAllowlist(ctx context.Context, arg ListBridgeAllowlistParams) ([]BridgeAllowlist, error)
 	ListBridgeSessionsByIdentity(ctx context.Context, arg ListBridgeSessionsByIdentityParams) ([]BridgeSession, error)
 	ListBridgeSessionsBySession(ctx context.Context, arg ListBridgeSessionsBySessionParams) ([]BridgeSession, error)
+	ListCheckpointsSinceMessage(ctx context.Context, arg ListCheckpointsSinceMessageParams) ([]Checkpoint, error)
 	ListChildSessions(ctx context.Context, rootSessionID sql.NullString) ([]Session, error)
+	ListCodeChunkFiles(ctx context.Context, projectID string) ([]ListCodeChunkFilesRow, error)
+	ListCodeChunks(ctx context.Context, arg ListCodeChunksParams) ([]CodeChunk, error)
 	ListCronJobsBySession(ctx context.Context, sessionID string) ([]CronJob, error)
 	ListDueCronJobs(ctx context.Context, nextRunAt sql.NullInt64) ([]CronJob, error)
 	ListFilesByPath(ctx context.Context, path string) ([]File, error)
 	ListFilesBySession(ctx context.Context, sessionID string) ([]File, error)
 	ListFilesBySessionTree(ctx context.Context, rootSessionID sql.NullString) ([]File, error)
+	ListFlowScheduleRuns(ctx context.Context, arg ListFlowScheduleRunsParams) ([]FlowScheduleRun, error)
 	ListFlowStatesByFlowID(ctx context.Context, flowID string) ([]FlowState, error)
 	ListFlowStatesByRootSession(ctx context.Context, rootSessionID string) ([]FlowState, error)
 	ListLatestMessagesBySession(ctx context.Context, arg ListLatestMessagesBySessionParams) ([]Message, error)
 	ListLatestSessionFiles(ctx context.Context, sessionID string) ([]File, error)
 	ListLatestSessionTreeFiles(ctx context.Context, rootSessionID sql.NullString) ([]File, error)
+	ListMemories(ctx context.Context, projectID string) ([]Memory, error)
+	ListMessageFeedback(ctx context.Context, sessionID string) ([]MessageFeedback, error)
+	ListMessageUsage(ctx context.Context, arg ListMessageUsageParams) ([]MessageUsage, error)
+	ListMessagesBefore(ctx context.Context, arg ListMessagesBeforeParams) ([]Message, error)
 	ListMessagesBySession(ctx context.Context, sessionID string) ([]Message, error)
+	ListMessagesFrom(ctx context.Context, arg ListMessagesFromParams) ([]Message, error)
 	ListMissedOneShots(ctx context.Context, nextRunAt sql.NullInt64) ([]CronJob, error)
+	ListPendingQueuedRuns(ctx context.Context) ([]QueuedRun, error)
+	ListQueuedRuns(ctx context.Context, limit int64) ([]QueuedRun, error)
+	ListSessionUsage(ctx context.Context, sessionID string) ([]SessionUsage, error)
 	ListSessions(ctx context.Context, projectID sql.NullString) ([]Session, error)
+	ListToolExecutions(ctx context.Context, arg ListToolExecutionsParams) ([]ToolExecution, error)
 	MarkBridgeSessionMentionConsumed(ctx context.Context, arg MarkBridgeSessionMentionConsumedParams) error
 	RemoveBridgeAllowlistEntry(ctx context.Context, arg RemoveBridgeAllowlistEntryParams) error
 	RenameSession(ctx context.Context, arg RenameSessionParams) (Session, error)
+	RequeueRunningRuns(ctx context.Context) error
 	SetCronJobFiring(ctx context.Context, arg SetCronJobFiringParams) error
+	SetFlowStateApproval(ctx context.Context, arg SetFlowStateApprovalParams) (FlowState, error)
 	SetGeneratedTitle(ctx context.Context, arg SetGeneratedTitleParams) (int64, error)
+	SupersedeMessage(ctx context.Context, id string) error
 	UpdateBridgeSessionPeerID(ctx context.Context, arg UpdateBridgeSessionPeerIDParams) error
 	UpdateBridgeSessionSessionID(ctx context.Context, arg UpdateBridgeSessionSessionIDParams) error
 	UpdateCronJobAfterRun(ctx context.Context, arg UpdateCronJobAfterRunParams) (CronJob, error)
@@ -76,10 +120,13 @@ type Querier interface {
 	UpdateCronJobStatus(ctx context.Context, arg UpdateCronJobStatusParams) error
 	UpdateFile(ctx context.Context, arg UpdateFileParams) (File, error)
 	UpdateFlowState(ctx context.Context, arg UpdateFlowStateParams) (FlowState, error)
+	UpdateMemory(ctx context.Context, arg UpdateMemoryParams) error
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error)
 	UpsertBridgeSession(ctx context.Context, arg UpsertBridgeSessionParams) (BridgeSession, error)
+	UpsertMessageFeedback(ctx context.Context, arg UpsertMessageFeedbackParams) error
 	UpsertRecap(ctx context.Context, arg UpsertRecapParams) (SessionRecap, error)
+	UpsertRuntimeSnapshot(ctx context.Context, arg UpsertRuntimeSnapshotParams) error
 }
 
 var _ Querier = (*Queries)(nil)
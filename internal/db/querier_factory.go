@@ -15,12 +15,18 @@ func NewQuerier(db *sql.DB) QuerierWithTx {
 	if err != nil {
 		// Fallback to SQLite if provider creation fails
 		logging.Error("Failed to create database provider, falling back to SQLite", "error", err)
-		return &queriesWrapper{Queries: New(db)}
+		return NewSQLiteQuerier(db)
 	}
 
 	if provider.Type() == config.ProviderMySQL {
 		return &mysqlQuerierWrapper{MySQLQuerier: NewMySQLQuerier(db)}
 	}
 
+	return NewSQLiteQuerier(db)
+}
+
+// NewSQLiteQuerier returns the SQLite querier without consulting the
+// config, for callers such as tests that already know the provider.
+func NewSQLiteQuerier(db *sql.DB) QuerierWithTx {
 	return &queriesWrapper{Queries: New(db)}
 }
@@ -44,6 +44,7 @@ CREATE TABLE IF NOT EXISTS messages (
   updated_at BIGINT NOT NULL,
   finished_at BIGINT,
   synthetic TINYINT(1) NOT NULL DEFAULT 0,
+  superseded_at BIGINT,
   KEY idx_messages_session_id (session_id),
 
   CONSTRAINT fk_messages_session_id FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
@@ -61,6 +62,7 @@ CREATE TABLE IF NOT EXISTS flow_states (
   iteration INT NOT NULL DEFAULT 1,
   created_at BIGINT NOT NULL,
   updated_at BIGINT NOT NULL,
+  approval VARCHAR(16) NULL,
   KEY idx_flow_states_root_session (root_session_id),
   KEY idx_flow_states_flow_id (flow_id),
   CONSTRAINT fk_flow_states_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
@@ -127,3 +129,160 @@ CREATE TABLE IF NOT EXISTS bridge_allowlist (
   created_at BIGINT NOT NULL,
   PRIMARY KEY (project_id, channel, identity_id, peer_id)
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS checkpoints (
+  id VARCHAR(255) PRIMARY KEY,
+  session_id VARCHAR(255) NOT NULL,
+  message_id VARCHAR(255) NOT NULL,
+  path VARCHAR(1024) NOT NULL,
+  content LONGTEXT NOT NULL,
+  existed TINYINT(1) NOT NULL DEFAULT 1,
+  created_at BIGINT NOT NULL,
+  UNIQUE KEY idx_checkpoints_message_path (session_id, message_id, path(255)),
+  KEY idx_checkpoints_session_id (session_id),
+  CONSTRAINT fk_checkpoints_session_id FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS queued_runs (
+  id VARCHAR(255) PRIMARY KEY,
+  kind VARCHAR(32) NOT NULL,
+  target VARCHAR(255) NOT NULL DEFAULT '',
+  payload LONGTEXT NOT NULL,
+  priority BIGINT NOT NULL DEFAULT 0,
+  provider VARCHAR(64) NOT NULL DEFAULT '',
+  project VARCHAR(255) NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT 'queued',
+  session_id VARCHAR(255),
+  error LONGTEXT,
+  created_at BIGINT NOT NULL,
+  started_at BIGINT,
+  finished_at BIGINT,
+  KEY idx_queued_runs_dispatch (status, priority, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS blackboard_entries (
+  id VARCHAR(255) PRIMARY KEY,
+  root_session_id VARCHAR(255) NOT NULL,
+  session_id VARCHAR(255) NOT NULL,
+  agent VARCHAR(255) NOT NULL DEFAULT '',
+  topic VARCHAR(255) NOT NULL DEFAULT '',
+  content LONGTEXT NOT NULL,
+  created_at BIGINT NOT NULL,
+  KEY idx_blackboard_entries_root (root_session_id, created_at),
+  CONSTRAINT fk_blackboard_entries_root FOREIGN KEY (root_session_id) REFERENCES sessions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS flow_schedule_runs (
+  id VARCHAR(255) PRIMARY KEY,
+  project_id VARCHAR(255) NOT NULL DEFAULT '',
+  flow_id VARCHAR(255) NOT NULL,
+  scheduled_at BIGINT NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  session_id VARCHAR(255),
+  error LONGTEXT,
+  started_at BIGINT NOT NULL,
+  finished_at BIGINT,
+  UNIQUE KEY idx_flow_schedule_runs_slot (project_id, flow_id, scheduled_at),
+  KEY idx_flow_schedule_runs_status (project_id, flow_id, status)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS runtime_snapshots (
+  session_id VARCHAR(255) PRIMARY KEY,
+  version VARCHAR(64) NOT NULL DEFAULT '',
+  state LONGTEXT NOT NULL,
+  updated_at BIGINT NOT NULL,
+  CONSTRAINT fk_runtime_snapshots_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS session_usage (
+  session_id VARCHAR(255) NOT NULL,
+  category VARCHAR(16) NOT NULL,
+  name VARCHAR(191) NOT NULL,
+  model VARCHAR(191) NOT NULL DEFAULT '',
+  requests BIGINT NOT NULL DEFAULT 0,
+  input_tokens BIGINT NOT NULL DEFAULT 0,
+  output_tokens BIGINT NOT NULL DEFAULT 0,
+  cache_creation_tokens BIGINT NOT NULL DEFAULT 0,
+  cache_read_tokens BIGINT NOT NULL DEFAULT 0,
+  cost DOUBLE NOT NULL DEFAULT 0,
+  updated_at BIGINT NOT NULL,
+  PRIMARY KEY (session_id, category, name, model),
+  CONSTRAINT fk_session_usage_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS tool_executions (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  seq BIGINT NOT NULL DEFAULT 0,
+  session_id VARCHAR(255) NOT NULL DEFAULT '',
+  agent_id VARCHAR(255) NOT NULL DEFAULT '',
+  tool VARCHAR(191) NOT NULL,
+  call_id VARCHAR(255) NOT NULL DEFAULT '',
+  input LONGTEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  exit_code BIGINT NULL,
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  decision VARCHAR(16) NOT NULL DEFAULT '',
+  via VARCHAR(32) NOT NULL DEFAULT '',
+  hash VARCHAR(64) NOT NULL DEFAULT '',
+  created_at BIGINT NOT NULL,
+  INDEX idx_tool_executions_created_at (created_at),
+  INDEX idx_tool_executions_session (session_id, created_at),
+  INDEX idx_tool_executions_tool (tool, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS message_feedback (
+  message_id VARCHAR(255) PRIMARY KEY,
+  session_id VARCHAR(255) NOT NULL,
+  rating VARCHAR(16) NOT NULL,
+  comment TEXT NOT NULL,
+  agent_id VARCHAR(255) NOT NULL DEFAULT '',
+  model VARCHAR(255) NOT NULL DEFAULT '',
+  created_at BIGINT NOT NULL,
+  updated_at BIGINT NOT NULL,
+  INDEX idx_message_feedback_session (session_id),
+  CONSTRAINT fk_message_feedback_message FOREIGN KEY (message_id) REFERENCES messages (id) ON DELETE CASCADE,
+  CONSTRAINT fk_message_feedback_session FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS code_chunks (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  project_id VARCHAR(255) NOT NULL,
+  path VARCHAR(767) NOT NULL,
+  start_line BIGINT NOT NULL,
+  end_line BIGINT NOT NULL,
+  content LONGTEXT NOT NULL,
+  file_hash VARCHAR(64) NOT NULL,
+  model VARCHAR(255) NOT NULL,
+  embedding LONGBLOB NOT NULL,
+  updated_at BIGINT NOT NULL,
+  INDEX idx_code_chunks_project_path (project_id, path(191))
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS memories (
+  id VARCHAR(255) NOT NULL PRIMARY KEY,
+  project_id VARCHAR(255) NOT NULL,
+  name VARCHAR(191) NOT NULL DEFAULT '',
+  content LONGTEXT NOT NULL,
+  priority VARCHAR(16) NOT NULL DEFAULT 'normal',
+  created_at BIGINT NOT NULL,
+  updated_at BIGINT NOT NULL,
+  INDEX idx_memories_project_name (project_id, name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS message_usage (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  message_id VARCHAR(255) NOT NULL DEFAULT '',
+  session_id VARCHAR(255) NOT NULL DEFAULT '',
+  project_id VARCHAR(255) NOT NULL DEFAULT '',
+  agent_id VARCHAR(255) NOT NULL DEFAULT '',
+  model VARCHAR(191) NOT NULL DEFAULT '',
+  input_tokens BIGINT NOT NULL DEFAULT 0,
+  output_tokens BIGINT NOT NULL DEFAULT 0,
+  cache_creation_tokens BIGINT NOT NULL DEFAULT 0,
+  cache_read_tokens BIGINT NOT NULL DEFAULT 0,
+  cost DOUBLE NOT NULL DEFAULT 0,
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  created_at BIGINT NOT NULL,
+  INDEX idx_message_usage_project (project_id, created_at),
+  INDEX idx_message_usage_session (session_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -110,6 +110,26 @@ func (q *Queries) DeleteSessionTree(ctx context.Context, arg DeleteSessionTreePa
 	return err
 }
 
+const getProjectUsage = `-- name: GetProjectUsage :one
+SELECT
+    CAST(COALESCE(SUM(cost), 0) AS REAL) AS cost,
+    CAST(COALESCE(SUM(total_prompt_tokens + total_completion_tokens), 0) AS INTEGER) AS tokens
+FROM sessions
+WHERE project_id = ?
+`
+
+type GetProjectUsageRow struct {
+	Cost   float64 `json:"cost"`
+	Tokens int64   `json:"tokens"`
+}
+
+func (q *Queries) GetProjectUsage(ctx context.Context, projectID sql.NullString) (GetProjectUsageRow, error) {
+	row := q.queryRow(ctx, q.getProjectUsageStmt, getProjectUsage, projectID)
+	var i GetProjectUsageRow
+	err := row.Scan(&i.Cost, &i.Tokens)
+	return i, err
+}
+
 const getSessionByID = `-- name: GetSessionByID :one
 SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id, total_prompt_tokens, total_completion_tokens, user_set_title
 FROM sessions
@@ -0,0 +1,20 @@
+-- name: CreateBlackboardEntry :one
+INSERT INTO blackboard_entries (
+    id,
+    root_session_id,
+    session_id,
+    agent,
+    topic,
+    content,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+) RETURNING *;
+
+-- name: GetBlackboardEntry :one
+SELECT * FROM blackboard_entries WHERE id = ? LIMIT 1;
+
+-- name: ListBlackboardEntries :many
+SELECT * FROM blackboard_entries
+WHERE root_session_id = ?
+ORDER BY created_at ASC, id ASC;
@@ -0,0 +1,25 @@
+-- name: CreateCheckpoint :exec
+INSERT INTO checkpoints (
+    id,
+    session_id,
+    message_id,
+    path,
+    content,
+    existed,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+ON CONFLICT(session_id, message_id, path) DO NOTHING;
+
+-- name: ListCheckpointsSinceMessage :many
+SELECT checkpoints.*
+FROM checkpoints
+JOIN messages ON messages.id = checkpoints.message_id
+WHERE checkpoints.session_id = sqlc.arg(session_id)
+  AND messages.seq >= (SELECT m.seq FROM messages m WHERE m.id = sqlc.arg(message_id))
+ORDER BY messages.seq ASC, checkpoints.created_at ASC;
+
+-- name: DeleteCheckpoint :exec
+DELETE FROM checkpoints
+WHERE id = ?;
@@ -0,0 +1,33 @@
+-- name: CreateCodeChunk :exec
+INSERT INTO code_chunks (
+    project_id,
+    path,
+    start_line,
+    end_line,
+    content,
+    file_hash,
+    model,
+    embedding,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteCodeChunksForPath :exec
+DELETE FROM code_chunks
+WHERE project_id = ? AND path = ?;
+
+-- name: DeleteCodeChunksForProject :exec
+DELETE FROM code_chunks
+WHERE project_id = ?;
+
+-- name: ListCodeChunkFiles :many
+SELECT DISTINCT path, file_hash, model
+FROM code_chunks
+WHERE project_id = ?;
+
+-- name: ListCodeChunks :many
+SELECT *
+FROM code_chunks
+WHERE project_id = ? AND model = ?
+ORDER BY path, start_line;
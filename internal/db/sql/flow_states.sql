@@ -45,5 +45,12 @@ SET status = ?,
 WHERE session_id = ?
 RETURNING *;
 
+-- name: SetFlowStateApproval :one
+UPDATE flow_states
+SET approval = ?,
+    updated_at = strftime('%s', 'now')
+WHERE session_id = ?
+RETURNING *;
+
 -- name: DeleteFlowStatesByRootSession :exec
 DELETE FROM flow_states WHERE root_session_id = ?;
@@ -0,0 +1,33 @@
+-- name: UpsertMessageFeedback :exec
+INSERT INTO message_feedback (
+    message_id,
+    session_id,
+    rating,
+    comment,
+    agent_id,
+    model,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(message_id) DO UPDATE SET
+    rating = excluded.rating,
+    comment = excluded.comment,
+    agent_id = excluded.agent_id,
+    model = excluded.model,
+    updated_at = excluded.updated_at;
+
+-- name: GetMessageFeedback :one
+SELECT *
+FROM message_feedback
+WHERE message_id = ? LIMIT 1;
+
+-- name: ListMessageFeedback :many
+SELECT *
+FROM message_feedback
+WHERE session_id = ?
+ORDER BY created_at, message_id;
+
+-- name: DeleteMessageFeedback :exec
+DELETE FROM message_feedback
+WHERE message_id = ?;
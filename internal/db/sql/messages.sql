@@ -6,7 +6,7 @@ WHERE id = ? LIMIT 1;
 -- name: ListMessagesBySession :many
 SELECT *
 FROM messages
-WHERE session_id = ?
+WHERE session_id = ? AND superseded_at IS NULL
 ORDER BY seq ASC, created_at ASC;
 
 -- name: CreateMessage :one
@@ -39,6 +39,11 @@ SET
 WHERE id = ?;
 
 
+-- name: SupersedeMessage :exec
+UPDATE messages
+SET superseded_at = strftime('%s', 'now')
+WHERE id = ? AND superseded_at IS NULL;
+
 -- name: DeleteMessage :exec
 DELETE FROM messages
 WHERE id = ?;
@@ -47,12 +52,34 @@ WHERE id = ?;
 SELECT * FROM (
     SELECT *
     FROM messages
-    WHERE session_id = ?
+    WHERE session_id = ? AND superseded_at IS NULL
     ORDER BY seq DESC, created_at DESC
     LIMIT ?
 ) sub
 ORDER BY seq ASC, created_at ASC;
 
+-- name: ListMessagesBefore :many
+SELECT * FROM (
+    SELECT *
+    FROM messages
+    WHERE session_id = sqlc.arg(session_id)
+      AND superseded_at IS NULL
+      AND (COALESCE(seq, 0) < sqlc.arg(before_seq)
+        OR (COALESCE(seq, 0) = sqlc.arg(before_seq) AND created_at < sqlc.arg(before_created_at)))
+    ORDER BY seq DESC, created_at DESC
+    LIMIT sqlc.arg(limit)
+) sub
+ORDER BY seq ASC, created_at ASC;
+
+-- name: ListMessagesFrom :many
+SELECT *
+FROM messages
+WHERE session_id = sqlc.arg(session_id)
+  AND superseded_at IS NULL
+  AND (COALESCE(seq, 0) > sqlc.arg(from_seq)
+    OR (COALESCE(seq, 0) = sqlc.arg(from_seq) AND created_at >= sqlc.arg(from_created_at)))
+ORDER BY seq ASC, created_at ASC;
+
 -- name: DeleteSessionMessages :exec
 DELETE FROM messages
 WHERE session_id = ?;
@@ -0,0 +1,20 @@
+-- name: CreateBlackboardEntry :execresult
+INSERT INTO blackboard_entries (
+    id,
+    root_session_id,
+    session_id,
+    agent,
+    topic,
+    content,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, UNIX_TIMESTAMP()
+);
+
+-- name: GetBlackboardEntry :one
+SELECT * FROM blackboard_entries WHERE id = ? LIMIT 1;
+
+-- name: ListBlackboardEntries :many
+SELECT * FROM blackboard_entries
+WHERE root_session_id = ?
+ORDER BY created_at ASC, id ASC;
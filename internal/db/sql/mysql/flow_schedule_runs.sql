@@ -0,0 +1,34 @@
+-- name: CreateFlowScheduleRun :execrows
+INSERT IGNORE INTO flow_schedule_runs (
+    id,
+    project_id,
+    flow_id,
+    scheduled_at,
+    status,
+    error,
+    started_at,
+    finished_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, UNIX_TIMESTAMP(), ?
+);
+
+-- name: CountRunningFlowScheduleRuns :one
+SELECT COUNT(*) FROM flow_schedule_runs
+WHERE project_id = ? AND flow_id = ? AND status = 'running';
+
+-- name: FinishFlowScheduleRun :exec
+UPDATE flow_schedule_runs
+SET status = ?, session_id = ?, error = ?, finished_at = UNIX_TIMESTAMP()
+WHERE id = ? AND status = 'running';
+
+-- name: FailRunningFlowScheduleRuns :exec
+UPDATE flow_schedule_runs
+SET status = 'failed', error = ?, finished_at = UNIX_TIMESTAMP()
+WHERE project_id = ? AND status = 'running';
+
+-- name: ListFlowScheduleRuns :many
+SELECT * FROM flow_schedule_runs
+WHERE project_id = sqlc.arg(project_id)
+  AND (sqlc.arg(flow_id) = '' OR flow_id = sqlc.arg(flow_id))
+ORDER BY scheduled_at DESC, started_at DESC
+LIMIT sqlc.arg(limit);
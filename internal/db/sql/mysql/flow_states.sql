@@ -43,5 +43,10 @@ SET status = ?,
     iteration = ?
 WHERE session_id = ?;
 
+-- name: SetFlowStateApproval :execresult
+UPDATE flow_states
+SET approval = ?
+WHERE session_id = ?;
+
 -- name: DeleteFlowStatesByRootSession :exec
 DELETE FROM flow_states WHERE root_session_id = ?;
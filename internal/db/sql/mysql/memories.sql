@@ -0,0 +1,33 @@
+-- name: CreateMemory :exec
+INSERT INTO memories (
+    id,
+    project_id,
+    name,
+    content,
+    priority,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateMemory :exec
+UPDATE memories
+SET content = ?, priority = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: GetMemory :one
+SELECT * FROM memories WHERE project_id = ? AND id = ? LIMIT 1;
+
+-- name: GetMemoryByName :one
+SELECT * FROM memories WHERE project_id = ? AND name = ? LIMIT 1;
+
+-- name: ListMemories :many
+SELECT *
+FROM memories
+WHERE project_id = ?
+ORDER BY updated_at DESC, id;
+
+-- name: DeleteMemory :exec
+DELETE FROM memories
+WHERE project_id = ? AND id = ?;
@@ -0,0 +1,25 @@
+-- name: CreateMessageUsage :exec
+INSERT INTO message_usage (
+    message_id,
+    session_id,
+    project_id,
+    agent_id,
+    model,
+    input_tokens,
+    output_tokens,
+    cache_creation_tokens,
+    cache_read_tokens,
+    cost,
+    duration_ms,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListMessageUsage :many
+SELECT *
+FROM message_usage
+WHERE project_id = sqlc.arg(project_id)
+  AND created_at >= sqlc.arg(since)
+  AND created_at <= sqlc.arg(until)
+ORDER BY created_at, id;
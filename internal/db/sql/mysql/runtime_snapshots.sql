@@ -0,0 +1,24 @@
+-- name: GetRuntimeSnapshot :one
+SELECT *
+FROM runtime_snapshots
+WHERE session_id = ? LIMIT 1;
+
+-- name: UpsertRuntimeSnapshot :exec
+INSERT INTO runtime_snapshots (
+    session_id,
+    version,
+    state,
+    updated_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    UNIX_TIMESTAMP()
+) ON DUPLICATE KEY UPDATE
+    version = VALUES(version),
+    state = VALUES(state),
+    updated_at = VALUES(updated_at);
+
+-- name: DeleteRuntimeSnapshot :exec
+DELETE FROM runtime_snapshots
+WHERE session_id = ?;
@@ -0,0 +1,39 @@
+-- name: AddSessionUsage :exec
+INSERT INTO session_usage (
+    session_id,
+    category,
+    name,
+    model,
+    requests,
+    input_tokens,
+    output_tokens,
+    cache_creation_tokens,
+    cache_read_tokens,
+    cost,
+    updated_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    UNIX_TIMESTAMP()
+) ON DUPLICATE KEY UPDATE
+    requests = requests + VALUES(requests),
+    input_tokens = input_tokens + VALUES(input_tokens),
+    output_tokens = output_tokens + VALUES(output_tokens),
+    cache_creation_tokens = cache_creation_tokens + VALUES(cache_creation_tokens),
+    cache_read_tokens = cache_read_tokens + VALUES(cache_read_tokens),
+    cost = cost + VALUES(cost),
+    updated_at = VALUES(updated_at);
+
+-- name: ListSessionUsage :many
+SELECT *
+FROM session_usage
+WHERE session_id = ?
+ORDER BY category, name, model;
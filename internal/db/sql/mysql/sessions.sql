@@ -75,3 +75,10 @@ SELECT *
 FROM sessions
 WHERE root_session_id = ?
 ORDER BY created_at ASC;
+
+-- name: GetProjectUsage :one
+SELECT
+    CAST(COALESCE(SUM(cost), 0) AS DOUBLE) AS cost,
+    CAST(COALESCE(SUM(total_prompt_tokens + total_completion_tokens), 0) AS SIGNED) AS tokens
+FROM sessions
+WHERE project_id = ?;
@@ -0,0 +1,28 @@
+-- name: CreateToolExecution :exec
+INSERT INTO tool_executions (
+    seq,
+    session_id,
+    agent_id,
+    tool,
+    call_id,
+    input,
+    status,
+    exit_code,
+    duration_ms,
+    decision,
+    via,
+    hash,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListToolExecutions :many
+SELECT *
+FROM tool_executions
+WHERE (sqlc.arg(session_id) = '' OR session_id = sqlc.arg(session_id))
+  AND (sqlc.arg(tool) = '' OR tool = sqlc.arg(tool))
+  AND created_at >= sqlc.arg(since)
+  AND created_at <= sqlc.arg(until)
+ORDER BY created_at, id
+LIMIT ?;
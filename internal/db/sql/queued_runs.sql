@@ -0,0 +1,40 @@
+-- name: CreateQueuedRun :one
+INSERT INTO queued_runs (
+    id,
+    kind,
+    target,
+    payload,
+    priority,
+    provider,
+    project,
+    status,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, 'queued', strftime('%s', 'now')
+) RETURNING *;
+
+-- name: GetQueuedRun :one
+SELECT * FROM queued_runs WHERE id = ? LIMIT 1;
+
+-- name: ListQueuedRuns :many
+SELECT * FROM queued_runs
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
+
+-- name: ListPendingQueuedRuns :many
+SELECT * FROM queued_runs
+WHERE status IN ('queued', 'running')
+ORDER BY priority DESC, created_at ASC, id ASC;
+
+-- name: ClaimQueuedRun :execrows
+UPDATE queued_runs SET status = 'running', started_at = strftime('%s', 'now')
+WHERE id = ? AND status = 'queued';
+
+-- name: FinishQueuedRun :execrows
+UPDATE queued_runs
+SET status = ?, session_id = ?, error = ?, finished_at = strftime('%s', 'now')
+WHERE id = ? AND status IN ('queued', 'running');
+
+-- name: RequeueRunningRuns :exec
+UPDATE queued_runs SET status = 'queued', started_at = NULL
+WHERE status = 'running';
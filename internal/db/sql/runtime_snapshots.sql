@@ -0,0 +1,24 @@
+-- name: GetRuntimeSnapshot :one
+SELECT *
+FROM runtime_snapshots
+WHERE session_id = ? LIMIT 1;
+
+-- name: UpsertRuntimeSnapshot :exec
+INSERT INTO runtime_snapshots (
+    session_id,
+    version,
+    state,
+    updated_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    strftime('%s', 'now')
+) ON CONFLICT(session_id) DO UPDATE SET
+    version = excluded.version,
+    state = excluded.state,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteRuntimeSnapshot :exec
+DELETE FROM runtime_snapshots
+WHERE session_id = ?;
@@ -0,0 +1,39 @@
+-- name: AddSessionUsage :exec
+INSERT INTO session_usage (
+    session_id,
+    category,
+    name,
+    model,
+    requests,
+    input_tokens,
+    output_tokens,
+    cache_creation_tokens,
+    cache_read_tokens,
+    cost,
+    updated_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    strftime('%s', 'now')
+) ON CONFLICT(session_id, category, name, model) DO UPDATE SET
+    requests = requests + excluded.requests,
+    input_tokens = input_tokens + excluded.input_tokens,
+    output_tokens = output_tokens + excluded.output_tokens,
+    cache_creation_tokens = cache_creation_tokens + excluded.cache_creation_tokens,
+    cache_read_tokens = cache_read_tokens + excluded.cache_read_tokens,
+    cost = cost + excluded.cost,
+    updated_at = excluded.updated_at;
+
+-- name: ListSessionUsage :many
+SELECT *
+FROM session_usage
+WHERE session_id = ?
+ORDER BY category, name, model;
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+)
+
+// gooseMu serializes the migrations of test databases: goose keeps its
+// base FS and dialect in package globals.
+var gooseMu sync.Mutex
+
+// OpenTestDB opens a migrated SQLite database in a temporary directory of
+// t. It is closed when the test ends.
+func OpenTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+	sqlDB, err := NewSQLiteProvider(t.TempDir()).Connect()
+	if err != nil {
+		t.Fatalf("connect sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gooseMu.Lock()
+	defer gooseMu.Unlock()
+	goose.SetBaseFS(FS)
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatalf("goose dialect: %v", err)
+	}
+	if err := goose.Up(sqlDB, "migrations/sqlite"); err != nil {
+		t.Fatalf("goose up: %v", err)
+	}
+	return sqlDB
+}
+
+// NewTestQuerier returns a querier over a fresh OpenTestDB database, for
+// tests that don't need the *sql.DB itself.
+func NewTestQuerier(t testing.TB) QuerierWithTx {
+	t.Helper()
+	return NewSQLiteQuerier(OpenTestDB(t))
+}
@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// reviewContextLines is the context kept around each change; changes closer
+// than twice this share a hunk, as in git.
+const reviewContextLines = 3
+
+// ReviewHunk is one hunk of the change from one version of a file to
+// another, as offered for review.
+type ReviewHunk struct {
+	// Unified is the hunk as a unified diff of its own, file headers
+	// included, so FormatDiff can render it.
+	Unified   string
+	Additions int
+	Removals  int
+}
+
+// Hunks splits the change from before to after into the hunks a reviewer
+// accepts or rejects one by one. fileName labels the rendered diffs.
+func Hunks(before, after, fileName string) []ReviewHunk {
+	u := reviewDiff(before, after)
+	fileName = strings.TrimPrefix(fileName, "/")
+	hunks := make([]ReviewHunk, 0, len(u.Hunks))
+	for _, h := range u.Hunks {
+		var body strings.Builder
+		var rh ReviewHunk
+		fromCount, toCount := 0, 0
+		for _, l := range h.Lines {
+			prefix := " "
+			switch l.Kind {
+			case udiff.Delete:
+				prefix = "-"
+				fromCount++
+				rh.Removals++
+			case udiff.Insert:
+				prefix = "+"
+				toCount++
+				rh.Additions++
+			default:
+				fromCount++
+				toCount++
+			}
+			body.WriteString(prefix + l.Content)
+			if !strings.HasSuffix(l.Content, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		rh.Unified = fmt.Sprintf("--- a/%s\n+++ b/%s\n@@ -%d,%d +%d,%d @@\n%s",
+			fileName, fileName, h.FromLine, fromCount, h.ToLine, toCount, body.String())
+		hunks = append(hunks, rh)
+	}
+	return hunks
+}
+
+// RejectHunks returns after with the hunks of Hunks(before, after) whose
+// indexes are in rejected put back the way they were in before. Rejecting
+// none returns after, rejecting all returns before.
+func RejectHunks(before, after string, rejected map[int]bool) string {
+	u := reviewDiff(before, after)
+	lines := splitKeepNewlines(before)
+	var out strings.Builder
+	next := 0 // next line of before to copy
+	for i, h := range u.Hunks {
+		for ; next < h.FromLine-1; next++ {
+			out.WriteString(lines[next])
+		}
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case udiff.Equal:
+				out.WriteString(l.Content)
+				next++
+			case udiff.Delete:
+				if rejected[i] {
+					out.WriteString(l.Content)
+				}
+				next++
+			case udiff.Insert:
+				if !rejected[i] {
+					out.WriteString(l.Content)
+				}
+			}
+		}
+	}
+	for ; next < len(lines); next++ {
+		out.WriteString(lines[next])
+	}
+	return out.String()
+}
+
+func reviewDiff(before, after string) udiff.UnifiedDiff {
+	// Line edits can't fail to convert; an error would leave no hunks, and
+	// with them nothing to review or reject.
+	u, _ := udiff.ToUnifiedDiff("a", "b", before, udiff.Lines(before, after), reviewContextLines)
+	return u
+}
+
+// splitKeepNewlines splits text into lines that keep their "\n"; a last
+// line without one is kept as is.
+func splitKeepNewlines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func numberedLines(n int, edit map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if s, ok := edit[i]; ok {
+			b.WriteString(s)
+			continue
+		}
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestRejectHunks(t *testing.T) {
+	before := numberedLines(30, nil)
+	// Two changes far enough apart to be separate hunks.
+	after := numberedLines(30, map[int]string{
+		3:  "line three\nan extra line\n",
+		25: "",
+	})
+
+	hunks := Hunks(before, after, "/w/a.txt")
+	require.Len(t, hunks, 2)
+	assert.Equal(t, 2, hunks[0].Additions)
+	assert.Equal(t, 1, hunks[0].Removals)
+	assert.Contains(t, hunks[0].Unified, "--- a/w/a.txt\n+++ b/w/a.txt\n@@ -1,6 +1,7 @@\n")
+	assert.Equal(t, 0, hunks[1].Additions)
+	assert.Equal(t, 1, hunks[1].Removals)
+
+	assert.Equal(t, after, RejectHunks(before, after, nil))
+	assert.Equal(t, before, RejectHunks(before, after, map[int]bool{0: true, 1: true}))
+	assert.Equal(t, numberedLines(30, map[int]string{25: ""}), RejectHunks(before, after, map[int]bool{0: true}))
+	assert.Equal(t, numberedLines(30, map[int]string{3: "line three\nan extra line\n"}), RejectHunks(before, after, map[int]bool{1: true}))
+}
+
+func TestRejectHunksWithoutTrailingNewline(t *testing.T) {
+	before := "a\nb\nc"
+	after := "a\nB\nc\nd"
+
+	hunks := Hunks(before, after, "f")
+	require.Len(t, hunks, 1)
+	assert.Contains(t, hunks[0].Unified, "\\ No newline at end of file")
+	_, err := FormatDiff(hunks[0].Unified, WithTotalWidth(80))
+	require.NoError(t, err)
+
+	assert.Equal(t, before, RejectHunks(before, after, map[int]bool{0: true}))
+	assert.Equal(t, after, RejectHunks(before, after, map[int]bool{}))
+	// A new file is one hunk that rejects back to nothing.
+	assert.Equal(t, "", RejectHunks("", "x\ny\n", map[int]bool{0: true}))
+	assert.Empty(t, Hunks("same\n", "same\n", "f"))
+}
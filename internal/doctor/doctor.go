@@ -0,0 +1,343 @@
+// Package doctor checks a loaded configuration against the outside world:
+// provider keys, agent models, MCP servers and LSP binaries. Problems it
+// finds would otherwise only show up as warnings in the logs, or as a
+// failure the first time an agent needs the broken piece.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/lsp/install"
+)
+
+// Severity says whether a problem breaks something or only degrades it.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is one finding, with the change that should fix it.
+type Problem struct {
+	Severity Severity
+	// Area is what was checked: config, provider, agent, mcp or lsp.
+	Area string
+	// Subject names the provider, agent or server, "" for the config as a
+	// whole.
+	Subject string
+	Problem string
+	Fix     string
+}
+
+// Options selects the checks to run.
+type Options struct {
+	// Offline skips the checks that reach out: provider API calls and
+	// starting MCP servers.
+	Offline bool
+	// LoadLogs are the warnings and errors logged while the configuration
+	// was loaded; each becomes a problem.
+	LoadLogs []logging.LogMessage
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	Problems []Problem
+	// Checked counts what was looked at, by area.
+	Checked map[string]int
+}
+
+// Errors counts the problems of error severity.
+func (r Report) Errors() int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// The checks that reach outside the process; replaced in tests.
+var (
+	checkAvailability = models.CheckAvailability
+	probeMCPServer    = agent.ProbeMCPServer
+	resolveLSPCommand = install.ResolveCommand
+)
+
+// Run checks cfg and the agents of reg.
+func Run(ctx context.Context, cfg *config.Config, reg agentregistry.Registry, opts Options) Report {
+	r := &Report{Checked: map[string]int{}}
+	r.checkLoadLogs(opts.LoadLogs)
+	availability := r.checkProviders(ctx, cfg, opts.Offline)
+	r.checkAgents(cfg, reg, availability)
+	if !opts.Offline {
+		r.checkMCPServers(ctx, cfg)
+	}
+	r.checkLSPServers(ctx, cfg)
+
+	order := map[string]int{"config": 0, "provider": 1, "agent": 2, "mcp": 3, "lsp": 4}
+	slices.SortStableFunc(r.Problems, func(a, b Problem) int {
+		if a.Severity != b.Severity {
+			if a.Severity == SeverityError {
+				return -1
+			}
+			return 1
+		}
+		if d := order[a.Area] - order[b.Area]; d != 0 {
+			return d
+		}
+		return strings.Compare(a.Subject, b.Subject)
+	})
+	return *r
+}
+
+func (r *Report) add(p Problem) {
+	r.Problems = append(r.Problems, p)
+}
+
+// checkLoadLogs turns what Load warned about, an invalid model replaced by
+// the default or an unknown setting, into problems.
+func (r *Report) checkLoadLogs(logs []logging.LogMessage) {
+	for _, msg := range logs {
+		severity := SeverityWarning
+		switch msg.Level {
+		case "error":
+			severity = SeverityError
+		case "warn":
+		default:
+			continue
+		}
+		var attrs []string
+		subject := ""
+		for _, a := range msg.Attributes {
+			switch a.Key {
+			case "source":
+				continue
+			case "agent", "provider", "server":
+				if subject == "" {
+					subject = a.Value
+				}
+			}
+			attrs = append(attrs, a.Key+"="+a.Value)
+		}
+		problem := msg.Message
+		if len(attrs) > 0 {
+			problem += " (" + strings.Join(attrs, ", ") + ")"
+		}
+		r.add(Problem{
+			Severity: severity,
+			Area:     "config",
+			Subject:  subject,
+			Problem:  problem,
+			Fix:      "Correct the setting in the config file; opencode-schema.json lists the valid values",
+		})
+	}
+}
+
+// providerKeyEnv names the environment variable each provider reads its
+// key from when the config has none.
+var providerKeyEnv = map[models.ModelProvider]string{
+	models.ProviderAnthropic:   "ANTHROPIC_API_KEY",
+	models.ProviderOpenAI:      "OPENAI_API_KEY",
+	models.ProviderGemini:      "GEMINI_API_KEY",
+	models.ProviderKimi:        "MOONSHOT_API_KEY",
+	models.ProviderYandexCloud: "YANDEXCLOUD_API_KEY",
+}
+
+// checkProviders lists the models of every provider that supports it, a
+// cheap authenticated call that proves the key works. It returns the
+// resulting availability, or the cached one when offline.
+func (r *Report) checkProviders(ctx context.Context, cfg *config.Config, offline bool) models.AvailabilityReport {
+	for p, pc := range cfg.Providers {
+		if pc.Disabled {
+			continue
+		}
+		r.Checked["provider"]++
+		if env, ok := providerKeyEnv[p]; ok && pc.APIKey == "" {
+			r.add(Problem{
+				Severity: SeverityError,
+				Area:     "provider",
+				Subject:  string(p),
+				Problem:  "no API key",
+				Fix:      fmt.Sprintf("Set providers.%s.apiKey, e.g. to \"${env:%s}\", or export %s", p, env, env),
+			})
+		}
+	}
+	if offline {
+		return models.CurrentAvailability()
+	}
+
+	creds := cfg.SyncCredentials()
+	checkable := make(map[models.ModelProvider]models.SyncCredentials, len(creds))
+	for p, c := range creds {
+		if c.APIKey != "" && models.CanCheckAvailability(p) {
+			checkable[p] = c
+		}
+	}
+	if len(checkable) == 0 {
+		return models.CurrentAvailability()
+	}
+	report, err := checkAvailability(ctx, checkable)
+	if err != nil {
+		r.add(Problem{
+			Severity: SeverityWarning,
+			Area:     "provider",
+			Problem:  "checking the providers failed: " + err.Error(),
+			Fix:      "Check the network connection and run the doctor again",
+		})
+		return report
+	}
+	for p := range checkable {
+		h, ok := report.Providers[p]
+		if !ok || h.Error == "" {
+			continue
+		}
+		fix := fmt.Sprintf("Check providers.%s.baseURL and the network connection", p)
+		if strings.Contains(h.Error, "401") || strings.Contains(h.Error, "403") {
+			fix = fmt.Sprintf("Replace providers.%s.apiKey", p)
+			if env, ok := providerKeyEnv[p]; ok {
+				fix += " or " + env
+			}
+			fix += " with a valid key"
+		}
+		r.add(Problem{
+			Severity: SeverityError,
+			Area:     "provider",
+			Subject:  string(p),
+			Problem:  h.Error,
+			Fix:      fix,
+		})
+	}
+	return report
+}
+
+// checkAgents verifies that each agent's model, and each of its fallback
+// models, exists, belongs to an enabled provider and is available to the
+// account.
+func (r *Report) checkAgents(cfg *config.Config, reg agentregistry.Registry, availability models.AvailabilityReport) {
+	for _, info := range reg.List() {
+		r.Checked["agent"]++
+		if info.Model == "" {
+			// Agents without a model of their own use the coder's.
+			continue
+		}
+		r.checkModel(cfg, availability, info.ID, "model", models.ModelID(info.Model), SeverityError)
+		for _, id := range info.FallbackModels {
+			r.checkModel(cfg, availability, info.ID, "fallbackModels", models.ModelID(id), SeverityWarning)
+		}
+	}
+}
+
+func (r *Report) checkModel(cfg *config.Config, availability models.AvailabilityReport, agentID, key string, id models.ModelID, severity Severity) {
+	setting := fmt.Sprintf("agents.%s.%s", agentID, key)
+	model, ok := models.SupportedModels[id]
+	if !ok {
+		r.add(Problem{
+			Severity: severity,
+			Area:     "agent",
+			Subject:  agentID,
+			Problem:  fmt.Sprintf("unknown model %q", id),
+			Fix:      fmt.Sprintf("Set %s to a model listed by `opencode models check`, or run `opencode models sync`", setting),
+		})
+		return
+	}
+	if pc, ok := cfg.Providers[model.Provider]; !ok || pc.Disabled {
+		r.add(Problem{
+			Severity: severity,
+			Area:     "agent",
+			Subject:  agentID,
+			Problem:  fmt.Sprintf("model %s needs provider %s, which is not configured", id, model.Provider),
+			Fix:      fmt.Sprintf("Configure providers.%s or pick another model for %s", model.Provider, setting),
+		})
+		return
+	}
+	if a, ok := availability.Models[id]; ok && !a.Available {
+		r.add(Problem{
+			Severity: severity,
+			Area:     "agent",
+			Subject:  agentID,
+			Problem:  fmt.Sprintf("model %s is not available: %s", id, a.Reason),
+			Fix:      fmt.Sprintf("Pick another model for %s", setting),
+		})
+	}
+}
+
+// checkMCPServers starts each enabled MCP server and lists its tools.
+func (r *Report) checkMCPServers(ctx context.Context, cfg *config.Config) {
+	for _, name := range slices.Sorted(maps.Keys(cfg.MCPServers)) {
+		m := cfg.MCPServers[name]
+		if m.Disabled {
+			continue
+		}
+		r.Checked["mcp"]++
+		n, err := probeMCPServer(ctx, m)
+		if err != nil {
+			fix := fmt.Sprintf("Check mcpServers.%s.url and its headers", name)
+			if m.Type == config.MCPStdio {
+				fix = fmt.Sprintf("Check that mcpServers.%s.command %q is installed and its args and env are right", name, m.Command)
+			}
+			r.add(Problem{
+				Severity: SeverityError,
+				Area:     "mcp",
+				Subject:  name,
+				Problem:  err.Error(),
+				Fix:      fix + `, or set "disabled": true`,
+			})
+			continue
+		}
+		if n == 0 {
+			r.add(Problem{
+				Severity: SeverityWarning,
+				Area:     "mcp",
+				Subject:  name,
+				Problem:  "the server offers no tools",
+				Fix:      fmt.Sprintf("Check the server's own configuration, or remove mcpServers.%s", name),
+			})
+		}
+	}
+}
+
+// checkLSPServers looks for the binary of each configured language server.
+// Nothing is installed: a server opencode can install is reported as a
+// warning when downloads are allowed.
+func (r *Report) checkLSPServers(ctx context.Context, cfg *config.Config) {
+	servers := install.ResolveServers(cfg)
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		server := servers[name]
+		r.Checked["lsp"]++
+		if _, _, err := resolveLSPCommand(ctx, server, true); err == nil {
+			continue
+		}
+		binary := name
+		if len(server.Command) > 0 {
+			binary = server.Command[0]
+		}
+		if server.Strategy != install.StrategyNone && !cfg.DisableLSPDownload {
+			r.add(Problem{
+				Severity: SeverityWarning,
+				Area:     "lsp",
+				Subject:  name,
+				Problem:  fmt.Sprintf("%s is not installed", binary),
+				Fix:      "It is downloaded the first time it is needed; install it yourself to skip the wait",
+			})
+			continue
+		}
+		r.add(Problem{
+			Severity: SeverityError,
+			Area:     "lsp",
+			Subject:  name,
+			Problem:  fmt.Sprintf("%s is not installed", binary),
+			Fix:      fmt.Sprintf("Install %s, set lsp.%s.command to its path, or set lsp.%s.disabled", binary, name, name),
+		})
+	}
+}
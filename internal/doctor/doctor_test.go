@@ -0,0 +1,151 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/lsp/install"
+)
+
+type fakeRegistry struct {
+	agentregistry.Registry
+	agents []agentregistry.AgentInfo
+}
+
+func (f fakeRegistry) List() []agentregistry.AgentInfo { return f.agents }
+
+// stubChecks replaces the outside checks: the OpenAI key is rejected,
+// the "broken" MCP server doesn't start and no LSP binary is installed.
+func stubChecks(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	prevAvailability, prevProbe, prevLSP := checkAvailability, probeMCPServer, resolveLSPCommand
+	checkAvailability = func(_ context.Context, creds map[models.ModelProvider]models.SyncCredentials) (models.AvailabilityReport, error) {
+		report := models.AvailabilityReport{Providers: map[models.ModelProvider]models.ProviderHealth{}, Models: map[models.ModelID]models.Availability{}}
+		for p := range creds {
+			if p == models.ProviderOpenAI {
+				report.Providers[p] = models.ProviderHealth{Error: "401 Unauthorized"}
+				report.Models[models.GPT5] = models.Availability{Reason: "the provider rejected the API key (401 Unauthorized)"}
+				continue
+			}
+			report.Providers[p] = models.ProviderHealth{Listed: 3}
+		}
+		return report, nil
+	}
+	probeMCPServer = func(_ context.Context, m config.MCPServer) (int, error) {
+		if m.Command == "broken" {
+			return 0, errors.New("starting: no such file")
+		}
+		return 2, nil
+	}
+	resolveLSPCommand = func(context.Context, install.ResolvedServer, bool) (string, []string, error) {
+		return "", nil, errors.New("not found")
+	}
+	t.Cleanup(func() {
+		checkAvailability, probeMCPServer, resolveLSPCommand = prevAvailability, prevProbe, prevLSP
+	})
+}
+
+func problemsOf(r Report, area string) map[string]Problem {
+	out := map[string]Problem{}
+	for _, p := range r.Problems {
+		if p.Area == area {
+			out[p.Subject] = p
+		}
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	stubChecks(t)
+	cfg := &config.Config{
+		Providers: map[models.ModelProvider]config.Provider{
+			models.ProviderOpenAI:    {APIKey: "sk-bad"},
+			models.ProviderAnthropic: {APIKey: "sk-ant"},
+			models.ProviderGemini:    {},
+		},
+		MCPServers: map[string]config.MCPServer{
+			"good":     {Command: "good", Type: config.MCPStdio},
+			"broken":   {Command: "broken", Type: config.MCPStdio},
+			"disabled": {Command: "broken", Type: config.MCPStdio, Disabled: true},
+		},
+		LSP: map[string]config.LSPConfig{
+			"custom": {Command: "custom-ls"},
+		},
+	}
+	reg := fakeRegistry{agents: []agentregistry.AgentInfo{
+		{ID: "coder", Model: string(models.GPT5)},
+		{ID: "explorer", Model: string(models.Claude45Haiku), FallbackModels: []string{"no-such-model"}},
+		{ID: "reviewer", Model: "kimi.kimi-k3"},
+		{ID: "inherits"},
+	}}
+	logs := []logging.LogMessage{
+		{Level: "warn", Message: "unsupported model configured, reverting to default", Attributes: []logging.Attr{{Key: "agent", Value: "coder"}, {Key: "source", Value: "config.go:1"}}},
+		{Level: "info", Message: "Agent provider created"},
+	}
+
+	report := Run(t.Context(), cfg, reg, Options{LoadLogs: logs})
+
+	assert.Equal(t, map[string]int{"provider": 3, "agent": 4, "mcp": 2, "lsp": 1}, report.Checked)
+
+	config := problemsOf(report, "config")
+	require.Len(t, config, 1, "info logs are not problems")
+	assert.Equal(t, "unsupported model configured, reverting to default (agent=coder)", config["coder"].Problem)
+
+	providers := problemsOf(report, "provider")
+	require.Len(t, providers, 2)
+	assert.Equal(t, "no API key", providers["gemini"].Problem)
+	assert.Contains(t, providers["gemini"].Fix, "${env:GEMINI_API_KEY}")
+	assert.Contains(t, providers["openai"].Fix, "OPENAI_API_KEY with a valid key")
+
+	agents := report.Problems[:0:0]
+	for _, p := range report.Problems {
+		if p.Area == "agent" {
+			agents = append(agents, p)
+		}
+	}
+	require.Len(t, agents, 3, "%v", agents)
+	assert.Equal(t, Problem{Severity: SeverityError, Area: "agent", Subject: "coder", Problem: "model gpt-5 is not available: the provider rejected the API key (401 Unauthorized)", Fix: "Pick another model for agents.coder.model"}, agents[0])
+	assert.Equal(t, "reviewer", agents[1].Subject)
+	assert.Contains(t, agents[1].Problem, "needs provider kimi")
+	assert.Equal(t, SeverityWarning, agents[2].Severity, "a bad fallback model degrades, it doesn't break")
+	assert.Equal(t, `unknown model "no-such-model"`, agents[2].Problem)
+
+	mcp := problemsOf(report, "mcp")
+	require.Len(t, mcp, 1)
+	assert.Contains(t, mcp["broken"].Fix, `mcpServers.broken.command "broken"`)
+
+	lsp := problemsOf(report, "lsp")
+	assert.Equal(t, SeverityError, lsp["custom"].Severity)
+	assert.Equal(t, "custom-ls is not installed", lsp["custom"].Problem)
+
+	assert.Equal(t, SeverityError, report.Problems[0].Severity, "errors come first")
+	assert.Equal(t, 6, report.Errors())
+}
+
+func TestRunOffline(t *testing.T) {
+	stubChecks(t)
+	checkAvailability = func(context.Context, map[models.ModelProvider]models.SyncCredentials) (models.AvailabilityReport, error) {
+		t.Fatal("providers are called offline")
+		return models.AvailabilityReport{}, nil
+	}
+	probeMCPServer = func(context.Context, config.MCPServer) (int, error) {
+		t.Fatal("MCP servers are started offline")
+		return 0, nil
+	}
+	cfg := &config.Config{
+		Providers:  map[models.ModelProvider]config.Provider{models.ProviderOpenAI: {APIKey: "sk"}},
+		MCPServers: map[string]config.MCPServer{"good": {Command: "good"}},
+	}
+
+	report := Run(t.Context(), cfg, fakeRegistry{}, Options{Offline: true})
+	assert.Empty(t, report.Problems)
+}
@@ -0,0 +1,165 @@
+// Package feedback stores the user's ratings of assistant replies: a thumbs
+// up or down per message with an optional comment. Ratings record the agent
+// and model that produced the reply, so they can be tallied next to usage
+// when choosing agents and models, and they travel with session archives
+// and datasets.
+package feedback
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+type Rating string
+
+const (
+	Up   Rating = "up"
+	Down Rating = "down"
+)
+
+// ParseRating accepts up/down and the usual synonyms.
+func ParseRating(s string) (Rating, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "up", "good", "+", "+1":
+		return Up, nil
+	case "down", "bad", "-", "-1":
+		return Down, nil
+	}
+	return "", fmt.Errorf("unknown rating %q (want up or down)", s)
+}
+
+type Feedback struct {
+	MessageID string `json:"message_id"`
+	SessionID string `json:"session_id"`
+	Rating    Rating `json:"rating"`
+	Comment   string `json:"comment,omitempty"`
+	AgentID   string `json:"agent_id,omitempty"`
+	Model     string `json:"model,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type Service interface {
+	// Rate records f, replacing an earlier rating of the same message.
+	Rate(ctx context.Context, f Feedback) (Feedback, error)
+	Get(ctx context.Context, messageID string) (Feedback, bool, error)
+	// List returns the ratings given in a session, oldest first.
+	List(ctx context.Context, sessionID string) ([]Feedback, error)
+	Clear(ctx context.Context, messageID string) error
+}
+
+type service struct {
+	q db.Querier
+}
+
+func (s *service) Rate(ctx context.Context, f Feedback) (Feedback, error) {
+	if f.Rating != Up && f.Rating != Down {
+		return Feedback{}, fmt.Errorf("unknown rating %q", f.Rating)
+	}
+	now := time.Now().Unix()
+	if err := s.q.UpsertMessageFeedback(ctx, db.UpsertMessageFeedbackParams{
+		MessageID: f.MessageID,
+		SessionID: f.SessionID,
+		Rating:    string(f.Rating),
+		Comment:   strings.TrimSpace(f.Comment),
+		AgentID:   f.AgentID,
+		Model:     f.Model,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		return Feedback{}, err
+	}
+	stored, _, err := s.Get(ctx, f.MessageID)
+	return stored, err
+}
+
+func (s *service) Get(ctx context.Context, messageID string) (Feedback, bool, error) {
+	r, err := s.q.GetMessageFeedback(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Feedback{}, false, nil
+		}
+		return Feedback{}, false, err
+	}
+	return fromDBItem(r), true, nil
+}
+
+func (s *service) List(ctx context.Context, sessionID string) ([]Feedback, error) {
+	rows, err := s.q.ListMessageFeedback(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Feedback, len(rows))
+	for i, r := range rows {
+		items[i] = fromDBItem(r)
+	}
+	return items, nil
+}
+
+func (s *service) Clear(ctx context.Context, messageID string) error {
+	return s.q.DeleteMessageFeedback(ctx, messageID)
+}
+
+func fromDBItem(r db.MessageFeedback) Feedback {
+	return Feedback{
+		MessageID: r.MessageID,
+		SessionID: r.SessionID,
+		Rating:    Rating(r.Rating),
+		Comment:   r.Comment,
+		AgentID:   r.AgentID,
+		Model:     r.Model,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	return &service{q: q}
+}
+
+// Tally counts the ratings one agent got on one model.
+type Tally struct {
+	AgentID string `json:"agent_id"`
+	Model   string `json:"model"`
+	Up      int    `json:"up"`
+	Down    int    `json:"down"`
+}
+
+// Summarize tallies ratings by agent and model, most rated first.
+func Summarize(items []Feedback) []Tally {
+	type key struct{ agent, model string }
+	byKey := map[key]*Tally{}
+	for _, f := range items {
+		k := key{f.AgentID, f.Model}
+		t, ok := byKey[k]
+		if !ok {
+			t = &Tally{AgentID: f.AgentID, Model: f.Model}
+			byKey[k] = t
+		}
+		if f.Rating == Up {
+			t.Up++
+		} else {
+			t.Down++
+		}
+	}
+	tallies := make([]Tally, 0, len(byKey))
+	for _, t := range byKey {
+		tallies = append(tallies, *t)
+	}
+	slices.SortFunc(tallies, func(a, b Tally) int {
+		return cmp.Or(
+			cmp.Compare(b.Up+b.Down, a.Up+a.Down),
+			cmp.Compare(a.AgentID, b.AgentID),
+			cmp.Compare(a.Model, b.Model),
+		)
+	})
+	return tallies
+}
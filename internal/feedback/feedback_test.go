@@ -0,0 +1,89 @@
+package feedback
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func newTestService(t *testing.T) (Service, db.Querier) {
+	t.Helper()
+	q := db.NewTestQuerier(t)
+	return NewService(q), q
+}
+
+func TestRateReplacesEarlierRating(t *testing.T) {
+	ctx := context.Background()
+	svc, q := newTestService(t)
+	if _, err := q.CreateSession(ctx, db.CreateSessionParams{ID: "s1", Title: "S"}); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"m1", "m2"} {
+		if _, err := q.CreateMessage(ctx, db.CreateMessageParams{
+			ID: id, SessionID: "s1", Role: "assistant", Parts: "[]",
+			Seq: sql.NullInt64{Int64: int64(i + 1), Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok, err := svc.Get(ctx, "m1"); err != nil || ok {
+		t.Fatalf("Get before rating = %v, %v", ok, err)
+	}
+	if _, err := svc.Rate(ctx, Feedback{MessageID: "m1", SessionID: "s1", Rating: Down, Comment: " wrong file ", AgentID: "coder", Model: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.Rate(ctx, Feedback{MessageID: "m1", SessionID: "s1", Rating: Up, AgentID: "coder", Model: "m"})
+	if err != nil || got.Rating != Up || got.Comment != "" || got.CreatedAt == 0 {
+		t.Fatalf("re-rated = %+v, %v", got, err)
+	}
+	if _, err := svc.Rate(ctx, Feedback{MessageID: "m2", SessionID: "s1", Rating: "meh"}); err == nil {
+		t.Error("expected an unknown rating to be rejected")
+	}
+	if _, err := svc.Rate(ctx, Feedback{MessageID: "m2", SessionID: "s1", Rating: Down, Comment: "too slow", AgentID: "coder", Model: "m"}); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := svc.List(ctx, "s1")
+	if err != nil || len(items) != 2 {
+		t.Fatalf("List = %+v, %v", items, err)
+	}
+	if items[1].Comment != "too slow" {
+		t.Errorf("second rating = %+v", items[1])
+	}
+
+	if err := svc.Clear(ctx, "m1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := svc.Get(ctx, "m1"); ok {
+		t.Error("rating still present after Clear")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tallies := Summarize([]Feedback{
+		{AgentID: "coder", Model: "a", Rating: Up},
+		{AgentID: "coder", Model: "b", Rating: Down},
+		{AgentID: "coder", Model: "b", Rating: Up},
+		{AgentID: "coder", Model: "b", Rating: Down},
+	})
+	if len(tallies) != 2 {
+		t.Fatalf("tallies = %+v", tallies)
+	}
+	if tallies[0] != (Tally{AgentID: "coder", Model: "b", Up: 1, Down: 2}) || tallies[1] != (Tally{AgentID: "coder", Model: "a", Up: 1}) {
+		t.Errorf("tallies = %+v", tallies)
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	for in, want := range map[string]Rating{"up": Up, "Good": Up, "+1": Up, "bad": Down, "down": Down} {
+		if got, err := ParseRating(in); err != nil || got != want {
+			t.Errorf("ParseRating(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseRating("meh"); err == nil {
+		t.Error("expected an error for an unknown rating")
+	}
+}
@@ -19,8 +19,16 @@ var (
 	ErrInvalidPredicate     = errors.New("invalid predicate")
 	ErrInvalidMaxTurns      = errors.New("invalid maxTurns")
 	ErrInvalidMaxIterations = errors.New("invalid maxIterations")
+	ErrInvalidGate          = errors.New("invalid gate")
+	ErrGateNotPending       = errors.New("step is not waiting for approval")
+	ErrGateRejected         = errors.New("step rejected at approval gate")
+	ErrInvalidSchedule      = errors.New("invalid schedule")
 )
 
+// GateManual is the only supported Step.Gate value. A manual gate pauses
+// the flow before the step runs until a user approves or rejects it.
+const GateManual = "manual"
+
 // Flow represents a discovered flow definition.
 type Flow struct {
 	ID          string
@@ -48,6 +56,12 @@ type FlowSpec struct {
 	Args    map[string]any `yaml:"args,omitempty"`
 	Session FlowSession    `yaml:"session,omitempty"`
 	Steps   []Step         `yaml:"steps"`
+	// Schedule is a 5-field cron expression (e.g. "0 9 * * 1"). Scheduled
+	// flows are started by `opencode flows daemon`; the field has no
+	// effect on other ways of running the flow.
+	Schedule string `yaml:"schedule,omitempty"`
+	// ScheduleArgs are the flow args passed to scheduled runs.
+	ScheduleArgs map[string]any `yaml:"scheduleArgs,omitempty"`
 }
 
 // Step defines a single step in the flow graph.
@@ -96,6 +110,12 @@ type Step struct {
 	// stays inside the model's cached prompt. See flow-creator SKILL
 	// "Per-step context compaction".
 	Compact *StepCompact `yaml:"compact,omitempty"`
+	// Gate optionally holds the step until a human signs off. With
+	// `gate: manual` the runner publishes a waiting_approval FlowState
+	// and blocks until Service.ResolveGate records a decision; a
+	// rejection fails the step and routes its fallback. The decision is
+	// persisted in flow_states so a re-triggered flow picks it up.
+	Gate string `yaml:"gate,omitempty"`
 }
 
 // StepCompact configures per-step overrides to the auto-compaction
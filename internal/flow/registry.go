@@ -12,6 +12,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/cron"
 	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/logging"
 )
@@ -442,6 +443,9 @@ func validateFlow(f *Flow) error {
 		if step.MaxIterations < 0 {
 			return fmt.Errorf("%w: step %q maxIterations must be >= 0 (got %d; 0 means unbounded)", ErrInvalidMaxIterations, step.ID, step.MaxIterations)
 		}
+		if step.Gate != "" && step.Gate != GateManual {
+			return fmt.Errorf("%w: step %q gate must be %q (got %q)", ErrInvalidGate, step.ID, GateManual, step.Gate)
+		}
 		// Timeout, when set, must parse cleanly and be non-negative. The
 		// runtime falls back gracefully on parse errors (env-var default,
 		// then unwrapped ctx), but surfacing the typo at load time is
@@ -452,6 +456,12 @@ func validateFlow(f *Flow) error {
 		}
 	}
 
+	if f.Spec.Schedule != "" {
+		if _, err := cron.ParseSchedule(f.Spec.Schedule); err != nil {
+			return fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, f.Spec.Schedule, err)
+		}
+	}
+
 	// Validate rule and fallback references
 	thenTargets := make(map[string]int) // track how many rules target each step
 	for _, step := range f.Spec.Steps {
@@ -177,6 +177,48 @@ func TestValidateFlow(t *testing.T) {
 			},
 			wantErr: ErrInvalidMaxTurns,
 		},
+		{
+			name: "manual gate is valid",
+			flow: Flow{
+				ID: "gate-manual",
+				Spec: FlowSpec{
+					Steps: []Step{{ID: "step-a", Prompt: "x", Gate: GateManual}},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "unknown gate rejected",
+			flow: Flow{
+				ID: "gate-auto",
+				Spec: FlowSpec{
+					Steps: []Step{{ID: "step-a", Prompt: "x", Gate: "auto"}},
+				},
+			},
+			wantErr: ErrInvalidGate,
+		},
+		{
+			name: "cron schedule is valid",
+			flow: Flow{
+				ID: "weekly",
+				Spec: FlowSpec{
+					Schedule: "0 9 * * 1",
+					Steps:    []Step{{ID: "step-a", Prompt: "x"}},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "malformed schedule rejected",
+			flow: Flow{
+				ID: "bad-schedule",
+				Spec: FlowSpec{
+					Schedule: "every monday",
+					Steps:    []Step{{ID: "step-a", Prompt: "x"}},
+				},
+			},
+			wantErr: ErrInvalidSchedule,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,369 @@
+package flow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opencode-ai/opencode/internal/cron"
+	"github.com/opencode-ai/opencode/internal/db"
+	agentpkg "github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// ScheduleStatus is the outcome recorded for one firing of a flow
+// schedule.
+type ScheduleStatus string
+
+const (
+	ScheduleStatusRunning   ScheduleStatus = "running"
+	ScheduleStatusCompleted ScheduleStatus = "completed"
+	ScheduleStatusFailed    ScheduleStatus = "failed"
+	// ScheduleStatusSkipped marks a firing dropped because the previous
+	// run of the same flow had not finished.
+	ScheduleStatusSkipped ScheduleStatus = "skipped"
+	// ScheduleStatusCancelled marks a run stopped by daemon shutdown.
+	ScheduleStatusCancelled ScheduleStatus = "cancelled"
+)
+
+// DefaultHistoryLimit caps Scheduler.History when no limit is given.
+const DefaultHistoryLimit = 50
+
+// scheduleTick is how often the scheduler checks for due flows and
+// re-reads flow files. Schedules have minute resolution.
+var scheduleTick = 10 * time.Second
+
+// loadScheduledFlows re-discovers flows on every tick so the daemon picks
+// up added, edited and removed flow files without a restart.
+var loadScheduledFlows = func() []Flow {
+	Invalidate()
+	return All()
+}
+
+// ScheduledRun is one entry of a flow's schedule history.
+type ScheduledRun struct {
+	ID          string         `json:"id"`
+	FlowID      string         `json:"flowID"`
+	ScheduledAt int64          `json:"scheduledAt"`
+	Status      ScheduleStatus `json:"status"`
+	SessionID   string         `json:"sessionID,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	StartedAt   int64          `json:"startedAt"`
+	FinishedAt  int64          `json:"finishedAt,omitempty"`
+}
+
+type scheduleEntry struct {
+	expr string
+	next time.Time
+}
+
+// Scheduler starts flows whose spec declares a cron schedule. A flow
+// never overlaps itself: a firing that comes due while the previous run
+// is still going is recorded as skipped. Every firing is kept in the
+// flow_schedule_runs table.
+//
+// Only one scheduler should run per project database; on Start it marks
+// runs left over from a previous process as failed.
+type Scheduler struct {
+	flows     Service
+	q         db.Querier
+	projectID string
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*scheduleEntry
+	active  map[string]struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewScheduler(flows Service, q db.Querier, projectID string) *Scheduler {
+	return &Scheduler{
+		flows:     flows,
+		q:         q,
+		projectID: projectID,
+		now:       time.Now,
+		entries:   make(map[string]*scheduleEntry),
+		active:    make(map[string]struct{}),
+	}
+}
+
+// Start runs the scheduling loop until ctx is cancelled or Stop is
+// called. Schedules count from the moment Start is called; firings
+// missed while no daemon was running are not replayed.
+func (s *Scheduler) Start(ctx context.Context) error {
+	if err := s.q.FailRunningFlowScheduleRuns(ctx, db.FailRunningFlowScheduleRunsParams{
+		Error:     sql.NullString{String: "interrupted by a scheduler restart", Valid: true},
+		ProjectID: s.projectID,
+	}); err != nil {
+		return fmt.Errorf("failed to reset interrupted scheduled runs: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	s.tick(ctx)
+	s.wg.Add(1)
+	go func() {
+		defer logging.RecoverPanic("flow-scheduler", nil)
+		defer s.wg.Done()
+		ticker := time.NewTicker(scheduleTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop cancels in-flight runs and waits for them to record their outcome.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// Scheduled returns the flows the scheduler is tracking with their next
+// firing time.
+func (s *Scheduler) Scheduled() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string]time.Time, len(s.entries))
+	for id, e := range s.entries {
+		next[id] = e.next
+	}
+	return next
+}
+
+// History returns recorded firings, newest first. An empty flowID lists
+// every flow of the project.
+func (s *Scheduler) History(ctx context.Context, flowID string, limit int) ([]ScheduledRun, error) {
+	return ScheduleHistory(ctx, s.q, s.projectID, flowID, limit)
+}
+
+// ScheduleHistory reads the schedule history without a running
+// scheduler, e.g. from a CLI command.
+func ScheduleHistory(ctx context.Context, q db.Querier, projectID, flowID string, limit int) ([]ScheduledRun, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	rows, err := q.ListFlowScheduleRuns(ctx, db.ListFlowScheduleRunsParams{
+		ProjectID: projectID,
+		FlowID:    flowID,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled runs: %w", err)
+	}
+	runs := make([]ScheduledRun, len(rows))
+	for i, r := range rows {
+		runs[i] = ScheduledRun{
+			ID:          r.ID,
+			FlowID:      r.FlowID,
+			ScheduledAt: r.ScheduledAt,
+			Status:      ScheduleStatus(r.Status),
+			SessionID:   r.SessionID.String,
+			Error:       r.Error.String,
+			StartedAt:   r.StartedAt,
+			FinishedAt:  r.FinishedAt.Int64,
+		}
+	}
+	return runs, nil
+}
+
+// tick refreshes the schedule table from the flow files and fires every
+// flow whose next firing time has passed.
+func (s *Scheduler) tick(ctx context.Context) {
+	now := s.now()
+	seen := make(map[string]bool)
+	for _, f := range loadScheduledFlows() {
+		if f.Spec.Schedule == "" || f.Disabled {
+			continue
+		}
+		schedule, err := cron.ParseSchedule(f.Spec.Schedule)
+		if err != nil {
+			// validateFlow rejects bad expressions, so this only guards
+			// flows registered by other means.
+			logging.Warn("Skipping flow with an invalid schedule", "flow", f.ID, "schedule", f.Spec.Schedule, "error", err)
+			continue
+		}
+		seen[f.ID] = true
+
+		s.mu.Lock()
+		entry, ok := s.entries[f.ID]
+		if !ok || entry.expr != f.Spec.Schedule {
+			entry = &scheduleEntry{expr: f.Spec.Schedule, next: schedule.Next(now)}
+			s.entries[f.ID] = entry
+			s.mu.Unlock()
+			logging.Info("Scheduled flow", "flow", f.ID, "schedule", f.Spec.Schedule, "next", entry.next)
+			continue
+		}
+		due := !now.Before(entry.next)
+		slot := entry.next
+		if due {
+			entry.next = schedule.Next(now)
+		}
+		s.mu.Unlock()
+
+		if due {
+			s.fire(ctx, f, slot)
+		}
+	}
+
+	s.mu.Lock()
+	for id := range s.entries {
+		if !seen[id] {
+			delete(s.entries, id)
+		}
+	}
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) fire(ctx context.Context, f Flow, slot time.Time) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		logging.Error("Failed to generate scheduled run ID", "flow", f.ID, "error", err)
+		return
+	}
+	params := db.CreateFlowScheduleRunParams{
+		ID:          id.String(),
+		ProjectID:   s.projectID,
+		FlowID:      f.ID,
+		ScheduledAt: slot.Unix(),
+		Status:      string(ScheduleStatusRunning),
+	}
+
+	s.mu.Lock()
+	_, busy := s.active[f.ID]
+	s.mu.Unlock()
+	if !busy {
+		running, err := s.q.CountRunningFlowScheduleRuns(ctx, db.CountRunningFlowScheduleRunsParams{
+			ProjectID: s.projectID,
+			FlowID:    f.ID,
+		})
+		if err != nil {
+			logging.Error("Failed to check for running scheduled flows", "flow", f.ID, "error", err)
+			return
+		}
+		busy = running > 0
+	}
+	if busy {
+		params.Status = string(ScheduleStatusSkipped)
+		params.Error = sql.NullString{String: "previous run still in progress", Valid: true}
+		params.FinishedAt = sql.NullInt64{Int64: s.now().Unix(), Valid: true}
+		if _, err := s.q.CreateFlowScheduleRun(ctx, params); err != nil {
+			logging.Error("Failed to record skipped scheduled flow", "flow", f.ID, "error", err)
+		}
+		logging.Warn("Skipping scheduled flow, previous run still in progress", "flow", f.ID, "slot", slot)
+		return
+	}
+
+	n, err := s.q.CreateFlowScheduleRun(ctx, params)
+	if err != nil {
+		logging.Error("Failed to record scheduled flow run", "flow", f.ID, "error", err)
+		return
+	}
+	if n == 0 {
+		// Another scheduler sharing the database already took this slot.
+		return
+	}
+
+	s.mu.Lock()
+	s.active[f.ID] = struct{}{}
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer logging.RecoverPanic("flow-scheduler-run", nil)
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.active, f.ID)
+			s.mu.Unlock()
+		}()
+		s.execute(ctx, f, params.ID)
+	}()
+}
+
+func (s *Scheduler) execute(ctx context.Context, f Flow, runID string) {
+	logging.Info("Starting scheduled flow", "flow", f.ID, "run", runID)
+	args := map[string]any{}
+	maps.Copy(args, f.Spec.ScheduleArgs)
+	// Without a spec prefix the flow keys its sessions on the current
+	// second; the run ID keeps every firing in its own sessions.
+	prefix := ""
+	if f.Spec.Session.Prefix == "" {
+		prefix = runID
+	}
+
+	var rootSessionID string
+	events, states, err := s.flows.Run(ctx, prefix, f.ID, args, false)
+	if err == nil {
+		rootSessionID, err = WaitForRun(ctx, events, states)
+	}
+
+	status := ScheduleStatusCompleted
+	switch {
+	case ctx.Err() != nil:
+		status = ScheduleStatusCancelled
+	case err != nil:
+		status = ScheduleStatusFailed
+	}
+	finish := db.FinishFlowScheduleRunParams{
+		ID:        runID,
+		Status:    string(status),
+		SessionID: sql.NullString{String: rootSessionID, Valid: rootSessionID != ""},
+	}
+	if err != nil {
+		finish.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+	// The run context may already be cancelled; the outcome must still
+	// be recorded.
+	if dbErr := s.q.FinishFlowScheduleRun(context.WithoutCancel(ctx), finish); dbErr != nil {
+		logging.Error("Failed to record scheduled flow outcome", "flow", f.ID, "run", runID, "error", dbErr)
+	}
+	logging.Info("Scheduled flow finished", "flow", f.ID, "run", runID, "status", status, "error", err)
+}
+
+// WaitForRun drains the channels returned by Service.Run and reports the
+// flow's root session and, when a step ended in failure, an error naming
+// it.
+func WaitForRun(ctx context.Context, events <-chan agentpkg.AgentEvent, states <-chan *FlowState) (string, error) {
+	go func() {
+		for range events {
+		}
+	}()
+
+	var rootSessionID string
+	var failed *FlowState
+	for state := range states {
+		if rootSessionID == "" {
+			rootSessionID = state.RootSessionID
+		}
+		if state.Status == FlowStatusFailed {
+			failed = state
+		}
+	}
+	if failed != nil {
+		if failed.Output != "" {
+			return rootSessionID, fmt.Errorf("step %q failed: %s", failed.StepID, failed.Output)
+		}
+		return rootSessionID, fmt.Errorf("step %q failed", failed.StepID)
+	}
+	return rootSessionID, ctx.Err()
+}
@@ -0,0 +1,246 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	agentpkg "github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// scriptedFlows is a flow Service whose runs stay open until the test
+// finishes them with a final step status or the run context ends.
+type scriptedFlows struct {
+	*pubsub.Broker[FlowState]
+
+	mu       sync.Mutex
+	calls    []scriptedCall
+	startedC chan string
+}
+
+type scriptedCall struct {
+	prefix string
+	args   map[string]any
+	done   chan FlowStatus
+}
+
+func newScriptedFlows() *scriptedFlows {
+	return &scriptedFlows{Broker: pubsub.NewBroker[FlowState](), startedC: make(chan string, 8)}
+}
+
+func (f *scriptedFlows) Run(ctx context.Context, prefix, flowID string, args map[string]any, _ bool) (<-chan agentpkg.AgentEvent, <-chan *FlowState, error) {
+	events := make(chan agentpkg.AgentEvent)
+	close(events)
+	states := make(chan *FlowState, 1)
+	call := scriptedCall{prefix: prefix, args: args, done: make(chan FlowStatus, 1)}
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+	go func() {
+		defer close(states)
+		select {
+		case status := <-call.done:
+			states <- &FlowState{RootSessionID: prefix + "-root", StepID: "only", Status: status, Output: "boom"}
+		case <-ctx.Done():
+		}
+	}()
+	f.startedC <- prefix
+	return events, states, nil
+}
+
+func (f *scriptedFlows) ResolveGate(context.Context, string, bool) (*FlowState, error) {
+	return nil, ErrGateNotPending
+}
+
+func (f *scriptedFlows) call(i int) scriptedCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[i]
+}
+
+func (f *scriptedFlows) finish(i int, status FlowStatus) {
+	f.call(i).done <- status
+}
+
+func (f *scriptedFlows) waitStarted(t *testing.T) string {
+	t.Helper()
+	select {
+	case prefix := <-f.startedC:
+		return prefix
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a scheduled run to start")
+		return ""
+	}
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestScheduler(t *testing.T, flows ...Flow) (*Scheduler, *scriptedFlows, *fakeClock, db.Querier) {
+	t.Helper()
+	orig := loadScheduledFlows
+	loadScheduledFlows = func() []Flow { return flows }
+	t.Cleanup(func() { loadScheduledFlows = orig })
+
+	q := db.NewTestQuerier(t)
+	svc := newScriptedFlows()
+	clock := &fakeClock{now: time.Date(2026, 10, 19, 8, 59, 30, 0, time.Local)}
+	s := NewScheduler(svc, q, "proj")
+	s.now = clock.Now
+	t.Cleanup(s.Stop)
+	return s, svc, clock, q
+}
+
+func waitHistory(t *testing.T, s *Scheduler, flowID string, want ...ScheduleStatus) []ScheduledRun {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runs, err := s.History(context.Background(), flowID, 0)
+		if err != nil {
+			t.Fatalf("history: %v", err)
+		}
+		if len(runs) == len(want) {
+			match := true
+			for i, r := range runs {
+				if r.Status != want[i] {
+					match = false
+				}
+			}
+			if match {
+				return runs
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("history = %+v, want statuses %v", runs, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+var weeklyFlow = Flow{
+	ID: "weekly-report",
+	Spec: FlowSpec{
+		Schedule:     "0 9 * * 1",
+		ScheduleArgs: map[string]any{"prompt": "summarise last week"},
+		Steps:        []Step{{ID: "only", Prompt: "${args.prompt}"}},
+	},
+}
+
+func TestSchedulerFiresDueFlowAndRecordsHistory(t *testing.T) {
+	s, svc, clock, _ := newTestScheduler(t, weeklyFlow)
+	ctx := context.Background()
+
+	s.tick(ctx)
+	next := s.Scheduled()[weeklyFlow.ID]
+	if want := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Fatalf("next firing = %v, want %v", next, want)
+	}
+	s.tick(ctx)
+	select {
+	case <-svc.startedC:
+		t.Fatal("flow ran before its schedule came due")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	s.tick(ctx)
+	prefix := svc.waitStarted(t)
+	if args := svc.call(0).args; args["prompt"] != "summarise last week" {
+		t.Fatalf("run args = %v, want the schedule args", args)
+	}
+	waitHistory(t, s, weeklyFlow.ID, ScheduleStatusRunning)
+	svc.finish(0, FlowStatusCompleted)
+
+	runs := waitHistory(t, s, weeklyFlow.ID, ScheduleStatusCompleted)
+	if runs[0].ID != prefix || runs[0].SessionID != prefix+"-root" || runs[0].FinishedAt == 0 {
+		t.Fatalf("history entry = %+v (run prefix %q)", runs[0], prefix)
+	}
+	if want := time.Date(2026, 10, 26, 9, 0, 0, 0, time.Local); !s.Scheduled()[weeklyFlow.ID].Equal(want) {
+		t.Fatalf("next firing = %v, want %v", s.Scheduled()[weeklyFlow.ID], want)
+	}
+}
+
+func TestSchedulerSkipsOverlappingRuns(t *testing.T) {
+	minutely := Flow{ID: "poll", Spec: FlowSpec{Schedule: "* * * * *", Steps: []Step{{ID: "only", Prompt: "x"}}}}
+	s, svc, clock, _ := newTestScheduler(t, minutely)
+	ctx := context.Background()
+
+	s.tick(ctx)
+	clock.Advance(time.Minute)
+	s.tick(ctx)
+	svc.waitStarted(t)
+
+	clock.Advance(time.Minute)
+	s.tick(ctx)
+	runs := waitHistory(t, s, minutely.ID, ScheduleStatusSkipped, ScheduleStatusRunning)
+	if runs[0].Error == "" {
+		t.Fatalf("skipped run has no reason: %+v", runs[0])
+	}
+
+	svc.finish(0, FlowStatusFailed)
+	waitHistory(t, s, minutely.ID, ScheduleStatusSkipped, ScheduleStatusFailed)
+
+	clock.Advance(time.Minute)
+	s.tick(ctx)
+	svc.waitStarted(t)
+	svc.finish(1, FlowStatusCompleted)
+	runs = waitHistory(t, s, minutely.ID, ScheduleStatusCompleted, ScheduleStatusSkipped, ScheduleStatusFailed)
+	if runs[2].Error != `step "only" failed: boom` {
+		t.Fatalf("failed run error = %q", runs[2].Error)
+	}
+}
+
+func TestSchedulerStartFailsInterruptedRuns(t *testing.T) {
+	s, _, _, q := newTestScheduler(t)
+	ctx := context.Background()
+	if _, err := q.CreateFlowScheduleRun(ctx, db.CreateFlowScheduleRunParams{
+		ID: "stale", ProjectID: "proj", FlowID: "poll", ScheduledAt: 1, Status: string(ScheduleStatusRunning),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	runs := waitHistory(t, s, "poll", ScheduleStatusFailed)
+	if runs[0].Error == "" {
+		t.Fatal("interrupted run has no reason")
+	}
+}
+
+func TestSchedulerStopCancelsRunningFlow(t *testing.T) {
+	orig := scheduleTick
+	scheduleTick = 10 * time.Millisecond
+	t.Cleanup(func() { scheduleTick = orig })
+
+	minutely := Flow{ID: "poll", Spec: FlowSpec{Schedule: "* * * * *", Steps: []Step{{ID: "only", Prompt: "x"}}}}
+	s, svc, clock, _ := newTestScheduler(t, minutely)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	svc.waitStarted(t)
+
+	s.Stop()
+	runs := waitHistory(t, s, minutely.ID, ScheduleStatusCancelled)
+	if runs[0].Error != context.Canceled.Error() {
+		t.Fatalf("cancelled run = %+v", runs[0])
+	}
+}
@@ -35,8 +35,23 @@ const (
 	FlowStatusFailed          FlowStatus = "failed"
 	FlowStatusPostponed       FlowStatus = "postponed"
 	FlowStatusWaitingForInput FlowStatus = "waiting_for_input"
+	// FlowStatusWaitingApproval marks a `gate: manual` step that is held
+	// until ResolveGate records a decision. Unlike waiting_for_input it
+	// is persisted, so the pause survives a restart.
+	FlowStatusWaitingApproval FlowStatus = "waiting_approval"
 )
 
+// Gate decisions as stored in flow_states.approval.
+const (
+	gateApproved = "approved"
+	gateRejected = "rejected"
+)
+
+// gatePollInterval is how often a waiting gate re-reads flow_states so a
+// decision recorded by another process (TUI vs. `opencode serve`) is
+// picked up without a restart.
+var gatePollInterval = 2 * time.Second
+
 type FlowState struct {
 	SessionID      string
 	RootSessionID  string
@@ -62,6 +77,10 @@ type FlowState struct {
 type Service interface {
 	pubsub.Suscriber[FlowState]
 	Run(ctx context.Context, sessionPrefix string, flowID string, args map[string]any, fresh bool) (<-chan agentpkg.AgentEvent, <-chan *FlowState, error)
+	// ResolveGate records the user's decision for a step that is
+	// waiting_approval and wakes the run blocked on it. sessionID is the
+	// step session carried on the waiting FlowState.
+	ResolveGate(ctx context.Context, sessionID string, approved bool) (*FlowState, error)
 }
 
 type service struct {
@@ -73,6 +92,8 @@ type service struct {
 	agents      agentpkg.AgentFactory
 
 	interactiveHook InteractiveHook // nil → uses nopInteractiveHook (fail-fast)
+
+	gateWaiters sync.Map // step session ID → chan struct{}
 }
 
 // SetInteractiveHook installs the chat-bridge hook used by
@@ -394,6 +415,22 @@ func (s *service) runStep(
 	}
 	stepVars := map[string]any{"iteration": iteration}
 
+	// Manual gate: hold the step before any agent or session work so a
+	// step that is never approved costs nothing. A postponed re-entry
+	// doesn't run the step, so it isn't gated.
+	if step.Gate == GateManual && !(prevState != nil && postpone) {
+		if err := s.awaitGate(ctx, f, step, sessionID, rootSessionID, args, iteration, flowStates); err != nil {
+			if ctx.Err() != nil {
+				// Cancelled while waiting: leave the row in
+				// waiting_approval so a re-trigger resumes at the gate.
+				logging.Info("Flow cancelled at approval gate", "step", step.ID)
+				return
+			}
+			s.handleStepError(ctx, step, sessionID, rootSessionID, f.ID, args, iteration, err, wg, agentEvents, flowStates, nextSteps, f)
+			return
+		}
+	}
+
 	agentID := step.Agent
 	if agentID == "" {
 		agentID = "coder"
@@ -626,6 +663,9 @@ func (s *service) runStep(
 			// stepCtx applies the precedence chain:
 			//   Step.Timeout > OPENCODE_NON_INTERACTIVE_TASK_WAIT_TIMEOUT > ctx unwrapped.
 			stepScopedCtx, cancelStep := stepCtx(ctx, step)
+			// A step is a branch of the run: CancelBranch on its session
+			// fails this step alone and routing (fallback) carries on.
+			stepScopedCtx, releaseBranch := agentpkg.TrackBranch(stepScopedCtx, sess.ID)
 			// Install the step-scoped ctx as a value so the async task
 			// spawn path (agent-tool-async.go) can derive detached
 			// subagent contexts from it: a subagent then survives any
@@ -634,7 +674,7 @@ func (s *service) runStep(
 			// of running unbounded on context.Background(). See openspec
 			// flow-runtime-resume "step-scoped context" requirement.
 			runCtx := context.WithValue(stepScopedCtx, tools.StepScopedContextKey, stepScopedCtx)
-			runOpts := agentpkg.RunOptions{NonInteractive: true}
+			runOpts := agentpkg.RunOptions{NonInteractive: true, Batch: true}
 			// Per-step compaction-threshold override. Nil / zero leaves the
 			// agent runtime on its global default (AutoCompactionThreshold).
 			// Range validation (must be in (0, 1]) happens inside
@@ -644,13 +684,25 @@ func (s *service) runStep(
 			}
 			done, runErr := agentSvc.RunWith(runCtx, sess.ID, prompt, step.MaxTurns, runOpts)
 			if runErr != nil {
+				releaseBranch()
 				cancelStep()
 				lastErr = runErr
 				continue
 			}
 
 			result = <-done
+			releaseBranch()
 			cancelStep()
+			if agentpkg.BranchCancelled(stepScopedCtx) && ctx.Err() == nil {
+				// The user stopped this step; retrying would undo that.
+				lastErr = fmt.Errorf("step %q %w", step.ID, agentpkg.ErrBranchCancelled)
+				goto doneRetry
+			}
+			if result.Type == agentpkg.AgentEventTypeBudgetExceeded {
+				// Retrying would only try to spend more of a spent budget.
+				lastErr = result.Error
+				goto doneRetry
+			}
 			if result.Type == agentpkg.AgentEventTypeError {
 				lastErr = result.Error
 				continue
@@ -822,6 +874,157 @@ doneRetry:
 	}
 }
 
+// awaitGate blocks a `gate: manual` step until a decision is recorded.
+// A decision already persisted on the row (from ResolveGate while no run
+// was waiting) is consumed immediately; otherwise the step is persisted
+// and published as waiting_approval. Returns nil on approval,
+// ErrGateRejected on rejection, or the ctx error on cancellation.
+func (s *service) awaitGate(
+	ctx context.Context,
+	f *Flow,
+	step Step,
+	sessionID string,
+	rootSessionID string,
+	args map[string]any,
+	iteration int,
+	flowStates chan<- *FlowState,
+) error {
+	decision, err := s.takeGateDecision(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if decision == "" {
+		wake := make(chan struct{}, 1)
+		s.gateWaiters.Store(sessionID, wake)
+		defer s.gateWaiters.Delete(sessionID)
+
+		updatedAt, err := s.persistWaitingApproval(ctx, f, step, sessionID, rootSessionID, args, iteration)
+		if err != nil {
+			return fmt.Errorf("persisting flow state: %w", err)
+		}
+		waitingState := &FlowState{
+			SessionID:     sessionID,
+			RootSessionID: rootSessionID,
+			FlowID:        f.ID,
+			StepID:        step.ID,
+			Status:        FlowStatusWaitingApproval,
+			Args:          args,
+			Iteration:     iteration,
+			UpdatedAt:     updatedAt,
+		}
+		flowStates <- waitingState
+		s.Publish(pubsub.UpdatedEvent, *waitingState)
+		logging.Info("Step waiting for approval", "step", step.ID, "session_id", sessionID)
+
+		ticker := time.NewTicker(gatePollInterval)
+		defer ticker.Stop()
+		for decision == "" {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wake:
+			case <-ticker.C:
+			}
+			if decision, err = s.takeGateDecision(ctx, sessionID); err != nil {
+				return err
+			}
+		}
+	}
+	if decision == gateRejected {
+		return fmt.Errorf("%w: %s", ErrGateRejected, step.ID)
+	}
+	logging.Info("Step approved", "step", step.ID)
+	return nil
+}
+
+// takeGateDecision returns and clears the decision persisted on the
+// step's row, so each entry into a gated step (e.g. every self-loop
+// iteration) asks again. Returns "" when no decision is recorded.
+func (s *service) takeGateDecision(ctx context.Context, sessionID string) (string, error) {
+	fs, err := s.querier.GetFlowState(ctx, sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading approval: %w", err)
+	}
+	if !fs.Approval.Valid || fs.Approval.String == "" {
+		return "", nil
+	}
+	if _, err := s.querier.SetFlowStateApproval(ctx, db.SetFlowStateApprovalParams{SessionID: sessionID}); err != nil {
+		return "", fmt.Errorf("clearing approval: %w", err)
+	}
+	return fs.Approval.String, nil
+}
+
+// persistWaitingApproval writes the waiting_approval row for a gated
+// step, creating it when the step has never run.
+func (s *service) persistWaitingApproval(
+	ctx context.Context,
+	f *Flow,
+	step Step,
+	sessionID string,
+	rootSessionID string,
+	args map[string]any,
+	iteration int,
+) (int64, error) {
+	argsJSON, _ := json.Marshal(args)
+	if _, err := s.querier.GetFlowState(ctx, sessionID); err == nil {
+		state, err := s.querier.UpdateFlowState(ctx, db.UpdateFlowStateParams{
+			Status:    string(FlowStatusWaitingApproval),
+			Args:      sql.NullString{String: string(argsJSON), Valid: true},
+			Iteration: int64(iteration),
+			SessionID: sessionID,
+		})
+		return state.UpdatedAt, err
+	}
+	state, err := s.querier.CreateFlowState(ctx, db.CreateFlowStateParams{
+		SessionID:     sessionID,
+		RootSessionID: rootSessionID,
+		FlowID:        f.ID,
+		StepID:        step.ID,
+		Status:        string(FlowStatusWaitingApproval),
+		Args:          sql.NullString{String: string(argsJSON), Valid: true},
+		Iteration:     int64(iteration),
+	})
+	return state.CreatedAt, err
+}
+
+// ResolveGate persists an approve/reject decision for a waiting step and
+// wakes the in-process run blocked on it, if any. A run in another
+// process picks the decision up on its next poll; with no run at all,
+// the decision is consumed when the flow is re-triggered.
+func (s *service) ResolveGate(ctx context.Context, sessionID string, approved bool) (*FlowState, error) {
+	fs, err := s.querier.GetFlowState(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrGateNotPending, sessionID)
+		}
+		return nil, err
+	}
+	if fs.Status != string(FlowStatusWaitingApproval) {
+		return nil, fmt.Errorf("%w: %s is %s", ErrGateNotPending, fs.StepID, fs.Status)
+	}
+	decision := gateRejected
+	if approved {
+		decision = gateApproved
+	}
+	updated, err := s.querier.SetFlowStateApproval(ctx, db.SetFlowStateApprovalParams{
+		Approval:  sql.NullString{String: decision, Valid: true},
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persisting approval: %w", err)
+	}
+	if wake, ok := s.gateWaiters.Load(sessionID); ok {
+		select {
+		case wake.(chan struct{}) <- struct{}{}:
+		default:
+		}
+	}
+	return dbFlowStateToFlowState(updated), nil
+}
+
 func (s *service) handleStepError(
 	ctx context.Context,
 	step Step,
@@ -894,7 +1097,8 @@ func (s *service) handleStepError(
 // (collectResumableSteps) for the given existing-states set. A
 // re-invocation of Run for the same session prefix resumes when there
 // is work still owed to the prior run — either a mid-state status
-// (running / postponed / waiting_for_input, or `failed` under per-flow
+// (running / postponed / waiting_for_input / waiting_approval, or
+// `failed` under per-flow
 // opt-in), OR a completed step whose rules still produce a pending
 // target: a self-route (next iteration was never scheduled — the
 // crash window between writing "iter N completed" and "iter N+1
@@ -920,7 +1124,8 @@ func hasResumableWork(states []db.FlowState, f *Flow, resumeOnFailure bool) bool
 		switch st.Status {
 		case string(FlowStatusRunning),
 			string(FlowStatusPostponed),
-			string(FlowStatusWaitingForInput):
+			string(FlowStatusWaitingForInput),
+			string(FlowStatusWaitingApproval):
 			return true
 		case string(FlowStatusFailed):
 			if resumeOnFailure {
@@ -0,0 +1,78 @@
+package flow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	agentpkg "github.com/opencode-ai/opencode/internal/llm/agent"
+)
+
+// TestCancelBranchFailsStepWithoutRetry cancels one step through its
+// session: the step fails without using its retries and the fallback runs.
+func TestCancelBranchFailsStepWithoutRetry(t *testing.T) {
+	registerTestFlow(t, Flow{
+		ID:   "cancel-step",
+		Name: "cancel-step",
+		Spec: FlowSpec{
+			Steps: []Step{
+				{ID: "slow", Prompt: "take forever", Fallback: &Fallback{Retry: 2, To: "recover"}},
+				{ID: "recover", Prompt: "clean up"},
+			},
+		},
+	})
+	agent := newStubAgent()
+	agent.block = func(prompt string) bool { return strings.Contains(prompt, "take forever") }
+	svc := NewService(&stubSessions{}, nil, &stubQuerier{}, &stubPermissions{}, &stubAgentFactory{agent: agent})
+
+	agentEvents, flowStates, err := svc.Run(context.Background(), "p", "cancel-step", map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	go drainAgentEvents(agentEvents)
+
+	var slowSession string
+	timeout := time.After(5 * time.Second)
+	for slowSession == "" {
+		select {
+		case st, ok := <-flowStates:
+			if !ok {
+				t.Fatal("flow finished before the slow step started")
+			}
+			if st.StepID == "slow" && st.Status == FlowStatusRunning {
+				slowSession = st.SessionID
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the slow step")
+		}
+	}
+	for !agentpkg.IsBranchRunning(slowSession) {
+		select {
+		case <-timeout:
+			t.Fatal("slow step never registered as a branch")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if !agentpkg.CancelBranch(slowSession) {
+		t.Fatal("CancelBranch() = false for a running step")
+	}
+
+	final := map[string]*FlowState{}
+	for st := range flowStates {
+		final[st.StepID] = st
+	}
+	slow := final["slow"]
+	if slow == nil || slow.Status != FlowStatusFailed || !strings.Contains(slow.Output, "cancelled by user") {
+		t.Fatalf("slow state = %+v, want failed with a cancellation message", slow)
+	}
+	if recover := final["recover"]; recover == nil || recover.Status != FlowStatusCompleted {
+		t.Fatalf("recover state = %+v, want completed", recover)
+	}
+	if got := agent.callCount(); got != 2 {
+		t.Fatalf("agent calls = %d, want 2 (slow once, recover once)", got)
+	}
+	if agentpkg.IsBranchRunning(slowSession) {
+		t.Fatal("slow step still tracked after it finished")
+	}
+}
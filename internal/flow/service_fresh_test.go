@@ -20,6 +20,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/question"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/translation"
 )
 
 // stubQuerier records calls to delete operations and returns pre-configured flow states.
@@ -122,6 +123,7 @@ func (q *stubQuerier) UpdateFlowState(_ context.Context, arg db.UpdateFlowStateP
 			updated.FlowID = fs.FlowID
 			updated.StepID = fs.StepID
 			updated.CreatedAt = fs.CreatedAt
+			updated.Approval = fs.Approval
 			q.flowStates[i] = updated
 			return updated, nil
 		}
@@ -129,6 +131,18 @@ func (q *stubQuerier) UpdateFlowState(_ context.Context, arg db.UpdateFlowStateP
 	return updated, nil
 }
 
+func (q *stubQuerier) SetFlowStateApproval(_ context.Context, arg db.SetFlowStateApprovalParams) (db.FlowState, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, fs := range q.flowStates {
+		if fs.SessionID == arg.SessionID {
+			q.flowStates[i].Approval = arg.Approval
+			return q.flowStates[i], nil
+		}
+	}
+	return db.FlowState{}, sql.ErrNoRows
+}
+
 func (q *stubQuerier) WithTx(_ *sql.Tx) db.QuerierWithTx { return q }
 
 // stubSessions records delete calls and provides minimal session operations.
@@ -173,6 +187,8 @@ type stubAgent struct {
 	responses []agentpkg.AgentEvent
 	calls     int
 	prompts   []string
+	// block, when set, makes prompts it matches run until ctx is done.
+	block func(prompt string) bool
 }
 
 func newStubAgent() *stubAgent {
@@ -197,10 +213,20 @@ func (a *stubAgent) Run(ctx context.Context, sessionID string, prompt string, ma
 	return a.RunWith(ctx, sessionID, prompt, maxTurns, agentpkg.RunOptions{}, atts...)
 }
 
-func (a *stubAgent) RunWith(_ context.Context, _ string, prompt string, _ int, _ agentpkg.RunOptions, _ ...message.Attachment) (<-chan agentpkg.AgentEvent, error) {
+func (a *stubAgent) RunWith(ctx context.Context, _ string, prompt string, _ int, _ agentpkg.RunOptions, _ ...message.Attachment) (<-chan agentpkg.AgentEvent, error) {
 	a.mu.Lock()
 	a.prompts = append(a.prompts, prompt)
 	ch := make(chan agentpkg.AgentEvent, 1)
+	if a.block != nil && a.block(prompt) {
+		a.calls++
+		a.mu.Unlock()
+		go func() {
+			<-ctx.Done()
+			ch <- agentpkg.AgentEvent{Type: agentpkg.AgentEventTypeError, Error: ctx.Err()}
+			close(ch)
+		}()
+		return ch, nil
+	}
 	var event agentpkg.AgentEvent
 	if len(a.responses) > 0 {
 		idx := a.calls
@@ -237,11 +263,19 @@ func (a *stubAgent) UnlockSession(_ string)                  {}
 func (a *stubAgent) Update(_ config.AgentName, _ models.ModelID) (models.Model, error) {
 	return models.Model{}, nil
 }
+func (a *stubAgent) Reload() (models.Model, error)                   { return models.Model{}, nil }
 func (a *stubAgent) Summarize(_ context.Context, _ string) error     { return nil }
 func (a *stubAgent) SummarizeSync(_ context.Context, _ string) error { return nil }
 func (a *stubAgent) GenerateRecap(_ context.Context, _ string) (string, error) {
 	return "", nil
 }
+func (a *stubAgent) InspectContext(_ context.Context, _ string) (agentpkg.ContextReport, error) {
+	return agentpkg.ContextReport{}, nil
+}
+func (a *stubAgent) ExcludeFromNextTurn(_ string, _ []string) error { return nil }
+func (a *stubAgent) RegenerateTitle(_ context.Context, _ string) (session.Session, error) {
+	return session.Session{}, nil
+}
 
 // stubAgentFactory returns the stubAgent.
 type stubAgentFactory struct {
@@ -271,6 +305,24 @@ func (f *stubAgentFactory) TodoStore() tools.TodoStore {
 	return nil
 }
 
+func (f *stubAgentFactory) SetBlackboard(_ tools.BlackboardService) {}
+
+func (f *stubAgentFactory) Blackboard() tools.BlackboardService {
+	return nil
+}
+
+func (f *stubAgentFactory) SetCodeIndex(_ tools.CodeSearchService) {}
+
+func (f *stubAgentFactory) CodeIndex() tools.CodeSearchService {
+	return nil
+}
+
+func (f *stubAgentFactory) SetMemory(_ tools.MemoryService) {}
+
+func (f *stubAgentFactory) Memory() tools.MemoryService {
+	return nil
+}
+
 func (f *stubAgentFactory) SetQuestionService(_ question.Service) {}
 
 func (f *stubAgentFactory) QuestionService() question.Service {
@@ -287,6 +339,10 @@ func (f *stubAgentFactory) SetHookRegistry(_ *hooks.Registry) {}
 
 func (f *stubAgentFactory) HookRegistry() *hooks.Registry { return nil }
 
+func (f *stubAgentFactory) SetTranslationService(_ translation.Service) {}
+
+func (f *stubAgentFactory) TranslationService() translation.Service { return nil }
+
 func registerTestFlow(t *testing.T, f Flow) {
 	t.Helper()
 	flowCacheLock.Lock()
@@ -0,0 +1,163 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	agentpkg "github.com/opencode-ai/opencode/internal/llm/agent"
+)
+
+func gatedTestFlow(id string) Flow {
+	return Flow{
+		ID:   id,
+		Name: id,
+		Spec: FlowSpec{
+			Steps: []Step{
+				{ID: "plan", Prompt: "plan it", Rules: []Rule{{Then: "deploy"}}},
+				{ID: "deploy", Prompt: "ship it", Gate: GateManual},
+			},
+		},
+	}
+}
+
+func drainAgentEvents(ch <-chan agentpkg.AgentEvent) {
+	for range ch {
+	}
+}
+
+// drainUntilGate reads flow states until the gated step reports
+// waiting_approval, failing the test if the run ends first.
+func drainUntilGate(t *testing.T, flowStates <-chan *FlowState) *FlowState {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case st, ok := <-flowStates:
+			if !ok {
+				t.Fatal("flow finished without reaching the gate")
+			}
+			if st.Status == FlowStatusWaitingApproval {
+				return st
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the gate")
+		}
+	}
+}
+
+func TestManualGateApproveResumesStep(t *testing.T) {
+	registerTestFlow(t, gatedTestFlow("gate-approve"))
+	q := &stubQuerier{}
+	agent := newStubAgent()
+	svc := NewService(&stubSessions{}, nil, q, &stubPermissions{}, &stubAgentFactory{agent: agent})
+
+	agentEvents, flowStates, err := svc.Run(context.Background(), "p", "gate-approve", map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	go drainAgentEvents(agentEvents)
+
+	waiting := drainUntilGate(t, flowStates)
+	if waiting.StepID != "deploy" {
+		t.Fatalf("waiting step = %q, want deploy", waiting.StepID)
+	}
+	if got := agent.callCount(); got != 1 {
+		t.Fatalf("agent calls before approval = %d, want 1", got)
+	}
+
+	if _, err := svc.ResolveGate(context.Background(), waiting.SessionID, true); err != nil {
+		t.Fatalf("ResolveGate() error: %v", err)
+	}
+	var last *FlowState
+	for st := range flowStates {
+		last = st
+	}
+	if last == nil || last.StepID != "deploy" || last.Status != FlowStatusCompleted {
+		t.Fatalf("final state = %+v, want deploy completed", last)
+	}
+	if got := agent.callCount(); got != 2 {
+		t.Errorf("agent calls = %d, want 2", got)
+	}
+	for _, fs := range q.snapshotFlowStates() {
+		if fs.Approval.Valid {
+			t.Errorf("step %s approval not consumed: %q", fs.StepID, fs.Approval.String)
+		}
+	}
+}
+
+func TestManualGateRejectFailsStep(t *testing.T) {
+	registerTestFlow(t, gatedTestFlow("gate-reject"))
+	q := &stubQuerier{}
+	agent := newStubAgent()
+	svc := NewService(&stubSessions{}, nil, q, &stubPermissions{}, &stubAgentFactory{agent: agent})
+
+	agentEvents, flowStates, err := svc.Run(context.Background(), "p", "gate-reject", map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	go drainAgentEvents(agentEvents)
+
+	waiting := drainUntilGate(t, flowStates)
+	if _, err := svc.ResolveGate(context.Background(), waiting.SessionID, false); err != nil {
+		t.Fatalf("ResolveGate() error: %v", err)
+	}
+	var last *FlowState
+	for st := range flowStates {
+		last = st
+	}
+	if last == nil || last.Status != FlowStatusFailed || !strings.Contains(last.Output, ErrGateRejected.Error()) {
+		t.Fatalf("final state = %+v, want rejected failure", last)
+	}
+	if got := agent.callCount(); got != 1 {
+		t.Errorf("agent calls = %d, want 1 (deploy must not run)", got)
+	}
+}
+
+func TestManualGateDecisionSurvivesCancel(t *testing.T) {
+	registerTestFlow(t, gatedTestFlow("gate-resume"))
+	q := &stubQuerier{}
+	agent := newStubAgent()
+	svc := NewService(&stubSessions{}, nil, q, &stubPermissions{}, &stubAgentFactory{agent: agent})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	agentEvents, flowStates, err := svc.Run(ctx, "p", "gate-resume", map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	go drainAgentEvents(agentEvents)
+	waiting := drainUntilGate(t, flowStates)
+	cancel()
+	for range flowStates {
+	}
+
+	// No run is waiting any more: the decision is persisted and
+	// consumed by the next trigger.
+	if _, err := svc.ResolveGate(context.Background(), waiting.SessionID, true); err != nil {
+		t.Fatalf("ResolveGate() error: %v", err)
+	}
+	if _, err := svc.ResolveGate(context.Background(), "p-gate-resume-plan", true); !errors.Is(err, ErrGateNotPending) {
+		t.Errorf("ResolveGate on completed step error = %v, want ErrGateNotPending", err)
+	}
+
+	agentEvents, flowStates, err = svc.Run(context.Background(), "p", "gate-resume", map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("second Run() error: %v", err)
+	}
+	go drainAgentEvents(agentEvents)
+	var last *FlowState
+	for st := range flowStates {
+		if st.Status == FlowStatusWaitingApproval {
+			t.Fatal("persisted approval was not honoured on resume")
+		}
+		last = st
+	}
+	if last == nil || last.StepID != "deploy" || last.Status != FlowStatusCompleted {
+		t.Fatalf("final state = %+v, want deploy completed", last)
+	}
+	if got := agent.callCount(); got != 2 {
+		t.Errorf("agent calls = %d, want 2 (plan once, deploy once)", got)
+	}
+}
@@ -21,6 +21,10 @@ const (
 
 	// JSONSchema format outputs the AI response validated against a JSON schema.
 	JSONSchema OutputFormat = "json_schema"
+
+	// JSONL format streams the run's events as JSON lines while it runs,
+	// ending with a result line.
+	JSONL OutputFormat = "jsonl"
 )
 
 // String returns the string representation of the OutputFormat
@@ -33,6 +37,7 @@ var SupportedFormats = []string{
 	string(Text),
 	string(JSON),
 	string(JSONSchema),
+	string(JSONL),
 }
 
 // Parse converts a string to an OutputFormat
@@ -46,6 +51,8 @@ func Parse(s string) (OutputFormat, error) {
 		return JSON, nil
 	case string(JSONSchema):
 		return JSONSchema, nil
+	case string(JSONL):
+		return JSONL, nil
 	default:
 		return "", fmt.Errorf("invalid format: %s", s)
 	}
@@ -175,8 +182,9 @@ func GetHelpText() string {
 - %s: Output validated against a JSON schema
     json_schema='{"type":"object",...}'  (inline)
     json_schema=/path/to/schema.json    (file path)
-    json_schema='{"$ref":"/path/to/schema.json"}'  ($ref)`,
-		Text, JSON, JSONSchema)
+    json_schema='{"$ref":"/path/to/schema.json"}'  ($ref)
+- %s: Every tool call, tool result, message, usage update and agent event as a JSON line while the run goes on`,
+		Text, JSON, JSONSchema, JSONL)
 }
 
 // FormatOutput formats the AI response according to the specified format
@@ -15,6 +15,7 @@ func TestParse(t *testing.T) {
 		{"text", Text, false},
 		{"json", JSON, false},
 		{"json_schema", JSONSchema, false},
+		{"jsonl", JSONL, false},
 		{"TEXT", Text, false},
 		{"invalid", "", true},
 	}
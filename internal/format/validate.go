@@ -0,0 +1,235 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateValue checks a decoded JSON value against schema. It covers the
+// keywords output schemas use in practice: type, enum, const, properties,
+// required, additionalProperties, items, the length and range bounds, and
+// allOf/anyOf/oneOf. Unknown keywords are ignored rather than rejected, so a
+// schema using more of JSON Schema is checked loosely, never refused.
+func ValidateValue(schema map[string]any, value any) error {
+	return validateAt("$", schema, value)
+}
+
+// ValidateJSON decodes data and checks it with ValidateValue.
+func ValidateJSON(schema map[string]any, data string) error {
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return ValidateValue(schema, value)
+}
+
+func validateAt(path string, schema map[string]any, value any) error {
+	if schema == nil {
+		return nil
+	}
+	if t, ok := schema["type"]; ok {
+		if err := checkType(path, t, value); err != nil {
+			return err
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(e, value) }) {
+			return fmt.Errorf("%s: %s is not one of %s", path, compactJSON(value), compactJSON(enum))
+		}
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		return fmt.Errorf("%s: must be %s", path, compactJSON(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if err := validateObject(path, schema, v); err != nil {
+			return err
+		}
+	case []any:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			return fmt.Errorf("%s: must have at least %g items", path, n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			return fmt.Errorf("%s: must have at most %g items", path, n)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateAt(fmt.Sprintf("%s[%d]", path, i), items, item); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			return fmt.Errorf("%s: must be at least %g characters", path, n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			return fmt.Errorf("%s: must be at most %g characters", path, n)
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && v < n {
+			return fmt.Errorf("%s: must be >= %g", path, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && v > n {
+			return fmt.Errorf("%s: must be <= %g", path, n)
+		}
+	}
+
+	return validateCombinators(path, schema, value)
+}
+
+func validateObject(path string, schema map[string]any, obj map[string]any) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := obj[name]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+	}
+	props, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if propSchema, ok := props[k].(map[string]any); ok {
+			if err := validateAt(path+"."+k, propSchema, obj[k]); err != nil {
+				return err
+			}
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				return fmt.Errorf("%s: unexpected property %q", path, k)
+			}
+		case map[string]any:
+			if err := validateAt(path+"."+k, extra, obj[k]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateCombinators(path string, schema map[string]any, value any) error {
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, s := range all {
+			if sub, ok := s.(map[string]any); ok {
+				if err := validateAt(path, sub, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		if matches(path, anyOf, value) == 0 {
+			return fmt.Errorf("%s: does not match any of the allowed schemas", path)
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		if n := matches(path, oneOf, value); n != 1 {
+			return fmt.Errorf("%s: must match exactly one of the allowed schemas, matches %d", path, n)
+		}
+	}
+	return nil
+}
+
+func matches(path string, schemas []any, value any) int {
+	n := 0
+	for _, s := range schemas {
+		if sub, ok := s.(map[string]any); ok && validateAt(path, sub, value) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func checkType(path string, t any, value any) error {
+	var allowed []string
+	switch t := t.(type) {
+	case string:
+		allowed = []string{t}
+	case []any:
+		for _, s := range t {
+			if s, ok := s.(string); ok {
+				allowed = append(allowed, s)
+			}
+		}
+	default:
+		return nil
+	}
+	actual := jsonType(value)
+	for _, a := range allowed {
+		if a == actual || (a == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(allowed, " or "), actual)
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// jsonEqual compares values after a JSON round trip, so schemas written in
+// Go (with int literals) compare equal to decoded documents.
+func jsonEqual(a, b any) bool {
+	var na, nb any
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	_ = json.Unmarshal(ja, &na)
+	_ = json.Unmarshal(jb, &nb)
+	return reflect.DeepEqual(na, nb)
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,72 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJSON(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"summary": map[string]any{"type": "string", "minLength": 1},
+			"score":   map[string]any{"type": "integer", "minimum": 0, "maximum": 10},
+			"status":  map[string]any{"enum": []any{"ok", "fail"}},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+		"required":             []any{"summary", "score"},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"valid", `{"summary":"x","score":3,"status":"ok","tags":["a"]}`, ""},
+		{"not json", `{"summary":`, "invalid JSON"},
+		{"missing required", `{"summary":"x"}`, `$: missing required property "score"`},
+		{"wrong type", `{"summary":"x","score":"3"}`, "$.score: expected integer, got string"},
+		{"fraction for integer", `{"summary":"x","score":2.5}`, "$.score: expected integer, got number"},
+		{"out of range", `{"summary":"x","score":11}`, "$.score: must be <= 10"},
+		{"enum", `{"summary":"x","score":1,"status":"maybe"}`, `$.status: "maybe" is not one of ["ok","fail"]`},
+		{"item type", `{"summary":"x","score":1,"tags":["a",2]}`, "$.tags[1]: expected string, got integer"},
+		{"extra property", `{"summary":"x","score":1,"other":true}`, `$: unexpected property "other"`},
+		{"too short", `{"summary":"","score":1}`, "$.summary: must be at least 1 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON(schema, tt.doc)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateValue_Combinators(t *testing.T) {
+	schema := map[string]any{
+		"anyOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "null"},
+		},
+	}
+	require.NoError(t, ValidateValue(schema, "x"))
+	require.NoError(t, ValidateValue(schema, nil))
+	require.Error(t, ValidateValue(schema, 1.0))
+
+	oneOf := map[string]any{"oneOf": []any{
+		map[string]any{"type": "number"},
+		map[string]any{"type": "integer"},
+	}}
+	require.Error(t, ValidateValue(oneOf, 1.0), "an integer matches both branches")
+	require.NoError(t, ValidateValue(oneOf, 1.5))
+
+	require.NoError(t, ValidateValue(map[string]any{"type": []any{"string", "null"}}, nil))
+}
@@ -0,0 +1,220 @@
+// Package gitsnapshot records the whole working tree of a git repository as
+// a hidden commit and resets the tree back to it. Snapshots live under
+// refs/opencode/snapshots/ so they survive `git gc` without showing up as
+// branches, tags or stash entries, and taking one never touches the real
+// index, HEAD or any file in the work tree.
+package gitsnapshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const refPrefix = "refs/opencode/snapshots/"
+
+var (
+	// ErrNotRepository is returned when dir is not inside a git work tree.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrNoSnapshot is returned by Restore when nothing was recorded
+	// under the given name.
+	ErrNoSnapshot = errors.New("no snapshot recorded")
+)
+
+// Take records the current state of the work tree containing dir, tracked
+// and untracked files alike but not ignored ones, under name, replacing any
+// earlier snapshot of that name. It returns the snapshot commit.
+func Take(ctx context.Context, dir, name string) (string, error) {
+	top, err := toplevel(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	tree, err := worktreeTree(ctx, top)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"commit-tree", tree, "-m", "opencode snapshot " + name}
+	if head, err := git(ctx, top, nil, "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		args = append(args, "-p", strings.TrimSpace(string(head)))
+	}
+	// Snapshot commits are never pushed or shown, so they carry a fixed
+	// identity instead of failing in repositories without user.name.
+	out, err := git(ctx, top, []string{
+		"GIT_AUTHOR_NAME=opencode", "GIT_AUTHOR_EMAIL=opencode@localhost",
+		"GIT_COMMITTER_NAME=opencode", "GIT_COMMITTER_EMAIL=opencode@localhost",
+	}, args...)
+	if err != nil {
+		return "", err
+	}
+	commit := strings.TrimSpace(string(out))
+	if _, err := git(ctx, top, nil, "update-ref", refPrefix+name, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// Restore resets the work tree containing dir to the snapshot recorded
+// under name: files changed or deleted since are written back and files
+// created since are removed. Ignored files, the index and HEAD are left
+// alone. It returns the paths it touched, relative to the repository root.
+func Restore(ctx context.Context, dir, name string) ([]string, error) {
+	top, err := toplevel(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := git(ctx, top, nil, "rev-parse", "--verify", "-q", refPrefix+name+"^{tree}")
+	if err != nil {
+		return nil, ErrNoSnapshot
+	}
+	snapTree := strings.TrimSpace(string(snap))
+	current, err := worktreeTree(ctx, top)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git(ctx, top, nil, "diff", "--name-status", "--no-renames", "-z", snapTree, current)
+	if err != nil {
+		return nil, err
+	}
+
+	var created, restore []string
+	fields := bytes.Split(bytes.TrimSuffix(diff, []byte{0}), []byte{0})
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := string(fields[i]), string(fields[i+1])
+		if status == "A" {
+			created = append(created, path)
+		} else {
+			restore = append(restore, path)
+		}
+	}
+
+	for _, path := range created {
+		if err := os.Remove(filepath.Join(top, path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		removeEmptyParents(top, filepath.Dir(filepath.Join(top, path)))
+	}
+	if len(restore) > 0 {
+		if err := checkoutFromTree(ctx, top, snapTree, restore); err != nil {
+			return nil, err
+		}
+	}
+	return append(restore, created...), nil
+}
+
+// Delete drops the snapshot recorded under name, if any.
+func Delete(ctx context.Context, dir, name string) error {
+	top, err := toplevel(ctx, dir)
+	if err != nil {
+		return err
+	}
+	_, err = git(ctx, top, nil, "update-ref", "-d", refPrefix+name)
+	return err
+}
+
+func toplevel(ctx context.Context, dir string) (string, error) {
+	out, err := git(ctx, dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", ErrNotRepository
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// worktreeTree writes a tree object for the work tree as `git add -A` would
+// stage it, using a throwaway copy of the index.
+func worktreeTree(ctx context.Context, top string) (string, error) {
+	index, cleanup, err := tempIndex(ctx, top)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := git(ctx, top, env, "add", "-A", "--", "."); err != nil {
+		return "", err
+	}
+	out, err := git(ctx, top, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// checkoutFromTree writes paths from tree into the work tree.
+func checkoutFromTree(ctx context.Context, top, tree string, paths []string) error {
+	index, cleanup, err := tempIndex(ctx, top)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := git(ctx, top, env, "read-tree", tree); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "checkout-index", "-f", "-z", "--stdin")
+	cmd.Dir = top
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git checkout-index: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// tempIndex copies the repository's index so git can reuse its cached
+// file stats, falling back to an empty index for fresh repositories.
+func tempIndex(ctx context.Context, top string) (string, func(), error) {
+	f, err := os.CreateTemp("", "opencode-index-*")
+	if err != nil {
+		return "", nil, err
+	}
+	name := f.Name()
+	cleanup := func() { _ = os.Remove(name) }
+
+	copied := false
+	if out, err := git(ctx, top, nil, "rev-parse", "--git-path", "index"); err == nil {
+		path := strings.TrimSpace(string(out))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(top, path)
+		}
+		if src, err := os.Open(path); err == nil {
+			_, err = io.Copy(f, src)
+			_ = src.Close()
+			copied = err == nil
+		}
+	}
+	_ = f.Close()
+	if !copied {
+		// git refuses an empty file as an index; a missing one is fine.
+		_ = os.Remove(name)
+	}
+	return name, cleanup, nil
+}
+
+func removeEmptyParents(top, dir string) {
+	for dir != top && strings.HasPrefix(dir, top) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func git(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package gitsnapshot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("init", "-q")
+	run("config", "user.name", "test")
+	run("config", "user.email", "test@example.com")
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	writeFile(t, dir, "tracked.txt", "v1\n")
+	writeFile(t, dir, "removed.txt", "keep me\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "init")
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestTakeAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := initRepo(t)
+
+	// Uncommitted and untracked work from before the agent ran.
+	writeFile(t, dir, "tracked.txt", "v2 uncommitted\n")
+	writeFile(t, dir, "notes.txt", "untracked\n")
+	statusBefore := gitStatus(t, dir)
+
+	_, err := Take(ctx, dir, "s1")
+	require.NoError(t, err)
+	require.Equal(t, statusBefore, gitStatus(t, dir), "taking a snapshot must not touch the index or work tree")
+
+	// The agent's changes.
+	writeFile(t, dir, "tracked.txt", "v3 agent\n")
+	writeFile(t, dir, "notes.txt", "rewritten\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "removed.txt")))
+	writeFile(t, dir, "pkg/new/file.go", "package new\n")
+	writeFile(t, dir, "build.log", "ignored\n")
+
+	paths, err := Restore(ctx, filepath.Join(dir), "s1")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"tracked.txt", "notes.txt", "removed.txt", "pkg/new/file.go"}, paths)
+
+	require.Equal(t, "v2 uncommitted\n", readFile(t, dir, "tracked.txt"))
+	require.Equal(t, "untracked\n", readFile(t, dir, "notes.txt"))
+	require.Equal(t, "keep me\n", readFile(t, dir, "removed.txt"))
+	require.NoDirExists(t, filepath.Join(dir, "pkg"))
+	require.Equal(t, "ignored\n", readFile(t, dir, "build.log"))
+	require.Equal(t, statusBefore, gitStatus(t, dir))
+}
+
+func TestRestoreWithoutSnapshot(t *testing.T) {
+	dir := initRepo(t)
+	_, err := Restore(context.Background(), dir, "missing")
+	require.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func TestNotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, err := Take(context.Background(), t.TempDir(), "s1")
+	require.ErrorIs(t, err, ErrNotRepository)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := initRepo(t)
+	_, err := Take(ctx, dir, "s1")
+	require.NoError(t, err)
+	require.NoError(t, Delete(ctx, dir, "s1"))
+	_, err = Restore(ctx, dir, "s1")
+	require.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func gitStatus(t *testing.T, dir string) string {
+	t.Helper()
+	cmd := exec.Command("git", "status", "--porcelain=v1", "--untracked-files=all")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	return string(out)
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Checkpoint records the on-disk content of path as it was before messageID
+// first modified it. It must be called before the write; later calls for the
+// same message and path keep the original pre-image.
+func (s *service) Checkpoint(ctx context.Context, sessionID, messageID, path string) error {
+	if sessionID == "" || messageID == "" {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s for checkpoint: %w", path, err)
+	}
+	return s.q.CreateCheckpoint(ctx, db.CreateCheckpointParams{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		MessageID: messageID,
+		Path:      path,
+		Content:   string(content),
+		Existed:   existed,
+	})
+}
+
+// BaselineFile is a file as it was before a point in the session.
+type BaselineFile struct {
+	Path    string
+	Content string
+	// Existed is false for files created since.
+	Existed bool
+}
+
+// Baseline returns every file changed by messageID or any later message in
+// the session with its content from before that point, in the order they
+// were first changed. Unlike Revert it leaves files and checkpoints alone.
+func (s *service) Baseline(ctx context.Context, sessionID, messageID string) ([]BaselineFile, error) {
+	checkpoints, err := s.q.ListCheckpointsSinceMessage(ctx, db.ListCheckpointsSinceMessageParams{
+		SessionID: sessionID,
+		MessageID: messageID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]BaselineFile, 0, len(checkpoints))
+	seen := make(map[string]struct{})
+	for _, cp := range checkpoints {
+		if _, ok := seen[cp.Path]; ok {
+			continue
+		}
+		seen[cp.Path] = struct{}{}
+		files = append(files, BaselineFile{Path: cp.Path, Content: cp.Content, Existed: cp.Existed})
+	}
+	return files, nil
+}
+
+// Revert restores every file changed by messageID or any later message in
+// the session to its content from before that point. Files that did not
+// exist yet are removed. The consumed checkpoints are dropped, so reverting
+// an earlier message afterwards steps further back. It returns the paths
+// that were restored.
+func (s *service) Revert(ctx context.Context, sessionID, messageID string) ([]string, error) {
+	checkpoints, err := s.q.ListCheckpointsSinceMessage(ctx, db.ListCheckpointsSinceMessageParams{
+		SessionID: sessionID,
+		MessageID: messageID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The earliest checkpoint of each path holds its state at the boundary.
+	restored := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, cp := range checkpoints {
+		if _, ok := seen[cp.Path]; ok {
+			continue
+		}
+		seen[cp.Path] = struct{}{}
+
+		if err := restoreCheckpoint(cp); err != nil {
+			return restored, err
+		}
+		restored = append(restored, cp.Path)
+
+		if _, err := s.CreateVersion(ctx, sessionID, cp.Path, cp.Content); err != nil {
+			logging.Debug("Error creating file history version after revert", "path", cp.Path, "error", err)
+		}
+	}
+
+	for _, cp := range checkpoints {
+		if err := s.q.DeleteCheckpoint(ctx, cp.ID); err != nil {
+			return restored, fmt.Errorf("failed to drop checkpoint for %s: %w", cp.Path, err)
+		}
+	}
+	logging.Info("Reverted session files", "sessionID", sessionID, "messageID", messageID, "files", len(restored))
+	return restored, nil
+}
+
+func restoreCheckpoint(cp db.Checkpoint) error {
+	if !cp.Existed {
+		if err := os.Remove(cp.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", cp.Path, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cp.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directories for %s: %w", cp.Path, err)
+	}
+	if err := os.WriteFile(cp.Path, []byte(cp.Content), 0o644); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", cp.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func newCheckpointTestService(t *testing.T) (*service, db.Querier) {
+	t.Helper()
+	sqlDB := db.OpenTestDB(t)
+	q := db.NewSQLiteQuerier(sqlDB)
+	return NewService(q, sqlDB).(*service), q
+}
+
+func TestCheckpointRevert(t *testing.T) {
+	ctx := context.Background()
+	svc, q := newCheckpointTestService(t)
+
+	if _, err := q.CreateSession(ctx, db.CreateSessionParams{ID: "s", Title: "t"}); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	for i, id := range []string{"user-1", "asst-1", "user-2", "asst-2"} {
+		if _, err := q.CreateMessage(ctx, db.CreateMessageParams{
+			ID: id, SessionID: "s", Role: "user", Parts: "[]",
+			Seq: sql.NullInt64{Int64: int64(i + 1), Valid: true},
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "sub", "b.txt")
+	write := func(msgID, path, content string) {
+		t.Helper()
+		if err := svc.Checkpoint(ctx, "s", msgID, path); err != nil {
+			t.Fatalf("checkpoint: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertContent := func(path, want string) {
+		t.Helper()
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+		}
+	}
+
+	if err := os.WriteFile(a, []byte("v0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write("asst-1", a, "v1")
+	write("asst-1", a, "v2") // second edit in the same message keeps the v0 pre-image
+	write("asst-2", a, "v3")
+	write("asst-2", b, "new")
+
+	// Baseline reports the same pre-images without touching anything.
+	base, err := svc.Baseline(ctx, "s", "user-2")
+	if err != nil {
+		t.Fatalf("baseline: %v", err)
+	}
+	if len(base) != 2 || base[0] != (BaselineFile{Path: a, Content: "v2", Existed: true}) || base[1] != (BaselineFile{Path: b}) {
+		t.Fatalf("baseline = %+v", base)
+	}
+	assertContent(a, "v3")
+
+	restored, err := svc.Revert(ctx, "s", "user-2")
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if len(restored) != 2 {
+		t.Fatalf("restored = %v, want a.txt and b.txt", restored)
+	}
+	assertContent(a, "v2")
+	if _, err := os.Stat(b); !os.IsNotExist(err) {
+		t.Errorf("b.txt should be removed by revert, stat err = %v", err)
+	}
+
+	// The turn's checkpoints are consumed; reverting it again is a no-op.
+	if restored, err := svc.Revert(ctx, "s", "user-2"); err != nil || len(restored) != 0 {
+		t.Fatalf("second revert = %v, %v; want nothing restored", restored, err)
+	}
+
+	if _, err := svc.Revert(ctx, "s", "user-1"); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	assertContent(a, "v0")
+}
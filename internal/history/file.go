@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -43,6 +44,15 @@ type Service interface {
 	Update(ctx context.Context, file File) (File, error)
 	Delete(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
+	// Checkpoint snapshots path before messageID modifies it, so the change
+	// can later be undone with Revert.
+	Checkpoint(ctx context.Context, sessionID, messageID, path string) error
+	// Revert restores the files changed at or after messageID and returns
+	// the restored paths.
+	Revert(ctx context.Context, sessionID, messageID string) ([]string, error)
+	// Baseline returns the files changed at or after messageID as they were
+	// before, without restoring anything.
+	Baseline(ctx context.Context, sessionID, messageID string) ([]BaselineFile, error)
 }
 
 type service struct {
@@ -289,6 +299,17 @@ func (s *service) fromDBItem(item db.File) File {
 	}
 }
 
+// SortVersions orders versions of a file from oldest to newest. Versions
+// created within the same second are ordered by their version number.
+func SortVersions(files []File) {
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].CreatedAt != files[j].CreatedAt {
+			return files[i].CreatedAt < files[j].CreatedAt
+		}
+		return parseVersionNum(files[i].Version) < parseVersionNum(files[j].Version)
+	})
+}
+
 // parseVersionNum extracts the numeric part from a version string.
 // Returns -1 for "initial", the number N for "vN", or -2 if unparseable.
 func parseVersionNum(version string) int {
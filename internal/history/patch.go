@@ -0,0 +1,153 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// Change is the net effect recorded versions had on one file.
+type Change struct {
+	Path   string
+	Before string
+	After  string
+}
+
+// Turn starts at a prompt of the root session. Versions recorded from Start
+// (unix seconds) until the next turn's Start are attributed to it.
+type Turn struct {
+	Subject string
+	Start   int64
+}
+
+// Changes reduces file versions, in any order, to one change per path: from
+// the content of its earliest version to that of its latest. Paths that end
+// up unchanged are omitted; the result is sorted by path.
+func Changes(files []File) []Change {
+	sorted := append([]File(nil), files...)
+	SortVersions(sorted)
+	return netChanges(sorted, map[string]string{})
+}
+
+// netChanges computes the changes of sorted versions. base holds the content
+// each path had before them and is advanced to their end state; a path
+// missing from base starts at its first version, which is the pre-image the
+// edit tools record.
+func netChanges(sorted []File, base map[string]string) []Change {
+	before := make(map[string]string)
+	after := make(map[string]string)
+	for _, f := range sorted {
+		if _, ok := before[f.Path]; !ok {
+			content, known := base[f.Path]
+			if !known {
+				content = f.Content
+			}
+			before[f.Path] = content
+		}
+		after[f.Path] = f.Content
+	}
+
+	changes := make([]Change, 0, len(after))
+	for path, content := range after {
+		base[path] = content
+		if before[path] != content {
+			changes = append(changes, Change{Path: path, Before: before[path], After: content})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// WritePatch writes changes as one git-style unified diff that `git apply`
+// or `patch -p1` accept. Paths under workDir are made relative to it. A file
+// whose first version is empty was created by the session and is diffed
+// against /dev/null.
+func WritePatch(w io.Writer, changes []Change, workDir string) error {
+	for _, c := range changes {
+		name := patchPath(c.Path, workDir)
+		from := "a/" + name
+		var header strings.Builder
+		fmt.Fprintf(&header, "diff --git a/%s b/%s\n", name, name)
+		if c.Before == "" {
+			header.WriteString("new file mode 100644\n")
+			from = "/dev/null"
+		}
+		unified := udiff.Unified(from, "b/"+name, c.Before, c.After)
+		if unified == "" {
+			continue
+		}
+		if _, err := io.WriteString(w, header.String()+unified); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePatchSeries writes one patch per turn in the mbox format of
+// `git format-patch`, so the series can be replayed with `git am`. Turns
+// must be ordered by Start; versions recorded before the first turn count
+// towards it, and turns that changed nothing are skipped.
+func WritePatchSeries(w io.Writer, files []File, turns []Turn, workDir string) error {
+	if len(turns) == 0 {
+		return nil
+	}
+	sorted := append([]File(nil), files...)
+	SortVersions(sorted)
+
+	type patch struct {
+		turn    Turn
+		changes []Change
+	}
+	var patches []patch
+	base := make(map[string]string)
+	next := 0
+	for i, turn := range turns {
+		end := len(sorted)
+		if i+1 < len(turns) {
+			end = next
+			for end < len(sorted) && sorted[end].CreatedAt < turns[i+1].Start {
+				end++
+			}
+		}
+		if changes := netChanges(sorted[next:end], base); len(changes) > 0 {
+			patches = append(patches, patch{turn: turn, changes: changes})
+		}
+		next = end
+	}
+
+	for i, p := range patches {
+		subject, body, _ := strings.Cut(strings.TrimSpace(p.turn.Subject), "\n")
+		if subject == "" {
+			subject = "opencode session changes"
+		}
+		fmt.Fprintf(w, "From 0000000000000000000000000000000000000000 Mon Sep 17 00:00:00 2001\n")
+		fmt.Fprintf(w, "From: opencode <opencode@localhost>\n")
+		fmt.Fprintf(w, "Date: %s\n", time.Unix(p.turn.Start, 0).Format(time.RFC1123Z))
+		fmt.Fprintf(w, "Subject: [PATCH %d/%d] %s\n\n", i+1, len(patches), subject)
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(w, "%s\n\n", body)
+		}
+		fmt.Fprintf(w, "---\n")
+		if err := WritePatch(w, p.changes, workDir); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "-- \nopencode\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchPath(path, workDir string) string {
+	if workDir != "" {
+		if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
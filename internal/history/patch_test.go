@@ -0,0 +1,102 @@
+package history
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	files := []File{
+		{Path: "/w/b.go", Version: "v1", Content: "b1\n", CreatedAt: 20},
+		{Path: "/w/a.go", Version: InitialVersion, Content: "a0\n", CreatedAt: 10},
+		{Path: "/w/b.go", Version: InitialVersion, Content: "b0\n", CreatedAt: 10},
+		{Path: "/w/a.go", Version: "v1", Content: "a1\n", CreatedAt: 11},
+		{Path: "/w/a.go", Version: "v2", Content: "a0\n", CreatedAt: 12},
+	}
+	changes := Changes(files)
+	if len(changes) != 1 {
+		t.Fatalf("want only b.go changed, got %+v", changes)
+	}
+	if c := changes[0]; c.Path != "/w/b.go" || c.Before != "b0\n" || c.After != "b1\n" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+}
+
+func TestWritePatchAppliesWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []Change{
+		{Path: "/sandbox/main.go", Before: "package main\n\nfunc main() {}\n", After: "package main\n\nfunc main() { run() }\n"},
+		{Path: "/sandbox/pkg/run.go", Before: "", After: "package main\n\nfunc run() {}\n"},
+	}
+	var sb strings.Builder
+	if err := WritePatch(&sb, changes, "/sandbox"); err != nil {
+		t.Fatal(err)
+	}
+	patch := sb.String()
+	if !strings.Contains(patch, "diff --git a/pkg/run.go b/pkg/run.go\nnew file mode 100644\n--- /dev/null\n") {
+		t.Errorf("new file header missing:\n%s", patch)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "session.patch"), []byte(patch), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("apply", "session.patch")
+
+	got, err := os.ReadFile(filepath.Join(dir, "pkg", "run.go"))
+	if err != nil || string(got) != "package main\n\nfunc run() {}\n" {
+		t.Errorf("pkg/run.go after apply: %q, %v", got, err)
+	}
+	got, _ = os.ReadFile(filepath.Join(dir, "main.go"))
+	if !strings.Contains(string(got), "run()") {
+		t.Errorf("main.go not patched: %q", got)
+	}
+}
+
+func TestWritePatchSeriesSplitsByTurn(t *testing.T) {
+	files := []File{
+		{Path: "/w/a.txt", Version: InitialVersion, Content: "one\n", CreatedAt: 100},
+		{Path: "/w/a.txt", Version: "v1", Content: "two\n", CreatedAt: 101},
+		{Path: "/w/a.txt", Version: "v2", Content: "three\n", CreatedAt: 205},
+	}
+	turns := []Turn{
+		{Subject: "first change\n\nwith details", Start: 100},
+		{Subject: "just a question", Start: 150},
+		{Subject: "second change", Start: 200},
+	}
+	var sb strings.Builder
+	if err := WritePatchSeries(&sb, files, turns, "/w"); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"Subject: [PATCH 1/2] first change\n\nwith details\n\n---\n",
+		"-one\n+two\n",
+		"Subject: [PATCH 2/2] second change\n",
+		"-two\n+three\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("series missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "just a question") {
+		t.Errorf("turn without changes produced a patch:\n%s", out)
+	}
+}
@@ -15,14 +15,37 @@ type MatcherGroup struct {
 
 // HookEntry is one executable hook within a MatcherGroup.
 //
-// v1 supports `type: "command"` only. The runner ignores entries with any
-// other type and logs a WARN — this is intentional so a `.opencode.json`
-// that also targets Claude Code's other hook types (http, mcp_tool,
-// prompt, agent) loads cleanly and silently skips the unsupported ones.
+// Two types are supported: `command` (the default when Type is empty)
+// spawns a subprocess, `http` POSTs the event JSON to URL. The runner
+// ignores entries with any other type and logs a WARN — this is
+// intentional so a `.opencode.json` that also targets Claude Code's other
+// hook types (mcp_tool, prompt, agent) loads cleanly and silently skips
+// the unsupported ones.
 type HookEntry struct {
 	Type    string   `json:"type"`
-	Command string   `json:"command"`
+	Command string   `json:"command,omitempty"`
 	Args    []string `json:"args,omitempty"`
 	Timeout int      `json:"timeout,omitempty"` // seconds; 0 means use default
 	Shell   string   `json:"shell,omitempty"`   // "sh" | "bash"; empty falls back to runner's default
+
+	// URL and Headers configure `type: "http"` entries. Header values
+	// expand `$VAR` / `${VAR}` from the environment so tokens stay out
+	// of the config file.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Hook types accepted in HookEntry.Type.
+const (
+	TypeCommand = "command"
+	TypeHTTP    = "http"
+)
+
+// target is the human-readable identity of an entry used in log lines:
+// the command for subprocess hooks, the URL for webhooks.
+func (e HookEntry) target() string {
+	if e.Type == TypeHTTP {
+		return e.URL
+	}
+	return e.Command
 }
@@ -1,18 +1,22 @@
 // Package hooks implements a Claude-Code-compatible hook runtime: external
-// processes are invoked at agent-lifecycle moments (currently PreToolUse and
-// PostToolUse), receive event JSON on stdin, and return decisions on stdout.
+// processes are invoked at agent-lifecycle moments (PreToolUse, PostToolUse,
+// SessionStart and SessionEnd), receive event JSON on stdin, and return
+// decisions on stdout. `http` hooks receive the same JSON as a POST body and
+// answer in the response body.
 //
-// The package is intentionally subprocess-only — there is no embedded JS
-// engine, no plugin manifest format, no language-specific authoring SDK. A
-// hook is any POSIX executable that speaks the JSON contract documented in
+// There is no embedded JS engine, no plugin manifest format, no
+// language-specific authoring SDK. A hook is any POSIX executable (or HTTP
+// endpoint) that speaks the JSON contract documented in
 // openspec/specs/hook-runtime/spec.md and at code.claude.com/docs/en/hooks.
 package hooks
 
 // Canonical event names. Strings match Claude Code 1:1 so plugin authors
 // can paste their settings.json blocks verbatim.
 const (
-	EventPreToolUse  = "PreToolUse"
-	EventPostToolUse = "PostToolUse"
+	EventPreToolUse   = "PreToolUse"
+	EventPostToolUse  = "PostToolUse"
+	EventSessionStart = "SessionStart"
+	EventSessionEnd   = "SessionEnd"
 )
 
 // PreToolUseInput is the JSON document written to a PreToolUse hook's stdin.
@@ -35,6 +39,22 @@ type PostToolUseInput struct {
 	ToolOutput    string         `json:"tool_output"`
 }
 
+// SessionStartInput is the JSON document sent to a SessionStart hook.
+type SessionStartInput struct {
+	SessionID     string `json:"session_id"`
+	CWD           string `json:"cwd"`
+	HookEventName string `json:"hook_event_name"`
+	Source        string `json:"source"`
+}
+
+// SessionEndInput is the JSON document sent to a SessionEnd hook.
+type SessionEndInput struct {
+	SessionID     string `json:"session_id"`
+	CWD           string `json:"cwd"`
+	HookEventName string `json:"hook_event_name"`
+	Reason        string `json:"reason"`
+}
+
 // HookOutput is the parsed JSON document a hook writes to stdout on exit 0.
 // Field names are camelCase per Claude Code's documented schema. All fields
 // are optional — a hook that wants no decision returns `{}` (or anything that
@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// httpClient is shared by every webhook call. Per-hook timeouts are
+// applied through the request context, not the client, so one slow
+// endpoint can't shorten another's budget.
+var httpClient = &http.Client{}
+
+// runHTTPHook POSTs payload to entry.URL and maps the response onto the
+// same Result the subprocess runner produces, so the registry applies
+// one set of decision rules to both hook types:
+//
+//   - 2xx → exit 0; the body is parsed like a command hook's stdout.
+//   - any other status → a non-blocking error (exit 1, body as stderr).
+//     A webhook blocks a call by answering 200 with
+//     `permissionDecision: "deny"`; there is no exit-2 analogue.
+//   - connection failures and timeouts set Err / Timeout.
+//
+// The response body is capped at the stdout limit.
+func runHTTPHook(ctx context.Context, entry HookEntry, payload []byte, projectRoot string) (res Result) {
+	start := time.Now()
+	defer func() {
+		res.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			res.Err = errFromRecover(r)
+		}
+	}()
+
+	if entry.URL == "" {
+		res.Err = errors.New("http hook has no url")
+		return
+	}
+	timeout := DefaultTimeout
+	if entry.Timeout > 0 {
+		timeout = time.Duration(entry.Timeout) * time.Second
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, entry.URL, bytes.NewReader(payload))
+	if err != nil {
+		res.Err = err
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Opencode-Project-Dir", projectRoot)
+	for k, v := range entry.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if errors.Is(reqCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			res.Timeout = true
+			res.ExitCode = -1
+			res.Err = errTimeout
+			return
+		}
+		res.Err = err
+		return
+	}
+	defer resp.Body.Close()
+
+	body := &cappedBuffer{cap: maxStdoutBytes}
+	if _, err := io.Copy(body, resp.Body); err != nil {
+		res.Err = fmt.Errorf("reading response: %w", err)
+		return
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		res.ExitCode = 1
+		stderr := &cappedBuffer{cap: maxStderrBytes}
+		fmt.Fprintf(stderr, "%s: ", resp.Status)
+		_, _ = stderr.Write(body.Bytes())
+		res.Stderr = stderr.Bytes()
+		res.TruncatedStderr = stderr.truncated
+		return
+	}
+	res.Stdout = body.Bytes()
+	res.TruncatedStdout = body.truncated
+	return
+}
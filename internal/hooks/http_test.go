@@ -0,0 +1,112 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestRunPreTool_HTTPHookDenies verifies a webhook receives the event
+// JSON plus its configured headers and can deny the call.
+func TestRunPreTool_HTTPHookDenies(t *testing.T) {
+	t.Setenv("HOOK_TOKEN", "secret")
+	var got PreToolUseInput
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = io.WriteString(w, `{"hookSpecificOutput":{"permissionDecision":"deny","permissionDecisionReason":"no rm"}}`)
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry(hooksGetter(map[string][]MatcherGroup{
+		"PreToolUse": {{Matcher: "bash", Hooks: []HookEntry{{
+			Type:    TypeHTTP,
+			URL:     srv.URL,
+			Headers: map[string]string{"Authorization": "Bearer $HOOK_TOKEN"},
+		}}}},
+	}), t.TempDir())
+	dec := reg.RunPreTool(context.Background(), "sess", "/work", "bash", map[string]any{"command": "rm -rf /"})
+	if !dec.Block || dec.BlockReason != "no rm" {
+		t.Fatalf("decision = %+v, want block with reason %q", dec, "no rm")
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want expanded token", auth)
+	}
+	if got.HookEventName != EventPreToolUse || got.ToolName != "bash" || got.ToolInput["command"] != "rm -rf /" {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+// TestRunPreTool_HTTPErrorStatusIsNonBlocking verifies a failing
+// endpoint doesn't stop the tool call.
+func TestRunPreTool_HTTPErrorStatusIsNonBlocking(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"hookSpecificOutput":{"permissionDecision":"deny"}}`, http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry(hooksGetter(map[string][]MatcherGroup{
+		"PreToolUse": {{Hooks: []HookEntry{{Type: TypeHTTP, URL: srv.URL}}}},
+	}), t.TempDir())
+	dec := reg.RunPreTool(context.Background(), "sess", "/work", "bash", map[string]any{})
+	if dec.Block {
+		t.Fatalf("non-2xx response must not block; got %+v", dec)
+	}
+}
+
+// TestRunPostTool_HTTPHookReplacesOutput verifies the response body is
+// applied like a command hook's stdout.
+func TestRunPostTool_HTTPHookReplacesOutput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"hookSpecificOutput":{"updatedToolOutput":"short"}}`)
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry(hooksGetter(map[string][]MatcherGroup{
+		"PostToolUse": {{Hooks: []HookEntry{{Type: TypeHTTP, URL: srv.URL}}}},
+	}), t.TempDir())
+	dec := reg.RunPostTool(context.Background(), "sess", "/work", "bash", nil, "long output")
+	if dec.UpdatedOutput == nil || *dec.UpdatedOutput != "short" {
+		t.Fatalf("UpdatedOutput = %v, want %q", dec.UpdatedOutput, "short")
+	}
+}
+
+// TestRunSessionEvents_MatchOnSourceAndReason verifies lifecycle events
+// reach their hooks and matchers compare against the source / reason.
+func TestRunSessionEvents_MatchOnSourceAndReason(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		events = append(events, body)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	entry := []HookEntry{{Type: TypeHTTP, URL: srv.URL}}
+	reg := NewRegistry(hooksGetter(map[string][]MatcherGroup{
+		"SessionStart": {{Matcher: "new", Hooks: entry}},
+		"SessionEnd":   {{Matcher: "delete", Hooks: entry}},
+	}), t.TempDir())
+	ctx := context.Background()
+	reg.RunSessionStart(ctx, "s1", "/work", "new")
+	reg.RunSessionEnd(ctx, "s1", "/work", "exit") // matcher doesn't match
+	reg.RunSessionEnd(ctx, "s1", "/work", "delete")
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %v", len(events), events)
+	}
+	if events[0]["hook_event_name"] != EventSessionStart || events[0]["source"] != "new" || events[0]["session_id"] != "s1" {
+		t.Errorf("SessionStart payload = %v", events[0])
+	}
+	if events[1]["hook_event_name"] != EventSessionEnd || events[1]["reason"] != "delete" {
+		t.Errorf("SessionEnd payload = %v", events[1])
+	}
+}
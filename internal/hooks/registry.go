@@ -222,23 +222,54 @@ func (r *Registry) RunPostTool(ctx context.Context, sessionID, cwd, toolName str
 	return decision
 }
 
-// dispatch is the PreToolUse subprocess wrapper. Returns the parsed
+// RunSessionStart fires the SessionStart event when a session begins.
+// source says how it began ("new" today) and is what matchers compare
+// against. SessionStart is a notification: hooks can't veto it, exit
+// codes and stdout are logged only.
+func (r *Registry) RunSessionStart(ctx context.Context, sessionID, cwd, source string) {
+	r.runLifecycle(ctx, EventSessionStart, source, SessionStartInput{
+		SessionID:     sessionID,
+		CWD:           cwd,
+		HookEventName: EventSessionStart,
+		Source:        source,
+	})
+}
+
+// RunSessionEnd fires the SessionEnd event when a session is deleted
+// ("delete") or the process that started it exits ("exit"). Matchers
+// compare against the reason; like SessionStart, results are logged only.
+func (r *Registry) RunSessionEnd(ctx context.Context, sessionID, cwd, reason string) {
+	r.runLifecycle(ctx, EventSessionEnd, reason, SessionEndInput{
+		SessionID:     sessionID,
+		CWD:           cwd,
+		HookEventName: EventSessionEnd,
+		Reason:        reason,
+	})
+}
+
+// runLifecycle runs every hook matching matchValue for a notification
+// event. Decisions are ignored — applyExit still logs failures, blocks
+// and malformed output the same way it does for tool events.
+func (r *Registry) runLifecycle(ctx context.Context, event, matchValue string, payload any) {
+	groups := r.loadGroups(event)
+	if len(groups) == 0 {
+		return
+	}
+	for _, entry := range matchEntries(groups, matchValue) {
+		_, _ = r.dispatch(ctx, entry, payload, event, matchValue)
+	}
+}
+
+// dispatch runs one PreToolUse (or lifecycle-event) hook. Returns the parsed
 // HookOutput (may be nil if no JSON was emitted but exit 0), and a bool
 // that's false when the hook fired but its result was non-actionable
 // (errored, timed out, etc — already logged). The caller passes the
 // returned HookOutput through its precedence rules.
-func (r *Registry) dispatch(ctx context.Context, entry HookEntry, payload PreToolUseInput, event, toolName string) (*HookOutput, bool) {
-	if entry.Type != "" && entry.Type != "command" {
-		logging.Warn("hook type not supported in v1; skipping",
-			"event", event, "tool", toolName, "type", entry.Type)
-		return nil, false
-	}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		logging.Warn("hook payload marshal failed", "event", event, "tool", toolName, "error", err)
+func (r *Registry) dispatch(ctx context.Context, entry HookEntry, payload any, event, toolName string) (*HookOutput, bool) {
+	res, ok := r.run(ctx, entry, payload, event, toolName)
+	if !ok {
 		return nil, false
 	}
-	res := runHook(ctx, entry, data, r.projectRoot)
 	return r.applyExit(event, toolName, entry, res, nil)
 }
 
@@ -246,18 +277,31 @@ func (r *Registry) dispatch(ctx context.Context, entry HookEntry, payload PreToo
 // semantics directly to the decision (PostToolUse block = replace output
 // with stderr, distinct from PreToolUse block = refuse to run tool).
 func (r *Registry) dispatchPost(ctx context.Context, entry HookEntry, payload PostToolUseInput, toolName string, decision *PostToolDecision) (*HookOutput, bool) {
-	if entry.Type != "" && entry.Type != "command" {
-		logging.Warn("hook type not supported in v1; skipping",
-			"event", EventPostToolUse, "tool", toolName, "type", entry.Type)
+	res, ok := r.run(ctx, entry, payload, EventPostToolUse, toolName)
+	if !ok {
 		return nil, false
 	}
+	return r.applyExit(EventPostToolUse, toolName, entry, res, decision)
+}
+
+// run marshals the event payload and hands it to the runner for the
+// entry's type. Returns false (already logged) when the type is
+// unsupported or the payload can't be encoded.
+func (r *Registry) run(ctx context.Context, entry HookEntry, payload any, event, toolName string) (Result, bool) {
+	if entry.Type != "" && entry.Type != TypeCommand && entry.Type != TypeHTTP {
+		logging.Warn("hook type not supported; skipping",
+			"event", event, "tool", toolName, "type", entry.Type)
+		return Result{}, false
+	}
 	data, err := json.Marshal(payload)
 	if err != nil {
-		logging.Warn("hook payload marshal failed", "event", EventPostToolUse, "tool", toolName, "error", err)
-		return nil, false
+		logging.Warn("hook payload marshal failed", "event", event, "tool", toolName, "error", err)
+		return Result{}, false
 	}
-	res := runHook(ctx, entry, data, r.projectRoot)
-	return r.applyExit(EventPostToolUse, toolName, entry, res, decision)
+	if entry.Type == TypeHTTP {
+		return runHTTPHook(ctx, entry, data, r.projectRoot), true
+	}
+	return runHook(ctx, entry, data, r.projectRoot), true
 }
 
 // applyExit interprets the runner Result against Claude Code's documented
@@ -269,7 +313,7 @@ func (r *Registry) dispatchPost(ctx context.Context, entry HookEntry, payload Po
 // BlockReason on it (since PreToolUse's "block tool" semantic doesn't
 // apply — instead we replace output with stderr).
 func (r *Registry) applyExit(event, toolName string, entry HookEntry, res Result, postDecision *PostToolDecision) (*HookOutput, bool) {
-	cmd := entry.Command
+	cmd := entry.target()
 	switch {
 	case res.Err != nil && !errors.Is(res.Err, errTimeout):
 		logging.Warn("hook spawn or wait error", "event", event, "tool", toolName, "command", cmd,
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Claude Code keeps one JSONL file per session under
+// ~/.claude/projects/<project dir with every non-alphanumeric rune
+// replaced by '-'>/<session id>.jsonl. CLAUDE_CONFIG_DIR moves the
+// ~/.claude part.
+
+// claudeTools maps Claude Code tool names onto the opencode tools taking
+// the same parameters, so imported calls render like native ones. Other
+// names are kept as they are.
+var claudeTools = map[string]string{
+	"Bash":         "bash",
+	"Read":         "read",
+	"Write":        "write",
+	"Edit":         "edit",
+	"MultiEdit":    "multiedit",
+	"Glob":         "glob",
+	"Grep":         "grep",
+	"LS":           "ls",
+	"WebFetch":     "webfetch",
+	"WebSearch":    "websearch",
+	"TodoWrite":    "todowrite",
+	"NotebookRead": "notebook_read",
+	"NotebookEdit": "notebook_edit",
+	"Task":         "task",
+}
+
+var claudeDirEscape = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+type claudeRecord struct {
+	Type        string    `json:"type"`
+	SessionID   string    `json:"sessionId"`
+	Timestamp   time.Time `json:"timestamp"`
+	IsSidechain bool      `json:"isSidechain"`
+	IsMeta      bool      `json:"isMeta"`
+	Summary     string    `json:"summary"`
+	Message     struct {
+		ID      string          `json:"id"`
+		Role    string          `json:"role"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+type claudeBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	Thinking  string          `json:"thinking"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+func claudeHome() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude")
+}
+
+func discoverClaude(projectDir string) ([]string, error) {
+	home := claudeHome()
+	if home == "" {
+		return nil, nil
+	}
+	dir := filepath.Join(home, "projects", claudeDirEscape.ReplaceAllString(filepath.Clean(projectDir), "-"))
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sortByModTime(paths)
+	return paths, nil
+}
+
+func parseClaude(r io.Reader) (*builder, error) {
+	b := newBuilder(SourceClaude)
+	err := readLines(r, func(line []byte) {
+		var rec claudeRecord
+		if json.Unmarshal(line, &rec) != nil {
+			return
+		}
+		if rec.Type == "summary" {
+			// Claude Code writes its generated titles as summary records;
+			// the latest one wins.
+			if rec.Summary != "" {
+				b.title = rec.Summary
+			}
+			return
+		}
+		// Sidechains are subagent conversations interleaved with the main
+		// one; meta records are injected context, not user input.
+		if rec.IsSidechain || rec.IsMeta || (rec.Type != "user" && rec.Type != "assistant") {
+			return
+		}
+		if b.sourceID == "" {
+			b.sourceID = rec.SessionID
+		}
+
+		var text string
+		if json.Unmarshal(rec.Message.Content, &text) == nil {
+			if rec.Type == "user" {
+				b.userText(text, rec.Timestamp)
+			} else {
+				b.assistantText(rec.Message.ID, rec.Message.Model, text, rec.Timestamp)
+			}
+			return
+		}
+		var blocks []claudeBlock
+		if json.Unmarshal(rec.Message.Content, &blocks) != nil {
+			return
+		}
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				if rec.Type == "user" {
+					b.userText(block.Text, rec.Timestamp)
+				} else {
+					b.assistantText(rec.Message.ID, rec.Message.Model, block.Text, rec.Timestamp)
+				}
+			case "thinking":
+				b.reasoning(rec.Message.ID, rec.Message.Model, block.Thinking, rec.Timestamp)
+			case "tool_use":
+				name := block.Name
+				if mapped, ok := claudeTools[name]; ok {
+					name = mapped
+				}
+				input := string(block.Input)
+				if input == "" {
+					input = "{}"
+				}
+				b.toolCall(rec.Message.ID, rec.Message.Model, block.ID, name, input, rec.Timestamp)
+			case "tool_result":
+				b.toolResult(block.ToolUseID, textOf(block.Content), block.IsError, rec.Timestamp)
+			}
+		}
+	})
+	return b, err
+}
+
+// sortByModTime orders paths oldest first; files that can't be stat'ed
+// keep their relative order at the front.
+func sortByModTime(paths []string) {
+	mod := make(map[string]time.Time, len(paths))
+	for _, p := range paths {
+		if st, err := os.Stat(p); err == nil {
+			mod[p] = st.ModTime()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		if !mod[paths[i]].Equal(mod[paths[j]]) {
+			return mod[paths[i]].Before(mod[paths[j]])
+		}
+		return strings.Compare(paths[i], paths[j]) < 0
+	})
+}
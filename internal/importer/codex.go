@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Codex CLI writes one rollout file per session under
+// ~/.codex/sessions/YYYY/MM/DD/rollout-<timestamp>-<id>.jsonl. CODEX_HOME
+// moves the ~/.codex part. Current rollouts wrap every record as
+// {"timestamp", "type", "payload"} and open with a session_meta record
+// naming the working directory; older ones hold bare response items.
+
+// codexUserPreambles mark user messages Codex injects itself (project
+// instructions, environment description) rather than prompts.
+var codexUserPreambles = []string{"<environment_context>", "<user_instructions>", "# AGENTS.md instructions"}
+
+type codexRecord struct {
+	Type      string          `json:"type"`
+	Timestamp string          `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+type codexItem struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	CWD     string `json:"cwd"`
+	Role    string `json:"role"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Summary []struct {
+		Text string `json:"text"`
+	} `json:"summary"`
+	Name      string          `json:"name"`
+	Arguments string          `json:"arguments"`
+	Input     string          `json:"input"`
+	CallID    string          `json:"call_id"`
+	Output    json.RawMessage `json:"output"`
+}
+
+func codexHome() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".codex")
+}
+
+func discoverCodex(projectDir string) ([]string, error) {
+	home := codexHome()
+	if home == "" {
+		return nil, nil
+	}
+	root := filepath.Join(home, "sessions")
+	want := filepath.Clean(projectDir)
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(d.Name(), "rollout-") || filepath.Ext(path) != ".jsonl" {
+			return nil
+		}
+		if cwd := codexSessionDir(path); cwd != "" && filepath.Clean(cwd) == want {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortByModTime(paths)
+	return paths, nil
+}
+
+// codexSessionDir reads the working directory from a rollout's
+// session_meta record. Rollouts that predate it yield "".
+func codexSessionDir(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, err := bufio.NewReaderSize(f, 64<<10).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return ""
+	}
+	var rec codexRecord
+	if json.Unmarshal(line, &rec) != nil || rec.Type != "session_meta" {
+		return ""
+	}
+	var meta codexItem
+	if json.Unmarshal(rec.Payload, &meta) != nil {
+		return ""
+	}
+	return meta.CWD
+}
+
+func parseCodex(r io.Reader) (*builder, error) {
+	b := newBuilder(SourceCodex)
+	var at time.Time
+	err := readLines(r, func(line []byte) {
+		var rec codexRecord
+		if json.Unmarshal(line, &rec) != nil {
+			return
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, rec.Timestamp); err == nil {
+			at = ts
+		}
+		raw := rec.Payload
+		switch {
+		case len(raw) == 0:
+			// Older rollouts: the line itself is the item, and the first
+			// line is a header carrying the session id.
+			raw = line
+		case rec.Type != "session_meta" && rec.Type != "response_item":
+			return
+		}
+		var item codexItem
+		if json.Unmarshal(raw, &item) != nil {
+			return
+		}
+		if rec.Type == "session_meta" || (item.Type == "" && item.ID != "") {
+			if b.sourceID == "" {
+				b.sourceID = item.ID
+			}
+			return
+		}
+
+		switch item.Type {
+		case "message":
+			var texts []string
+			for _, c := range item.Content {
+				if c.Text != "" {
+					texts = append(texts, c.Text)
+				}
+			}
+			text := strings.Join(texts, "\n")
+			switch item.Role {
+			case "user":
+				if !isCodexPreamble(text) {
+					b.userText(text, at)
+				}
+			case "assistant":
+				b.assistantText("", "", text, at)
+			}
+		case "reasoning":
+			var texts []string
+			for _, s := range item.Summary {
+				texts = append(texts, s.Text)
+			}
+			b.reasoning("", "", strings.Join(texts, "\n\n"), at)
+		case "function_call":
+			name, input := codexToolCall(item.Name, item.Arguments)
+			b.toolCall("", "", item.CallID, name, input, at)
+		case "custom_tool_call":
+			name, input := item.Name, item.Input
+			if name == "apply_patch" {
+				// opencode's patch tool takes the same envelope format.
+				data, _ := json.Marshal(map[string]string{"patch_text": item.Input})
+				name, input = "patch", string(data)
+			} else {
+				data, _ := json.Marshal(map[string]string{"input": item.Input})
+				input = string(data)
+			}
+			b.toolCall("", "", item.CallID, name, input, at)
+		case "function_call_output", "custom_tool_call_output":
+			content, isError := codexToolOutput(item.Output)
+			b.toolResult(item.CallID, content, isError, at)
+		}
+	})
+	return b, err
+}
+
+func isCodexPreamble(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, prefix := range codexUserPreambles {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// codexToolCall maps Codex's shell tools onto opencode's bash tool. The
+// command arrives either as a string or as an argv, usually
+// ["bash", "-lc", "<script>"].
+func codexToolCall(name, arguments string) (string, string) {
+	if arguments == "" {
+		arguments = "{}"
+	}
+	if name != "shell" && name != "shell_command" && name != "" {
+		return name, arguments
+	}
+	var args struct {
+		Command json.RawMessage `json:"command"`
+		Workdir string          `json:"workdir"`
+	}
+	if json.Unmarshal([]byte(arguments), &args) != nil {
+		return "bash", arguments
+	}
+	var command string
+	if json.Unmarshal(args.Command, &command) != nil {
+		var argv []string
+		if json.Unmarshal(args.Command, &argv) != nil {
+			return "bash", arguments
+		}
+		if len(argv) == 3 && (argv[1] == "-lc" || argv[1] == "-c") {
+			command = argv[2]
+		} else {
+			command = strings.Join(argv, " ")
+		}
+	}
+	data, _ := json.Marshal(map[string]string{"command": command, "workdir": args.Workdir})
+	return "bash", string(data)
+}
+
+// codexToolOutput unwraps a tool output. Shell outputs are often a JSON
+// string of {"output", "metadata": {"exit_code"}}; a non-zero exit code
+// marks the result as an error.
+func codexToolOutput(raw json.RawMessage) (string, bool) {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		s = textOf(raw)
+	}
+	var wrapped struct {
+		Output   *string `json:"output"`
+		Metadata struct {
+			ExitCode int `json:"exit_code"`
+		} `json:"metadata"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(s), "{") && json.Unmarshal([]byte(s), &wrapped) == nil && wrapped.Output != nil {
+		return *wrapped.Output, wrapped.Metadata.ExitCode != 0
+	}
+	return s, false
+}
@@ -0,0 +1,339 @@
+// Package importer converts session transcripts written by other coding
+// agents into opencode session archives, so history survives a switch of
+// tools. Conversion is best-effort: user prompts, assistant text,
+// reasoning, tool calls and tool results are carried over; anything the
+// source format records that opencode has no place for is dropped.
+//
+// The resulting session.Archive is imported like any exported archive.
+// Session and message IDs are derived from the source transcript's ID, so
+// importing the same transcript twice is refused as a duplicate instead
+// of creating a copy.
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// Source names a supported transcript format.
+type Source string
+
+const (
+	SourceClaude Source = "claude"
+	SourceCodex  Source = "codex"
+)
+
+// Sources lists the supported formats in the order they are documented.
+var Sources = []Source{SourceClaude, SourceCodex}
+
+// ErrEmptyTranscript is returned by Convert for transcripts without a
+// single message worth importing.
+var ErrEmptyTranscript = errors.New("transcript has no messages")
+
+// maxLineBytes bounds one JSONL record. Tool results with whole files in
+// them make lines far longer than bufio's default.
+const maxLineBytes = 64 << 20
+
+// titleMaxLen caps titles taken from the first prompt.
+const titleMaxLen = 80
+
+// idNamespace seeds the deterministic IDs of imported rows.
+var idNamespace = uuid.MustParse("0b7f4b6e-51a3-4c56-9a0e-8a3f2f1c7d2e")
+
+// ParseSource validates a --from value.
+func ParseSource(name string) (Source, error) {
+	for _, s := range Sources {
+		if string(s) == strings.ToLower(name) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported source %q (want claude or codex)", name)
+}
+
+// Discover returns the transcripts source has stored for projectDir,
+// oldest first. A source that was never used yields no transcripts.
+func Discover(source Source, projectDir string) ([]string, error) {
+	switch source {
+	case SourceClaude:
+		return discoverClaude(projectDir)
+	case SourceCodex:
+		return discoverCodex(projectDir)
+	}
+	return nil, fmt.Errorf("unsupported source %q", source)
+}
+
+// Convert reads the transcript at path and returns it as an archive with
+// a single root session.
+func Convert(source Source, path string) (session.Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return session.Archive{}, err
+	}
+	defer f.Close()
+
+	var b *builder
+	switch source {
+	case SourceClaude:
+		b, err = parseClaude(f)
+	case SourceCodex:
+		b, err = parseCodex(f)
+	default:
+		return session.Archive{}, fmt.Errorf("unsupported source %q", source)
+	}
+	if err != nil {
+		return session.Archive{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return b.archive()
+}
+
+// readLines calls fn with every non-empty line of a JSONL stream.
+// Malformed records are the caller's to skip; only I/O errors stop the
+// scan.
+func readLines(r io.Reader, fn func(line []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<20), maxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
+
+// builder accumulates a transcript in opencode's message shape: user
+// prompts, assistant messages holding text, reasoning and tool calls,
+// and tool messages holding the results of the preceding calls.
+type builder struct {
+	source   Source
+	sourceID string
+	title    string
+	messages []*pendingMessage
+	// toolNames maps call IDs to the (mapped) tool name, for results
+	// that only carry the ID.
+	toolNames map[string]string
+}
+
+type pendingMessage struct {
+	role     message.MessageRole
+	parts    []message.ContentPart
+	model    string
+	created  time.Time
+	finished time.Time
+	// key groups streamed records that belong to one provider message.
+	key string
+}
+
+func newBuilder(source Source) *builder {
+	return &builder{source: source, toolNames: map[string]string{}}
+}
+
+func (b *builder) last() *pendingMessage {
+	if len(b.messages) == 0 {
+		return nil
+	}
+	return b.messages[len(b.messages)-1]
+}
+
+func (b *builder) userText(text string, at time.Time) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	b.messages = append(b.messages, &pendingMessage{
+		role:    message.User,
+		parts:   []message.ContentPart{message.TextContent{Text: text}},
+		created: at,
+	})
+}
+
+// assistant returns the assistant message to append to: the open one when
+// key matches (or is empty and the last message is an assistant), a new
+// one otherwise.
+func (b *builder) assistant(key, model string, at time.Time) *pendingMessage {
+	if m := b.last(); m != nil && m.role == message.Assistant && (key == "" || m.key == key) {
+		m.finished = at
+		return m
+	}
+	m := &pendingMessage{role: message.Assistant, model: model, created: at, finished: at, key: key}
+	b.messages = append(b.messages, m)
+	return m
+}
+
+func (b *builder) assistantText(key, model, text string, at time.Time) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	m := b.assistant(key, model, at)
+	m.parts = append(m.parts, message.TextContent{Text: text})
+}
+
+func (b *builder) reasoning(key, model, text string, at time.Time) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	m := b.assistant(key, model, at)
+	m.parts = append(m.parts, message.ReasoningContent{Thinking: text})
+}
+
+func (b *builder) toolCall(key, model, id, name, input string, at time.Time) {
+	m := b.assistant(key, model, at)
+	b.toolNames[id] = name
+	m.parts = append(m.parts, message.ToolCall{
+		ID:       id,
+		Name:     name,
+		Input:    input,
+		Type:     "tool_use",
+		Finished: true,
+	})
+}
+
+func (b *builder) toolResult(callID, content string, isError bool, at time.Time) {
+	m := b.last()
+	if m == nil || m.role != message.Tool {
+		m = &pendingMessage{role: message.Tool, created: at}
+		b.messages = append(b.messages, m)
+	}
+	m.parts = append(m.parts, message.ToolResult{
+		Type:       message.ToolResultTypeText,
+		ToolCallID: callID,
+		Name:       b.toolNames[callID],
+		Content:    content,
+		IsError:    isError,
+	})
+}
+
+// archive turns the accumulated messages into a one-session archive.
+func (b *builder) archive() (session.Archive, error) {
+	if len(b.messages) == 0 {
+		return session.Archive{}, ErrEmptyTranscript
+	}
+	if b.sourceID == "" {
+		return session.Archive{}, errors.New("transcript has no session id")
+	}
+
+	sessionID := uuid.NewSHA1(idNamespace, []byte(string(b.source)+":"+b.sourceID)).String()
+	title := b.title
+	if title == "" {
+		title = b.firstPrompt()
+	}
+	if title == "" {
+		title = "Imported " + string(b.source) + " session"
+	}
+
+	first, last := b.messages[0].created, b.messages[0].created
+	archive := session.Archive{
+		Version:       session.ArchiveVersion,
+		ExportedAt:    time.Now().Unix(),
+		RootSessionID: sessionID,
+	}
+	for i, m := range b.messages {
+		if m.role == message.Assistant {
+			reason := message.FinishReasonEndTurn
+			for _, p := range m.parts {
+				if _, ok := p.(message.ToolCall); ok {
+					reason = message.FinishReasonToolUse
+				}
+			}
+			m.parts = append(m.parts, message.Finish{Reason: reason, Time: m.finished.Unix()})
+		}
+		parts, err := message.MarshalParts(m.parts)
+		if err != nil {
+			return session.Archive{}, err
+		}
+		seq := int64(i + 1)
+		am := session.ArchiveMessage{
+			ID:        uuid.NewSHA1(idNamespace, []byte(fmt.Sprintf("%s:%d", sessionID, seq))).String(),
+			SessionID: sessionID,
+			Role:      string(m.role),
+			Parts:     parts,
+			Model:     m.model,
+			Seq:       seq,
+			CreatedAt: m.created.Unix(),
+			UpdatedAt: later(m.created, m.finished).Unix(),
+		}
+		if m.role == message.Assistant {
+			am.FinishedAt = m.finished.Unix()
+		}
+		archive.Messages = append(archive.Messages, am)
+		if m.created.After(last) {
+			last = m.created
+		}
+		if m.finished.After(last) {
+			last = m.finished
+		}
+	}
+	archive.Sessions = []session.ArchiveSession{{
+		ID:        sessionID,
+		Title:     title,
+		CreatedAt: first.Unix(),
+		UpdatedAt: last.Unix(),
+	}}
+	return archive, nil
+}
+
+func (b *builder) firstPrompt() string {
+	for _, m := range b.messages {
+		if m.role != message.User {
+			continue
+		}
+		for _, p := range m.parts {
+			if t, ok := p.(message.TextContent); ok {
+				line, _, _ := strings.Cut(strings.TrimSpace(t.Text), "\n")
+				if r := []rune(line); len(r) > titleMaxLen {
+					line = string(r[:titleMaxLen-1]) + "…"
+				}
+				return line
+			}
+		}
+	}
+	return ""
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// textOf flattens a content value that is either a string or a list of
+// typed blocks into plain text. Non-text blocks become a placeholder.
+func textOf(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &blocks) != nil {
+		return string(raw)
+	}
+	var parts []string
+	for _, block := range blocks {
+		switch {
+		case block.Text != "":
+			parts = append(parts, block.Text)
+		case block.Type != "":
+			parts = append(parts, "["+block.Type+"]")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,221 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+const claudeTranscript = `{"type":"summary","summary":"Fix the flaky test","leafUuid":"x"}
+{"type":"user","sessionId":"c1","timestamp":"2026-10-01T10:00:00Z","isMeta":true,"message":{"role":"user","content":"Caveat: injected"}}
+{"type":"user","sessionId":"c1","timestamp":"2026-10-01T10:00:01Z","message":{"role":"user","content":"why does TestFoo fail?"}}
+{"type":"assistant","sessionId":"c1","timestamp":"2026-10-01T10:00:02Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet","content":[{"type":"thinking","thinking":"look at it","signature":"sig"}]}}
+{"type":"assistant","sessionId":"c1","timestamp":"2026-10-01T10:00:03Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet","content":[{"type":"tool_use","id":"toolu_1","name":"Read","input":{"file_path":"/repo/foo_test.go"}}]}}
+{"type":"user","sessionId":"c1","timestamp":"2026-10-01T10:00:04Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":[{"type":"text","text":"package foo"}]}]}}
+{"type":"assistant","sessionId":"c1","timestamp":"2026-10-01T10:00:05Z","isSidechain":true,"message":{"id":"msg_s","role":"assistant","content":[{"type":"text","text":"subagent"}]}}
+{"type":"assistant","sessionId":"c1","timestamp":"2026-10-01T10:00:06Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet","content":[{"type":"text","text":"It races on the clock."}]}}
+not json
+`
+
+const codexRollout = `{"timestamp":"2026-10-02T09:00:00.000Z","type":"session_meta","payload":{"id":"x1","cwd":"/repo","timestamp":"2026-10-02T09:00:00.000Z"}}
+{"timestamp":"2026-10-02T09:00:00.100Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>cwd</environment_context>"}]}}
+{"timestamp":"2026-10-02T09:00:01.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"list the files"}]}}
+{"timestamp":"2026-10-02T09:00:01.500Z","type":"event_msg","payload":{"type":"token_count"}}
+{"timestamp":"2026-10-02T09:00:02.000Z","type":"response_item","payload":{"type":"reasoning","summary":[{"type":"summary_text","text":"run ls"}]}}
+{"timestamp":"2026-10-02T09:00:03.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"ls\"],\"workdir\":\"/repo\"}","call_id":"call_1"}}
+{"timestamp":"2026-10-02T09:00:04.000Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_1","output":"{\"output\":\"main.go\\n\",\"metadata\":{\"exit_code\":0}}"}}
+{"timestamp":"2026-10-02T09:00:05.000Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** End Patch","call_id":"call_2"}}
+{"timestamp":"2026-10-02T09:00:06.000Z","type":"response_item","payload":{"type":"custom_tool_call_output","call_id":"call_2","output":"{\"output\":\"failed\",\"metadata\":{\"exit_code\":1}}"}}
+{"timestamp":"2026-10-02T09:00:07.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Only main.go."}]}}
+`
+
+func writeTranscript(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// decodeParts reads the stored parts layout back into type/data pairs.
+func decodeParts(t *testing.T, m session.ArchiveMessage) []struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+} {
+	t.Helper()
+	var parts []struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(m.Parts, &parts); err != nil {
+		t.Fatalf("parts of %s: %v", m.ID, err)
+	}
+	return parts
+}
+
+func partTypes(t *testing.T, m session.ArchiveMessage) string {
+	var types []string
+	for _, p := range decodeParts(t, m) {
+		types = append(types, p.Type)
+	}
+	return m.Role + ":" + strings.Join(types, ",")
+}
+
+func TestConvertClaude(t *testing.T) {
+	path := writeTranscript(t, t.TempDir(), "c1.jsonl", claudeTranscript)
+	archive, err := Convert(SourceClaude, path)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if len(archive.Sessions) != 1 || archive.Sessions[0].Title != "Fix the flaky test" {
+		t.Fatalf("sessions = %+v", archive.Sessions)
+	}
+
+	var got []string
+	for _, m := range archive.Messages {
+		got = append(got, partTypes(t, m))
+	}
+	want := []string{
+		"user:text",
+		"assistant:reasoning,tool_call,finish",
+		"tool:tool_result",
+		"assistant:text,finish",
+	}
+	if strings.Join(got, " | ") != strings.Join(want, " | ") {
+		t.Fatalf("messages = %v, want %v", got, want)
+	}
+	if archive.Messages[1].Model != "claude-sonnet" || archive.Messages[1].Seq != 2 {
+		t.Errorf("assistant message = model %q seq %d", archive.Messages[1].Model, archive.Messages[1].Seq)
+	}
+
+	var call message.ToolCall
+	_ = json.Unmarshal(decodeParts(t, archive.Messages[1])[1].Data, &call)
+	if call.Name != "read" || call.ID != "toolu_1" || !strings.Contains(call.Input, "/repo/foo_test.go") || !call.Finished {
+		t.Errorf("tool call = %+v", call)
+	}
+	var result message.ToolResult
+	_ = json.Unmarshal(decodeParts(t, archive.Messages[2])[0].Data, &result)
+	if result.ToolCallID != "toolu_1" || result.Name != "read" || result.Content != "package foo" {
+		t.Errorf("tool result = %+v", result)
+	}
+}
+
+func TestConvertCodex(t *testing.T) {
+	path := writeTranscript(t, t.TempDir(), "rollout-1.jsonl", codexRollout)
+	archive, err := Convert(SourceCodex, path)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if archive.Sessions[0].Title != "list the files" {
+		t.Errorf("title = %q", archive.Sessions[0].Title)
+	}
+
+	var got []string
+	for _, m := range archive.Messages {
+		got = append(got, partTypes(t, m))
+	}
+	want := []string{
+		"user:text",
+		"assistant:reasoning,tool_call,finish",
+		"tool:tool_result",
+		"assistant:tool_call,finish",
+		"tool:tool_result",
+		"assistant:text,finish",
+	}
+	if strings.Join(got, " | ") != strings.Join(want, " | ") {
+		t.Fatalf("messages = %v, want %v", got, want)
+	}
+
+	var shell message.ToolCall
+	_ = json.Unmarshal(decodeParts(t, archive.Messages[1])[1].Data, &shell)
+	if shell.Name != "bash" || shell.Input != `{"command":"ls","workdir":"/repo"}` {
+		t.Errorf("shell call = %+v", shell)
+	}
+	var patch message.ToolCall
+	_ = json.Unmarshal(decodeParts(t, archive.Messages[3])[0].Data, &patch)
+	if patch.Name != "patch" || !strings.Contains(patch.Input, "patch_text") {
+		t.Errorf("patch call = %+v", patch)
+	}
+	var ok, failed message.ToolResult
+	_ = json.Unmarshal(decodeParts(t, archive.Messages[2])[0].Data, &ok)
+	_ = json.Unmarshal(decodeParts(t, archive.Messages[4])[0].Data, &failed)
+	if ok.Content != "main.go\n" || ok.IsError || ok.Name != "bash" {
+		t.Errorf("shell result = %+v", ok)
+	}
+	if failed.Content != "failed" || !failed.IsError {
+		t.Errorf("patch result = %+v", failed)
+	}
+}
+
+func TestConvertIDsAreStable(t *testing.T) {
+	path := writeTranscript(t, t.TempDir(), "c1.jsonl", claudeTranscript)
+	a, err := Convert(SourceClaude, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Convert(SourceClaude, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.RootSessionID != b.RootSessionID || a.Messages[0].ID != b.Messages[0].ID {
+		t.Errorf("IDs differ between conversions: %s/%s", a.RootSessionID, b.RootSessionID)
+	}
+}
+
+func TestConvertEmptyTranscript(t *testing.T) {
+	path := writeTranscript(t, t.TempDir(), "empty.jsonl",
+		`{"type":"summary","summary":"nothing"}`+"\n"+
+			`{"type":"user","sessionId":"e1","isMeta":true,"message":{"role":"user","content":"meta"}}`+"\n")
+	if _, err := Convert(SourceClaude, path); !errors.Is(err, ErrEmptyTranscript) {
+		t.Errorf("err = %v, want ErrEmptyTranscript", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(home, "claude"))
+	t.Setenv("CODEX_HOME", filepath.Join(home, "codex"))
+
+	claudeDir := filepath.Join(home, "claude", "projects", "-repo-my-app")
+	want := writeTranscript(t, claudeDir, "c1.jsonl", claudeTranscript)
+	writeTranscript(t, filepath.Join(home, "claude", "projects", "-other"), "c2.jsonl", claudeTranscript)
+
+	paths, err := Discover(SourceClaude, "/repo/my.app")
+	if err != nil || len(paths) != 1 || paths[0] != want {
+		t.Errorf("claude = %v, %v; want [%s]", paths, err, want)
+	}
+
+	day := filepath.Join(home, "codex", "sessions", "2026", "10", "02")
+	want = writeTranscript(t, day, "rollout-1.jsonl", codexRollout)
+	writeTranscript(t, day, "rollout-2.jsonl", strings.Replace(codexRollout, `"cwd":"/repo"`, `"cwd":"/elsewhere"`, 1))
+	writeTranscript(t, day, "notes.jsonl", codexRollout)
+
+	paths, err = Discover(SourceCodex, "/repo/")
+	if err != nil || len(paths) != 1 || paths[0] != want {
+		t.Errorf("codex = %v, %v; want [%s]", paths, err, want)
+	}
+
+	t.Setenv("CODEX_HOME", filepath.Join(home, "missing"))
+	if paths, err := Discover(SourceCodex, "/repo"); err != nil || len(paths) != 0 {
+		t.Errorf("missing codex home = %v, %v", paths, err)
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	if s, err := ParseSource("Claude"); err != nil || s != SourceClaude {
+		t.Errorf("ParseSource(Claude) = %q, %v", s, err)
+	}
+	if _, err := ParseSource("cursor"); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
@@ -40,10 +40,19 @@ func (s *Span) SetUsage(u *Usage) {
 	if u.CacheCreation > 0 {
 		usageMap["cache_creation"] = u.CacheCreation
 	}
-	usage, _ := json.Marshal(usageMap)
-	cost, _ := json.Marshal(map[string]float64{
+	costMap := map[string]float64{
 		"input": u.InputCost, "output": u.OutputCost, "total": u.TotalCost,
-	})
+	}
+	if u.Reasoning > 0 {
+		// Langfuse's own convention: output_reasoning is split off output,
+		// not counted in it.
+		usageMap["output"] = u.Output - u.Reasoning
+		usageMap["output_reasoning"] = u.Reasoning
+		costMap["output"] = u.OutputCost - u.ReasoningCost
+		costMap["output_reasoning"] = u.ReasoningCost
+	}
+	usage, _ := json.Marshal(usageMap)
+	cost, _ := json.Marshal(costMap)
 	s.span.SetAttributes(
 		attribute.String("langfuse.observation.usage_details", string(usage)),
 		attribute.String("langfuse.observation.cost_details", string(cost)),
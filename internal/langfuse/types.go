@@ -35,7 +35,11 @@ type Usage struct {
 	Total         int64
 	CacheRead     int64
 	CacheCreation int64
+	// Reasoning is the part of Output spent thinking, and ReasoningCost
+	// the part of OutputCost it accounts for.
+	Reasoning     int64
 	InputCost     float64
 	OutputCost    float64
+	ReasoningCost float64
 	TotalCost     float64
 }
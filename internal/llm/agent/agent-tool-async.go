@@ -41,6 +41,7 @@ func (b *agentTool) runAsync(
 	isResumed bool,
 	a Service,
 	prompt string,
+	link SubagentEvent,
 ) (tools.ToolResponse, error) {
 	reg := task.GlobalRegistry()
 	if reg == nil {
@@ -65,8 +66,11 @@ func (b *agentTool) runAsync(
 	if stepScope := tools.StepScopedContext(ctx); stepScope != nil {
 		runCtx = context.WithValue(runCtx, tools.StepScopedContextKey, stepScope)
 	}
-	done, err := a.Run(runCtx, taskSession.ID, prompt, 0)
+	runCtx = inheritRunSnapshot(runCtx, ctx)
+	runCtx, release := TrackBranch(runCtx, taskSession.ID)
+	done, err := b.runStreamed(runCtx, a, link, prompt)
 	if err != nil {
+		release()
 		cancel()
 		_ = outputFile.Close()
 		_ = os.Remove(outputPath)
@@ -84,6 +88,7 @@ func (b *agentTool) runAsync(
 		Cancel:                cancel,
 	}
 	if err := reg.Register(tk); err != nil {
+		release()
 		cancel()
 		_ = outputFile.Close()
 		_ = os.Remove(outputPath)
@@ -91,7 +96,12 @@ func (b *agentTool) runAsync(
 	}
 
 	syntheticInput := call.Input
-	go b.waitAsyncAndNotify(done, outputFile, outputPath, sessionID, call.ID, taskID, taskSession.ID, syntheticInput)
+	go func() {
+		defer release()
+		b.waitAsyncAndNotify(done, outputFile, outputPath, sessionID, call.ID, taskID, taskSession.ID, syntheticInput, func() bool {
+			return BranchCancelled(runCtx)
+		})
+	}()
 
 	agentName := subagentType
 	if subagentInfo.Name != "" {
@@ -118,6 +128,7 @@ func (b *agentTool) waitAsyncAndNotify(
 	done <-chan AgentEvent,
 	outputFile *os.File,
 	outputPath, sessionID, callID, taskID, taskSessionID, syntheticInput string,
+	cancelledByUser func() bool,
 ) {
 	defer logging.RecoverPanic("agent.runAsync.wait", nil)
 	result := <-done
@@ -138,6 +149,11 @@ func (b *agentTool) waitAsyncAndNotify(
 		_ = isStructOutput
 	}
 
+	if cancelledByUser() {
+		status = task.StatusKilled
+		content = "Async task cancelled by the user"
+	}
+
 	// If taskstop was used, the registry state was set to Killed before
 	// the context cancellation reached us. Honor that by overriding status.
 	if reg := task.GlobalRegistry(); reg != nil {
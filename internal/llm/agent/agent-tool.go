@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
 	"github.com/opencode-ai/opencode/internal/config"
@@ -17,6 +18,7 @@ import (
 
 type agentTool struct {
 	sessions    session.Service
+	messages    message.Service
 	permissions permission.Service
 	registry    agentregistry.Registry
 	factory     AgentFactory
@@ -48,6 +50,9 @@ type TaskResponseMetadata struct {
 	SubagentName   string `json:"subagent_name"`
 	IsResumed      bool   `json:"is_resumed"`
 	IsStructOutput bool   `json:"is_struct_output"`
+	// Cached is set when the response came from the subagent's response
+	// cache instead of a new run.
+	Cached bool `json:"cached,omitempty"`
 }
 
 // Deprecated: use TaskParams instead
@@ -138,6 +143,30 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 		}
 		return tools.NewTextErrorResponse(fmt.Sprintf("unknown subagent type %q. Available: %s", subagentType, strings.Join(names, ", "))), nil
 	}
+	agentName := subagentType
+	if subagentInfo.Name != "" {
+		agentName = subagentInfo.Name
+	}
+
+	// A fresh synchronous call repeating an earlier prompt on the same
+	// workspace state is answered from the response cache when the
+	// subagent enables it.
+	var cache responseCache
+	if !params.Async && params.TaskID == "" {
+		cache = newResponseCache(ctx, subagentType)
+		if cached, ok := cache.get(params.Prompt); ok {
+			logging.Info("Task answered from response cache", "subagent", subagentType, "task_id", cached.TaskID)
+			return tools.WithResponseMetadata(
+				tools.NewTextResponse(cached.Content),
+				TaskResponseMetadata{
+					TaskID:         cached.TaskID,
+					SubagentType:   subagentType,
+					SubagentName:   agentName,
+					IsStructOutput: cached.IsStructOutput,
+					Cached:         true,
+				}), nil
+		}
+	}
 
 	// Subagents invoked via the task tool never represent an
 	// interactive flow step — that's the parent agent's role. Pass
@@ -183,11 +212,21 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	// Async path: spawn the subagent in the background, return an
 	// immediate ack. A goroutine waits on `done` and fires the synthetic
 	// completion via task.EnqueueTaskCompletion when the subagent exits.
+	link := SubagentEvent{
+		AgentEvent:      AgentEvent{SessionID: taskSession.ID},
+		ParentSessionID: sessionID,
+		ToolCallID:      call.ID,
+		SubagentType:    subagentType,
+	}
 	if params.Async {
-		return b.runAsync(ctx, call, params, sessionID, subagentType, subagentInfo, taskSession, isResumed, a, prompt)
+		return b.runAsync(ctx, call, params, sessionID, subagentType, subagentInfo, taskSession, isResumed, a, prompt, link)
 	}
 
-	done, err := a.Run(ctx, taskSession.ID, prompt, 0)
+	// The subagent runs as a branch: CancelBranch stops it alone and this
+	// turn continues with an error result for the call.
+	runCtx, release := TrackBranch(ctx, taskSession.ID)
+	defer release()
+	done, err := b.runStreamed(runCtx, a, link, prompt)
 	if err != nil {
 		return tools.ToolResponse{}, fmt.Errorf("error while running task agent: %s", err)
 	}
@@ -206,6 +245,18 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	// still sees the real subagent result.
 	b.rollUpSubagentCost(ctx, sessionID, taskSession.ID)
 
+	if BranchCancelled(runCtx) && ctx.Err() == nil {
+		return tools.WithResponseMetadata(
+			tools.NewTextErrorResponse(fmt.Sprintf(
+				"The user cancelled this %s subagent before it finished. Its partial work stays in task_id %q. Carry on without its result unless the user asks you to resume it.",
+				subagentType, taskSession.ID)),
+			TaskResponseMetadata{
+				TaskID:       taskSession.ID,
+				SubagentType: subagentType,
+				SubagentName: agentName,
+				IsResumed:    isResumed,
+			}), nil
+	}
 	if result.Error != nil {
 		return tools.ToolResponse{}, fmt.Errorf("error while running task agent: %s", result.Error)
 	}
@@ -216,20 +267,21 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	}
 	responseContent, isStructOutput := buildTaskResponseContent(result, taskSession.ID)
 	logging.Debug("Task completed", "subagent", subagentType, "structured", isStructOutput, "error", result.Error)
-
-	agentName := subagentType
-	if subagentInfo.Name != "" {
-		agentName = subagentInfo.Name
-	}
+	cache.put(params.Prompt, cachedResponse{
+		Content:        responseContent,
+		IsStructOutput: isStructOutput,
+		TaskID:         taskSession.ID,
+		CreatedAt:      time.Now().Unix(),
+	})
 
 	return tools.WithResponseMetadata(
 		tools.NewTextResponse(responseContent),
 		TaskResponseMetadata{
-			taskSession.ID,
-			subagentType,
-			agentName,
-			isResumed,
-			isStructOutput,
+			TaskID:         taskSession.ID,
+			SubagentType:   subagentType,
+			SubagentName:   agentName,
+			IsResumed:      isResumed,
+			IsStructOutput: isStructOutput,
 		}), nil
 }
 
@@ -278,12 +330,14 @@ func (b *agentTool) IsBaseline() bool { return true }
 
 func NewAgentTool(
 	sessions session.Service,
+	messages message.Service,
 	permissions permission.Service,
 	reg agentregistry.Registry,
 	factory AgentFactory,
 ) tools.BaseTool {
 	return &agentTool{
 		sessions:    sessions,
+		messages:    messages,
 		permissions: permissions,
 		registry:    reg,
 		factory:     factory,
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -24,7 +25,9 @@ import (
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/moderation"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
@@ -51,6 +54,9 @@ const (
 	AgentEventTypeError     AgentEventType = "error"
 	AgentEventTypeResponse  AgentEventType = "response"
 	AgentEventTypeSummarize AgentEventType = "summarize"
+	// AgentEventTypeBudgetExceeded ends a run that hit a configured cost
+	// or token limit. Error holds a *BudgetExceededError.
+	AgentEventTypeBudgetExceeded AgentEventType = "budget_exceeded"
 )
 
 const (
@@ -88,13 +94,18 @@ type AgentEvent struct {
 	// When has structured output
 	StructOutput *message.ToolResult
 
-	// When summarizing
+	// SessionID is set on every event published by Run and on
+	// summarization progress events.
 	SessionID string
 	Progress  string
 	Done      bool
 
 	// FlowStepID is set when event originates from a Flow step
 	FlowStepID string
+
+	// HandoffTo is set on the terminal event of a run that ended with a
+	// handoff tool call: the session continues with that primary agent.
+	HandoffTo config.AgentName
 }
 
 // RunOptions configures a single agent.Run invocation. New options should
@@ -111,6 +122,12 @@ type RunOptions struct {
 	// auto-resume behaviour where ResumeSession kicks a fresh agent.Run.
 	NonInteractive bool
 
+	// Batch lets the run's requests go through the provider's batch API
+	// when the provider has batch mode enabled. Set for offline work
+	// only (flow steps, headless prompts, queued runs): a batched answer
+	// can take hours.
+	Batch bool
+
 	// CompactionThreshold overrides the global AutoCompactionThreshold
 	// (default 0.95) for this Run only. Set to a value in (0, 1] to trigger
 	// synchronous compaction earlier — e.g. a flow step processing a lot of
@@ -150,6 +167,9 @@ type Service interface {
 	TryLockSession(sessionID string) bool
 	UnlockSession(sessionID string)
 	Update(agentName config.AgentName, modelID models.ModelID) (models.Model, error)
+	// Reload rebuilds the agent's providers from the current configuration,
+	// e.g. after a profile switch. Returns ErrAgentBusy while it runs.
+	Reload() (models.Model, error)
 	Summarize(ctx context.Context, sessionID string) error
 	// SummarizeSync compacts the session and blocks until the summary has been
 	// written (unlike Summarize, which is event-driven and returns immediately).
@@ -157,6 +177,16 @@ type Service interface {
 	// interleave, and returns ErrSessionBusy if the session is already in use.
 	SummarizeSync(ctx context.Context, sessionID string) error
 	GenerateRecap(ctx context.Context, sessionID string) (string, error)
+	// RegenerateTitle describes the session again from its current
+	// conversation and renames it to the result, replacing a title the
+	// user set.
+	RegenerateTitle(ctx context.Context, sessionID string) (session.Session, error)
+	// InspectContext lists what the next request of the session will
+	// contain, with estimated token costs.
+	InspectContext(ctx context.Context, sessionID string) (ContextReport, error)
+	// ExcludeFromNextTurn leaves items listed by InspectContext out of the
+	// session's next run only.
+	ExcludeFromNextTurn(sessionID string, ids []string) error
 }
 
 type agent struct {
@@ -171,9 +201,36 @@ type agent struct {
 	toolsResolved    atomic.Bool
 	provider         provider.Provider
 	allowParallelism bool
+	// maxParallelTasks bounds the task tool calls of one turn that run
+	// their subagents at the same time; 0 leaves them unbounded.
+	maxParallelTasks int
+	// fallbackModels is the chain failover walks when the provider runs
+	// out of quota or is overloaded; providerOpts rebuilds the provider
+	// for each of them. See failover.go.
+	fallbackModels []models.ModelID
+	providerOpts   []providerOption
+	// autoReasoning is set for reasoningEffort "auto": every turn then
+	// runs with the effort turnReasoningEffort picks for its prompt.
+	autoReasoning bool
+	// routedProviders caches a provider per model that routing.rules sends
+	// requests to; see routing.go.
+	routedProviders sync.Map
+	// dryRun makes every write tool call of this agent report its change
+	// instead of applying it; see tools.IsDryRun.
+	dryRun bool
+	// outputSchema is the schema the final answer must match; see
+	// structured_output.go.
+	outputSchema map[string]any
+	// moderator screens responses before their tool calls run; nil when
+	// moderation is not configured. See moderation.go.
+	moderator   *moderation.Moderator
+	permissions permission.Service
 
 	titleProvider     provider.Provider
 	summarizeProvider provider.Provider
+	// translateProvider is only built when translation.language is
+	// configured; see translate.go.
+	translateProvider provider.Provider
 
 	// factory exposes services that are late-injected on the factory
 	// after agent construction. Today we read HookRegistry off it at
@@ -183,6 +240,13 @@ type agent struct {
 	factory AgentFactory
 
 	activeRequests sync.Map
+	// contextExclusions holds the items each session's next run leaves
+	// out; see context_inspector.go.
+	contextExclusions sync.Map
+	// memoryVersion and contextVersion are the memory.Version and
+	// prompt.ContextVersion the provider's system prompt was built at.
+	memoryVersion  int64
+	contextVersion int64
 }
 
 func newAgent(
@@ -199,12 +263,13 @@ func newAgent(
 ) (Service, error) {
 	agentTools := NewToolSet(ctx, agentInfo, reg, permissions, historyService, lspService, sessions, messages, mcpReg, factory)
 
-	agentProvider, err := createAgentProvider(
-		agentInfo.ID,
+	providerOpts := []providerOption{
 		withInteractive(agentInfo.Interactive),
 		withBoundPeers(agentInfo.BoundPeers),
 		withHasOutputSchema(agentInfo.Output != nil && agentInfo.Output.Schema != nil),
-	)
+	}
+	memoryVersion, contextVersion := memory.Version(), prompt.ContextVersion()
+	agentProvider, err := createAgentProvider(agentInfo.ID, providerOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -221,18 +286,45 @@ func newAgent(
 		}
 	}
 
+	moderator, err := moderation.New(config.Get().Moderation)
+	if err != nil {
+		return nil, err
+	}
+
+	var translateProvider provider.Provider
+	if cfg := config.Get(); agentInfo.Mode == config.AgentModeAgent && cfg.Translation != nil && cfg.Translation.Language != "" {
+		translateProvider, err = createAgentProvider(config.AgentTranslator, withDisableCache())
+		if err != nil {
+			// Translation is an optional post-processing step; a broken
+			// translator model must not keep the primary agent from starting.
+			logging.Warn("Failed to create translator provider, responses will not be translated", "error", err)
+			translateProvider = nil
+		}
+	}
+
 	agent := &agent{
 		Broker:            pubsub.NewBroker[AgentEvent](),
 		agentID:           agentInfo.ID,
 		provider:          agentProvider,
+		fallbackModels:    modelIDs(agentInfo.FallbackModels),
+		providerOpts:      providerOpts,
 		messages:          messages,
 		sessions:          sessions,
 		toolsCh:           agentTools,
 		titleProvider:     titleProvider,
 		summarizeProvider: summarizeProvider,
+		translateProvider: translateProvider,
 		activeRequests:    sync.Map{},
 		allowParallelism:  agentInfo.AllowsParallelToolUse(),
+		maxParallelTasks:  agentInfo.ParallelTaskLimit(),
+		autoReasoning:     strings.EqualFold(agentInfo.ReasoningEffort, config.ReasoningEffortAuto),
+		dryRun:            agentInfo.DryRun,
+		outputSchema:      outputSchemaFor(agentInfo),
+		moderator:         moderator,
+		permissions:       permissions,
 		factory:           factory,
+		memoryVersion:     memoryVersion,
+		contextVersion:    contextVersion,
 	}
 
 	// Resolve tools in background so they're ready before first Run() call
@@ -407,6 +499,38 @@ func (a *agent) Cancel(sessionID string) {
 			cancel()
 		}
 	}
+
+	a.killSessionJobs(sessionID)
+}
+
+// killSessionJobs stops the background shell jobs (bash run_in_background
+// and monitor) started in sessionID or, when it is a root session, in any
+// of its subagent sessions. Their completion notifications still land in
+// the owning sessions.
+func (a *agent) killSessionJobs(sessionID string) {
+	reg := task.GlobalRegistry()
+	if reg == nil {
+		return
+	}
+	sessionIDs := []string{sessionID}
+	if children, err := a.sessions.ListChildren(context.Background(), sessionID); err == nil {
+		for _, child := range children {
+			sessionIDs = append(sessionIDs, child.ID)
+		}
+	}
+	for _, id := range sessionIDs {
+		for _, tk := range reg.ListBySession(id) {
+			if tk.Kind != task.KindBash && tk.Kind != task.KindMonitor {
+				continue
+			}
+			if tk.State() != task.StateRunning {
+				continue
+			}
+			if err := reg.Kill(tk.ID); err == nil {
+				logging.Info("Killed background job on cancel", "session", id, "job", tk.ID)
+			}
+		}
+	}
 }
 
 func (a *agent) IsBusy() bool {
@@ -458,53 +582,6 @@ func (a *agent) UnlockSession(sessionID string) {
 	}
 }
 
-func (a *agent) generateTitle(ctx context.Context, sessionID string, content string) error {
-	if content == "" {
-		return nil
-	}
-	if a.titleProvider == nil {
-		return nil
-	}
-	sess, err := a.sessions.Get(ctx, sessionID)
-	if err != nil {
-		return err
-	}
-	// A user-set title is authoritative — skip generation entirely so we don't
-	// waste a descriptor call. The write below is also guarded at the DB level
-	// (SetGeneratedTitle) to close the race where a rename lands after this read.
-	if sess.UserSetTitle {
-		return nil
-	}
-	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
-	ctx = context.WithValue(ctx, tools.AgentIDContextKey, config.AgentName("descriptor"))
-	ctx = a.createLangfuseTrace(ctx, sess)
-	defer langfuse.EndTrace(ctx)
-	parts := []message.ContentPart{message.TextContent{Text: content}}
-	response, err := a.titleProvider.SendMessages(
-		ctx,
-		[]message.Message{
-			{
-				Role:  message.User,
-				Parts: parts,
-			},
-		},
-		make([]tools.BaseTool, 0),
-	)
-	if err != nil {
-		return err
-	}
-
-	title := strings.TrimSpace(strings.ReplaceAll(response.Content, "\n", " "))
-	if title == "" {
-		return nil
-	}
-
-	// Guarded write: only lands if the session is still not user-titled, so a
-	// rename that commits while this descriptor call was in flight wins.
-	_, err = a.sessions.SetGeneratedTitle(ctx, sessionID, title)
-	return err
-}
-
 const recapMessageWindow = 30
 
 // Minimum thresholds for generating a recap. Sessions below either threshold
@@ -649,6 +726,9 @@ func (a *agent) RunWith(ctx context.Context, sessionID string, content string, m
 			logging.Info("Agent completed", "sessionID", sessionID, "agent", a.AgentID(), "gauge", gauge)
 		}
 
+		if result.SessionID == "" {
+			result.SessionID = sessionID
+		}
 		a.Publish(pubsub.CreatedEvent, result)
 		events <- result
 		close(events)
@@ -658,8 +738,14 @@ func (a *agent) RunWith(ctx context.Context, sessionID string, content string, m
 
 func (a *agent) processGeneration(ctx context.Context, sessionID, content string, maxTurnsOverride int, attachmentParts []message.ContentPart, opts RunOptions) AgentEvent {
 	cfg := config.Get()
-	// List existing messages; if none, start title generation asynchronously.
-	msgs, err := a.messages.List(ctx, sessionID)
+	ctx = withRunSnapshot(ctx, sessionID)
+	session, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return a.err(fmt.Errorf("failed to get session: %w", err))
+	}
+	// Load history from the summary on; if there is none yet, start title
+	// generation asynchronously.
+	msgs, err := a.loadHistory(ctx, session)
 	if err != nil {
 		return a.err(fmt.Errorf("failed to list messages: %w", err))
 	}
@@ -675,21 +761,15 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			}
 		}()
 	}
-	session, err := a.sessions.Get(ctx, sessionID)
-	if err != nil {
-		return a.err(fmt.Errorf("failed to get session: %w", err))
-	}
-	if session.SummaryMessageID != "" {
-		msgs = a.filterMessagesFromSummary(msgs, session.SummaryMessageID)
-	}
-	// Auto-recover sessions previously corrupted by an empty user turn
+	// Besides cutting the history at the summary, requestHistory
+	// auto-recovers sessions previously corrupted by an empty user turn
 	// (older builds called createUserMessage unconditionally on auto-resume
 	// — see the comment block before the createUserMessage call below).
 	// The persisted `user(text="")` makes every subsequent agent.Run on
 	// that session fail with HTTP 400 `messages: text content blocks must
 	// be non-empty`. Dropping these messages from the history we send
 	// upstream lets the model continue without manual intervention.
-	msgs = filterEmptyUserMessages(msgs)
+	msgs = a.requestHistory(msgs, session)
 	if session.ParentSessionID != "" {
 		ctx = context.WithValue(ctx, tools.IsTaskAgentContextKey, true)
 	}
@@ -700,6 +780,22 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	// a foreground `sleep` to the background-task wait instead of burning
 	// wall-clock while tasks are pending. Runtime-only, never persisted.
 	ctx = context.WithValue(ctx, tools.NonInteractiveContextKey, opts.NonInteractive)
+	if opts.Batch {
+		ctx = provider.BatchContext(ctx)
+	}
+	// Dry-run is only ever switched on here, never off, so subagents of a
+	// dry-run agent inherit it through ctx.
+	if cfg := config.Get(); a.dryRun || (cfg != nil && cfg.DryRun) {
+		ctx = context.WithValue(ctx, tools.DryRunContextKey, true)
+	}
+	if cfg := config.Get(); cfg != nil && cfg.ReadOnly {
+		ctx = context.WithValue(ctx, tools.ReadOnlyContextKey, true)
+	}
+	// Each run gets a fresh diff budget; a subagent's run keeps its
+	// caller's, so its changes count against the turn that started it.
+	if cfg := config.Get(); cfg != nil {
+		ctx = tools.WithDiffBudget(ctx, tools.NewDiffBudget(cfg.DiffBudget))
+	}
 	ctx = tools.AddTag(ctx, "agent", a.AgentID())
 
 	ctx = a.createLangfuseTrace(ctx, session)
@@ -720,7 +816,24 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	var userMsg message.Message
 	hasUserTurn := content != "" || len(attachmentParts) > 0
 	msgHistory := msgs
+	if a.autoReasoning {
+		effort := turnReasoningEffort(content, hasUserTurn)
+		logging.Debug("Picked reasoning effort for turn", "agent", a.agentID, "session_id", sessionID, "effort", effort)
+		ctx = provider.ReasoningEffortContext(ctx, effort)
+	}
 	if hasUserTurn {
+		if routed, class := a.routedProvider(content); routed != nil {
+			// The route only holds for this run. A failover switch made
+			// meanwhile replaces the routed provider and is kept.
+			prev := a.provider
+			a.provider = routed
+			defer func() {
+				if a.provider == routed {
+					a.provider = prev
+				}
+			}()
+			logging.Info("Routed request", "agent", a.agentID, "session_id", sessionID, "class", class, "model", routed.Model().ID)
+		}
 		if hint := proactiveMaxTurnsHint(effectiveMaxTurns); hint != "" {
 			content += hint
 		}
@@ -737,9 +850,18 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	structOutputIsErr := true
 	cycles := 0
 	preserveTail := false
+	// filterRetries counts the retries made for responses the provider's
+	// content filter blocked.
+	filterRetries := 0
 
 	// Susped to get lazy tools
 	toolSet := a.resolveTools()
+	// Memories and context files written since the provider was built are
+	// missing from its system prompt; send a rebuilt one.
+	if memory.Version() != a.memoryVersion || prompt.ContextVersion() != a.contextVersion {
+		ctx = provider.SystemMessageContext(ctx, a.systemPrompt())
+	}
+	ctx, msgHistory, toolSet = a.applyContextExclusions(ctx, a.takeContextExclusions(sessionID), msgHistory, toolSet)
 
 	tracker := newCallTracker()
 
@@ -781,6 +903,12 @@ OuterLoop:
 			default:
 				// Continue processing
 			}
+			// Refuse to start another model call once a budget is spent;
+			// earlier turns may have crossed it in a child session.
+			if budgetErr := a.checkSessionBudget(ctx, sessionID); errors.Is(budgetErr, ErrBudgetExceeded) {
+				logging.Warn("Budget exceeded, stopping run", "session_id", sessionID, "error", budgetErr)
+				return a.budgetExceeded(sessionID, agentMessage, budgetErr)
+			}
 
 			etaTokens, shouldTriggerAutoCompaction := a.provider.CountTokens(ctx, effectiveCompactionThreshold(opts.CompactionThreshold), msgHistory, toolSet)
 			// Check if auto-compaction should be triggered before each model call
@@ -803,15 +931,15 @@ OuterLoop:
 					// Continue anyway - better to risk context overflow than stop completely
 				} else {
 					// After successful compaction, reload messages and rebuild msgHistory
-					msgs, errMsg := a.messages.List(ctx, sessionID)
-					if err != nil {
-						return a.err(fmt.Errorf("failed to reload messages after compaction: %w", errMsg))
-					}
-
 					session, errMsg := a.sessions.Get(ctx, sessionID)
 					if errMsg != nil {
 						return a.err(fmt.Errorf("failed to get session after compaction: %w", errMsg))
 					}
+
+					msgs, errMsg := a.loadHistory(ctx, session)
+					if errMsg != nil {
+						return a.err(fmt.Errorf("failed to reload messages after compaction: %w", errMsg))
+					}
 					msgs = a.filterMessagesFromSummary(msgs, session.SummaryMessageID)
 
 					// Carry task budget across compaction: tell provider how much budget remains
@@ -918,11 +1046,20 @@ OuterLoop:
 
 			agentMessage, toolResults, err = a.streamAndHandleEvents(ctx, sessionID, msgHistory, toolSet, tracker)
 			if err != nil {
+				if a.failover(ctx, sessionID, &agentMessage, err) {
+					continue
+				}
 				a.createErrorToolResults(agentMessage)
 				if errors.Is(err, context.Canceled) {
 					a.finishMessage(ctx, &agentMessage, message.FinishReasonCanceled)
 					return a.err(ErrRequestCancelled)
 				}
+				if errors.Is(err, ErrBudgetExceeded) {
+					// The response itself completed; only the tool calls it
+					// asked for were dropped above.
+					logging.Warn("Budget exceeded, stopping run", "session_id", sessionID, "error", err)
+					return a.budgetExceeded(sessionID, agentMessage, err)
+				}
 				a.finishMessage(ctx, &agentMessage, message.FinishReasonError)
 				return a.err(fmt.Errorf("failed to process events: %w", err))
 			}
@@ -933,6 +1070,12 @@ OuterLoop:
 			} else {
 				logging.Info("Provider stream completed", "reason", agentMessage.FinishReason(), "cycle", cycles)
 			}
+			if agentMessage.FinishReason() == message.FinishReasonContentFilter {
+				if next, retry := a.handleContentFilter(ctx, sessionID, &agentMessage, msgHistory, &filterRetries); retry {
+					msgHistory = next
+					continue
+				}
+			}
 			if agentMessage.FinishReason() == message.FinishReasonToolUse {
 				if toolResults == nil {
 					// Tool results are nil (tool execution failed or returned empty)
@@ -995,6 +1138,19 @@ OuterLoop:
 					logging.Info("struct_output accepted but background tasks pending — continuing to the wait cycle", "session_id", sessionID, "pending_count", pendingTasks)
 				}
 
+				// A handoff ends the run at once: the next turn belongs to
+				// the agent the session was handed to.
+				if to, ok := handoffTarget(agentMessage, toolResults); ok {
+					logging.Info("Session handed off", "session_id", sessionID, "from", a.agentID, "to", to)
+					finalResult = AgentEvent{
+						Type:      AgentEventTypeResponse,
+						Message:   agentMessage,
+						Done:      true,
+						HandoffTo: to,
+					}
+					break OuterLoop
+				}
+
 				preserveTail = true
 				continue
 			}
@@ -1048,7 +1204,7 @@ OuterLoop:
 		// Wait completed — synthetic completions are in the message log.
 		// Reload, filter the empty-user-turn corruption, and let the
 		// inner loop run another cycle so the model can react.
-		freshMsgs, listErr := a.messages.List(ctx, sessionID)
+		freshMsgs, listErr := a.loadHistory(ctx, session)
 		if listErr != nil {
 			logging.Warn(
 				"Failed to reload messages after non-interactive wait; returning pre-wait result",
@@ -1074,6 +1230,16 @@ OuterLoop:
 		preserveTail = false
 		hasUserTurn = false
 	}
+	if a.needsStructuredOutput(finalResult, toolSet) {
+		finalMsg, structOutput, err := a.finalizeStructuredOutput(ctx, sessionID, append(msgHistory, finalResult.Message), toolSet, tracker)
+		if err != nil {
+			return a.err(err)
+		}
+		finalResult.Message = finalMsg
+		finalResult.StructOutput = structOutput
+	}
+	a.translateFinalResponse(ctx, sessionID, &finalResult)
+	a.annotateCitations(ctx, sessionID, &finalResult)
 	return finalResult
 }
 
@@ -1148,6 +1314,9 @@ func (a *agent) createUserMessage(ctx context.Context, sessionID, content string
 }
 
 func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message, toolSet []tools.BaseTool, tracker *callTracker) (message.Message, *message.Message, error) {
+	a.auditProviderRequest(sessionID, msgHistory, toolSet)
+	toolTokens := toolOutputTokens(msgHistory)
+	requestStart := time.Now()
 	eventChan := a.provider.StreamResponse(ctx, msgHistory, toolSet)
 
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
@@ -1162,8 +1331,9 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 	ctx = context.WithValue(ctx, tools.MessageIDContextKey, assistantMsg.ID)
 
 	// Process provider response first
+	inputFlush := &toolInputThrottle{interval: toolInputFlushInterval}
 	for event := range eventChan {
-		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event); processErr != nil {
+		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event, toolTokens, requestStart, inputFlush); processErr != nil {
 			return assistantMsg, nil, processErr
 		}
 		if ctx.Err() != nil {
@@ -1181,9 +1351,22 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 	// invariant by passing entry.index, which is assigned during phase 1.
 	// Concurrent invocation from those goroutines is safe: the broker's
 	// Publish takes RLock, and per-index ownership prevents slice races.
+	// Every result gets the common metadata envelope (tools.ResultMetadata);
+	// started holds each dispatched call's start time for its duration.
+	started := make([]time.Time, len(toolCalls))
+	// decisions holds each dispatched call's permission decisions for the
+	// audit trail.
+	decisions := make([]*permission.DecisionRecorder, len(toolCalls))
 	record := func(index int, tr message.ToolResult) {
+		call := toolCalls[index]
+		var meta tools.ResultMetadata
+		tr.Metadata, meta = tools.StampResultMetadata(
+			tools.ToolCall{ID: call.ID, Name: call.Name, Input: call.Input},
+			tr.Metadata, tr.Content, tr.IsError, started[index],
+		)
 		toolResults[index] = tr
 		a.messages.PublishPart(sessionID, assistantMsg.ID, tr)
+		a.auditToolCall(sessionID, call, meta, decisions[index])
 	}
 
 	// Phase 1: Pre-processing (synchronous) — resolve tools, loop detection, classify parallelism
@@ -1201,7 +1384,18 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 		allToolCalls[i] = tools.ToolCall{ID: tc.ID, Name: tc.Name, Input: tc.Input}
 	}
 
+	moderationBlock := a.moderate(ctx, sessionID, assistantMsg, toolCalls)
+
 	for i, toolCall := range toolCalls {
+		if moderationBlock != "" {
+			record(i, message.ToolResult{
+				ToolCallID: toolCall.ID,
+				Name:       toolCall.Name,
+				Content:    moderationBlock,
+				IsError:    true,
+			})
+			continue
+		}
 		var tool tools.BaseTool
 		for _, availableTool := range toolSet {
 			if availableTool.Info().Name == toolCall.Name {
@@ -1244,6 +1438,8 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 		}
 	}
 
+	snapshotBeforeMutation(ctx, toolCalls)
+
 	logging.Debug("Tool execution groups",
 		"parallel", len(parallelGroup),
 		"sequential", len(sequentialGroup),
@@ -1257,12 +1453,28 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 	permissionDenied := false
 	if len(parallelGroup) > 0 {
 		permCtx, permCancel := context.WithCancel(ctx)
+		// Task calls beyond maxParallelTasks wait for a slot. Waiting
+		// calls give up when the turn is canceled; running subagents are
+		// canceled with it, as their runs derive from permCtx.
+		var taskSlots chan struct{}
+		if a.maxParallelTasks > 0 {
+			taskSlots = make(chan struct{}, a.maxParallelTasks)
+		}
 		var wg sync.WaitGroup
 		for _, entry := range parallelGroup {
 			wg.Add(1)
 			go func(e toolEntry) {
 				defer wg.Done()
+				if taskSlots != nil && e.toolCall.Name == TaskToolName {
+					select {
+					case taskSlots <- struct{}{}:
+						defer func() { <-taskSlots }()
+					case <-permCtx.Done():
+						return
+					}
+				}
 				now := time.Now()
+				started[e.index] = now
 
 				// Start Langfuse tool span
 				var toolSpan *langfuse.Span
@@ -1311,6 +1523,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 				if hc.decision.ExplicitAllow {
 					toolCtx = context.WithValue(permCtx, permission.HookAllowKey, true)
 				}
+				toolCtx, decisions[e.index] = permission.WithDecisionRecorder(toolCtx)
 				go func() {
 					r, errTool := e.tool.Run(toolCtx, tools.ToolCall{
 						ID:    e.toolCall.ID,
@@ -1527,10 +1740,12 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 		}
 
 		now := time.Now()
+		started[entry.index] = now
 		seqToolCtx := ctx
 		if seqHC.decision.ExplicitAllow {
 			seqToolCtx = context.WithValue(ctx, permission.HookAllowKey, true)
 		}
+		seqToolCtx, decisions[entry.index] = permission.WithDecisionRecorder(seqToolCtx)
 		toolResult, toolErr := entry.tool.Run(seqToolCtx, tools.ToolCall{
 			ID:    entry.toolCall.ID,
 			Name:  entry.toolCall.Name,
@@ -1615,8 +1830,15 @@ out:
 		return assistantMsg, nil, nil
 	}
 	parts := make([]message.ContentPart, 0)
+	pinned := false
 	for _, tr := range toolResults {
 		parts = append(parts, tr)
+		if meta, ok := tools.ParseResultMetadata(tr.Metadata); ok && meta.Pinned {
+			pinned = true
+		}
+	}
+	if pinned {
+		parts = append(parts, message.Pin{Time: time.Now().Unix()})
 	}
 	msg, err := a.messages.Create(context.Background(), assistantMsg.SessionID, message.CreateMessageParams{
 		Role:  message.Tool,
@@ -1630,6 +1852,14 @@ out:
 }
 
 func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishReson message.FinishReason) {
+	// A call cut off while its input streamed holds a JSON prefix that
+	// would be replayed to the provider as malformed arguments.
+	for _, tc := range msg.ToolCalls() {
+		if !tc.Finished && tc.Input != "" {
+			tc.Input = ""
+			msg.UpdateToolCall(tc)
+		}
+	}
 	msg.AddFinish(finishReson)
 	// When the caller's ctx is already cancelled (graceful shutdown, step
 	// timeout, ErrRequestCancelled cleanup), a.messages.Update would fail
@@ -1675,6 +1905,29 @@ func (a *agent) createErrorToolResults(assistantMsg message.Message) *message.Me
 	return &msg
 }
 
+// toolInputFlushInterval is how often streamed tool-call input is persisted
+// while a call is being generated.
+const toolInputFlushInterval = 150 * time.Millisecond
+
+// toolInputThrottle rate-limits persisting the partial input of streaming
+// tool calls. The first delta of each call is persisted right away so the
+// call shows up with its arguments as soon as they start.
+type toolInputThrottle struct {
+	interval time.Duration
+	callID   string
+	last     time.Time
+}
+
+func (t *toolInputThrottle) due(callID string) bool {
+	now := time.Now()
+	if callID == t.callID && now.Sub(t.last) < t.interval {
+		return false
+	}
+	t.callID = callID
+	t.last = now
+	return true
+}
+
 // mergeToolCalls updates tool call Input from the accumulated response without replacing IDs.
 // During streaming, tool calls are registered with IDs from ContentBlockStartEvent.
 // The accumulated SDK response may carry different IDs (e.g. through LiteLLM/Vertex proxies).
@@ -1697,7 +1950,7 @@ func (a *agent) mergeToolCalls(assistantMsg *message.Message, accumulated []mess
 	}
 }
 
-func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent) error {
+func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent, toolTokens map[string]int64, started time.Time, inputFlush *toolInputThrottle) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -1724,15 +1977,19 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 			a.messages.PublishPart(sessionID, assistantMsg.ID, tc)
 		}
 		return a.messages.Update(ctx, *assistantMsg)
-	// TODO: see how to handle this
-	// case provider.EventToolUseDelta:
-	// 	tm := time.Unix(assistantMsg.UpdatedAt, 0)
-	// 	assistantMsg.AppendToolCallInput(event.ToolCall.ID, event.ToolCall.Input)
-	// 	if time.Since(tm) > 1000*time.Millisecond {
-	// 		err := a.messages.Update(ctx, *assistantMsg)
-	// 		assistantMsg.UpdatedAt = time.Now().Unix()
-	// 		return err
-	// 	}
+	case provider.EventToolUseDelta:
+		// Large write/patch payloads stream for a long time; persist the
+		// partial input at most every toolInputFlushInterval so the TUI
+		// and part subscribers can follow it without a store write per
+		// delta. EventToolUseStop persists the complete input.
+		assistantMsg.AppendToolCallInput(event.ToolCall.ID, event.ToolCall.Input)
+		if !inputFlush.due(event.ToolCall.ID) {
+			return nil
+		}
+		if tc, ok := assistantMsg.FindToolCall(event.ToolCall.ID); ok {
+			a.messages.PublishPart(sessionID, assistantMsg.ID, tc)
+		}
+		return a.messages.Update(ctx, *assistantMsg)
 	case provider.EventToolUseStop:
 		assistantMsg.FinishToolCall(event.ToolCall.ID)
 		// "running" — provider finished assembling the call, tool execution
@@ -1741,6 +1998,10 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 			a.messages.PublishPart(sessionID, assistantMsg.ID, tc)
 		}
 		return a.messages.Update(ctx, *assistantMsg)
+	case provider.EventWarning:
+		// Retry notices: keep them on the status bar until the retry fires.
+		logging.WarnPersist(event.Content, logging.PersistTimeArg, event.RetryAfter+100*time.Millisecond)
+		return nil
 	case provider.EventError:
 		if errors.Is(event.Error, context.Canceled) {
 			logging.InfoPersist(fmt.Sprintf("Event processing canceled for session: %s", sessionID))
@@ -1764,6 +2025,10 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		if len(event.Response.Reasoning) > 0 {
 			assistantMsg.SetReasoningParts(event.Response.Reasoning)
 		}
+		images := a.saveImages(sessionID, event.Response.Images)
+		for _, image := range images {
+			assistantMsg.AddImage(image)
+		}
 		assistantMsg.AddFinish(event.Response.FinishReason)
 		if err := a.messages.Update(ctx, *assistantMsg); err != nil {
 			return fmt.Errorf("failed to update message: %w", err)
@@ -1782,12 +2047,40 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		for _, tc := range assistantMsg.ToolCalls() {
 			a.messages.PublishPart(sessionID, assistantMsg.ID, tc)
 		}
+		for _, image := range images {
+			a.messages.PublishPart(sessionID, assistantMsg.ID, image)
+		}
+		a.recordUsage(ctx, sessionID, a.agentID, a.provider.Model(), event.Response.Usage, toolTokens, assistantMsg.ID, time.Since(started))
 		return a.TrackUsage(ctx, sessionID, a.provider.Model(), event.Response.Usage)
 	}
 
 	return nil
 }
 
+// saveImages stores the images a response produced under
+// <data dir>/images/<session id>. An image that can't be saved is logged
+// and left out; the rest of the response still counts.
+func (a *agent) saveImages(sessionID string, images []message.BinaryContent) []message.ImageContent {
+	if len(images) == 0 {
+		return nil
+	}
+	dataDir := config.Get().Data.Directory
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(config.WorkingDirectory(), dataDir)
+	}
+	dir := filepath.Join(dataDir, "images", sessionID)
+	saved := make([]message.ImageContent, 0, len(images))
+	for _, image := range images {
+		part, err := message.SaveImage(dir, image.MIMEType, image.Data)
+		if err != nil {
+			logging.Warn("Failed to save generated image", "session_id", sessionID, "error", err)
+			continue
+		}
+		saved = append(saved, part)
+	}
+	return saved
+}
+
 func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.Model, usage provider.TokenUsage) error {
 	sess, err := a.sessions.Get(ctx, sessionID)
 	if err != nil {
@@ -1798,6 +2091,9 @@ func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.M
 	cost := inputCost + outputCost
 
 	sess.Cost += cost
+	// PromptTokens and CompletionTokens hold the size of the latest request,
+	// which is what the context window check needs; the totals add up. The
+	// per-request rows written by recordUsage are the durable record.
 	sess.CompletionTokens = usage.OutputTokens + usage.CacheReadTokens
 	sess.PromptTokens = usage.InputTokens + usage.CacheCreationTokens
 	sess.TotalCompletionTokens += usage.OutputTokens
@@ -1808,6 +2104,7 @@ func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.M
 		"token_in_total", sess.PromptTokens,
 		"token_in", usage.InputTokens,
 		"token_out", usage.OutputTokens,
+		"token_reasoning", usage.ReasoningTokens,
 		"cache_created", usage.CacheCreationTokens,
 		"cache_read", usage.CacheReadTokens,
 		"cost", cost,
@@ -1817,7 +2114,15 @@ func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.M
 	if err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
-	return nil
+	usageBroker.Publish(pubsub.UpdatedEvent, UsageEvent{
+		SessionID:     sessionID,
+		AgentID:       a.agentID,
+		Model:         model,
+		Usage:         usage,
+		Cost:          cost,
+		ContextTokens: sess.PromptTokens + sess.CompletionTokens,
+	})
+	return a.checkBudget(ctx, sess)
 }
 
 func (a *agent) Update(agentName config.AgentName, modelID models.ModelID) (models.Model, error) {
@@ -1907,6 +2212,19 @@ func isEmptyUserTextMessage(m message.Message) bool {
 
 // This reduces context size by excluding messages before the summary.
 // It ensures that tool_use/tool_result pairs are not split by the filter boundary.
+// loadHistory reads the session's messages from its summary on, so long
+// sessions do not load what the summary already replaced. Without a summary,
+// or when it is gone, the whole history is read.
+func (a *agent) loadHistory(ctx context.Context, sess session.Session) ([]message.Message, error) {
+	if sess.SummaryMessageID != "" {
+		msgs, err := a.messages.ListFrom(ctx, sess.ID, sess.SummaryMessageID)
+		if !errors.Is(err, message.ErrMessageNotFound) {
+			return msgs, err
+		}
+	}
+	return a.messages.List(ctx, sess.ID)
+}
+
 func (a *agent) filterMessagesFromSummary(msgs []message.Message, summaryMessageID string) []message.Message {
 	if summaryMessageID == "" {
 		return msgs
@@ -1985,6 +2303,7 @@ func (a *agent) performSynchronousCompaction(ctx context.Context, sessionID stri
 	}
 
 	msgsWithPrompt := append(msgs, promptMsg)
+	started := time.Now()
 	events := a.summarizeProvider.StreamResponse(
 		summarizeCtx,
 		msgsWithPrompt,
@@ -2015,6 +2334,9 @@ func (a *agent) performSynchronousCompaction(ctx context.Context, sessionID stri
 	if err != nil {
 		return fmt.Errorf("failed to create summary message: %w", err)
 	}
+	a.keepPinned(summarizeCtx, oldSession.ID, msgs, oldSession.SummaryMessageID)
+	a.keepTodos(summarizeCtx, oldSession.ID)
+	a.retitleInBackground(oldSession.ID, summary)
 
 	oldSession.SummaryMessageID = msg.ID
 	oldSession.CompletionTokens = response.Usage.OutputTokens
@@ -2023,6 +2345,7 @@ func (a *agent) performSynchronousCompaction(ctx context.Context, sessionID stri
 	oldSession.TotalPromptTokens += response.Usage.InputTokens + response.Usage.CacheCreationTokens + response.Usage.CacheReadTokens
 	inCost, outCost := provider.CalculateCost(a.summarizeProvider.Model(), response.Usage)
 	oldSession.Cost += inCost + outCost
+	a.recordUsage(summarizeCtx, oldSession.ID, config.AgentSummarizer, a.summarizeProvider.Model(), response.Usage, nil, msg.ID, time.Since(started))
 
 	_, err = a.sessions.Save(summarizeCtx, oldSession)
 	if err != nil {
@@ -2124,6 +2447,7 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 
 		// Send the messages to the summarize provider via streaming
 		// to avoid Anthropic's non-streaming timeout restriction
+		started := time.Now()
 		events := a.summarizeProvider.StreamResponse(
 			summarizeCtx,
 			msgsWithPrompt,
@@ -2189,6 +2513,9 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			a.Publish(pubsub.CreatedEvent, event)
 			return
 		}
+		a.keepPinned(summarizeCtx, oldSession.ID, msgs, oldSession.SummaryMessageID)
+		a.keepTodos(summarizeCtx, oldSession.ID)
+		a.retitleInBackground(oldSession.ID, summary)
 		oldSession.SummaryMessageID = msg.ID
 		oldSession.CompletionTokens = response.Usage.OutputTokens
 		oldSession.PromptTokens = 0
@@ -2196,6 +2523,7 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 		oldSession.TotalPromptTokens += response.Usage.InputTokens + response.Usage.CacheCreationTokens + response.Usage.CacheReadTokens
 		inCost, outCost := provider.CalculateCost(a.summarizeProvider.Model(), response.Usage)
 		oldSession.Cost += inCost + outCost
+		a.recordUsage(summarizeCtx, oldSession.ID, config.AgentSummarizer, a.summarizeProvider.Model(), response.Usage, nil, msg.ID, time.Since(started))
 		_, err = a.sessions.Save(summarizeCtx, oldSession)
 		if err != nil {
 			event = AgentEvent{
@@ -2257,10 +2585,21 @@ type providerOptions struct {
 	// it), so — exactly like `interactive` — this presence bit must be
 	// threaded through explicitly.
 	hasOutputSchema bool
+	// model replaces the agent's configured model and its fallbacks;
+	// failover uses it to build the provider for the next model in line.
+	model models.ModelID
 }
 
 type providerOption func(*providerOptions)
 
+func (o providerOptions) promptOptions() prompt.AgentPromptOptions {
+	return prompt.AgentPromptOptions{
+		Interactive:     o.interactive,
+		BoundPeers:      o.boundPeers,
+		HasOutputSchema: o.hasOutputSchema,
+	}
+}
+
 func withDisableCache() providerOption {
 	return func(o *providerOptions) {
 		o.disableCache = true
@@ -2294,6 +2633,14 @@ func withHasOutputSchema(b bool) providerOption {
 	}
 }
 
+func withModel(id models.ModelID) providerOption {
+	return func(o *providerOptions) {
+		o.model = id
+	}
+}
+
+// createAgentProvider builds the provider for the agent's model. When that
+// fails, the agent's fallback models are tried in order.
 func createAgentProvider(agentName config.AgentName, providerOpts ...providerOption) (agentProvider provider.Provider, err error) {
 	var popts providerOptions
 	for _, o := range providerOpts {
@@ -2311,9 +2658,11 @@ func createAgentProvider(agentName config.AgentName, providerOpts ...providerOpt
 		reg := agentregistry.GetRegistry()
 		if info, found := reg.Get(agentName); found && info.Model != "" {
 			agentConfig = config.Agent{
-				Model:           models.ModelID(info.Model),
-				MaxTokens:       info.MaxTokens,
-				ReasoningEffort: info.ReasoningEffort,
+				Model:                models.ModelID(info.Model),
+				FallbackModels:       modelIDs(info.FallbackModels),
+				MaxTokens:            info.MaxTokens,
+				ReasoningEffort:      info.ReasoningEffort,
+				ThinkingBudgetTokens: info.ThinkingBudgetTokens,
 			}
 		} else if found {
 			// Inherit coder's model if no model specified
@@ -2322,17 +2671,40 @@ func createAgentProvider(agentName config.AgentName, providerOpts ...providerOpt
 				return nil, fmt.Errorf("agent %s has no model and coder agent not configured", agentName)
 			}
 			agentConfig = config.Agent{
-				Model:           coderCfg.Model,
-				MaxTokens:       coderCfg.MaxTokens,
-				ReasoningEffort: coderCfg.ReasoningEffort,
+				Model:                coderCfg.Model,
+				FallbackModels:       coderCfg.FallbackModels,
+				MaxTokens:            coderCfg.MaxTokens,
+				ReasoningEffort:      coderCfg.ReasoningEffort,
+				ThinkingBudgetTokens: coderCfg.ThinkingBudgetTokens,
 			}
 		} else {
 			return nil, fmt.Errorf("agent %s not found", agentName)
 		}
 	}
-	model, ok := models.SupportedModels[agentConfig.Model]
+
+	if popts.model != "" {
+		return newModelProvider(agentName, agentConfig, popts.model, popts)
+	}
+	var errs []error
+	for i, id := range append([]models.ModelID{agentConfig.Model}, agentConfig.FallbackModels...) {
+		if i > 0 {
+			logging.Warn("Agent model unavailable, trying fallback model", "agent", agentName, "fallback", id, "error", errs[len(errs)-1])
+		}
+		agentProvider, err = newModelProvider(agentName, agentConfig, id, popts)
+		if err == nil {
+			return agentProvider, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// newModelProvider builds the provider for one model of the agent.
+func newModelProvider(agentName config.AgentName, agentConfig config.Agent, modelID models.ModelID, popts providerOptions) (provider.Provider, error) {
+	cfg := config.Get()
+	model, ok := models.SupportedModels[modelID]
 	if !ok {
-		return nil, fmt.Errorf("model %s not supported", agentConfig.Model)
+		return nil, fmt.Errorf("model %s not supported", modelID)
 	}
 
 	providerCfg, ok := cfg.Providers[model.Provider]
@@ -2350,11 +2722,7 @@ func createAgentProvider(agentName config.AgentName, providerOpts ...providerOpt
 	opts := []provider.ProviderClientOption{
 		provider.WithAPIKey(providerCfg.APIKey),
 		provider.WithModel(model),
-		provider.WithSystemMessage(prompt.GetAgentPromptWithOptions(agentName, model.Provider, prompt.AgentPromptOptions{
-			Interactive:     popts.interactive,
-			BoundPeers:      popts.boundPeers,
-			HasOutputSchema: popts.hasOutputSchema,
-		})),
+		provider.WithSystemMessage(prompt.GetAgentPromptWithOptions(agentName, model.Provider, popts.promptOptions())),
 		provider.WithMaxTokens(maxTokens),
 	}
 	if providerCfg.BaseURL != "" {
@@ -2366,13 +2734,34 @@ func createAgentProvider(agentName config.AgentName, providerOpts ...providerOpt
 	if providerCfg.Metadata != nil {
 		opts = append(opts, provider.WithMetadata(providerCfg.Metadata))
 	}
+	if providerCfg.Retry != nil {
+		opts = append(opts, provider.WithRetryPolicy(provider.RetryPolicyFromConfig(providerCfg.Retry)))
+	}
+	if model.Provider == models.ProviderAnthropic || model.Provider == models.ProviderOpenAI {
+		opts = append(opts, provider.WithBatchPolicy(provider.BatchPolicyFromConfig(providerCfg.Batch)))
+	}
 	if lf := langfuse.Get(); lf != nil && lf.Enabled() {
 		opts = append(opts, provider.WithLangfuse(lf))
 	}
 
-	if model.Provider == models.ProviderOpenAI || model.Provider == models.ProviderYandexCloud || model.Provider == models.ProviderLocal && model.CanReason {
+	reasoningEffort := agentConfig.ReasoningEffort
+	if strings.EqualFold(reasoningEffort, config.ReasoningEffortAuto) {
+		// Only the fallback for requests the agent did not classify; see
+		// turnReasoningEffort.
+		reasoningEffort = "medium"
+	}
+	thinkingBudget := agentConfig.ThinkingBudgetTokens
+	if thinkingBudget > 0 {
+		// Markdown agents don't go through config validation.
+		thinkingBudget = max(thinkingBudget, config.MinThinkingBudgetTokens)
+		if reasoningEffort == "" {
+			reasoningEffort = config.ReasoningEffortForBudget(thinkingBudget)
+		}
+	}
+
+	if model.Provider == models.ProviderOpenAI || model.Provider == models.ProviderYandexCloud || model.Provider == models.ProviderCopilot || model.Provider == models.ProviderLocal && model.CanReason {
 		openaiOpts := []provider.OpenAIOption{
-			provider.WithReasoningEffort(agentConfig.ReasoningEffort),
+			provider.WithReasoningEffort(reasoningEffort),
 		}
 		if model.UseLegacyMaxTokens {
 			openaiOpts = append(openaiOpts, provider.WithLegacyMaxTokens())
@@ -2389,7 +2778,9 @@ func createAgentProvider(agentName config.AgentName, providerOpts ...providerOpt
 		if model.CanReason {
 			anthropicOpts = append(anthropicOpts, provider.WithAnthropicShouldThinkFn(provider.DefaultShouldThinkFn))
 			if model.SupportsAdaptiveThinking {
-				anthropicOpts = append(anthropicOpts, provider.WithAnthropicReasoningEffort(agentConfig.ReasoningEffort))
+				anthropicOpts = append(anthropicOpts, provider.WithAnthropicReasoningEffort(reasoningEffort))
+			} else if thinkingBudget > 0 {
+				anthropicOpts = append(anthropicOpts, provider.WithAnthropicThinkingBudget(thinkingBudget))
 			}
 			if agentConfig.TaskBudget > 0 && model.SupportsTaskBudget {
 				anthropicOpts = append(anthropicOpts, provider.WithAnthropicTaskBudget(agentConfig.TaskBudget))
@@ -2401,11 +2792,25 @@ func createAgentProvider(agentName config.AgentName, providerOpts ...providerOpt
 		if len(anthropicOpts) > 0 {
 			opts = append(opts, provider.WithAnthropicOptions(anthropicOpts...))
 		}
-	} else if model.Provider == models.ProviderGemini && popts.disableCache {
-		opts = append(opts, provider.WithGeminiOptions(provider.WithGeminiDisableCache()))
+	} else if model.Provider == models.ProviderGemini {
+		var geminiOpts []provider.GeminiOption
+		if model.CanReason && thinkingBudget > 0 {
+			geminiOpts = append(geminiOpts, provider.WithGeminiThinkingBudget(thinkingBudget))
+		}
+		if popts.disableCache {
+			geminiOpts = append(geminiOpts, provider.WithGeminiDisableCache())
+		}
+		if len(geminiOpts) > 0 {
+			opts = append(opts, provider.WithGeminiOptions(geminiOpts...))
+		}
+	} else if model.Provider == models.ProviderOllama {
+		opts = append(opts, provider.WithOllamaOptions(
+			provider.WithOllamaKeepAlive(providerCfg.KeepAlive),
+			provider.WithOllamaNumCtx(providerCfg.NumCtx),
+		))
 	}
 
-	agentProvider, err = provider.NewProvider(
+	agentProvider, err := provider.NewProvider(
 		model.Provider,
 		opts...,
 	)
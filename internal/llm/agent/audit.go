@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// auditToolCall records the outcome of a tool call in the audit trail,
+// taking status, exit code and duration from the result's metadata
+// envelope and the permission decision from the call's recorder.
+func (a *agent) auditToolCall(sessionID string, call message.ToolCall, meta tools.ResultMetadata, decisions *permission.DecisionRecorder) {
+	if !audit.Enabled() {
+		return
+	}
+	decision, via := decisions.Result()
+	if decision == "" && meta.Status == tools.ResultStatusDenied {
+		// Denied before any prompt: a deny rule or a PreToolUse hook.
+		decision, via = "deny", "rule"
+	}
+	audit.Record(audit.Entry{
+		Kind:       audit.KindToolCall,
+		SessionID:  sessionID,
+		AgentID:    string(a.agentID),
+		Tool:       call.Name,
+		CallID:     call.ID,
+		Input:      call.Input,
+		Status:     meta.Status,
+		ExitCode:   meta.ExitCode,
+		DurationMs: meta.DurationMs,
+		Decision:   decision,
+		Via:        via,
+	})
+}
+
+// auditProviderRequest records a digest of the conversation and tool set
+// about to be sent to the provider. The content itself stays in the
+// session store; the digest ties the trail to it.
+func (a *agent) auditProviderRequest(sessionID string, msgHistory []message.Message, toolSet []tools.BaseTool) {
+	if !audit.Enabled() {
+		return
+	}
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, msg := range msgHistory {
+		_ = enc.Encode(struct {
+			ID    string
+			Role  message.MessageRole
+			Parts []message.ContentPart
+		}{msg.ID, msg.Role, msg.Parts})
+	}
+	for _, t := range toolSet {
+		_ = enc.Encode(t.Info())
+	}
+	audit.Record(audit.Entry{
+		Kind:        audit.KindProviderRequest,
+		SessionID:   sessionID,
+		AgentID:     string(a.agentID),
+		Model:       string(a.provider.Model().ID),
+		RequestHash: hex.EncodeToString(h.Sum(nil)),
+	})
+}
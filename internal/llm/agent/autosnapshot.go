@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/gitsnapshot"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// autoSnapshotTimeout bounds recording the work tree, which blocks the
+// first mutating tool call of a run.
+const autoSnapshotTimeout = 30 * time.Second
+
+// runSnapshot records the work tree at most once per run, under the ID of
+// the session the run was started for. Subagents inherit it through the
+// context, so /restore on the parent session also undoes their changes.
+type runSnapshot struct {
+	sessionID string
+	once      sync.Once
+}
+
+type runSnapshotKey struct{}
+
+// withRunSnapshot arms the autoSnapshot git snapshot for a run. A context
+// that already carries one, i.e. a subagent run, keeps its parent's.
+func withRunSnapshot(ctx context.Context, sessionID string) context.Context {
+	cfg := config.Get()
+	if cfg == nil || !cfg.AutoSnapshot {
+		return ctx
+	}
+	if _, ok := ctx.Value(runSnapshotKey{}).(*runSnapshot); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, runSnapshotKey{}, &runSnapshot{sessionID: sessionID})
+}
+
+// inheritRunSnapshot copies the run snapshot of parent onto ctx, for
+// subagents that run on a context detached from their caller's.
+func inheritRunSnapshot(ctx, parent context.Context) context.Context {
+	if rs, ok := parent.Value(runSnapshotKey{}).(*runSnapshot); ok {
+		return context.WithValue(ctx, runSnapshotKey{}, rs)
+	}
+	return ctx
+}
+
+// snapshotsWorktree reports whether a tool call may change files on disk.
+// bash is included since any command might.
+func snapshotsWorktree(toolName string) bool {
+	return tools.IsMutatingTool(toolName) ||
+		toolName == tools.BashToolName ||
+		toolName == tools.NotebookEditToolName
+}
+
+// snapshotBeforeMutation records the work tree before the first tool call of
+// the run that may change it. Failing to record it is logged and does not
+// hold up the tool call.
+func snapshotBeforeMutation(ctx context.Context, calls []message.ToolCall) {
+	rs, ok := ctx.Value(runSnapshotKey{}).(*runSnapshot)
+	if !ok {
+		return
+	}
+	mutating := false
+	for _, call := range calls {
+		if snapshotsWorktree(call.Name) {
+			mutating = true
+			break
+		}
+	}
+	if !mutating {
+		return
+	}
+	rs.once.Do(func() {
+		snapCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), autoSnapshotTimeout)
+		defer cancel()
+		commit, err := gitsnapshot.Take(snapCtx, config.WorkingDirectory(), rs.sessionID)
+		if err != nil {
+			logging.Warn("Failed to record git snapshot before file changes", "session_id", rs.sessionID, "error", err)
+			return
+		}
+		logging.Debug("Recorded git snapshot before file changes", "session_id", rs.sessionID, "commit", commit)
+	})
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/gitsnapshot"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func withAutoSnapshotRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	require.NoError(t, cmd.Run())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("v1"), 0o644))
+
+	if config.Get() == nil {
+		_, err := config.Load(t.TempDir(), false)
+		require.NoError(t, err)
+	}
+	cfg := config.Get()
+	prevWD, prevSnap := cfg.WorkingDir, cfg.AutoSnapshot
+	cfg.WorkingDir, cfg.AutoSnapshot = dir, true
+	t.Cleanup(func() { cfg.WorkingDir, cfg.AutoSnapshot = prevWD, prevSnap })
+	return dir
+}
+
+// The snapshot is taken once per run, before the first call that may
+// change files, and shared with subagents.
+func TestSnapshotBeforeMutation(t *testing.T) {
+	dir := withAutoSnapshotRepo(t)
+	ctx := withRunSnapshot(context.Background(), "root")
+
+	snapshotBeforeMutation(ctx, []message.ToolCall{{Name: "view"}, {Name: "grep"}})
+	_, err := gitsnapshot.Restore(context.Background(), dir, "root")
+	require.ErrorIs(t, err, gitsnapshot.ErrNoSnapshot)
+
+	snapshotBeforeMutation(ctx, []message.ToolCall{{Name: "view"}, {Name: "edit"}})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("v2"), 0o644))
+
+	// A subagent run keeps the parent's snapshot rather than taking its own.
+	sub := withRunSnapshot(ctx, "child")
+	snapshotBeforeMutation(sub, []message.ToolCall{{Name: "bash"}})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("v3"), 0o644))
+
+	_, err = gitsnapshot.Restore(context.Background(), dir, "child")
+	require.ErrorIs(t, err, gitsnapshot.ErrNoSnapshot)
+	_, err = gitsnapshot.Restore(context.Background(), dir, "root")
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+}
+
+func TestWithRunSnapshotDisabled(t *testing.T) {
+	withAutoSnapshotRepo(t)
+	config.Get().AutoSnapshot = false
+	ctx := withRunSnapshot(context.Background(), "s")
+	_, ok := ctx.Value(runSnapshotKey{}).(*runSnapshot)
+	require.False(t, ok)
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBranchCancelled is the cancellation cause of a task-tool subagent or
+// flow step stopped with CancelBranch. Unlike Service.Cancel, which stops
+// the whole run of a session, only that branch ends; its parent keeps
+// running and sees the branch fail with this error.
+var ErrBranchCancelled = errors.New("cancelled by user")
+
+type branch struct {
+	cancel context.CancelCauseFunc
+}
+
+// branches maps the session a branch runs in to its cancel func.
+var branches sync.Map
+
+// TrackBranch derives a context for the work running in sessionID that
+// CancelBranch can cancel on its own. release must be called when the
+// branch finishes.
+func TrackBranch(ctx context.Context, sessionID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	b := &branch{cancel: cancel}
+	branches.Store(sessionID, b)
+	return ctx, func() {
+		branches.CompareAndDelete(sessionID, b)
+		cancel(nil)
+	}
+}
+
+// CancelBranch cancels the subagent or flow step running in sessionID. It
+// reports false when no branch is running in that session.
+func CancelBranch(sessionID string) bool {
+	v, ok := branches.LoadAndDelete(sessionID)
+	if !ok {
+		return false
+	}
+	v.(*branch).cancel(ErrBranchCancelled)
+	return true
+}
+
+// IsBranchRunning reports whether sessionID runs a branch that
+// CancelBranch can stop.
+func IsBranchRunning(sessionID string) bool {
+	_, ok := branches.Load(sessionID)
+	return ok
+}
+
+// BranchCancelled reports whether ctx was cancelled through CancelBranch.
+func BranchCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrBranchCancelled)
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCancelBranch(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	defer cancelParent()
+
+	ctx, release := TrackBranch(parent, "branch-session")
+	if !IsBranchRunning("branch-session") {
+		t.Fatal("IsBranchRunning() = false after TrackBranch")
+	}
+	if !CancelBranch("branch-session") {
+		t.Fatal("CancelBranch() = false for a tracked branch")
+	}
+	if ctx.Err() == nil || !BranchCancelled(ctx) {
+		t.Fatalf("branch ctx cause = %v, want ErrBranchCancelled", context.Cause(ctx))
+	}
+	if parent.Err() != nil {
+		t.Fatal("cancelling the branch cancelled its parent")
+	}
+	release()
+	if CancelBranch("branch-session") {
+		t.Fatal("CancelBranch() = true for a branch that was already cancelled")
+	}
+}
+
+func TestReleaseBranch(t *testing.T) {
+	ctx, release := TrackBranch(context.Background(), "released-session")
+	release()
+	if IsBranchRunning("released-session") {
+		t.Fatal("IsBranchRunning() = true after release")
+	}
+	if CancelBranch("released-session") {
+		t.Fatal("CancelBranch() = true after release")
+	}
+	if BranchCancelled(ctx) {
+		t.Fatal("BranchCancelled() = true for a branch that finished normally")
+	}
+
+	// A stale release must not untrack a newer branch in the same session.
+	_, releaseOld := TrackBranch(context.Background(), "reused-session")
+	_, releaseNew := TrackBranch(context.Background(), "reused-session")
+	defer releaseNew()
+	releaseOld()
+	if !IsBranchRunning("reused-session") {
+		t.Fatal("stale release untracked the newer branch")
+	}
+}
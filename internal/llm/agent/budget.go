@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// ErrBudgetExceeded matches every *BudgetExceededError via errors.Is.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget scopes, from narrowest to widest.
+const (
+	BudgetScopeAgent   = "agent"
+	BudgetScopeSession = "session"
+	BudgetScopeGlobal  = "global"
+)
+
+// BudgetExceededError reports the limit that stopped a run.
+type BudgetExceededError struct {
+	Scope string
+	// Exactly one of the limit pairs is set, depending on which was hit.
+	MaxCostUSD   float64
+	SpentCostUSD float64
+	MaxTokens    int64
+	SpentTokens  int64
+}
+
+func (e *BudgetExceededError) Error() string {
+	if e.MaxTokens > 0 {
+		return fmt.Sprintf("%s budget exceeded: %d tokens used of %d allowed", e.Scope, e.SpentTokens, e.MaxTokens)
+	}
+	return fmt.Sprintf("%s budget exceeded: $%.4f spent of $%.2f allowed", e.Scope, e.SpentCostUSD, e.MaxCostUSD)
+}
+
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+func checkLimits(scope string, limits *config.BudgetLimits, usage session.Usage) error {
+	if limits.IsZero() {
+		return nil
+	}
+	if limits.MaxCostUSD > 0 && usage.Cost >= limits.MaxCostUSD {
+		return &BudgetExceededError{Scope: scope, MaxCostUSD: limits.MaxCostUSD, SpentCostUSD: usage.Cost}
+	}
+	if limits.MaxTokens > 0 && usage.Tokens >= limits.MaxTokens {
+		return &BudgetExceededError{Scope: scope, MaxTokens: limits.MaxTokens, SpentTokens: usage.Tokens}
+	}
+	return nil
+}
+
+// checkBudget enforces the agent, session tree and global limits against
+// sess, which must carry the totals recorded so far. Limits are checked
+// narrowest first so the error names the most specific budget. A failed
+// usage lookup is logged and skipped rather than failing the run.
+func (a *agent) checkBudget(ctx context.Context, sess session.Session) error {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+	if agentCfg, ok := cfg.Agents[a.agentID]; ok {
+		own := session.Usage{Cost: sess.Cost, Tokens: sess.TotalPromptTokens + sess.TotalCompletionTokens}
+		if err := checkLimits(BudgetScopeAgent, agentCfg.Budget, own); err != nil {
+			return err
+		}
+	}
+	if cfg.Budget == nil {
+		return nil
+	}
+	if !cfg.Budget.Session.IsZero() {
+		usage, err := a.sessions.TreeUsage(ctx, sess.ID)
+		if err != nil {
+			logging.Warn("Failed to compute session usage, skipping session budget", "session_id", sess.ID, "error", err)
+		} else if err := checkLimits(BudgetScopeSession, cfg.Budget.Session, usage); err != nil {
+			return err
+		}
+	}
+	if !cfg.Budget.Global.IsZero() {
+		usage, err := a.sessions.ProjectUsage(ctx)
+		if err != nil {
+			logging.Warn("Failed to compute project usage, skipping global budget", "error", err)
+		} else if err := checkLimits(BudgetScopeGlobal, cfg.Budget.Global, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *agent) checkSessionBudget(ctx context.Context, sessionID string) error {
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	return a.checkBudget(ctx, sess)
+}
+
+func (a *agent) budgetExceeded(sessionID string, msg message.Message, err error) AgentEvent {
+	return AgentEvent{
+		Type:      AgentEventTypeBudgetExceeded,
+		Message:   msg,
+		Error:     err,
+		SessionID: sessionID,
+		Done:      true,
+	}
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+func withAgentBudget(t *testing.T, limits *config.BudgetLimits) {
+	t.Helper()
+	cfg := config.Get()
+	prev, had := cfg.Agents["coder"]
+	next := prev
+	next.Budget = limits
+	cfg.Agents["coder"] = next
+	t.Cleanup(func() {
+		if had {
+			cfg.Agents["coder"] = prev
+		} else {
+			delete(cfg.Agents, "coder")
+		}
+	})
+}
+
+// A turn that pushes the session over its token budget must stop the run
+// before the tool calls it requested execute or another turn is requested.
+func TestProcessGeneration_StopsWhenTurnExceedsBudget(t *testing.T) {
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(int) *provider.ProviderResponse {
+		return &provider.ProviderResponse{
+			ToolCalls:    []message.ToolCall{{ID: "call-1", Name: "ls", Input: `{}`, Finished: true}},
+			FinishReason: message.FinishReasonToolUse,
+			Usage:        provider.TokenUsage{InputTokens: 900, OutputTokens: 200},
+		}
+	}}
+	a := newLoopAgent(t, p)
+	withAgentBudget(t, &config.BudgetLimits{MaxTokens: 1000})
+
+	res := a.processGeneration(context.Background(), "sess-budget", "list files", 0, nil, RunOptions{})
+
+	if res.Type != AgentEventTypeBudgetExceeded {
+		t.Fatalf("event type = %q (err %v), want %q", res.Type, res.Error, AgentEventTypeBudgetExceeded)
+	}
+	var budgetErr *BudgetExceededError
+	if !errors.As(res.Error, &budgetErr) || budgetErr.Scope != BudgetScopeAgent || budgetErr.SpentTokens != 1100 {
+		t.Fatalf("error = %v, want agent budget exceeded at 1100 tokens", res.Error)
+	}
+	if !errors.Is(res.Error, ErrBudgetExceeded) {
+		t.Error("error does not match ErrBudgetExceeded")
+	}
+	if got := p.callCount(); got != 1 {
+		t.Errorf("provider calls = %d, want 1", got)
+	}
+}
+
+// A session that is already over budget must not reach the provider at all.
+func TestProcessGeneration_RefusesToStartOverBudget(t *testing.T) {
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(int) *provider.ProviderResponse { return endTurn() }}
+	a := newLoopAgent(t, p)
+	withAgentBudget(t, &config.BudgetLimits{MaxCostUSD: 0.5})
+	if _, err := a.sessions.Save(context.Background(), session.Session{ID: "sess-spent", Cost: 0.75}); err != nil {
+		t.Fatal(err)
+	}
+
+	res := a.processGeneration(context.Background(), "sess-spent", "hello", 0, nil, RunOptions{})
+
+	if res.Type != AgentEventTypeBudgetExceeded || !errors.Is(res.Error, ErrBudgetExceeded) {
+		t.Fatalf("result = %q / %v, want budget exceeded", res.Type, res.Error)
+	}
+	if got := p.callCount(); got != 0 {
+		t.Errorf("provider calls = %d, want 0", got)
+	}
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/opencode-ai/opencode/internal/citation"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// annotateCitations links the file references and quoted code in the run's
+// final response to the tool results they came from and stores the links
+// on the message. It runs after translation so the recorded offsets match
+// the text the user actually sees.
+func (a *agent) annotateCitations(ctx context.Context, sessionID string, result *AgentEvent) {
+	if result.Type != AgentEventTypeResponse || result.StructOutput != nil || result.Message.ID == "" {
+		return
+	}
+	cfg := config.Get()
+	text := result.Message.Content().Text
+	if cfg == nil || text == "" {
+		return
+	}
+	msgs, err := a.messages.List(ctx, sessionID)
+	if err != nil {
+		logging.Debug("Failed to load messages for citations", "session_id", sessionID, "error", err)
+		return
+	}
+	cites := citation.Extract(text, citation.Collect(msgs, cfg.WorkingDir), cfg.WorkingDir)
+	if len(cites) == 0 {
+		return
+	}
+	result.Message.SetCitations(cites)
+	if err := a.messages.Update(ctx, result.Message); err != nil {
+		logging.Warn("Failed to persist response citations", "session_id", sessionID, "error", err)
+	}
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// contentFilterNote stands in for a response the content filter blocked
+// before any of it was written, which would otherwise show up as an empty
+// answer.
+const contentFilterNote = "The provider's content filter blocked this response."
+
+// contentFilterRephrase asks the model to answer again after a blocked
+// response.
+const contentFilterRephrase = "Your previous response was blocked by the provider's content filter. " +
+	"Answer again in a way that stays within its policy: don't reproduce long passages of existing text verbatim, " +
+	"and leave personal data and credentials out. If the request can't be answered that way, say so briefly."
+
+// contentFilterStrategy returns the configured retry strategy and the
+// number of retries one turn may make.
+func contentFilterStrategy() (string, int) {
+	strategy, maxRetries := config.ContentFilterRetryNone, 1
+	if cfg := config.Get(); cfg != nil && cfg.ContentFilter != nil {
+		if cfg.ContentFilter.Retry != "" {
+			strategy = cfg.ContentFilter.Retry
+		}
+		if cfg.ContentFilter.MaxRetries > 0 {
+			maxRetries = cfg.ContentFilter.MaxRetries
+		}
+	}
+	return strategy, maxRetries
+}
+
+// handleContentFilter deals with a response the provider's content filter
+// blocked: an empty one gets a note saying so, the event goes to the log
+// and the audit trail, and the configured retry is made unless the turn
+// has used up its retries. It returns the history to retry with and
+// whether to retry; retries counts the turn's retries so far.
+func (a *agent) handleContentFilter(ctx context.Context, sessionID string, blocked *message.Message, msgHistory []message.Message, retries *int) ([]message.Message, bool) {
+	model := a.provider.Model()
+	if strings.TrimSpace(blocked.Content().String()) == "" {
+		blocked.SetContent(contentFilterNote)
+		if err := a.messages.Update(ctx, *blocked); err != nil {
+			logging.Warn("Failed to mark blocked response", "session_id", sessionID, "error", err)
+		}
+	}
+
+	strategy, maxRetries := contentFilterStrategy()
+	if *retries >= maxRetries {
+		strategy = config.ContentFilterRetryNone
+	}
+	var next []message.Message
+	switch strategy {
+	case config.ContentFilterRetryRephrase:
+		note, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+			Role:      message.User,
+			Parts:     []message.ContentPart{message.TextContent{Text: contentFilterRephrase}},
+			Synthetic: true,
+		})
+		if err != nil {
+			logging.Warn("Failed to ask for a rephrased response", "session_id", sessionID, "error", err)
+			strategy = config.ContentFilterRetryNone
+			break
+		}
+		next = append(msgHistory, *blocked, note)
+	case config.ContentFilterRetryFallback:
+		_, to, ok := a.switchToFallback()
+		if !ok {
+			strategy = config.ContentFilterRetryNone
+			break
+		}
+		logging.WarnPersist(fmt.Sprintf("%s blocked the response, retrying on %s", model.Name, to.Name))
+		next = msgHistory
+	}
+
+	logging.Warn("Response blocked by the provider's content filter", "agent", a.agentID, "session_id", sessionID, "model", model.ID, "retry", strategy)
+	audit.Record(audit.Entry{
+		Kind:      audit.KindContentFilter,
+		SessionID: sessionID,
+		AgentID:   string(a.agentID),
+		Model:     string(model.ID),
+		Action:    strategy,
+	})
+	if strategy == config.ContentFilterRetryNone {
+		return msgHistory, false
+	}
+	*retries++
+	return next, true
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func withContentFilter(t *testing.T, c *config.ContentFilterConfig) {
+	t.Helper()
+	cfg := config.Get()
+	prev := cfg.ContentFilter
+	cfg.ContentFilter = c
+	t.Cleanup(func() { cfg.ContentFilter = prev })
+}
+
+func blockedTurn() *provider.ProviderResponse {
+	return &provider.ProviderResponse{FinishReason: message.FinishReasonContentFilter}
+}
+
+func TestContentFilterEndsTurnByDefault(t *testing.T) {
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(int) *provider.ProviderResponse { return blockedTurn() }}
+	a := newLoopAgent(t, p)
+	withContentFilter(t, nil)
+
+	res := a.processGeneration(context.Background(), "sess-filter", "quote the whole book", 0, nil, RunOptions{NonInteractive: true})
+
+	require.NoError(t, res.Error)
+	assert.Equal(t, 1, p.callCount())
+	assert.Equal(t, message.FinishReasonContentFilter, res.Message.FinishReason())
+	assert.Equal(t, contentFilterNote, res.Message.Content().String())
+}
+
+func TestContentFilterRephrases(t *testing.T) {
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(call int) *provider.ProviderResponse {
+		if call == 1 {
+			return blockedTurn()
+		}
+		return endTurn()
+	}}
+	a := newLoopAgent(t, p)
+	withContentFilter(t, &config.ContentFilterConfig{Retry: config.ContentFilterRetryRephrase})
+
+	res := a.processGeneration(context.Background(), "sess-rephrase", "quote the whole book", 0, nil, RunOptions{NonInteractive: true})
+
+	require.NoError(t, res.Error)
+	assert.Equal(t, 2, p.callCount())
+	assert.Equal(t, message.FinishReasonEndTurn, res.Message.FinishReason())
+
+	msgs, err := a.messages.List(context.Background(), "sess-rephrase")
+	require.NoError(t, err)
+	var notes int
+	for _, m := range msgs {
+		if m.Synthetic && m.Role == message.User && m.Content().String() == contentFilterRephrase {
+			notes++
+		}
+	}
+	assert.Equal(t, 1, notes)
+}
+
+func TestContentFilterRetriesAreCapped(t *testing.T) {
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(int) *provider.ProviderResponse { return blockedTurn() }}
+	a := newLoopAgent(t, p)
+	withContentFilter(t, &config.ContentFilterConfig{Retry: config.ContentFilterRetryRephrase, MaxRetries: 2})
+
+	res := a.processGeneration(context.Background(), "sess-capped", "quote the whole book", 0, nil, RunOptions{NonInteractive: true})
+
+	require.NoError(t, res.Error)
+	assert.Equal(t, 3, p.callCount())
+	assert.Equal(t, message.FinishReasonContentFilter, res.Message.FinishReason())
+}
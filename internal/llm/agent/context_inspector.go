@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/prompt"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// ContextItemKind classifies a ContextItem.
+type ContextItemKind string
+
+const (
+	ContextItemSystem         ContextItemKind = "system"
+	ContextItemSkill          ContextItemKind = prompt.SectionSkill
+	ContextItemContextFile    ContextItemKind = prompt.SectionContextFile
+	ContextItemContextSnippet ContextItemKind = prompt.SectionContextSnippet
+	ContextItemMemory         ContextItemKind = prompt.SectionMemory
+	ContextItemSummary        ContextItemKind = "summary"
+	ContextItemMessage        ContextItemKind = "message"
+	ContextItemTool           ContextItemKind = "tool"
+)
+
+// ContextItem is one part of the next provider request. An assistant
+// message item covers the tool results that answer it, since the two can
+// only be sent or left out together.
+type ContextItem struct {
+	// ID identifies the item for ExcludeFromNextTurn: "system", or the
+	// kind and the skill name, context file path, message ID or tool name,
+	// e.g. "tool:bash".
+	ID     string          `json:"id"`
+	Kind   ContextItemKind `json:"kind"`
+	Name   string          `json:"name"`
+	Tokens int64           `json:"tokens"`
+	// Excluded is set for items ExcludeFromNextTurn leaves out of the next
+	// request.
+	Excluded bool `json:"excluded"`
+}
+
+// ContextReport lists what the next request of a session will contain.
+// Token counts are estimates; Tokens sums the items that are not excluded.
+type ContextReport struct {
+	SessionID     string         `json:"sessionID"`
+	Model         models.ModelID `json:"model"`
+	ContextWindow int64          `json:"contextWindow"`
+	Tokens        int64          `json:"tokens"`
+	Items         []ContextItem  `json:"items"`
+}
+
+// ErrContextItemRequired is returned for an attempt to exclude the system
+// prompt.
+var ErrContextItemRequired = errors.New("the system prompt cannot be excluded")
+
+func contextItemID(kind ContextItemKind, key string) string {
+	if kind == ContextItemSystem {
+		return string(kind)
+	}
+	return string(kind) + ":" + key
+}
+
+func estimateTextTokens(s string) int64 {
+	return int64(len(s) / message.BytesPerTokenEta)
+}
+
+// InspectContext lists the system prompt sections, messages and tool
+// schemas the next request of sessionID will carry. The new user prompt of
+// that turn is not part of it.
+func (a *agent) InspectContext(ctx context.Context, sessionID string) (ContextReport, error) {
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return ContextReport{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	msgs, err := a.loadHistory(ctx, sess)
+	if err != nil {
+		return ContextReport{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+	excluded, _ := a.contextExclusions.Load(sessionID)
+	isExcluded := func(id string) bool {
+		set, _ := excluded.(map[string]bool)
+		return set[id]
+	}
+
+	model := a.provider.Model()
+	report := ContextReport{SessionID: sessionID, Model: model.ID, ContextWindow: model.ContextWindow}
+	add := func(kind ContextItemKind, key, name string, tokens int64) {
+		id := contextItemID(kind, key)
+		item := ContextItem{ID: id, Kind: kind, Name: name, Tokens: tokens, Excluded: isExcluded(id)}
+		if !item.Excluded {
+			report.Tokens += tokens
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	system := a.systemPrompt()
+	sections := prompt.PromptSections(a.agentID)
+	base := system
+	for _, s := range sections {
+		base = strings.Replace(base, s.Text, "", 1)
+	}
+	add(ContextItemSystem, "", "System prompt", estimateTextTokens(base))
+	for _, s := range sections {
+		add(ContextItemKind(s.Kind), s.Name, s.Name, estimateTextTokens(s.Text))
+	}
+
+	history := a.requestHistory(msgs, sess)
+	for i := 0; i < len(history); i++ {
+		msg := history[i]
+		group := []message.Message{msg}
+		if msg.Role == message.Assistant {
+			for i+1 < len(history) && history[i+1].Role == message.Tool {
+				i++
+				group = append(group, history[i])
+			}
+		}
+		kind := ContextItemMessage
+		if msg.ID == sess.SummaryMessageID {
+			kind = ContextItemSummary
+		}
+		add(kind, msg.ID, describeContextMessage(msg), message.EstimateTokens(group, nil, message.BytesPerTokenEta))
+	}
+
+	for _, t := range a.resolveTools() {
+		name := t.Info().Name
+		add(ContextItemTool, name, name, message.EstimateTokens(nil, []tools.BaseTool{t}, message.BytesPerTokenEta))
+	}
+	return report, nil
+}
+
+// ExcludeFromNextTurn leaves the items with the given IDs out of the next
+// run of sessionID. It replaces earlier exclusions for the session; no IDs
+// clears them.
+func (a *agent) ExcludeFromNextTurn(sessionID string, ids []string) error {
+	if len(ids) == 0 {
+		a.contextExclusions.Delete(sessionID)
+		return nil
+	}
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == string(ContextItemSystem) {
+			return ErrContextItemRequired
+		}
+		set[id] = true
+	}
+	a.contextExclusions.Store(sessionID, set)
+	return nil
+}
+
+// takeContextExclusions returns and clears the exclusions of sessionID.
+func (a *agent) takeContextExclusions(sessionID string) map[string]bool {
+	set, ok := a.contextExclusions.LoadAndDelete(sessionID)
+	if !ok {
+		return nil
+	}
+	return set.(map[string]bool)
+}
+
+// systemPrompt rebuilds the system prompt the agent's provider was created
+// with.
+func (a *agent) systemPrompt() string {
+	var popts providerOptions
+	for _, o := range a.providerOpts {
+		o(&popts)
+	}
+	return prompt.GetAgentPromptWithOptions(a.agentID, a.provider.Model().Provider, popts.promptOptions())
+}
+
+// applyContextExclusions strips excluded items from one run's history, tool
+// set and, through ctx, system prompt.
+func (a *agent) applyContextExclusions(ctx context.Context, excluded map[string]bool, history []message.Message, toolSet []tools.BaseTool) (context.Context, []message.Message, []tools.BaseTool) {
+	if len(excluded) == 0 {
+		return ctx, history, toolSet
+	}
+	var keptMsgs []message.Message
+	for i := 0; i < len(history); i++ {
+		msg := history[i]
+		if !excluded[contextItemID(ContextItemMessage, msg.ID)] && !excluded[contextItemID(ContextItemSummary, msg.ID)] {
+			keptMsgs = append(keptMsgs, msg)
+			continue
+		}
+		if msg.Role == message.Assistant {
+			for i+1 < len(history) && history[i+1].Role == message.Tool {
+				i++
+			}
+		}
+	}
+
+	var keptTools []tools.BaseTool
+	for _, t := range toolSet {
+		if !excluded[contextItemID(ContextItemTool, t.Info().Name)] {
+			keptTools = append(keptTools, t)
+		}
+	}
+
+	system, stripped := a.systemPrompt(), false
+	for _, s := range prompt.PromptSections(a.agentID) {
+		if excluded[contextItemID(ContextItemKind(s.Kind), s.Name)] {
+			system = strings.Replace(system, s.Text, "", 1)
+			stripped = true
+		}
+	}
+	if stripped {
+		ctx = provider.SystemMessageContext(ctx, system)
+	}
+	return ctx, keptMsgs, keptTools
+}
+
+func describeContextMessage(msg message.Message) string {
+	text := strings.TrimSpace(msg.Content().String())
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	if text == "" {
+		var names []string
+		for _, tc := range msg.ToolCalls() {
+			names = append(names, tc.Name)
+		}
+		if len(names) > 0 {
+			text = "calls " + strings.Join(names, ", ")
+		}
+	}
+	role := string(msg.Role)
+	return strings.ToUpper(role[:1]) + role[1:] + ": " + truncateStr(text, 80)
+}
+
+// requestHistory is the part of a session's messages its next request
+// sends.
+func (a *agent) requestHistory(msgs []message.Message, sess session.Session) []message.Message {
+	if sess.SummaryMessageID != "" {
+		msgs = a.filterMessagesFromSummary(msgs, sess.SummaryMessageID)
+	}
+	return filterEmptyUserMessages(msgs)
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedToolExchange(t *testing.T, a *agent, sessionID string) []message.Message {
+	t.Helper()
+	ctx := context.Background()
+	create := func(role message.MessageRole, parts ...message.ContentPart) message.Message {
+		msg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{Role: role, Parts: parts})
+		require.NoError(t, err)
+		return msg
+	}
+	return []message.Message{
+		create(message.User, message.TextContent{Text: "list the files"}),
+		create(message.Assistant, message.ToolCall{ID: "c1", Name: "ls", Input: "{}", Finished: true}),
+		create(message.Tool, message.ToolResult{ToolCallID: "c1", Name: "ls", Content: "a.go\nb.go"}),
+		create(message.User, message.TextContent{Text: "thanks"}),
+	}
+}
+
+func itemsByID(report ContextReport) map[string]ContextItem {
+	out := make(map[string]ContextItem, len(report.Items))
+	for _, item := range report.Items {
+		out[item.ID] = item
+	}
+	return out
+}
+
+func TestInspectContextListsRequestParts(t *testing.T) {
+	a := newLoopAgent(t, &scriptedProvider{})
+	msgs := seedToolExchange(t, a, "s1")
+
+	report, err := a.InspectContext(context.Background(), "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", report.SessionID)
+	assert.EqualValues(t, 200_000, report.ContextWindow)
+
+	items := itemsByID(report)
+	assert.Equal(t, ContextItemSystem, report.Items[0].Kind)
+	assert.Contains(t, items, "message:"+msgs[0].ID)
+	assert.Contains(t, items, "message:"+msgs[1].ID)
+	assert.Equal(t, "Assistant: calls ls", items["message:"+msgs[1].ID].Name)
+	// Tool results are counted with the assistant message that requested them.
+	assert.NotContains(t, items, "message:"+msgs[2].ID)
+	assert.Contains(t, items, "message:"+msgs[3].ID)
+
+	var toolItems int
+	var total int64
+	for _, item := range report.Items {
+		total += item.Tokens
+		if item.Kind == ContextItemTool {
+			toolItems++
+		}
+	}
+	assert.Equal(t, 1, toolItems)
+	assert.Equal(t, total, report.Tokens)
+}
+
+func TestExcludeFromNextTurn(t *testing.T) {
+	a := newLoopAgent(t, &scriptedProvider{})
+	msgs := seedToolExchange(t, a, "s1")
+	toolID := contextItemID(ContextItemTool, a.resolveTools()[0].Info().Name)
+	assistantID := contextItemID(ContextItemMessage, msgs[1].ID)
+
+	before, err := a.InspectContext(context.Background(), "s1")
+	require.NoError(t, err)
+
+	require.NoError(t, a.ExcludeFromNextTurn("s1", []string{assistantID, toolID}))
+	after, err := a.InspectContext(context.Background(), "s1")
+	require.NoError(t, err)
+	items := itemsByID(after)
+	assert.True(t, items[assistantID].Excluded)
+	assert.True(t, items[toolID].Excluded)
+	assert.Equal(t, before.Tokens-items[assistantID].Tokens-items[toolID].Tokens, after.Tokens)
+
+	ctx := context.Background()
+	history, toolSet := msgs, a.resolveTools()
+	_, history, toolSet = a.applyContextExclusions(ctx, a.takeContextExclusions("s1"), history, toolSet)
+	require.Len(t, history, 2)
+	assert.Equal(t, msgs[0].ID, history[0].ID)
+	assert.Equal(t, msgs[3].ID, history[1].ID)
+	assert.Empty(t, toolSet)
+
+	// Exclusions only apply to one turn.
+	assert.Nil(t, a.takeContextExclusions("s1"))
+}
+
+func TestExcludeFromNextTurnRejectsSystemPrompt(t *testing.T) {
+	a := newLoopAgent(t, &scriptedProvider{})
+	assert.ErrorIs(t, a.ExcludeFromNextTurn("s1", []string{"tool:ls", "system"}), ErrContextItemRequired)
+	assert.Nil(t, a.takeContextExclusions("s1"))
+
+	require.NoError(t, a.ExcludeFromNextTurn("s1", []string{"tool:ls"}))
+	require.NoError(t, a.ExcludeFromNextTurn("s1", nil))
+	assert.Nil(t, a.takeContextExclusions("s1"))
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/question"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/translation"
 )
 
 // AgentFactory creates agent instances with optional output schema overrides.
@@ -44,6 +45,18 @@ type AgentFactory interface {
 	CronServices() (tools.CronToolService, tools.CronScheduleHelper)
 	SetTodoStore(store tools.TodoStore)
 	TodoStore() tools.TodoStore
+	// SetBlackboard installs the shared board behind the blackboard_post
+	// and blackboard_read tools. nil leaves both tools out.
+	SetBlackboard(board tools.BlackboardService)
+	Blackboard() tools.BlackboardService
+	// SetCodeIndex installs the embeddings index behind the codesearch
+	// tool. nil leaves the tool out.
+	SetCodeIndex(index tools.CodeSearchService)
+	CodeIndex() tools.CodeSearchService
+	// SetMemory installs the project memory store behind the
+	// memory_write and memory_read tools. nil leaves both tools out.
+	SetMemory(memories tools.MemoryService)
+	Memory() tools.MemoryService
 	SetQuestionService(svc question.Service)
 	QuestionService() question.Service
 	// SetBridgeSender installs the chat-bridge handle the router_send
@@ -66,6 +79,11 @@ type AgentFactory interface {
 	// HookRegistry returns the registered hook runtime, or nil if none
 	// has been installed.
 	HookRegistry() *hooks.Registry
+
+	// SetTranslationService installs the per-session output translation
+	// toggle. nil (the default) disables response translation.
+	SetTranslationService(svc translation.Service)
+	TranslationService() translation.Service
 }
 
 type agentFactory struct {
@@ -80,6 +98,9 @@ type agentFactory struct {
 	cronToolService    tools.CronToolService
 	cronScheduleHelper tools.CronScheduleHelper
 	todoStore          tools.TodoStore
+	blackboard         tools.BlackboardService
+	codeIndex          tools.CodeSearchService
+	memories           tools.MemoryService
 	questionService    question.Service
 
 	bridgeSender    tools.BridgeSender
@@ -88,6 +109,8 @@ type agentFactory struct {
 
 	hookRegistry *hooks.Registry
 
+	translationService translation.Service
+
 	mu        sync.Mutex
 	stepCache map[string]Service
 }
@@ -108,6 +131,20 @@ func (f *agentFactory) HookRegistry() *hooks.Registry {
 	return f.hookRegistry
 }
 
+// SetTranslationService installs the per-session translation toggle.
+func (f *agentFactory) SetTranslationService(svc translation.Service) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.translationService = svc
+}
+
+// TranslationService returns the installed toggle (or nil).
+func (f *agentFactory) TranslationService() translation.Service {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.translationService
+}
+
 // SetBridgeSender installs the chat-bridge handle the router_send tool
 // uses. cmd/serve.go calls this after the bridge orchestrator starts.
 // nil sender disables the router_send tool entirely.
@@ -178,6 +215,48 @@ func (f *agentFactory) TodoStore() tools.TodoStore {
 	return f.todoStore
 }
 
+// SetBlackboard injects the shared agent blackboard.
+func (f *agentFactory) SetBlackboard(board tools.BlackboardService) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blackboard = board
+}
+
+// Blackboard returns the injected blackboard, or nil.
+func (f *agentFactory) Blackboard() tools.BlackboardService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.blackboard
+}
+
+// SetCodeIndex injects the codesearch index.
+func (f *agentFactory) SetCodeIndex(index tools.CodeSearchService) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.codeIndex = index
+}
+
+// CodeIndex returns the injected codesearch index, or nil.
+func (f *agentFactory) CodeIndex() tools.CodeSearchService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.codeIndex
+}
+
+// SetMemory injects the project memory store.
+func (f *agentFactory) SetMemory(memories tools.MemoryService) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.memories = memories
+}
+
+// Memory returns the injected project memory store, or nil.
+func (f *agentFactory) Memory() tools.MemoryService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.memories
+}
+
 // SetQuestionService injects the question service after factory creation
 // (only in interactive mode).
 func (f *agentFactory) SetQuestionService(svc question.Service) {
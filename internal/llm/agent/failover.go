@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func modelIDs(ids []string) []models.ModelID {
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]models.ModelID, len(ids))
+	for i, id := range ids {
+		out[i] = models.ModelID(id)
+	}
+	return out
+}
+
+// nextFallbacks returns the fallback models after current in the agent's
+// chain. A current model outside the chain (picked in the model dialog)
+// falls back to the whole list.
+func (a *agent) nextFallbacks(current models.ModelID) []models.ModelID {
+	if i := slices.Index(a.fallbackModels, current); i >= 0 {
+		return a.fallbackModels[i+1:]
+	}
+	return slices.DeleteFunc(slices.Clone(a.fallbackModels), func(id models.ModelID) bool {
+		return id == current
+	})
+}
+
+// failover switches the agent to its next fallback model after a stream
+// failed with err. It reports false when err is not a quota or overload
+// error, or no fallback model could be created. On a switch, failed is
+// rewritten into a note that records it in the session; its partial
+// output is dropped since the request is repeated on the new model. The
+// switch sticks for later requests.
+func (a *agent) failover(ctx context.Context, sessionID string, failed *message.Message, err error) bool {
+	if !provider.IsFailoverError(err) {
+		return false
+	}
+	from, to, ok := a.switchToFallback()
+	if !ok {
+		return false
+	}
+	logging.WarnPersist(fmt.Sprintf("%s failed, switched to %s", from.Name, to.Name))
+	logging.Warn("Switched to fallback model", "agent", a.agentID, "session_id", sessionID, "from", from.ID, "to", to.ID, "error", err)
+
+	failed.Parts = []message.ContentPart{message.TextContent{
+		Text: fmt.Sprintf("%s failed (%v). Switched to fallback model %s.", from.Name, err, to.Name),
+	}}
+	a.finishMessage(ctx, failed, message.FinishReasonError)
+	return true
+}
+
+// switchToFallback moves the agent to the first of its next fallback
+// models that can be created and returns the models it switched between.
+func (a *agent) switchToFallback() (from, to models.Model, ok bool) {
+	from = a.provider.Model()
+	for _, id := range a.nextFallbacks(from.ID) {
+		p, perr := createAgentProvider(a.agentID, append(slices.Clone(a.providerOpts), withModel(id))...)
+		if perr != nil {
+			logging.Warn("Fallback model unavailable", "agent", a.agentID, "model", id, "error", perr)
+			continue
+		}
+		a.provider = p
+		return from, p.Model(), true
+	}
+	return from, models.Model{}, false
+}
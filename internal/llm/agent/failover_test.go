@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func overloadedErr() error {
+	return &anthropic.Error{StatusCode: 529, Response: &http.Response{StatusCode: 529, Header: http.Header{}}}
+}
+
+// withAnthropicProvider configures a dummy Anthropic provider so fallback
+// providers can be built without touching the network.
+func withAnthropicProvider(t *testing.T) {
+	t.Helper()
+	cfg := config.Get()
+	prev, had := cfg.Providers[models.ProviderAnthropic]
+	if cfg.Providers == nil {
+		cfg.Providers = map[models.ModelProvider]config.Provider{}
+	}
+	cfg.Providers[models.ProviderAnthropic] = config.Provider{APIKey: "test"}
+	t.Cleanup(func() {
+		if had {
+			cfg.Providers[models.ProviderAnthropic] = prev
+		} else {
+			delete(cfg.Providers, models.ProviderAnthropic)
+		}
+	})
+}
+
+func TestFailoverSwitchesToNextModel(t *testing.T) {
+	a := newLoopAgent(t, &scriptedProvider{})
+	withAnthropicProvider(t)
+	a.fallbackModels = []models.ModelID{"no-such-model", models.Claude46Sonnet}
+
+	failed := message.Message{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "partial"}}}
+	require.True(t, a.failover(context.Background(), "s1", &failed, overloadedErr()))
+
+	assert.Equal(t, models.Claude46Sonnet, a.provider.Model().ID)
+	assert.Contains(t, failed.Content().String(), "Switched to fallback model Claude 4.6 Sonnet")
+	assert.NotContains(t, failed.Content().String(), "partial")
+	assert.Equal(t, message.FinishReasonError, failed.FinishReason())
+
+	// The chain is exhausted once the last fallback is in use.
+	assert.False(t, a.failover(context.Background(), "s1", &failed, overloadedErr()))
+}
+
+func TestFailoverIgnoresOtherErrors(t *testing.T) {
+	a := newLoopAgent(t, &scriptedProvider{})
+	withAnthropicProvider(t)
+	a.fallbackModels = []models.ModelID{models.Claude46Sonnet}
+
+	var failed message.Message
+	assert.False(t, a.failover(context.Background(), "s1", &failed, errors.New("invalid request")))
+	assert.False(t, a.failover(context.Background(), "s1", &failed, context.Canceled))
+	assert.Equal(t, models.ModelID("scripted-model"), a.provider.Model().ID)
+}
+
+func TestNextFallbacks(t *testing.T) {
+	a := &agent{fallbackModels: []models.ModelID{"b", "c"}}
+	assert.Equal(t, []models.ModelID{"b", "c"}, a.nextFallbacks("a"))
+	assert.Equal(t, []models.ModelID{"c"}, a.nextFallbacks("b"))
+	assert.Empty(t, a.nextFallbacks("c"))
+}
@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+const (
+	FixDiagnosticsToolName = "fix_diagnostics"
+
+	defaultFixDiagnosticsRounds = 3
+	maxFixDiagnosticsRounds     = 10
+	// fixDiagnosticsPromptLimit caps the diagnostics quoted to the
+	// subagent per round; the rest are left for later rounds.
+	fixDiagnosticsPromptLimit = 50
+)
+
+type FixDiagnosticsParams struct {
+	FilePath        string `json:"file_path,omitempty"`
+	MaxRounds       int    `json:"max_rounds,omitempty"`
+	IncludeWarnings bool   `json:"include_warnings,omitempty"`
+	SubagentType    string `json:"subagent_type,omitempty"`
+}
+
+type FixDiagnosticsResponseMetadata struct {
+	TaskID    string `json:"task_id"`
+	Rounds    int    `json:"rounds"`
+	Initial   int    `json:"initial"`
+	Remaining int    `json:"remaining"`
+}
+
+// diagnosticsFunc returns the formatted diagnostics of filePath, or of the
+// whole project when filePath is empty.
+type diagnosticsFunc func(ctx context.Context, filePath string, includeWarnings bool) []string
+
+type fixDiagnosticsTool struct {
+	task        *agentTool
+	diagnostics diagnosticsFunc
+}
+
+func (f *fixDiagnosticsTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{
+		Name: FixDiagnosticsToolName,
+		Description: "Fix language server errors in a file or the whole project with a subagent. " +
+			"Each round collects the current LSP diagnostics, hands the exact errors to the subagent to fix, " +
+			"then checks again, until no errors are left or max_rounds is reached. " +
+			"Rounds reuse one subagent session, so later rounds see what was already tried.\n\n" +
+			"Use it after a change leaves compile or type errors behind, instead of fixing them one edit at a time. " +
+			"The result lists the diagnostics that remain, if any.",
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "File to fix. Omit to fix the errors of every file the language servers report on.",
+			},
+			"max_rounds": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum fix rounds (default %d, at most %d)", defaultFixDiagnosticsRounds, maxFixDiagnosticsRounds),
+			},
+			"include_warnings": map[string]any{
+				"type":        "boolean",
+				"description": "Fix warnings as well as errors",
+			},
+			"subagent_type": map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("Subagent that applies the fixes (default %q)", config.AgentWorkhorse),
+			},
+		},
+	}
+}
+
+func (f *fixDiagnosticsTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	var params FixDiagnosticsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return tools.NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	rounds := params.MaxRounds
+	if rounds <= 0 {
+		rounds = defaultFixDiagnosticsRounds
+	}
+	rounds = min(rounds, maxFixDiagnosticsRounds)
+	subagentType := params.SubagentType
+	if subagentType == "" {
+		subagentType = config.AgentWorkhorse
+	}
+	if info, ok := f.task.registry.Get(subagentType); !ok || info.Mode != config.AgentModeSubagent {
+		return tools.NewTextErrorResponse(fmt.Sprintf("unknown subagent type %q", subagentType)), nil
+	}
+
+	file := params.FilePath
+	if file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(config.WorkingDirectory(), file)
+		}
+		if _, err := os.Stat(file); err != nil {
+			return tools.NewTextErrorResponse(fmt.Sprintf("file not found: %s", file)), nil
+		}
+	}
+
+	sessionID, messageID := tools.GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return tools.ToolResponse{}, fmt.Errorf("session_id and message_id are required")
+	}
+
+	scope := "the project"
+	if file != "" {
+		scope = file
+	}
+	diags := f.diagnostics(ctx, file, params.IncludeWarnings)
+	meta := FixDiagnosticsResponseMetadata{Initial: len(diags)}
+	if len(diags) == 0 {
+		return tools.WithResponseMetadata(
+			tools.NewTextResponse(fmt.Sprintf("No diagnostics to fix in %s.", scope)), meta), nil
+	}
+
+	a, err := f.task.factory.NewAgent(ctx, subagentType, nil, "", false, nil)
+	if err != nil {
+		return tools.ToolResponse{}, fmt.Errorf("error creating agent: %s", err)
+	}
+	taskSession, err := f.task.sessions.CreateTaskSession(ctx, call.ID, sessionID, fmt.Sprintf("%s task: fix diagnostics in %s", subagentType, scope))
+	if err != nil {
+		return tools.ToolResponse{}, fmt.Errorf("error creating session: %s", err)
+	}
+	f.task.permissions.LinkSession(taskSession.ID, sessionID)
+	meta.TaskID = taskSession.ID
+	defer f.task.rollUpSubagentCost(ctx, sessionID, taskSession.ID)
+
+	for meta.Rounds < rounds {
+		meta.Rounds++
+		done, err := a.Run(ctx, taskSession.ID, fixDiagnosticsPrompt(scope, diags, meta.Rounds, rounds), 0)
+		if err != nil {
+			return tools.ToolResponse{}, fmt.Errorf("error while running fix agent: %s", err)
+		}
+		result := <-done
+		if result.Error != nil {
+			return tools.ToolResponse{}, fmt.Errorf("error while running fix agent: %s", result.Error)
+		}
+		if result.Message.Role != message.Assistant {
+			return tools.NewTextErrorResponse("no response from fix agent"), nil
+		}
+
+		previous := diags
+		diags = f.diagnostics(ctx, file, params.IncludeWarnings)
+		logging.Debug("Fix diagnostics round finished", "round", meta.Rounds, "before", len(previous), "after", len(diags))
+		if len(diags) == 0 {
+			break
+		}
+		if slices.Equal(previous, diags) {
+			// Another round with the same input would only repeat the
+			// attempt that just failed.
+			break
+		}
+	}
+	meta.Remaining = len(diags)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Fixed %d of %d diagnostics in %s after %d round(s).", meta.Initial-min(meta.Initial, meta.Remaining), meta.Initial, scope, meta.Rounds)
+	if len(diags) > 0 {
+		out.WriteString("\n\n<remaining_diagnostics>\n")
+		out.WriteString(strings.Join(diags, "\n"))
+		out.WriteString("\n</remaining_diagnostics>")
+	}
+	fmt.Fprintf(&out, "\n\n<task_id>%s</task_id>", taskSession.ID)
+	return tools.WithResponseMetadata(tools.NewTextResponse(out.String()), meta), nil
+}
+
+func fixDiagnosticsPrompt(scope string, diags []string, round, rounds int) string {
+	shown := diags
+	if len(shown) > fixDiagnosticsPromptLimit {
+		shown = shown[:fixDiagnosticsPromptLimit]
+	}
+	var b strings.Builder
+	if round == 1 {
+		fmt.Fprintf(&b, "The language servers report these diagnostics in %s:\n\n", scope)
+	} else {
+		fmt.Fprintf(&b, "Round %d of %d: these diagnostics remain after your last changes:\n\n", round, rounds)
+	}
+	b.WriteString("<diagnostics>\n")
+	b.WriteString(strings.Join(shown, "\n"))
+	if len(diags) > len(shown) {
+		fmt.Fprintf(&b, "\n... and %d more", len(diags)-len(shown))
+	}
+	b.WriteString("\n</diagnostics>\n\n")
+	b.WriteString("Fix the root cause of each one by editing the code. Do not silence them with ignore comments, " +
+		"do not delete code just to make an error go away, and keep the change as small as the fix allows. " +
+		"When done, reply with a short list of what you changed.")
+	return b.String()
+}
+
+func (f *fixDiagnosticsTool) AllowParallelism(call tools.ToolCall, allCalls []tools.ToolCall) bool {
+	return false
+}
+
+func (f *fixDiagnosticsTool) IsBaseline() bool { return true }
+
+// NewFixDiagnosticsTool creates the fix_diagnostics tool. It reads
+// diagnostics from the language servers of lspService.
+func NewFixDiagnosticsTool(
+	sessions session.Service,
+	messages message.Service,
+	permissions permission.Service,
+	reg agentregistry.Registry,
+	factory AgentFactory,
+	lspService lsp.LspService,
+) tools.BaseTool {
+	return &fixDiagnosticsTool{
+		task: &agentTool{
+			sessions:    sessions,
+			messages:    messages,
+			permissions: permissions,
+			registry:    reg,
+			factory:     factory,
+		},
+		diagnostics: func(ctx context.Context, filePath string, includeWarnings bool) []string {
+			if filePath != "" {
+				lspService.WaitForDiagnostics(ctx, filePath)
+			}
+			return lsp.CollectDiagnostics(filePath, lspService.Clients(), includeWarnings)
+		},
+	}
+}
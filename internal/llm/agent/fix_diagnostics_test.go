@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/bridge"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixSessions struct {
+	memSessions
+}
+
+func (s *fixSessions) CreateTaskSession(ctx context.Context, toolCallID, parentSessionID, title string) (session.Session, error) {
+	return s.Save(ctx, session.Session{ID: "task-" + toolCallID, ParentSessionID: parentSessionID, Title: title})
+}
+
+type fixPermissions struct {
+	permission.Service
+}
+
+func (fixPermissions) LinkSession(string, string) {}
+
+// fixAgent records the prompts it is run with and calls onRun after each.
+type fixAgent struct {
+	Service
+	prompts []string
+	onRun   func()
+}
+
+func (f *fixAgent) Run(_ context.Context, _ string, content string, _ int, _ ...message.Attachment) (<-chan AgentEvent, error) {
+	f.prompts = append(f.prompts, content)
+	f.onRun()
+	done := make(chan AgentEvent, 1)
+	done <- AgentEvent{Message: message.Message{Role: message.Assistant}}
+	return done, nil
+}
+
+type fixFactory struct {
+	AgentFactory
+	agent *fixAgent
+}
+
+func (f *fixFactory) NewAgent(context.Context, string, map[string]any, string, bool, []bridge.PeerRef) (Service, error) {
+	return f.agent, nil
+}
+
+func newTestFixDiagnosticsTool(t *testing.T, rounds [][]string) (*fixDiagnosticsTool, *fixAgent) {
+	t.Helper()
+	newLoopAgent(t, &scriptedProvider{}) // loads config
+	i := 0
+	fa := &fixAgent{onRun: func() { i++ }}
+	return &fixDiagnosticsTool{
+		task: &agentTool{
+			sessions:    &fixSessions{},
+			permissions: fixPermissions{},
+			registry:    agentregistry.GetRegistry(),
+			factory:     &fixFactory{agent: fa},
+		},
+		diagnostics: func(context.Context, string, bool) []string {
+			return rounds[min(i, len(rounds)-1)]
+		},
+	}, fa
+}
+
+func runFixDiagnostics(t *testing.T, tool *fixDiagnosticsTool, params FixDiagnosticsParams) (tools.ToolResponse, FixDiagnosticsResponseMetadata) {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), tools.SessionIDContextKey, "parent")
+	ctx = context.WithValue(ctx, tools.MessageIDContextKey, "msg")
+	resp, err := tool.Run(ctx, tools.ToolCall{ID: "call1", Name: FixDiagnosticsToolName, Input: string(input)})
+	require.NoError(t, err)
+	var meta FixDiagnosticsResponseMetadata
+	if resp.Metadata != "" {
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	}
+	return resp, meta
+}
+
+func TestFixDiagnosticsStopsWhenClean(t *testing.T) {
+	tool, fa := newTestFixDiagnosticsTool(t, [][]string{
+		{"Error: a.go:1:1 [gopls] undefined: x", "Error: a.go:2:1 [gopls] undefined: y"},
+		{"Error: a.go:2:1 [gopls] undefined: y"},
+		{},
+	})
+	resp, meta := runFixDiagnostics(t, tool, FixDiagnosticsParams{MaxRounds: 5})
+	assert.False(t, resp.IsError)
+	assert.Equal(t, 2, meta.Rounds)
+	assert.Equal(t, 2, meta.Initial)
+	assert.Equal(t, 0, meta.Remaining)
+	assert.Equal(t, "task-call1", meta.TaskID)
+	require.Len(t, fa.prompts, 2)
+	assert.Contains(t, fa.prompts[0], "undefined: x")
+	assert.Contains(t, fa.prompts[1], "Round 2 of 5")
+	assert.NotContains(t, fa.prompts[1], "undefined: x")
+}
+
+func TestFixDiagnosticsStopsWithoutProgress(t *testing.T) {
+	stuck := []string{"Error: a.go:1:1 [gopls] undefined: x"}
+	tool, fa := newTestFixDiagnosticsTool(t, [][]string{stuck})
+	resp, meta := runFixDiagnostics(t, tool, FixDiagnosticsParams{})
+	assert.Equal(t, 1, meta.Rounds)
+	assert.Equal(t, 1, meta.Remaining)
+	assert.Len(t, fa.prompts, 1)
+	assert.Contains(t, resp.Content, "<remaining_diagnostics>")
+}
+
+func TestFixDiagnosticsNothingToFix(t *testing.T) {
+	tool, fa := newTestFixDiagnosticsTool(t, [][]string{{}})
+	resp, meta := runFixDiagnostics(t, tool, FixDiagnosticsParams{})
+	assert.Contains(t, resp.Content, "No diagnostics to fix")
+	assert.Equal(t, 0, meta.Rounds)
+	assert.Empty(t, fa.prompts)
+}
+
+func TestFixDiagnosticsRejectsPrimaryAgent(t *testing.T) {
+	tool, _ := newTestFixDiagnosticsTool(t, [][]string{{"Error: x"}})
+	resp, _ := runFixDiagnostics(t, tool, FixDiagnosticsParams{SubagentType: "coder"})
+	assert.True(t, resp.IsError)
+}
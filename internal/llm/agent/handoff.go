@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+const HandoffToolName = "handoff"
+
+type HandoffParams struct {
+	Agent string `json:"agent"`
+	Note  string `json:"note"`
+}
+
+type HandoffResponseMetadata struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// handoffTool transfers the session to another primary agent. Unlike the
+// task tool it starts no new session: the run ends right after the call,
+// and the front-end makes the target the active agent and runs it on the
+// same session, where the handoff result is the last thing it reads.
+type handoffTool struct {
+	registry agentregistry.Registry
+}
+
+func NewHandoffTool(reg agentregistry.Registry) tools.BaseTool {
+	return &handoffTool{registry: reg}
+}
+
+// handoffTargets returns the primary agents agentID can hand a session to.
+func handoffTargets(reg agentregistry.Registry, agentID string) []agentregistry.AgentInfo {
+	var targets []agentregistry.AgentInfo
+	for _, a := range reg.ListByMode(config.AgentModeAgent) {
+		if a.ID != agentID && !a.Disabled {
+			targets = append(targets, a)
+		}
+	}
+	return targets
+}
+
+func (h *handoffTool) Info() tools.ToolInfo {
+	var ids, descs []string
+	for _, a := range h.registry.ListByMode(config.AgentModeAgent) {
+		if a.Disabled {
+			continue
+		}
+		desc := a.Description
+		if desc == "" {
+			desc = "No description available"
+		}
+		ids = append(ids, a.ID)
+		descs = append(descs, fmt.Sprintf("- %s: %s", a.ID, desc))
+	}
+	return tools.ToolInfo{
+		Name: HandoffToolName,
+		Description: "Hand this session over to another primary agent. The other agent takes over the conversation as it is — " +
+			"every message, tool result and the session's todos and file changes — and answers the user from then on. " +
+			"Your turn ends with this call; do not call other tools after it.\n\n" +
+			"Use it when the user's request is better served by another agent for the rest of the session, " +
+			"e.g. when a coding task grows into work that needs coordinating several subagents. " +
+			"To delegate one self-contained piece of work and get its result back, use the task tool instead.\n\n" +
+			"Primary agents:\n" + strings.Join(descs, "\n"),
+		Parameters: map[string]any{
+			"agent": map[string]any{
+				"type":        "string",
+				"description": "The primary agent to hand the session over to",
+				"enum":        ids,
+			},
+			"note": map[string]any{
+				"type":        "string",
+				"description": "What the next agent needs to know to continue: what was done, what is left, and anything the user asked for that is not obvious from the conversation",
+			},
+		},
+		Required: []string{"agent", "note"},
+	}
+}
+
+func (h *handoffTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	var params HandoffParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return tools.NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Agent == "" {
+		return tools.NewTextErrorResponse("agent is required"), nil
+	}
+	from := string(tools.GetAgentID(ctx))
+	if params.Agent == from {
+		return tools.NewTextErrorResponse(fmt.Sprintf("this session is already run by %s", from)), nil
+	}
+	target, ok := h.registry.Get(params.Agent)
+	if !ok || target.Mode != config.AgentModeAgent || target.Disabled {
+		var names []string
+		for _, a := range handoffTargets(h.registry, from) {
+			names = append(names, a.ID)
+		}
+		return tools.NewTextErrorResponse(fmt.Sprintf("unknown primary agent %q. Available: %s", params.Agent, strings.Join(names, ", "))), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "The %s agent handed this session over to the %s agent.\n", from, target.ID)
+	fmt.Fprintf(&b, "%s: you are now in charge of this session. Continue from the conversation above and answer the user yourself.", target.ID)
+	if note := strings.TrimSpace(params.Note); note != "" {
+		fmt.Fprintf(&b, "\n\n<handoff_note from=%q>\n%s\n</handoff_note>", from, note)
+	}
+	return tools.WithResponseMetadata(
+		tools.NewTextResponse(b.String()),
+		HandoffResponseMetadata{From: from, To: target.ID},
+	), nil
+}
+
+func (h *handoffTool) AllowParallelism(tools.ToolCall, []tools.ToolCall) bool { return false }
+
+func (h *handoffTool) IsBaseline() bool { return true }
+
+// handoffTarget returns the agent a successful handoff call among the
+// results of the turn names.
+func handoffTarget(assistantMsg message.Message, toolResults *message.Message) (config.AgentName, bool) {
+	for _, tr := range toolResults.ToolResults() {
+		if tr.Name != HandoffToolName || tr.IsError {
+			continue
+		}
+		call, ok := assistantMsg.FindToolCall(tr.ToolCallID)
+		if !ok {
+			continue
+		}
+		var params HandoffParams
+		if json.Unmarshal([]byte(call.Input), &params) == nil && params.Agent != "" {
+			return config.AgentName(params.Agent), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runHandoff(t *testing.T, from config.AgentName, params HandoffParams) tools.ToolResponse {
+	t.Helper()
+	newLoopAgent(t, &scriptedProvider{}) // loads config
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), tools.AgentIDContextKey, from)
+	resp, err := NewHandoffTool(agentregistry.GetRegistry()).Run(ctx, tools.ToolCall{ID: "call-1", Name: HandoffToolName, Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestHandoffTool(t *testing.T) {
+	resp := runHandoff(t, config.AgentCoder, HandoffParams{Agent: string(config.AgentHivemind), Note: "split the migration across workhorses"})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "hivemind: you are now in charge")
+	assert.Contains(t, resp.Content, "split the migration across workhorses")
+	var meta HandoffResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.Equal(t, HandoffResponseMetadata{From: "coder", To: "hivemind"}, meta)
+
+	resp = runHandoff(t, config.AgentCoder, HandoffParams{Agent: string(config.AgentCoder)})
+	assert.True(t, resp.IsError, "handing off to the same agent")
+
+	resp = runHandoff(t, config.AgentCoder, HandoffParams{Agent: string(config.AgentExplorer)})
+	assert.True(t, resp.IsError, "handing off to a subagent")
+	assert.Contains(t, resp.Content, "hivemind")
+}
+
+// A run whose turn hands the session off ends right after the tool
+// results, without a wrap-up request, and names the next agent.
+func TestProcessGeneration_EndsOnHandoff(t *testing.T) {
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(int) *provider.ProviderResponse {
+		return &provider.ProviderResponse{
+			ToolCalls:    []message.ToolCall{{ID: "call-1", Name: HandoffToolName, Input: `{"agent":"hivemind","note":"fan out"}`, Finished: true}},
+			FinishReason: message.FinishReasonToolUse,
+		}
+	}}
+	a := newLoopAgent(t, p)
+	toolsCh := make(chan tools.BaseTool, 1)
+	toolsCh <- NewHandoffTool(agentregistry.GetRegistry())
+	close(toolsCh)
+	a.toolsCh = toolsCh
+
+	res := a.processGeneration(context.Background(), "sess-handoff", "plan the migration", 0, nil, RunOptions{})
+
+	require.NoError(t, res.Error)
+	assert.Equal(t, config.AgentHivemind, res.HandoffTo)
+	assert.Equal(t, 1, p.callCount())
+	msgs, err := a.messages.List(context.Background(), "sess-handoff")
+	require.NoError(t, err)
+	last := msgs[len(msgs)-1]
+	require.Equal(t, message.Tool, last.Role)
+	assert.Contains(t, last.ToolResults()[0].Content, "hivemind: you are now in charge")
+}
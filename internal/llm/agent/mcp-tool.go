@@ -58,6 +58,9 @@ type (
 		LoadedServers() map[string]bool
 		// ServerTools returns the tool names for a loaded MCP server (without the server prefix).
 		ServerTools(name string) []string
+		// Health returns the connection state of every persistent MCP server
+		// that has been connected to.
+		Health() map[string]MCPServerHealth
 		pubsub.Suscriber[MCPServerEvent]
 	}
 	MCPRegistryFiler struct {
@@ -67,6 +70,9 @@ type (
 	mcpRegistry struct {
 		// *mcp.ListToolsResult by MCP server name
 		mcpTools sync.Map
+		// *mcpConn and MCPServerHealth by server name, for persistent servers
+		conns  sync.Map
+		health sync.Map
 
 		permissions   permission.Service
 		agentRegistry agentregistry.Registry
@@ -79,7 +85,7 @@ type (
 		mcpConfig   config.MCPServer
 		permissions permission.Service
 		reg         agentregistry.Registry
-		mcpReg      MCPRegistry
+		mcpReg      *mcpRegistry
 	}
 )
 
@@ -100,33 +106,72 @@ func (r *mcpRegistry) StartClient(ctx context.Context, name string) (c *client.C
 
 	startCtx, cancelStart := context.WithTimeout(ctx, 20*time.Second)
 	defer cancelStart()
+	c, err = newMCPClient(m)
+	if err != nil {
+		logging.Error("Error creating MCP client", "server", name, "cause", err)
+		return nil, err
+	}
+	if err = c.Start(startCtx); err != nil {
+		logging.Error("Error starting MCP client", "server", m.Command, "cause", err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// ProbeMCPServer starts the server, completes the MCP handshake and lists
+// its tools, then shuts it down. It returns how many tools the server
+// offers; `opencode config doctor` uses it to check a server works before
+// an agent needs it.
+func ProbeMCPServer(ctx context.Context, m config.MCPServer) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	c, err := newMCPClient(m)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	if err := c.Start(ctx); err != nil {
+		return 0, fmt.Errorf("starting: %w", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "opencode",
+		Version: version.Version,
+	}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		return 0, fmt.Errorf("initializing: %w", err)
+	}
+	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("listing tools: %w", err)
+	}
+	return len(result.Tools), nil
+}
+
+func newMCPClient(m config.MCPServer) (*client.Client, error) {
 	switch m.Type {
 	case config.MCPStdio:
-		c, err = client.NewStdioMCPClient(
+		return client.NewStdioMCPClient(
 			m.Command,
 			m.Env,
 			m.Args...,
 		)
 	case config.MCPSse:
-		c, err = client.NewSSEMCPClient(
+		return client.NewSSEMCPClient(
 			m.URL,
 			client.WithHeaders(m.Headers),
 		)
-	case config.MCPHttp:
-		c, err = client.NewStreamableHttpClient(
+	case config.MCPHttp, config.MCPStreamableHttp:
+		return client.NewStreamableHttpClient(
 			m.URL,
 			transport.WithHTTPHeaders(m.Headers),
 		)
+	case config.MCPWebSocket:
+		return client.NewClient(newWebSocketTransport(m.URL, m.Headers)), nil
+	default:
+		return nil, fmt.Errorf("unsupported mcp type %q", m.Type)
 	}
-	if err != nil {
-		logging.Error("Error creating MCP client", "server", name, "cause", err)
-		return nil, err
-	}
-	if err = c.Start(startCtx); err != nil {
-		logging.Error("Error starting MCP client", "server", m.Command, "cause", err)
-		return nil, err
-	}
-	return c, nil
 }
 
 func (r *mcpRegistry) LoadedServers() map[string]bool {
@@ -249,6 +294,17 @@ func (r *mcpRegistry) getTools(ctx context.Context, name string, m config.MCPSer
 		// fetch
 		defer close(entry.done)
 
+		if isPersistentMCP(m.Type) {
+			entry.data, entry.err = r.listPersistentTools(ctx, name)
+			if entry.err != nil {
+				logging.Error("Error listing MCP tools", "server", name, "cause", entry.err.Error())
+				r.mcpTools.Delete(name)
+				return toolsToAdd
+			}
+			entry.ts = time.Now().UnixMilli()
+			return r.wrapTools(name, m, entry.data)
+		}
+
 		var c *client.Client
 		c, entry.err = r.StartClient(ctx, name)
 		if entry.err != nil {
@@ -286,8 +342,13 @@ func (r *mcpRegistry) getTools(ctx context.Context, name string, m config.MCPSer
 		return toolsToAdd
 	}
 
-	if entry.data != nil {
-		for _, t := range entry.data.Tools {
+	return r.wrapTools(name, m, entry.data)
+}
+
+func (r *mcpRegistry) wrapTools(name string, m config.MCPServer, data *mcp.ListToolsResult) []tools.BaseTool {
+	toolsToAdd := []tools.BaseTool{}
+	if data != nil {
+		for _, t := range data.Tools {
 			toolsToAdd = append(toolsToAdd, newMCPTool(name, t, r.permissions, m, r.agentRegistry, r))
 		}
 	}
@@ -300,7 +361,7 @@ func newMCPTool(
 	permissions permission.Service,
 	mcpConfig config.MCPServer,
 	reg agentregistry.Registry,
-	mcpReg MCPRegistry,
+	mcpReg *mcpRegistry,
 ) tools.BaseTool {
 	return &mcpTool{
 		mcpName:     name,
@@ -342,6 +403,7 @@ func (b *mcpTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolRes
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        config.WorkingDirectory(),
+				Pattern:     params.Input,
 				ToolName:    b.Info().Name,
 				Action:      "execute",
 				Description: permissionDescription,
@@ -353,6 +415,9 @@ func (b *mcpTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolRes
 		}
 	}
 
+	if isPersistentMCP(b.mcpConfig.Type) {
+		return b.mcpReg.callPersistent(ctx, b.mcpName, b.tool.Name, params.Input, resolveCallToolTimeout(b.mcpConfig))
+	}
 	c, err := b.mcpReg.StartClient(ctx, b.mcpName)
 	if err != nil {
 		return tools.NewTextErrorResponse(err.Error()), nil
@@ -383,11 +448,20 @@ func runTool(ctx context.Context, c MCPClient, toolName string, input string, ca
 	if err != nil {
 		return tools.NewTextErrorResponse(err.Error()), nil
 	}
+	resp, err := callTool(ctx, c, toolName, input, callTimeout)
+	if err != nil {
+		return tools.NewTextErrorResponse(err.Error()), nil
+	}
+	return resp, nil
+}
 
+// callTool calls toolName on an initialized client. Errors from the call
+// itself are returned as is so callers can tell transport failures apart.
+func callTool(ctx context.Context, c MCPClient, toolName string, input string, callTimeout time.Duration) (tools.ToolResponse, error) {
 	toolRequest := mcp.CallToolRequest{}
 	toolRequest.Params.Name = toolName
 	var args map[string]any
-	if err = json.Unmarshal([]byte(input), &args); err != nil {
+	if err := json.Unmarshal([]byte(input), &args); err != nil {
 		return tools.NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
 	}
 	toolRequest.Params.Arguments = args
@@ -405,7 +479,7 @@ func runTool(ctx context.Context, c MCPClient, toolName string, input string, ca
 				toolName, callTimeout,
 			)), nil
 		}
-		return tools.NewTextErrorResponse(err.Error()), nil
+		return tools.ToolResponse{}, err
 	}
 
 	output := ""
@@ -417,6 +491,9 @@ func runTool(ctx context.Context, c MCPClient, toolName string, input string, ca
 		}
 	}
 
+	if result.IsError {
+		return tools.NewTextErrorResponse(output), nil
+	}
 	return tools.NewTextResponse(output), nil
 }
 
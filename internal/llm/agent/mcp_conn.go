@@ -0,0 +1,275 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/version"
+)
+
+// MCPHealthState is the connection state of a persistent MCP server.
+type MCPHealthState string
+
+const (
+	MCPHealthConnected    MCPHealthState = "connected"
+	MCPHealthReconnecting MCPHealthState = "reconnecting"
+	MCPHealthFailed       MCPHealthState = "failed"
+)
+
+// MCPServerHealth describes a persistent (streamable-http or websocket)
+// MCP server connection. Servers using the per-call transports have no
+// health entry.
+type MCPServerHealth struct {
+	State MCPHealthState
+	// Attempts counts consecutive failed connection attempts.
+	Attempts  int
+	LastError string
+	// SessionID is the streamable HTTP session the client resumes after a
+	// dropped connection.
+	SessionID string
+	UpdatedAt time.Time
+}
+
+const MCPServerHealthChanged MCPServerEventType = "health_changed"
+
+const (
+	mcpReconnectAttempts  = 5
+	mcpReconnectBaseDelay = 500 * time.Millisecond
+	mcpReconnectMaxDelay  = 15 * time.Second
+)
+
+// mcpBackoff returns the delay before reconnect attempt n (0-based),
+// doubling from mcpReconnectBaseDelay up to mcpReconnectMaxDelay.
+func mcpBackoff(n int) time.Duration {
+	d := mcpReconnectBaseDelay << n
+	if d <= 0 || d > mcpReconnectMaxDelay {
+		return mcpReconnectMaxDelay
+	}
+	return d
+}
+
+// isPersistentMCP reports whether servers of type t keep one long-lived
+// connection instead of connecting for every tool call.
+func isPersistentMCP(t config.MCPType) bool {
+	return t == config.MCPStreamableHttp || t == config.MCPWebSocket
+}
+
+// mcpConn is the shared connection to one persistent server. sessionID
+// outlives client so a reconnect can resume the server-side session.
+type mcpConn struct {
+	mu        sync.Mutex
+	client    *client.Client
+	sessionID string
+}
+
+func (r *mcpRegistry) Health() map[string]MCPServerHealth {
+	result := make(map[string]MCPServerHealth)
+	r.health.Range(func(key, value any) bool {
+		result[key.(string)] = value.(MCPServerHealth)
+		return true
+	})
+	return result
+}
+
+func (r *mcpRegistry) setHealth(name string, h MCPServerHealth) {
+	h.UpdatedAt = time.Now()
+	r.health.Store(name, h)
+	var err error
+	if h.LastError != "" {
+		err = errors.New(h.LastError)
+	}
+	r.Publish(pubsub.UpdatedEvent, MCPServerEvent{
+		Type:       MCPServerHealthChanged,
+		ServerName: name,
+		Error:      err,
+	})
+}
+
+func (r *mcpRegistry) conn(name string) *mcpConn {
+	v, _ := r.conns.LoadOrStore(name, &mcpConn{})
+	return v.(*mcpConn)
+}
+
+// connect returns the live client of a persistent server, dialing with
+// exponential backoff when there is none. Concurrent callers wait for the
+// same dial rather than opening connections of their own.
+func (r *mcpRegistry) connect(ctx context.Context, name string) (*client.Client, error) {
+	m, ok := config.ResolveMCPServers()[name]
+	if !ok {
+		return nil, fmt.Errorf("no mcp found with name %s", name)
+	}
+	cn := r.conn(name)
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	if cn.client != nil {
+		return cn.client, nil
+	}
+
+	var lastErr error
+	for attempt := range mcpReconnectAttempts {
+		if attempt > 0 {
+			r.setHealth(name, MCPServerHealth{
+				State:     MCPHealthReconnecting,
+				Attempts:  attempt,
+				LastError: lastErr.Error(),
+				SessionID: cn.sessionID,
+			})
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(mcpBackoff(attempt - 1)):
+			}
+		}
+		c, err := r.dialPersistent(ctx, name, m, cn.sessionID)
+		if err == nil {
+			cn.client = c
+			cn.sessionID = c.GetSessionId()
+			r.setHealth(name, MCPServerHealth{State: MCPHealthConnected, SessionID: cn.sessionID})
+			return c, nil
+		}
+		logging.Warn("Error connecting to MCP server", "server", name, "attempt", attempt+1, "cause", err)
+		lastErr = err
+	}
+	r.setHealth(name, MCPServerHealth{
+		State:     MCPHealthFailed,
+		Attempts:  mcpReconnectAttempts,
+		LastError: lastErr.Error(),
+		SessionID: cn.sessionID,
+	})
+	return nil, fmt.Errorf("connecting to MCP server %s: %w", name, lastErr)
+}
+
+// dialPersistent opens a connection. For streamable HTTP with a known
+// session it first tries to resume that session, checked with a ping, and
+// only initializes a new one when the server no longer knows it.
+func (r *mcpRegistry) dialPersistent(ctx context.Context, name string, m config.MCPServer, sessionID string) (*client.Client, error) {
+	startCtx, cancelStart := context.WithTimeout(ctx, 20*time.Second)
+	defer cancelStart()
+
+	if sessionID != "" && m.Type == config.MCPStreamableHttp {
+		trans, err := transport.NewStreamableHTTP(m.URL,
+			transport.WithHTTPHeaders(m.Headers),
+			transport.WithSession(sessionID),
+		)
+		if err == nil {
+			c := client.NewClient(trans, client.WithSession())
+			if err = c.Start(startCtx); err == nil {
+				if err = c.Ping(startCtx); err == nil {
+					logging.Debug("MCP session resumed", "server", name, "session", sessionID)
+					return c, nil
+				}
+			}
+			_ = c.Close()
+		}
+		logging.Debug("MCP session could not be resumed", "server", name, "session", sessionID, "cause", err)
+	}
+
+	c, err := newMCPClient(m)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.Start(startCtx); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "opencode",
+		Version: version.Version,
+	}
+	if _, err = c.Initialize(startCtx, initRequest); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// drop forgets c after a transport failure so the next call reconnects. A
+// streamable HTTP client is abandoned rather than closed: closing would
+// delete the session the reconnect is meant to resume.
+func (r *mcpRegistry) drop(name string, c *client.Client, cause error) {
+	cn := r.conn(name)
+	cn.mu.Lock()
+	if cn.client != c {
+		cn.mu.Unlock()
+		return
+	}
+	cn.client = nil
+	if errors.Is(cause, transport.ErrSessionTerminated) {
+		cn.sessionID = ""
+	}
+	if _, ws := c.GetTransport().(*webSocketTransport); ws {
+		_ = c.Close()
+	}
+	sessionID := cn.sessionID
+	cn.mu.Unlock()
+
+	r.setHealth(name, MCPServerHealth{
+		State:     MCPHealthReconnecting,
+		LastError: cause.Error(),
+		SessionID: sessionID,
+	})
+}
+
+// retryable reports whether err shows the request never reached a live
+// session, so repeating it on a new connection cannot run the tool twice.
+func retryable(err error) bool {
+	return errors.Is(err, transport.ErrSessionTerminated) || errors.Is(err, errWebSocketClosed)
+}
+
+func (r *mcpRegistry) listPersistentTools(ctx context.Context, name string) (*mcp.ListToolsResult, error) {
+	for retried := false; ; retried = true {
+		c, err := r.connect(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil && isTransportError(err) {
+			r.drop(name, c, err)
+			if retryable(err) && !retried && ctx.Err() == nil {
+				continue
+			}
+		}
+		return result, err
+	}
+}
+
+// callPersistent runs a tool on a persistent server. A call rejected
+// because the session or socket was gone is retried once on a new
+// connection; any other transport failure drops the connection and is
+// reported, since the server may already have run the tool.
+func (r *mcpRegistry) callPersistent(ctx context.Context, name, toolName, input string, callTimeout time.Duration) (tools.ToolResponse, error) {
+	for retried := false; ; retried = true {
+		c, err := r.connect(ctx, name)
+		if err != nil {
+			return tools.NewTextErrorResponse(err.Error()), nil
+		}
+		resp, err := callTool(ctx, c, toolName, input, callTimeout)
+		if err != nil && isTransportError(err) {
+			r.drop(name, c, err)
+			if retryable(err) && !retried && ctx.Err() == nil {
+				continue
+			}
+		}
+		if err != nil {
+			return tools.NewTextErrorResponse(err.Error()), nil
+		}
+		return resp, nil
+	}
+}
+
+func isTransportError(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr)
+}
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// fakeWebSocketMCP serves the handful of MCP methods the registry uses.
+// The first connection is dropped right after tools/list, so the next call
+// has to reconnect.
+func fakeWebSocketMCP(t *testing.T) (url string, conns *atomic.Int32) {
+	t.Helper()
+	conns = &atomic.Int32{}
+	upgrader := websocket.Upgrader{Subprotocols: []string{"mcp"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := conns.Add(1)
+		for {
+			var req struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			var result any
+			switch req.Method {
+			case "initialize":
+				result = map[string]any{
+					"protocolVersion": "2025-03-26",
+					"capabilities":    map[string]any{"tools": map[string]any{}},
+					"serverInfo":      map[string]any{"name": "fake", "version": "1"},
+				}
+			case "tools/list":
+				result = map[string]any{"tools": []any{map[string]any{
+					"name":        "echo",
+					"inputSchema": map[string]any{"type": "object"},
+				}}}
+			case "tools/call":
+				result = map[string]any{"content": []any{map[string]any{"type": "text", "text": "pong"}}}
+			default:
+				continue
+			}
+			_ = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+			if n == 1 && req.Method == "tools/list" {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), conns
+}
+
+func withMCPServer(t *testing.T, name string, server config.MCPServer) {
+	t.Helper()
+	if config.Get() == nil {
+		if _, err := config.Load(t.TempDir(), false); err != nil {
+			t.Fatalf("config.Load: %v", err)
+		}
+	}
+	cfg := config.Get()
+	if cfg.MCPServers == nil {
+		cfg.MCPServers = make(map[string]config.MCPServer)
+	}
+	cfg.MCPServers[name] = server
+	t.Cleanup(func() { delete(cfg.MCPServers, name) })
+}
+
+func TestPersistentWebSocketMCPReconnects(t *testing.T) {
+	url, conns := fakeWebSocketMCP(t)
+	withMCPServer(t, "ws", config.MCPServer{
+		Type:    config.MCPWebSocket,
+		URL:     url,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+
+	reg := NewMCPRegistry(nil, nil).(*mcpRegistry)
+	events := reg.Subscribe(t.Context())
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	var names []string
+	for tool := range reg.LoadTools(ctx, nil) {
+		names = append(names, tool.Info().Name)
+	}
+	if len(names) != 1 || names[0] != "ws_echo" {
+		t.Fatalf("loaded tools = %v, want [ws_echo]", names)
+	}
+	if h := reg.Health()["ws"]; h.State != MCPHealthConnected {
+		t.Fatalf("health after load = %+v, want connected", h)
+	}
+
+	// The server hung up after listing tools; the call must transparently
+	// reconnect instead of failing.
+	resp, err := reg.callPersistent(ctx, "ws", "echo", `{}`, time.Second)
+	if err != nil || resp.IsError || resp.Content != "pong" {
+		t.Fatalf("callPersistent = %+v, %v", resp, err)
+	}
+	if got := conns.Load(); got != 2 {
+		t.Fatalf("connections = %d, want 2", got)
+	}
+	if h := reg.Health()["ws"]; h.State != MCPHealthConnected {
+		t.Fatalf("health after reconnect = %+v, want connected", h)
+	}
+
+	// The pooled connection is reused for further calls.
+	if _, err := reg.callPersistent(ctx, "ws", "echo", `{}`, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := conns.Load(); got != 2 {
+		t.Fatalf("connections = %d, want 2 (reused)", got)
+	}
+
+	sawHealth := false
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == pubsub.UpdatedEvent && ev.Payload.Type == MCPServerHealthChanged {
+			sawHealth = true
+		}
+	}
+	if !sawHealth {
+		t.Fatal("expected health change events")
+	}
+}
+
+func TestPersistentMCPReportsFailure(t *testing.T) {
+	url, _ := fakeWebSocketMCP(t)
+	// Missing credentials: every handshake is rejected.
+	withMCPServer(t, "ws-denied", config.MCPServer{Type: config.MCPWebSocket, URL: url})
+
+	reg := NewMCPRegistry(nil, nil).(*mcpRegistry)
+	ctx, cancel := context.WithTimeout(t.Context(), 1500*time.Millisecond)
+	defer cancel()
+	if _, err := reg.connect(ctx, "ws-denied"); err == nil {
+		t.Fatal("expected connect to fail")
+	}
+	h := reg.Health()["ws-denied"]
+	if h.State != MCPHealthReconnecting && h.State != MCPHealthFailed {
+		t.Fatalf("health = %+v, want reconnecting or failed", h)
+	}
+	if !strings.Contains(h.LastError, "401") {
+		t.Fatalf("last error = %q, want handshake status", h.LastError)
+	}
+}
+
+func TestMCPBackoff(t *testing.T) {
+	want := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 15 * time.Second, 15 * time.Second}
+	for i, w := range want {
+		if got := mcpBackoff(i); got != w {
+			t.Errorf("mcpBackoff(%d) = %s, want %s", i, got, w)
+		}
+	}
+	if got := mcpBackoff(100); got != mcpReconnectMaxDelay {
+		t.Errorf("mcpBackoff(100) = %s, want cap", got)
+	}
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/llm/agent/mcptest"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	mcptest.MaybeServe()
+	os.Exit(m.Run())
+}
+
+func TestStdioMCPToolsLoadAndRun(t *testing.T) {
+	srv := mcptest.StdioServer(t, mcptest.Spec{Tools: []mcptest.Tool{
+		{Name: "echo", Description: "Echoes its arguments", Params: []string{"text"}},
+		{Name: "fail", Result: "no such issue", IsError: true},
+	}})
+	withMCPServer(t, "fake", srv.Config)
+
+	reg := NewMCPRegistry(grantPermissions{}, agentregistry.GetRegistry())
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	loaded := map[string]tools.BaseTool{}
+	for tool := range reg.LoadTools(ctx, nil) {
+		loaded[tool.Info().Name] = tool
+	}
+	require.Contains(t, loaded, "fake_echo")
+	require.Contains(t, loaded, "fake_fail")
+	assert.Equal(t, []string{"text"}, loaded["fake_echo"].Info().Required)
+	assert.Equal(t, []string{"echo", "fail"}, slices.Sorted(slices.Values(reg.ServerTools("fake"))))
+
+	ctx = context.WithValue(ctx, tools.SessionIDContextKey, "sess")
+	ctx = context.WithValue(ctx, tools.MessageIDContextKey, "msg")
+	resp, err := loaded["fake_echo"].Run(ctx, tools.ToolCall{ID: "c1", Name: "fake_echo", Input: `{"text":"hi"}`})
+	require.NoError(t, err)
+	assert.False(t, resp.IsError)
+	assert.JSONEq(t, `{"text":"hi"}`, resp.Content)
+
+	resp, err = loaded["fake_fail"].Run(ctx, tools.ToolCall{ID: "c2", Name: "fake_fail", Input: `{}`})
+	require.NoError(t, err)
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "no such issue")
+
+	assert.Equal(t, []mcptest.Call{
+		{Tool: "echo", Arguments: map[string]any{"text": "hi"}},
+		{Tool: "fail", Arguments: map[string]any{}},
+	}, srv.Calls())
+}
+
+func TestProbeMCPServer(t *testing.T) {
+	srv := mcptest.StdioServer(t, mcptest.Spec{Tools: []mcptest.Tool{{Name: "echo"}, {Name: "fail"}}})
+	n, err := ProbeMCPServer(t.Context(), srv.Config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	broken := srv.Config
+	broken.Command = "/nonexistent/mcp-server"
+	_, err = ProbeMCPServer(t.Context(), broken)
+	assert.Error(t, err)
+}
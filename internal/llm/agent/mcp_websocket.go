@@ -0,0 +1,200 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errWebSocketClosed is returned for requests issued after the connection
+// dropped. The request never reached the server, so it is safe to retry on
+// a fresh connection.
+var errWebSocketClosed = errors.New("mcp websocket connection closed")
+
+// webSocketTransport carries MCP JSON-RPC messages over a single WebSocket,
+// one message per text frame, using the "mcp" subprotocol. mcp-go ships no
+// WebSocket transport, so this implements transport.Interface directly.
+type webSocketTransport struct {
+	url     string
+	headers map[string]string
+
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan *transport.JSONRPCResponse
+
+	notifyMu       sync.RWMutex
+	onNotification func(mcp.JSONRPCNotification)
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ transport.Interface = (*webSocketTransport)(nil)
+
+func newWebSocketTransport(url string, headers map[string]string) *webSocketTransport {
+	return &webSocketTransport{
+		url:     url,
+		headers: headers,
+		pending: make(map[string]chan *transport.JSONRPCResponse),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (t *webSocketTransport) Start(ctx context.Context) error {
+	if t.conn != nil {
+		return nil
+	}
+	header := http.Header{}
+	for k, v := range t.headers {
+		header.Set(k, v)
+	}
+	dialer := websocket.Dialer{
+		Proxy:        http.ProxyFromEnvironment,
+		Subprotocols: []string{"mcp"},
+	}
+	conn, resp, err := dialer.DialContext(ctx, t.url, header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("websocket handshake failed with status %d: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("websocket dial failed: %w", err)
+	}
+	t.conn = conn
+	go t.readLoop()
+	return nil
+}
+
+// wsMessage is the union of the JSON-RPC shapes a server may send.
+type wsMessage struct {
+	JSONRPC string                   `json:"jsonrpc"`
+	ID      *mcp.RequestId           `json:"id,omitempty"`
+	Method  string                   `json:"method,omitempty"`
+	Result  json.RawMessage          `json:"result,omitempty"`
+	Error   *mcp.JSONRPCErrorDetails `json:"error,omitempty"`
+}
+
+func (t *webSocketTransport) readLoop() {
+	defer t.shutdown()
+	for {
+		_, data, err := t.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.Method != "" && (msg.ID == nil || msg.ID.IsNil()):
+			var n mcp.JSONRPCNotification
+			if err := json.Unmarshal(data, &n); err != nil {
+				continue
+			}
+			t.notifyMu.RLock()
+			handler := t.onNotification
+			t.notifyMu.RUnlock()
+			if handler != nil {
+				handler(n)
+			}
+		case msg.Method != "":
+			// Server-initiated requests (sampling, elicitation) are not
+			// supported; answer so the server doesn't wait forever.
+			t.write(transport.NewJSONRPCErrorResponse(*msg.ID, mcp.METHOD_NOT_FOUND, "method not supported by client", nil))
+		case msg.ID != nil:
+			t.mu.Lock()
+			ch, ok := t.pending[msg.ID.String()]
+			delete(t.pending, msg.ID.String())
+			t.mu.Unlock()
+			if ok {
+				ch <- &transport.JSONRPCResponse{JSONRPC: msg.JSONRPC, ID: *msg.ID, Result: msg.Result, Error: msg.Error}
+			}
+		}
+	}
+}
+
+func (t *webSocketTransport) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	select {
+	case <-t.closed:
+		return errWebSocketClosed
+	default:
+	}
+	if err := t.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("%w: %v", errWebSocketClosed, err)
+	}
+	return nil
+}
+
+func (t *webSocketTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if t.conn == nil {
+		return nil, errors.New("websocket transport not started")
+	}
+	key := request.ID.String()
+	ch := make(chan *transport.JSONRPCResponse, 1)
+	t.mu.Lock()
+	t.pending[key] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, key)
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(request); err != nil {
+		return nil, err
+	}
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.closed:
+		return nil, errWebSocketClosed
+	}
+}
+
+func (t *webSocketTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	if t.conn == nil {
+		return errors.New("websocket transport not started")
+	}
+	return t.write(notification)
+}
+
+func (t *webSocketTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	t.notifyMu.Lock()
+	defer t.notifyMu.Unlock()
+	t.onNotification = handler
+}
+
+func (t *webSocketTransport) shutdown() {
+	t.closeOnce.Do(func() { close(t.closed) })
+}
+
+func (t *webSocketTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	t.writeMu.Lock()
+	_ = t.conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	t.writeMu.Unlock()
+	t.shutdown()
+	return t.conn.Close()
+}
+
+// GetSessionId returns "": the connection itself is the session.
+func (t *webSocketTransport) GetSessionId() string { return "" }
@@ -0,0 +1,193 @@
+// Package mcptest provides a fake MCP server speaking stdio, so code that
+// loads and calls MCP tools can be tested without external processes.
+//
+// The server runs inside the test binary itself. A test package wires it
+// up once in TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		mcptest.MaybeServe()
+//		os.Exit(m.Run())
+//	}
+//
+// and each test then gets a config.MCPServer that re-executes the test
+// binary as the server:
+//
+//	srv := mcptest.StdioServer(t, mcptest.Spec{Tools: []mcptest.Tool{{Name: "echo"}}})
+package mcptest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// SpecEnv carries the JSON Spec of the server a re-executed test binary
+// should run.
+const SpecEnv = "OPENCODE_FAKE_MCP_SPEC"
+
+// Spec describes the fake server's tools.
+type Spec struct {
+	Tools []Tool `json:"tools"`
+	// CallLog is the file each tool call is appended to as a JSON line.
+	// StdioServer sets it; Server.Calls reads it back.
+	CallLog string `json:"callLog,omitempty"`
+}
+
+// Tool is one tool of the fake server. It takes the listed string
+// parameters, all required, and answers with Result, or with its
+// arguments as JSON when Result is empty.
+type Tool struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Params      []string `json:"params,omitempty"`
+	Result      string   `json:"result,omitempty"`
+	IsError     bool     `json:"isError,omitempty"`
+}
+
+// Call is one tool call the fake server received.
+type Call struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// NewServer returns the MCP server described by spec.
+func NewServer(spec Spec) *server.MCPServer {
+	s := server.NewMCPServer("opencode-fake-mcp", "1.0.0", server.WithToolCapabilities(false))
+	var logMu sync.Mutex
+	for _, tool := range spec.Tools {
+		opts := []mcp.ToolOption{mcp.WithDescription(tool.Description)}
+		for _, param := range tool.Params {
+			opts = append(opts, mcp.WithString(param, mcp.Required()))
+		}
+		s.AddTool(mcp.NewTool(tool.Name, opts...), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			if spec.CallLog != "" {
+				logMu.Lock()
+				err := appendCall(spec.CallLog, Call{Tool: tool.Name, Arguments: args})
+				logMu.Unlock()
+				if err != nil {
+					return nil, err
+				}
+			}
+			text := tool.Result
+			if text == "" {
+				data, err := json.Marshal(args)
+				if err != nil {
+					return nil, err
+				}
+				text = string(data)
+			}
+			if tool.IsError {
+				return mcp.NewToolResultError(text), nil
+			}
+			return mcp.NewToolResultText(text), nil
+		})
+	}
+	return s
+}
+
+// Serve runs the server described by spec over in and out until ctx is
+// done or in is closed.
+func Serve(ctx context.Context, spec Spec, in io.Reader, out io.Writer) error {
+	return server.NewStdioServer(NewServer(spec)).Listen(ctx, in, out)
+}
+
+// MaybeServe turns the running test binary into the fake server when it
+// was started by StdioServer, and exits once the client hangs up. It
+// returns right away otherwise; call it first thing in TestMain.
+func MaybeServe() {
+	raw, ok := os.LookupEnv(SpecEnv)
+	if !ok {
+		return
+	}
+	var spec Spec
+	if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "mcptest: invalid %s: %v\n", SpecEnv, err)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := Serve(ctx, spec, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "mcptest: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// Server is a fake stdio server started by StdioServer.
+type Server struct {
+	// Config starts the server as a stdio MCP server.
+	Config  config.MCPServer
+	callLog string
+}
+
+// StdioServer returns a fake server whose config re-executes the test
+// binary with spec. The test package's TestMain must call MaybeServe.
+func StdioServer(t testing.TB, spec Spec) *Server {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("mcptest: locating test binary: %v", err)
+	}
+	spec.CallLog = filepath.Join(t.TempDir(), "calls.jsonl")
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("mcptest: encoding spec: %v", err)
+	}
+	return &Server{
+		Config: config.MCPServer{
+			Type:    config.MCPStdio,
+			Command: exe,
+			// Keeps a stray server from running the test suite if
+			// MaybeServe is missing.
+			Args: []string{"-test.run=^$"},
+			Env:  []string{SpecEnv + "=" + string(raw)},
+		},
+		callLog: spec.CallLog,
+	}
+}
+
+// Calls returns the tool calls the server has received so far, across
+// every process started from its config.
+func (s *Server) Calls() []Call {
+	f, err := os.Open(s.callLog)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var calls []Call
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var call Call
+		if json.Unmarshal(scanner.Bytes(), &call) == nil {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func appendCall(path string, call Call) error {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
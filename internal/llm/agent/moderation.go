@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/moderation"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// moderationToolName is the tool name override prompts are raised under.
+const moderationToolName = "moderation"
+
+// moderate runs the configured moderation over a response before its tool
+// calls execute. It returns the tool result content for the held-back
+// calls, or "" when they may run: either nothing was flagged or the user
+// chose to run them anyway. Sessions without a user to ask (auto-approve)
+// never override.
+func (a *agent) moderate(ctx context.Context, sessionID string, msg message.Message, toolCalls []message.ToolCall) string {
+	if a.moderator == nil || len(toolCalls) == 0 {
+		return ""
+	}
+	req := moderation.Request{
+		SessionID: sessionID,
+		AgentID:   string(a.agentID),
+		Text:      msg.Content().String(),
+	}
+	names := make([]string, 0, len(toolCalls))
+	for _, tc := range toolCalls {
+		req.ToolCalls = append(req.ToolCalls, moderation.ToolCall{ID: tc.ID, Name: tc.Name, Input: tc.Input})
+		names = append(names, tc.Name)
+	}
+	verdict, err := a.moderator.Check(ctx, req)
+	if err != nil {
+		logging.Warn("Moderation check failed", "session_id", sessionID, "error", err)
+	}
+	if !verdict.Flagged {
+		return ""
+	}
+	logging.Warn("Response flagged by moderation", "session_id", sessionID, "source", verdict.Source, "rule", verdict.Rule, "reason", verdict.Reason)
+
+	allowed := false
+	if !a.moderator.NoOverride && a.permissions != nil && !a.permissions.IsAutoApproveSession(sessionID) {
+		allowed = a.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolName:    moderationToolName,
+			Action:      "override",
+			Path:        config.WorkingDirectory(),
+			Description: fmt.Sprintf("Moderation flagged this response (%s). Run its tool calls anyway?\n\n%s", verdict.Reason, describeToolCalls(toolCalls)),
+		})
+	}
+	decision := "deny"
+	if allowed {
+		decision = "allow"
+	}
+	audit.Record(audit.Entry{
+		Kind:      audit.KindModeration,
+		SessionID: sessionID,
+		AgentID:   string(a.agentID),
+		Tool:      strings.Join(names, ","),
+		Action:    verdict.Rule,
+		Input:     verdict.Reason,
+		Decision:  decision,
+		Via:       verdict.Source,
+	})
+	if allowed {
+		return ""
+	}
+	return fmt.Sprintf("Blocked by moderation: %s. None of the tool calls in this response were run.", verdict.Reason)
+}
+
+func describeToolCalls(toolCalls []message.ToolCall) string {
+	var b strings.Builder
+	for _, tc := range toolCalls {
+		fmt.Fprintf(&b, "- %s: %s\n", tc.Name, truncateStr(tc.Input, 300))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
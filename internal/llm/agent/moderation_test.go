@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/moderation"
+	mock_permission "github.com/opencode-ai/opencode/internal/permission/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func newModeratedLoopAgent(t *testing.T, p *scriptedProvider) *agent {
+	t.Helper()
+	withFreshTaskRegistry(t)
+	a := newLoopAgent(t, p)
+	m, err := moderation.New(&config.ModerationConfig{Rules: []config.ModerationRule{
+		{Name: "done", Pattern: `"done"`, Tools: []string{"struct_output"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.moderator = m
+	return a
+}
+
+func TestProcessGeneration_ModerationBlocksFlaggedToolCalls(t *testing.T) {
+	p := &scriptedProvider{respond: func(call int) *provider.ProviderResponse {
+		if call == 1 {
+			return structOutputTurn()
+		}
+		return endTurn()
+	}}
+	a := newModeratedLoopAgent(t, p)
+
+	res := a.processGeneration(context.Background(), "sess-moderated", "produce the output", 0, nil, RunOptions{NonInteractive: true})
+
+	if res.Error != nil {
+		t.Fatalf("processGeneration error: %v", res.Error)
+	}
+	if res.StructOutput != nil && !res.StructOutput.IsError {
+		t.Fatalf("flagged struct_output ran: %+v", res.StructOutput)
+	}
+	msgs, _ := a.messages.List(context.Background(), "sess-moderated")
+	var blocked bool
+	for _, m := range msgs {
+		for _, tr := range m.ToolResults() {
+			blocked = blocked || (tr.IsError && strings.HasPrefix(tr.Content, "Blocked by moderation"))
+		}
+	}
+	if !blocked {
+		t.Error("no tool result reports the moderation block")
+	}
+	if got := p.callCount(); got != 2 {
+		t.Errorf("provider calls = %d, want 2 (blocked turn, then wrap-up)", got)
+	}
+}
+
+func TestProcessGeneration_ModerationUserOverride(t *testing.T) {
+	p := &scriptedProvider{respond: func(call int) *provider.ProviderResponse {
+		return structOutputTurn()
+	}}
+	a := newModeratedLoopAgent(t, p)
+	ctrl := gomock.NewController(t)
+	perms := mock_permission.NewMockService(ctrl)
+	perms.EXPECT().IsAutoApproveSession("sess-override").Return(false)
+	perms.EXPECT().Request(gomock.Any(), gomock.Any()).Return(true)
+	a.permissions = perms
+
+	res := a.processGeneration(context.Background(), "sess-override", "produce the output", 0, nil, RunOptions{NonInteractive: true})
+
+	if res.Error != nil {
+		t.Fatalf("processGeneration error: %v", res.Error)
+	}
+	if res.StructOutput == nil || res.StructOutput.IsError {
+		t.Fatalf("overridden struct_output should have run, got %+v", res.StructOutput)
+	}
+}
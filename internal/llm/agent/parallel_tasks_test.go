@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// concurrentTaskTool stands in for the task tool and records how many of
+// its calls ran at the same time.
+type concurrentTaskTool struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+	calls   int
+	hold    time.Duration
+}
+
+func (t *concurrentTaskTool) Info() tools.ToolInfo { return tools.ToolInfo{Name: TaskToolName} }
+
+func (t *concurrentTaskTool) AllowParallelism(tools.ToolCall, []tools.ToolCall) bool { return true }
+
+func (t *concurrentTaskTool) IsBaseline() bool { return true }
+
+func (t *concurrentTaskTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	t.mu.Lock()
+	t.running++
+	t.calls++
+	t.peak = max(t.peak, t.running)
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.running--
+		t.mu.Unlock()
+	}()
+	select {
+	case <-time.After(t.hold):
+	case <-ctx.Done():
+		return tools.ToolResponse{}, ctx.Err()
+	}
+	return tools.NewTextResponse("done " + call.ID), nil
+}
+
+// stats returns the number of calls started and the peak concurrency.
+func (t *concurrentTaskTool) stats() (calls, peak int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls, t.peak
+}
+
+func newParallelTaskAgent(t *testing.T, calls, limit int) (*agent, *concurrentTaskTool) {
+	t.Helper()
+	withFreshTaskRegistry(t)
+	p := &scriptedProvider{respond: func(n int) *provider.ProviderResponse {
+		if n > 1 {
+			return endTurn()
+		}
+		var toolCalls []message.ToolCall
+		for i := range calls {
+			toolCalls = append(toolCalls, message.ToolCall{ID: fmt.Sprintf("call-%d", i), Name: TaskToolName, Input: fmt.Sprintf(`{"prompt":"part %d"}`, i), Finished: true})
+		}
+		return &provider.ProviderResponse{ToolCalls: toolCalls, FinishReason: message.FinishReasonToolUse}
+	}}
+	a := newLoopAgent(t, p)
+	task := &concurrentTaskTool{hold: 50 * time.Millisecond}
+	toolsCh := make(chan tools.BaseTool, 1)
+	toolsCh <- task
+	close(toolsCh)
+	a.toolsCh = toolsCh
+	a.allowParallelism = true
+	a.maxParallelTasks = limit
+	return a, task
+}
+
+func TestParallelTaskCallsAreBounded(t *testing.T) {
+	a, task := newParallelTaskAgent(t, 5, 2)
+
+	res := a.processGeneration(context.Background(), "sess-parallel", "fan out", 0, nil, RunOptions{})
+	if res.Error != nil {
+		t.Fatalf("run failed: %v", res.Error)
+	}
+
+	calls, peak := task.stats()
+	if calls != 5 {
+		t.Fatalf("task calls = %d, want 5", calls)
+	}
+	if peak != 2 {
+		t.Errorf("peak concurrent task calls = %d, want 2", peak)
+	}
+	msgs, err := a.messages.List(context.Background(), "sess-parallel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []message.ToolResult
+	for _, msg := range msgs {
+		results = append(results, msg.ToolResults()...)
+	}
+	if len(results) != 5 {
+		t.Fatalf("tool results = %d, want one per call", len(results))
+	}
+	for i, r := range results {
+		if want := fmt.Sprintf("done call-%d", i); r.Content != want || r.IsError {
+			t.Errorf("result %d = %q (error %v), want %q", i, r.Content, r.IsError, want)
+		}
+	}
+}
+
+func TestParallelTaskCallsStopWithTheTurn(t *testing.T) {
+	a, task := newParallelTaskAgent(t, 4, 1)
+	task.hold = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	a.processGeneration(ctx, "sess-cancel", "fan out", 0, nil, RunOptions{})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("turn took %v after cancellation", elapsed)
+	}
+
+	if calls, _ := task.stats(); calls != 1 {
+		t.Errorf("task calls started = %d, want only the one holding the slot", calls)
+	}
+	msgs, err := a.messages.List(context.Background(), "sess-cancel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceled := 0
+	for _, msg := range msgs {
+		for _, r := range msg.ToolResults() {
+			if r.IsError && r.Content == "Tool execution canceled by user" {
+				canceled++
+			}
+		}
+	}
+	if canceled != 4 {
+		t.Errorf("canceled results = %d, want 4", canceled)
+	}
+}
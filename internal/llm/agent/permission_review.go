@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// permissionReviewer implements permission.Reviewer by asking the agent
+// configured under permission.review.agent for a verdict.
+type permissionReviewer struct {
+	agentID  config.AgentName
+	tools    []string
+	provider provider.Provider
+}
+
+// NewPermissionReviewer builds a reviewer from the permission.review
+// config block. The reviewer agent's own prompt carries the policy; the
+// response format is fixed by prompts/permission_review.md.
+func NewPermissionReviewer(cfg *config.PermissionReviewConfig) (permission.Reviewer, error) {
+	p, err := createAgentProvider(cfg.Agent, withDisableCache())
+	if err != nil {
+		return nil, fmt.Errorf("creating reviewer agent %q: %w", cfg.Agent, err)
+	}
+	return &permissionReviewer{agentID: cfg.Agent, tools: cfg.Tools, provider: p}, nil
+}
+
+func (r *permissionReviewer) Reviews(toolName string) bool {
+	if len(r.tools) == 0 {
+		return true
+	}
+	for _, pattern := range r.tools {
+		if permission.MatchWildcard(pattern, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *permissionReviewer) Review(ctx context.Context, req permission.PermissionRequest) (permission.ReviewDecision, string, error) {
+	instructions, err := AgentPrompts.ReadFile("prompts/permission_review.md")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load review prompt: %w", err)
+	}
+	params, err := json.MarshalIndent(req.Params, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode request params: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.Write(instructions)
+	fmt.Fprintf(&sb, "\n## Request\n\nTool: %s\nAction: %s\nDirectory: %s\nDescription: %s\n\nParameters:\n```json\n%s\n```\n",
+		req.ToolName, req.Action, req.Path, req.Description, params)
+
+	ctx = context.WithValue(ctx, tools.AgentIDContextKey, r.agentID)
+	response, err := r.provider.SendMessages(
+		ctx,
+		[]message.Message{{
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: sb.String()}},
+		}},
+		make([]tools.BaseTool, 0),
+	)
+	if err != nil {
+		return "", "", err
+	}
+	return parseReviewVerdict(response.Content)
+}
+
+// parseReviewVerdict extracts the JSON verdict from the reviewer's reply,
+// tolerating surrounding prose or a Markdown code fence.
+func parseReviewVerdict(content string) (permission.ReviewDecision, string, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return "", "", fmt.Errorf("reviewer returned no JSON verdict: %q", truncateStr(content, 200))
+	}
+	var verdict struct {
+		Decision string `json:"decision"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &verdict); err != nil {
+		return "", "", fmt.Errorf("invalid reviewer verdict: %w", err)
+	}
+	decision := permission.ReviewDecision(strings.ToLower(strings.TrimSpace(verdict.Decision)))
+	switch decision {
+	case permission.ReviewApprove, permission.ReviewDeny, permission.ReviewEscalate:
+		return decision, verdict.Reason, nil
+	default:
+		return "", "", fmt.Errorf("unknown reviewer decision %q", verdict.Decision)
+	}
+}
@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+func TestParseReviewVerdict(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    permission.ReviewDecision
+		wantErr bool
+	}{
+		{`{"decision":"approve","reason":"read-only"}`, permission.ReviewApprove, false},
+		{"```json\n{\"decision\": \"DENY\", \"reason\": \"rm -rf\"}\n```", permission.ReviewDeny, false},
+		{`Sure. {"decision":"escalate","reason":"unclear"} Thanks`, permission.ReviewEscalate, false},
+		{`{"decision":"maybe"}`, "", true},
+		{`no json here`, "", true},
+	}
+	for _, tc := range cases {
+		got, _, err := parseReviewVerdict(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("parseReviewVerdict(%q) err = %v, wantErr %v", tc.in, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Fatalf("parseReviewVerdict(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
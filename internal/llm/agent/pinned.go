@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// keepPinned re-creates the pinned messages of the compacted history right
+// after the new summary, so requirements the user pinned (or results a
+// tool flagged) reach the model verbatim instead of through the summary.
+// Only messages after the previous summary are looked at: pinned messages
+// from before it were already carried over by the previous compaction.
+// The copies are pinned too and survive later compactions the same way.
+func (a *agent) keepPinned(ctx context.Context, sessionID string, msgs []message.Message, prevSummaryID string) {
+	for _, msg := range pinnedSince(msgs, prevSummaryID) {
+		parts := pinnedParts(msg)
+		if len(parts) == 0 {
+			continue
+		}
+		parts = append(parts, message.Pin{Time: time.Now().Unix()})
+		if _, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+			Role:  message.User,
+			Parts: parts,
+		}); err != nil {
+			logging.Warn("Failed to keep pinned message through compaction", "session_id", sessionID, "message_id", msg.ID, "error", err)
+		}
+	}
+}
+
+// pinnedSince returns the pinned messages after prevSummaryID, or all of
+// them when there is no previous summary.
+func pinnedSince(msgs []message.Message, prevSummaryID string) []message.Message {
+	start := 0
+	for i, msg := range msgs {
+		if msg.ID == prevSummaryID {
+			start = i + 1
+			break
+		}
+	}
+	var pinned []message.Message
+	for _, msg := range msgs[start:] {
+		if msg.IsPinned() {
+			pinned = append(pinned, msg)
+		}
+	}
+	return pinned
+}
+
+// pinnedParts returns the parts a pinned message is carried over with. The
+// copy is a user message, so tool results are rendered as text: a tool
+// result without its tool call cannot be replayed. When a tool flagged
+// some of its results, only those are kept.
+func pinnedParts(msg message.Message) []message.ContentPart {
+	var parts []message.ContentPart
+	if msg.Role != message.Tool {
+		for _, part := range msg.Parts {
+			switch part.(type) {
+			case message.TextContent, message.BinaryContent, message.ImageURLContent:
+				parts = append(parts, part)
+			}
+		}
+		return parts
+	}
+
+	results := msg.ToolResults()
+	var flagged []message.ToolResult
+	for _, tr := range results {
+		if meta, ok := tools.ParseResultMetadata(tr.Metadata); ok && meta.Pinned {
+			flagged = append(flagged, tr)
+		}
+	}
+	if len(flagged) > 0 {
+		results = flagged
+	}
+	for _, tr := range results {
+		parts = append(parts, message.TextContent{
+			Text: fmt.Sprintf("Pinned result of the %s tool call %s:\n\n%s", tr.Name, tr.ToolCallID, tr.Content),
+		})
+	}
+	return parts
+}
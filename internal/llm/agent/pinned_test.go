@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createRecordingMessages struct {
+	message.Service
+	created []message.CreateMessageParams
+}
+
+func (r *createRecordingMessages) Create(_ context.Context, sessionID string, params message.CreateMessageParams) (message.Message, error) {
+	r.created = append(r.created, params)
+	return message.Message{SessionID: sessionID, Role: params.Role, Parts: params.Parts}, nil
+}
+
+func pinnedMessage(id string, role message.MessageRole, parts ...message.ContentPart) message.Message {
+	msg := message.Message{ID: id, Role: role, Parts: parts}
+	msg.SetPinned(true)
+	return msg
+}
+
+func TestKeepPinned(t *testing.T) {
+	flagged, _ := tools.StampResultMetadata(tools.ToolCall{ID: "c1", Name: "fetch"}, `{"pin":true}`, "spec text", false, time.Time{})
+	msgs := []message.Message{
+		pinnedMessage("old", message.User, message.TextContent{Text: "carried by the previous summary"}),
+		{ID: "summary", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "summary"}}},
+		pinnedMessage("req", message.User, message.TextContent{Text: "Never touch the migrations."}),
+		{ID: "chat", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "not pinned"}}},
+		pinnedMessage("tool", message.Tool,
+			message.ToolResult{ToolCallID: "c0", Name: "ls", Content: "noise"},
+			message.ToolResult{ToolCallID: "c1", Name: "fetch", Content: "spec text", Metadata: flagged},
+		),
+	}
+
+	rec := &createRecordingMessages{}
+	a := &agent{Broker: pubsub.NewBroker[AgentEvent](), messages: rec}
+	a.keepPinned(context.Background(), "sess", msgs, "summary")
+
+	require.Len(t, rec.created, 2)
+	first := message.Message{Role: rec.created[0].Role, Parts: rec.created[0].Parts}
+	assert.Equal(t, message.User, first.Role)
+	assert.Equal(t, "Never touch the migrations.", first.Content().Text)
+	assert.True(t, first.IsPinned(), "copies stay pinned for the next compaction")
+
+	second := message.Message{Role: rec.created[1].Role, Parts: rec.created[1].Parts}
+	assert.Equal(t, message.User, second.Role)
+	assert.Empty(t, second.ToolResults(), "tool results are carried as text")
+	assert.Contains(t, second.Content().Text, "spec text")
+	assert.NotContains(t, second.Content().Text, "noise", "only the flagged result is kept")
+}
+
+func TestPinnedSinceWithoutSummary(t *testing.T) {
+	msgs := []message.Message{
+		pinnedMessage("a", message.User, message.TextContent{Text: "a"}),
+		{ID: "b", Role: message.User},
+	}
+	got := pinnedSince(msgs, "")
+	require.Len(t, got, 1)
+	assert.Equal(t, "a", got[0].ID)
+}
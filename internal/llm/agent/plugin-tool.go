@@ -0,0 +1,318 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// Plugin tools are executables dropped into a tools directory. Each one
+// speaks a two-command JSON protocol (see docs/plugins.md):
+//
+//	<exe> describe            → {"tools": [{name, description, parameters, required}]}
+//	<exe> run  (request JSON) → {"content": "...", "is_error": false}
+//
+// Every call spawns a fresh process, like hooks, so a plugin needs no
+// server loop and can be written in any language.
+const (
+	pluginDescribeTimeout = 10 * time.Second
+	pluginCallTimeout     = 5 * time.Minute
+	// pluginMaxOutput caps what is read from a plugin's stdout; the tool
+	// response is truncated further by tools.NewTextResponse.
+	pluginMaxOutput = 4 << 20
+)
+
+// pluginDescription is what `<exe> describe` prints.
+type pluginDescription struct {
+	Tools []pluginToolInfo `json:"tools"`
+}
+
+type pluginToolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+	Required    []string       `json:"required"`
+}
+
+// pluginRunRequest is written to the stdin of `<exe> run`.
+type pluginRunRequest struct {
+	Tool      string          `json:"tool"`
+	Input     json.RawMessage `json:"input"`
+	SessionID string          `json:"session_id"`
+	MessageID string          `json:"message_id"`
+	Agent     string          `json:"agent"`
+	CWD       string          `json:"cwd"`
+}
+
+// pluginRunResponse is what `<exe> run` prints on success. Output that
+// isn't a JSON object is used as the content verbatim.
+type pluginRunResponse struct {
+	Content string `json:"content"`
+	IsError bool   `json:"is_error"`
+}
+
+type pluginTool struct {
+	path        string
+	info        pluginToolInfo
+	permissions permission.Service
+	reg         agentregistry.Registry
+}
+
+// pluginCacheEntry remembers an executable's description until the file
+// changes, so building the tool set for every agent doesn't re-run
+// `describe` for every plugin.
+type pluginCacheEntry struct {
+	modTime time.Time
+	size    int64
+	tools   []pluginToolInfo
+}
+
+var pluginCache sync.Map // path → pluginCacheEntry
+
+// pluginToolDirs lists the directories scanned for plugin executables,
+// global first so a project plugin can replace a global one of the same
+// tool name.
+func pluginToolDirs() []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "opencode", "tools"))
+	}
+	if wd := config.WorkingDirectory(); wd != "" {
+		dirs = append(dirs, filepath.Join(wd, ".opencode", "tools"))
+	}
+	return dirs
+}
+
+// LoadPluginTools describes every executable in the plugin directories
+// and returns one tool per advertised name. Plugins that fail to
+// describe themselves are logged and skipped. In read-only mode no
+// plugin is run at all: they are arbitrary executables, so nothing
+// vouches that they only read.
+func LoadPluginTools(ctx context.Context, permissions permission.Service, reg agentregistry.Registry) []tools.BaseTool {
+	if cfg := config.Get(); cfg != nil && cfg.ReadOnly {
+		return nil
+	}
+	byName := make(map[string]*pluginTool)
+	for _, dir := range pluginToolDirs() {
+		for _, path := range pluginExecutables(dir) {
+			infos, err := describePlugin(ctx, path)
+			if err != nil {
+				logging.Warn("Skipping plugin tool", "path", path, "error", err)
+				continue
+			}
+			for _, info := range infos {
+				if prev, ok := byName[info.Name]; ok {
+					logging.Debug("Plugin tool overridden", "tool", info.Name, "by", path, "previous", prev.path)
+				}
+				byName[info.Name] = &pluginTool{path: path, info: info, permissions: permissions, reg: reg}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]tools.BaseTool, 0, len(names))
+	for _, name := range names {
+		result = append(result, byName[name])
+	}
+	return result
+}
+
+// pluginExecutables returns the executable regular files directly inside
+// dir, skipping hidden files. A missing directory yields nothing.
+func pluginExecutables(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		st, err := os.Stat(path) // follows symlinks
+		if err != nil || !st.Mode().IsRegular() || st.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func describePlugin(ctx context.Context, path string) ([]pluginToolInfo, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := pluginCache.Load(path); ok {
+		entry := v.(pluginCacheEntry)
+		if entry.modTime.Equal(st.ModTime()) && entry.size == st.Size() {
+			return entry.tools, nil
+		}
+	}
+
+	describeCtx, cancel := context.WithTimeout(ctx, pluginDescribeTimeout)
+	defer cancel()
+	stdout, err := runPlugin(describeCtx, path, "describe", nil)
+	if err != nil {
+		return nil, err
+	}
+	var desc pluginDescription
+	if err := json.Unmarshal(stdout, &desc); err != nil {
+		return nil, fmt.Errorf("invalid describe output: %w", err)
+	}
+	infos := make([]pluginToolInfo, 0, len(desc.Tools))
+	for _, info := range desc.Tools {
+		if info.Name == "" {
+			logging.Warn("Plugin advertised a tool without a name", "path", path)
+			continue
+		}
+		if info.Parameters == nil {
+			info.Parameters = map[string]any{}
+		}
+		if info.Required == nil {
+			info.Required = []string{}
+		}
+		infos = append(infos, info)
+	}
+	pluginCache.Store(path, pluginCacheEntry{modTime: st.ModTime(), size: st.Size(), tools: infos})
+	return infos, nil
+}
+
+// runPlugin runs `<path> <command>` with stdin and returns its stdout. A
+// non-zero exit is an error carrying the plugin's stderr.
+func runPlugin(ctx context.Context, path, command string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, path, command)
+	cmd.Dir = config.WorkingDirectory()
+	cmd.Env = append(os.Environ(), "OPENCODE_PROJECT_DIR="+config.WorkingDirectory())
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.WaitDelay = 2 * time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &stdout, n: pluginMaxOutput}
+	cmd.Stderr = &limitedWriter{w: &stderr, n: 64 << 10}
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", err, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// limitedWriter discards everything past n bytes.
+type limitedWriter struct {
+	w *bytes.Buffer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if remaining := l.n - l.w.Len(); remaining > 0 {
+		l.w.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+func (p *pluginTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{
+		Name:        p.info.Name,
+		Description: p.info.Description,
+		Parameters:  p.info.Parameters,
+		Required:    p.info.Required,
+	}
+}
+
+func (p *pluginTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolResponse, error) {
+	sessionID, messageID := tools.GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return tools.ToolResponse{}, fmt.Errorf("session ID and message ID are required for running a plugin tool")
+	}
+	agentID := string(tools.GetAgentID(ctx))
+
+	switch p.reg.EvaluatePermission(agentID, p.info.Name, params.Input) {
+	case permission.ActionAllow:
+	case permission.ActionDeny:
+		return tools.NewEmptyResponse(), permission.ErrorPermissionDenied
+	default:
+		granted := p.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        config.WorkingDirectory(),
+			Pattern:     params.Input,
+			ToolName:    p.info.Name,
+			Action:      "execute",
+			Description: fmt.Sprintf("execute plugin tool %s with the following parameters: %s", p.info.Name, params.Input),
+			Params:      params.Input,
+		})
+		if !granted {
+			return tools.NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+	}
+
+	input := json.RawMessage(params.Input)
+	if !json.Valid(input) {
+		return tools.NewTextErrorResponse("error parsing parameters: input is not valid JSON"), nil
+	}
+	req, err := json.Marshal(pluginRunRequest{
+		Tool:      p.info.Name,
+		Input:     input,
+		SessionID: sessionID,
+		MessageID: messageID,
+		Agent:     agentID,
+		CWD:       config.WorkingDirectory(),
+	})
+	if err != nil {
+		return tools.ToolResponse{}, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	stdout, err := runPlugin(callCtx, p.path, "run", req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return tools.ToolResponse{}, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return tools.NewTextErrorResponse(fmt.Sprintf("plugin tool %q timed out after %s", p.info.Name, pluginCallTimeout)), nil
+		}
+		return tools.NewTextErrorResponse(fmt.Sprintf("plugin tool %q failed: %s", p.info.Name, err)), nil
+	}
+
+	var resp pluginRunResponse
+	if trimmed := bytes.TrimSpace(stdout); len(trimmed) > 0 && trimmed[0] == '{' && json.Unmarshal(trimmed, &resp) == nil {
+		if resp.IsError {
+			return tools.NewTextErrorResponse(resp.Content), nil
+		}
+		return tools.NewTextResponse(resp.Content), nil
+	}
+	return tools.NewTextResponse(string(stdout)), nil
+}
+
+func (p *pluginTool) AllowParallelism(tools.ToolCall, []tools.ToolCall) bool { return false }
+
+func (p *pluginTool) IsBaseline() bool { return false }
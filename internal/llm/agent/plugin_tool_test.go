@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type grantPermissions struct {
+	permission.Service
+}
+
+func (grantPermissions) Request(context.Context, permission.CreatePermissionRequest) bool {
+	return true
+}
+
+const echoPlugin = `#!/bin/sh
+case "$1" in
+describe)
+  echo '{"tools":[{"name":"echo_input","description":"Echo the request","parameters":{"text":{"type":"string"}},"required":["text"]}]}'
+  ;;
+run)
+  input=$(cat)
+  case "$input" in
+    *'"fail"'*) echo "boom" >&2; exit 1 ;;
+  esac
+  printf '{"content":%s}' "$(printf '%s' "$input" | sed 's/\\/\\\\/g; s/"/\\"/g; s/^/"/; s/$/"/')"
+  ;;
+esac
+`
+
+func withPluginDir(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test scripts are POSIX shell")
+	}
+	t.Setenv("HOME", t.TempDir())
+	if config.Get() == nil {
+		_, err := config.Load(t.TempDir(), false)
+		require.NoError(t, err)
+	}
+	cfg := config.Get()
+	dir := t.TempDir()
+	prevWD := cfg.WorkingDir
+	cfg.WorkingDir = dir
+	t.Cleanup(func() { cfg.WorkingDir = prevWD })
+
+	toolsDir := filepath.Join(dir, ".opencode", "tools")
+	require.NoError(t, os.MkdirAll(toolsDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "echo"), []byte(echoPlugin), 0o755))
+	// Not executable: ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "README.md"), []byte("notes"), 0o644))
+	return dir
+}
+
+func TestLoadPluginTools(t *testing.T) {
+	dir := withPluginDir(t)
+	loaded := LoadPluginTools(context.Background(), grantPermissions{}, agentregistry.GetRegistry())
+	require.Len(t, loaded, 1)
+
+	info := loaded[0].Info()
+	assert.Equal(t, "echo_input", info.Name)
+	assert.Equal(t, []string{"text"}, info.Required)
+	assert.False(t, loaded[0].IsBaseline())
+
+	ctx := context.WithValue(context.Background(), tools.SessionIDContextKey, "sess")
+	ctx = context.WithValue(ctx, tools.MessageIDContextKey, "msg")
+	resp, err := loaded[0].Run(ctx, tools.ToolCall{ID: "c1", Name: "echo_input", Input: `{"text":"hi"}`})
+	require.NoError(t, err)
+	assert.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, `"tool":"echo_input"`)
+	assert.Contains(t, resp.Content, `"input":{"text":"hi"}`)
+	assert.Contains(t, resp.Content, `"session_id":"sess"`)
+	assert.Contains(t, resp.Content, `"cwd":"`+dir+`"`)
+
+	resp, err = loaded[0].Run(ctx, tools.ToolCall{ID: "c2", Name: "echo_input", Input: `{"text":"fail"}`})
+	require.NoError(t, err)
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "boom")
+}
+
+func TestLoadPluginToolsReadOnly(t *testing.T) {
+	withPluginDir(t)
+	cfg := config.Get()
+	cfg.ReadOnly = true
+	t.Cleanup(func() { cfg.ReadOnly = false })
+
+	assert.Empty(t, LoadPluginTools(context.Background(), grantPermissions{}, agentregistry.GetRegistry()))
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Prompts that ask the model to work something out rather than carry out a
+// spelled-out step. Matched as lower-case substrings, so "debug" also
+// covers "debugging" and "optimi" both spellings of "optimise".
+var deepReasoningCues = []string{
+	"architect", "design", "debug", "root cause", "investigate", "diagnose",
+	"why ", "why?", "how does", "how do ", "explain", "trade-off", "tradeoff",
+	"refactor", "plan ", "approach", "race condition", "deadlock", "leak",
+	"optimi", "performance", "security", "vulnerab", "crash", "panic:",
+	"traceback", "stack trace", "doesn't work", "does not work", "not working",
+	"flaky", "think",
+}
+
+// Openers of short instructions that need no deliberation. A prompt only
+// counts as simple when it is also short and has none of the cues above.
+var simpleInstructionOpeners = []string{
+	"run", "commit", "push", "rename", "format", "list", "show", "open",
+	"read", "delete", "remove", "add", "bump", "install", "continue",
+	"go ahead", "proceed", "yes", "no", "ok", "thanks", "thank you", "lgtm",
+}
+
+const (
+	// simplePromptMaxRunes bounds prompts that may be classified as simple.
+	simplePromptMaxRunes = 160
+	// longPromptMinRunes marks prompts long enough to warrant high effort
+	// on their own, such as pasted logs or multi-part specifications.
+	longPromptMinRunes = 2000
+)
+
+// turnReasoningEffort picks the reasoning effort for a turn of an agent
+// configured with reasoningEffort "auto". Turns without a user prompt react
+// to tool or background task results and run at low effort; prompts are
+// classified with cheap keyword and length heuristics so that the choice
+// costs no extra model call.
+func turnReasoningEffort(prompt string, hasUserTurn bool) string {
+	if !hasUserTurn {
+		return "low"
+	}
+	text := strings.ToLower(strings.TrimSpace(prompt))
+	n := utf8.RuneCountInString(text)
+	if n >= longPromptMinRunes || strings.Contains(text, "```") {
+		return "high"
+	}
+	for _, cue := range deepReasoningCues {
+		if strings.Contains(text, cue) {
+			return "high"
+		}
+	}
+	if n <= simplePromptMaxRunes && !strings.Contains(text, "\n") {
+		for _, opener := range simpleInstructionOpeners {
+			if rest, ok := strings.CutPrefix(text, opener); ok && !startsWithLetter(rest) {
+				return "low"
+			}
+		}
+	}
+	return "medium"
+}
+
+func startsWithLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
@@ -0,0 +1,29 @@
+package agent
+
+import "testing"
+
+func TestTurnReasoningEffort(t *testing.T) {
+	cases := []struct {
+		name        string
+		prompt      string
+		hasUserTurn bool
+		want        string
+	}{
+		{"auto-resume", "", false, "low"},
+		{"short instruction", "run the tests", true, "low"},
+		{"acknowledgement", "ok, go ahead", true, "low"},
+		{"opener must be a whole word", "notice the header in main.go and update the copyright year", true, "medium"},
+		{"debugging", "The server crashes on startup, can you debug it?", true, "high"},
+		{"design", "Design a caching layer for the API client", true, "high"},
+		{"short but asks why", "why is this slow?", true, "high"},
+		{"pasted code", "add this:\n```go\nfunc f() {}\n```", true, "high"},
+		{"ordinary task", "Update the README table with the new flag and its default", true, "medium"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := turnReasoningEffort(tc.prompt, tc.hasUserTurn); got != tc.want {
+				t.Errorf("turnReasoningEffort(%q) = %q, want %q", tc.prompt, got, tc.want)
+			}
+		})
+	}
+}
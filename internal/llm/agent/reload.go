@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Reload rebuilds the agent's providers from the current configuration and
+// agent registry, after a profile switch replaced both. Nothing changes
+// when one of them can't be built. The tool set is kept: the tools read
+// their permissions from the registry on every call.
+func (a *agent) Reload() (models.Model, error) {
+	if a.IsBusy() {
+		return models.Model{}, ErrAgentBusy
+	}
+	info, ok := agentregistry.GetRegistry().Get(a.agentID)
+	if !ok {
+		return models.Model{}, fmt.Errorf("agent %s is not configured", a.agentID)
+	}
+
+	agentProvider, err := createAgentProvider(a.agentID, a.providerOpts...)
+	if err != nil {
+		return models.Model{}, err
+	}
+	var titleProvider, summarizeProvider, translateProvider provider.Provider
+	if info.Mode == config.AgentModeAgent {
+		if summarizeProvider, err = createAgentProvider(config.AgentSummarizer, withDisableCache()); err != nil {
+			return models.Model{}, err
+		}
+		if titleProvider, err = createAgentProvider(config.AgentDescriptor, withDisableCache()); err != nil {
+			return models.Model{}, err
+		}
+		if cfg := config.Get(); cfg.Translation != nil && cfg.Translation.Language != "" {
+			if translateProvider, err = createAgentProvider(config.AgentTranslator, withDisableCache()); err != nil {
+				logging.Warn("Failed to create translator provider, responses will not be translated", "error", err)
+				translateProvider = nil
+			}
+		}
+	}
+
+	a.provider = agentProvider
+	a.titleProvider = titleProvider
+	a.summarizeProvider = summarizeProvider
+	a.translateProvider = translateProvider
+	a.fallbackModels = modelIDs(info.FallbackModels)
+	a.autoReasoning = strings.EqualFold(info.ReasoningEffort, config.ReasoningEffortAuto)
+	a.routedProviders = sync.Map{}
+	return a.provider.Model(), nil
+}
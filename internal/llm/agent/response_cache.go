@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/project"
+)
+
+// cachedResponse is a task tool answer stored by the subagent response
+// cache, see config.Agent.ResponseCache.
+type cachedResponse struct {
+	Content        string `json:"content"`
+	IsStructOutput bool   `json:"is_struct_output"`
+	// TaskID is the session that produced the answer; it can still be
+	// resumed.
+	TaskID    string `json:"task_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// responseCache is where one subagent's answers for one workspace state
+// are looked up and stored. The zero value disables caching.
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// newResponseCache returns the cache for subagentType, or the zero value
+// when the agent has no responseCache config or the working directory is
+// not a git repository.
+func newResponseCache(ctx context.Context, subagentType string) responseCache {
+	cfg := config.Get()
+	if cfg == nil {
+		return responseCache{}
+	}
+	agentCfg, ok := cfg.Agents[config.AgentName(subagentType)]
+	if !ok || agentCfg.ResponseCache == nil {
+		return responseCache{}
+	}
+	ttl, err := config.ParseDurationExtended(agentCfg.ResponseCache.TTL)
+	if err != nil {
+		logging.Warn("task tool: ignoring invalid responseCache.ttl", "agent", subagentType, "err", err)
+		return responseCache{}
+	}
+	workDir := config.WorkingDirectory()
+	workspace := project.WorkspaceHash(ctx, workDir)
+	if workspace == "" {
+		return responseCache{}
+	}
+	dataDir := cfg.Data.Directory
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(workDir, dataDir)
+	}
+	return responseCache{
+		dir: filepath.Join(dataDir, "response-cache", subagentType, workspace),
+		ttl: ttl,
+	}
+}
+
+func (c responseCache) enabled() bool { return c.dir != "" }
+
+func (c responseCache) path(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the stored answer for prompt unless it has expired.
+func (c responseCache) get(prompt string) (cachedResponse, bool) {
+	if !c.enabled() {
+		return cachedResponse{}, false
+	}
+	path := c.path(prompt)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cachedResponse{}, false
+	}
+	var r cachedResponse
+	if err := json.Unmarshal(data, &r); err != nil {
+		return cachedResponse{}, false
+	}
+	if time.Since(time.Unix(r.CreatedAt, 0)) > c.ttl {
+		_ = os.Remove(path)
+		return cachedResponse{}, false
+	}
+	return r, true
+}
+
+func (c responseCache) put(prompt string, r cachedResponse) {
+	if !c.enabled() {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		logging.Warn("task tool: creating response cache dir failed", "dir", c.dir, "err", err)
+		return
+	}
+	if err := os.WriteFile(c.path(prompt), data, 0o644); err != nil {
+		logging.Warn("task tool: writing response cache failed", "dir", c.dir, "err", err)
+	}
+}
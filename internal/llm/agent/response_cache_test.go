@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := responseCache{dir: t.TempDir(), ttl: time.Hour}
+
+	if _, ok := cache.get("where is the router?"); ok {
+		t.Fatal("get() hit on an empty cache")
+	}
+	cache.put("where is the router?", cachedResponse{Content: "internal/api/server.go", TaskID: "task-1", CreatedAt: time.Now().Unix()})
+	got, ok := cache.get("where is the router?")
+	if !ok || got.Content != "internal/api/server.go" || got.TaskID != "task-1" {
+		t.Fatalf("get() = %+v, %v; want the stored response", got, ok)
+	}
+	if _, ok := cache.get("where is the router"); ok {
+		t.Fatal("get() hit for a different prompt")
+	}
+
+	cache.put("stale", cachedResponse{Content: "old", CreatedAt: time.Now().Add(-2 * time.Hour).Unix()})
+	if _, ok := cache.get("stale"); ok {
+		t.Fatal("get() returned a response older than the TTL")
+	}
+
+	var disabled responseCache
+	disabled.put("where is the router?", cachedResponse{Content: "x", CreatedAt: time.Now().Unix()})
+	if _, ok := disabled.get("where is the router?"); ok {
+		t.Fatal("zero responseCache should never hit")
+	}
+}
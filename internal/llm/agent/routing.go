@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Requests for a summary rather than a change. Matched as lower-case
+// substrings like the reasoning cues.
+var summarizationCues = []string{
+	"summarize", "summarise", "summary", "tl;dr", "tldr", "recap",
+	"give me an overview", "what changed", "what did we do", "changelog",
+	"release notes",
+}
+
+// Requests whose changes span much of the codebase.
+var largeRefactorCues = []string{
+	"refactor", "migrate", "migration", "rewrite", "restructure",
+	"re-architect", "rearchitect", "across the codebase", "across the repo",
+	"throughout the codebase", "every file", "all files", "all the files",
+	"whole codebase", "entire codebase", "split the package", "extract a package",
+	"upgrade to", "port to",
+}
+
+// Openers of questions. The prompt also has to be free of edit verbs so
+// that "can you fix the test?" still counts as an edit.
+var questionOpeners = []string{
+	"what", "why", "how", "where", "which", "who", "when", "is ", "are ",
+	"does", "do ", "can ", "could ", "should ", "explain", "tell me",
+}
+
+// Verbs asking for a change, matched as whole words so that "where is it
+// created" stays a question.
+var editVerbs = []string{
+	"fix", "add", "implement", "change", "update", "write", "create",
+	"remove", "delete", "rename", "edit", "modify", "replace", "make",
+	"move", "convert", "bump",
+}
+
+// quickQuestionMaxRunes bounds prompts that may be classified as quick
+// questions; longer ones usually carry context that needs a stronger model.
+const quickQuestionMaxRunes = 300
+
+// classifyRequest sorts a user prompt into a routing class with keyword and
+// length heuristics, so routing costs no extra model call.
+func classifyRequest(prompt string) config.RouteClass {
+	text := strings.ToLower(strings.TrimSpace(prompt))
+	n := utf8.RuneCountInString(text)
+	containsAny := func(cues []string) bool {
+		for _, cue := range cues {
+			if strings.Contains(text, cue) {
+				return true
+			}
+		}
+		return false
+	}
+
+	asksForChange := slices.ContainsFunc(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}), func(word string) bool {
+		return slices.Contains(editVerbs, word)
+	})
+
+	if containsAny(summarizationCues) && !asksForChange {
+		return config.RouteSummarization
+	}
+	if n >= longPromptMinRunes || containsAny(largeRefactorCues) {
+		return config.RouteLargeRefactor
+	}
+	if n <= quickQuestionMaxRunes && !strings.Contains(text, "```") && !asksForChange {
+		if strings.HasSuffix(text, "?") {
+			return config.RouteQuickQuestion
+		}
+		for _, opener := range questionOpeners {
+			if strings.HasPrefix(text, opener) {
+				return config.RouteQuickQuestion
+			}
+		}
+	}
+	return config.RouteCodeEdit
+}
+
+// routedProvider returns the provider configured for prompt's class, or nil
+// when the agent is not routed, the class has no rule or its rule names the
+// model the agent already uses. Providers are built once per model and
+// reused; a model that cannot be created is skipped with a warning.
+func (a *agent) routedProvider(prompt string) (provider.Provider, config.RouteClass) {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Routing.RoutesAgent(a.agentID) {
+		return nil, ""
+	}
+	class := classifyRequest(prompt)
+	id, ok := cfg.Routing.Rules[class]
+	if !ok || id == "" || id == a.provider.Model().ID {
+		return nil, class
+	}
+	if p, ok := a.routedProviders.Load(id); ok {
+		return p.(provider.Provider), class
+	}
+	if _, ok := models.SupportedModels[id]; !ok {
+		logging.Warn("Routing rule names an unknown model, keeping the agent model", "agent", a.agentID, "class", class, "model", id)
+		return nil, class
+	}
+	p, err := createAgentProvider(a.agentID, append(slices.Clone(a.providerOpts), withModel(id))...)
+	if err != nil {
+		logging.Warn("Routed model unavailable, keeping the agent model", "agent", a.agentID, "class", class, "model", id, "error", err)
+		return nil, class
+	}
+	a.routedProviders.Store(id, p)
+	return p, class
+}
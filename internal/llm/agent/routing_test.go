@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+func TestClassifyRequest(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   config.RouteClass
+	}{
+		{"What does the pubsub broker do?", config.RouteQuickQuestion},
+		{"where is the session service created", config.RouteQuickQuestion},
+		{"Can you fix the failing test?", config.RouteCodeEdit},
+		{"Add a --json flag to the run command", config.RouteCodeEdit},
+		{"Summarize what we did in this session", config.RouteSummarization},
+		{"tl;dr of internal/llm/agent please", config.RouteSummarization},
+		{"Refactor the provider package to share the retry loop", config.RouteLargeRefactor},
+		{"Rename Foo to Bar across the codebase", config.RouteLargeRefactor},
+		{strings.Repeat("Handle the edge case. ", 100), config.RouteLargeRefactor},
+		{"why does this panic?\n```go\nvar m map[string]int\nm[\"a\"] = 1\n```", config.RouteCodeEdit},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyRequest(tt.prompt), tt.prompt)
+	}
+}
+
+func TestRoutesAgent(t *testing.T) {
+	var unset *config.RoutingConfig
+	assert.False(t, unset.RoutesAgent(config.AgentCoder))
+
+	r := &config.RoutingConfig{Rules: map[config.RouteClass]models.ModelID{config.RouteQuickQuestion: "claude-4.5-haiku"}}
+	assert.True(t, r.RoutesAgent(config.AgentCoder))
+	assert.False(t, r.RoutesAgent(config.AgentExplorer))
+
+	r.Agents = []config.AgentName{config.AgentExplorer}
+	assert.False(t, r.RoutesAgent(config.AgentCoder))
+	assert.True(t, r.RoutesAgent(config.AgentExplorer))
+}
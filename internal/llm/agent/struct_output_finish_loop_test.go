@@ -46,6 +46,7 @@ func (m *memMessages) Create(_ context.Context, sessionID string, params message
 		Parts:     params.Parts,
 		Model:     params.Model,
 		Seq:       int64(m.seq),
+		Synthetic: params.Synthetic,
 	}
 	m.byID[msg.ID] = msg
 	m.bySession[sessionID] = append(m.bySession[sessionID], msg.ID)
@@ -76,6 +77,25 @@ type memSessions struct {
 	session.Service
 	mu       sync.Mutex
 	sessions map[string]session.Session
+	usage    map[string][]session.UsageEntry
+	requests []session.MessageUsage
+}
+
+func (s *memSessions) RecordMessageUsage(_ context.Context, u session.MessageUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, u)
+	return nil
+}
+
+func (s *memSessions) RecordUsage(_ context.Context, id string, entries ...session.UsageEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage == nil {
+		s.usage = map[string][]session.UsageEntry{}
+	}
+	s.usage[id] = append(s.usage[id], entries...)
+	return nil
 }
 
 func (s *memSessions) Get(_ context.Context, id string) (session.Session, error) {
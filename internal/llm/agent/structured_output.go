@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// maxStructuredOutputAttempts bounds the extra turns spent getting a final
+// answer that matches the output schema after a run ended without one.
+const maxStructuredOutputAttempts = 3
+
+// outputSchemaFor returns the schema an agent's final answer is held to,
+// in the shape struct_output arguments take, or nil when the agent has no
+// output schema. Interactive flow steps get nil too: they legitimately end
+// turns in prose while talking to their reviewer.
+func outputSchemaFor(info *agentregistry.AgentInfo) map[string]any {
+	if info.Output == nil || info.Output.Schema == nil || info.Interactive {
+		return nil
+	}
+	baseDir := ""
+	if info.Location != "" {
+		baseDir = filepath.Dir(info.Location)
+	}
+	resolved, err := format.ResolveSchemaRef(info.Output.Schema, baseDir)
+	if err != nil {
+		logging.Error("Failed to resolve output schema $ref", "agent", info.ID, "error", err)
+		return nil
+	}
+	return tools.StructOutputSchema(resolved)
+}
+
+// needsStructuredOutput reports whether a run result still lacks the
+// structured answer the agent's output schema asks for. Agents that have
+// struct_output disabled opted into free-form answers and are left alone.
+func (a *agent) needsStructuredOutput(result AgentEvent, toolSet []tools.BaseTool) bool {
+	if a.outputSchema == nil || result.Type != AgentEventTypeResponse {
+		return false
+	}
+	if result.StructOutput != nil && !result.StructOutput.IsError {
+		return false
+	}
+	return slices.ContainsFunc(toolSet, func(t tools.BaseTool) bool {
+		return t.Info().Name == tools.StructOutputToolName
+	})
+}
+
+// finalizeStructuredOutput asks the model for its final answer with the
+// provider's native schema enforcement switched on (see
+// provider.StructuredOutputContext), validates it, and asks again with the
+// validation error when it does not match.
+func (a *agent) finalizeStructuredOutput(ctx context.Context, sessionID string, history []message.Message, toolSet []tools.BaseTool, tracker *callTracker) (message.Message, *message.ToolResult, error) {
+	structuredCtx := provider.StructuredOutputContext(ctx, a.outputSchema)
+	prompt := "Give your final answer now as JSON matching the required output schema."
+	var lastMsg message.Message
+	var lastErr error
+	for attempt := 1; attempt <= maxStructuredOutputAttempts; attempt++ {
+		nudge, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: prompt}},
+		})
+		if err != nil {
+			return lastMsg, nil, err
+		}
+		history = append(history, nudge)
+
+		msg, toolResults, err := a.streamAndHandleEvents(structuredCtx, sessionID, history, toolSet, tracker)
+		lastMsg = msg
+		if err != nil {
+			a.createErrorToolResults(msg)
+			if errors.Is(err, context.Canceled) {
+				a.finishMessage(ctx, &msg, message.FinishReasonCanceled)
+				return msg, nil, ErrRequestCancelled
+			}
+			a.finishMessage(ctx, &msg, message.FinishReasonError)
+			return msg, nil, err
+		}
+		history = append(history, msg)
+
+		if toolResults != nil {
+			// Anthropic answers through the forced struct_output call, which
+			// validates its own input.
+			history = append(history, *toolResults)
+			result, ok := toolResults.StructOutput()
+			if ok {
+				return msg, result, nil
+			}
+			if result != nil {
+				lastErr = errors.New(result.Content)
+			} else {
+				lastErr = errors.New("called other tools instead of answering")
+			}
+		} else {
+			content, err := parseStructuredAnswer(a.outputSchema, msg.Content().String())
+			if err == nil {
+				return msg, &message.ToolResult{Name: tools.StructOutputToolName, Content: content}, nil
+			}
+			lastErr = err
+		}
+		logging.Warn("Final answer does not match the output schema", "session_id", sessionID, "attempt", attempt, "error", lastErr)
+		prompt = fmt.Sprintf("Your final answer does not match the output schema: %v. Reply again with only the corrected JSON.", lastErr)
+	}
+	return lastMsg, nil, fmt.Errorf("final answer does not match the output schema after %d attempts: %w", maxStructuredOutputAttempts, lastErr)
+}
+
+// parseStructuredAnswer validates a plain text answer against schema and
+// returns it indented the way struct_output returns its result. Code fences
+// are tolerated for providers that ignore the requested response format.
+func parseStructuredAnswer(schema map[string]any, content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```json")
+		content = strings.TrimPrefix(content, "```")
+		content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	}
+	if err := format.ValidateJSON(schema, content); err != nil {
+		return "", err
+	}
+	var value any
+	_ = json.Unmarshal([]byte(content), &value)
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
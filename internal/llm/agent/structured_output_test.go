@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func textTurn(content string) *provider.ProviderResponse {
+	return &provider.ProviderResponse{Content: content, FinishReason: message.FinishReasonEndTurn}
+}
+
+// streamingProvider streams the scripted response's text as a content delta
+// before completing, the way real providers deliver text.
+type streamingProvider struct {
+	*scriptedProvider
+}
+
+func (p streamingProvider) StreamResponse(ctx context.Context, msgs []message.Message, toolSet []tools.BaseTool) <-chan provider.ProviderEvent {
+	complete := <-p.scriptedProvider.StreamResponse(ctx, msgs, toolSet)
+	ch := make(chan provider.ProviderEvent, 2)
+	if complete.Response.Content != "" {
+		ch <- provider.ProviderEvent{Type: provider.EventContentDelta, Content: complete.Response.Content}
+	}
+	ch <- complete
+	close(ch)
+	return ch
+}
+
+func newStructuredLoopAgent(t *testing.T, p *scriptedProvider) *agent {
+	t.Helper()
+	withFreshTaskRegistry(t)
+	a := newLoopAgent(t, streamingProvider{p})
+	a.outputSchema = outputSchemaFor(&agentregistry.AgentInfo{Output: &agentregistry.Output{Schema: map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"status": map[string]any{"type": "string"}},
+		"required":   []any{"status"},
+	}}})
+	return a
+}
+
+func TestProcessGeneration_ProseAnswerIsFinalizedAsStructuredOutput(t *testing.T) {
+	p := &scriptedProvider{respond: func(call int) *provider.ProviderResponse {
+		if call == 1 {
+			return textTurn("the status is done")
+		}
+		return textTurn("```json\n{\"status\": \"done\"}\n```")
+	}}
+	a := newStructuredLoopAgent(t, p)
+
+	res := a.processGeneration(context.Background(), "sess-prose", "produce the output", 0, nil, RunOptions{NonInteractive: true})
+
+	if res.Error != nil {
+		t.Fatalf("processGeneration error: %v", res.Error)
+	}
+	if res.StructOutput == nil || !strings.Contains(res.StructOutput.Content, `"status": "done"`) {
+		t.Fatalf("StructOutput = %+v, want the finalized JSON", res.StructOutput)
+	}
+	if got := p.callCount(); got != 2 {
+		t.Errorf("provider calls = %d, want 2", got)
+	}
+}
+
+func TestProcessGeneration_StructuredOutputRetriedThroughTool(t *testing.T) {
+	p := &scriptedProvider{respond: func(call int) *provider.ProviderResponse {
+		switch call {
+		case 1:
+			return textTurn("done")
+		case 2:
+			return textTurn(`{"state": "done"}`)
+		default:
+			return structOutputTurn()
+		}
+	}}
+	a := newStructuredLoopAgent(t, p)
+
+	res := a.processGeneration(context.Background(), "sess-retry", "produce the output", 0, nil, RunOptions{NonInteractive: true})
+
+	if res.Error != nil {
+		t.Fatalf("processGeneration error: %v", res.Error)
+	}
+	if res.StructOutput == nil || res.StructOutput.IsError {
+		t.Fatalf("StructOutput = %+v, want the struct_output result", res.StructOutput)
+	}
+	if got := p.callCount(); got != 3 {
+		t.Errorf("provider calls = %d, want 3", got)
+	}
+
+	// The retry prompt must tell the model what was wrong.
+	msgs, _ := a.messages.List(context.Background(), "sess-retry")
+	var prompts []string
+	for _, m := range msgs {
+		if m.Role == message.User {
+			prompts = append(prompts, m.Content().String())
+		}
+	}
+	if len(prompts) != 3 || !strings.Contains(prompts[2], `missing required property "status"`) {
+		t.Errorf("user prompts = %q", prompts)
+	}
+}
+
+func TestProcessGeneration_StructuredOutputFailureIsAnError(t *testing.T) {
+	p := &scriptedProvider{respond: func(int) *provider.ProviderResponse { return textTurn("no JSON here") }}
+	a := newStructuredLoopAgent(t, p)
+
+	res := a.processGeneration(context.Background(), "sess-fail", "produce the output", 0, nil, RunOptions{NonInteractive: true})
+
+	if res.Type != AgentEventTypeError || res.Error == nil {
+		t.Fatalf("result = %+v, want an error event", res)
+	}
+	if !strings.Contains(res.Error.Error(), "does not match the output schema") {
+		t.Errorf("error = %v", res.Error)
+	}
+	if got := p.callCount(); got != 1+maxStructuredOutputAttempts {
+		t.Errorf("provider calls = %d, want %d", got, 1+maxStructuredOutputAttempts)
+	}
+}
+
+func TestOutputSchemaFor(t *testing.T) {
+	if outputSchemaFor(&agentregistry.AgentInfo{}) != nil {
+		t.Error("agents without output schema must not be held to one")
+	}
+	info := &agentregistry.AgentInfo{Output: &agentregistry.Output{Schema: map[string]any{"type": "string"}}, Interactive: true}
+	if outputSchemaFor(info) != nil {
+		t.Error("interactive steps must not be held to the schema")
+	}
+	info.Interactive = false
+	schema := outputSchemaFor(info)
+	if schema == nil || schema["properties"].(map[string]any)["output"] == nil {
+		t.Errorf("schema = %v, want the struct_output wrapper", schema)
+	}
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// SubagentEvent is an event of a subagent spawned by the task tool,
+// published while the subagent runs so the view of the calling session can
+// follow it live. Events of type AgentEventTypeMessage carry the
+// subagent's messages as they are created and streamed into; the last
+// event of a run is the subagent's terminal event, with Done set.
+type SubagentEvent struct {
+	AgentEvent
+	// ParentSessionID is the session whose task call spawned the subagent.
+	ParentSessionID string
+	// ToolCallID is that task call. It differs from the subagent's
+	// SessionID when the call resumed an earlier task.
+	ToolCallID   string
+	SubagentType string
+}
+
+// AgentEventTypeMessage carries a snapshot of a subagent message. It is
+// only published as a SubagentEvent.
+const AgentEventTypeMessage AgentEventType = "message"
+
+var subagentBroker = pubsub.NewBroker[SubagentEvent]()
+
+// SubscribeSubagents streams the events of every subagent the task tool
+// runs, nested subagents included.
+func SubscribeSubagents(ctx context.Context) <-chan pubsub.Event[SubagentEvent] {
+	return subagentBroker.Subscribe(ctx)
+}
+
+// runStreamed runs the subagent a on its task session and forwards its
+// messages as SubagentEvents until the run ends. The returned channel
+// delivers the run's terminal event once it has been forwarded too.
+func (b *agentTool) runStreamed(ctx context.Context, a Service, link SubagentEvent, prompt string) (<-chan AgentEvent, error) {
+	taskSessionID := link.SessionID
+	if b.messages == nil {
+		return a.Run(ctx, taskSessionID, prompt, 0)
+	}
+	// Subscribed before the run starts so its first message is not missed,
+	// and detached from ctx so async runs keep streaming after the
+	// spawning turn ends.
+	subCtx, stop := context.WithCancel(context.Background())
+	msgs := b.messages.Subscribe(subCtx)
+	done, err := a.Run(ctx, taskSessionID, prompt, 0)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+
+	forward := func(ev pubsub.Event[message.Message]) {
+		if ev.Payload.SessionID != taskSessionID {
+			return
+		}
+		fwd := link
+		fwd.AgentEvent = AgentEvent{Type: AgentEventTypeMessage, Message: ev.Payload, SessionID: taskSessionID}
+		subagentBroker.Publish(ev.Type, fwd)
+	}
+	out := make(chan AgentEvent, 1)
+	go func() {
+		defer stop()
+		defer close(out)
+		for {
+			select {
+			case ev, ok := <-msgs:
+				if !ok {
+					msgs = nil
+					continue
+				}
+				forward(ev)
+			case result, ok := <-done:
+				if !ok {
+					return
+				}
+				// The run's last updates are already queued; flush them
+				// so the terminal event comes last.
+				for drained := false; !drained && msgs != nil; {
+					select {
+					case ev, ok := <-msgs:
+						if !ok {
+							drained = true
+							break
+						}
+						forward(ev)
+					default:
+						drained = true
+					}
+				}
+				fwd := link
+				fwd.AgentEvent = result
+				fwd.SessionID = taskSessionID
+				fwd.Done = true
+				subagentBroker.Publish(pubsub.UpdatedEvent, fwd)
+				out <- result
+				return
+			}
+		}
+	}()
+	return out, nil
+}
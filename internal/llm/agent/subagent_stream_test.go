@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokerMessages is a message.Service whose events are published by hand.
+type brokerMessages struct {
+	message.Service
+	broker *pubsub.Broker[message.Message]
+}
+
+func (m brokerMessages) Subscribe(ctx context.Context) <-chan pubsub.Event[message.Message] {
+	return m.broker.Subscribe(ctx)
+}
+
+// streamingAgent publishes the given message events when run, then ends
+// the run with a response.
+type streamingAgent struct {
+	Service
+	messages brokerMessages
+	events   []pubsub.Event[message.Message]
+}
+
+func (a *streamingAgent) Run(_ context.Context, sessionID string, _ string, _ int, _ ...message.Attachment) (<-chan AgentEvent, error) {
+	for _, ev := range a.events {
+		a.messages.broker.Publish(ev.Type, ev.Payload)
+	}
+	done := make(chan AgentEvent, 1)
+	done <- AgentEvent{Type: AgentEventTypeResponse, SessionID: sessionID, Message: message.Message{ID: "final", Role: message.Assistant}}
+	close(done)
+	return done, nil
+}
+
+func TestRunStreamedForwardsSubagentMessages(t *testing.T) {
+	msgs := brokerMessages{broker: pubsub.NewBroker[message.Message]()}
+	streaming := message.Message{ID: "m1", SessionID: "task-1", Role: message.Assistant}
+	a := &streamingAgent{
+		messages: msgs,
+		events: []pubsub.Event[message.Message]{
+			{Type: pubsub.CreatedEvent, Payload: streaming},
+			{Type: pubsub.CreatedEvent, Payload: message.Message{ID: "other", SessionID: "parent", Role: message.User}},
+			{Type: pubsub.UpdatedEvent, Payload: streaming},
+		},
+	}
+	b := &agentTool{messages: msgs}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := SubscribeSubagents(ctx)
+
+	link := SubagentEvent{
+		AgentEvent:      AgentEvent{SessionID: "task-1"},
+		ParentSessionID: "parent",
+		ToolCallID:      "call-1",
+		SubagentType:    "explorer",
+	}
+	done, err := b.runStreamed(ctx, a, link, "prompt")
+	require.NoError(t, err)
+	result := <-done
+	assert.Equal(t, "final", result.Message.ID)
+
+	var got []pubsub.Event[SubagentEvent]
+	for len(got) == 0 || !got[len(got)-1].Payload.Done {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("terminal event not published, got %d events", len(got))
+		}
+	}
+
+	require.Len(t, got, 3)
+	for _, ev := range got {
+		assert.Equal(t, "parent", ev.Payload.ParentSessionID)
+		assert.Equal(t, "call-1", ev.Payload.ToolCallID)
+		assert.Equal(t, "explorer", ev.Payload.SubagentType)
+		assert.Equal(t, "task-1", ev.Payload.SessionID)
+	}
+	assert.Equal(t, pubsub.CreatedEvent, got[0].Type)
+	assert.Equal(t, AgentEventTypeMessage, got[0].Payload.Type)
+	assert.Equal(t, "m1", got[0].Payload.Message.ID)
+	assert.Equal(t, pubsub.UpdatedEvent, got[1].Type)
+	assert.Equal(t, AgentEventTypeMessage, got[1].Payload.Type)
+	assert.Equal(t, AgentEventTypeResponse, got[2].Payload.Type)
+	assert.Equal(t, "final", got[2].Payload.Message.ID)
+}
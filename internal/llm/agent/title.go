@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/langfuse"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+const (
+	// titleDigestMessages is how many of the latest messages RegenerateTitle
+	// describes the session from.
+	titleDigestMessages = 20
+	// titleDigestMaxChars caps the text sent to the descriptor; a title
+	// needs the gist, not the whole conversation.
+	titleDigestMaxChars = 6000
+)
+
+// autoTitleEnabled reports whether sessions get generated titles on their
+// first message and after compaction.
+func autoTitleEnabled() bool {
+	cfg := config.Get()
+	return cfg == nil || !cfg.DisableAutoTitle
+}
+
+// generateTitle describes content with the descriptor agent and applies the
+// result as the session's generated title.
+func (a *agent) generateTitle(ctx context.Context, sessionID string, content string) error {
+	if content == "" {
+		return nil
+	}
+	if a.titleProvider == nil || !autoTitleEnabled() {
+		return nil
+	}
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// A user-set title is authoritative — skip generation entirely so we don't
+	// waste a descriptor call. The write below is also guarded at the DB level
+	// (SetGeneratedTitle) to close the race where a rename lands after this read.
+	if sess.UserSetTitle {
+		return nil
+	}
+	title, err := a.describe(ctx, sess, content)
+	if err != nil || title == "" {
+		return err
+	}
+
+	// Guarded write: only lands if the session is still not user-titled, so a
+	// rename that commits while this descriptor call was in flight wins.
+	_, err = a.sessions.SetGeneratedTitle(ctx, sessionID, title)
+	return err
+}
+
+// retitleInBackground regenerates the title of a session from its new
+// summary, which describes where the work went better than the first
+// message did.
+func (a *agent) retitleInBackground(sessionID, summary string) {
+	go func() {
+		defer logging.RecoverPanic("agent.retitle", nil)
+		if err := a.generateTitle(context.Background(), sessionID, summary); err != nil {
+			logging.Warn("Failed to regenerate title after compaction", "session_id", sessionID, "error", err)
+		}
+	}()
+}
+
+func (a *agent) RegenerateTitle(ctx context.Context, sessionID string) (session.Session, error) {
+	if a.titleProvider == nil {
+		return session.Session{}, fmt.Errorf("title provider not available")
+	}
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	msgs, err := a.loadHistory(ctx, sess)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+	content := titleDigest(msgs)
+	if content == "" {
+		return session.Session{}, fmt.Errorf("session has no messages to describe")
+	}
+	title, err := a.describe(ctx, sess, content)
+	if err != nil {
+		return session.Session{}, err
+	}
+	if title == "" {
+		return session.Session{}, fmt.Errorf("empty title returned")
+	}
+	// Asking for a new title is choosing it: Rename keeps it from being
+	// replaced by the next automatic title.
+	return a.sessions.Rename(ctx, sessionID, title)
+}
+
+// describe asks the descriptor agent for a title for content.
+func (a *agent) describe(ctx context.Context, sess session.Session, content string) (string, error) {
+	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sess.ID)
+	ctx = context.WithValue(ctx, tools.AgentIDContextKey, config.AgentDescriptor)
+	ctx = a.createLangfuseTrace(ctx, sess)
+	defer langfuse.EndTrace(ctx)
+	response, err := a.titleProvider.SendMessages(
+		ctx,
+		[]message.Message{
+			{
+				Role:  message.User,
+				Parts: []message.ContentPart{message.TextContent{Text: content}},
+			},
+		},
+		make([]tools.BaseTool, 0),
+	)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.ReplaceAll(response.Content, "\n", " ")), nil
+}
+
+// titleDigest renders the text of the latest user and assistant messages,
+// newest last, trimmed from the front to titleDigestMaxChars.
+func titleDigest(msgs []message.Message) string {
+	if len(msgs) > titleDigestMessages {
+		msgs = msgs[len(msgs)-titleDigestMessages:]
+	}
+	var b strings.Builder
+	for _, msg := range msgs {
+		if msg.Role != message.User && msg.Role != message.Assistant {
+			continue
+		}
+		text := strings.TrimSpace(msg.Content().Text)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", msg.Role, text)
+	}
+	digest := strings.TrimSpace(b.String())
+	if r := []rune(digest); len(r) > titleDigestMaxChars {
+		digest = string(r[len(r)-titleDigestMaxChars:])
+	}
+	return digest
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describingProvider answers every descriptor call with title.
+type describingProvider struct {
+	provider.Provider
+	title   string
+	prompts []string
+}
+
+func (p *describingProvider) SendMessages(_ context.Context, msgs []message.Message, _ []tools.BaseTool) (*provider.ProviderResponse, error) {
+	p.prompts = append(p.prompts, msgs[len(msgs)-1].Content().Text)
+	return &provider.ProviderResponse{Content: p.title}, nil
+}
+
+// titledSessions records how titles are applied.
+type titledSessions struct {
+	session.Service
+	sess      session.Session
+	renamed   string
+	generated string
+}
+
+func (s *titledSessions) Get(context.Context, string) (session.Session, error) { return s.sess, nil }
+
+func (s *titledSessions) Rename(_ context.Context, _ string, title string) (session.Session, error) {
+	s.renamed = title
+	s.sess.Title, s.sess.UserSetTitle = title, true
+	return s.sess, nil
+}
+
+func (s *titledSessions) SetGeneratedTitle(_ context.Context, _ string, title string) (session.Session, error) {
+	s.generated = title
+	s.sess.Title = title
+	return s.sess, nil
+}
+
+func newTitleAgent(t *testing.T, sess session.Session) (*agent, *titledSessions, *describingProvider) {
+	t.Helper()
+	newLoopAgent(t, &scriptedProvider{}) // loads config
+	sessions := &titledSessions{sess: sess}
+	p := &describingProvider{title: "Backfill order totals\n"}
+	return &agent{
+		Broker:        pubsub.NewBroker[AgentEvent](),
+		sessions:      sessions,
+		messages:      newMemMessages(),
+		titleProvider: p,
+	}, sessions, p
+}
+
+func TestGenerateTitle(t *testing.T) {
+	a, sessions, p := newTitleAgent(t, session.Session{ID: "sess"})
+	require.NoError(t, a.generateTitle(context.Background(), "sess", "summary of the work"))
+	assert.Equal(t, "Backfill order totals", sessions.generated)
+	assert.Equal(t, []string{"summary of the work"}, p.prompts)
+
+	a, sessions, p = newTitleAgent(t, session.Session{ID: "sess", UserSetTitle: true})
+	require.NoError(t, a.generateTitle(context.Background(), "sess", "summary of the work"))
+	assert.Empty(t, sessions.generated)
+	assert.Empty(t, p.prompts, "a user-set title costs no descriptor call")
+}
+
+func TestGenerateTitleDisabled(t *testing.T) {
+	a, sessions, p := newTitleAgent(t, session.Session{ID: "sess"})
+	cfg := config.Get()
+	cfg.DisableAutoTitle = true
+	t.Cleanup(func() { cfg.DisableAutoTitle = false })
+
+	require.NoError(t, a.generateTitle(context.Background(), "sess", "first prompt"))
+	assert.Empty(t, sessions.generated)
+	assert.Empty(t, p.prompts)
+}
+
+func TestRegenerateTitle(t *testing.T) {
+	a, sessions, p := newTitleAgent(t, session.Session{ID: "sess", Title: "Fix typo", UserSetTitle: true})
+	ctx := context.Background()
+	for _, m := range []message.CreateMessageParams{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "fix the typo in the README"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "Fixed."}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "now backfill the order totals"}}},
+	} {
+		_, err := a.messages.Create(ctx, "sess", m)
+		require.NoError(t, err)
+	}
+
+	sess, err := a.RegenerateTitle(ctx, "sess")
+	require.NoError(t, err)
+	assert.Equal(t, "Backfill order totals", sess.Title)
+	assert.Equal(t, "Backfill order totals", sessions.renamed, "a requested title replaces the user's and is kept")
+	require.Len(t, p.prompts, 1)
+	assert.True(t, strings.HasSuffix(p.prompts[0], "user: now backfill the order totals"), p.prompts[0])
+}
+
+func TestTitleDigestKeepsTheEnd(t *testing.T) {
+	long := strings.Repeat("a", titleDigestMaxChars)
+	msgs := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: long}}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{Content: "ignored"}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "latest"}}},
+	}
+	got := titleDigest(msgs)
+	assert.Len(t, []rune(got), titleDigestMaxChars)
+	assert.True(t, strings.HasSuffix(got, "user: latest"))
+	assert.NotContains(t, got, "ignored")
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/todo"
+)
+
+// keepTodos adds the session's current todo list right after a new
+// summary. The todowrite calls that built the list are compacted away, and
+// a summary tends to drop the items that were still pending; the list is
+// what keeps multi-step work on track from one context window to the next.
+// Unlike pinned copies it is not pinned: the next compaction carries the
+// list as it is then.
+func (a *agent) keepTodos(ctx context.Context, sessionID string) {
+	if a.factory == nil {
+		return
+	}
+	store := a.factory.TodoStore()
+	if store == nil {
+		return
+	}
+	items := store.Get(sessionID)
+	if todo.Remaining(items) == 0 {
+		return
+	}
+	if _, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: todoReminder(items)}},
+	}); err != nil {
+		logging.Warn("Failed to keep todo list through compaction", "session_id", sessionID, "error", err)
+	}
+}
+
+// todoReminder renders items the way the model wrote them, one per line.
+func todoReminder(items []todo.Item) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The todo list of this session, as it was when the conversation was summarized. Keep it up to date with the %s tool.\n<todos>\n", tools.TodoWriteToolName)
+	for _, item := range items {
+		fmt.Fprintf(&b, "- [%s] (%s) %s\n", item.Status, item.Priority, item.Content)
+	}
+	b.WriteString("</todos>")
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFactoryWithTodos struct {
+	AgentFactory
+	store *todo.Store
+}
+
+func (f *fakeFactoryWithTodos) TodoStore() tools.TodoStore { return f.store }
+
+func TestKeepTodos(t *testing.T) {
+	store := todo.NewStore()
+	rec := &createRecordingMessages{}
+	a := &agent{Broker: pubsub.NewBroker[AgentEvent](), messages: rec, factory: &fakeFactoryWithTodos{store: store}}
+
+	a.keepTodos(context.Background(), "sess")
+	assert.Empty(t, rec.created, "no list, nothing to keep")
+
+	store.Set("sess", []todo.Item{
+		{Content: "add the migration", Status: "completed", Priority: "high"},
+		{Content: "backfill old rows", Status: "in_progress", Priority: "high"},
+		{Content: "update the docs", Status: "pending", Priority: "low"},
+	})
+	a.keepTodos(context.Background(), "sess")
+
+	require.Len(t, rec.created, 1)
+	kept := message.Message{Role: rec.created[0].Role, Parts: rec.created[0].Parts}
+	assert.Equal(t, message.User, kept.Role)
+	assert.False(t, kept.IsPinned(), "the next compaction carries the list as it is then")
+	text := kept.Content().Text
+	assert.Contains(t, text, "- [completed] (high) add the migration")
+	assert.Contains(t, text, "- [in_progress] (high) backfill old rows")
+	assert.Contains(t, text, "- [pending] (low) update the docs")
+
+	store.Set("sess", []todo.Item{{Content: "ship it", Status: "completed", Priority: "high"}})
+	a.keepTodos(context.Background(), "sess")
+	assert.Len(t, rec.created, 1, "a finished list is not carried over")
+}
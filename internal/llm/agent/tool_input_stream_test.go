@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type partRecordingMessages struct {
+	recordingMessages
+	parts []message.ContentPart
+}
+
+func (r *partRecordingMessages) PublishPart(_, _ string, part message.ContentPart) {
+	r.parts = append(r.parts, part)
+}
+
+func TestProcessEventStreamsToolInput(t *testing.T) {
+	rec := &partRecordingMessages{}
+	a := &agent{Broker: pubsub.NewBroker[AgentEvent](), messages: rec}
+	ctx := context.Background()
+	msg := message.Message{ID: "msg-1", Role: message.Assistant}
+	flush := &toolInputThrottle{interval: time.Hour}
+	process := func(event provider.ProviderEvent) {
+		t.Helper()
+		require.NoError(t, a.processEvent(ctx, "sess", &msg, event, nil, time.Now(), flush))
+	}
+	delta := func(input string) provider.ProviderEvent {
+		return provider.ProviderEvent{Type: provider.EventToolUseDelta, ToolCall: &message.ToolCall{ID: "call-1", Input: input}}
+	}
+
+	process(provider.ProviderEvent{Type: provider.EventToolUseStart, ToolCall: &message.ToolCall{ID: "call-1", Name: "write"}})
+	process(delta(`{"file_path":`))
+	assert.Equal(t, 2, rec.updateCalls, "the first delta of a call is persisted right away")
+	persisted, _ := rec.updatedMsg.FindToolCall("call-1")
+	assert.Equal(t, `{"file_path":`, persisted.Input)
+
+	process(delta(`"a.go",`))
+	process(delta(`"content":"x"}`))
+	assert.Equal(t, 2, rec.updateCalls, "later deltas wait for the interval")
+	tc, _ := msg.FindToolCall("call-1")
+	assert.Equal(t, `{"file_path":"a.go","content":"x"}`, tc.Input, "the in-memory message has every delta")
+
+	flush.interval = 0
+	process(delta(""))
+	assert.Equal(t, 3, rec.updateCalls)
+
+	process(provider.ProviderEvent{Type: provider.EventToolUseStop, ToolCall: &message.ToolCall{ID: "call-1"}})
+	persisted, _ = rec.updatedMsg.FindToolCall("call-1")
+	assert.True(t, persisted.Finished)
+	assert.Equal(t, `{"file_path":"a.go","content":"x"}`, persisted.Input)
+	require.NotEmpty(t, rec.parts)
+	assert.Equal(t, persisted, rec.parts[len(rec.parts)-1])
+}
+
+func TestFinishMessageDropsPartialToolInput(t *testing.T) {
+	rec := &recordingMessages{}
+	a := &agent{Broker: pubsub.NewBroker[AgentEvent](), messages: rec}
+	msg := message.Message{ID: "msg-1", Role: message.Assistant}
+	msg.AddToolCall(message.ToolCall{ID: "done", Name: "ls", Input: `{"path":"."}`, Finished: true})
+	msg.AddToolCall(message.ToolCall{ID: "cut", Name: "write", Input: `{"file_path":"a.go","cont`})
+
+	a.finishMessage(context.Background(), &msg, message.FinishReasonCanceled)
+
+	done, _ := rec.updatedMsg.FindToolCall("done")
+	assert.Equal(t, `{"path":"."}`, done.Input)
+	cut, _ := rec.updatedMsg.FindToolCall("cut")
+	assert.Empty(t, cut.Input)
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -17,45 +18,88 @@ import (
 	"github.com/opencode-ai/opencode/internal/lsp/install"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/project"
 	"github.com/opencode-ai/opencode/internal/session"
 )
 
 var (
 	viewerToolNames = []string{
 		tools.LSToolName,
+		tools.TreeToolName,
 		tools.GlobToolName,
 		tools.GrepToolName,
+		tools.CodeSearchToolName,
 		tools.ReadToolName,
 		tools.ViewImageToolName,
+		tools.NotebookReadToolName,
 		tools.WebFetchToolName,
 		tools.SkillToolName,
 		tools.SourcegraphToolName,
+		tools.FactsToolName,
+		tools.GitContextToolName,
+		// The blackboard leaves the workspace untouched, so read-only
+		// subagents can share findings too.
+		tools.BlackboardPostToolName,
+		tools.BlackboardReadToolName,
+		// Project memories live in the database, not the workspace.
+		tools.MemoryWriteToolName,
+		tools.MemoryReadToolName,
 	}
 	editorToolNames = []string{
 		tools.WriteToolName,
 		tools.EditToolName,
 		tools.MultiEditToolName,
+		tools.NotebookEditToolName,
 		tools.DeleteToolName,
 		tools.PatchToolName,
 		tools.BashToolName,
+		tools.RunTaskToolName,
 		// Background-task tools spawn/kill subprocesses or subagents and are
 		// available to both agents and subagents (subagents may want to
 		// monitor or kill their own background work too).
 		tools.MonitorToolName,
 		tools.TaskListToolName,
 		tools.TaskStopToolName,
+		tools.JobStatusToolName,
+		tools.JobOutputToolName,
+		tools.JobKillToolName,
+		// Subagents keep their own todo list in their task session, so a
+		// workhorse can track a long task the way the coder does.
+		tools.TodoWriteToolName,
+	}
+	// readOnlyExcludedToolNames are left out of every tool set in
+	// read-only mode: they change files, run build targets or spawn
+	// arbitrary commands. bash stays and refuses anything but read-only
+	// commands itself.
+	readOnlyExcludedToolNames = []string{
+		tools.WriteToolName,
+		tools.EditToolName,
+		tools.MultiEditToolName,
+		tools.NotebookEditToolName,
+		tools.DeleteToolName,
+		tools.PatchToolName,
+		tools.RunTaskToolName,
+		tools.MonitorToolName,
+		FixDiagnosticsToolName,
 	}
 	managerToolNames = []string{
 		TaskToolName,
+		FixDiagnosticsToolName,
+		HandoffToolName,
 		tools.QuestionToolName,
 		tools.CronCreateToolName,
 		tools.CronDeleteToolName,
 		tools.CronListToolName,
-		tools.TodoWriteToolName,
 		tools.RouterSendToolName,
 	}
 )
 
+// offeredInReadOnly reports whether a built-in tool stays in the tool
+// set in read-only mode.
+func offeredInReadOnly(name string) bool {
+	return !slices.Contains(readOnlyExcludedToolNames, name)
+}
+
 // NewToolSet dynamically builds the tool slice for an agent based on its
 // registry info. Only tools that pass registry.IsToolEnabled are included.
 func NewToolSet(
@@ -72,25 +116,40 @@ func NewToolSet(
 ) <-chan tools.BaseTool {
 	agentID := info.ID
 	result := make(chan tools.BaseTool, 100)
+	limits := agentOutputLimits(config.AgentName(agentID))
 
 	createTool := func(name string) tools.BaseTool {
 		switch name {
 		case tools.LSToolName:
-			return tools.NewLsTool(config.Get(), reg, permissions)
+			return tools.NewLsTool(config.Get(), reg, permissions, limits)
+		case tools.TreeToolName:
+			return tools.NewTreeTool(config.Get(), reg, permissions)
 		case tools.GlobToolName:
 			return tools.NewGlobTool(reg, permissions)
 		case tools.GrepToolName:
 			return tools.NewGrepTool(reg, permissions)
+		case tools.CodeSearchToolName:
+			// Only offered when codeSearch is configured.
+			if index := factory.CodeIndex(); index != nil {
+				return tools.NewCodeSearchTool(index)
+			}
+			return nil
 		case tools.ReadToolName:
-			return tools.NewReadTool(lspService, reg, permissions)
+			return tools.NewReadTool(lspService, reg, permissions, limits)
 		case tools.ViewImageToolName:
 			return tools.NewViewImageTool()
+		case tools.NotebookReadToolName:
+			return tools.NewNotebookReadTool(reg, permissions)
 		case tools.WebFetchToolName:
 			return tools.NewFetchTool(reg, permissions)
 		case tools.SkillToolName:
 			return tools.NewSkillTool(permissions, reg)
 		case tools.SourcegraphToolName:
 			return tools.NewSourcegraphTool()
+		case tools.FactsToolName:
+			return tools.NewFactsTool(config.WorkingDirectory(), config.Get().Data.Directory)
+		case tools.GitContextToolName:
+			return tools.NewGitContextTool(config.WorkingDirectory())
 		case tools.WebSearchToolName:
 			return tools.NewWebSearchTool(reg, tools.NewSearchProviderRegistry(config.Get()), permissions)
 		case tools.WriteToolName:
@@ -99,14 +158,34 @@ func NewToolSet(
 			return tools.NewEditTool(lspService, permissions, historyService, reg)
 		case tools.MultiEditToolName:
 			return tools.NewMultiEditTool(lspService, permissions, historyService, reg)
+		case tools.NotebookEditToolName:
+			return tools.NewNotebookEditTool(permissions, historyService, reg)
 		case tools.DeleteToolName:
 			return tools.NewDeleteTool(permissions, historyService, reg)
 		case tools.PatchToolName:
 			return tools.NewPatchTool(lspService, permissions, historyService, reg)
 		case tools.BashToolName:
-			return tools.NewBashTool(permissions, reg)
+			return tools.NewBashTool(permissions, reg, limits)
+		case tools.RunTaskToolName:
+			// Only offered when the working directory defines targets.
+			targets := project.DetectTargets(config.WorkingDirectory())
+			if len(targets) == 0 {
+				return nil
+			}
+			return tools.NewRunTaskTool(targets, permissions, reg, limits)
 		case TaskToolName:
-			return NewAgentTool(sessions, permissions, reg, factory)
+			return NewAgentTool(sessions, messages, permissions, reg, factory)
+		case HandoffToolName:
+			// Only offered when there is another primary agent to take over.
+			if len(handoffTargets(reg, agentID)) == 0 {
+				return nil
+			}
+			return NewHandoffTool(reg)
+		case FixDiagnosticsToolName:
+			if len(install.ResolveServers(config.Get())) == 0 {
+				return nil
+			}
+			return NewFixDiagnosticsTool(sessions, messages, permissions, reg, factory, lspService)
 		case tools.CronCreateToolName:
 			if svc, helper := factory.CronServices(); svc != nil {
 				return tools.NewCronCreateTool(svc, helper)
@@ -132,12 +211,38 @@ func NewToolSet(
 				return tools.NewTodoWriteTool(store)
 			}
 			return nil
+		case tools.BlackboardPostToolName:
+			if board := factory.Blackboard(); board != nil {
+				return tools.NewBlackboardPostTool(board)
+			}
+			return nil
+		case tools.BlackboardReadToolName:
+			if board := factory.Blackboard(); board != nil {
+				return tools.NewBlackboardReadTool(board)
+			}
+			return nil
+		case tools.MemoryWriteToolName:
+			if memories := factory.Memory(); memories != nil {
+				return tools.NewMemoryWriteTool(memories)
+			}
+			return nil
+		case tools.MemoryReadToolName:
+			if memories := factory.Memory(); memories != nil {
+				return tools.NewMemoryReadTool(memories)
+			}
+			return nil
 		case tools.MonitorToolName:
 			return tools.NewMonitorTool(permissions, reg)
 		case tools.TaskListToolName:
 			return tools.NewTaskListTool()
 		case tools.TaskStopToolName:
 			return tools.NewTaskStopTool(permissions, reg)
+		case tools.JobStatusToolName:
+			return tools.NewJobStatusTool()
+		case tools.JobOutputToolName:
+			return tools.NewJobOutputTool(limits)
+		case tools.JobKillToolName:
+			return tools.NewJobKillTool(permissions, reg)
 		case tools.RouterSendToolName:
 			// Conditional registration per chat-bridge-agent-tool spec:
 			// (a) agent mode (enforced by managerToolNames branch's
@@ -157,8 +262,17 @@ func NewToolSet(
 		}
 	}
 
+	cfg := config.Get()
+	readOnly := cfg != nil && cfg.ReadOnly
+	toolEnabled := func(name string) bool {
+		if readOnly && !offeredInReadOnly(name) {
+			return false
+		}
+		return reg.IsToolEnabled(agentID, name)
+	}
+
 	for _, name := range viewerToolNames {
-		if reg.IsToolEnabled(agentID, name) {
+		if toolEnabled(name) {
 			if t := createTool(name); t != nil {
 				result <- t
 			}
@@ -166,7 +280,6 @@ func NewToolSet(
 	}
 
 	// Only add websearch tool if providers are configured
-	cfg := config.Get()
 	if cfg != nil && cfg.WebSearch != nil && len(cfg.WebSearch.Providers) > 0 {
 		if reg.IsToolEnabled(agentID, tools.WebSearchToolName) {
 			if t := createTool(tools.WebSearchToolName); t != nil {
@@ -176,7 +289,7 @@ func NewToolSet(
 	}
 
 	for _, name := range editorToolNames {
-		if reg.IsToolEnabled(agentID, name) {
+		if toolEnabled(name) {
 			if t := createTool(name); t != nil {
 				result <- t
 			}
@@ -195,7 +308,7 @@ func NewToolSet(
 		if isCronTool {
 			enabled = reg.IsToolExplicitlyEnabled(agentID, name)
 		} else {
-			enabled = reg.IsToolEnabled(agentID, name)
+			enabled = toolEnabled(name)
 		}
 
 		if enabled {
@@ -241,7 +354,21 @@ func NewToolSet(
 		}
 	}()
 
-	// LSP tools – can be properly initialised only after servers up and running
+	// Plugin tools — executables in the tools directories, filtered per
+	// agent like MCP tools. See plugin-tool.go.
+	wg.Add(1)
+	go func() {
+		defer logging.RecoverPanic("plugin-goroutine", nil)
+		defer wg.Done()
+		for _, pt := range LoadPluginTools(ctx, permissions, reg) {
+			if toolEnabled(pt.Info().Name) {
+				result <- pt
+			}
+		}
+	}()
+
+	// LSP tools resolve their clients per call through lspService, waiting
+	// for a server that is still starting, so they can be built right away.
 	wg.Add(1)
 	go func() {
 		defer logging.RecoverPanic("LSP-goroutine", nil)
@@ -250,6 +377,9 @@ func NewToolSet(
 		if len(install.ResolveServers(cfg)) > 0 && reg.IsToolEnabled(agentID, tools.LSPToolName) {
 			result <- tools.NewLspTool(lspService)
 		}
+		if len(install.ResolveServers(cfg)) > 0 && reg.IsToolEnabled(agentID, tools.LspSymbolsToolName) {
+			result <- tools.NewLspSymbolsTool(lspService, reg, permissions)
+		}
 	}()
 
 	go func() {
@@ -292,19 +422,57 @@ func (a *agent) resolveTools() []tools.BaseTool {
 }
 
 // OrderTools partitions tools into baseline (preserving original order) followed
-// by external/MCP tools (sorted by name). This guarantees a deterministic tool
-// list for stable LLM cache prefixes.
+// by external/MCP/plugin tools (sorted by name). This guarantees a deterministic
+// tool list for stable LLM cache prefixes. An external tool named like a
+// baseline tool is dropped: providers reject duplicate tool names.
 func OrderTools(toolSet []tools.BaseTool) []tools.BaseTool {
 	var baseline, external []tools.BaseTool
+	baselineNames := make(map[string]bool)
 	for _, t := range toolSet {
 		if t.IsBaseline() {
 			baseline = append(baseline, t)
-		} else {
-			external = append(external, t)
+			baselineNames[t.Info().Name] = true
 		}
 	}
+	for _, t := range toolSet {
+		if t.IsBaseline() {
+			continue
+		}
+		if name := t.Info().Name; baselineNames[name] {
+			logging.Warn("External tool shadows a built-in tool and is ignored", "tool", name)
+			continue
+		}
+		external = append(external, t)
+	}
 	sort.Slice(external, func(i, j int) bool {
 		return external[i].Info().Name < external[j].Info().Name
 	})
 	return append(baseline, external...)
 }
+
+// explorerOutputLimits are half the stock tool output caps. The explorer
+// is fanned out in parallel and only reports back a summary, so it gets
+// by with smaller reads.
+var explorerOutputLimits = tools.OutputLimits{
+	MaxOutputBytes: tools.MaxOutputBytes / 2,
+	MaxOutputLines: tools.MaxOutputLines / 2,
+	MaxReadBytes:   tools.MaxReadSize / 2,
+	ReadLines:      tools.DefaultReadLimit / 2,
+	MaxLineLength:  tools.MaxLineLength / 2,
+	MaxListFiles:   tools.MaxLSFiles / 2,
+}
+
+// agentOutputLimits returns the tool output caps of agentID: its
+// outputLimits config on top of the built-in defaults for the agent.
+func agentOutputLimits(agentID config.AgentName) tools.OutputLimits {
+	var limits tools.OutputLimits
+	if agentID == config.AgentExplorer {
+		limits = explorerOutputLimits
+	}
+	if cfg := config.Get(); cfg != nil {
+		if agentCfg, ok := cfg.Agents[agentID]; ok {
+			limits = limits.Merge(tools.NewOutputLimits(agentCfg.OutputLimits))
+		}
+	}
+	return limits
+}
@@ -2,8 +2,10 @@ package agent
 
 import (
 	"context"
+	"slices"
 	"testing"
 
+	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 )
 
@@ -66,6 +68,13 @@ func TestOrderTools(t *testing.T) {
 			},
 			expected: []string{"read", "write", "bash", "mcp_a", "mcp_m", "mcp_z"},
 		},
+		{
+			name: "external tool shadowing a baseline tool is dropped",
+			input: []tools.BaseTool{
+				newMock("bash", true), newMock("bash", false), newMock("jira", false),
+			},
+			expected: []string{"bash", "jira"},
+		},
 		{
 			name:     "empty input",
 			input:    []tools.BaseTool{},
@@ -115,3 +124,50 @@ func TestOrderToolsDeterminism(t *testing.T) {
 		}
 	}
 }
+
+func TestAgentOutputLimits(t *testing.T) {
+	if config.Get() == nil {
+		if _, err := config.Load(t.TempDir(), false); err != nil {
+			t.Fatalf("config.Load: %v", err)
+		}
+	}
+	cfg := config.Get()
+	saved := cfg.Agents
+	t.Cleanup(func() { cfg.Agents = saved })
+	cfg.Agents = map[config.AgentName]config.Agent{
+		config.AgentExplorer: {OutputLimits: &config.OutputLimits{MaxListFiles: 50}},
+		config.AgentCoder:    {OutputLimits: &config.OutputLimits{MaxOutputBytes: 4096}},
+	}
+
+	// The explorer keeps its halved defaults for what it doesn't set.
+	explorer := agentOutputLimits(config.AgentExplorer)
+	if explorer.MaxListFiles != 50 || explorer.MaxOutputBytes != tools.MaxOutputBytes/2 {
+		t.Errorf("explorer limits = %+v", explorer)
+	}
+	coder := agentOutputLimits(config.AgentCoder)
+	if coder != (tools.OutputLimits{MaxOutputBytes: 4096}) {
+		t.Errorf("coder limits = %+v", coder)
+	}
+	if got := agentOutputLimits("workhorse"); got != (tools.OutputLimits{}) {
+		t.Errorf("unconfigured agent limits = %+v, want the defaults", got)
+	}
+}
+
+func TestReadOnlyToolSet(t *testing.T) {
+	var offered []string
+	for _, names := range [][]string{viewerToolNames, editorToolNames, managerToolNames} {
+		for _, name := range names {
+			if offeredInReadOnly(name) {
+				offered = append(offered, name)
+			}
+		}
+	}
+	for _, name := range []string{tools.MonitorToolName, tools.WriteToolName, tools.EditToolName, tools.RunTaskToolName} {
+		if slices.Contains(offered, name) {
+			t.Errorf("%s is offered in read-only mode", name)
+		}
+	}
+	if !slices.Contains(offered, tools.BashToolName) {
+		t.Error("bash should stay in read-only mode and refuse writes itself")
+	}
+}
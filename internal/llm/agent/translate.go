@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/translation"
+)
+
+// translateFinalResponse rewrites the text of the run's final assistant
+// message into the session's configured output language. Code blocks and
+// inline code are masked before the translator sees the text and restored
+// afterwards. Structured-output runs are never touched — their payload is
+// consumed by machines, not read by the user.
+//
+// Every failure is logged and leaves the original response in place:
+// translation is presentation, never a reason to fail a run.
+func (a *agent) translateFinalResponse(ctx context.Context, sessionID string, result *AgentEvent) {
+	if a.translateProvider == nil || a.factory == nil || result.Type != AgentEventTypeResponse || result.StructOutput != nil {
+		return
+	}
+	svc := a.factory.TranslationService()
+	if svc == nil || !svc.IsEnabled(sessionID) {
+		return
+	}
+	original := result.Message.Content().Text
+	if strings.TrimSpace(original) == "" || result.Message.ID == "" {
+		return
+	}
+	masked := translation.Mask(original)
+	if !masked.HasProse() {
+		return
+	}
+
+	translated, err := a.translate(ctx, sessionID, svc.Language(), masked)
+	if err != nil {
+		logging.Warn("Response translation failed, keeping original", "session_id", sessionID, "error", err)
+		return
+	}
+
+	result.Message.SetContent(translated)
+	if err := a.messages.Update(ctx, result.Message); err != nil {
+		logging.Warn("Failed to persist translated response", "session_id", sessionID, "error", err)
+	}
+}
+
+func (a *agent) translate(ctx context.Context, sessionID, language string, masked translation.Masked) (string, error) {
+	ctx = context.WithValue(ctx, tools.AgentIDContextKey, config.AgentTranslator)
+	prompt := fmt.Sprintf("Target language: %s\n\n%s", language, masked.Text)
+	started := time.Now()
+	response, err := a.translateProvider.SendMessages(
+		ctx,
+		[]message.Message{{
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: prompt}},
+		}},
+		make([]tools.BaseTool, 0),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if sess, getErr := a.sessions.Get(ctx, sessionID); getErr == nil {
+		inCost, outCost := provider.CalculateCost(a.translateProvider.Model(), response.Usage)
+		sess.Cost += inCost + outCost
+		a.recordUsage(ctx, sessionID, config.AgentTranslator, a.translateProvider.Model(), response.Usage, nil, "", time.Since(started))
+		if _, saveErr := a.sessions.Save(ctx, sess); saveErr != nil {
+			logging.Warn("Failed to record translation cost", "session_id", sessionID, "error", saveErr)
+		}
+	}
+
+	return masked.Restore(strings.TrimSpace(response.Content))
+}
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// UsageEvent is the usage of one request, published once TrackUsage has
+// added it to the session.
+type UsageEvent struct {
+	SessionID string
+	AgentID   config.AgentName
+	Model     models.Model
+	Usage     provider.TokenUsage
+	Cost      float64
+	// ContextTokens is how much of the model's context window the request
+	// took up.
+	ContextTokens int64
+}
+
+var usageBroker = pubsub.NewBroker[UsageEvent]()
+
+// SubscribeUsage streams the usage of every request as it is tracked.
+func SubscribeUsage(ctx context.Context) <-chan pubsub.Event[UsageEvent] {
+	return usageBroker.Subscribe(ctx)
+}
+
+// toolOutputTokens estimates how many input tokens the results of each tool
+// take up in msgHistory. Image results are left out; their token cost
+// depends on the provider and is not proportional to their encoded size.
+func toolOutputTokens(msgHistory []message.Message) map[string]int64 {
+	callNames := map[string]string{}
+	tokens := map[string]int64{}
+	for _, msg := range msgHistory {
+		for _, call := range msg.ToolCalls() {
+			callNames[call.ID] = call.Name
+		}
+		for _, result := range msg.ToolResults() {
+			if result.IsImageToolResponse() {
+				continue
+			}
+			name := result.Name
+			if name == "" {
+				name = callNames[result.ToolCallID]
+			}
+			if name == "" {
+				continue
+			}
+			tokens[name] += estimateTextTokens(result.Content)
+		}
+	}
+	return tokens
+}
+
+// recordUsage attributes one request's spend to agentName, estimates the
+// share of its input cost each tool's earlier results account for and keeps
+// a row for the request itself, tied to the message it produced when there
+// is one. Failing to record it only costs the reports, so errors are
+// logged, not returned.
+func (a *agent) recordUsage(ctx context.Context, sessionID string, agentName config.AgentName, model models.Model, usage provider.TokenUsage, toolTokens map[string]int64, messageID string, duration time.Duration) {
+	inputCost, outputCost := provider.CalculateCost(model, usage)
+	entries := []session.UsageEntry{{
+		Category:            session.UsageAgent,
+		Name:                string(agentName),
+		Model:               string(model.ID),
+		Requests:            1,
+		InputTokens:         usage.InputTokens,
+		OutputTokens:        usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationTokens,
+		CacheReadTokens:     usage.CacheReadTokens,
+		Cost:                inputCost + outputCost,
+	}}
+
+	totalInput := usage.InputTokens + usage.CacheCreationTokens + usage.CacheReadTokens
+	var estimated int64
+	for _, n := range toolTokens {
+		estimated += n
+	}
+	if totalInput > 0 && estimated > 0 {
+		// Estimates are byte-based; never attribute more than was sent.
+		scale := min(1, float64(totalInput)/float64(estimated))
+		for name, n := range toolTokens {
+			tokens := int64(float64(n) * scale)
+			if tokens == 0 {
+				continue
+			}
+			entries = append(entries, session.UsageEntry{
+				Category:    session.UsageTool,
+				Name:        name,
+				Requests:    1,
+				InputTokens: tokens,
+				// Charged at the request's blended input rate, so cache
+				// hits lower a tool's share the same way they lower the bill.
+				Cost: inputCost * float64(tokens) / float64(totalInput),
+			})
+		}
+	}
+
+	if err := a.sessions.RecordUsage(ctx, sessionID, entries...); err != nil {
+		logging.Warn("Failed to record usage breakdown", "session_id", sessionID, "agent", agentName, "error", err)
+	}
+	if err := a.sessions.RecordMessageUsage(ctx, session.MessageUsage{
+		MessageID:           messageID,
+		SessionID:           sessionID,
+		AgentID:             string(agentName),
+		Model:               string(model.ID),
+		InputTokens:         usage.InputTokens,
+		OutputTokens:        usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationTokens,
+		CacheReadTokens:     usage.CacheReadTokens,
+		Cost:                inputCost + outputCost,
+		DurationMs:          duration.Milliseconds(),
+	}); err != nil {
+		logging.Warn("Failed to record request usage", "session_id", sessionID, "agent", agentName, "error", err)
+	}
+}
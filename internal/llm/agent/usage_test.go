@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+func TestToolOutputTokens(t *testing.T) {
+	history := []message.Message{
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "1", Name: "view"},
+			message.ToolCall{ID: "2", Name: "bash"},
+		}},
+		{Role: message.Tool, Parts: []message.ContentPart{
+			message.ToolResult{ToolCallID: "1", Name: "view", Content: strings.Repeat("a", 400)},
+			// Older results carry no name; it comes from the call.
+			message.ToolResult{ToolCallID: "2", Content: strings.Repeat("b", 80)},
+			message.ToolResult{ToolCallID: "3", Name: "view", Type: message.ToolResultTypeImage, Content: strings.Repeat("c", 4000)},
+		}},
+	}
+	assert.Equal(t, map[string]int64{"view": 100, "bash": 20}, toolOutputTokens(history))
+}
+
+func TestRecordUsageAttributesToolShare(t *testing.T) {
+	sessions := &memSessions{}
+	a := &agent{sessions: sessions}
+	model := models.Model{ID: "test-model", CostPer1MIn: 10, CostPer1MOut: 20}
+	usage := provider.TokenUsage{InputTokens: 1000, OutputTokens: 100}
+
+	// Estimates above what was actually sent are scaled down.
+	a.recordUsage(context.Background(), "s1", config.AgentCoder, model, usage, map[string]int64{"view": 1500, "bash": 500}, "msg-1", 1500*time.Millisecond)
+
+	entries := sessions.usage["s1"]
+	require.Len(t, entries, 3)
+	assert.Equal(t, session.UsageEntry{
+		Category: session.UsageAgent, Name: "coder", Model: "test-model", Requests: 1,
+		InputTokens: 1000, OutputTokens: 100, Cost: 0.012,
+	}, entries[0])
+
+	tools := map[string]session.UsageEntry{}
+	for _, e := range entries[1:] {
+		tools[e.Name] = e
+	}
+	assert.EqualValues(t, 750, tools["view"].InputTokens)
+	assert.InDelta(t, 0.0075, tools["view"].Cost, 1e-9)
+	assert.EqualValues(t, 250, tools["bash"].InputTokens)
+	assert.Equal(t, session.UsageTool, tools["bash"].Category)
+
+	require.Len(t, sessions.requests, 1)
+	req := sessions.requests[0]
+	assert.Equal(t, "msg-1", req.MessageID)
+	assert.Equal(t, "coder", req.AgentID)
+	assert.EqualValues(t, 1500, req.DurationMs)
+	assert.InDelta(t, 0.012, req.Cost, 1e-9)
+}
@@ -0,0 +1,216 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Availability is whether the configured account can use a model, as
+// last checked against its provider's model-listing endpoint.
+type Availability struct {
+	Available bool `json:"available"`
+	// Reason says why an unavailable model cannot be used.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProviderHealth is the outcome of the last check of one provider.
+type ProviderHealth struct {
+	CheckedAt int64 `json:"checked_at"`
+	LatencyMS int64 `json:"latency_ms"`
+	// Listed counts the chat models the provider returned.
+	Listed int `json:"listed"`
+	// Error is set when the listing request failed. Its models are then
+	// unknown, unless the provider rejected the credentials.
+	Error string `json:"error,omitempty"`
+}
+
+// AvailabilityReport is the cached result of the model availability
+// checks. Models of providers that cannot be checked have no entry.
+type AvailabilityReport struct {
+	CheckedAt int64                            `json:"checked_at"`
+	Providers map[ModelProvider]ProviderHealth `json:"providers"`
+	Models    map[ModelID]Availability         `json:"models"`
+}
+
+// AvailabilityPath is where the report is cached, next to the synced
+// catalog: $XDG_CACHE_HOME/opencode/availability.json.
+func AvailabilityPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "opencode", "availability.json")
+}
+
+// LoadAvailability reads the cached report. A missing file is an empty
+// report.
+func LoadAvailability() (AvailabilityReport, error) {
+	report := AvailabilityReport{
+		Providers: map[ModelProvider]ProviderHealth{},
+		Models:    map[ModelID]Availability{},
+	}
+	path := AvailabilityPath()
+	if path == "" {
+		return report, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if report.Providers == nil {
+		report.Providers = map[ModelProvider]ProviderHealth{}
+	}
+	if report.Models == nil {
+		report.Models = map[ModelID]Availability{}
+	}
+	return report, nil
+}
+
+// SaveAvailability writes the report cache.
+func SaveAvailability(report AvailabilityReport) error {
+	path := AvailabilityPath()
+	if path == "" {
+		return errors.New("no cache directory available")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+var (
+	availabilityMu     sync.Mutex
+	availabilityLoaded bool
+	availability       AvailabilityReport
+)
+
+// CurrentAvailability returns the latest report, loading the cache on
+// first use.
+func CurrentAvailability() AvailabilityReport {
+	availabilityMu.Lock()
+	defer availabilityMu.Unlock()
+	if !availabilityLoaded {
+		report, err := LoadAvailability()
+		if err != nil {
+			logging.Debug("Failed to load model availability cache", "error", err)
+		}
+		availability, availabilityLoaded = report, true
+	}
+	return availability
+}
+
+// AvailabilityOf returns the availability of a model. ok is false when it
+// has not been checked.
+func AvailabilityOf(id ModelID) (a Availability, ok bool) {
+	a, ok = CurrentAvailability().Models[id]
+	return a, ok
+}
+
+// CanCheckAvailability reports whether provider's models can be checked.
+func CanCheckAvailability(provider ModelProvider) bool {
+	return CanSync(provider)
+}
+
+// CheckAvailability lists the models of each provider in creds and records
+// which known models the account can use. Providers not in creds keep
+// their previous results. The report is cached and becomes the current
+// one.
+func CheckAvailability(ctx context.Context, creds map[ModelProvider]SyncCredentials) (AvailabilityReport, error) {
+	report := CurrentAvailability()
+	next := AvailabilityReport{
+		CheckedAt: time.Now().Unix(),
+		Providers: map[ModelProvider]ProviderHealth{},
+		Models:    map[ModelID]Availability{},
+	}
+	for p, h := range report.Providers {
+		next.Providers[p] = h
+	}
+	for id, a := range report.Models {
+		// Drop models that are no longer registered.
+		if _, ok := SupportedModels[id]; ok {
+			next.Models[id] = a
+		}
+	}
+
+	providers := make([]ModelProvider, 0, len(creds))
+	for p := range creds {
+		if CanCheckAvailability(p) {
+			providers = append(providers, p)
+		}
+	}
+	slices.Sort(providers)
+
+	for _, provider := range providers {
+		for id, m := range SupportedModels {
+			if m.Provider == provider {
+				delete(next.Models, id)
+			}
+		}
+		start := time.Now()
+		listed, err := modelListers[provider](ctx, creds[provider])
+		health := ProviderHealth{
+			CheckedAt: next.CheckedAt,
+			LatencyMS: time.Since(start).Milliseconds(),
+			Listed:    len(listed),
+		}
+		if err != nil {
+			health.Error = err.Error()
+			next.Providers[provider] = health
+			var le *listError
+			if errors.As(err, &le) && (le.code == http.StatusUnauthorized || le.code == http.StatusForbidden) {
+				reason := "the provider rejected the API key (" + le.status + ")"
+				for id, m := range SupportedModels {
+					if m.Provider == provider {
+						next.Models[id] = Availability{Reason: reason}
+					}
+				}
+			}
+			continue
+		}
+		next.Providers[provider] = health
+
+		served := make(map[string]bool, len(listed))
+		for _, l := range listed {
+			served[l.ID] = true
+		}
+		for id, m := range SupportedModels {
+			if m.Provider != provider {
+				continue
+			}
+			if served[m.APIModel] {
+				next.Models[id] = Availability{Available: true}
+			} else {
+				next.Models[id] = Availability{Reason: fmt.Sprintf("%s is not listed for this account", m.APIModel)}
+			}
+		}
+	}
+
+	availabilityMu.Lock()
+	availability, availabilityLoaded = next, true
+	availabilityMu.Unlock()
+
+	if err := SaveAvailability(next); err != nil {
+		return next, fmt.Errorf("failed to save model availability: %w", err)
+	}
+	return next, nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	saved := maps.Clone(SupportedModels)
+	t.Cleanup(func() { SupportedModels = saved })
+	resetAvailability(t)
+
+	SupportedModels = map[ModelID]Model{
+		"gpt-5":            {ID: "gpt-5", Provider: ProviderOpenAI, APIModel: "gpt-5"},
+		"o3":               {ID: "o3", Provider: ProviderOpenAI, APIModel: "o3"},
+		"claude-sonnet-4":  {ID: "claude-sonnet-4", Provider: ProviderAnthropic, APIModel: "claude-sonnet-4"},
+		"bedrock.claude-4": {ID: "bedrock.claude-4", Provider: ProviderBedrock, APIModel: "claude-4"},
+	}
+
+	anthropicStatus := http.StatusForbidden
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openai/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"id":"gpt-5"},{"id":"gpt-5-mini"}]}`))
+	})
+	mux.HandleFunc("GET /anthropic/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(anthropicStatus)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	creds := map[ModelProvider]SyncCredentials{
+		ProviderOpenAI:    {APIKey: "sk-openai", BaseURL: srv.URL + "/openai"},
+		ProviderAnthropic: {APIKey: "sk-ant", BaseURL: srv.URL + "/anthropic"},
+		ProviderBedrock:   {APIKey: "ignored"},
+	}
+	report, err := CheckAvailability(context.Background(), creds)
+	require.NoError(t, err)
+
+	assert.True(t, report.Models["gpt-5"].Available)
+	assert.False(t, report.Models["o3"].Available)
+	assert.Contains(t, report.Models["o3"].Reason, "not listed")
+	assert.Equal(t, 2, report.Providers[ProviderOpenAI].Listed)
+
+	// Rejected credentials make every model of the provider unavailable.
+	assert.False(t, report.Models["claude-sonnet-4"].Available)
+	assert.Contains(t, report.Models["claude-sonnet-4"].Reason, "403")
+	assert.NotEmpty(t, report.Providers[ProviderAnthropic].Error)
+
+	// Providers without a listing endpoint are not checked.
+	_, checked := AvailabilityOf("bedrock.claude-4")
+	assert.False(t, checked)
+	assert.NotContains(t, report.Providers, ProviderBedrock)
+
+	// A transient failure leaves the provider's models unknown rather than
+	// unavailable.
+	anthropicStatus = http.StatusInternalServerError
+	_, err = CheckAvailability(context.Background(), map[ModelProvider]SyncCredentials{ProviderAnthropic: creds[ProviderAnthropic]})
+	require.NoError(t, err)
+	_, checked = AvailabilityOf("claude-sonnet-4")
+	assert.False(t, checked)
+	a, _ := AvailabilityOf("gpt-5")
+	assert.True(t, a.Available, "providers not checked again keep their results")
+
+	// The report is cached on disk.
+	cached, err := LoadAvailability()
+	require.NoError(t, err)
+	assert.True(t, cached.Models["gpt-5"].Available)
+	assert.Contains(t, cached.Providers[ProviderAnthropic].Error, "500")
+}
+
+func resetAvailability(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		availabilityMu.Lock()
+		availability, availabilityLoaded = AvailabilityReport{}, false
+		availabilityMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
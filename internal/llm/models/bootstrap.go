@@ -12,5 +12,8 @@ func init() {
 	maps.Copy(SupportedModels, YandexCloudModels)
 	maps.Copy(SupportedModels, KimiModels)
 
+	initCatalogModels()
 	initLocalModels()
+	initOllamaModels()
+	initCopilotModels()
 }
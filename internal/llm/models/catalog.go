@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Catalog is the model list written by `opencode models sync`. Its models are
+// registered next to the built-in ones on start, so they show up in the model
+// picker and can be configured like any other model.
+type Catalog struct {
+	UpdatedAt int64   `json:"updated_at"`
+	Models    []Model `json:"models"`
+}
+
+// CatalogPath is where the synced catalog is cached:
+// $XDG_CACHE_HOME/opencode/models.json or ~/.cache/opencode/models.json.
+func CatalogPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "opencode", "models.json")
+}
+
+// LoadCatalog reads the cached catalog. A missing file is an empty catalog.
+func LoadCatalog() (Catalog, error) {
+	var catalog Catalog
+	path := CatalogPath()
+	if path == "" {
+		return catalog, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return catalog, nil
+	}
+	if err != nil {
+		return catalog, err
+	}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return catalog, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// SaveCatalog writes the catalog cache.
+func SaveCatalog(catalog Catalog) error {
+	path := CatalogPath()
+	if path == "" {
+		return errors.New("no cache directory available")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	slices.SortFunc(catalog.Models, func(a, b Model) int { return strings.Compare(string(a.ID), string(b.ID)) })
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func initCatalogModels() {
+	catalog, err := LoadCatalog()
+	if err != nil {
+		logging.Debug("Failed to load synced model catalog", "error", err)
+		return
+	}
+	for _, m := range catalog.Models {
+		// Built-in definitions win over synced ones with the same ID.
+		if _, ok := SupportedModels[m.ID]; !ok {
+			SupportedModels[m.ID] = m
+		}
+	}
+}
+
+// catalogModelID is the ID a synced model is registered as.
+func catalogModelID(provider ModelProvider, apiModel string) ModelID {
+	return ModelID(string(provider) + "." + apiModel)
+}
+
+// knownAPIModel reports whether a built-in model of provider already serves
+// apiModel.
+func knownAPIModel(provider ModelProvider, apiModel string) bool {
+	for _, m := range SupportedModels {
+		if m.Provider == provider && m.APIModel == apiModel {
+			return true
+		}
+	}
+	return false
+}
+
+// closestModel returns the built-in model of provider whose API name shares
+// the longest prefix with apiModel, so a synced model can inherit pricing and
+// capabilities the listing endpoints do not report. For example a new
+// "gpt-5.2-mini" inherits from "gpt-5-mini" rather than from "gpt-4.1".
+func closestModel(provider ModelProvider, apiModel string) (Model, bool) {
+	var (
+		best    Model
+		bestLen int
+	)
+	for _, m := range SupportedModels {
+		if m.Provider != provider {
+			continue
+		}
+		n := commonPrefixLen(m.APIModel, apiModel)
+		// Require the model family to match, not just a shared letter.
+		if n < 4 {
+			continue
+		}
+		if n > bestLen || (n == bestLen && m.APIModel < best.APIModel) {
+			best, bestLen = m, n
+		}
+	}
+	return best, bestLen > 0
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
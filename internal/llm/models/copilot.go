@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/opencode-ai/opencode/internal/copilot"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/spf13/viper"
+)
+
+// GitHub Copilot models are listed from the catalog of the signed-in
+// account (see `opencode auth copilot`) and registered as
+// "copilot.<catalog id>". The chat API is OpenAI-compatible; see
+// provider.NewProvider's ProviderCopilot case.
+const ProviderCopilot ModelProvider = "copilot"
+
+const (
+	copilotDefaultContextWindow = 128_000
+	copilotDefaultMaxTokens     = 16_384
+)
+
+// copilotPreferredModels picks the agents' default model when Copilot is the
+// only provider; the first one the catalog offers wins.
+var copilotPreferredModels = []string{"claude-sonnet-4.5", "gpt-5", "claude-sonnet-4", "gpt-4.1"}
+
+func initCopilotModels() {
+	auth, err := copilot.LoadAuth()
+	if err != nil {
+		if !errors.Is(err, copilot.ErrNotSignedIn) {
+			logging.Debug("Failed to load Copilot sign-in", "error", err)
+		}
+		return
+	}
+	if len(auth.Models) == 0 {
+		logging.Debug("No Copilot models stored; run `opencode auth copilot` again to list them")
+	}
+
+	var ids []string
+	for _, info := range auth.Models {
+		model := convertCopilotModel(info)
+		SupportedModels[model.ID] = model
+		ids = append(ids, info.ID)
+	}
+	if len(ids) > 0 && !viper.IsSet("agents.coder.model") {
+		def := ids[0]
+		for _, id := range copilotPreferredModels {
+			if slices.Contains(ids, id) {
+				def = id
+				break
+			}
+		}
+		id := CopilotModelID(def)
+		viper.SetDefault("agents.coder.model", id)
+		viper.SetDefault("agents.summarizer.model", id)
+		viper.SetDefault("agents.explorer.model", id)
+		viper.SetDefault("agents.descriptor.model", id)
+		viper.SetDefault("agents.workhorse.model", id)
+		viper.SetDefault("agents.hivemind.model", id)
+	}
+	viper.SetDefault("providers.copilot.apiKey", auth.OAuthToken)
+	ProviderPopularity[ProviderCopilot] = 8
+}
+
+// CopilotModelID is the model ID a Copilot catalog entry is registered as.
+func CopilotModelID(catalogID string) ModelID {
+	return ModelID("copilot." + catalogID)
+}
+
+func convertCopilotModel(info copilot.ModelInfo) Model {
+	name := info.Name
+	if name == "" {
+		name = info.ID
+	}
+	window := info.ContextWindow
+	if window <= 0 {
+		window = copilotDefaultContextWindow
+	}
+	maxTokens := info.MaxOutputTokens
+	if maxTokens <= 0 {
+		maxTokens = copilotDefaultMaxTokens
+	}
+	return Model{
+		ID:       CopilotModelID(info.ID),
+		Name:     "Copilot: " + name,
+		Provider: ProviderCopilot,
+		APIModel: info.ID,
+		// Usage is billed through the Copilot subscription, not per token.
+		ContextWindow:       window,
+		DefaultMaxTokens:    maxTokens,
+		CanReason:           info.SupportsReasoningEffort,
+		SupportsAttachments: info.SupportsVision,
+	}
+}
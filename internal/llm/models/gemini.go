@@ -4,8 +4,9 @@ const (
 	ProviderGemini ModelProvider = "gemini"
 
 	// Models
-	Gemini30Pro   ModelID = "gemini-3.0-pro"
-	Gemini30Flash ModelID = "gemini-3.0-flash"
+	Gemini30Pro      ModelID = "gemini-3.0-pro"
+	Gemini30Flash    ModelID = "gemini-3.0-flash"
+	Gemini30ProImage ModelID = "gemini-3.0-pro-image"
 )
 
 var GeminiModels = map[ModelID]Model{
@@ -37,4 +38,19 @@ var GeminiModels = map[ModelID]Model{
 		SupportsAttachments: true,
 		CanReason:           true,
 	},
+	Gemini30ProImage: {
+		ID:                  Gemini30ProImage,
+		Name:                "Gemini 3.0 Pro Image",
+		Provider:            ProviderGemini,
+		APIModel:            "gemini-3-pro-image-preview",
+		CostPer1MIn:         2,
+		CostPer1MInCached:   0.2,
+		CostPer1MOutCached:  0.2,
+		CostPer1MOut:        120, // image output; text is billed at 12
+		ContextWindow:       65536,
+		DefaultMaxTokens:    32768,
+		SupportsAttachments: true,
+		SupportsImageOutput: true,
+		CanReason:           true,
+	},
 }
@@ -22,7 +22,10 @@ type Model struct {
 	SupportsXHighThinking    bool          `json:"supports_xhigh_thinking"`
 	SupportsTaskBudget       bool          `json:"supports_task_budget"`
 	SupportsAttachments      bool          `json:"supports_attachments"`
-	UseLegacyMaxTokens       bool          `json:"use_legacy_max_tokens,omitempty"`
+	// SupportsImageOutput marks models that answer with images as well as
+	// text.
+	SupportsImageOutput bool `json:"supports_image_output,omitempty"`
+	UseLegacyMaxTokens  bool `json:"use_legacy_max_tokens,omitempty"`
 }
 
 const (
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/spf13/viper"
+)
+
+const (
+	ProviderOllama ModelProvider = "ollama"
+
+	ollamaDefaultHost = "http://localhost:11434"
+	ollamaTagsPath    = "/api/tags"
+	ollamaShowPath    = "/api/show"
+
+	// ollamaMaxContextWindow caps the discovered context window. Ollama
+	// allocates the KV cache for the whole num_ctx up front, so asking for a
+	// model's full 128k+ window can exhaust memory on a workstation. Set
+	// providers.ollama.numCtx to go higher.
+	ollamaMaxContextWindow = 32768
+	ollamaFallbackContext  = 4096
+)
+
+var ollamaHTTPClient = &http.Client{Timeout: 3 * time.Second}
+
+// OllamaHost returns the Ollama server URL from OLLAMA_HOST, accepting the
+// bare host:port form the ollama CLI uses, or the default local server.
+func OllamaHost() string {
+	host := strings.TrimSpace(os.Getenv("OLLAMA_HOST"))
+	if host == "" {
+		return ollamaDefaultHost
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/")
+}
+
+func initOllamaModels() {
+	if _, ok := os.LookupEnv("OLLAMA_HOST"); !ok {
+		return
+	}
+	host := OllamaHost()
+	tags := listOllamaModels(host)
+	if len(tags) == 0 {
+		logging.Debug("No ollama models found", "host", host)
+		return
+	}
+	logging.Debug(fmt.Sprintf("%d ollama models found", len(tags)))
+
+	for i, tag := range tags {
+		model := convertOllamaModel(tag, showOllamaModel(host, tag.Name))
+		SupportedModels[model.ID] = model
+		if i == 0 && !viper.IsSet("agents.coder.model") {
+			viper.SetDefault("agents.coder.model", model.ID)
+			viper.SetDefault("agents.summarizer.model", model.ID)
+			viper.SetDefault("agents.explorer.model", model.ID)
+			viper.SetDefault("agents.descriptor.model", model.ID)
+			viper.SetDefault("agents.workhorse.model", model.ID)
+			viper.SetDefault("agents.hivemind.model", model.ID)
+		}
+	}
+
+	// Ollama ignores credentials, but a provider without an API key is
+	// treated as disabled.
+	if token, ok := os.LookupEnv("OLLAMA_API_KEY"); ok {
+		viper.SetDefault("providers.ollama.apiKey", token)
+	} else {
+		viper.SetDefault("providers.ollama.apiKey", "ollama")
+	}
+	ProviderPopularity[ProviderOllama] = 0
+}
+
+type ollamaTag struct {
+	Name string `json:"name"`
+}
+
+type ollamaShow struct {
+	Capabilities []string       `json:"capabilities"`
+	ModelInfo    map[string]any `json:"model_info"`
+}
+
+func listOllamaModels(host string) []ollamaTag {
+	res, err := ollamaHTTPClient.Get(host + ollamaTagsPath)
+	if err != nil {
+		logging.Debug("Failed to list ollama models", "error", err, "host", host)
+		return nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		logging.Debug("Failed to list ollama models", "status", res.StatusCode, "host", host)
+		return nil
+	}
+	var list struct {
+		Models []ollamaTag `json:"models"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		logging.Debug("Failed to list ollama models", "error", err, "host", host)
+		return nil
+	}
+	return list.Models
+}
+
+// showOllamaModel fetches capabilities and the trained context length.
+// Older servers without /api/show capabilities yield nil and the model is
+// registered with conservative defaults.
+func showOllamaModel(host, name string) *ollamaShow {
+	body, _ := json.Marshal(map[string]string{"model": name})
+	res, err := ollamaHTTPClient.Post(host+ollamaShowPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logging.Debug("Failed to show ollama model", "error", err, "model", name)
+		return nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	var show ollamaShow
+	if err := json.NewDecoder(res.Body).Decode(&show); err != nil {
+		logging.Debug("Failed to show ollama model", "error", err, "model", name)
+		return nil
+	}
+	return &show
+}
+
+// contextLength finds "<arch>.context_length" in model_info.
+func (s *ollamaShow) contextLength() int64 {
+	for k, v := range s.ModelInfo {
+		if !strings.HasSuffix(k, ".context_length") {
+			continue
+		}
+		if n, ok := v.(float64); ok && n > 0 {
+			return int64(n)
+		}
+	}
+	return 0
+}
+
+func convertOllamaModel(tag ollamaTag, show *ollamaShow) Model {
+	model := Model{
+		ID:               ModelID("ollama." + tag.Name),
+		Name:             friendlyModelName(strings.Replace(strings.TrimSuffix(tag.Name, ":latest"), ":", "@", 1)),
+		Provider:         ProviderOllama,
+		APIModel:         tag.Name,
+		ContextWindow:    ollamaFallbackContext,
+		DefaultMaxTokens: ollamaFallbackContext / 2,
+	}
+	if show == nil {
+		return model
+	}
+	if n := show.contextLength(); n > 0 {
+		model.ContextWindow = min(n, ollamaMaxContextWindow)
+		model.DefaultMaxTokens = model.ContextWindow / 4
+	}
+	model.CanReason = slices.Contains(show.Capabilities, "thinking")
+	model.SupportsAttachments = slices.Contains(show.Capabilities, "vision")
+	return model
+}
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SyncCredentials is what a provider's model-listing endpoint needs.
+type SyncCredentials struct {
+	APIKey  string
+	BaseURL string
+	Headers map[string]string
+}
+
+// SyncResult reports what syncing one provider changed.
+type SyncResult struct {
+	Provider ModelProvider
+	// Listed counts the chat models the provider returned.
+	Listed int
+	// Added are the models not built in, now in the catalog.
+	Added []Model
+	Err   error
+}
+
+// listedModel is a chat model as reported by a provider's listing endpoint.
+// Zero limits mean the endpoint does not report them.
+type listedModel struct {
+	ID              string
+	Name            string
+	ContextWindow   int64
+	MaxOutputTokens int64
+}
+
+type modelLister func(ctx context.Context, creds SyncCredentials) ([]listedModel, error)
+
+// modelListers are the providers whose catalogs can be synced. Local, Ollama
+// and Copilot models are discovered on every start instead; Bedrock, Vertex
+// AI, YandexCloud and Kimi have no listing endpoint usable with the
+// configured credentials.
+var modelListers = map[ModelProvider]modelLister{
+	ProviderOpenAI:    listOpenAIModels,
+	ProviderAnthropic: listAnthropicModels,
+	ProviderGemini:    listGeminiModels,
+}
+
+var (
+	openAIModelsURL    = "https://api.openai.com/v1"
+	anthropicModelsURL = "https://api.anthropic.com"
+	geminiModelsURL    = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+var syncHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// CanSync reports whether provider's models can be synced.
+func CanSync(provider ModelProvider) bool {
+	_, ok := modelListers[provider]
+	return ok
+}
+
+// SyncCatalog lists the models of each provider in creds, merges the ones
+// that are not built in into the cached catalog and registers them in
+// SupportedModels. A provider that fails keeps its previously synced models;
+// one that succeeds has its entries replaced, so retired models drop out.
+func SyncCatalog(ctx context.Context, creds map[ModelProvider]SyncCredentials) ([]SyncResult, error) {
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]ModelProvider, 0, len(creds))
+	for p := range creds {
+		if CanSync(p) {
+			providers = append(providers, p)
+		}
+	}
+	slices.Sort(providers)
+
+	var results []SyncResult
+	for _, provider := range providers {
+		result := SyncResult{Provider: provider}
+		listed, err := modelListers[provider](ctx, creds[provider])
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+		result.Listed = len(listed)
+
+		// Drop the previous sync first so new entries only derive from
+		// built-in models.
+		catalog.Models = slices.DeleteFunc(catalog.Models, func(m Model) bool {
+			if m.Provider != provider {
+				return false
+			}
+			delete(SupportedModels, m.ID)
+			return true
+		})
+		for _, l := range listed {
+			if knownAPIModel(provider, l.ID) {
+				continue
+			}
+			result.Added = append(result.Added, newCatalogModel(provider, l))
+		}
+		catalog.Models = append(catalog.Models, result.Added...)
+		for _, m := range result.Added {
+			SupportedModels[m.ID] = m
+		}
+		results = append(results, result)
+	}
+
+	catalog.UpdatedAt = time.Now().Unix()
+	if err := SaveCatalog(catalog); err != nil {
+		return results, fmt.Errorf("failed to save model catalog: %w", err)
+	}
+	return results, nil
+}
+
+func newCatalogModel(provider ModelProvider, l listedModel) Model {
+	m, ok := closestModel(provider, l.ID)
+	if !ok {
+		m = Model{
+			ContextWindow:    128_000,
+			DefaultMaxTokens: 8_192,
+		}
+	}
+	m.ID = catalogModelID(provider, l.ID)
+	m.Name = cmp.Or(l.Name, friendlyModelName(l.ID))
+	m.Provider = provider
+	m.APIModel = l.ID
+	m.ContextWindow = cmp.Or(l.ContextWindow, m.ContextWindow)
+	m.DefaultMaxTokens = cmp.Or(l.MaxOutputTokens, m.DefaultMaxTokens)
+	// Gemini names its image generation models gemini-*-image*.
+	m.SupportsImageOutput = provider == ProviderGemini && strings.Contains(l.ID, "-image")
+	return m
+}
+
+func getModelList(ctx context.Context, endpoint string, headers map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := syncHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return &listError{code: res.StatusCode, status: res.Status}
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// listError is a listing request the provider answered with an error
+// status.
+type listError struct {
+	code   int
+	status string
+}
+
+func (e *listError) Error() string { return "listing models failed: " + e.status }
+
+func syncHeaders(creds SyncCredentials, extra map[string]string) map[string]string {
+	headers := map[string]string{}
+	for k, v := range creds.Headers {
+		headers[k] = v
+	}
+	for k, v := range extra {
+		headers[k] = v
+	}
+	return headers
+}
+
+// openAINonChat marks listed OpenAI models that cannot run an agent.
+var openAINonChat = []string{"audio", "realtime", "tts", "transcribe", "image", "embedding", "search", "instruct", "moderation", "dall-e", "whisper", "davinci", "babbage"}
+
+func listOpenAIModels(ctx context.Context, creds SyncCredentials) ([]listedModel, error) {
+	var body struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	base := strings.TrimRight(cmp.Or(creds.BaseURL, openAIModelsURL), "/")
+	headers := syncHeaders(creds, map[string]string{"Authorization": "Bearer " + creds.APIKey})
+	if err := getModelList(ctx, base+"/models", headers, &body); err != nil {
+		return nil, err
+	}
+	var listed []listedModel
+	for _, m := range body.Data {
+		chat := strings.HasPrefix(m.ID, "gpt-") || (len(m.ID) > 1 && m.ID[0] == 'o' && m.ID[1] >= '1' && m.ID[1] <= '9')
+		if !chat || slices.ContainsFunc(openAINonChat, func(s string) bool { return strings.Contains(m.ID, s) }) {
+			continue
+		}
+		listed = append(listed, listedModel{ID: m.ID})
+	}
+	return listed, nil
+}
+
+func listAnthropicModels(ctx context.Context, creds SyncCredentials) ([]listedModel, error) {
+	base := strings.TrimRight(cmp.Or(creds.BaseURL, anthropicModelsURL), "/")
+	headers := syncHeaders(creds, map[string]string{
+		"x-api-key":         creds.APIKey,
+		"anthropic-version": "2023-06-01",
+	})
+	var listed []listedModel
+	afterID := ""
+	for {
+		var body struct {
+			Data []struct {
+				ID             string `json:"id"`
+				DisplayName    string `json:"display_name"`
+				MaxInputTokens int64  `json:"max_input_tokens"`
+				MaxTokens      int64  `json:"max_tokens"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		query := url.Values{"limit": {"1000"}}
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+		if err := getModelList(ctx, base+"/v1/models?"+query.Encode(), headers, &body); err != nil {
+			return nil, err
+		}
+		for _, m := range body.Data {
+			listed = append(listed, listedModel{
+				ID:              m.ID,
+				Name:            m.DisplayName,
+				ContextWindow:   m.MaxInputTokens,
+				MaxOutputTokens: m.MaxTokens,
+			})
+		}
+		if !body.HasMore || body.LastID == "" {
+			return listed, nil
+		}
+		afterID = body.LastID
+	}
+}
+
+func listGeminiModels(ctx context.Context, creds SyncCredentials) ([]listedModel, error) {
+	base := strings.TrimRight(cmp.Or(creds.BaseURL, geminiModelsURL), "/")
+	headers := syncHeaders(creds, map[string]string{"x-goog-api-key": creds.APIKey})
+	var listed []listedModel
+	pageToken := ""
+	for {
+		var body struct {
+			Models []struct {
+				Name                       string   `json:"name"`
+				DisplayName                string   `json:"displayName"`
+				InputTokenLimit            int64    `json:"inputTokenLimit"`
+				OutputTokenLimit           int64    `json:"outputTokenLimit"`
+				SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+			} `json:"models"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		query := url.Values{"pageSize": {"1000"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		if err := getModelList(ctx, base+"/models?"+query.Encode(), headers, &body); err != nil {
+			return nil, err
+		}
+		for _, m := range body.Models {
+			id := strings.TrimPrefix(m.Name, "models/")
+			if !strings.HasPrefix(id, "gemini") || !slices.Contains(m.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			listed = append(listed, listedModel{
+				ID:              id,
+				Name:            m.DisplayName,
+				ContextWindow:   m.InputTokenLimit,
+				MaxOutputTokens: m.OutputTokenLimit,
+			})
+		}
+		if body.NextPageToken == "" {
+			return listed, nil
+		}
+		pageToken = body.NextPageToken
+	}
+}
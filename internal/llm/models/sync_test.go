@@ -0,0 +1,85 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncCatalog(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	saved := maps.Clone(SupportedModels)
+	t.Cleanup(func() { SupportedModels = saved })
+
+	openAIFails := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openai/models", func(w http.ResponseWriter, r *http.Request) {
+		if openAIFails {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "Bearer sk-openai", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data":[{"id":"gpt-5"},{"id":"gpt-5.2"},{"id":"gpt-4o-realtime-preview"},{"id":"text-embedding-3-small"},{"id":"o9"}]}`))
+	})
+	mux.HandleFunc("GET /anthropic/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sk-ant", r.Header.Get("x-api-key"))
+		if r.URL.Query().Get("after_id") == "" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"data":     []map[string]any{{"id": "claude-sonnet-9", "display_name": "Claude Sonnet 9", "max_input_tokens": 400000}},
+				"has_more": true, "last_id": "claude-sonnet-9",
+			})
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"claude-haiku-9","display_name":"Claude Haiku 9"}],"has_more":false}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	creds := map[ModelProvider]SyncCredentials{
+		ProviderOpenAI:    {APIKey: "sk-openai", BaseURL: srv.URL + "/openai"},
+		ProviderAnthropic: {APIKey: "sk-ant", BaseURL: srv.URL + "/anthropic"},
+		ProviderBedrock:   {APIKey: "ignored"},
+	}
+	results, err := SyncCatalog(context.Background(), creds)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, ProviderAnthropic, results[0].Provider)
+	assert.Len(t, results[0].Added, 2)
+	sonnet := SupportedModels["anthropic.claude-sonnet-9"]
+	assert.Equal(t, "Claude Sonnet 9", sonnet.Name)
+	assert.EqualValues(t, 400000, sonnet.ContextWindow)
+
+	assert.Equal(t, ProviderOpenAI, results[1].Provider)
+	assert.Equal(t, 3, results[1].Listed)
+	require.Len(t, results[1].Added, 2)
+	gpt := SupportedModels["openai.gpt-5.2"]
+	assert.Equal(t, "gpt-5.2", gpt.APIModel)
+	// Pricing is inherited from the closest built-in model.
+	assert.Equal(t, SupportedModels[GPT5].CostPer1MIn, gpt.CostPer1MIn)
+	assert.Equal(t, SupportedModels[GPT5].ContextWindow, gpt.ContextWindow)
+
+	catalog, err := LoadCatalog()
+	require.NoError(t, err)
+	assert.Len(t, catalog.Models, 4)
+
+	// A failing provider keeps its previously synced models.
+	openAIFails = true
+	results, err = SyncCatalog(context.Background(), creds)
+	require.NoError(t, err)
+	assert.Error(t, results[1].Err)
+	catalog, err = LoadCatalog()
+	require.NoError(t, err)
+	assert.Len(t, catalog.Models, 4)
+
+	// Cached models are registered on start.
+	SupportedModels = maps.Clone(saved)
+	initCatalogModels()
+	assert.Contains(t, SupportedModels, ModelID("openai.gpt-5.2"))
+}
@@ -0,0 +1,136 @@
+package prompt
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// ContextFile is a context file injected into an agent's system prompt.
+type ContextFile struct {
+	// Path is relative to the working directory.
+	Path string
+	// Tokens estimates the file as injected, after any truncation.
+	Tokens int
+	// OriginalTokens estimates the whole file; it is 0 unless the file was
+	// truncated to its contextPaths limit.
+	OriginalTokens int
+
+	// text is the file's block in the prompt.
+	text string
+}
+
+// ProjectContext is the project-specific context of an agent's system
+// prompt: its context files, then its inline snippets.
+type ProjectContext struct {
+	Text     string
+	Files    []ContextFile
+	Snippets []string
+	// Tokens estimates Text as a whole.
+	Tokens int
+}
+
+// contextFileSet is a read contextPaths list and the text it renders to.
+type contextFileSet struct {
+	entries []contextEntry
+	text    string
+}
+
+var (
+	contextMu sync.Mutex
+	// contextFileSets caches the files read for the global contextPaths
+	// (key "") and for each agent that sets its own (key agent ID).
+	contextFileSets = map[string]contextFileSet{}
+	// contextVersion counts InvalidateContext calls.
+	contextVersion atomic.Int64
+)
+
+// InvalidateContext drops the context files read so far, so system prompts
+// built from now on read them again. Call it after writing a context file
+// or changing contextPaths.
+func InvalidateContext() {
+	contextMu.Lock()
+	contextFileSets = map[string]contextFileSet{}
+	contextMu.Unlock()
+	contextVersion.Add(1)
+}
+
+// ContextVersion changes on every InvalidateContext; a system prompt built
+// at another version may hold stale context files.
+func ContextVersion() int64 {
+	return contextVersion.Load()
+}
+
+// BuildContext assembles the project context of the agent's system prompt
+// and reports what went into it, so oversized prompts can be traced back to
+// the files responsible. Files are read once per process; an agent with its
+// own contextPaths reads those instead of the global list.
+func BuildContext(agentName config.AgentName) ProjectContext {
+	return buildContext(agentName, agentregistry.GetRegistry())
+}
+
+func buildContext(agentName config.AgentName, reg agentregistry.Registry) ProjectContext {
+	cfg := config.Get()
+	paths, key := cfg.ContextPaths, ""
+	var snippets []string
+	if info, ok := reg.Get(agentName); ok {
+		if info.ContextPaths != nil {
+			paths, key = info.ContextPaths, agentName
+		}
+		for _, s := range info.Context {
+			if s = strings.TrimSpace(s); s != "" {
+				snippets = append(snippets, s)
+			}
+		}
+	}
+
+	contextMu.Lock()
+	files, ok := contextFileSets[key]
+	if !ok {
+		files.entries = readContextPaths(cfg.WorkingDir, paths)
+		files.text = joinContextEntries(cfg.WorkingDir, files.entries)
+		contextFileSets[key] = files
+		logging.Debug("Context content", "agent", agentName, "context", files.text)
+	}
+	contextMu.Unlock()
+
+	pc := ProjectContext{Snippets: snippets}
+	parts := make([]string, 0, 2)
+	if files.text != "" {
+		parts = append(parts, files.text)
+	}
+	for _, e := range files.entries {
+		pc.Files = append(pc.Files, ContextFile{
+			Path:           relContextPath(cfg.WorkingDir, e.path),
+			Tokens:         estimateTokens(e.content),
+			OriginalTokens: e.originalTokens,
+			text:           e.content,
+		})
+	}
+	if len(snippets) > 0 {
+		parts = append(parts, strings.Join(snippets, "\n\n"))
+	}
+	pc.Text = strings.Join(parts, "\n")
+	pc.Tokens = estimateTokens(pc.Text)
+	return pc
+}
+
+func relContextPath(workDir, path string) string {
+	rel, err := filepath.Rel(workDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// snippetName names the i-th context snippet of an agent in prompt
+// sections.
+func snippetName(i int) string {
+	return fmt.Sprintf("snippet %d", i+1)
+}
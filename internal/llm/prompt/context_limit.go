@@ -0,0 +1,115 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// estimateTokens uses the same four-bytes-per-token rule as tool output
+// limits; exact counts would need the active model's tokenizer.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+type contextBlock struct {
+	text    string
+	heading bool
+	// first marks the first paragraph after a heading (or of the file).
+	first bool
+}
+
+// splitContextBlocks splits markdown into headings and blank-line separated
+// paragraphs. Fenced code blocks stay whole even when they contain blank
+// lines.
+func splitContextBlocks(content string) []contextBlock {
+	var (
+		blocks  []contextBlock
+		para    []string
+		inFence bool
+		first   = true
+	)
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		blocks = append(blocks, contextBlock{text: strings.Join(para, "\n"), first: first})
+		para = nil
+		first = false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			para = append(para, line)
+			continue
+		}
+		switch {
+		case inFence:
+			para = append(para, line)
+		case strings.HasPrefix(line, "#"):
+			flush()
+			blocks = append(blocks, contextBlock{text: line, heading: true})
+			first = true
+		case trimmed == "":
+			flush()
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// truncateContext shrinks content to about maxTokens. Rather than cutting
+// the tail, it keeps the document outline: every heading first, then the
+// first paragraph under each heading, then the remaining paragraphs in
+// order, as long as they fit. Dropped runs are marked with "[...]" so the
+// model knows the file is incomplete.
+func truncateContext(content string, maxTokens int) (string, bool) {
+	if maxTokens <= 0 || estimateTokens(content) <= maxTokens {
+		return content, false
+	}
+	blocks := splitContextBlocks(content)
+	keep := make([]bool, len(blocks))
+	budget := maxTokens
+	take := func(pick func(contextBlock) bool) {
+		for i, b := range blocks {
+			if keep[i] || !pick(b) {
+				continue
+			}
+			// +1 for the separating blank line
+			if cost := estimateTokens(b.text) + 1; cost <= budget {
+				keep[i] = true
+				budget -= cost
+			}
+		}
+	}
+	take(func(b contextBlock) bool { return b.heading })
+	take(func(b contextBlock) bool { return b.first })
+	take(func(contextBlock) bool { return true })
+
+	var out []string
+	skipped := false
+	for i, b := range blocks {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out = append(out, "[...]")
+			skipped = false
+		}
+		out = append(out, b.text)
+	}
+	if skipped {
+		out = append(out, "[...]")
+	}
+	return strings.Join(out, "\n\n"), true
+}
+
+// truncationNote tells the model where to find the rest of a shortened
+// context file.
+func truncationNote(path string, originalTokens, maxTokens int) string {
+	return fmt.Sprintf("\n\n[This file was shortened from ~%d to ~%d tokens to fit the context budget. Read %s for the full content.]",
+		originalTokens, maxTokens, path)
+}
@@ -0,0 +1,68 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateContextKeepsOutline(t *testing.T) {
+	filler := strings.Repeat("filler text that pads a section well past the limit. ", 10)
+	content := strings.Join([]string{
+		"# Guide",
+		"Intro paragraph.",
+		filler,
+		"## Build",
+		"Run make build.",
+		"```sh\nmake build\n\nmake test\n```",
+		filler,
+		"## Style",
+		"Use gofmt.",
+		filler,
+	}, "\n\n")
+
+	out, truncated := truncateContext(content, 60)
+	require.True(t, truncated)
+	assert.LessOrEqual(t, estimateTokens(out), 60+10, "output should stay near the budget")
+	for _, want := range []string{"# Guide", "## Build", "## Style", "Intro paragraph.", "Run make build.", "Use gofmt.", "[...]"} {
+		assert.Contains(t, out, want)
+	}
+	assert.NotContains(t, out, "filler")
+	assert.Less(t, strings.Index(out, "## Build"), strings.Index(out, "## Style"), "document order is preserved")
+
+	same, truncated := truncateContext("short", 60)
+	assert.False(t, truncated)
+	assert.Equal(t, "short", same)
+}
+
+func TestSplitContextBlocksKeepsFencesWhole(t *testing.T) {
+	blocks := splitContextBlocks("# T\n\n```\n# not a heading\n\nstill code\n```\n\nafter")
+	require.Len(t, blocks, 3)
+	assert.True(t, blocks[0].heading)
+	assert.Equal(t, "```\n# not a heading\n\nstill code\n```", blocks[1].text)
+	assert.True(t, blocks[1].first)
+	assert.False(t, blocks[2].first)
+}
+
+func TestProcessContextPathsLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	big := "# Big\n\nFirst.\n\n" + strings.Repeat("x", 4000)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "big.md"), []byte(big), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "free.md"), []byte(big), 0o644))
+
+	result := processContextPaths(tmpDir, []config.ContextPath{
+		{Path: "big.md", MaxTokens: 100},
+		{Path: "free.md", MaxTokens: -1},
+	})
+	parts := strings.SplitN(result, "# From:"+filepath.Join(tmpDir, "free.md"), 2)
+	require.Len(t, parts, 2)
+	assert.Contains(t, parts[0], "First.")
+	assert.Contains(t, parts[0], "shortened from ~1004 to ~100 tokens")
+	assert.NotContains(t, parts[0], strings.Repeat("x", 100))
+	assert.Contains(t, parts[1], strings.Repeat("x", 4000), "maxTokens -1 disables the limit")
+}
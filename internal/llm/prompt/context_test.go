@@ -0,0 +1,56 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+func TestBuildContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFiles(t, tmpDir, []string{"AGENTS.md", "review/rules.md", "review/style.md"})
+	config.Reset()
+	_, err := config.Load(tmpDir, false)
+	require.NoError(t, err)
+	t.Cleanup(config.Reset)
+	// Drop files another test may have cached for the global contextPaths.
+	InvalidateContext()
+
+	reg := &mockRegistry{agents: map[string]agentregistry.AgentInfo{
+		"build-context-reviewer": {
+			ID:           "build-context-reviewer",
+			ContextPaths: []config.ContextPath{{Path: "review/"}},
+			Context:      []string{"Flag every TODO.", "  "},
+		},
+	}}
+
+	pc := buildContext("build-context-reviewer", reg)
+	require.Len(t, pc.Files, 2)
+	assert.Equal(t, "review/rules.md", pc.Files[0].Path)
+	assert.Equal(t, "review/style.md", pc.Files[1].Path)
+	assert.Positive(t, pc.Files[0].Tokens)
+	assert.Zero(t, pc.Files[0].OriginalTokens)
+	assert.Equal(t, []string{"Flag every TODO."}, pc.Snippets)
+	assert.NotContains(t, pc.Text, "AGENTS.md", "agent contextPaths replace the global list")
+	assert.True(t, strings.HasSuffix(pc.Text, "\nFlag every TODO."), "snippets follow the files")
+	assert.Equal(t, estimateTokens(pc.Text), pc.Tokens)
+
+	// Agents without their own contextPaths get the global list.
+	pc = buildContext("unregistered", reg)
+	require.Len(t, pc.Files, 1)
+	assert.Equal(t, "AGENTS.md", pc.Files[0].Path)
+	assert.Empty(t, pc.Snippets)
+
+	// Files are cached until the context is invalidated.
+	createTestFiles(t, tmpDir, []string{"review/naming.md"})
+	assert.Len(t, buildContext("build-context-reviewer", reg).Files, 2)
+	version := ContextVersion()
+	InvalidateContext()
+	assert.NotEqual(t, version, ContextVersion())
+	assert.Len(t, buildContext("build-context-reviewer", reg).Files, 3)
+}
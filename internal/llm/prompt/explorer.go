@@ -16,6 +16,7 @@ Your strengths:
 
 - Use Glob for broad file pattern matching
 - ALWAYS use Grep for searching file contents. NEVER invoke ` + "`grep`" + ` or ` + "`rg`" + ` via bash
+- When LSP Symbols is available, use it to jump to a definition, list the references to a function or type, or outline a file; fall back to Grep for text, comments and file types without a language server
 - Use Read when you know the specific file path you need to read
 - Use View Image when you know the specific image file path you need to view
 - Use Web Fetch when you have a web link to lookup
@@ -18,7 +18,9 @@ import (
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp/install"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/project"
 	"github.com/opencode-ai/opencode/internal/skill"
 )
 
@@ -210,6 +212,7 @@ const backgroundTasksPrompt = `
 - To watch a streaming command for specific markers, use ` + "`monitor`" + ` (cmd + pattern). Matched lines are coalesced into per-window notifications — strictly better than ` + "`while true; do sleep 5; grep ERROR ...; done`" + `.
 - ` + "`tasklist`" + ` is for ONE-SHOT inventory queries only. Do NOT poll it. Completion notifications arrive automatically.
 - ` + "`taskstop`" + ` kills a background task and emits a synthetic ` + "`killed`" + ` completion. Use only when the task is no longer useful.
+- For servers and watchers that never exit, ` + "`job_output`" + ` reads what a background bash job has printed so far (pass the returned ` + "`next_offset`" + ` to read only new output), ` + "`job_status`" + ` reports whether it is still up and ` + "`job_kill`" + ` stops it. Stop such jobs once you are done with them; they are also killed when the user cancels the session.
 - DO NOT use ` + "`sleep N`" + ` followed by status-check tool calls — every polling round costs tokens and invalidates the prompt cache. Spawn the work in background and let the notification system wake you when it finishes. In non-interactive (flow) runs the runtime holds your turn open until pending background tasks complete and converts a foreground ` + "`sleep`" + ` into that same wait — sleeping can never observe progress sooner.`
 
 // taskToolReportingPrompt instructs primary (mode=agent) agents that have the
@@ -251,7 +254,7 @@ func getEnvironmentInfo() string {
 	isGit := isGitRepo(cwd)
 	platform := runtime.GOOS
 	date := time.Now().Format("1/2/2006")
-	ls := tools.NewLsTool(config.Get(), nil, nil)
+	ls := tools.NewLsTool(config.Get(), nil, nil, tools.OutputLimits{})
 	r, _ := ls.Run(context.Background(), tools.ToolCall{
 		Input: `{"path":"."}`,
 	})
@@ -268,6 +271,40 @@ Today's date: %s
 		`, cwd, boolToYesNo(isGit), platform, date, r.Content)
 }
 
+// workspaceFacts renders the cached project facts, or "" when nothing was
+// detected.
+func workspaceFacts(withTool bool) string {
+	facts := project.LoadFacts(context.Background(), config.WorkingDirectory(), config.Get().Data.Directory, false).Prompt()
+	if facts == "" {
+		return ""
+	}
+	hint := ""
+	if withTool {
+		hint = "\nUse the " + tools.FactsToolName + " tool for per-language file counts and CI file details instead of exploring for them."
+	}
+	return fmt.Sprintf("Here are facts about the workspace, detected at the current commit:\n<workspace_facts>\n%s\n</workspace_facts>%s", facts, hint)
+}
+
+// gitInfoTimeout bounds the git calls made while building a prompt, so a
+// huge or locked repository cannot stall agent startup.
+const gitInfoTimeout = 3 * time.Second
+
+// gitInfo renders the repository state at prompt time, or "" outside a git
+// repository.
+func gitInfo(withTool bool) string {
+	ctx, cancel := context.WithTimeout(context.Background(), gitInfoTimeout)
+	defer cancel()
+	info, ok := project.LoadGitInfo(ctx, config.WorkingDirectory())
+	if !ok {
+		return ""
+	}
+	hint := "\nThis is the state when this prompt was written; do not run git status just to learn it."
+	if withTool {
+		hint += " Use the " + tools.GitContextToolName + " tool for the current state and the list of changed files."
+	}
+	return fmt.Sprintf("Here is the state of the git repository:\n<git>\n%s\n</git>%s", info.Prompt(), hint)
+}
+
 func isGitRepo(dir string) bool {
 	_, err := os.Stat(filepath.Join(dir, ".git"))
 	return err == nil
@@ -362,6 +399,8 @@ func getAgentPromptInternal(agentName config.AgentName, provider models.ModelPro
 			basePrompt = WorkhorsePrompt(provider)
 		case config.AgentHivemind:
 			basePrompt = HivemindPrompt(provider)
+		case config.AgentTranslator:
+			basePrompt = TranslatorPrompt(provider)
 		default:
 			basePrompt = "You are a helpful assistant"
 		}
@@ -446,6 +485,17 @@ func getAgentPromptInternal(agentName config.AgentName, provider models.ModelPro
 	if info, ok := reg.Get(agentName); ok {
 		if info.Mode == config.AgentModeAgent {
 			basePrompt += "\n\n" + getEnvironmentInfo()
+			if git := gitInfo(reg.IsToolEnabled(agentName, tools.GitContextToolName)); git != "" {
+				basePrompt += "\n\n" + git
+			}
+		}
+	}
+
+	// Give the coding agents the workspace facts up front so they don't
+	// spend turns rediscovering the toolchain.
+	if agentName == config.AgentCoder || agentName == config.AgentWorkhorse {
+		if facts := workspaceFacts(reg.IsToolEnabled(agentName, tools.FactsToolName)); facts != "" {
+			basePrompt += "\n\n" + facts
 		}
 	}
 
@@ -455,7 +505,15 @@ func getAgentPromptInternal(agentName config.AgentName, provider models.ModelPro
 		basePrompt += "\n" + lspInformation()
 	}
 
-	contextContent := getContextFromPaths()
+	// Remembered project facts follow the agent across sessions; only
+	// agents with tools can act on them or keep them up to date.
+	if reg.HasTools(agentName) {
+		if mem := memory.PromptSection(context.Background()); mem != "" {
+			basePrompt += "\n\n" + mem
+		}
+	}
+
+	contextContent := BuildContext(agentName).Text
 	if contextContent != "" {
 		return fmt.Sprintf("%s\n\n# Project-Specific Context\n Make sure to follow the instructions in the context below\n%s", basePrompt, contextContent)
 	}
@@ -468,9 +526,29 @@ const preloadedSkillSizeWarningThreshold = 200 * 1024 // 200KB
 // Skills are only skipped if explicitly denied. ActionAsk is treated as allow because
 // listing a skill in the agent definition is explicit user intent.
 func appendPreloadedSkills(agentName string, reg agentregistry.Registry) string {
+	var sb strings.Builder
+	totalSize := 0
+	for _, section := range preloadedSkills(agentName, reg) {
+		totalSize += len(section.Text)
+		sb.WriteString("\n\n")
+		sb.WriteString(section.Text)
+	}
+
+	if totalSize > preloadedSkillSizeWarningThreshold {
+		logging.Warn("Preloaded skills total size exceeds recommended threshold",
+			"agentID", agentName,
+			"totalBytes", totalSize,
+			"threshold", preloadedSkillSizeWarningThreshold,
+		)
+	}
+
+	return sb.String()
+}
+
+func preloadedSkills(agentName string, reg agentregistry.Registry) []PromptSection {
 	info, ok := reg.Get(agentName)
 	if !ok || len(info.Skills) == 0 {
-		return ""
+		return nil
 	}
 
 	// Sort for deterministic output
@@ -478,9 +556,7 @@ func appendPreloadedSkills(agentName string, reg agentregistry.Registry) string
 	copy(sorted, info.Skills)
 	sort.Strings(sorted)
 
-	var sb strings.Builder
-	totalSize := 0
-
+	var sections []PromptSection
 	for _, name := range sorted {
 		// Check skill-specific permission patterns directly, bypassing IsToolEnabled.
 		// Preloaded skills don't use the skill tool, so tools:{"skill": false} (which
@@ -497,48 +573,28 @@ func appendPreloadedSkills(agentName string, reg agentregistry.Registry) string
 			continue
 		}
 
-		wrapped := skill.WrapSkillContent(name, skillInfo.Content)
-		totalSize += len(wrapped)
-		sb.WriteString("\n\n")
-		sb.WriteString(wrapped)
-	}
-
-	if totalSize > preloadedSkillSizeWarningThreshold {
-		logging.Warn("Preloaded skills total size exceeds recommended threshold",
-			"agentID", agentName,
-			"totalBytes", totalSize,
-			"threshold", preloadedSkillSizeWarningThreshold,
-		)
+		sections = append(sections, PromptSection{
+			Kind: SectionSkill,
+			Name: name,
+			Text: skill.WrapSkillContent(name, skillInfo.Content),
+		})
 	}
-
-	return sb.String()
-}
-
-var (
-	onceContext    sync.Once
-	contextContent string
-)
-
-func getContextFromPaths() string {
-	onceContext.Do(func() {
-		var (
-			cfg          = config.Get()
-			workDir      = cfg.WorkingDir
-			contextPaths = cfg.ContextPaths
-		)
-		contextContent = processContextPaths(workDir, contextPaths)
-		logging.Debug("Context content", "context", contextContent)
-	})
-
-	return contextContent
+	return sections
 }
 
 type contextEntry struct {
 	path    string
 	content string
+	// originalTokens is set when content was truncated.
+	originalTokens int
 }
 
-func processContextPaths(workDir string, paths []string) string {
+func processContextPaths(workDir string, paths []config.ContextPath) string {
+	return joinContextEntries(workDir, readContextPaths(workDir, paths))
+}
+
+// readContextPaths reads the files paths name, sorted by path.
+func readContextPaths(workDir string, paths []config.ContextPath) []contextEntry {
 	var (
 		wg       sync.WaitGroup
 		resultCh = make(chan contextEntry)
@@ -550,7 +606,7 @@ func processContextPaths(workDir string, paths []string) string {
 
 	for _, path := range paths {
 		wg.Add(1)
-		go func(p string) {
+		go func(p string, maxTokens int) {
 			defer wg.Done()
 
 			if strings.HasSuffix(p, "/") {
@@ -560,8 +616,8 @@ func processContextPaths(workDir string, paths []string) string {
 					}
 					if !d.IsDir() {
 						if tryMarkProcessed(path, processedFiles, &processedMutex) {
-							if content := processFile(path); content != "" {
-								resultCh <- contextEntry{path: path, content: content}
+							if entry, ok := processFile(path, maxTokens); ok {
+								resultCh <- entry
 							}
 						}
 					}
@@ -570,12 +626,12 @@ func processContextPaths(workDir string, paths []string) string {
 			} else {
 				fullPath := filepath.Join(workDir, p)
 				if tryMarkProcessed(fullPath, processedFiles, &processedMutex) {
-					if content := processFile(fullPath); content != "" {
-						resultCh <- contextEntry{path: fullPath, content: content}
+					if entry, ok := processFile(fullPath, maxTokens); ok {
+						resultCh <- entry
 					}
 				}
 			}
-		}(path)
+		}(path.Path, path.TokenLimit())
 	}
 
 	go func() {
@@ -591,10 +647,24 @@ func processContextPaths(workDir string, paths []string) string {
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].path < entries[j].path
 	})
+	return entries
+}
 
+func joinContextEntries(workDir string, entries []contextEntry) string {
 	contents := make([]string, 0, len(entries))
+	var truncated []string
 	for _, e := range entries {
 		contents = append(contents, e.content)
+		if e.originalTokens > 0 {
+			rel, err := filepath.Rel(workDir, e.path)
+			if err != nil {
+				rel = e.path
+			}
+			truncated = append(truncated, fmt.Sprintf("%s (~%d tokens)", rel, e.originalTokens))
+		}
+	}
+	if len(truncated) > 0 {
+		logging.WarnPersist("Context files truncated to their token limit: " + strings.Join(truncated, ", "))
 	}
 	return strings.Join(contents, "\n")
 }
@@ -618,10 +688,19 @@ func tryMarkProcessed(path string, processed map[string]bool, mu *sync.Mutex) bo
 	return true
 }
 
-func processFile(filePath string) string {
+// processFile reads a context file, truncating it to maxTokens (0 means no
+// limit).
+func processFile(filePath string, maxTokens int) (contextEntry, bool) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return ""
+		return contextEntry{}, false
+	}
+	entry := contextEntry{path: filePath}
+	text, truncated := truncateContext(string(content), maxTokens)
+	if truncated {
+		entry.originalTokens = estimateTokens(string(content))
+		text += truncationNote(filePath, entry.originalTokens, maxTokens)
 	}
-	return "# From:" + filePath + "\n" + string(content)
+	entry.content = "# From:" + filePath + "\n" + text
+	return entry, true
 }
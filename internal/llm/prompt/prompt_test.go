@@ -24,10 +24,7 @@ func TestGetContextFromPaths(t *testing.T) {
 	}
 	cfg := config.Get()
 	cfg.WorkingDir = tmpDir
-	cfg.ContextPaths = []string{
-		"file.txt",
-		"directory/",
-	}
+	cfg.ContextPaths = contextPaths("file.txt", "directory/")
 	testFiles := []string{
 		"file.txt",
 		"directory/file_a.txt",
@@ -52,7 +49,7 @@ func TestProcessContextPaths(t *testing.T) {
 		tmpDir := t.TempDir()
 		createTestFiles(t, tmpDir, []string{"a.txt"})
 
-		result := processContextPaths(tmpDir, []string{"a.txt"})
+		result := processContextPaths(tmpDir, contextPaths("a.txt"))
 		assert.Contains(t, result, "a.txt: test content")
 	})
 
@@ -61,7 +58,7 @@ func TestProcessContextPaths(t *testing.T) {
 		tmpDir := t.TempDir()
 		createTestFiles(t, tmpDir, []string{"docs/one.txt", "docs/two.txt"})
 
-		result := processContextPaths(tmpDir, []string{"docs/"})
+		result := processContextPaths(tmpDir, contextPaths("docs/"))
 		assert.Contains(t, result, "one.txt: test content")
 		assert.Contains(t, result, "two.txt: test content")
 	})
@@ -74,7 +71,7 @@ func TestProcessContextPaths(t *testing.T) {
 		err := os.Symlink(filepath.Join(tmpDir, "real.txt"), filepath.Join(tmpDir, "link.txt"))
 		require.NoError(t, err)
 
-		result := processContextPaths(tmpDir, []string{"real.txt", "link.txt"})
+		result := processContextPaths(tmpDir, contextPaths("real.txt", "link.txt"))
 		count := countOccurrences(result, "real.txt: test content")
 		assert.Equal(t, 1, count, "symlinked file should only appear once")
 	})
@@ -87,7 +84,7 @@ func TestProcessContextPaths(t *testing.T) {
 		err := os.Symlink(filepath.Join(tmpDir, "realdir"), filepath.Join(tmpDir, "linkdir"))
 		require.NoError(t, err)
 
-		result := processContextPaths(tmpDir, []string{"realdir/", "linkdir/"})
+		result := processContextPaths(tmpDir, contextPaths("realdir/", "linkdir/"))
 		count := countOccurrences(result, "file.txt: test content")
 		assert.Equal(t, 1, count, "file in symlinked directory should only appear once")
 	})
@@ -97,7 +94,7 @@ func TestProcessContextPaths(t *testing.T) {
 		tmpDir := t.TempDir()
 		createTestFiles(t, tmpDir, []string{"dup.txt"})
 
-		result := processContextPaths(tmpDir, []string{"dup.txt", "dup.txt"})
+		result := processContextPaths(tmpDir, contextPaths("dup.txt", "dup.txt"))
 		count := countOccurrences(result, "dup.txt: test content")
 		assert.Equal(t, 1, count, "duplicate path should only appear once")
 	})
@@ -107,7 +104,7 @@ func TestProcessContextPaths(t *testing.T) {
 		tmpDir := t.TempDir()
 		createTestFiles(t, tmpDir, []string{"ctx/notes.txt"})
 
-		result := processContextPaths(tmpDir, []string{"ctx/", "ctx/notes.txt"})
+		result := processContextPaths(tmpDir, contextPaths("ctx/", "ctx/notes.txt"))
 		count := countOccurrences(result, "notes.txt: test content")
 		assert.Equal(t, 1, count, "file listed both via directory and explicit path should only appear once")
 	})
@@ -116,7 +113,7 @@ func TestProcessContextPaths(t *testing.T) {
 		t.Parallel()
 		tmpDir := t.TempDir()
 
-		result := processContextPaths(tmpDir, []string{"does-not-exist.txt"})
+		result := processContextPaths(tmpDir, contextPaths("does-not-exist.txt"))
 		assert.Empty(t, result)
 	})
 
@@ -124,7 +121,7 @@ func TestProcessContextPaths(t *testing.T) {
 		t.Parallel()
 		tmpDir := t.TempDir()
 
-		result := processContextPaths(tmpDir, []string{})
+		result := processContextPaths(tmpDir, contextPaths())
 		assert.Empty(t, result)
 	})
 
@@ -138,7 +135,7 @@ func TestProcessContextPaths(t *testing.T) {
 		err = os.Symlink(filepath.Join(tmpDir, "source.txt"), filepath.Join(tmpDir, "dir", "link.txt"))
 		require.NoError(t, err)
 
-		result := processContextPaths(tmpDir, []string{"source.txt", "dir/"})
+		result := processContextPaths(tmpDir, contextPaths("source.txt", "dir/"))
 		count := countOccurrences(result, "source.txt: test content")
 		assert.Equal(t, 1, count, "symlink inside directory should be deduplicated against explicit path")
 	})
@@ -444,3 +441,11 @@ func createTestFiles(t *testing.T, tmpDir string, testFiles []string) {
 		}
 	}
 }
+
+func contextPaths(paths ...string) []config.ContextPath {
+	result := make([]config.ContextPath, 0, len(paths))
+	for _, p := range paths {
+		result = append(result, config.ContextPath{Path: p})
+	}
+	return result
+}
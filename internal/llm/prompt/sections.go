@@ -0,0 +1,51 @@
+package prompt
+
+import (
+	"context"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/memory"
+)
+
+const memorySectionName = "project memory"
+
+// Kinds of PromptSection.
+const (
+	SectionSkill          = "skill"
+	SectionContextFile    = "context_file"
+	SectionContextSnippet = "context_snippet"
+	SectionMemory         = "memory"
+)
+
+// PromptSection is a part of an agent's system prompt that can be left out
+// of a single request: a preloaded skill, the remembered project facts, a
+// project context file or an inline context snippet. Text occurs verbatim in the prompt
+// GetAgentPromptWithOptions returns.
+type PromptSection struct {
+	Kind string
+	// Name is the skill name, "project memory", the context file path
+	// relative to the working directory, or "snippet N".
+	Name string
+	Text string
+}
+
+// PromptSections lists the preloaded skills, project memory and project
+// context of the agent's system prompt, in prompt order.
+func PromptSections(agentName config.AgentName) []PromptSection {
+	reg := agentregistry.GetRegistry()
+	sections := preloadedSkills(agentName, reg)
+	if reg.HasTools(agentName) {
+		if mem := memory.PromptSection(context.Background()); mem != "" {
+			sections = append(sections, PromptSection{Kind: SectionMemory, Name: memorySectionName, Text: mem})
+		}
+	}
+	pc := BuildContext(agentName)
+	for _, f := range pc.Files {
+		sections = append(sections, PromptSection{Kind: SectionContextFile, Name: f.Path, Text: f.text})
+	}
+	for i, s := range pc.Snippets {
+		sections = append(sections, PromptSection{Kind: SectionContextSnippet, Name: snippetName(i), Text: s})
+	}
+	return sections
+}
@@ -0,0 +1,15 @@
+package prompt
+
+import "github.com/opencode-ai/opencode/internal/llm/models"
+
+func TranslatorPrompt(_ models.ModelProvider) string {
+	return `You are a translation engine for a coding assistant's responses.
+
+Translate the text you are given into the requested target language and reply with the translation only — no preamble, notes, or quotes around it.
+
+Rules:
+- Preserve Markdown structure exactly: headings, lists, tables, links, emphasis and line breaks.
+- Tokens of the form [[CODE_N]] stand for code and MUST be copied verbatim, each exactly once, in the position that reads naturally in the target language.
+- Do not translate file paths, identifiers, command names, URLs or error messages quoted from tools.
+- If the text is already in the target language, return it unchanged.`
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -51,6 +52,7 @@ type anthropicOptions struct {
 	shouldThink     func(userMessage string) bool
 	reasoningEffort string
 	taskBudget      int64
+	thinkingBudget  int64
 }
 
 type AnthropicOption func(*anthropicOptions)
@@ -364,6 +366,8 @@ func (a *anthropicClient) finishReason(reason string) message.FinishReason {
 		return message.FinishReasonToolUse
 	case "stop_sequence":
 		return message.FinishReasonEndTurn
+	case "refusal":
+		return message.FinishReasonContentFilter
 	default:
 		return message.FinishReasonUnknown
 	}
@@ -402,7 +406,7 @@ func (a *anthropicClient) preparedMessages(ctx context.Context, messages []anthr
 			if !a.providerOptions.model.SupportsXHighThinking {
 				temperature = anthropic.Float(1)
 			}
-			effort := a.options.reasoningEffort
+			effort := reasoningEffortFromContext(ctx, a.options.reasoningEffort)
 			if effort == "" {
 				effort = "high"
 			}
@@ -421,24 +425,41 @@ func (a *anthropicClient) preparedMessages(ctx context.Context, messages []anthr
 					"task_budget": budget,
 				})
 			}
+		} else if a.options.thinkingBudget > 0 {
+			// A configured budget turns thinking on for every turn; it has
+			// to stay below max_tokens, which may have been lowered to fit
+			// the context window since.
+			thinkingParam = anthropic.ThinkingConfigParamOfEnabled(min(a.options.thinkingBudget, a.providerOptions.maxTokens*4/5))
+			temperature = anthropic.Float(1)
 		} else if messageContent != "" && a.options.shouldThink != nil && a.options.shouldThink(messageContent) {
 			thinkingParam = anthropic.ThinkingConfigParamOfEnabled(int64(float64(a.providerOptions.maxTokens) * 0.8))
 			temperature = anthropic.Float(1)
 		}
 	}
 
-	// TODO: Consider adding ToolChoice in case of agent having output schema set, however it limits tool calls
+	// A forced tool call is how Anthropic models are held to a schema. It is
+	// only requested for the final answer turn since it rules out every
+	// other tool, and the API refuses it together with extended thinking.
+	var toolChoice anthropic.ToolChoiceUnionParam
+	if structuredOutputFromContext(ctx) != nil && slices.ContainsFunc(tools, func(t anthropic.ToolUnionParam) bool {
+		return t.OfTool != nil && t.OfTool.Name == toolsPkg.StructOutputToolName
+	}) {
+		toolChoice = anthropic.ToolChoiceParamOfTool(toolsPkg.StructOutputToolName)
+		thinkingParam = anthropic.ThinkingConfigParamUnion{}
+	}
+
 	return anthropic.MessageNewParams{
 		Model:        anthropic.Model(a.providerOptions.model.APIModel),
 		MaxTokens:    a.providerOptions.maxTokens,
 		Temperature:  temperature,
 		Messages:     messages,
 		Tools:        tools,
+		ToolChoice:   toolChoice,
 		Thinking:     thinkingParam,
 		OutputConfig: outputConfig,
 		System: []anthropic.TextBlockParam{
 			{
-				Text:         a.providerOptions.systemMessage,
+				Text:         systemMessageFromContext(ctx, a.providerOptions.systemMessage),
 				CacheControl: cacheControlParam(a.options.disableCache),
 			},
 		},
@@ -474,7 +495,7 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 				return nil, retryErr
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying transient API error... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				logRetry(attempts, a.providerOptions.retry.limit(), time.Duration(after)*time.Millisecond)
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -501,6 +522,94 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 	}
 }
 
+// sendBatched answers the request through the Message Batches API.
+func (a *anthropicClient) sendBatched(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) (*ProviderResponse, error) {
+	preparedMessages := a.preparedMessages(ctx, a.convertMessages(messages), a.convertTools(tools))
+	a.applyMetadata(ctx, &preparedMessages)
+	raw, err := json.Marshal(preparedMessages)
+	if err != nil {
+		return nil, err
+	}
+	params := param.Override[anthropic.MessageBatchNewParamsRequestParams](json.RawMessage(raw))
+
+	var requestOpts []option.RequestOption
+	if a.options.taskBudget > 0 {
+		requestOpts = append(requestOpts, option.WithHeaderAdd("anthropic-beta", taskBudgetsBeta))
+	}
+	key := batcherKey("anthropic", a.providerOptions)
+	if a.options.taskBudget > 0 {
+		key += "|" + taskBudgetsBeta
+	}
+	b := sharedBatcher(key, a.providerOptions.batch, a.batchAPI(requestOpts))
+	msg, err := b.do(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	for _, block := range msg.Content {
+		if text, ok := block.AsAny().(anthropic.TextBlock); ok {
+			sb.WriteString(text.Text)
+		}
+	}
+	return &ProviderResponse{
+		Content:      sb.String(),
+		ToolCalls:    a.toolCalls(msg),
+		Reasoning:    a.reasoningParts(msg),
+		Usage:        a.usage(msg),
+		FinishReason: a.finishReason(string(msg.StopReason)),
+	}, nil
+}
+
+func (a *anthropicClient) batchAPI(requestOpts []option.RequestOption) batchAPI[anthropic.MessageBatchNewParamsRequestParams, anthropic.Message] {
+	batches := a.client.Messages.Batches
+	return batchAPI[anthropic.MessageBatchNewParamsRequestParams, anthropic.Message]{
+		submit: func(ctx context.Context, requests map[string]anthropic.MessageBatchNewParamsRequestParams) (string, error) {
+			body := anthropic.MessageBatchNewParams{}
+			for id, params := range requests {
+				body.Requests = append(body.Requests, anthropic.MessageBatchNewParamsRequest{CustomID: id, Params: params})
+			}
+			batch, err := batches.New(ctx, body, requestOpts...)
+			if err != nil {
+				return "", err
+			}
+			return batch.ID, nil
+		},
+		ended: func(ctx context.Context, batchID string) (bool, error) {
+			batch, err := batches.Get(ctx, batchID)
+			if err != nil {
+				return false, err
+			}
+			return batch.ProcessingStatus == anthropic.MessageBatchProcessingStatusEnded, nil
+		},
+		results: func(ctx context.Context, batchID string) (map[string]batchResult[anthropic.Message], error) {
+			stream := batches.ResultsStreaming(ctx, batchID)
+			defer stream.Close()
+			results := map[string]batchResult[anthropic.Message]{}
+			for stream.Next() {
+				entry := stream.Current()
+				switch entry.Result.Type {
+				case "succeeded":
+					results[entry.CustomID] = batchResult[anthropic.Message]{value: entry.Result.Message}
+				case "errored":
+					results[entry.CustomID] = batchResult[anthropic.Message]{
+						err: fmt.Errorf("batch request failed: %s", entry.Result.Error.Error.Message),
+					}
+				default:
+					results[entry.CustomID] = batchResult[anthropic.Message]{
+						err: fmt.Errorf("batch request %s", entry.Result.Type),
+					}
+				}
+			}
+			return results, stream.Err()
+		},
+		cancel: func(ctx context.Context, batchID string) error {
+			_, err := batches.Cancel(ctx, batchID)
+			return err
+		},
+	}
+}
+
 func (a *anthropicClient) stream(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) <-chan ProviderEvent {
 	preparedMessages := a.preparedMessages(ctx, a.convertMessages(messages), a.convertTools(tools))
 	a.applyMetadata(ctx, &preparedMessages)
@@ -608,7 +717,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 								ToolCall: &message.ToolCall{
 									ID:       currentToolCallID,
 									Finished: false,
-									Input:    event.Delta.JSON.PartialJSON.Raw(),
+									Input:    event.Delta.PartialJSON,
 								},
 							}
 						}
@@ -650,7 +759,8 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 
 			if errors.Is(streamErr, ErrStreamStalled) {
 				logging.Warn("Anthropic stream stalled, will retry", "attempt", attempts)
-				if attempts < maxRetries {
+				if limit := a.providerOptions.retry.limit(); attempts < limit {
+					eventChan <- retryEvent(attempts, limit, 0, streamErr)
 					continue
 				}
 				eventChan <- ProviderEvent{Type: EventError, Error: streamErr}
@@ -690,8 +800,9 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 			// Retry transient transport errors (e.g. unexpected EOF, connection reset)
 			if isTransientStreamError(err) {
 				logging.Warn("Anthropic stream transport error, will retry", "attempt", attempts, "error", err)
-				if attempts < maxRetries {
-					backoffMs := 2000 * (1 << (attempts - 1))
+				if limit := a.providerOptions.retry.limit(); attempts < limit {
+					backoff := a.providerOptions.retry.backoff(attempts, 2*time.Second)
+					eventChan <- retryEvent(attempts, limit, backoff, err)
 					select {
 					case <-ctx.Done():
 						if ctx.Err() != nil {
@@ -699,7 +810,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 						}
 						close(eventChan)
 						return
-					case <-time.After(time.Duration(backoffMs) * time.Millisecond):
+					case <-time.After(backoff):
 						continue
 					}
 				}
@@ -753,7 +864,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 				return
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying transient API error... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				eventChan <- retryEvent(attempts, a.providerOptions.retry.limit(), time.Duration(after)*time.Millisecond, err)
 				select {
 				case <-ctx.Done():
 					// context cancelled
@@ -816,12 +927,8 @@ func (a *anthropicClient) applyMetadata(ctx context.Context, params *anthropic.M
 // them just amplifies impact during incidents.
 //
 // The retry path uses 2s/4s/8s/… exponential backoff with 20% jitter,
-// capped by maxRetries.
-var retryableHTTPStatuses = map[int]struct{}{
-	429: {},
-	503: {},
-	529: {},
-}
+// capped by maxRetries. A provider's retry config can replace both.
+var retryableHTTPStatuses = []int{429, 503, 529}
 
 func (a *anthropicClient) shouldRetry(attempts int, err error) (bool, int64, error) {
 	var apierr *anthropic.Error
@@ -829,26 +936,20 @@ func (a *anthropicClient) shouldRetry(attempts int, err error) (bool, int64, err
 		return false, 0, err
 	}
 
-	if _, ok := retryableHTTPStatuses[apierr.StatusCode]; !ok {
+	policy := a.providerOptions.retry
+	if !policy.retriesStatus(apierr.StatusCode, retryableHTTPStatuses...) {
 		return false, 0, err
 	}
 
-	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for HTTP %d: %d retries", apierr.StatusCode, maxRetries)
+	if limit := policy.limit(); attempts > limit {
+		return false, 0, fmt.Errorf("maximum retry attempts reached for HTTP %d: %d retries: %w", apierr.StatusCode, limit, err)
 	}
 
-	retryMs := 0
-	retryAfterValues := apierr.Response.Header.Values("Retry-After")
-
-	backoffMs := 2000 * (1 << (attempts - 1))
-	jitterMs := int(float64(backoffMs) * 0.2)
-	retryMs = backoffMs + jitterMs
-	if len(retryAfterValues) > 0 {
-		if _, err := fmt.Sscanf(retryAfterValues[0], "%d", &retryMs); err == nil {
-			retryMs = retryMs * 1000
-		}
+	var header http.Header
+	if apierr.Response != nil {
+		header = apierr.Response.Header
 	}
-	return true, int64(retryMs), nil
+	return true, policy.delay(attempts, 2*time.Second, header).Milliseconds(), nil
 }
 
 // shouldReplayReasoning gates thinking-block replay to messages produced by
@@ -964,6 +1065,14 @@ func WithAnthropicReasoningEffort(effort string) AnthropicOption {
 	}
 }
 
+// WithAnthropicThinkingBudget enables extended thinking on every turn with
+// budget as budget_tokens, instead of only when the prompt asks for it.
+func WithAnthropicThinkingBudget(budget int64) AnthropicOption {
+	return func(options *anthropicOptions) {
+		options.thinkingBudget = budget
+	}
+}
+
 func WithAnthropicTaskBudget(budget int64) AnthropicOption {
 	return func(options *anthropicOptions) {
 		options.taskBudget = budget
@@ -253,3 +253,31 @@ func TestReasoningPartsEmptyTextStillReplayed(t *testing.T) {
 		t.Fatalf("empty-text signed block not replayed: %+v", blocks)
 	}
 }
+
+func TestPreparedMessagesThinkingBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int64
+		want      int64
+	}{
+		{name: "budget as configured", maxTokens: 8000, want: 2048},
+		{name: "kept below max tokens", maxTokens: 2000, want: 1600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newReasoningTestClient(t)
+			a.providerOptions.model = models.SupportedModels[models.Claude45Opus]
+			a.providerOptions.maxTokens = tt.maxTokens
+			WithAnthropicThinkingBudget(2048)(&a.options)
+			msgs := []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("hello"))}
+
+			params := a.preparedMessages(context.Background(), msgs, nil)
+			if params.Thinking.OfEnabled == nil {
+				t.Fatalf("thinking not enabled: %+v", params.Thinking)
+			}
+			if got := params.Thinking.OfEnabled.BudgetTokens; got != tt.want {
+				t.Fatalf("budget_tokens = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// slowConsumerAfter is how long the consumer of a stream may leave
+// events pending before bufferStream logs it as stalled.
+const slowConsumerAfter = 10 * time.Second
+
+// stallCheckInterval is how often bufferStream's watchdog looks for a
+// stalled consumer.
+const stallCheckInterval = 2 * time.Second
+
+// bufferStream decouples a provider client's event stream from its
+// consumer. The provider clients send on unbuffered channels, so a
+// consumer blocked on a slow database write or a busy TUI used to stop
+// the client from reading the HTTP stream at all; long enough, and the
+// stream inactivity timeout or the server gave up on the turn.
+//
+// Events are read from upstream as fast as they arrive and queued.
+// While the consumer is behind, consecutive content, thinking and
+// tool-input deltas are merged into the queued event instead of
+// growing the queue, so the consumer catches up with fewer, larger
+// updates and the queue stays bounded by the number of distinct events
+// in the turn. Order is preserved and nothing is dropped.
+//
+// A watchdog logs when the consumer has left events pending for
+// slowConsumerAfter, and again when it recovers. When ctx is done the
+// remaining events are abandoned and upstream is drained so the client
+// goroutine can exit.
+func bufferStream(ctx context.Context, upstream <-chan ProviderEvent) <-chan ProviderEvent {
+	out := make(chan ProviderEvent)
+
+	go func() {
+		defer close(out)
+
+		var queue []ProviderEvent
+		in := upstream
+		lastSent := time.Now()
+		stalled := false
+		coalesced := 0
+		watchdog := time.NewTicker(stallCheckInterval)
+		defer watchdog.Stop()
+
+		for in != nil || len(queue) > 0 {
+			var send chan<- ProviderEvent
+			var next ProviderEvent
+			if len(queue) > 0 {
+				send = out
+				next = queue[0]
+			}
+
+			select {
+			case event, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				if n := len(queue); n > 0 && coalesce(&queue[n-1], event) {
+					coalesced++
+					continue
+				}
+				queue = append(queue, event)
+			case send <- next:
+				queue[0] = ProviderEvent{}
+				queue = queue[1:]
+				lastSent = time.Now()
+				if stalled {
+					logging.Info("Provider stream consumer caught up",
+						"coalesced_deltas", coalesced)
+					stalled = false
+				}
+			case <-watchdog.C:
+				if !stalled && len(queue) > 0 && time.Since(lastSent) > slowConsumerAfter {
+					logging.Warn("Provider stream consumer is slow; coalescing deltas until it catches up",
+						"pending", len(queue), "stalled_for", time.Since(lastSent).Round(time.Second))
+					stalled = true
+				}
+			case <-ctx.Done():
+				if in != nil {
+					go func(in <-chan ProviderEvent) {
+						for range in {
+						}
+					}(in)
+				}
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// coalesce merges event into the queued event dst when both are deltas
+// of the same kind (and, for tool input, of the same call). Reports
+// whether event was absorbed.
+func coalesce(dst *ProviderEvent, event ProviderEvent) bool {
+	if dst.Type != event.Type {
+		return false
+	}
+	switch event.Type {
+	case EventContentDelta:
+		dst.Content += event.Content
+		return true
+	case EventThinkingDelta:
+		dst.Thinking += event.Thinking
+		return true
+	case EventToolUseDelta:
+		if dst.ToolCall == nil || event.ToolCall == nil || dst.ToolCall.ID != event.ToolCall.ID {
+			return false
+		}
+		// Copy before appending: the client may still hold the pointer.
+		merged := *dst.ToolCall
+		merged.Input += event.ToolCall.Input
+		dst.ToolCall = &merged
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func TestBufferStreamDoesNotBlockProducer(t *testing.T) {
+	upstream := make(chan ProviderEvent)
+	out := bufferStream(context.Background(), upstream)
+
+	// Nobody reads out yet: the producer must still be able to send
+	// everything and close.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		upstream <- ProviderEvent{Type: EventContentStart}
+		for _, s := range []string{"a", "b", "c"} {
+			upstream <- ProviderEvent{Type: EventContentDelta, Content: s}
+		}
+		upstream <- ProviderEvent{Type: EventThinkingDelta, Thinking: "x"}
+		upstream <- ProviderEvent{Type: EventThinkingDelta, Thinking: "y"}
+		upstream <- ProviderEvent{Type: EventToolUseDelta, ToolCall: &message.ToolCall{ID: "1", Input: `{"a":`}}
+		upstream <- ProviderEvent{Type: EventToolUseDelta, ToolCall: &message.ToolCall{ID: "1", Input: `1}`}}
+		upstream <- ProviderEvent{Type: EventToolUseDelta, ToolCall: &message.ToolCall{ID: "2", Input: `{}`}}
+		upstream <- ProviderEvent{Type: EventContentDelta, Content: "d"}
+		upstream <- ProviderEvent{Type: EventComplete}
+		close(upstream)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("producer blocked on a consumer that isn't reading")
+	}
+
+	var got []ProviderEvent
+	for ev := range out {
+		got = append(got, ev)
+	}
+	want := []struct {
+		typ   EventType
+		value string
+	}{
+		{EventContentStart, ""},
+		{EventContentDelta, "abc"},
+		{EventThinkingDelta, "xy"},
+		{EventToolUseDelta, `{"a":1}`},
+		{EventToolUseDelta, `{}`},
+		{EventContentDelta, "d"},
+		{EventComplete, ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		ev := got[i]
+		var value string
+		switch ev.Type {
+		case EventContentDelta:
+			value = ev.Content
+		case EventThinkingDelta:
+			value = ev.Thinking
+		case EventToolUseDelta:
+			value = ev.ToolCall.Input
+		}
+		if ev.Type != w.typ || value != w.value {
+			t.Errorf("event %d = %s %q, want %s %q", i, ev.Type, value, w.typ, w.value)
+		}
+	}
+}
+
+func TestBufferStreamDrainsUpstreamOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	upstream := make(chan ProviderEvent)
+	out := bufferStream(ctx, upstream)
+	cancel()
+
+	// out closes without being read to the end...
+	select {
+	case <-waitClosed(out):
+	case <-time.After(2 * time.Second):
+		t.Fatal("output channel not closed after cancel")
+	}
+	// ...and the producer can still finish.
+	sent := make(chan struct{})
+	go func() {
+		upstream <- ProviderEvent{Type: EventContentDelta, Content: "late"}
+		close(upstream)
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("producer blocked after cancel")
+	}
+}
+
+func waitClosed(ch <-chan ProviderEvent) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/config"
+	toolsPkg "github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+const (
+	defaultBatchWindow       = 2 * time.Second
+	defaultBatchPollInterval = 30 * time.Second
+	defaultBatchTimeout      = 24 * time.Hour
+
+	// batchDiscount is the share of the list price batch requests cost
+	// with both anthropic and openai.
+	batchDiscount = 0.5
+)
+
+// BatchPolicy routes the requests of offline runs through the provider's
+// batch API: they are collected for Window, submitted as one
+// batch and answered once the batch ends. Batches cost about half as much
+// but may take hours, so only requests made with BatchContext use them.
+type BatchPolicy struct {
+	Enabled      bool
+	Window       time.Duration
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// BatchPolicyFromConfig converts a provider's batch config. Durations that
+// don't parse keep their defaults; config validation reports them.
+func BatchPolicyFromConfig(cfg *config.BatchConfig) BatchPolicy {
+	if cfg == nil || !cfg.Enabled {
+		return BatchPolicy{}
+	}
+	parse := func(s string, def time.Duration) time.Duration {
+		if d, err := config.ParseDurationExtended(s); err == nil && d > 0 {
+			return d
+		}
+		return def
+	}
+	return BatchPolicy{
+		Enabled:      true,
+		Window:       parse(cfg.Window, defaultBatchWindow),
+		PollInterval: parse(cfg.PollInterval, defaultBatchPollInterval),
+		Timeout:      parse(cfg.Timeout, defaultBatchTimeout),
+	}
+}
+
+func WithBatchPolicy(policy BatchPolicy) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.batch = policy
+	}
+}
+
+// batchClient is implemented by the clients whose provider has a batch
+// API.
+type batchClient interface {
+	sendBatched(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) (*ProviderResponse, error)
+}
+
+type batchKeyType struct{}
+
+var batchKey = batchKeyType{}
+
+// BatchContext returns a context whose requests may go through the batch
+// API of providers with batch mode enabled. Offline runs use it; requests
+// made with any other context stream.
+func BatchContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchKey, true)
+}
+
+// useBatch reports whether a request made with ctx goes through the batch
+// API.
+func (p BatchPolicy) useBatch(ctx context.Context) bool {
+	batch, _ := ctx.Value(batchKey).(bool)
+	return p.Enabled && batch
+}
+
+// batchStream answers a request through the batch API with the single
+// EventComplete a stream would end with.
+func batchStream(ctx context.Context, client batchClient, messages []message.Message, tools []toolsPkg.BaseTool) <-chan ProviderEvent {
+	events := make(chan ProviderEvent, 1)
+	go func() {
+		defer close(events)
+		resp, err := client.sendBatched(ctx, messages, tools)
+		if err != nil {
+			events <- ProviderEvent{Type: EventError, Error: err}
+			return
+		}
+		resp.Usage.Batched = true
+		events <- ProviderEvent{Type: EventComplete, Response: resp}
+	}()
+	return events
+}
+
+// batchResult is the answer to one request of a batch.
+type batchResult[R any] struct {
+	value R
+	err   error
+}
+
+// batchAPI is how a batcher talks to one provider's batch endpoints.
+type batchAPI[P, R any] struct {
+	// submit creates a batch from requests keyed by custom ID and returns
+	// its ID.
+	submit func(ctx context.Context, requests map[string]P) (string, error)
+	// ended reports whether the batch has finished processing.
+	ended func(ctx context.Context, batchID string) (bool, error)
+	// results returns the answers of an ended batch by custom ID.
+	results func(ctx context.Context, batchID string) (map[string]batchResult[R], error)
+	// cancel asks the provider to stop a batch that timed out.
+	cancel func(ctx context.Context, batchID string) error
+}
+
+type batchItem[P, R any] struct {
+	id     string
+	params P
+	done   chan batchResult[R]
+}
+
+// batcher collects the requests made within its policy's window into one
+// batch. Requests from every agent sharing the provider credentials land
+// in the same batch, so a flow fanned out over many repositories is
+// submitted as a few large batches.
+type batcher[P, R any] struct {
+	policy BatchPolicy
+	api    batchAPI[P, R]
+
+	mu      sync.Mutex
+	pending []*batchItem[P, R]
+}
+
+// do queues params for the next batch and waits for its answer. A caller
+// whose context ends stops waiting, but the batch it joined still runs.
+func (b *batcher[P, R]) do(ctx context.Context, params P) (R, error) {
+	item := &batchItem[P, R]{id: uuid.NewString(), params: params, done: make(chan batchResult[R], 1)}
+	b.mu.Lock()
+	b.pending = append(b.pending, item)
+	if len(b.pending) == 1 {
+		time.AfterFunc(b.policy.Window, b.flush)
+	}
+	b.mu.Unlock()
+
+	select {
+	case res := <-item.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+// flush submits the pending requests and answers them once the batch
+// ends.
+func (b *batcher[P, R]) flush() {
+	b.mu.Lock()
+	items := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(items) == 0 {
+		return
+	}
+	defer logging.RecoverPanic("provider.batcher.flush", func() {
+		b.fail(items, errors.New("batch processing panicked"))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.policy.Timeout)
+	defer cancel()
+
+	requests := make(map[string]P, len(items))
+	for _, item := range items {
+		requests[item.id] = item.params
+	}
+	batchID, err := b.api.submit(ctx, requests)
+	if err != nil {
+		b.fail(items, fmt.Errorf("submitting batch: %w", err))
+		return
+	}
+	logging.Info("Submitted request batch", "batch_id", batchID, "requests", len(items))
+
+	ticker := time.NewTicker(b.policy.PollInterval)
+	defer ticker.Stop()
+	for {
+		ended, err := b.api.ended(ctx, batchID)
+		if err != nil {
+			logging.Warn("Polling request batch failed", "batch_id", batchID, "error", err)
+		}
+		if ended {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if b.api.cancel != nil {
+				_ = b.api.cancel(context.Background(), batchID)
+			}
+			b.fail(items, fmt.Errorf("batch %s did not end within %s", batchID, b.policy.Timeout))
+			return
+		}
+	}
+
+	results, err := b.api.results(ctx, batchID)
+	if err != nil {
+		b.fail(items, fmt.Errorf("reading results of batch %s: %w", batchID, err))
+		return
+	}
+	for _, item := range items {
+		res, ok := results[item.id]
+		if !ok {
+			res.err = fmt.Errorf("batch %s has no result for request %s", batchID, item.id)
+		}
+		item.done <- res
+	}
+}
+
+func (b *batcher[P, R]) fail(items []*batchItem[P, R], err error) {
+	for _, item := range items {
+		select {
+		case item.done <- batchResult[R]{err: err}:
+		default:
+		}
+	}
+}
+
+var (
+	batchersMu sync.Mutex
+	batchers   = map[string]any{}
+)
+
+// sharedBatcher returns the batcher for key, creating it with policy and
+// api on first use. Keys identify the provider endpoint and credentials.
+func sharedBatcher[P, R any](key string, policy BatchPolicy, api batchAPI[P, R]) *batcher[P, R] {
+	batchersMu.Lock()
+	defer batchersMu.Unlock()
+	if b, ok := batchers[key].(*batcher[P, R]); ok {
+		return b
+	}
+	b := &batcher[P, R]{policy: policy, api: api}
+	batchers[key] = b
+	return b
+}
+
+// batcherKey identifies the endpoint and credentials of a client, so only
+// requests that could have been sent together share a batch.
+func batcherKey(kind string, opts providerClientOptions) string {
+	sum := sha256.Sum256([]byte(opts.apiKey))
+	return kind + "|" + opts.baseURL + "|" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchPolicyFromConfig(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, BatchPolicy{}, BatchPolicyFromConfig(nil))
+	assert.Equal(t, BatchPolicy{}, BatchPolicyFromConfig(&config.BatchConfig{Window: "5s"}))
+
+	p := BatchPolicyFromConfig(&config.BatchConfig{Enabled: true, PollInterval: "1m", Timeout: "2d"})
+	assert.Equal(t, BatchPolicy{Enabled: true, Window: defaultBatchWindow, PollInterval: time.Minute, Timeout: 48 * time.Hour}, p)
+
+	ctx := context.Background()
+	assert.False(t, p.useBatch(ctx), "interactive requests stream")
+	assert.True(t, p.useBatch(BatchContext(ctx)))
+	assert.False(t, BatchPolicy{}.useBatch(BatchContext(ctx)))
+}
+
+func TestBatcherCollectsRequestsIntoOneBatch(t *testing.T) {
+	t.Parallel()
+	var submits atomic.Int32
+	var polls atomic.Int32
+	var mu sync.Mutex
+	submitted := map[string]string{}
+	b := &batcher[string, string]{
+		policy: BatchPolicy{Enabled: true, Window: 20 * time.Millisecond, PollInterval: time.Millisecond, Timeout: time.Second},
+		api: batchAPI[string, string]{
+			submit: func(_ context.Context, requests map[string]string) (string, error) {
+				submits.Add(1)
+				mu.Lock()
+				defer mu.Unlock()
+				for id, p := range requests {
+					submitted[id] = p
+				}
+				return "batch-1", nil
+			},
+			ended: func(context.Context, string) (bool, error) {
+				return polls.Add(1) >= 3, nil
+			},
+			results: func(context.Context, string) (map[string]batchResult[string], error) {
+				mu.Lock()
+				defer mu.Unlock()
+				out := map[string]batchResult[string]{}
+				for id, p := range submitted {
+					out[id] = batchResult[string]{value: strings.ToUpper(p)}
+				}
+				return out, nil
+			},
+		},
+	}
+
+	var wg sync.WaitGroup
+	answers := make([]string, 3)
+	for i, prompt := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answer, err := b.do(context.Background(), prompt)
+			assert.NoError(t, err)
+			answers[i] = answer
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"A", "B", "C"}, answers)
+	assert.EqualValues(t, 1, submits.Load())
+}
+
+func TestBatcherTimeoutCancelsBatch(t *testing.T) {
+	t.Parallel()
+	var cancelled atomic.Bool
+	b := &batcher[string, string]{
+		policy: BatchPolicy{Enabled: true, Window: time.Millisecond, PollInterval: time.Millisecond, Timeout: 30 * time.Millisecond},
+		api: batchAPI[string, string]{
+			submit: func(context.Context, map[string]string) (string, error) { return "batch-1", nil },
+			ended:  func(context.Context, string) (bool, error) { return false, nil },
+			cancel: func(context.Context, string) error {
+				cancelled.Store(true)
+				return nil
+			},
+		},
+	}
+	_, err := b.do(context.Background(), "a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not end")
+	assert.True(t, cancelled.Load())
+}
+
+func TestOpenAIBatchLineResult(t *testing.T) {
+	t.Parallel()
+	var ok openaiBatchLine
+	require.NoError(t, json.Unmarshal([]byte(`{"custom_id":"r1","response":{"status_code":200,"body":{"id":"c1","choices":[{"message":{"content":"hi"}}]}}}`), &ok))
+	res := ok.result()
+	require.NoError(t, res.err)
+	assert.Equal(t, "hi", res.value.Choices[0].Message.Content)
+
+	var failed openaiBatchLine
+	require.NoError(t, json.Unmarshal([]byte(`{"custom_id":"r2","error":{"code":"invalid","message":"bad request"}}`), &failed))
+	assert.ErrorContains(t, failed.result().err, "bad request")
+}
+
+func TestCalculateCostBatchDiscount(t *testing.T) {
+	t.Parallel()
+	model := models.Model{CostPer1MIn: 10, CostPer1MOut: 20}
+	in, out := CalculateCost(model, TokenUsage{InputTokens: 1_000_000, OutputTokens: 1_000_000, Batched: true})
+	assert.InDelta(t, 5, in, 1e-9)
+	assert.InDelta(t, 10, out, 1e-9)
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"maps"
+	"net/http"
+	"net/url"
+
+	"github.com/openai/openai-go/option"
+	"github.com/opencode-ai/opencode/internal/copilot"
+)
+
+// newCopilotClient builds an OpenAI client for the Copilot chat API. The
+// configured API key is the GitHub OAuth token from `opencode auth copilot`;
+// each request carries a Copilot token exchanged from it and goes to the
+// endpoint that token was issued for.
+func newCopilotClient(opts providerClientOptions) OpenAIClient {
+	tokens := copilot.NewTokenSource(opts.apiKey)
+	copilot.RefreshStoredModels(tokens)
+
+	headers := copilot.Headers()
+	maps.Copy(headers, opts.headers)
+	opts.headers = headers
+	if opts.baseURL == "" {
+		opts.baseURL = copilot.DefaultAPIEndpoint
+	}
+	pinned := opts.baseURL != copilot.DefaultAPIEndpoint
+	opts.apiKey = "copilot"
+	opts.openaiOptions = append(opts.openaiOptions, withOpenAIMiddleware(
+		func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			token, err := tokens.Token(req.Context())
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token.Value)
+			if !pinned {
+				if endpoint, err := url.Parse(token.Endpoint); err == nil && endpoint.Host != "" {
+					req.URL.Scheme = endpoint.Scheme
+					req.URL.Host = endpoint.Host
+					req.Host = endpoint.Host
+				}
+			}
+			return next(req)
+		}))
+	return newOpenAIClient(opts)
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/opencode-ai/opencode/internal/llm/models"
@@ -17,6 +19,7 @@ type fakeCountClient struct {
 	tokens int64
 	err    error
 	max    int64
+	calls  int
 }
 
 func (f *fakeCountClient) send(context.Context, []message.Message, []toolsPkg.BaseTool) (*ProviderResponse, error) {
@@ -26,6 +29,7 @@ func (f *fakeCountClient) stream(context.Context, []message.Message, []toolsPkg.
 	return nil
 }
 func (f *fakeCountClient) countTokens(context.Context, []message.Message, []toolsPkg.BaseTool) (int64, error) {
+	f.calls++
 	return f.tokens, f.err
 }
 func (f *fakeCountClient) maxTokens() int64     { return f.max }
@@ -154,3 +158,70 @@ func TestCountTokens_NoContextWindowNeverHits(t *testing.T) {
 		t.Errorf("expected hit=false when ContextWindow<=0")
 	}
 }
+
+func textMessage(id string, chars int) message.Message {
+	return message.Message{
+		ID:    id,
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: strings.Repeat("x", chars)}},
+	}
+}
+
+func TestCountTokens_CachesMeasuredMessages(t *testing.T) {
+	var history []message.Message
+	for i := range 10 {
+		history = append(history, textMessage(fmt.Sprintf("m%d", i), 4000))
+	}
+	// Each message is estimated locally at (4000+100)/4 = 1025 tokens; the
+	// endpoint reports about twice that.
+	client := &fakeCountClient{tokens: 20_000}
+	p := newCountProvider(client, 1_000_000, "")
+
+	tokens, _ := p.CountTokens(context.Background(), 0.95, history, nil)
+	if tokens != 20_000 || client.calls != 1 {
+		t.Fatalf("first count = %d after %d calls, want the endpoint value from one call", tokens, client.calls)
+	}
+
+	// A small new message is estimated locally, scaled by the measured
+	// endpoint/local ratio.
+	history = append(history, textMessage("m10", 400))
+	tokens, _ = p.CountTokens(context.Background(), 0.95, history, nil)
+	if client.calls != 1 {
+		t.Fatalf("small delta should be served from the cache, endpoint called %d times", client.calls)
+	}
+	if want := int64(20_000 + 125*20_000/10_250); tokens != want {
+		t.Errorf("cached count = %d, want %d", tokens, want)
+	}
+
+	// Editing a cached message or adding a large one forces a new count.
+	history[0] = textMessage("m0", 40_000)
+	client.tokens = 100_000
+	tokens, _ = p.CountTokens(context.Background(), 0.95, history, nil)
+	if tokens != 100_000 || client.calls != 2 {
+		t.Fatalf("changed history: count = %d after %d calls, want a fresh endpoint count", tokens, client.calls)
+	}
+	if tokens, _ = p.CountTokens(context.Background(), 0.95, history, nil); tokens != 100_000 || client.calls != 2 {
+		t.Errorf("unchanged history: count = %d after %d calls, want the cached total", tokens, client.calls)
+	}
+}
+
+func TestCountTokens_RefreshesNearThreshold(t *testing.T) {
+	history := []message.Message{textMessage("m0", 4000)}
+	client := &fakeCountClient{tokens: 2_000}
+	// The threshold (95k) is far away: the second call is cached.
+	p := newCountProvider(client, 100_000, "")
+	p.CountTokens(context.Background(), 0.95, history, nil)
+	p.CountTokens(context.Background(), 0.95, history, nil)
+	if client.calls != 1 {
+		t.Fatalf("endpoint called %d times, want 1", client.calls)
+	}
+
+	// Within 5% of the context window of the threshold every call measures.
+	client = &fakeCountClient{tokens: 93_000}
+	p = newCountProvider(client, 100_000, "")
+	p.CountTokens(context.Background(), 0.95, history, nil)
+	p.CountTokens(context.Background(), 0.95, history, nil)
+	if client.calls != 2 {
+		t.Fatalf("endpoint called %d times near the threshold, want 2", client.calls)
+	}
+}
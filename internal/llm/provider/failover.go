@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+)
+
+// failoverStatuses are the HTTP statuses that say the provider cannot serve
+// the request for now: payment required, rate limited past the retry
+// budget, unavailable, or overloaded (Anthropic's 529).
+var failoverStatuses = []int{402, 429, 503, 529}
+
+// IsFailoverError reports whether err means the provider is out of quota or
+// overloaded, so the same request may still succeed on a different
+// provider. Errors caused by the request itself (bad input, context too
+// long, auth) are not failover errors: another model would likely reject
+// them too, or the user has to fix their config.
+func IsFailoverError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return slices.Contains(failoverStatuses, anthropicErr.StatusCode)
+	}
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return openaiErr.Code == "insufficient_quota" || slices.Contains(failoverStatuses, openaiErr.StatusCode)
+	}
+	var geminiErr genai.APIError
+	if errors.As(err, &geminiErr) {
+		return slices.Contains(failoverStatuses, geminiErr.Code)
+	}
+	var ollamaErr *ollamaStatusError
+	if errors.As(err, &ollamaErr) {
+		return slices.Contains(failoverStatuses, ollamaErr.StatusCode)
+	}
+	// Mid-stream errors arrive as SDK-specific event payloads or strings.
+	return contains(err.Error(),
+		"overloaded",
+		"insufficient_quota",
+		"quota exceeded",
+		"resource_exhausted",
+		"ThrottlingException",
+		"ServiceUnavailableException",
+	)
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genai"
+)
+
+func TestIsFailoverError(t *testing.T) {
+	t.Parallel()
+	for name, tc := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"anthropic overloaded":      {newAPIErrWithStatus(529, nil), true},
+		"anthropic bad request":     {newAPIErrWithStatus(http.StatusBadRequest, nil), false},
+		"retries exhausted":         {fmt.Errorf("maximum retry attempts reached for HTTP 429: 8 retries: %w", newAPIErrWithStatus(429, nil)), true},
+		"openai insufficient quota": {&openai.Error{Code: "insufficient_quota", StatusCode: http.StatusBadRequest}, true},
+		"openai unauthorized":       {&openai.Error{StatusCode: http.StatusUnauthorized}, false},
+		"gemini exhausted":          {genai.APIError{Code: 429}, true},
+		"ollama busy":               {&ollamaStatusError{StatusCode: 503}, true},
+		"stream overloaded":         {errors.New(`{"type":"overloaded_error","message":"Overloaded"}`), true},
+		"bedrock throttling":        {errors.New("received exception ThrottlingException: slow down"), true},
+		"canceled":                  {context.Canceled, false},
+		"nil":                       {nil, false},
+	} {
+		assert.Equal(t, tc.want, IsFailoverError(tc.err), name)
+	}
+}
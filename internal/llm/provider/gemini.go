@@ -19,7 +19,8 @@ import (
 )
 
 type geminiOptions struct {
-	disableCache bool
+	disableCache   bool
+	thinkingBudget int64
 }
 
 type GeminiOption func(*geminiOptions)
@@ -181,16 +182,32 @@ func (g *geminiClient) convertTools(tools []tools.BaseTool) []*genai.Tool {
 }
 
 func (g *geminiClient) finishReason(reason genai.FinishReason) message.FinishReason {
-	switch {
-	case reason == genai.FinishReasonStop:
+	switch reason {
+	case genai.FinishReasonStop:
 		return message.FinishReasonEndTurn
-	case reason == genai.FinishReasonMaxTokens:
+	case genai.FinishReasonMaxTokens:
 		return message.FinishReasonMaxTokens
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation, genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent, genai.FinishReasonSPII, genai.FinishReasonImageSafety,
+		genai.FinishReasonImageProhibitedContent, genai.FinishReasonImageRecitation:
+		return message.FinishReasonContentFilter
 	default:
 		return message.FinishReasonUnknown
 	}
 }
 
+// responseFinishReason is the finish reason of resp. A prompt Gemini
+// blocks outright comes back without candidates, only a block reason.
+func (g *geminiClient) responseFinishReason(resp *genai.GenerateContentResponse) message.FinishReason {
+	if len(resp.Candidates) > 0 {
+		return g.finishReason(resp.Candidates[0].FinishReason)
+	}
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return message.FinishReasonContentFilter
+	}
+	return message.FinishReasonEndTurn
+}
+
 func (g *geminiClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
 	// Convert messages
 	geminiMessages := g.convertMessages(messages)
@@ -206,7 +223,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 	config := &genai.GenerateContentConfig{
 		MaxOutputTokens: int32(g.providerOptions.maxTokens),
 		SystemInstruction: &genai.Content{
-			Parts: []*genai.Part{{Text: g.providerOptions.systemMessage}},
+			Parts: []*genai.Part{{Text: systemMessageFromContext(ctx, g.providerOptions.systemMessage)}},
 		},
 	}
 	if len(g.providerOptions.headers) != 0 {
@@ -215,9 +232,8 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 		}
 	}
 	g.applyMetadata(ctx, config)
-	if len(tools) > 0 {
-		config.Tools = g.convertTools(tools)
-	}
+	g.applyTools(ctx, config, tools)
+	g.applyThinking(config)
 	chat, _ := g.client.Chats.Create(ctx, g.providerOptions.model.APIModel, config, history)
 
 	attempts := 0
@@ -237,7 +253,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 				return nil, retryErr
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				logRetry(attempts, g.providerOptions.retry.limit(), time.Duration(after)*time.Millisecond)
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -249,9 +265,14 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 		}
 
 		content := ""
+		var images []message.BinaryContent
 
 		if len(resp.Candidates) > 0 && resp.Candidates[0].Content != nil {
 			for _, part := range resp.Candidates[0].Content.Parts {
+				if image, ok := inlineImage(part); ok {
+					images = append(images, image)
+					continue
+				}
 				switch {
 				case part.Text != "":
 					content = string(part.Text)
@@ -273,10 +294,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 				}
 			}
 		}
-		finishReason := message.FinishReasonEndTurn
-		if len(resp.Candidates) > 0 {
-			finishReason = g.finishReason(resp.Candidates[0].FinishReason)
-		}
+		finishReason := g.responseFinishReason(resp)
 		if len(toolCalls) > 0 {
 			finishReason = message.FinishReasonToolUse
 		}
@@ -284,6 +302,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 		return &ProviderResponse{
 			Content:      content,
 			ToolCalls:    toolCalls,
+			Images:       images,
 			Usage:        g.usage(resp),
 			FinishReason: finishReason,
 		}, nil
@@ -305,7 +324,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 	config := &genai.GenerateContentConfig{
 		MaxOutputTokens: int32(g.providerOptions.maxTokens),
 		SystemInstruction: &genai.Content{
-			Parts: []*genai.Part{{Text: g.providerOptions.systemMessage}},
+			Parts: []*genai.Part{{Text: systemMessageFromContext(ctx, g.providerOptions.systemMessage)}},
 		},
 	}
 	if len(g.providerOptions.headers) != 0 {
@@ -314,9 +333,8 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 		}
 	}
 	g.applyMetadata(ctx, config)
-	if len(tools) > 0 {
-		config.Tools = g.convertTools(tools)
-	}
+	g.applyTools(ctx, config, tools)
+	g.applyThinking(config)
 	chat, err := g.client.Chats.Create(ctx, g.providerOptions.model.APIModel, config, history)
 	if err != nil {
 		eventChan := make(chan ProviderEvent)
@@ -342,6 +360,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 
 			currentContent := ""
 			toolCalls := []message.ToolCall{}
+			var images []message.BinaryContent
 			var finalResp *genai.GenerateContentResponse
 
 			eventChan <- ProviderEvent{Type: EventContentStart}
@@ -397,15 +416,16 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 					if isTransientStreamError(item.err) {
 						logging.Warn("Gemini stream transport error, will retry", "attempt", attempts, "error", item.err)
 						reader.Close()
-						if attempts < maxRetries {
-							backoffMs := 2000 * (1 << (attempts - 1))
+						if limit := g.providerOptions.retry.limit(); attempts < limit {
+							backoff := g.providerOptions.retry.backoff(attempts, 2*time.Second)
+							eventChan <- retryEvent(attempts, limit, backoff, item.err)
 							select {
 							case <-ctx.Done():
 								if ctx.Err() != nil {
 									eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
 								}
 								return
-							case <-time.After(time.Duration(backoffMs) * time.Millisecond):
+							case <-time.After(backoff):
 								continue retryLoop
 							}
 						}
@@ -420,7 +440,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 						return
 					}
 					if retry {
-						logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+						eventChan <- retryEvent(attempts, g.providerOptions.retry.limit(), time.Duration(after)*time.Millisecond, item.err)
 						select {
 						case <-ctx.Done():
 							if ctx.Err() != nil {
@@ -442,6 +462,10 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 
 				if item.resp != nil && len(item.resp.Candidates) > 0 && item.resp.Candidates[0].Content != nil {
 					for _, part := range item.resp.Candidates[0].Content.Parts {
+						if image, ok := inlineImage(part); ok {
+							images = append(images, image)
+							continue
+						}
 						switch {
 						case part.Text != "":
 							delta := string(part.Text)
@@ -486,7 +510,8 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 
 			if errors.Is(streamErr, ErrStreamStalled) {
 				logging.Warn("Gemini stream stalled, will retry", "attempt", attempts)
-				if attempts < maxRetries {
+				if limit := g.providerOptions.retry.limit(); attempts < limit {
+					eventChan <- retryEvent(attempts, limit, 0, streamErr)
 					continue
 				}
 				eventChan <- ProviderEvent{Type: EventError, Error: ErrStreamStalled}
@@ -497,10 +522,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 
 			if finalResp != nil {
 
-				finishReason := message.FinishReasonEndTurn
-				if len(finalResp.Candidates) > 0 {
-					finishReason = g.finishReason(finalResp.Candidates[0].FinishReason)
-				}
+				finishReason := g.responseFinishReason(finalResp)
 				if len(toolCalls) > 0 {
 					finishReason = message.FinishReasonToolUse
 				}
@@ -509,6 +531,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 					Response: &ProviderResponse{
 						Content:      currentContent,
 						ToolCalls:    toolCalls,
+						Images:       images,
 						Usage:        g.usage(finalResp),
 						FinishReason: finishReason,
 					},
@@ -527,6 +550,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 				Response: &ProviderResponse{
 					Content:      currentContent,
 					ToolCalls:    toolCalls,
+					Images:       images,
 					FinishReason: finishReason,
 				},
 			}
@@ -538,6 +562,34 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 	return eventChan
 }
 
+// applyTools declares tools on the request, or, when the turn must answer
+// with structured output, asks for JSON matching the schema instead: Gemini
+// models before 3 reject a JSON response type while functions are declared.
+func (g *geminiClient) applyTools(ctx context.Context, config *genai.GenerateContentConfig, tools []tools.BaseTool) {
+	if schema := structuredOutputFromContext(ctx); schema != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseJsonSchema = schema
+		return
+	}
+	if g.providerOptions.model.SupportsImageOutput {
+		// Image models answer with text and images and take no function
+		// declarations.
+		config.ResponseModalities = []string{"TEXT", "IMAGE"}
+		return
+	}
+	if len(tools) > 0 {
+		config.Tools = g.convertTools(tools)
+	}
+}
+
+// inlineImage returns the image of a response part, if it is one.
+func inlineImage(part *genai.Part) (message.BinaryContent, bool) {
+	if part.InlineData == nil || !strings.HasPrefix(part.InlineData.MIMEType, "image/") || part.Thought {
+		return message.BinaryContent{}, false
+	}
+	return message.BinaryContent{MIMEType: part.InlineData.MIMEType, Data: part.InlineData.Data}, true
+}
+
 func (g *geminiClient) applyMetadata(ctx context.Context, config *genai.GenerateContentConfig) {
 	resolved := resolveMetadata(ctx, g.providerOptions.metadata)
 	if resolved == nil {
@@ -554,35 +606,31 @@ func (g *geminiClient) applyMetadata(ctx context.Context, config *genai.Generate
 }
 
 func (g *geminiClient) shouldRetry(attempts int, err error) (bool, int64, error) {
+	policy := g.providerOptions.retry
 	// Check if error is a rate limit error
-	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+	if limit := policy.limit(); attempts > limit {
+		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries: %w", limit, err)
 	}
 
-	// Gemini doesn't have a standard error type we can check against
-	// So we'll check the error message for rate limit indicators
 	if errors.Is(err, io.EOF) {
 		return false, 0, err
 	}
 
-	errMsg := err.Error()
-	isRateLimit := false
-
-	// Check for common rate limit error messages
-	if contains(errMsg, "rate limit", "quota exceeded", "too many requests") {
-		isRateLimit = true
+	var isRateLimit bool
+	var apiErr genai.APIError
+	if policy.RetryOnStatus != nil && errors.As(err, &apiErr) {
+		isRateLimit = policy.retriesStatus(apiErr.Code)
+	} else {
+		// Without configured statuses, check the error message for rate
+		// limit indicators.
+		isRateLimit = contains(err.Error(), "rate limit", "quota exceeded", "too many requests")
 	}
 
 	if !isRateLimit {
 		return false, 0, err
 	}
 
-	// Calculate backoff with jitter
-	backoffMs := 2000 * (1 << (attempts - 1))
-	jitterMs := int(float64(backoffMs) * 0.2)
-	retryMs := backoffMs + jitterMs
-
-	return true, int64(retryMs), nil
+	return true, policy.backoff(attempts, 2*time.Second).Milliseconds(), nil
 }
 
 func (g *geminiClient) toolCalls(resp *genai.GenerateContentResponse) []message.ToolCall {
@@ -611,11 +659,14 @@ func (g *geminiClient) usage(resp *genai.GenerateContentResponse) TokenUsage {
 		return TokenUsage{}
 	}
 
+	// Thoughts are billed as output but not counted in the candidates.
+	thoughts := int64(resp.UsageMetadata.ThoughtsTokenCount)
 	return TokenUsage{
 		InputTokens:         int64(resp.UsageMetadata.PromptTokenCount),
-		OutputTokens:        int64(resp.UsageMetadata.CandidatesTokenCount),
+		OutputTokens:        int64(resp.UsageMetadata.CandidatesTokenCount) + thoughts,
 		CacheCreationTokens: 0, // Not directly provided by Gemini
 		CacheReadTokens:     int64(resp.UsageMetadata.CachedContentTokenCount),
+		ReasoningTokens:     thoughts,
 	}
 }
 
@@ -649,6 +700,23 @@ func WithGeminiDisableCache() GeminiOption {
 	}
 }
 
+func WithGeminiThinkingBudget(budget int64) GeminiOption {
+	return func(options *geminiOptions) {
+		options.thinkingBudget = budget
+	}
+}
+
+// applyThinking caps the tokens the model spends thinking, when the agent
+// sets a budget; otherwise the model decides.
+func (g *geminiClient) applyThinking(config *genai.GenerateContentConfig) {
+	if g.options.thinkingBudget <= 0 {
+		return
+	}
+	config.ThinkingConfig = &genai.ThinkingConfig{
+		ThinkingBudget: genai.Ptr(int32(g.options.thinkingBudget)),
+	}
+}
+
 // Helper functions
 func parseJsonToMap(jsonStr string) (map[string]interface{}, error) {
 	var result map[string]interface{}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// gatewayImages reads the images OpenAI-compatible gateways, OpenRouter
+// among them, add to a chat message next to its content:
+//
+//	"images": [{"type": "image_url", "image_url": {"url": "data:image/png;base64,..."}}]
+//
+// OpenAI's own chat completions never carry images. raw is the JSON of the
+// field, "" when the message has none.
+func gatewayImages(raw string) []message.BinaryContent {
+	if raw == "" {
+		return nil
+	}
+	var items []struct {
+		ImageURL struct {
+			URL string `json:"url"`
+		} `json:"image_url"`
+	}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil
+	}
+	var images []message.BinaryContent
+	for _, item := range items {
+		if mimeType, data, ok := decodeDataURL(item.ImageURL.URL); ok {
+			images = append(images, message.BinaryContent{MIMEType: mimeType, Data: data})
+		}
+	}
+	return images
+}
+
+// decodeDataURL decodes a base64 data: URL of an image.
+func decodeDataURL(url string) (mimeType string, data []byte, ok bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", nil, false
+	}
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok || !strings.HasSuffix(header, ";base64") {
+		return "", nil, false
+	}
+	mimeType = strings.TrimSuffix(header, ";base64")
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", nil, false
+	}
+	return mimeType, data, true
+}
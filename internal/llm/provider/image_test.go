@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genai"
+)
+
+func TestGatewayImages(t *testing.T) {
+	t.Parallel()
+	raw := `[
+		{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw=="}},
+		{"type": "image_url", "image_url": {"url": "https://example.com/a.png"}},
+		{"type": "image_url", "image_url": {"url": "data:text/plain;base64,aGk="}}
+	]`
+	images := gatewayImages(raw)
+	if assert.Len(t, images, 1) {
+		assert.Equal(t, "image/png", images[0].MIMEType)
+		assert.Equal(t, []byte{0x89, 0x50, 0x4e, 0x47}, images[0].Data)
+	}
+	assert.Nil(t, gatewayImages(""))
+	assert.Nil(t, gatewayImages("null"))
+}
+
+func TestInlineImage(t *testing.T) {
+	t.Parallel()
+	img, ok := inlineImage(&genai.Part{InlineData: &genai.Blob{MIMEType: "image/jpeg", Data: []byte{1, 2}}})
+	assert.True(t, ok)
+	assert.Equal(t, "image/jpeg", img.MIMEType)
+
+	_, ok = inlineImage(&genai.Part{InlineData: &genai.Blob{MIMEType: "application/pdf", Data: []byte{1}}})
+	assert.False(t, ok)
+	_, ok = inlineImage(&genai.Part{Thought: true, InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte{1}}})
+	assert.False(t, ok, "thought images are drafts, not the answer")
+	_, ok = inlineImage(&genai.Part{Text: "hi"})
+	assert.False(t, ok)
+}
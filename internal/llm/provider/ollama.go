@@ -0,0 +1,503 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+type ollamaOptions struct {
+	keepAlive string
+	numCtx    int64
+}
+
+type OllamaOption func(*ollamaOptions)
+
+type ollamaClient struct {
+	providerOptions providerClientOptions
+	options         ollamaOptions
+	httpClient      *http.Client
+}
+
+type OllamaClient ProviderClient
+
+func newOllamaClient(opts providerClientOptions) OllamaClient {
+	var ollamaOpts ollamaOptions
+	for _, o := range opts.ollamaOptions {
+		o(&ollamaOpts)
+	}
+	if opts.baseURL == "" {
+		opts.baseURL = models.OllamaHost()
+	}
+	return &ollamaClient{
+		providerOptions: opts,
+		options:         ollamaOpts,
+		httpClient:      &http.Client{},
+	}
+}
+
+type ollamaChatRequest struct {
+	Model     string              `json:"model"`
+	Messages  []ollamaMessage     `json:"messages"`
+	Tools     []ollamaTool        `json:"tools,omitempty"`
+	Stream    bool                `json:"stream"`
+	Think     *bool               `json:"think,omitempty"`
+	KeepAlive any                 `json:"keep_alive,omitempty"`
+	Format    map[string]any      `json:"format,omitempty"`
+	Options   ollamaRequestOption `json:"options"`
+}
+
+type ollamaRequestOption struct {
+	NumCtx     int64 `json:"num_ctx,omitempty"`
+	NumPredict int64 `json:"num_predict,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+type ollamaChatResponse struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int64         `json:"prompt_eval_count"`
+	EvalCount       int64         `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// ollamaStatusError is a non-200 reply from the server. Ollama answers 503
+// while another request holds the model and it cannot queue more.
+type ollamaStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ollamaStatusError) Error() string {
+	return fmt.Sprintf("ollama: %d %s", e.StatusCode, e.Message)
+}
+
+func (o *ollamaClient) convertMessages(messages []message.Message) []ollamaMessage {
+	ollamaMessages := []ollamaMessage{{Role: "system", Content: o.providerOptions.systemMessage}}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case message.User:
+			m := ollamaMessage{Role: "user", Content: msg.Content().String()}
+			for _, bc := range msg.BinaryContent() {
+				if image, ok := ollamaImage(bc); ok {
+					m.Images = append(m.Images, image)
+				} else {
+					m.Content += "\n\n" + ollamaAttachmentText(bc)
+				}
+			}
+			if strings.TrimSpace(m.Content) == "" && len(m.Images) == 0 {
+				logging.Warn("Skipping user message with no renderable content",
+					"message_id", msg.ID,
+				)
+				continue
+			}
+			ollamaMessages = append(ollamaMessages, m)
+
+		case message.Assistant:
+			m := ollamaMessage{Role: "assistant", Content: msg.Content().String()}
+			for _, call := range msg.ToolCalls() {
+				tc := ollamaToolCall{}
+				tc.Function.Name = call.Name
+				tc.Function.Arguments = json.RawMessage("{}")
+				if strings.TrimSpace(call.Input) != "" && json.Valid([]byte(call.Input)) {
+					tc.Function.Arguments = json.RawMessage(call.Input)
+				}
+				m.ToolCalls = append(m.ToolCalls, tc)
+			}
+			ollamaMessages = append(ollamaMessages, m)
+
+		case message.Tool:
+			for _, result := range msg.ToolResults() {
+				ollamaMessages = append(ollamaMessages, ollamaMessage{
+					Role:     "tool",
+					Content:  result.Content,
+					ToolName: result.Name,
+				})
+			}
+		}
+	}
+
+	return ollamaMessages
+}
+
+// ollamaImage returns the base64 payload Ollama expects in "images" for
+// vision models. Everything else is inlined as text by the caller.
+func ollamaImage(bc message.BinaryContent) (string, bool) {
+	mimeType := strings.ToLower(strings.TrimSpace(bc.MIMEType))
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return base64.StdEncoding.EncodeToString(bc.Data), true
+	}
+	return "", false
+}
+
+func ollamaAttachmentText(bc message.BinaryContent) string {
+	if len(bc.Data) > 0 && strings.HasPrefix(strings.ToLower(bc.MIMEType), "text/") && utf8.Valid(bc.Data) {
+		return string(bc.Data)
+	}
+	return unsupportedAttachmentNote(bc)
+}
+
+func (o *ollamaClient) convertTools(tools []tools.BaseTool) []ollamaTool {
+	ollamaTools := make([]ollamaTool, len(tools))
+	for i, tool := range tools {
+		info := tool.Info()
+		ollamaTools[i].Type = "function"
+		ollamaTools[i].Function.Name = info.Name
+		ollamaTools[i].Function.Description = info.Description
+		ollamaTools[i].Function.Parameters = map[string]any{
+			"type":       "object",
+			"properties": info.Parameters,
+			"required":   info.Required,
+		}
+	}
+	return ollamaTools
+}
+
+func (o *ollamaClient) finishReason(reason string) message.FinishReason {
+	switch reason {
+	case "stop":
+		return message.FinishReasonEndTurn
+	case "length":
+		return message.FinishReasonMaxTokens
+	default:
+		return message.FinishReasonUnknown
+	}
+}
+
+func (o *ollamaClient) preparedRequest(messages []message.Message, tools []tools.BaseTool, stream bool) ollamaChatRequest {
+	req := ollamaChatRequest{
+		Model:     o.providerOptions.model.APIModel,
+		Messages:  o.convertMessages(messages),
+		Tools:     o.convertTools(tools),
+		Stream:    stream,
+		KeepAlive: ollamaKeepAlive(o.options.keepAlive),
+		Options: ollamaRequestOption{
+			// Without num_ctx Ollama falls back to its small server default
+			// and silently drops the start of the prompt, system prompt
+			// included.
+			NumCtx:     o.options.numCtx,
+			NumPredict: o.providerOptions.maxTokens,
+		},
+	}
+	if req.Options.NumCtx == 0 {
+		req.Options.NumCtx = o.providerOptions.model.ContextWindow
+	}
+	if o.providerOptions.model.CanReason {
+		think := true
+		req.Think = &think
+	}
+	return req
+}
+
+// ollamaKeepAlive encodes keep_alive the way the server parses it: a bare
+// number is seconds (negative keeps the model loaded), anything else is a
+// Go duration string.
+func ollamaKeepAlive(keepAlive string) any {
+	if keepAlive == "" {
+		return nil
+	}
+	if n, err := strconv.ParseInt(keepAlive, 10, 64); err == nil {
+		return n
+	}
+	return keepAlive
+}
+
+func (o *ollamaClient) do(ctx context.Context, req ollamaChatRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if cfg := config.Get(); cfg != nil && cfg.Debug {
+		logging.Debug("Prepared messages", "messages", string(body))
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(o.providerOptions.baseURL, "/")+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = o.providerOptions.asHeader().Clone()
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.providerOptions.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.providerOptions.apiKey)
+	}
+	resp, err := o.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Error == "" {
+			apiErr.Error = strings.TrimSpace(string(data))
+		}
+		return nil, &ollamaStatusError{StatusCode: resp.StatusCode, Message: apiErr.Error}
+	}
+	return resp, nil
+}
+
+func (o *ollamaClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	req := o.preparedRequest(messages, tools, false)
+	req.Format = structuredOutputFromContext(ctx)
+	req.Messages[0].Content = systemMessageFromContext(ctx, req.Messages[0].Content)
+	attempts := 0
+	for {
+		attempts++
+		resp, err := o.do(ctx, req)
+		if err != nil {
+			retry, after, retryErr := o.shouldRetry(attempts, err)
+			if !retry {
+				return nil, retryErr
+			}
+			logRetry(attempts, o.providerOptions.retry.limit(), after)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(after):
+				continue
+			}
+		}
+		var chat ollamaChatResponse
+		err = json.NewDecoder(resp.Body).Decode(&chat)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if chat.Error != "" {
+			return nil, fmt.Errorf("ollama: %s", chat.Error)
+		}
+		return o.response(chat.Message.Content, o.toolCalls(chat.Message.ToolCalls), chat), nil
+	}
+}
+
+func (o *ollamaClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	req := o.preparedRequest(messages, tools, true)
+	req.Format = structuredOutputFromContext(ctx)
+	req.Messages[0].Content = systemMessageFromContext(ctx, req.Messages[0].Content)
+	eventChan := make(chan ProviderEvent)
+
+	go func() {
+		defer close(eventChan)
+		attempts := 0
+		for {
+			attempts++
+			resp, err := o.do(ctx, req)
+			if err != nil {
+				retry, after, retryErr := o.shouldRetry(attempts, err)
+				if !retry {
+					eventChan <- ProviderEvent{Type: EventError, Error: retryErr}
+					return
+				}
+				logging.Warn("Ollama request failed, will retry", "attempt", attempts, "error", err)
+				eventChan <- retryEvent(attempts, o.providerOptions.retry.limit(), after, err)
+				select {
+				case <-ctx.Done():
+					eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+					return
+				case <-time.After(after):
+					continue
+				}
+			}
+
+			// Ollama streams one JSON object per line.
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+			reader := newStreamReader(ctx, func() (ollamaChatResponse, bool) {
+				for scanner.Scan() {
+					line := bytes.TrimSpace(scanner.Bytes())
+					if len(line) == 0 {
+						continue
+					}
+					var chunk ollamaChatResponse
+					if err := json.Unmarshal(line, &chunk); err != nil {
+						chunk.Error = fmt.Sprintf("invalid stream chunk: %v", err)
+					}
+					return chunk, true
+				}
+				return ollamaChatResponse{}, false
+			}, func() {
+				resp.Body.Close()
+			})
+
+			var (
+				content   strings.Builder
+				toolCalls []message.ToolCall
+				final     *ollamaChatResponse
+				streamErr error
+			)
+			for final == nil && streamErr == nil {
+				chunk, ok, recvErr := reader.Recv()
+				if recvErr != nil {
+					streamErr = recvErr
+					break
+				}
+				if !ok {
+					break
+				}
+				if chunk.Error != "" {
+					streamErr = fmt.Errorf("ollama: %s", chunk.Error)
+					break
+				}
+				if chunk.Message.Thinking != "" {
+					eventChan <- ProviderEvent{Type: EventThinkingDelta, Thinking: chunk.Message.Thinking}
+				}
+				if chunk.Message.Content != "" {
+					eventChan <- ProviderEvent{Type: EventContentDelta, Content: chunk.Message.Content}
+					content.WriteString(chunk.Message.Content)
+				}
+				toolCalls = append(toolCalls, o.toolCalls(chunk.Message.ToolCalls)...)
+				if chunk.Done {
+					final = &chunk
+				}
+			}
+			reader.Close()
+			if streamErr == nil {
+				streamErr = scanner.Err()
+			}
+
+			if final != nil {
+				eventChan <- ProviderEvent{
+					Type:     EventComplete,
+					Response: o.response(content.String(), toolCalls, *final),
+				}
+				return
+			}
+			if streamErr == nil {
+				streamErr = io.ErrUnexpectedEOF
+			}
+			if ctx.Err() != nil {
+				streamErr = ctx.Err()
+			}
+			eventChan <- ProviderEvent{Type: EventError, Error: streamErr}
+			return
+		}
+	}()
+
+	return eventChan
+}
+
+func (o *ollamaClient) response(content string, toolCalls []message.ToolCall, final ollamaChatResponse) *ProviderResponse {
+	finishReason := o.finishReason(final.DoneReason)
+	if len(toolCalls) > 0 {
+		finishReason = message.FinishReasonToolUse
+	}
+	return &ProviderResponse{
+		Content:   content,
+		ToolCalls: toolCalls,
+		Usage: TokenUsage{
+			InputTokens:  final.PromptEvalCount,
+			OutputTokens: final.EvalCount,
+		},
+		FinishReason: finishReason,
+	}
+}
+
+// toolCalls converts Ollama tool calls, which carry no IDs, into calls
+// with generated IDs so results can be paired with them.
+func (o *ollamaClient) toolCalls(calls []ollamaToolCall) []message.ToolCall {
+	var toolCalls []message.ToolCall
+	for _, call := range calls {
+		input := strings.TrimSpace(string(call.Function.Arguments))
+		if input == "" || input == "null" {
+			input = "{}"
+		}
+		toolCalls = append(toolCalls, message.ToolCall{
+			ID:       "call_" + uuid.NewString(),
+			Name:     call.Function.Name,
+			Input:    input,
+			Type:     "function",
+			Finished: true,
+		})
+	}
+	return toolCalls
+}
+
+// shouldRetry retries a request that never produced output because the
+// connection broke or the server was busy with another request.
+func (o *ollamaClient) shouldRetry(attempts int, err error) (bool, time.Duration, error) {
+	policy := o.providerOptions.retry
+	var statusErr *ollamaStatusError
+	if errors.As(err, &statusErr) {
+		if !policy.retriesStatus(statusErr.StatusCode, http.StatusServiceUnavailable, http.StatusTooManyRequests) {
+			return false, 0, err
+		}
+	} else if !isTransientStreamError(err) {
+		return false, 0, err
+	}
+	if limit := policy.limit(); attempts > limit {
+		return false, 0, fmt.Errorf("maximum retry attempts reached: %d retries: %w", limit, err)
+	}
+	return true, policy.backoff(attempts, 500*time.Millisecond), nil
+}
+
+func (o *ollamaClient) countTokens(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (int64, error) {
+	return 0, fmt.Errorf("countTokens is unsupported by ollama client: %w", errors.ErrUnsupported)
+}
+
+func (o *ollamaClient) setMaxTokens(maxTokens int64) {
+	o.providerOptions.maxTokens = maxTokens
+}
+
+func (o *ollamaClient) maxTokens() int64 {
+	return o.providerOptions.maxTokens
+}
+
+// WithOllamaKeepAlive sets how long the server keeps the model loaded after
+// a request, e.g. "30m", or "-1" to keep it loaded indefinitely.
+func WithOllamaKeepAlive(keepAlive string) OllamaOption {
+	return func(options *ollamaOptions) {
+		options.keepAlive = keepAlive
+	}
+}
+
+// WithOllamaNumCtx overrides the context window requested per call.
+func WithOllamaNumCtx(numCtx int64) OllamaOption {
+	return func(options *ollamaOptions) {
+		options.numCtx = numCtx
+	}
+}
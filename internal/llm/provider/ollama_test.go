@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+func newTestOllama(t *testing.T, handler http.HandlerFunc, opts ...OllamaOption) Provider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	p, err := NewProvider(models.ProviderOllama,
+		WithModel(models.Model{ID: "ollama.qwen3:8b", APIModel: "qwen3:8b", Provider: models.ProviderOllama, ContextWindow: 16384, CanReason: true}),
+		WithBaseURL(srv.URL),
+		WithMaxTokens(1024),
+		WithSystemMessage("be brief"),
+		WithOllamaOptions(opts...),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestOllamaStreamToolCall(t *testing.T) {
+	var got map[string]any
+	p := newTestOllama(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"","thinking":"hmm"},"done":false}`,
+			`{"message":{"role":"assistant","content":"Let me look."},"done":false}`,
+			`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"ls","arguments":{"path":"."}}}]},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":42,"eval_count":7}`,
+		} {
+			_, _ = w.Write([]byte(line + "\n"))
+		}
+	}, WithOllamaKeepAlive("-1"))
+
+	msgs := []message.Message{{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "list files"}}}}
+	var (
+		thinking, content string
+		resp              *ProviderResponse
+	)
+	for ev := range p.StreamResponse(t.Context(), msgs, nil) {
+		switch ev.Type {
+		case EventThinkingDelta:
+			thinking += ev.Thinking
+		case EventContentDelta:
+			content += ev.Content
+		case EventComplete:
+			resp = ev.Response
+		case EventError:
+			t.Fatal(ev.Error)
+		}
+	}
+
+	if thinking != "hmm" || content != "Let me look." {
+		t.Fatalf("thinking=%q content=%q", thinking, content)
+	}
+	if resp == nil || resp.FinishReason != message.FinishReasonToolUse || len(resp.ToolCalls) != 1 {
+		t.Fatalf("response = %+v", resp)
+	}
+	if tc := resp.ToolCalls[0]; tc.Name != "ls" || tc.Input != `{"path":"."}` || tc.ID == "" {
+		t.Fatalf("tool call = %+v", tc)
+	}
+	if resp.Usage.InputTokens != 42 || resp.Usage.OutputTokens != 7 {
+		t.Fatalf("usage = %+v", resp.Usage)
+	}
+
+	options := got["options"].(map[string]any)
+	if options["num_ctx"] != float64(16384) || options["num_predict"] != float64(1024) {
+		t.Fatalf("options = %v", options)
+	}
+	if got["keep_alive"] != float64(-1) || got["think"] != true || got["stream"] != true {
+		t.Fatalf("request = %v", got)
+	}
+	if m := got["messages"].([]any)[0].(map[string]any); m["role"] != "system" || m["content"] != "be brief" {
+		t.Fatalf("first message = %v", m)
+	}
+}
+
+func TestOllamaToolResultsCarryToolName(t *testing.T) {
+	c := newOllamaClient(providerClientOptions{}).(*ollamaClient)
+	msgs := c.convertMessages([]message.Message{
+		{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: "call_1", Name: "ls", Input: ""}}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call_1", Name: "ls", Content: "a.go"}}},
+	})
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages", len(msgs))
+	}
+	if args := string(msgs[1].ToolCalls[0].Function.Arguments); args != "{}" {
+		t.Fatalf("arguments = %s", args)
+	}
+	if msgs[2].Role != "tool" || msgs[2].ToolName != "ls" || msgs[2].Content != "a.go" {
+		t.Fatalf("tool message = %+v", msgs[2])
+	}
+}
+
+func TestOllamaSendErrorStatus(t *testing.T) {
+	p := newTestOllama(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"qwen3:8b\" not found, try pulling it first"}`))
+	}, WithOllamaNumCtx(8192))
+	_, err := p.SendMessages(t.Context(), []message.Message{{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}}}, nil)
+	if err == nil || err.Error() != `ollama: 404 model "qwen3:8b" not found, try pulling it first` {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestOllamaKeepAlive(t *testing.T) {
+	for in, want := range map[string]any{"": nil, "-1": int64(-1), "300": int64(300), "30m": "30m"} {
+		if got := ollamaKeepAlive(in); got != want {
+			t.Errorf("ollamaKeepAlive(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
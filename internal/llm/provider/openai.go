@@ -1,11 +1,13 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
@@ -25,6 +27,7 @@ type openaiOptions struct {
 	disableCache    bool
 	reasoningEffort string
 	legacyMaxTokens bool
+	middleware      []option.Middleware
 }
 
 type OpenAIOption func(*openaiOptions)
@@ -58,6 +61,10 @@ func newOpenAIClient(opts providerClientOptions) OpenAIClient {
 		}
 	}
 
+	if len(openaiOpts.middleware) > 0 {
+		openaiClientOptions = append(openaiClientOptions, option.WithMiddleware(openaiOpts.middleware...))
+	}
+
 	client := openai.NewClient(openaiClientOptions...)
 	return &openaiClient{
 		providerOptions: opts,
@@ -220,12 +227,18 @@ func (o *openaiClient) finishReason(reason string) message.FinishReason {
 		return message.FinishReasonMaxTokens
 	case "tool_calls":
 		return message.FinishReasonToolUse
+	case "content_filter":
+		return message.FinishReasonContentFilter
 	default:
 		return message.FinishReasonUnknown
 	}
 }
 
-func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) openai.ChatCompletionNewParams {
+func (o *openaiClient) preparedParams(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) openai.ChatCompletionNewParams {
+	// convertMessages always puts the system message first.
+	if systemMessage := systemMessageFromContext(ctx, o.providerOptions.systemMessage); len(messages) > 0 && systemMessage != o.providerOptions.systemMessage {
+		messages[0] = openai.SystemMessage(systemMessage)
+	}
 	params := openai.ChatCompletionNewParams{
 		Model:    openai.ChatModel(o.providerOptions.model.APIModel),
 		Messages: messages,
@@ -234,7 +247,7 @@ func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessagePar
 
 	if o.providerOptions.model.CanReason == true {
 		params.MaxCompletionTokens = openai.Int(o.providerOptions.maxTokens)
-		switch o.options.reasoningEffort {
+		switch reasoningEffortFromContext(ctx, o.options.reasoningEffort) {
 		case "low":
 			params.ReasoningEffort = shared.ReasoningEffortLow
 		case "medium":
@@ -251,11 +264,27 @@ func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessagePar
 		}
 	}
 
+	if schema := structuredOutputFromContext(ctx); schema != nil {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "final_answer",
+					Schema: schema,
+				},
+			},
+		}
+		// The answer is the JSON document itself. Tools stay declared so
+		// the request keeps its cached prefix, but must not be called.
+		if len(tools) > 0 {
+			params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String("none")}
+		}
+	}
+
 	return params
 }
 
 func (o *openaiClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (response *ProviderResponse, err error) {
-	params := o.preparedParams(o.convertMessages(messages), o.convertTools(tools))
+	params := o.preparedParams(ctx, o.convertMessages(messages), o.convertTools(tools))
 	o.applyMetadata(ctx, &params)
 	cfg := config.Get()
 	if cfg.Debug {
@@ -276,7 +305,7 @@ func (o *openaiClient) send(ctx context.Context, messages []message.Message, too
 				return nil, retryErr
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				logRetry(attempts, o.providerOptions.retry.limit(), time.Duration(after)*time.Millisecond)
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -302,14 +331,152 @@ func (o *openaiClient) send(ctx context.Context, messages []message.Message, too
 		return &ProviderResponse{
 			Content:      content,
 			ToolCalls:    toolCalls,
+			Images:       gatewayImages(openaiResponse.Choices[0].Message.JSON.ExtraFields["images"].Raw()),
 			Usage:        o.usage(*openaiResponse),
 			FinishReason: finishReason,
 		}, nil
 	}
 }
 
+// sendBatched answers the request through the Batch API.
+func (o *openaiClient) sendBatched(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	params := o.preparedParams(ctx, o.convertMessages(messages), o.convertTools(tools))
+	o.applyMetadata(ctx, &params)
+	b := sharedBatcher(batcherKey("openai", o.providerOptions), o.providerOptions.batch, o.batchAPI())
+	completion, err := b.do(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("batch response %s has no choices", completion.ID)
+	}
+
+	toolCalls := o.toolCalls(completion)
+	finishReason := o.finishReason(string(completion.Choices[0].FinishReason))
+	if len(toolCalls) > 0 {
+		finishReason = message.FinishReasonToolUse
+	}
+	return &ProviderResponse{
+		Content:      completion.Choices[0].Message.Content,
+		ToolCalls:    toolCalls,
+		Usage:        o.usage(completion),
+		FinishReason: finishReason,
+	}, nil
+}
+
+// openaiBatchLine is one line of a batch's output or error file.
+type openaiBatchLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (o *openaiClient) batchAPI() batchAPI[openai.ChatCompletionNewParams, openai.ChatCompletion] {
+	client := o.client
+	return batchAPI[openai.ChatCompletionNewParams, openai.ChatCompletion]{
+		submit: func(ctx context.Context, requests map[string]openai.ChatCompletionNewParams) (string, error) {
+			var input bytes.Buffer
+			enc := json.NewEncoder(&input)
+			for id, params := range requests {
+				line := map[string]any{
+					"custom_id": id,
+					"method":    http.MethodPost,
+					"url":       string(openai.BatchNewParamsEndpointV1ChatCompletions),
+					"body":      params,
+				}
+				if err := enc.Encode(line); err != nil {
+					return "", err
+				}
+			}
+			file, err := client.Files.New(ctx, openai.FileNewParams{
+				File:    openai.File(&input, "batch.jsonl", "application/jsonl"),
+				Purpose: openai.FilePurposeBatch,
+			})
+			if err != nil {
+				return "", fmt.Errorf("uploading batch input: %w", err)
+			}
+			batch, err := client.Batches.New(ctx, openai.BatchNewParams{
+				CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+				Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+				InputFileID:      file.ID,
+			})
+			if err != nil {
+				return "", err
+			}
+			return batch.ID, nil
+		},
+		ended: func(ctx context.Context, batchID string) (bool, error) {
+			batch, err := client.Batches.Get(ctx, batchID)
+			if err != nil {
+				return false, err
+			}
+			switch batch.Status {
+			case openai.BatchStatusCompleted, openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+				return true, nil
+			}
+			return false, nil
+		},
+		results: func(ctx context.Context, batchID string) (map[string]batchResult[openai.ChatCompletion], error) {
+			batch, err := client.Batches.Get(ctx, batchID)
+			if err != nil {
+				return nil, err
+			}
+			if batch.OutputFileID == "" && batch.ErrorFileID == "" {
+				return nil, fmt.Errorf("batch %s", batch.Status)
+			}
+			results := map[string]batchResult[openai.ChatCompletion]{}
+			for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+				if fileID == "" {
+					continue
+				}
+				resp, err := client.Files.Content(ctx, fileID)
+				if err != nil {
+					return nil, err
+				}
+				dec := json.NewDecoder(resp.Body)
+				for dec.More() {
+					var line openaiBatchLine
+					if err := dec.Decode(&line); err != nil {
+						resp.Body.Close()
+						return nil, err
+					}
+					results[line.CustomID] = line.result()
+				}
+				resp.Body.Close()
+			}
+			return results, nil
+		},
+		cancel: func(ctx context.Context, batchID string) error {
+			_, err := client.Batches.Cancel(ctx, batchID)
+			return err
+		},
+	}
+}
+
+func (l openaiBatchLine) result() batchResult[openai.ChatCompletion] {
+	switch {
+	case l.Error != nil:
+		return batchResult[openai.ChatCompletion]{err: fmt.Errorf("batch request failed: %s: %s", l.Error.Code, l.Error.Message)}
+	case l.Response == nil:
+		return batchResult[openai.ChatCompletion]{err: errors.New("batch request has no response")}
+	case l.Response.StatusCode != http.StatusOK:
+		return batchResult[openai.ChatCompletion]{err: fmt.Errorf("batch request failed with status %d: %s", l.Response.StatusCode, l.Response.Body)}
+	}
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal(l.Response.Body, &completion); err != nil {
+		return batchResult[openai.ChatCompletion]{err: err}
+	}
+	return batchResult[openai.ChatCompletion]{value: completion}
+}
+
 func (o *openaiClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
-	params := o.preparedParams(o.convertMessages(messages), o.convertTools(tools))
+	params := o.preparedParams(ctx, o.convertMessages(messages), o.convertTools(tools))
 	o.applyMetadata(ctx, &params)
 	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
 		IncludeUsage: openai.Bool(true),
@@ -342,6 +509,9 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 			acc := openai.ChatCompletionAccumulator{}
 			currentContent := ""
 			toolCalls := make([]message.ToolCall, 0)
+			// The accumulator drops fields it doesn't know, images among
+			// them; gateways send each image whole in one delta.
+			var images []message.BinaryContent
 
 			reader := newStreamReader(ctx, func() (openai.ChatCompletionChunk, bool) {
 				if !openaiStream.Next() {
@@ -366,6 +536,7 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 				acc.AddChunk(chunk)
 
 				for _, choice := range chunk.Choices {
+					images = append(images, gatewayImages(choice.Delta.JSON.ExtraFields["images"].Raw())...)
 					if choice.Delta.Content != "" {
 						emittedOutput = true
 						eventChan <- ProviderEvent{
@@ -380,7 +551,8 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 
 			if errors.Is(streamErr, ErrStreamStalled) {
 				logging.Warn("OpenAI stream stalled, will retry", "attempt", attempts)
-				if attempts < maxRetries {
+				if limit := o.providerOptions.retry.limit(); attempts < limit {
+					eventChan <- retryEvent(attempts, limit, 0, streamErr)
 					continue
 				}
 				eventChan <- ProviderEvent{Type: EventError, Error: streamErr}
@@ -412,6 +584,7 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 					Response: &ProviderResponse{
 						Content:      currentContent,
 						ToolCalls:    toolCalls,
+						Images:       images,
 						Usage:        o.usage(acc.ChatCompletion),
 						FinishReason: finishReason,
 					},
@@ -423,8 +596,9 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 			// Retry transient transport errors (e.g. unexpected EOF, connection reset)
 			if isTransientStreamError(err) {
 				logging.Warn("OpenAI stream transport error, will retry", "attempt", attempts, "error", err)
-				if attempts < maxRetries {
-					backoffMs := 2000 * (1 << (attempts - 1))
+				if limit := o.providerOptions.retry.limit(); attempts < limit {
+					backoff := o.providerOptions.retry.backoff(attempts, 2*time.Second)
+					eventChan <- retryEvent(attempts, limit, backoff, err)
 					select {
 					case <-ctx.Done():
 						if ctx.Err() != nil {
@@ -432,7 +606,7 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 						}
 						close(eventChan)
 						return
-					case <-time.After(time.Duration(backoffMs) * time.Millisecond):
+					case <-time.After(backoff):
 						continue
 					}
 				}
@@ -486,7 +660,7 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 				return
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				eventChan <- retryEvent(attempts, o.providerOptions.retry.limit(), time.Duration(after)*time.Millisecond, err)
 				select {
 				case <-ctx.Done():
 					// context cancelled
@@ -533,26 +707,20 @@ func (o *openaiClient) shouldRetry(attempts int, err error) (bool, int64, error)
 		return false, 0, err
 	}
 
-	if apierr.StatusCode != 429 && apierr.StatusCode != 500 {
+	policy := o.providerOptions.retry
+	if !policy.retriesStatus(apierr.StatusCode, 429, 500) {
 		return false, 0, err
 	}
 
-	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+	if limit := policy.limit(); attempts > limit {
+		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries: %w", limit, err)
 	}
 
-	retryMs := 0
-	retryAfterValues := apierr.Response.Header.Values("Retry-After")
-
-	backoffMs := 2000 * (1 << (attempts - 1))
-	jitterMs := int(float64(backoffMs) * 0.2)
-	retryMs = backoffMs + jitterMs
-	if len(retryAfterValues) > 0 {
-		if _, err := fmt.Sscanf(retryAfterValues[0], "%d", &retryMs); err == nil {
-			retryMs = retryMs * 1000
-		}
+	var header http.Header
+	if apierr.Response != nil {
+		header = apierr.Response.Header
 	}
-	return true, int64(retryMs), nil
+	return true, policy.delay(attempts, 2*time.Second, header).Milliseconds(), nil
 }
 
 func (o *openaiClient) toolCalls(completion openai.ChatCompletion) []message.ToolCall {
@@ -587,6 +755,7 @@ func (o *openaiClient) usage(completion openai.ChatCompletion) TokenUsage {
 		OutputTokens:        completion.Usage.CompletionTokens,
 		CacheCreationTokens: 0, // OpenAI doesn't provide this directly
 		CacheReadTokens:     cachedTokens,
+		ReasoningTokens:     completion.Usage.CompletionTokensDetails.ReasoningTokens,
 	}
 }
 
@@ -608,6 +777,14 @@ func WithOpenAIDisableCache() OpenAIOption {
 	}
 }
 
+// withOpenAIMiddleware intercepts every request of the client, e.g. to
+// attach credentials that expire.
+func withOpenAIMiddleware(middleware option.Middleware) OpenAIOption {
+	return func(options *openaiOptions) {
+		options.middleware = append(options.middleware, middleware)
+	}
+}
+
 func WithLegacyMaxTokens() OpenAIOption {
 	return func(options *openaiOptions) {
 		options.legacyMaxTokens = true
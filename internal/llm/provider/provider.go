@@ -259,6 +259,12 @@ type TokenUsage struct {
 	OutputTokens        int64
 	CacheCreationTokens int64
 	CacheReadTokens     int64
+	// ReasoningTokens is the part of OutputTokens the model spent
+	// thinking, for providers that report it.
+	ReasoningTokens int64
+	// Batched marks usage of a request answered through a batch API,
+	// which is billed at batchDiscount of the list price.
+	Batched bool
 }
 
 type ProviderResponse struct {
@@ -269,7 +275,10 @@ type ProviderResponse struct {
 	// emission order. Consumers persist these verbatim so they can be
 	// replayed on subsequent requests (thinking-block echo). Empty for
 	// providers/turns without reasoning.
-	Reasoning    []message.ReasoningContent
+	Reasoning []message.ReasoningContent
+	// Images are the images the model produced, in order. The agent saves
+	// them to the data directory and keeps a reference in the message.
+	Images       []message.BinaryContent
 	Usage        TokenUsage
 	FinishReason message.FinishReason
 }
@@ -282,6 +291,9 @@ type ProviderEvent struct {
 	Response *ProviderResponse
 	ToolCall *message.ToolCall
 	Error    error
+	// RetryAfter is how long the client waits before the retry an
+	// EventWarning announces.
+	RetryAfter time.Duration
 }
 
 type Provider interface {
@@ -315,6 +327,12 @@ type providerClientOptions struct {
 	openaiOptions    []OpenAIOption
 	geminiOptions    []GeminiOption
 	bedrockOptions   []BedrockOption
+	ollamaOptions    []OllamaOption
+
+	// retry overrides the client's built-in retry behavior.
+	retry RetryPolicy
+	// batch sends non-interactive requests through the batch API.
+	batch BatchPolicy
 }
 
 func (opts *providerClientOptions) asHeader() *http.Header {
@@ -339,8 +357,9 @@ type ProviderClient interface {
 }
 
 type baseProvider[C ProviderClient] struct {
-	options providerClientOptions
-	client  C
+	options     providerClientOptions
+	client      C
+	tokenCounts tokenCountCache
 }
 
 func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption) (Provider, error) {
@@ -408,6 +427,16 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 			options: clientOptions,
 			client:  newOpenAIClient(clientOptions),
 		}, nil
+	case models.ProviderCopilot:
+		return &baseProvider[OpenAIClient]{
+			options: clientOptions,
+			client:  newCopilotClient(clientOptions),
+		}, nil
+	case models.ProviderOllama:
+		return &baseProvider[OllamaClient]{
+			options: clientOptions,
+			client:  newOllamaClient(clientOptions),
+		}, nil
 	case models.ProviderMock:
 		// TODO: implement mock client for test
 		panic("not implemented")
@@ -643,9 +672,18 @@ func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message
 	messages = p.cleanMessages(messages)
 	messages = p.sanitizeToolPairs(messages)
 
+	var upstream <-chan ProviderEvent
+	if bc, ok := any(p.client).(batchClient); ok && p.options.batch.useBatch(ctx) {
+		upstream = batchStream(ctx, bc, messages, tools)
+	} else {
+		// Buffer the client's stream so a slow consumer can't stall it; see
+		// backpressure.go.
+		upstream = bufferStream(ctx, p.client.stream(ctx, messages, tools))
+	}
+
 	lf := p.options.langfuseClient
 	if lf == nil || !lf.Enabled() {
-		return p.client.stream(ctx, messages, tools)
+		return upstream
 	}
 
 	model := p.options.model
@@ -655,7 +693,6 @@ func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message
 		Metadata: p.generationMetadata(ctx),
 	})
 
-	upstream := p.client.stream(ctx, messages, tools)
 	wrapped := make(chan ProviderEvent)
 
 	go func() {
@@ -737,47 +774,62 @@ func reconcileTokenEstimate(endpoint, local int64) int64 {
 	return local
 }
 
+// CountTokens serves unchanged messages from the token count cache and
+// only calls the count_tokens endpoint when the cached total has drifted
+// too far from a measurement (see tokenCountCache).
 func (p *baseProvider[C]) CountTokens(ctx context.Context, threshold float64, messages []message.Message, tools []toolsPkg.BaseTool) (int64, bool) {
-	local := p.localTokenEstimate(messages, tools)
-	estimatedTokens := local
-	endpointTokens, err := p.client.countTokens(ctx, messages, tools)
-	if err != nil {
-		// Endpoint unavailable — fall back to the local estimate.
-		switch {
-		case errors.Is(err, context.Canceled):
-			// Shutdown/cancel noise, nothing to log.
-		case errors.Is(err, errors.ErrUnsupported):
-			// Known-unsupported endpoint (e.g. 404-latched or a client
-			// with no count_tokens at all): local estimation is the
-			// steady state, so don't warn once per loop iteration.
-			logging.Debug("countTokens unsupported, using local strategy for max_tokens", "model", p.options.model.Name, "cause", err.Error())
-		default:
-			logging.Warn("Provider doesn't support countTokens endpoint, using local strategy for max_tokens", "model", p.options.model.Name, "cause", err.Error())
-		}
-	} else {
-		estimatedTokens = reconcileTokenEstimate(endpointTokens, local)
-		// A proxy that returns fewer tokens than the local estimate is almost
-		// certainly omitting system + tools from the count (see
-		// reconcileTokenEstimate). Warn once per call so the drift is visible
-		// without silently under-compacting.
-		if endpointTokens < local {
-			logging.Warn("count_tokens endpoint returned fewer tokens than the local estimate; using local estimate (endpoint likely omits system prompt and tool schemas)",
-				"model", p.options.model.Name,
-				"endpoint_tokens", endpointTokens,
-				"local_tokens", local,
-			)
+	model := string(p.options.model.ID)
+	overheadKey := overheadFingerprint(p.options.systemMessage, tools)
+	overheadLocal := p.localTokenEstimate(nil, tools)
+	plan := p.tokenCounts.lookup(model, messages, overheadKey, overheadLocal)
+	local := plan.local
+
+	contextWindow := p.Model().ContextWindow
+	thresholdAbs := int64(float64(contextWindow) * threshold)
+	estimatedTokens := reconcileTokenEstimate(plan.total, local)
+	var endpointTokens int64
+	cached := !plan.needsRefresh(contextWindow, thresholdAbs)
+	if !cached {
+		var err error
+		endpointTokens, err = p.client.countTokens(ctx, messages, tools)
+		if err != nil {
+			// Endpoint unavailable — fall back to the local estimate.
+			switch {
+			case errors.Is(err, context.Canceled):
+				// Shutdown/cancel noise, nothing to log.
+			case errors.Is(err, errors.ErrUnsupported):
+				// Known-unsupported endpoint (e.g. 404-latched or a client
+				// with no count_tokens at all): local estimation is the
+				// steady state, so don't warn once per loop iteration.
+				logging.Debug("countTokens unsupported, using local strategy for max_tokens", "model", p.options.model.Name, "cause", err.Error())
+			default:
+				logging.Warn("Provider doesn't support countTokens endpoint, using local strategy for max_tokens", "model", p.options.model.Name, "cause", err.Error())
+			}
+		} else {
+			estimatedTokens = reconcileTokenEstimate(endpointTokens, local)
+			p.tokenCounts.record(model, messages, overheadKey, overheadLocal, estimatedTokens)
+			// A proxy that returns fewer tokens than the local estimate is almost
+			// certainly omitting system + tools from the count (see
+			// reconcileTokenEstimate). Warn once per call so the drift is visible
+			// without silently under-compacting.
+			if endpointTokens < local {
+				logging.Warn("count_tokens endpoint returned fewer tokens than the local estimate; using local estimate (endpoint likely omits system prompt and tool schemas)",
+					"model", p.options.model.Name,
+					"endpoint_tokens", endpointTokens,
+					"local_tokens", local,
+				)
+			}
 		}
 	}
-	contextWindow := p.Model().ContextWindow
 	if contextWindow <= 0 {
 		return estimatedTokens, false
 	}
-	thresholdAbs := int64(float64(contextWindow) * threshold)
 	hitThreshold := estimatedTokens >= thresholdAbs
 	logging.Debug("Token estimation for auto-compaction",
 		"estimated_tokens", estimatedTokens,
 		"endpoint_tokens", endpointTokens,
 		"local_tokens", local,
+		"cached", cached,
 		"threshold", thresholdAbs,
 		"context_window", contextWindow,
 		"auto-compaction required", hitThreshold,
@@ -837,9 +889,23 @@ func CalculateCost(model models.Model, u TokenUsage) (inputCost, outputCost floa
 		model.CostPer1MOutCached/1e6*float64(u.CacheReadTokens) +
 		model.CostPer1MIn/1e6*float64(u.InputTokens)
 	outputCost = model.CostPer1MOut / 1e6 * float64(u.OutputTokens)
+	if u.Batched {
+		inputCost *= batchDiscount
+		outputCost *= batchDiscount
+	}
 	return
 }
 
+// ReasoningCost is the part of the output cost CalculateCost returns for u
+// that went to reasoning tokens.
+func ReasoningCost(model models.Model, u TokenUsage) float64 {
+	if u.OutputTokens == 0 {
+		return 0
+	}
+	_, outputCost := CalculateCost(model, u)
+	return outputCost * float64(u.ReasoningTokens) / float64(u.OutputTokens)
+}
+
 // buildUsage converts a ProviderResponse's TokenUsage into a Langfuse Usage struct.
 func (p *baseProvider[C]) buildUsage(u TokenUsage) *langfuse.Usage {
 	inputCost, outputCost := CalculateCost(p.options.model, u)
@@ -850,8 +916,10 @@ func (p *baseProvider[C]) buildUsage(u TokenUsage) *langfuse.Usage {
 		Total:         totalInput + u.OutputTokens,
 		CacheRead:     u.CacheReadTokens,
 		CacheCreation: u.CacheCreationTokens,
+		Reasoning:     u.ReasoningTokens,
 		InputCost:     inputCost,
 		OutputCost:    outputCost,
+		ReasoningCost: ReasoningCost(p.options.model, u),
 		TotalCost:     inputCost + outputCost,
 	}
 }
@@ -864,6 +932,9 @@ func (p *baseProvider[C]) generationMetadata(ctx context.Context) map[string]any
 	if agentID := getAgentIDFromCtx(ctx); agentID != "" {
 		meta["agent_id"] = agentID
 	}
+	if effort := reasoningEffortFromContext(ctx, ""); effort != "" {
+		meta["reasoning_effort"] = effort
+	}
 	// Apply metadata namespace prefix when configured.
 	if cfg := config.Get(); cfg.Telemetry != nil && cfg.Telemetry.MetadataNamespace != "" {
 		meta = langfuse.NamespaceMetadata(meta, cfg.Telemetry.MetadataNamespace)
@@ -879,6 +950,66 @@ func getAgentIDFromCtx(ctx context.Context) string {
 	return ""
 }
 
+type reasoningEffortKeyType struct{}
+
+var reasoningEffortKey = reasoningEffortKeyType{}
+
+// ReasoningEffortContext returns a context that overrides the configured
+// reasoning effort for requests made with it. Agents with reasoningEffort
+// "auto" use it to pick the effort per turn.
+func ReasoningEffortContext(ctx context.Context, effort string) context.Context {
+	return context.WithValue(ctx, reasoningEffortKey, effort)
+}
+
+// reasoningEffortFromContext returns the effort set by ReasoningEffortContext,
+// or fallback when there is none.
+func reasoningEffortFromContext(ctx context.Context, fallback string) string {
+	if effort, ok := ctx.Value(reasoningEffortKey).(string); ok && effort != "" {
+		return effort
+	}
+	return fallback
+}
+
+type structuredOutputKeyType struct{}
+
+var structuredOutputKey = structuredOutputKeyType{}
+
+// StructuredOutputContext returns a context that asks for the next response
+// to be JSON matching schema, enforced the way each API supports natively:
+// response_format for OpenAI, responseJsonSchema for Gemini and a forced
+// struct_output tool call for Anthropic. Agents use it for the turn that
+// produces their final answer.
+func StructuredOutputContext(ctx context.Context, schema map[string]any) context.Context {
+	return context.WithValue(ctx, structuredOutputKey, schema)
+}
+
+// structuredOutputFromContext returns the schema set by
+// StructuredOutputContext, or nil.
+func structuredOutputFromContext(ctx context.Context) map[string]any {
+	schema, _ := ctx.Value(structuredOutputKey).(map[string]any)
+	return schema
+}
+
+type systemMessageKeyType struct{}
+
+var systemMessageKey = systemMessageKeyType{}
+
+// SystemMessageContext returns a context that replaces the configured
+// system message for requests made with it. The context inspector uses it
+// to leave excluded context files and skills out of a turn.
+func SystemMessageContext(ctx context.Context, systemMessage string) context.Context {
+	return context.WithValue(ctx, systemMessageKey, systemMessage)
+}
+
+// systemMessageFromContext returns the system message set by
+// SystemMessageContext, or fallback when there is none.
+func systemMessageFromContext(ctx context.Context, fallback string) string {
+	if systemMessage, ok := ctx.Value(systemMessageKey).(string); ok {
+		return systemMessage
+	}
+	return fallback
+}
+
 func WithBaseURL(baseURL string) ProviderClientOption {
 	return func(options *providerClientOptions) {
 		options.baseURL = baseURL
@@ -939,6 +1070,12 @@ func WithBedrockOptions(bedrockOptions ...BedrockOption) ProviderClientOption {
 	}
 }
 
+func WithOllamaOptions(ollamaOptions ...OllamaOption) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.ollamaOptions = ollamaOptions
+	}
+}
+
 func WithMetadata(metadata *config.ProviderMetadata) ProviderClientOption {
 	return func(options *providerClientOptions) {
 		options.metadata = metadata
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+func TestReasoningCost(t *testing.T) {
+	t.Parallel()
+	model := models.Model{CostPer1MIn: 10, CostPer1MOut: 20}
+	u := TokenUsage{OutputTokens: 1_000_000, ReasoningTokens: 250_000}
+	assert.InDelta(t, 5, ReasoningCost(model, u), 1e-9)
+	u.Batched = true
+	assert.InDelta(t, 2.5, ReasoningCost(model, u), 1e-9)
+	assert.Zero(t, ReasoningCost(model, TokenUsage{}))
+}
+
+func TestUsageReasoningTokens(t *testing.T) {
+	t.Parallel()
+	var completion openai.ChatCompletion
+	require.NoError(t, json.Unmarshal([]byte(`{"usage": {"prompt_tokens": 100, "completion_tokens": 40, "completion_tokens_details": {"reasoning_tokens": 30}}}`), &completion))
+	u := (&openaiClient{}).usage(completion)
+	assert.Equal(t, int64(40), u.OutputTokens)
+	assert.Equal(t, int64(30), u.ReasoningTokens)
+
+	// Gemini counts thoughts apart from the candidates, but bills them as
+	// output all the same.
+	u = (&geminiClient{}).usage(&genai.GenerateContentResponse{UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     100,
+		CandidatesTokenCount: 10,
+		ThoughtsTokenCount:   30,
+	}})
+	assert.Equal(t, int64(40), u.OutputTokens)
+	assert.Equal(t, int64(30), u.ReasoningTokens)
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// RetryPolicy controls how a provider client retries failed requests. The
+// zero value keeps each client's built-in behavior.
+type RetryPolicy struct {
+	// MaxRetries caps the retries after the first attempt. 0 means
+	// maxRetries; a negative value disables retrying.
+	MaxRetries int
+	// BaseDelay is the first backoff, doubled on every further retry. 0
+	// means the client's default.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff, Retry-After included. 0 means no cap.
+	MaxDelay time.Duration
+	// RetryOnStatus replaces the HTTP status codes the client retries.
+	RetryOnStatus []int
+	// IgnoreRetryAfter backs off on the policy's own schedule even when
+	// the provider sends a Retry-After header.
+	IgnoreRetryAfter bool
+}
+
+// RetryPolicyFromConfig converts a provider's retry config.
+func RetryPolicyFromConfig(cfg *config.RetryConfig) RetryPolicy {
+	if cfg == nil {
+		return RetryPolicy{}
+	}
+	return RetryPolicy{
+		MaxRetries:       cfg.MaxRetries,
+		BaseDelay:        time.Duration(cfg.BaseDelayMs) * time.Millisecond,
+		MaxDelay:         time.Duration(cfg.MaxDelayMs) * time.Millisecond,
+		RetryOnStatus:    cfg.RetryOnStatus,
+		IgnoreRetryAfter: cfg.RespectRetryAfter != nil && !*cfg.RespectRetryAfter,
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.retry = policy
+	}
+}
+
+// limit is the number of retries the policy allows.
+func (p RetryPolicy) limit() int {
+	switch {
+	case p.MaxRetries < 0:
+		return 0
+	case p.MaxRetries == 0:
+		return maxRetries
+	default:
+		return p.MaxRetries
+	}
+}
+
+// retriesStatus reports whether an HTTP status is retried, falling back to
+// the client's defaults when the policy names no statuses.
+func (p RetryPolicy) retriesStatus(status int, defaults ...int) bool {
+	if p.RetryOnStatus != nil {
+		return slices.Contains(p.RetryOnStatus, status)
+	}
+	return slices.Contains(defaults, status)
+}
+
+// backoff is the delay before retry number attempts (1-based): the base
+// delay doubled per retry plus 20% jitter, capped at MaxDelay.
+func (p RetryPolicy) backoff(attempts int, defaultBase time.Duration) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultBase
+	}
+	d := base * time.Duration(1<<(attempts-1))
+	d += d / 5
+	return p.capDelay(d)
+}
+
+// delay is backoff, replaced by the header's Retry-After seconds unless
+// the policy ignores them.
+func (p RetryPolicy) delay(attempts int, defaultBase time.Duration, header http.Header) time.Duration {
+	if !p.IgnoreRetryAfter && header != nil {
+		if secs, err := strconv.Atoi(header.Get("Retry-After")); err == nil && secs >= 0 {
+			return p.capDelay(time.Duration(secs) * time.Second)
+		}
+	}
+	return p.backoff(attempts, defaultBase)
+}
+
+func (p RetryPolicy) capDelay(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// retryMessage is the user-facing note for retry number attempts.
+func retryMessage(attempts, limit int) string {
+	return fmt.Sprintf("Retrying (%d/%d)…", attempts, limit)
+}
+
+// retryEvent announces a retry to a streaming consumer, which shows it to
+// the user for the duration of the wait.
+func retryEvent(attempts, limit int, after time.Duration, err error) ProviderEvent {
+	return ProviderEvent{Type: EventWarning, Content: retryMessage(attempts, limit), Error: err, RetryAfter: after}
+}
+
+// logRetry is the counterpart of retryEvent for non-streaming requests.
+func logRetry(attempts, limit int, after time.Duration) {
+	logging.WarnPersist(retryMessage(attempts, limit), logging.PersistTimeArg, after+100*time.Millisecond)
+}
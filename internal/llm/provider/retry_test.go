@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyLimit(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, maxRetries, RetryPolicy{}.limit())
+	assert.Equal(t, 3, RetryPolicy{MaxRetries: 3}.limit())
+	assert.Equal(t, 0, RetryPolicy{MaxRetries: -1}.limit())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+	p := RetryPolicy{}
+	assert.Equal(t, 2400*time.Millisecond, p.backoff(1, 2*time.Second))
+	assert.Equal(t, 4800*time.Millisecond, p.backoff(2, 2*time.Second))
+
+	p = RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	assert.Equal(t, 120*time.Millisecond, p.backoff(1, 2*time.Second))
+	assert.Equal(t, time.Second, p.backoff(6, 2*time.Second), "backoff is capped at MaxDelay")
+}
+
+func TestRetryPolicyDelayRetryAfter(t *testing.T) {
+	t.Parallel()
+	header := http.Header{"Retry-After": []string{"7"}}
+
+	assert.Equal(t, 7*time.Second, RetryPolicy{}.delay(1, 2*time.Second, header))
+	assert.Equal(t, 5*time.Second, RetryPolicy{MaxDelay: 5 * time.Second}.delay(1, 2*time.Second, header))
+	assert.Equal(t, 2400*time.Millisecond, RetryPolicy{IgnoreRetryAfter: true}.delay(1, 2*time.Second, header))
+	assert.Equal(t, 2400*time.Millisecond, RetryPolicy{}.delay(1, 2*time.Second, http.Header{}))
+}
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	t.Parallel()
+	no := false
+	p := RetryPolicyFromConfig(&config.RetryConfig{
+		MaxRetries:        2,
+		BaseDelayMs:       500,
+		MaxDelayMs:        10000,
+		RetryOnStatus:     []int{500},
+		RespectRetryAfter: &no,
+	})
+	assert.Equal(t, RetryPolicy{
+		MaxRetries:       2,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		RetryOnStatus:    []int{500},
+		IgnoreRetryAfter: true,
+	}, p)
+	assert.False(t, RetryPolicyFromConfig(&config.RetryConfig{}).IgnoreRetryAfter)
+	assert.Equal(t, RetryPolicy{}, RetryPolicyFromConfig(nil))
+}
+
+func TestShouldRetry_CustomPolicy(t *testing.T) {
+	t.Parallel()
+	a := &anthropicClient{providerOptions: providerClientOptions{retry: RetryPolicy{
+		MaxRetries:    2,
+		RetryOnStatus: []int{http.StatusInternalServerError},
+	}}}
+
+	retry, _, _ := a.shouldRetry(1, newAPIErrWithStatus(http.StatusInternalServerError, nil))
+	assert.True(t, retry, "configured status is retried")
+
+	retry, _, _ = a.shouldRetry(1, newAPIErrWithStatus(http.StatusTooManyRequests, nil))
+	assert.False(t, retry, "retryOnStatus replaces the default statuses")
+
+	retry, _, err := a.shouldRetry(3, newAPIErrWithStatus(http.StatusInternalServerError, nil))
+	assert.False(t, retry)
+	assert.ErrorContains(t, err, "2 retries")
+}
+
+func TestRetryEvent(t *testing.T) {
+	t.Parallel()
+	ev := retryEvent(3, 8, time.Second, nil)
+	assert.Equal(t, EventWarning, ev.Type)
+	assert.Equal(t, "Retrying (3/8)…", ev.Content)
+	assert.Equal(t, time.Second, ev.RetryAfter)
+}
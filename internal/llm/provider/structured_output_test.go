@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"google.golang.org/genai"
+)
+
+var testOutputSchema = map[string]any{
+	"type":       "object",
+	"properties": map[string]any{"answer": map[string]any{"type": "string"}},
+	"required":   []any{"answer"},
+}
+
+func TestStructuredOutputAnthropicForcesTool(t *testing.T) {
+	a := newReasoningTestClient(t)
+	a.providerOptions.model.SupportsAdaptiveThinking = true
+	msgs := []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("answer"))}
+	toolList := []anthropic.ToolUnionParam{
+		{OfTool: &anthropic.ToolParam{Name: "bash"}},
+		{OfTool: &anthropic.ToolParam{Name: tools.StructOutputToolName}},
+	}
+
+	params := a.preparedMessages(context.Background(), msgs, toolList)
+	if params.ToolChoice.OfTool != nil {
+		t.Fatal("tool choice must stay free without a structured output request")
+	}
+	if params.Thinking.OfAdaptive == nil {
+		t.Fatal("expected adaptive thinking on a plain turn")
+	}
+
+	ctx := StructuredOutputContext(context.Background(), testOutputSchema)
+	params = a.preparedMessages(ctx, msgs, toolList)
+	if params.ToolChoice.OfTool == nil || params.ToolChoice.OfTool.Name != tools.StructOutputToolName {
+		t.Fatalf("expected struct_output to be forced, got %+v", params.ToolChoice)
+	}
+	if params.Thinking.OfAdaptive != nil || params.Thinking.OfEnabled != nil {
+		t.Fatal("thinking must be off when a tool is forced")
+	}
+
+	// Without the struct_output tool there is nothing to force.
+	params = a.preparedMessages(ctx, msgs, toolList[:1])
+	if params.ToolChoice.OfTool != nil {
+		t.Fatalf("unexpected forced tool %+v", params.ToolChoice)
+	}
+}
+
+func TestStructuredOutputOpenAIResponseFormat(t *testing.T) {
+	o := &openaiClient{}
+	toolList := []openai.ChatCompletionToolParam{{Function: openai.FunctionDefinitionParam{Name: "bash"}}}
+
+	params := o.preparedParams(context.Background(), nil, toolList)
+	if params.ResponseFormat.OfJSONSchema != nil {
+		t.Fatal("response format must not be set without a structured output request")
+	}
+
+	params = o.preparedParams(StructuredOutputContext(context.Background(), testOutputSchema), nil, toolList)
+	if params.ResponseFormat.OfJSONSchema == nil {
+		t.Fatal("expected a json_schema response format")
+	}
+	if params.ResponseFormat.OfJSONSchema.JSONSchema.Schema == nil {
+		t.Fatal("expected the schema to be sent")
+	}
+	if params.ToolChoice.OfAuto.Value != "none" {
+		t.Fatalf("expected tool_choice none, got %+v", params.ToolChoice)
+	}
+}
+
+func TestStructuredOutputGeminiResponseSchema(t *testing.T) {
+	g := &geminiClient{}
+	toolList := []tools.BaseTool{tools.NewStructOutputTool(testOutputSchema)}
+
+	config := &genai.GenerateContentConfig{}
+	g.applyTools(context.Background(), config, toolList)
+	if len(config.Tools) == 0 || config.ResponseJsonSchema != nil {
+		t.Fatalf("expected tools only, got %+v", config)
+	}
+
+	config = &genai.GenerateContentConfig{}
+	g.applyTools(StructuredOutputContext(context.Background(), testOutputSchema), config, toolList)
+	if config.ResponseMIMEType != "application/json" || config.ResponseJsonSchema == nil {
+		t.Fatalf("expected a JSON response schema, got %+v", config)
+	}
+	if len(config.Tools) != 0 {
+		t.Fatal("tools must not be declared together with a JSON response type")
+	}
+}
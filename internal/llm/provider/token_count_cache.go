@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+
+	toolsPkg "github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+const (
+	// tokenCountRefreshShare triggers a count_tokens call once messages
+	// that were only estimated locally make up this share of the total.
+	tokenCountRefreshShare = 0.1
+	// tokenCountThresholdMargin triggers a count_tokens call when the
+	// cached total is within this share of the context window of the
+	// compaction threshold, so the compaction decision uses a measured
+	// count.
+	tokenCountThresholdMargin = 0.05
+	// maxTokenCountEntries bounds the cache; entries of messages missing
+	// from the current request are dropped once it is exceeded.
+	maxTokenCountEntries = 20_000
+)
+
+// tokenCountCache remembers per-message token counts keyed by model and
+// message ID, so CountTokens only has to account for messages that are new
+// or changed since the previous cycle instead of re-counting the whole
+// history.
+//
+// The count_tokens endpoint only reports request totals. After each call
+// the part of the total not covered by already measured messages is
+// attributed to the new ones in proportion to their local estimates. New
+// messages are estimated locally, scaled by the last endpoint/local ratio,
+// until they make up tokenCountRefreshShare of the total or the total nears
+// the compaction threshold; only then is the endpoint called again.
+type tokenCountCache struct {
+	mu      sync.Mutex
+	entries map[string]*tokenCountEntry
+	// overhead holds the measured tokens of the system prompt and tool
+	// schemas, keyed by their fingerprint.
+	overhead map[uint64]int64
+	// ratio is endpoint/local from the last measurement; zero until then.
+	ratio float64
+}
+
+type tokenCountEntry struct {
+	fingerprint uint64
+	local       int64
+	tokens      int64
+	// measured is set once tokens were attributed from an endpoint count.
+	measured bool
+}
+
+// tokenCountPlan is the cache's view of one request.
+type tokenCountPlan struct {
+	// total is the best count without calling the endpoint.
+	total int64
+	// local is the plain heuristic estimate, used as a floor.
+	local int64
+	// estimated is the part of total not backed by a measurement.
+	estimated int64
+}
+
+// needsRefresh reports whether the cached total is too uncertain to use.
+func (p tokenCountPlan) needsRefresh(contextWindow, thresholdAbs int64) bool {
+	if p.estimated > int64(float64(p.total)*tokenCountRefreshShare) {
+		return true
+	}
+	if contextWindow <= 0 {
+		return false
+	}
+	margin := int64(float64(contextWindow) * tokenCountThresholdMargin)
+	diff := p.total - thresholdAbs
+	return diff > -margin && diff < margin
+}
+
+func tokenCountKey(model string, msg message.Message) string {
+	if msg.ID == "" {
+		return ""
+	}
+	return model + "\x00" + msg.ID
+}
+
+// lookup builds the plan for messages, creating or refreshing the entries
+// of new and changed messages with their local estimate.
+func (c *tokenCountCache) lookup(model string, messages []message.Message, overheadKey uint64, overheadLocal int64) tokenCountPlan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*tokenCountEntry)
+		c.overhead = make(map[uint64]int64)
+	}
+	scale := c.ratio
+	if scale <= 0 {
+		scale = 1
+	}
+
+	var plan tokenCountPlan
+	if tokens, ok := c.overhead[overheadKey]; ok {
+		plan.total += tokens
+	} else {
+		estimate := int64(float64(overheadLocal) * scale)
+		plan.total += estimate
+		plan.estimated += estimate
+	}
+	plan.local += overheadLocal
+
+	for _, msg := range messages {
+		e := c.entry(model, msg)
+		if !e.measured {
+			e.tokens = int64(float64(e.local) * scale)
+			plan.estimated += e.tokens
+		}
+		plan.total += e.tokens
+		plan.local += e.local
+	}
+
+	if len(c.entries) > maxTokenCountEntries {
+		live := make(map[string]bool, len(messages))
+		for _, msg := range messages {
+			live[tokenCountKey(model, msg)] = true
+		}
+		for key := range c.entries {
+			if !live[key] {
+				delete(c.entries, key)
+			}
+		}
+	}
+	return plan
+}
+
+// entry returns the cache entry for msg, resetting it when the message
+// changed. Messages without an ID get a throwaway entry.
+func (c *tokenCountCache) entry(model string, msg message.Message) *tokenCountEntry {
+	fp := messageFingerprint(msg)
+	key := tokenCountKey(model, msg)
+	if e, ok := c.entries[key]; ok && key != "" && e.fingerprint == fp {
+		return e
+	}
+	e := &tokenCountEntry{
+		fingerprint: fp,
+		local:       message.EstimateTokens([]message.Message{msg}, nil, message.BytesPerTokenEta),
+	}
+	if key != "" {
+		c.entries[key] = e
+	}
+	return e
+}
+
+// record attributes an endpoint total for messages: measured messages and
+// overhead keep their counts and the remainder is split across the rest by
+// local estimate. If the remainder is negative (a compaction or a model
+// tokenizer change invalidated earlier counts), every count is rescaled.
+func (c *tokenCountCache) record(model string, messages []message.Message, overheadKey uint64, overheadLocal, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*tokenCountEntry)
+		c.overhead = make(map[uint64]int64)
+	}
+
+	entries := make([]*tokenCountEntry, len(messages))
+	localTotal := overheadLocal
+	rest := total
+	pendingLocal := int64(0)
+	overhead, overheadKnown := c.overhead[overheadKey]
+	if overheadKnown {
+		rest -= overhead
+	} else {
+		pendingLocal += overheadLocal
+	}
+	for i, msg := range messages {
+		e := c.entry(model, msg)
+		entries[i] = e
+		localTotal += e.local
+		if e.measured {
+			rest -= e.tokens
+		} else {
+			pendingLocal += e.local
+		}
+	}
+	if localTotal > 0 {
+		c.ratio = float64(total) / float64(localTotal)
+	}
+
+	if rest < 0 {
+		// Earlier attributions no longer add up; start over from the
+		// ratio of this measurement.
+		overheadKnown = false
+		pendingLocal = localTotal
+		rest = total
+		for _, e := range entries {
+			e.measured = false
+		}
+	}
+	share := func(local int64) int64 {
+		if pendingLocal == 0 {
+			return 0
+		}
+		return int64(float64(rest) * float64(local) / float64(pendingLocal))
+	}
+
+	assigned := int64(0)
+	for _, e := range entries {
+		if !e.measured {
+			e.tokens = share(e.local)
+			e.measured = true
+			assigned += e.tokens
+		}
+	}
+	// The overhead absorbs rounding and, when nothing was pending, any
+	// drift, so the cached total matches the measurement exactly.
+	if overheadKnown {
+		c.overhead[overheadKey] = overhead + rest - assigned
+	} else {
+		c.overhead[overheadKey] = rest - assigned
+	}
+}
+
+// messageFingerprint changes whenever a message's content does. It avoids
+// serialising the message: role, update time and the size of every part
+// are enough to notice edits between cycles.
+func messageFingerprint(msg message.Message) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	writeInt := func(v int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	h.Write([]byte(msg.Role))
+	writeInt(msg.UpdatedAt)
+	writeInt(int64(len(msg.Parts)))
+	for _, part := range msg.Parts {
+		switch p := part.(type) {
+		case message.TextContent:
+			writeInt(1)
+			writeInt(int64(len(p.Text)))
+		case message.ReasoningContent:
+			writeInt(2)
+			writeInt(int64(len(p.Thinking)))
+		case message.ToolCall:
+			writeInt(3)
+			h.Write([]byte(p.ID))
+			writeInt(int64(len(p.Input)))
+		case message.ToolResult:
+			writeInt(4)
+			h.Write([]byte(p.ToolCallID))
+			writeInt(int64(len(p.Content)))
+		case message.BinaryContent:
+			writeInt(5)
+			writeInt(int64(len(p.Data)))
+		case message.ImageURLContent:
+			writeInt(6)
+			h.Write([]byte(p.URL))
+		default:
+			writeInt(7)
+		}
+	}
+	return h.Sum64()
+}
+
+// overheadFingerprint identifies the system prompt and tool set of a
+// request, whose tokens are tracked apart from the messages.
+func overheadFingerprint(systemMessage string, tools []toolsPkg.BaseTool) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(systemMessage))
+	for _, t := range tools {
+		info := t.Info()
+		h.Write([]byte{0})
+		h.Write([]byte(info.Name))
+		h.Write([]byte(info.Description))
+	}
+	return h.Sum64()
+}
@@ -27,9 +27,13 @@ type BashParams struct {
 	// EnqueueTaskCompletion primitive.
 	//
 	// The 600s synchronous timeout cap does NOT apply when RunInBackground
-	// is true — the subprocess can run until natural exit, `taskstop`,
-	// opencode shutdown, or the pod's activeDeadlineSeconds.
+	// is true — the subprocess can run until natural exit, `taskstop` /
+	// `job_kill`, a user cancel of the session, opencode shutdown, or the
+	// pod's activeDeadlineSeconds.
 	RunInBackground bool `json:"run_in_background,omitempty"`
+	// DryRun reports the command instead of running it. Commands classified
+	// as read-only still run, since they are what a plan is built from.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 type BashPermissionsParams struct {
@@ -44,10 +48,12 @@ type BashResponseMetadata struct {
 	Description  string `json:"description,omitempty"`
 	ExitCode     int    `json:"exit_code"`
 	TempFilePath string `json:"temp_file_path,omitempty"`
+	DryRun       bool   `json:"dry_run,omitempty"`
 }
 type bashTool struct {
 	permissions permission.Service
 	registry    agentregistry.Registry
+	limits      OutputLimits
 }
 
 const (
@@ -69,13 +75,44 @@ var safeReadOnlyCommands = []string{
 	"go version", "go help", "go list", "go env", "go doc", "go vet", "go fmt", "go mod", "go test", "go build", "go run", "go install", "go clean",
 }
 
-func bashDescription() string {
+// powershellSafeReadOnlyCommands and cmdSafeReadOnlyCommands extend
+// safeReadOnlyCommands when the shell is PowerShell or cmd.
+var powershellSafeReadOnlyCommands = []string{
+	"Get-ChildItem", "gci", "dir", "Get-Location", "gl", "Get-Date", "Get-Process", "gps", "Get-Command", "gcm", "Get-Help",
+	"Get-Host", "Get-Item", "gi", "Get-ItemProperty", "Test-Path", "Resolve-Path", "Split-Path", "Join-Path", "Write-Output",
+	"Write-Host", "$PSVersionTable", "where.exe",
+}
+
+var cmdSafeReadOnlyCommands = []string{
+	"dir", "ver", "vol", "where", "date /t", "time /t", "tasklist", "systeminfo",
+}
+
+func bashDescription(limits OutputLimits) string {
 	r := strings.NewReplacer(
 		"${directory}", config.WorkingDirectory(),
-		"${maxBytes}", strconv.Itoa(MaxOutputBytes),
-		"${maxLines}", strconv.Itoa(MaxOutputLines),
+		"${maxBytes}", strconv.Itoa(limits.outputBytes()),
+		"${maxLines}", strconv.Itoa(limits.outputLines()),
 	)
-	return r.Replace(bashDescriptionTemplate)
+	return r.Replace(bashDescriptionTemplate) + dialectNote(shell.CurrentDialect())
+}
+
+// dialectNote tells the model which syntax to write when the shell is not
+// a POSIX one. The rest of the description still says bash, which is the
+// tool's name.
+func dialectNote(d shell.Dialect) string {
+	switch d {
+	case shell.DialectPowerShell:
+		return `
+
+# Shell
+Commands run in PowerShell, not bash. Write PowerShell: ` + "`;`" + ` separates commands, ` + "`$env:NAME`" + ` reads environment variables, and cmdlets such as Get-ChildItem, Select-String and Remove-Item replace ls, grep and rm. Quote paths with spaces with double quotes and start a command from a quoted path with the call operator: ` + "`& \"C:\\Program Files\\tool.exe\" --help`" + `. ` + "`&&`" + ` and ` + "`||`" + ` only work in PowerShell 7 (pwsh).`
+	case shell.DialectCmd:
+		return `
+
+# Shell
+Commands run in cmd.exe, not bash. Write batch syntax: ` + "`&&`" + ` chains commands, ` + "`%NAME%`" + ` reads environment variables, and dir, type, findstr, del and copy replace ls, cat, grep, rm and cp. Quote paths with spaces with double quotes. Commands run from a batch file, so write ` + "`%%i`" + ` for loop variables and ` + "`%%`" + ` for a literal percent sign.`
+	}
+	return ""
 }
 
 const bashDescriptionTemplate = `Executes a given bash command in a persistent shell session with optional timeout, ensuring proper handling and security measures.
@@ -192,17 +229,18 @@ Important:
 # Other common operations
 - View comments on a Github PR: gh api repos/foo/bar/pulls/123/comments`
 
-func NewBashTool(permission permission.Service, reg agentregistry.Registry) BaseTool {
+func NewBashTool(permission permission.Service, reg agentregistry.Registry, limits OutputLimits) BaseTool {
 	return &bashTool{
 		permissions: permission,
 		registry:    reg,
+		limits:      limits,
 	}
 }
 
 func (b *bashTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        BashToolName,
-		Description: bashDescription(),
+		Description: bashDescription(b.limits),
 		Parameters: map[string]any{
 			"command": map[string]any{
 				"type":        "string",
@@ -222,7 +260,11 @@ func (b *bashTool) Info() ToolInfo {
 			},
 			"run_in_background": map[string]any{
 				"type":        "boolean",
-				"description": "If true, start the command as a detached subprocess. The tool returns IMMEDIATELY with an ack containing a `task_id` and an `output_file` path. The subprocess keeps running; when it exits, a synthetic completion notification is automatically injected into this session (no polling — wait for the notification). Use this for long-running commands (test suites, builds, deploys) instead of `sleep` loops. The 600s timeout cap does NOT apply in background mode. Use the `tasklist` tool to inspect, and the `taskstop` tool to kill a background task.",
+				"description": "If true, start the command as a detached subprocess. The tool returns IMMEDIATELY with an ack containing a `task_id` and an `output_file` path. The subprocess keeps running; when it exits, a synthetic completion notification is automatically injected into this session (no polling — wait for the notification). Use this for long-running commands (test suites, builds, deploys) instead of `sleep` loops. The 600s timeout cap does NOT apply in background mode. The `task_id` is the job ID for `job_status`, `job_output` (read what a server or build has printed so far) and `job_kill`. Background jobs are killed when the user cancels the session.",
+			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": "If true, do not run the command: report what would be executed instead. Read-only commands (ls, cat, git status, ...) still run.",
 			},
 		},
 		Required: []string{"command", "description"},
@@ -249,13 +291,26 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if workdir == "" {
 		workdir = config.WorkingDirectory()
 	}
+	if err := checkSandbox(workdir); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
-	isSafeReadOnly := IsSafeReadOnlyCommand(params.Command)
+	// Naming a secret file takes a command through the permission registry,
+	// where the secret guard (and any allowSecrets opt-in) applies.
+	isSafeReadOnly := IsSafeReadOnlyCommand(params.Command) && !permission.CommandTouchesSecret(params.Command, nil)
 
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
 		return NewEmptyResponse(), fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
+	if IsReadOnly(ctx) && !IsReadOnlyCommand(params.Command) {
+		return NewTextErrorResponse(readOnlyRefusal), nil
+	}
+	if !isSafeReadOnly && IsDryRun(ctx, params.DryRun) {
+		return dryRunResponse(ctx, b.registry, BashToolName, params.Command,
+			fmt.Sprintf("Would run in %s:", workdir), params.Command,
+			BashResponseMetadata{Description: params.Description, DryRun: true})
+	}
 	if !isSafeReadOnly {
 		action := b.registry.EvaluatePermission(string(GetAgentID(ctx)), BashToolName, params.Command)
 		switch action {
@@ -269,6 +324,7 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 				permission.CreatePermissionRequest{
 					SessionID:   sessionID,
 					Path:        workdir,
+					Pattern:     params.Command,
 					ToolName:    BashToolName,
 					Action:      "execute",
 					Description: fmt.Sprintf("Execute command: %s", params.Command),
@@ -298,13 +354,13 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if sh == nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to create shell instance")
 	}
-	stdout, stderr, exitCode, interrupted, err := sh.Exec(ctx, params.Command, params.Timeout)
+	stdout, stderr, exitCode, interrupted, err := sh.ExecStream(ctx, params.Command, params.Timeout, bashOutputStreamer(sessionID, call.ID))
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error executing command: %w", err)
 	}
 
-	stdoutResult := persistAndTruncate(stdout, "stdout", BashToolName)
-	stderrResult := persistAndTruncate(stderr, "stderr", BashToolName)
+	stdoutResult := persistAndTruncate(stdout, "stdout", BashToolName, b.limits)
+	stderrResult := persistAndTruncate(stderr, "stderr", BashToolName, b.limits)
 
 	errorMessage := stderrResult.content
 	if interrupted {
@@ -363,7 +419,7 @@ type persistResult struct {
 	filePath string
 }
 
-func persistAndTruncate(content, label, tool string) persistResult {
+func persistAndTruncate(content, label, tool string, limits OutputLimits) persistResult {
 	if content == "" {
 		return persistResult{}
 	}
@@ -371,12 +427,12 @@ func persistAndTruncate(content, label, tool string) persistResult {
 	lines := strings.Split(content, "\n")
 	totalBytes := len(content)
 
-	if totalBytes <= MaxOutputBytes && len(lines) <= MaxOutputLines {
+	if totalBytes <= limits.outputBytes() && len(lines) <= limits.outputLines() {
 		return persistResult{content: content}
 	}
 
 	filePath := persistToTempFile(content, fmt.Sprintf("%s-%s", tool, label))
-	preview, totalLines := buildPreview(content, TruncatedHeadLines, TruncatedTailLines)
+	preview, totalLines := buildPreview(content, limits.previewLines(), limits.previewLines())
 	header := buildTruncationHeader(label, totalLines, filePath, totalBytes)
 
 	return persistResult{
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/task"
 )
@@ -30,8 +31,9 @@ func (b *bashTool) runBackground(_ context.Context, call ToolCall, params BashPa
 		return NewEmptyResponse(), fmt.Errorf("background bash: prepare output file: %w", err)
 	}
 
-	cmd := exec.Command("bash", "-c", params.Command)
-	cmd.Dir = workdir
+	// With the container backend the task gets a container of its own;
+	// `docker run` forwards the SIGTERM of a later taskstop into it.
+	cmd := shell.OneShotCommand(workdir, params.Command)
 	cmd.Stdout = outputFile
 	cmd.Stderr = outputFile
 	// The leaf bash becomes its own process-group leader so a later
@@ -68,14 +70,14 @@ func (b *bashTool) runBackground(_ context.Context, call ToolCall, params BashPa
 	// Detach from the caller's ctx — when the agent's turn ends, ctx is
 	// cancelled, but the subprocess must keep running. We use context.Background
 	// for the EnqueueTaskCompletion call as well.
-	go bashWaitAndNotify(cmd, outputFile, outputPath, sessionID, call.ID, taskID, syntheticInput, params)
+	go bashWaitAndNotify(cmd, outputFile, outputPath, sessionID, call.ID, taskID, syntheticInput, params, b.limits)
 
 	notice := ""
 	if params.Timeout != 0 && params.Timeout != DefaultTimeout {
 		notice = "\n(timeout parameter is ignored in background mode)"
 	}
 	body := fmt.Sprintf(
-		"Background task started.\ntask_id: %s\noutput_file: %s\ncommand: %s%s\n\nThe task is running. A synthetic tool result with the final output will arrive automatically when it completes — do NOT poll and do NOT sleep while waiting. In a non-interactive (flow) step the runtime holds the turn open until the task reaches a terminal state, so sleeping cannot observe progress sooner. The output_file holds the full output once the task finishes. Use `tasklist` for a one-shot inventory query and `taskstop` to kill.",
+		"Background task started.\ntask_id: %s\noutput_file: %s\ncommand: %s%s\n\nThe task is running. A synthetic tool result with the final output will arrive automatically when it completes — do NOT poll and do NOT sleep while waiting. In a non-interactive (flow) step the runtime holds the turn open until the task reaches a terminal state, so sleeping cannot observe progress sooner. For a server or watcher that does not exit on its own, use `job_output` with this task_id to read what it has printed so far and `job_kill` to stop it when done. The output_file holds the full output once the task finishes. Use `tasklist` for a one-shot inventory query.",
 		taskID,
 		outputPath,
 		truncateCommand(params.Command),
@@ -89,7 +91,7 @@ func (b *bashTool) runBackground(_ context.Context, call ToolCall, params BashPa
 // bash output budget), and calls EnqueueTaskCompletion with the resulting
 // status and content. The function honors the registry's `notified` dedupe
 // flag indirectly via SuppressIfNotified=true.
-func bashWaitAndNotify(cmd *exec.Cmd, outputFile *os.File, outputPath, sessionID, callID, taskID, syntheticInput string, _ BashParams) {
+func bashWaitAndNotify(cmd *exec.Cmd, outputFile *os.File, outputPath, sessionID, callID, taskID, syntheticInput string, _ BashParams, limits OutputLimits) {
 	defer logging.RecoverPanic("bash.runBackground.wait", nil)
 	err := cmd.Wait()
 	_ = outputFile.Sync()
@@ -105,7 +107,7 @@ func bashWaitAndNotify(cmd *exec.Cmd, outputFile *os.File, outputPath, sessionID
 	}
 
 	raw, _ := os.ReadFile(outputPath)
-	out := persistAndTruncate(string(raw), "stdout", BashToolName)
+	out := persistAndTruncate(string(raw), "stdout", BashToolName, limits)
 	content := out.content
 	if exitCode != 0 {
 		if content != "" {
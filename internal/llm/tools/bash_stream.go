@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// BashOutput is output a foreground bash command printed while it was still
+// running. It is only meant for display: the model gets the complete,
+// truncated output in the tool result once the command exits.
+type BashOutput struct {
+	SessionID  string
+	ToolCallID string
+	Chunk      string
+}
+
+var bashOutputBroker = pubsub.NewBroker[BashOutput]()
+
+// SubscribeBashOutput streams the output of running bash commands.
+func SubscribeBashOutput(ctx context.Context) <-chan pubsub.Event[BashOutput] {
+	return bashOutputBroker.Subscribe(ctx)
+}
+
+// bashOutputStreamer returns the callback that publishes the output of the
+// given call, or nil when nobody listens so the shell skips following it.
+func bashOutputStreamer(sessionID, callID string) func(string) {
+	if bashOutputBroker.GetSubscriberCount() == 0 {
+		return nil
+	}
+	return func(chunk string) {
+		bashOutputBroker.Publish(pubsub.UpdatedEvent, BashOutput{
+			SessionID:  sessionID,
+			ToolCallID: callID,
+			Chunk:      chunk,
+		})
+	}
+}
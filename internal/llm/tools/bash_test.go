@@ -76,7 +76,7 @@ func TestPersistAndTruncate(t *testing.T) {
 	})
 
 	t.Run("empty content returns empty result", func(t *testing.T) {
-		result := persistAndTruncate("", "stdout", BashToolName)
+		result := persistAndTruncate("", "stdout", BashToolName, OutputLimits{})
 		if result.content != "" {
 			t.Errorf("expected empty content, got %q", result.content)
 		}
@@ -86,7 +86,7 @@ func TestPersistAndTruncate(t *testing.T) {
 	})
 
 	t.Run("small content returned unchanged", func(t *testing.T) {
-		result := persistAndTruncate("hello world", "stdout", BashToolName)
+		result := persistAndTruncate("hello world", "stdout", BashToolName, OutputLimits{})
 		if result.content != "hello world" {
 			t.Errorf("expected unchanged content, got %q", result.content)
 		}
@@ -102,7 +102,7 @@ func TestPersistAndTruncate(t *testing.T) {
 		}
 		content := strings.Join(lines, "\n")
 
-		result := persistAndTruncate(content, "stdout", BashToolName)
+		result := persistAndTruncate(content, "stdout", BashToolName, OutputLimits{})
 
 		if result.filePath == "" {
 			t.Fatal("expected temp file to be created")
@@ -141,7 +141,7 @@ func TestPersistAndTruncate(t *testing.T) {
 			t.Skip("test content not large enough")
 		}
 
-		result := persistAndTruncate(content, "stderr", BashToolName)
+		result := persistAndTruncate(content, "stderr", BashToolName, OutputLimits{})
 
 		if result.filePath == "" {
 			t.Fatal("expected temp file to be created")
@@ -158,7 +158,7 @@ func TestPersistAndTruncate(t *testing.T) {
 		}
 		content := strings.Join(lines, "\n")
 
-		result := persistAndTruncate(content, "stdout", BashToolName)
+		result := persistAndTruncate(content, "stdout", BashToolName, OutputLimits{})
 
 		if result.filePath == "" {
 			t.Fatal("expected temp file to be created")
@@ -207,7 +207,7 @@ func TestCleanupTempDir(t *testing.T) {
 	}
 	content := strings.Join(lines, "\n")
 
-	result := persistAndTruncate(content, "stdout", BashToolName)
+	result := persistAndTruncate(content, "stdout", BashToolName, OutputLimits{})
 	if result.filePath == "" {
 		t.Fatal("expected temp file to be created")
 	}
@@ -235,7 +235,7 @@ func TestBashRun_InterceptsSleepEndToEnd(t *testing.T) {
 		reg.MarkFinished(taskID, task.StateCompleted, nil)
 	}()
 
-	bash := NewBashTool(&allowAllPerms{}, agentregistry.GetRegistry())
+	bash := NewBashTool(&allowAllPerms{}, agentregistry.GetRegistry(), OutputLimits{})
 	start := time.Now()
 	resp, err := bash.Run(waitFixtureCtx(true), ToolCall{
 		ID:    "call-1",
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/blackboard"
+)
+
+const (
+	BlackboardPostToolName = "blackboard_post"
+	BlackboardReadToolName = "blackboard_read"
+
+	maxBlackboardWait = 120 * time.Second
+)
+
+// BlackboardService is the interface the blackboard tools require.
+type BlackboardService interface {
+	Post(ctx context.Context, sessionID, agent, topic, content string) (blackboard.Entry, error)
+	Query(ctx context.Context, sessionID string, q blackboard.Query) ([]blackboard.Entry, error)
+}
+
+type blackboardPostTool struct {
+	board BlackboardService
+}
+
+type blackboardPostParams struct {
+	Topic   string `json:"topic"`
+	Content string `json:"content"`
+}
+
+func NewBlackboardPostTool(board BlackboardService) BaseTool {
+	return &blackboardPostTool{board: board}
+}
+
+func (t *blackboardPostTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: BlackboardPostToolName,
+		Description: `Post a finding to the blackboard shared by every agent working on the current task tree (the main session and all of its subagents).
+
+## When to use
+- You discovered something other agents working in parallel need: an API shape, the root cause of a failure, a file you are about to change, a decision you made
+- You finished a unit of work and want the coordinator to see the result before your final report
+- You are blocked on information another agent may have — post the question under a clear topic
+
+## Rules
+- Keep entries short and self-contained; include file paths and symbol names rather than prose
+- Use a stable topic (e.g. "api-schema", "test-failures") so readers can filter on it
+- Don't post progress chatter; post facts others can act on`,
+		Parameters: map[string]any{
+			"topic": map[string]any{
+				"type":        "string",
+				"description": "Short topic used to group related entries",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "The finding to share",
+			},
+		},
+		Required: []string{"content"},
+	}
+}
+
+func (t *blackboardPostTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params blackboardPostParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return NewTextErrorResponse("session context required"), nil
+	}
+
+	entry, err := t.board.Post(ctx, sessionID, string(GetAgentID(ctx)), params.Topic, params.Content)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return NewTextResponse(fmt.Sprintf("Posted entry %s to the blackboard.", entry.ID)), nil
+}
+
+func (t *blackboardPostTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return true
+}
+
+func (t *blackboardPostTool) IsBaseline() bool { return true }
+
+type blackboardReadTool struct {
+	board BlackboardService
+}
+
+type blackboardReadParams struct {
+	Topic       string `json:"topic"`
+	Query       string `json:"query"`
+	Agent       string `json:"agent"`
+	After       string `json:"after"`
+	Limit       int    `json:"limit"`
+	WaitSeconds int    `json:"wait_seconds"`
+}
+
+func NewBlackboardReadTool(board BlackboardService) BaseTool {
+	return &blackboardReadTool{board: board}
+}
+
+func (t *blackboardReadTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: BlackboardReadToolName,
+		Description: `Read the blackboard shared by every agent working on the current task tree. Entries are returned oldest first with their ID, topic, posting agent and content.
+
+## When to use
+- Before starting delegated work, to pick up what other agents already found
+- As a coordinator, to collect findings from subagents running in parallel
+- To wait for another agent's result: set wait_seconds and, to skip entries you already read, pass the ID of the last entry you saw as after`,
+		Parameters: map[string]any{
+			"topic": map[string]any{
+				"type":        "string",
+				"description": "Only entries with this topic",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Only entries whose topic or content contains this text (case-insensitive)",
+			},
+			"agent": map[string]any{
+				"type":        "string",
+				"description": "Only entries posted by this agent",
+			},
+			"after": map[string]any{
+				"type":        "string",
+				"description": "Only entries posted after the entry with this ID",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Return only the most recent N matching entries",
+			},
+			"wait_seconds": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("If nothing matches yet, wait up to this many seconds (max %d) for a matching entry", int(maxBlackboardWait.Seconds())),
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *blackboardReadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params blackboardReadParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return NewTextErrorResponse("session context required"), nil
+	}
+
+	wait := time.Duration(params.WaitSeconds) * time.Second
+	if wait > maxBlackboardWait {
+		wait = maxBlackboardWait
+	}
+	entries, err := t.board.Query(ctx, sessionID, blackboard.Query{
+		Topic:    params.Topic,
+		Contains: params.Query,
+		Agent:    params.Agent,
+		After:    params.After,
+		Limit:    params.Limit,
+		Wait:     wait,
+	})
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(entries) == 0 {
+		return NewTextResponse("No matching blackboard entries."), nil
+	}
+
+	var sb strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "[%s] %s", e.ID, time.Unix(e.CreatedAt, 0).Format(time.TimeOnly))
+		if e.Agent != "" {
+			fmt.Fprintf(&sb, " %s", e.Agent)
+		}
+		if e.Topic != "" {
+			fmt.Fprintf(&sb, " #%s", e.Topic)
+		}
+		sb.WriteString("\n")
+		sb.WriteString(e.Content)
+		sb.WriteString("\n")
+	}
+	return NewTextResponse(sb.String()), nil
+}
+
+// AllowParallelism lets a waiting read run alongside other tool calls
+// instead of holding them up.
+func (t *blackboardReadTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return true
+}
+
+func (t *blackboardReadTool) IsBaseline() bool { return true }
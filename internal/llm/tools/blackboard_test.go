@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/blackboard"
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+type fakeBlackboard struct {
+	posted    []blackboard.Entry
+	lastQuery blackboard.Query
+	entries   []blackboard.Entry
+}
+
+func (f *fakeBlackboard) Post(_ context.Context, sessionID, agent, topic, content string) (blackboard.Entry, error) {
+	e := blackboard.Entry{ID: "e1", SessionID: sessionID, Agent: agent, Topic: topic, Content: content}
+	f.posted = append(f.posted, e)
+	return e, nil
+}
+
+func (f *fakeBlackboard) Query(_ context.Context, _ string, q blackboard.Query) ([]blackboard.Entry, error) {
+	f.lastQuery = q
+	return f.entries, nil
+}
+
+func TestBlackboardPostUsesCallerSessionAndAgent(t *testing.T) {
+	board := &fakeBlackboard{}
+	tool := NewBlackboardPostTool(board)
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "sess_child")
+	ctx = context.WithValue(ctx, AgentIDContextKey, config.AgentName("explorer"))
+	resp, err := tool.Run(ctx, ToolCall{Input: `{"topic":"api","content":"routes in server.go"}`})
+	if err != nil || resp.IsError {
+		t.Fatalf("run: err=%v resp=%+v", err, resp)
+	}
+	if len(board.posted) != 1 {
+		t.Fatalf("posted %d entries, want 1", len(board.posted))
+	}
+	got := board.posted[0]
+	if got.SessionID != "sess_child" || got.Agent != "explorer" || got.Topic != "api" {
+		t.Fatalf("posted entry = %+v", got)
+	}
+
+	resp, _ = tool.Run(context.Background(), ToolCall{Input: `{"content":"x"}`})
+	if !resp.IsError {
+		t.Fatal("post without a session should fail")
+	}
+}
+
+func TestBlackboardReadFormatsEntriesAndCapsWait(t *testing.T) {
+	board := &fakeBlackboard{entries: []blackboard.Entry{
+		{ID: "e1", Agent: "explorer", Topic: "api", Content: "routes in server.go", CreatedAt: time.Now().Unix()},
+	}}
+	tool := NewBlackboardReadTool(board)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "sess_root")
+
+	resp, err := tool.Run(ctx, ToolCall{Input: `{"topic":"api","after":"e0","limit":3,"wait_seconds":900}`})
+	if err != nil || resp.IsError {
+		t.Fatalf("run: err=%v resp=%+v", err, resp)
+	}
+	for _, want := range []string{"[e1]", "explorer", "#api", "routes in server.go"} {
+		if !strings.Contains(resp.Content, want) {
+			t.Fatalf("response %q missing %q", resp.Content, want)
+		}
+	}
+	q := board.lastQuery
+	if q.Topic != "api" || q.After != "e0" || q.Limit != 3 || q.Wait != maxBlackboardWait {
+		t.Fatalf("query = %+v", q)
+	}
+
+	board.entries = nil
+	resp, _ = tool.Run(ctx, ToolCall{Input: `{}`})
+	if resp.Content != "No matching blackboard entries." {
+		t.Fatalf("empty board response = %q", resp.Content)
+	}
+}
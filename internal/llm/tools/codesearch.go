@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/codesearch"
+)
+
+const (
+	CodeSearchToolName = "codesearch"
+
+	defaultCodeSearchLimit = 8
+	maxCodeSearchLimit     = 30
+	// codeSearchPreviewLines caps each snippet; read the file for more.
+	codeSearchPreviewLines = 30
+)
+
+// CodeSearchService is the interface the codesearch tool requires.
+type CodeSearchService interface {
+	Search(ctx context.Context, query, pathPrefix string, limit int) ([]codesearch.Result, error)
+}
+
+type CodeSearchParams struct {
+	Query string `json:"query"`
+	Path  string `json:"path,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type CodeSearchResponseMetadata struct {
+	Results []codesearch.Result `json:"results"`
+}
+
+type codeSearchTool struct {
+	index CodeSearchService
+}
+
+// NewCodeSearchTool answers natural-language queries from index.
+func NewCodeSearchTool(index CodeSearchService) BaseTool {
+	return &codeSearchTool{index: index}
+}
+
+func (t *codeSearchTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: CodeSearchToolName,
+		Description: `Semantic search over the workspace: finds the code that best matches a natural-language description, even when it shares no words with the query.
+
+WHEN TO USE THIS TOOL:
+- You know what the code does but not what it is called ("where are retries on rate limits handled", "code that parses the config file")
+- You are exploring an unfamiliar area and want the most relevant files to read first
+
+WHEN NOT TO USE IT:
+- You know an exact identifier, string or pattern; grep is faster and exhaustive
+- You need every occurrence; this returns only the closest matches
+
+HOW TO USE:
+- Describe the behaviour in a sentence rather than listing keywords
+- Results are ranked snippets with file paths and line ranges; read the file for the full context
+- Set path to restrict the search to a directory
+- The index follows edits made in this session; files changed outside it are picked up on the next start`,
+		Parameters: map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "What the code you are looking for does, in natural language",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Only search files under this directory, relative to the working directory",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of snippets to return (default %d, max %d)", defaultCodeSearchLimit, maxCodeSearchLimit),
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+func (t *codeSearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params CodeSearchParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if strings.TrimSpace(params.Query) == "" {
+		return NewTextErrorResponse("query is required"), nil
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultCodeSearchLimit
+	}
+	limit = min(limit, maxCodeSearchLimit)
+
+	prefix := strings.TrimSuffix(strings.TrimPrefix(params.Path, "./"), "/")
+	if prefix == "." {
+		prefix = ""
+	} else if prefix != "" {
+		prefix += "/"
+	}
+
+	results, err := t.index.Search(ctx, params.Query, prefix, limit)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("code search failed: %s", err)), nil
+	}
+	if len(results) == 0 {
+		return NewTextResponse("No matching code found"), nil
+	}
+
+	var sb strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s:%d-%d (score %.2f)\n", r.Path, r.StartLine, r.EndLine, r.Score)
+		lines := strings.Split(r.Content, "\n")
+		if len(lines) > codeSearchPreviewLines {
+			lines = append(lines[:codeSearchPreviewLines], "...")
+		}
+		sb.WriteString("```\n")
+		sb.WriteString(strings.Join(lines, "\n"))
+		sb.WriteString("\n```\n")
+	}
+	return WithResponseMetadata(
+		NewTextResponse(sb.String()),
+		CodeSearchResponseMetadata{Results: results},
+	), nil
+}
+
+func (t *codeSearchTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return true
+}
+
+func (t *codeSearchTool) IsBaseline() bool { return true }
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/codesearch"
+)
+
+type stubCodeIndex struct {
+	query, prefix string
+	limit         int
+	results       []codesearch.Result
+}
+
+func (s *stubCodeIndex) Search(_ context.Context, query, pathPrefix string, limit int) ([]codesearch.Result, error) {
+	s.query, s.prefix, s.limit = query, pathPrefix, limit
+	return s.results, nil
+}
+
+func TestCodeSearchTool(t *testing.T) {
+	index := &stubCodeIndex{results: []codesearch.Result{
+		{Path: "internal/config/load.go", StartLine: 10, EndLine: 12, Score: 0.876, Content: "func Load() {\n\tparse()\n}"},
+	}}
+	tool := NewCodeSearchTool(index)
+
+	resp, err := tool.Run(context.Background(), ToolCall{Input: `{"query":"where is config loaded","path":"./internal/","limit":100}`})
+	if err != nil || resp.IsError {
+		t.Fatalf("run: err=%v resp=%+v", err, resp)
+	}
+	if index.prefix != "internal/" || index.limit != maxCodeSearchLimit {
+		t.Errorf("search called with prefix %q limit %d", index.prefix, index.limit)
+	}
+	if !strings.Contains(resp.Content, "internal/config/load.go:10-12 (score 0.88)") || !strings.Contains(resp.Content, "\tparse()") {
+		t.Errorf("unexpected output:\n%s", resp.Content)
+	}
+	var meta CodeSearchResponseMetadata
+	if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil || len(meta.Results) != 1 {
+		t.Errorf("metadata = %q, %v", resp.Metadata, err)
+	}
+
+	resp, _ = tool.Run(context.Background(), ToolCall{Input: `{"query":"  "}`})
+	if !resp.IsError {
+		t.Error("empty query should be rejected")
+	}
+
+	index.results = nil
+	resp, _ = tool.Run(context.Background(), ToolCall{Input: `{"query":"anything"}`})
+	if resp.Content != "No matching code found" || index.limit != defaultCodeSearchLimit || index.prefix != "" {
+		t.Errorf("empty result = %q (limit %d, prefix %q)", resp.Content, index.limit, index.prefix)
+	}
+}
@@ -17,7 +17,8 @@ import (
 )
 
 type DeleteParams struct {
-	Path string `json:"path"`
+	Path   string `json:"path"`
+	DryRun bool   `json:"dry_run,omitempty"`
 }
 
 type DeletePermissionsParams struct {
@@ -29,6 +30,7 @@ type DeleteResponseMetadata struct {
 	Diff         string `json:"diff"`
 	Removals     int    `json:"removals"`
 	FilesDeleted int    `json:"files_deleted"`
+	DryRun       bool   `json:"dry_run,omitempty"`
 }
 
 type deleteTool struct {
@@ -86,6 +88,10 @@ func (d *deleteTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The path to the file or directory to delete",
 			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": dryRunParamDescription,
+			},
 		},
 		Required: []string{"path"},
 	}
@@ -105,6 +111,9 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 	if !filepath.IsAbs(absPath) {
 		absPath = filepath.Join(config.WorkingDirectory(), absPath)
 	}
+	if err := checkSandbox(absPath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
 	fileInfo, err := os.Lstat(absPath)
 	if err != nil {
@@ -132,6 +141,12 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 
 		diffStr, _, removals := diff.GenerateDiff(string(content), "", absPath)
 
+		if IsDryRun(ctx, params.DryRun) {
+			return dryRunResponse(ctx, d.registry, DeleteToolName, absPath,
+				fmt.Sprintf("Would delete file %s (-%d).", absPath, removals), diffStr,
+				DeleteResponseMetadata{Diff: diffStr, Removals: removals, FilesDeleted: 1, DryRun: true})
+		}
+
 		action := d.registry.EvaluatePermission(string(GetAgentID(ctx)), DeleteToolName, absPath)
 		switch action {
 		case permission.ActionAllow:
@@ -142,6 +157,7 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 				permission.CreatePermissionRequest{
 					SessionID:   sessionID,
 					Path:        filepath.Dir(absPath),
+					Pattern:     absPath,
 					ToolName:    DeleteToolName,
 					Action:      "delete",
 					Description: fmt.Sprintf("Delete file %s", absPath),
@@ -156,6 +172,11 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 			}
 		}
 
+		if err := chargeDiffBudget(ctx, d.permissions, DeleteToolName, []string{absPath}, removals); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+
+		checkpointFile(ctx, d.files, absPath)
 		err = os.Remove(absPath)
 		if err != nil {
 			return NewEmptyResponse(), fmt.Errorf("error deleting file: %w", err)
@@ -233,6 +254,16 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 		return NewTextErrorResponse("directory contains more than 500 files. Use bash rm -rf for large directory deletions, or delete subdirectories individually"), nil
 	}
 
+	if IsDryRun(ctx, params.DryRun) {
+		var listing strings.Builder
+		for _, f := range files {
+			listing.WriteString(f.path + "\n")
+		}
+		return dryRunResponse(ctx, d.registry, DeleteToolName, absPath,
+			fmt.Sprintf("Would delete directory %s with %d files (-%d):", absPath, len(files), totalRemovals), listing.String(),
+			DeleteResponseMetadata{Removals: totalRemovals, FilesDeleted: len(files), DryRun: true})
+	}
+
 	action := d.registry.EvaluatePermission(string(GetAgentID(ctx)), DeleteToolName, absPath)
 	switch action {
 	case permission.ActionAllow:
@@ -243,6 +274,7 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        filepath.Dir(absPath),
+				Pattern:     absPath,
 				ToolName:    DeleteToolName,
 				Action:      "delete",
 				Description: fmt.Sprintf("Delete directory %s (%d files)", absPath, len(files)),
@@ -257,6 +289,17 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 		}
 	}
 
+	deleted := make([]string, len(files))
+	for i, f := range files {
+		deleted[i] = f.path
+	}
+	if err := chargeDiffBudget(ctx, d.permissions, DeleteToolName, deleted, totalRemovals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	for _, f := range files {
+		checkpointFile(ctx, d.files, f.path)
+	}
 	err = os.RemoveAll(absPath)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error deleting directory: %w", err)
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// DiffBudgetToolName is the tool name diff budget confirmations are raised
+// under.
+const DiffBudgetToolName = "diff_budget"
+
+// DiffBudget is one turn's allowance of diffBudget: the files and lines
+// the write tools may still change before the user has to confirm. The
+// agent installs one per run with WithDiffBudget; subagents spend their
+// caller's through ctx.
+type DiffBudget struct {
+	maxFiles int
+	maxLines int
+	deny     bool
+
+	// mu is held across the confirmation prompt, so parallel calls that
+	// go over the budget together are asked about once.
+	mu        sync.Mutex
+	files     map[string]struct{}
+	lines     int
+	fileLimit int
+	lineLimit int
+}
+
+// NewDiffBudget returns the allowance cfg describes, or nil when cfg sets
+// no limit.
+func NewDiffBudget(cfg *config.DiffBudgetConfig) *DiffBudget {
+	if cfg == nil || (cfg.MaxFiles <= 0 && cfg.MaxLines <= 0) {
+		return nil
+	}
+	return &DiffBudget{
+		maxFiles:  cfg.MaxFiles,
+		maxLines:  cfg.MaxLines,
+		deny:      cfg.Action == config.DiffBudgetDeny,
+		files:     make(map[string]struct{}),
+		fileLimit: cfg.MaxFiles,
+		lineLimit: cfg.MaxLines,
+	}
+}
+
+// WithDiffBudget installs b on the tool-execution ctx of a run. A ctx that
+// already carries a budget keeps it: a subagent's changes count against
+// the turn that started it.
+func WithDiffBudget(ctx context.Context, b *DiffBudget) context.Context {
+	if b == nil {
+		return ctx
+	}
+	if _, ok := ctx.Value(DiffBudgetContextKey).(*DiffBudget); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, DiffBudgetContextKey, b)
+}
+
+// chargeDiffBudget counts a change to paths of lines added plus removed
+// against the turn's budget before a write tool applies it. A change that
+// would go over the budget is put to the user, even in auto-approve
+// sessions; the error is returned when it is refused, when diffBudget.action
+// is "deny", or when the run has no user to ask. Once confirmed, the turn
+// may change another budget's worth before the next confirmation.
+func chargeDiffBudget(ctx context.Context, perms permission.Service, toolName string, paths []string, lines int) error {
+	b, _ := ctx.Value(DiffBudgetContextKey).(*DiffBudget)
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var added []string
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(config.WorkingDirectory(), p)
+		}
+		if _, ok := b.files[p]; !ok && !slices.Contains(added, p) {
+			added = append(added, p)
+		}
+	}
+	files := len(b.files) + len(added)
+	total := b.lines + lines
+	overFiles := b.fileLimit > 0 && files > b.fileLimit
+	overLines := b.lineLimit > 0 && total > b.lineLimit
+	if !overFiles && !overLines {
+		b.record(added, lines)
+		return nil
+	}
+
+	summary := b.describe(files, total)
+	sessionID, _ := GetContextValues(ctx)
+	if b.deny || perms == nil || sessionID == "" || IsNonInteractive(ctx) {
+		return fmt.Errorf("diff budget exceeded: this turn would change %s. The change was not applied; make smaller, targeted edits, or ask the user to raise diffBudget or to continue in a new turn", summary)
+	}
+	allowed := perms.Request(ctx, permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolName:    DiffBudgetToolName,
+		Action:      "exceed",
+		Path:        config.WorkingDirectory(),
+		Confirm:     true,
+		Description: fmt.Sprintf("%s would take this turn to %s. Apply it anyway?\n\n%s", toolName, summary, describePaths(paths)),
+	})
+	if !allowed {
+		return fmt.Errorf("diff budget exceeded: the user declined letting this turn change %s. The change was not applied", summary)
+	}
+	b.record(added, lines)
+	if b.maxFiles > 0 {
+		b.fileLimit = files + b.maxFiles
+	}
+	if b.maxLines > 0 {
+		b.lineLimit = total + b.maxLines
+	}
+	return nil
+}
+
+func (b *DiffBudget) record(added []string, lines int) {
+	for _, p := range added {
+		b.files[p] = struct{}{}
+	}
+	b.lines += lines
+}
+
+// describe renders the turn's totals against the limits in force.
+func (b *DiffBudget) describe(files, lines int) string {
+	var parts []string
+	if b.fileLimit > 0 {
+		parts = append(parts, fmt.Sprintf("%d files (limit %d)", files, b.fileLimit))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d files", files))
+	}
+	if b.lineLimit > 0 {
+		parts = append(parts, fmt.Sprintf("%d lines (limit %d)", lines, b.lineLimit))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d lines", lines))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// describePaths lists the files of a change relative to the working
+// directory, at most ten of them.
+func describePaths(paths []string) string {
+	const maxShown = 10
+	rel := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if r, err := filepath.Rel(config.WorkingDirectory(), p); err == nil && !strings.HasPrefix(r, "..") {
+			p = r
+		}
+		rel = append(rel, p)
+	}
+	sort.Strings(rel)
+	var sb strings.Builder
+	for i, p := range rel {
+		if i == maxShown {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(rel)-maxShown)
+			break
+		}
+		fmt.Fprintf(&sb, "- %s\n", p)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// recordingPerms answers every request with allow and keeps it.
+type recordingPerms struct {
+	mockPermissionService
+	allow    bool
+	requests []permission.CreatePermissionRequest
+}
+
+func (r *recordingPerms) Request(_ context.Context, opts permission.CreatePermissionRequest) bool {
+	r.requests = append(r.requests, opts)
+	return r.allow
+}
+
+func diffBudgetCtx(t *testing.T, cfg *config.DiffBudgetConfig) context.Context {
+	t.Helper()
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "sess")
+	return WithDiffBudget(ctx, NewDiffBudget(cfg))
+}
+
+func TestDiffBudgetAsksOnceOverLimit(t *testing.T) {
+	ctx := diffBudgetCtx(t, &config.DiffBudgetConfig{MaxFiles: 2, MaxLines: 100})
+	perms := &recordingPerms{allow: true}
+
+	if err := chargeDiffBudget(ctx, perms, EditToolName, []string{"/w/a.go"}, 40); err != nil {
+		t.Fatalf("first change: %v", err)
+	}
+	// The same file again only adds lines.
+	if err := chargeDiffBudget(ctx, perms, EditToolName, []string{"/w/a.go"}, 40); err != nil {
+		t.Fatalf("second change: %v", err)
+	}
+	if len(perms.requests) != 0 {
+		t.Fatalf("asked within budget: %+v", perms.requests)
+	}
+
+	if err := chargeDiffBudget(ctx, perms, WriteToolName, []string{"/w/b.go"}, 30); err != nil {
+		t.Fatalf("confirmed change: %v", err)
+	}
+	if len(perms.requests) != 1 {
+		t.Fatalf("got %d confirmations, want 1", len(perms.requests))
+	}
+	req := perms.requests[0]
+	if !req.Confirm || req.ToolName != DiffBudgetToolName || !strings.Contains(req.Description, "110 lines (limit 100)") {
+		t.Fatalf("confirmation = %+v", req)
+	}
+
+	// After confirming, the turn gets another budget's worth.
+	if err := chargeDiffBudget(ctx, perms, WriteToolName, []string{"/w/b.go"}, 90); err != nil {
+		t.Fatalf("change within the raised budget: %v", err)
+	}
+	if len(perms.requests) != 1 {
+		t.Fatalf("asked again within the raised budget")
+	}
+}
+
+func TestDiffBudgetRefuses(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   *config.DiffBudgetConfig
+		allow bool
+		ctx   func(context.Context) context.Context
+	}{
+		{name: "deny action", cfg: &config.DiffBudgetConfig{MaxFiles: 1, Action: config.DiffBudgetDeny}, allow: true},
+		{name: "user declines", cfg: &config.DiffBudgetConfig{MaxFiles: 1}},
+		{
+			name:  "non-interactive run",
+			cfg:   &config.DiffBudgetConfig{MaxFiles: 1},
+			allow: true,
+			ctx: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, NonInteractiveContextKey, true)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := diffBudgetCtx(t, tt.cfg)
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			perms := &recordingPerms{allow: tt.allow}
+			err := chargeDiffBudget(ctx, perms, PatchToolName, []string{"/w/a.go", "/w/b.go"}, 2)
+			if err == nil || !strings.Contains(err.Error(), "diff budget exceeded") {
+				t.Fatalf("err = %v", err)
+			}
+			// A refused change is not counted.
+			if err := chargeDiffBudget(ctx, perms, EditToolName, []string{"/w/a.go"}, 2); err != nil {
+				t.Fatalf("change within budget after a refusal: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithDiffBudgetKeepsCallersBudget(t *testing.T) {
+	parent := NewDiffBudget(&config.DiffBudgetConfig{MaxLines: 10})
+	ctx := WithDiffBudget(context.Background(), parent)
+	ctx = WithDiffBudget(ctx, NewDiffBudget(&config.DiffBudgetConfig{MaxLines: 1000}))
+	if got := ctx.Value(DiffBudgetContextKey); got != parent {
+		t.Fatal("a subagent run replaced its caller's budget")
+	}
+	if NewDiffBudget(&config.DiffBudgetConfig{Action: config.DiffBudgetDeny}) != nil {
+		t.Fatal("a budget without limits should be nil")
+	}
+}
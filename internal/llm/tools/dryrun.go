@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// dryRunParamDescription documents the per-call dry_run parameter shared by
+// the write tools.
+const dryRunParamDescription = "If true, do not apply the change: return the diff of what would change instead. Use it to preview a change or to present a plan for review."
+
+// IsDryRun reports whether a write tool call must only report what it would
+// change: either the call asked for it or the agent runs with dryRun set.
+func IsDryRun(ctx context.Context, requested bool) bool {
+	if requested {
+		return true
+	}
+	v, _ := ctx.Value(DryRunContextKey).(bool)
+	return v
+}
+
+// dryRunResponse answers a write tool call in dry-run mode with the change
+// it would have made. A call the agent's permissions deny still fails, so
+// the plan never relies on a change that could not be applied; anything
+// else is reported without asking, since nothing is touched.
+func dryRunResponse(ctx context.Context, reg agentregistry.Registry, toolName, permissionInput, summary, diff string, metadata any) (ToolResponse, error) {
+	if reg != nil && reg.EvaluatePermission(string(GetAgentID(ctx)), toolName, permissionInput) == permission.ActionDeny {
+		return NewEmptyResponse(), permission.ErrorPermissionDenied
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run, nothing was changed. %s", summary)
+	if diff = strings.TrimSpace(diff); diff != "" {
+		fmt.Fprintf(&b, "\n\n%s", diff)
+	}
+	b.WriteString("\n\nFiles on disk are unchanged, so later calls still see their current content.")
+	return WithResponseMetadata(NewTextResponse(b.String()), metadata), nil
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/permission"
+	mock_permission "github.com/opencode-ai/opencode/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type denyRegistry struct{ stubRegistry }
+
+func (d *denyRegistry) EvaluatePermission(agentID, toolName, input string) permission.Action {
+	return permission.ActionDeny
+}
+
+func TestIsDryRun(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, IsDryRun(ctx, false))
+	assert.True(t, IsDryRun(ctx, true))
+	assert.True(t, IsDryRun(context.WithValue(ctx, DryRunContextKey, true), false))
+}
+
+func TestEditTool_DryRun(t *testing.T) {
+	t.Run("per call", func(t *testing.T) {
+		ctx, tmpPath, tool := setupEditTest(t)
+		writeAndTrack(t, tmpPath, "hello world")
+
+		resp := runEdit(t, tool, ctx, EditParams{
+			FilePath:  tmpPath,
+			OldString: "world",
+			NewString: "go",
+			DryRun:    true,
+		})
+		assert.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "Dry run")
+		assert.Contains(t, resp.Content, "+hello go")
+
+		content, _ := os.ReadFile(tmpPath)
+		assert.Equal(t, "hello world", string(content))
+
+		var meta EditResponseMetadata
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+		assert.True(t, meta.DryRun)
+		assert.Equal(t, 1, meta.Additions)
+	})
+
+	t.Run("from context does not create the file", func(t *testing.T) {
+		ctx, _, tool := setupEditTest(t)
+		ctx = context.WithValue(ctx, DryRunContextKey, true)
+		newFile := filepath.Join(t.TempDir(), "sub", "new.txt")
+
+		resp := runEdit(t, tool, ctx, EditParams{FilePath: newFile, NewString: "content"})
+		assert.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "Would create")
+		assert.NoDirExists(t, filepath.Dir(newFile))
+	})
+}
+
+func TestBashTool_DryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	marker := filepath.Join(t.TempDir(), "marker")
+
+	run := func(reg agentregistry.Registry, command string) (ToolResponse, error) {
+		input, _ := json.Marshal(BashParams{Command: command, Description: "test", DryRun: true})
+		return NewBashTool(mockPerms, reg, OutputLimits{}).Run(ctx, ToolCall{Name: BashToolName, Input: string(input)})
+	}
+
+	resp, err := run(&stubRegistry{}, "touch "+marker)
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "touch "+marker)
+	assert.NoFileExists(t, marker)
+
+	_, err = run(&denyRegistry{}, "touch "+marker)
+	assert.ErrorIs(t, err, permission.ErrorPermissionDenied)
+
+	resp, err = run(&stubRegistry{}, "echo still-runs")
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "still-runs")
+	assert.NotContains(t, resp.Content, "Dry run")
+}
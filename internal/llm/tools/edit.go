@@ -23,6 +23,7 @@ type EditParams struct {
 	OldString  string `json:"old_string"`
 	NewString  string `json:"new_string"`
 	ReplaceAll bool   `json:"replace_all,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"`
 }
 
 type EditPermissionsParams struct {
@@ -34,6 +35,7 @@ type EditResponseMetadata struct {
 	Diff      string `json:"diff"`
 	Additions int    `json:"additions"`
 	Removals  int    `json:"removals"`
+	DryRun    bool   `json:"dry_run,omitempty"`
 }
 
 type editTool struct {
@@ -59,6 +61,7 @@ To make a file edit, provide the following:
 2. old_string: The text to replace (must match the file contents exactly, including all whitespace and indentation)
 3. new_string: The edited text to replace the old_string
 4. replace_all: (optional) Replace all occurrences of old_string (default false)
+5. dry_run: (optional) Return the diff without changing the file
 
 Special cases:
 - To create a new file: provide file_path and new_string, leave old_string empty
@@ -112,6 +115,10 @@ func (e *editTool) Info() ToolInfo {
 				"type":        "boolean",
 				"description": "Replace all occurrences of old_string (default false)",
 			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": dryRunParamDescription,
+			},
 		},
 		Required: []string{"file_path", "old_string", "new_string"},
 	}
@@ -131,12 +138,16 @@ func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		wd := config.WorkingDirectory()
 		params.FilePath = filepath.Join(wd, params.FilePath)
 	}
+	if err := checkSandbox(params.FilePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
 	var response ToolResponse
 	var err error
+	dryRun := IsDryRun(ctx, params.DryRun)
 
 	if params.OldString == "" {
-		response, err = e.createNewFile(ctx, params.FilePath, params.NewString)
+		response, err = e.createNewFile(ctx, params.FilePath, params.NewString, dryRun)
 		if err != nil {
 			return response, err
 		}
@@ -144,20 +155,21 @@ func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 
 	if params.NewString == "" {
-		response, err = e.deleteContent(ctx, params.FilePath, params.OldString, params.ReplaceAll)
+		response, err = e.deleteContent(ctx, params.FilePath, params.OldString, params.ReplaceAll, dryRun)
 		if err != nil {
 			return response, err
 		}
 		return response, nil
 	}
 
-	response, err = e.replaceContent(ctx, params.FilePath, params.OldString, params.NewString, params.ReplaceAll)
+	response, err = e.replaceContent(ctx, params.FilePath, params.OldString, params.NewString, params.ReplaceAll, dryRun)
 	if err != nil {
 		return response, err
 	}
-	if response.IsError {
+	if response.IsError || dryRun {
 		// Return early if there was an error during content replacement
-		// This prevents unnecessary LSP diagnostics processing
+		// or nothing was written. This prevents unnecessary LSP
+		// diagnostics processing
 		return response, nil
 	}
 
@@ -178,7 +190,7 @@ func (e *editTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
 
 func (e *editTool) IsBaseline() bool { return true }
 
-func (e *editTool) createNewFile(ctx context.Context, filePath, content string) (ToolResponse, error) {
+func (e *editTool) createNewFile(ctx context.Context, filePath, content string, dryRun bool) (ToolResponse, error) {
 	fileInfo, err := os.Stat(filePath)
 	if err == nil {
 		if fileInfo.IsDir() {
@@ -189,11 +201,6 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
 	}
 
-	dir := filepath.Dir(filePath)
-	if err = os.MkdirAll(dir, 0o755); err != nil {
-		return NewEmptyResponse(), fmt.Errorf("failed to create parent directories: %w", err)
-	}
-
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
 		return NewEmptyResponse(), fmt.Errorf("session ID and message ID are required for creating a new file")
@@ -210,6 +217,11 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 		permissionPath = rootDir
 	}
 
+	if dryRun {
+		return dryRunResponse(ctx, e.registry, EditToolName, filePath,
+			fmt.Sprintf("Would create file %s (+%d -%d).", filePath, additions, removals), diff,
+			EditResponseMetadata{Diff: diff, Additions: additions, Removals: removals, DryRun: true})
+	}
 	action := e.registry.EvaluatePermission(string(GetAgentID(ctx)), EditToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
@@ -221,6 +233,7 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        permissionPath,
+				Pattern:     filePath,
 				ToolName:    EditToolName,
 				Action:      "write",
 				Description: fmt.Sprintf("Create file %s", filePath),
@@ -235,6 +248,14 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 		}
 	}
 
+	if err := chargeDiffBudget(ctx, e.permissions, EditToolName, []string{filePath}, additions+removals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return NewEmptyResponse(), fmt.Errorf("failed to create parent directories: %w", err)
+	}
+	checkpointFile(ctx, e.files, filePath)
 	err = os.WriteFile(filePath, []byte(content), 0o644)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
@@ -267,7 +288,7 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 	), nil
 }
 
-func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string, replaceAll bool) (ToolResponse, error) {
+func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string, replaceAll, dryRun bool) (ToolResponse, error) {
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -335,6 +356,11 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
+	if dryRun {
+		return dryRunResponse(ctx, e.registry, EditToolName, filePath,
+			fmt.Sprintf("Would delete content from file %s (+%d -%d).", filePath, additions, removals), diff,
+			EditResponseMetadata{Diff: diff, Additions: additions, Removals: removals, DryRun: true})
+	}
 	action := e.registry.EvaluatePermission(string(GetAgentID(ctx)), EditToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
@@ -346,6 +372,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        permissionPath,
+				Pattern:     filePath,
 				ToolName:    EditToolName,
 				Action:      "write",
 				Description: fmt.Sprintf("Delete content from file %s", filePath),
@@ -360,6 +387,11 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		}
 	}
 
+	if err := chargeDiffBudget(ctx, e.permissions, EditToolName, []string{filePath}, additions+removals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	checkpointFile(ctx, e.files, filePath)
 	err = os.WriteFile(filePath, []byte(newContent), 0o644)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
@@ -400,7 +432,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	), nil
 }
 
-func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newString string, replaceAll bool) (ToolResponse, error) {
+func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newString string, replaceAll, dryRun bool) (ToolResponse, error) {
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -470,6 +502,11 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
+	if dryRun {
+		return dryRunResponse(ctx, e.registry, EditToolName, filePath,
+			fmt.Sprintf("Would replace content in file %s (+%d -%d).", filePath, additions, removals), diff,
+			EditResponseMetadata{Diff: diff, Additions: additions, Removals: removals, DryRun: true})
+	}
 	action := e.registry.EvaluatePermission(string(GetAgentID(ctx)), EditToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
@@ -481,6 +518,7 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        permissionPath,
+				Pattern:     filePath,
 				ToolName:    EditToolName,
 				Action:      "write",
 				Description: fmt.Sprintf("Replace content in file %s", filePath),
@@ -495,6 +533,11 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 		}
 	}
 
+	if err := chargeDiffBudget(ctx, e.permissions, EditToolName, []string{filePath}, additions+removals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	checkpointFile(ctx, e.files, filePath)
 	err = os.WriteFile(filePath, []byte(newContent), 0o644)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
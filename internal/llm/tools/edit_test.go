@@ -11,6 +11,8 @@ import (
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/lsp/lsptest"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
 	"github.com/opencode-ai/opencode/internal/permission"
 	mock_permission "github.com/opencode-ai/opencode/internal/permission/mocks"
 	"github.com/opencode-ai/opencode/internal/pubsub"
@@ -116,6 +118,16 @@ func (s *stubHistoryService) Delete(context.Context, string) error { return nil
 
 func (s *stubHistoryService) DeleteSessionFiles(context.Context, string) error { return nil }
 
+func (s *stubHistoryService) Checkpoint(context.Context, string, string, string) error { return nil }
+
+func (s *stubHistoryService) Revert(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (s *stubHistoryService) Baseline(context.Context, string, string) ([]history.BaselineFile, error) {
+	return nil, nil
+}
+
 func setupEditTest(t *testing.T) (context.Context, string, BaseTool) {
 	t.Helper()
 	ctrl := gomock.NewController(t)
@@ -232,6 +244,29 @@ func TestEditTool_Replace(t *testing.T) {
 	})
 }
 
+func TestEditTool_ReportsDiagnostics(t *testing.T) {
+	ctx, tmpPath, _ := setupEditTest(t)
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+
+	svc := lsptest.New()
+	svc.AddServer("txt", ".txt")
+	svc.Script("txt", tmpPath, []protocol.Diagnostic{lsptest.Error(1, "unknown word")}, nil)
+	tool := NewEditTool(svc, mockPerms, &stubHistoryService{}, &stubRegistry{})
+
+	writeAndTrack(t, tmpPath, "hello world")
+	resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "world", NewString: "wrold"})
+	assert.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "<file_diagnostics>")
+	assert.Contains(t, resp.Content, "unknown word")
+
+	resp = runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "wrold", NewString: "world"})
+	assert.False(t, resp.IsError)
+	assert.NotContains(t, resp.Content, "unknown word", "the fix clears the diagnostic")
+	assert.Equal(t, 2, svc.Waits(tmpPath))
+}
+
 func TestEditTool_CreateFile(t *testing.T) {
 	ctx, _, tool := setupEditTest(t)
 
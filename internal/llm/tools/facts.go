@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/project"
+)
+
+const FactsToolName = "facts"
+
+type FactsParams struct {
+	Refresh bool `json:"refresh,omitempty"`
+}
+
+type factsTool struct {
+	workDir string
+	dataDir string
+}
+
+// NewFactsTool reports project.LoadFacts for workDir, caching under dataDir.
+func NewFactsTool(workDir, dataDir string) BaseTool {
+	return &factsTool{workDir: workDir, dataDir: dataDir}
+}
+
+func (t *factsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: FactsToolName,
+		Description: `Reports structured facts about the workspace: languages with file and line counts, frameworks, build systems, package managers, test commands and CI configuration files.
+
+WHEN TO USE THIS TOOL:
+- Before exploring the repository to find out how it is built or tested; the answer is already here
+- To check the exact line counts or CI file paths behind the summary in your system prompt
+
+HOW TO USE:
+- Facts are detected once per git commit and cached; set refresh to true after adding manifests or CI files in the current session`,
+		Parameters: map[string]any{
+			"refresh": map[string]any{
+				"type":        "boolean",
+				"description": "Detect again instead of using the cached facts for this commit",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *factsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params FactsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	f := project.LoadFacts(ctx, t.workDir, t.dataDir, params.Refresh)
+	var sb strings.Builder
+	if f.Commit != "" {
+		fmt.Fprintf(&sb, "Commit: %s\n", f.Commit)
+	}
+	if len(f.Languages) > 0 {
+		sb.WriteString("Languages:\n")
+		for _, l := range f.Languages {
+			fmt.Fprintf(&sb, "- %s: %d files, %d lines\n", l.Name, l.Files, l.Lines)
+		}
+		if f.Truncated {
+			sb.WriteString("(file walk truncated; counts cover part of the tree)\n")
+		}
+	}
+	writeList := func(label string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", label, strings.Join(items, ", "))
+		}
+	}
+	writeList("Frameworks", f.Frameworks)
+	writeList("Build systems", f.BuildSystems)
+	writeList("Package managers", f.PackageManagers)
+	writeList("Test commands", f.TestCommands)
+	if len(f.CI) > 0 {
+		sb.WriteString("CI:\n")
+		for _, ci := range f.CI {
+			fmt.Fprintf(&sb, "- %s: %s\n", ci.System, ci.Path)
+		}
+	}
+	if sb.Len() == 0 {
+		return NewTextResponse("No languages, manifests or CI configuration detected in the workspace."), nil
+	}
+	return NewTextResponse(strings.TrimRight(sb.String(), "\n")), nil
+}
+
+func (t *factsTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return true
+}
+
+func (t *factsTool) IsBaseline() bool { return true }
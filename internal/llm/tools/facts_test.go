@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFactsToolReportsWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/app\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := NewFactsTool(dir, "").Run(context.Background(), ToolCall{})
+	if err != nil || resp.IsError {
+		t.Fatalf("run: err=%v resp=%+v", err, resp)
+	}
+	for _, want := range []string{"- Go: 1 files, 3 lines", "Test commands: go test ./..."} {
+		if !strings.Contains(resp.Content, want) {
+			t.Errorf("response missing %q:\n%s", want, resp.Content)
+		}
+	}
+
+	resp, _ = NewFactsTool(t.TempDir(), "").Run(context.Background(), ToolCall{Input: `{"refresh":true}`})
+	if !strings.Contains(resp.Content, "No languages") {
+		t.Errorf("empty workspace response = %q", resp.Content)
+	}
+}
@@ -1,8 +1,12 @@
 package tools
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/logging"
 )
 
 // File record to track when files were read/written
@@ -51,3 +55,13 @@ func recordFileWrite(path string) {
 	record.writeTime = time.Now()
 	fileRecords[path] = record
 }
+
+// checkpointFile snapshots path before the current message modifies it, so
+// the message's changes can be reverted later. Failures only get logged:
+// a missing checkpoint must not block the edit itself.
+func checkpointFile(ctx context.Context, files history.Service, path string) {
+	sessionID, messageID := GetContextValues(ctx)
+	if err := files.Checkpoint(ctx, sessionID, messageID, path); err != nil {
+		logging.Debug("Error creating file checkpoint", "path", path, "error", err)
+	}
+}
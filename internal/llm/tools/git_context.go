@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/project"
+)
+
+const GitContextToolName = "git_context"
+
+type gitContextTool struct {
+	workDir string
+}
+
+// NewGitContextTool reports project.LoadGitInfo for workDir.
+func NewGitContextTool(workDir string) BaseTool {
+	return &gitContextTool{workDir: workDir}
+}
+
+func (t *gitContextTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: GitContextToolName,
+		Description: `Reports the current git state of the workspace: branch, last commit, uncommitted files with their status codes, and the remote URL.
+
+WHEN TO USE THIS TOOL:
+- Instead of running git status, git branch or git log -1 through bash
+- To see which files changed since your system prompt was written; its git section is a snapshot from the start of the session
+
+HOW TO USE:
+- Takes no parameters; the state is read fresh on every call
+- Changes are listed as git status --short lines, at most 100 of them`,
+		Parameters: map[string]any{},
+		Required:   []string{},
+	}
+}
+
+func (t *gitContextTool) Run(ctx context.Context, _ ToolCall) (ToolResponse, error) {
+	info, ok := project.LoadGitInfo(ctx, t.workDir)
+	if !ok {
+		return NewTextResponse("The working directory is not inside a git repository."), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(info.Prompt())
+	if len(info.Changes) > 0 {
+		sb.WriteString("\nChanges:\n")
+		sb.WriteString(strings.Join(info.Changes, "\n"))
+		if more := info.DirtyFiles - len(info.Changes); more > 0 {
+			fmt.Fprintf(&sb, "\n(%d more not shown)", more)
+		}
+	}
+	return NewTextResponse(sb.String()), nil
+}
+
+func (t *gitContextTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return true
+}
+
+func (t *gitContextTool) IsBaseline() bool { return true }
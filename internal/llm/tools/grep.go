@@ -1,9 +1,10 @@
 package tools
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -16,6 +17,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
+
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/fileutil"
@@ -37,6 +40,7 @@ type GrepParams struct {
 	HeadLimit       *int   `json:"head_limit"`
 	Offset          int    `json:"offset"`
 	Multiline       bool   `json:"multiline"`
+	Column          bool   `json:"column"`
 }
 
 // resolveGlob returns the effective glob pattern, preferring the new "glob"
@@ -66,11 +70,38 @@ func (p *GrepParams) resolveOutputMode() string {
 	}
 }
 
-type grepMatch struct {
-	path     string
-	modTime  time.Time
-	lineNum  int
-	lineText string
+// resolveContext returns the number of context lines before and after
+// each match. -C sets both; -B and -A override it on their side.
+func (p *GrepParams) resolveContext() (before, after int) {
+	if p.Context != nil && *p.Context > 0 {
+		before, after = *p.Context, *p.Context
+	}
+	if p.BeforeContext != nil && *p.BeforeContext > 0 {
+		before = *p.BeforeContext
+	}
+	if p.AfterContext != nil && *p.AfterContext > 0 {
+		after = *p.AfterContext
+	}
+	return before, after
+}
+
+// GrepMatch is one line of content-mode output: a line that matched, or a
+// context line around one. A match spanning several lines in multiline
+// mode yields one GrepMatch per line; only the first carries a column.
+type GrepMatch struct {
+	File    string `json:"file"` // relative to the searched path
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"` // 1-based byte column of the first match on the line
+	Preview string `json:"preview"`
+	Context bool   `json:"context,omitempty"`
+}
+
+// grepFile is a file with matches, as listed by files_with_matches and
+// count mode.
+type grepFile struct {
+	path    string
+	modTime time.Time
+	count   int
 }
 
 type GrepResponseMetadata struct {
@@ -80,8 +111,19 @@ type GrepResponseMetadata struct {
 	Truncated       bool   `json:"truncated"`
 	Offset          int    `json:"offset,omitempty"`
 	Limit           int    `json:"limit"`
+	// Matches holds the lines shown in content mode, separators excluded.
+	Matches []GrepMatch `json:"matches,omitempty"`
+}
+
+// grepUsageError is a search the engine rejected, such as an invalid
+// regex or an unknown file type. It is reported to the model rather than
+// failing the tool call.
+type grepUsageError struct {
+	msg string
 }
 
+func (e *grepUsageError) Error() string { return e.msg }
+
 type grepTool struct {
 	registry    agentregistry.Registry
 	permissions permission.Service
@@ -96,6 +138,7 @@ Usage:
 - Supports full regex syntax (e.g., "log.*Error", "function\\s+\\w+")
 - Filter files with glob parameter (e.g., "*.js", "**/*.tsx") or file_type parameter (e.g., "js", "py", "go")
 - Output modes: "content" shows matching lines, "files_with_matches" shows only file paths (default), "count" shows match counts
+- In content mode, context/before_context/after_context add surrounding lines and column=true prefixes each match with its column
 - Use Task tool for open-ended searches requiring multiple rounds
 - Pattern syntax: Uses ripgrep (not grep) — literal braces need escaping (use ` + "`interface\\{\\}`" + ` to find ` + "`interface{}`" + ` in Go code)
 - Multiline matching: By default patterns match within single lines only. For cross-line patterns like ` + "`struct \\{[\\s\\S]*?field`" + `, use multiline=true
@@ -174,6 +217,10 @@ func (g *grepTool) Info() ToolInfo {
 				"type":        "boolean",
 				"description": "Enable multiline mode where . matches newlines and patterns can span lines (rg -U --multiline-dotall). Default: false.",
 			},
+			"column": map[string]any{
+				"type":        "boolean",
+				"description": "Show the 1-based column of the first match on each line, as file:line:column:text (rg --column). Requires output_mode: \"content\", ignored otherwise. Default: false.",
+			},
 		},
 		Required: []string{"pattern"},
 	}
@@ -232,6 +279,10 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 
 	output, metadata, err := runGrepSearch(ctx, searchPattern, searchPath, &params, glob, mode, headLimit, denyPatterns)
 	if err != nil {
+		var usage *grepUsageError
+		if errors.As(err, &usage) {
+			return NewTextErrorResponse(usage.Error()), nil
+		}
 		return NewEmptyResponse(), fmt.Errorf("error searching files: %w", err)
 	}
 
@@ -243,16 +294,11 @@ func runGrepSearch(ctx context.Context, pattern, rootPath string, params *GrepPa
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Try ripgrep first; fall back to Go regex search
-	rgAvailable := isRipgrepAvailable()
-
-	if rgAvailable {
+	// Try ripgrep first; fall back to the built-in engine
+	if isRipgrepAvailable() {
 		return searchWithRipgrepModes(ctx, pattern, rootPath, params, glob, mode, headLimit, denyPatterns)
 	}
-
-	// Fallback: Go regex search only supports basic files_with_matches-like output.
-	// Pass headLimit so the fallback collection cap aligns with the requested limit.
-	return searchWithRegexFallback(ctx, pattern, rootPath, glob, mode, headLimit, params.Offset, denyPatterns)
+	return searchWithRegexFallback(ctx, pattern, rootPath, params, glob, mode, headLimit, denyPatterns)
 }
 
 var (
@@ -273,6 +319,8 @@ func searchWithRipgrepModes(ctx context.Context, pattern, path string, params *G
 	args = append(args, path)
 
 	cmd := exec.CommandContext(ctx, "rg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	output, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -281,7 +329,12 @@ func searchWithRipgrepModes(ctx context.Context, pattern, path string, params *G
 				// No matches
 				return "No matches found", GrepResponseMetadata{Mode: mode, Limit: headLimit}, nil
 			case 2:
-				// Partial results — continue processing
+				// Partial results — continue processing. Without any
+				// output the search itself was rejected (bad regex,
+				// unknown --type); --no-messages leaves only that on stderr.
+				if len(output) == 0 && stderr.Len() > 0 {
+					return "", GrepResponseMetadata{}, &grepUsageError{msg: strings.TrimSpace(stderr.String())}
+				}
 			default:
 				return "", GrepResponseMetadata{}, err
 			}
@@ -298,11 +351,16 @@ func searchWithRipgrepModes(ctx context.Context, pattern, path string, params *G
 	rootPath := path
 	switch mode {
 	case "content":
-		return formatContentMode(raw, rootPath, params.Offset, headLimit)
+		before, after := params.resolveContext()
+		matches := parseRipgrepJSON(raw, rootPath)
+		if len(matches) == 0 {
+			return "No matches found", GrepResponseMetadata{Mode: mode, Limit: headLimit}, nil
+		}
+		return formatContentMode(matches, before+after > 0, params.Column, params.Offset, headLimit)
 	case "count":
-		return formatCountMode(raw, rootPath, params.Offset, headLimit)
+		return formatCountMode(parseRipgrepCounts(raw), rootPath, params.Offset, headLimit, false)
 	default:
-		return formatFilesMode(raw, rootPath, params.Offset, headLimit)
+		return formatFilesMode(parseRipgrepFiles(raw), rootPath, params.Offset, headLimit, false)
 	}
 }
 
@@ -349,7 +407,9 @@ func buildRipgrepArgs(pattern string, params *GrepParams, glob, mode string, den
 	case "count":
 		args = append(args, "-c")
 	case "content":
-		args = append(args, "-H", "-n", "--field-match-separator=\x1f")
+		// --json reports each match with its line, column and the lines
+		// around it, whatever the file path or line contains.
+		args = append(args, "--json")
 		if params.Context != nil && *params.Context > 0 {
 			args = append(args, "-C", strconv.Itoa(*params.Context))
 		}
@@ -387,6 +447,126 @@ func buildRipgrepArgs(pattern string, params *GrepParams, glob, mode string, den
 	return args
 }
 
+// rgText is a path or line in ripgrep's JSON output: text when it is
+// valid UTF-8, base64 bytes otherwise.
+type rgText struct {
+	Text  string `json:"text"`
+	Bytes []byte `json:"bytes"`
+}
+
+func (t rgText) String() string {
+	if t.Bytes != nil {
+		return string(t.Bytes)
+	}
+	return t.Text
+}
+
+type rgMessage struct {
+	Type string `json:"type"`
+	Data struct {
+		Path       rgText `json:"path"`
+		Lines      rgText `json:"lines"`
+		LineNumber int    `json:"line_number"`
+		Submatches []struct {
+			Start int `json:"start"`
+		} `json:"submatches"`
+	} `json:"data"`
+}
+
+// parseRipgrepJSON turns rg --json output into one GrepMatch per line, in
+// the order ripgrep reported them.
+func parseRipgrepJSON(raw, rootPath string) []GrepMatch {
+	var matches []GrepMatch
+	for _, line := range strings.Split(raw, "\n") {
+		var msg rgMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue
+		}
+		if msg.Type != "match" && msg.Type != "context" {
+			continue
+		}
+		file := toRelativePath(msg.Data.Path.String(), rootPath)
+		column := 0
+		if msg.Type == "match" && len(msg.Data.Submatches) > 0 {
+			column = msg.Data.Submatches[0].Start + 1
+		}
+		// A multiline match reports all the lines it spans at once.
+		text := strings.TrimSuffix(msg.Data.Lines.String(), "\n")
+		for i, l := range strings.Split(text, "\n") {
+			m := GrepMatch{
+				File:    file,
+				Line:    msg.Data.LineNumber + i,
+				Preview: grepPreview(l),
+				Context: msg.Type == "context",
+			}
+			if i == 0 {
+				m.Column = column
+			}
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// parseRipgrepFiles parses rg -l output: one file path per line.
+func parseRipgrepFiles(raw string) []grepFile {
+	lines := strings.Split(raw, "\n")
+	files := make([]grepFile, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		f := grepFile{path: line}
+		if info, err := os.Stat(line); err == nil {
+			f.modTime = info.ModTime()
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// parseRipgrepCounts parses rg -c output: file:count per line.
+func parseRipgrepCounts(raw string) []grepFile {
+	lines := strings.Split(raw, "\n")
+	files := make([]grepFile, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Format: file:count (last colon separates)
+		idx := strings.LastIndex(line, ":")
+		if idx < 0 {
+			continue
+		}
+		count, err := strconv.Atoi(line[idx+1:])
+		if err != nil {
+			continue
+		}
+		files = append(files, grepFile{path: line[:idx], count: count})
+	}
+	return files
+}
+
+// grepMaxPreview matches the --max-columns limit ripgrep applies to the
+// other output modes.
+const grepMaxPreview = 500
+
+func grepPreview(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	if len(line) <= grepMaxPreview {
+		return line
+	}
+	cut := grepMaxPreview
+	for cut > 0 && !isRuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + " [... omitted]"
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
 // paginationResult holds the output of paginating a slice.
 type paginationResult struct {
 	start     int
@@ -416,90 +596,83 @@ func paginate(total, offset, headLimit int) paginationResult {
 	}
 }
 
-// formatFilesMode processes rg -l output: one file path per line.
-// Sorts by mtime (newest first), applies offset/limit pagination.
-func formatFilesMode(raw, rootPath string, offset, headLimit int) (string, GrepResponseMetadata, error) {
-	lines := strings.Split(raw, "\n")
-
-	type fileEntry struct {
-		path    string
-		modTime time.Time
-	}
-	entries := make([]fileEntry, 0, len(lines))
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		info, err := os.Stat(line)
-		if err != nil {
-			entries = append(entries, fileEntry{path: line})
-			continue
-		}
-		entries = append(entries, fileEntry{path: line, modTime: info.ModTime()})
-	}
-
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].modTime.After(entries[j].modTime)
+// formatFilesMode lists the files with matches, newest first, and applies
+// offset/limit pagination. capped means the search stopped early, so the
+// total is a lower bound.
+func formatFilesMode(files []grepFile, rootPath string, offset, headLimit int, capped bool) (string, GrepResponseMetadata, error) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
 	})
 
-	pg := paginate(len(entries), offset, headLimit)
-	page := entries[pg.start:pg.end]
+	pg := paginate(len(files), offset, headLimit)
+	page := files[pg.start:pg.end]
 
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "Found %d files", pg.total)
+	if capped {
+		sb.WriteByte('+')
+	}
 	if pg.truncated {
 		fmt.Fprintf(&sb, " (showing %d, limit: %d)", len(page), headLimit)
 	}
 	sb.WriteByte('\n')
 
-	for _, e := range page {
-		sb.WriteString(toRelativePath(e.path, rootPath))
+	for _, f := range page {
+		sb.WriteString(toRelativePath(f.path, rootPath))
 		sb.WriteByte('\n')
 	}
 
 	return sb.String(), GrepResponseMetadata{
 		Mode:          "files_with_matches",
 		NumberOfFiles: pg.total,
-		Truncated:     pg.truncated,
+		Truncated:     pg.truncated || capped,
 		Offset:        offset,
 		Limit:         headLimit,
 	}, nil
 }
 
-// formatContentMode processes rg -H -n output (with optional context).
-// Converts absolute paths to relative, applies offset/limit pagination on output lines.
-func formatContentMode(raw, rootPath string, offset, headLimit int) (string, GrepResponseMetadata, error) {
-	lines := strings.Split(raw, "\n")
-
-	// Count match lines and unique files using the unit separator (\x1f).
-	// buildRipgrepArgs uses --field-match-separator=\x1f for content mode, so
-	// match lines contain \x1f (e.g. "file\x1fline\x1fcontent") while context
-	// lines use dashes ("file-line-content") and separators are "--".
+// formatContentMode renders matches as file:line:text, and context lines
+// as file-line-text, with "--" between non-adjacent groups when context
+// was requested. Offset/limit pagination applies to output lines.
+func formatContentMode(matches []GrepMatch, withContext, column bool, offset, headLimit int) (string, GrepResponseMetadata, error) {
+	// A zero Line marks a separator.
+	lines := make([]GrepMatch, 0, len(matches))
 	matchCount := 0
 	fileSet := make(map[string]struct{})
-	for i, line := range lines {
-		if strings.ContainsRune(line, '\x1f') {
-			matchCount++
-			if idx := strings.IndexByte(line, '\x1f'); idx > 0 {
-				fileSet[line[:idx]] = struct{}{}
+	for i, m := range matches {
+		if withContext && i > 0 {
+			prev := matches[i-1]
+			if prev.File != m.File || m.Line != prev.Line+1 {
+				lines = append(lines, GrepMatch{})
 			}
-			lines[i] = strings.ReplaceAll(line, "\x1f", ":")
 		}
-	}
-
-	// Convert absolute paths to relative in each line
-	for i, line := range lines {
-		lines[i] = relativizeLine(line, rootPath)
+		lines = append(lines, m)
+		if !m.Context {
+			matchCount++
+			fileSet[m.File] = struct{}{}
+		}
 	}
 
 	pg := paginate(len(lines), offset, headLimit)
 	page := lines[pg.start:pg.end]
 
 	var sb strings.Builder
-	for _, line := range page {
-		sb.WriteString(line)
+	shown := make([]GrepMatch, 0, len(page))
+	for _, m := range page {
+		switch {
+		case m.Line == 0:
+			sb.WriteString("--")
+		case m.Context:
+			fmt.Fprintf(&sb, "%s-%d-%s", m.File, m.Line, m.Preview)
+		case column && m.Column > 0:
+			fmt.Fprintf(&sb, "%s:%d:%d:%s", m.File, m.Line, m.Column, m.Preview)
+		default:
+			fmt.Fprintf(&sb, "%s:%d:%s", m.File, m.Line, m.Preview)
+		}
 		sb.WriteByte('\n')
+		if m.Line != 0 {
+			shown = append(shown, m)
+		}
 	}
 
 	if pg.truncated || offset > 0 {
@@ -513,57 +686,35 @@ func formatContentMode(raw, rootPath string, offset, headLimit int) (string, Gre
 		Truncated:       pg.truncated,
 		Offset:          offset,
 		Limit:           headLimit,
+		Matches:         shown,
 	}, nil
 }
 
-// formatCountMode processes rg -c output (file:count per line).
-// Sums total matches, applies offset/limit pagination on file entries.
-func formatCountMode(raw, rootPath string, offset, headLimit int) (string, GrepResponseMetadata, error) {
-	lines := strings.Split(raw, "\n")
-
-	type countEntry struct {
-		path  string
-		count int
-	}
-	entries := make([]countEntry, 0, len(lines))
+// formatCountMode lists file:count entries, sums total matches and
+// applies offset/limit pagination on file entries.
+func formatCountMode(files []grepFile, rootPath string, offset, headLimit int, capped bool) (string, GrepResponseMetadata, error) {
 	totalMatches := 0
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// Format: file:count (last colon separates)
-		idx := strings.LastIndex(line, ":")
-		if idx < 0 {
-			continue
-		}
-		filePath := line[:idx]
-		count, err := strconv.Atoi(line[idx+1:])
-		if err != nil {
-			continue
-		}
-		totalMatches += count
-		entries = append(entries, countEntry{
-			path:  filePath,
-			count: count,
-		})
+	for _, f := range files {
+		totalMatches += f.count
 	}
 
-	pg := paginate(len(entries), offset, headLimit)
-	page := entries[pg.start:pg.end]
+	pg := paginate(len(files), offset, headLimit)
+	page := files[pg.start:pg.end]
 
 	var sb strings.Builder
-	for _, e := range page {
-		fmt.Fprintf(&sb, "%s:%d\n", toRelativePath(e.path, rootPath), e.count)
+	for _, f := range page {
+		fmt.Fprintf(&sb, "%s:%d\n", toRelativePath(f.path, rootPath), f.count)
 	}
 	fmt.Fprintf(&sb, "Found %d total matches across %d files.\n", totalMatches, pg.total)
+	if capped {
+		sb.WriteString("(Search stopped early; counts cover the files searched so far.)\n")
+	}
 
 	return sb.String(), GrepResponseMetadata{
 		Mode:            "count",
 		NumberOfFiles:   pg.total,
 		NumberOfMatches: totalMatches,
-		Truncated:       pg.truncated,
+		Truncated:       pg.truncated || capped,
 		Offset:          offset,
 		Limit:           headLimit,
 	}, nil
@@ -575,142 +726,256 @@ func toRelativePath(absPath, rootPath string) string {
 	if err != nil {
 		return absPath
 	}
+	if rel == "." {
+		// rootPath is the file itself.
+		return filepath.Base(absPath)
+	}
 	return rel
 }
 
-// relativizeLine converts the leading file path in a ripgrep content line to relative.
-// Lines may be "file:line:content", "file-line-content" (context), or "--" (separator).
-func relativizeLine(line, rootPath string) string {
-	if line == "--" {
-		return line
-	}
-	if strings.HasPrefix(line, rootPath+"/") {
-		return line[len(rootPath)+1:]
-	}
-	return line
+// fallbackFileTypes maps the common ripgrep --type names to the globs the
+// built-in engine filters on.
+var fallbackFileTypes = map[string][]string{
+	"c":          {"*.c", "*.h"},
+	"cpp":        {"*.cpp", "*.cc", "*.cxx", "*.hpp", "*.hh", "*.hxx", "*.h"},
+	"cs":         {"*.cs"},
+	"csharp":     {"*.cs"},
+	"css":        {"*.css", "*.scss", "*.sass", "*.less"},
+	"go":         {"*.go"},
+	"html":       {"*.html", "*.htm"},
+	"java":       {"*.java"},
+	"js":         {"*.js", "*.jsx", "*.mjs", "*.cjs", "*.vue"},
+	"json":       {"*.json"},
+	"kotlin":     {"*.kt", "*.kts"},
+	"lua":        {"*.lua"},
+	"markdown":   {"*.md", "*.markdown", "*.mdx"},
+	"md":         {"*.md", "*.markdown", "*.mdx"},
+	"php":        {"*.php"},
+	"proto":      {"*.proto"},
+	"py":         {"*.py", "*.pyi"},
+	"python":     {"*.py", "*.pyi"},
+	"rb":         {"*.rb", "Gemfile", "Rakefile"},
+	"ruby":       {"*.rb", "Gemfile", "Rakefile"},
+	"rust":       {"*.rs"},
+	"scala":      {"*.scala", "*.sbt"},
+	"sh":         {"*.sh", "*.bash", "*.zsh"},
+	"sql":        {"*.sql"},
+	"swift":      {"*.swift"},
+	"toml":       {"*.toml"},
+	"ts":         {"*.ts", "*.tsx", "*.mts", "*.cts"},
+	"typescript": {"*.ts", "*.tsx", "*.mts", "*.cts"},
+	"yaml":       {"*.yaml", "*.yml"},
 }
 
-// searchWithRegexFallback is the Go regex fallback for environments without ripgrep.
-func searchWithRegexFallback(ctx context.Context, pattern, rootPath, glob, mode string, headLimit, offset int, denyPatterns []string) (string, GrepResponseMetadata, error) {
-	// Collect more than headLimit so pagination can report accurate totals,
-	// but still cap to avoid unbounded memory use.
-	collectLimit := max(headLimit+offset, 500)
-	matches, capped, err := searchFilesWithRegex(ctx, pattern, rootPath, glob, collectLimit)
+// fallbackMaxFileSize skips files the built-in engine would have to load
+// whole; ripgrep streams them instead.
+const fallbackMaxFileSize = 10 << 20
+
+// regexSearch is the built-in engine used when ripgrep is not installed.
+// It supports the same parameters but does not read .gitignore.
+type regexSearch struct {
+	re            *regexp.Regexp
+	multiline     bool
+	before, after int
+	glob          string
+	types         []string
+	deny          []string
+	rootPath      string
+}
+
+func newRegexSearch(pattern, rootPath string, params *GrepParams, glob string, denyPatterns []string) (*regexSearch, error) {
+	flags := ""
+	if params.CaseInsensitive {
+		flags += "i"
+	}
+	if params.Multiline {
+		// ripgrep's ^ and $ always match at line boundaries.
+		flags += "ms"
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return "", GrepResponseMetadata{}, err
+		return nil, &grepUsageError{msg: fmt.Sprintf("invalid regex pattern: %s", err)}
 	}
-
-	// Filter out files matching read deny patterns.
-	// Absolute patterns match the full path; relative patterns match against
-	// the base name and relative path (mirroring ripgrep's !**/pattern behavior).
-	if len(denyPatterns) > 0 {
-		filtered := matches[:0]
-		for _, m := range matches {
-			denied := false
-			for _, dp := range denyPatterns {
-				if strings.HasPrefix(dp, "/") {
-					if permission.MatchWildcard(dp, m.path) {
-						denied = true
-						break
-					}
-				} else {
-					base := filepath.Base(m.path)
-					rel, _ := filepath.Rel(rootPath, m.path)
-					if permission.MatchWildcard(dp, base) || (rel != "" && permission.MatchWildcard(dp, rel)) {
-						denied = true
-						break
-					}
-				}
-			}
-			if !denied {
-				filtered = append(filtered, m)
-			}
+	s := &regexSearch{
+		re:        re,
+		multiline: params.Multiline,
+		glob:      glob,
+		deny:      denyPatterns,
+		rootPath:  rootPath,
+	}
+	if params.FileType != "" {
+		types, ok := fallbackFileTypes[params.FileType]
+		if !ok {
+			return nil, &grepUsageError{msg: fmt.Sprintf("unrecognized file type: %s", params.FileType)}
 		}
-		matches = filtered
+		s.types = types
 	}
+	s.before, s.after = params.resolveContext()
+	return s, nil
+}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].modTime.After(matches[j].modTime)
-	})
-
-	if len(matches) == 0 {
-		return "No matches found", GrepResponseMetadata{Mode: mode, Limit: headLimit}, nil
+// wants reports whether a file passes the glob, type and deny filters.
+// Globs without a slash match the base name, like ripgrep's.
+func (s *regexSearch) wants(path string) bool {
+	base := filepath.Base(path)
+	rel, _ := filepath.Rel(s.rootPath, path)
+	rel = filepath.ToSlash(rel)
+	matchGlob := func(glob string) bool {
+		if !strings.Contains(glob, "/") {
+			ok, _ := doublestar.Match(glob, base)
+			return ok
+		}
+		ok, _ := doublestar.Match(strings.TrimPrefix(glob, "/"), rel)
+		return ok
 	}
-
-	pg := paginate(len(matches), offset, headLimit)
-	// If the walk was capped, the total we have may be incomplete.
-	truncated := pg.truncated || capped
-	page := matches[pg.start:pg.end]
-
-	var sb strings.Builder
-	note := ""
-	if mode == "content" || mode == "count" {
-		note = "\n(Advanced search features require ripgrep. Install it for full functionality.)\n"
+	if s.glob != "" && !matchGlob(s.glob) {
+		return false
+	}
+	if s.types != nil && !slices.ContainsFunc(s.types, matchGlob) {
+		return false
+	}
+	// Absolute deny patterns match the full path; relative ones match the
+	// base name or the relative path (mirroring ripgrep's !**/pattern).
+	for _, dp := range s.deny {
+		if strings.HasPrefix(dp, "/") {
+			if permission.MatchWildcard(dp, path) {
+				return false
+			}
+		} else if permission.MatchWildcard(dp, base) || (rel != "" && permission.MatchWildcard(dp, rel)) {
+			return false
+		}
 	}
+	return true
+}
 
-	if capped {
-		fmt.Fprintf(&sb, "Found %d+ files\n", pg.total)
-	} else {
-		fmt.Fprintf(&sb, "Found %d files\n", pg.total)
+// searchFile returns the lines of content that match, with context lines
+// around them, and how many lines matched. firstOnly stops at the first
+// match and returns no lines.
+func (s *regexSearch) searchFile(path, content string, firstOnly bool) ([]GrepMatch, int) {
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
 	}
 
-	currentFile := ""
-	for _, match := range page {
-		relPath := toRelativePath(match.path, rootPath)
-		if currentFile != relPath {
-			if currentFile != "" {
-				sb.WriteByte('\n')
+	// matched maps a line index to its 1-based match column, 0 for the
+	// continuation lines of a multiline match.
+	matched := make(map[int]int)
+	if s.multiline {
+		locs := s.re.FindAllStringIndex(content, -1)
+		if firstOnly && len(locs) > 0 {
+			return nil, 1
+		}
+		for _, loc := range locs {
+			first := strings.Count(content[:loc[0]], "\n")
+			lineStart := strings.LastIndexByte(content[:loc[0]], '\n') + 1
+			last := first
+			if loc[1] > loc[0] {
+				last = strings.Count(content[:loc[1]-1], "\n")
+			}
+			if _, ok := matched[first]; !ok {
+				matched[first] = loc[0] - lineStart + 1
+			}
+			for i := first + 1; i <= last; i++ {
+				if _, ok := matched[i]; !ok {
+					matched[i] = 0
+				}
 			}
-			currentFile = relPath
-			fmt.Fprintf(&sb, "%s:\n", relPath)
 		}
-		if match.lineNum > 0 {
-			fmt.Fprintf(&sb, "  Line %d: %s\n", match.lineNum, match.lineText)
+	} else {
+		for i, line := range lines {
+			if loc := s.re.FindStringIndex(strings.TrimSuffix(line, "\r")); loc != nil {
+				if firstOnly {
+					return nil, 1
+				}
+				matched[i] = loc[0] + 1
+			}
 		}
 	}
+	if len(matched) == 0 || firstOnly {
+		return nil, len(matched)
+	}
 
-	if truncated {
-		fmt.Fprintf(&sb, "\n(Results truncated: showing %d files. Consider a more specific path or pattern.)", len(page))
+	file := toRelativePath(path, s.rootPath)
+	var out []GrepMatch
+	for i := range lines {
+		if col, ok := matched[i]; ok {
+			out = append(out, GrepMatch{File: file, Line: i + 1, Column: col, Preview: grepPreview(lines[i])})
+			continue
+		}
+		if s.nearMatch(i, matched, len(lines)) {
+			out = append(out, GrepMatch{File: file, Line: i + 1, Preview: grepPreview(lines[i]), Context: true})
+		}
 	}
+	return out, len(matched)
+}
 
-	sb.WriteString(note)
+// nearMatch reports whether line i is within the context window of a
+// matched line.
+func (s *regexSearch) nearMatch(i int, matched map[int]int, n int) bool {
+	for j := max(0, i-s.after); j < i; j++ {
+		if _, ok := matched[j]; ok {
+			return true
+		}
+	}
+	for j := i + 1; j <= min(n-1, i+s.before); j++ {
+		if _, ok := matched[j]; ok {
+			return true
+		}
+	}
+	return false
+}
 
-	return sb.String(), GrepResponseMetadata{
-		Mode:          mode,
-		NumberOfFiles: pg.total,
-		Truncated:     truncated,
-		Offset:        offset,
-		Limit:         headLimit,
-	}, nil
+// isBinary treats content with a NUL byte near the start as binary, which
+// ripgrep skips by default.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
 }
 
-// searchFilesWithRegex walks rootPath and returns files matching the regex.
-// collectLimit caps how many matches are collected (0 = no limit, uses a
-// sensible default of 500). The boolean return indicates whether the walk
-// was stopped early because the cap was reached.
-func searchFilesWithRegex(ctx context.Context, pattern, rootPath, include string, collectLimit int) ([]grepMatch, bool, error) {
-	if collectLimit <= 0 {
-		collectLimit = 500
+// searchWithRegexFallback runs the built-in engine over rootPath and
+// formats the results like the ripgrep path does.
+func searchWithRegexFallback(ctx context.Context, pattern, rootPath string, params *GrepParams, glob, mode string, headLimit int, denyPatterns []string) (string, GrepResponseMetadata, error) {
+	s, err := newRegexSearch(pattern, rootPath, params, glob, denyPatterns)
+	if err != nil {
+		return "", GrepResponseMetadata{}, err
 	}
 
-	matches := []grepMatch{}
-
-	regex, err := regexp.Compile(pattern)
+	// Collect more than headLimit so pagination can report accurate totals,
+	// but still cap to avoid unbounded memory use.
+	collectLimit := max(headLimit+params.Offset, 500)
+	if headLimit == 0 {
+		collectLimit = 0
+	}
+	files, matches, capped, err := s.walk(ctx, mode, collectLimit)
 	if err != nil {
-		return nil, false, fmt.Errorf("invalid regex pattern: %w", err)
+		return "", GrepResponseMetadata{}, err
+	}
+	if len(files) == 0 {
+		return "No matches found", GrepResponseMetadata{Mode: mode, Limit: headLimit}, nil
 	}
 
-	var includePattern *regexp.Regexp
-	if include != "" {
-		regexPattern := globToRegex(include)
-		includePattern, err = regexp.Compile(regexPattern)
-		if err != nil {
-			return nil, false, fmt.Errorf("invalid include pattern: %w", err)
-		}
+	switch mode {
+	case "content":
+		before, after := params.resolveContext()
+		out, meta, err := formatContentMode(matches, before+after > 0, params.Column, params.Offset, headLimit)
+		meta.Truncated = meta.Truncated || capped
+		return out, meta, err
+	case "count":
+		return formatCountMode(files, rootPath, params.Offset, headLimit, capped)
+	default:
+		return formatFilesMode(files, rootPath, params.Offset, headLimit, capped)
 	}
+}
 
+// walk searches every file under s.rootPath. It stops once collectLimit
+// files (or, in content mode, output lines) have matched; 0 means no limit.
+func (s *regexSearch) walk(ctx context.Context, mode string, collectLimit int) ([]grepFile, []GrepMatch, bool, error) {
+	var files []grepFile
+	var matches []GrepMatch
 	capped := false
-	err = filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+	err := filepath.WalkDir(s.rootPath, func(path string, d os.DirEntry, err error) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
@@ -718,78 +983,46 @@ func searchFilesWithRegex(ctx context.Context, pattern, rootPath, include string
 			return nil // Skip errors
 		}
 
-		if info.IsDir() {
+		if d.IsDir() {
 			// Skip common ignored directories (matching ripgrep's --glob exclusions).
 			// Hidden files/dirs are allowed (matching ripgrep's --hidden flag).
 			// Never skip the root search directory itself.
-			if path != rootPath && slices.Contains(commonIgnoredDirs, filepath.Base(path)) {
+			if path != s.rootPath && slices.Contains(commonIgnoredDirs, d.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-
-		if includePattern != nil && !includePattern.MatchString(path) {
+		if !d.Type().IsRegular() || (path != s.rootPath && !s.wants(path)) {
 			return nil
 		}
-
-		match, lineNum, lineText, err := fileContainsPattern(path, regex)
-		if err != nil {
+		info, err := d.Info()
+		if err != nil || info.Size() > fallbackMaxFileSize {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || isBinary(content) {
 			return nil // Skip files we can't read
 		}
 
-		if match {
-			matches = append(matches, grepMatch{
-				path:     path,
-				modTime:  info.ModTime(),
-				lineNum:  lineNum,
-				lineText: lineText,
-			})
-
-			if len(matches) >= collectLimit {
-				capped = true
-				return filepath.SkipAll
-			}
+		lines, count := s.searchFile(path, string(content), mode == "files_with_matches")
+		if count == 0 {
+			return nil
 		}
+		files = append(files, grepFile{path: path, modTime: info.ModTime(), count: count})
+		matches = append(matches, lines...)
 
+		size := len(files)
+		if mode == "content" {
+			size = len(matches)
+		}
+		if collectLimit > 0 && size >= collectLimit {
+			capped = true
+			return filepath.SkipAll
+		}
 		return nil
 	})
 	if err != nil {
-		return nil, false, err
-	}
-
-	return matches, capped, nil
-}
-
-func fileContainsPattern(filePath string, pattern *regexp.Regexp) (bool, int, string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false, 0, "", err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		if pattern.MatchString(line) {
-			return true, lineNum, line, nil
-		}
+		return nil, nil, false, err
 	}
-
-	return false, 0, "", scanner.Err()
-}
-
-func globToRegex(glob string) string {
-	regexPattern := strings.ReplaceAll(glob, ".", "\\.")
-	regexPattern = strings.ReplaceAll(regexPattern, "*", ".*")
-	regexPattern = strings.ReplaceAll(regexPattern, "?", ".")
-
-	re := regexp.MustCompile(`\{([^}]+)\}`)
-	regexPattern = re.ReplaceAllStringFunc(regexPattern, func(match string) string {
-		inner := match[1 : len(match)-1]
-		return "(" + strings.ReplaceAll(inner, ",", "|") + ")"
-	})
-
-	return regexPattern
+	return files, matches, capped, nil
 }
@@ -473,7 +473,7 @@ func TestGrepTool_ReturnsResultsNormally(t *testing.T) {
 	}
 }
 
-func TestSearchFilesWithRegex_RespectsContextCancellation(t *testing.T) {
+func TestRegexSearch_RespectsContextCancellation(t *testing.T) {
 	dir := t.TempDir()
 	sub := filepath.Join(dir, "sub")
 	os.MkdirAll(sub, 0755)
@@ -484,7 +484,11 @@ func TestSearchFilesWithRegex_RespectsContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, _, err := searchFilesWithRegex(ctx, "package", dir, "", 0)
+	s, err := newRegexSearch("package", dir, &GrepParams{}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = s.walk(ctx, "files_with_matches", 0)
 	if err == nil {
 		t.Fatal("expected error for cancelled context")
 	}
@@ -631,11 +635,11 @@ func TestBuildRipgrepArgs(t *testing.T) {
 		}
 	})
 
-	t.Run("content mode includes field-match-separator", func(t *testing.T) {
+	t.Run("content mode uses json output", func(t *testing.T) {
 		args := buildRipgrepArgs("test", &GrepParams{}, "", "content", nil)
 		joined := strings.Join(args, " ")
-		if !strings.Contains(joined, "--field-match-separator=") {
-			t.Errorf("content mode should include --field-match-separator, got: %s", joined)
+		if !strings.Contains(joined, "--json") {
+			t.Errorf("content mode should include --json, got: %s", joined)
 		}
 	})
 
@@ -953,3 +957,99 @@ func TestPaginate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRipgrepJSON(t *testing.T) {
+	raw := strings.Join([]string{
+		`{"type":"begin","data":{"path":{"text":"/repo/a.go"}}}`,
+		`{"type":"context","data":{"path":{"text":"/repo/a.go"},"lines":{"text":"package a\n"},"line_number":1,"submatches":[]}}`,
+		`{"type":"match","data":{"path":{"text":"/repo/a.go"},"lines":{"text":"\tfunc main() {\n"},"line_number":2,"submatches":[{"match":{"text":"func"},"start":1,"end":5}]}}`,
+		`{"type":"match","data":{"path":{"text":"/repo/b.go"},"lines":{"text":"type T struct {\n\tName string\n"},"line_number":7,"submatches":[{"match":{"text":"struct {\n\tName"},"start":7,"end":20}]}}`,
+		`{"type":"end","data":{"path":{"text":"/repo/b.go"}}}`,
+		`{"type":"summary","data":{}}`,
+	}, "\n")
+
+	got := parseRipgrepJSON(raw, "/repo")
+	want := []GrepMatch{
+		{File: "a.go", Line: 1, Preview: "package a", Context: true},
+		{File: "a.go", Line: 2, Column: 2, Preview: "\tfunc main() {"},
+		{File: "b.go", Line: 7, Column: 8, Preview: "type T struct {"},
+		{File: "b.go", Line: 8, Preview: "\tName string"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d matches, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGrepTool_StructuredMatches(t *testing.T) {
+	dir := setupGrepTestDir(t, map[string]string{
+		"code.go": "package main\n\nfunc main() {\n\tx := target()\n}\n",
+	})
+
+	ctxLines := 1
+	resp, err := runGrep(t, GrepParams{
+		Pattern:    "target",
+		Path:       dir,
+		OutputMode: "content",
+		Context:    &ctxLines,
+		Column:     true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resp.Content, "code.go:4:7:\tx := target()") {
+		t.Errorf("column=true should show file:line:column:text, got:\n%s", resp.Content)
+	}
+	if !strings.Contains(resp.Content, "code.go-3-func main() {") {
+		t.Errorf("context line missing, got:\n%s", resp.Content)
+	}
+
+	var meta GrepResponseMetadata
+	if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+	if meta.NumberOfMatches != 1 || len(meta.Matches) != 3 {
+		t.Fatalf("want 1 match and 3 lines in metadata, got %+v", meta)
+	}
+	if m := meta.Matches[1]; m.File != "code.go" || m.Line != 4 || m.Column != 7 || m.Context {
+		t.Errorf("unexpected match %+v", m)
+	}
+}
+
+func TestGrepTool_UsageErrors(t *testing.T) {
+	dir := setupGrepTestDir(t, map[string]string{"a.go": "package a\n"})
+
+	for name, params := range map[string]GrepParams{
+		"invalid regex": {Pattern: "(unclosed", Path: dir},
+		"unknown type":  {Pattern: "package", Path: dir, FileType: "no-such-type"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := runGrep(t, params)
+			if err != nil {
+				t.Fatalf("should be reported to the model, got error: %v", err)
+			}
+			if !resp.IsError {
+				t.Errorf("expected an error response, got:\n%s", resp.Content)
+			}
+		})
+	}
+}
+
+func TestRegexSearch_Multiline(t *testing.T) {
+	content := "type Foo struct {\n\tName string\n}\n"
+	s, err := newRegexSearch(`struct \{\s*Name`, "/repo", &GrepParams{Multiline: true}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, count := s.searchFile("/repo/foo.go", content, false)
+	if count != 2 || len(lines) != 2 {
+		t.Fatalf("want the match to span 2 lines, got %d: %+v", count, lines)
+	}
+	if lines[0].Line != 1 || lines[0].Column != 10 || lines[1].Line != 2 || lines[1].Column != 0 {
+		t.Errorf("unexpected lines %+v", lines)
+	}
+}
@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/task"
+)
+
+// Job tools manage the processes started by bash run_in_background (and
+// monitor). A job ID is the task_id those tools return.
+const (
+	JobStatusToolName = "job_status"
+	JobOutputToolName = "job_output"
+	JobKillToolName   = "job_kill"
+
+	defaultJobOutputBytes = 16 * 1024
+)
+
+type JobParams struct {
+	JobID string `json:"job_id"`
+}
+
+type JobOutputParams struct {
+	JobID string `json:"job_id"`
+	// Offset is the byte offset to read from. Nil reads the tail.
+	Offset   *int64 `json:"offset,omitempty"`
+	MaxBytes int    `json:"max_bytes,omitempty"`
+}
+
+var jobIDParameter = map[string]any{
+	"type":        "string",
+	"description": "The task_id returned by bash with run_in_background (or by monitor)",
+}
+
+// lookupJob resolves a job of the calling session. A non-empty errMsg is
+// meant for the model.
+func lookupJob(ctx context.Context, jobID string) (tk *task.Task, reg task.Registry, errMsg string, err error) {
+	jobID = strings.TrimSpace(jobID)
+	if jobID == "" {
+		return nil, nil, "job_id is required", nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return nil, nil, "", errors.New("session id is required")
+	}
+	reg = task.GlobalRegistry()
+	if reg == nil {
+		return nil, nil, "background jobs not available: task registry not initialized", nil
+	}
+	tk, ok := reg.Get(jobID)
+	if !ok {
+		return nil, nil, fmt.Sprintf("No job found with ID: %s", jobID), nil
+	}
+	if tk.SessionID != sessionID {
+		return nil, nil, fmt.Sprintf("Job %s does not belong to this session", jobID), nil
+	}
+	if tk.Kind != task.KindBash && tk.Kind != task.KindMonitor {
+		return nil, nil, fmt.Sprintf("%s is a %s task, not a shell job; use tasklist or taskstop for it", jobID, tk.Kind), nil
+	}
+	return tk, reg, "", nil
+}
+
+type jobStatusTool struct{}
+
+func NewJobStatusTool() BaseTool { return &jobStatusTool{} }
+
+func (t *jobStatusTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: JobStatusToolName,
+		Description: `Report the state of one background shell job: running / completed / failed / killed, start time, runtime, exit code and how many bytes of output it has written.
+
+Use it to check that a server or watcher you started with bash run_in_background is still up. Builds and tests notify you automatically when they exit — do NOT call this in a loop waiting for them.`,
+		Parameters: map[string]any{
+			"job_id": jobIDParameter,
+		},
+		Required: []string{"job_id"},
+	}
+}
+
+func (t *jobStatusTool) AllowParallelism(ToolCall, []ToolCall) bool { return true }
+func (t *jobStatusTool) IsBaseline() bool                           { return false }
+
+func (t *jobStatusTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params JobParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid parameters: %s", err)), nil
+	}
+	tk, _, errMsg, err := lookupJob(ctx, params.JobID)
+	if err != nil {
+		return NewEmptyResponse(), err
+	}
+	if errMsg != "" {
+		return NewTextErrorResponse(errMsg), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "job_id: %s\nstate: %s\n", tk.ID, tk.State())
+	if tk.Description != "" {
+		fmt.Fprintf(&sb, "description: %s\n", tk.Description)
+	}
+	fmt.Fprintf(&sb, "started_at: %s\n", tk.StartedAt.Format(time.RFC3339))
+	end := time.Now()
+	if finished := tk.FinishedAt(); !finished.IsZero() {
+		end = finished
+		fmt.Fprintf(&sb, "finished_at: %s\n", finished.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&sb, "runtime: %s\n", end.Sub(tk.StartedAt).Round(time.Second))
+	if code, ok := tk.ExitCode(); ok {
+		fmt.Fprintf(&sb, "exit_code: %d\n", code)
+	}
+	if info, err := os.Stat(tk.OutputPath); err == nil {
+		fmt.Fprintf(&sb, "output_bytes: %d\n", info.Size())
+	}
+	fmt.Fprintf(&sb, "output_file: %s", tk.OutputPath)
+	return NewTextResponse(sb.String()), nil
+}
+
+type jobOutputTool struct {
+	limits OutputLimits
+}
+
+func NewJobOutputTool(limits OutputLimits) BaseTool { return &jobOutputTool{limits: limits} }
+
+func (t *jobOutputTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: JobOutputToolName,
+		Description: fmt.Sprintf(`Read the combined stdout/stderr a background shell job has written so far, while it is still running or after it finished.
+
+Without offset the last max_bytes of output are returned. Every response ends with next_offset; pass it as offset on a later call to read only what was written since. Use this to check a dev server's startup log or the progress of a long build when you actually need it — finished jobs notify you automatically, so do NOT sleep and re-read in a loop.
+
+max_bytes defaults to %d and is capped at %d.`, min(defaultJobOutputBytes, t.limits.outputBytes()), t.limits.outputBytes()),
+		Parameters: map[string]any{
+			"job_id": jobIDParameter,
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "Byte offset to start reading at, usually the next_offset of a previous call. Omit to read the tail.",
+			},
+			"max_bytes": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of bytes to return",
+			},
+		},
+		Required: []string{"job_id"},
+	}
+}
+
+func (t *jobOutputTool) AllowParallelism(ToolCall, []ToolCall) bool { return true }
+func (t *jobOutputTool) IsBaseline() bool                           { return false }
+
+func (t *jobOutputTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params JobOutputParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid parameters: %s", err)), nil
+	}
+	tk, _, errMsg, err := lookupJob(ctx, params.JobID)
+	if err != nil {
+		return NewEmptyResponse(), err
+	}
+	if errMsg != "" {
+		return NewTextErrorResponse(errMsg), nil
+	}
+	maxBytes := params.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultJobOutputBytes
+	}
+	maxBytes = min(maxBytes, t.limits.outputBytes())
+
+	f, err := os.Open(tk.OutputPath)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("reading output of %s: %v", tk.ID, err)), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("reading output of %s: %v", tk.ID, err)), nil
+	}
+	size := info.Size()
+
+	start := max(size-int64(maxBytes), 0)
+	if params.Offset != nil {
+		start = min(max(*params.Offset, 0), size)
+	}
+	buf := make([]byte, min(int64(maxBytes), size-start))
+	n, err := f.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return NewTextErrorResponse(fmt.Sprintf("reading output of %s: %v", tk.ID, err)), nil
+	}
+	chunk := buf[:n]
+	// Keep whole runes: drop a partial one at the start (tail reads) and
+	// leave a partial one at the end for the next read.
+	if params.Offset == nil {
+		for len(chunk) > 0 && !utf8.RuneStart(chunk[0]) {
+			chunk = chunk[1:]
+			start++
+		}
+	}
+	for i := len(chunk) - 1; i >= 0 && i >= len(chunk)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(chunk[i]) {
+			if !utf8.FullRune(chunk[i:]) {
+				chunk = chunk[:i]
+			}
+			break
+		}
+	}
+	next := start + int64(len(chunk))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "job_id: %s (%s)\nbytes %d-%d of %d\n\n", tk.ID, tk.State(), start, next, size)
+	if len(chunk) == 0 {
+		sb.WriteString("(no new output)")
+	} else {
+		sb.Write(chunk)
+	}
+	fmt.Fprintf(&sb, "\n\nnext_offset: %d", next)
+	return NewTextResponse(sb.String()), nil
+}
+
+type jobKillTool struct {
+	permissions permission.Service
+	registry    agentregistry.Registry
+}
+
+func NewJobKillTool(perm permission.Service, reg agentregistry.Registry) BaseTool {
+	return &jobKillTool{permissions: perm, registry: reg}
+}
+
+func (t *jobKillTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: JobKillToolName,
+		Description: `Stop a background shell job: SIGTERM to its whole process group, escalated to SIGKILL after 5 seconds. Returns once the process has exited and its killed notification was written.
+
+Stop servers and watchers you started once you no longer need them. Jobs are also stopped when the user cancels the session.`,
+		Parameters: map[string]any{
+			"job_id": jobIDParameter,
+		},
+		Required: []string{"job_id"},
+	}
+}
+
+func (t *jobKillTool) AllowParallelism(ToolCall, []ToolCall) bool { return false }
+func (t *jobKillTool) IsBaseline() bool                           { return false }
+
+func (t *jobKillTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params JobParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid parameters: %s", err)), nil
+	}
+	tk, reg, errMsg, err := lookupJob(ctx, params.JobID)
+	if err != nil {
+		return NewEmptyResponse(), err
+	}
+	if errMsg != "" {
+		return NewTextErrorResponse(errMsg), nil
+	}
+	if tk.State() != task.StateRunning {
+		return NewTextResponse(fmt.Sprintf("Job %s is not running (state: %s); no kill performed.", tk.ID, tk.State())), nil
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	action := t.registry.EvaluatePermission(string(GetAgentID(ctx)), JobKillToolName, tk.ID)
+	switch action {
+	case permission.ActionAllow:
+	case permission.ActionDeny:
+		return NewEmptyResponse(), permission.ErrorPermissionDenied
+	default:
+		ok := t.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolName:    JobKillToolName,
+			Action:      "kill",
+			Description: fmt.Sprintf("Kill background job %s (%s)", tk.ID, tk.Description),
+			Params:      params,
+		})
+		if !ok {
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+	}
+
+	forced, err := killAndAwait(reg, tk)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("kill failed: %v", err)), nil
+	}
+	if forced {
+		return NewTextResponse(fmt.Sprintf("Job %s killed (forced).", tk.ID)), nil
+	}
+	return NewTextResponse(fmt.Sprintf("Job %s killed.", tk.ID)), nil
+}
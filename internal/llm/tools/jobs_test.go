@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/task"
+)
+
+// registerJob registers a bash task of session s1 whose output file holds
+// output.
+func registerJob(t *testing.T, kind task.Kind, output string) string {
+	t.Helper()
+	reg := task.GlobalRegistry()
+	id := task.NewTaskID(kind)
+	path, f, err := reg.PrepareOutputFile(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(output); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := reg.Register(&task.Task{ID: id, SessionID: "s1", Kind: kind, OutputPath: path, Description: "dev server"}); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestJobOutput_TailAndOffset(t *testing.T) {
+	_, cleanup := setupForToolTest(t)
+	defer cleanup()
+	id := registerJob(t, task.KindBash, "line1\nline2\nline3\n")
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "s1")
+	tool := NewJobOutputTool(OutputLimits{})
+
+	resp, err := tool.Run(ctx, ToolCall{Input: `{"job_id":"` + id + `","max_bytes":6}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "line3") || strings.Contains(resp.Content, "line2") {
+		t.Errorf("tail read: %q", resp.Content)
+	}
+	if !strings.HasSuffix(resp.Content, "next_offset: 18") {
+		t.Errorf("tail next_offset: %q", resp.Content)
+	}
+
+	resp, err = tool.Run(ctx, ToolCall{Input: `{"job_id":"` + id + `","offset":6,"max_bytes":6}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "line2") || strings.Contains(resp.Content, "line3") {
+		t.Errorf("offset read: %q", resp.Content)
+	}
+	if !strings.HasSuffix(resp.Content, "next_offset: 12") {
+		t.Errorf("offset next_offset: %q", resp.Content)
+	}
+
+	resp, err = tool.Run(ctx, ToolCall{Input: `{"job_id":"` + id + `","offset":18}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "(no new output)") {
+		t.Errorf("read at end: %q", resp.Content)
+	}
+}
+
+func TestJobOutput_KeepsWholeRunes(t *testing.T) {
+	_, cleanup := setupForToolTest(t)
+	defer cleanup()
+	id := registerJob(t, task.KindBash, "aé") // é is two bytes
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "s1")
+
+	resp, err := NewJobOutputTool(OutputLimits{}).Run(ctx, ToolCall{Input: `{"job_id":"` + id + `","offset":0,"max_bytes":2}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(resp.Content, "next_offset: 1") {
+		t.Errorf("partial rune not left for next read: %q", resp.Content)
+	}
+}
+
+func TestJobStatus(t *testing.T) {
+	_, cleanup := setupForToolTest(t)
+	defer cleanup()
+	id := registerJob(t, task.KindBash, "ready\n")
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "s1")
+
+	resp, err := NewJobStatusTool().Run(ctx, ToolCall{Input: `{"job_id":"` + id + `"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"job_id: " + id, "state: running", "description: dev server", "output_bytes: 6"} {
+		if !strings.Contains(resp.Content, want) {
+			t.Errorf("status missing %q: %q", want, resp.Content)
+		}
+	}
+}
+
+func TestJobTools_RejectForeignJobs(t *testing.T) {
+	_, cleanup := setupForToolTest(t)
+	defer cleanup()
+	shell := registerJob(t, task.KindBash, "")
+	subagent := registerJob(t, task.KindTask, "")
+
+	other := context.WithValue(context.Background(), SessionIDContextKey, "s2")
+	resp, err := NewJobStatusTool().Run(other, ToolCall{Input: `{"job_id":"` + shell + `"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content, "does not belong") {
+		t.Errorf("cross-session job not refused: %+v", resp)
+	}
+
+	own := context.WithValue(context.Background(), SessionIDContextKey, "s1")
+	resp, err = NewJobOutputTool(OutputLimits{}).Run(own, ToolCall{Input: `{"job_id":"` + subagent + `"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content, "not a shell job") {
+		t.Errorf("subagent task not refused: %+v", resp)
+	}
+}
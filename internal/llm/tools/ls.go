@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
@@ -38,6 +39,7 @@ type lsTool struct {
 	cfg         config.Configurator
 	registry    agentregistry.Registry
 	permissions permission.Service
+	limits      OutputLimits
 }
 
 const (
@@ -63,7 +65,7 @@ FEATURES:
 - Can filter out files matching specific patterns
 
 LIMITATIONS:
-- Results are limited to 1000 files
+- Results are limited to ${maxFiles} files
 - Very large directories will be truncated
 - Does not show file sizes or permissions
 - Cannot recursively list all directories in a large project
@@ -76,14 +78,14 @@ TIPS:
 - Combine with other tools for more effective exploration`
 )
 
-func NewLsTool(cfg config.Configurator, reg agentregistry.Registry, permissions permission.Service) BaseTool {
-	return &lsTool{cfg: cfg, registry: reg, permissions: permissions}
+func NewLsTool(cfg config.Configurator, reg agentregistry.Registry, permissions permission.Service, limits OutputLimits) BaseTool {
+	return &lsTool{cfg: cfg, registry: reg, permissions: permissions, limits: limits}
 }
 
 func (l *lsTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        LSToolName,
-		Description: lsDescription,
+		Description: strings.ReplaceAll(lsDescription, "${maxFiles}", strconv.Itoa(l.limits.listFiles())),
 		Parameters: map[string]any{
 			"path": map[string]any{
 				"type":        "string",
@@ -127,7 +129,7 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 		return NewTextErrorResponse(fmt.Sprintf("path does not exist: %s", searchPath)), nil
 	}
 
-	files, truncated, err := listDirectory(ctx, searchPath, params.Ignore, MaxLSFiles)
+	files, truncated, err := listDirectory(ctx, searchPath, params.Ignore, l.limits.listFiles())
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error listing directory: %w", err)
 	}
@@ -155,7 +157,7 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	output := printTree(tree, searchPath)
 
 	if truncated {
-		output = fmt.Sprintf("There are more than %d files in the directory. Use a more specific path or use the Glob tool to find specific files. The first %d files and directories are included below:\n\n%s", MaxLSFiles, MaxLSFiles, output)
+		output = fmt.Sprintf("There are more than %d files in the directory. Use a more specific path or use the Glob tool to find specific files. The first %d files and directories are included below:\n\n%s", l.limits.listFiles(), l.limits.listFiles(), output)
 	}
 
 	return WithResponseMetadata(
@@ -114,7 +114,10 @@ func (t *lspTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 
 	// Find LSP clients that handle this file type
-	clients := t.lsp.ClientsForFile(file)
+	clients, err := t.lsp.WaitClientsForFile(ctx, file)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 	if len(clients) == 0 {
 		return NewTextErrorResponse("no LSP server available for this file type"), nil
 	}
@@ -0,0 +1,413 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type LspSymbolsParams struct {
+	Operation string `json:"operation"`
+	FilePath  string `json:"file_path"`
+	Line      int    `json:"line,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	Character int    `json:"character,omitempty"`
+}
+
+type LspSymbolsResponseMetadata struct {
+	Title   string `json:"title"`
+	Results int    `json:"results"`
+}
+
+type lspSymbolsTool struct {
+	lsp         lsp.LspService
+	registry    agentregistry.Registry
+	permissions permission.Service
+}
+
+const (
+	LspSymbolsToolName    = "lsp_symbols"
+	lspSymbolsMaxResults  = 100
+	lspSymbolsMaxSnippet  = 200
+	lspSymbolsDescription = `Navigate code semantically through the language server: jump to a definition, list every reference to a symbol, or outline the symbols declared in a file.
+
+Operations:
+- definition: where the symbol at the given position is declared
+- references: every use of the symbol at the given position, declaration included
+- symbols: the functions, types, methods, fields and variables declared in file_path, nested by scope
+
+Results are "path:line: source line" snippets that can be opened directly with the view tool.
+
+Usage:
+- definition and references need a position: line (1-based) plus either symbol, the identifier as it appears on that line, or character (1-based column). Prefer symbol; it saves counting columns
+- symbols only needs file_path
+- Prefer this tool over grep when following a call or finding the users of a function or type: it resolves the actual symbol, so same-named identifiers in other scopes and matches in comments or strings are not reported
+- Fall back to grep when no language server handles the file type`
+)
+
+var lspSymbolsOperations = []string{"definition", "references", "symbols"}
+
+func NewLspSymbolsTool(lspService lsp.LspService, reg agentregistry.Registry, permissions permission.Service) BaseTool {
+	return &lspSymbolsTool{lsp: lspService, registry: reg, permissions: permissions}
+}
+
+func (t *lspSymbolsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        LspSymbolsToolName,
+		Description: lspSymbolsDescription,
+		Parameters: map[string]any{
+			"operation": map[string]any{
+				"type":        "string",
+				"description": "What to look up",
+				"enum":        lspSymbolsOperations,
+			},
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The absolute or working-directory relative path of the file",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "The 1-based line the symbol is on (definition and references)",
+			},
+			"symbol": map[string]any{
+				"type":        "string",
+				"description": "The identifier on that line to look up; its first occurrence on the line is used",
+			},
+			"character": map[string]any{
+				"type":        "integer",
+				"description": "The 1-based column of the symbol, when symbol is not given",
+			},
+		},
+		Required: []string{"operation", "file_path"},
+	}
+}
+
+func (t *lspSymbolsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params LspSymbolsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	switch params.Operation {
+	case "definition", "references", "symbols":
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("invalid operation %q: must be one of %s", params.Operation, strings.Join(lspSymbolsOperations, ", "))), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+
+	root := config.WorkingDirectory()
+	file := params.FilePath
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(root, file)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("file not found: %s", file)), nil
+	}
+	if err := checkReadPermission(ctx, t.registry, t.permissions, LspSymbolsToolName, file); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Permission denied: reading %s", file)), nil
+	}
+
+	var pos protocol.Position
+	if params.Operation != "symbols" {
+		var err error
+		if pos, err = symbolPosition(file, params.Line, params.Symbol, params.Character); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+
+	clients, err := t.lsp.WaitClientsForFile(ctx, file)
+	if err != nil {
+		return NewTextErrorResponse(err.Error() + "; use grep instead"), nil
+	}
+	if len(clients) == 0 {
+		return NewTextErrorResponse("no LSP server available for this file type; use grep instead"), nil
+	}
+
+	uri := protocol.URIFromPath(file)
+	title := fmt.Sprintf("%s %s", params.Operation, toRelativePath(file, root))
+	if params.Operation != "symbols" {
+		title = fmt.Sprintf("%s:%d", title, params.Line)
+		if params.Symbol != "" {
+			title += " " + params.Symbol
+		}
+	}
+
+	var lastErr error
+	for _, client := range clients {
+		if err := client.OpenFile(ctx, file); err != nil {
+			lastErr = err
+			continue
+		}
+		output, n, err := runLspSymbolsOperation(ctx, client, params.Operation, uri, pos, root)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return WithResponseMetadata(NewTextResponse(output), LspSymbolsResponseMetadata{Title: title, Results: n}), nil
+	}
+	return NewTextErrorResponse(fmt.Sprintf("LSP %s failed: %s", params.Operation, lastErr)), nil
+}
+
+func (t *lspSymbolsTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
+	return true
+}
+
+func (t *lspSymbolsTool) IsBaseline() bool { return true }
+
+func runLspSymbolsOperation(ctx context.Context, client *lsp.Client, operation string, uri protocol.DocumentUri, pos protocol.Position, root string) (string, int, error) {
+	textDocPos := protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}
+	switch operation {
+	case "definition":
+		result, err := client.Definition(ctx, protocol.DefinitionParams{TextDocumentPositionParams: textDocPos})
+		if err != nil {
+			return "", 0, err
+		}
+		locs := definitionLocations(result)
+		return formatLocations("definition", locs, root), len(locs), nil
+	case "references":
+		locs, err := client.References(ctx, protocol.ReferenceParams{
+			TextDocumentPositionParams: textDocPos,
+			Context:                    protocol.ReferenceContext{IncludeDeclaration: true},
+		})
+		if err != nil {
+			return "", 0, err
+		}
+		return formatLocations("reference", locs, root), len(locs), nil
+	default:
+		result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		})
+		if err != nil {
+			return "", 0, err
+		}
+		out, n := formatDocumentSymbols(result, uri.Path(), root)
+		return out, n, nil
+	}
+}
+
+// symbolPosition converts the 1-based line and either symbol or character
+// to an LSP position. Columns are counted in UTF-16 code units, which is
+// what servers expect unless another encoding was negotiated.
+func symbolPosition(file string, line int, symbol string, character int) (protocol.Position, error) {
+	if line < 1 {
+		return protocol.Position{}, fmt.Errorf("line is required and must be 1 or greater")
+	}
+	if symbol == "" {
+		if character < 1 {
+			return protocol.Position{}, fmt.Errorf("either symbol or character is required")
+		}
+		return protocol.Position{Line: uint32(line - 1), Character: uint32(character - 1)}, nil
+	}
+	text, ok := readLines(file, map[int]bool{line: true})[line]
+	if !ok {
+		return protocol.Position{}, fmt.Errorf("line %d is past the end of %s", line, file)
+	}
+	idx := indexIdentifier(text, symbol)
+	if idx < 0 {
+		return protocol.Position{}, fmt.Errorf("symbol %q not found on line %d: %s", symbol, line, strings.TrimSpace(text))
+	}
+	return protocol.Position{Line: uint32(line - 1), Character: uint32(utf16Len(text[:idx]))}, nil
+}
+
+// indexIdentifier finds symbol in text as a whole word, falling back to any
+// occurrence so that qualified names like "pkg.Func" still resolve.
+func indexIdentifier(text, symbol string) int {
+	isIdent := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+	}
+	for off := 0; ; {
+		i := strings.Index(text[off:], symbol)
+		if i < 0 {
+			break
+		}
+		start, end := off+i, off+i+len(symbol)
+		if (start == 0 || !isIdent(text[start-1])) && (end == len(text) || !isIdent(text[end])) {
+			return start
+		}
+		off = start + 1
+	}
+	return strings.Index(text, symbol)
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func definitionLocations(result protocol.Or_Result_textDocument_definition) []protocol.Location {
+	switch v := result.Value.(type) {
+	case protocol.Definition:
+		switch d := v.Value.(type) {
+		case protocol.Location:
+			return []protocol.Location{d}
+		case []protocol.Location:
+			return d
+		}
+	case []protocol.DefinitionLink:
+		locs := make([]protocol.Location, 0, len(v))
+		for _, link := range v {
+			locs = append(locs, protocol.Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+		}
+		return locs
+	}
+	return nil
+}
+
+// formatLocations renders locations as "path:line: source" lines ordered by
+// file and line, reading every file once.
+func formatLocations(noun string, locs []protocol.Location, root string) string {
+	if len(locs) == 0 {
+		return fmt.Sprintf("No %s found", noun)
+	}
+	locs = append([]protocol.Location(nil), locs...)
+	sort.SliceStable(locs, func(i, j int) bool {
+		if locs[i].URI != locs[j].URI {
+			return locs[i].URI < locs[j].URI
+		}
+		return locs[i].Range.Start.Line < locs[j].Range.Start.Line
+	})
+	shown := locs
+	if len(shown) > lspSymbolsMaxResults {
+		shown = shown[:lspSymbolsMaxResults]
+	}
+
+	wanted := map[string]map[int]bool{}
+	for _, loc := range shown {
+		path := loc.URI.Path()
+		if wanted[path] == nil {
+			wanted[path] = map[int]bool{}
+		}
+		wanted[path][int(loc.Range.Start.Line)+1] = true
+	}
+	sources := make(map[string]map[int]string, len(wanted))
+	for path, lines := range wanted {
+		sources[path] = readLines(path, lines)
+	}
+
+	var sb strings.Builder
+	if len(locs) == 1 {
+		fmt.Fprintf(&sb, "Found 1 %s\n", noun)
+	} else {
+		fmt.Fprintf(&sb, "Found %d %ss\n", len(locs), noun)
+	}
+	for _, loc := range shown {
+		path := loc.URI.Path()
+		line := int(loc.Range.Start.Line) + 1
+		fmt.Fprintf(&sb, "%s:%d: %s\n", toRelativePath(path, root), line, snippet(sources[path][line]))
+	}
+	if len(locs) > len(shown) {
+		fmt.Fprintf(&sb, "[%d more not shown]\n", len(locs)-len(shown))
+	}
+	return sb.String()
+}
+
+// formatDocumentSymbols renders a file outline, one symbol per line and
+// indented by nesting. Servers that answer with flat SymbolInformation get
+// a flat list.
+func formatDocumentSymbols(result protocol.Or_Result_textDocument_documentSymbol, file, root string) (string, int) {
+	rel := toRelativePath(file, root)
+	var sb strings.Builder
+	n := 0
+	var walk func(symbols []protocol.DocumentSymbol, depth int)
+	walk = func(symbols []protocol.DocumentSymbol, depth int) {
+		for _, s := range symbols {
+			n++
+			fmt.Fprintf(&sb, "%s%s %s", strings.Repeat("  ", depth), symbolKindName(s.Kind), s.Name)
+			if s.Detail != "" {
+				fmt.Fprintf(&sb, " %s", snippet(s.Detail))
+			}
+			fmt.Fprintf(&sb, " — %s:%d\n", rel, s.SelectionRange.Start.Line+1)
+			walk(s.Children, depth+1)
+		}
+	}
+	switch v := result.Value.(type) {
+	case []protocol.DocumentSymbol:
+		walk(v, 0)
+	case []protocol.SymbolInformation:
+		for _, s := range v {
+			n++
+			name := s.Name
+			if s.ContainerName != "" {
+				name = s.ContainerName + "." + name
+			}
+			fmt.Fprintf(&sb, "%s %s — %s:%d\n", symbolKindName(s.Kind), name, rel, s.Location.Range.Start.Line+1)
+		}
+	}
+	if n == 0 {
+		return fmt.Sprintf("No symbols found in %s", rel), 0
+	}
+	return sb.String(), n
+}
+
+var symbolKindNames = map[protocol.SymbolKind]string{
+	protocol.File: "file", protocol.Module: "module", protocol.Namespace: "namespace",
+	protocol.Package: "package", protocol.Class: "class", protocol.Method: "method",
+	protocol.Property: "property", protocol.Field: "field", protocol.Constructor: "constructor",
+	protocol.Enum: "enum", protocol.Interface: "interface", protocol.Function: "func",
+	protocol.Variable: "var", protocol.Constant: "const", protocol.String: "string",
+	protocol.Number: "number", protocol.Boolean: "bool", protocol.Array: "array",
+	protocol.Object: "object", protocol.Key: "key", protocol.Null: "null",
+	protocol.EnumMember: "enum member", protocol.Struct: "struct", protocol.Event: "event",
+	protocol.Operator: "operator", protocol.TypeParameter: "type parameter",
+}
+
+func symbolKindName(kind protocol.SymbolKind) string {
+	if name, ok := symbolKindNames[kind]; ok {
+		return name
+	}
+	return "symbol"
+}
+
+// readLines returns the requested 1-based lines of path. Missing files and
+// lines are left out.
+func readLines(path string, lines map[int]bool) map[int]string {
+	out := make(map[int]string, len(lines))
+	f, err := os.Open(path)
+	if err != nil {
+		return out
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for n := 1; sc.Scan() && len(out) < len(lines); n++ {
+		if lines[n] {
+			out[n] = sc.Text()
+		}
+	}
+	return out
+}
+
+func snippet(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > lspSymbolsMaxSnippet {
+		cut := lspSymbolsMaxSnippet
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut] + "…"
+	}
+	return s
+}
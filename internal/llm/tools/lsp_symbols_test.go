@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lspSymbolsTestSource = `package main
+
+// Greet says hello. Greet is exported.
+func Greet(name string) string {
+	return "hi " + name
+}
+
+func main() { println(Greet("€x"), greeting, greet) }
+`
+
+func writeLspSymbolsFixture(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte(lspSymbolsTestSource), 0o644))
+	return dir, path
+}
+
+func TestSymbolPosition(t *testing.T) {
+	_, path := writeLspSymbolsFixture(t)
+
+	pos, err := symbolPosition(path, 8, "Greet", 0)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.Position{Line: 7, Character: 22}, pos)
+
+	// "greet" first occurs inside "greeting"; the whole word comes later.
+	pos, err = symbolPosition(path, 8, "greet", 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(45), pos.Character, "columns are UTF-16 code units, not bytes, after the euro sign")
+
+	pos, err = symbolPosition(path, 4, "", 6)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.Position{Line: 3, Character: 5}, pos)
+
+	_, err = symbolPosition(path, 4, "Missing", 0)
+	assert.ErrorContains(t, err, `symbol "Missing" not found on line 4`)
+	_, err = symbolPosition(path, 4, "", 0)
+	assert.ErrorContains(t, err, "either symbol or character")
+	_, err = symbolPosition(path, 99, "Greet", 0)
+	assert.ErrorContains(t, err, "past the end")
+}
+
+func TestFormatLocations(t *testing.T) {
+	dir, path := writeLspSymbolsFixture(t)
+	uri := protocol.URIFromPath(path)
+	at := func(line uint32) protocol.Location {
+		return protocol.Location{URI: uri, Range: protocol.Range{Start: protocol.Position{Line: line}}}
+	}
+
+	out := formatLocations("reference", []protocol.Location{at(7), at(3)}, dir)
+	assert.Equal(t, "Found 2 references\nmain.go:4: func Greet(name string) string {\nmain.go:8: func main() { println(Greet(\"€x\"), greeting, greet) }\n", out)
+	assert.Equal(t, "No definition found", formatLocations("definition", nil, dir))
+
+	links := protocol.Or_Result_textDocument_definition{Value: []protocol.DefinitionLink{{TargetURI: uri, TargetSelectionRange: protocol.Range{Start: protocol.Position{Line: 3, Character: 5}}}}}
+	assert.Equal(t, []protocol.Location{{URI: uri, Range: protocol.Range{Start: protocol.Position{Line: 3, Character: 5}}}}, definitionLocations(links))
+	single := protocol.Or_Result_textDocument_definition{Value: protocol.Definition{Value: at(3)}}
+	assert.Len(t, definitionLocations(single), 1)
+}
+
+func TestFormatDocumentSymbols(t *testing.T) {
+	dir, path := writeLspSymbolsFixture(t)
+	result := protocol.Or_Result_textDocument_documentSymbol{Value: []protocol.DocumentSymbol{
+		{Name: "Server", Kind: protocol.Struct, SelectionRange: protocol.Range{Start: protocol.Position{Line: 2}}, Children: []protocol.DocumentSymbol{
+			{Name: "Addr", Kind: protocol.Field, Detail: "string", SelectionRange: protocol.Range{Start: protocol.Position{Line: 3}}},
+		}},
+		{Name: "Greet", Kind: protocol.Function, Detail: "func(name string) string", SelectionRange: protocol.Range{Start: protocol.Position{Line: 3}}},
+	}}
+
+	out, n := formatDocumentSymbols(result, path, dir)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "struct Server — main.go:3\n  field Addr string — main.go:4\nfunc Greet func(name string) string — main.go:4\n", out)
+
+	out, n = formatDocumentSymbols(protocol.Or_Result_textDocument_documentSymbol{}, path, dir)
+	assert.Zero(t, n)
+	assert.Equal(t, "No symbols found in main.go", out)
+}
+
+func TestLspSymbolsTool_Validation(t *testing.T) {
+	_, path := writeLspSymbolsFixture(t)
+	tool := NewLspSymbolsTool(&noopLspService{}, nil, nil)
+	run := func(params LspSymbolsParams) ToolResponse {
+		input, _ := json.Marshal(params)
+		resp, err := tool.Run(t.Context(), ToolCall{Input: string(input)})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := run(LspSymbolsParams{Operation: "hover", FilePath: path})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "invalid operation")
+
+	resp = run(LspSymbolsParams{Operation: "definition", FilePath: path, Line: 4})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "either symbol or character")
+
+	resp = run(LspSymbolsParams{Operation: "symbols", FilePath: path})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "no LSP server available")
+}
@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/memory"
+)
+
+const (
+	MemoryWriteToolName = "memory_write"
+	MemoryReadToolName  = "memory_read"
+
+	defaultMemoryReadLimit = 50
+)
+
+// MemoryService is the interface the memory tools require.
+type MemoryService interface {
+	Write(ctx context.Context, key, content string, priority memory.Priority) (memory.Memory, error)
+	List(ctx context.Context, q memory.Query) ([]memory.Memory, error)
+	Delete(ctx context.Context, keyOrID string) (memory.Memory, error)
+}
+
+type memoryWriteTool struct {
+	memories MemoryService
+}
+
+type memoryWriteParams struct {
+	Key      string `json:"key"`
+	Content  string `json:"content"`
+	Priority string `json:"priority"`
+	Delete   bool   `json:"delete"`
+}
+
+func NewMemoryWriteTool(memories MemoryService) BaseTool {
+	return &memoryWriteTool{memories: memories}
+}
+
+func (t *memoryWriteTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: MemoryWriteToolName,
+		Description: `Remember a fact about this project for future sessions, or forget one. Memories are stored per project and outlive the current session.
+
+## When to use
+- The user states a preference or convention ("always use pnpm", "never touch the generated files in gen/")
+- You learn something non-obvious that took effort to find: how to run the tests, where a config really lives, why an odd piece of code exists
+- A remembered fact turns out to be wrong or outdated: overwrite it, or delete it
+
+## When not to use
+- Anything only relevant to the current task; the conversation already holds it
+- Facts that are obvious from the code or the project's instruction files
+- Secrets, credentials or personal data
+
+## Rules
+- Keep each memory to one short, self-contained fact
+- Give facts you may need to update a stable key (e.g. "test-command"); writing the same key again replaces the memory
+- Use priority "high" only for facts every future session must know; high-priority memories are put into the system prompt of every agent
+- To forget a memory, set delete to true and pass its key or ID as key`,
+		Parameters: map[string]any{
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Stable name of the memory; writing an existing key replaces it. With delete, the key or ID of the memory to forget",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("The fact to remember (at most %d characters)", memory.MaxContentLength),
+			},
+			"priority": map[string]any{
+				"type":        "string",
+				"enum":        []string{string(memory.PriorityNormal), string(memory.PriorityHigh)},
+				"description": `"high" puts the memory into every future system prompt; "normal" (default) keeps it available through memory_read`,
+			},
+			"delete": map[string]any{
+				"type":        "boolean",
+				"description": "Forget the memory named by key instead of writing one",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *memoryWriteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params memoryWriteParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	if params.Delete {
+		m, err := t.memories.Delete(ctx, params.Key)
+		if errors.Is(err, memory.ErrNotFound) {
+			return NewTextErrorResponse(fmt.Sprintf("no memory with key or ID %q", params.Key)), nil
+		}
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		return NewTextResponse(fmt.Sprintf("Forgot memory %s.", describeMemory(m))), nil
+	}
+
+	m, err := t.memories.Write(ctx, params.Key, params.Content, memory.Priority(strings.ToLower(params.Priority)))
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	verb := "Remembered"
+	if m.CreatedAt != m.UpdatedAt {
+		verb = "Updated"
+	}
+	return NewTextResponse(fmt.Sprintf("%s memory %s (%s priority).", verb, describeMemory(m), m.Priority)), nil
+}
+
+func (t *memoryWriteTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return false
+}
+
+func (t *memoryWriteTool) IsBaseline() bool { return true }
+
+type memoryReadTool struct {
+	memories MemoryService
+}
+
+type memoryReadParams struct {
+	Key   string `json:"key"`
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+func NewMemoryReadTool(memories MemoryService) BaseTool {
+	return &memoryReadTool{memories: memories}
+}
+
+func (t *memoryReadTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: MemoryReadToolName,
+		Description: `Recall facts remembered about this project in earlier sessions. Memories are returned most recently updated first with their ID, key, priority and content.
+
+## When to use
+- At the start of unfamiliar work, to pick up conventions and decisions from earlier sessions
+- Before asking the user something they may already have told you in a past session
+- Before writing a memory, to check whether one with the same meaning already exists
+
+High-priority memories are already in your system prompt; this tool also returns the normal-priority ones.`,
+		Parameters: map[string]any{
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Only the memory with this key",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Only memories whose key or content contains this text (case-insensitive)",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of memories to return (default %d)", defaultMemoryReadLimit),
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *memoryReadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params memoryReadParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultMemoryReadLimit
+	}
+
+	memories, err := t.memories.List(ctx, memory.Query{Key: params.Key, Contains: params.Query, Limit: limit})
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(memories) == 0 {
+		return NewTextResponse("No matching memories."), nil
+	}
+
+	var sb strings.Builder
+	for i, m := range memories {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "[%s] %s", m.ID, time.Unix(m.UpdatedAt, 0).Format(time.DateOnly))
+		if m.Key != "" {
+			fmt.Fprintf(&sb, " %s", m.Key)
+		}
+		if m.Priority == memory.PriorityHigh {
+			sb.WriteString(" (high)")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+	return NewTextResponse(sb.String()), nil
+}
+
+func (t *memoryReadTool) AllowParallelism(_ ToolCall, _ []ToolCall) bool {
+	return true
+}
+
+func (t *memoryReadTool) IsBaseline() bool { return true }
+
+func describeMemory(m memory.Memory) string {
+	if m.Key != "" {
+		return fmt.Sprintf("%q", m.Key)
+	}
+	return m.ID
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/memory"
+)
+
+type fakeMemories struct {
+	written   []memory.Memory
+	deleted   []string
+	lastQuery memory.Query
+	listed    []memory.Memory
+}
+
+func (f *fakeMemories) Write(_ context.Context, key, content string, priority memory.Priority) (memory.Memory, error) {
+	if priority == "" {
+		priority = memory.PriorityNormal
+	}
+	m := memory.Memory{ID: "m1", Key: key, Content: content, Priority: priority, CreatedAt: 1, UpdatedAt: 1}
+	f.written = append(f.written, m)
+	return m, nil
+}
+
+func (f *fakeMemories) List(_ context.Context, q memory.Query) ([]memory.Memory, error) {
+	f.lastQuery = q
+	return f.listed, nil
+}
+
+func (f *fakeMemories) Delete(_ context.Context, keyOrID string) (memory.Memory, error) {
+	if keyOrID == "missing" {
+		return memory.Memory{}, memory.ErrNotFound
+	}
+	f.deleted = append(f.deleted, keyOrID)
+	return memory.Memory{ID: "m1", Key: keyOrID}, nil
+}
+
+func TestMemoryWriteStoresAndForgets(t *testing.T) {
+	store := &fakeMemories{}
+	tool := NewMemoryWriteTool(store)
+
+	resp, err := tool.Run(context.Background(), ToolCall{Input: `{"key":"test-command","content":"make test","priority":"HIGH"}`})
+	if err != nil || resp.IsError {
+		t.Fatalf("write: err=%v resp=%+v", err, resp)
+	}
+	if len(store.written) != 1 || store.written[0].Priority != memory.PriorityHigh {
+		t.Fatalf("written = %+v", store.written)
+	}
+	if !strings.Contains(resp.Content, `"test-command"`) {
+		t.Fatalf("response = %q", resp.Content)
+	}
+
+	resp, _ = tool.Run(context.Background(), ToolCall{Input: `{"key":"test-command","delete":true}`})
+	if resp.IsError || len(store.deleted) != 1 {
+		t.Fatalf("delete: resp=%+v deleted=%v", resp, store.deleted)
+	}
+	resp, _ = tool.Run(context.Background(), ToolCall{Input: `{"key":"missing","delete":true}`})
+	if !resp.IsError {
+		t.Fatal("deleting an unknown memory should fail")
+	}
+}
+
+func TestMemoryReadFormatsAndDefaultsLimit(t *testing.T) {
+	store := &fakeMemories{listed: []memory.Memory{
+		{ID: "m1", Key: "style", Content: "tabs, not spaces", Priority: memory.PriorityHigh},
+		{ID: "m2", Content: "a note", Priority: memory.PriorityNormal},
+	}}
+	tool := NewMemoryReadTool(store)
+
+	resp, err := tool.Run(context.Background(), ToolCall{Input: `{"query":"tabs"}`})
+	if err != nil || resp.IsError {
+		t.Fatalf("read: err=%v resp=%+v", err, resp)
+	}
+	if store.lastQuery.Contains != "tabs" || store.lastQuery.Limit != defaultMemoryReadLimit {
+		t.Fatalf("query = %+v", store.lastQuery)
+	}
+	for _, want := range []string{"[m1]", "style (high)", "tabs, not spaces", "[m2]", "a note"} {
+		if !strings.Contains(resp.Content, want) {
+			t.Fatalf("response missing %q: %q", want, resp.Content)
+		}
+	}
+
+	store.listed = nil
+	resp, _ = tool.Run(context.Background(), ToolCall{Input: ""})
+	if resp.Content != "No matching memories." {
+		t.Fatalf("empty response = %q", resp.Content)
+	}
+}
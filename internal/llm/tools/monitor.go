@@ -137,10 +137,10 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		return NewTextErrorResponse(fmt.Sprintf("invalid pattern regex: %s", err)), nil
 	}
 
-	cwd := params.Cwd
-	if cwd == "" {
-		cwd = config.WorkingDirectory()
+	if params.Cwd == "" {
+		params.Cwd = config.WorkingDirectory()
 	}
+	cwd := params.Cwd
 
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
@@ -158,6 +158,7 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		ok := m.permissions.Request(ctx, permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        cwd,
+			Pattern:     params.Cmd,
 			ToolName:    MonitorToolName,
 			Action:      "spawn",
 			Description: fmt.Sprintf("Monitor: %s (pattern: %s)", joinCommand(params.Cmd, params.Args), params.Pattern),
@@ -168,14 +169,45 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		}
 	}
 
+	if task.GlobalRegistry() == nil {
+		return NewTextErrorResponse("monitor: task registry not initialized"), nil
+	}
+	taskID, outputPath, err := startMonitor(sessionID, string(GetAgentID(ctx)), call.ID, params, re)
+	if errors.Is(err, errMonitorSpawn) {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if err != nil {
+		return NewEmptyResponse(), err
+	}
+
+	ack := fmt.Sprintf(
+		"Monitor started.\ntask_id: %s\noutput_file: %s\ncmd: %s\npattern: %s\nmin_interval_ms: %d\nmax_events: %d\n\nMatching lines will arrive as synthetic monitor-event notifications. A terminal notification (completed / failed / killed) fires when the subprocess exits, max_events is reached, or you call taskstop. Do NOT poll — the events arrive automatically.",
+		taskID, outputPath, joinCommand(params.Cmd, params.Args), params.Pattern, params.MinIntervalMs, params.MaxEvents,
+	)
+	return WithResponseMetadata(NewTextResponse(ack), MonitorResponseMetadata{
+		TaskID:        taskID,
+		OutputPath:    outputPath,
+		Pattern:       params.Pattern,
+		MinIntervalMs: params.MinIntervalMs,
+		MaxEvents:     params.MaxEvents,
+	}), nil
+}
+
+// errMonitorSpawn marks a command that could not be started, which is
+// reported back to the model rather than treated as an internal failure.
+var errMonitorSpawn = errors.New("failed to start command")
+
+// startMonitor spawns an already validated and permission-checked monitor
+// and registers it as a background task of sessionID.
+func startMonitor(sessionID, agentID, callID string, params MonitorParams, re *regexp.Regexp) (string, string, error) {
 	reg := task.GlobalRegistry()
 	if reg == nil {
-		return NewTextErrorResponse("monitor: task registry not initialized"), nil
+		return "", "", errors.New("monitor: task registry not initialized")
 	}
 	taskID := task.NewTaskID(task.KindMonitor)
 	outputPath, outputFile, err := reg.PrepareOutputFile(taskID)
 	if err != nil {
-		return NewEmptyResponse(), fmt.Errorf("monitor: prepare output file: %w", err)
+		return "", "", fmt.Errorf("monitor: prepare output file: %w", err)
 	}
 
 	// Pipe stdout+stderr through a tee. cmd.Stdout and cmd.Stderr both
@@ -183,7 +215,7 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 	// can scan the merged stream while every byte is also written to disk.
 	pr, pw := io.Pipe()
 	cmd := exec.Command(params.Cmd, params.Args...)
-	cmd.Dir = cwd
+	cmd.Dir = params.Cwd
 	cmd.Stdout = pw
 	cmd.Stderr = pw
 	// The monitored leaf becomes its own process-group leader so taskstop
@@ -196,7 +228,7 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		_ = pw.Close()
 		_ = outputFile.Close()
 		_ = os.Remove(outputPath)
-		return NewTextErrorResponse(fmt.Sprintf("failed to start command: %v", err)), nil
+		return "", "", fmt.Errorf("%w: %v", errMonitorSpawn, err)
 	}
 
 	tk := &task.Task{
@@ -204,7 +236,7 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		SessionID:             sessionID,
 		Kind:                  task.KindMonitor,
 		OutputPath:            outputPath,
-		OriginatingToolCallID: call.ID,
+		OriginatingToolCallID: callID,
 		OriginatingToolName:   MonitorToolName,
 		Description:           params.Description,
 		Proc:                  cmd.Process,
@@ -214,13 +246,19 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		_ = pw.Close()
 		_ = outputFile.Close()
 		_ = os.Remove(outputPath)
-		return NewEmptyResponse(), fmt.Errorf("monitor: register task: %w", err)
+		return "", "", fmt.Errorf("monitor: register task: %w", err)
 	}
+	runningMonitors.Store(taskID, RunningMonitor{
+		SessionID: sessionID,
+		AgentID:   agentID,
+		CallID:    callID,
+		Params:    params,
+	})
 
 	state := &monitorState{
 		taskID:      taskID,
 		sessionID:   sessionID,
-		callID:      call.ID,
+		callID:      callID,
 		params:      params,
 		re:          re,
 		outputFile:  outputFile,
@@ -233,18 +271,44 @@ func (m *monitorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 	go state.scanLoop(pr)
 	go state.coalesceLoop(syntheticInput)
 	go state.waitAndFinalize(cmd, pw, syntheticInput)
+	return taskID, outputPath, nil
+}
 
-	ack := fmt.Sprintf(
-		"Monitor started.\ntask_id: %s\noutput_file: %s\ncmd: %s\npattern: %s\nmin_interval_ms: %d\nmax_events: %d\n\nMatching lines will arrive as synthetic monitor-event notifications. A terminal notification (completed / failed / killed) fires when the subprocess exits, max_events is reached, or you call taskstop. Do NOT poll — the events arrive automatically.",
-		taskID, outputPath, joinCommand(params.Cmd, params.Args), params.Pattern, params.MinIntervalMs, params.MaxEvents,
-	)
-	return WithResponseMetadata(NewTextResponse(ack), MonitorResponseMetadata{
-		TaskID:        taskID,
-		OutputPath:    outputPath,
-		Pattern:       params.Pattern,
-		MinIntervalMs: params.MinIntervalMs,
-		MaxEvents:     params.MaxEvents,
-	}), nil
+// RunningMonitor describes a live monitor task with the normalized
+// parameters it was started with.
+type RunningMonitor struct {
+	SessionID string
+	AgentID   string
+	CallID    string
+	Params    MonitorParams
+}
+
+// runningMonitors maps task IDs to the monitors that are still streaming.
+var runningMonitors sync.Map
+
+// RunningMonitors returns the monitors still running, in no particular order.
+func RunningMonitors() []RunningMonitor {
+	var out []RunningMonitor
+	runningMonitors.Range(func(_, v any) bool {
+		out = append(out, v.(RunningMonitor))
+		return true
+	})
+	return out
+}
+
+// RestartMonitor starts m again, e.g. after the process was restarted. The
+// permission recorded for its agent is re-evaluated; a denial refuses the
+// restart, anything else is treated as consent given when it first ran.
+func RestartMonitor(reg agentregistry.Registry, m RunningMonitor) (string, error) {
+	if reg != nil && reg.EvaluatePermission(m.AgentID, MonitorToolName, m.Params.Cmd) == permission.ActionDeny {
+		return "", permission.ErrorPermissionDenied
+	}
+	re, err := regexp.Compile(m.Params.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern regex: %w", err)
+	}
+	taskID, _, err := startMonitor(m.SessionID, m.AgentID, m.CallID, m.Params, re)
+	return taskID, err
 }
 
 // monitorState carries the bookkeeping for one running monitor task. All
@@ -375,6 +439,7 @@ func (s *monitorState) killForBudget() {
 func (s *monitorState) waitAndFinalize(cmd *exec.Cmd, pw *io.PipeWriter, syntheticInput string) {
 	defer logging.RecoverPanic("monitor.waitAndFinalize", nil)
 	waitErr := cmd.Wait()
+	runningMonitors.Delete(s.taskID)
 	// Closing the write end signals the scanLoop to return; the buffered
 	// reader drains any remaining bytes first.
 	_ = pw.Close()
@@ -27,6 +27,7 @@ type MultiEditItem struct {
 type MultiEditParams struct {
 	FilePath string          `json:"file_path"`
 	Edits    []MultiEditItem `json:"edits"`
+	DryRun   bool            `json:"dry_run,omitempty"`
 }
 
 type MultiEditPermissionEdit struct {
@@ -43,6 +44,7 @@ type MultiEditResponseMetadata struct {
 	Diff      string `json:"diff"`
 	Additions int    `json:"additions"`
 	Removals  int    `json:"removals"`
+	DryRun    bool   `json:"dry_run,omitempty"`
 }
 
 type multiEditTool struct {
@@ -67,6 +69,7 @@ To make multiple file edits, provide the following:
    - old_string: The text to replace (must match the file contents exactly, including all whitespace and indentation)
    - new_string: The edited text to replace the old_string
    - replace_all: Replace all occurrences of old_string. This parameter is optional and defaults to false.
+3. dry_run: (optional) Return the combined diff without changing the file
 
 IMPORTANT:
 - All edits are applied in sequence, in the order they are provided
@@ -131,6 +134,10 @@ func (m *multiEditTool) Info() ToolInfo {
 					"required": []string{"old_string", "new_string"},
 				},
 			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": dryRunParamDescription,
+			},
 		},
 		Required: []string{"file_path", "edits"},
 	}
@@ -154,6 +161,9 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 		wd := config.WorkingDirectory()
 		params.FilePath = filepath.Join(wd, params.FilePath)
 	}
+	if err := checkSandbox(params.FilePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
 	fileInfo, err := os.Stat(params.FilePath)
 	if err != nil {
@@ -247,6 +257,11 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 	if strings.HasPrefix(params.FilePath, rootDir) {
 		permissionPath = rootDir
 	}
+	if IsDryRun(ctx, params.DryRun) {
+		return dryRunResponse(ctx, m.registry, MultiEditToolName, params.FilePath,
+			fmt.Sprintf("Would apply %d edits to file %s (+%d -%d).", len(params.Edits), params.FilePath, additions, removals), combinedDiff,
+			MultiEditResponseMetadata{Diff: combinedDiff, Additions: additions, Removals: removals, DryRun: true})
+	}
 	action := m.registry.EvaluatePermission(string(GetAgentID(ctx)), MultiEditToolName, params.FilePath)
 	switch action {
 	case permission.ActionAllow:
@@ -258,6 +273,7 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        permissionPath,
+				Pattern:     params.FilePath,
 				ToolName:    MultiEditToolName,
 				Action:      "write",
 				Description: fmt.Sprintf("Apply %d edits to file %s", len(params.Edits), params.FilePath),
@@ -272,6 +288,11 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 		}
 	}
 
+	if err := chargeDiffBudget(ctx, m.permissions, MultiEditToolName, []string{params.FilePath}, additions+removals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	checkpointFile(ctx, m.files, params.FilePath)
 	err = os.WriteFile(params.FilePath, []byte(currentContent), 0o644)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
@@ -0,0 +1,601 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+const (
+	NotebookReadToolName = "notebook_read"
+	NotebookEditToolName = "notebook_edit"
+
+	// maxNotebookOutputBytes caps each cell output shown by notebook_read;
+	// outputs are evidence of a past run, not something to page through.
+	maxNotebookOutputBytes = 2000
+)
+
+// notebook is an .ipynb document. Fields the tools do not touch are kept
+// as raw JSON so that saving preserves them.
+type notebook struct {
+	fields map[string]json.RawMessage
+	cells  []notebookCell
+}
+
+type notebookCell map[string]json.RawMessage
+
+func loadNotebook(path string) (*notebook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("not a valid notebook: %w", err)
+	}
+	var cells []notebookCell
+	if raw, ok := fields["cells"]; ok {
+		if err := json.Unmarshal(raw, &cells); err != nil {
+			return nil, fmt.Errorf("not a valid notebook: cells: %w", err)
+		}
+	}
+	return &notebook{fields: fields, cells: cells}, nil
+}
+
+// encode serialises the notebook the way Jupyter does: one-space indent,
+// sorted keys, unescaped non-ASCII and a trailing newline.
+func (nb *notebook) encode() ([]byte, error) {
+	cells, err := marshalNotebookJSON(nb.cells, "")
+	if err != nil {
+		return nil, err
+	}
+	nb.fields["cells"] = cells
+	return marshalNotebookJSON(nb.fields, " ")
+}
+
+// marshalNotebookJSON encodes v without escaping <, > and &, which would
+// otherwise show up as spurious diffs in every cell that contains them.
+func marshalNotebookJSON(v any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// usesCellIDs reports whether the notebook format (4.5+) requires cell ids.
+func (nb *notebook) usesCellIDs() bool {
+	var major, minor int
+	_ = json.Unmarshal(nb.fields["nbformat"], &major)
+	_ = json.Unmarshal(nb.fields["nbformat_minor"], &minor)
+	return major > 4 || (major == 4 && minor >= 5)
+}
+
+// findCell resolves a cell id, or a 0-based index for notebooks without
+// ids, to a position.
+func (nb *notebook) findCell(id string) (int, bool) {
+	for i, c := range nb.cells {
+		if c.str("id") == id {
+			return i, true
+		}
+	}
+	if i, err := strconv.Atoi(id); err == nil && i >= 0 && i < len(nb.cells) {
+		return i, true
+	}
+	return 0, false
+}
+
+func (c notebookCell) str(key string) string {
+	var s string
+	_ = json.Unmarshal(c[key], &s)
+	return s
+}
+
+// label is how notebook_read and notebook_edit refer to the cell at index.
+func (c notebookCell) label(index int) string {
+	if id := c.str("id"); id != "" {
+		return id
+	}
+	return strconv.Itoa(index)
+}
+
+// multiline decodes a Jupyter string field, which is either a string or a
+// list of lines.
+func multiline(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var lines []string
+	_ = json.Unmarshal(raw, &lines)
+	return strings.Join(lines, "")
+}
+
+func (c notebookCell) source() string { return multiline(c["source"]) }
+
+func (c notebookCell) setSource(src string) {
+	lines := strings.SplitAfter(src, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	c["source"], _ = marshalNotebookJSON(lines, "")
+}
+
+// setType switches the cell between code and markdown, adding or removing
+// the fields only code cells have.
+func (c notebookCell) setType(cellType string) {
+	c["cell_type"], _ = json.Marshal(cellType)
+	if cellType == "code" {
+		if _, ok := c["outputs"]; !ok {
+			c["outputs"] = json.RawMessage("[]")
+		}
+		if _, ok := c["execution_count"]; !ok {
+			c["execution_count"] = json.RawMessage("null")
+		}
+		return
+	}
+	delete(c, "outputs")
+	delete(c, "execution_count")
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// renderOutputs summarises the outputs of a code cell as text. Rich data
+// is named but not included.
+func renderOutputs(raw json.RawMessage) string {
+	var outputs []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &outputs); err != nil || len(outputs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, out := range outputs {
+		var text string
+		switch notebookCell(out).str("output_type") {
+		case "stream":
+			text = multiline(out["text"])
+		case "execute_result", "display_data":
+			var data map[string]json.RawMessage
+			_ = json.Unmarshal(out["data"], &data)
+			if plain, ok := data["text/plain"]; ok {
+				text = multiline(plain)
+			}
+			for _, mime := range slices.Sorted(maps.Keys(data)) {
+				if mime != "text/plain" {
+					text += fmt.Sprintf("\n[%s output omitted]", mime)
+				}
+			}
+		case "error":
+			var traceback []string
+			_ = json.Unmarshal(out["traceback"], &traceback)
+			text = fmt.Sprintf("%s: %s\n%s", notebookCell(out).str("ename"), notebookCell(out).str("evalue"), strings.Join(traceback, "\n"))
+		}
+		text = strings.TrimRight(ansiEscape.ReplaceAllString(text, ""), "\n")
+		if text == "" {
+			continue
+		}
+		if len(text) > maxNotebookOutputBytes {
+			cut := maxNotebookOutputBytes
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + fmt.Sprintf("\n... [%d more bytes]", len(text)-cut)
+		}
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+type NotebookReadParams struct {
+	NotebookPath string `json:"notebook_path"`
+	CellID       string `json:"cell_id,omitempty"`
+}
+
+type notebookReadTool struct {
+	registry    agentregistry.Registry
+	permissions permission.Service
+}
+
+func NewNotebookReadTool(reg agentregistry.Registry, permissions permission.Service) BaseTool {
+	return &notebookReadTool{registry: reg, permissions: permissions}
+}
+
+func (t *notebookReadTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: NotebookReadToolName,
+		Description: fmt.Sprintf(`Reads a Jupyter notebook (.ipynb) as a list of cells: each cell's id, type and source, followed by the text of its outputs from the last run.
+
+Use this instead of read for notebooks — the raw JSON is hard to follow and editing it with edit or write routinely corrupts the file. Change notebooks with notebook_edit, using the cell ids shown here.
+
+Images and other rich outputs are only named, and each output is cut at %d bytes. Pass cell_id to read a single cell.`, maxNotebookOutputBytes),
+		Parameters: map[string]any{
+			"notebook_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the .ipynb file",
+			},
+			"cell_id": map[string]any{
+				"type":        "string",
+				"description": "Only show this cell",
+			},
+		},
+		Required: []string{"notebook_path"},
+	}
+}
+
+func (t *notebookReadTool) AllowParallelism(ToolCall, []ToolCall) bool { return true }
+func (t *notebookReadTool) IsBaseline() bool                           { return false }
+
+func (t *notebookReadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params NotebookReadParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.NotebookPath == "" {
+		return NewTextErrorResponse("notebook_path is required"), nil
+	}
+	path := params.NotebookPath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.WorkingDirectory(), path)
+	}
+	if err := checkReadPermission(ctx, t.registry, t.permissions, NotebookReadToolName, path); err != nil {
+		return NewEmptyResponse(), err
+	}
+
+	nb, err := loadNotebook(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("File not found: %s", path)), nil
+		}
+		return NewTextErrorResponse(fmt.Sprintf("Error reading %s: %s", path, err)), nil
+	}
+	recordFileRead(path)
+
+	cells := nb.cells
+	first := 0
+	if params.CellID != "" {
+		i, ok := nb.findCell(params.CellID)
+		if !ok {
+			return NewTextErrorResponse(fmt.Sprintf("Cell %s not found in %s", params.CellID, path)), nil
+		}
+		cells, first = nb.cells[i:i+1], i
+	}
+	if len(cells) == 0 {
+		return NewTextResponse(fmt.Sprintf("%s has no cells.", path)), nil
+	}
+
+	var sb strings.Builder
+	for i, c := range cells {
+		cellType := c.str("cell_type")
+		fmt.Fprintf(&sb, "<cell id=%q type=%q", c.label(first+i), cellType)
+		if cellType == "code" {
+			var count *int
+			_ = json.Unmarshal(c["execution_count"], &count)
+			if count != nil {
+				fmt.Fprintf(&sb, " execution_count=\"%d\"", *count)
+			}
+		}
+		sb.WriteString(">\n")
+		if src := c.source(); src != "" {
+			sb.WriteString(strings.TrimRight(src, "\n"))
+			sb.WriteString("\n")
+		}
+		if out := renderOutputs(c["outputs"]); out != "" {
+			sb.WriteString("<output>\n")
+			sb.WriteString(out)
+			sb.WriteString("</output>\n")
+		}
+		sb.WriteString("</cell>\n")
+	}
+	return NewTextResponse(sb.String()), nil
+}
+
+const (
+	NotebookEditModeReplace = "replace"
+	NotebookEditModeInsert  = "insert"
+	NotebookEditModeDelete  = "delete"
+)
+
+type NotebookEditParams struct {
+	NotebookPath string `json:"notebook_path"`
+	CellID       string `json:"cell_id,omitempty"`
+	NewSource    string `json:"new_source,omitempty"`
+	CellType     string `json:"cell_type,omitempty"`
+	EditMode     string `json:"edit_mode,omitempty"`
+}
+
+type NotebookEditPermissionsParams struct {
+	NotebookPath string `json:"notebook_path"`
+	CellID       string `json:"cell_id"`
+	EditMode     string `json:"edit_mode"`
+	Diff         string `json:"diff"`
+}
+
+type NotebookEditResponseMetadata struct {
+	CellID    string `json:"cell_id"`
+	EditMode  string `json:"edit_mode"`
+	Diff      string `json:"diff"`
+	Additions int    `json:"additions"`
+	Removals  int    `json:"removals"`
+}
+
+type notebookEditTool struct {
+	permissions permission.Service
+	files       history.Service
+	registry    agentregistry.Registry
+}
+
+func NewNotebookEditTool(permissions permission.Service, files history.Service, reg agentregistry.Registry) BaseTool {
+	return &notebookEditTool{permissions: permissions, files: files, registry: reg}
+}
+
+func (t *notebookEditTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: NotebookEditToolName,
+		Description: `Edits one cell of a Jupyter notebook (.ipynb) while keeping the rest of the file, including outputs and metadata, intact.
+
+edit_mode:
+- replace (default): replace the source of cell_id. cell_type may change the cell between code and markdown. The outputs of a changed code cell are cleared, since they no longer match its source.
+- insert: add a new cell of cell_type (required) after cell_id, or at the top of the notebook when cell_id is omitted.
+- delete: remove cell_id.
+
+Read the notebook with notebook_read first; cell ids come from its output. Do not edit .ipynb files with edit or write.`,
+		Parameters: map[string]any{
+			"notebook_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the .ipynb file",
+			},
+			"cell_id": map[string]any{
+				"type":        "string",
+				"description": "The cell to replace or delete, or to insert after",
+			},
+			"new_source": map[string]any{
+				"type":        "string",
+				"description": "The new source of the cell",
+			},
+			"cell_type": map[string]any{
+				"type":        "string",
+				"enum":        []string{"code", "markdown"},
+				"description": "The type of the cell; required for insert",
+			},
+			"edit_mode": map[string]any{
+				"type":        "string",
+				"enum":        []string{NotebookEditModeReplace, NotebookEditModeInsert, NotebookEditModeDelete},
+				"description": "replace (default), insert or delete",
+			},
+		},
+		Required: []string{"notebook_path"},
+	}
+}
+
+func (t *notebookEditTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
+	var params NotebookEditParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return false
+	}
+	return !hasFileConflict(call, []string{params.NotebookPath}, allCalls)
+}
+
+func (t *notebookEditTool) IsBaseline() bool { return false }
+
+func (t *notebookEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params NotebookEditParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.NotebookPath == "" {
+		return NewTextErrorResponse("notebook_path is required"), nil
+	}
+	mode := params.EditMode
+	if mode == "" {
+		mode = NotebookEditModeReplace
+	}
+	switch mode {
+	case NotebookEditModeReplace, NotebookEditModeDelete:
+		if params.CellID == "" {
+			return NewTextErrorResponse(fmt.Sprintf("cell_id is required for %s", mode)), nil
+		}
+	case NotebookEditModeInsert:
+		if params.CellType == "" {
+			return NewTextErrorResponse("cell_type is required for insert"), nil
+		}
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("invalid edit_mode %q: want replace, insert or delete", params.EditMode)), nil
+	}
+	if params.CellType != "" && params.CellType != "code" && params.CellType != "markdown" {
+		return NewTextErrorResponse(fmt.Sprintf("invalid cell_type %q: want code or markdown", params.CellType)), nil
+	}
+
+	path := params.NotebookPath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.WorkingDirectory(), path)
+	}
+	if err := checkSandbox(path); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("File not found: %s", path)), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("error checking file: %w", err)
+	}
+	if lastRead := getLastReadTime(path); fileInfo.ModTime().After(lastRead) {
+		return NewTextErrorResponse(fmt.Sprintf("Notebook %s has been modified since it was last read.\nLast modification: %s\nLast read: %s\n\nPlease read it again with notebook_read before editing it.",
+			path, fileInfo.ModTime().Format(time.RFC3339), lastRead.Format(time.RFC3339))), nil
+	}
+
+	oldBytes, err := os.ReadFile(path)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error reading file: %w", err)
+	}
+	nb, err := loadNotebook(path)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Error reading %s: %s", path, err)), nil
+	}
+
+	var index int
+	if params.CellID != "" {
+		var ok bool
+		if index, ok = nb.findCell(params.CellID); !ok {
+			return NewTextErrorResponse(fmt.Sprintf("Cell %s not found in %s", params.CellID, path)), nil
+		}
+	}
+
+	var oldSource, newSource, cellLabel string
+	switch mode {
+	case NotebookEditModeReplace:
+		cell := nb.cells[index]
+		oldSource = cell.source()
+		newSource = params.NewSource
+		cellType := cell.str("cell_type")
+		if params.CellType != "" && params.CellType != cellType {
+			cellType = params.CellType
+			cell.setType(cellType)
+		} else if oldSource == newSource {
+			return NewTextErrorResponse(fmt.Sprintf("Cell %s already has this source. No changes made.", params.CellID)), nil
+		}
+		cell.setSource(newSource)
+		if cellType == "code" {
+			cell["outputs"] = json.RawMessage("[]")
+			cell["execution_count"] = json.RawMessage("null")
+		}
+		cellLabel = cell.label(index)
+	case NotebookEditModeInsert:
+		cell := notebookCell{"metadata": json.RawMessage("{}")}
+		if nb.usesCellIDs() {
+			cell["id"], _ = json.Marshal(newCellID())
+		}
+		cell.setType(params.CellType)
+		cell.setSource(params.NewSource)
+		newSource = params.NewSource
+		at := 0
+		if params.CellID != "" {
+			at = index + 1
+		}
+		nb.cells = append(nb.cells[:at], append([]notebookCell{cell}, nb.cells[at:]...)...)
+		cellLabel = cell.label(at)
+	case NotebookEditModeDelete:
+		oldSource = nb.cells[index].source()
+		cellLabel = nb.cells[index].label(index)
+		nb.cells = append(nb.cells[:index], nb.cells[index+1:]...)
+	}
+
+	newBytes, err := nb.encode()
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error encoding notebook: %w", err)
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return NewEmptyResponse(), fmt.Errorf("session_id and message_id are required")
+	}
+
+	cellDiff, additions, removals := diff.GenerateDiff(oldSource, newSource, fmt.Sprintf("%s#%s", path, cellLabel))
+
+	rootDir := config.WorkingDirectory()
+	permissionPath := filepath.Dir(path)
+	if strings.HasPrefix(path, rootDir) {
+		permissionPath = rootDir
+	}
+	action := t.registry.EvaluatePermission(string(GetAgentID(ctx)), NotebookEditToolName, path)
+	switch action {
+	case permission.ActionAllow:
+	case permission.ActionDeny:
+		return NewEmptyResponse(), permission.ErrorPermissionDenied
+	default:
+		p := t.permissions.Request(ctx,
+			permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        permissionPath,
+				Pattern:     path,
+				ToolName:    NotebookEditToolName,
+				Action:      "write",
+				Description: fmt.Sprintf("%s cell %s of %s", strings.ToUpper(mode[:1])+mode[1:], cellLabel, path),
+				Params: NotebookEditPermissionsParams{
+					NotebookPath: path,
+					CellID:       cellLabel,
+					EditMode:     mode,
+					Diff:         cellDiff,
+				},
+			},
+		)
+		if !p {
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+	}
+
+	if err := chargeDiffBudget(ctx, t.permissions, NotebookEditToolName, []string{path}, additions+removals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	checkpointFile(ctx, t.files, path)
+	if err := os.WriteFile(path, newBytes, fileInfo.Mode().Perm()); err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error writing file: %w", err)
+	}
+
+	oldContent := string(oldBytes)
+	file, err := t.files.GetByPathAndSession(ctx, path, sessionID)
+	if err != nil {
+		if _, err = t.files.Create(ctx, sessionID, path, oldContent); err != nil {
+			return NewEmptyResponse(), fmt.Errorf("error creating file history: %w", err)
+		}
+	}
+	if file.Content != oldContent {
+		if _, err = t.files.CreateVersion(ctx, sessionID, path, oldContent); err != nil {
+			logging.Debug("Error creating file history version", "error", err)
+		}
+	}
+	if _, err = t.files.CreateVersion(ctx, sessionID, path, string(newBytes)); err != nil {
+		logging.Debug("Error creating file history version", "error", err)
+	}
+
+	recordFileWrite(path)
+	recordFileRead(path)
+
+	var result string
+	switch mode {
+	case NotebookEditModeReplace:
+		result = fmt.Sprintf("Updated cell %s of %s", cellLabel, path)
+	case NotebookEditModeInsert:
+		result = fmt.Sprintf("Inserted %s cell %s into %s", params.CellType, cellLabel, path)
+	case NotebookEditModeDelete:
+		result = fmt.Sprintf("Deleted cell %s from %s", cellLabel, path)
+	}
+	return WithResponseMetadata(NewTextResponse(fmt.Sprintf("<result>\n%s\n</result>", result)),
+		NotebookEditResponseMetadata{
+			CellID:    cellLabel,
+			EditMode:  mode,
+			Diff:      cellDiff,
+			Additions: additions,
+			Removals:  removals,
+		},
+	), nil
+}
+
+func newCellID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
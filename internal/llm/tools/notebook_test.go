@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mock_permission "github.com/opencode-ai/opencode/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "id": "intro",
+   "metadata": {},
+   "source": [
+    "# Analysis <draft>\n"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "id": "load",
+   "metadata": {
+    "tags": [
+     "setup"
+    ]
+   },
+   "outputs": [
+    {
+     "name": "stdout",
+     "output_type": "stream",
+     "text": [
+      "loaded 42 rows\n"
+     ]
+    },
+    {
+     "data": {
+      "image/png": "iVBORw0KGgo=",
+      "text/plain": [
+       "<Figure size 640x480>"
+      ]
+     },
+     "metadata": {},
+     "output_type": "display_data"
+    }
+   ],
+   "source": [
+    "df = load()\n",
+    "print(f\"loaded {len(df)} rows\")"
+   ]
+  }
+ ],
+ "metadata": {
+  "kernelspec": {
+   "display_name": "Python 3",
+   "language": "python",
+   "name": "python3"
+  }
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func setupNotebookTest(t *testing.T) (context.Context, string, BaseTool, BaseTool) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+
+	path := filepath.Join(t.TempDir(), "analysis.ipynb")
+	require.NoError(t, os.WriteFile(path, []byte(testNotebook), 0o644))
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	return ctx, path, NewNotebookReadTool(&stubRegistry{}, mockPerms), NewNotebookEditTool(mockPerms, &stubHistoryService{}, &stubRegistry{})
+}
+
+func runNotebookTool(t *testing.T, tool BaseTool, ctx context.Context, params any) ToolResponse {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := tool.Run(ctx, ToolCall{Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
+func readNotebookJSON(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var nb map[string]any
+	require.NoError(t, json.Unmarshal(data, &nb))
+	return nb
+}
+
+func TestNotebookRead(t *testing.T) {
+	ctx, path, read, _ := setupNotebookTest(t)
+
+	resp := runNotebookTool(t, read, ctx, NotebookReadParams{NotebookPath: path})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, `<cell id="intro" type="markdown">`+"\n# Analysis <draft>\n</cell>")
+	assert.Contains(t, resp.Content, `<cell id="load" type="code" execution_count="3">`)
+	assert.Contains(t, resp.Content, "print(f\"loaded {len(df)} rows\")\n<output>\nloaded 42 rows\n<Figure size 640x480>\n[image/png output omitted]\n</output>")
+	assert.NotContains(t, resp.Content, "iVBORw0KGgo")
+
+	resp = runNotebookTool(t, read, ctx, NotebookReadParams{NotebookPath: path, CellID: "load"})
+	assert.NotContains(t, resp.Content, "intro")
+}
+
+func TestNotebookEdit_ReplaceClearsOutputs(t *testing.T) {
+	ctx, path, read, edit := setupNotebookTest(t)
+	runNotebookTool(t, read, ctx, NotebookReadParams{NotebookPath: path})
+
+	resp := runNotebookTool(t, edit, ctx, NotebookEditParams{NotebookPath: path, CellID: "load", NewSource: "df = load(limit=10)\n"})
+	require.False(t, resp.IsError, resp.Content)
+
+	nb := readNotebookJSON(t, path)
+	cell := nb["cells"].([]any)[1].(map[string]any)
+	assert.Equal(t, []any{"df = load(limit=10)\n"}, cell["source"])
+	assert.Empty(t, cell["outputs"])
+	assert.Nil(t, cell["execution_count"])
+	assert.Equal(t, map[string]any{"tags": []any{"setup"}}, cell["metadata"], "cell metadata must survive")
+	assert.Contains(t, nb, "metadata")
+
+	data, _ := os.ReadFile(path)
+	assert.Contains(t, string(data), "# Analysis <draft>", "HTML characters must not be escaped")
+	assert.True(t, strings.HasPrefix(string(data), "{\n \"cells\": [\n  {\n"), "Jupyter's one-space indent")
+}
+
+func TestNotebookEdit_InsertAndDelete(t *testing.T) {
+	ctx, path, read, edit := setupNotebookTest(t)
+	runNotebookTool(t, read, ctx, NotebookReadParams{NotebookPath: path})
+
+	resp := runNotebookTool(t, edit, ctx, NotebookEditParams{NotebookPath: path, CellID: "intro", EditMode: "insert", CellType: "code", NewSource: "import pandas"})
+	require.False(t, resp.IsError, resp.Content)
+	cells := readNotebookJSON(t, path)["cells"].([]any)
+	require.Len(t, cells, 3)
+	inserted := cells[1].(map[string]any)
+	assert.Equal(t, "code", inserted["cell_type"])
+	assert.Equal(t, []any{"import pandas"}, inserted["source"])
+	assert.NotEmpty(t, inserted["id"], "nbformat 4.5 cells need an id")
+	assert.Contains(t, inserted, "outputs")
+
+	resp = runNotebookTool(t, edit, ctx, NotebookEditParams{NotebookPath: path, CellID: "intro", EditMode: "delete"})
+	require.False(t, resp.IsError, resp.Content)
+	cells = readNotebookJSON(t, path)["cells"].([]any)
+	require.Len(t, cells, 2)
+	assert.Equal(t, "load", cells[1].(map[string]any)["id"])
+}
+
+func TestNotebookEdit_Validation(t *testing.T) {
+	ctx, path, read, edit := setupNotebookTest(t)
+
+	resp := runNotebookTool(t, edit, ctx, NotebookEditParams{NotebookPath: path, CellID: "load", NewSource: "x"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "modified since it was last read")
+
+	runNotebookTool(t, read, ctx, NotebookReadParams{NotebookPath: path})
+	resp = runNotebookTool(t, edit, ctx, NotebookEditParams{NotebookPath: path, CellID: "missing", NewSource: "x"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "not found")
+
+	resp = runNotebookTool(t, edit, ctx, NotebookEditParams{NotebookPath: path, EditMode: "insert", NewSource: "x"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "cell_type is required")
+}
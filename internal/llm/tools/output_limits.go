@@ -0,0 +1,62 @@
+package tools
+
+import "github.com/opencode-ai/opencode/internal/config"
+
+// OutputLimits caps how much a single tool call hands back to the model.
+// Zero fields fall back to the package defaults (MaxOutputBytes,
+// MaxOutputLines, MaxReadSize, DefaultReadLimit, MaxLineLength and
+// MaxLSFiles), so the zero value is the stock budget.
+type OutputLimits struct {
+	// MaxOutputBytes and MaxOutputLines bound bash, run_task and
+	// job_output before the output is spilled to a temp file.
+	MaxOutputBytes int
+	MaxOutputLines int
+	// MaxReadBytes is the largest file read opens; ReadLines caps the
+	// lines of one read call and MaxLineLength the characters per line.
+	MaxReadBytes  int
+	ReadLines     int
+	MaxLineLength int
+	// MaxListFiles caps the entries ls returns.
+	MaxListFiles int
+}
+
+// NewOutputLimits converts an agent's outputLimits config.
+func NewOutputLimits(cfg *config.OutputLimits) OutputLimits {
+	if cfg == nil {
+		return OutputLimits{}
+	}
+	return OutputLimits(*cfg)
+}
+
+// Merge returns l with the non-zero fields of override applied.
+func (l OutputLimits) Merge(override OutputLimits) OutputLimits {
+	pick := func(base, v int) int {
+		if v > 0 {
+			return v
+		}
+		return base
+	}
+	return OutputLimits{
+		MaxOutputBytes: pick(l.MaxOutputBytes, override.MaxOutputBytes),
+		MaxOutputLines: pick(l.MaxOutputLines, override.MaxOutputLines),
+		MaxReadBytes:   pick(l.MaxReadBytes, override.MaxReadBytes),
+		ReadLines:      pick(l.ReadLines, override.ReadLines),
+		MaxLineLength:  pick(l.MaxLineLength, override.MaxLineLength),
+		MaxListFiles:   pick(l.MaxListFiles, override.MaxListFiles),
+	}
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+func (l OutputLimits) outputBytes() int  { return orDefault(l.MaxOutputBytes, MaxOutputBytes) }
+func (l OutputLimits) outputLines() int  { return orDefault(l.MaxOutputLines, MaxOutputLines) }
+func (l OutputLimits) readBytes() int    { return orDefault(l.MaxReadBytes, MaxReadSize) }
+func (l OutputLimits) readLines() int    { return orDefault(l.ReadLines, DefaultReadLimit) }
+func (l OutputLimits) lineLength() int   { return orDefault(l.MaxLineLength, MaxLineLength) }
+func (l OutputLimits) listFiles() int    { return orDefault(l.MaxListFiles, MaxLSFiles) }
+func (l OutputLimits) previewLines() int { return max(1, min(TruncatedHeadLines, l.outputLines()/4)) }
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputLimitsMerge(t *testing.T) {
+	base := OutputLimits{MaxOutputBytes: 100, MaxOutputLines: 10}
+	got := base.Merge(OutputLimits{MaxOutputLines: 5, MaxListFiles: 3})
+	assert.Equal(t, OutputLimits{MaxOutputBytes: 100, MaxOutputLines: 5, MaxListFiles: 3}, got)
+
+	// Unset fields keep the package defaults.
+	assert.Equal(t, MaxOutputBytes, OutputLimits{}.outputBytes())
+	assert.Equal(t, 5, got.outputLines())
+}
+
+func TestPersistAndTruncateHonorsLimits(t *testing.T) {
+	var lines []string
+	for i := range 100 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	content := strings.Join(lines, "\n")
+
+	// Within the stock limits nothing is cut.
+	assert.Equal(t, content, persistAndTruncate(content, "stdout", BashToolName, OutputLimits{}).content)
+
+	result := persistAndTruncate(content, "stdout", BashToolName, OutputLimits{MaxOutputLines: 40})
+	require.NotEmpty(t, result.filePath)
+	assert.Contains(t, result.content, "--- First 10 lines ---")
+	assert.Contains(t, result.content, "--- Last 10 lines ---")
+}
+
+func TestReadTextFileLineLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", 50)+"\nshort\n"), 0o644))
+
+	content, _, err := readTextFile(path, 0, 10, 20)
+	require.NoError(t, err)
+	assert.Contains(t, content, strings.Repeat("x", 20)+"...")
+	assert.NotContains(t, content, strings.Repeat("x", 21))
+	assert.Contains(t, content, "short")
+}
@@ -3,6 +3,8 @@ package tools
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
 )
 
 func TestIsMutatingTool(t *testing.T) {
@@ -343,3 +345,30 @@ func TestPatchAllowParallelism(t *testing.T) {
 		}
 	})
 }
+
+func TestIsSafeReadOnlyCommand_WindowsShells(t *testing.T) {
+	tests := []struct {
+		command string
+		dialect shell.Dialect
+		want    bool
+	}{
+		{"Get-ChildItem -Recurse", shell.DialectPowerShell, true},
+		{"get-location", shell.DialectPowerShell, true},
+		{"& git status", shell.DialectPowerShell, true},
+		{"git.exe log --oneline", shell.DialectPowerShell, true},
+		{"Remove-Item -Recurse build", shell.DialectPowerShell, false},
+		{"Get-ChildItem", shell.DialectPOSIX, false},
+		{"dir/b", shell.DialectCmd, true},
+		{"ver", shell.DialectCmd, true},
+		{"git.exe diff", shell.DialectCmd, true},
+		{"del /q build", shell.DialectCmd, false},
+		{"git.exe diff", shell.DialectPOSIX, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect)+" "+tt.command, func(t *testing.T) {
+			if got := isSafeReadOnlyCommand(tt.command, tt.dialect); got != tt.want {
+				t.Errorf("isSafeReadOnlyCommand(%q, %s) = %v, want %v", tt.command, tt.dialect, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,12 +22,15 @@ import (
 
 type PatchParams struct {
 	PatchText string `json:"patch_text"`
+	DryRun    bool   `json:"dry_run,omitempty"`
 }
 
 type PatchResponseMetadata struct {
 	FilesChanged []string `json:"files_changed"`
 	Additions    int      `json:"additions"`
 	Removals     int      `json:"removals"`
+	Diff         string   `json:"diff,omitempty"`
+	DryRun       bool     `json:"dry_run,omitempty"`
 }
 
 type patchTool struct {
@@ -109,6 +114,10 @@ func (p *patchTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The full patch text that describes all changes to be made",
 			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": dryRunParamDescription,
+			},
 		},
 		Required: []string{"patch_text"},
 	}
@@ -213,6 +222,20 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	if err != nil {
 		return NewTextErrorResponse(fmt.Sprintf("failed to create commit from patch: %s", err)), nil
 	}
+	for filePath, change := range commit.Changes {
+		paths := []string{filePath}
+		if change.MovePath != nil {
+			paths = append(paths, *change.MovePath)
+		}
+		for _, path := range paths {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(config.WorkingDirectory(), path)
+			}
+			if err := checkSandbox(path); err != nil {
+				return NewTextErrorResponse(err.Error()), nil
+			}
+		}
+	}
 
 	// Get session ID and message ID
 	sessionID, messageID := GetContextValues(ctx)
@@ -220,6 +243,10 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return NewEmptyResponse(), fmt.Errorf("session ID and message ID are required for creating a patch")
 	}
 
+	if IsDryRun(ctx, params.DryRun) {
+		return p.dryRun(ctx, commit)
+	}
+
 	// Request permission for all changes
 	var combinedDiff string
 	needsPermission := false
@@ -271,6 +298,28 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 	}
 
+	// Count the whole patch against the turn's diff budget.
+	var budgetPaths []string
+	budgetLines := 0
+	for filePath, change := range commit.Changes {
+		oldContent, newContent := "", ""
+		if change.OldContent != nil {
+			oldContent = *change.OldContent
+		}
+		if change.NewContent != nil {
+			newContent = *change.NewContent
+		}
+		_, additions, removals := diff.GenerateDiff(oldContent, newContent, filePath)
+		budgetLines += additions + removals
+		budgetPaths = append(budgetPaths, filePath)
+		if change.MovePath != nil {
+			budgetPaths = append(budgetPaths, *change.MovePath)
+		}
+	}
+	if err := chargeDiffBudget(ctx, p.permissions, PatchToolName, budgetPaths, budgetLines); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
 	// Apply the changes to the filesystem
 	err = diff.ApplyCommit(commit, func(path string, content string) error {
 		absPath := path
@@ -285,6 +334,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			return fmt.Errorf("failed to create parent directories for %s: %w", absPath, err)
 		}
 
+		checkpointFile(ctx, p.files, absPath)
 		return os.WriteFile(absPath, []byte(content), 0o644)
 	}, func(path string) error {
 		absPath := path
@@ -292,6 +342,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			wd := config.WorkingDirectory()
 			absPath = filepath.Join(wd, absPath)
 		}
+		checkpointFile(ctx, p.files, absPath)
 		return os.Remove(absPath)
 	})
 	if err != nil {
@@ -385,6 +436,40 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}), nil
 }
 
+// dryRun reports the files a patch would change and their diffs without
+// writing anything.
+func (p *patchTool) dryRun(ctx context.Context, commit diff.Commit) (ToolResponse, error) {
+	paths := slices.Sorted(maps.Keys(commit.Changes))
+	var combinedDiff strings.Builder
+	meta := PatchResponseMetadata{DryRun: true}
+	for _, path := range paths {
+		if p.registry.EvaluatePermission(string(GetAgentID(ctx)), PatchToolName, path) == permission.ActionDeny {
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+		change := commit.Changes[path]
+		oldContent, newContent := "", ""
+		if change.OldContent != nil {
+			oldContent = *change.OldContent
+		}
+		if change.NewContent != nil {
+			newContent = *change.NewContent
+		}
+		fileDiff, additions, removals := diff.GenerateDiff(oldContent, newContent, path)
+		combinedDiff.WriteString(fileDiff + "\n")
+		meta.Additions += additions
+		meta.Removals += removals
+		absPath := path
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(config.WorkingDirectory(), absPath)
+		}
+		meta.FilesChanged = append(meta.FilesChanged, absPath)
+	}
+	meta.Diff = combinedDiff.String()
+	summary := fmt.Sprintf("Patch would change %d files, %d additions, %d removals.", len(paths), meta.Additions, meta.Removals)
+	// Permissions were checked per file above.
+	return dryRunResponse(ctx, nil, PatchToolName, "", summary, meta.Diff, meta)
+}
+
 func (p *patchTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
 	var params PatchParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
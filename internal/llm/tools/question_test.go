@@ -200,7 +200,10 @@ type mockPermissionService struct {
 
 func (m *mockPermissionService) Grant(_ permission.PermissionRequest)           {}
 func (m *mockPermissionService) GrantPersistant(_ permission.PermissionRequest) {}
-func (m *mockPermissionService) Deny(_ permission.PermissionRequest)            {}
+func (m *mockPermissionService) GrantProject(_ permission.PermissionRequest) error {
+	return nil
+}
+func (m *mockPermissionService) Deny(_ permission.PermissionRequest) {}
 func (m *mockPermissionService) Request(_ context.Context, _ permission.CreatePermissionRequest) bool {
 	return false
 }
@@ -217,6 +220,7 @@ func (m *mockPermissionService) LinkSession(_, _ string)            {}
 func (m *mockPermissionService) MarkInteractiveSession(_ string)    {}
 func (m *mockPermissionService) RemoveInteractiveSession(_ string)  {}
 func (m *mockPermissionService) IsInteractiveSession(_ string) bool { return false }
+func (m *mockPermissionService) SetReviewer(_ permission.Reviewer)  {}
 func (m *mockPermissionService) Subscribe(_ context.Context) <-chan pubsub.Event[permission.PermissionRequest] {
 	return nil
 }
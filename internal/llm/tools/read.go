@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
@@ -26,6 +27,7 @@ type viewTool struct {
 	lsp         lsp.LspService
 	registry    agentregistry.Registry
 	permissions permission.Service
+	limits      OutputLimits
 }
 
 type ViewResponseMetadata struct {
@@ -58,9 +60,9 @@ FEATURES:
 - Suggests similar file names when the requested file isn't found
 
 LIMITATIONS:
-- Maximum file size is 250KB
-- Default reading limit is 2000 lines
-- Lines longer than 2000 characters are truncated
+- Maximum file size is ${maxKB}KB
+- Default reading limit is ${maxLines} lines
+- Lines longer than ${maxLineLength} characters are truncated
 - Cannot display binary files or images
 - Images can be identified but not displayed
 
@@ -71,18 +73,23 @@ TIPS:
 - Avoid tiny repeated slices (e.g. 30-line chunks). If you need more context, read a larger window in a single call`
 )
 
-func NewReadTool(lspService lsp.LspService, reg agentregistry.Registry, permissions permission.Service) BaseTool {
+func NewReadTool(lspService lsp.LspService, reg agentregistry.Registry, permissions permission.Service, limits OutputLimits) BaseTool {
 	return &viewTool{
 		lsp:         lspService,
 		registry:    reg,
 		permissions: permissions,
+		limits:      limits,
 	}
 }
 
 func (v *viewTool) Info() ToolInfo {
 	return ToolInfo{
-		Name:        ReadToolName,
-		Description: viewDescription,
+		Name: ReadToolName,
+		Description: strings.NewReplacer(
+			"${maxKB}", strconv.Itoa(v.limits.readBytes()/1024),
+			"${maxLines}", strconv.Itoa(v.limits.readLines()),
+			"${maxLineLength}", strconv.Itoa(v.limits.lineLength()),
+		).Replace(viewDescription),
 		Parameters: map[string]any{
 			"file_path": map[string]any{
 				"type":        "string",
@@ -163,19 +170,19 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 
 	// Check file size
-	if fileInfo.Size() > MaxReadSize {
+	if fileInfo.Size() > int64(v.limits.readBytes()) {
 		return NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
-			fileInfo.Size(), MaxReadSize)), nil
+			fileInfo.Size(), v.limits.readBytes())), nil
 	}
 
 	// Set default limit if not provided
 	if params.Limit <= 0 {
-		params.Limit = DefaultReadLimit
+		params.Limit = v.limits.readLines()
 	}
 
 	// Cap the limit to the maximum allowed
-	if params.Limit > DefaultReadLimit {
-		params.Limit = DefaultReadLimit
+	if params.Limit > v.limits.readLines() {
+		params.Limit = v.limits.readLines()
 	}
 
 	// Check if it's an image file
@@ -190,7 +197,7 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 
 	// Read the file content
-	content, lineCount, err := readTextFile(filePath, params.Offset, params.Limit)
+	content, lineCount, err := readTextFile(filePath, params.Offset, params.Limit, v.limits.lineLength())
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error reading file: %w", err)
 	}
@@ -251,7 +258,7 @@ func addLineNumbers(content string, startLine int) string {
 	return strings.Join(result, "\n")
 }
 
-func readTextFile(filePath string, offset, limit int) (string, int, error) {
+func readTextFile(filePath string, offset, limit, maxLineLength int) (string, int, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", 0, err
@@ -283,8 +290,8 @@ func readTextFile(filePath string, offset, limit int) (string, int, error) {
 	for scanner.Scan() && len(lines) < limit {
 		lineCount++
 		lineText := scanner.Text()
-		if len(lineText) > MaxLineLength {
-			lineText = lineText[:MaxLineLength] + "..."
+		if len(lineText) > maxLineLength {
+			lineText = lineText[:maxLineLength] + "..."
 		}
 		lines = append(lines, lineText)
 	}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// readOnlyRefusal answers a bash command refused in read-only mode.
+const readOnlyRefusal = "OpenCode is running in read-only mode: only commands that read files or inspect the system are allowed, one at a time or joined with pipes. Use the read, grep, glob, ls and tree tools to explore instead."
+
+// IsReadOnly reports whether the process runs in read-only mode.
+func IsReadOnly(ctx context.Context) bool {
+	v, _ := ctx.Value(ReadOnlyContextKey).(bool)
+	return v
+}
+
+// readOnlyCommands are the commands read-only mode lets bash run. Unlike
+// safeReadOnlyCommands, which only decides what needs no permission
+// prompt, nothing here may change files or run another program: no
+// `env`, `timeout` or `go build`.
+var readOnlyCommands = []string{
+	"ls", "cat", "head", "tail", "wc", "pwd", "date", "whoami", "id", "uname",
+	"df", "du", "free", "uptime", "ps", "which", "whereis", "file", "stat", "realpath", "dirname", "basename",
+	"grep", "egrep", "fgrep", "rg", "find", "tree", "sort", "uniq", "cut", "tr", "diff", "cmp", "comm",
+	"echo", "printf", "printenv", "nl", "column", "jq", "md5sum", "sha1sum", "sha256sum", "true", "false",
+
+	"git status", "git log", "git diff", "git show", "git blame", "git grep", "git ls-files", "git ls-tree",
+	"git rev-parse", "git describe", "git shortlog", "git cat-file", "git branch --list", "git tag --list",
+	"git remote -v", "git config --get", "git config --list",
+
+	"go version", "go list", "go doc", "go env",
+}
+
+// readOnlyDeniedArgs are arguments that make an otherwise read-only command
+// write files or run other programs.
+var readOnlyDeniedArgs = map[string][]string{
+	"find": {"-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprint0", "-fprintf", "-fls"},
+	"sort": {"-o", "--output"},
+	"git":  {"--output", "--ext-diff", "-O", "--open-files-in-pager"},
+	"go":   {"-w", "-u"},
+	"rg":   {"--pre"},
+	"tree": {"-o"},
+	"date": {"-s", "--set"},
+}
+
+// IsReadOnlyCommand reports whether command only reads: a single command
+// from readOnlyCommands, or several joined by pipes. Anything that could
+// chain, substitute or redirect is refused, even when quoted.
+func IsReadOnlyCommand(command string) bool {
+	if strings.ContainsAny(command, ";&<>`\n\r") || strings.Contains(command, "$(") {
+		return false
+	}
+	for _, segment := range strings.Split(command, "|") {
+		if !isReadOnlySegment(strings.Fields(segment)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isReadOnlySegment(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	allowed := false
+	for _, cmd := range readOnlyCommands {
+		words := strings.Fields(cmd)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+	positional := 0
+	for _, arg := range args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			positional++
+		}
+		for _, denied := range readOnlyDeniedArgs[args[0]] {
+			// Short flags may carry their value attached: -ofile.
+			short := len(denied) == 2 && denied != "--"
+			if arg == denied || strings.HasPrefix(arg, denied+"=") || (short && strings.HasPrefix(arg, denied)) {
+				return false
+			}
+		}
+	}
+	// `uniq in out` writes its second operand.
+	return args[0] != "uniq" || positional <= 1
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	mock_permission "github.com/opencode-ai/opencode/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIsReadOnlyCommand(t *testing.T) {
+	allowed := []string{
+		"ls -la",
+		"git log --oneline -5",
+		"grep -rn TODO internal | head -20",
+		"find . -name '*.go' -type f",
+		"sort -u names.txt",
+		"go env GOPATH",
+	}
+	for _, cmd := range allowed {
+		assert.True(t, IsReadOnlyCommand(cmd), cmd)
+	}
+
+	refused := []string{
+		"",
+		"rm -rf build",
+		"ls; rm -rf build",
+		"ls && touch x",
+		"echo hi > out.txt",
+		"cat $(which sh)",
+		"find . -name '*.tmp' -delete",
+		"find . -exec rm {} +",
+		"sort -o out.txt in.txt",
+		"sort -oout.txt in.txt",
+		"uniq in.txt out.txt",
+		"git branch feature",
+		"git diff --output=patch.diff",
+		"go env -w GOFLAGS=-mod=mod",
+		"env rm -rf build",
+		"ls | xargs rm",
+		"lsof",
+	}
+	for _, cmd := range refused {
+		assert.False(t, IsReadOnlyCommand(cmd), cmd)
+	}
+}
+
+func TestBashTool_ReadOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	ctx = context.WithValue(ctx, ReadOnlyContextKey, true)
+	marker := filepath.Join(t.TempDir(), "marker")
+
+	run := func(command string) ToolResponse {
+		input, _ := json.Marshal(BashParams{Command: command, Description: "test"})
+		// The registry allows everything: read-only mode wins over permissions.
+		resp, err := NewBashTool(mockPerms, &stubRegistry{}, OutputLimits{}).Run(ctx, ToolCall{Name: BashToolName, Input: string(input)})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := run("touch " + marker)
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "read-only mode")
+	assert.NoFileExists(t, marker)
+
+	resp = run("echo still-runs")
+	assert.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "still-runs")
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ResultMetadataKey is the key of the common envelope inside every tool
+// result's metadata object. The tool's own metadata fields sit next to
+// it unchanged, so existing readers of e.g. EditResponseMetadata keep
+// working.
+const ResultMetadataKey = "result"
+
+// Result statuses recorded in ResultMetadata.Status.
+const (
+	ResultStatusOK     = "ok"
+	ResultStatusError  = "error"
+	ResultStatusDenied = "denied"
+)
+
+// ResultMetadata is the machine-readable summary the agent attaches to
+// every tool result, whatever the tool. It is persisted with the result,
+// served with message parts over the API, and read by the TUI and the
+// audit trail.
+type ResultMetadata struct {
+	Tool       string `json:"tool"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	// ExitCode is set for tools that run a process (bash, run_task).
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Files lists the paths a successful file-changing call touched.
+	Files []string `json:"files,omitempty"`
+	// Count is the number of items a search or listing returned.
+	Count     *int `json:"count,omitempty"`
+	Truncated bool `json:"truncated,omitempty"`
+	DryRun    bool `json:"dry_run,omitempty"`
+	// Pinned results are kept verbatim through compaction. Tools ask
+	// for it with "pin": true in their metadata.
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// countKeys are the tool metadata fields Count is taken from, in order
+// of preference.
+var countKeys = []string{"number_of_matches", "number_of_files", "files_deleted", "results"}
+
+// StampResultMetadata builds the envelope for a finished call and returns
+// the tool's metadata with the envelope added under ResultMetadataKey.
+// started is when the call was dispatched; a zero value (the call never
+// ran) records no duration. Metadata that isn't a JSON object is returned
+// unchanged along with the envelope.
+func StampResultMetadata(call ToolCall, metadata, content string, isError bool, started time.Time) (string, ResultMetadata) {
+	meta := ResultMetadata{Tool: call.Name, Status: ResultStatusOK}
+	switch {
+	case isError && content == "Permission denied":
+		meta.Status = ResultStatusDenied
+	case isError:
+		meta.Status = ResultStatusError
+	}
+	if !started.IsZero() {
+		meta.DurationMs = time.Since(started).Milliseconds()
+	}
+
+	fields := map[string]json.RawMessage{}
+	if metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &fields); err != nil {
+			return metadata, meta
+		}
+	}
+	meta.fillFrom(fields)
+	if meta.Status == ResultStatusOK && !meta.DryRun && IsMutatingTool(call.Name) {
+		meta.Files = ExtractPathsFromCall(call)
+	}
+
+	envelope, err := json.Marshal(meta)
+	if err != nil {
+		return metadata, meta
+	}
+	fields[ResultMetadataKey] = envelope
+	stamped, err := json.Marshal(fields)
+	if err != nil {
+		return metadata, meta
+	}
+	return string(stamped), meta
+}
+
+// fillFrom copies the fields the tools already report under their own
+// names into the envelope.
+func (m *ResultMetadata) fillFrom(fields map[string]json.RawMessage) {
+	if raw, ok := fields["exit_code"]; ok {
+		var code int
+		if json.Unmarshal(raw, &code) == nil {
+			m.ExitCode = &code
+		}
+	}
+	for _, key := range countKeys {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		var n int
+		if json.Unmarshal(raw, &n) == nil {
+			m.Count = &n
+			break
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) == nil {
+			n = len(items)
+			m.Count = &n
+			break
+		}
+	}
+	if raw, ok := fields["truncated"]; ok {
+		_ = json.Unmarshal(raw, &m.Truncated)
+	}
+	if raw, ok := fields["dry_run"]; ok {
+		_ = json.Unmarshal(raw, &m.DryRun)
+	}
+	if raw, ok := fields["pin"]; ok {
+		_ = json.Unmarshal(raw, &m.Pinned)
+	}
+}
+
+// ParseResultMetadata reads the envelope out of a tool result's metadata.
+// The bool is false for results recorded before the envelope existed.
+func ParseResultMetadata(metadata string) (ResultMetadata, bool) {
+	var fields struct {
+		Result *ResultMetadata `json:"result"`
+	}
+	if metadata == "" || json.Unmarshal([]byte(metadata), &fields) != nil || fields.Result == nil {
+		return ResultMetadata{}, false
+	}
+	return *fields.Result, true
+}
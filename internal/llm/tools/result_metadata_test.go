@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStampResultMetadata(t *testing.T) {
+	t.Run("keeps tool fields and derives envelope", func(t *testing.T) {
+		call := ToolCall{ID: "1", Name: BashToolName, Input: `{"command":"ls"}`}
+		own, _ := json.Marshal(BashResponseMetadata{StartTime: 1, EndTime: 2, ExitCode: 3})
+		stamped, meta := StampResultMetadata(call, string(own), "out", false, time.Now().Add(-2*time.Second))
+
+		assert.Equal(t, ResultStatusOK, meta.Status)
+		assert.GreaterOrEqual(t, meta.DurationMs, int64(2000))
+		require.NotNil(t, meta.ExitCode)
+		assert.Equal(t, 3, *meta.ExitCode)
+
+		var back BashResponseMetadata
+		require.NoError(t, json.Unmarshal([]byte(stamped), &back))
+		assert.Equal(t, int64(2), back.EndTime)
+		parsed, ok := ParseResultMetadata(stamped)
+		require.True(t, ok)
+		assert.Equal(t, meta, parsed)
+	})
+
+	t.Run("files for successful edits", func(t *testing.T) {
+		call := ToolCall{Name: EditToolName, Input: `{"file_path":"/p/a.go"}`}
+		_, meta := StampResultMetadata(call, `{"diff":"","additions":1,"removals":0}`, "ok", false, time.Time{})
+		assert.Equal(t, []string{"/p/a.go"}, meta.Files)
+		assert.Zero(t, meta.DurationMs)
+
+		_, meta = StampResultMetadata(call, `{"dry_run":true}`, "ok", false, time.Time{})
+		assert.Empty(t, meta.Files)
+		assert.True(t, meta.DryRun)
+
+		_, meta = StampResultMetadata(call, "", "Permission denied", true, time.Time{})
+		assert.Equal(t, ResultStatusDenied, meta.Status)
+		assert.Empty(t, meta.Files)
+	})
+
+	t.Run("pin flag", func(t *testing.T) {
+		_, meta := StampResultMetadata(ToolCall{Name: WebFetchToolName}, `{"pin":true}`, "spec", false, time.Time{})
+		assert.True(t, meta.Pinned)
+		_, meta = StampResultMetadata(ToolCall{Name: WebFetchToolName}, `{}`, "spec", false, time.Time{})
+		assert.False(t, meta.Pinned)
+	})
+
+	t.Run("count from numbers or lists", func(t *testing.T) {
+		_, meta := StampResultMetadata(ToolCall{Name: GrepToolName}, `{"number_of_files":2,"number_of_matches":7,"truncated":true}`, "", false, time.Time{})
+		require.NotNil(t, meta.Count)
+		assert.Equal(t, 7, *meta.Count)
+		assert.True(t, meta.Truncated)
+
+		_, meta = StampResultMetadata(ToolCall{Name: WebSearchToolName}, `{"results":[{},{},{}]}`, "", false, time.Time{})
+		require.NotNil(t, meta.Count)
+		assert.Equal(t, 3, *meta.Count)
+	})
+
+	t.Run("empty metadata gets an envelope, errors are marked", func(t *testing.T) {
+		stamped, meta := StampResultMetadata(ToolCall{Name: "mcp_tool"}, "", "boom", true, time.Time{})
+		assert.Equal(t, ResultStatusError, meta.Status)
+		parsed, ok := ParseResultMetadata(stamped)
+		require.True(t, ok)
+		assert.Equal(t, "mcp_tool", parsed.Tool)
+	})
+
+	t.Run("non-object metadata is left alone", func(t *testing.T) {
+		stamped, _ := StampResultMetadata(ToolCall{Name: "x"}, `[1,2]`, "", false, time.Time{})
+		assert.Equal(t, `[1,2]`, stamped)
+		_, ok := ParseResultMetadata(stamped)
+		assert.False(t, ok)
+	})
+}
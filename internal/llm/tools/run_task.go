@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/project"
+)
+
+type RunTaskParams struct {
+	Target  string   `json:"target"`
+	Args    []string `json:"args,omitempty"`
+	Timeout int      `json:"timeout,omitempty"`
+}
+
+type RunTaskPermissionsParams struct {
+	Target  string `json:"target"`
+	Command string `json:"command"`
+}
+
+type RunTaskResponseMetadata struct {
+	StartTime    int64  `json:"start_time"`
+	EndTime      int64  `json:"end_time"`
+	Command      string `json:"command"`
+	ExitCode     int    `json:"exit_code"`
+	TempFilePath string `json:"temp_file_path,omitempty"`
+}
+
+type runTaskTool struct {
+	targets     []project.Target
+	permissions permission.Service
+	registry    agentregistry.Registry
+	limits      OutputLimits
+}
+
+const (
+	RunTaskToolName = "run_task"
+
+	runTaskDescription = `Runs a target of one of the project's own task runners (Makefile, justfile, Taskfile, package.json scripts).
+
+WHEN TO USE THIS TOOL:
+- Prefer it over bash whenever the project already defines a target for the job (build, test, lint, format, generate, ...)
+- Targets are referenced as "<runner>:<name>", e.g. "make:test" or "npm:build"
+
+HOW TO USE:
+- Pick a target from the list below; any other value is rejected
+- Pass extra arguments in args; each one is passed to the runner verbatim, without shell expansion
+- Output is truncated the same way as bash output
+
+AVAILABLE TARGETS:
+`
+)
+
+// NewRunTaskTool exposes targets, as returned by project.DetectTargets, as
+// an enumerated tool. Permission rules for the tool match against the target
+// ID rather than the full command line.
+func NewRunTaskTool(targets []project.Target, permissions permission.Service, reg agentregistry.Registry, limits OutputLimits) BaseTool {
+	return &runTaskTool{
+		targets:     targets,
+		permissions: permissions,
+		registry:    reg,
+		limits:      limits,
+	}
+}
+
+func (r *runTaskTool) Info() ToolInfo {
+	ids := make([]string, 0, len(r.targets))
+	var desc strings.Builder
+	desc.WriteString(runTaskDescription)
+	for _, t := range r.targets {
+		ids = append(ids, t.ID())
+		desc.WriteString("- " + t.ID())
+		if t.Description != "" {
+			desc.WriteString(": " + t.Description)
+		}
+		desc.WriteString("\n")
+	}
+	return ToolInfo{
+		Name:        RunTaskToolName,
+		Description: desc.String(),
+		Parameters: map[string]any{
+			"target": map[string]any{
+				"type":        "string",
+				"description": "The target to run",
+				"enum":        ids,
+			},
+			"args": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Optional extra arguments for the target",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Optional timeout in milliseconds (max 600000)",
+			},
+		},
+		Required: []string{"target"},
+	}
+}
+
+func (r *runTaskTool) lookup(id string) (project.Target, bool) {
+	for _, t := range r.targets {
+		if t.ID() == id {
+			return t, true
+		}
+	}
+	return project.Target{}, false
+}
+
+func (r *runTaskTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RunTaskParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse("invalid parameters"), nil
+	}
+	target, ok := r.lookup(params.Target)
+	if !ok {
+		ids := make([]string, 0, len(r.targets))
+		for _, t := range r.targets {
+			ids = append(ids, t.ID())
+		}
+		return NewTextErrorResponse(fmt.Sprintf("unknown target %q, available targets: %s", params.Target, strings.Join(ids, ", "))), nil
+	}
+
+	if params.Timeout > MaxTimeout {
+		params.Timeout = MaxTimeout
+	} else if params.Timeout <= 0 {
+		params.Timeout = DefaultTimeout
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return NewEmptyResponse(), fmt.Errorf("session ID and message ID are required for running a task")
+	}
+
+	command := target.Command(params.Args)
+	workdir := config.WorkingDirectory()
+	switch r.registry.EvaluatePermission(string(GetAgentID(ctx)), RunTaskToolName, target.ID()) {
+	case permission.ActionAllow:
+	case permission.ActionDeny:
+		return NewEmptyResponse(), permission.ErrorPermissionDenied
+	default:
+		p := r.permissions.Request(ctx,
+			permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        workdir,
+				ToolName:    RunTaskToolName,
+				Action:      "execute",
+				Description: fmt.Sprintf("Run task %s: %s", target.ID(), command),
+				Params: RunTaskPermissionsParams{
+					Target:  target.ID(),
+					Command: command,
+				},
+			},
+		)
+		if !p {
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+	}
+
+	startTime := time.Now()
+	sh := shell.GetPersistentShell(workdir)
+	if sh == nil {
+		return NewEmptyResponse(), fmt.Errorf("failed to create shell instance")
+	}
+	stdout, stderr, exitCode, interrupted, err := sh.Exec(ctx, command, params.Timeout)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error running task: %w", err)
+	}
+
+	stdoutResult := persistAndTruncate(stdout, "stdout", RunTaskToolName, r.limits)
+	stderrResult := persistAndTruncate(stderr, "stderr", RunTaskToolName, r.limits)
+
+	var parts []string
+	if stdoutResult.content != "" {
+		parts = append(parts, stdoutResult.content)
+	}
+	if stderrResult.content != "" {
+		parts = append(parts, stderrResult.content)
+	}
+	if interrupted {
+		parts = append(parts, "Task was aborted before completion")
+	} else if exitCode != 0 {
+		parts = append(parts, fmt.Sprintf("Exit code %d", exitCode))
+	}
+	output := strings.Join(parts, "\n")
+	if output == "" {
+		output = "no output"
+	}
+
+	tempPath := stdoutResult.filePath
+	if tempPath == "" {
+		tempPath = stderrResult.filePath
+	}
+	metadata := RunTaskResponseMetadata{
+		StartTime:    startTime.UnixMilli(),
+		EndTime:      time.Now().UnixMilli(),
+		Command:      command,
+		ExitCode:     exitCode,
+		TempFilePath: tempPath,
+	}
+	return WithResponseMetadata(NewTextResponse(output), metadata), nil
+}
+
+func (r *runTaskTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
+	return false
+}
+
+func (r *runTaskTool) IsBaseline() bool { return true }
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+// checkSandbox refuses a path that the file-changing tools may not touch
+// under sandbox.allowedPaths. Both the path and the roots are compared
+// after resolving symlinks, so a link inside the workspace that points
+// elsewhere does not escape it. Paths matching a sandbox.exceptions glob
+// are let through. Without allowedPaths every path passes; the permission
+// prompt still applies either way.
+func checkSandbox(path string) error {
+	cfg := config.Get()
+	if cfg == nil || cfg.Sandbox == nil || len(cfg.Sandbox.AllowedPaths) == 0 {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.WorkingDirectory(), path)
+	}
+	resolved := resolvePath(path)
+	roots := make([]string, 0, len(cfg.Sandbox.AllowedPaths))
+	for _, root := range cfg.Sandbox.AllowedPaths {
+		root = resolvePath(absSandboxPath(root))
+		if isWithin(resolved, root) {
+			return nil
+		}
+		roots = append(roots, root)
+	}
+	for _, pattern := range cfg.Sandbox.Exceptions {
+		if permission.MatchWildcard(resolvePattern(pattern), resolved) {
+			return nil
+		}
+	}
+	shown := path
+	if resolved != filepath.Clean(path) {
+		shown = fmt.Sprintf("%s (resolves to %s)", path, resolved)
+	}
+	return fmt.Errorf("%s is outside the sandbox; allowed paths: %s. Ask the user to add it to sandbox.exceptions if it must be changed", shown, strings.Join(roots, ", "))
+}
+
+// absSandboxPath expands "~" and makes a configured path absolute against
+// the working directory.
+func absSandboxPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil && home != "" {
+			p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(config.WorkingDirectory(), p)
+	}
+	return filepath.Clean(p)
+}
+
+// maxSymlinkHops bounds how many dangling links resolvePath follows by
+// hand, so a link cycle can't loop forever.
+const maxSymlinkHops = 40
+
+// resolvePath resolves the symlinks of the longest existing prefix of
+// path, so files that are about to be created resolve too. A dangling
+// link in that prefix is followed to its target, since a write through
+// it creates the target.
+func resolvePath(path string) string {
+	return resolvePathHops(path, 0)
+}
+
+func resolvePathHops(path string, hops int) string {
+	path = filepath.Clean(path)
+	rest := ""
+	for p := path; ; {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		if fi, err := os.Lstat(p); err == nil && fi.Mode()&os.ModeSymlink != 0 && hops < maxSymlinkHops {
+			if target, err := os.Readlink(p); err == nil {
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(filepath.Dir(p), target)
+				}
+				return resolvePathHops(filepath.Join(target, rest), hops+1)
+			}
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(p), rest)
+		p = parent
+	}
+}
+
+// resolvePattern resolves the directory before a glob's first wildcard,
+// so "/tmp/*" also matches where /tmp links to.
+func resolvePattern(pattern string) string {
+	pattern = absSandboxPath(pattern)
+	i := strings.Index(pattern, "*")
+	if i < 0 {
+		return resolvePath(pattern)
+	}
+	dir := filepath.Dir(pattern[:i] + "x")
+	return resolvePath(dir) + pattern[len(dir):]
+}
+
+// isWithin reports whether path is root or inside it.
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+func withSandbox(t *testing.T, sandbox *config.SandboxConfig) {
+	t.Helper()
+	cfg := config.Get()
+	prev := cfg.Sandbox
+	cfg.Sandbox = sandbox
+	t.Cleanup(func() { cfg.Sandbox = prev })
+}
+
+func TestCheckSandbox(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "src"), 0o755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	require.NoError(t, os.Mkdir(filepath.Join(outside, "cache"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "newfile"), filepath.Join(root, "dangling")))
+	require.NoError(t, os.Symlink("missing", filepath.Join(root, "dangling-inside")))
+
+	withSandbox(t, &config.SandboxConfig{
+		AllowedPaths: []string{root},
+		Exceptions:   []string{filepath.Join(outside, "cache") + "/*"},
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		allowed bool
+	}{
+		{"root itself", root, true},
+		{"existing file", filepath.Join(root, "src", "main.go"), true},
+		{"new directory", filepath.Join(root, "new", "dir", "file.go"), true},
+		{"dot-dot out of root", filepath.Join(root, "src", "..", "..", "etc"), false},
+		{"outside", filepath.Join(outside, "file"), false},
+		{"symlink out of root", filepath.Join(root, "escape", "file"), false},
+		{"dangling symlink out of root", filepath.Join(root, "dangling"), false},
+		{"dangling symlink inside root", filepath.Join(root, "dangling-inside"), true},
+		{"exception", filepath.Join(outside, "cache", "x"), true},
+		{"exception through symlink", filepath.Join(root, "escape", "cache", "x"), true},
+		{"sibling with root prefix", root + "-other/file", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSandbox(tt.path)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	withSandbox(t, nil)
+	assert.NoError(t, checkSandbox(filepath.Join(outside, "file")), "no sandbox configured")
+}
+
+func TestEditTool_Sandbox(t *testing.T) {
+	ctx, tmpPath, tool := setupEditTest(t)
+	writeAndTrack(t, tmpPath, "hello world")
+	withSandbox(t, &config.SandboxConfig{AllowedPaths: []string{t.TempDir()}})
+
+	resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "world", NewString: "go"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "outside the sandbox")
+	content, _ := os.ReadFile(tmpPath)
+	assert.Equal(t, "hello world", string(content))
+}
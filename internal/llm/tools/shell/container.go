@@ -0,0 +1,131 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// containerLabel marks the containers opencode starts, so leftovers can be
+// found with `docker ps --filter label=opencode.shell`.
+const containerLabel = "opencode.shell=true"
+
+// containerConfig returns the container settings when shell.backend is
+// "container".
+func containerConfig() (*config.ContainerConfig, bool) {
+	cfg := config.Get()
+	if cfg == nil || cfg.Shell.Backend != config.ShellBackendContainer || cfg.Shell.Container == nil {
+		return nil, false
+	}
+	return cfg.Shell.Container, true
+}
+
+func containerRuntime(c *config.ContainerConfig) string {
+	if c.Runtime != "" {
+		return c.Runtime
+	}
+	return "docker"
+}
+
+// containerShellPath is the shell started in the image: shell.path when
+// set, /bin/sh otherwise, since the host's $SHELL may not exist there.
+func containerShellPath() string {
+	if cfg := config.Get(); cfg != nil && cfg.Shell.Path != "" {
+		return cfg.Shell.Path
+	}
+	return "/bin/sh"
+}
+
+// containerRunArgs builds the `run` arguments shared by the persistent
+// shell and one-off commands: network policy, the working directory and
+// cwd mounted at their host paths, extra mounts, environment and user.
+func containerRunArgs(c *config.ContainerConfig, cwd string, extraMounts ...string) []string {
+	network := c.Network
+	if network == "" {
+		network = "none"
+	}
+	args := []string{"run", "--rm", "--label", containerLabel, "--network", network, "-w", cwd}
+
+	wd := config.WorkingDirectory()
+	mode := ""
+	if c.ReadOnly {
+		mode = ":ro"
+	}
+	args = append(args, "-v", wd+":"+wd+mode)
+	if rel, err := filepath.Rel(wd, cwd); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		args = append(args, "-v", cwd+":"+cwd+mode)
+	}
+	for _, m := range c.Mounts {
+		if host, rest, ok := strings.Cut(m, ":"); ok && !filepath.IsAbs(host) {
+			m = filepath.Join(wd, host) + ":" + rest
+		}
+		args = append(args, "-v", m)
+	}
+	for _, m := range extraMounts {
+		args = append(args, "-v", m+":"+m)
+	}
+
+	args = append(args, "-e", "GIT_EDITOR=true")
+	for _, e := range c.Env {
+		args = append(args, "-e", e)
+	}
+
+	// Files the commands create should belong to the user, not root.
+	switch containerRuntime(c) {
+	case "podman":
+		args = append(args, "--userns=keep-id")
+	default:
+		if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 {
+			args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+		}
+	}
+	return append(args, c.Args...)
+}
+
+// newContainerCommand returns the command that runs the persistent shell
+// in a new container named name. tempDir, where commands leave their
+// output, is mounted at the same path.
+func newContainerCommand(c *config.ContainerConfig, cwd, tempDir, name string, shellArgs []string) *exec.Cmd {
+	args := containerRunArgs(c, cwd, tempDir)
+	args = append(args[:1], append([]string{"-i", "--name", name}, args[1:]...)...)
+	args = append(args, c.Image, containerShellPath())
+	args = append(args, shellArgs...)
+	return exec.Command(containerRuntime(c), args...)
+}
+
+// ContainerCommand returns a command that runs command once in a fresh
+// container with workdir as its working directory, or nil when the shell
+// backend is the host. Background bash tasks use it.
+func ContainerCommand(workdir, command string) *exec.Cmd {
+	c, ok := containerConfig()
+	if !ok {
+		return nil
+	}
+	args := containerRunArgs(c, workdir)
+	args = append(args, c.Image, containerShellPath(), "-c", command)
+	return exec.Command(containerRuntime(c), args...)
+}
+
+func newContainerName() string {
+	return fmt.Sprintf("opencode-shell-%d-%d", os.Getpid(), time.Now().UnixNano())
+}
+
+// killContainerChildren stops what the shell started without stopping
+// the shell: inside the container the shell is PID 1, which kill -1 skips.
+func killContainerChildren(runtime, name string) {
+	if err := exec.Command(runtime, "exec", name, "sh", "-c", "kill -TERM -1").Run(); err != nil {
+		logging.Debug("Failed to stop container commands", "container", name, "error", err)
+	}
+}
+
+func removeContainer(runtime, name string) {
+	if err := exec.Command(runtime, "rm", "-f", name).Run(); err != nil {
+		logging.Debug("Failed to remove shell container", "container", name, "error", err)
+	}
+}
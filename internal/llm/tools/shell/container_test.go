@@ -0,0 +1,80 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// fakeRuntime is a stand-in for the docker CLI: it records the `run`
+// arguments and runs what follows the image on the host, in the -w
+// directory.
+const fakeRuntime = `#!/bin/sh
+[ "$1" = run ] || exit 0
+echo "$@" >> "$FAKE_RUNTIME_LOG"
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -w) cd "$2"; shift 2 ;;
+    test-image) shift; exec "$@" ;;
+    *) shift ;;
+  esac
+done
+`
+
+func TestContainerShell(t *testing.T) {
+	wd := t.TempDir()
+	_, err := config.Load(wd, false)
+	require.NoError(t, err)
+	cfg := config.Get()
+	prev := cfg.Shell
+	cfg.Shell = config.ShellConfig{
+		Backend: config.ShellBackendContainer,
+		Container: &config.ContainerConfig{
+			Image:  "test-image",
+			Mounts: []string{"cache:/cache:ro"},
+			Env:    []string{"GOFLAGS"},
+		},
+	}
+	t.Cleanup(func() { cfg.Shell = prev })
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte(fakeRuntime), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	log := filepath.Join(t.TempDir(), "runtime.log")
+	t.Setenv("FAKE_RUNTIME_LOG", log)
+
+	sh := newPersistentShell(wd)
+	require.NotNil(t, sh)
+	defer sh.Close()
+	require.NotEmpty(t, sh.container)
+
+	out, _, code, _, err := sh.Exec(t.Context(), "pwd", 5000)
+	require.NoError(t, err)
+	require.Zero(t, code)
+	require.Equal(t, wd, strings.TrimSpace(out))
+
+	logged, err := os.ReadFile(log)
+	require.NoError(t, err)
+	args := string(logged)
+	for _, want := range []string{
+		"run -i --name " + sh.container + " --rm",
+		"--network none",
+		"-v " + wd + ":" + wd + " ",
+		"-v " + filepath.Join(wd, "cache") + ":/cache:ro",
+		"-v " + sh.tempDir + ":" + sh.tempDir,
+		"-e GOFLAGS",
+		"test-image /bin/sh",
+	} {
+		require.Contains(t, args, want)
+	}
+
+	cmd := ContainerCommand(wd, "make test")
+	require.NotNil(t, cmd)
+	require.Equal(t, []string{"test-image", "/bin/sh", "-c", "make test"}, cmd.Args[len(cmd.Args)-4:])
+	require.NotContains(t, strings.Join(cmd.Args, " "), " -i ")
+}
@@ -0,0 +1,182 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Dialect is the command language of the configured shell. The persistent
+// shell wraps each command differently per dialect, and the bash tool
+// tells the model which syntax to use.
+type Dialect string
+
+const (
+	DialectPOSIX      Dialect = "posix"
+	DialectPowerShell Dialect = "powershell"
+	DialectCmd        Dialect = "cmd"
+)
+
+// DialectOf derives the dialect from a shell's file name: pwsh and
+// powershell speak PowerShell, cmd speaks batch, anything else (bash, zsh,
+// sh, Git Bash's bash.exe) is POSIX.
+func DialectOf(shellPath string) Dialect {
+	name := strings.ToLower(shellPath[strings.LastIndexAny(shellPath, `/\`)+1:])
+	name = strings.TrimSuffix(name, ".exe")
+	switch name {
+	case "pwsh", "powershell":
+		return DialectPowerShell
+	case "cmd":
+		return DialectCmd
+	}
+	return DialectPOSIX
+}
+
+// CurrentDialect is the dialect of the shell commands run in on the host.
+// The container backend always runs a POSIX shell.
+func CurrentDialect() Dialect {
+	if _, ok := containerConfig(); ok {
+		return DialectPOSIX
+	}
+	return DialectOf(GetShellPath())
+}
+
+// defaultWindowsShell picks the shell on Windows when neither shell.path
+// nor $SHELL names one: PowerShell 7, then Windows PowerShell, then cmd.
+func defaultWindowsShell() string {
+	for _, name := range []string{"pwsh.exe", "powershell.exe"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	if comspec := os.Getenv("ComSpec"); comspec != "" {
+		return comspec
+	}
+	return "cmd.exe"
+}
+
+func defaultShellPath() string {
+	if runtime.GOOS == "windows" {
+		return defaultWindowsShell()
+	}
+	return "/bin/bash"
+}
+
+// defaultShellArgs starts the persistent shell reading commands from
+// stdin without a prompt.
+func defaultShellArgs(d Dialect) []string {
+	switch d {
+	case DialectPowerShell:
+		return []string{"-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", "-"}
+	case DialectCmd:
+		return []string{"/Q", "/D", "/K"}
+	}
+	return []string{"-l"}
+}
+
+// commandFiles are the files a wrapped command leaves its results in, and
+// the script its wrapper is written to for PowerShell and cmd.
+type commandFiles struct {
+	stdout, stderr, cwd, status string
+	script                      string
+}
+
+// wrapCommand returns the line written to the shell's stdin to run
+// command, and the scripts it invokes by path. PowerShell and cmd read a
+// line at a time, so their wrappers go into a script that is dot-sourced
+// or called; either way directory changes and variables persist between
+// commands like they do in the POSIX shell.
+func wrapCommand(d Dialect, command string, f commandFiles) (line string, scripts map[string][]byte) {
+	switch d {
+	case DialectPowerShell:
+		body := fmt.Sprintf(`$global:LASTEXITCODE = 0
+$__opencodeOK = $true
+try {
+  . ([ScriptBlock]::Create(%s)) > %s 2> %s
+  $__opencodeOK = $?
+} catch {
+  $_ | Out-File -Append -Encoding utf8 %s
+  $__opencodeOK = $false
+}
+$__opencodeCode = if ($LASTEXITCODE) { $LASTEXITCODE } elseif ($__opencodeOK) { 0 } else { 1 }
+(Get-Location).ProviderPath | Out-File -Encoding utf8 %s
+$__opencodeCode | Out-File -Encoding utf8 %s
+`,
+			powershellQuote(command),
+			powershellQuote(f.stdout),
+			powershellQuote(f.stderr),
+			powershellQuote(f.stderr),
+			powershellQuote(f.cwd),
+			powershellQuote(f.status),
+		)
+		// Windows PowerShell writes redirections as UTF-16 unless told
+		// otherwise; PowerShell 7 already uses UTF-8.
+		body = "$PSDefaultParameterValues['Out-File:Encoding'] = 'utf8'\n" + body
+		return ". " + powershellQuote(f.script), map[string][]byte{f.script: []byte(body)}
+	case DialectCmd:
+		// The command goes into a batch file of its own so the redirections
+		// apply to all of it, whatever parentheses or && it contains.
+		body := strings.TrimSuffix(f.script, ".bat") + "-body.bat"
+		wrapper := strings.Join([]string{
+			"@echo off",
+			fmt.Sprintf("call %s < NUL > %s 2> %s", cmdQuote(body), cmdQuote(f.stdout), cmdQuote(f.stderr)),
+			"set OPENCODE_EXIT=%ERRORLEVEL%",
+			"cd > " + cmdQuote(f.cwd),
+			// The redirection comes first: "echo 1> file" would redirect
+			// stream 1 instead of writing 1.
+			">" + cmdQuote(f.status) + " echo %OPENCODE_EXIT%",
+			"",
+		}, "\r\n")
+		return "call " + cmdQuote(f.script), map[string][]byte{
+			f.script: []byte(wrapper),
+			body:     []byte("@echo off\r\n" + command + "\r\n"),
+		}
+	}
+	return fmt.Sprintf(`
+eval %s < /dev/null > %s 2> %s
+EXEC_EXIT_CODE=$?
+pwd > %s
+echo $EXEC_EXIT_CODE > %s
+`,
+		shellQuote(command),
+		shellQuote(f.stdout),
+		shellQuote(f.stderr),
+		shellQuote(f.cwd),
+		shellQuote(f.status),
+	), nil
+}
+
+// OneShotCommand returns a command that runs command once, in its own
+// shell process, with workdir as its working directory. Background bash
+// tasks use it.
+func OneShotCommand(workdir, command string) *exec.Cmd {
+	if cmd := ContainerCommand(workdir, command); cmd != nil {
+		return cmd
+	}
+	var cmd *exec.Cmd
+	switch shellPath := GetShellPath(); DialectOf(shellPath) {
+	case DialectPowerShell:
+		cmd = exec.Command(shellPath, "-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", command)
+	case DialectCmd:
+		cmd = exec.Command(shellPath)
+		// cmd does not parse its command line by the rules Go quotes
+		// arguments with, so the line is passed as is.
+		setCmdLine(cmd, fmt.Sprintf(`%s /D /S /C "%s"`, cmdQuote(shellPath), command))
+	default:
+		cmd = exec.Command("bash", "-c", command)
+	}
+	cmd.Dir = workdir
+	return cmd
+}
+
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// cmdQuote quotes a path for cmd. Paths cannot contain double quotes on
+// Windows, so there is nothing to escape.
+func cmdQuote(s string) string {
+	return `"` + s + `"`
+}
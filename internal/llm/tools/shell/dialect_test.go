@@ -0,0 +1,84 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+func TestDialectOf(t *testing.T) {
+	for path, want := range map[string]Dialect{
+		"/bin/bash":                              DialectPOSIX,
+		"/usr/bin/zsh":                           DialectPOSIX,
+		`C:\Program Files\Git\bin\bash.exe`:      DialectPOSIX,
+		`C:\Program Files\PowerShell\7\pwsh.exe`: DialectPowerShell,
+		"powershell.exe":                         DialectPowerShell,
+		"/usr/local/bin/pwsh":                    DialectPowerShell,
+		`C:\Windows\System32\cmd.exe`:            DialectCmd,
+		"CMD.EXE":                                DialectCmd,
+	} {
+		assert.Equal(t, want, DialectOf(path), path)
+	}
+}
+
+func TestWrapCommand(t *testing.T) {
+	files := commandFiles{stdout: `C:\t\out`, stderr: `C:\t\err`, cwd: `C:\t\cwd`, status: `C:\t\status`}
+
+	files.script = `C:\t\cmd.ps1`
+	line, scripts := wrapCommand(DialectPowerShell, "Write-Output 'it''s'", files)
+	assert.Equal(t, `. 'C:\t\cmd.ps1'`, line)
+	require.Len(t, scripts, 1)
+	assert.Contains(t, string(scripts[files.script]), `[ScriptBlock]::Create('Write-Output ''it''''s''')`)
+	assert.Contains(t, string(scripts[files.script]), `> 'C:\t\out' 2> 'C:\t\err'`)
+
+	files.script = `C:\t\cmd.bat`
+	line, scripts = wrapCommand(DialectCmd, "dir /b && echo (done)", files)
+	assert.Equal(t, `call "C:\t\cmd.bat"`, line)
+	require.Len(t, scripts, 2)
+	assert.Contains(t, string(scripts[files.script]), `call "C:\t\cmd-body.bat" < NUL > "C:\t\out" 2> "C:\t\err"`+"\r\n")
+	assert.Contains(t, string(scripts[files.script]), `>"C:\t\status" echo %OPENCODE_EXIT%`+"\r\n")
+	assert.Equal(t, "@echo off\r\ndir /b && echo (done)\r\n", string(scripts[`C:\t\cmd-body.bat`]))
+
+	line, scripts = wrapCommand(DialectPOSIX, "echo 'hi'", files)
+	assert.Nil(t, scripts)
+	assert.Contains(t, line, `eval 'echo '\''hi'\''' < /dev/null`)
+}
+
+// TestPowerShellPersistentShell runs the PowerShell wrapper for real where
+// pwsh is installed.
+func TestPowerShellPersistentShell(t *testing.T) {
+	pwsh, err := exec.LookPath("pwsh")
+	if err != nil {
+		t.Skip("pwsh not installed")
+	}
+	wd := t.TempDir()
+	_, err = config.Load(wd, false)
+	require.NoError(t, err)
+	cfg := config.Get()
+	prev := cfg.Shell
+	cfg.Shell = config.ShellConfig{Path: pwsh}
+	t.Cleanup(func() { cfg.Shell = prev })
+
+	sh := newPersistentShell(wd)
+	require.NotNil(t, sh)
+	defer sh.Close()
+
+	out, _, code, _, err := sh.Exec(t.Context(), "$x = 'kept'; Write-Output \"it's\"", 10000)
+	require.NoError(t, err)
+	assert.Zero(t, code)
+	assert.Equal(t, "it's", strings.TrimSpace(out))
+
+	out, _, _, _, err = sh.Exec(t.Context(), "Write-Output $x", 10000)
+	require.NoError(t, err)
+	assert.Equal(t, "kept", strings.TrimSpace(out), "variables persist between commands")
+
+	_, stderr, code, _, err := sh.Exec(t.Context(), "Get-Item does-not-exist", 10000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, code)
+	assert.NotEmpty(t, stderr)
+}
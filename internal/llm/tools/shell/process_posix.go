@@ -0,0 +1,34 @@
+//go:build !windows
+
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setCmdLine is only needed for cmd.exe; elsewhere the arguments stand.
+func setCmdLine(*exec.Cmd, string) {}
+
+// killChildProcesses sends SIGTERM to the direct children of the shell
+// with the given PID, leaving the shell itself running.
+func killChildProcesses(pid int) {
+	output, err := exec.Command("pgrep", "-P", fmt.Sprintf("%d", pid)).Output()
+	if err != nil {
+		return
+	}
+	for pidStr := range strings.SplitSeq(string(output), "\n") {
+		if pidStr = strings.TrimSpace(pidStr); pidStr != "" {
+			var child int
+			fmt.Sscanf(pidStr, "%d", &child)
+			if child > 0 {
+				if proc, err := os.FindProcess(child); err == nil {
+					proc.Signal(syscall.SIGTERM)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package shell
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setCmdLine passes line to the process verbatim instead of letting
+// os/exec quote the arguments.
+func setCmdLine(cmd *exec.Cmd, line string) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = line
+}
+
+// killChildProcesses ends the process trees started by the shell with the
+// given PID, leaving the shell itself running. Windows has no pgrep, so
+// the children are listed through CIM.
+func killChildProcesses(pid int) {
+	query := fmt.Sprintf("Get-CimInstance Win32_Process -Filter 'ParentProcessId=%d' | Where-Object { $_.Name -ne 'conhost.exe' } | ForEach-Object { $_.ProcessId }", pid)
+	output, err := exec.Command("powershell.exe", "-NoLogo", "-NoProfile", "-NonInteractive", "-Command", query).Output()
+	if err != nil {
+		return
+	}
+	for child := range strings.FieldsSeq(string(output)) {
+		_ = exec.Command("taskkill", "/F", "/T", "/PID", child).Run()
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/config"
@@ -23,6 +22,15 @@ type PersistentShell struct {
 	cwd          string
 	mu           sync.Mutex
 	commandQueue chan *commandExecution
+
+	// tempDir holds the files commands write their output to; empty means
+	// os.TempDir(). A container shell gets its own, mounted into it.
+	tempDir string
+	// runtime and container name the container the shell runs in, if any.
+	runtime   string
+	container string
+	// dialect decides how commands are wrapped; see wrapCommand.
+	dialect Dialect
 }
 
 type commandExecution struct {
@@ -30,6 +38,7 @@ type commandExecution struct {
 	timeout    time.Duration
 	resultChan chan commandResult
 	ctx        context.Context
+	onOutput   func(chunk string)
 }
 
 type commandResult struct {
@@ -61,7 +70,20 @@ func GetPersistentShell(workingDir string) *PersistentShell {
 	return sh
 }
 
-// GetShellPath returns the shell path resolved from config, $SHELL, or /bin/bash default.
+// LookupPersistentShell returns the live shell for workingDir without
+// starting one, or nil if no command has run there yet.
+func LookupPersistentShell(workingDir string) *PersistentShell {
+	shellInstancesMu.Lock()
+	defer shellInstancesMu.Unlock()
+
+	if sh, ok := shellInstances[workingDir]; ok && sh != nil && sh.isAlive {
+		return sh
+	}
+	return nil
+}
+
+// GetShellPath returns the shell path resolved from config, $SHELL, or the
+// platform default: /bin/bash, or PowerShell or cmd on Windows.
 func GetShellPath() string {
 	cfg := config.Get()
 	if cfg != nil && cfg.Shell.Path != "" {
@@ -70,7 +92,7 @@ func GetShellPath() string {
 	if s := os.Getenv("SHELL"); s != "" {
 		return s
 	}
-	return "/bin/bash"
+	return defaultShellPath()
 }
 
 func newPersistentShell(cwd string) *PersistentShell {
@@ -82,34 +104,48 @@ func newPersistentShell(cwd string) *PersistentShell {
 		shellArgs = cfg.Shell.Args
 	}
 
-	// Default shell args
-	if len(shellArgs) == 0 {
-		shellArgs = []string{"-l"}
+	shell := &PersistentShell{
+		cwd:          cwd,
+		commandQueue: make(chan *commandExecution, 10),
+		dialect:      DialectOf(shellPath),
 	}
 
-	cmd := exec.Command(shellPath, shellArgs...)
-	cmd.Dir = cwd
+	var cmd *exec.Cmd
+	if c, ok := containerConfig(); ok {
+		tempDir, err := os.MkdirTemp("", "opencode-shell-")
+		if err != nil {
+			logging.Error(fmt.Sprintf("Can't create shell output directory: %s", err.Error()))
+			return nil
+		}
+		shell.tempDir = tempDir
+		shell.runtime = containerRuntime(c)
+		shell.container = newContainerName()
+		shell.dialect = DialectPOSIX
+		cmd = newContainerCommand(c, cwd, tempDir, shell.container, shellArgs)
+	} else {
+		if len(shellArgs) == 0 {
+			shellArgs = defaultShellArgs(shell.dialect)
+		}
+		cmd = exec.Command(shellPath, shellArgs...)
+		cmd.Dir = cwd
+		cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	}
 
 	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
+		shell.removeTempDir()
 		return nil
 	}
 
-	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
-
 	err = cmd.Start()
 	if err != nil {
 		logging.Error(fmt.Sprintf("Can't start shell: %s", err.Error()))
+		shell.removeTempDir()
 		return nil
 	}
-
-	shell := &PersistentShell{
-		cmd:          cmd,
-		stdin:        stdinPipe.(*os.File),
-		isAlive:      true,
-		cwd:          cwd,
-		commandQueue: make(chan *commandExecution, 10),
-	}
+	shell.cmd = cmd
+	shell.stdin = stdinPipe.(*os.File)
+	shell.isAlive = true
 
 	go func() {
 		defer func() {
@@ -129,6 +165,7 @@ func newPersistentShell(cwd string) *PersistentShell {
 		}
 		shell.isAlive = false
 		close(shell.commandQueue)
+		shell.removeTempDir()
 	}()
 
 	return shell
@@ -136,12 +173,12 @@ func newPersistentShell(cwd string) *PersistentShell {
 
 func (s *PersistentShell) processCommands() {
 	for cmd := range s.commandQueue {
-		result := s.execCommand(cmd.command, cmd.timeout, cmd.ctx)
+		result := s.execCommand(cmd.command, cmd.timeout, cmd.ctx, cmd.onOutput)
 		cmd.resultChan <- result
 	}
 }
 
-func (s *PersistentShell) execCommand(command string, timeout time.Duration, ctx context.Context) commandResult {
+func (s *PersistentShell) execCommand(command string, timeout time.Duration, ctx context.Context, onOutput func(string)) commandResult {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -153,31 +190,43 @@ func (s *PersistentShell) execCommand(command string, timeout time.Duration, ctx
 		}
 	}
 
-	tempDir := os.TempDir()
+	tempDir := s.tempDir
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
 	stdoutFile := filepath.Join(tempDir, fmt.Sprintf("opencode-stdout-%d", time.Now().UnixNano()))
 	stderrFile := filepath.Join(tempDir, fmt.Sprintf("opencode-stderr-%d", time.Now().UnixNano()))
 	statusFile := filepath.Join(tempDir, fmt.Sprintf("opencode-status-%d", time.Now().UnixNano()))
 	cwdFile := filepath.Join(tempDir, fmt.Sprintf("opencode-cwd-%d", time.Now().UnixNano()))
+	scriptFile := filepath.Join(tempDir, fmt.Sprintf("opencode-command-%d%s", time.Now().UnixNano(), scriptExt(s.dialect)))
+
+	fullCommand, scripts := wrapCommand(s.dialect, command, commandFiles{
+		stdout: stdoutFile,
+		stderr: stderrFile,
+		cwd:    cwdFile,
+		status: statusFile,
+		script: scriptFile,
+	})
 
 	defer func() {
 		os.Remove(stdoutFile)
 		os.Remove(stderrFile)
 		os.Remove(statusFile)
 		os.Remove(cwdFile)
+		for path := range scripts {
+			os.Remove(path)
+		}
 	}()
 
-	fullCommand := fmt.Sprintf(`
-eval %s < /dev/null > %s 2> %s
-EXEC_EXIT_CODE=$?
-pwd > %s
-echo $EXEC_EXIT_CODE > %s
-`,
-		shellQuote(command),
-		shellQuote(stdoutFile),
-		shellQuote(stderrFile),
-		shellQuote(cwdFile),
-		shellQuote(statusFile),
-	)
+	for path, body := range scripts {
+		if err := os.WriteFile(path, body, 0o600); err != nil {
+			return commandResult{
+				stderr:   fmt.Sprintf("Failed to write command script: %v", err),
+				exitCode: 1,
+				err:      err,
+			}
+		}
+	}
 
 	_, err := s.stdin.Write([]byte(fullCommand + "\n"))
 	if err != nil {
@@ -191,6 +240,7 @@ echo $EXEC_EXIT_CODE > %s
 	interrupted := false
 
 	startTime := time.Now()
+	stream := newOutputStream(onOutput, stdoutFile, stderrFile)
 
 	done := make(chan bool)
 	go func() {
@@ -203,6 +253,7 @@ echo $EXEC_EXIT_CODE > %s
 				return
 
 			case <-time.After(10 * time.Millisecond):
+				stream.poll()
 				if fileExists(statusFile) && fileSize(statusFile) > 0 {
 					done <- true
 					return
@@ -231,7 +282,7 @@ echo $EXEC_EXIT_CODE > %s
 
 	stdout := readFileOrEmpty(stdoutFile)
 	stderr := readFileOrEmpty(stderrFile)
-	exitCodeStr := readFileOrEmpty(statusFile)
+	exitCodeStr := strings.TrimSpace(readFileOrEmpty(statusFile))
 	newCwd := readFileOrEmpty(cwdFile)
 
 	exitCode := 0
@@ -258,28 +309,22 @@ func (s *PersistentShell) killChildren() {
 	if s.cmd == nil || s.cmd.Process == nil {
 		return
 	}
-
-	pgrepCmd := exec.Command("pgrep", "-P", fmt.Sprintf("%d", s.cmd.Process.Pid))
-	output, err := pgrepCmd.Output()
-	if err != nil {
+	if s.container != "" {
+		killContainerChildren(s.runtime, s.container)
 		return
 	}
-
-	for pidStr := range strings.SplitSeq(string(output), "\n") {
-		if pidStr = strings.TrimSpace(pidStr); pidStr != "" {
-			var pid int
-			fmt.Sscanf(pidStr, "%d", &pid)
-			if pid > 0 {
-				proc, err := os.FindProcess(pid)
-				if err == nil {
-					proc.Signal(syscall.SIGTERM)
-				}
-			}
-		}
-	}
+	killChildProcesses(s.cmd.Process.Pid)
 }
 
 func (s *PersistentShell) Exec(ctx context.Context, command string, timeoutMs int) (string, string, int, bool, error) {
+	return s.ExecStream(ctx, command, timeoutMs, nil)
+}
+
+// ExecStream runs command like Exec and, while it runs, passes onOutput
+// what it printed to stdout and stderr since the previous call. Chunks
+// arrive in the order they were noticed, so the two streams may interleave
+// differently than on a terminal. The returned output is complete either way.
+func (s *PersistentShell) ExecStream(ctx context.Context, command string, timeoutMs int, onOutput func(chunk string)) (string, string, int, bool, error) {
 	if !s.isAlive {
 		return "", "Shell is not alive", 1, false, errors.New("shell is not alive")
 	}
@@ -292,6 +337,7 @@ func (s *PersistentShell) Exec(ctx context.Context, command string, timeoutMs in
 		timeout:    timeout,
 		resultChan: resultChan,
 		ctx:        ctx,
+		onOutput:   onOutput,
 	}
 
 	result := <-resultChan
@@ -310,18 +356,41 @@ func (s *PersistentShell) Close() {
 
 	s.cmd.Process.Kill()
 	s.isAlive = false
+	if s.container != "" {
+		removeContainer(s.runtime, s.container)
+	}
+}
+
+func (s *PersistentShell) removeTempDir() {
+	if s.tempDir != "" {
+		os.RemoveAll(s.tempDir)
+	}
 }
 
 func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
 }
 
+// readFileOrEmpty reads a result file. PowerShell's UTF-8 files may start
+// with a byte order mark, which is dropped.
 func readFileOrEmpty(path string) string {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return ""
 	}
-	return string(content)
+	return strings.TrimPrefix(string(content), "\ufeff")
+}
+
+// scriptExt is the extension of the script a command is wrapped in; cmd
+// only runs batch files by their extension.
+func scriptExt(d Dialect) string {
+	switch d {
+	case DialectPowerShell:
+		return ".ps1"
+	case DialectCmd:
+		return ".bat"
+	}
+	return ""
 }
 
 func fileExists(path string) bool {
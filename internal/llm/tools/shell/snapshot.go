@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// snapshotTimeoutMs bounds the helper commands run to capture and restore
+// shell state so a wedged shell cannot stall shutdown or session restore.
+const snapshotTimeoutMs = 5000
+
+// volatileEnv are variables the shell maintains itself; carrying them over
+// would confuse the fresh shell rather than restore anything useful.
+var volatileEnv = map[string]bool{
+	"PWD":    true,
+	"OLDPWD": true,
+	"SHLVL":  true,
+	"_":      true,
+}
+
+// Cwd returns the directory the shell was in after its last command.
+func (s *PersistentShell) Cwd() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cwd
+}
+
+// CaptureEnv returns the variables exported in the shell whose values differ
+// from the environment opencode itself was started with, i.e. what the agent
+// changed with `export` during the session.
+func (s *PersistentShell) CaptureEnv(ctx context.Context) (map[string]string, error) {
+	stdout, stderr, exitCode, _, err := s.Exec(ctx, "env", snapshotTimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("env exited with %d: %s", exitCode, strings.TrimSpace(stderr))
+	}
+	base := parseEnv(strings.Join(os.Environ(), "\n"))
+	changed := make(map[string]string)
+	for k, v := range parseEnv(stdout) {
+		if volatileEnv[k] {
+			continue
+		}
+		if old, ok := base[k]; ok && old == v {
+			continue
+		}
+		changed[k] = v
+	}
+	return changed, nil
+}
+
+// Restore moves the shell to cwd and exports env. A cwd that no longer
+// exists is skipped so the remaining state is still applied.
+func (s *PersistentShell) Restore(ctx context.Context, cwd string, env map[string]string) error {
+	var b strings.Builder
+	if cwd != "" {
+		if info, err := os.Stat(cwd); err == nil && info.IsDir() {
+			fmt.Fprintf(&b, "cd %s\n", shellQuote(cwd))
+		}
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		if isEnvName(k) && !volatileEnv[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%s\n", k, shellQuote(env[k]))
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	_, stderr, exitCode, _, err := s.Exec(ctx, b.String(), snapshotTimeoutMs)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("restore exited with %d: %s", exitCode, strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// parseEnv parses `env` output. Values may span lines, so a line that does
+// not start with NAME= continues the previous variable.
+func parseEnv(out string) map[string]string {
+	vars := make(map[string]string)
+	last := ""
+	for line := range strings.SplitSeq(out, "\n") {
+		if k, v, ok := strings.Cut(line, "="); ok && isEnvName(k) {
+			vars[k] = v
+			last = k
+			continue
+		}
+		if last != "" {
+			vars[last] += "\n" + line
+		}
+	}
+	// The trailing newline of the output is not part of the last value.
+	if last != "" {
+		vars[last] = strings.TrimSuffix(vars[last], "\n")
+	}
+	return vars
+}
+
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
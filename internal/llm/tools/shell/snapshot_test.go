@@ -0,0 +1,56 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnv(t *testing.T) {
+	out := "HOME=/root\nMULTI=first\nsecond\nEMPTY=\nEQ=a=b\n"
+	require.Equal(t, map[string]string{
+		"HOME":  "/root",
+		"MULTI": "first\nsecond",
+		"EMPTY": "",
+		"EQ":    "a=b",
+	}, parseEnv(out))
+}
+
+func TestIsEnvName(t *testing.T) {
+	for _, name := range []string{"PATH", "_x", "GO111MODULE"} {
+		require.True(t, isEnvName(name), name)
+	}
+	for _, name := range []string{"", "1ABC", "A-B", "A B"} {
+		require.False(t, isEnvName(name), name)
+	}
+}
+
+func TestCaptureAndRestore(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+
+	sh := newPersistentShell(dir)
+	require.NotNil(t, sh)
+	defer sh.Close()
+	_, _, code, _, err := sh.Exec(ctx, "export OPENCODE_SNAPSHOT_TEST='a b' && cd sub", 5000)
+	require.NoError(t, err)
+	require.Zero(t, code)
+
+	env, err := sh.CaptureEnv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a b", env["OPENCODE_SNAPSHOT_TEST"])
+	require.NotContains(t, env, "PWD")
+	cwd := sh.Cwd()
+
+	fresh := newPersistentShell(dir)
+	require.NotNil(t, fresh)
+	defer fresh.Close()
+	require.NoError(t, fresh.Restore(ctx, cwd, env))
+	out, _, _, _, err := fresh.Exec(ctx, `pwd; echo "$OPENCODE_SNAPSHOT_TEST"`, 5000)
+	require.NoError(t, err)
+	require.Equal(t, cwd+"\na b\n", out)
+}
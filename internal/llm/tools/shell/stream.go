@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// streamInterval is how often a running command's output files are
+	// checked for new output.
+	streamInterval = 200 * time.Millisecond
+	// streamChunkLimit bounds what is read from one file per check, so a
+	// command flooding its output cannot stall the wait loop.
+	streamChunkLimit = 32 * 1024
+)
+
+// outputStream follows the files a command writes its output to and hands
+// new output to a callback.
+type outputStream struct {
+	onOutput func(string)
+	paths    []string
+	offsets  []int64
+	last     time.Time
+}
+
+func newOutputStream(onOutput func(string), paths ...string) *outputStream {
+	return &outputStream{
+		onOutput: onOutput,
+		paths:    paths,
+		offsets:  make([]int64, len(paths)),
+	}
+}
+
+// poll reports output written since the previous call, at most once per
+// streamInterval.
+func (o *outputStream) poll() {
+	if o.onOutput == nil || time.Since(o.last) < streamInterval {
+		return
+	}
+	o.last = time.Now()
+	for i, path := range o.paths {
+		if chunk := readFrom(path, &o.offsets[i]); chunk != "" {
+			o.onOutput(chunk)
+		}
+	}
+}
+
+func readFrom(path string, offset *int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, streamChunkLimit)
+	n, err := f.ReadAt(buf, *offset)
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	// Leave a rune cut off by the read for the next call.
+	for i := n - 1; i >= 0 && i >= n-utf8.UTFMax; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if !utf8.FullRune(buf[i:n]) {
+				n = i
+			}
+			break
+		}
+	}
+	chunk := string(buf[:n])
+	if *offset == 0 {
+		// PowerShell may start its UTF-8 files with a byte order mark.
+		chunk = strings.TrimPrefix(chunk, "\ufeff")
+	}
+	*offset += int64(n)
+	return chunk
+}
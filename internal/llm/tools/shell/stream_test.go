@@ -0,0 +1,49 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecStream(t *testing.T) {
+	sh := newPersistentShell(t.TempDir())
+	require.NotNil(t, sh)
+	defer sh.Close()
+
+	var mu sync.Mutex
+	var chunks []string
+	stdout, _, code, _, err := sh.ExecStream(t.Context(), "echo first; sleep 0.5; echo second", 5000, func(chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, chunk)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, code)
+	require.Equal(t, "first\nsecond\n", stdout)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The second line may land after the last check; the first must have
+	// been reported while the command was still sleeping.
+	require.NotEmpty(t, chunks)
+	require.Equal(t, "first\n", chunks[0])
+	require.True(t, strings.HasPrefix("first\nsecond\n", strings.Join(chunks, "")))
+}
+
+func TestReadFromKeepsSplitRune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, os.WriteFile(path, []byte("a\xc3"), 0o644))
+
+	var offset int64
+	require.Equal(t, "a", readFrom(path, &offset))
+	require.Equal(t, int64(1), offset)
+
+	require.NoError(t, os.WriteFile(path, []byte("a\xc3\xa9"), 0o644))
+	require.Equal(t, "é", readFrom(path, &offset))
+	require.Empty(t, readFrom(path, &offset))
+}
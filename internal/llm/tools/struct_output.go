@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+
+	"github.com/opencode-ai/opencode/internal/format"
 )
 
 const (
@@ -25,6 +27,7 @@ type structOutputTool struct {
 	schema       map[string]any
 	structParams map[string]any
 	required     []string
+	argsSchema   map[string]any
 }
 
 func NewStructOutputTool(schema map[string]any) BaseTool {
@@ -33,6 +36,7 @@ func NewStructOutputTool(schema map[string]any) BaseTool {
 		schema:       schema,
 		structParams: params,
 		required:     required,
+		argsSchema:   StructOutputSchema(schema),
 	}
 }
 
@@ -50,6 +54,9 @@ func (s *structOutputTool) Run(ctx context.Context, call ToolCall) (ToolResponse
 	if err := json.Unmarshal([]byte(call.Input), &result); err != nil {
 		return NewTextErrorResponse(fmt.Sprintf("Invalid JSON: %s", err.Error())), nil
 	}
+	if err := format.ValidateValue(s.argsSchema, result); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Output does not match the schema: %s. Call struct_output again with corrected values.", err)), nil
+	}
 	output, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return NewTextErrorResponse(fmt.Sprintf("Failed to format output: %s", err.Error())), nil
@@ -63,6 +70,29 @@ func (s *structOutputTool) AllowParallelism(call ToolCall, allCalls []ToolCall)
 
 func (s *structOutputTool) IsBaseline() bool { return true }
 
+// StructOutputSchema returns the object schema that struct_output arguments,
+// and so the run's structured output, follow for an agent output schema:
+// the schema itself for objects with properties, otherwise an object
+// wrapping it in a required "output" property.
+func StructOutputSchema(schema map[string]any) map[string]any {
+	params, required := buildParamsFromSchema(schema)
+	out := map[string]any{
+		"type":       "object",
+		"properties": params,
+	}
+	if len(required) > 0 {
+		req := make([]any, len(required))
+		for i, r := range required {
+			req[i] = r
+		}
+		out["required"] = req
+	}
+	if extra, ok := schema["additionalProperties"]; ok && schema["type"] == "object" {
+		out["additionalProperties"] = extra
+	}
+	return out
+}
+
 // buildParamsFromSchema converts a JSON schema into the ToolInfo.Parameters format.
 // If the schema is an object type with properties, those properties are used directly.
 // Otherwise, the entire schema is wrapped as a single "output" parameter.
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -142,3 +143,42 @@ func TestBuildParamsFromSchema_ObjectWithoutProperties(t *testing.T) {
 		t.Errorf("expected required=[output], got %v", required)
 	}
 }
+
+func TestStructOutputTool_Run_SchemaMismatch(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score": map[string]any{"type": "number"},
+		},
+		"required": []any{"score"},
+	}
+
+	tool := NewStructOutputTool(schema)
+
+	resp, err := tool.Run(context.Background(), ToolCall{
+		ID:    "test-3",
+		Name:  StructOutputToolName,
+		Input: `{"score": "high"}`,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.IsError {
+		t.Fatal("expected error response for a value not matching the schema")
+	}
+	if !strings.Contains(resp.Content, "$.score") {
+		t.Errorf("expected the error to name the field, got %q", resp.Content)
+	}
+}
+
+func TestStructOutputSchema_WrapsNonObject(t *testing.T) {
+	got := StructOutputSchema(map[string]any{"type": "string"})
+	if got["type"] != "object" {
+		t.Fatalf("expected an object schema, got %v", got)
+	}
+	props := got["properties"].(map[string]any)
+	if _, ok := props["output"]; !ok {
+		t.Errorf("expected the output property, got %v", props)
+	}
+}
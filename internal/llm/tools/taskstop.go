@@ -105,19 +105,31 @@ func (t *taskstopTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 		}
 	}
 
-	if err := reg.Kill(params.TaskID); err != nil {
+	forced, err := killAndAwait(reg, tk)
+	if err != nil {
 		return NewTextErrorResponse(fmt.Sprintf("kill failed: %v", err)), nil
 	}
+	if forced {
+		return NewTextResponse(fmt.Sprintf("Task %s killed (forced).", params.TaskID)), nil
+	}
+	return NewTextResponse(fmt.Sprintf("Task %s killed.", params.TaskID)), nil
+}
+
+// killAndAwait kills tk and waits synchronously (with escalation) for its
+// terminal notification to land. The originating tool's monitor goroutine
+// (bash/async/monitor) calls EnqueueTaskCompletion when its underlying work
+// observes the SIGTERM / cancel; we poll Notified.Load until either it
+// flips or we hit the 5s SIGKILL escalation window. forced reports that
+// the escalation was needed.
+func killAndAwait(reg task.Registry, tk *task.Task) (forced bool, err error) {
+	if err := reg.Kill(tk.ID); err != nil {
+		return false, err
+	}
 
-	// Wait synchronously (with escalation) for the task's terminal
-	// notification to land. The originating tool's monitor goroutine
-	// (bash/async/monitor) calls EnqueueTaskCompletion when its underlying
-	// work observes the SIGTERM / cancel; we poll Notified.Load until
-	// either it flips or we hit the 5s SIGKILL escalation window.
 	deadline := time.Now().Add(5 * time.Second)
 	for {
 		if tk.Notified.Load() {
-			return NewTextResponse(fmt.Sprintf("Task %s killed.", params.TaskID)), nil
+			return false, nil
 		}
 		if time.Now().After(deadline) {
 			// Escalate: SIGKILL the whole process group on POSIX, leaf-
@@ -141,5 +153,5 @@ func (t *taskstopTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 		}
 		time.Sleep(50 * time.Millisecond)
 	}
-	return NewTextResponse(fmt.Sprintf("Task %s killed (forced).", params.TaskID)), nil
+	return true, nil
 }
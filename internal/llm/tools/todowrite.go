@@ -52,7 +52,8 @@ Skip when:
 - Each call replaces the entire list — always include all items
 - Keep exactly one item in_progress at a time
 - Mark completed only after the required work is actually done. Never based on intent.
-- Items should be specific and actionable; break large work into smaller steps`,
+- Items should be specific and actionable; break large work into smaller steps
+- The list is kept through context compaction, so it is the place to record what is left`,
 		Parameters: map[string]any{
 			"todos": map[string]any{
 				"type":        "array",
@@ -114,6 +115,9 @@ func (t *todoWriteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 
 	// Normalize invalid status/priority values to defaults.
 	for i := range params.Todos {
+		if params.Todos[i].Status == "done" {
+			params.Todos[i].Status = "completed"
+		}
 		if !validStatuses[params.Todos[i].Status] {
 			params.Todos[i].Status = "pending"
 		}
@@ -124,14 +128,7 @@ func (t *todoWriteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 
 	t.store.Set(sessionID, params.Todos)
 
-	remaining := 0
-	for _, item := range params.Todos {
-		if item.Status != "completed" && item.Status != "cancelled" {
-			remaining++
-		}
-	}
-
-	resp := NewTextResponse(fmt.Sprintf("Todos updated. %d remaining. Continue with current tasks.", remaining))
+	resp := NewTextResponse(fmt.Sprintf("Todos updated. %d remaining. Continue with current tasks.", todo.Remaining(params.Todos)))
 	return WithResponseMetadata(resp, map[string]any{
 		"todos": params.Todos,
 	}), nil
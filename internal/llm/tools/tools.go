@@ -10,6 +10,7 @@ import (
 	agentregistry "github.com/opencode-ai/opencode/internal/agent"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/permission"
 )
@@ -36,6 +37,9 @@ type (
 	flowArgsContextKey          string
 	nonInteractiveContextKey    string
 	stepScopedContextKey        string
+	dryRunContextKey            string
+	readOnlyContextKey          string
+	diffBudgetContextKey        string
 )
 
 const (
@@ -65,6 +69,17 @@ const (
 	// instead of context.Background() so a timed-out step cancels them
 	// (see openspec flow-runtime-resume / task-async-mode specs).
 	StepScopedContextKey stepScopedContextKey = "step_scoped_ctx"
+	// DryRunContextKey marks the tool-execution ctx of an agent configured
+	// with dryRun (globally or per agent). Write tools then report the
+	// change they would make instead of making it; see dryrun.go.
+	DryRunContextKey dryRunContextKey = "dry_run"
+	// ReadOnlyContextKey marks the tool-execution ctx of a process running
+	// in read-only mode; bash then refuses anything but read-only commands.
+	// See readonly.go.
+	ReadOnlyContextKey readOnlyContextKey = "read_only"
+	// DiffBudgetContextKey carries the *DiffBudget of the current turn;
+	// write tools charge their changes to it. See diffbudget.go.
+	DiffBudgetContextKey diffBudgetContextKey = "diff_budget"
 
 	// MaxToolResponseTokens is the maximum number of tokens allowed in a tool response
 	// to prevent context overflow. ~1200KB of text content.
@@ -214,10 +229,35 @@ func hasFileConflict(call ToolCall, myPaths []string, allCalls []ToolCall) bool
 }
 
 func IsSafeReadOnlyCommand(command string) bool {
+	return isSafeReadOnlyCommand(command, shell.CurrentDialect())
+}
+
+// isSafeReadOnlyCommand matches command against the safe prefixes. For
+// PowerShell and cmd it also accepts their own read-only commands, a
+// leading call operator (& git status), an .exe suffix (git.exe status)
+// and /flags (dir/b).
+func isSafeReadOnlyCommand(command string, dialect shell.Dialect) bool {
 	cmdLower := strings.ToLower(command)
-	for _, safe := range safeReadOnlyCommands {
+	safeList := safeReadOnlyCommands
+	separators := " -"
+	switch dialect {
+	case shell.DialectPowerShell:
+		cmdLower = strings.TrimPrefix(strings.TrimSpace(cmdLower), "& ")
+		safeList = append(slices.Clone(safeList), powershellSafeReadOnlyCommands...)
+		separators = " -/"
+	case shell.DialectCmd:
+		safeList = append(slices.Clone(safeList), cmdSafeReadOnlyCommands...)
+		separators = " -/"
+	}
+	if dialect != shell.DialectPOSIX {
+		first, rest, _ := strings.Cut(cmdLower, " ")
+		if trimmed, ok := strings.CutSuffix(first, ".exe"); ok {
+			cmdLower = strings.TrimSpace(trimmed + " " + rest)
+		}
+	}
+	for _, safe := range safeList {
 		if strings.HasPrefix(cmdLower, strings.ToLower(safe)) {
-			if len(cmdLower) == len(safe) || cmdLower[len(safe)] == ' ' || cmdLower[len(safe)] == '-' {
+			if len(cmdLower) == len(safe) || strings.ContainsRune(separators, rune(cmdLower[len(safe)])) {
 				return true
 			}
 		}
@@ -0,0 +1,288 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	agentregistry "github.com/opencode-ai/opencode/internal/agent"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type TreeParams struct {
+	Path       string   `json:"path"`
+	Depth      int      `json:"depth,omitempty"`
+	MaxEntries int      `json:"max_entries,omitempty"`
+	Sizes      *bool    `json:"sizes,omitempty"`
+	Ignore     []string `json:"ignore,omitempty"`
+}
+
+type TreeResponseMetadata struct {
+	NumberOfFiles int   `json:"number_of_files"`
+	TotalSize     int64 `json:"total_size"`
+	Truncated     bool  `json:"truncated"`
+}
+
+type treeTool struct {
+	cfg         config.Configurator
+	registry    agentregistry.Registry
+	permissions permission.Service
+}
+
+const (
+	TreeToolName = "tree"
+
+	defaultTreeDepth      = 3
+	maxTreeDepth          = 10
+	defaultTreeMaxEntries = 50
+	// maxTreeFiles bounds the files scanned; counts and sizes past it are
+	// left out and the output says so.
+	maxTreeFiles = 100_000
+
+	treeDescription = `Compact directory tree with per-directory file counts and sizes, for getting the shape of a project in one call.
+
+WHEN TO USE THIS TOOL:
+- Use it to see how a project or a large directory is organised before reading files
+- Prefer it over chaining ls and glob calls to explore several levels of directories
+
+HOW TO USE:
+- path: directory to show (defaults to the working directory)
+- depth: directory levels to expand (default 3, at most 10); deeper directories are shown collapsed with their totals
+- max_entries: entries shown per directory (default 50); the rest are summarised in one line
+- sizes: set to false to leave out file sizes
+- ignore: extra glob patterns to skip
+
+Respects .gitignore when ripgrep is available, and skips hidden files and common build and dependency directories.
+Each directory line reads "name/ (files, size)", counting every file below it, expanded or not.`
+)
+
+func NewTreeTool(cfg config.Configurator, reg agentregistry.Registry, permissions permission.Service) BaseTool {
+	return &treeTool{cfg: cfg, registry: reg, permissions: permissions}
+}
+
+func (t *treeTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        TreeToolName,
+		Description: treeDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The directory to show (defaults to current working directory)",
+			},
+			"depth": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Directory levels to expand (default %d, max %d)", defaultTreeDepth, maxTreeDepth),
+			},
+			"max_entries": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Entries shown per directory before the rest are summarised (default %d)", defaultTreeMaxEntries),
+			},
+			"sizes": map[string]any{
+				"type":        "boolean",
+				"description": "Show file and directory sizes (default true)",
+			},
+			"ignore": map[string]any{
+				"type":        "array",
+				"description": "List of glob patterns to ignore",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+	}
+}
+
+type treeDir struct {
+	name  string
+	files int
+	size  int64
+	dirs  map[string]*treeDir
+	// entries are the files directly in the directory.
+	entries []treeFile
+}
+
+type treeFile struct {
+	name string
+	size int64
+}
+
+func newTreeDir(name string) *treeDir {
+	return &treeDir{name: name, dirs: map[string]*treeDir{}}
+}
+
+func (d *treeDir) add(parts []string, size int64) {
+	d.files++
+	d.size += size
+	if len(parts) == 1 {
+		d.entries = append(d.entries, treeFile{name: parts[0], size: size})
+		return
+	}
+	child, ok := d.dirs[parts[0]]
+	if !ok {
+		child = newTreeDir(parts[0])
+		d.dirs[parts[0]] = child
+	}
+	child.add(parts[1:], size)
+}
+
+func (t *treeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params TreeParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	root := params.Path
+	if root == "" {
+		root = t.cfg.WorkingDirectory()
+	}
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(t.cfg.WorkingDirectory(), root)
+	}
+	depth := params.Depth
+	if depth <= 0 {
+		depth = defaultTreeDepth
+	}
+	depth = min(depth, maxTreeDepth)
+	maxEntries := params.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultTreeMaxEntries
+	}
+	sizes := params.Sizes == nil || *params.Sizes
+
+	if err := checkReadPermission(ctx, t.registry, t.permissions, TreeToolName, root); err != nil {
+		if err == permission.ErrorPermissionDenied {
+			return NewTextErrorResponse(fmt.Sprintf("Permission denied: listing %s", root)), nil
+		}
+		return NewEmptyResponse(), err
+	}
+	if info, err := os.Stat(root); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("path does not exist: %s", root)), nil
+	} else if !info.IsDir() {
+		return NewTextErrorResponse(fmt.Sprintf("not a directory: %s", root)), nil
+	}
+
+	paths, truncated, err := listDirectory(ctx, root, params.Ignore, maxTreeFiles)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error listing directory: %w", err)
+	}
+
+	tree := newTreeDir(root)
+	sep := string(filepath.Separator)
+	for _, p := range paths {
+		// The walker fallback lists directories too; they are implied by
+		// the files below them.
+		if strings.HasSuffix(p, sep) {
+			continue
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		var size int64
+		if sizes {
+			if info, err := os.Lstat(p); err == nil {
+				size = info.Size()
+			}
+		}
+		tree.add(strings.Split(rel, sep), size)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s%s %s\n", root, sep, treeSummary(tree.files, tree.size, sizes))
+	printTreeDir(&out, tree, 1, depth, maxEntries, sizes)
+	if truncated {
+		fmt.Fprintf(&out, "\nOnly the first %d files were scanned; counts and sizes are incomplete. Use a more specific path.\n", maxTreeFiles)
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(out.String()),
+		TreeResponseMetadata{
+			NumberOfFiles: tree.files,
+			TotalSize:     tree.size,
+			Truncated:     truncated,
+		},
+	), nil
+}
+
+func printTreeDir(out *strings.Builder, d *treeDir, level, depth, maxEntries int, sizes bool) {
+	indent := strings.Repeat("  ", level)
+	names := make([]string, 0, len(d.dirs))
+	for name := range d.dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sort.Slice(d.entries, func(i, j int) bool { return d.entries[i].name < d.entries[j].name })
+
+	shown := 0
+	for _, name := range names {
+		if shown == maxEntries {
+			break
+		}
+		child := d.dirs[name]
+		fmt.Fprintf(out, "%s%s/ %s\n", indent, name, treeSummary(child.files, child.size, sizes))
+		if level < depth {
+			printTreeDir(out, child, level+1, depth, maxEntries, sizes)
+		}
+		shown++
+	}
+	for _, f := range d.entries {
+		if shown == maxEntries {
+			break
+		}
+		if sizes {
+			fmt.Fprintf(out, "%s%s (%s)\n", indent, f.name, formatSize(f.size))
+		} else {
+			fmt.Fprintf(out, "%s%s\n", indent, f.name)
+		}
+		shown++
+	}
+
+	if hidden := len(names) + len(d.entries) - shown; hidden > 0 {
+		var files int
+		var size int64
+		for i := shown; i < len(names); i++ {
+			files += d.dirs[names[i]].files
+			size += d.dirs[names[i]].size
+		}
+		for i := max(0, shown-len(names)); i < len(d.entries); i++ {
+			files++
+			size += d.entries[i].size
+		}
+		fmt.Fprintf(out, "%s... %d more entries %s\n", indent, hidden, treeSummary(files, size, sizes))
+	}
+}
+
+func treeSummary(files int, size int64, sizes bool) string {
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	if !sizes {
+		return fmt.Sprintf("(%d %s)", files, noun)
+	}
+	return fmt.Sprintf("(%d %s, %s)", files, noun, formatSize(size))
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (t *treeTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
+	return true
+}
+
+func (t *treeTool) IsBaseline() bool { return true }
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mock_config "github.com/opencode-ai/opencode/internal/config/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func runTree(t *testing.T, params TreeParams) (ToolResponse, TreeResponseMetadata) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	cfg := mock_config.NewMockConfigurator(ctrl)
+	cfg.EXPECT().WorkingDirectory().AnyTimes().Return(params.Path)
+
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := NewTreeTool(cfg, nil, nil).Run(context.Background(), ToolCall{Name: TreeToolName, Input: string(input)})
+	require.NoError(t, err)
+	var meta TreeResponseMetadata
+	if resp.Metadata != "" {
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	}
+	return resp, meta
+}
+
+func writeTreeFiles(t *testing.T, root string, files map[string]int) {
+	t.Helper()
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+	}
+}
+
+func TestTreeTool_Run(t *testing.T) {
+	root := t.TempDir()
+	writeTreeFiles(t, root, map[string]int{
+		"main.go":                 100,
+		"internal/a/a.go":         2048,
+		"internal/a/deep/d.go":    10,
+		"internal/b/b.go":         30,
+		".hidden/secret.txt":      5,
+		"node_modules/pkg/idx.js": 5,
+	})
+
+	t.Run("summarises directories", func(t *testing.T) {
+		resp, meta := runTree(t, TreeParams{Path: root, Depth: 2})
+		assert.False(t, resp.IsError)
+		assert.Equal(t, 4, meta.NumberOfFiles)
+		assert.EqualValues(t, 2188, meta.TotalSize)
+
+		lines := strings.Split(strings.TrimSpace(resp.Content), "\n")
+		assert.Equal(t, root+string(filepath.Separator)+" (4 files, 2.1 KB)", lines[0])
+		assert.Contains(t, resp.Content, "  internal/ (3 files, 2.0 KB)\n")
+		assert.Contains(t, resp.Content, "    a/ (2 files, 2.0 KB)\n")
+		assert.Contains(t, resp.Content, "  main.go (100 B)\n")
+		// Depth 2 shows deep/ only as part of a/'s totals.
+		assert.NotContains(t, resp.Content, "deep/")
+		assert.NotContains(t, resp.Content, "secret.txt")
+		assert.NotContains(t, resp.Content, "node_modules")
+	})
+
+	t.Run("without sizes", func(t *testing.T) {
+		sizes := false
+		resp, _ := runTree(t, TreeParams{Path: root, Depth: 5, Sizes: &sizes})
+		assert.Contains(t, resp.Content, "      deep/ (1 file)\n")
+		assert.Contains(t, resp.Content, "  main.go\n")
+		assert.NotContains(t, resp.Content, " B)")
+	})
+
+	t.Run("not a directory", func(t *testing.T) {
+		resp, _ := runTree(t, TreeParams{Path: filepath.Join(root, "main.go")})
+		assert.True(t, resp.IsError)
+	})
+}
+
+func TestTreeTool_TruncatesPerDirectory(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{}
+	for i := range 5 {
+		files[fmt.Sprintf("f%d.txt", i)] = 10
+	}
+	files["sub/x.txt"] = 1
+	writeTreeFiles(t, root, files)
+
+	resp, meta := runTree(t, TreeParams{Path: root, MaxEntries: 3})
+	assert.Equal(t, 6, meta.NumberOfFiles)
+	assert.Contains(t, resp.Content, "  sub/ (1 file, 1 B)\n")
+	assert.Contains(t, resp.Content, "  f1.txt (10 B)\n")
+	assert.NotContains(t, resp.Content, "f2.txt")
+	assert.Contains(t, resp.Content, "  ... 3 more entries (3 files, 30 B)\n")
+}
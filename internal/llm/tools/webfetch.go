@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -22,10 +23,12 @@ type FetchParams struct {
 	URL     string `json:"url"`
 	Format  string `json:"format"`
 	Timeout int    `json:"timeout,omitempty"`
+	MaxSize int    `json:"max_size,omitempty"`
 }
 
 type FetchPermissionsParams struct {
 	URL     string `json:"url"`
+	Domain  string `json:"domain"`
 	Format  string `json:"format"`
 	Timeout int    `json:"timeout,omitempty"`
 }
@@ -37,9 +40,14 @@ type fetchTool struct {
 }
 
 const (
-	WebFetchToolName     = "webfetch"
-	browserUserAgent     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
-	fetchToolDescription = `Fetches text-based content from a URL and returns it in the specified format.
+	WebFetchToolName = "webfetch"
+	// maxFetchBytes caps how much of a response body is downloaded.
+	maxFetchBytes = 5 * 1024 * 1024
+	// defaultFetchOutputBytes is how much converted content is returned
+	// when the call does not set max_size.
+	defaultFetchOutputBytes = 100 * 1024
+	browserUserAgent        = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+	fetchToolDescription    = `Fetches text-based content from a URL and returns it in the specified format.
 
 WHEN TO USE THIS TOOL:
 - Use when you need to fetch text-based content from a URL (HTML pages, API responses, documentation)
@@ -54,15 +62,17 @@ HOW TO USE:
 - Provide the URL to fetch content from
 - Specify the desired output format (text, markdown, or html)
 - Optionally set a timeout for the request
+- Optionally set max_size to return more (or less) than the default 100KB of content
 
 FEATURES:
 - Supports three output formats: text, markdown, and html
+- In text and markdown formats, navigation, headers, footers, scripts and similar page chrome are stripped; when the page has a main or article element only that is kept
 - Automatically handles HTTP redirects
 - Sets reasonable timeouts to prevent hanging
 - Validates input parameters before making requests
 
 LIMITATIONS:
-- Maximum response size is 5MB
+- At most 5MB of the response is downloaded; content beyond max_size is cut off with a note saying so
 - Only supports text-based content (HTML, JSON, plain text, XML, etc.)
 - Binary content (images, archives, executables, PDFs) will be rejected — use bash with curl for those
 - Only supports HTTP and HTTPS protocols
@@ -105,6 +115,10 @@ func (t *fetchTool) Info() ToolInfo {
 				"type":        "number",
 				"description": "Optional timeout in seconds (max 120)",
 			},
+			"max_size": map[string]any{
+				"type":        "number",
+				"description": "Optional maximum size in bytes of the returned content (default 102400, max 5242880)",
+			},
 		},
 		Required: []string{"url"},
 	}
@@ -131,13 +145,25 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	if !strings.HasPrefix(params.URL, "http://") && !strings.HasPrefix(params.URL, "https://") {
 		return NewTextErrorResponse("URL must start with http:// or https://"), nil
 	}
+	parsed, err := url.Parse(params.URL)
+	if err != nil || parsed.Hostname() == "" {
+		return NewTextErrorResponse("Invalid URL: " + params.URL), nil
+	}
+	// Permission rules for this tool are glob patterns on the domain,
+	// e.g. "*.github.com": "allow".
+	domain := strings.ToLower(parsed.Hostname())
+
+	maxSize := defaultFetchOutputBytes
+	if params.MaxSize > 0 {
+		maxSize = min(params.MaxSize, maxFetchBytes)
+	}
 
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
 		return NewEmptyResponse(), fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 
-	action := t.agentRegistry.EvaluatePermission(string(GetAgentID(ctx)), WebFetchToolName, params.URL)
+	action := t.agentRegistry.EvaluatePermission(string(GetAgentID(ctx)), WebFetchToolName, domain)
 	switch action {
 	case permission.ActionAllow:
 		// Allowed by config, skip interactive permission
@@ -148,10 +174,16 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        config.WorkingDirectory(),
+				Pattern:     domain,
 				ToolName:    WebFetchToolName,
 				Action:      "webfetch",
-				Description: fmt.Sprintf("Fetch content from URL: %s", params.URL),
-				Params:      FetchPermissionsParams(params),
+				Description: fmt.Sprintf("Fetch content from %s: %s", domain, params.URL),
+				Params: FetchPermissionsParams{
+					URL:     params.URL,
+					Domain:  domain,
+					Format:  params.Format,
+					Timeout: params.Timeout,
+				},
 			},
 		) {
 			return NewEmptyResponse(), permission.ErrorPermissionDenied
@@ -226,17 +258,21 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	}
 	defer resp.Body.Close()
 
-	maxSize := int64(5 * 1024 * 1024) // 5MB
 	if cl := resp.Header.Get("Content-Length"); cl != "" {
-		if size, err := strconv.ParseInt(cl, 10, 64); err == nil && size > maxSize {
-			return NewTextErrorResponse(fmt.Sprintf("Response too large: %d bytes (max %d bytes)", size, maxSize)), nil
+		if size, err := strconv.ParseInt(cl, 10, 64); err == nil && size > maxFetchBytes {
+			return NewTextErrorResponse(fmt.Sprintf("Response too large: %d bytes (max %d bytes)", size, maxFetchBytes)), nil
 		}
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+	// Read one byte past the cap to tell a body of exactly maxFetchBytes
+	// from a longer one.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
 	if err != nil {
 		return NewTextErrorResponse("Failed to read response body: " + err.Error()), nil
 	}
+	if len(body) > maxFetchBytes {
+		return NewTextErrorResponse(fmt.Sprintf("Response too large: more than %d bytes", maxFetchBytes)), nil
+	}
 
 	if isBinaryContent(resp.Header.Get("Content-Type"), body) {
 		return NewTextErrorResponse(fmt.Sprintf(
@@ -248,36 +284,31 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	}
 
 	content := string(body)
-	contentType := resp.Header.Get("Content-Type")
+	isHTML := strings.Contains(resp.Header.Get("Content-Type"), "text/html")
 
 	switch format {
 	case "text":
-		if strings.Contains(contentType, "text/html") {
+		if isHTML {
 			text, err := extractTextFromHTML(content)
 			if err != nil {
 				return NewTextErrorResponse("Failed to extract text from HTML: " + err.Error()), nil
 			}
-			return NewTextResponse(text), nil
+			content = text
 		}
-		return NewTextResponse(content), nil
 
 	case "markdown":
-		if strings.Contains(contentType, "text/html") {
+		if isHTML {
 			markdown, err := convertHTMLToMarkdown(content)
 			if err != nil {
 				return NewTextErrorResponse("Failed to convert HTML to Markdown: " + err.Error()), nil
 			}
-			return NewTextResponse(markdown), nil
+			content = markdown
+		} else {
+			content = "```\n" + content + "\n```"
 		}
-
-		return NewTextResponse("```\n" + content + "\n```"), nil
-
-	case "html":
-		return NewTextResponse(content), nil
-
-	default:
-		return NewTextResponse(content), nil
 	}
+
+	return NewTextResponse(truncateFetchedContent(content, maxSize)), nil
 }
 
 func (t *fetchTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
@@ -295,23 +326,78 @@ func isWAFChallenge(resp *http.Response) bool {
 }
 
 func extractTextFromHTML(html string) (string, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	content, err := stripBoilerplate(html)
 	if err != nil {
 		return "", err
 	}
 
-	text := doc.Text()
+	text := content.Text()
 	text = strings.Join(strings.Fields(text), " ")
 
 	return text, nil
 }
 
 func convertHTMLToMarkdown(html string) (string, error) {
-	markdown, err := htmltomarkdown.ConvertString(html)
+	content, err := stripBoilerplate(html)
+	if err != nil {
+		return "", err
+	}
+	cleaned, err := goquery.OuterHtml(content)
 	if err != nil {
 		return "", err
 	}
-	return markdown, nil
+	markdown, err := htmltomarkdown.ConvertString(cleaned)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(markdown), nil
+}
+
+// boilerplateSelector matches page chrome that carries no content of its
+// own: scripts, navigation, sidebars and forms. Site headers and footers
+// are handled apart because an article's own header holds its title.
+const boilerplateSelector = "script, style, noscript, template, iframe, svg, canvas, form, nav, aside, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [role=complementary], [role=search], " +
+	"[aria-hidden=true], [hidden]"
+
+const contentSelector = "main, [role=main], article"
+
+// stripBoilerplate parses html, drops boilerplateSelector and returns the
+// element holding the page content: the first main or article element when
+// there is one, the body otherwise.
+func stripBoilerplate(html string) (*goquery.Selection, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, err
+	}
+	doc.Find(boilerplateSelector).Remove()
+	doc.Find("header, footer").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.Closest(contentSelector).Length() == 0
+	}).Remove()
+
+	for _, sel := range strings.Split(contentSelector, ", ") {
+		if content := doc.Find(sel).First(); content.Length() > 0 {
+			return content, nil
+		}
+	}
+	if body := doc.Find("body"); body.Length() > 0 {
+		return body, nil
+	}
+	return doc.Selection, nil
+}
+
+// truncateFetchedContent cuts content to maxSize bytes on a rune boundary
+// and says how much was left out.
+func truncateFetchedContent(content string, maxSize int) string {
+	if len(content) <= maxSize {
+		return content
+	}
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n\n[Content truncated: showing %d of %d bytes. Call again with a larger max_size to read more.]",
+		content[:cut], cut, len(content))
 }
 
 var binaryContentTypes = []string{
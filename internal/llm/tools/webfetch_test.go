@@ -319,3 +319,78 @@ func TestFetchNonCloudflare403(t *testing.T) {
 		t.Errorf("server received %d requests, want 1 (no retry)", got)
 	}
 }
+
+func TestFetchStripsBoilerplate(t *testing.T) {
+	page := `<html><head><style>body{}</style><script>track()</script></head><body>
+<header><a href="/">Site logo</a></header>
+<nav><a href="/docs">Docs</a> <a href="/blog">Blog</a></nav>
+<main><article><header><h1>Install guide</h1></header><p>Run the installer.</p></article></main>
+<aside>Related posts</aside>
+<footer>Copyright</footer>
+</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	tool := newTestFetchTool(t)
+	for _, format := range []string{"markdown", "text"} {
+		input, _ := json.Marshal(FetchParams{URL: server.URL, Format: format})
+		resp, err := tool.Run(fetchToolCtx(), ToolCall{ID: "1", Name: WebFetchToolName, Input: string(input)})
+		if err != nil || resp.IsError {
+			t.Fatalf("%s: unexpected failure: %v %s", format, err, resp.Content)
+		}
+		for _, want := range []string{"Install guide", "Run the installer."} {
+			if !strings.Contains(resp.Content, want) {
+				t.Errorf("%s: content = %q, want to contain %q", format, resp.Content, want)
+			}
+		}
+		for _, chrome := range []string{"Site logo", "Blog", "Related posts", "Copyright", "track()"} {
+			if strings.Contains(resp.Content, chrome) {
+				t.Errorf("%s: content = %q, should not contain %q", format, resp.Content, chrome)
+			}
+		}
+	}
+}
+
+func TestFetchTruncatesToMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("é", 100))
+	}))
+	defer server.Close()
+
+	tool := newTestFetchTool(t)
+	input, _ := json.Marshal(FetchParams{URL: server.URL, Format: "text", MaxSize: 51})
+	resp, err := tool.Run(fetchToolCtx(), ToolCall{ID: "1", Name: WebFetchToolName, Input: string(input)})
+	if err != nil || resp.IsError {
+		t.Fatalf("unexpected failure: %v %s", err, resp.Content)
+	}
+	body, note, ok := strings.Cut(resp.Content, "\n\n")
+	if !ok || !strings.Contains(note, "showing 50 of 200 bytes") {
+		t.Fatalf("content = %q, want a truncation note after 50 bytes", resp.Content)
+	}
+	if body != strings.Repeat("é", 25) {
+		t.Errorf("truncated body = %q, want 25 whole runes", body)
+	}
+}
+
+func TestFetchPermissionUsesDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRegistry := mock_agent.NewMockRegistry(ctrl)
+	mockRegistry.EXPECT().
+		EvaluatePermission(gomock.Any(), WebFetchToolName, "docs.example.com").
+		Return(permission.ActionDeny)
+	tool := &fetchTool{
+		agentRegistry: mockRegistry,
+		client:        http.DefaultClient,
+		permissions:   mock_permission.NewMockService(ctrl),
+	}
+
+	input, _ := json.Marshal(FetchParams{URL: "https://Docs.Example.com:8443/guide?x=1"})
+	_, err := tool.Run(fetchToolCtx(), ToolCall{ID: "1", Name: WebFetchToolName, Input: string(input)})
+	if err != permission.ErrorPermissionDenied {
+		t.Fatalf("err = %v, want ErrorPermissionDenied", err)
+	}
+}
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -43,10 +42,30 @@ type SearchProviderInfo struct {
 }
 
 type ResolvedProvider struct {
+	// Type is one of the SearchBackend* constants.
+	Type    string
 	BaseURL string
 	APIKey  string
 }
 
+// WebSearchResponseMetadata is attached to every successful websearch
+// response so clients get the ranked results without parsing the text.
+type WebSearchResponseMetadata struct {
+	Provider string            `json:"provider"`
+	Query    string            `json:"query"`
+	Results  []WebSearchResult `json:"results"`
+}
+
+type WebSearchResult struct {
+	// Rank is the 1-based position the backend ranked the result at.
+	Rank    int     `json:"rank"`
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score,omitempty"`
+	Date    string  `json:"date,omitempty"`
+}
+
 type SearchProviderRegistry interface {
 	Providers() []SearchProviderInfo
 	GetProvider(name string) (*ResolvedProvider, error)
@@ -97,8 +116,13 @@ func (r *searchProviderRegistry) GetProvider(name string) (*ResolvedProvider, er
 		sort.Strings(available)
 		return nil, fmt.Errorf("provider %q not found. Available providers: %s", name, strings.Join(available, ", "))
 	}
+	typ, baseURL, err := resolveSearchBackend(p.Type, p.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("provider %q: %w", name, err)
+	}
 	return &ResolvedProvider{
-		BaseURL: p.BaseURL,
+		Type:    typ,
+		BaseURL: baseURL,
 		APIKey:  resolveAPIKey(p),
 	}, nil
 }
@@ -197,11 +221,12 @@ type searchResponse struct {
 }
 
 type searchResult struct {
-	Title   string `json:"title"`
-	URL     string `json:"url"`
-	Snippet string `json:"snippet"`
-	Content string `json:"content"`
-	Date    string `json:"date"`
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Snippet string  `json:"snippet"`
+	Content string  `json:"content"`
+	Score   float64 `json:"score,omitempty"`
+	Date    string  `json:"date"`
 }
 
 func (t *websearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
@@ -246,6 +271,7 @@ func (t *websearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        config.WorkingDirectory(),
+				Pattern:     params.Query,
 				ToolName:    WebSearchToolName,
 				Action:      "websearch",
 				Description: fmt.Sprintf("Web search query: %s", params.Query),
@@ -256,20 +282,10 @@ func (t *websearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 		}
 	}
 
-	reqBody, _ := json.Marshal(map[string]any{
-		"query":       params.Query,
-		"max_results": maxResults,
-	})
-
-	req, err := http.NewRequestWithContext(ctx, "POST", provider.BaseURL, bytes.NewReader(reqBody))
+	req, err := newSearchRequest(ctx, provider, params.Query, maxResults)
 	if err != nil {
 		return NewTextErrorResponse("Failed to create request: " + err.Error()), nil
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "opencode/1.0")
-	if provider.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+provider.APIKey)
-	}
 
 	resp, err := t.client.Do(req)
 	if err != nil {
@@ -294,16 +310,25 @@ func (t *websearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 		return NewTextErrorResponse(msg), nil
 	}
 
-	var searchResp searchResponse
-	if err := json.Unmarshal(body, &searchResp); err != nil {
+	results, err := decodeSearchResults(provider.Type, body)
+	if err != nil {
 		return NewTextErrorResponse("Failed to parse search response: " + err.Error()), nil
 	}
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
 
-	if len(searchResp.Results) == 0 {
+	if len(results) == 0 {
 		return NewTextResponse(fmt.Sprintf("No results found for query: %q. Try different search terms.", params.Query)), nil
 	}
 
-	return NewTextResponse(formatResults(searchResp.Results)), nil
+	return WithResponseMetadata(
+		NewTextResponse(formatResults(results)),
+		WebSearchResponseMetadata{
+			Provider: params.Provider,
+			Query:    params.Query,
+			Results:  rankedResults(results),
+		}), nil
 }
 
 func (t *websearchTool) AllowParallelism(call ToolCall, allCalls []ToolCall) bool {
@@ -312,6 +337,25 @@ func (t *websearchTool) AllowParallelism(call ToolCall, allCalls []ToolCall) boo
 
 func (t *websearchTool) IsBaseline() bool { return true }
 
+func rankedResults(results []searchResult) []WebSearchResult {
+	ranked := make([]WebSearchResult, len(results))
+	for i, r := range results {
+		snippet := r.Snippet
+		if snippet == "" {
+			snippet = r.Content
+		}
+		ranked[i] = WebSearchResult{
+			Rank:    i + 1,
+			Title:   r.Title,
+			URL:     r.URL,
+			Snippet: snippet,
+			Score:   r.Score,
+			Date:    r.Date,
+		}
+	}
+	return ranked
+}
+
 func formatResults(results []searchResult) string {
 	var sb strings.Builder
 	sb.WriteString("## Search Results\n\n")
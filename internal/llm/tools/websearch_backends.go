@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Search backends select the wire format used to talk to a provider, see
+// config.WebSearchProvider.Type.
+const (
+	// SearchBackendGeneric POSTs {"query", "max_results"} and expects
+	// {"results": [{"title", "url", "snippet"|"content", "date"}]}. It
+	// suits search proxies written for opencode.
+	SearchBackendGeneric = "generic"
+	SearchBackendTavily  = "tavily"
+	SearchBackendBrave   = "brave"
+	// SearchBackendSearxNG queries the JSON API of a self-hosted SearXNG
+	// instance; the instance must list "json" in search.formats.
+	SearchBackendSearxNG = "searxng"
+)
+
+var defaultSearchBaseURLs = map[string]string{
+	SearchBackendTavily: "https://api.tavily.com/search",
+	SearchBackendBrave:  "https://api.search.brave.com/res/v1/web/search",
+}
+
+// resolveSearchBackend normalises a configured type and fills in the
+// backend's default base URL.
+func resolveSearchBackend(typ, baseURL string) (string, string, error) {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	switch typ {
+	case "":
+		typ = SearchBackendGeneric
+	case SearchBackendGeneric, SearchBackendTavily, SearchBackendBrave, SearchBackendSearxNG:
+	default:
+		return "", "", fmt.Errorf("unsupported search provider type %q (want %s, %s, %s or %s)",
+			typ, SearchBackendGeneric, SearchBackendTavily, SearchBackendBrave, SearchBackendSearxNG)
+	}
+	if baseURL == "" {
+		baseURL = defaultSearchBaseURLs[typ]
+	}
+	if baseURL == "" {
+		return "", "", fmt.Errorf("search provider type %q requires baseUrl", typ)
+	}
+	return typ, baseURL, nil
+}
+
+func newSearchRequest(ctx context.Context, p *ResolvedProvider, query string, maxResults int) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	switch p.Type {
+	case SearchBackendBrave:
+		u, parseErr := url.Parse(p.BaseURL)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		q := u.Query()
+		q.Set("q", query)
+		q.Set("count", strconv.Itoa(maxResults))
+		u.RawQuery = q.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if p.APIKey != "" {
+			req.Header.Set("X-Subscription-Token", p.APIKey)
+		}
+
+	case SearchBackendSearxNG:
+		u, parseErr := url.Parse(strings.TrimRight(p.BaseURL, "/"))
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if !strings.HasSuffix(u.Path, "/search") {
+			u.Path += "/search"
+		}
+		q := u.Query()
+		q.Set("q", query)
+		q.Set("format", "json")
+		u.RawQuery = q.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if p.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+p.APIKey)
+		}
+
+	default:
+		// Tavily takes the same body as the generic protocol.
+		reqBody, _ := json.Marshal(map[string]any{
+			"query":       query,
+			"max_results": maxResults,
+		})
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if p.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+p.APIKey)
+		}
+	}
+	req.Header.Set("User-Agent", "opencode/1.0")
+	return req, nil
+}
+
+// decodeSearchResults parses a backend response into results in the
+// backend's ranking order.
+func decodeSearchResults(backend string, body []byte) ([]searchResult, error) {
+	switch backend {
+	case SearchBackendTavily:
+		var resp struct {
+			Results []struct {
+				Title         string  `json:"title"`
+				URL           string  `json:"url"`
+				Content       string  `json:"content"`
+				Score         float64 `json:"score"`
+				PublishedDate string  `json:"published_date"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		results := make([]searchResult, 0, len(resp.Results))
+		for _, r := range resp.Results {
+			results = append(results, searchResult{Title: r.Title, URL: r.URL, Snippet: r.Content, Score: r.Score, Date: r.PublishedDate})
+		}
+		return results, nil
+
+	case SearchBackendBrave:
+		var resp struct {
+			Web struct {
+				Results []struct {
+					Title       string `json:"title"`
+					URL         string `json:"url"`
+					Description string `json:"description"`
+					Age         string `json:"age"`
+				} `json:"results"`
+			} `json:"web"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		results := make([]searchResult, 0, len(resp.Web.Results))
+		for _, r := range resp.Web.Results {
+			results = append(results, searchResult{Title: stripSnippetTags(r.Title), URL: r.URL, Snippet: stripSnippetTags(r.Description), Date: r.Age})
+		}
+		return results, nil
+
+	case SearchBackendSearxNG:
+		var resp struct {
+			Results []struct {
+				Title         string  `json:"title"`
+				URL           string  `json:"url"`
+				Content       string  `json:"content"`
+				Score         float64 `json:"score"`
+				PublishedDate string  `json:"publishedDate"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		results := make([]searchResult, 0, len(resp.Results))
+		for _, r := range resp.Results {
+			results = append(results, searchResult{Title: r.Title, URL: r.URL, Snippet: r.Content, Score: r.Score, Date: r.PublishedDate})
+		}
+		return results, nil
+
+	default:
+		var resp searchResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+		return resp.Results, nil
+	}
+}
+
+var snippetTagPattern = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+// stripSnippetTags removes the highlight markup (<strong>) Brave puts
+// around matched terms.
+func stripSnippetTags(s string) string {
+	return snippetTagPattern.ReplaceAllString(s, "")
+}
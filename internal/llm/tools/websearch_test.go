@@ -429,3 +429,135 @@ func TestFormatResults(t *testing.T) {
 		t.Fatal("expected non-empty output")
 	}
 }
+
+func TestSearchProviderRegistry_GetProviderBackends(t *testing.T) {
+	cfg := &config.Config{
+		WebSearch: &config.WebSearchConfig{
+			Providers: map[string]config.WebSearchProvider{
+				"tavily":  {Type: "tavily"},
+				"brave":   {Type: "Brave", BaseURL: "http://brave.local/search"},
+				"searx":   {Type: "searxng"},
+				"proxy":   {BaseURL: "http://proxy.local"},
+				"unknown": {Type: "bing", BaseURL: "http://bing.local"},
+			},
+		},
+	}
+	reg := NewSearchProviderRegistry(cfg)
+
+	tests := []struct {
+		name        string
+		wantType    string
+		wantBaseURL string
+		wantErr     bool
+	}{
+		{"tavily", SearchBackendTavily, "https://api.tavily.com/search", false},
+		{"brave", SearchBackendBrave, "http://brave.local/search", false},
+		{"proxy", SearchBackendGeneric, "http://proxy.local", false},
+		{"searx", "", "", true},
+		{"unknown", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := reg.GetProvider(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetProvider(%q) = %+v, want error", tt.name, p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Type != tt.wantType || p.BaseURL != tt.wantBaseURL {
+				t.Errorf("GetProvider(%q) = %+v, want type %q baseUrl %q", tt.name, p, tt.wantType, tt.wantBaseURL)
+			}
+		})
+	}
+}
+
+func TestWebSearchTool_Run_Backends(t *testing.T) {
+	tests := []struct {
+		backend string
+		check   func(t *testing.T, r *http.Request)
+		body    string
+	}{
+		{
+			backend: SearchBackendTavily,
+			check: func(t *testing.T, r *http.Request) {
+				var req map[string]any
+				json.NewDecoder(r.Body).Decode(&req)
+				if r.Method != http.MethodPost || req["query"] != "go generics" || r.Header.Get("Authorization") != "Bearer k" {
+					t.Errorf("tavily request = %s %v auth %q", r.Method, req, r.Header.Get("Authorization"))
+				}
+			},
+			body: `{"results": [
+				{"title": "Generics tutorial", "url": "https://go.dev/doc/tutorial/generics", "content": "Learn generics", "score": 0.9},
+				{"title": "Spec", "url": "https://go.dev/ref/spec", "content": "Type parameters", "score": 0.7},
+				{"title": "Blog", "url": "https://go.dev/blog/intro-generics", "content": "Intro", "score": 0.5}]}`,
+		},
+		{
+			backend: SearchBackendBrave,
+			check: func(t *testing.T, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Query().Get("q") != "go generics" || r.URL.Query().Get("count") != "2" || r.Header.Get("X-Subscription-Token") != "k" {
+					t.Errorf("brave request = %s %s token %q", r.Method, r.URL, r.Header.Get("X-Subscription-Token"))
+				}
+			},
+			body: `{"web": {"results": [
+				{"title": "Generics tutorial", "url": "https://go.dev/doc/tutorial/generics", "description": "Learn <strong>generics</strong>"},
+				{"title": "Spec", "url": "https://go.dev/ref/spec", "description": "Type parameters"}]}}`,
+		},
+		{
+			backend: SearchBackendSearxNG,
+			check: func(t *testing.T, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" || r.URL.Query().Get("q") != "go generics" {
+					t.Errorf("searxng request = %s %s", r.Method, r.URL)
+				}
+			},
+			body: `{"results": [
+				{"title": "Generics tutorial", "url": "https://go.dev/doc/tutorial/generics", "content": "Learn generics", "score": 3.5},
+				{"title": "Spec", "url": "https://go.dev/ref/spec", "content": "Type parameters", "score": 2},
+				{"title": "Blog", "url": "https://go.dev/blog/intro-generics", "content": "Intro", "score": 1}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			reg := NewSearchProviderRegistry(&config.Config{
+				WebSearch: &config.WebSearchConfig{
+					Providers: map[string]config.WebSearchProvider{
+						"web": {Type: tt.backend, BaseURL: server.URL, APIKey: "k"},
+					},
+				},
+			})
+			mockAgents := mock_agent.NewMockRegistry(ctrl)
+			mockAgents.EXPECT().EvaluatePermission(gomock.Any(), WebSearchToolName, "go generics").Return(permission.ActionAllow)
+			tool := NewWebSearchTool(mockAgents, reg, permMocks.NewMockService(ctrl))
+
+			input, _ := json.Marshal(WebSearchParams{Query: "go generics", Provider: "web", MaxResults: 2})
+			resp, err := tool.Run(newTestCtx(), ToolCall{ID: "1", Name: WebSearchToolName, Input: string(input)})
+			if err != nil || resp.IsError {
+				t.Fatalf("Run() = %v, %s", err, resp.Content)
+			}
+			var meta WebSearchResponseMetadata
+			if err := json.Unmarshal([]byte(resp.Metadata), &meta); err != nil {
+				t.Fatalf("metadata %q: %v", resp.Metadata, err)
+			}
+			if meta.Provider != "web" || meta.Query != "go generics" || len(meta.Results) != 2 {
+				t.Fatalf("metadata = %+v, want 2 results for the query", meta)
+			}
+			first := meta.Results[0]
+			if first.Rank != 1 || first.URL != "https://go.dev/doc/tutorial/generics" || first.Snippet != "Learn generics" {
+				t.Errorf("first result = %+v", first)
+			}
+			if meta.Results[1].Rank != 2 {
+				t.Errorf("second result rank = %d, want 2", meta.Results[1].Rank)
+			}
+		})
+	}
+}
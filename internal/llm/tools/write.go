@@ -21,6 +21,7 @@ import (
 type WriteParams struct {
 	FilePath string `json:"file_path"`
 	Content  string `json:"content"`
+	DryRun   bool   `json:"dry_run,omitempty"`
 }
 
 type WritePermissionsParams struct {
@@ -39,6 +40,7 @@ type WriteResponseMetadata struct {
 	Diff      string `json:"diff"`
 	Additions int    `json:"additions"`
 	Removals  int    `json:"removals"`
+	DryRun    bool   `json:"dry_run,omitempty"`
 }
 
 const (
@@ -60,6 +62,7 @@ FEATURES:
 - Creates parent directories automatically if they don't exist
 - Checks if the file has been modified since last read for safety
 - Avoids unnecessary writes when content hasn't changed
+- dry_run returns the diff without writing the file
 
 LIMITATIONS:
 - You should read a file before writing to it to avoid conflicts
@@ -98,6 +101,10 @@ func (w *writeTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The content to write to the file",
 			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": dryRunParamDescription,
+			},
 		},
 		Required: []string{"file_path", "content"},
 	}
@@ -121,6 +128,9 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	if !filepath.IsAbs(filePath) {
 		filePath = filepath.Join(config.WorkingDirectory(), filePath)
 	}
+	if err := checkSandbox(filePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
 	fileInfo, err := os.Stat(filePath)
 	if err == nil {
@@ -143,11 +153,6 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return NewEmptyResponse(), fmt.Errorf("error checking file: %w", err)
 	}
 
-	dir := filepath.Dir(filePath)
-	if err = os.MkdirAll(dir, 0o755); err != nil {
-		return NewEmptyResponse(), fmt.Errorf("error creating directory: %w", err)
-	}
-
 	oldContent := ""
 	if fileInfo != nil && !fileInfo.IsDir() {
 		oldBytes, readErr := os.ReadFile(filePath)
@@ -172,6 +177,11 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
+	if IsDryRun(ctx, params.DryRun) {
+		return dryRunResponse(ctx, w.registry, WriteToolName, filePath,
+			fmt.Sprintf("Would write file %s (+%d -%d).", filePath, additions, removals), diff,
+			WriteResponseMetadata{Diff: diff, Additions: additions, Removals: removals, DryRun: true})
+	}
 	action := w.registry.EvaluatePermission(string(GetAgentID(ctx)), WriteToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
@@ -183,6 +193,7 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        permissionPath,
+				Pattern:     filePath,
 				ToolName:    WriteToolName,
 				Action:      "write",
 				Description: fmt.Sprintf("Create file %s", filePath),
@@ -197,6 +208,14 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 	}
 
+	if err := chargeDiffBudget(ctx, w.permissions, WriteToolName, []string{filePath}, additions+removals); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error creating directory: %w", err)
+	}
+	checkpointFile(ctx, w.files, filePath)
 	err = os.WriteFile(filePath, []byte(params.Content), 0o644)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error writing file: %w", err)
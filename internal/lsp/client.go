@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -57,6 +58,27 @@ type Client struct {
 	serverState atomic.Value
 }
 
+// ErrDetached is returned by requests to a client without a server
+// process.
+var ErrDetached = errors.New("lsp client has no server")
+
+// NewDetachedClient returns a client with no server process behind it. It
+// only holds diagnostics, published with HandleDiagnostics, and fails
+// every request with ErrDetached. Test doubles such as lsptest.Service
+// use it to feed diagnostics to code that reads them from clients.
+func NewDetachedClient(extensions ...string) *Client {
+	client := &Client{
+		handlers:              make(map[int32]chan *Message),
+		notificationHandlers:  make(map[string]NotificationHandler),
+		serverRequestHandlers: make(map[string]ServerRequestHandler),
+		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		openFiles:             make(map[string]*OpenFileInfo),
+		extensions:            extensions,
+	}
+	client.serverState.Store(StateReady)
+	return client
+}
+
 func NewClient(ctx context.Context, command string, env map[string]string, args ...string) (*Client, error) {
 	cmd := exec.CommandContext(ctx, command, args...)
 	// Copy env and add server-specific env vars
@@ -235,6 +257,9 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string, i
 }
 
 func (c *Client) Close() error {
+	if c.stdin == nil {
+		return nil
+	}
 	// Try to close all open files first
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -23,56 +23,6 @@ func FormatDiagnostics(filePath string, clients map[string]*Client) string {
 	fileDiagnostics := []string{}
 	projectDiagnostics := []string{}
 
-	formatDiagnostic := func(pth string, diagnostic protocol.Diagnostic, source string) string {
-		severity := "Info"
-		switch diagnostic.Severity {
-		case protocol.SeverityError:
-			severity = "Error"
-		case protocol.SeverityWarning:
-			severity = "Warn"
-		case protocol.SeverityHint:
-			severity = "Hint"
-		}
-
-		location := fmt.Sprintf("%s:%d:%d", pth, diagnostic.Range.Start.Line+1, diagnostic.Range.Start.Character+1)
-
-		sourceInfo := ""
-		if diagnostic.Source != "" {
-			sourceInfo = diagnostic.Source
-		} else if source != "" {
-			sourceInfo = source
-		}
-
-		codeInfo := ""
-		if diagnostic.Code != nil {
-			codeInfo = fmt.Sprintf("[%v]", diagnostic.Code)
-		}
-
-		tagsInfo := ""
-		if len(diagnostic.Tags) > 0 {
-			tags := []string{}
-			for _, tag := range diagnostic.Tags {
-				switch tag {
-				case protocol.Unnecessary:
-					tags = append(tags, "unnecessary")
-				case protocol.Deprecated:
-					tags = append(tags, "deprecated")
-				}
-			}
-			if len(tags) > 0 {
-				tagsInfo = fmt.Sprintf(" (%s)", strings.Join(tags, ", "))
-			}
-		}
-
-		return fmt.Sprintf("%s: %s [%s]%s%s %s",
-			severity,
-			location,
-			sourceInfo,
-			codeInfo,
-			tagsInfo,
-			diagnostic.Message)
-	}
-
 	for lspName, client := range clients {
 		diagnostics := client.GetDiagnostics()
 		if len(diagnostics) > 0 {
@@ -95,23 +45,8 @@ func FormatDiagnostics(filePath string, clients map[string]*Client) string {
 		}
 	}
 
-	sort.Slice(fileDiagnostics, func(i, j int) bool {
-		iIsError := strings.HasPrefix(fileDiagnostics[i], "Error")
-		jIsError := strings.HasPrefix(fileDiagnostics[j], "Error")
-		if iIsError != jIsError {
-			return iIsError
-		}
-		return fileDiagnostics[i] < fileDiagnostics[j]
-	})
-
-	sort.Slice(projectDiagnostics, func(i, j int) bool {
-		iIsError := strings.HasPrefix(projectDiagnostics[i], "Error")
-		jIsError := strings.HasPrefix(projectDiagnostics[j], "Error")
-		if iIsError != jIsError {
-			return iIsError
-		}
-		return projectDiagnostics[i] < projectDiagnostics[j]
-	})
+	sortDiagnostics(fileDiagnostics)
+	sortDiagnostics(projectDiagnostics)
 
 	output := ""
 
@@ -154,6 +89,96 @@ func FormatDiagnostics(filePath string, clients map[string]*Client) string {
 	return output
 }
 
+func formatDiagnostic(pth string, diagnostic protocol.Diagnostic, source string) string {
+	severity := "Info"
+	switch diagnostic.Severity {
+	case protocol.SeverityError:
+		severity = "Error"
+	case protocol.SeverityWarning:
+		severity = "Warn"
+	case protocol.SeverityHint:
+		severity = "Hint"
+	}
+
+	location := fmt.Sprintf("%s:%d:%d", pth, diagnostic.Range.Start.Line+1, diagnostic.Range.Start.Character+1)
+
+	sourceInfo := ""
+	if diagnostic.Source != "" {
+		sourceInfo = diagnostic.Source
+	} else if source != "" {
+		sourceInfo = source
+	}
+
+	codeInfo := ""
+	if diagnostic.Code != nil {
+		codeInfo = fmt.Sprintf("[%v]", diagnostic.Code)
+	}
+
+	tagsInfo := ""
+	if len(diagnostic.Tags) > 0 {
+		tags := []string{}
+		for _, tag := range diagnostic.Tags {
+			switch tag {
+			case protocol.Unnecessary:
+				tags = append(tags, "unnecessary")
+			case protocol.Deprecated:
+				tags = append(tags, "deprecated")
+			}
+		}
+		if len(tags) > 0 {
+			tagsInfo = fmt.Sprintf(" (%s)", strings.Join(tags, ", "))
+		}
+	}
+
+	return fmt.Sprintf("%s: %s [%s]%s%s %s",
+		severity,
+		location,
+		sourceInfo,
+		codeInfo,
+		tagsInfo,
+		diagnostic.Message)
+}
+
+// sortDiagnostics orders formatted diagnostics errors first, then by text.
+func sortDiagnostics(diagnostics []string) {
+	sort.Slice(diagnostics, func(i, j int) bool {
+		iIsError := strings.HasPrefix(diagnostics[i], "Error")
+		jIsError := strings.HasPrefix(diagnostics[j], "Error")
+		if iIsError != jIsError {
+			return iIsError
+		}
+		return diagnostics[i] < diagnostics[j]
+	})
+}
+
+// CollectDiagnostics returns the formatted errors of filePath, or of every
+// file the clients report on when filePath is empty. Warnings are included
+// when includeWarnings is set. Duplicates reported by several servers are
+// dropped.
+func CollectDiagnostics(filePath string, clients map[string]*Client, includeWarnings bool) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for lspName, client := range clients {
+		for location, diags := range client.GetDiagnostics() {
+			if filePath != "" && location.Path() != filePath {
+				continue
+			}
+			for _, diag := range diags {
+				if diag.Severity != protocol.SeverityError && (!includeWarnings || diag.Severity != protocol.SeverityWarning) {
+					continue
+				}
+				formatted := formatDiagnostic(location.Path(), diag, lspName)
+				if !seen[formatted] {
+					seen[formatted] = true
+					out = append(out, formatted)
+				}
+			}
+		}
+	}
+	sortDiagnostics(out)
+	return out
+}
+
 func CountSeverity(diagnostics []string, severity string) int {
 	count := 0
 	for _, diag := range diagnostics {
@@ -0,0 +1,193 @@
+// Package lsptest provides an in-memory lsp.LspService for tests. Its
+// servers are detached clients whose diagnostics are scripted per file, so
+// tools that wait for and format diagnostics can be tested without
+// starting a language server.
+package lsptest
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// Service is an lsp.LspService backed by detached clients. Diagnostics are
+// published onto a file when a tool waits for them: each
+// WaitForDiagnostics call takes the next round scripted for the file, and
+// the last round repeats once the script runs out. The zero value is not
+// usable; create one with New.
+type Service struct {
+	*pubsub.Broker[lsp.LSPServerEvent]
+
+	mu      sync.Mutex
+	clients map[string]*lsp.Client
+	scripts map[string]map[string][][]protocol.Diagnostic
+	opened  []string
+	waits   map[string]int
+}
+
+var _ lsp.LspService = (*Service)(nil)
+
+// New returns a service with no servers.
+func New() *Service {
+	return &Service{
+		Broker:  pubsub.NewBroker[lsp.LSPServerEvent](),
+		clients: make(map[string]*lsp.Client),
+		scripts: make(map[string]map[string][][]protocol.Diagnostic),
+		waits:   make(map[string]int),
+	}
+}
+
+// AddServer registers a server handling files with the given extensions,
+// such as ".go", and publishes its ready event.
+func (s *Service) AddServer(name string, extensions ...string) *lsp.Client {
+	client := lsp.NewDetachedClient(extensions...)
+	s.mu.Lock()
+	s.clients[name] = client
+	s.mu.Unlock()
+	s.Publish(pubsub.CreatedEvent, lsp.LSPServerEvent{Type: lsp.LSPServerReady, ServerName: name})
+	return client
+}
+
+// SetDiagnostics publishes diagnostics for path right away and makes them
+// the only round scripted for it, replacing any earlier script.
+func (s *Service) SetDiagnostics(server, path string, diags ...protocol.Diagnostic) {
+	s.Script(server, path, diags)
+	s.publish(server, path, diags)
+}
+
+// Script sets the rounds of diagnostics server reports for path, one per
+// WaitForDiagnostics call. An empty round clears the file's diagnostics.
+// The rounds are copied, so callers may reuse their slices.
+func (s *Service) Script(server, path string, rounds ...[]protocol.Diagnostic) {
+	copied := make([][]protocol.Diagnostic, len(rounds))
+	for i, round := range rounds {
+		copied[i] = slices.Clone(round)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scripts[server] == nil {
+		s.scripts[server] = make(map[string][][]protocol.Diagnostic)
+	}
+	s.scripts[server][path] = copied
+	s.waits[path] = 0
+}
+
+// Opened returns the files announced with NotifyOpenFile, in order.
+func (s *Service) Opened() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.opened)
+}
+
+// Waits returns how many times WaitForDiagnostics ran for path.
+func (s *Service) Waits(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waits[path]
+}
+
+func (s *Service) Init(context.Context)     {}
+func (s *Service) Shutdown(context.Context) {}
+func (s *Service) ForceShutdown()           {}
+
+func (s *Service) Clients() map[string]*lsp.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make(map[string]*lsp.Client, len(s.clients))
+	for name, client := range s.clients {
+		clients[name] = client
+	}
+	return clients
+}
+
+func (s *Service) ClientsForFile(filePath string) []*lsp.Client {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	var matched []*lsp.Client
+	for _, client := range s.Clients() {
+		if slices.Contains(client.GetExtensions(), ext) {
+			matched = append(matched, client)
+		}
+	}
+	return matched
+}
+
+func (s *Service) WaitClientsForFile(_ context.Context, filePath string) ([]*lsp.Client, error) {
+	return s.ClientsForFile(filePath), nil
+}
+
+func (s *Service) NotifyOpenFile(_ context.Context, filePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opened = append(s.opened, filePath)
+}
+
+// WaitForDiagnostics publishes the next scripted round for filePath from
+// every server that has a script for it. It never blocks.
+func (s *Service) WaitForDiagnostics(_ context.Context, filePath string) {
+	s.mu.Lock()
+	round := s.waits[filePath]
+	s.waits[filePath]++
+	next := make(map[string][]protocol.Diagnostic)
+	for server, files := range s.scripts {
+		rounds, ok := files[filePath]
+		if !ok || len(rounds) == 0 {
+			continue
+		}
+		next[server] = rounds[min(round, len(rounds)-1)]
+	}
+	s.mu.Unlock()
+
+	for server, diags := range next {
+		s.publish(server, filePath, diags)
+	}
+}
+
+func (s *Service) FormatDiagnostics(filePath string) string {
+	return lsp.FormatDiagnostics(filePath, s.Clients())
+}
+
+// publish hands diags to server's client the way a language server's
+// publishDiagnostics notification would.
+func (s *Service) publish(server, path string, diags []protocol.Diagnostic) {
+	s.mu.Lock()
+	client, ok := s.clients[server]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	params, err := json.Marshal(protocol.PublishDiagnosticsParams{
+		URI:         protocol.URIFromPath(path),
+		Diagnostics: diags,
+	})
+	if err != nil {
+		return
+	}
+	lsp.HandleDiagnostics(client, params)
+}
+
+// Error returns an error diagnostic on the 1-based line.
+func Error(line int, message string) protocol.Diagnostic {
+	return diagnostic(protocol.SeverityError, line, message)
+}
+
+// Warning returns a warning diagnostic on the 1-based line.
+func Warning(line int, message string) protocol.Diagnostic {
+	return diagnostic(protocol.SeverityWarning, line, message)
+}
+
+func diagnostic(severity protocol.DiagnosticSeverity, line int, message string) protocol.Diagnostic {
+	pos := protocol.Position{Line: uint32(max(line-1, 0))}
+	return protocol.Diagnostic{
+		Range:    protocol.Range{Start: pos, End: pos},
+		Severity: severity,
+		Source:   "lsptest",
+		Message:  message,
+	}
+}
@@ -0,0 +1,55 @@
+package lsptest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceScriptsDiagnosticRounds(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := New()
+	svc.AddServer("gopls", ".go")
+
+	round := []protocol.Diagnostic{Error(3, "undefined: foo")}
+	svc.Script("gopls", "/src/main.go", round, nil)
+	round[0].Message = "changed after scripting"
+
+	assert.Empty(t, svc.FormatDiagnostics("/src/main.go"), "nothing is published before a wait")
+
+	svc.WaitForDiagnostics(ctx, "/src/main.go")
+	out := svc.FormatDiagnostics("/src/main.go")
+	assert.Contains(t, out, "<file_diagnostics>")
+	assert.Contains(t, out, "undefined: foo")
+	assert.NotContains(t, out, "changed after scripting")
+
+	svc.WaitForDiagnostics(ctx, "/src/main.go")
+	svc.WaitForDiagnostics(ctx, "/src/main.go")
+	assert.Empty(t, svc.FormatDiagnostics("/src/main.go"), "the last round repeats")
+	assert.Equal(t, 3, svc.Waits("/src/main.go"))
+}
+
+func TestServiceClientsAndOpenedFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := New()
+	client := svc.AddServer("gopls", ".go")
+	svc.SetDiagnostics("gopls", "/src/other.go", Warning(1, "unused variable"))
+
+	clients, err := svc.WaitClientsForFile(ctx, "/src/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, []*lsp.Client{client}, clients)
+	assert.Empty(t, svc.ClientsForFile("/src/main.py"))
+
+	svc.NotifyOpenFile(ctx, "/src/main.go")
+	assert.Equal(t, []string{"/src/main.go"}, svc.Opened())
+	assert.Contains(t, svc.FormatDiagnostics("/src/main.go"), "<project_diagnostics>")
+
+	assert.ErrorIs(t, client.Notify(ctx, "textDocument/didSave", nil), lsp.ErrDetached)
+	assert.NoError(t, client.Close())
+}
@@ -56,20 +56,6 @@ func (mr *MockLspServiceMockRecorder) Clients() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clients", reflect.TypeOf((*MockLspService)(nil).Clients))
 }
 
-// ClientsCh mocks base method.
-func (m *MockLspService) ClientsCh() <-chan *lsp.Client {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClientsCh")
-	ret0, _ := ret[0].(<-chan *lsp.Client)
-	return ret0
-}
-
-// ClientsCh indicates an expected call of ClientsCh.
-func (mr *MockLspServiceMockRecorder) ClientsCh() *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientsCh", reflect.TypeOf((*MockLspService)(nil).ClientsCh))
-}
-
 // ClientsForFile mocks base method.
 func (m *MockLspService) ClientsForFile(filePath string) []*lsp.Client {
 	m.ctrl.T.Helper()
@@ -160,6 +146,21 @@ func (mr *MockLspServiceMockRecorder) Subscribe(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockLspService)(nil).Subscribe), arg0)
 }
 
+// WaitClientsForFile mocks base method.
+func (m *MockLspService) WaitClientsForFile(ctx context.Context, filePath string) ([]*lsp.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitClientsForFile", ctx, filePath)
+	ret0, _ := ret[0].([]*lsp.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitClientsForFile indicates an expected call of WaitClientsForFile.
+func (mr *MockLspServiceMockRecorder) WaitClientsForFile(ctx, filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitClientsForFile", reflect.TypeOf((*MockLspService)(nil).WaitClientsForFile), ctx, filePath)
+}
+
 // WaitForDiagnostics mocks base method.
 func (m *MockLspService) WaitForDiagnostics(ctx context.Context, filePath string) {
 	m.ctrl.T.Helper()
@@ -2,10 +2,15 @@ package lsp
 
 import (
 	"context"
+	"errors"
 
 	"github.com/opencode-ai/opencode/internal/pubsub"
 )
 
+// ErrServerStarting is returned by WaitClientsForFile when a server for the
+// file is still starting once the wait times out.
+var ErrServerStarting = errors.New("LSP server for this file type is still starting")
+
 type LSPServerEventType string
 
 const (
@@ -23,9 +28,16 @@ type LspService interface {
 	Shutdown(ctx context.Context)
 	ForceShutdown()
 
+	// Clients returns a snapshot of the running clients; it is safe to call
+	// while servers start, restart or shut down.
 	Clients() map[string]*Client
-	ClientsCh() <-chan *Client
 	ClientsForFile(filePath string) []*Client
+	// WaitClientsForFile is ClientsForFile for the first use of a file
+	// type: while a server handling its extension is still starting, it
+	// blocks until that server is up or gave up, ctx is done, or a bounded
+	// wait runs out. It returns no clients and no error when no configured
+	// server handles the file.
+	WaitClientsForFile(ctx context.Context, filePath string) ([]*Client, error)
 
 	NotifyOpenFile(ctx context.Context, filePath string)
 	WaitForDiagnostics(ctx context.Context, filePath string)
@@ -216,6 +216,9 @@ func (c *Client) handleMessages() {
 
 // Call makes a request and waits for the response
 func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	if c.stdin == nil {
+		return ErrDetached
+	}
 	cnf := config.Get()
 	id := c.nextID.Add(1)
 
@@ -284,6 +287,9 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 
 // Notify sends a notification (a request without an ID that doesn't expect a response)
 func (c *Client) Notify(ctx context.Context, method string, params any) error {
+	if c.stdin == nil {
+		return ErrDetached
+	}
 	cnf := config.Get()
 	if cnf.DebugLSP {
 		logging.Debug("Sending notification", "method", method)
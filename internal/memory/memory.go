@@ -0,0 +1,272 @@
+// Package memory keeps facts about a project across sessions: build
+// conventions, decisions, preferences the user stated. Agents write them
+// with memory_write and read them with memory_read; high-priority memories
+// are also put into every agent's system prompt.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+// Priority decides whether a memory is put into the system prompt.
+type Priority string
+
+const (
+	// PriorityHigh memories are put into every agent's system prompt.
+	PriorityHigh Priority = "high"
+	// PriorityNormal memories are only seen through memory_read.
+	PriorityNormal Priority = "normal"
+)
+
+// MaxContentLength caps one memory; memories are meant to be short facts,
+// not documents.
+const MaxContentLength = 2000
+
+var (
+	ErrNotFound     = errors.New("memory not found")
+	ErrEmptyMemory  = errors.New("memory content is empty")
+	ErrTooLong      = fmt.Errorf("memory content is longer than %d characters", MaxContentLength)
+	ErrBadPriority  = errors.New(`priority must be "high" or "normal"`)
+	ErrMissingQuery = errors.New("a key or ID is required")
+)
+
+// Memory is one remembered fact. Key is empty for free-text notes.
+type Memory struct {
+	ID        string   `json:"id"`
+	Key       string   `json:"key,omitempty"`
+	Content   string   `json:"content"`
+	Priority  Priority `json:"priority"`
+	CreatedAt int64    `json:"createdAt"`
+	UpdatedAt int64    `json:"updatedAt"`
+}
+
+// Query filters memories. The zero value returns every memory.
+type Query struct {
+	// Key matches the memory with exactly this key, ignoring case.
+	Key string
+	// Contains matches memories whose key or content contains the text,
+	// ignoring case.
+	Contains string
+	Priority Priority
+	// Limit keeps only the most recently updated matches when positive.
+	Limit int
+}
+
+func (q Query) matches(m Memory) bool {
+	if q.Key != "" && !strings.EqualFold(m.Key, q.Key) {
+		return false
+	}
+	if q.Priority != "" && m.Priority != q.Priority {
+		return false
+	}
+	if q.Contains != "" {
+		needle := strings.ToLower(q.Contains)
+		if !strings.Contains(strings.ToLower(m.Key), needle) &&
+			!strings.Contains(strings.ToLower(m.Content), needle) {
+			return false
+		}
+	}
+	return true
+}
+
+type Service interface {
+	// Write stores a memory. A memory with the same key is replaced;
+	// without a key a new note is added.
+	Write(ctx context.Context, key, content string, priority Priority) (Memory, error)
+	// List returns the project's memories matching q, most recently
+	// updated first.
+	List(ctx context.Context, q Query) ([]Memory, error)
+	// Delete removes the memory with the given key or ID.
+	Delete(ctx context.Context, keyOrID string) (Memory, error)
+}
+
+type service struct {
+	q         db.Querier
+	projectID string
+}
+
+// NewService returns the memories of projectID.
+func NewService(q db.Querier, projectID string) Service {
+	return &service{q: q, projectID: projectID}
+}
+
+func (s *service) Write(ctx context.Context, key, content string, priority Priority) (Memory, error) {
+	key = strings.TrimSpace(key)
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return Memory{}, ErrEmptyMemory
+	}
+	if len(content) > MaxContentLength {
+		return Memory{}, ErrTooLong
+	}
+	if priority == "" {
+		priority = PriorityNormal
+	}
+	if priority != PriorityHigh && priority != PriorityNormal {
+		return Memory{}, ErrBadPriority
+	}
+
+	now := time.Now().Unix()
+	if key != "" {
+		existing, err := s.byKey(ctx, key)
+		if err == nil {
+			err = s.q.UpdateMemory(ctx, db.UpdateMemoryParams{
+				ID:        existing.ID,
+				Content:   content,
+				Priority:  string(priority),
+				UpdatedAt: now,
+			})
+			if err != nil {
+				return Memory{}, fmt.Errorf("failed to update memory: %w", err)
+			}
+			existing.Content, existing.Priority, existing.UpdatedAt = content, priority, now
+			changed()
+			return existing, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return Memory{}, err
+		}
+	}
+
+	m := Memory{
+		ID:        uuid.New().String(),
+		Key:       key,
+		Content:   content,
+		Priority:  priority,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := s.q.CreateMemory(ctx, db.CreateMemoryParams{
+		ID:        m.ID,
+		ProjectID: s.projectID,
+		Name:      m.Key,
+		Content:   m.Content,
+		Priority:  string(m.Priority),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	})
+	if err != nil {
+		return Memory{}, fmt.Errorf("failed to store memory: %w", err)
+	}
+	changed()
+	return m, nil
+}
+
+func (s *service) List(ctx context.Context, q Query) ([]Memory, error) {
+	rows, err := s.q.ListMemories(ctx, s.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	var memories []Memory
+	for _, r := range rows {
+		m := fromDB(r)
+		if !q.matches(m) {
+			continue
+		}
+		memories = append(memories, m)
+		if q.Limit > 0 && len(memories) == q.Limit {
+			break
+		}
+	}
+	return memories, nil
+}
+
+func (s *service) Delete(ctx context.Context, keyOrID string) (Memory, error) {
+	keyOrID = strings.TrimSpace(keyOrID)
+	if keyOrID == "" {
+		return Memory{}, ErrMissingQuery
+	}
+	m, err := s.byKey(ctx, keyOrID)
+	if errors.Is(err, ErrNotFound) {
+		var row db.Memory
+		row, err = s.q.GetMemory(ctx, db.GetMemoryParams{ProjectID: s.projectID, ID: keyOrID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return Memory{}, ErrNotFound
+		}
+		m = fromDB(row)
+	}
+	if err != nil {
+		return Memory{}, err
+	}
+	if err := s.q.DeleteMemory(ctx, db.DeleteMemoryParams{ProjectID: s.projectID, ID: m.ID}); err != nil {
+		return Memory{}, fmt.Errorf("failed to delete memory: %w", err)
+	}
+	changed()
+	return m, nil
+}
+
+// byKey finds a memory by key. Keys are compared ignoring case, so the
+// lookup lists the project's memories rather than relying on the
+// database collation.
+func (s *service) byKey(ctx context.Context, key string) (Memory, error) {
+	row, err := s.q.GetMemoryByName(ctx, db.GetMemoryByNameParams{ProjectID: s.projectID, Name: key})
+	if err == nil {
+		return fromDB(row), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Memory{}, fmt.Errorf("failed to look up memory: %w", err)
+	}
+	memories, err := s.List(ctx, Query{Key: key, Limit: 1})
+	if err != nil {
+		return Memory{}, err
+	}
+	if len(memories) == 0 {
+		return Memory{}, ErrNotFound
+	}
+	return memories[0], nil
+}
+
+func fromDB(r db.Memory) Memory {
+	return Memory{
+		ID:        r.ID,
+		Key:       r.Name,
+		Content:   r.Content,
+		Priority:  Priority(r.Priority),
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// The default service is the one the prompt builder reads high-priority
+// memories from; app.New installs it.
+var (
+	defaultMu      sync.RWMutex
+	defaultService Service
+	version        atomic.Int64
+)
+
+// SetDefault installs the service whose memories go into system prompts.
+func SetDefault(s Service) {
+	defaultMu.Lock()
+	defaultService = s
+	defaultMu.Unlock()
+	changed()
+}
+
+// Default returns the installed service, or nil.
+func Default() Service {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultService
+}
+
+// Version changes whenever a memory is written or deleted, so agents can
+// tell that the memories in their system prompt are stale.
+func Version() int64 {
+	return version.Load()
+}
+
+func changed() {
+	version.Add(1)
+}
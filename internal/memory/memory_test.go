@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func TestWriteReplacesKeyAndAddsNotes(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(db.NewTestQuerier(t), "proj")
+
+	first, err := s.Write(ctx, "test-command", "go test ./...", "")
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if first.Priority != PriorityNormal {
+		t.Fatalf("priority = %q, want normal by default", first.Priority)
+	}
+	second, err := s.Write(ctx, "Test-Command", "make test", PriorityHigh)
+	if err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("rewriting a key created a new memory: %s != %s", second.ID, first.ID)
+	}
+	if _, err := s.Write(ctx, "", "the API is versioned by URL prefix", PriorityNormal); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+
+	all, err := s.List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %d memories, want 2: %+v", len(all), all)
+	}
+	got, err := s.List(ctx, Query{Key: "test-command"})
+	if err != nil || len(got) != 1 || got[0].Content != "make test" || got[0].Priority != PriorityHigh {
+		t.Fatalf("key lookup = %+v, %v", got, err)
+	}
+	got, _ = s.List(ctx, Query{Contains: "url PREFIX"})
+	if len(got) != 1 || got[0].Key != "" {
+		t.Fatalf("contains lookup = %+v", got)
+	}
+}
+
+func TestWriteValidates(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(db.NewTestQuerier(t), "proj")
+
+	if _, err := s.Write(ctx, "k", "  ", PriorityNormal); !errors.Is(err, ErrEmptyMemory) {
+		t.Fatalf("empty content: err = %v", err)
+	}
+	if _, err := s.Write(ctx, "k", strings.Repeat("x", MaxContentLength+1), PriorityNormal); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("long content: err = %v", err)
+	}
+	if _, err := s.Write(ctx, "k", "x", "urgent"); !errors.Is(err, ErrBadPriority) {
+		t.Fatalf("bad priority: err = %v", err)
+	}
+}
+
+func TestDeleteByKeyOrID(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(db.NewTestQuerier(t), "proj")
+
+	keyed, _ := s.Write(ctx, "style", "tabs", PriorityNormal)
+	note, _ := s.Write(ctx, "", "a note", PriorityNormal)
+
+	if _, err := s.Delete(ctx, "STYLE"); err != nil {
+		t.Fatalf("delete by key: %v", err)
+	}
+	if _, err := s.Delete(ctx, note.ID); err != nil {
+		t.Fatalf("delete by ID: %v", err)
+	}
+	if _, err := s.Delete(ctx, keyed.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second delete: err = %v, want ErrNotFound", err)
+	}
+	if all, _ := s.List(ctx, Query{}); len(all) != 0 {
+		t.Fatalf("memories left after delete: %+v", all)
+	}
+}
+
+func TestMemoriesAreScopedToProject(t *testing.T) {
+	ctx := context.Background()
+	q := db.NewTestQuerier(t)
+	a := NewService(q, "a")
+	b := NewService(q, "b")
+
+	if _, err := a.Write(ctx, "k", "from a", PriorityNormal); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, _ := b.List(ctx, Query{}); len(got) != 0 {
+		t.Fatalf("project b sees %+v", got)
+	}
+	if _, err := b.Delete(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("project b deleted a's memory: err = %v", err)
+	}
+}
+
+func TestPromptSectionOnlyHighPriority(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(db.NewTestQuerier(t), "proj")
+	prev := Default()
+	SetDefault(s)
+	t.Cleanup(func() { SetDefault(prev) })
+
+	if got := PromptSection(ctx); got != "" {
+		t.Fatalf("empty store rendered %q", got)
+	}
+
+	before := Version()
+	_, _ = s.Write(ctx, "pkg-manager", "use pnpm,\nnever npm", PriorityHigh)
+	_, _ = s.Write(ctx, "", "normal fact", PriorityNormal)
+	if Version() == before {
+		t.Fatal("writes did not change Version")
+	}
+
+	got := PromptSection(ctx)
+	if !strings.HasPrefix(got, "<project_memory>") || !strings.HasSuffix(got, "</project_memory>") {
+		t.Fatalf("section not wrapped: %q", got)
+	}
+	if !strings.Contains(got, "- pkg-manager: use pnpm, never npm\n") {
+		t.Fatalf("high-priority memory missing: %q", got)
+	}
+	if strings.Contains(got, "normal fact") {
+		t.Fatalf("normal memory leaked into the prompt: %q", got)
+	}
+}
+
+func TestRenderCapsSize(t *testing.T) {
+	var memories []Memory
+	for range 20 {
+		memories = append(memories, Memory{Content: strings.Repeat("x", 500)})
+	}
+	got := Render(memories)
+	if len(got) > maxPromptBytes+500 {
+		t.Fatalf("rendered %d bytes", len(got))
+	}
+	if !strings.Contains(got, "older memories omitted") {
+		t.Fatalf("omission not reported: %q", got[len(got)-200:])
+	}
+}
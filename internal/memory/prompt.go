@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+const (
+	// maxPromptMemories and maxPromptBytes bound what high-priority
+	// memories add to every request; the rest stay reachable through
+	// memory_read.
+	maxPromptMemories = 50
+	maxPromptBytes    = 4000
+)
+
+// PromptSection renders the default service's high-priority memories for
+// the system prompt, or "" when there are none.
+func PromptSection(ctx context.Context) string {
+	s := Default()
+	if s == nil {
+		return ""
+	}
+	memories, err := s.List(ctx, Query{Priority: PriorityHigh, Limit: maxPromptMemories})
+	if err != nil {
+		logging.Warn("Failed to load project memories", "error", err)
+		return ""
+	}
+	return Render(memories)
+}
+
+// Render formats memories as the <project_memory> block of the system
+// prompt, dropping the oldest once maxPromptBytes is reached.
+func Render(memories []Memory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+	var body strings.Builder
+	omitted := 0
+	for i, m := range memories {
+		var line string
+		if m.Key != "" {
+			line = fmt.Sprintf("- %s: %s\n", m.Key, oneLine(m.Content))
+		} else {
+			line = fmt.Sprintf("- %s\n", oneLine(m.Content))
+		}
+		if body.Len()+len(line) > maxPromptBytes {
+			omitted = len(memories) - i
+			break
+		}
+		body.WriteString(line)
+	}
+	if body.Len() == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<project_memory>\n")
+	sb.WriteString("Facts remembered about this project from earlier sessions. Treat them as true unless the user or the code says otherwise; update or delete stale ones with memory_write.\n")
+	sb.WriteString(body.String())
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "(%d older memories omitted; use memory_read to see them)\n", omitted)
+	}
+	sb.WriteString("</project_memory>")
+	return sb.String()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -28,6 +28,9 @@ const (
 	FinishReasonCanceled         FinishReason = "canceled"
 	FinishReasonError            FinishReason = "error"
 	FinishReasonPermissionDenied FinishReason = "permission_denied"
+	// FinishReasonContentFilter is a response the provider's content
+	// filter blocked, in full or part way through.
+	FinishReasonContentFilter FinishReason = "content_filter"
 
 	// Should never happen
 	FinishReasonUnknown FinishReason = "unknown"
@@ -133,6 +136,46 @@ type Finish struct {
 
 func (Finish) isPart() {}
 
+// Citation links a byte range of the message text to the tool result that
+// backs it. Path and the line range are set when the evidence is a file
+// (read, grep); command output citations carry only the tool call.
+type Citation struct {
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	ToolCallID string `json:"tool_call_id"`
+	ToolName   string `json:"tool_name"`
+	Path       string `json:"path,omitempty"`
+	StartLine  int    `json:"start_line,omitempty"`
+	EndLine    int    `json:"end_line,omitempty"`
+}
+
+// Citations is the metadata part holding every citation of an assistant
+// message. It is never sent to providers.
+type Citations struct {
+	Items []Citation `json:"items"`
+}
+
+func (Citations) isPart() {}
+
+// Pin is the metadata part marking a message pinned: compaction keeps it
+// verbatim after the summary instead of folding it in. It is never sent to
+// providers.
+type Pin struct {
+	Time int64 `json:"time"`
+}
+
+func (Pin) isPart() {}
+
+// ImageContent is an image the model produced. The image is saved to the
+// data directory by SaveImage and the part only records where; it is
+// never sent back to providers.
+type ImageContent struct {
+	Path     string `json:"path"`
+	MIMEType string `json:"mime_type"`
+}
+
+func (ImageContent) isPart() {}
+
 type Message struct {
 	ID        string
 	Role      MessageRole
@@ -202,6 +245,17 @@ func (m *Message) BinaryContent() []BinaryContent {
 	return binaryContents
 }
 
+// Images returns the images the model produced in this message.
+func (m *Message) Images() []ImageContent {
+	var images []ImageContent
+	for _, part := range m.Parts {
+		if c, ok := part.(ImageContent); ok {
+			images = append(images, c)
+		}
+	}
+	return images
+}
+
 func (m *Message) ToolCalls() []ToolCall {
 	toolCalls := make([]ToolCall, 0)
 	for _, part := range m.Parts {
@@ -251,6 +305,56 @@ func (m *Message) StructOutput() (result *ToolResult, ok bool) {
 	return result, false
 }
 
+// Citations returns the citations recorded for the message text, if any.
+func (m *Message) Citations() []Citation {
+	for _, part := range m.Parts {
+		if c, ok := part.(Citations); ok {
+			return c.Items
+		}
+	}
+	return nil
+}
+
+// SetCitations replaces the message citations. An empty list removes the
+// part altogether.
+func (m *Message) SetCitations(items []Citation) {
+	for i, part := range m.Parts {
+		if _, ok := part.(Citations); ok {
+			m.Parts = slices.Delete(m.Parts, i, i+1)
+			break
+		}
+	}
+	if len(items) > 0 {
+		m.Parts = append(m.Parts, Citations{Items: items})
+	}
+}
+
+// IsPinned reports whether the message is pinned.
+func (m *Message) IsPinned() bool {
+	for _, part := range m.Parts {
+		if _, ok := part.(Pin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPinned pins or unpins the message.
+func (m *Message) SetPinned(pinned bool) {
+	for i, part := range m.Parts {
+		if _, ok := part.(Pin); ok {
+			if pinned {
+				return
+			}
+			m.Parts = slices.Delete(m.Parts, i, i+1)
+			return
+		}
+	}
+	if pinned {
+		m.Parts = append(m.Parts, Pin{Time: time.Now().Unix()})
+	}
+}
+
 func (m *Message) IsFinished() bool {
 	for _, part := range m.Parts {
 		if _, ok := part.(Finish); ok {
@@ -298,6 +402,17 @@ func (m *Message) AppendContent(delta string) {
 	}
 }
 
+// SetContent replaces the text of the message, keeping all other parts.
+func (m *Message) SetContent(text string) {
+	for i, part := range m.Parts {
+		if _, ok := part.(TextContent); ok {
+			m.Parts[i] = TextContent{Text: text}
+			return
+		}
+	}
+	m.Parts = append(m.Parts, TextContent{Text: text})
+}
+
 func (m *Message) AppendReasoningContent(delta string) {
 	found := false
 	for i, part := range m.Parts {
@@ -442,6 +557,10 @@ func (m *Message) AddImageURL(url, detail string) {
 	m.Parts = append(m.Parts, ImageURLContent{URL: url, Detail: detail})
 }
 
+func (m *Message) AddImage(image ImageContent) {
+	m.Parts = append(m.Parts, image)
+}
+
 func (m *Message) AddBinary(mimeType string, data []byte) {
 	m.Parts = append(m.Parts, BinaryContent{MIMEType: mimeType, Data: data})
 }
@@ -0,0 +1,46 @@
+package message
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// imageExts maps the image types providers return to a file extension;
+// mime.ExtensionsByType is the fallback for anything else.
+var imageExts = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// SaveImage writes an image the model produced to dir, creating it if
+// needed, and returns the part that refers to it. Images are kept out of
+// the messages table: a few of them would outgrow every other part of a
+// session.
+func SaveImage(dir, mimeType string, data []byte) (ImageContent, error) {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	mimeType = strings.TrimSpace(mimeType)
+	ext, ok := imageExts[mimeType]
+	if !ok {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return ImageContent{}, fmt.Errorf("creating image directory: %w", err)
+	}
+	path, err := filepath.Abs(filepath.Join(dir, uuid.New().String()+ext))
+	if err != nil {
+		return ImageContent{}, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return ImageContent{}, fmt.Errorf("saving image: %w", err)
+	}
+	return ImageContent{Path: path, MIMEType: mimeType}, nil
+}
@@ -0,0 +1,45 @@
+package message
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveImage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "images", "session-1")
+	data := []byte("\x89PNG\r\n\x1a\nfake")
+
+	img, err := SaveImage(dir, "image/png; charset=binary", data)
+	if err != nil {
+		t.Fatalf("SaveImage: %v", err)
+	}
+	if img.MIMEType != "image/png" || filepath.Ext(img.Path) != ".png" || filepath.Dir(img.Path) != dir {
+		t.Fatalf("unexpected part: %+v", img)
+	}
+	saved, err := os.ReadFile(img.Path)
+	if err != nil || !bytes.Equal(saved, data) {
+		t.Fatalf("image not saved verbatim: %v", err)
+	}
+}
+
+func TestImagePartMarshalRoundTrip(t *testing.T) {
+	parts := []ContentPart{
+		TextContent{Text: "here it is"},
+		ImageContent{Path: "/data/images/s/1.png", MIMEType: "image/png"},
+	}
+	data, err := marshallParts(parts)
+	if err != nil {
+		t.Fatalf("marshallParts: %v", err)
+	}
+	got, err := unmarshallParts(data)
+	if err != nil {
+		t.Fatalf("unmarshallParts: %v", err)
+	}
+	msg := Message{Parts: got}
+	images := msg.Images()
+	if len(images) != 1 || images[0] != parts[1] {
+		t.Fatalf("image part did not round-trip: %+v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -21,6 +22,10 @@ const partsBufferSize = 256
 
 const BytesPerTokenEta = 4
 
+// ErrMessageNotFound is returned when a message passed as a cursor or to
+// SupersedeFrom is not a visible message of the session.
+var ErrMessageNotFound = errors.New("message not found in session")
+
 type CreateMessageParams struct {
 	Role  MessageRole
 	Parts []ContentPart
@@ -42,8 +47,21 @@ type Service interface {
 	Get(ctx context.Context, id string) (Message, error)
 	List(ctx context.Context, sessionID string) ([]Message, error)
 	ListLatest(ctx context.Context, sessionID string, limit int64) ([]Message, error)
+	// ListBefore pages backwards through history: it returns up to limit
+	// messages preceding beforeID, oldest first. An empty beforeID pages
+	// from the end of the session, like ListLatest.
+	ListBefore(ctx context.Context, sessionID, beforeID string, limit int64) ([]Message, error)
+	// ListFrom returns fromID and every later message of the session. It is
+	// how the agent loads history from the summary without reading what the
+	// summary replaced.
+	ListFrom(ctx context.Context, sessionID, fromID string) ([]Message, error)
 	MaxSeq(ctx context.Context, sessionID string) (int64, error)
 	Delete(ctx context.Context, id string) error
+	// SupersedeFrom hides messageID and every later message of the session
+	// from List and ListLatest, in one transaction, and returns the hidden
+	// messages. The rows are kept so checkpoints recorded during the
+	// superseded turns can still be reverted.
+	SupersedeFrom(ctx context.Context, sessionID, messageID string) ([]Message, error)
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
 
 	// Per-part SSE event surface — independent of the whole-message broker.
@@ -109,6 +127,34 @@ func (s *service) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *service) SupersedeFrom(ctx context.Context, sessionID, messageID string) ([]Message, error) {
+	superseded, err := s.ListFrom(ctx, sessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.q.WithTx(tx)
+	for _, m := range superseded {
+		if err := qtx.SupersedeMessage(ctx, m.ID); err != nil {
+			return nil, fmt.Errorf("failed to supersede message %s: %w", m.ID, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, m := range superseded {
+		s.Publish(pubsub.DeletedEvent, m)
+	}
+	return superseded, nil
+}
+
 func (s *service) Create(ctx context.Context, sessionID string, params CreateMessageParams) (Message, error) {
 	if params.Role != Assistant {
 		params.Parts = append(params.Parts, Finish{
@@ -388,6 +434,64 @@ func (s *service) ListLatest(ctx context.Context, sessionID string, limit int64)
 	return messages, nil
 }
 
+func (s *service) ListBefore(ctx context.Context, sessionID, beforeID string, limit int64) ([]Message, error) {
+	if beforeID == "" {
+		return s.ListLatest(ctx, sessionID, limit)
+	}
+	cursor, err := s.cursor(ctx, sessionID, beforeID)
+	if err != nil {
+		return nil, err
+	}
+	dbMessages, err := s.q.ListMessagesBefore(ctx, db.ListMessagesBeforeParams{
+		SessionID:       sessionID,
+		BeforeSeq:       cursor.Seq.Int64,
+		BeforeCreatedAt: cursor.CreatedAt,
+		Limit:           limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.fromDBItems(dbMessages)
+}
+
+func (s *service) ListFrom(ctx context.Context, sessionID, fromID string) ([]Message, error) {
+	cursor, err := s.cursor(ctx, sessionID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	dbMessages, err := s.q.ListMessagesFrom(ctx, db.ListMessagesFromParams{
+		SessionID:     sessionID,
+		FromSeq:       cursor.Seq.Int64,
+		FromCreatedAt: cursor.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.fromDBItems(dbMessages)
+}
+
+// cursor loads the message a page starts or ends at, which must be a
+// visible message of the session.
+func (s *service) cursor(ctx context.Context, sessionID, messageID string) (db.Message, error) {
+	m, err := s.q.GetMessage(ctx, messageID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (m.SessionID != sessionID || m.SupersededAt.Valid)) {
+		return db.Message{}, ErrMessageNotFound
+	}
+	return m, err
+}
+
+func (s *service) fromDBItems(dbMessages []db.Message) ([]Message, error) {
+	messages := make([]Message, len(dbMessages))
+	for i, dbMessage := range dbMessages {
+		var err error
+		messages[i], err = s.fromDBItem(dbMessage)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
 func (s *service) MaxSeq(ctx context.Context, sessionID string) (int64, error) {
 	return s.q.GetMaxSeqBySession(ctx, sessionID)
 }
@@ -420,6 +524,9 @@ const (
 	toolCallType   partType = "tool_call"
 	toolResultType partType = "tool_result"
 	finishType     partType = "finish"
+	citationsType  partType = "citations"
+	pinType        partType = "pin"
+	imageType      partType = "image"
 )
 
 type partWrapper struct {
@@ -448,6 +555,12 @@ func marshallParts(parts []ContentPart) ([]byte, error) {
 			typ = toolResultType
 		case Finish:
 			typ = finishType
+		case Citations:
+			typ = citationsType
+		case Pin:
+			typ = pinType
+		case ImageContent:
+			typ = imageType
 		default:
 			return nil, fmt.Errorf("unknown part type: %T", part)
 		}
@@ -460,6 +573,13 @@ func marshallParts(parts []ContentPart) ([]byte, error) {
 	return json.Marshal(wrappedParts)
 }
 
+// MarshalParts encodes parts in the layout stored in the messages table,
+// for code that builds message rows without going through the service
+// (e.g. session importers producing an archive).
+func MarshalParts(parts []ContentPart) ([]byte, error) {
+	return marshallParts(parts)
+}
+
 func unmarshallParts(data []byte) ([]ContentPart, error) {
 	temp := []json.RawMessage{}
 
@@ -522,6 +642,24 @@ func unmarshallParts(data []byte) ([]ContentPart, error) {
 				return nil, err
 			}
 			parts = append(parts, part)
+		case citationsType:
+			part := Citations{}
+			if err := json.Unmarshal(wrapper.Data, &part); err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		case pinType:
+			part := Pin{}
+			if err := json.Unmarshal(wrapper.Data, &part); err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		case imageType:
+			part := ImageContent{}
+			if err := json.Unmarshal(wrapper.Data, &part); err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
 		default:
 			return nil, fmt.Errorf("unknown part type: %s", wrapper.Type)
 		}
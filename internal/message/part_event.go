@@ -40,6 +40,8 @@ func clonePart(p ContentPart) ContentPart {
 		return v
 	case ImageURLContent:
 		return v
+	case ImageContent:
+		return v
 	case Finish:
 		return v
 	default:
@@ -0,0 +1,123 @@
+package message
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+// newDBTestService opens a migrated SQLite database holding session s1 with
+// the messages ids, in order.
+func newDBTestService(t *testing.T, ids ...string) (*service, db.Querier) {
+	t.Helper()
+	ctx := context.Background()
+	sqlDB := db.OpenTestDB(t)
+	q := db.NewSQLiteQuerier(sqlDB)
+
+	if _, err := q.CreateSession(ctx, db.CreateSessionParams{ID: "s1", Title: "t"}); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	for i, id := range ids {
+		if _, err := q.CreateMessage(ctx, db.CreateMessageParams{
+			ID:        id,
+			SessionID: "s1",
+			Role:      "user",
+			Parts:     `[{"type":"text","data":{"text":"` + id + `"}}]`,
+			Seq:       sql.NullInt64{Int64: int64(i + 1), Valid: true},
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+	return NewService(q, sqlDB).(*service), q
+}
+
+func messageIDs(msgs []Message) []string {
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func TestListBefore(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newDBTestService(t, "m1", "m2", "m3", "m4", "m5")
+
+	tests := []struct {
+		before string
+		limit  int64
+		want   []string
+	}{
+		{"", 2, []string{"m4", "m5"}},
+		{"m4", 2, []string{"m2", "m3"}},
+		{"m2", 2, []string{"m1"}},
+		{"m1", 2, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := svc.ListBefore(ctx, "s1", tt.before, tt.limit)
+		if err != nil {
+			t.Fatalf("ListBefore(%q): %v", tt.before, err)
+		}
+		if ids := messageIDs(got); !slices.Equal(ids, tt.want) {
+			t.Errorf("ListBefore(%q, %d) = %v, want %v", tt.before, tt.limit, ids, tt.want)
+		}
+	}
+
+	if _, err := svc.ListBefore(ctx, "other", "m3", 2); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("cursor from another session: err = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestListFrom(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newDBTestService(t, "m1", "m2", "m3")
+
+	got, err := svc.ListFrom(ctx, "s1", "m2")
+	if err != nil {
+		t.Fatalf("ListFrom: %v", err)
+	}
+	if ids := messageIDs(got); !slices.Equal(ids, []string{"m2", "m3"}) {
+		t.Errorf("ListFrom(m2) = %v, want [m2 m3]", ids)
+	}
+	if _, err := svc.ListFrom(ctx, "s1", "missing"); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("missing cursor: err = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestSupersedeFrom(t *testing.T) {
+	ctx := context.Background()
+	svc, q := newDBTestService(t, "m1", "m2", "m3", "m4")
+
+	superseded, err := svc.SupersedeFrom(ctx, "s1", "m3")
+	if err != nil {
+		t.Fatalf("supersede: %v", err)
+	}
+	if len(superseded) != 2 || superseded[0].ID != "m3" || superseded[1].ID != "m4" {
+		t.Errorf("superseded = %v, want m3 and m4", superseded)
+	}
+	msgs, err := svc.List(ctx, "s1")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(msgs) != 2 || msgs[1].ID != "m2" {
+		t.Errorf("list has %d messages, want m1 and m2", len(msgs))
+	}
+	if _, err := svc.Get(ctx, "m4"); err != nil {
+		t.Errorf("superseded message should still be readable: %v", err)
+	}
+	sess, err := q.GetSessionByID(ctx, "s1")
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if sess.MessageCount != 2 {
+		t.Errorf("message count = %d, want 2", sess.MessageCount)
+	}
+
+	if _, err := svc.SupersedeFrom(ctx, "s1", "m4"); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("superseding a hidden message: err = %v, want ErrMessageNotFound", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package moderation screens assistant responses before their tool calls
+// run. A Moderator checks the response against local regexp rules and,
+// when configured, a remote moderation endpoint.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// ToolCall is a tool call of the response under review.
+type ToolCall struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Input string `json:"input"`
+}
+
+// Request is what gets moderated, and the body POSTed to the endpoint.
+type Request struct {
+	SessionID string     `json:"session_id"`
+	AgentID   string     `json:"agent_id"`
+	Text      string     `json:"text"`
+	ToolCalls []ToolCall `json:"tool_calls"`
+}
+
+// Verdict is the outcome of a check. Source is "rule" or "endpoint"; Rule
+// names the matching rule for rule verdicts.
+type Verdict struct {
+	Flagged bool
+	Source  string
+	Rule    string
+	Reason  string
+}
+
+type rule struct {
+	name   string
+	re     *regexp.Regexp
+	tools  []string
+	reason string
+}
+
+// Moderator checks responses against the configured rules and endpoint.
+type Moderator struct {
+	rules      []rule
+	endpoint   string
+	headers    map[string]string
+	failClosed bool
+	// NoOverride reports whether flagged calls are blocked without asking
+	// the user.
+	NoOverride bool
+	client     *http.Client
+}
+
+// New builds a Moderator from cfg. It returns nil when cfg is nil or has
+// neither rules nor an endpoint, and an error for an invalid rule.
+func New(cfg *config.ModerationConfig) (*Moderator, error) {
+	if cfg == nil || (len(cfg.Rules) == 0 && cfg.Endpoint == "") {
+		return nil, nil
+	}
+	m := &Moderator{
+		endpoint:   cfg.Endpoint,
+		headers:    cfg.Headers,
+		failClosed: cfg.FailClosed,
+		NoOverride: cfg.NoOverride,
+	}
+	for i, r := range cfg.Rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("moderation rule %d (%s): %w", i, r.Name, err)
+		}
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i)
+		}
+		m.rules = append(m.rules, rule{name: name, re: re, tools: r.Tools, reason: r.Reason})
+	}
+	if m.endpoint != "" {
+		timeout := defaultTimeout
+		if cfg.TimeoutMs > 0 {
+			timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
+		}
+		m.client = &http.Client{Timeout: timeout}
+	}
+	return m, nil
+}
+
+// Check returns the first rule that flags req, or else the endpoint's
+// verdict. An unreachable endpoint flags the response only when the config
+// asks to fail closed; either way the error is returned for logging.
+func (m *Moderator) Check(ctx context.Context, req Request) (Verdict, error) {
+	for _, r := range m.rules {
+		if r.matches(req) {
+			reason := r.reason
+			if reason == "" {
+				reason = fmt.Sprintf("matched moderation rule %q", r.name)
+			}
+			return Verdict{Flagged: true, Source: "rule", Rule: r.name, Reason: reason}, nil
+		}
+	}
+	if m.endpoint == "" {
+		return Verdict{}, nil
+	}
+	v, err := m.ask(ctx, req)
+	if err != nil {
+		if m.failClosed {
+			return Verdict{Flagged: true, Source: "endpoint", Reason: "moderation endpoint unavailable"}, err
+		}
+		return Verdict{}, err
+	}
+	return v, nil
+}
+
+func (r rule) matches(req Request) bool {
+	if len(r.tools) == 0 && r.re.MatchString(req.Text) {
+		return true
+	}
+	for _, tc := range req.ToolCalls {
+		if r.appliesTo(tc.Name) && r.re.MatchString(tc.Input) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r rule) appliesTo(toolName string) bool {
+	if len(r.tools) == 0 {
+		return true
+	}
+	for _, t := range r.tools {
+		if permission.MatchWildcard(t, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Moderator) ask(ctx context.Context, req Request) (Verdict, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range m.headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Verdict{}, fmt.Errorf("moderation endpoint returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var out struct {
+		Flagged bool   `json:"flagged"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Verdict{}, fmt.Errorf("decoding moderation endpoint response: %w", err)
+	}
+	if !out.Flagged {
+		return Verdict{}, nil
+	}
+	if out.Reason == "" {
+		out.Reason = "flagged by moderation endpoint"
+	}
+	return Verdict{Flagged: true, Source: "endpoint", Reason: out.Reason}, nil
+}
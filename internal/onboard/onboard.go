@@ -0,0 +1,89 @@
+// Package onboard generates a project's agent instructions file by having
+// the explorer agent survey the repository.
+package onboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultFile is the instructions file created when the project has none.
+const DefaultFile = "AGENTS.md"
+
+// candidates are the instruction files `opencode init` updates in place,
+// in order of preference. All of them are in the default context paths, so
+// whichever exists is already sent to every agent.
+var candidates = []string{
+	"AGENTS.md",
+	"opencode.md",
+	"OpenCode.md",
+	"OPENCODE.md",
+	"CLAUDE.md",
+}
+
+// TargetFile returns the file to write, relative to dir: override if set,
+// else the first instructions file that already exists, else DefaultFile.
+func TargetFile(dir, override string) string {
+	if override != "" {
+		return override
+	}
+	for _, name := range candidates {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return name
+		}
+	}
+	return DefaultFile
+}
+
+// Prompt builds the request sent to the explorer agent. existing is the
+// current content of file, or empty when it is being created.
+func Prompt(file, existing string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `Survey this repository and write the %s file that coding agents read at the start of every session in it.
+
+Explore before writing: read the README, build and CI files (Makefile, package manifests, go.mod, .github/workflows, Dockerfiles), the top-level directory layout and a few representative source and test files. Look for existing rules for other tools (.cursorrules, .cursor/rules/, .github/copilot-instructions.md, CLAUDE.md) and carry over what they say.
+
+The file must cover, in this order:
+1. Build, lint and test commands, including how to run a single test. Only list commands you found evidence for.
+2. An architecture overview: the main packages or modules, what each is responsible for and how a request flows through them.
+3. Conventions: imports and formatting, naming, error handling, logging, how tests are laid out, and anything a newcomer would likely get wrong.
+
+Be specific to this repository and keep it under about 150 lines; generic advice is noise. Use Markdown headings and short bullet lists.
+`, file)
+	if strings.TrimSpace(existing) != "" {
+		fmt.Fprintf(&b, `
+%s already exists. Keep what is still accurate, fix what is outdated and fill in what is missing. Its current content:
+
+<existing>
+%s
+</existing>
+`, file, existing)
+	}
+	b.WriteString(`
+Reply with the complete content of the file and nothing else: no preamble, no closing remarks and no code fence around it.`)
+	return b.String()
+}
+
+// ExtractDocument returns the file content from the agent's final reply,
+// unwrapping a Markdown code fence around the whole reply and dropping any
+// preamble before the first heading.
+func ExtractDocument(reply string) (string, error) {
+	doc := strings.TrimSpace(reply)
+	if strings.HasPrefix(doc, "```") {
+		if nl := strings.IndexByte(doc, '\n'); nl >= 0 && strings.HasSuffix(doc, "```") {
+			doc = strings.TrimSpace(strings.TrimSuffix(doc[nl+1:], "```"))
+		}
+	}
+	if !strings.HasPrefix(doc, "#") {
+		if i := strings.Index(doc, "\n#"); i >= 0 {
+			doc = doc[i+1:]
+		}
+	}
+	if doc == "" {
+		return "", errors.New("the agent returned an empty document")
+	}
+	return doc + "\n", nil
+}
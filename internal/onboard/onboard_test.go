@@ -0,0 +1,60 @@
+package onboard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetFile(t *testing.T) {
+	dir := t.TempDir()
+	if got := TargetFile(dir, ""); got != DefaultFile {
+		t.Errorf("empty project: got %q, want %q", got, DefaultFile)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "opencode.md"), []byte("# x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := TargetFile(dir, ""); got != "opencode.md" {
+		t.Errorf("existing opencode.md: got %q", got)
+	}
+	if got := TargetFile(dir, "docs/AGENTS.md"); got != "docs/AGENTS.md" {
+		t.Errorf("override: got %q", got)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	p := Prompt("AGENTS.md", "")
+	if strings.Contains(p, "<existing>") {
+		t.Error("prompt for a new file should not include existing content")
+	}
+	p = Prompt("opencode.md", "# Old notes\n")
+	if !strings.Contains(p, "opencode.md already exists") || !strings.Contains(p, "# Old notes") {
+		t.Errorf("prompt should include the existing file:\n%s", p)
+	}
+}
+
+func TestExtractDocument(t *testing.T) {
+	tests := []struct {
+		name, reply, want string
+	}{
+		{"plain", "# Project\n\n- go test ./...", "# Project\n\n- go test ./...\n"},
+		{"fenced", "```markdown\n# Project\n- make\n```", "# Project\n- make\n"},
+		{"preamble", "Here is the file:\n\n# Project\nbody", "# Project\nbody\n"},
+		{"no heading", "Build with make.", "Build with make.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractDocument(tt.reply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := ExtractDocument("  \n"); err == nil {
+		t.Error("expected an error for an empty reply")
+	}
+}
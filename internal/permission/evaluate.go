@@ -165,6 +165,22 @@ func ReadDenyPatterns(toolName string, agentPerms, globalPerms map[string]any) [
 	return patterns
 }
 
+// PolicyDenies reports whether deny-only rules, such as an organisation
+// policy's, refuse input for toolName. Rules apply under the tool's own
+// name, under "read" for read-category tools, and under "*".
+func PolicyDenies(toolName, input string, read bool, rules map[string]any) bool {
+	keys := []string{toolName}
+	if read && toolName != "read" {
+		keys = append(keys, "read")
+	}
+	for _, key := range append(keys, "*") {
+		if v, ok := rules[key]; ok && resolvePermissionValue(input, v) == ActionDeny {
+			return true
+		}
+	}
+	return false
+}
+
 func IsToolEnabled(toolName string, toolsConfig map[string]bool) bool {
 	if toolsConfig == nil {
 		return true
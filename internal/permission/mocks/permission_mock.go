@@ -18,6 +18,60 @@ import (
 	gomock "go.uber.org/mock/gomock"
 )
 
+// MockReviewer is a mock of Reviewer interface.
+type MockReviewer struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewerMockRecorder
+	isgomock struct{}
+}
+
+// MockReviewerMockRecorder is the mock recorder for MockReviewer.
+type MockReviewerMockRecorder struct {
+	mock *MockReviewer
+}
+
+// NewMockReviewer creates a new mock instance.
+func NewMockReviewer(ctrl *gomock.Controller) *MockReviewer {
+	mock := &MockReviewer{ctrl: ctrl}
+	mock.recorder = &MockReviewerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewer) EXPECT() *MockReviewerMockRecorder {
+	return m.recorder
+}
+
+// Review mocks base method.
+func (m *MockReviewer) Review(ctx context.Context, req permission.PermissionRequest) (permission.ReviewDecision, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, req)
+	ret0, _ := ret[0].(permission.ReviewDecision)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockReviewerMockRecorder) Review(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockReviewer)(nil).Review), ctx, req)
+}
+
+// Reviews mocks base method.
+func (m *MockReviewer) Reviews(toolName string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reviews", toolName)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Reviews indicates an expected call of Reviews.
+func (mr *MockReviewerMockRecorder) Reviews(toolName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reviews", reflect.TypeOf((*MockReviewer)(nil).Reviews), toolName)
+}
+
 // MockService is a mock of Service interface.
 type MockService struct {
 	ctrl     *gomock.Controller
@@ -78,6 +132,20 @@ func (mr *MockServiceMockRecorder) Grant(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockService)(nil).Grant), arg0)
 }
 
+// GrantProject mocks base method.
+func (m *MockService) GrantProject(arg0 permission.PermissionRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantProject", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantProject indicates an expected call of GrantProject.
+func (mr *MockServiceMockRecorder) GrantProject(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantProject", reflect.TypeOf((*MockService)(nil).GrantProject), arg0)
+}
+
 // GrantPersistant mocks base method.
 func (m *MockService) GrantPersistant(arg0 permission.PermissionRequest) {
 	m.ctrl.T.Helper()
@@ -180,6 +248,18 @@ func (mr *MockServiceMockRecorder) Request(ctx, opts any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockService)(nil).Request), ctx, opts)
 }
 
+// SetReviewer mocks base method.
+func (m *MockService) SetReviewer(r permission.Reviewer) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReviewer", r)
+}
+
+// SetReviewer indicates an expected call of SetReviewer.
+func (mr *MockServiceMockRecorder) SetReviewer(r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReviewer", reflect.TypeOf((*MockService)(nil).SetReviewer), r)
+}
+
 // Subscribe mocks base method.
 func (m *MockService) Subscribe(arg0 context.Context) <-chan pubsub.Event[permission.PermissionRequest] {
 	m.ctrl.T.Helper()
@@ -7,7 +7,9 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/audit"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 )
 
@@ -20,6 +22,18 @@ type CreatePermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+	// Pattern is the input permission rules are matched against for this
+	// call: the command for bash, the file path for file tools, the same
+	// string the tool passes to EvaluatePermission. "Allow for session"
+	// and "allow always" grants are recorded as a rule for it. Empty
+	// means the grant covers the tool in Path, and the call can't be
+	// allowed for the project.
+	Pattern string `json:"pattern,omitempty"`
+	// Confirm asks the user even in auto-approve sessions, ignoring hooks,
+	// the reviewer and earlier grants. It is for guards against what
+	// auto-approval itself lets through; callers must not raise it
+	// without a user to answer.
+	Confirm bool `json:"confirm,omitempty"`
 }
 
 type PermissionRequest struct {
@@ -30,11 +44,49 @@ type PermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+	Pattern     string `json:"pattern,omitempty"`
+	// Confirm marks a request that can only be allowed once; see
+	// CreatePermissionRequest.Confirm.
+	Confirm bool `json:"confirm,omitempty"`
+}
+
+// ErrNoPattern is returned by GrantProject for requests that carry no
+// pattern to save.
+var ErrNoPattern = errors.New("permission request has no pattern to save")
+
+// ReviewDecision is the verdict returned by a Reviewer.
+type ReviewDecision string
+
+const (
+	ReviewApprove ReviewDecision = "approve"
+	ReviewDeny    ReviewDecision = "deny"
+	// ReviewEscalate hands the request to a human. Sessions without a
+	// human in the loop (auto-approve) treat it as a denial.
+	ReviewEscalate ReviewDecision = "escalate"
+)
+
+// Reviewer inspects permission requests on behalf of the user, typically
+// by asking a dedicated reviewer agent whether a command or edit complies
+// with a policy. It lets unattended runs keep a safety gate in place of the
+// interactive prompt.
+type Reviewer interface {
+	// Reviews reports whether requests for toolName go through the reviewer.
+	Reviews(toolName string) bool
+	// Review returns the verdict and a human-readable justification.
+	Review(ctx context.Context, req PermissionRequest) (ReviewDecision, string, error)
 }
 
 type Service interface {
 	pubsub.Suscriber[PermissionRequest]
+	// GrantPersistant allows the request and every later identical one
+	// (same tool, action and pattern, or path when there is no pattern)
+	// in its session and the sessions linked below it.
 	GrantPersistant(permission PermissionRequest)
+	// GrantProject allows the request and saves an "allow" rule for its
+	// pattern to the project config, so the call is not asked about
+	// again in this or any later session. The request is granted even
+	// if saving fails; the error is returned for the caller to report.
+	GrantProject(permission PermissionRequest) error
 	Grant(permission PermissionRequest)
 	Deny(permission PermissionRequest)
 	Request(ctx context.Context, opts CreatePermissionRequest) bool
@@ -60,17 +112,27 @@ type Service interface {
 	MarkInteractiveSession(sessionID string)
 	RemoveInteractiveSession(sessionID string)
 	IsInteractiveSession(sessionID string) bool
+
+	// SetReviewer installs a reviewer that is consulted before the
+	// auto-approve and interactive paths for the tools it covers. nil
+	// removes it.
+	SetReviewer(r Reviewer)
 }
 
 type permissionService struct {
 	*pubsub.Broker[PermissionRequest]
 
 	sessionPermissions   []PermissionRequest
+	projectPermissions   []PermissionRequest
+	grantsMu             sync.RWMutex
 	pendingRequests      sync.Map
 	autoApproveSessions  sync.Map
 	interactiveSessions  sync.Map
 	sessionParents       sync.Map // child session ID -> parent session ID
 	serializePermissions sync.Mutex
+
+	reviewerMu sync.RWMutex
+	reviewer   Reviewer
 }
 
 func (s *permissionService) GrantPersistant(permission PermissionRequest) {
@@ -78,7 +140,22 @@ func (s *permissionService) GrantPersistant(permission PermissionRequest) {
 	if ok {
 		respCh.(chan bool) <- true
 	}
+	s.grantsMu.Lock()
 	s.sessionPermissions = append(s.sessionPermissions, permission)
+	s.grantsMu.Unlock()
+}
+
+func (s *permissionService) GrantProject(permission PermissionRequest) error {
+	s.Grant(permission)
+	if permission.Pattern == "" {
+		return ErrNoPattern
+	}
+	// Keep the grant in memory too: the agent registry evaluates the
+	// rules it was built with until it is rebuilt.
+	s.grantsMu.Lock()
+	s.projectPermissions = append(s.projectPermissions, permission)
+	s.grantsMu.Unlock()
+	return config.AddProjectPermissionRule(permission.ToolName, permission.Pattern, string(ActionAllow))
 }
 
 func (s *permissionService) Grant(permission PermissionRequest) {
@@ -106,18 +183,116 @@ type hookAllowKeyType struct{}
 // the standard permission gate for this call only.
 var HookAllowKey = hookAllowKeyType{}
 
+type decisionRecorderKeyType struct{}
+
+var decisionRecorderKey = decisionRecorderKeyType{}
+
+// DecisionRecorder collects the permission decisions made while one tool
+// call runs, so the call's audit record can say whether and how it was
+// allowed. A call may ask more than once (one request per file); a deny
+// sticks, otherwise the last allow is kept.
+type DecisionRecorder struct {
+	mu       sync.Mutex
+	decision string
+	via      string
+}
+
+// WithDecisionRecorder returns a context whose permission requests are
+// recorded in the returned recorder.
+func WithDecisionRecorder(ctx context.Context) (context.Context, *DecisionRecorder) {
+	r := &DecisionRecorder{}
+	return context.WithValue(ctx, decisionRecorderKey, r), r
+}
+
+func (r *DecisionRecorder) record(decision, via string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.decision == "deny" {
+		return
+	}
+	r.decision, r.via = decision, via
+}
+
+// Result returns the recorded decision (allow or deny) and what decided
+// it. Both are empty when the call asked for no permission; a nil
+// recorder records nothing.
+func (r *DecisionRecorder) Result() (decision, via string) {
+	if r == nil {
+		return "", ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.decision, r.via
+}
+
 func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRequest) bool {
+	allowed, via, reason := s.decide(ctx, opts)
+	decision := "deny"
+	if allowed {
+		decision = "allow"
+	}
+	if r, ok := ctx.Value(decisionRecorderKey).(*DecisionRecorder); ok {
+		r.record(decision, via)
+	}
+	audit.Record(audit.Entry{
+		Kind:      audit.KindPermission,
+		SessionID: opts.SessionID,
+		Tool:      opts.ToolName,
+		Action:    opts.Action,
+		Path:      opts.Path,
+		Input:     opts.Description,
+		Decision:  decision,
+		Via:       via,
+		Reason:    reason,
+	})
+	return allowed
+}
+
+// decide resolves a permission request and names what decided it. reason
+// is the reviewer's justification when the reviewer decided.
+func (s *permissionService) decide(ctx context.Context, opts CreatePermissionRequest) (allowed bool, via, reason string) {
+	if opts.Confirm {
+		defer s.serializePermissions.Unlock()
+		s.serializePermissions.Lock()
+		allowed, via = s.ask(ctx, newPermissionRequest(opts))
+		return allowed, via, ""
+	}
 	if v, ok := ctx.Value(HookAllowKey).(bool); ok && v {
-		return true
+		return true, "hook", ""
+	}
+	autoApprove := s.IsAutoApproveSession(opts.SessionID)
+	reviewer := s.reviewerFor(opts.ToolName)
+	if autoApprove && reviewer == nil {
+		return true, "auto_approve", ""
 	}
-	if s.IsAutoApproveSession(opts.SessionID) {
-		return true
+	permission := newPermissionRequest(opts)
+
+	if reviewer != nil {
+		if allowed, decided, reason := review(ctx, reviewer, permission, autoApprove); decided {
+			return allowed, "reviewer", reason
+		}
+	}
+	if autoApprove {
+		return true, "auto_approve", ""
+	}
+
+	// NOTE: serialise permission dialog, permissions requests are interactive
+	defer s.serializePermissions.Unlock()
+	s.serializePermissions.Lock()
+
+	if via, ok := s.granted(permission); ok {
+		return true, via, ""
 	}
+	allowed, via = s.ask(ctx, permission)
+	return allowed, via, ""
+}
+
+func newPermissionRequest(opts CreatePermissionRequest) PermissionRequest {
 	dir := filepath.Dir(opts.Path)
 	if dir == "." {
 		dir = config.WorkingDirectory()
 	}
-	permission := PermissionRequest{
+	return PermissionRequest{
 		ID:          uuid.New().String(),
 		Path:        dir,
 		SessionID:   opts.SessionID,
@@ -125,36 +300,118 @@ func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRe
 		Description: opts.Description,
 		Action:      opts.Action,
 		Params:      opts.Params,
+		Pattern:     opts.Pattern,
+		Confirm:     opts.Confirm,
 	}
+}
 
-	// NOTE: serialise permission dialog, permissions requests are interactive
-	defer s.serializePermissions.Unlock()
-	s.serializePermissions.Lock()
+// ask publishes permission to the user and waits for the answer. Callers
+// hold serializePermissions.
+func (s *permissionService) ask(ctx context.Context, permission PermissionRequest) (bool, string) {
+	respCh := make(chan bool, 1)
+
+	s.pendingRequests.Store(permission.ID, respCh)
+	defer s.pendingRequests.Delete(permission.ID)
+
+	s.Publish(pubsub.CreatedEvent, permission)
 
+	select {
+	case resp := <-respCh:
+		return resp, "user"
+	case <-ctx.Done():
+		return false, "cancelled"
+	}
+}
+
+// granted reports whether an earlier "allow for session" or "allow
+// always" answer covers permission.
+func (s *permissionService) granted(permission PermissionRequest) (string, bool) {
+	s.grantsMu.RLock()
+	defer s.grantsMu.RUnlock()
+
+	for _, p := range s.projectPermissions {
+		if p.ToolName == permission.ToolName && permission.Pattern != "" && MatchWildcard(p.Pattern, permission.Pattern) {
+			return "project_grant", true
+		}
+	}
 	for _, p := range s.sessionPermissions {
-		if p.ToolName != permission.ToolName || p.Action != permission.Action || p.Path != permission.Path {
+		if !grantCovers(p, permission) {
 			continue
 		}
 		// A persistent grant covers the session it was issued on and every
-		// descendant session linked below it, so "allow always" on the main
-		// conversation also covers subagents it spawns later.
+		// descendant session linked below it, so "allow for session" on the
+		// main conversation also covers subagents it spawns later.
 		if s.walkSessionChain(permission.SessionID, func(id string) bool { return p.SessionID == id }) {
-			return true
+			return "session_grant", true
 		}
 	}
+	return "", false
+}
 
-	respCh := make(chan bool, 1)
+// grantCovers matches a session grant against a new request: by pattern
+// when the grant recorded one, by directory otherwise.
+func grantCovers(grant, req PermissionRequest) bool {
+	if grant.ToolName != req.ToolName || grant.Action != req.Action {
+		return false
+	}
+	if grant.Pattern != "" {
+		return req.Pattern != "" && MatchWildcard(grant.Pattern, req.Pattern)
+	}
+	return grant.Path == req.Path
+}
 
-	s.pendingRequests.Store(permission.ID, respCh)
-	defer s.pendingRequests.Delete(permission.ID)
+func (s *permissionService) SetReviewer(r Reviewer) {
+	s.reviewerMu.Lock()
+	defer s.reviewerMu.Unlock()
+	s.reviewer = r
+}
 
-	s.Publish(pubsub.CreatedEvent, permission)
+// reviewerFor returns the installed reviewer if it covers toolName.
+func (s *permissionService) reviewerFor(toolName string) Reviewer {
+	s.reviewerMu.RLock()
+	defer s.reviewerMu.RUnlock()
+	if s.reviewer == nil || !s.reviewer.Reviews(toolName) {
+		return nil
+	}
+	return s.reviewer
+}
 
-	select {
-	case resp := <-respCh:
-		return resp
-	case <-ctx.Done():
-		return false
+// review asks r for a verdict and returns its reason. decided is false
+// when the reviewer fails or escalates in a session that has a human to
+// ask; the request then follows the regular path. In an auto-approve
+// session there is nobody to fall back to, so both deny: an outage of the
+// reviewer must not approve the requests it exists to gate.
+func review(ctx context.Context, r Reviewer, req PermissionRequest, autoApprove bool) (allowed, decided bool, reason string) {
+	decision, reason, err := r.Review(ctx, req)
+	if err != nil {
+		if autoApprove {
+			logging.Warn("Permission review failed, denying in auto-approve session",
+				"session_id", req.SessionID, "tool", req.ToolName, "error", err)
+			return false, true, "review failed: " + err.Error()
+		}
+		logging.Warn("Permission review failed, falling back to regular permission flow",
+			"session_id", req.SessionID, "tool", req.ToolName, "error", err)
+		return false, false, ""
+	}
+	logging.Info("Permission reviewed",
+		"session_id", req.SessionID,
+		"tool", req.ToolName,
+		"action", req.Action,
+		"description", req.Description,
+		"decision", decision,
+		"reason", reason)
+
+	switch decision {
+	case ReviewApprove:
+		return true, true, reason
+	case ReviewDeny:
+		return false, true, reason
+	default:
+		if autoApprove {
+			// Nobody is there to answer the escalation.
+			return false, true, reason
+		}
+		return false, false, ""
 	}
 }
 
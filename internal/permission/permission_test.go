@@ -1,8 +1,17 @@
 package permission
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/opencode-ai/opencode/internal/audit"
+	"github.com/opencode-ai/opencode/internal/config"
 )
 
 func TestAutoApproveToggle(t *testing.T) {
@@ -241,3 +250,244 @@ func TestPersistentGrantCoversLinkedSubagents(t *testing.T) {
 		t.Fatal("expected child grant to not cover the parent session")
 	}
 }
+
+func TestConfirmAsksDespiteAutoApproveAndGrants(t *testing.T) {
+	svc := NewPermissionService()
+	svc.AutoApproveSession("main")
+	svc.GrantPersistant(PermissionRequest{SessionID: "main", ToolName: "diff_budget", Action: "exceed", Path: "/repo"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := svc.Subscribe(ctx)
+	result := make(chan bool, 1)
+	go func() {
+		result <- svc.Request(context.WithValue(ctx, HookAllowKey, true), CreatePermissionRequest{
+			SessionID: "main",
+			ToolName:  "diff_budget",
+			Action:    "exceed",
+			Path:      "/repo/x",
+			Confirm:   true,
+		})
+	}()
+
+	event := <-events
+	if !event.Payload.Confirm {
+		t.Fatal("published request lost Confirm")
+	}
+	svc.Deny(event.Payload)
+	if <-result {
+		t.Fatal("expected the user's denial to win over auto-approve, hooks and grants")
+	}
+}
+
+type stubReviewer struct {
+	tools    []string
+	decision ReviewDecision
+	err      error
+	calls    int
+}
+
+func (r *stubReviewer) Reviews(toolName string) bool {
+	for _, t := range r.tools {
+		if t == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *stubReviewer) Review(_ context.Context, _ PermissionRequest) (ReviewDecision, string, error) {
+	r.calls++
+	return r.decision, "stub", r.err
+}
+
+func TestReviewerGatesAutoApproveSession(t *testing.T) {
+	cases := []struct {
+		name     string
+		decision ReviewDecision
+		err      error
+		want     bool
+	}{
+		{"approve", ReviewApprove, nil, true},
+		{"deny", ReviewDeny, nil, false},
+		// No human to escalate to in an auto-approve session.
+		{"escalate", ReviewEscalate, nil, false},
+		// A failing reviewer must not degrade into plain auto-approve.
+		{"error", "", context.DeadlineExceeded, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewPermissionService()
+			r := &stubReviewer{tools: []string{"bash"}, decision: tc.decision, err: tc.err}
+			svc.SetReviewer(r)
+			svc.AutoApproveSession("s")
+
+			got := svc.Request(context.Background(), CreatePermissionRequest{
+				SessionID: "s",
+				ToolName:  "bash",
+				Action:    "execute",
+				Path:      "/tmp/project/file",
+			})
+			if got != tc.want {
+				t.Fatalf("Request() = %v, want %v", got, tc.want)
+			}
+			if r.calls != 1 {
+				t.Fatalf("reviewer called %d times, want 1", r.calls)
+			}
+		})
+	}
+}
+
+func TestReviewerErrorFallsBackToUser(t *testing.T) {
+	svc := NewPermissionService()
+	svc.SetReviewer(&stubReviewer{tools: []string{"bash"}, err: context.DeadlineExceeded})
+	events := svc.Subscribe(t.Context())
+
+	result := make(chan bool, 1)
+	go func() {
+		result <- svc.Request(context.Background(), CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp/project/file"})
+	}()
+	svc.Grant((<-events).Payload)
+	if !<-result {
+		t.Fatal("expected an interactive session to ask the user when the reviewer fails")
+	}
+}
+
+func TestReviewerReasonIsAudited(t *testing.T) {
+	trail := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := audit.Init(&config.Config{Audit: &config.AuditConfig{Enabled: true, Path: trail}}); err != nil {
+		t.Fatalf("audit init: %v", err)
+	}
+	t.Cleanup(audit.Shutdown)
+
+	svc := NewPermissionService()
+	svc.SetReviewer(&stubReviewer{tools: []string{"bash"}, decision: ReviewDeny})
+	svc.AutoApproveSession("s")
+	if svc.Request(context.Background(), CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp/project/file"}) {
+		t.Fatal("expected the reviewer's denial")
+	}
+	audit.Shutdown()
+
+	data, err := os.ReadFile(trail)
+	if err != nil {
+		t.Fatalf("read trail: %v", err)
+	}
+	var e audit.Entry
+	if err := json.Unmarshal(bytes.TrimSpace(data), &e); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	if e.Kind != audit.KindPermission || e.Via != "reviewer" || e.Reason != "stub" {
+		t.Fatalf("entry = %+v, want a reviewer permission entry with reason %q", e, "stub")
+	}
+}
+
+func TestReviewerSkipsUncoveredTools(t *testing.T) {
+	svc := NewPermissionService()
+	r := &stubReviewer{tools: []string{"bash"}, decision: ReviewDeny}
+	svc.SetReviewer(r)
+	svc.AutoApproveSession("s")
+
+	if !svc.Request(context.Background(), CreatePermissionRequest{SessionID: "s", ToolName: "write", Action: "write"}) {
+		t.Fatal("expected uncovered tool to follow auto-approve")
+	}
+	if r.calls != 0 {
+		t.Fatalf("reviewer should not be consulted for uncovered tools, got %d calls", r.calls)
+	}
+}
+
+func TestSessionGrantMatchesPattern(t *testing.T) {
+	svc := NewPermissionService()
+	svc.GrantPersistant(PermissionRequest{
+		SessionID: "main",
+		ToolName:  "bash",
+		Action:    "execute",
+		Path:      "/repo",
+		Pattern:   "go test ./...",
+	})
+
+	if !svc.Request(context.Background(), CreatePermissionRequest{
+		SessionID: "main", ToolName: "bash", Action: "execute", Path: "/repo/x", Pattern: "go test ./...",
+	}) {
+		t.Fatal("expected the identical command to be allowed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if svc.Request(ctx, CreatePermissionRequest{
+		SessionID: "main", ToolName: "bash", Action: "execute", Path: "/repo/x", Pattern: "rm -rf /",
+	}) {
+		t.Fatal("a command grant must not cover other commands in the same directory")
+	}
+	if svc.Request(ctx, CreatePermissionRequest{
+		SessionID: "other", ToolName: "bash", Action: "execute", Path: "/repo/x", Pattern: "go test ./...",
+	}) {
+		t.Fatal("a session grant must not cover other sessions")
+	}
+}
+
+func TestProjectGrant(t *testing.T) {
+	dir := t.TempDir()
+	if config.Get() == nil {
+		if _, err := config.Load(dir, false); err != nil {
+			t.Fatalf("load config: %v", err)
+		}
+	}
+	cfg := config.Get()
+	prevWD := cfg.WorkingDir
+	cfg.WorkingDir = dir
+	t.Cleanup(func() { cfg.WorkingDir = prevWD })
+
+	svc := NewPermissionService()
+	if err := svc.GrantProject(PermissionRequest{SessionID: "a", ToolName: "bash"}); !errors.Is(err, ErrNoPattern) {
+		t.Fatalf("grant without pattern: err = %v", err)
+	}
+	if err := svc.GrantProject(PermissionRequest{
+		SessionID: "a", ToolName: "bash", Action: "execute", Path: dir, Pattern: "make lint",
+	}); err != nil {
+		t.Fatalf("GrantProject: %v", err)
+	}
+
+	// Any session, without asking again.
+	if !svc.Request(context.Background(), CreatePermissionRequest{
+		SessionID: "b", ToolName: "bash", Action: "execute", Path: dir, Pattern: "make lint",
+	}) {
+		t.Fatal("expected the project grant to cover a new session")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".opencode.json"))
+	if err != nil {
+		t.Fatalf("project config not written: %v", err)
+	}
+	if !strings.Contains(string(data), `"make lint": "allow"`) {
+		t.Errorf("project config = %s", data)
+	}
+}
+
+func TestDecisionRecorder(t *testing.T) {
+	svc := NewPermissionService()
+	svc.AutoApproveSession("s")
+
+	ctx, rec := WithDecisionRecorder(context.Background())
+	if d, via := rec.Result(); d != "" || via != "" {
+		t.Fatalf("fresh recorder = %q/%q", d, via)
+	}
+	svc.Request(ctx, CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp/project/file"})
+	if d, via := rec.Result(); d != "allow" || via != "auto_approve" {
+		t.Errorf("after auto-approved request = %q/%q", d, via)
+	}
+
+	// A denial sticks even if a later request of the same call is allowed.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	svc.RemoveAutoApproveSession("s")
+	svc.Request(cancelled, CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp/x"})
+	svc.AutoApproveSession("s")
+	svc.Request(ctx, CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp/project/file"})
+	if d, via := rec.Result(); d != "deny" || via != "cancelled" {
+		t.Errorf("after denied request = %q/%q", d, via)
+	}
+
+	var none *DecisionRecorder
+	if d, via := none.Result(); d != "" || via != "" {
+		t.Errorf("nil recorder = %q/%q", d, via)
+	}
+}
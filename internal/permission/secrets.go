@@ -0,0 +1,129 @@
+package permission
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultSecretPatterns are the files the secret guard keeps away from the
+// model unless a project opts in. Patterns without a slash match the file's
+// base name, relative ones with a slash match at any depth, and absolute or
+// "~/" ones match the full path.
+var DefaultSecretPatterns = []string{
+	".env",
+	".env.*",
+	"*.pem",
+	"*.key",
+	"*.p12",
+	"*.pfx",
+	"id_rsa",
+	"id_dsa",
+	"id_ecdsa",
+	"id_ed25519",
+	".netrc",
+	".git-credentials",
+	"~/.aws/credentials",
+	"~/.config/gcloud/*",
+	"~/.azure/*",
+	"~/.kube/config",
+	"~/.docker/config.json",
+}
+
+// secretTemplates are checked-in examples that match DefaultSecretPatterns
+// by name but hold no real values.
+var secretTemplates = []string{
+	".env.example",
+	".env.sample",
+	".env.template",
+	".env.dist",
+}
+
+// SecretGuardedTools are the tools whose permission input is a file path the
+// secret guard applies to. Bash is guarded separately, by its arguments.
+var SecretGuardedTools = []string{
+	"read", "grep", "glob", "ls", "notebook_read", "lsp_symbols",
+	"edit", "write", "multiedit", "patch", "delete", "notebook_edit",
+}
+
+// IsSecretPath reports whether path matches a default secret pattern that
+// allow does not opt back in. allow takes the same pattern forms; "*" turns
+// the guard off.
+func IsSecretPath(path string, allow []string) bool {
+	if path == "" || slices.Contains(allow, "*") {
+		return false
+	}
+	if slices.Contains(secretTemplates, filepath.Base(path)) {
+		return false
+	}
+	if !matchesAnySecretPattern(path, DefaultSecretPatterns) {
+		return false
+	}
+	return !matchesAnySecretPattern(path, allow)
+}
+
+// CommandTouchesSecret reports whether a shell command names a secret file
+// among its arguments, e.g. "cat .env" or "cp ~/.aws/credentials /tmp".
+// It is a best-effort scan of the words in the command, not a shell parser.
+func CommandTouchesSecret(command string, allow []string) bool {
+	if slices.Contains(allow, "*") {
+		return false
+	}
+	home, _ := os.UserHomeDir()
+	words := strings.FieldsFunc(command, func(r rune) bool {
+		return strings.ContainsRune(" \t\n;|&<>()`'\"=", r)
+	})
+	for _, w := range words {
+		if home != "" {
+			if strings.HasPrefix(w, "~/") {
+				w = home + w[1:]
+			}
+			w = strings.Replace(w, "$HOME", home, 1)
+			w = strings.Replace(w, "${HOME}", home, 1)
+		}
+		if IsSecretPath(w, allow) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretDenyPatterns returns the default secret patterns in the form
+// ReadDenyPatterns uses, so search tools skip secret files. A pattern that
+// allow names verbatim is left out; narrower opt-ins still exclude the file
+// from search results but let read tools open it.
+func SecretDenyPatterns(allow []string) []string {
+	if slices.Contains(allow, "*") {
+		return nil
+	}
+	var patterns []string
+	for _, p := range DefaultSecretPatterns {
+		if !slices.Contains(allow, p) {
+			patterns = append(patterns, expandHome(p))
+		}
+	}
+	return patterns
+}
+
+func matchesAnySecretPattern(path string, patterns []string) bool {
+	base := filepath.Base(path)
+	for _, p := range patterns {
+		if strings.Contains(p, "/") {
+			if MatchWildcard(p, path) {
+				return true
+			}
+			// Relative patterns like "testdata/*.pem" match at any depth.
+			if !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "~") && MatchWildcard("*/"+p, path) {
+				return true
+			}
+		} else if MatchWildcard(p, base) {
+			// A bare ".key" is a jq path or an extension, not a key file.
+			if strings.HasPrefix(p, "*") && base == p[1:] {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package permission
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSecretPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("cannot determine home dir")
+	}
+
+	tests := []struct {
+		path  string
+		allow []string
+		want  bool
+	}{
+		{"/repo/.env", nil, true},
+		{"/repo/config/.env.production", nil, true},
+		{"/repo/.env.example", nil, false},
+		{"/repo/certs/server.pem", nil, true},
+		{"/home/u/.ssh/id_ed25519", nil, true},
+		{"/home/u/.ssh/id_ed25519.pub", nil, false},
+		{filepath.Join(home, ".aws", "credentials"), nil, true},
+		{filepath.Join(home, ".aws", "config"), nil, false},
+		{"/repo/main.go", nil, false},
+		{"/repo/.environment.go", nil, false},
+		{"/repo/.env.test", []string{".env.test"}, false},
+		{"/repo/.env.prod", []string{".env.test"}, true},
+		{"/repo/testdata/key.pem", []string{"/repo/testdata/*"}, false},
+		{"/repo/pkg/testdata/key.pem", []string{"testdata/*.pem"}, false},
+		{"/repo/.env", []string{"*"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsSecretPath(tt.path, tt.allow); got != tt.want {
+				t.Errorf("IsSecretPath(%q, %v) = %v, want %v", tt.path, tt.allow, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandTouchesSecret(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"cat .env", true},
+		{"grep API_KEY config/.env.local | head", true},
+		{`cp "$HOME/.aws/credentials" /tmp/x`, true},
+		{"cat ~/.aws/credentials", true},
+		{"kubectl --kubeconfig ~/.kube/config get pods", true},
+		{"cat ~/.aws/config", false},
+		{"echo KEY=1 >> .env", true},
+		{"cat .env.example", false},
+		{"jq .key package.json", false},
+		{"go test ./...", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := CommandTouchesSecret(tt.command, nil); got != tt.want {
+				t.Errorf("CommandTouchesSecret(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+	if CommandTouchesSecret("cat .env", []string{".env"}) {
+		t.Error("opted-in .env should not be flagged")
+	}
+}
@@ -0,0 +1,544 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/fileutil"
+)
+
+// Facts describes a workspace: what it is written in and how it is built,
+// tested and checked in CI. Agents get a compact rendering in their prompt
+// instead of rediscovering the same layout every session.
+type Facts struct {
+	// Commit is the HEAD commit the facts were detected at; empty outside
+	// a git repository.
+	Commit          string         `json:"commit,omitempty"`
+	Languages       []LanguageStat `json:"languages,omitempty"`
+	Frameworks      []string       `json:"frameworks,omitempty"`
+	BuildSystems    []string       `json:"buildSystems,omitempty"`
+	PackageManagers []string       `json:"packageManagers,omitempty"`
+	TestCommands    []string       `json:"testCommands,omitempty"`
+	CI              []CIConfig     `json:"ci,omitempty"`
+	// Truncated is set when the file walk stopped at maxFactsFiles, so
+	// line counts cover only part of the tree.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// LanguageStat is the size of one language in the workspace.
+type LanguageStat struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Lines int    `json:"lines"`
+}
+
+// CIConfig is one CI pipeline definition.
+type CIConfig struct {
+	System string `json:"system"`
+	Path   string `json:"path"`
+}
+
+const (
+	// maxFactsFiles bounds the walk on very large trees.
+	maxFactsFiles = 20000
+	// maxFactsFileSize skips generated bundles and data files when
+	// counting lines.
+	maxFactsFileSize = 1 << 20
+	// promptLanguages is how many languages Prompt lists.
+	promptLanguages = 5
+)
+
+var languageByExt = map[string]string{
+	".go":     "Go",
+	".rs":     "Rust",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".kts":    "Kotlin",
+	".scala":  "Scala",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".cs":     "C#",
+	".fs":     "F#",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".cxx":    "C++",
+	".hpp":    "C++",
+	".swift":  "Swift",
+	".m":      "Objective-C",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".erl":    "Erlang",
+	".hs":     "Haskell",
+	".lua":    "Lua",
+	".zig":    "Zig",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".sql":    "SQL",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".html":   "HTML",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".proto":  "Protocol Buffers",
+	".tf":     "Terraform",
+}
+
+// dependencyMarker names a framework when a manifest mentions needle.
+type dependencyMarker struct {
+	needle string
+	name   string
+}
+
+var (
+	npmFrameworks = []dependencyMarker{
+		{"next", "Next.js"},
+		{"react", "React"},
+		{"vue", "Vue"},
+		{"nuxt", "Nuxt"},
+		{"svelte", "Svelte"},
+		{"@sveltejs/kit", "SvelteKit"},
+		{"@angular/core", "Angular"},
+		{"express", "Express"},
+		{"fastify", "Fastify"},
+		{"@nestjs/core", "NestJS"},
+		{"electron", "Electron"},
+		{"jest", "Jest"},
+		{"vitest", "Vitest"},
+		{"mocha", "Mocha"},
+		{"@playwright/test", "Playwright"},
+		{"cypress", "Cypress"},
+	}
+	goFrameworks = []dependencyMarker{
+		{"github.com/gin-gonic/gin", "Gin"},
+		{"github.com/labstack/echo", "Echo"},
+		{"github.com/gofiber/fiber", "Fiber"},
+		{"github.com/go-chi/chi", "chi"},
+		{"github.com/gorilla/mux", "gorilla/mux"},
+		{"github.com/spf13/cobra", "Cobra"},
+		{"github.com/charmbracelet/bubbletea", "Bubble Tea"},
+		{"google.golang.org/grpc", "gRPC"},
+		{"gorm.io/gorm", "GORM"},
+		{"github.com/stretchr/testify", "testify"},
+	}
+	pythonFrameworks = []dependencyMarker{
+		{"django", "Django"},
+		{"flask", "Flask"},
+		{"fastapi", "FastAPI"},
+		{"pytest", "pytest"},
+		{"sqlalchemy", "SQLAlchemy"},
+		{"pydantic", "Pydantic"},
+	}
+	rustFrameworks = []dependencyMarker{
+		{"tokio", "Tokio"},
+		{"axum", "Axum"},
+		{"actix-web", "Actix Web"},
+		{"serde", "Serde"},
+		{"clap", "clap"},
+	}
+	rubyFrameworks = []dependencyMarker{
+		{"rails", "Rails"},
+		{"sinatra", "Sinatra"},
+		{"rspec", "RSpec"},
+	}
+
+	pythonManifests = []string{"pyproject.toml", "requirements.txt", "requirements-dev.txt", "Pipfile", "setup.py", "setup.cfg"}
+
+	ciFiles = []CIConfig{
+		{"GitLab CI", ".gitlab-ci.yml"},
+		{"CircleCI", ".circleci/config.yml"},
+		{"Jenkins", "Jenkinsfile"},
+		{"Azure Pipelines", "azure-pipelines.yml"},
+		{"Travis CI", ".travis.yml"},
+		{"Bitbucket Pipelines", "bitbucket-pipelines.yml"},
+		{"Buildkite", ".buildkite/pipeline.yml"},
+	}
+)
+
+// DetectFacts inspects dir. Unreadable files are skipped; detection never
+// fails, it just reports less.
+func DetectFacts(dir string) Facts {
+	var f Facts
+	f.Languages, f.Truncated = countLanguages(dir)
+
+	has := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+	addBuild := func(name string) { f.BuildSystems = appendUnique(f.BuildSystems, name) }
+	addPM := func(name string) { f.PackageManagers = appendUnique(f.PackageManagers, name) }
+	addTest := func(cmd string) { f.TestCommands = appendUnique(f.TestCommands, cmd) }
+
+	if has("go.mod") {
+		addBuild("go")
+		addPM("go modules")
+		f.Frameworks = append(f.Frameworks, manifestMarkers(filepath.Join(dir, "go.mod"), goFrameworks)...)
+		addTest("go test ./...")
+	}
+	if has("Cargo.toml") {
+		addBuild("cargo")
+		addPM("cargo")
+		f.Frameworks = append(f.Frameworks, manifestMarkers(filepath.Join(dir, "Cargo.toml"), rustFrameworks)...)
+		addTest("cargo test")
+	}
+	if has("package.json") {
+		addPM(string(packageRunner(dir)))
+		f.Frameworks = append(f.Frameworks, npmMarkers(dir)...)
+	}
+	for _, manifest := range pythonManifests {
+		if !has(manifest) {
+			continue
+		}
+		f.Frameworks = append(f.Frameworks, manifestMarkers(filepath.Join(dir, manifest), pythonFrameworks)...)
+	}
+	switch {
+	case has("uv.lock"):
+		addPM("uv")
+	case has("poetry.lock"):
+		addPM("poetry")
+	case has("Pipfile"):
+		addPM("pipenv")
+	case has("requirements.txt"), has("pyproject.toml"), has("setup.py"):
+		addPM("pip")
+	}
+	if slices.Contains(f.Frameworks, "pytest") {
+		addTest("pytest")
+	}
+	if has("Gemfile") {
+		addPM("bundler")
+		f.Frameworks = append(f.Frameworks, manifestMarkers(filepath.Join(dir, "Gemfile"), rubyFrameworks)...)
+	}
+	if has("composer.json") {
+		addPM("composer")
+	}
+	if has("pom.xml") {
+		addBuild("maven")
+		addTest("mvn test")
+	}
+	if has("build.gradle") || has("build.gradle.kts") {
+		addBuild("gradle")
+		if has("gradlew") {
+			addTest("./gradlew test")
+		} else {
+			addTest("gradle test")
+		}
+	}
+	if has("CMakeLists.txt") {
+		addBuild("cmake")
+	}
+	if has("MODULE.bazel") || has("WORKSPACE") || has("WORKSPACE.bazel") {
+		addBuild("bazel")
+	}
+
+	// Task runner targets are what the project itself calls its tests, so
+	// they go first.
+	var runnerTests []string
+	for _, t := range DetectTargets(dir) {
+		switch t.Runner {
+		case RunnerMake, RunnerJust, RunnerTask:
+			addBuild(string(t.Runner))
+		}
+		if t.Name == "test" || t.Name == "tests" || t.Name == "check" {
+			runnerTests = append(runnerTests, testCommand(t))
+		}
+	}
+	f.TestCommands = append(runnerTests, f.TestCommands...)
+
+	if entries, err := os.ReadDir(filepath.Join(dir, ".github", "workflows")); err == nil {
+		for _, e := range entries {
+			if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".yml" || ext == ".yaml") {
+				f.CI = append(f.CI, CIConfig{System: "GitHub Actions", Path: ".github/workflows/" + e.Name()})
+			}
+		}
+	}
+	for _, ci := range ciFiles {
+		if has(ci.Path) {
+			f.CI = append(f.CI, ci)
+		}
+	}
+
+	f.Frameworks = slices.Compact(sortedCopy(f.Frameworks))
+	return f
+}
+
+// Prompt renders the facts as a few short lines for a system prompt.
+func (f Facts) Prompt() string {
+	var sb strings.Builder
+	if len(f.Languages) > 0 {
+		total := 0
+		for _, l := range f.Languages {
+			total += l.Lines
+		}
+		parts := make([]string, 0, promptLanguages)
+		for _, l := range f.Languages[:min(len(f.Languages), promptLanguages)] {
+			pct := 0
+			if total > 0 {
+				pct = l.Lines * 100 / total
+			}
+			parts = append(parts, fmt.Sprintf("%s %d%%", l.Name, pct))
+		}
+		fmt.Fprintf(&sb, "Languages (by lines): %s\n", strings.Join(parts, ", "))
+	}
+	writeList := func(label string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", label, strings.Join(items, ", "))
+		}
+	}
+	writeList("Frameworks", f.Frameworks)
+	writeList("Build systems", f.BuildSystems)
+	writeList("Package managers", f.PackageManagers)
+	writeList("Test commands", f.TestCommands)
+	if len(f.CI) > 0 {
+		paths := make([]string, len(f.CI))
+		for i, ci := range f.CI {
+			paths[i] = fmt.Sprintf("%s (%s)", ci.Path, ci.System)
+		}
+		writeList("CI", paths)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+var (
+	factsMu    sync.Mutex
+	factsCache = map[string]Facts{}
+)
+
+// LoadFacts returns the facts for dir, detecting them once per commit.
+// Results are kept in memory and under <dataDir>/facts/<commit>.json, so
+// later sessions on the same commit skip the walk. A relative dataDir is
+// taken relative to dir. Uncommitted changes do not invalidate the cache;
+// refresh forces a new detection.
+func LoadFacts(ctx context.Context, dir, dataDir string, refresh bool) Facts {
+	commit := headCommit(ctx, dir)
+	key := dir + "@" + commit
+
+	factsMu.Lock()
+	defer factsMu.Unlock()
+	if f, ok := factsCache[key]; ok && !refresh {
+		return f
+	}
+
+	var path string
+	if commit != "" && dataDir != "" {
+		if !filepath.IsAbs(dataDir) {
+			dataDir = filepath.Join(dir, dataDir)
+		}
+		path = filepath.Join(dataDir, "facts", commit+".json")
+		if !refresh {
+			if data, err := os.ReadFile(path); err == nil {
+				var f Facts
+				if json.Unmarshal(data, &f) == nil {
+					factsCache[key] = f
+					return f
+				}
+			}
+		}
+	}
+
+	f := DetectFacts(dir)
+	f.Commit = commit
+	factsCache[key] = f
+	if path != "" {
+		if data, err := json.Marshal(f); err == nil {
+			if os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+				_ = os.WriteFile(path, data, 0o644)
+			}
+		}
+	}
+	return f
+}
+
+func headCommit(ctx context.Context, dir string) string {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// countLanguages sums lines per language, largest first. Hidden, vendored
+// and build output directories are skipped.
+func countLanguages(dir string) ([]LanguageStat, bool) {
+	stats := map[string]*LanguageStat{}
+	files := 0
+	truncated := false
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if d.IsDir() {
+			if rel != "." && fileutil.SkipHidden(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		lang, ok := languageByExt[strings.ToLower(filepath.Ext(path))]
+		if !ok || fileutil.SkipHidden(rel) {
+			return nil
+		}
+		if files >= maxFactsFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFactsFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		files++
+		s, ok := stats[lang]
+		if !ok {
+			s = &LanguageStat{Name: lang}
+			stats[lang] = s
+		}
+		s.Files++
+		s.Lines += bytes.Count(data, []byte{'\n'})
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			s.Lines++
+		}
+		return nil
+	})
+
+	langs := make([]LanguageStat, 0, len(stats))
+	for _, s := range stats {
+		langs = append(langs, *s)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].Lines != langs[j].Lines {
+			return langs[i].Lines > langs[j].Lines
+		}
+		return langs[i].Name < langs[j].Name
+	})
+	return langs, truncated
+}
+
+// npmMarkers reads dependencies and devDependencies of package.json.
+func npmMarkers(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	var names []string
+	for _, m := range npmFrameworks {
+		_, dep := pkg.Dependencies[m.needle]
+		_, dev := pkg.DevDependencies[m.needle]
+		if dep || dev {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+// manifestMarkers matches markers against the dependency names of a
+// line-oriented manifest (go.mod, Cargo.toml, requirements.txt, Gemfile,
+// ...). A line matches when a dependency name starts it, possibly after a
+// keyword or quote, and is followed by a version or delimiter.
+func manifestMarkers(path string, markers []dependencyMarker) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	found := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		for _, token := range strings.FieldsFunc(strings.ToLower(scanner.Text()), isManifestDelimiter) {
+			for _, m := range markers {
+				if token == m.needle || strings.HasPrefix(token, m.needle+"/v") {
+					found[m.name] = true
+				}
+			}
+		}
+	}
+	var names []string
+	for _, m := range markers {
+		if found[m.name] {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+func isManifestDelimiter(r rune) bool {
+	switch r {
+	case ' ', '\t', '"', '\'', '=', ',', '[', ']', '(', ')', '<', '>', '~', '^', '!', ';', ':':
+		return true
+	}
+	return false
+}
+
+// packageRunner picks the package manager whose lockfile is present,
+// falling back to npm.
+func packageRunner(dir string) RunnerKind {
+	switch {
+	case firstExisting(dir, []string{"pnpm-lock.yaml"}) != "":
+		return RunnerPNPM
+	case firstExisting(dir, []string{"yarn.lock"}) != "":
+		return RunnerYarn
+	case firstExisting(dir, []string{"bun.lockb", "bun.lock"}) != "":
+		return RunnerBun
+	}
+	return RunnerNPM
+}
+
+// testCommand is the short form a developer would type, e.g. "make test"
+// or "npm test".
+func testCommand(t Target) string {
+	switch t.Runner {
+	case RunnerNPM, RunnerPNPM, RunnerYarn:
+		if t.Name == "test" {
+			return string(t.Runner) + " test"
+		}
+		return string(t.Runner) + " run " + t.Name
+	case RunnerBun:
+		return "bun run " + t.Name
+	}
+	return string(t.Runner) + " " + t.Name
+}
+
+func appendUnique(list []string, item string) []string {
+	if slices.Contains(list, item) {
+		return list
+	}
+	return append(list, item)
+}
+
+func sortedCopy(list []string) []string {
+	out := slices.Clone(list)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,118 @@
+package project
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectFacts(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": `module example.com/app
+
+require (
+	github.com/labstack/echo/v4 v4.11.0
+	github.com/stretchr/testify v1.9.0
+)
+`,
+		"main.go":                   "package main\n\nfunc main() {}\n",
+		"internal/x/x.go":           "package x",
+		"node_modules/dep/index.js": "module.exports = 1\n",
+		"web/app.ts":                "export const a = 1\n",
+		"package.json":              `{"scripts": {"test": "vitest"}, "dependencies": {"react": "^18"}, "devDependencies": {"vitest": "^1"}}`,
+		"yarn.lock":                 "",
+		"requirements.txt":          "Django>=4.2\npytest==8.0\n",
+		"Makefile":                  "test:\n\tgo test ./...\n",
+		".github/workflows/ci.yml":  "on: push\n",
+		".gitlab-ci.yml":            "test:\n  script: make test\n",
+	})
+
+	f := DetectFacts(dir)
+
+	wantLangs := []LanguageStat{{Name: "Go", Files: 2, Lines: 4}, {Name: "TypeScript", Files: 1, Lines: 1}}
+	if !reflect.DeepEqual(f.Languages, wantLangs) {
+		t.Errorf("Languages = %+v, want %+v", f.Languages, wantLangs)
+	}
+	checks := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"Frameworks", f.Frameworks, []string{"Django", "Echo", "React", "Vitest", "pytest", "testify"}},
+		{"BuildSystems", f.BuildSystems, []string{"go", "make"}},
+		{"PackageManagers", f.PackageManagers, []string{"go modules", "yarn", "pip"}},
+		{"TestCommands", f.TestCommands, []string{"make test", "yarn test", "go test ./...", "pytest"}},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	wantCI := []CIConfig{{"GitHub Actions", ".github/workflows/ci.yml"}, {"GitLab CI", ".gitlab-ci.yml"}}
+	if !reflect.DeepEqual(f.CI, wantCI) {
+		t.Errorf("CI = %+v, want %+v", f.CI, wantCI)
+	}
+
+	prompt := f.Prompt()
+	for _, want := range []string{"Languages (by lines): Go 80%, TypeScript 20%", "Test commands: make test, yarn test", ".gitlab-ci.yml (GitLab CI)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Prompt() missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestDetectFactsEmpty(t *testing.T) {
+	f := DetectFacts(t.TempDir())
+	if got := f.Prompt(); got != "" {
+		t.Fatalf("expected empty prompt, got %q", got)
+	}
+}
+
+func TestLoadFactsCachesPerCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := writeFiles(t, map[string]string{"main.go": "package main\n"})
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	ctx := context.Background()
+	first := LoadFacts(ctx, dir, ".data", false)
+	if first.Commit == "" {
+		t.Fatal("expected the HEAD commit to be recorded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".data", "facts", first.Commit+".json")); err != nil {
+		t.Fatalf("expected an on-disk cache entry: %v", err)
+	}
+
+	// Uncommitted files don't invalidate the cache...
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadFacts(ctx, dir, ".data", false); !reflect.DeepEqual(got, first) {
+		t.Fatalf("expected cached facts, got %+v", got)
+	}
+	// ...but refresh and a new commit do.
+	if got := LoadFacts(ctx, dir, ".data", true); len(got.BuildSystems) == 0 {
+		t.Fatalf("refresh should pick up go.mod, got %+v", got)
+	}
+	git("add", "go.mod")
+	git("commit", "-q", "-m", "add go.mod")
+	second := LoadFacts(ctx, dir, ".data", false)
+	if second.Commit == first.Commit || len(second.BuildSystems) == 0 {
+		t.Fatalf("expected fresh facts for the new commit, got %+v", second)
+	}
+}
@@ -0,0 +1,234 @@
+// Package project inspects the working directory for tooling the agent can
+// use directly, such as the task runners a project already defines.
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RunnerKind identifies a task runner.
+type RunnerKind string
+
+const (
+	RunnerMake RunnerKind = "make"
+	RunnerJust RunnerKind = "just"
+	RunnerNPM  RunnerKind = "npm"
+	RunnerPNPM RunnerKind = "pnpm"
+	RunnerYarn RunnerKind = "yarn"
+	RunnerBun  RunnerKind = "bun"
+	RunnerTask RunnerKind = "task"
+)
+
+// Target is a single runnable entry of a task runner.
+type Target struct {
+	Runner RunnerKind
+	Name   string
+	// Description comes from the runner file: a doc comment for make and
+	// just, desc for Taskfile, and the script itself for package.json.
+	Description string
+}
+
+// ID is the name the agent refers to the target by: the runner prefix
+// keeps same-named targets from different runners apart.
+func (t Target) ID() string {
+	return string(t.Runner) + ":" + t.Name
+}
+
+// Command returns the shell command that runs the target with args. Every
+// argument is single-quoted so it reaches the runner verbatim.
+func (t Target) Command(args []string) string {
+	parts := []string{string(t.Runner)}
+	switch t.Runner {
+	case RunnerNPM, RunnerPNPM, RunnerYarn, RunnerBun:
+		parts = append(parts, "run", quote(t.Name))
+		if len(args) > 0 && t.Runner == RunnerNPM {
+			parts = append(parts, "--")
+		}
+	default:
+		parts = append(parts, quote(t.Name))
+	}
+	for _, a := range args {
+		parts = append(parts, quote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var (
+	makeFiles = []string{"GNUmakefile", "makefile", "Makefile"}
+	justFiles = []string{"justfile", "Justfile", ".justfile"}
+	taskFiles = []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"}
+
+	makeTargetRe = regexp.MustCompile(`^([A-Za-z0-9][\w.\-/ ]*?)\s*:([^=:]|$)`)
+	justRecipeRe = regexp.MustCompile(`^@?([A-Za-z][\w-]*)\b[^:]*:([^=]|$)`)
+	justKeywords = map[string]bool{"set": true, "alias": true, "export": true, "import": true, "mod": true}
+)
+
+// DetectTargets lists the targets of every task runner found directly in
+// dir, ordered by runner and then by name. Runner files that cannot be read
+// or parsed are skipped.
+func DetectTargets(dir string) []Target {
+	var targets []Target
+	if path := firstExisting(dir, makeFiles); path != "" {
+		targets = append(targets, parseMakefile(path)...)
+	}
+	if path := firstExisting(dir, justFiles); path != "" {
+		targets = append(targets, parseJustfile(path)...)
+	}
+	if path := firstExisting(dir, taskFiles); path != "" {
+		targets = append(targets, parseTaskfile(path)...)
+	}
+	targets = append(targets, parsePackageJSON(dir)...)
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Runner != targets[j].Runner {
+			return targets[i].Runner < targets[j].Runner
+		}
+		return targets[i].Name < targets[j].Name
+	})
+	return targets
+}
+
+func firstExisting(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// parseMakefile collects explicit targets. Special targets (.PHONY),
+// pattern rules and variable assignments are skipped. A "## text" comment
+// on the target line or a "#" comment right above it becomes the
+// description.
+func parseMakefile(path string) []Target {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var targets []Target
+	seen := make(map[string]bool)
+	comment := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			comment = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+		m := makeTargetRe.FindStringSubmatch(line)
+		if m == nil || strings.Contains(line, "%") {
+			comment = ""
+			continue
+		}
+		desc := comment
+		if i := strings.Index(line, "##"); i >= 0 {
+			desc = strings.TrimSpace(line[i+2:])
+		}
+		comment = ""
+		// "a b: deps" declares several targets at once.
+		for _, name := range strings.Fields(m[1]) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			targets = append(targets, Target{Runner: RunnerMake, Name: name, Description: desc})
+		}
+	}
+	return targets
+}
+
+// parseJustfile collects public recipes; recipes starting with an
+// underscore or marked [private] are left out.
+func parseJustfile(path string) []Target {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var targets []Target
+	comment := ""
+	private := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "#"):
+			comment = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		case strings.HasPrefix(line, "["):
+			private = private || strings.Contains(line, "private")
+			continue
+		}
+		m := justRecipeRe.FindStringSubmatch(line)
+		if m != nil && !justKeywords[m[1]] && !private {
+			targets = append(targets, Target{Runner: RunnerJust, Name: m[1], Description: comment})
+		}
+		if strings.TrimSpace(line) != "" {
+			comment = ""
+			private = false
+		}
+	}
+	return targets
+}
+
+func parseTaskfile(path string) []Target {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var file struct {
+		Tasks map[string]struct {
+			Desc     string `yaml:"desc"`
+			Internal bool   `yaml:"internal"`
+		} `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil
+	}
+	var targets []Target
+	for name, task := range file.Tasks {
+		if task.Internal {
+			continue
+		}
+		targets = append(targets, Target{Runner: RunnerTask, Name: name, Description: task.Desc})
+	}
+	return targets
+}
+
+// parsePackageJSON lists package.json scripts under the package manager
+// whose lockfile is present, falling back to npm.
+func parsePackageJSON(dir string) []Target {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	runner := packageRunner(dir)
+	var targets []Target
+	for name, script := range pkg.Scripts {
+		targets = append(targets, Target{Runner: runner, Name: name, Description: script})
+	}
+	return targets
+}
@@ -0,0 +1,112 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetectTargets(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"Makefile": `GO ?= go
+.PHONY: build test
+# Compile the binary
+build:
+	$(GO) build ./...
+test lint: build ## Run checks
+	$(GO) test ./...
+%.o: %.c
+	cc -c $<
+VERSION := 1.0
+`,
+		"justfile": `set shell := ["bash", "-c"]
+alias b := build
+
+# Build everything
+build:
+    go build ./...
+
+_helper:
+    echo hidden
+
+[private]
+secret:
+    echo hidden
+
+fmt target="./...":
+    gofmt -w {{target}}
+`,
+		"Taskfile.yml": `version: '3'
+tasks:
+  docs:
+    desc: Generate docs
+    cmds: [echo docs]
+  setup:
+    internal: true
+    cmds: [echo setup]
+`,
+		"package.json":   `{"scripts": {"dev": "vite", "test": "vitest"}}`,
+		"pnpm-lock.yaml": "",
+	})
+
+	got := DetectTargets(dir)
+	want := []Target{
+		{RunnerJust, "build", "Build everything"},
+		{RunnerJust, "fmt", ""},
+		{RunnerMake, "build", "Compile the binary"},
+		{RunnerMake, "lint", "Run checks"},
+		{RunnerMake, "test", "Run checks"},
+		{RunnerPNPM, "dev", "vite"},
+		{RunnerPNPM, "test", "vitest"},
+		{RunnerTask, "docs", "Generate docs"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d targets, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDetectTargetsEmpty(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"package.json": `{"name": "x"}`})
+	if got := DetectTargets(dir); len(got) != 0 {
+		t.Fatalf("expected no targets, got %+v", got)
+	}
+}
+
+func TestTargetCommand(t *testing.T) {
+	tests := []struct {
+		target Target
+		args   []string
+		want   string
+	}{
+		{Target{Runner: RunnerMake, Name: "test"}, nil, "make 'test'"},
+		{Target{Runner: RunnerJust, Name: "fmt"}, []string{"./cmd"}, "just 'fmt' './cmd'"},
+		{Target{Runner: RunnerNPM, Name: "test"}, []string{"--watch"}, "npm run 'test' -- '--watch'"},
+		{Target{Runner: RunnerYarn, Name: "test"}, []string{"it's"}, `yarn run 'test' 'it'\''s'`},
+		{Target{Runner: RunnerTask, Name: "docs"}, []string{"$(rm -rf /)"}, "task 'docs' '$(rm -rf /)'"},
+	}
+	for _, tt := range tests {
+		if got := tt.target.Command(tt.args); got != tt.want {
+			t.Errorf("%s.Command(%q) = %q, want %q", tt.target.ID(), tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// WorkspaceHash identifies the state of the working tree in dir. A clean
+// tree hashes to its HEAD commit; uncommitted changes, untracked files
+// included, add a digest of the diff and of the untracked files' sizes and
+// modification times. It is empty outside a git repository.
+func WorkspaceHash(ctx context.Context, dir string) string {
+	commit := headCommit(ctx, dir)
+	if commit == "" {
+		return ""
+	}
+	status, err := gitOutput(ctx, dir, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return ""
+	}
+	if len(status) == 0 {
+		return commit
+	}
+
+	h := sha256.New()
+	h.Write(status)
+	diff, err := gitOutput(ctx, dir, "diff", "HEAD", "--binary")
+	if err != nil {
+		return ""
+	}
+	h.Write(diff)
+	// Untracked files are absent from the diff; their stat stands in for
+	// their content.
+	for _, entry := range bytes.Split(status, []byte{0}) {
+		path, ok := bytes.CutPrefix(entry, []byte("?? "))
+		if !ok {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, string(path))); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return commit + "-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
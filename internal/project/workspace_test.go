@@ -0,0 +1,63 @@
+package project
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceHash(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := writeFiles(t, map[string]string{"main.go": "package main\n"})
+	ctx := context.Background()
+	if got := WorkspaceHash(ctx, dir); got != "" {
+		t.Fatalf("WorkspaceHash outside a repository = %q, want empty", got)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	clean := WorkspaceHash(ctx, dir)
+	if clean == "" || strings.Contains(clean, "-") {
+		t.Fatalf("clean hash = %q, want the bare HEAD commit", clean)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n\nfunc main() {}\n")
+	edited := WorkspaceHash(ctx, dir)
+	if edited == clean || !strings.HasPrefix(edited, clean+"-") {
+		t.Fatalf("hash after edit = %q, want %q plus a diff digest", edited, clean)
+	}
+	if again := WorkspaceHash(ctx, dir); again != edited {
+		t.Fatalf("hash of an unchanged dirty tree moved: %q then %q", edited, again)
+	}
+
+	write("notes.txt", "todo\n")
+	if untracked := WorkspaceHash(ctx, dir); untracked == edited {
+		t.Fatal("adding an untracked file did not change the hash")
+	}
+
+	os.Remove(filepath.Join(dir, "notes.txt"))
+	write("main.go", "package main\n")
+	if reverted := WorkspaceHash(ctx, dir); reverted != clean {
+		t.Fatalf("hash after reverting = %q, want %q", reverted, clean)
+	}
+}
@@ -0,0 +1,251 @@
+package runqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// pollInterval is how often the dispatcher re-reads the queue. Submissions
+// and cancellations made in-process wake it immediately; the poll picks up
+// those made by other processes sharing the database (e.g. the CLI).
+var pollInterval = time.Second
+
+// Executor runs a claimed run to completion. It is provided by the app so
+// this package stays free of agent and flow wiring.
+type Executor interface {
+	// Provider names the LLM provider a run will use; it is consulted
+	// for runs submitted without one so they still count against the
+	// right provider limit.
+	Provider(run Run) string
+	// Execute blocks until the run ends and returns the session holding
+	// its transcript. ctx is cancelled when the run is cancelled or the
+	// dispatcher stops.
+	Execute(ctx context.Context, run Run) (sessionID string, err error)
+}
+
+// Limits caps concurrently running runs. Zero or missing entries in
+// Providers and Projects are unlimited; Concurrency below 1 means 1.
+type Limits struct {
+	Concurrency int
+	Providers   map[string]int
+	Projects    map[string]int
+}
+
+// Dispatcher starts queued runs as limits allow. Run one dispatcher per
+// database: on start it re-queues every run left running, assuming the
+// process that claimed it is gone.
+type Dispatcher struct {
+	svc    *service
+	exec   Executor
+	limits func() Limits
+
+	wake chan struct{}
+
+	mu     sync.Mutex
+	active map[string]context.CancelFunc
+
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher for svc. limits is read on every
+// tick, so config reloads apply to runs not yet started.
+func NewDispatcher(svc Service, exec Executor, limits func() Limits) *Dispatcher {
+	return &Dispatcher{
+		svc:    svc.(*service),
+		exec:   exec,
+		limits: limits,
+		wake:   make(chan struct{}, 1),
+		active: make(map[string]context.CancelFunc),
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	if err := d.svc.q.RequeueRunningRuns(ctx); err != nil {
+		logging.Warn("Run queue: failed to re-queue interrupted runs", "error", err)
+	}
+	events := d.svc.Subscribe(ctx)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		d.tick(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				d.onEvent(ev)
+			case <-d.wake:
+				d.tick(ctx)
+			case <-ticker.C:
+				d.tick(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels in-flight executions and waits for them to return. Their
+// rows stay running and are re-queued by the next Start.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		if d.cancel != nil {
+			d.cancel()
+		}
+		d.wg.Wait()
+	})
+}
+
+func (d *Dispatcher) kick() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) onEvent(ev pubsub.Event[Run]) {
+	switch {
+	case ev.Type == pubsub.CreatedEvent:
+		d.kick()
+	case ev.Payload.Status == StatusCancelled:
+		d.stopRun(ev.Payload.ID)
+	}
+}
+
+func (d *Dispatcher) stopRun(id string) {
+	d.mu.Lock()
+	cancel, ok := d.active[id]
+	d.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (d *Dispatcher) provider(run Run) string {
+	if run.Provider != "" {
+		return run.Provider
+	}
+	return d.exec.Provider(run)
+}
+
+func (d *Dispatcher) tick(ctx context.Context) {
+	runs, err := d.svc.Pending(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logging.Warn("Run queue: failed to list pending runs", "error", err)
+		}
+		return
+	}
+
+	limits := d.limits()
+	if limits.Concurrency < 1 {
+		limits.Concurrency = 1
+	}
+
+	// Counts come from the database rather than d.active so runs claimed
+	// by another process still occupy their slots.
+	running := 0
+	byProvider := map[string]int{}
+	byProject := map[string]int{}
+	stillRunning := map[string]bool{}
+	for _, run := range runs {
+		if run.Status != StatusRunning {
+			continue
+		}
+		running++
+		byProvider[d.provider(run)]++
+		byProject[run.Project]++
+		stillRunning[run.ID] = true
+	}
+
+	// A local run whose row left the running state was cancelled from
+	// another process.
+	d.mu.Lock()
+	for id, cancel := range d.active {
+		if !stillRunning[id] {
+			cancel()
+		}
+	}
+	d.mu.Unlock()
+
+	for _, run := range runs {
+		if running >= limits.Concurrency {
+			return
+		}
+		if run.Status != StatusQueued {
+			continue
+		}
+		provider := d.provider(run)
+		if atLimit(limits.Providers, provider, byProvider[provider]) || atLimit(limits.Projects, run.Project, byProject[run.Project]) {
+			continue
+		}
+		claimed, err := d.svc.claim(ctx, run.ID)
+		if err != nil {
+			logging.Warn("Run queue: claim failed", "run", run.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		running++
+		byProvider[provider]++
+		byProject[run.Project]++
+		run.Status = StatusRunning
+		d.launch(ctx, run)
+	}
+}
+
+func atLimit(limits map[string]int, key string, running int) bool {
+	limit, ok := limits[key]
+	return ok && limit > 0 && running >= limit
+}
+
+func (d *Dispatcher) launch(ctx context.Context, run Run) {
+	runCtx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	d.active[run.ID] = cancel
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			d.mu.Lock()
+			delete(d.active, run.ID)
+			d.mu.Unlock()
+			cancel()
+		}()
+
+		logging.Info("Run queue: starting run", "run", run.ID, "kind", run.Kind, "target", run.Target, "priority", run.Priority)
+		sessionID, err := d.exec.Execute(runCtx, run)
+		if ctx.Err() != nil {
+			// Shutting down: leave the row running for the next Start.
+			return
+		}
+
+		status := StatusCompleted
+		switch {
+		case runCtx.Err() != nil:
+			status, err = StatusCancelled, nil
+		case err != nil:
+			status = StatusFailed
+		}
+		if _, finishErr := d.svc.finish(context.Background(), run.ID, status, sessionID, err); finishErr != nil && !errors.Is(finishErr, ErrRunNotFound) {
+			logging.Warn("Run queue: failed to record run result", "run", run.ID, "error", finishErr)
+		}
+		logging.Info("Run queue: run finished", "run", run.ID, "status", status, "session", sessionID)
+		d.kick()
+	}()
+}
@@ -0,0 +1,277 @@
+// Package runqueue persists headless runs submitted to a server so they
+// execute in priority order under per-provider and per-project
+// concurrency limits, and survive restarts.
+package runqueue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// Kind selects what a queued run executes.
+type Kind string
+
+const (
+	// KindPrompt sends Payload.Prompt to a fresh session of the agent
+	// named by Target (the active agent when empty).
+	KindPrompt Kind = "prompt"
+	// KindFlow runs the flow whose ID is Target with Payload.Args.
+	KindFlow Kind = "flow"
+)
+
+// Status is the lifecycle state of a queued run.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// DefaultListLimit caps List when the caller passes no limit.
+const DefaultListLimit = 100
+
+var (
+	ErrRunNotFound = errors.New("queued run not found")
+	// ErrRunFinished is returned when cancelling a run that already
+	// reached a terminal status.
+	ErrRunFinished = errors.New("queued run already finished")
+	ErrInvalidRun  = errors.New("invalid queued run")
+)
+
+// Payload carries the kind-specific inputs of a run.
+type Payload struct {
+	Prompt string         `json:"prompt,omitempty"`
+	Args   map[string]any `json:"args,omitempty"`
+	// Fresh discards prior flow state for KindFlow runs.
+	Fresh bool `json:"fresh,omitempty"`
+}
+
+type Run struct {
+	ID       string  `json:"id"`
+	Kind     Kind    `json:"kind"`
+	Target   string  `json:"target,omitempty"`
+	Payload  Payload `json:"payload"`
+	Priority int64   `json:"priority"`
+	// Provider and Project pick the concurrency buckets the run counts
+	// against. An empty Provider is resolved by the Executor.
+	Provider   string `json:"provider,omitempty"`
+	Project    string `json:"project,omitempty"`
+	Status     Status `json:"status"`
+	SessionID  string `json:"sessionID,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+	StartedAt  int64  `json:"startedAt,omitempty"`
+	FinishedAt int64  `json:"finishedAt,omitempty"`
+}
+
+type SubmitParams struct {
+	Kind     Kind
+	Target   string
+	Payload  Payload
+	Priority int64
+	Provider string
+	// Project defaults to the project the service was created for.
+	Project string
+}
+
+type Service interface {
+	pubsub.Suscriber[Run]
+	Submit(ctx context.Context, params SubmitParams) (Run, error)
+	Get(ctx context.Context, id string) (Run, error)
+	// List returns the most recently submitted runs of every status.
+	List(ctx context.Context, limit int) ([]Run, error)
+	// Pending returns queued and running runs in dispatch order:
+	// highest priority first, then oldest first.
+	Pending(ctx context.Context) ([]Run, error)
+	// Cancel drops a queued run, or stops a running one at the next
+	// dispatcher tick of the process executing it.
+	Cancel(ctx context.Context, id string) (Run, error)
+}
+
+type service struct {
+	*pubsub.Broker[Run]
+	q         db.Querier
+	projectID string
+}
+
+func NewService(q db.Querier, projectID string) Service {
+	return &service{
+		Broker:    pubsub.NewBroker[Run](),
+		q:         q,
+		projectID: projectID,
+	}
+}
+
+func (s *service) Submit(ctx context.Context, params SubmitParams) (Run, error) {
+	switch params.Kind {
+	case KindPrompt:
+		if params.Payload.Prompt == "" {
+			return Run{}, fmt.Errorf("%w: prompt runs need a prompt", ErrInvalidRun)
+		}
+	case KindFlow:
+		if params.Target == "" {
+			return Run{}, fmt.Errorf("%w: flow runs need a flow ID", ErrInvalidRun)
+		}
+	default:
+		return Run{}, fmt.Errorf("%w: unknown kind %q", ErrInvalidRun, params.Kind)
+	}
+	if params.Project == "" {
+		params.Project = s.projectID
+	}
+	payload, err := json.Marshal(params.Payload)
+	if err != nil {
+		return Run{}, fmt.Errorf("%w: encoding payload: %v", ErrInvalidRun, err)
+	}
+
+	// Version 7 IDs sort by creation time, which keeps runs submitted
+	// within the same second first-in first-out.
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Run{}, fmt.Errorf("failed to generate run ID: %w", err)
+	}
+	dbRun, err := s.q.CreateQueuedRun(ctx, db.CreateQueuedRunParams{
+		ID:       id.String(),
+		Kind:     string(params.Kind),
+		Target:   params.Target,
+		Payload:  string(payload),
+		Priority: params.Priority,
+		Provider: params.Provider,
+		Project:  params.Project,
+	})
+	if err != nil {
+		return Run{}, fmt.Errorf("failed to queue run: %w", err)
+	}
+	run := fromDBItem(dbRun)
+	s.Publish(pubsub.CreatedEvent, run)
+	return run, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (Run, error) {
+	dbRun, err := s.q.GetQueuedRun(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Run{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if err != nil {
+		return Run{}, fmt.Errorf("failed to get queued run: %w", err)
+	}
+	return fromDBItem(dbRun), nil
+}
+
+func (s *service) List(ctx context.Context, limit int) ([]Run, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	dbRuns, err := s.q.ListQueuedRuns(ctx, int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued runs: %w", err)
+	}
+	return fromDBItems(dbRuns), nil
+}
+
+func (s *service) Pending(ctx context.Context) ([]Run, error) {
+	dbRuns, err := s.q.ListPendingQueuedRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending runs: %w", err)
+	}
+	return fromDBItems(dbRuns), nil
+}
+
+func (s *service) Cancel(ctx context.Context, id string) (Run, error) {
+	ok, err := s.finish(ctx, id, StatusCancelled, "", nil)
+	if err != nil {
+		return Run{}, err
+	}
+	run, err := s.Get(ctx, id)
+	if err != nil {
+		return Run{}, err
+	}
+	if !ok {
+		return run, fmt.Errorf("%w: %s is %s", ErrRunFinished, id, run.Status)
+	}
+	return run, nil
+}
+
+// claim moves a queued run to running. It returns false when another
+// dispatcher claimed it first or it was cancelled meanwhile.
+func (s *service) claim(ctx context.Context, id string) (bool, error) {
+	n, err := s.q.ClaimQueuedRun(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim queued run: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	s.publishUpdated(ctx, id)
+	return true, nil
+}
+
+// finish records a terminal status. It returns false when the run had
+// already finished, which keeps a cancellation from being overwritten by
+// the result of the execution it interrupted.
+func (s *service) finish(ctx context.Context, id string, status Status, sessionID string, runErr error) (bool, error) {
+	params := db.FinishQueuedRunParams{
+		ID:        id,
+		Status:    string(status),
+		SessionID: sql.NullString{String: sessionID, Valid: sessionID != ""},
+	}
+	if runErr != nil {
+		params.Error = sql.NullString{String: runErr.Error(), Valid: true}
+	}
+	n, err := s.q.FinishQueuedRun(ctx, params)
+	if err != nil {
+		return false, fmt.Errorf("failed to finish queued run: %w", err)
+	}
+	if n == 0 {
+		if _, getErr := s.q.GetQueuedRun(ctx, id); errors.Is(getErr, sql.ErrNoRows) {
+			return false, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+		}
+		return false, nil
+	}
+	s.publishUpdated(ctx, id)
+	return true, nil
+}
+
+func (s *service) publishUpdated(ctx context.Context, id string) {
+	if run, err := s.Get(ctx, id); err == nil {
+		s.Publish(pubsub.UpdatedEvent, run)
+	}
+}
+
+func fromDBItems(items []db.QueuedRun) []Run {
+	runs := make([]Run, len(items))
+	for i, item := range items {
+		runs[i] = fromDBItem(item)
+	}
+	return runs
+}
+
+func fromDBItem(item db.QueuedRun) Run {
+	run := Run{
+		ID:         item.ID,
+		Kind:       Kind(item.Kind),
+		Target:     item.Target,
+		Priority:   item.Priority,
+		Provider:   item.Provider,
+		Project:    item.Project,
+		Status:     Status(item.Status),
+		SessionID:  item.SessionID.String,
+		Error:      item.Error.String,
+		CreatedAt:  item.CreatedAt,
+		StartedAt:  item.StartedAt.Int64,
+		FinishedAt: item.FinishedAt.Int64,
+	}
+	// A payload that no longer decodes still lets the run be listed and
+	// cancelled; execution then fails on the missing inputs.
+	_ = json.Unmarshal([]byte(item.Payload), &run.Payload)
+	return run
+}
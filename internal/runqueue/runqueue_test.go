@@ -0,0 +1,302 @@
+package runqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func newTestService(t *testing.T) *service {
+	t.Helper()
+	return NewService(db.NewTestQuerier(t), "proj").(*service)
+}
+
+// blockingExecutor records the order runs start in and holds each one
+// until released or cancelled.
+type blockingExecutor struct {
+	mu       sync.Mutex
+	started  []string
+	release  map[string]chan error
+	startedC chan string
+}
+
+func newBlockingExecutor() *blockingExecutor {
+	return &blockingExecutor{release: map[string]chan error{}, startedC: make(chan string, 16)}
+}
+
+func (e *blockingExecutor) Provider(run Run) string { return "anthropic" }
+
+func (e *blockingExecutor) Execute(ctx context.Context, run Run) (string, error) {
+	ch := make(chan error, 1)
+	e.mu.Lock()
+	e.started = append(e.started, run.Payload.Prompt)
+	e.release[run.Payload.Prompt] = ch
+	e.mu.Unlock()
+	e.startedC <- run.Payload.Prompt
+	select {
+	case err := <-ch:
+		return "sess-" + run.Payload.Prompt, err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (e *blockingExecutor) finish(prompt string, err error) {
+	e.mu.Lock()
+	ch := e.release[prompt]
+	e.mu.Unlock()
+	ch <- err
+}
+
+func (e *blockingExecutor) waitStarted(t *testing.T) string {
+	t.Helper()
+	select {
+	case p := <-e.startedC:
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a run to start")
+		return ""
+	}
+}
+
+func (e *blockingExecutor) assertIdle(t *testing.T) {
+	t.Helper()
+	select {
+	case p := <-e.startedC:
+		t.Fatalf("run %q started past the concurrency limit", p)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func waitStatus(t *testing.T, svc *service, id string, want Status) Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		run, err := svc.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("get run: %v", err)
+		}
+		if run.Status == want {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run %s status = %s, want %s", id, run.Status, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func submit(t *testing.T, svc Service, prompt string, priority int64) Run {
+	t.Helper()
+	run, err := svc.Submit(context.Background(), SubmitParams{
+		Kind:     KindPrompt,
+		Payload:  Payload{Prompt: prompt},
+		Priority: priority,
+	})
+	if err != nil {
+		t.Fatalf("submit %q: %v", prompt, err)
+	}
+	return run
+}
+
+func TestSubmitValidatesAndDefaultsProject(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Submit(ctx, SubmitParams{Kind: KindPrompt}); !errors.Is(err, ErrInvalidRun) {
+		t.Fatalf("empty prompt: err = %v, want ErrInvalidRun", err)
+	}
+	if _, err := svc.Submit(ctx, SubmitParams{Kind: KindFlow}); !errors.Is(err, ErrInvalidRun) {
+		t.Fatalf("flow without ID: err = %v, want ErrInvalidRun", err)
+	}
+	if _, err := svc.Submit(ctx, SubmitParams{Kind: "shell", Payload: Payload{Prompt: "x"}}); !errors.Is(err, ErrInvalidRun) {
+		t.Fatalf("unknown kind: err = %v, want ErrInvalidRun", err)
+	}
+
+	run, err := svc.Submit(ctx, SubmitParams{
+		Kind:    KindFlow,
+		Target:  "triage",
+		Payload: Payload{Args: map[string]any{"issue": "42"}, Fresh: true},
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	got, err := svc.Get(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != StatusQueued || got.Project != "proj" || got.Payload.Args["issue"] != "42" || !got.Payload.Fresh {
+		t.Fatalf("stored run = %+v", got)
+	}
+}
+
+func TestPendingOrdersByPriorityThenAge(t *testing.T) {
+	svc := newTestService(t)
+	low := submit(t, svc, "low", 0)
+	high := submit(t, svc, "high", 5)
+	mid := submit(t, svc, "mid", 1)
+
+	runs, err := svc.Pending(context.Background())
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	want := []string{high.ID, mid.ID, low.ID}
+	for i, run := range runs {
+		if run.ID != want[i] {
+			t.Fatalf("pending[%d] = %s (%s), want %s", i, run.ID, run.Payload.Prompt, want[i])
+		}
+	}
+}
+
+func TestCancelQueuedAndFinishedRuns(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+	run := submit(t, svc, "p", 0)
+
+	cancelled, err := svc.Cancel(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelled.Status != StatusCancelled || cancelled.FinishedAt == 0 {
+		t.Fatalf("cancelled run = %+v", cancelled)
+	}
+	if _, err := svc.Cancel(ctx, run.ID); !errors.Is(err, ErrRunFinished) {
+		t.Fatalf("second cancel: err = %v, want ErrRunFinished", err)
+	}
+	if _, err := svc.Cancel(ctx, "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("cancel missing: err = %v, want ErrRunNotFound", err)
+	}
+}
+
+func TestDispatcherRunsInPriorityOrderWithinConcurrency(t *testing.T) {
+	svc := newTestService(t)
+	submit(t, svc, "low", 0)
+	submit(t, svc, "high", 10)
+	failing := submit(t, svc, "mid", 5)
+
+	exec := newBlockingExecutor()
+	d := NewDispatcher(svc, exec, func() Limits { return Limits{Concurrency: 1} })
+	d.Start(context.Background())
+	t.Cleanup(d.Stop)
+
+	if got := exec.waitStarted(t); got != "high" {
+		t.Fatalf("first run = %q, want high", got)
+	}
+	exec.assertIdle(t)
+	exec.finish("high", nil)
+
+	if got := exec.waitStarted(t); got != "mid" {
+		t.Fatalf("second run = %q, want mid", got)
+	}
+	exec.finish("mid", errors.New("boom"))
+	failed := waitStatus(t, svc, failing.ID, StatusFailed)
+	if failed.Error != "boom" || failed.SessionID != "sess-mid" {
+		t.Fatalf("failed run = %+v", failed)
+	}
+
+	if got := exec.waitStarted(t); got != "low" {
+		t.Fatalf("third run = %q, want low", got)
+	}
+	exec.finish("low", nil)
+}
+
+func TestDispatcherHonoursProviderAndProjectLimits(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+	mustSubmit := func(prompt, provider, project string) {
+		t.Helper()
+		if _, err := svc.Submit(ctx, SubmitParams{
+			Kind: KindPrompt, Payload: Payload{Prompt: prompt}, Provider: provider, Project: project,
+		}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	mustSubmit("a1", "", "p1") // provider resolved by the executor
+	mustSubmit("a2", "", "p2")
+	mustSubmit("o1", "openai", "p1")
+	mustSubmit("o2", "openai", "p3")
+
+	exec := newBlockingExecutor()
+	d := NewDispatcher(svc, exec, func() Limits {
+		return Limits{
+			Concurrency: 10,
+			Providers:   map[string]int{"anthropic": 1},
+			Projects:    map[string]int{"p1": 1},
+		}
+	})
+	d.Start(ctx)
+	t.Cleanup(d.Stop)
+
+	started := map[string]bool{}
+	for range 2 {
+		started[exec.waitStarted(t)] = true
+	}
+	exec.assertIdle(t)
+	// a1 takes the only anthropic and p1 slots, which holds back a2
+	// (anthropic) and o1 (p1); o2 fits both buckets.
+	if !started["a1"] || !started["o2"] {
+		t.Fatalf("started = %v, want a1 and o2", started)
+	}
+
+	exec.finish("a1", nil)
+	for range 2 {
+		started[exec.waitStarted(t)] = true
+	}
+	if !started["a2"] || !started["o1"] {
+		t.Fatalf("started = %v, want a2 and o1 after a1 finished", started)
+	}
+}
+
+func TestDispatcherCancelStopsRunningRun(t *testing.T) {
+	svc := newTestService(t)
+	run := submit(t, svc, "p", 0)
+
+	exec := newBlockingExecutor()
+	d := NewDispatcher(svc, exec, func() Limits { return Limits{} })
+	d.Start(context.Background())
+	t.Cleanup(d.Stop)
+
+	exec.waitStarted(t)
+	if _, err := svc.Cancel(context.Background(), run.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	// The executor only returns once its ctx is cancelled; the row must
+	// keep the cancellation rather than be overwritten as failed.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		d.mu.Lock()
+		n := len(d.active)
+		d.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("cancelled run is still active")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitStatus(t, svc, run.ID, StatusCancelled)
+}
+
+func TestDispatcherRequeuesInterruptedRunsOnStart(t *testing.T) {
+	svc := newTestService(t)
+	run := submit(t, svc, "p", 0)
+	if ok, err := svc.claim(context.Background(), run.ID); err != nil || !ok {
+		t.Fatalf("claim: ok=%v err=%v", ok, err)
+	}
+
+	exec := newBlockingExecutor()
+	d := NewDispatcher(svc, exec, func() Limits { return Limits{} })
+	d.Start(context.Background())
+	t.Cleanup(d.Stop)
+
+	if got := exec.waitStarted(t); got != "p" {
+		t.Fatalf("started %q, want the interrupted run", got)
+	}
+	exec.finish("p", nil)
+	waitStatus(t, svc, run.ID, StatusCompleted)
+}
@@ -0,0 +1,100 @@
+// Package runtimestate persists the working context of a session that lives
+// only in process memory — the shell's directory and exported variables, the
+// todo list and running monitors — so that it survives restarting opencode,
+// for example to upgrade the binary mid-project.
+package runtimestate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/todo"
+	"github.com/opencode-ai/opencode/internal/version"
+)
+
+// FormatVersion is bumped whenever State changes incompatibly. Snapshots
+// written by a newer opencode are ignored rather than half-applied.
+const FormatVersion = 1
+
+type State struct {
+	FormatVersion int         `json:"format_version"`
+	Shell         *Shell      `json:"shell,omitempty"`
+	Todos         []todo.Item `json:"todos,omitempty"`
+	Monitors      []Monitor   `json:"monitors,omitempty"`
+}
+
+// Shell is the state of the persistent shell that bash commands run in.
+// Env only holds variables that differ from opencode's own environment.
+type Shell struct {
+	Cwd string            `json:"cwd,omitempty"`
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// Monitor is a monitor tool invocation that was still streaming.
+type Monitor struct {
+	AgentID string              `json:"agent_id"`
+	CallID  string              `json:"call_id"`
+	Params  tools.MonitorParams `json:"params"`
+}
+
+// IsEmpty reports whether s carries nothing worth restoring.
+func (s State) IsEmpty() bool {
+	return (s.Shell == nil || (s.Shell.Cwd == "" && len(s.Shell.Env) == 0)) &&
+		len(s.Todos) == 0 && len(s.Monitors) == 0
+}
+
+type Service interface {
+	// Save replaces the snapshot of a session.
+	Save(ctx context.Context, sessionID string, state State) error
+	// Load returns the snapshot of a session, or nil if there is none.
+	Load(ctx context.Context, sessionID string) (*State, error)
+	Delete(ctx context.Context, sessionID string) error
+}
+
+type service struct {
+	q db.Querier
+}
+
+func (s *service) Save(ctx context.Context, sessionID string, state State) error {
+	state.FormatVersion = FormatVersion
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.q.UpsertRuntimeSnapshot(ctx, db.UpsertRuntimeSnapshotParams{
+		SessionID: sessionID,
+		Version:   version.Version,
+		State:     string(data),
+	})
+}
+
+func (s *service) Load(ctx context.Context, sessionID string) (*State, error) {
+	r, err := s.q.GetRuntimeSnapshot(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal([]byte(r.State), &state); err != nil {
+		return nil, fmt.Errorf("decode runtime snapshot: %w", err)
+	}
+	if state.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("runtime snapshot written by opencode %s uses format %d, this build reads up to %d", r.Version, state.FormatVersion, FormatVersion)
+	}
+	return &state, nil
+}
+
+func (s *service) Delete(ctx context.Context, sessionID string) error {
+	return s.q.DeleteRuntimeSnapshot(ctx, sessionID)
+}
+
+func NewService(q db.Querier) Service {
+	return &service{q: q}
+}
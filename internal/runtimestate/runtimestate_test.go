@@ -0,0 +1,75 @@
+package runtimestate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/todo"
+)
+
+func newTestService(t *testing.T) (Service, db.Querier) {
+	t.Helper()
+	q := db.NewTestQuerier(t)
+	return NewService(q), q
+}
+
+func TestSaveLoadDelete(t *testing.T) {
+	ctx := context.Background()
+	svc, q := newTestService(t)
+	_, err := q.CreateSession(ctx, db.CreateSessionParams{ID: "s", Title: "t"})
+	require.NoError(t, err)
+
+	got, err := svc.Load(ctx, "s")
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	want := State{
+		Shell: &Shell{Cwd: "/tmp/work", Env: map[string]string{"GOFLAGS": "-mod=mod"}},
+		Todos: []todo.Item{{Content: "write tests", Status: "in_progress", Priority: "high"}},
+		Monitors: []Monitor{{
+			AgentID: "coder",
+			CallID:  "call-1",
+			Params:  tools.MonitorParams{Cmd: "tail", Args: []string{"-f", "app.log"}, Pattern: "ERROR", MinIntervalMs: 5000, MaxEvents: 200},
+		}},
+	}
+	require.NoError(t, svc.Save(ctx, "s", want))
+	// Saving again replaces the previous snapshot.
+	require.NoError(t, svc.Save(ctx, "s", want))
+
+	got, err = svc.Load(ctx, "s")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	want.FormatVersion = FormatVersion
+	require.Equal(t, want, *got)
+
+	require.NoError(t, svc.Delete(ctx, "s"))
+	got, err = svc.Load(ctx, "s")
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestLoadRejectsNewerFormat(t *testing.T) {
+	ctx := context.Background()
+	svc, q := newTestService(t)
+	_, err := q.CreateSession(ctx, db.CreateSessionParams{ID: "s", Title: "t"})
+	require.NoError(t, err)
+	require.NoError(t, q.UpsertRuntimeSnapshot(ctx, db.UpsertRuntimeSnapshotParams{
+		SessionID: "s",
+		Version:   "v9.9.9",
+		State:     `{"format_version": 99}`,
+	}))
+
+	_, err = svc.Load(ctx, "s")
+	require.ErrorContains(t, err, "v9.9.9")
+}
+
+func TestIsEmpty(t *testing.T) {
+	require.True(t, State{}.IsEmpty())
+	require.True(t, State{Shell: &Shell{}}.IsEmpty())
+	require.False(t, State{Shell: &Shell{Cwd: "/tmp"}}.IsEmpty())
+	require.False(t, State{Todos: []todo.Item{{Content: "x"}}}.IsEmpty())
+}
@@ -0,0 +1,315 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// ArchiveVersion is the layout version written by Export. Import refuses
+// archives with a newer version than it understands.
+const ArchiveVersion = 1
+
+// ErrSessionExists is returned by Import when a session in the archive
+// already exists in the target database.
+var ErrSessionExists = errors.New("session already exists")
+
+// Archive is a portable, backend-independent snapshot of a whole session
+// tree: the root session, every descendant (flow steps, task and title
+// sessions), their messages and the file history recorded for them.
+type Archive struct {
+	Version       int              `json:"version"`
+	ExportedAt    int64            `json:"exportedAt"`
+	RootSessionID string           `json:"rootSessionID"`
+	Sessions      []ArchiveSession `json:"sessions"`
+	Messages      []ArchiveMessage `json:"messages"`
+	Files         []ArchiveFile    `json:"files"`
+	// Feedback holds the user's ratings of messages in the tree. Archives
+	// written before ratings existed have none.
+	Feedback []ArchiveFeedback `json:"feedback,omitempty"`
+}
+
+type ArchiveSession struct {
+	ID                    string  `json:"id"`
+	ParentSessionID       string  `json:"parentSessionID,omitempty"`
+	RootSessionID         string  `json:"rootSessionID,omitempty"`
+	Title                 string  `json:"title"`
+	PromptTokens          int64   `json:"promptTokens"`
+	CompletionTokens      int64   `json:"completionTokens"`
+	TotalPromptTokens     int64   `json:"totalPromptTokens"`
+	TotalCompletionTokens int64   `json:"totalCompletionTokens"`
+	SummaryMessageID      string  `json:"summaryMessageID,omitempty"`
+	Cost                  float64 `json:"cost"`
+	UserSetTitle          bool    `json:"userSetTitle,omitempty"`
+	CreatedAt             int64   `json:"createdAt"`
+	UpdatedAt             int64   `json:"updatedAt"`
+}
+
+// ArchiveMessage keeps the stored parts JSON verbatim, so tool calls, tool
+// results, reasoning and attachments survive the round trip unchanged.
+type ArchiveMessage struct {
+	ID         string          `json:"id"`
+	SessionID  string          `json:"sessionID"`
+	Role       string          `json:"role"`
+	Parts      json.RawMessage `json:"parts"`
+	Model      string          `json:"model,omitempty"`
+	Seq        int64           `json:"seq,omitempty"`
+	Synthetic  bool            `json:"synthetic,omitempty"`
+	CreatedAt  int64           `json:"createdAt"`
+	UpdatedAt  int64           `json:"updatedAt"`
+	FinishedAt int64           `json:"finishedAt,omitempty"`
+}
+
+type ArchiveFile struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionID"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Version   string `json:"version"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+type ArchiveFeedback struct {
+	MessageID string `json:"messageID"`
+	SessionID string `json:"sessionID"`
+	Rating    string `json:"rating"`
+	Comment   string `json:"comment,omitempty"`
+	AgentID   string `json:"agentID,omitempty"`
+	Model     string `json:"model,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// Export collects the session tree containing id. Any session in the tree
+// may be passed; the archive always starts from the root.
+func (s *service) Export(ctx context.Context, id string) (Archive, error) {
+	sess, err := s.q.GetSessionByID(ctx, id)
+	if err != nil {
+		return Archive{}, err
+	}
+	rootID := sess.ID
+	if sess.RootSessionID.Valid && sess.RootSessionID.String != "" {
+		rootID = sess.RootSessionID.String
+	}
+	root := sess
+	if rootID != sess.ID {
+		if root, err = s.q.GetSessionByID(ctx, rootID); err != nil {
+			return Archive{}, fmt.Errorf("failed to load root session %s: %w", rootID, err)
+		}
+	}
+	children, err := s.q.ListChildSessions(ctx, sql.NullString{String: rootID, Valid: true})
+	if err != nil {
+		return Archive{}, err
+	}
+
+	archive := Archive{
+		Version:       ArchiveVersion,
+		ExportedAt:    time.Now().Unix(),
+		RootSessionID: rootID,
+	}
+	tree := []db.Session{root}
+	for _, child := range children {
+		if child.ID != rootID {
+			tree = append(tree, child)
+		}
+	}
+	for _, item := range tree {
+		archive.Sessions = append(archive.Sessions, toArchiveSession(item))
+
+		msgs, err := s.q.ListMessagesBySession(ctx, item.ID)
+		if err != nil {
+			return Archive{}, fmt.Errorf("failed to list messages for session %s: %w", item.ID, err)
+		}
+		for _, m := range msgs {
+			archive.Messages = append(archive.Messages, ArchiveMessage{
+				ID:         m.ID,
+				SessionID:  m.SessionID,
+				Role:       m.Role,
+				Parts:      json.RawMessage(m.Parts),
+				Model:      m.Model.String,
+				Seq:        m.Seq.Int64,
+				Synthetic:  m.Synthetic,
+				CreatedAt:  m.CreatedAt,
+				UpdatedAt:  m.UpdatedAt,
+				FinishedAt: m.FinishedAt.Int64,
+			})
+		}
+
+		files, err := s.q.ListFilesBySession(ctx, item.ID)
+		if err != nil {
+			return Archive{}, fmt.Errorf("failed to list files for session %s: %w", item.ID, err)
+		}
+		for _, f := range files {
+			archive.Files = append(archive.Files, ArchiveFile{
+				ID:        f.ID,
+				SessionID: f.SessionID,
+				Path:      f.Path,
+				Content:   f.Content,
+				Version:   f.Version,
+				CreatedAt: f.CreatedAt,
+				UpdatedAt: f.UpdatedAt,
+			})
+		}
+
+		feedback, err := s.q.ListMessageFeedback(ctx, item.ID)
+		if err != nil {
+			return Archive{}, fmt.Errorf("failed to list feedback for session %s: %w", item.ID, err)
+		}
+		for _, f := range feedback {
+			archive.Feedback = append(archive.Feedback, ArchiveFeedback(f))
+		}
+	}
+	return archive, nil
+}
+
+// Import recreates an exported session tree under the current project,
+// keeping every ID so references between sessions, messages and summaries
+// stay intact. Row timestamps are assigned by the target database. On
+// failure the partially imported tree is removed again.
+func (s *service) Import(ctx context.Context, archive Archive) (Session, error) {
+	if archive.Version < 1 || archive.Version > ArchiveVersion {
+		return Session{}, fmt.Errorf("unsupported session archive version %d", archive.Version)
+	}
+	if len(archive.Sessions) == 0 || archive.Sessions[0].ID != archive.RootSessionID {
+		return Session{}, errors.New("session archive must start with its root session")
+	}
+	for _, item := range archive.Sessions {
+		_, err := s.q.GetSessionByID(ctx, item.ID)
+		if err == nil {
+			return Session{}, fmt.Errorf("%w: %s", ErrSessionExists, item.ID)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return Session{}, err
+		}
+	}
+
+	if err := s.importTree(ctx, archive); err != nil {
+		rootID := archive.RootSessionID
+		if cleanupErr := s.q.DeleteSessionTree(ctx, db.DeleteSessionTreeParams{
+			ID:            rootID,
+			RootSessionID: sql.NullString{String: rootID, Valid: true},
+		}); cleanupErr != nil {
+			return Session{}, errors.Join(err, fmt.Errorf("failed to roll back import: %w", cleanupErr))
+		}
+		return Session{}, err
+	}
+
+	root, err := s.Get(ctx, archive.RootSessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	s.Publish(pubsub.CreatedEvent, root)
+	return root, nil
+}
+
+func (s *service) importTree(ctx context.Context, archive Archive) error {
+	for _, item := range archive.Sessions {
+		if _, err := s.q.CreateSession(ctx, db.CreateSessionParams{
+			ID:               item.ID,
+			ProjectID:        sql.NullString{String: s.projectID, Valid: true},
+			ParentSessionID:  sql.NullString{String: item.ParentSessionID, Valid: item.ParentSessionID != ""},
+			RootSessionID:    sql.NullString{String: item.RootSessionID, Valid: item.RootSessionID != ""},
+			Title:            item.Title,
+			PromptTokens:     item.PromptTokens,
+			CompletionTokens: item.CompletionTokens,
+			Cost:             item.Cost,
+		}); err != nil {
+			return fmt.Errorf("failed to create session %s: %w", item.ID, err)
+		}
+	}
+
+	for _, m := range archive.Messages {
+		var parts bytes.Buffer
+		if err := json.Compact(&parts, m.Parts); err != nil {
+			return fmt.Errorf("invalid parts in message %s: %w", m.ID, err)
+		}
+		if _, err := s.q.CreateMessage(ctx, db.CreateMessageParams{
+			ID:        m.ID,
+			SessionID: m.SessionID,
+			Role:      m.Role,
+			Parts:     parts.String(),
+			Model:     sql.NullString{String: m.Model, Valid: m.Model != ""},
+			Seq:       sql.NullInt64{Int64: m.Seq, Valid: m.Seq != 0},
+			Synthetic: m.Synthetic,
+		}); err != nil {
+			return fmt.Errorf("failed to create message %s: %w", m.ID, err)
+		}
+		if m.FinishedAt != 0 {
+			if err := s.q.UpdateMessage(ctx, db.UpdateMessageParams{
+				ID:         m.ID,
+				Parts:      parts.String(),
+				FinishedAt: sql.NullInt64{Int64: m.FinishedAt, Valid: true},
+			}); err != nil {
+				return fmt.Errorf("failed to finish message %s: %w", m.ID, err)
+			}
+		}
+	}
+
+	for _, f := range archive.Files {
+		if _, err := s.q.CreateFile(ctx, db.CreateFileParams{
+			ID:        f.ID,
+			SessionID: f.SessionID,
+			Path:      f.Path,
+			Content:   f.Content,
+			Version:   f.Version,
+		}); err != nil {
+			return fmt.Errorf("failed to create file version %s: %w", f.ID, err)
+		}
+	}
+
+	// Ratings keep their timestamps: they say when the user judged a reply.
+	for _, f := range archive.Feedback {
+		if err := s.q.UpsertMessageFeedback(ctx, db.UpsertMessageFeedbackParams(f)); err != nil {
+			return fmt.Errorf("failed to restore feedback on message %s: %w", f.MessageID, err)
+		}
+	}
+
+	// Totals and the summary pointer can only be set once the messages
+	// they refer to exist; the user-renamed flag goes through Rename.
+	for _, item := range archive.Sessions {
+		if _, err := s.q.UpdateSession(ctx, db.UpdateSessionParams{
+			ID:                    item.ID,
+			Title:                 item.Title,
+			PromptTokens:          item.PromptTokens,
+			CompletionTokens:      item.CompletionTokens,
+			TotalPromptTokens:     item.TotalPromptTokens,
+			TotalCompletionTokens: item.TotalCompletionTokens,
+			SummaryMessageID:      sql.NullString{String: item.SummaryMessageID, Valid: item.SummaryMessageID != ""},
+			Cost:                  item.Cost,
+		}); err != nil {
+			return fmt.Errorf("failed to update session %s: %w", item.ID, err)
+		}
+		if item.UserSetTitle {
+			if _, err := s.q.RenameSession(ctx, db.RenameSessionParams{ID: item.ID, Title: item.Title}); err != nil {
+				return fmt.Errorf("failed to restore title of session %s: %w", item.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func toArchiveSession(item db.Session) ArchiveSession {
+	return ArchiveSession{
+		ID:                    item.ID,
+		ParentSessionID:       item.ParentSessionID.String,
+		RootSessionID:         item.RootSessionID.String,
+		Title:                 item.Title,
+		PromptTokens:          item.PromptTokens,
+		CompletionTokens:      item.CompletionTokens,
+		TotalPromptTokens:     item.TotalPromptTokens,
+		TotalCompletionTokens: item.TotalCompletionTokens,
+		SummaryMessageID:      item.SummaryMessageID.String,
+		Cost:                  item.Cost,
+		UserSetTitle:          item.UserSetTitle,
+		CreatedAt:             item.CreatedAt,
+		UpdatedAt:             item.UpdatedAt,
+	}
+}
@@ -0,0 +1,124 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newTestService(t).(*service)
+
+	root, err := src.Create(ctx, "Root")
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	task, err := src.CreateTaskSession(ctx, "call-1", root.ID, "Task")
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	for i, sid := range []string{root.ID, root.ID, task.ID} {
+		if _, err := src.q.CreateMessage(ctx, db.CreateMessageParams{
+			ID:        sid + "-msg-" + string(rune('a'+i)),
+			SessionID: sid,
+			Role:      "user",
+			Parts:     `[{"type":"text","data":{"text":"hello"}}]`,
+			Seq:       sql.NullInt64{Int64: int64(i + 1), Valid: true},
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+	if _, err := src.q.CreateFile(ctx, db.CreateFileParams{
+		ID: "file-1", SessionID: task.ID, Path: "/repo/main.go", Content: "package main", Version: "initial",
+	}); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	if err := src.q.UpsertMessageFeedback(ctx, db.UpsertMessageFeedbackParams{
+		MessageID: task.ID + "-msg-c", SessionID: task.ID, Rating: "down", Comment: "wrong file", AgentID: "coder", CreatedAt: 1, UpdatedAt: 1,
+	}); err != nil {
+		t.Fatalf("rate message: %v", err)
+	}
+	root.Cost = 1.25
+	root.TotalPromptTokens = 42
+	if _, err := src.Save(ctx, root); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := src.Rename(ctx, root.ID, "Renamed"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	// Export from the task session: the archive must still cover the tree.
+	archive, err := src.Export(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if archive.RootSessionID != root.ID || len(archive.Sessions) != 2 || len(archive.Messages) != 3 || len(archive.Files) != 1 || len(archive.Feedback) != 1 {
+		t.Fatalf("archive = root %q, %d sessions, %d messages, %d files, %d ratings",
+			archive.RootSessionID, len(archive.Sessions), len(archive.Messages), len(archive.Files), len(archive.Feedback))
+	}
+
+	data, err := json.Marshal(archive)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded Archive
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	dst := newTestService(t).(*service)
+	imported, err := dst.Import(ctx, decoded)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if imported.ID != root.ID || imported.Title != "Renamed" || !imported.UserSetTitle {
+		t.Errorf("imported root = {%q, %q, userSet=%v}", imported.ID, imported.Title, imported.UserSetTitle)
+	}
+	if imported.Cost != 1.25 || imported.TotalPromptTokens != 42 || imported.MessageCount != 2 {
+		t.Errorf("imported totals = cost %v, prompt %d, messages %d", imported.Cost, imported.TotalPromptTokens, imported.MessageCount)
+	}
+	gotTask, err := dst.Get(ctx, task.ID)
+	if err != nil || gotTask.ParentSessionID != root.ID || gotTask.RootSessionID != root.ID {
+		t.Fatalf("imported task = %+v, err %v", gotTask, err)
+	}
+	files, err := dst.q.ListFilesBySession(ctx, task.ID)
+	if err != nil || len(files) != 1 || files[0].Content != "package main" {
+		t.Fatalf("files = %+v, err %v", files, err)
+	}
+	rating, err := dst.q.GetMessageFeedback(ctx, task.ID+"-msg-c")
+	if err != nil || rating.Rating != "down" || rating.Comment != "wrong file" {
+		t.Fatalf("feedback = %+v, err %v", rating, err)
+	}
+
+	if _, err := dst.Import(ctx, decoded); !errors.Is(err, ErrSessionExists) {
+		t.Fatalf("second import error = %v, want ErrSessionExists", err)
+	}
+}
+
+func TestImportRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	archive := Archive{
+		Version:       ArchiveVersion,
+		RootSessionID: "root",
+		Sessions:      []ArchiveSession{{ID: "root", Title: "Root"}},
+		Messages:      []ArchiveMessage{{ID: "m1", SessionID: "root", Role: "user", Parts: json.RawMessage(`{not json`)}},
+	}
+	if _, err := svc.Import(ctx, archive); err == nil {
+		t.Fatal("expected import with malformed parts to fail")
+	}
+	if _, err := svc.Get(ctx, "root"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("partially imported session left behind: err = %v", err)
+	}
+
+	archive.Version = ArchiveVersion + 1
+	if _, err := svc.Import(ctx, archive); err == nil {
+		t.Fatal("expected newer archive version to be rejected")
+	}
+}
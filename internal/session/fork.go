@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// ErrMessageNotFound is returned by Fork when the message to fork at is not
+// part of the session.
+var ErrMessageNotFound = errors.New("message not found in session")
+
+// Fork starts a new root session whose history is a copy of the session's
+// messages up to and including messageID. The original is left untouched,
+// so both conversations can go on independently. Messages get new IDs and
+// keep their parts verbatim; the sessions spawned by task tool calls are
+// not copied, and the fork starts with no spend of its own. On failure the
+// partially created fork is removed again.
+func (s *service) Fork(ctx context.Context, sessionID, messageID string) (Session, error) {
+	src, err := s.q.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	msgs, err := s.q.ListMessagesBySession(ctx, sessionID)
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to list messages for session %s: %w", sessionID, err)
+	}
+	end := -1
+	for i, m := range msgs {
+		if m.ID == messageID {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return Session{}, fmt.Errorf("%w: %s", ErrMessageNotFound, messageID)
+	}
+
+	forkID := uuid.New().String()
+	if err := s.copyHistory(ctx, forkID, "Fork of "+src.Title, src.SummaryMessageID.String, msgs[:end+1]); err != nil {
+		if cleanupErr := s.q.DeleteSessionTree(ctx, db.DeleteSessionTreeParams{
+			ID:            forkID,
+			RootSessionID: sql.NullString{String: forkID, Valid: true},
+		}); cleanupErr != nil {
+			return Session{}, errors.Join(err, fmt.Errorf("failed to roll back fork: %w", cleanupErr))
+		}
+		return Session{}, err
+	}
+
+	fork, err := s.Get(ctx, forkID)
+	if err != nil {
+		return Session{}, err
+	}
+	s.Publish(pubsub.CreatedEvent, fork)
+	return fork, nil
+}
+
+func (s *service) copyHistory(ctx context.Context, forkID, title, summaryMessageID string, msgs []db.Message) error {
+	if _, err := s.q.CreateSession(ctx, db.CreateSessionParams{
+		ID:        forkID,
+		ProjectID: sql.NullString{String: s.projectID, Valid: true},
+		Title:     title,
+	}); err != nil {
+		return fmt.Errorf("failed to create session %s: %w", forkID, err)
+	}
+
+	// A summary past the fork point covers messages the fork does not
+	// have, so the fork then starts from its full history instead.
+	summaryID := ""
+	for _, m := range msgs {
+		id := uuid.New().String()
+		if m.ID == summaryMessageID {
+			summaryID = id
+		}
+		if _, err := s.q.CreateMessage(ctx, db.CreateMessageParams{
+			ID:        id,
+			SessionID: forkID,
+			Role:      m.Role,
+			Parts:     m.Parts,
+			Model:     m.Model,
+			Seq:       m.Seq,
+			Synthetic: m.Synthetic,
+		}); err != nil {
+			return fmt.Errorf("failed to copy message %s: %w", m.ID, err)
+		}
+		if m.FinishedAt.Valid {
+			if err := s.q.UpdateMessage(ctx, db.UpdateMessageParams{
+				ID:         id,
+				Parts:      m.Parts,
+				FinishedAt: m.FinishedAt,
+			}); err != nil {
+				return fmt.Errorf("failed to finish message %s: %w", id, err)
+			}
+		}
+	}
+
+	if summaryID != "" {
+		if _, err := s.q.UpdateSession(ctx, db.UpdateSessionParams{
+			ID:               forkID,
+			Title:            title,
+			SummaryMessageID: sql.NullString{String: summaryID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to update session %s: %w", forkID, err)
+		}
+	}
+	return nil
+}
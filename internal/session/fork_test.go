@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+func TestFork(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t).(*service)
+
+	src, err := svc.Create(ctx, "Original")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ids := []string{"m1", "m2", "m3", "m4"}
+	for i, id := range ids {
+		if _, err := svc.q.CreateMessage(ctx, db.CreateMessageParams{
+			ID:        id,
+			SessionID: src.ID,
+			Role:      "user",
+			Parts:     `[{"type":"text","data":{"text":"` + id + `"}}]`,
+			Seq:       sql.NullInt64{Int64: int64(i + 1), Valid: true},
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+	src.SummaryMessageID = "m2"
+	src.Cost = 3
+	if _, err := svc.Save(ctx, src); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	fork, err := svc.Fork(ctx, src.ID, "m3")
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	if fork.ID == src.ID || fork.Title != "Fork of Original" || fork.ParentSessionID != "" || fork.Cost != 0 {
+		t.Errorf("fork = {%q, %q, parent %q, cost %v}", fork.ID, fork.Title, fork.ParentSessionID, fork.Cost)
+	}
+	msgs, err := svc.q.ListMessagesBySession(ctx, fork.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("fork has %d messages, want 3", len(msgs))
+	}
+	for i, m := range msgs {
+		if m.ID == ids[i] || m.Parts != `[{"type":"text","data":{"text":"`+ids[i]+`"}}]` {
+			t.Errorf("message %d = {%q, %s}", i, m.ID, m.Parts)
+		}
+	}
+	if fork.SummaryMessageID != msgs[1].ID {
+		t.Errorf("summary = %q, want the copy of m2 %q", fork.SummaryMessageID, msgs[1].ID)
+	}
+
+	// The original keeps its whole history.
+	orig, err := svc.q.ListMessagesBySession(ctx, src.ID)
+	if err != nil || len(orig) != 4 {
+		t.Fatalf("original has %d messages, err %v", len(orig), err)
+	}
+
+	// Forking before the summary drops it.
+	early, err := svc.Fork(ctx, src.ID, "m1")
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	if early.SummaryMessageID != "" || early.MessageCount != 1 {
+		t.Errorf("early fork = summary %q, %d messages", early.SummaryMessageID, early.MessageCount)
+	}
+}
+
+func TestForkUnknownMessage(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	src, err := svc.Create(ctx, "Original")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Fork(ctx, src.ID, "missing"); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("fork err = %v, want ErrMessageNotFound", err)
+	}
+	all, err := svc.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 1 {
+		t.Errorf("%d sessions after failed fork, want 1", len(all))
+	}
+}
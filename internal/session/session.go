@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -60,6 +61,28 @@ type Service interface {
 	DeleteTree(ctx context.Context, id string) error
 	ListOldSessions(ctx context.Context, activeSessionID string) ([]Session, error)
 	CleanupOldSessions(ctx context.Context, activeSessionID string) (int, error)
+	// TreeUsage sums the spend of the whole tree containing id.
+	TreeUsage(ctx context.Context, id string) (Usage, error)
+	// ProjectUsage sums the spend of every session in the project.
+	ProjectUsage(ctx context.Context) (Usage, error)
+	// RecordUsage adds one request's spend to the session's per-agent and
+	// per-tool totals.
+	RecordUsage(ctx context.Context, sessionID string, entries ...UsageEntry) error
+	// UsageBreakdown lists the spend of the whole tree containing id by
+	// agent, model and tool.
+	UsageBreakdown(ctx context.Context, id string) ([]UsageEntry, error)
+	// RecordMessageUsage keeps the usage of one model request. Unlike the
+	// session totals these rows outlive the session.
+	RecordMessageUsage(ctx context.Context, u MessageUsage) error
+	// ListMessageUsage lists the project's requests between since and until.
+	ListMessageUsage(ctx context.Context, since, until time.Time) ([]MessageUsage, error)
+	// Export snapshots the whole tree containing id into a portable archive.
+	Export(ctx context.Context, id string) (Archive, error)
+	// Import recreates an exported tree in this database and returns its root.
+	Import(ctx context.Context, archive Archive) (Session, error)
+	// Fork copies the session's history up to and including messageID into
+	// a new root session and returns it.
+	Fork(ctx context.Context, sessionID, messageID string) (Session, error)
 }
 
 type service struct {
@@ -302,6 +325,61 @@ func (s *service) ListChildren(ctx context.Context, rootSessionID string) ([]Ses
 	return sessions, nil
 }
 
+// Usage is the cost and token spend recorded on one or more sessions.
+type Usage struct {
+	Cost   float64
+	Tokens int64
+}
+
+func (s *service) TreeUsage(ctx context.Context, id string) (Usage, error) {
+	tree, err := s.treeSessions(ctx, id)
+	if err != nil {
+		return Usage{}, err
+	}
+	var usage Usage
+	for _, c := range tree {
+		usage.Cost += c.Cost
+		usage.Tokens += c.TotalPromptTokens + c.TotalCompletionTokens
+	}
+	return usage, nil
+}
+
+// treeSessions returns every session of the tree containing id.
+func (s *service) treeSessions(ctx context.Context, id string) ([]Session, error) {
+	sess, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rootID := sess.ID
+	if sess.RootSessionID != "" {
+		rootID = sess.RootSessionID
+	}
+	tree, err := s.ListChildren(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	// Sessions created before root tracking have no root_session_id and
+	// are missing from their own tree listing.
+	if !slices.ContainsFunc(tree, func(c Session) bool { return c.ID == rootID }) {
+		root := sess
+		if rootID != sess.ID {
+			if root, err = s.Get(ctx, rootID); err != nil {
+				return nil, err
+			}
+		}
+		tree = append(tree, root)
+	}
+	return tree, nil
+}
+
+func (s *service) ProjectUsage(ctx context.Context) (Usage, error) {
+	row, err := s.q.GetProjectUsage(ctx, sql.NullString{String: s.projectID, Valid: true})
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{Cost: row.Cost, Tokens: row.Tokens}, nil
+}
+
 func (s service) fromDBItem(item db.Session) Session {
 	return Session{
 		ID:                    item.ID,
@@ -8,8 +8,6 @@ import (
 
 	"github.com/opencode-ai/opencode/internal/db"
 	"github.com/opencode-ai/opencode/internal/pubsub"
-
-	"github.com/pressly/goose/v3"
 )
 
 // newTestService builds a session Service backed by a real, migrated SQLite
@@ -17,23 +15,7 @@ import (
 // guarded UPDATE in SetGeneratedTitle — rather than a stubbed Querier.
 func newTestService(t *testing.T) Service {
 	t.Helper()
-	provider := db.NewSQLiteProvider(t.TempDir())
-	sqlDB, err := provider.Connect()
-	if err != nil {
-		t.Fatalf("connect sqlite: %v", err)
-	}
-	t.Cleanup(func() { _ = sqlDB.Close() })
-
-	goose.SetBaseFS(db.FS)
-	if err := goose.SetDialect("sqlite3"); err != nil {
-		t.Fatalf("goose dialect: %v", err)
-	}
-	if err := goose.Up(sqlDB, "migrations/sqlite"); err != nil {
-		t.Fatalf("goose up: %v", err)
-	}
-	// db.New yields the SQLite-backed Queries directly; db.NewQuerier would
-	// need a global config to pick a provider (nil in tests).
-	return NewService(db.New(sqlDB), "test-project")
+	return NewService(db.NewTestQuerier(t), "test-project")
 }
 
 func TestRename(t *testing.T) {
@@ -180,3 +162,49 @@ func drain(ch <-chan pubsub.Event[Session]) {
 		}
 	}
 }
+
+func TestTreeAndProjectUsage(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+
+	root, err := svc.Create(ctx, "Root")
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	task, err := svc.CreateTaskSession(ctx, "call-1", root.ID, "Task")
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	other, err := svc.Create(ctx, "Other")
+	if err != nil {
+		t.Fatalf("create other: %v", err)
+	}
+	for _, u := range []struct {
+		sess   Session
+		cost   float64
+		prompt int64
+	}{{root, 1.0, 100}, {task, 0.5, 50}, {other, 2.0, 1000}} {
+		u.sess.Cost = u.cost
+		u.sess.TotalPromptTokens = u.prompt
+		u.sess.TotalCompletionTokens = 10
+		if _, err := svc.Save(ctx, u.sess); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	tree, err := svc.TreeUsage(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("TreeUsage: %v", err)
+	}
+	if tree.Cost != 1.5 || tree.Tokens != 170 {
+		t.Errorf("tree usage = %+v, want cost 1.5 and 170 tokens", tree)
+	}
+
+	project, err := svc.ProjectUsage(ctx)
+	if err != nil {
+		t.Fatalf("ProjectUsage: %v", err)
+	}
+	if project.Cost != 3.5 || project.Tokens != 1180 {
+		t.Errorf("project usage = %+v, want cost 3.5 and 1180 tokens", project)
+	}
+}
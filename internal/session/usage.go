@@ -0,0 +1,234 @@
+package session
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+)
+
+// UsageCategory says what a UsageEntry is attributed to.
+type UsageCategory string
+
+const (
+	// UsageAgent entries carry the billed usage of an agent's requests.
+	UsageAgent UsageCategory = "agent"
+	// UsageTool entries estimate the input tokens a tool's earlier results
+	// took up in later requests. They break down part of the agents' input
+	// spend and are not billed on top of it.
+	UsageTool UsageCategory = "tool"
+)
+
+// UsageEntry is the spend attributed to one agent or tool on one model.
+type UsageEntry struct {
+	Category            UsageCategory `json:"category"`
+	Name                string        `json:"name"`
+	Model               string        `json:"model"`
+	Requests            int64         `json:"requests"`
+	InputTokens         int64         `json:"input_tokens"`
+	OutputTokens        int64         `json:"output_tokens"`
+	CacheCreationTokens int64         `json:"cache_creation_tokens"`
+	CacheReadTokens     int64         `json:"cache_read_tokens"`
+	Cost                float64       `json:"cost"`
+}
+
+func (s *service) RecordUsage(ctx context.Context, sessionID string, entries ...UsageEntry) error {
+	for _, e := range entries {
+		if err := s.q.AddSessionUsage(ctx, db.AddSessionUsageParams{
+			SessionID:           sessionID,
+			Category:            string(e.Category),
+			Name:                e.Name,
+			Model:               e.Model,
+			Requests:            e.Requests,
+			InputTokens:         e.InputTokens,
+			OutputTokens:        e.OutputTokens,
+			CacheCreationTokens: e.CacheCreationTokens,
+			CacheReadTokens:     e.CacheReadTokens,
+			Cost:                e.Cost,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UsageBreakdown merges the entries of every session in the tree, so a
+// subagent's spend shows under its own name rather than the task tool that
+// started it. Entries are ordered by category, then by cost, highest first.
+func (s *service) UsageBreakdown(ctx context.Context, id string) ([]UsageEntry, error) {
+	tree, err := s.treeSessions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	type key struct {
+		category UsageCategory
+		name     string
+		model    string
+	}
+	merged := map[key]*UsageEntry{}
+	for _, sess := range tree {
+		rows, err := s.q.ListSessionUsage(ctx, sess.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			k := key{UsageCategory(r.Category), r.Name, r.Model}
+			e, ok := merged[k]
+			if !ok {
+				e = &UsageEntry{Category: k.category, Name: k.name, Model: k.model}
+				merged[k] = e
+			}
+			e.Requests += r.Requests
+			e.InputTokens += r.InputTokens
+			e.OutputTokens += r.OutputTokens
+			e.CacheCreationTokens += r.CacheCreationTokens
+			e.CacheReadTokens += r.CacheReadTokens
+			e.Cost += r.Cost
+		}
+	}
+
+	entries := make([]UsageEntry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, *e)
+	}
+	slices.SortFunc(entries, func(a, b UsageEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.Category, b.Category),
+			cmp.Compare(b.Cost, a.Cost),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Model, b.Model),
+		)
+	})
+	return entries, nil
+}
+
+// MessageUsage is the usage of one model request: an agent turn, a
+// summary or a translation.
+type MessageUsage struct {
+	MessageID           string    `json:"message_id,omitempty"`
+	SessionID           string    `json:"session_id"`
+	AgentID             string    `json:"agent_id"`
+	Model               string    `json:"model"`
+	InputTokens         int64     `json:"input_tokens"`
+	OutputTokens        int64     `json:"output_tokens"`
+	CacheCreationTokens int64     `json:"cache_creation_tokens"`
+	CacheReadTokens     int64     `json:"cache_read_tokens"`
+	Cost                float64   `json:"cost"`
+	DurationMs          int64     `json:"duration_ms"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+func (s *service) RecordMessageUsage(ctx context.Context, u MessageUsage) error {
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+	return s.q.CreateMessageUsage(ctx, db.CreateMessageUsageParams{
+		MessageID:           u.MessageID,
+		SessionID:           u.SessionID,
+		ProjectID:           s.projectID,
+		AgentID:             u.AgentID,
+		Model:               u.Model,
+		InputTokens:         u.InputTokens,
+		OutputTokens:        u.OutputTokens,
+		CacheCreationTokens: u.CacheCreationTokens,
+		CacheReadTokens:     u.CacheReadTokens,
+		Cost:                u.Cost,
+		DurationMs:          u.DurationMs,
+		CreatedAt:           u.CreatedAt.UnixMilli(),
+	})
+}
+
+// ListMessageUsage returns the project's requests made between since and
+// until, oldest first. A zero bound leaves that side open.
+func (s *service) ListMessageUsage(ctx context.Context, since, until time.Time) ([]MessageUsage, error) {
+	params := db.ListMessageUsageParams{ProjectID: s.projectID, Until: 1<<63 - 1}
+	if !since.IsZero() {
+		params.Since = since.UnixMilli()
+	}
+	if !until.IsZero() {
+		params.Until = until.UnixMilli()
+	}
+	rows, err := s.q.ListMessageUsage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]MessageUsage, len(rows))
+	for i, r := range rows {
+		usage[i] = MessageUsage{
+			MessageID:           r.MessageID,
+			SessionID:           r.SessionID,
+			AgentID:             r.AgentID,
+			Model:               r.Model,
+			InputTokens:         r.InputTokens,
+			OutputTokens:        r.OutputTokens,
+			CacheCreationTokens: r.CacheCreationTokens,
+			CacheReadTokens:     r.CacheReadTokens,
+			Cost:                r.Cost,
+			DurationMs:          r.DurationMs,
+			CreatedAt:           time.UnixMilli(r.CreatedAt),
+		}
+	}
+	return usage, nil
+}
+
+// UsageGroup sums the requests that share a day, model and agent. Keys a
+// report doesn't group by are left empty.
+type UsageGroup struct {
+	Day                 string  `json:"day,omitempty"`
+	Model               string  `json:"model,omitempty"`
+	Agent               string  `json:"agent,omitempty"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Cost                float64 `json:"cost"`
+	DurationMs          int64   `json:"duration_ms"`
+}
+
+// GroupMessageUsage sums usage by local day, model and agent, each only
+// when asked for. Groups are ordered by day, then by cost, highest first.
+func GroupMessageUsage(usage []MessageUsage, byDay, byModel, byAgent bool) []UsageGroup {
+	type key struct{ day, model, agent string }
+	merged := map[key]*UsageGroup{}
+	for _, u := range usage {
+		var k key
+		if byDay {
+			k.day = u.CreatedAt.Local().Format(time.DateOnly)
+		}
+		if byModel {
+			k.model = u.Model
+		}
+		if byAgent {
+			k.agent = u.AgentID
+		}
+		g, ok := merged[k]
+		if !ok {
+			g = &UsageGroup{Day: k.day, Model: k.model, Agent: k.agent}
+			merged[k] = g
+		}
+		g.Requests++
+		g.InputTokens += u.InputTokens
+		g.OutputTokens += u.OutputTokens
+		g.CacheCreationTokens += u.CacheCreationTokens
+		g.CacheReadTokens += u.CacheReadTokens
+		g.Cost += u.Cost
+		g.DurationMs += u.DurationMs
+	}
+
+	groups := make([]UsageGroup, 0, len(merged))
+	for _, g := range merged {
+		groups = append(groups, *g)
+	}
+	slices.SortFunc(groups, func(a, b UsageGroup) int {
+		return cmp.Or(
+			cmp.Compare(a.Day, b.Day),
+			cmp.Compare(b.Cost, a.Cost),
+			cmp.Compare(a.Model, b.Model),
+			cmp.Compare(a.Agent, b.Agent),
+		)
+	})
+	return groups
+}
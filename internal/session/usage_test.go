@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUsageBreakdownMergesTree(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	root, err := svc.Create(ctx, "Root")
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	task, err := svc.CreateTaskSession(ctx, "call-1", root.ID, "Task")
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	coder := UsageEntry{Category: UsageAgent, Name: "coder", Model: "claude-5-sonnet", Requests: 1, InputTokens: 100, OutputTokens: 10, Cost: 0.5}
+	for range 2 {
+		if err := svc.RecordUsage(ctx, root.ID, coder,
+			UsageEntry{Category: UsageTool, Name: "view", Requests: 1, InputTokens: 40, Cost: 0.1},
+		); err != nil {
+			t.Fatalf("record root usage: %v", err)
+		}
+	}
+	if err := svc.RecordUsage(ctx, task.ID,
+		UsageEntry{Category: UsageAgent, Name: "explorer", Model: "claude-4.5-haiku", Requests: 1, InputTokens: 300, Cost: 2},
+		UsageEntry{Category: UsageTool, Name: "view", Requests: 1, InputTokens: 60, Cost: 0.05},
+	); err != nil {
+		t.Fatalf("record task usage: %v", err)
+	}
+
+	// Any session of the tree yields the same breakdown.
+	entries, err := svc.UsageBreakdown(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("breakdown: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3: %+v", len(entries), entries)
+	}
+	if e := entries[0]; e.Name != "explorer" || e.Cost != 2 {
+		t.Errorf("most expensive agent first, got %+v", e)
+	}
+	if e := entries[1]; e.Name != "coder" || e.Requests != 2 || e.InputTokens != 200 || e.OutputTokens != 20 || e.Cost != 1 {
+		t.Errorf("coder usage not accumulated: %+v", e)
+	}
+	if e := entries[2]; e.Category != UsageTool || e.Requests != 3 || e.InputTokens != 140 {
+		t.Errorf("view usage not merged across the tree: %+v", e)
+	}
+}
+
+func TestMessageUsageReport(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	day1 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	for _, u := range []MessageUsage{
+		{SessionID: "s1", AgentID: "coder", Model: "sonnet", InputTokens: 100, Cost: 1, DurationMs: 800, CreatedAt: day1},
+		{SessionID: "s1", AgentID: "explorer", Model: "haiku", InputTokens: 50, Cost: 0.1, DurationMs: 200, CreatedAt: day1.Add(time.Hour)},
+		{SessionID: "s2", AgentID: "coder", Model: "sonnet", InputTokens: 300, Cost: 3, DurationMs: 1000, CreatedAt: day2},
+	} {
+		if err := svc.RecordMessageUsage(ctx, u); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	all, err := svc.ListMessageUsage(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 3 || all[0].AgentID != "coder" || !all[2].CreatedAt.Equal(day2) {
+		t.Fatalf("unexpected rows %+v", all)
+	}
+	recent, err := svc.ListMessageUsage(ctx, day2, time.Time{})
+	if err != nil {
+		t.Fatalf("list since: %v", err)
+	}
+	if len(recent) != 1 || recent[0].SessionID != "s2" {
+		t.Fatalf("since not applied: %+v", recent)
+	}
+
+	byDay := GroupMessageUsage(all, true, false, false)
+	if len(byDay) != 2 || byDay[0].Requests != 2 || byDay[0].Cost != 1.1 || byDay[0].DurationMs != 1000 {
+		t.Fatalf("unexpected daily groups %+v", byDay)
+	}
+	byAgent := GroupMessageUsage(all, false, false, true)
+	if len(byAgent) != 2 || byAgent[0].Agent != "coder" || byAgent[0].InputTokens != 400 || byAgent[0].Day != "" {
+		t.Fatalf("unexpected agent groups %+v", byAgent)
+	}
+}
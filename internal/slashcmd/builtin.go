@@ -39,7 +39,7 @@ func BuiltinCommands() []CommandInfo {
 		{
 			ID:          "init",
 			Title:       "Initialize Project",
-			Description: "Create/Update the AGENTS.md memory file",
+			Description: "Survey the repo with the explorer and write AGENTS.md",
 			Content:     readPrompt("commands/init.md"),
 		},
 		{
@@ -61,6 +61,12 @@ func BuiltinCommands() []CommandInfo {
 			Description: "Summarize the current session and create a new one with the summary",
 			TUIOnly:     true,
 		},
+		{
+			ID:          "pin",
+			Title:       "Pin Prompt",
+			Description: "Keep the last prompt verbatim through compaction; run again to unpin it",
+			TUIOnly:     true,
+		},
 		{
 			ID:          "agents",
 			Title:       "List Agents",
@@ -73,6 +79,96 @@ func BuiltinCommands() []CommandInfo {
 			Description: "Toggle auto-approve mode for the current session (skip permission dialogs)",
 			TUIOnly:     true,
 		},
+		{
+			ID:          "translate",
+			Title:       "Toggle Translation",
+			Description: "Toggle translating responses into the configured language for the current session",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "file-history",
+			Title:       "File History",
+			Description: "Browse the versions of files changed in this session and restore one",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "context",
+			Title:       "Inspect Context",
+			Description: "List what the next request sends with token estimates and exclude items for one turn",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "usage",
+			Title:       "Usage Breakdown",
+			Description: "Show what the session's cost went to, by agent, model and tool",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "usage-panel",
+			Title:       "Toggle Usage Panel",
+			Description: "Show or hide live token usage, context fill and cost in the sidebar",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "fork",
+			Title:       "Fork Session",
+			Description: "Continue from an earlier turn in a new session, keeping this one as it is",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "merge",
+			Title:       "Merge Session",
+			Description: "Fold a side session's summary and file changes into this one",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "edit",
+			Title:       "Edit Prompt",
+			Description: "Edit or regenerate an earlier prompt, dropping everything after it",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "changes",
+			Title:       "Review Changes",
+			Description: "Step through the last turn's file changes hunk by hunk and reject the ones you don't want",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "undo",
+			Title:       "Undo File Changes",
+			Description: "Revert the files changed since the last prompt that modified any",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "restore",
+			Title:       "Restore Git Snapshot",
+			Description: "Reset the work tree to the snapshot taken before the last agent run changed files (requires autoSnapshot)",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "cancel-subagent",
+			Title:       "Cancel Subagent",
+			Description: "Stop one running subagent or flow step; its parent continues with an error result for it",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "subagents",
+			Title:       "Toggle Subagent Output",
+			Description: "Collapse or expand the subagent tool calls and text streamed under task calls",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "snippets",
+			Title:       "Insert Snippet",
+			Description: "Pick a prompt snippet to insert into the editor; !name expands one inline",
+			TUIOnly:     true,
+		},
+		{
+			ID:          "profile",
+			Title:       "Switch Profile",
+			Description: "Switch to another configuration profile: its providers, agent models and permissions",
+			TUIOnly:     true,
+		},
 		{
 			ID:          "vim",
 			Title:       "Toggle Vim Mode",
@@ -92,6 +188,26 @@ func BuiltinCommands() []CommandInfo {
 			ArgumentHint: "[new title]",
 			TUIOnly:      true,
 		},
+		{
+			ID:          "retitle",
+			Title:       "Regenerate Session Title",
+			Description: "Describe the current session again from its conversation and rename it",
+			TUIOnly:     true,
+		},
+		{
+			ID:           "good",
+			Title:        "Rate Reply Good",
+			Description:  "Give the last reply a thumbs up, with an optional comment",
+			ArgumentHint: "[comment]",
+			TUIOnly:      true,
+		},
+		{
+			ID:           "bad",
+			Title:        "Rate Reply Bad",
+			Description:  "Give the last reply a thumbs down, with an optional reason",
+			ArgumentHint: "[reason]",
+			TUIOnly:      true,
+		},
 		{
 			ID:           "loop",
 			Title:        "Schedule Recurring Task",
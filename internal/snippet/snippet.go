@@ -0,0 +1,198 @@
+// Package snippet loads the user's named prompt snippets and expands them in
+// prompt text.
+//
+// A snippet is a piece of text the user types often: a review checklist, a
+// bug report template. Snippets come from the `snippets` map in the config
+// and from markdown files in snippets directories; in the prompt editor,
+// `!name` expands to the snippet's text.
+package snippet
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Snippet is a named piece of prompt text.
+type Snippet struct {
+	Name        string
+	Description string
+	Content     string
+	// Source is the file the snippet was read from, or "config".
+	Source string
+}
+
+// Context fills the placeholders of a snippet.
+type Context struct {
+	// File replaces $FILE, the file the prompt is about. When empty the
+	// placeholder is left in the text for the user to fill in.
+	File string
+	// Selection replaces $SELECTION. Expand sets it to the rest of the
+	// prompt.
+	Selection string
+}
+
+// tokenPattern matches a !name reference at the start of the text or after
+// whitespace, so "!=" and "wow!" are left alone.
+var tokenPattern = regexp.MustCompile(`(?:^|\s)!([A-Za-z][\w-]*)`)
+
+var placeholders = []string{"FILE", "SELECTION"}
+
+// Load returns the snippets of the loaded config and of the snippets
+// directories, sorted by name. User directories are read first, then the
+// config, then the project's .opencode/snippets, so a project snippet
+// replaces a user snippet of the same name. Names are case-insensitive.
+func Load() []Snippet {
+	byName := make(map[string]Snippet)
+	for _, dir := range userDirs() {
+		loadDir(dir, byName)
+	}
+	if cfg := config.Get(); cfg != nil {
+		for name, content := range cfg.Snippets {
+			name = strings.ToLower(name)
+			byName[name] = Snippet{Name: name, Content: content, Source: "config"}
+		}
+		loadDir(filepath.Join(cfg.WorkingDir, ".opencode", "snippets"), byName)
+	}
+
+	snippets := make([]Snippet, 0, len(byName))
+	for _, s := range byName {
+		snippets = append(snippets, s)
+	}
+	sort.Slice(snippets, func(i, j int) bool { return snippets[i].Name < snippets[j].Name })
+	return snippets
+}
+
+func userDirs() []string {
+	var dirs []string
+	home, homeErr := os.UserHomeDir()
+	xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgConfigHome == "" && homeErr == nil {
+		xdgConfigHome = filepath.Join(home, ".config")
+	}
+	if xdgConfigHome != "" {
+		dirs = append(dirs, filepath.Join(xdgConfigHome, "opencode", "snippets"))
+	}
+	if homeErr == nil {
+		dirs = append(dirs, filepath.Join(home, ".opencode", "snippets"))
+	}
+	return dirs
+}
+
+// loadDir reads the *.md files of dir into byName. The file name without
+// the extension is the snippet name; an optional YAML frontmatter may give
+// a description.
+func loadDir(dir string, byName map[string]Snippet) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Warn("Failed to read snippets directory", "dir", dir, "error", err)
+		}
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			logging.Warn("Failed to read snippet", "path", path, "error", err)
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		description, content := parseMarkdown(string(raw))
+		byName[name] = Snippet{Name: name, Description: description, Content: content, Source: path}
+	}
+}
+
+func parseMarkdown(raw string) (description, content string) {
+	rest, ok := strings.CutPrefix(raw, "---\n")
+	if !ok {
+		return "", raw
+	}
+	front, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		return "", raw
+	}
+	var fm struct {
+		Description string `yaml:"description"`
+	}
+	if err := yaml.Unmarshal([]byte(front), &fm); err != nil {
+		logging.Warn("Failed to parse snippet frontmatter", "error", err)
+		return "", raw
+	}
+	return fm.Description, strings.TrimLeft(body, "\n")
+}
+
+// Find returns the snippet called name.
+func Find(snippets []Snippet, name string) (Snippet, bool) {
+	name = strings.ToLower(name)
+	for _, s := range snippets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Snippet{}, false
+}
+
+// UsesSelection reports whether the snippet takes the rest of the prompt
+// through $SELECTION.
+func (s Snippet) UsesSelection() bool {
+	return strings.Contains(s.Content, "$SELECTION") || strings.Contains(s.Content, "${SELECTION}")
+}
+
+// Fill returns the snippet's text with its placeholders replaced.
+func (s Snippet) Fill(c Context) string {
+	values := map[string]string{"FILE": c.File, "SELECTION": c.Selection}
+	var pairs []string
+	for _, p := range placeholders {
+		v := values[p]
+		if p == "FILE" && v == "" {
+			continue
+		}
+		pairs = append(pairs, "${"+p+"}", v, "$"+p, v)
+	}
+	return strings.NewReplacer(pairs...).Replace(s.Content)
+}
+
+// Expand replaces every !name in text that names a snippet with the
+// snippet's text and reports whether any was found. Unknown names are left
+// as typed. A snippet that uses $SELECTION wraps the rest of the text: the
+// prompt "!bugreport saving panics" becomes the bugreport template with
+// "saving panics" in place of $SELECTION. Only the first such snippet
+// wraps; later ones get an empty selection.
+func Expand(text string, snippets []Snippet, file string) (string, bool) {
+	var out strings.Builder
+	var wrap *Snippet
+	last, found := 0, false
+	for _, m := range tokenPattern.FindAllStringSubmatchIndex(text, -1) {
+		s, ok := Find(snippets, text[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		found = true
+		out.WriteString(text[last : m[2]-1])
+		last = m[3]
+		if wrap == nil && s.UsesSelection() {
+			wrap = &s
+			continue
+		}
+		out.WriteString(s.Fill(Context{File: file}))
+	}
+	if !found {
+		return text, false
+	}
+	out.WriteString(text[last:])
+	if wrap == nil {
+		return out.String(), true
+	}
+	return wrap.Fill(Context{File: file, Selection: strings.TrimSpace(out.String())}), true
+}
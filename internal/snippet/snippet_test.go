@@ -0,0 +1,75 @@
+package snippet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+func TestExpand(t *testing.T) {
+	snippets := []Snippet{
+		{Name: "review", Content: "Check $FILE for races."},
+		{Name: "bug", Content: "Bug report\n\n${SELECTION}\n\nSteps to reproduce:"},
+		{Name: "tests", Content: "Add tests."},
+	}
+
+	tests := []struct {
+		name, text, file, want string
+		found                  bool
+	}{
+		{"inline", "please !review then !tests", "main.go", "please Check main.go for races. then Add tests.", true},
+		{"file left to fill in", "!review", "", "Check $FILE for races.", true},
+		{"wraps the rest", "saving panics !bug", "", "Bug report\n\nsaving panics\n\nSteps to reproduce:", true},
+		{"wraps expanded text", "!bug it hangs !tests", "", "Bug report\n\nit hangs Add tests.\n\nSteps to reproduce:", true},
+		{"unknown names stay", "wow! a != b !nope", "", "wow! a != b !nope", false},
+		{"case-insensitive", "!Tests", "", "Add tests.", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := Expand(tt.text, snippets, tt.file)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	userDir := filepath.Join(home, ".config", "opencode", "snippets")
+	require.NoError(t, os.MkdirAll(userDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, "review.md"), []byte("user review"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, "standup.md"), []byte("What did I do yesterday?"), 0o644))
+
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".opencode.json"),
+		[]byte(`{"snippets": {"lgtm": "Looks good, merge it."}}`), 0o644))
+	projectDir := filepath.Join(workDir, ".opencode", "snippets")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Review.md"),
+		[]byte("---\ndescription: Our review checklist\n---\n\n- errors wrapped\n"), 0o644))
+	_, err := config.Load(workDir, false)
+	require.NoError(t, err)
+
+	snippets := Load()
+	var names []string
+	for _, s := range snippets {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"lgtm", "review", "standup"}, names)
+
+	review, ok := Find(snippets, "review")
+	require.True(t, ok)
+	assert.Equal(t, "Our review checklist", review.Description)
+	assert.Equal(t, "- errors wrapped\n", review.Content)
+	assert.Equal(t, filepath.Join(projectDir, "Review.md"), review.Source)
+
+	lgtm, _ := Find(snippets, "lgtm")
+	assert.Equal(t, "config", lgtm.Source)
+}
@@ -1,6 +1,10 @@
 package todo
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
 
 // Item represents a single task in the todo list.
 type Item struct {
@@ -9,9 +13,29 @@ type Item struct {
 	Priority string `json:"priority"` // high, medium, low
 }
 
+// List is the todo list of a session, as published on every change.
+type List struct {
+	SessionID string
+	Items     []Item
+}
+
+// Remaining returns the number of items that are neither completed nor
+// cancelled.
+func Remaining(items []Item) int {
+	n := 0
+	for _, item := range items {
+		if item.Status != "completed" && item.Status != "cancelled" {
+			n++
+		}
+	}
+	return n
+}
+
 // Store is an in-memory, session-scoped todo list store.
-// Thread-safe for concurrent access.
+// Thread-safe for concurrent access. Every change is published as a List;
+// clearing a session's list publishes a DeletedEvent.
 type Store struct {
+	*pubsub.Broker[List]
 	mu    sync.RWMutex
 	items map[string][]Item // sessionID -> items
 }
@@ -19,21 +43,26 @@ type Store struct {
 // NewStore creates a new empty Store.
 func NewStore() *Store {
 	return &Store{
-		items: make(map[string][]Item),
+		Broker: pubsub.NewBroker[List](),
+		items:  make(map[string][]Item),
 	}
 }
 
 // Set replaces the entire todo list for a session.
 func (s *Store) Set(sessionID string, items []Item) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	if len(items) == 0 {
-		delete(s.items, sessionID)
+		s.Delete(sessionID)
 		return
 	}
 	cp := make([]Item, len(items))
 	copy(cp, items)
+	s.mu.Lock()
 	s.items[sessionID] = cp
+	s.mu.Unlock()
+
+	pub := make([]Item, len(items))
+	copy(pub, items)
+	s.Publish(pubsub.UpdatedEvent, List{SessionID: sessionID, Items: pub})
 }
 
 // Get returns a copy of the todo list for a session.
@@ -53,6 +82,21 @@ func (s *Store) Get(sessionID string) []Item {
 // Delete removes all todos for a session.
 func (s *Store) Delete(sessionID string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	_, had := s.items[sessionID]
 	delete(s.items, sessionID)
+	s.mu.Unlock()
+	if had {
+		s.Publish(pubsub.DeletedEvent, List{SessionID: sessionID})
+	}
+}
+
+// Sessions returns the IDs of sessions that currently have todos.
+func (s *Store) Sessions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.items))
+	for id := range s.items {
+		ids = append(ids, id)
+	}
+	return ids
 }
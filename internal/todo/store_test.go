@@ -1,8 +1,11 @@
 package todo
 
 import (
+	"context"
 	"sync"
 	"testing"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
 )
 
 func TestStore_SetGet(t *testing.T) {
@@ -78,3 +81,38 @@ func TestStore_Concurrent(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestStore_PublishesChanges(t *testing.T) {
+	s := NewStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	s.Set("sess1", []Item{{Content: "task 1", Status: "pending", Priority: "high"}})
+	ev := <-events
+	if ev.Type != pubsub.UpdatedEvent || ev.Payload.SessionID != "sess1" || len(ev.Payload.Items) != 1 {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	s.Set("sess1", nil)
+	ev = <-events
+	if ev.Type != pubsub.DeletedEvent || ev.Payload.SessionID != "sess1" || ev.Payload.Items != nil {
+		t.Fatalf("clearing the list should publish a delete, got %+v", ev)
+	}
+
+	s.Delete("sess1")
+	select {
+	case ev := <-events:
+		t.Fatalf("deleting a missing list published %+v", ev)
+	default:
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	items := []Item{
+		{Status: "pending"}, {Status: "in_progress"}, {Status: "completed"}, {Status: "cancelled"},
+	}
+	if got := Remaining(items); got != 2 {
+		t.Fatalf("Remaining = %d, want 2", got)
+	}
+}
@@ -0,0 +1,103 @@
+// Package translation holds the per-session toggle and the code-preserving
+// text masking used to post-process final assistant responses through the
+// translator agent.
+package translation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// Service tracks which sessions have output translation enabled. Sessions
+// without an explicit toggle fall back to translation.enabled in config.
+type Service interface {
+	// Language returns the configured target language, or "" when
+	// translation is not configured at all.
+	Language() string
+	IsEnabled(sessionID string) bool
+	SetEnabled(sessionID string, enabled bool)
+}
+
+type service struct {
+	overrides sync.Map // session ID -> bool
+}
+
+func NewService() Service {
+	return &service{}
+}
+
+func (s *service) Language() string {
+	cfg := config.Get()
+	if cfg == nil || cfg.Translation == nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Translation.Language)
+}
+
+func (s *service) IsEnabled(sessionID string) bool {
+	if s.Language() == "" {
+		return false
+	}
+	if v, ok := s.overrides.Load(sessionID); ok {
+		return v.(bool)
+	}
+	return config.Get().Translation.Enabled
+}
+
+func (s *service) SetEnabled(sessionID string, enabled bool) {
+	s.overrides.Store(sessionID, enabled)
+}
+
+// codePattern matches fenced code blocks (``` or ~~~) and inline code
+// spans. Fences are tried first so inline backticks inside a block are
+// never matched on their own.
+var codePattern = regexp.MustCompile("(?s)(```.*?```|~~~.*?~~~|`[^`\n]+`)")
+
+// Masked is a text whose code segments were replaced by placeholders.
+type Masked struct {
+	Text     string
+	segments []string
+}
+
+func placeholder(i int) string {
+	return fmt.Sprintf("[[CODE_%d]]", i)
+}
+
+// Mask replaces every code block and inline code span in text with a
+// numbered placeholder so the translator only ever sees prose.
+func Mask(text string) Masked {
+	var segments []string
+	masked := codePattern.ReplaceAllStringFunc(text, func(m string) string {
+		segments = append(segments, m)
+		return placeholder(len(segments) - 1)
+	})
+	return Masked{Text: masked, segments: segments}
+}
+
+// Restore puts the original code segments back into a translated text. It
+// fails when the translator dropped or duplicated a placeholder, in which
+// case the caller should keep the untranslated response.
+func (m Masked) Restore(translated string) (string, error) {
+	for i, seg := range m.segments {
+		ph := placeholder(i)
+		if n := strings.Count(translated, ph); n != 1 {
+			return "", fmt.Errorf("placeholder %s appears %d times in translation", ph, n)
+		}
+		translated = strings.Replace(translated, ph, seg, 1)
+	}
+	return translated, nil
+}
+
+// HasProse reports whether anything besides code and whitespace is left
+// after masking — there is no point calling the translator otherwise.
+func (m Masked) HasProse() bool {
+	rest := m.Text
+	for i := range m.segments {
+		rest = strings.Replace(rest, placeholder(i), "", 1)
+	}
+	return strings.TrimSpace(rest) != ""
+}
@@ -0,0 +1,41 @@
+package translation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskRestoreRoundTrip(t *testing.T) {
+	text := "Run `go test` first.\n\n```go\nfunc main() { fmt.Println(\"`hi`\") }\n```\n\nThen commit."
+	m := Mask(text)
+
+	if strings.Contains(m.Text, "func main") || strings.Contains(m.Text, "go test") {
+		t.Fatalf("code leaked into masked text: %q", m.Text)
+	}
+	if !m.HasProse() {
+		t.Fatal("expected prose to remain")
+	}
+
+	translated := strings.NewReplacer("Run", "Запустите", "first.", "сначала.", "Then commit.", "Затем сделайте коммит.").Replace(m.Text)
+	got, err := m.Restore(translated)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	want := "Запустите `go test` сначала.\n\n```go\nfunc main() { fmt.Println(\"`hi`\") }\n```\n\nЗатем сделайте коммит."
+	if got != want {
+		t.Fatalf("Restore mismatch\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRestoreRejectsMissingPlaceholder(t *testing.T) {
+	m := Mask("see `x` and `y`")
+	if _, err := m.Restore("siehe [[CODE_0]]"); err == nil {
+		t.Fatal("expected error when a placeholder is dropped")
+	}
+}
+
+func TestHasProseCodeOnly(t *testing.T) {
+	if Mask("```\nls -la\n```\n").HasProse() {
+		t.Fatal("code-only response should not need translation")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/x/ansi"
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/lsp"
 	"github.com/opencode-ai/opencode/internal/lsp/install"
 	"github.com/opencode-ai/opencode/internal/message"
@@ -22,6 +23,12 @@ type SendMsg struct {
 	Attachments []message.Attachment
 }
 
+// EditPromptMsg replaces the editor content with a rewound prompt.
+type EditPromptMsg struct {
+	Text        string
+	Attachments []message.Attachment
+}
+
 type SessionSelectedMsg = session.Session
 
 type SessionClearedMsg struct{}
@@ -268,10 +275,12 @@ func mcpServersConfigured(width int, a *app.App) string {
 	agentName := ""
 	toolsResolved := false
 	var loadedServers map[string]bool
+	var health map[string]agent.MCPServerHealth
 	if a != nil {
 		agentName = a.ActiveAgentName()
 		_, toolsResolved = a.ActiveAgent().ResolvedTools()
 		loadedServers = a.MCPRegistry.LoadedServers()
+		health = a.MCPRegistry.Health()
 	}
 
 	loadedCount := len(loadedServers)
@@ -325,6 +334,16 @@ func mcpServersConfigured(width int, a *app.App) string {
 			indicator = "●"
 			indicatorColor = t.Success()
 		}
+		// Persistent connections report their own state, which wins over
+		// the tools having loaded once.
+		switch health[name].State {
+		case agent.MCPHealthReconnecting:
+			indicator = "◐"
+			indicatorColor = t.Warning()
+		case agent.MCPHealthFailed:
+			indicator = "✗"
+			indicatorColor = t.Error()
+		}
 
 		indicatorStr := baseStyle.
 			Foreground(indicatorColor).
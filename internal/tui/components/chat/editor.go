@@ -20,6 +20,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/snippet"
 	"github.com/opencode-ai/opencode/internal/tui/components/dialog"
 	"github.com/opencode-ai/opencode/internal/tui/layout"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
@@ -48,11 +49,15 @@ type editorCmp struct {
 	shellHistoryIdx int
 	shellExecuting  bool
 	vimHandler      *vim.Handler // nil when vim mode is disabled
+	// lastFile is the last file picked with @, which fills $FILE in
+	// snippets.
+	lastFile string
 }
 
 type EditorKeyMaps struct {
-	Send       key.Binding
-	OpenEditor key.Binding
+	Send          key.Binding
+	OpenEditor    key.Binding
+	ExpandSnippet key.Binding
 }
 
 type DeleteAttachmentKeyMaps struct {
@@ -70,6 +75,10 @@ var editorMaps = EditorKeyMaps{
 		key.WithKeys("ctrl+e"),
 		key.WithHelp("ctrl+e", "open editor"),
 	),
+	ExpandSnippet: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "expand !snippets"),
+	),
 }
 
 var DeleteKeyMaps = DeleteAttachmentKeyMaps{
@@ -142,7 +151,7 @@ func (m *editorCmp) send() tea.Cmd {
 		return util.ReportWarn("Agent is working, please wait...")
 	}
 
-	value := m.textarea.Value()
+	value, _ := snippet.Expand(m.textarea.Value(), snippet.Load(), m.lastFile)
 	m.textarea.Reset()
 	attachments := m.attachments
 
@@ -229,6 +238,32 @@ func (m *editorCmp) shellHistoryDown() {
 	}
 }
 
+// expandSnippets replaces the !name snippets in the prompt with their text,
+// so it can be edited before sending. It reports whether there were any.
+func (m *editorCmp) expandSnippets() bool {
+	value := m.textarea.Value()
+	if !strings.Contains(value, "!") {
+		return false
+	}
+	expanded, ok := snippet.Expand(value, snippet.Load(), m.lastFile)
+	if ok {
+		m.textarea.SetValue(expanded)
+	}
+	return ok
+}
+
+// insertSnippet puts a snippet picked from the snippet dialog into the
+// prompt. One that uses $SELECTION takes the prompt typed so far in.
+func (m *editorCmp) insertSnippet(s snippet.Snippet) {
+	c := snippet.Context{File: m.lastFile}
+	if s.UsesSelection() {
+		c.Selection = strings.TrimSpace(m.textarea.Value())
+		m.textarea.SetValue(s.Fill(c))
+		return
+	}
+	m.textarea.InsertString(s.Fill(c))
+}
+
 func (m *editorCmp) IsShellMode() bool {
 	return m.mode == modeShell
 }
@@ -262,6 +297,7 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialog.ThemeChangedMsg:
 		m.textarea = CreateTextArea(&m.textarea)
 	case dialog.CompletionSelectedMsg:
+		m.lastFile = msg.CompletionValue
 		existingValue := m.textarea.Value()
 		modifiedValue := strings.Replace(existingValue, msg.SearchString, msg.CompletionValue, 1)
 		m.textarea.SetValue(modifiedValue)
@@ -271,6 +307,18 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		modifiedValue := strings.Replace(existingValue, msg.SearchString, "", 1)
 		m.textarea.SetValue(modifiedValue)
 		return m, nil
+	case dialog.SnippetSelectedMsg:
+		if m.mode == modeShell {
+			m.exitShellMode()
+			m.insertSnippet(msg.Snippet)
+			return m, util.CmdHandler(ShellModeChangedMsg{ShellMode: false})
+		}
+		m.insertSnippet(msg.Snippet)
+		return m, nil
+	case EditPromptMsg:
+		m.textarea.SetValue(msg.Text)
+		m.attachments = msg.Attachments
+		return m, nil
 	case SessionClearedMsg:
 		m.session = session.Session{}
 		if m.mode == modeShell {
@@ -348,6 +396,15 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.exitShellMode()
 				return m, util.CmdHandler(ShellModeChangedMsg{ShellMode: false})
 			}
+			// Expanding a snippet name leaves shell mode for the snippet:
+			// a prompt can't start with !name, since ! turns on shell mode.
+			if key.Matches(msg, editorMaps.ExpandSnippet) {
+				if s, ok := snippet.Find(snippet.Load(), strings.TrimSpace(m.textarea.Value())); ok {
+					m.exitShellMode()
+					m.insertSnippet(s)
+					return m, util.CmdHandler(ShellModeChangedMsg{ShellMode: false})
+				}
+			}
 			// Up/Down navigate shell history
 			if msg.String() == "up" {
 				m.shellHistoryUp()
@@ -386,6 +443,9 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, m.openEditor()
 		}
+		if key.Matches(msg, editorMaps.ExpandSnippet) && m.expandSnippets() {
+			return m, nil
+		}
 		if key.Matches(msg, DeleteKeyMaps.Escape) {
 			m.deleteMode = false
 			return m, nil
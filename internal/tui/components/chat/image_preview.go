@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+)
+
+// maxPreviewRows caps the height of an image preview in terminal rows.
+const maxPreviewRows = 16
+
+// previewsSupported reports whether the terminal shows 24-bit color, which
+// the half block previews need to be recognizable. Elsewhere images are
+// only linked.
+var previewsSupported = func() bool {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return true
+	}
+	return false
+}
+
+// renderImages renders the images an assistant message produced: a link to
+// each saved file, followed by a preview when the terminal supports one.
+func renderImages(images []message.ImageContent, width int) []string {
+	t := theme.CurrentTheme()
+	linkStyle := styles.BaseStyle().Width(width).Foreground(t.TextMuted())
+	var lines []string
+	for _, img := range images {
+		link := linkStyle.Hyperlink("file://" + filepath.ToSlash(img.Path)).
+			Render(fmt.Sprintf(" %s %s", styles.DocumentIcon, img.Path))
+		lines = append(lines, link)
+		if !previewsSupported() {
+			continue
+		}
+		preview := sharedRenderCache.getOrRender(width, []string{"image", img.Path}, func() string {
+			return imagePreview(img.Path, width-1)
+		})
+		if preview != "" {
+			lines = append(lines, preview)
+		}
+	}
+	return lines
+}
+
+// imagePreview draws the image at path with upper half blocks, two pixels
+// to a cell, scaled to fit cols columns and maxPreviewRows rows. It returns
+// "" when the image can't be read.
+func imagePreview(path string, cols int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return ""
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || cols <= 0 {
+		return ""
+	}
+
+	w := min(cols, bounds.Dx())
+	h := w * bounds.Dy() / bounds.Dx()
+	if h > 2*maxPreviewRows {
+		h = 2 * maxPreviewRows
+		w = max(1, h*bounds.Dx()/bounds.Dy())
+	}
+	h = max(2, h+h%2)
+
+	var b strings.Builder
+	for row := 0; row < h; row += 2 {
+		if row > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteByte(' ')
+		for col := range w {
+			sx := bounds.Min.X + col*bounds.Dx()/w
+			top := img.At(sx, bounds.Min.Y+row*bounds.Dy()/h)
+			bottom := img.At(sx, bounds.Min.Y+(row+1)*bounds.Dy()/h)
+			b.WriteString(lipgloss.NewStyle().Foreground(top).Background(bottom).Render("▀"))
+		}
+	}
+	return b.String()
+}
@@ -3,6 +3,8 @@ package chat
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
@@ -11,6 +13,7 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
@@ -41,10 +44,44 @@ type messagesCmp struct {
 	cachedPending       pendingToolCounts
 	cachedUnfinished    bool
 	taskMessages        map[string][]message.Message
-	userScrolledUp      bool
-	newMessageCount     int
-	recapContent        string
+	// subagentCalls maps the session of each subagent streaming into the
+	// view to the task call that runs it.
+	subagentCalls map[string]subagentCall
+	// collapseSubagents folds each task call's subagent block into a
+	// one-line summary.
+	collapseSubagents bool
+	liveOutput        map[string]string
+	userScrolledUp    bool
+	newMessageCount   int
+	recapContent      string
+	// hasOlder is set while history before messages[0] is not loaded yet.
+	hasOlder     bool
+	loadingOlder bool
 }
+
+// historyPageSize is how many messages are loaded when a session is opened
+// and each time the user scrolls past the oldest loaded one.
+const historyPageSize = 100
+
+// olderMessagesLoadedMsg carries a page of history preceding the loaded
+// messages of sessionID.
+type olderMessagesLoadedMsg struct {
+	sessionID string
+	msgs      []message.Message
+	err       error
+}
+
+// subagentCall is the task call running a subagent session, and the
+// session that made the call.
+type subagentCall struct {
+	toolCallID      string
+	parentSessionID string
+}
+
+// ToggleSubagentOutputMsg collapses or expands the subagent blocks nested
+// under task calls.
+type ToggleSubagentOutputMsg struct{}
+
 type renderFinishedMsg struct {
 	uiMessages      []uiMessage
 	cacheUpdates    map[string]cacheItem
@@ -108,14 +145,24 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.session = session.Session{}
 		m.messages = make([]message.Message, 0)
 		m.taskMessages = make(map[string][]message.Message)
+		m.subagentCalls = make(map[string]subagentCall)
+		m.liveOutput = make(map[string]string)
 		m.cachedContent = make(map[string]cacheItem)
 		m.currentMsgID = ""
 		m.recapContent = ""
 		m.rendering = false
 		m.userScrolledUp = false
 		m.newMessageCount = 0
+		m.hasOlder = false
+		m.loadingOlder = false
 		cmds = append(cmds, m.emitScrollState())
 
+	case ToggleSubagentOutputMsg:
+		m.collapseSubagents = !m.collapseSubagents
+		if m.rendering {
+			m.rendering = false
+		}
+		m.rerender()
 	case tea.KeyPressMsg:
 		if key.Matches(msg, messageKeys.PageUp) || key.Matches(msg, messageKeys.PageDown) ||
 			key.Matches(msg, messageKeys.HalfPageUp) || key.Matches(msg, messageKeys.HalfPageDown) {
@@ -123,12 +170,40 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport = u
 			cmds = append(cmds, cmd)
 			cmds = append(cmds, m.updateScrollState())
+			cmds = append(cmds, m.loadOlderAtTop())
 		}
 	case tea.MouseWheelMsg:
 		u, cmd := m.viewport.Update(msg)
 		m.viewport = u
 		cmds = append(cmds, cmd)
 		cmds = append(cmds, m.updateScrollState())
+		cmds = append(cmds, m.loadOlderAtTop())
+
+	case olderMessagesLoadedMsg:
+		if msg.sessionID != m.session.ID {
+			break
+		}
+		m.loadingOlder = false
+		if msg.err != nil {
+			cmds = append(cmds, util.ReportError(msg.err))
+			break
+		}
+		m.hasOlder = len(msg.msgs) == historyPageSize
+		if len(msg.msgs) == 0 {
+			break
+		}
+		m.messages = append(msg.msgs, m.messages...)
+		m.loadTaskMessages(msg.msgs)
+		m.recomputeToolState()
+		// Keep the lines the user is looking at in place as the older
+		// messages are rendered above them.
+		lines := m.viewport.TotalLineCount()
+		yOff := m.viewport.YOffset()
+		if m.rendering {
+			m.rendering = false
+		}
+		m.renderViewSync()
+		m.viewport.SetYOffset(yOff + m.viewport.TotalLineCount() - lines)
 
 	case renderFinishedMsg:
 		if !m.rendering {
@@ -167,6 +242,33 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.renderViewSync()
 			}
 		}
+	case pubsub.Event[tools.BashOutput]:
+		if msgID := m.toolCallMessageID(msg.Payload.ToolCallID); msgID != "" {
+			m.liveOutput[msg.Payload.ToolCallID] = appendLiveOutput(m.liveOutput[msg.Payload.ToolCallID], msg.Payload.Chunk)
+			m.invalidateCache(msgID)
+			if !m.rendering {
+				yOff := m.viewport.YOffset()
+				m.renderViewSync()
+				if m.userScrolledUp {
+					m.viewport.SetYOffset(yOff)
+				} else {
+					m.viewport.GotoBottom()
+				}
+			}
+		}
+	case pubsub.Event[agent.SubagentEvent]:
+		if msgID := m.applySubagentEvent(msg.Type, msg.Payload); msgID != "" {
+			m.invalidateCache(msgID)
+			if !m.rendering {
+				yOff := m.viewport.YOffset()
+				m.renderViewSync()
+				if m.userScrolledUp {
+					m.viewport.SetYOffset(yOff)
+				} else {
+					m.viewport.GotoBottom()
+				}
+			}
+		}
 	case pubsub.Event[message.Message]:
 		needsRerender := false
 		if msg.Type == pubsub.CreatedEvent {
@@ -181,6 +283,9 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if !messageExists {
+					for _, r := range msg.Payload.ToolResults() {
+						delete(m.liveOutput, r.ToolCallID)
+					}
 					if len(m.messages) > 0 {
 						lastMsgID := m.messages[len(m.messages)-1].ID
 						m.invalidateCache(lastMsgID)
@@ -195,13 +300,19 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-			// There are tool calls from the child task
-			for _, v := range m.messages {
-				for _, c := range v.ToolCalls() {
-					if c.ID == msg.Payload.SessionID {
-						m.taskMessages[c.ID] = append(m.taskMessages[c.ID], msg.Payload)
-						m.invalidateCache(v.ID)
+		} else if msg.Type == pubsub.DeletedEvent {
+			if msg.Payload.SessionID == m.session.ID {
+				for i, v := range m.messages {
+					if v.ID == msg.Payload.ID {
+						m.messages = slices.Delete(m.messages, i, i+1)
+						delete(m.cachedContent, v.ID)
+						m.currentMsgID = ""
+						if len(m.messages) > 0 {
+							m.currentMsgID = m.messages[len(m.messages)-1].ID
+							m.invalidateCache(m.currentMsgID)
+						}
 						needsRerender = true
+						break
 					}
 				}
 			}
@@ -216,20 +327,6 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-			for _, v := range m.messages {
-				for _, c := range v.ToolCalls() {
-					if c.ID == msg.Payload.SessionID {
-						for i, tm := range m.taskMessages[c.ID] {
-							if tm.ID == msg.Payload.ID {
-								m.taskMessages[c.ID][i] = msg.Payload
-								m.invalidateCache(v.ID)
-								needsRerender = true
-								break
-							}
-						}
-					}
-				}
-			}
 		}
 		if needsRerender {
 			m.recomputeToolState()
@@ -286,6 +383,82 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// liveOutputLimit bounds the output kept per running command; only the
+// last few lines are shown anyway.
+const liveOutputLimit = 16 * 1024
+
+// appendLiveOutput adds chunk to buf and drops the oldest output beyond
+// liveOutputLimit, starting the kept part at a line boundary when possible.
+func appendLiveOutput(buf, chunk string) string {
+	buf += chunk
+	if len(buf) <= liveOutputLimit {
+		return buf
+	}
+	buf = buf[len(buf)-liveOutputLimit:]
+	if i := strings.IndexByte(buf, '\n'); i >= 0 {
+		return buf[i+1:]
+	}
+	return strings.ToValidUTF8(buf, "")
+}
+
+// toolCallMessageID returns the ID of the shown message that made the tool
+// call, or "" when it is not part of the current session.
+func (m *messagesCmp) toolCallMessageID(callID string) string {
+	for _, msg := range m.messages {
+		for _, c := range msg.ToolCalls() {
+			if c.ID == callID {
+				return msg.ID
+			}
+		}
+	}
+	return ""
+}
+
+// applySubagentEvent records an event of a subagent running under a task
+// call of the view, at any nesting depth, and returns the ID of the shown
+// message holding the outermost task call. It returns "" and ignores the
+// event when the subagent does not belong to the view.
+func (m *messagesCmp) applySubagentEvent(t pubsub.EventType, ev agent.SubagentEvent) string {
+	msgID := m.taskOwnerMessageID(ev.ParentSessionID, ev.ToolCallID)
+	if msgID == "" {
+		return ""
+	}
+	m.subagentCalls[ev.SessionID] = subagentCall{toolCallID: ev.ToolCallID, parentSessionID: ev.ParentSessionID}
+	if ev.Type != agent.AgentEventTypeMessage {
+		return msgID
+	}
+	msgs := m.taskMessages[ev.ToolCallID]
+	i := slices.IndexFunc(msgs, func(tm message.Message) bool { return tm.ID == ev.Message.ID })
+	switch {
+	case t == pubsub.DeletedEvent:
+		if i >= 0 {
+			msgs = slices.Delete(msgs, i, i+1)
+		}
+	case i >= 0:
+		msgs[i] = ev.Message
+	default:
+		msgs = append(msgs, ev.Message)
+	}
+	m.taskMessages[ev.ToolCallID] = msgs
+	return msgID
+}
+
+// taskOwnerMessageID follows a task call up through the task calls that
+// spawned its session to the shown message holding the outermost one.
+func (m *messagesCmp) taskOwnerMessageID(parentSessionID, toolCallID string) string {
+	for range len(m.subagentCalls) + 1 {
+		if parentSessionID == m.session.ID {
+			return m.toolCallMessageID(toolCallID)
+		}
+		call, ok := m.subagentCalls[parentSessionID]
+		if !ok {
+			return ""
+		}
+		parentSessionID, toolCallID = call.parentSessionID, call.toolCallID
+	}
+	return ""
+}
+
 func (m *messagesCmp) IsAgentWorking() bool {
 	return m.app.ActiveAgent().IsSessionBusy(m.session.ID)
 }
@@ -372,8 +545,10 @@ func (m *messagesCmp) computeRender() renderResult {
 				inx,
 				m.messages,
 				m.taskMessages,
+				m.liveOutput,
 				m.currentMsgID,
 				isSummary,
+				m.collapseSubagents,
 				m.width,
 				pos,
 			)
@@ -449,6 +624,11 @@ func (m *messagesCmp) renderViewAsync() tea.Cmd {
 		taskMsgsCopy[k] = append([]message.Message(nil), v...)
 	}
 
+	liveOutputCopy := make(map[string]string, len(m.liveOutput))
+	for k, v := range m.liveOutput {
+		liveOutputCopy[k] = v
+	}
+
 	cacheCopy := make(map[string]cacheItem, len(m.cachedContent))
 	for k, v := range m.cachedContent {
 		cacheCopy[k] = v
@@ -458,6 +638,7 @@ func (m *messagesCmp) renderViewAsync() tea.Cmd {
 	currentMsgID := m.currentMsgID
 	summaryMsgID := m.session.SummaryMessageID
 	recapContent := m.recapContent
+	collapseSubagents := m.collapseSubagents
 
 	return func() tea.Msg {
 		var uiMsgs []uiMessage
@@ -495,8 +676,10 @@ func (m *messagesCmp) renderViewAsync() tea.Cmd {
 					inx,
 					msgsCopy,
 					taskMsgsCopy,
+					liveOutputCopy,
 					currentMsgID,
 					isSummary,
+					collapseSubagents,
 					width,
 					pos,
 				)
@@ -787,22 +970,18 @@ func (m *messagesCmp) SetSession(session session.Session) tea.Cmd {
 	}
 	m.session = session
 	m.recapContent = ""
-	messages, err := m.app.Messages.List(context.Background(), session.ID)
+	messages, err := m.app.Messages.ListLatest(context.Background(), session.ID, historyPageSize)
 	if err != nil {
 		return util.ReportError(err)
 	}
 	m.messages = messages
+	m.hasOlder = len(messages) == historyPageSize
+	m.loadingOlder = false
 	m.cachedContent = make(map[string]cacheItem)
 	m.taskMessages = make(map[string][]message.Message)
-	for _, msg := range m.messages {
-		for _, tc := range msg.ToolCalls() {
-			if tc.Name == agent.TaskToolName {
-				if taskMsgs, err := m.app.Messages.List(context.Background(), tc.ID); err == nil {
-					m.taskMessages[tc.ID] = taskMsgs
-				}
-			}
-		}
-	}
+	m.subagentCalls = make(map[string]subagentCall)
+	m.liveOutput = make(map[string]string)
+	m.loadTaskMessages(m.messages)
 	if len(m.messages) > 0 {
 		m.currentMsgID = m.messages[len(m.messages)-1].ID
 	}
@@ -814,6 +993,36 @@ func (m *messagesCmp) SetSession(session session.Session) tea.Cmd {
 	return m.emitScrollState()
 }
 
+// loadTaskMessages loads the subagent sessions of the task calls in msgs.
+func (m *messagesCmp) loadTaskMessages(msgs []message.Message) {
+	for _, msg := range msgs {
+		for _, tc := range msg.ToolCalls() {
+			if tc.Name == agent.TaskToolName {
+				if taskMsgs, err := m.app.Messages.List(context.Background(), tc.ID); err == nil {
+					m.taskMessages[tc.ID] = taskMsgs
+					m.subagentCalls[tc.ID] = subagentCall{toolCallID: tc.ID, parentSessionID: m.session.ID}
+				}
+			}
+		}
+	}
+}
+
+// loadOlderAtTop fetches the page of history before the oldest loaded
+// message once the user has scrolled to the top of the view.
+func (m *messagesCmp) loadOlderAtTop() tea.Cmd {
+	if !m.hasOlder || m.loadingOlder || len(m.messages) == 0 || !m.viewport.AtTop() {
+		return nil
+	}
+	m.loadingOlder = true
+	messages := m.app.Messages
+	sessionID := m.session.ID
+	beforeID := m.messages[0].ID
+	return func() tea.Msg {
+		msgs, err := messages.ListBefore(context.Background(), sessionID, beforeID, historyPageSize)
+		return olderMessagesLoadedMsg{sessionID: sessionID, msgs: msgs, err: err}
+	}
+}
+
 func (m *messagesCmp) BindingKeys() []key.Binding {
 	return []key.Binding{
 		m.viewport.KeyMap.PageDown,
@@ -836,6 +1045,8 @@ func NewMessagesCmp(app *app.App) tea.Model {
 		app:           app,
 		cachedContent: make(map[string]cacheItem),
 		taskMessages:  make(map[string][]message.Message),
+		subagentCalls: make(map[string]subagentCall),
+		liveOutput:    make(map[string]string),
 		viewport:      vp,
 		spinner:       s,
 		attachments:   attachmets,
@@ -21,6 +21,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/todo"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 	"github.com/opencode-ai/opencode/internal/tui/theme"
 )
@@ -49,6 +50,62 @@ func toMarkdown(content string, focused bool, width int) string {
 	return rendered
 }
 
+// renderCitations inserts a numbered marker after every cited span of
+// content and returns one source line per distinct piece of evidence. File
+// sources are terminal hyperlinks, so supporting terminals open the file.
+func renderCitations(content string, cites []message.Citation, width int) (string, []string) {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	type sourceKey struct {
+		toolCallID, path   string
+		startLine, endLine int
+	}
+	numbers := make(map[sourceKey]int)
+	markers := make([]int, len(cites))
+	var sources []string
+	for i, c := range cites {
+		k := sourceKey{c.ToolCallID, c.Path, c.StartLine, c.EndLine}
+		n, ok := numbers[k]
+		if !ok {
+			n = len(numbers) + 1
+			numbers[k] = n
+			sources = append(sources, renderCitationSource(n, c, width, baseStyle.Foreground(t.TextMuted())))
+		}
+		markers[i] = n
+	}
+
+	// Insert from the end so earlier offsets stay valid.
+	for i := len(cites) - 1; i >= 0; i-- {
+		c := cites[i]
+		if c.Start < 0 || c.End > len(content) || c.Start >= c.End {
+			continue
+		}
+		marker := fmt.Sprintf(" [%d]", markers[i])
+		if strings.HasSuffix(content[c.Start:c.End], "```") {
+			// A marker on the closing fence line would break the fence.
+			marker = fmt.Sprintf("\n[%d]", markers[i])
+		}
+		content = content[:c.End] + marker + content[c.End:]
+	}
+	return content, sources
+}
+
+func renderCitationSource(n int, c message.Citation, width int, style lipgloss.Style) string {
+	label := fmt.Sprintf("%s output", c.ToolName)
+	if c.Path != "" {
+		label = removeWorkingDirPrefix(c.Path)
+		switch {
+		case c.StartLine > 0 && c.EndLine > c.StartLine:
+			label = fmt.Sprintf("%s:%d-%d", label, c.StartLine, c.EndLine)
+		case c.StartLine > 0:
+			label = fmt.Sprintf("%s:%d", label, c.StartLine)
+		}
+		style = style.Hyperlink("file://" + filepath.ToSlash(c.Path))
+	}
+	return style.Width(width - 1).Render(fmt.Sprintf(" [%d] %s (%s)", n, label, c.ToolName))
+}
+
 func renderMessage(msg string, isUser bool, isFocused bool, width int, info ...string) string {
 	t := theme.CurrentTheme()
 
@@ -66,7 +123,7 @@ func renderMessage(msg string, isUser bool, isFocused bool, width int, info ...s
 
 	// Apply markdown formatting and handle background color
 	parts := []string{
-		styles.ForceReplaceBackgroundWithLipgloss(toMarkdown(msg, isFocused, width-1), t.Background()),
+		styles.ForceReplaceBackgroundWithLipgloss(cachedMarkdown(msg, width-1), t.Background()),
 	}
 
 	// Remove newline at the end
@@ -112,13 +169,14 @@ func renderUserMessage(msg message.Message, isFocused bool, width int, position
 		}
 		styledAttachments = append(styledAttachments, attachmentStyles.Render(filename))
 	}
-	content := ""
+	var info []string
 	if len(styledAttachments) > 0 {
-		attachmentContent := styles.BaseStyle().Width(width).Render(lipgloss.JoinHorizontal(lipgloss.Left, styledAttachments...))
-		content = renderMessage(textContent, true, isFocused, width, attachmentContent)
-	} else {
-		content = renderMessage(textContent, true, isFocused, width)
+		info = append(info, styles.BaseStyle().Width(width).Render(lipgloss.JoinHorizontal(lipgloss.Left, styledAttachments...)))
+	}
+	if msg.IsPinned() {
+		info = append(info, styles.BaseStyle().Width(width-1).Foreground(t.TextMuted()).Render(" pinned: kept verbatim through compaction"))
 	}
+	content := renderMessage(textContent, true, isFocused, width, info...)
 	userMsg := uiMessage{
 		ID:          msg.ID,
 		messageType: userMessageType,
@@ -203,7 +261,7 @@ func renderShellOutputMessage(msg message.Message, text string, width int, posit
 	resultContent := truncateHeight(inner, maxResultHeight)
 	resultContent = fmt.Sprintf("```bash\n%s\n```", resultContent)
 	rendered := styles.ForceReplaceBackgroundWithLipgloss(
-		toMarkdown(resultContent, true, width-2),
+		cachedMarkdown(resultContent, width-2),
 		t.Background(),
 	)
 	rendered = strings.TrimSuffix(rendered, "\n")
@@ -229,8 +287,10 @@ func renderAssistantMessage(
 	msgIndex int,
 	allMessages []message.Message,
 	taskMessages map[string][]message.Message,
+	liveOutput map[string]string,
 	focusedUIMessageId string,
 	isSummary bool,
+	collapseTasks bool,
 	width int,
 	position int,
 ) []uiMessage {
@@ -273,12 +333,28 @@ func renderAssistantMessage(
 				Foreground(t.TextMuted()).
 				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "permission denied")),
 			)
+		case message.FinishReasonContentFilter:
+			info = append(info, baseStyle.
+				Width(width-1).
+				Foreground(t.Warning()).
+				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "blocked by content filter")),
+			)
 		}
 	}
+	images := msg.Images()
+	if len(images) > 0 {
+		info = append(renderImages(images, width-1), info...)
+	}
 	contentRendered := false
-	if strings.TrimSpace(content) != "" || (finished && finishData.Reason == message.FinishReasonEndTurn) {
-		if strings.TrimSpace(content) == "" {
+	if strings.TrimSpace(content) != "" || len(images) > 0 || (finished && finishData.Reason == message.FinishReasonEndTurn) {
+		if strings.TrimSpace(content) == "" && len(images) > 0 {
+			content = "*Generated image*"
+		} else if strings.TrimSpace(content) == "" {
 			content = "*Finished without output*"
+		} else if cites := msg.Citations(); len(cites) > 0 {
+			var sources []string
+			content, sources = renderCitations(content, cites, width)
+			info = append(sources, info...)
 		}
 		if isSummary {
 			info = append(info, baseStyle.Width(width-1).Foreground(t.TextMuted()).Render(" (summary)"))
@@ -308,8 +384,10 @@ func renderAssistantMessage(
 			toolCall,
 			allMessages,
 			taskMessages,
+			liveOutput,
 			focusedUIMessageId,
 			false,
+			collapseTasks,
 			width,
 			i+1,
 		)
@@ -335,8 +413,14 @@ func toolName(name string) string {
 	switch name {
 	case agent.TaskToolName:
 		return "Task"
+	case agent.FixDiagnosticsToolName:
+		return "Fix Diagnostics"
+	case agent.HandoffToolName:
+		return "Handoff"
 	case tools.BashToolName:
 		return "Bash"
+	case tools.RunTaskToolName:
+		return "Run Task"
 	case tools.EditToolName:
 		return "Edit"
 	case tools.MultiEditToolName:
@@ -347,8 +431,12 @@ func toolName(name string) string {
 		return "Glob"
 	case tools.GrepToolName:
 		return "Grep"
+	case tools.CodeSearchToolName:
+		return "Code Search"
 	case tools.LSToolName:
 		return "List"
+	case tools.TreeToolName:
+		return "Tree"
 	case tools.SourcegraphToolName:
 		return "Sourcegraph"
 	case tools.ReadToolName:
@@ -373,6 +461,10 @@ func toolName(name string) string {
 		return "Cancel Task"
 	case tools.CronListToolName:
 		return "List Scheduled"
+	case tools.MemoryWriteToolName:
+		return "Remember"
+	case tools.MemoryReadToolName:
+		return "Recall"
 	}
 	return name
 }
@@ -381,8 +473,14 @@ func getToolAction(name string) string {
 	switch name {
 	case agent.TaskToolName:
 		return "Preparing prompt..."
+	case agent.FixDiagnosticsToolName:
+		return "Collecting diagnostics..."
+	case agent.HandoffToolName:
+		return "Handing over..."
 	case tools.BashToolName:
 		return "Building command..."
+	case tools.RunTaskToolName:
+		return "Choosing task..."
 	case tools.EditToolName:
 		return "Preparing edit..."
 	case tools.MultiEditToolName:
@@ -393,8 +491,12 @@ func getToolAction(name string) string {
 		return "Finding files..."
 	case tools.GrepToolName:
 		return "Searching content..."
+	case tools.CodeSearchToolName:
+		return "Searching code..."
 	case tools.LSToolName:
 		return "Listing directory..."
+	case tools.TreeToolName:
+		return "Building tree..."
 	case tools.SourcegraphToolName:
 		return "Searching code..."
 	case tools.ReadToolName:
@@ -419,6 +521,10 @@ func getToolAction(name string) string {
 		return "Cancelling task..."
 	case tools.CronListToolName:
 		return "Listing tasks..."
+	case tools.MemoryWriteToolName:
+		return "Updating memory..."
+	case tools.MemoryReadToolName:
+		return "Recalling memories..."
 	}
 	return "Working..."
 }
@@ -494,6 +600,14 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 			toolParams = append(toolParams, "resumed", "true")
 		}
 		return renderParams(paramWidth, toolParams...)
+	case agent.HandoffToolName:
+		var params agent.HandoffParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Agent, "note", strings.ReplaceAll(params.Note, "\n", " "))
+	case tools.RunTaskToolName:
+		var params tools.RunTaskParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, strings.Join(append([]string{params.Target}, params.Args...), " "))
 	case tools.BashToolName:
 		var params tools.BashParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -560,10 +674,21 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		if params.Include != "" {
 			toolParams = append(toolParams, "include", params.Include)
 		}
+		if params.FileType != "" {
+			toolParams = append(toolParams, "type", params.FileType)
+		}
 		if params.LiteralText {
 			toolParams = append(toolParams, "literal", "true")
 		}
 		return renderParams(paramWidth, toolParams...)
+	case tools.CodeSearchToolName:
+		var params tools.CodeSearchParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		toolParams := []string{params.Query}
+		if params.Path != "" {
+			toolParams = append(toolParams, "path", params.Path)
+		}
+		return renderParams(paramWidth, toolParams...)
 	case tools.LSToolName:
 		var params tools.LSParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -572,6 +697,18 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 			path = "."
 		}
 		return renderParams(paramWidth, path)
+	case tools.TreeToolName:
+		var params tools.TreeParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		path := params.Path
+		if path == "" {
+			path = "."
+		}
+		toolParams := []string{path}
+		if params.Depth > 0 {
+			toolParams = append(toolParams, "depth", fmt.Sprintf("%d", params.Depth))
+		}
+		return renderParams(paramWidth, toolParams...)
 	case tools.SourcegraphToolName:
 		var params tools.SourcegraphParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -620,6 +757,18 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		filePath := removeWorkingDirPrefix(params.Path)
 		return renderParams(paramWidth, filePath)
+	case agent.FixDiagnosticsToolName:
+		var params agent.FixDiagnosticsParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		target := "project"
+		if params.FilePath != "" {
+			target = removeWorkingDirPrefix(params.FilePath)
+		}
+		toolParams := []string{target}
+		if params.MaxRounds > 0 {
+			toolParams = append(toolParams, "max_rounds", fmt.Sprintf("%d", params.MaxRounds))
+		}
+		return renderParams(paramWidth, toolParams...)
 	default:
 		input := strings.ReplaceAll(toolCall.Input, "\n", " ")
 		params = renderParams(paramWidth, input)
@@ -627,6 +776,19 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 	return params
 }
 
+// toolDuration renders how long a finished call took, from its metadata
+// envelope, when that is long enough to be worth showing.
+func toolDuration(response *message.ToolResult) string {
+	if response == nil {
+		return ""
+	}
+	meta, ok := tools.ParseResultMetadata(response.Metadata)
+	if !ok || meta.DurationMs < 1000 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", (time.Duration(meta.DurationMs) * time.Millisecond).Round(100*time.Millisecond))
+}
+
 func truncateHeight(content string, height int) string {
 	lines := strings.Split(content, "\n")
 	if len(lines) > height {
@@ -678,7 +840,7 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 			rendered = strings.TrimRight(rendered, "\n") + "\n" + footer
 		}
 		return rendered
-	case tools.BashToolName:
+	case tools.BashToolName, tools.RunTaskToolName:
 		resultContent = fmt.Sprintf("```bash\n%s\n```", resultContent)
 		return styles.ForceReplaceBackgroundWithLipgloss(
 			toMarkdown(resultContent, true, width),
@@ -713,7 +875,7 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 		)
 	case tools.GlobToolName:
 		return baseStyle.Width(width).Foreground(t.TextMuted()).Render(resultContent)
-	case tools.GrepToolName:
+	case tools.GrepToolName, tools.CodeSearchToolName:
 		return baseStyle.Width(width).Foreground(t.TextMuted()).Render(resultContent)
 	case tools.LSToolName:
 		return baseStyle.Width(width).Foreground(t.TextMuted()).Render(resultContent)
@@ -790,12 +952,88 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 	}
 }
 
+// renderLiveOutput shows the last lines a still running command printed.
+func renderLiveOutput(output string, width int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > maxResultHeight {
+		lines = lines[len(lines)-maxResultHeight:]
+	}
+	for i, line := range lines {
+		// Progress bars redraw their line after a carriage return.
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = ansi.Strip(line)
+	}
+	return styles.ForceReplaceBackgroundWithLipgloss(
+		toMarkdown(fmt.Sprintf("```bash\n%s\n```", strings.Join(lines, "\n")), true, width),
+		theme.CurrentTheme().Background(),
+	)
+}
+
+// streamingInputPreview returns the tail of a tool call's input while it
+// is still being generated: the partial JSON with string escapes decoded,
+// so long write and patch payloads read as the text being written.
+func streamingInputPreview(input string) string {
+	if input == "" {
+		return ""
+	}
+	var b strings.Builder
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		if c != '\\' || i+1 == len(input) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch input[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+		case 'u':
+			if i+4 < len(input) {
+				if r, err := strconv.ParseUint(input[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteString("\\u")
+		default:
+			b.WriteByte(input[i])
+		}
+	}
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	if len(lines) > maxResultHeight {
+		lines = lines[len(lines)-maxResultHeight:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderStreamingInput(preview string, width int) string {
+	return styles.ForceReplaceBackgroundWithLipgloss(
+		toMarkdown(fmt.Sprintf("```\n%s\n```", ansi.Strip(preview)), true, width),
+		theme.CurrentTheme().Background(),
+	)
+}
+
+func formatInputSize(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
+
 func renderToolMessage(
 	toolCall message.ToolCall,
 	allMessages []message.Message,
 	taskMessages map[string][]message.Message,
+	liveOutput map[string]string,
 	focusedUIMessageId string,
 	nested bool,
+	collapseTasks bool,
 	width int,
 	position int,
 ) uiMessage {
@@ -816,7 +1054,7 @@ func renderToolMessage(
 
 	response := findToolResponse(toolCall.ID, allMessages)
 	toolNameText := baseStyle.Foreground(t.TextMuted()).
-		Render(fmt.Sprintf("%s: ", toolName(toolCall.Name)))
+		Render(fmt.Sprintf("%s%s: ", toolName(toolCall.Name), toolDuration(response)))
 
 	// Show subagent badge for task tool calls
 	if toolCall.Name == agent.TaskToolName {
@@ -834,12 +1072,19 @@ func renderToolMessage(
 		if progressWidth < 0 {
 			progressWidth = 0
 		}
+		if toolCall.Input != "" {
+			toolAction = fmt.Sprintf("%s (%s)", toolAction, formatInputSize(len(toolCall.Input)))
+		}
 		progressText := baseStyle.
 			Width(progressWidth).
 			Foreground(t.TextMuted()).
 			Render(fmt.Sprintf(" %s", toolAction))
 
-		content := style.Render(lipgloss.JoinHorizontal(lipgloss.Left, toolNameText, progressText))
+		header := lipgloss.JoinHorizontal(lipgloss.Left, toolNameText, progressText)
+		content := style.Render(header)
+		if preview := streamingInputPreview(toolCall.Input); preview != "" && !nested {
+			content = style.Render(lipgloss.JoinVertical(lipgloss.Left, header, renderStreamingInput(preview, width-2)))
+		}
 		toolMsg := uiMessage{
 			messageType: toolMessageType,
 			position:    position,
@@ -856,7 +1101,7 @@ func renderToolMessage(
 	params := renderToolParams(paramWidth, toolCall)
 	responseContent := ""
 	if response != nil {
-		responseContent = renderToolResponse(toolCall, *response, width-2)
+		responseContent = cachedToolResponse(toolCall, *response, width-2)
 		responseContent = strings.TrimSuffix(responseContent, "\n")
 
 		// Extract and render diagnostics summary from response content
@@ -864,6 +1109,8 @@ func renderToolMessage(
 		if diagSummary != "" {
 			responseContent = responseContent + "\n" + diagSummary
 		}
+	} else if output := liveOutput[toolCall.ID]; output != "" {
+		responseContent = renderLiveOutput(output, width-2)
 	} else {
 		responseContent = baseStyle.
 			Italic(true).
@@ -893,14 +1140,7 @@ func renderToolMessage(
 
 	if toolCall.Name == agent.TaskToolName {
 		if msgs, ok := taskMessages[toolCall.ID]; ok {
-			toolCalls := []message.ToolCall{}
-			for _, v := range msgs {
-				toolCalls = append(toolCalls, v.ToolCalls()...)
-			}
-			for _, call := range toolCalls {
-				rendered := renderToolMessage(call, []message.Message{}, taskMessages, focusedUIMessageId, true, width, 0)
-				parts = append(parts, rendered.content)
-			}
+			parts = append(parts, renderSubagentBlock(msgs, taskMessages, liveOutput, focusedUIMessageId, collapseTasks, response == nil, width)...)
 		}
 	}
 	if responseContent != "" && !nested {
@@ -928,6 +1168,60 @@ func renderToolMessage(
 	return toolMsg
 }
 
+// subagentTextLines is how many trailing lines of a running subagent's
+// latest text are shown under its task call.
+const subagentTextLines = 3
+
+// renderSubagentBlock renders the session of a subagent under the task call
+// running it: its tool calls, nested task calls included, and while it runs
+// the tail of the text it is writing. Collapsed, the block is a one-line
+// summary.
+func renderSubagentBlock(
+	msgs []message.Message,
+	taskMessages map[string][]message.Message,
+	liveOutput map[string]string,
+	focusedUIMessageId string,
+	collapse bool,
+	running bool,
+	width int,
+) []string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	var calls []message.ToolCall
+	text := ""
+	for _, msg := range msgs {
+		if msg.Role != message.Assistant {
+			continue
+		}
+		calls = append(calls, msg.ToolCalls()...)
+		if content := strings.TrimSpace(msg.Content().String()); content != "" {
+			text = content
+		}
+	}
+
+	if collapse {
+		summary := fmt.Sprintf(" └ %d tool calls hidden", len(calls))
+		if len(calls) == 1 {
+			summary = " └ 1 tool call hidden"
+		}
+		return []string{baseStyle.Foreground(t.TextMuted()).Render(summary)}
+	}
+
+	var parts []string
+	for _, call := range calls {
+		parts = append(parts, renderToolMessage(call, msgs, taskMessages, liveOutput, focusedUIMessageId, true, collapse, width, 0).content)
+	}
+	if running && text != "" {
+		lines := strings.Split(text, "\n")
+		for _, line := range lines[max(len(lines)-subagentTextLines, 0):] {
+			line = ansi.Truncate(strings.TrimRight(line, " \t"), max(width-5, 1), "…")
+			parts = append(parts, baseStyle.Foreground(t.TextMuted()).Render(" │ "+line))
+		}
+	}
+	return parts
+}
+
 func subagentBadge(agentType string, title string, isResumed bool) string {
 	t := theme.CurrentTheme()
 
@@ -1057,11 +1351,7 @@ func renderTodoChecklist(metadata string, fallback string, width int) string {
 	baseStyle := styles.BaseStyle()
 
 	var meta struct {
-		Todos []struct {
-			Content  string `json:"content"`
-			Status   string `json:"status"`
-			Priority string `json:"priority"`
-		} `json:"todos"`
+		Todos []todo.Item `json:"todos"`
 	}
 	if err := json.Unmarshal([]byte(metadata), &meta); err != nil || len(meta.Todos) == 0 {
 		return baseStyle.Width(width).Foreground(t.TextMuted()).Render(fallback)
@@ -1070,22 +1360,7 @@ func renderTodoChecklist(metadata string, fallback string, width int) string {
 	bg := t.Background()
 	var sb strings.Builder
 	for _, item := range meta.Todos {
-		var icon string
-		var style lipgloss.Style
-		switch item.Status {
-		case "completed":
-			icon = "✓"
-			style = baseStyle.Foreground(t.Success()).Background(bg).Strikethrough(true)
-		case "in_progress":
-			icon = "→"
-			style = baseStyle.Foreground(t.Primary()).Background(bg)
-		case "cancelled":
-			icon = "~"
-			style = baseStyle.Foreground(t.TextMuted()).Background(bg).Strikethrough(true)
-		default: // pending
-			icon = "○"
-			style = baseStyle.Foreground(t.Text()).Background(bg)
-		}
+		icon, style := todoItemStyle(item.Status)
 		iconStyle := baseStyle.Foreground(style.GetForeground()).Background(bg)
 		pad := baseStyle.Background(bg).Render("  ")
 		gap := baseStyle.Background(bg).Render(" ")
@@ -1,9 +1,19 @@
 package chat
 
 import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/session"
 )
 
 func TestRenderAssistantMessage_EmptyContentNoTextBlock(t *testing.T) {
@@ -74,7 +84,7 @@ func TestRenderAssistantMessage_EmptyContentNoTextBlock(t *testing.T) {
 				Parts: tt.parts,
 			}
 
-			results := renderAssistantMessage(msg, 0, nil, nil, "", false, 80, 0)
+			results := renderAssistantMessage(msg, 0, nil, nil, nil, "", false, false, 80, 0)
 
 			var textMessages, toolMessages int
 			for _, r := range results {
@@ -98,3 +108,193 @@ func TestRenderAssistantMessage_EmptyContentNoTextBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderCitationsMarkers(t *testing.T) {
+	content := "Tests fail with `[build failed]`:\n```\nFAIL\tgithub.com/x/cmd\n```\nSee `[build failed]` again."
+	first := strings.Index(content, "`[build failed]`")
+	fenceStart := strings.Index(content, "```")
+	fenceEnd := strings.LastIndex(content, "```") + 3
+	last := strings.LastIndex(content, "`[build failed]`")
+	cites := []message.Citation{
+		{Start: first, End: first + 16, ToolCallID: "bash-1", ToolName: "bash"},
+		{Start: fenceStart, End: fenceEnd, ToolCallID: "bash-2", ToolName: "bash"},
+		{Start: last, End: last + 16, ToolCallID: "bash-1", ToolName: "bash"},
+	}
+
+	got, sources := renderCitations(content, cites, 80)
+	want := "Tests fail with `[build failed]` [1]:\n```\nFAIL\tgithub.com/x/cmd\n```\n[2]\nSee `[build failed]` [1] again."
+	if got != want {
+		t.Fatalf("content =\n%q\nwant\n%q", got, want)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("got %d source lines, want 2", len(sources))
+	}
+}
+
+func TestAppendLiveOutput(t *testing.T) {
+	buf := appendLiveOutput("", "one\n")
+	buf = appendLiveOutput(buf, "two\n")
+	if buf != "one\ntwo\n" {
+		t.Fatalf("got %q", buf)
+	}
+
+	long := strings.Repeat("x", liveOutputLimit) + "\nlast\n"
+	if got := appendLiveOutput("head\n", long); got != "last\n" {
+		t.Fatalf("expected output before the cut line to be dropped, got %q", got)
+	}
+}
+
+func TestRenderToolMessage_LiveOutput(t *testing.T) {
+	call := message.ToolCall{ID: "call-1", Name: "bash", Input: `{"command":"make"}`, Finished: true}
+	live := map[string]string{"call-1": "building\r50%\r100%\ndone\n"}
+
+	content := renderToolMessage(call, nil, nil, live, "", false, false, 80, 0).content
+	if !strings.Contains(content, "100%") || !strings.Contains(content, "done") {
+		t.Fatalf("expected live output in %q", content)
+	}
+	if strings.Contains(content, "50%") || strings.Contains(content, "Waiting for response") {
+		t.Fatalf("unexpected content in %q", content)
+	}
+}
+
+func TestStreamingInputPreview(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "empty", input: "", want: ""},
+		{name: "escapes decoded", input: `{"content":"a\tb\n\"c\" é\\`, want: "{\"content\":\"a\tb\n\"c\" é\\"},
+		{name: "tail kept", input: strings.Repeat(`line\n`, 20) + "last", want: strings.Repeat("line\n", maxResultHeight-1) + "last"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamingInputPreview(tt.input); got != tt.want {
+				t.Errorf("streamingInputPreview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSubagentBlock(t *testing.T) {
+	msgs := []message.Message{
+		{ID: "m1", Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "c1", Name: "glob", Input: `{"pattern":"*.go"}`, Finished: true},
+			message.ToolCall{ID: "c2", Name: "grep", Input: `{"pattern":"TODO"}`, Finished: true},
+		}},
+		{ID: "m2", Role: message.Tool, Parts: []message.ContentPart{
+			message.ToolResult{ToolCallID: "c1", Name: "glob", Content: "main.go"},
+		}},
+		{ID: "m3", Role: message.Assistant, Parts: []message.ContentPart{
+			message.TextContent{Text: "first\nsecond\nthird\nfourth"},
+		}},
+	}
+
+	running := ansi.Strip(strings.Join(renderSubagentBlock(msgs, nil, nil, "", false, true, 80), "\n"))
+	for _, want := range []string{"Glob", "Grep", "second", "fourth"} {
+		if !strings.Contains(running, want) {
+			t.Errorf("expected %q in running block %q", want, running)
+		}
+	}
+	if strings.Contains(running, "first") {
+		t.Errorf("expected only the last %d lines of text in %q", subagentTextLines, running)
+	}
+
+	finished := ansi.Strip(strings.Join(renderSubagentBlock(msgs, nil, nil, "", false, false, 80), "\n"))
+	if strings.Contains(finished, "fourth") {
+		t.Errorf("expected no text once the task finished, got %q", finished)
+	}
+
+	collapsed := renderSubagentBlock(msgs, nil, nil, "", true, true, 80)
+	if len(collapsed) != 1 || !strings.Contains(ansi.Strip(collapsed[0]), "2 tool calls hidden") {
+		t.Errorf("expected a one-line summary, got %q", collapsed)
+	}
+}
+
+func TestApplySubagentEvent(t *testing.T) {
+	m := &messagesCmp{
+		session: session.Session{ID: "parent"},
+		messages: []message.Message{
+			{ID: "p1", SessionID: "parent", Role: message.Assistant, Parts: []message.ContentPart{
+				message.ToolCall{ID: "call-1", Name: agent.TaskToolName, Finished: true},
+			}},
+		},
+		taskMessages:  make(map[string][]message.Message),
+		subagentCalls: make(map[string]subagentCall),
+	}
+	event := func(parent, callID, sessionID string, msg message.Message) agent.SubagentEvent {
+		return agent.SubagentEvent{
+			AgentEvent:      agent.AgentEvent{Type: agent.AgentEventTypeMessage, SessionID: sessionID, Message: msg},
+			ParentSessionID: parent,
+			ToolCallID:      callID,
+		}
+	}
+
+	// A resumed task streams into the new call under its earlier session.
+	child := message.Message{ID: "t1", SessionID: "task-0", Role: message.Assistant}
+	if got := m.applySubagentEvent(pubsub.CreatedEvent, event("parent", "call-1", "task-0", child)); got != "p1" {
+		t.Fatalf("got owner %q, want p1", got)
+	}
+	child.Parts = []message.ContentPart{message.TextContent{Text: "working"}}
+	m.applySubagentEvent(pubsub.UpdatedEvent, event("parent", "call-1", "task-0", child))
+	if msgs := m.taskMessages["call-1"]; len(msgs) != 1 || msgs[0].Content().String() != "working" {
+		t.Fatalf("expected the streamed message to be updated in place, got %+v", msgs)
+	}
+
+	// A subagent started by the subagent resolves to the same shown message.
+	nested := message.Message{ID: "n1", SessionID: "call-2", Role: message.Assistant}
+	if got := m.applySubagentEvent(pubsub.CreatedEvent, event("task-0", "call-2", "call-2", nested)); got != "p1" {
+		t.Fatalf("got owner %q for nested task, want p1", got)
+	}
+	if len(m.taskMessages["call-2"]) != 1 {
+		t.Fatalf("expected nested task message to be recorded")
+	}
+
+	if got := m.applySubagentEvent(pubsub.CreatedEvent, event("elsewhere", "call-9", "task-9", nested)); got != "" {
+		t.Fatalf("expected events of other sessions to be ignored, got owner %q", got)
+	}
+	if _, ok := m.taskMessages["call-9"]; ok {
+		t.Fatalf("recorded a message of another session")
+	}
+}
+
+func TestRenderAssistantMessageImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.png")
+	src := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for x := range 8 {
+		for y := range 4 {
+			src.Set(x, y, color.RGBA{R: uint8(x * 30), B: uint8(y * 60), A: 255})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, src); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	preview := imagePreview(path, 40)
+	if got := strings.Count(ansi.Strip(preview), "▀"); got != 8*2 {
+		t.Fatalf("preview has %d cells, want 16 (8 columns, 2 rows):\n%s", got, preview)
+	}
+	if imagePreview(filepath.Join(t.TempDir(), "missing.png"), 40) != "" {
+		t.Fatal("a missing image must render no preview")
+	}
+
+	msg := message.Message{
+		ID:   "m1",
+		Role: message.Assistant,
+		Parts: []message.ContentPart{
+			message.ImageContent{Path: path, MIMEType: "image/png"},
+		},
+	}
+	out := renderAssistantMessage(msg, 0, []message.Message{msg}, nil, nil, "", false, false, 80, 0)
+	if len(out) != 1 {
+		t.Fatalf("expected one rendered message, got %d", len(out))
+	}
+	if !strings.Contains(ansi.Strip(out[0].content), "out.png") {
+		t.Fatalf("image link missing:\n%s", out[0].content)
+	}
+}
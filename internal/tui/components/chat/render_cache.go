@@ -0,0 +1,128 @@
+package chat
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+)
+
+// renderCacheMaxBytes bounds the rendered output kept in memory. Large tool
+// results dominate the size, so the budget is in bytes rather than entries.
+const renderCacheMaxBytes = 32 * 1024 * 1024
+
+// renderCache is a process-wide LRU of rendered markdown and tool results.
+// Unlike messagesCmp.cachedContent it survives session switches, so going
+// back to a session with a long history doesn't re-run glamour and chroma
+// over every part. Entries are keyed by a hash of the input together with
+// the width and theme, so stale entries are never hit, only evicted.
+type renderCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	order    *list.List
+	entries  map[[sha256.Size]byte]*list.Element
+}
+
+type renderCacheEntry struct {
+	key   [sha256.Size]byte
+	value string
+}
+
+var sharedRenderCache = newRenderCache(renderCacheMaxBytes)
+
+func newRenderCache(maxBytes int) *renderCache {
+	return &renderCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// getOrRender returns the cached rendering for parts at width under the
+// current theme, calling render on a miss. render runs without the lock
+// held; concurrent misses for the same key may both render.
+func (c *renderCache) getOrRender(width int, parts []string, render func() string) string {
+	key := renderCacheKey(theme.CurrentThemeName(), width, parts)
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		value := el.Value.(*renderCacheEntry).value
+		c.mu.Unlock()
+		return value
+	}
+	c.mu.Unlock()
+
+	value := render()
+	c.put(key, value)
+	return value
+}
+
+func (c *renderCache) put(key [sha256.Size]byte, value string) {
+	if len(value) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&renderCacheEntry{key: key, value: value})
+	c.size += len(value)
+	for c.size > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*renderCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= len(entry.value)
+	}
+}
+
+func (c *renderCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// renderCacheKey hashes the parts length-prefixed, so ("ab", "c") and
+// ("a", "bc") produce different keys.
+func renderCacheKey(themeName string, width int, parts []string) [sha256.Size]byte {
+	h := sha256.New()
+	var buf [8]byte
+	write := func(s string) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(s)))
+		h.Write(buf[:])
+		h.Write([]byte(s))
+	}
+	write(themeName)
+	binary.LittleEndian.PutUint64(buf[:], uint64(width))
+	h.Write(buf[:])
+	for _, p := range parts {
+		write(p)
+	}
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+func cachedMarkdown(content string, width int) string {
+	return sharedRenderCache.getOrRender(width, []string{"markdown", content}, func() string {
+		return toMarkdown(content, false, width)
+	})
+}
+
+func cachedToolResponse(toolCall message.ToolCall, response message.ToolResult, width int) string {
+	isError := "0"
+	if response.IsError {
+		isError = "1"
+	}
+	parts := []string{"tool", toolCall.Name, toolCall.Input, response.Content, response.Metadata, isError}
+	return sharedRenderCache.getOrRender(width, parts, func() string {
+		return renderToolResponse(toolCall, response, width)
+	})
+}
@@ -0,0 +1,55 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCacheHitsAndEvicts(t *testing.T) {
+	c := newRenderCache(10)
+	calls := 0
+	render := func(v string) func() string {
+		return func() string {
+			calls++
+			return v
+		}
+	}
+
+	if got := c.getOrRender(80, []string{"a"}, render("aaaa")); got != "aaaa" {
+		t.Fatalf("got %q", got)
+	}
+	if got := c.getOrRender(80, []string{"a"}, render("other")); got != "aaaa" || calls != 1 {
+		t.Fatalf("expected cache hit, got %q after %d renders", got, calls)
+	}
+	// Width is part of the key.
+	c.getOrRender(40, []string{"a"}, render("bbbb"))
+	if calls != 2 {
+		t.Fatalf("different width should miss, renders = %d", calls)
+	}
+
+	// Touch the 80-wide entry so the 40-wide one is the eviction victim.
+	c.getOrRender(80, []string{"a"}, render("x"))
+	c.getOrRender(80, []string{"c"}, render("cccc"))
+	if c.len() != 2 {
+		t.Fatalf("len = %d, want 2 after eviction", c.len())
+	}
+	c.getOrRender(80, []string{"a"}, render("x"))
+	if calls != 3 {
+		t.Fatalf("recently used entry was evicted, renders = %d", calls)
+	}
+
+	// Values larger than the whole budget are rendered but never stored.
+	c.getOrRender(80, []string{"big"}, render(strings.Repeat("z", 11)))
+	if c.len() != 2 {
+		t.Fatalf("oversized value was cached, len = %d", c.len())
+	}
+}
+
+func TestRenderCacheKeyIsUnambiguous(t *testing.T) {
+	if renderCacheKey("dark", 80, []string{"ab", "c"}) == renderCacheKey("dark", 80, []string{"a", "bc"}) {
+		t.Fatal("part boundaries must affect the key")
+	}
+	if renderCacheKey("dark", 80, []string{"x"}) == renderCacheKey("light", 80, []string{"x"}) {
+		t.Fatal("theme must affect the key")
+	}
+}
@@ -14,9 +14,11 @@ import (
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/diff"
 	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/todo"
 	"github.com/opencode-ai/opencode/internal/tui/components/dialog"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 	"github.com/opencode-ai/opencode/internal/tui/theme"
@@ -37,6 +39,9 @@ type sidebarCmp struct {
 	filesCh         <-chan pubsub.Event[history.File]
 	initialVersions map[string]history.File
 	subCancel       context.CancelFunc
+	usage           *usagePanel
+	showUsage       bool
+	todos           []todo.Item
 }
 
 func (m *sidebarCmp) waitForFileEvent() tea.Cmd {
@@ -55,6 +60,9 @@ func (m *sidebarCmp) waitForFileEvent() tea.Cmd {
 func (m *sidebarCmp) Init() tea.Cmd {
 	var cmds []tea.Cmd
 
+	m.usage.load(context.Background(), m.sessions, m.session.ID)
+	m.loadTodos()
+
 	if m.history != nil {
 		ctx, cancel := context.WithCancel(context.Background())
 		m.subCancel = cancel
@@ -87,6 +95,8 @@ func (m *sidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.subCancel = cancel
 			m.filesCh = m.history.Subscribe(ctx)
 			m.loadModifiedFiles(ctx)
+			m.usage.load(ctx, m.sessions, m.session.ID)
+			m.loadTodos()
 			return m, m.waitForFileEvent()
 		}
 	case pubsub.Event[session.Session]:
@@ -113,6 +123,16 @@ func (m *sidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.processFileChanges(ctx, msg.Payload)
 		}
 		return m, m.waitForFileEvent()
+	case pubsub.Event[agent.UsageEvent]:
+		if m.isInSessionTree(msg.Payload.SessionID) {
+			m.usage.record(msg.Payload, msg.Payload.SessionID == m.session.ID)
+		}
+	case pubsub.Event[todo.List]:
+		if msg.Payload.SessionID == m.session.ID {
+			m.todos = msg.Payload.Items
+		}
+	case ToggleUsagePanelMsg:
+		m.showUsage = !m.showUsage
 	case AgentChangedMsg:
 		reg := agentregistry.GetRegistry()
 		m.lspEnabled = reg.IsToolEnabled(msg.Name, tools.LSPToolName)
@@ -154,6 +174,20 @@ func (m *sidebarCmp) View() tea.View {
 		sections = append(sections, " ")
 	}
 
+	if m.showUsage {
+		var contextWindow int64
+		if m.app != nil {
+			contextWindow = m.app.ActiveAgent().Model().ContextWindow
+		}
+		sections = append(sections, m.usage.view(cw, m.session.PromptTokens+m.session.CompletionTokens, contextWindow))
+		sections = append(sections, " ")
+	}
+
+	if len(m.todos) > 0 {
+		sections = append(sections, renderTodoPanel(m.todos, cw))
+		sections = append(sections, " ")
+	}
+
 	usedHeight := 0
 	for _, s := range sections {
 		usedHeight += lipgloss.Height(s)
@@ -297,6 +331,15 @@ func (m *sidebarCmp) modifiedFiles(availableHeight int) string {
 		)
 }
 
+// loadTodos reads the todo list of the session shown; later changes arrive
+// as todo.List events.
+func (m *sidebarCmp) loadTodos() {
+	m.todos = nil
+	if m.app != nil && m.app.Todos != nil && m.session.ID != "" {
+		m.todos = m.app.Todos.Get(m.session.ID)
+	}
+}
+
 func (m *sidebarCmp) SetSize(width, height int) tea.Cmd {
 	m.width = width
 	m.height = height
@@ -321,6 +364,8 @@ func NewSidebarCmp(s session.Session, sessions session.Service, history history.
 		history:    history,
 		app:        a,
 		lspEnabled: lspEnabled,
+		usage:      newUsagePanel(),
+		showUsage:  config.Get() != nil && config.Get().TUI.UsagePanel,
 	}
 }
 
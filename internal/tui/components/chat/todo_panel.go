@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"fmt"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/opencode-ai/opencode/internal/todo"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+)
+
+// maxSidebarTodos caps how many items the sidebar lists, so a long plan
+// leaves room for the modified files below it.
+const maxSidebarTodos = 10
+
+// todoItemStyle returns the icon and text style of a todo item's status.
+func todoItemStyle(status string) (string, lipgloss.Style) {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	bg := t.Background()
+	switch status {
+	case "completed":
+		return "✓", baseStyle.Foreground(t.Success()).Background(bg).Strikethrough(true)
+	case "in_progress":
+		return "→", baseStyle.Foreground(t.Primary()).Background(bg)
+	case "cancelled":
+		return "~", baseStyle.Foreground(t.TextMuted()).Background(bg).Strikethrough(true)
+	default: // pending
+		return "○", baseStyle.Foreground(t.Text()).Background(bg)
+	}
+}
+
+// renderTodoPanel renders the sidebar's view of a session's todo list: a
+// header with the progress, then one truncated line per item. Finished
+// items are dropped first when the list is longer than maxSidebarTodos.
+func renderTodoPanel(items []todo.Item, width int) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	done := len(items) - todo.Remaining(items)
+	header := baseStyle.
+		Width(width).
+		Foreground(t.Primary()).
+		Bold(true).
+		Render(fmt.Sprintf("Todo %d/%d", done, len(items)))
+
+	shown := items
+	if len(shown) > maxSidebarTodos {
+		shown = nil
+		for _, item := range items {
+			if item.Status != "completed" && item.Status != "cancelled" {
+				shown = append(shown, item)
+			}
+		}
+		if len(shown) > maxSidebarTodos {
+			shown = shown[:maxSidebarTodos]
+		}
+	}
+
+	lines := []string{header}
+	for _, item := range shown {
+		icon, style := todoItemStyle(item.Status)
+		iconStyle := baseStyle.Foreground(style.GetForeground()).Background(t.Background())
+		content := ansi.Truncate(item.Content, max(width-2, 1), "…")
+		lines = append(lines, iconStyle.Render(icon)+baseStyle.Render(" ")+style.Render(content))
+	}
+	if hidden := len(items) - len(shown); hidden > 0 {
+		lines = append(lines, baseStyle.Foreground(t.TextMuted()).Width(width).Render(fmt.Sprintf("%d more...", hidden)))
+	}
+	return baseStyle.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
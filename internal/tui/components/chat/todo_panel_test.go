@@ -0,0 +1,55 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/opencode-ai/opencode/internal/todo"
+)
+
+func TestRenderTodoPanel(t *testing.T) {
+	items := []todo.Item{
+		{Content: "add the migration", Status: "completed"},
+		{Content: "backfill old rows with a rather long description", Status: "in_progress"},
+		{Content: "update the docs", Status: "pending"},
+	}
+	out := ansi.Strip(renderTodoPanel(items, 30))
+	if !strings.Contains(out, "Todo 1/3") {
+		t.Fatalf("missing progress header:\n%s", out)
+	}
+	for _, want := range []string{"✓ add the migration", "→ backfill old rows", "○ update the docs"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if w := ansi.StringWidth(line); w > 30 {
+			t.Errorf("line wider than the sidebar (%d): %q", w, line)
+		}
+	}
+}
+
+func TestRenderTodoPanelDropsFinishedItemsFirst(t *testing.T) {
+	var items []todo.Item
+	for i := range 8 {
+		items = append(items, todo.Item{Content: fmt.Sprintf("done %d", i), Status: "completed"})
+	}
+	for i := range 4 {
+		items = append(items, todo.Item{Content: fmt.Sprintf("open %d", i), Status: "pending"})
+	}
+	out := ansi.Strip(renderTodoPanel(items, 40))
+	if strings.Contains(out, "done 0") {
+		t.Errorf("finished items should make room for open ones:\n%s", out)
+	}
+	for i := range 4 {
+		if !strings.Contains(out, fmt.Sprintf("open %d", i)) {
+			t.Errorf("open item %d missing:\n%s", i, out)
+		}
+	}
+	if !strings.Contains(out, "8 more...") {
+		t.Errorf("hidden count missing:\n%s", out)
+	}
+}
@@ -0,0 +1,206 @@
+package chat
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+)
+
+// ToggleUsagePanelMsg shows or hides the usage panel in the sidebar.
+type ToggleUsagePanelMsg struct{}
+
+// maxUsageCycles caps how many requests the sparkline remembers.
+const maxUsageCycles = 120
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// usageTotals is the token usage and spend of one agent, or of the whole
+// session tree.
+type usageTotals struct {
+	prompt     int64
+	completion int64
+	cacheRead  int64
+	cacheWrite int64
+	cost       float64
+}
+
+func (u *usageTotals) add(prompt, completion, cacheRead, cacheWrite int64, cost float64) {
+	u.prompt += prompt
+	u.completion += completion
+	u.cacheRead += cacheRead
+	u.cacheWrite += cacheWrite
+	u.cost += cost
+}
+
+// usagePanel keeps the token usage of the session tree shown in the
+// sidebar. It starts from the recorded breakdown and follows the
+// UsageEvents published as requests complete; the sparkline only covers
+// requests made since the session was opened.
+type usagePanel struct {
+	total         usageTotals
+	agents        map[string]*usageTotals
+	contextWindow int64
+	cycles        []int64
+}
+
+func newUsagePanel() *usagePanel {
+	return &usagePanel{agents: map[string]*usageTotals{}}
+}
+
+// load resets the panel to the usage recorded for the tree containing
+// sessionID.
+func (u *usagePanel) load(ctx context.Context, sessions session.Service, sessionID string) {
+	u.total = usageTotals{}
+	u.agents = map[string]*usageTotals{}
+	u.cycles = nil
+	if sessions == nil || sessionID == "" {
+		return
+	}
+	entries, err := sessions.UsageBreakdown(ctx, sessionID)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.Category != session.UsageAgent {
+			continue
+		}
+		u.agentTotals(e.Name).add(e.InputTokens, e.OutputTokens, e.CacheReadTokens, e.CacheCreationTokens, e.Cost)
+		u.total.add(e.InputTokens, e.OutputTokens, e.CacheReadTokens, e.CacheCreationTokens, e.Cost)
+	}
+}
+
+func (u *usagePanel) agentTotals(name string) *usageTotals {
+	t, ok := u.agents[name]
+	if !ok {
+		t = &usageTotals{}
+		u.agents[name] = t
+	}
+	return t
+}
+
+// record adds one request of the session tree. main says whether it was
+// made in the session itself rather than a subagent's, whose context
+// window doesn't apply.
+func (u *usagePanel) record(e agent.UsageEvent, main bool) {
+	usage := e.Usage
+	u.agentTotals(string(e.AgentID)).add(usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens, usage.CacheCreationTokens, e.Cost)
+	u.total.add(usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens, usage.CacheCreationTokens, e.Cost)
+	if main {
+		u.contextWindow = e.Model.ContextWindow
+	}
+	u.cycles = append(u.cycles, usage.InputTokens+usage.OutputTokens+usage.CacheReadTokens+usage.CacheCreationTokens)
+	if len(u.cycles) > maxUsageCycles {
+		u.cycles = u.cycles[len(u.cycles)-maxUsageCycles:]
+	}
+}
+
+// view renders the panel; contextTokens is what the session's last request
+// took up and contextWindow the active model's window, used until a
+// request of the session reports its own.
+func (u *usagePanel) view(width int, contextTokens, contextWindow int64) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	muted := baseStyle.Foreground(t.TextMuted())
+	if u.contextWindow > 0 {
+		contextWindow = u.contextWindow
+	}
+
+	lines := []string{baseStyle.Width(width).Foreground(t.Primary()).Bold(true).Render("Usage")}
+	if contextWindow > 0 {
+		pct := min(1, float64(contextTokens)/float64(contextWindow))
+		label := fmt.Sprintf(" %3d%%", int(pct*100))
+		barWidth := max(4, width-lipgloss.Width(label))
+		filled := int(pct * float64(barWidth))
+		color := t.Success()
+		if pct > 0.8 {
+			color = t.Warning()
+		}
+		lines = append(lines,
+			baseStyle.Foreground(color).Render(strings.Repeat("█", filled))+
+				muted.Render(strings.Repeat("░", barWidth-filled))+
+				baseStyle.Render(label),
+			muted.Render(fmt.Sprintf("context %s / %s", tokenCount(contextTokens), tokenCount(contextWindow))),
+		)
+	}
+
+	lines = append(lines, baseStyle.Render(fmt.Sprintf("Session  $%.4f", u.total.cost)))
+	for _, l := range totalsLines(u.total) {
+		lines = append(lines, muted.Render(l))
+	}
+
+	names := make([]string, 0, len(u.agents))
+	for name := range u.agents {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(u.agents[b].cost, u.agents[a].cost), cmp.Compare(a, b))
+	})
+	for _, name := range names {
+		a := u.agents[name]
+		lines = append(lines, baseStyle.Render(fmt.Sprintf("%s  $%.4f", name, a.cost)))
+		for _, l := range totalsLines(*a) {
+			lines = append(lines, muted.Render(l))
+		}
+	}
+
+	if len(u.cycles) > 0 {
+		lines = append(lines,
+			muted.Render("tokens per request"),
+			baseStyle.Foreground(t.Secondary()).Render(sparkline(u.cycles, width)),
+		)
+	}
+
+	for i, l := range lines {
+		lines[i] = baseStyle.Width(width).MaxWidth(width).Render(l)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func totalsLines(u usageTotals) []string {
+	lines := []string{fmt.Sprintf("  in %s  out %s", tokenCount(u.prompt), tokenCount(u.completion))}
+	if u.cacheRead > 0 || u.cacheWrite > 0 {
+		lines = append(lines, fmt.Sprintf("  cache read %s  write %s", tokenCount(u.cacheRead), tokenCount(u.cacheWrite)))
+	}
+	return lines
+}
+
+// sparkline draws the last width values scaled to the largest of them.
+func sparkline(values []int64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	peak := slices.Max(values)
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if peak > 0 {
+			i = int(v * int64(len(sparkBlocks)-1) / peak)
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// tokenCount returns a short token count such as 950, 12.3k or 1.2M.
+func tokenCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int64{0, 50, 100}, 10); got != "▁▄█" {
+		t.Fatalf("got %q", got)
+	}
+	if got := sparkline([]int64{100, 0, 0, 100}, 2); got != "▁█" {
+		t.Fatalf("only the last values should fit, got %q", got)
+	}
+	if got := sparkline([]int64{0, 0}, 5); got != "▁▁" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestUsagePanelRecord(t *testing.T) {
+	u := newUsagePanel()
+	u.record(agent.UsageEvent{
+		AgentID: "coder",
+		Model:   models.Model{ContextWindow: 200_000},
+		Usage:   provider.TokenUsage{InputTokens: 100, OutputTokens: 20, CacheReadTokens: 1000},
+		Cost:    0.5,
+	}, true)
+	u.record(agent.UsageEvent{
+		AgentID: "explorer",
+		Model:   models.Model{ContextWindow: 32_000},
+		Usage:   provider.TokenUsage{InputTokens: 40, OutputTokens: 10},
+		Cost:    0.1,
+	}, false)
+
+	if u.total.prompt != 140 || u.total.completion != 30 || u.total.cacheRead != 1000 {
+		t.Fatalf("unexpected totals %+v", u.total)
+	}
+	if a := u.agents["explorer"]; a == nil || a.cost != 0.1 {
+		t.Fatalf("unexpected explorer totals %+v", a)
+	}
+	if u.contextWindow != 200_000 {
+		t.Fatalf("a subagent's model must not set the context window, got %d", u.contextWindow)
+	}
+	if len(u.cycles) != 2 || u.cycles[0] != 1120 {
+		t.Fatalf("unexpected cycles %v", u.cycles)
+	}
+}
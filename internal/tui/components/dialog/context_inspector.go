@@ -0,0 +1,223 @@
+package dialog
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ApplyContextExclusionsMsg asks the TUI to leave the items with the given
+// IDs out of the session's next turn.
+type ApplyContextExclusionsMsg struct {
+	SessionID string
+	IDs       []string
+}
+
+// CloseContextInspectorDialogMsg is sent when the context inspector is closed.
+type CloseContextInspectorDialogMsg struct{}
+
+// ContextInspectorDialog lists what the next request of a session will send
+// and lets the user exclude items from that turn.
+type ContextInspectorDialog interface {
+	tea.Model
+	layout.Bindings
+	SetReport(report agent.ContextReport)
+}
+
+type contextInspectorKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+	Apply  key.Binding
+	Escape key.Binding
+}
+
+var contextInspectorKeys = contextInspectorKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous item"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next item"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("space"),
+		key.WithHelp("space", "include/exclude"),
+	),
+	Apply: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "apply"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+type contextInspectorDialogCmp struct {
+	report   agent.ContextReport
+	excluded map[string]bool
+	selected int
+
+	width  int
+	height int
+}
+
+func (d *contextInspectorDialogCmp) SetReport(report agent.ContextReport) {
+	d.report = report
+	d.excluded = make(map[string]bool)
+	for _, item := range report.Items {
+		if item.Excluded {
+			d.excluded[item.ID] = true
+		}
+	}
+	d.selected = 0
+}
+
+func (d *contextInspectorDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *contextInspectorDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, contextInspectorKeys.Escape):
+			return d, util.CmdHandler(CloseContextInspectorDialogMsg{})
+		case key.Matches(msg, contextInspectorKeys.Up):
+			if d.selected > 0 {
+				d.selected--
+			}
+		case key.Matches(msg, contextInspectorKeys.Down):
+			if d.selected < len(d.report.Items)-1 {
+				d.selected++
+			}
+		case key.Matches(msg, contextInspectorKeys.Toggle):
+			if d.selected < len(d.report.Items) {
+				item := d.report.Items[d.selected]
+				if item.Kind != agent.ContextItemSystem {
+					d.excluded[item.ID] = !d.excluded[item.ID]
+				}
+			}
+		case key.Matches(msg, contextInspectorKeys.Apply):
+			var ids []string
+			for _, item := range d.report.Items {
+				if d.excluded[item.ID] {
+					ids = append(ids, item.ID)
+				}
+			}
+			return d, util.CmdHandler(ApplyContextExclusionsMsg{SessionID: d.report.SessionID, IDs: ids})
+		}
+	}
+	return d, nil
+}
+
+func (d *contextInspectorDialogCmp) contentSize() (int, int) {
+	w, h := 90, 24
+	if d.width > 0 {
+		w = max(40, min(w, d.width-16))
+	}
+	if d.height > 0 {
+		h = max(6, d.height-16)
+	}
+	return w, h
+}
+
+// includedTokens sums the items that stay in the request with the pending
+// toggles applied.
+func (d *contextInspectorDialogCmp) includedTokens() int64 {
+	var total int64
+	for _, item := range d.report.Items {
+		if !d.excluded[item.ID] {
+			total += item.Tokens
+		}
+	}
+	return total
+}
+
+func (d *contextInspectorDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	w, h := d.contentSize()
+	title := baseStyle.Foreground(t.Primary()).Bold(true).Width(w).Padding(0, 1)
+	muted := baseStyle.Foreground(t.TextMuted()).Width(w).Padding(0, 1)
+
+	summary := fmt.Sprintf("~%d tokens", d.includedTokens())
+	if d.report.ContextWindow > 0 {
+		summary = fmt.Sprintf("~%d of %d tokens (%d%%)", d.includedTokens(), d.report.ContextWindow,
+			d.includedTokens()*100/d.report.ContextWindow)
+	}
+
+	start := 0
+	if d.selected >= h {
+		start = d.selected - h + 1
+	}
+	end := min(start+h, len(d.report.Items))
+	rows := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		item := d.report.Items[i]
+		mark := "[x]"
+		if d.excluded[item.ID] {
+			mark = "[ ]"
+		}
+		tokens := fmt.Sprintf("%7d", item.Tokens)
+		label := truncateDialogText(fmt.Sprintf("%s %-12s %s", mark, item.Kind, item.Name), w-len(tokens)-3)
+		line := label + lipgloss.NewStyle().Width(w-2-lipgloss.Width(label)).Align(lipgloss.Right).Render(tokens)
+		style := baseStyle.Width(w).Padding(0, 1)
+		switch {
+		case i == d.selected:
+			style = style.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+		case d.excluded[item.ID]:
+			style = style.Foreground(t.TextMuted())
+		}
+		rows = append(rows, style.Render(line))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title.Render("Next Request Context — "+string(d.report.Model)),
+		muted.Render(summary),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		"",
+		muted.Render("↑↓ select  space include/exclude for next turn  ⏎ apply  esc close"),
+	)
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (d *contextInspectorDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(contextInspectorKeys)
+}
+
+func truncateDialogText(s string, width int) string {
+	if width <= 1 || lipgloss.Width(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && lipgloss.Width(string(r)) > width-1 {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
+// NewContextInspectorDialogCmp creates the context inspector dialog.
+func NewContextInspectorDialogCmp() ContextInspectorDialog {
+	return &contextInspectorDialogCmp{excluded: make(map[string]bool)}
+}
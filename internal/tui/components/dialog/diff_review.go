@@ -0,0 +1,312 @@
+package dialog
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ReviewFile is a file changed by the turn under review.
+type ReviewFile struct {
+	Path string
+	// Before is the content from before the turn; Existed is false for a
+	// file the turn created.
+	Before  string
+	Existed bool
+	// After is the content on disk; Exists is false for a file the turn
+	// deleted.
+	After  string
+	Exists bool
+}
+
+// ReviewedFile is a file with hunks rejected in review and the content it
+// goes back to.
+type ReviewedFile struct {
+	Path    string
+	Content string
+	// Remove is set when every hunk of a file the turn created was
+	// rejected, so the file goes away again.
+	Remove bool
+	// Rejected counts the rejected hunks.
+	Rejected int
+}
+
+// ApplyDiffReviewMsg asks the TUI to put the rejected hunks back the way
+// they were.
+type ApplyDiffReviewMsg struct {
+	Files []ReviewedFile
+}
+
+// CloseDiffReviewDialogMsg is sent when the review is cancelled.
+type CloseDiffReviewDialogMsg struct{}
+
+// DiffReviewDialog steps through the hunks of the files changed by the
+// last turn and lets the user reject the ones they don't want. Nothing is
+// written until the review is applied.
+type DiffReviewDialog interface {
+	tea.Model
+	layout.Bindings
+	SetFiles(files []ReviewFile)
+}
+
+type diffReviewKeyMap struct {
+	Prev     key.Binding
+	Next     key.Binding
+	PrevFile key.Binding
+	NextFile key.Binding
+	Reject   key.Binding
+	Apply    key.Binding
+	Escape   key.Binding
+}
+
+var diffReviewKeys = diffReviewKeyMap{
+	Prev: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "previous hunk"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "next hunk"),
+	),
+	PrevFile: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous file"),
+	),
+	NextFile: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next file"),
+	),
+	Reject: key.NewBinding(
+		key.WithKeys("x", "space"),
+		key.WithHelp("x", "reject/keep hunk"),
+	),
+	Apply: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "apply review"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+type reviewedFile struct {
+	ReviewFile
+	hunks    []diff.ReviewHunk
+	rejected map[int]bool
+}
+
+type diffReviewDialogCmp struct {
+	files        []reviewedFile
+	selectedFile int
+	selectedHunk int
+
+	width    int
+	height   int
+	viewport viewport.Model
+}
+
+func (d *diffReviewDialogCmp) SetFiles(files []ReviewFile) {
+	d.files = d.files[:0]
+	for _, f := range files {
+		hunks := diff.Hunks(f.Before, f.After, displayPath(f.Path))
+		if len(hunks) == 0 {
+			continue
+		}
+		d.files = append(d.files, reviewedFile{ReviewFile: f, hunks: hunks, rejected: make(map[int]bool)})
+	}
+	d.selectedFile, d.selectedHunk = 0, 0
+	d.renderHunk()
+}
+
+func (d *diffReviewDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *diffReviewDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.renderHunk()
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, diffReviewKeys.Escape):
+			return d, util.CmdHandler(CloseDiffReviewDialogMsg{})
+		case key.Matches(msg, diffReviewKeys.Apply):
+			return d, util.CmdHandler(ApplyDiffReviewMsg{Files: d.reviewed()})
+		case key.Matches(msg, diffReviewKeys.Prev):
+			d.step(-1)
+		case key.Matches(msg, diffReviewKeys.Next):
+			d.step(1)
+		case key.Matches(msg, diffReviewKeys.PrevFile):
+			d.stepFile(-1)
+		case key.Matches(msg, diffReviewKeys.NextFile):
+			d.stepFile(1)
+		case key.Matches(msg, diffReviewKeys.Reject):
+			if len(d.files) > 0 {
+				f := &d.files[d.selectedFile]
+				f.rejected[d.selectedHunk] = !f.rejected[d.selectedHunk]
+				// Move on, so a run of rejections is one key per hunk.
+				d.step(1)
+			}
+		default:
+			vp, cmd := d.viewport.Update(msg)
+			d.viewport = vp
+			return d, cmd
+		}
+	}
+	return d, nil
+}
+
+// step moves to the next or previous hunk, crossing into the neighbouring
+// file at either end.
+func (d *diffReviewDialogCmp) step(delta int) {
+	if len(d.files) == 0 {
+		return
+	}
+	hunk := d.selectedHunk + delta
+	switch {
+	case hunk >= len(d.files[d.selectedFile].hunks):
+		if d.selectedFile == len(d.files)-1 {
+			return
+		}
+		d.selectedFile++
+		hunk = 0
+	case hunk < 0:
+		if d.selectedFile == 0 {
+			return
+		}
+		d.selectedFile--
+		hunk = len(d.files[d.selectedFile].hunks) - 1
+	}
+	d.selectedHunk = hunk
+	d.renderHunk()
+}
+
+func (d *diffReviewDialogCmp) stepFile(delta int) {
+	if n := len(d.files); n > 0 {
+		d.selectedFile = (d.selectedFile + delta + n) % n
+		d.selectedHunk = 0
+		d.renderHunk()
+	}
+}
+
+// reviewed returns the files with rejected hunks and what they go back to.
+func (d *diffReviewDialogCmp) reviewed() []ReviewedFile {
+	var out []ReviewedFile
+	for _, f := range d.files {
+		rejected := 0
+		for i := range f.hunks {
+			if f.rejected[i] {
+				rejected++
+			}
+		}
+		if rejected == 0 {
+			continue
+		}
+		r := ReviewedFile{Path: f.Path, Rejected: rejected}
+		if rejected == len(f.hunks) {
+			r.Content, r.Remove = f.Before, !f.Existed
+		} else {
+			r.Content = diff.RejectHunks(f.Before, f.After, f.rejected)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (d *diffReviewDialogCmp) contentSize() (int, int) {
+	w, h := 100, 30
+	if d.width > 0 {
+		w = max(40, d.width-16)
+	}
+	if d.height > 0 {
+		h = max(8, d.height-14)
+	}
+	return w, h
+}
+
+func (d *diffReviewDialogCmp) renderHunk() {
+	if len(d.files) == 0 {
+		return
+	}
+	w, h := d.contentSize()
+	d.viewport.SetWidth(w)
+	d.viewport.SetHeight(h)
+	hunk := d.files[d.selectedFile].hunks[d.selectedHunk]
+	rendered, err := diff.FormatDiff(hunk.Unified, diff.WithTotalWidth(w))
+	if err != nil {
+		rendered = hunk.Unified
+	}
+	d.viewport.SetContent(rendered)
+	d.viewport.GotoTop()
+}
+
+func (d *diffReviewDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	w, _ := d.contentSize()
+	title := baseStyle.Foreground(t.Primary()).Bold(true).Width(w).Padding(0, 1)
+	muted := baseStyle.Foreground(t.TextMuted()).Width(w).Padding(0, 1)
+
+	var content string
+	if len(d.files) == 0 {
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("Review Changes"),
+			"",
+			muted.Render("The last turn left no changes to review"),
+		)
+	} else {
+		f := d.files[d.selectedFile]
+		hunk := f.hunks[d.selectedHunk]
+		header := fmt.Sprintf("%s — file %d of %d, hunk %d of %d (+%d -%d)",
+			displayPath(f.Path), d.selectedFile+1, len(d.files), d.selectedHunk+1, len(f.hunks),
+			hunk.Additions, hunk.Removals)
+		state := baseStyle.Foreground(t.Success()).Width(w).Padding(0, 1).Render("Kept")
+		if f.rejected[d.selectedHunk] {
+			state = baseStyle.Foreground(t.Error()).Bold(true).Width(w).Padding(0, 1).Render("Rejected: will be reverted")
+		}
+		total := 0
+		for _, rf := range d.files {
+			for _, r := range rf.rejected {
+				if r {
+					total++
+				}
+			}
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render(header),
+			state,
+			"",
+			d.viewport.View(),
+			"",
+			muted.Render(fmt.Sprintf("%d hunk(s) rejected  ←→ hunks  tab files  x reject/keep  ↑↓ scroll  ⏎ apply  esc cancel", total)),
+		)
+	}
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (d *diffReviewDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(diffReviewKeys)
+}
+
+// NewDiffReviewDialogCmp creates the diff review dialog.
+func NewDiffReviewDialogCmp() DiffReviewDialog {
+	return &diffReviewDialogCmp{viewport: viewport.New()}
+}
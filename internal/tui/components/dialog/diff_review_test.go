@@ -0,0 +1,64 @@
+package dialog
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+func press(t *testing.T, d DiffReviewDialog, keys ...tea.KeyPressMsg) tea.Msg {
+	t.Helper()
+	var last tea.Msg
+	for _, k := range keys {
+		_, cmd := d.Update(k)
+		if cmd != nil {
+			last = cmd()
+		}
+	}
+	return last
+}
+
+func TestDiffReviewRejectsHunks(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(config.Reset)
+
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	d := NewDiffReviewDialogCmp()
+	d.SetFiles([]ReviewFile{
+		// Two hunks: b and l changed.
+		{Path: "/w/two.txt", Before: before, Existed: true, After: "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\n", Exists: true},
+		{Path: "/w/new.txt", After: "fresh\n", Exists: true},
+		{Path: "/w/same.txt", Before: "x\n", Existed: true, After: "x\n", Exists: true},
+	})
+
+	x := tea.KeyPressMsg{Code: 'x', Text: "x"}
+	right := tea.KeyPressMsg{Code: tea.KeyRight}
+	enter := tea.KeyPressMsg{Code: tea.KeyEnter}
+
+	// Keep the first hunk, reject the second and the new file.
+	msg := press(t, d, right, x, x, enter)
+	apply, ok := msg.(ApplyDiffReviewMsg)
+	if !ok {
+		t.Fatalf("got %T, want ApplyDiffReviewMsg", msg)
+	}
+	if len(apply.Files) != 2 {
+		t.Fatalf("files = %+v", apply.Files)
+	}
+	if f := apply.Files[0]; f.Path != "/w/two.txt" || f.Content != "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n" || f.Remove || f.Rejected != 1 {
+		t.Errorf("two.txt = %+v", f)
+	}
+	if f := apply.Files[1]; f.Path != "/w/new.txt" || !f.Remove {
+		t.Errorf("new.txt = %+v, want removed", f)
+	}
+
+	// The cursor stayed on new.txt, the last hunk; rejecting it again keeps
+	// it after all.
+	msg = press(t, d, x, enter)
+	if apply := msg.(ApplyDiffReviewMsg); len(apply.Files) != 1 || apply.Files[0].Path != "/w/two.txt" {
+		t.Errorf("after keeping new.txt: %+v", apply.Files)
+	}
+}
@@ -0,0 +1,304 @@
+package dialog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// RestoreFileVersionMsg asks the TUI to write a recorded version back to disk.
+type RestoreFileVersionMsg struct {
+	File history.File
+}
+
+// CloseFileHistoryDialogMsg is sent when the file history dialog is closed.
+type CloseFileHistoryDialogMsg struct{}
+
+// FileHistoryDialog lets the user pick a file touched in the session, step
+// through its recorded versions with the diff against the previous version,
+// and restore any of them.
+type FileHistoryDialog interface {
+	tea.Model
+	layout.Bindings
+	SetFiles(files []history.File)
+}
+
+type fileHistoryKeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Prev    key.Binding
+	Next    key.Binding
+	Open    key.Binding
+	Restore key.Binding
+	Escape  key.Binding
+}
+
+var fileHistoryKeys = fileHistoryKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous file"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next file"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "older version"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "newer version"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "browse versions"),
+	),
+	Restore: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "restore version"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back/close"),
+	),
+}
+
+type fileHistoryDialogCmp struct {
+	paths    []string
+	versions map[string][]history.File
+
+	selectedPath int
+	// browsing is true while scrubbing through the versions of one file.
+	browsing        bool
+	selectedVersion int
+
+	width    int
+	height   int
+	viewport viewport.Model
+}
+
+func (d *fileHistoryDialogCmp) SetFiles(files []history.File) {
+	d.paths = nil
+	d.versions = make(map[string][]history.File)
+	for _, f := range files {
+		if _, ok := d.versions[f.Path]; !ok {
+			d.paths = append(d.paths, f.Path)
+		}
+		d.versions[f.Path] = append(d.versions[f.Path], f)
+	}
+	for _, p := range d.paths {
+		history.SortVersions(d.versions[p])
+	}
+	d.selectedPath = 0
+	d.browsing = false
+}
+
+func (d *fileHistoryDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *fileHistoryDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		if d.browsing {
+			d.renderVersion()
+		}
+	case tea.KeyPressMsg:
+		if d.browsing {
+			return d, d.updateBrowsing(msg)
+		}
+		switch {
+		case key.Matches(msg, fileHistoryKeys.Escape):
+			return d, util.CmdHandler(CloseFileHistoryDialogMsg{})
+		case key.Matches(msg, fileHistoryKeys.Up):
+			if d.selectedPath > 0 {
+				d.selectedPath--
+			}
+		case key.Matches(msg, fileHistoryKeys.Down):
+			if d.selectedPath < len(d.paths)-1 {
+				d.selectedPath++
+			}
+		case key.Matches(msg, fileHistoryKeys.Open):
+			if len(d.paths) > 0 {
+				d.browsing = true
+				d.selectedVersion = len(d.currentVersions()) - 1
+				d.renderVersion()
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *fileHistoryDialogCmp) updateBrowsing(msg tea.KeyPressMsg) tea.Cmd {
+	versions := d.currentVersions()
+	switch {
+	case key.Matches(msg, fileHistoryKeys.Escape):
+		d.browsing = false
+		return nil
+	case key.Matches(msg, fileHistoryKeys.Prev):
+		if d.selectedVersion > 0 {
+			d.selectedVersion--
+			d.renderVersion()
+		}
+		return nil
+	case key.Matches(msg, fileHistoryKeys.Next):
+		if d.selectedVersion < len(versions)-1 {
+			d.selectedVersion++
+			d.renderVersion()
+		}
+		return nil
+	case key.Matches(msg, fileHistoryKeys.Restore):
+		return util.CmdHandler(RestoreFileVersionMsg{File: versions[d.selectedVersion]})
+	}
+	vp, cmd := d.viewport.Update(msg)
+	d.viewport = vp
+	return cmd
+}
+
+func (d *fileHistoryDialogCmp) currentVersions() []history.File {
+	if len(d.paths) == 0 {
+		return nil
+	}
+	return d.versions[d.paths[d.selectedPath]]
+}
+
+func (d *fileHistoryDialogCmp) contentSize() (int, int) {
+	w, h := 100, 30
+	if d.width > 0 {
+		w = max(40, d.width-16)
+	}
+	if d.height > 0 {
+		h = max(8, d.height-14)
+	}
+	return w, h
+}
+
+// renderVersion fills the viewport with the diff between the selected
+// version and the one before it. The oldest version is diffed against an
+// empty file so its full content is visible.
+func (d *fileHistoryDialogCmp) renderVersion() {
+	versions := d.currentVersions()
+	if len(versions) == 0 {
+		return
+	}
+	w, h := d.contentSize()
+	d.viewport.SetWidth(w)
+	d.viewport.SetHeight(h)
+
+	cur := versions[d.selectedVersion]
+	before := ""
+	if d.selectedVersion > 0 {
+		before = versions[d.selectedVersion-1].Content
+	}
+	if before == cur.Content {
+		d.viewport.SetContent(styles.BaseStyle().Foreground(theme.CurrentTheme().TextMuted()).Render("No changes from the previous version"))
+		d.viewport.GotoTop()
+		return
+	}
+	unified, _, _ := diff.GenerateDiff(before, cur.Content, cur.Path)
+	rendered, err := diff.FormatDiff(unified, diff.WithTotalWidth(w))
+	if err != nil {
+		rendered = unified
+	}
+	d.viewport.SetContent(rendered)
+	d.viewport.GotoTop()
+}
+
+func (d *fileHistoryDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	w, _ := d.contentSize()
+	title := baseStyle.Foreground(t.Primary()).Bold(true).Width(w).Padding(0, 1)
+	muted := baseStyle.Foreground(t.TextMuted()).Width(w).Padding(0, 1)
+
+	var content string
+	switch {
+	case len(d.paths) == 0:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("File History"),
+			"",
+			muted.Render("No files have been changed in this session"),
+		)
+	case d.browsing:
+		versions := d.currentVersions()
+		cur := versions[d.selectedVersion]
+		header := fmt.Sprintf("%s — version %d of %d (%s, %s)",
+			displayPath(cur.Path), d.selectedVersion+1, len(versions), cur.Version,
+			time.Unix(cur.CreatedAt, 0).Format("2006-01-02 15:04:05"))
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render(header),
+			"",
+			d.viewport.View(),
+			"",
+			muted.Render("←→ versions  ↑↓ scroll  r restore to disk  esc back"),
+		)
+	default:
+		_, h := d.contentSize()
+		start := 0
+		if d.selectedPath >= h {
+			start = d.selectedPath - h + 1
+		}
+		end := min(start+h, len(d.paths))
+		rows := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			p := d.paths[i]
+			line := fmt.Sprintf("%s  (%d versions)", displayPath(p), len(d.versions[p]))
+			style := baseStyle.Width(w).Padding(0, 1)
+			if i == d.selectedPath {
+				style = style.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+			}
+			rows = append(rows, style.Render(line))
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("File History"),
+			"",
+			lipgloss.JoinVertical(lipgloss.Left, rows...),
+			"",
+			muted.Render("↑↓ select file  ⏎ browse versions  esc close"),
+		)
+	}
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (d *fileHistoryDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(fileHistoryKeys)
+}
+
+// displayPath shows paths inside the project relative to its root.
+func displayPath(path string) string {
+	if rel, err := filepath.Rel(config.WorkingDirectory(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// NewFileHistoryDialogCmp creates the file history dialog.
+func NewFileHistoryDialogCmp() FileHistoryDialog {
+	return &fileHistoryDialogCmp{
+		versions: make(map[string][]history.File),
+		viewport: viewport.New(),
+	}
+}
@@ -0,0 +1,209 @@
+package dialog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/flow"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ResolveFlowGateMsg is emitted when the user approves or rejects a flow
+// step held at a manual gate. The TUI calls flow.Service.ResolveGate.
+type ResolveFlowGateMsg struct {
+	SessionID string
+	StepID    string
+	Approved  bool
+}
+
+// CloseFlowGateDialogMsg hides the dialog without deciding. The flow
+// stays paused; the gate can still be resolved through the API.
+type CloseFlowGateDialogMsg struct{}
+
+// FlowGateDialog asks the user to approve or reject flow steps that are
+// waiting_approval, one gate at a time.
+type FlowGateDialog interface {
+	tea.Model
+	layout.Bindings
+	AddGate(state flow.FlowState)
+	RemoveGate(sessionID string)
+	HasGates() bool
+}
+
+type flowGateDialogCmp struct {
+	gates    []flow.FlowState
+	selected int // 0=Approve, 1=Reject
+}
+
+var flowGateLabels = []string{"Approve", "Reject"}
+
+// AddGate queues a waiting step. A repeated event for the same step
+// session replaces the queued entry.
+func (d *flowGateDialogCmp) AddGate(state flow.FlowState) {
+	for i, g := range d.gates {
+		if g.SessionID == state.SessionID {
+			d.gates[i] = state
+			return
+		}
+	}
+	d.gates = append(d.gates, state)
+}
+
+// RemoveGate drops a step that was resolved elsewhere (e.g. via the API).
+func (d *flowGateDialogCmp) RemoveGate(sessionID string) {
+	for i, g := range d.gates {
+		if g.SessionID == sessionID {
+			d.gates = append(d.gates[:i], d.gates[i+1:]...)
+			if i == 0 {
+				d.selected = 0
+			}
+			return
+		}
+	}
+}
+
+func (d *flowGateDialogCmp) HasGates() bool {
+	return len(d.gates) > 0
+}
+
+func (d *flowGateDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *flowGateDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("left", "h", "right", "l", "tab"))):
+			d.selected = 1 - d.selected
+		case key.Matches(msg, key.NewBinding(key.WithKeys("a", "y"))):
+			return d, d.resolveCurrent(true)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r", "n"))):
+			return d, d.resolveCurrent(false)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter", " "))):
+			return d, d.resolveCurrent(d.selected == 0)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			d.selected = 0
+			return d, util.CmdHandler(CloseFlowGateDialogMsg{})
+		}
+	}
+	return d, nil
+}
+
+func (d *flowGateDialogCmp) resolveCurrent(approved bool) tea.Cmd {
+	if len(d.gates) == 0 {
+		return util.CmdHandler(CloseFlowGateDialogMsg{})
+	}
+	gate := d.gates[0]
+	d.gates = d.gates[1:]
+	d.selected = 0
+
+	cmds := []tea.Cmd{util.CmdHandler(ResolveFlowGateMsg{
+		SessionID: gate.SessionID,
+		StepID:    gate.StepID,
+		Approved:  approved,
+	})}
+	if len(d.gates) == 0 {
+		cmds = append(cmds, util.CmdHandler(CloseFlowGateDialogMsg{}))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (d *flowGateDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	if len(d.gates) == 0 {
+		return tea.NewView(baseStyle.Render(""))
+	}
+	gate := d.gates[0]
+
+	header := "⏸ Flow step waiting for approval"
+	if len(d.gates) > 1 {
+		header = fmt.Sprintf("%s (%d pending)", header, len(d.gates))
+	}
+	lines := []string{
+		header,
+		"",
+		fmt.Sprintf("Flow: %s", gate.FlowID),
+		fmt.Sprintf("Step: %s", gate.StepID),
+	}
+	if gate.Iteration > 1 {
+		lines = append(lines, fmt.Sprintf("Iteration: %d", gate.Iteration))
+	}
+	if args := formatGateArgs(gate.Args); args != "" {
+		lines = append(lines, "", "```\n"+args+"\n```")
+	}
+
+	spacerStyle := baseStyle.Background(t.Background())
+	highlight := func(active bool) lipgloss.Style {
+		if active {
+			return baseStyle.Background(t.Primary()).Foreground(t.Background())
+		}
+		return baseStyle.Background(t.Background()).Foreground(t.Primary())
+	}
+	approveBtn := highlight(d.selected == 0).Padding(0, 1).Render(flowGateLabels[0])
+	rejectBtn := highlight(d.selected == 1).Padding(0, 1).Render(flowGateLabels[1])
+	buttons := lipgloss.JoinHorizontal(lipgloss.Left, approveBtn, spacerStyle.Render("  "), rejectBtn)
+	lines = append(lines, "", buttons)
+
+	bg := t.Background()
+	content := baseStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	width := max(lipgloss.Width(content), 50)
+
+	rendered := baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(bg).
+		BorderForeground(t.TextMuted()).
+		Width(width + 6).
+		Render(content)
+
+	return tea.NewView(styles.ForceReplaceBackgroundWithLipgloss(rendered, bg))
+}
+
+// formatGateArgs renders the step args as sorted key: value lines so the
+// user can see what the step is about to act on.
+func formatGateArgs(args map[string]any) string {
+	if len(args) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		v := fmt.Sprint(args[k])
+		if len(v) > 80 {
+			v = v[:77] + "..."
+		}
+		fmt.Fprintf(&b, "%s: %s", k, v)
+	}
+	return b.String()
+}
+
+func (d *flowGateDialogCmp) BindingKeys() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("left", "h", "right", "l", "tab"), key.WithHelp("←/→/tab", "switch")),
+		key.NewBinding(key.WithKeys("a", "y"), key.WithHelp("a", "approve")),
+		key.NewBinding(key.WithKeys("r", "n"), key.WithHelp("r", "reject")),
+		key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter/space", "confirm")),
+		key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "defer")),
+	}
+}
+
+func NewFlowGateDialog() FlowGateDialog {
+	return &flowGateDialogCmp{}
+}
@@ -0,0 +1,189 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ForkSessionMsg asks the TUI to fork the session after MessageID.
+type ForkSessionMsg struct {
+	SessionID string
+	MessageID string
+}
+
+// CloseForkDialogMsg is sent when the fork dialog is closed.
+type CloseForkDialogMsg struct{}
+
+// ForkDialog lets the user pick the turn a session is forked after.
+type ForkDialog interface {
+	tea.Model
+	layout.Bindings
+	SetMessages(sessionID string, msgs []message.Message)
+}
+
+type forkKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Fork   key.Binding
+	Escape key.Binding
+}
+
+var forkKeys = forkKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous turn"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next turn"),
+	),
+	Fork: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "fork after turn"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+// forkTurn is one user prompt and everything answering it. Forking at a
+// turn keeps it whole, up to lastMessageID.
+type forkTurn struct {
+	prompt        string
+	lastMessageID string
+}
+
+type forkDialogCmp struct {
+	sessionID string
+	turns     []forkTurn
+	selected  int
+
+	width  int
+	height int
+}
+
+func (d *forkDialogCmp) SetMessages(sessionID string, msgs []message.Message) {
+	d.sessionID = sessionID
+	d.turns = nil
+	for _, msg := range msgs {
+		if msg.Role == message.User && !msg.Synthetic {
+			d.turns = append(d.turns, forkTurn{prompt: msg.Content().String()})
+		}
+		if len(d.turns) > 0 {
+			d.turns[len(d.turns)-1].lastMessageID = msg.ID
+		}
+	}
+	d.selected = max(0, len(d.turns)-1)
+}
+
+func (d *forkDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *forkDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, forkKeys.Escape):
+			return d, util.CmdHandler(CloseForkDialogMsg{})
+		case key.Matches(msg, forkKeys.Up):
+			if d.selected > 0 {
+				d.selected--
+			}
+		case key.Matches(msg, forkKeys.Down):
+			if d.selected < len(d.turns)-1 {
+				d.selected++
+			}
+		case key.Matches(msg, forkKeys.Fork):
+			if len(d.turns) > 0 {
+				return d, util.CmdHandler(ForkSessionMsg{
+					SessionID: d.sessionID,
+					MessageID: d.turns[d.selected].lastMessageID,
+				})
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *forkDialogCmp) contentSize() (int, int) {
+	w, h := 80, 20
+	if d.width > 0 {
+		w = max(40, min(100, d.width-16))
+	}
+	if d.height > 0 {
+		h = max(5, d.height-14)
+	}
+	return w, h
+}
+
+func (d *forkDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	w, h := d.contentSize()
+	title := baseStyle.Foreground(t.Primary()).Bold(true).Width(w).Padding(0, 1)
+	muted := baseStyle.Foreground(t.TextMuted()).Width(w).Padding(0, 1)
+
+	var content string
+	if len(d.turns) == 0 {
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("Fork Session"),
+			"",
+			muted.Render("No prompts in this session to fork after"),
+		)
+	} else {
+		start := 0
+		if d.selected >= h {
+			start = d.selected - h + 1
+		}
+		end := min(start+h, len(d.turns))
+		rows := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			prompt := strings.Join(strings.Fields(d.turns[i].prompt), " ")
+			line := truncateDialogText(fmt.Sprintf("%d. %s", i+1, prompt), w-2)
+			style := baseStyle.Width(w).Padding(0, 1)
+			if i == d.selected {
+				style = style.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+			}
+			rows = append(rows, style.Render(line))
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("Fork Session"),
+			muted.Render("The new session keeps the selected turn and everything before it"),
+			"",
+			lipgloss.JoinVertical(lipgloss.Left, rows...),
+			"",
+			muted.Render("↑↓ select turn  ⏎ fork  esc close"),
+		)
+	}
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (d *forkDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(forkKeys)
+}
+
+// NewForkDialogCmp creates the fork dialog.
+func NewForkDialogCmp() ForkDialog {
+	return &forkDialogCmp{}
+}
@@ -1,9 +1,11 @@
 package dialog
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
@@ -29,6 +31,11 @@ type ModelSelectedMsg struct {
 // CloseModelDialogMsg is sent when a model is selected
 type CloseModelDialogMsg struct{}
 
+// modelsCheckedMsg is sent when an on-demand availability check finishes.
+type modelsCheckedMsg struct {
+	err error
+}
+
 // ModelDialog interface for the model selection dialog
 type ModelDialog interface {
 	tea.Model
@@ -46,6 +53,7 @@ type modelDialogCmp struct {
 	scrollOffset    int
 	hScrollOffset   int
 	hScrollPossible bool
+	checking        bool
 }
 
 type modelKeyMap struct {
@@ -59,6 +67,7 @@ type modelKeyMap struct {
 	K      key.Binding
 	H      key.Binding
 	L      key.Binding
+	Check  key.Binding
 }
 
 var modelKeys = modelKeyMap{
@@ -102,6 +111,10 @@ var modelKeys = modelKeyMap{
 		key.WithKeys("l"),
 		key.WithHelp("l", "scroll right"),
 	),
+	Check: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "check availability"),
+	),
 }
 
 func (m *modelDialogCmp) Init() tea.Cmd {
@@ -126,11 +139,27 @@ func (m *modelDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.switchProvider(1)
 			}
 		case key.Matches(msg, modelKeys.Enter):
-			util.ReportInfo(fmt.Sprintf("selected model: %s", m.models[m.selectedIdx].Name))
-			return m, util.CmdHandler(ModelSelectedMsg{Model: m.models[m.selectedIdx]})
+			selected := m.models[m.selectedIdx]
+			if a, ok := models.AvailabilityOf(selected.ID); ok && !a.Available {
+				return m, util.ReportWarn(fmt.Sprintf("%s is unavailable: %s", selected.Name, a.Reason))
+			}
+			util.ReportInfo(fmt.Sprintf("selected model: %s", selected.Name))
+			return m, util.CmdHandler(ModelSelectedMsg{Model: selected})
+		case key.Matches(msg, modelKeys.Check):
+			if m.checking {
+				return m, nil
+			}
+			m.checking = true
+			return m, checkModelAvailability
 		case key.Matches(msg, modelKeys.Escape):
 			return m, util.CmdHandler(CloseModelDialogMsg{})
 		}
+	case modelsCheckedMsg:
+		m.checking = false
+		if msg.err != nil {
+			return m, util.ReportError(msg.err)
+		}
+		return m, util.ReportInfo("Model availability checked")
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -139,6 +168,20 @@ func (m *modelDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// checkModelAvailability re-checks the configured providers' models on
+// demand.
+func checkModelAvailability() tea.Msg {
+	cfg := config.Get()
+	creds := cfg.SyncCredentials()
+	if len(creds) == 0 {
+		return modelsCheckedMsg{err: fmt.Errorf("no configured provider supports model checks")}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err := models.CheckAvailability(ctx, creds)
+	return modelsCheckedMsg{err: err}
+}
+
 // moveSelectionUp moves the selection up or wraps to bottom
 func (m *modelDialogCmp) moveSelectionUp() {
 	if m.selectedIdx > 0 {
@@ -204,21 +247,30 @@ func (m *modelDialogCmp) View() tea.View {
 
 	for i := m.scrollOffset; i < endIdx; i++ {
 		itemStyle := baseStyle.Width(maxDialogWidth)
+		name := m.models[i].Name
+		// Models a check found unavailable are greyed out.
+		if a, ok := models.AvailabilityOf(m.models[i].ID); ok && !a.Available {
+			name = "✗ " + name
+			itemStyle = itemStyle.Foreground(t.TextMuted())
+		}
 		if i == m.selectedIdx {
 			itemStyle = itemStyle.Background(t.Primary()).
 				Foreground(t.Background()).Bold(true)
 		}
-		modelItems = append(modelItems, itemStyle.Render(m.models[i].Name))
+		modelItems = append(modelItems, itemStyle.Render(name))
 	}
 
 	scrollIndicator := m.getScrollIndicators(maxDialogWidth)
 
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
+	parts := []string{
 		title,
 		baseStyle.Width(maxDialogWidth).Render(lipgloss.JoinVertical(lipgloss.Left, modelItems...)),
 		scrollIndicator,
-	)
+	}
+	if status := m.availabilityStatus(); status != "" {
+		parts = append(parts, baseStyle.Foreground(t.TextMuted()).Width(maxDialogWidth).Padding(1, 0, 0).Render(status))
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left, parts...)
 
 	return tea.NewView(baseStyle.Padding(1, 2).
 		Border(lipgloss.RoundedBorder()).
@@ -228,6 +280,23 @@ func (m *modelDialogCmp) View() tea.View {
 		Render(content))
 }
 
+// availabilityStatus explains why the selected model is unavailable, or
+// reports the provider's last failed check.
+func (m *modelDialogCmp) availabilityStatus() string {
+	if m.checking {
+		return "Checking availability…"
+	}
+	if len(m.models) > 0 {
+		if a, ok := models.AvailabilityOf(m.models[m.selectedIdx].ID); ok && !a.Available {
+			return "Unavailable: " + a.Reason
+		}
+	}
+	if h, ok := models.CurrentAvailability().Providers[m.provider]; ok && h.Error != "" {
+		return "Last check failed: " + h.Error
+	}
+	return ""
+}
+
 func (m *modelDialogCmp) getScrollIndicators(maxWidth int) string {
 	var indicator string
 
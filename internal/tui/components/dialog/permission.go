@@ -23,6 +23,7 @@ type PermissionAction string
 const (
 	PermissionAllow           PermissionAction = "allow"
 	PermissionAllowForSession PermissionAction = "allow_session"
+	PermissionAllowForProject PermissionAction = "allow_project"
 	PermissionDeny            PermissionAction = "deny"
 )
 
@@ -45,6 +46,7 @@ type permissionsMapping struct {
 	EnterSpace   key.Binding
 	Allow        key.Binding
 	AllowSession key.Binding
+	AllowProject key.Binding
 	Deny         key.Binding
 	Tab          key.Binding
 }
@@ -70,6 +72,10 @@ var permissionsKeys = permissionsMapping{
 		key.WithKeys("s"),
 		key.WithHelp("s", "allow for session"),
 	),
+	AllowProject: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "always allow in project"),
+	),
 	Deny: key.NewBinding(
 		key.WithKeys("d"),
 		key.WithHelp("d", "deny"),
@@ -87,7 +93,7 @@ type permissionDialogCmp struct {
 	permission      permission.PermissionRequest
 	windowSize      tea.WindowSizeMsg
 	contentViewPort viewport.Model
-	selectedOption  int // 0: Allow, 1: Allow for session, 2: Deny
+	selectedOption  int // index into options()
 
 	diffCache     map[string]string
 	markdownCache map[string]string
@@ -110,16 +116,19 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		switch {
 		case key.Matches(msg, permissionsKeys.Right) || key.Matches(msg, permissionsKeys.Tab):
-			p.selectedOption = (p.selectedOption + 1) % 3
+			p.selectedOption = (p.selectedOption + 1) % len(p.options())
 			return p, nil
 		case key.Matches(msg, permissionsKeys.Left):
-			p.selectedOption = (p.selectedOption + 2) % 3
+			n := len(p.options())
+			p.selectedOption = (p.selectedOption + n - 1) % n
 		case key.Matches(msg, permissionsKeys.EnterSpace):
 			return p, p.selectCurrentOption()
 		case key.Matches(msg, permissionsKeys.Allow):
 			return p, util.CmdHandler(PermissionResponseMsg{Action: PermissionAllow, Permission: p.permission})
-		case key.Matches(msg, permissionsKeys.AllowSession):
+		case key.Matches(msg, permissionsKeys.AllowSession) && !p.permission.Confirm:
 			return p, util.CmdHandler(PermissionResponseMsg{Action: PermissionAllowForSession, Permission: p.permission})
+		case key.Matches(msg, permissionsKeys.AllowProject) && p.permission.Pattern != "" && !p.permission.Confirm:
+			return p, util.CmdHandler(PermissionResponseMsg{Action: PermissionAllowForProject, Permission: p.permission})
 		case key.Matches(msg, permissionsKeys.Deny):
 			return p, util.CmdHandler(PermissionResponseMsg{Action: PermissionDeny, Permission: p.permission})
 		default:
@@ -133,59 +142,46 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return p, tea.Batch(cmds...)
 }
 
-func (p *permissionDialogCmp) selectCurrentOption() tea.Cmd {
-	var action PermissionAction
-
-	switch p.selectedOption {
-	case 0:
-		action = PermissionAllow
-	case 1:
-		action = PermissionAllowForSession
-	case 2:
-		action = PermissionDeny
+// options lists the answers offered for the current request. Allowing
+// in the project saves a rule for the request's pattern, so it is only
+// offered when the tool supplied one. Confirmations are never remembered.
+func (p *permissionDialogCmp) options() []PermissionAction {
+	if p.permission.Confirm {
+		return []PermissionAction{PermissionAllow, PermissionDeny}
 	}
+	if p.permission.Pattern == "" {
+		return []PermissionAction{PermissionAllow, PermissionAllowForSession, PermissionDeny}
+	}
+	return []PermissionAction{PermissionAllow, PermissionAllowForSession, PermissionAllowForProject, PermissionDeny}
+}
 
+func (p *permissionDialogCmp) selectCurrentOption() tea.Cmd {
+	options := p.options()
+	action := options[min(p.selectedOption, len(options)-1)]
 	return util.CmdHandler(PermissionResponseMsg{Action: action, Permission: p.permission})
 }
 
+var permissionButtonLabels = map[PermissionAction]string{
+	PermissionAllow:           "Allow (a)",
+	PermissionAllowForSession: "Allow for session (s)",
+	PermissionAllowForProject: "Always allow in project (p)",
+	PermissionDeny:            "Deny (d)",
+}
+
 func (p *permissionDialogCmp) renderButtons() string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
-
-	allowStyle := baseStyle
-	allowSessionStyle := baseStyle
-	denyStyle := baseStyle
 	spacerStyle := baseStyle.Background(t.Background())
 
-	// Style the selected button
-	switch p.selectedOption {
-	case 0:
-		allowStyle = allowStyle.Background(t.Primary()).Foreground(t.Background())
-		allowSessionStyle = allowSessionStyle.Background(t.Background()).Foreground(t.Primary())
-		denyStyle = denyStyle.Background(t.Background()).Foreground(t.Primary())
-	case 1:
-		allowStyle = allowStyle.Background(t.Background()).Foreground(t.Primary())
-		allowSessionStyle = allowSessionStyle.Background(t.Primary()).Foreground(t.Background())
-		denyStyle = denyStyle.Background(t.Background()).Foreground(t.Primary())
-	case 2:
-		allowStyle = allowStyle.Background(t.Background()).Foreground(t.Primary())
-		allowSessionStyle = allowSessionStyle.Background(t.Background()).Foreground(t.Primary())
-		denyStyle = denyStyle.Background(t.Primary()).Foreground(t.Background())
+	var buttons []string
+	for i, action := range p.options() {
+		style := baseStyle.Background(t.Background()).Foreground(t.Primary())
+		if i == p.selectedOption {
+			style = baseStyle.Background(t.Primary()).Foreground(t.Background())
+		}
+		buttons = append(buttons, style.Padding(0, 1).Render(permissionButtonLabels[action]), spacerStyle.Render("  "))
 	}
-
-	allowButton := allowStyle.Padding(0, 1).Render("Allow (a)")
-	allowSessionButton := allowSessionStyle.Padding(0, 1).Render("Allow for session (s)")
-	denyButton := denyStyle.Padding(0, 1).Render("Deny (d)")
-
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Left,
-		allowButton,
-		spacerStyle.Render("  "),
-		allowSessionButton,
-		spacerStyle.Render("  "),
-		denyButton,
-		spacerStyle.Render("  "),
-	)
+	content := lipgloss.JoinHorizontal(lipgloss.Left, buttons...)
 
 	remainingWidth := p.width - lipgloss.Width(content)
 	if remainingWidth > 0 {
@@ -227,7 +223,7 @@ func (p *permissionDialogCmp) renderHeader() string {
 
 	// Add tool-specific header information
 	switch p.permission.ToolName {
-	case tools.BashToolName:
+	case tools.BashToolName, tools.RunTaskToolName:
 		headerParts = append(headerParts, baseStyle.Foreground(t.TextMuted()).Width(p.width).Bold(true).Render("Command"))
 	case tools.EditToolName:
 		params := p.permission.Params.(tools.EditPermissionsParams)
@@ -288,8 +284,15 @@ func (p *permissionDialogCmp) renderBashContent() string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
 
-	if pr, ok := p.permission.Params.(tools.BashPermissionsParams); ok {
-		content := fmt.Sprintf("```bash\n%s\n```", pr.Command)
+	command := ""
+	switch pr := p.permission.Params.(type) {
+	case tools.BashPermissionsParams:
+		command = pr.Command
+	case tools.RunTaskPermissionsParams:
+		command = pr.Command
+	}
+	if command != "" {
+		content := fmt.Sprintf("```bash\n%s\n```", command)
 
 		// Use the cache for markdown rendering
 		renderedContent := p.GetOrSetMarkdown(p.permission.ID, func() (string, error) {
@@ -515,7 +518,7 @@ func (p *permissionDialogCmp) render() string {
 	// Render content based on tool type
 	var contentFinal string
 	switch p.permission.ToolName {
-	case tools.BashToolName:
+	case tools.BashToolName, tools.RunTaskToolName:
 		contentFinal = p.renderBashContent()
 	case tools.EditToolName:
 		contentFinal = p.renderEditContent()
@@ -568,7 +571,7 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 		return nil
 	}
 	switch p.permission.ToolName {
-	case tools.BashToolName:
+	case tools.BashToolName, tools.RunTaskToolName:
 		p.width = max(40, int(float64(p.windowSize.Width)*0.4))
 		p.height = max(15, int(float64(p.windowSize.Height)*0.4))
 	case tools.EditToolName, tools.MultiEditToolName:
@@ -584,11 +587,17 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 		p.width = int(float64(p.windowSize.Width) * 0.7)
 		p.height = int(float64(p.windowSize.Height) * 0.5)
 	}
+	// Keep every answer on screen: the project option makes the button
+	// row wider than the narrow bash dialog.
+	if w := lipgloss.Width(p.renderButtons()); w > p.width {
+		p.width = min(w, p.windowSize.Width)
+	}
 	return nil
 }
 
 func (p *permissionDialogCmp) SetPermissions(permission permission.PermissionRequest) tea.Cmd {
 	p.permission = permission
+	p.selectedOption = min(p.selectedOption, len(p.options())-1)
 	p.contentViewPort.GotoTop()
 	return p.SetSize()
 }
@@ -0,0 +1,165 @@
+package dialog
+
+import (
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	utilComponents "github.com/opencode-ai/opencode/internal/tui/components/util"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ProfileSelectedMsg is sent when a profile is picked; Name is "" for the
+// base configuration without a profile.
+type ProfileSelectedMsg struct {
+	Name string
+}
+
+// CloseProfileDialogMsg is sent when the profile picker is closed.
+type CloseProfileDialogMsg struct{}
+
+// ProfileDialog lists the configured profiles to switch to.
+type ProfileDialog interface {
+	tea.Model
+	layout.Bindings
+	SetProfiles(names []string, current string)
+}
+
+type profileItem struct {
+	name    string
+	current bool
+}
+
+func (p profileItem) label() string {
+	label := p.name
+	if label == "" {
+		label = "(no profile)"
+	}
+	if p.current {
+		label += " ✓"
+	}
+	return label
+}
+
+func (p profileItem) Render(selected bool, width int) string {
+	t := theme.CurrentTheme()
+	itemStyle := styles.BaseStyle().Width(width).
+		Foreground(t.Text()).
+		Background(t.Background())
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.Background()).
+			Bold(true)
+	}
+	return itemStyle.Padding(0, 1).Render(p.label())
+}
+
+type profileDialogCmp struct {
+	listView utilComponents.SimpleList[profileItem]
+	width    int
+	height   int
+}
+
+type profileKeyMap struct {
+	Enter  key.Binding
+	Escape key.Binding
+}
+
+var profileKeys = profileKeyMap{
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "switch profile"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+func (p *profileDialogCmp) Init() tea.Cmd {
+	return p.listView.Init()
+}
+
+func (p *profileDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, profileKeys.Enter):
+			item, idx := p.listView.GetSelectedItem()
+			if idx != -1 {
+				return p, util.CmdHandler(ProfileSelectedMsg{Name: item.name})
+			}
+		case key.Matches(msg, profileKeys.Escape):
+			return p, util.CmdHandler(CloseProfileDialogMsg{})
+		}
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+	}
+
+	u, cmd := p.listView.Update(msg)
+	p.listView = u.(utilComponents.SimpleList[profileItem])
+	return p, cmd
+}
+
+func (p *profileDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	maxWidth := 30
+	for _, item := range p.listView.GetItems() {
+		maxWidth = max(maxWidth, lipgloss.Width(item.label())+4)
+	}
+	p.listView.SetMaxWidth(maxWidth)
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("Switch Profile")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(p.listView.View().Content),
+		baseStyle.Width(maxWidth).Render(""),
+	)
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (p *profileDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(profileKeys)
+}
+
+// SetProfiles lists the base configuration followed by the named profiles
+// and marks the one in force.
+func (p *profileDialogCmp) SetProfiles(names []string, current string) {
+	items := []profileItem{{current: current == ""}}
+	for _, name := range names {
+		items = append(items, profileItem{name: name, current: name == current})
+	}
+	p.listView.SetItems(items)
+}
+
+// NewProfileDialogCmp creates the profile picker.
+func NewProfileDialogCmp() ProfileDialog {
+	return &profileDialogCmp{
+		listView: utilComponents.NewSimpleList(
+			[]profileItem{},
+			10,
+			"No profiles configured",
+			true,
+		),
+	}
+}
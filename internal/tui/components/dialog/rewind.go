@@ -0,0 +1,193 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// RewindPromptMsg asks the TUI to drop MessageID and everything after it.
+// With Regenerate set the prompt is sent again as is, otherwise it is put
+// back into the editor.
+type RewindPromptMsg struct {
+	SessionID  string
+	MessageID  string
+	Regenerate bool
+}
+
+// CloseRewindDialogMsg is sent when the rewind dialog is closed.
+type CloseRewindDialogMsg struct{}
+
+// RewindDialog lets the user pick the prompt to edit or regenerate.
+type RewindDialog interface {
+	tea.Model
+	layout.Bindings
+	SetMessages(sessionID string, msgs []message.Message)
+}
+
+type rewindKeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	Edit       key.Binding
+	Regenerate key.Binding
+	Escape     key.Binding
+}
+
+var rewindKeys = rewindKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous prompt"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next prompt"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "edit prompt"),
+	),
+	Regenerate: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "regenerate"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+type rewindPrompt struct {
+	messageID string
+	text      string
+}
+
+type rewindDialogCmp struct {
+	sessionID string
+	prompts   []rewindPrompt
+	selected  int
+
+	width  int
+	height int
+}
+
+func (d *rewindDialogCmp) SetMessages(sessionID string, msgs []message.Message) {
+	d.sessionID = sessionID
+	d.prompts = nil
+	for _, msg := range msgs {
+		if msg.Role == message.User && !msg.Synthetic {
+			d.prompts = append(d.prompts, rewindPrompt{messageID: msg.ID, text: msg.Content().String()})
+		}
+	}
+	d.selected = max(0, len(d.prompts)-1)
+}
+
+func (d *rewindDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *rewindDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, rewindKeys.Escape):
+			return d, util.CmdHandler(CloseRewindDialogMsg{})
+		case key.Matches(msg, rewindKeys.Up):
+			if d.selected > 0 {
+				d.selected--
+			}
+		case key.Matches(msg, rewindKeys.Down):
+			if d.selected < len(d.prompts)-1 {
+				d.selected++
+			}
+		case key.Matches(msg, rewindKeys.Edit), key.Matches(msg, rewindKeys.Regenerate):
+			if len(d.prompts) > 0 {
+				return d, util.CmdHandler(RewindPromptMsg{
+					SessionID:  d.sessionID,
+					MessageID:  d.prompts[d.selected].messageID,
+					Regenerate: key.Matches(msg, rewindKeys.Regenerate),
+				})
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *rewindDialogCmp) contentSize() (int, int) {
+	w, h := 80, 20
+	if d.width > 0 {
+		w = max(40, min(100, d.width-16))
+	}
+	if d.height > 0 {
+		h = max(5, d.height-14)
+	}
+	return w, h
+}
+
+func (d *rewindDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	w, h := d.contentSize()
+	title := baseStyle.Foreground(t.Primary()).Bold(true).Width(w).Padding(0, 1)
+	muted := baseStyle.Foreground(t.TextMuted()).Width(w).Padding(0, 1)
+
+	var content string
+	if len(d.prompts) == 0 {
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("Edit Prompt"),
+			"",
+			muted.Render("No prompts in this session to edit"),
+		)
+	} else {
+		start := 0
+		if d.selected >= h {
+			start = d.selected - h + 1
+		}
+		end := min(start+h, len(d.prompts))
+		rows := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			prompt := strings.Join(strings.Fields(d.prompts[i].text), " ")
+			line := truncateDialogText(fmt.Sprintf("%d. %s", i+1, prompt), w-2)
+			style := baseStyle.Width(w).Padding(0, 1)
+			if i == d.selected {
+				style = style.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+			}
+			rows = append(rows, style.Render(line))
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			title.Render("Edit Prompt"),
+			muted.Render("The selected prompt and everything after it are removed from the session"),
+			"",
+			lipgloss.JoinVertical(lipgloss.Left, rows...),
+			"",
+			muted.Render("↑↓ select prompt  ⏎ edit  r regenerate  esc close"),
+		)
+	}
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (d *rewindDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(rewindKeys)
+}
+
+// NewRewindDialogCmp creates the edit/regenerate prompt dialog.
+func NewRewindDialogCmp() RewindDialog {
+	return &rewindDialogCmp{}
+}
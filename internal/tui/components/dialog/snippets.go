@@ -0,0 +1,174 @@
+package dialog
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/opencode-ai/opencode/internal/snippet"
+	utilComponents "github.com/opencode-ai/opencode/internal/tui/components/util"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// SnippetSelectedMsg is sent when a snippet is picked for the editor.
+type SnippetSelectedMsg struct {
+	Snippet snippet.Snippet
+}
+
+// CloseSnippetDialogMsg is sent when the snippet picker is closed.
+type CloseSnippetDialogMsg struct{}
+
+// SnippetDialog lists the prompt snippets to insert into the editor.
+type SnippetDialog interface {
+	tea.Model
+	layout.Bindings
+	SetSnippets(snippets []snippet.Snippet)
+}
+
+type snippetItem struct {
+	snippet.Snippet
+}
+
+func (s snippetItem) Render(selected bool, width int) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	descStyle := baseStyle.Width(width).Foreground(t.TextMuted())
+	itemStyle := baseStyle.Width(width).
+		Foreground(t.Text()).
+		Background(t.Background())
+
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.Background()).
+			Bold(true)
+		descStyle = descStyle.
+			Background(t.Primary()).
+			Foreground(t.Background())
+	}
+
+	title := itemStyle.Padding(0, 1).Render("!" + s.Name)
+	if d := s.summary(); d != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, title, descStyle.Padding(0, 1).Render(d))
+	}
+	return title
+}
+
+// summary is the description, or the first line of a snippet without one.
+func (s snippetItem) summary() string {
+	if s.Description != "" {
+		return s.Description
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(s.Content), "\n")
+	return truncateDialogText(line, 60)
+}
+
+type snippetDialogCmp struct {
+	listView utilComponents.SimpleList[snippetItem]
+	width    int
+	height   int
+}
+
+type snippetKeyMap struct {
+	Enter  key.Binding
+	Escape key.Binding
+}
+
+var snippetKeys = snippetKeyMap{
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "insert snippet"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+func (s *snippetDialogCmp) Init() tea.Cmd {
+	return s.listView.Init()
+}
+
+func (s *snippetDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, snippetKeys.Enter):
+			item, idx := s.listView.GetSelectedItem()
+			if idx != -1 {
+				return s, util.CmdHandler(SnippetSelectedMsg{Snippet: item.Snippet})
+			}
+		case key.Matches(msg, snippetKeys.Escape):
+			return s, util.CmdHandler(CloseSnippetDialogMsg{})
+		}
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+	}
+
+	u, cmd := s.listView.Update(msg)
+	s.listView = u.(utilComponents.SimpleList[snippetItem])
+	return s, cmd
+}
+
+func (s *snippetDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	maxWidth := 40
+	for _, item := range s.listView.GetItems() {
+		maxWidth = max(maxWidth, lipgloss.Width(item.Name)+5, lipgloss.Width(item.summary())+4)
+	}
+	s.listView.SetMaxWidth(maxWidth)
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("Snippets")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(s.listView.View().Content),
+		baseStyle.Width(maxWidth).Render(""),
+	)
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (s *snippetDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(snippetKeys)
+}
+
+func (s *snippetDialogCmp) SetSnippets(snippets []snippet.Snippet) {
+	items := make([]snippetItem, len(snippets))
+	for i, sn := range snippets {
+		items[i] = snippetItem{sn}
+	}
+	s.listView.SetItems(items)
+}
+
+// NewSnippetDialogCmp creates the snippet picker.
+func NewSnippetDialogCmp() SnippetDialog {
+	return &snippetDialogCmp{
+		listView: utilComponents.NewSimpleList(
+			[]snippetItem{},
+			10,
+			"No snippets; add them to .opencode/snippets or the snippets config",
+			true,
+		),
+	}
+}
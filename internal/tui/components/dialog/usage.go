@@ -0,0 +1,198 @@
+package dialog
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/opencode-ai/opencode/internal/feedback"
+	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// CloseUsageDialogMsg is sent when the usage breakdown is closed.
+type CloseUsageDialogMsg struct{}
+
+// UsageDialog shows what a session's spend went to, by agent and tool, and
+// how its replies were rated.
+type UsageDialog interface {
+	tea.Model
+	layout.Bindings
+	SetUsage(total session.Usage, entries []session.UsageEntry)
+	SetFeedback(tallies []feedback.Tally)
+}
+
+type usageKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Escape key.Binding
+}
+
+var usageKeys = usageKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+type usageDialogCmp struct {
+	total   session.Usage
+	entries []session.UsageEntry
+	ratings []feedback.Tally
+	offset  int
+
+	width  int
+	height int
+}
+
+func (d *usageDialogCmp) SetUsage(total session.Usage, entries []session.UsageEntry) {
+	d.total = total
+	d.entries = entries
+	d.offset = 0
+}
+
+func (d *usageDialogCmp) SetFeedback(tallies []feedback.Tally) {
+	d.ratings = tallies
+}
+
+func (d *usageDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (d *usageDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, usageKeys.Escape):
+			return d, util.CmdHandler(CloseUsageDialogMsg{})
+		case key.Matches(msg, usageKeys.Up):
+			if d.offset > 0 {
+				d.offset--
+			}
+		case key.Matches(msg, usageKeys.Down):
+			_, h := d.contentSize()
+			if d.offset < len(d.lines())-h {
+				d.offset++
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *usageDialogCmp) contentSize() (int, int) {
+	w, h := 90, 24
+	if d.width > 0 {
+		w = max(40, min(w, d.width-16))
+	}
+	if d.height > 0 {
+		h = max(6, d.height-16)
+	}
+	return w, h
+}
+
+// usageLine is one row of the breakdown; heading rows have no value.
+type usageLine struct {
+	label   string
+	value   string
+	heading bool
+}
+
+func (d *usageDialogCmp) lines() []usageLine {
+	var agents, tools []usageLine
+	for _, e := range d.entries {
+		switch e.Category {
+		case session.UsageTool:
+			tools = append(tools, usageLine{
+				label: fmt.Sprintf("  %-20s ~%d tokens over %d requests", e.Name, e.InputTokens, e.Requests),
+				value: fmt.Sprintf("$%.4f", e.Cost),
+			})
+		default:
+			tokens := e.InputTokens + e.CacheCreationTokens + e.CacheReadTokens + e.OutputTokens
+			agents = append(agents, usageLine{
+				label: fmt.Sprintf("  %-12s %s  %d requests, %d tokens", e.Name, e.Model, e.Requests, tokens),
+				value: fmt.Sprintf("$%.4f", e.Cost),
+			})
+		}
+	}
+	lines := []usageLine{{label: "By agent", heading: true}}
+	if len(agents) == 0 {
+		agents = []usageLine{{label: "  No usage recorded yet"}}
+	}
+	lines = append(lines, agents...)
+	if len(tools) > 0 {
+		lines = append(lines, usageLine{}, usageLine{label: "Re-sent tool output (estimated share of input cost)", heading: true})
+		lines = append(lines, tools...)
+	}
+	if len(d.ratings) > 0 {
+		lines = append(lines, usageLine{}, usageLine{label: "Feedback", heading: true})
+		for _, r := range d.ratings {
+			lines = append(lines, usageLine{
+				label: fmt.Sprintf("  %-12s %s", r.AgentID, r.Model),
+				value: fmt.Sprintf("%d up, %d down", r.Up, r.Down),
+			})
+		}
+	}
+	return lines
+}
+
+func (d *usageDialogCmp) View() tea.View {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	w, h := d.contentSize()
+	title := baseStyle.Foreground(t.Primary()).Bold(true).Width(w).Padding(0, 1)
+	muted := baseStyle.Foreground(t.TextMuted()).Width(w).Padding(0, 1)
+
+	lines := d.lines()
+	end := min(d.offset+h, len(lines))
+	rows := make([]string, 0, end-d.offset)
+	for _, l := range lines[d.offset:end] {
+		label := truncateDialogText(l.label, w-len(l.value)-3)
+		line := label + lipgloss.NewStyle().Width(w-2-lipgloss.Width(label)).Align(lipgloss.Right).Render(l.value)
+		style := baseStyle.Width(w).Padding(0, 1)
+		if l.heading {
+			style = style.Foreground(t.Secondary()).Bold(true)
+		}
+		rows = append(rows, style.Render(line))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title.Render("Session Usage"),
+		muted.Render(fmt.Sprintf("$%.4f, %d tokens including subagents", d.total.Cost, d.total.Tokens)),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		"",
+		muted.Render("↑↓ scroll  esc close"),
+	)
+
+	return tea.NewView(baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 6).
+		Render(content))
+}
+
+func (d *usageDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(usageKeys)
+}
+
+// NewUsageDialogCmp creates the usage breakdown dialog.
+func NewUsageDialogCmp() UsageDialog {
+	return &usageDialogCmp{}
+}
@@ -2,7 +2,10 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -12,14 +15,20 @@ import (
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/cron"
+	"github.com/opencode-ai/opencode/internal/feedback"
+	"github.com/opencode-ai/opencode/internal/flow"
+	"github.com/opencode-ai/opencode/internal/gitsnapshot"
+	"github.com/opencode-ai/opencode/internal/history"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/question"
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/slashcmd"
+	"github.com/opencode-ai/opencode/internal/snippet"
 	"github.com/opencode-ai/opencode/internal/tui/components/chat"
 	"github.com/opencode-ai/opencode/internal/tui/components/core"
 	"github.com/opencode-ai/opencode/internal/tui/components/dialog"
@@ -47,10 +56,35 @@ type keyMap struct {
 
 type (
 	startCompactSessionMsg       struct{}
+	togglePinMsg                 struct{}
+	startInitProjectMsg          struct{}
 	toggleAutoApproveMsg         struct{}
+	toggleTranslationMsg         struct{}
 	toggleVimModeMsg             struct{}
+	toggleUsagePanelMsg          struct{}
+	undoFileChangesMsg           struct{}
+	restoreSnapshotMsg           struct{}
+	cancelBranchMsg              struct{}
+	mergeSessionMsg              struct{}
+	openFileHistoryMsg           struct{}
+	openDiffReviewMsg            struct{}
+	showDiffReviewMsg            struct{ files []dialog.ReviewFile }
+	openSnippetsMsg              struct{}
+	openProfilesMsg              struct{}
+	profileSwitchedMsg           struct{ name string }
+	diffReviewAppliedMsg         struct{ files, hunks int }
+	turnChangedFilesMsg          struct{ count int }
+	showFileHistoryMsg           struct{ files []history.File }
+	openContextInspectorMsg      struct{}
+	showContextInspectorMsg      struct{ report agent.ContextReport }
+	openUsageMsg                 struct{}
+	openForkMsg                  struct{}
+	openRewindMsg                struct{}
+	fileChangesRevertedMsg       struct{ files []string }
+	snapshotRestoredMsg          struct{ files []string }
 	sessionDeletedMsg            struct{ id string }
 	startSessionsCleanupMsg      struct{}
+	regenerateTitleMsg           struct{}
 	showSessionsCleanupDialogMsg struct{ count int }
 	sessionsCleanupDoneMsg       struct{ count int }
 	sessionsCleanupFailedMsg     struct{ err error }
@@ -58,6 +92,38 @@ type (
 	loopFailedMsg                struct{ err error }
 )
 
+// sessionMergedMsg reports the end of a /merge.
+type sessionMergedMsg struct {
+	title  string
+	result app.MergeResult
+	err    error
+}
+
+// projectInitializedMsg reports the end of an /init survey.
+type projectInitializedMsg struct {
+	result app.InitResult
+	err    error
+}
+
+// showForkMsg carries the messages of the session to fork.
+type showForkMsg struct {
+	sessionID string
+	msgs      []message.Message
+}
+
+// showRewindMsg carries the messages of the session whose prompt is edited.
+type showRewindMsg struct {
+	sessionID string
+	msgs      []message.Message
+}
+
+// showUsageMsg carries the loaded usage of the selected session tree.
+type showUsageMsg struct {
+	total    session.Usage
+	entries  []session.UsageEntry
+	feedback []feedback.Tally
+}
+
 const (
 	quitKey = "q"
 )
@@ -154,6 +220,12 @@ type appModel struct {
 
 	showDeleteSessionDialog bool
 	deleteSessionDialog     dialog.SessionDialog
+	// cancelBranchMode makes a pick in deleteSessionDialog cancel the
+	// running subagent or flow step in that session instead of deleting it.
+	cancelBranchMode bool
+	// mergeSessionMode makes a pick in deleteSessionDialog merge that
+	// session into the selected one instead of deleting it.
+	mergeSessionMode bool
 
 	showCommandDialog bool
 	commandDialog     dialog.CommandDialog
@@ -180,6 +252,30 @@ type appModel struct {
 	showMissedCronDialog bool
 	missedCronDialog     dialog.MissedCronDialog
 
+	showFlowGateDialog bool
+	flowGateDialog     dialog.FlowGateDialog
+
+	showFileHistoryDialog bool
+	fileHistoryDialog     dialog.FileHistoryDialog
+
+	showDiffReviewDialog bool
+	diffReviewDialog     dialog.DiffReviewDialog
+
+	showSnippetDialog bool
+	snippetDialog     dialog.SnippetDialog
+
+	showProfileDialog bool
+	profileDialog     dialog.ProfileDialog
+
+	showContextInspectorDialog bool
+	contextInspectorDialog     dialog.ContextInspectorDialog
+	showUsageDialog            bool
+	usageDialog                dialog.UsageDialog
+	showForkDialog             bool
+	forkDialog                 dialog.ForkDialog
+	showRewindDialog           bool
+	rewindDialog               dialog.RewindDialog
+
 	showQuestionDialog bool
 	questionDialog     dialog.QuestionDialogCmp
 
@@ -218,6 +314,24 @@ func (a appModel) Init() tea.Cmd {
 	cmds = append(cmds, cmd)
 	cmd = a.missedCronDialog.Init()
 	cmds = append(cmds, cmd)
+	cmd = a.flowGateDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.fileHistoryDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.diffReviewDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.snippetDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.profileDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.contextInspectorDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.usageDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.forkDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.rewindDialog.Init()
+	cmds = append(cmds, cmd)
 	cmd = a.questionDialog.Init()
 	cmds = append(cmds, cmd)
 
@@ -279,6 +393,35 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.filepicker = filepicker.(dialog.FilepickerCmp)
 		cmds = append(cmds, filepickerCmd)
 
+		fileHistory, fileHistoryCmd := a.fileHistoryDialog.Update(msg)
+		a.fileHistoryDialog = fileHistory.(dialog.FileHistoryDialog)
+		cmds = append(cmds, fileHistoryCmd)
+
+		diffReview, diffReviewCmd := a.diffReviewDialog.Update(msg)
+		a.diffReviewDialog = diffReview.(dialog.DiffReviewDialog)
+		cmds = append(cmds, diffReviewCmd)
+
+		snippets, snippetsCmd := a.snippetDialog.Update(msg)
+		a.snippetDialog = snippets.(dialog.SnippetDialog)
+		cmds = append(cmds, snippetsCmd)
+
+		profiles, profilesCmd := a.profileDialog.Update(msg)
+		a.profileDialog = profiles.(dialog.ProfileDialog)
+		cmds = append(cmds, profilesCmd)
+
+		contextInspector, contextInspectorCmd := a.contextInspectorDialog.Update(msg)
+		a.contextInspectorDialog = contextInspector.(dialog.ContextInspectorDialog)
+		cmds = append(cmds, contextInspectorCmd)
+		usage, usageCmd := a.usageDialog.Update(msg)
+		a.usageDialog = usage.(dialog.UsageDialog)
+		cmds = append(cmds, usageCmd)
+		fork, forkCmd := a.forkDialog.Update(msg)
+		a.forkDialog = fork.(dialog.ForkDialog)
+		cmds = append(cmds, forkCmd)
+		rewind, rewindCmd := a.rewindDialog.Update(msg)
+		a.rewindDialog = rewind.(dialog.RewindDialog)
+		cmds = append(cmds, rewindCmd)
+
 		a.initDialog.SetSize(msg.Width, msg.Height)
 
 		if a.showMultiArgumentsDialog {
@@ -349,6 +492,12 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.app.Permissions.Grant(msg.Permission)
 		case dialog.PermissionAllowForSession:
 			a.app.Permissions.GrantPersistant(msg.Permission)
+		case dialog.PermissionAllowForProject:
+			if err := a.app.Permissions.GrantProject(msg.Permission); err != nil {
+				cmd = util.ReportError(fmt.Errorf("failed to save permission rule: %w", err))
+			} else {
+				cmd = util.ReportInfo(fmt.Sprintf("Saved rule: %s %q allowed in this project", msg.Permission.ToolName, msg.Permission.Pattern))
+			}
 		case dialog.PermissionDeny:
 			a.app.Permissions.Deny(msg.Permission)
 		}
@@ -384,6 +533,8 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialog.CloseSessionDialogMsg:
 		if a.showDeleteSessionDialog {
 			a.showDeleteSessionDialog = false
+			a.cancelBranchMode = false
+			a.mergeSessionMode = false
 			return a, nil
 		}
 		a.showSessionDialog = false
@@ -408,6 +559,429 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.status = s.(core.StatusCmp)
 		return a, util.ReportInfo("Auto-approve enabled")
 
+	case toggleTranslationMsg:
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		lang := a.app.Translations.Language()
+		if lang == "" {
+			return a, util.ReportWarn("Translation is not configured (set translation.language)")
+		}
+		if a.app.Translations.IsEnabled(a.selectedSession.ID) {
+			a.app.Translations.SetEnabled(a.selectedSession.ID, false)
+			return a, util.ReportInfo("Translation disabled")
+		}
+		a.app.Translations.SetEnabled(a.selectedSession.ID, true)
+		return a, util.ReportInfo("Responses will be translated to " + lang)
+
+	case cancelBranchMsg:
+		rootID := a.selectedSession.RootSessionID
+		if rootID == "" {
+			rootID = a.selectedSession.ID
+		}
+		if rootID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		children, err := a.app.Sessions.ListChildren(context.Background(), rootID)
+		if err != nil {
+			return a, util.ReportError(err)
+		}
+		var running []session.Session
+		for _, s := range children {
+			if s.ID != rootID && agent.IsBranchRunning(s.ID) {
+				running = append(running, s)
+			}
+		}
+		if len(running) == 0 {
+			return a, util.ReportWarn("No subagent or flow step is running")
+		}
+		a.cancelBranchMode = true
+		a.mergeSessionMode = false
+		a.deleteSessionDialog.SetTitle("Cancel Subagent")
+		a.deleteSessionDialog.SetSessions(running)
+		a.showDeleteSessionDialog = true
+		return a, nil
+
+	case mergeSessionMsg:
+		targetID := a.selectedSession.ID
+		if targetID == "" || a.selectedSession.ParentSessionID != "" {
+			return a, util.ReportWarn("Open the session to merge into first")
+		}
+		sessions, err := a.app.Sessions.List(context.Background())
+		if err != nil {
+			return a, util.ReportError(err)
+		}
+		var sources []session.Session
+		for _, s := range sessions {
+			if s.ID != targetID && s.ParentSessionID == "" && s.MessageCount > 0 {
+				sources = append(sources, s)
+			}
+		}
+		if len(sources) == 0 {
+			return a, util.ReportWarn("No other session to merge")
+		}
+		a.cancelBranchMode = false
+		a.mergeSessionMode = true
+		a.deleteSessionDialog.SetTitle("Merge Into This Session")
+		a.deleteSessionDialog.SetSessions(sources)
+		a.showDeleteSessionDialog = true
+		return a, nil
+
+	case sessionMergedMsg:
+		if msg.err != nil {
+			return a, util.ReportError(fmt.Errorf("merge failed: %w", msg.err))
+		}
+		info := fmt.Sprintf("Merged %s", msg.title)
+		if n := len(msg.result.Files); n > 0 {
+			info += fmt.Sprintf(" with %d changed file(s)", n)
+		}
+		return a, util.ReportInfo(info)
+
+	case togglePinMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		return a, func() tea.Msg {
+			msg, err := a.app.TogglePin(context.Background(), sessionID)
+			if errors.Is(err, app.ErrNothingToPin) {
+				return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No prompt to pin yet"}
+			}
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to pin prompt: " + err.Error()}
+			}
+			if msg.IsPinned() {
+				return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Pinned the last prompt; compaction keeps it verbatim"}
+			}
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Unpinned the last prompt"}
+		}
+
+	case undoFileChangesMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		if a.app.ActiveAgent().IsSessionBusy(sessionID) {
+			return a, util.ReportWarn("Wait for the agent to finish before undoing changes")
+		}
+		return a, func() tea.Msg {
+			files, err := undoLastFileChanges(context.Background(), a.app, sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Undo failed: " + err.Error()}
+			}
+			return fileChangesRevertedMsg{files: files}
+		}
+
+	case restoreSnapshotMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		if a.app.ActiveAgent().IsSessionBusy(sessionID) {
+			return a, util.ReportWarn("Wait for the agent to finish before restoring the snapshot")
+		}
+		return a, func() tea.Msg {
+			files, err := gitsnapshot.Restore(context.Background(), config.WorkingDirectory(), sessionID)
+			if errors.Is(err, gitsnapshot.ErrNoSnapshot) && !config.Get().AutoSnapshot {
+				return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No snapshot for this session; set \"autoSnapshot\": true to record one before each agent run"}
+			}
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Restore failed: " + err.Error()}
+			}
+			return snapshotRestoredMsg{files: files}
+		}
+
+	case openFileHistoryMsg:
+		sessionID := a.selectedSession.ID
+		rootID := a.selectedSession.RootSessionID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		return a, func() tea.Msg {
+			ctx := context.Background()
+			var files []history.File
+			var err error
+			if rootID != "" {
+				files, err = a.app.History.ListBySessionTree(ctx, rootID)
+			} else {
+				files, err = a.app.History.ListBySession(ctx, sessionID)
+			}
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load file history: " + err.Error()}
+			}
+			return showFileHistoryMsg{files: files}
+		}
+
+	case showFileHistoryMsg:
+		a.fileHistoryDialog.SetFiles(msg.files)
+		a.showFileHistoryDialog = true
+		return a, nil
+
+	case dialog.CloseFileHistoryDialogMsg:
+		a.showFileHistoryDialog = false
+		return a, nil
+
+	case openDiffReviewMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		if a.app.ActiveAgent().IsSessionBusy(sessionID) {
+			return a, util.ReportWarn("Wait for the agent to finish before reviewing its changes")
+		}
+		return a, func() tea.Msg {
+			files, err := lastTurnChanges(context.Background(), a.app, sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load the changes: " + err.Error()}
+			}
+			if len(files) == 0 {
+				return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No file changes to review"}
+			}
+			return showDiffReviewMsg{files: files}
+		}
+
+	case showDiffReviewMsg:
+		a.diffReviewDialog.SetFiles(msg.files)
+		a.showDiffReviewDialog = true
+		return a, nil
+
+	case dialog.CloseDiffReviewDialogMsg:
+		a.showDiffReviewDialog = false
+		return a, nil
+
+	case dialog.ApplyDiffReviewMsg:
+		a.showDiffReviewDialog = false
+		if len(msg.Files) == 0 {
+			return a, util.ReportInfo("All changes kept")
+		}
+		sessionID := a.selectedSession.ID
+		return a, func() tea.Msg {
+			hunks := 0
+			for _, f := range msg.Files {
+				if err := applyReviewedFile(context.Background(), a.app, sessionID, f); err != nil {
+					return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to revert " + f.Path + ": " + err.Error()}
+				}
+				hunks += f.Rejected
+			}
+			return diffReviewAppliedMsg{files: len(msg.Files), hunks: hunks}
+		}
+
+	case diffReviewAppliedMsg:
+		return a, util.ReportInfo(fmt.Sprintf("Reverted %d hunk(s) in %d file(s)", msg.hunks, msg.files))
+
+	case openSnippetsMsg:
+		a.snippetDialog.SetSnippets(snippet.Load())
+		a.showSnippetDialog = true
+		return a, nil
+
+	case dialog.CloseSnippetDialogMsg:
+		a.showSnippetDialog = false
+		return a, nil
+
+	case dialog.SnippetSelectedMsg:
+		a.showSnippetDialog = false
+		a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
+		return a, cmd
+
+	case openProfilesMsg:
+		cfg := config.Get()
+		if len(cfg.Profiles) == 0 {
+			return a, util.ReportWarn("No profiles configured; add them under \"profiles\" in the config")
+		}
+		a.profileDialog.SetProfiles(cfg.ProfileNames(), cfg.Profile)
+		a.showProfileDialog = true
+		return a, nil
+
+	case dialog.CloseProfileDialogMsg:
+		a.showProfileDialog = false
+		return a, nil
+
+	case dialog.ProfileSelectedMsg:
+		a.showProfileDialog = false
+		name := msg.Name
+		return a, func() tea.Msg {
+			if err := a.app.SwitchProfile(name); err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to switch profile: " + err.Error()}
+			}
+			return profileSwitchedMsg{name: name}
+		}
+
+	case profileSwitchedMsg:
+		info := "Switched to profile " + msg.name
+		if msg.name == "" {
+			info = "Profile cleared"
+		}
+		return a, tea.Batch(
+			util.CmdHandler(core.ActiveAgentChangedMsg{Name: a.app.ActiveAgentName()}),
+			util.CmdHandler(chat.AgentChangedMsg{Name: a.app.ActiveAgentName()}),
+			util.ReportInfo(info),
+		)
+
+	case turnChangedFilesMsg:
+		return a, util.ReportInfo(fmt.Sprintf("Changed %d file(s); /changes reviews them hunk by hunk", msg.count))
+
+	case openContextInspectorMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		activeAgent := a.app.ActiveAgent()
+		return a, func() tea.Msg {
+			report, err := activeAgent.InspectContext(context.Background(), sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to inspect context: " + err.Error()}
+			}
+			return showContextInspectorMsg{report: report}
+		}
+
+	case showContextInspectorMsg:
+		a.contextInspectorDialog.SetReport(msg.report)
+		a.showContextInspectorDialog = true
+		return a, nil
+
+	case dialog.CloseContextInspectorDialogMsg:
+		a.showContextInspectorDialog = false
+		return a, nil
+
+	case dialog.ApplyContextExclusionsMsg:
+		a.showContextInspectorDialog = false
+		if err := a.app.ActiveAgent().ExcludeFromNextTurn(msg.SessionID, msg.IDs); err != nil {
+			return a, util.ReportError(err)
+		}
+		if len(msg.IDs) == 0 {
+			return a, util.ReportInfo("Next turn sends the full context")
+		}
+		return a, util.ReportInfo(fmt.Sprintf("Excluding %d items from the next turn", len(msg.IDs)))
+
+	case openUsageMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		sessions := a.app.Sessions
+		feedbacks := a.app.Feedback
+		return a, func() tea.Msg {
+			total, err := sessions.TreeUsage(context.Background(), sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load usage: " + err.Error()}
+			}
+			entries, err := sessions.UsageBreakdown(context.Background(), sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load usage: " + err.Error()}
+			}
+			ratings, err := feedbacks.List(context.Background(), sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load feedback: " + err.Error()}
+			}
+			return showUsageMsg{total: total, entries: entries, feedback: feedback.Summarize(ratings)}
+		}
+
+	case showUsageMsg:
+		a.usageDialog.SetUsage(msg.total, msg.entries)
+		a.usageDialog.SetFeedback(msg.feedback)
+		a.showUsageDialog = true
+		return a, nil
+
+	case dialog.CloseUsageDialogMsg:
+		a.showUsageDialog = false
+		return a, nil
+
+	case openForkMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		if a.app.ActiveAgent().IsSessionBusy(sessionID) {
+			return a, util.ReportWarn("Agent is busy, please wait before forking the session...")
+		}
+		return a, func() tea.Msg {
+			msgs, err := a.app.Messages.List(context.Background(), sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load messages: " + err.Error()}
+			}
+			return showForkMsg{sessionID: sessionID, msgs: msgs}
+		}
+
+	case showForkMsg:
+		a.forkDialog.SetMessages(msg.sessionID, msg.msgs)
+		a.showForkDialog = true
+		return a, nil
+
+	case dialog.CloseForkDialogMsg:
+		a.showForkDialog = false
+		return a, nil
+
+	case dialog.ForkSessionMsg:
+		a.showForkDialog = false
+		sessions := a.app.Sessions
+		return a, func() tea.Msg {
+			fork, err := sessions.Fork(context.Background(), msg.SessionID, msg.MessageID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Fork failed: " + err.Error()}
+			}
+			return chat.SessionSelectedMsg(fork)
+		}
+
+	case openRewindMsg:
+		sessionID := a.selectedSession.ID
+		if sessionID == "" {
+			return a, util.ReportWarn("No active session")
+		}
+		if a.app.ActiveAgent().IsSessionBusy(sessionID) {
+			return a, util.ReportWarn("Agent is busy, please wait before editing a prompt...")
+		}
+		return a, func() tea.Msg {
+			msgs, err := a.app.Messages.List(context.Background(), sessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load messages: " + err.Error()}
+			}
+			return showRewindMsg{sessionID: sessionID, msgs: msgs}
+		}
+
+	case showRewindMsg:
+		a.rewindDialog.SetMessages(msg.sessionID, msg.msgs)
+		a.showRewindDialog = true
+		return a, nil
+
+	case dialog.CloseRewindDialogMsg:
+		a.showRewindDialog = false
+		return a, nil
+
+	case dialog.RewindPromptMsg:
+		a.showRewindDialog = false
+		return a, func() tea.Msg {
+			text, attachments, err := a.app.Rewind(context.Background(), msg.SessionID, msg.MessageID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Rewind failed: " + err.Error()}
+			}
+			if msg.Regenerate {
+				return chat.SendMsg{Text: text, Attachments: attachments}
+			}
+			return chat.EditPromptMsg{Text: text, Attachments: attachments}
+		}
+
+	case dialog.RestoreFileVersionMsg:
+		sessionID := a.selectedSession.ID
+		file := msg.File
+		return a, func() tea.Msg {
+			if err := restoreFileVersion(context.Background(), a.app, sessionID, file); err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: "Restore failed: " + err.Error()}
+			}
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Restored %s to %s", filepath.Base(file.Path), file.Version)}
+		}
+
+	case snapshotRestoredMsg:
+		if len(msg.files) == 0 {
+			return a, util.ReportInfo("Work tree already matches the snapshot")
+		}
+		return a, util.ReportInfo(fmt.Sprintf("Restored %d file(s) from the snapshot", len(msg.files)))
+
+	case fileChangesRevertedMsg:
+		if len(msg.files) == 0 {
+			return a, util.ReportInfo("No file changes to undo")
+		}
+		return a, util.ReportInfo(fmt.Sprintf("Reverted %d file(s)", len(msg.files)))
+
 	case toggleVimModeMsg:
 		newVal := !config.Get().TUI.VimMode
 		if err := config.UpdateVimMode(newVal); err != nil {
@@ -422,6 +996,23 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			util.ReportInfo(statusMsg),
 		)
 
+	case toggleUsagePanelMsg:
+		newVal := !config.Get().TUI.UsagePanel
+		if err := config.UpdateUsagePanel(newVal); err != nil {
+			return a, util.ReportError(err)
+		}
+		statusMsg := "Usage panel shown"
+		if !newVal {
+			statusMsg = "Usage panel hidden"
+		}
+		return a, tea.Batch(
+			util.CmdHandler(chat.ToggleUsagePanelMsg{}),
+			util.ReportInfo(statusMsg),
+		)
+
+	case regenerateTitleMsg:
+		return a, a.handleRetitleCommand()
+
 	case startSessionsCleanupMsg:
 		activeID := a.selectedSession.ID
 		return a, func() tea.Msg {
@@ -498,6 +1089,41 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// the dialog stays hidden until the next event/activation.
 		return a, nil
 
+	case pubsub.Event[flow.FlowState]:
+		// Gated flow steps pause until the user decides. Any later
+		// transition of the same step means it was resolved elsewhere
+		// (API or another process), so drop it from the queue.
+		if msg.Payload.Status == flow.FlowStatusWaitingApproval {
+			a.flowGateDialog.AddGate(msg.Payload)
+			a.showFlowGateDialog = true
+		} else {
+			a.flowGateDialog.RemoveGate(msg.Payload.SessionID)
+			if !a.flowGateDialog.HasGates() {
+				a.showFlowGateDialog = false
+			}
+		}
+		return a, nil
+
+	case dialog.ResolveFlowGateMsg:
+		flows := a.app.Flows
+		return a, func() tea.Msg {
+			if flows == nil {
+				return nil
+			}
+			if _, err := flows.ResolveGate(context.Background(), msg.SessionID, msg.Approved); err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("Failed to resolve flow step %s: %s", msg.StepID, err)}
+			}
+			verb := "rejected"
+			if msg.Approved {
+				verb = "approved"
+			}
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Flow step %s %s", msg.StepID, verb)}
+		}
+
+	case dialog.CloseFlowGateDialogMsg:
+		a.showFlowGateDialog = false
+		return a, nil
+
 	case dialog.CommandRunCustomMsg:
 		if msg.CommandID == "loop" {
 			return a, a.handleLoopCommand(msg.Args)
@@ -505,6 +1131,9 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.CommandID == "rename" {
 			return a, a.handleRenameCommand(msg.Args)
 		}
+		if msg.CommandID == "good" || msg.CommandID == "bad" {
+			return a, a.handleFeedbackCommand(msg.CommandID, msg.Args)
+		}
 
 	case loopCreatedMsg:
 		return a, util.ReportInfo(msg.info)
@@ -512,6 +1141,30 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case loopFailedMsg:
 		return a, util.ReportError(msg.err)
 
+	case startInitProjectMsg:
+		// The survey runs in a session of its own, so the chat stays free.
+		return a, tea.Batch(
+			util.ReportInfo("Surveying the repository with the explorer agent..."),
+			func() tea.Msg {
+				res, err := a.app.InitProject(context.Background(), "")
+				return projectInitializedMsg{result: res, err: err}
+			},
+		)
+
+	case projectInitializedMsg:
+		if msg.err != nil {
+			return a, util.ReportError(msg.err)
+		}
+		verb := "Updated"
+		if msg.result.Created {
+			verb = "Created"
+		}
+		info := fmt.Sprintf("%s %s; agents read it from their next request", verb, msg.result.File)
+		if msg.result.Registered {
+			info += " (added to contextPaths)"
+		}
+		return a, util.ReportInfo(info)
+
 	case startCompactSessionMsg:
 		// Start compacting the current session
 		a.isCompacting = true
@@ -538,6 +1191,17 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		a.compactingMessage = payload.Progress
 
+		if payload.Done && payload.HandoffTo != "" && payload.SessionID == a.selectedSession.ID {
+			to := payload.HandoffTo
+			if err := a.app.FollowHandoff(context.Background(), payload.SessionID, to); err != nil {
+				return a, util.ReportError(err)
+			}
+			return a, tea.Batch(
+				util.CmdHandler(core.ActiveAgentChangedMsg{Name: to}),
+				util.CmdHandler(chat.AgentChangedMsg{Name: to}),
+				util.ReportInfo(fmt.Sprintf("Handed off to %s", to)),
+			)
+		}
 		if payload.Done && payload.Type == agent.AgentEventTypeSummarize {
 			a.isCompacting = false
 			return a, util.ReportInfo("Session summarization complete")
@@ -550,6 +1214,16 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				logging.Info("auto-compaction triggered...")
 				return a, util.CmdHandler(startCompactSessionMsg{})
 			}
+			if payload.SessionID == a.selectedSession.ID {
+				sessionID := payload.SessionID
+				return a, func() tea.Msg {
+					files, err := latestTurnChanges(context.Background(), a.app, sessionID)
+					if err != nil || len(files) == 0 {
+						return nil
+					}
+					return turnChangedFilesMsg{count: len(files)}
+				}
+			}
 		}
 		// Continue listening for events
 		return a, nil
@@ -650,6 +1324,26 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.topbar = tb.(core.TopBarCmp)
 	case dialog.SessionSelectedMsg:
 		// if we're in "delete" mode, delete instead of switch
+		if a.showDeleteSessionDialog && a.cancelBranchMode {
+			a.showDeleteSessionDialog = false
+			a.cancelBranchMode = false
+			if !agent.CancelBranch(msg.Session.ID) {
+				return a, util.ReportWarn("That subagent has already finished")
+			}
+			return a, util.ReportInfo("Cancelled " + msg.Session.Title + "; the parent keeps running")
+		}
+		if a.showDeleteSessionDialog && a.mergeSessionMode {
+			a.showDeleteSessionDialog = false
+			a.mergeSessionMode = false
+			targetID, source := a.selectedSession.ID, msg.Session
+			return a, tea.Batch(
+				util.ReportInfo("Merging "+source.Title+"..."),
+				func() tea.Msg {
+					res, err := a.app.MergeSession(context.Background(), targetID, source.ID)
+					return sessionMergedMsg{title: source.Title, result: res, err: err}
+				},
+			)
+		}
 		if a.showDeleteSessionDialog {
 			a.showDeleteSessionDialog = false
 			deletedID := msg.Session.ID
@@ -814,6 +1508,8 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(sessions) == 0 {
 					return a, util.ReportWarn("No sessions available")
 				}
+				a.cancelBranchMode = false
+				a.mergeSessionMode = false
 				a.deleteSessionDialog.SetTitle("Prune Session")
 				a.deleteSessionDialog.SetSessions(sessions)
 				a.showDeleteSessionDialog = true
@@ -1010,6 +1706,80 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if a.showFileHistoryDialog {
+		d, fileHistoryCmd := a.fileHistoryDialog.Update(msg)
+		a.fileHistoryDialog = d.(dialog.FileHistoryDialog)
+		cmds = append(cmds, fileHistoryCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showDiffReviewDialog {
+		d, diffReviewCmd := a.diffReviewDialog.Update(msg)
+		a.diffReviewDialog = d.(dialog.DiffReviewDialog)
+		cmds = append(cmds, diffReviewCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showSnippetDialog {
+		d, snippetCmd := a.snippetDialog.Update(msg)
+		a.snippetDialog = d.(dialog.SnippetDialog)
+		cmds = append(cmds, snippetCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showProfileDialog {
+		d, profileCmd := a.profileDialog.Update(msg)
+		a.profileDialog = d.(dialog.ProfileDialog)
+		cmds = append(cmds, profileCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showRewindDialog {
+		d, rewindCmd := a.rewindDialog.Update(msg)
+		a.rewindDialog = d.(dialog.RewindDialog)
+		cmds = append(cmds, rewindCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showForkDialog {
+		d, forkCmd := a.forkDialog.Update(msg)
+		a.forkDialog = d.(dialog.ForkDialog)
+		cmds = append(cmds, forkCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showUsageDialog {
+		d, usageCmd := a.usageDialog.Update(msg)
+		a.usageDialog = d.(dialog.UsageDialog)
+		cmds = append(cmds, usageCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showContextInspectorDialog {
+		d, contextInspectorCmd := a.contextInspectorDialog.Update(msg)
+		a.contextInspectorDialog = d.(dialog.ContextInspectorDialog)
+		cmds = append(cmds, contextInspectorCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
 	if a.showMissedCronDialog {
 		d, missedCmd := a.missedCronDialog.Update(msg)
 		a.missedCronDialog = d.(dialog.MissedCronDialog)
@@ -1019,6 +1789,15 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if a.showFlowGateDialog {
+		d, gateCmd := a.flowGateDialog.Update(msg)
+		a.flowGateDialog = d.(dialog.FlowGateDialog)
+		cmds = append(cmds, gateCmd)
+		if _, ok := msg.(tea.KeyPressMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
 	switch msg.(type) {
 	case pubsub.Event[agent.MCPServerEvent]:
 		chat.InvalidateMcpCache()
@@ -1103,7 +1882,16 @@ func (a *appModel) anyDismissibleDialogOpen() bool {
 		a.showThemeDialog ||
 		a.showInitDialog ||
 		a.showSessionsCleanupDialog ||
-		a.showMissedCronDialog
+		a.showMissedCronDialog ||
+		a.showFlowGateDialog ||
+		a.showFileHistoryDialog ||
+		a.showDiffReviewDialog ||
+		a.showSnippetDialog ||
+		a.showProfileDialog ||
+		a.showContextInspectorDialog ||
+		a.showUsageDialog ||
+		a.showForkDialog ||
+		a.showRewindDialog
 }
 
 // dismissAllDialogs closes every dismissible overlay. Intended for ctrl+c
@@ -1119,6 +1907,15 @@ func (a *appModel) dismissAllDialogs() {
 	a.showInitDialog = false
 	a.showSessionsCleanupDialog = false
 	a.showMissedCronDialog = false
+	a.showFlowGateDialog = false
+	a.showFileHistoryDialog = false
+	a.showDiffReviewDialog = false
+	a.showSnippetDialog = false
+	a.showProfileDialog = false
+	a.showContextInspectorDialog = false
+	a.showUsageDialog = false
+	a.showForkDialog = false
+	a.showRewindDialog = false
 	if a.showFilepicker {
 		a.showFilepicker = false
 		a.filepicker.ToggleFilepicker(a.showFilepicker)
@@ -1285,10 +2082,46 @@ func (a appModel) View() tea.View {
 		centerOverlay(a.sessionsCleanupDialog.View().Content)
 	}
 
+	if a.showFileHistoryDialog {
+		centerOverlay(a.fileHistoryDialog.View().Content)
+	}
+
+	if a.showDiffReviewDialog {
+		centerOverlay(a.diffReviewDialog.View().Content)
+	}
+
+	if a.showSnippetDialog {
+		centerOverlay(a.snippetDialog.View().Content)
+	}
+
+	if a.showProfileDialog {
+		centerOverlay(a.profileDialog.View().Content)
+	}
+
+	if a.showContextInspectorDialog {
+		centerOverlay(a.contextInspectorDialog.View().Content)
+	}
+
+	if a.showUsageDialog {
+		centerOverlay(a.usageDialog.View().Content)
+	}
+
+	if a.showForkDialog {
+		centerOverlay(a.forkDialog.View().Content)
+	}
+
+	if a.showRewindDialog {
+		centerOverlay(a.rewindDialog.View().Content)
+	}
+
 	if a.showMissedCronDialog {
 		centerOverlay(a.missedCronDialog.View().Content)
 	}
 
+	if a.showFlowGateDialog {
+		centerOverlay(a.flowGateDialog.View().Content)
+	}
+
 	v := tea.NewView(appView)
 	v.AltScreen = true
 	v.ReportFocus = true
@@ -1324,9 +2157,18 @@ func New(app *app.App) tea.Model {
 			page.AgentsPage: page.NewAgentsPage(app.Registry),
 			page.CronsPage:  page.NewCronsPage(app.Crons),
 		},
-		filepicker:            dialog.NewFilepickerCmp(app),
-		sessionsCleanupDialog: dialog.NewSessionsCleanupDialogCmp(),
-		missedCronDialog:      dialog.NewMissedCronDialog(),
+		filepicker:             dialog.NewFilepickerCmp(app),
+		sessionsCleanupDialog:  dialog.NewSessionsCleanupDialogCmp(),
+		missedCronDialog:       dialog.NewMissedCronDialog(),
+		flowGateDialog:         dialog.NewFlowGateDialog(),
+		fileHistoryDialog:      dialog.NewFileHistoryDialogCmp(),
+		diffReviewDialog:       dialog.NewDiffReviewDialogCmp(),
+		snippetDialog:          dialog.NewSnippetDialogCmp(),
+		profileDialog:          dialog.NewProfileDialogCmp(),
+		contextInspectorDialog: dialog.NewContextInspectorDialogCmp(),
+		usageDialog:            dialog.NewUsageDialogCmp(),
+		forkDialog:             dialog.NewForkDialogCmp(),
+		rewindDialog:           dialog.NewRewindDialogCmp(),
 	}
 
 	// Wire the cron scheduler's active-session view to the TUI's selected session.
@@ -1353,8 +2195,8 @@ func buildCommands() []dialog.Command {
 
 	// TUI-specific handlers keyed by command ID
 	handlers := map[string]func(dialog.Command) tea.Cmd{
-		"init": func(cmd dialog.Command) tea.Cmd {
-			return util.CmdHandler(chat.SendMsg{Text: cmd.Content})
+		"init": func(_ dialog.Command) tea.Cmd {
+			return util.CmdHandler(startInitProjectMsg{})
 		},
 		"review": func(cmd dialog.Command) tea.Cmd {
 			return dialog.ParameterizedCommandHandler(cmd.Content, &cmd)
@@ -1365,12 +2207,60 @@ func buildCommands() []dialog.Command {
 		"compact": func(_ dialog.Command) tea.Cmd {
 			return func() tea.Msg { return startCompactSessionMsg{} }
 		},
+		"pin": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return togglePinMsg{} }
+		},
 		"agents": func(_ dialog.Command) tea.Cmd {
 			return util.CmdHandler(page.PageChangeMsg{ID: page.AgentsPage})
 		},
 		"auto-approve": func(_ dialog.Command) tea.Cmd {
 			return func() tea.Msg { return toggleAutoApproveMsg{} }
 		},
+		"translate": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return toggleTranslationMsg{} }
+		},
+		"file-history": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openFileHistoryMsg{} }
+		},
+		"context": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openContextInspectorMsg{} }
+		},
+		"usage": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openUsageMsg{} }
+		},
+		"usage-panel": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return toggleUsagePanelMsg{} }
+		},
+		"fork": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openForkMsg{} }
+		},
+		"edit": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openRewindMsg{} }
+		},
+		"undo": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return undoFileChangesMsg{} }
+		},
+		"restore": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return restoreSnapshotMsg{} }
+		},
+		"cancel-subagent": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return cancelBranchMsg{} }
+		},
+		"subagents": func(_ dialog.Command) tea.Cmd {
+			return util.CmdHandler(chat.ToggleSubagentOutputMsg{})
+		},
+		"merge": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return mergeSessionMsg{} }
+		},
+		"changes": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openDiffReviewMsg{} }
+		},
+		"snippets": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openSnippetsMsg{} }
+		},
+		"profile": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return openProfilesMsg{} }
+		},
 		"vim": func(_ dialog.Command) tea.Cmd {
 			return func() tea.Msg { return toggleVimModeMsg{} }
 		},
@@ -1387,6 +2277,27 @@ func buildCommands() []dialog.Command {
 				}
 			}
 		},
+		"retitle": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg { return regenerateTitleMsg{} }
+		},
+		"good": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg {
+				return dialog.ShowMultiArgumentsDialogMsg{
+					CommandID: "good",
+					ArgNames:  []string{"comment"},
+					ArgHints:  map[string]string{"comment": "What worked (optional)"},
+				}
+			}
+		},
+		"bad": func(_ dialog.Command) tea.Cmd {
+			return func() tea.Msg {
+				return dialog.ShowMultiArgumentsDialogMsg{
+					CommandID: "bad",
+					ArgNames:  []string{"comment"},
+					ArgHints:  map[string]string{"comment": "What went wrong (optional)"},
+				}
+			}
+		},
 		"loop": func(_ dialog.Command) tea.Cmd {
 			return func() tea.Msg {
 				return dialog.ShowMultiArgumentsDialogMsg{
@@ -1441,6 +2352,67 @@ func (a appModel) handleRenameCommand(args map[string]string) tea.Cmd {
 	}
 }
 
+// handleRetitleCommand asks the active agent for a new title of the
+// active session, described from its current conversation. Like
+// handleRenameCommand it relies on the session UpdatedEvent for the refresh.
+func (a appModel) handleRetitleCommand() tea.Cmd {
+	if a.selectedSession.ID == "" {
+		return util.ReportWarn("No active session")
+	}
+	sessionID := a.selectedSession.ID
+	return func() tea.Msg {
+		sess, err := a.app.ActiveAgent().RegenerateTitle(context.Background(), sessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to regenerate title: " + err.Error()}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Renamed session to: " + sess.Title}
+	}
+}
+
+// handleFeedbackCommand rates the last assistant reply of the active
+// session from /good or /bad arguments. Rating the same reply again
+// replaces the earlier rating.
+func (a appModel) handleFeedbackCommand(commandID string, args map[string]string) tea.Cmd {
+	if a.selectedSession.ID == "" {
+		return util.ReportWarn("No active session")
+	}
+	rating, err := feedback.ParseRating(commandID)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	sessionID := a.selectedSession.ID
+	agentID := string(a.app.ActiveAgentName())
+	messages, feedbacks := a.app.Messages, a.app.Feedback
+	comment := strings.TrimSpace(args["comment"])
+	return func() tea.Msg {
+		msgs, err := messages.List(context.Background(), sessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to load messages: " + err.Error()}
+		}
+		var reply *message.Message
+		for i := len(msgs) - 1; i >= 0; i-- {
+			if msgs[i].Role == message.Assistant {
+				reply = &msgs[i]
+				break
+			}
+		}
+		if reply == nil {
+			return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No reply to rate yet"}
+		}
+		if _, err := feedbacks.Rate(context.Background(), feedback.Feedback{
+			MessageID: reply.ID,
+			SessionID: sessionID,
+			Rating:    rating,
+			Comment:   comment,
+			AgentID:   agentID,
+			Model:     string(reply.Model),
+		}); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: "Failed to save feedback: " + err.Error()}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Rated the last reply %s", rating)}
+	}
+}
+
 // handleLoopCommand creates a cron job from /loop arguments.
 func (a appModel) handleLoopCommand(args map[string]string) tea.Cmd {
 	if a.app.Crons == nil {
@@ -1531,6 +2503,122 @@ func truncateRunes(s string, max int) string {
 
 // formatDuration returns a human-readable duration string like "30 days",
 // "2 hours", or "5 minutes".
+// undoLastFileChanges reverts the file changes made since the most recent
+// user prompt that led to any. Earlier prompts are tried in turn because
+// reverting consumes a turn's checkpoints, so repeated undos step back
+// through the session.
+func undoLastFileChanges(ctx context.Context, a *app.App, sessionID string) ([]string, error) {
+	msgs, err := a.Messages.List(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != message.User || msgs[i].Synthetic {
+			continue
+		}
+		files, err := a.History.Revert(ctx, sessionID, msgs[i].ID)
+		if err != nil || len(files) > 0 {
+			return files, err
+		}
+	}
+	return nil, nil
+}
+
+// lastTurnChanges returns the files changed since the last prompt that
+// modified any, with their content from before it and on disk now.
+func lastTurnChanges(ctx context.Context, a *app.App, sessionID string) ([]dialog.ReviewFile, error) {
+	msgs, err := a.Messages.List(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != message.User || msgs[i].Synthetic {
+			continue
+		}
+		files, err := changesSince(ctx, a, sessionID, msgs[i].ID)
+		if err != nil || len(files) > 0 {
+			return files, err
+		}
+	}
+	return nil, nil
+}
+
+// latestTurnChanges returns the files changed since the session's latest
+// prompt.
+func latestTurnChanges(ctx context.Context, a *app.App, sessionID string) ([]dialog.ReviewFile, error) {
+	msgs, err := a.Messages.List(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == message.User && !msgs[i].Synthetic {
+			return changesSince(ctx, a, sessionID, msgs[i].ID)
+		}
+	}
+	return nil, nil
+}
+
+// changesSince returns the files changed since the prompt messageID with
+// their content from before it and on disk now. Files that were changed and
+// then put back are left out.
+func changesSince(ctx context.Context, a *app.App, sessionID, messageID string) ([]dialog.ReviewFile, error) {
+	baseline, err := a.History.Baseline(ctx, sessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]dialog.ReviewFile, 0, len(baseline))
+	for _, b := range baseline {
+		f := dialog.ReviewFile{Path: b.Path, Before: b.Content, Existed: b.Existed}
+		content, err := os.ReadFile(b.Path)
+		switch {
+		case err == nil:
+			f.After, f.Exists = string(content), true
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+		if f.Before != f.After || f.Existed != f.Exists {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// applyReviewedFile puts the hunks rejected in review back on disk and
+// records the result as a new version so the sidebar and later diffs
+// reflect it.
+func applyReviewedFile(ctx context.Context, a *app.App, sessionID string, f dialog.ReviewedFile) error {
+	if f.Remove {
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.Path, []byte(f.Content), 0o644); err != nil {
+			return err
+		}
+	}
+	_, err := a.History.CreateVersion(ctx, sessionID, f.Path, f.Content)
+	return err
+}
+
+// restoreFileVersion writes a recorded version back to disk and records the
+// restore as a new version so the sidebar and later diffs reflect it.
+func restoreFileVersion(ctx context.Context, a *app.App, sessionID string, file history.File) error {
+	if err := os.MkdirAll(filepath.Dir(file.Path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file.Path, []byte(file.Content), 0o644); err != nil {
+		return err
+	}
+	if sessionID == "" {
+		sessionID = file.SessionID
+	}
+	_, err := a.History.CreateVersion(ctx, sessionID, file.Path, file.Content)
+	return err
+}
+
 func formatDuration(d time.Duration) string {
 	if days := int(d.Hours() / 24); days > 0 {
 		if days == 1 {
@@ -0,0 +1,205 @@
+// Package watch implements a debounced, glob-filtered recursive file
+// watcher used by `opencode watch` to re-trigger agent runs and flows.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/opencode-ai/opencode/internal/fileutil"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// DefaultDebounce is the quiet period after the last matching change
+// before a trigger fires.
+const DefaultDebounce = 500 * time.Millisecond
+
+// TriggerFunc is invoked once per debounced batch of changes with the
+// sorted, de-duplicated list of changed paths (relative to the root).
+type TriggerFunc func(ctx context.Context, changed []string)
+
+// Watcher recursively watches a root directory and fires a TriggerFunc
+// when files matching any of its doublestar patterns change.
+//
+// Triggers never overlap: changes that arrive while a trigger is
+// running are accumulated and fire exactly one follow-up trigger once
+// the current one returns.
+type Watcher struct {
+	root     string
+	patterns []string
+	debounce time.Duration
+	trigger  TriggerFunc
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+	timer   *time.Timer
+	running bool
+	rerun   bool
+}
+
+// New validates the patterns and returns a Watcher rooted at root.
+// Patterns are matched against slash-separated paths relative to root.
+func New(root string, patterns []string, debounce time.Duration, trigger TriggerFunc) (*Watcher, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one path pattern is required")
+	}
+	for _, p := range patterns {
+		if !doublestar.ValidatePattern(p) {
+			return nil, fmt.Errorf("invalid path pattern %q", p)
+		}
+	}
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving watch root: %w", err)
+	}
+	return &Watcher{
+		root:     abs,
+		patterns: patterns,
+		debounce: debounce,
+		trigger:  trigger,
+		pending:  make(map[string]struct{}),
+	}, nil
+}
+
+// Matches reports whether the root-relative path matches any pattern.
+func (w *Watcher) Matches(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, p := range w.patterns {
+		if ok, _ := doublestar.Match(p, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Run blocks until ctx is cancelled, dispatching debounced triggers.
+func (w *Watcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	if err := w.addTree(fsw, w.root); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.mu.Lock()
+			if w.timer != nil {
+				w.timer.Stop()
+			}
+			w.mu.Unlock()
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(ctx, fsw, event)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			logging.Warn("Watch: file watcher error", "error", err)
+		}
+	}
+}
+
+func (w *Watcher) handleEvent(ctx context.Context, fsw *fsnotify.Watcher, event fsnotify.Event) {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return
+	}
+	// New directories need their own watch so nested changes are seen.
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.addTree(fsw, event.Name); err != nil {
+				logging.Warn("Watch: failed to watch new directory", "path", event.Name, "error", err)
+			}
+			return
+		}
+	}
+
+	rel, err := filepath.Rel(w.root, event.Name)
+	if err != nil || fileutil.SkipHidden(rel) || !w.Matches(rel) {
+		return
+	}
+	w.schedule(ctx, filepath.ToSlash(rel))
+}
+
+func (w *Watcher) schedule(ctx context.Context, rel string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending[rel] = struct{}{}
+	if w.running {
+		w.rerun = true
+		return
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, func() { w.fire(ctx) })
+}
+
+func (w *Watcher) fire(ctx context.Context) {
+	w.mu.Lock()
+	if w.running || len(w.pending) == 0 || ctx.Err() != nil {
+		w.mu.Unlock()
+		return
+	}
+	changed := make([]string, 0, len(w.pending))
+	for p := range w.pending {
+		changed = append(changed, p)
+	}
+	sort.Strings(changed)
+	w.pending = make(map[string]struct{})
+	w.running = true
+	w.rerun = false
+	w.mu.Unlock()
+
+	w.trigger(ctx, changed)
+
+	w.mu.Lock()
+	w.running = false
+	again := w.rerun && len(w.pending) > 0
+	w.rerun = false
+	if again {
+		w.timer = time.AfterFunc(w.debounce, func() { w.fire(ctx) })
+	}
+	w.mu.Unlock()
+}
+
+// addTree registers dir and every non-ignored subdirectory with fsw.
+func (w *Watcher) addTree(fsw *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subtrees are skipped rather than aborting the walk.
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != w.root {
+			if rel, relErr := filepath.Rel(w.root, path); relErr == nil && fileutil.SkipHidden(rel) {
+				return filepath.SkipDir
+			}
+		}
+		if err := fsw.Add(path); err != nil {
+			return fmt.Errorf("watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
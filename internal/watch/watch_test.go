@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	w, err := New(t.TempDir(), []string{"src/**/*.go", "README.md"}, 0, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	cases := map[string]bool{
+		"src/main.go":         true,
+		"src/pkg/util/x.go":   true,
+		"src/pkg/util/x.txt":  false,
+		"README.md":           true,
+		"docs/README.md":      false,
+		"other/src/nested.go": false,
+	}
+	for path, want := range cases {
+		if got := w.Matches(path); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidInput(t *testing.T) {
+	if _, err := New(t.TempDir(), nil, 0, nil); err == nil {
+		t.Error("expected error for empty patterns")
+	}
+	if _, err := New(t.TempDir(), []string{"src/[a"}, 0, nil); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
+
+func TestRunDebouncesChanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var batches [][]string
+	fired := make(chan struct{}, 10)
+	w, err := New(root, []string{"src/**"}, 100*time.Millisecond, func(_ context.Context, changed []string) {
+		mu.Lock()
+		batches = append(batches, changed)
+		mu.Unlock()
+		fired <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	// Give fsnotify a moment to register the initial tree.
+	time.Sleep(100 * time.Millisecond)
+
+	for _, name := range []string{"a.go", "b.go", "a.go"} {
+		if err := os.WriteFile(filepath.Join(root, "src", name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "ignored.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fired:
+	case <-time.After(3 * time.Second):
+		t.Fatal("trigger did not fire")
+	}
+	// No second batch should follow for the same burst.
+	select {
+	case <-fired:
+		t.Fatal("burst of writes fired more than once")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 || len(batches[0]) != 2 || batches[0][0] != "src/a.go" || batches[0][1] != "src/b.go" {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+}